@@ -0,0 +1,169 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/graphql-go/graphql"
+)
+
+// Live storefront announcements.
+// The orders service publishes AnnouncementPublished on orders.events; each gateway instance
+// binds its own exclusive queue (as for catalog events) and fans the events out to the
+// announcementPublished subscriptions it is serving. Clients that were disconnected catch up
+// with the announcements query.
+
+// announcementEventsExchange and the routing key the orders service publishes announcements on
+const (
+    announcementEventsExchange = "orders.events"
+    announcementRoutingKey     = "announcement.published"
+)
+
+// announcementSubscriberBuffer is how many announcements a slow subscriber may fall behind
+// before further ones are dropped for it
+const announcementSubscriberBuffer = 16
+
+// announcementEvent is the AnnouncementPublished payload
+type announcementEvent struct {
+    AnnouncementID int64      `json:"announcement_id"`
+    Kind           string     `json:"kind"`
+    Title          string     `json:"title"`
+    Message        string     `json:"message"`
+    StartsAt       time.Time  `json:"starts_at"`
+    EndsAt         *time.Time `json:"ends_at,omitempty"`
+}
+
+// announcement returns the event in the shape of the Announcement type (as served by orders)
+func (e announcementEvent) announcement() map[string]interface{} {
+    a := map[string]interface{}{
+        "id":        e.AnnouncementID,
+        "kind":      e.Kind,
+        "title":     e.Title,
+        "message":   e.Message,
+        "starts_at": e.StartsAt.Format(time.RFC3339),
+    }
+    if e.EndsAt != nil {
+        a["ends_at"] = e.EndsAt.Format(time.RFC3339)
+    }
+    return a
+}
+
+// AnnouncementHub fans published announcements out to this instance's subscribers
+type AnnouncementHub struct {
+    mu          sync.Mutex
+    subscribers map[chan interface{}]struct{}
+}
+
+// NewAnnouncementHub creates an empty hub
+func NewAnnouncementHub() *AnnouncementHub {
+    return &AnnouncementHub{subscribers: make(map[chan interface{}]struct{})}
+}
+
+// Subscribe returns a channel of announcements that is closed when ctx is done
+func (h *AnnouncementHub) Subscribe(ctx context.Context) chan interface{} {
+    ch := make(chan interface{}, announcementSubscriberBuffer)
+
+    h.mu.Lock()
+    h.subscribers[ch] = struct{}{}
+    h.mu.Unlock()
+
+    go func() {
+        <-ctx.Done()
+        h.mu.Lock()
+        delete(h.subscribers, ch)
+        h.mu.Unlock()
+        close(ch)
+    }()
+
+    return ch
+}
+
+// Publish sends an announcement to every subscriber.
+// Why: a stalled client must not hold up the consumer, so a full subscriber misses it.
+func (h *AnnouncementHub) Publish(announcement interface{}) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    for ch := range h.subscribers {
+        select {
+        case ch <- announcement:
+        default:
+            log.Println("⚠️  Announcement subscriber is behind, dropping announcement")
+        }
+    }
+}
+
+// ConsumeAnnouncementEvents feeds published announcements to the hub until ctx is done,
+// reconnecting to RabbitMQ whenever the connection drops
+func ConsumeAnnouncementEvents(ctx context.Context, rabbitURL string, hub *AnnouncementHub) {
+    consumeEvents(ctx, rabbitURL, eventSubscription{
+        name:        "Announcement",
+        exchange:    announcementEventsExchange,
+        routingKeys: []string{announcementRoutingKey},
+        onConnect: func() {
+            log.Println("✓ Listening for announcements")
+        },
+        handle: func(body []byte) {
+            var event announcementEvent
+            if err := json.Unmarshal(body, &event); err != nil {
+                log.Printf("⚠️  Unreadable announcement event: %v", err)
+                return
+            }
+            hub.Publish(event.announcement())
+        },
+    })
+}
+
+// subscriptionHandler serves GraphQL subscriptions as server-sent events:
+// GET /graphql/subscriptions?query=...&variables=... streams one `data:` line per result
+func subscriptionHandler(schema *graphql.Schema, limits QueryLimitsConfig) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        query := c.Query("query")
+        if query == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter required"})
+            return
+        }
+        var variables map[string]interface{}
+        if raw := c.Query("variables"); raw != "" {
+            if err := json.Unmarshal([]byte(raw), &variables); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variables parameter"})
+                return
+            }
+        }
+
+        // Why: the server's read and write timeouts would cut the stream off (an expired read
+        // deadline cancels the request context too)
+        rc := http.NewResponseController(c.Writer)
+        if err := rc.SetReadDeadline(time.Time{}); err != nil {
+            log.Printf("⚠️  Failed to clear read deadline for subscription: %v", err)
+        }
+        if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+            log.Printf("⚠️  Failed to clear write deadline for subscription: %v", err)
+        }
+
+        c.Header("Content-Type", "text/event-stream")
+        c.Header("Cache-Control", "no-cache")
+        c.Header("Connection", "keep-alive")
+        c.Status(http.StatusOK)
+        c.Writer.Flush()
+
+        results := ExecuteSubscription(query, variables, schema, limits, c.Request.Context())
+        for result := range results {
+            payload, err := json.Marshal(FormatResult(result))
+            if err != nil {
+                log.Printf("⚠️  Failed to encode subscription result: %v", err)
+                continue
+            }
+            if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", payload); err != nil {
+                return
+            }
+            c.Writer.Flush()
+        }
+    }
+}
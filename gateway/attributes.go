@@ -0,0 +1,100 @@
+package main
+
+import (
+    "fmt"
+    "net/url"
+    "sort"
+    "strconv"
+)
+
+// Product attributes
+// Products carry free-form specs as a JSON object (e.g. {"screen_size": 15.6}); the products
+// service checks them against attribute templates of the product's category. GraphQL has no
+// "any" scalar, so each attribute is exposed as a row with one typed value field set.
+
+// productAttributes turns a product's attributes object into rows sorted by name
+func productAttributes(attributes map[string]interface{}) []map[string]interface{} {
+    names := make([]string, 0, len(attributes))
+    for name := range attributes {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    rows := make([]map[string]interface{}, 0, len(names))
+    for _, name := range names {
+        row := map[string]interface{}{"name": name}
+        switch v := attributes[name].(type) {
+        case string:
+            row["value"] = v
+            row["string_value"] = v
+        case float64:
+            row["value"] = strconv.FormatFloat(v, 'f', -1, 64)
+            row["number_value"] = v
+        case bool:
+            row["value"] = strconv.FormatBool(v)
+            row["boolean_value"] = v
+        default:
+            row["value"] = fmt.Sprint(v)
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+// attributeInputs turns [ProductAttributeInput] into the attributes object sent to products.
+// An input with no value set removes the attribute on update.
+func attributeInputs(inputs []interface{}) (map[string]interface{}, error) {
+    attributes := make(map[string]interface{}, len(inputs))
+    for _, entry := range inputs {
+        input, _ := entry.(map[string]interface{})
+        name, _ := input["name"].(string)
+        if _, dup := attributes[name]; dup {
+            return nil, Validation(fmt.Sprintf("attribute %q is given more than once", name))
+        }
+
+        var value interface{}
+        set := 0
+        for _, field := range []string{"string_value", "number_value", "boolean_value"} {
+            if v, ok := input[field]; ok && v != nil {
+                value = v
+                set++
+            }
+        }
+        if set > 1 {
+            return nil, Validation(fmt.Sprintf("attribute %q must set only one of string_value, number_value and boolean_value", name))
+        }
+        attributes[name] = value
+    }
+    return attributes, nil
+}
+
+// attributeFilterParams turns [AttributeFilterInput] into the products service's
+// attr[name]=value, attr_min[name]=n and attr_max[name]=n query parameters
+func attributeFilterParams(filters []interface{}) (url.Values, error) {
+    params := url.Values{}
+    for _, entry := range filters {
+        filter, _ := entry.(map[string]interface{})
+        name, _ := filter["name"].(string)
+
+        value, hasValue := filter["value"].(string)
+        min, hasMin := filter["min"].(float64)
+        max, hasMax := filter["max"].(float64)
+        if !hasValue && !hasMin && !hasMax {
+            return nil, Validation(fmt.Sprintf("attribute filter %q needs a value, min or max", name))
+        }
+        if hasMin && hasMax && min > max {
+            return nil, Validation(fmt.Sprintf("attribute filter %q has min greater than max", name))
+        }
+
+        if hasValue {
+            params.Set("attr["+name+"]", value)
+        }
+        if hasMin {
+            params.Set("attr_min["+name+"]", strconv.FormatFloat(min, 'f', -1, 64))
+        }
+        if hasMax {
+            params.Set("attr_max["+name+"]", strconv.FormatFloat(max, 'f', -1, 64))
+        }
+    }
+    return params, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+    "context"
     "fmt"
     "strings"
 
@@ -12,34 +13,69 @@ type UserClaims struct {
     UserID   string `json:"user_id"`
     Email    string `json:"email"`
     Username string `json:"username"`
+    Role     string `json:"role,omitempty"`
     jwt.RegisteredClaims
 }
 
 // TokenValidator validates JWT tokens
 type TokenValidator struct {
-    secret string
+    keys  *JWTKeySet
+    cache *ClaimCache // nil disables caching
 }
 
-// NewTokenValidator creates a new token validator
-func NewTokenValidator(secret string) *TokenValidator {
+// NewTokenValidator creates a new token validator; cache may be nil
+func NewTokenValidator(keys *JWTKeySet, cache *ClaimCache) *TokenValidator {
     return &TokenValidator{
-        secret: secret,
+        keys:  keys,
+        cache: cache,
     }
 }
 
-// ValidateToken validates and parses JWT token
-func (tv *TokenValidator) ValidateToken(tokenString string) (*UserClaims, error) {
+// ValidateToken validates and parses JWT token, using cached claims when available
+func (tv *TokenValidator) ValidateToken(ctx context.Context, tokenString string) (*UserClaims, error) {
     // Remove "Bearer " prefix if present
     tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
+    if tv.cache == nil {
+        return tv.parseToken(tokenString)
+    }
+
+    key := tokenKey(tokenString)
+    claims, err := tv.cache.Get(ctx, key)
+    if err != nil {
+        return nil, err
+    }
+    if claims != nil {
+        return claims, nil
+    }
+
+    claims, err = tv.parseToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+    tv.cache.Put(ctx, key, claims)
+    return claims, nil
+}
+
+// RevokeToken rejects the token on every gateway instance until it expires (logout)
+func (tv *TokenValidator) RevokeToken(ctx context.Context, tokenString string) error {
+    tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+    claims, err := tv.parseToken(tokenString)
+    if err != nil {
+        return err
+    }
+    if tv.cache == nil {
+        return fmt.Errorf("token revocation requires the claim cache")
+    }
+    return tv.cache.Revoke(ctx, tokenKey(tokenString), claims)
+}
+
+// parseToken verifies the signature and expiry of a raw token
+func (tv *TokenValidator) parseToken(tokenString string) (*UserClaims, error) {
     claims := &UserClaims{}
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        // Verify signing method
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(tv.secret), nil
-    })
+    // The key is picked by kid, and must match the token's alg
+    token, err := jwt.ParseWithClaims(tokenString, claims, tv.keys.keyfunc, jwt.WithValidMethods([]string{"HS256", "RS256"}))
 
     if err != nil {
         return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -50,4 +86,4 @@ func (tv *TokenValidator) ValidateToken(tokenString string) (*UserClaims, error)
     }
 
     return claims, nil
-}
\ No newline at end of file
+}
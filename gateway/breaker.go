@@ -0,0 +1,145 @@
+package main
+
+import (
+    "errors"
+    "log"
+    "sync"
+    "time"
+)
+
+// ErrServiceUnavailable is returned when a downstream circuit breaker is open
+var ErrServiceUnavailable = errors.New("service unavailable")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+    BreakerClosed BreakerState = iota
+    BreakerOpen
+    BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+    switch s {
+    case BreakerOpen:
+        return "open"
+    case BreakerHalfOpen:
+        return "half-open"
+    default:
+        return "closed"
+    }
+}
+
+// BreakerConfig holds circuit breaker thresholds
+type BreakerConfig struct {
+    FailureThreshold int           // consecutive failures before opening
+    OpenTimeout      time.Duration // how long to stay open before probing
+}
+
+// DefaultBreakerConfig returns the breaker settings used by the gateway
+func DefaultBreakerConfig() BreakerConfig {
+    return BreakerConfig{
+        FailureThreshold: 5,
+        OpenTimeout:      30 * time.Second,
+    }
+}
+
+// CircuitBreaker stops calling a downstream service after repeated failures,
+// failing fast instead of letting every request wait for a timeout.
+type CircuitBreaker struct {
+    name     string
+    config   BreakerConfig
+    mu       sync.Mutex
+    state    BreakerState
+    failures int
+    openedAt time.Time
+    probing  bool
+    now      func() time.Time
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+    return &CircuitBreaker{
+        name:   name,
+        config: config,
+        state:  BreakerClosed,
+        now:    time.Now,
+    }
+}
+
+// Name returns the downstream the breaker guards
+func (cb *CircuitBreaker) Name() string {
+    return cb.name
+}
+
+// State returns the current breaker state
+func (cb *CircuitBreaker) State() BreakerState {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    return cb.state
+}
+
+// Allow reports whether a request may be sent.
+// After OpenTimeout a single probe request is let through (half-open).
+func (cb *CircuitBreaker) Allow() bool {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+
+    switch cb.state {
+    case BreakerOpen:
+        if cb.now().Sub(cb.openedAt) < cb.config.OpenTimeout {
+            return false
+        }
+        cb.state = BreakerHalfOpen
+        cb.probing = true
+        log.Printf("⚠️  Circuit breaker %s half-open, probing", cb.name)
+        return true
+    case BreakerHalfOpen:
+        if cb.probing {
+            return false
+        }
+        cb.probing = true
+        return true
+    default:
+        return true
+    }
+}
+
+// RecordSuccess closes the breaker and resets the failure count
+func (cb *CircuitBreaker) RecordSuccess() {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+
+    if cb.state != BreakerClosed {
+        log.Printf("✓ Circuit breaker %s closed", cb.name)
+    }
+    cb.state = BreakerClosed
+    cb.failures = 0
+    cb.probing = false
+}
+
+// Release ends a request that says nothing about the downstream's health, e.g. one its caller
+// cancelled: a half-open breaker lets the next probe through, state and failure count stay
+func (cb *CircuitBreaker) Release() {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+
+    cb.probing = false
+}
+
+// RecordFailure counts a failure and opens the breaker at the threshold
+func (cb *CircuitBreaker) RecordFailure() {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+
+    cb.failures++
+    cb.probing = false
+
+    if cb.state == BreakerHalfOpen || cb.failures >= cb.config.FailureThreshold {
+        if cb.state != BreakerOpen {
+            log.Printf("❌ Circuit breaker %s opened after %d failures", cb.name, cb.failures)
+        }
+        cb.state = BreakerOpen
+        cb.openedAt = cb.now()
+    }
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+)
+
+// Catalog response cache
+// Why: products and categories rarely change, but every catalog query went to the products
+// service. Responses of the catalog GETs are cached by endpoint and arguments, locally and
+// (optionally) in Redis. ProductCreated/Updated/Deleted events drop the affected entries
+// (see catalogevents.go); the TTL bounds staleness for changes that have no event, like stock.
+
+// Cache keys; the products list key carries its filter so each category is cached separately.
+// Category tree responses depend on both products and categories, so they live under the
+// products prefix and are dropped with either.
+const (
+    catalogKeyPrefix        = "gateway:catalog:"
+    catalogProductKey       = "product:"
+    catalogProductsKey      = "products:"
+    catalogCategoriesKey    = "categories"
+    catalogCategoryPagesKey = catalogCategoriesKey + ":page:"
+    catalogCategoryTreeKey  = catalogProductsKey + "tree:"
+)
+
+// CatalogCacheConfig controls the catalog response cache
+type CatalogCacheConfig struct {
+    Enabled     bool
+    TTL         time.Duration
+    MaxEntries  int    // local entries kept before evicting
+    RedisURL    string // shared tier; empty keeps the cache local
+    RabbitMQURL string // product events for invalidation; empty relies on the TTL alone
+}
+
+// SharedCatalogStore is the cross-instance tier of the catalog cache
+type SharedCatalogStore interface {
+    // Get returns the cached body and whether it was found
+    Get(ctx context.Context, key string) ([]byte, bool, error)
+    Set(ctx context.Context, key string, body []byte, ttl time.Duration) error
+    Delete(ctx context.Context, keys ...string) error
+    DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// cachedResponse is one local cache entry
+type cachedResponse struct {
+    body      []byte
+    expiresAt time.Time
+}
+
+// CatalogCache caches products service responses; a nil *CatalogCache caches nothing
+type CatalogCache struct {
+    config CatalogCacheConfig
+    shared SharedCatalogStore // nil when running without Redis
+    now    func() time.Time
+
+    mu      sync.Mutex
+    entries map[string]cachedResponse
+}
+
+// NewCatalogCache creates a catalog cache; shared may be nil
+func NewCatalogCache(config CatalogCacheConfig, shared SharedCatalogStore) *CatalogCache {
+    return &CatalogCache{
+        config:  config,
+        shared:  shared,
+        now:     time.Now,
+        entries: make(map[string]cachedResponse),
+    }
+}
+
+// Get returns a cached response body, checking the shared store on a local miss
+func (cc *CatalogCache) Get(ctx context.Context, key string) ([]byte, bool) {
+    if cc == nil {
+        return nil, false
+    }
+    now := cc.now()
+
+    cc.mu.Lock()
+    if entry, ok := cc.entries[key]; ok {
+        if now.Before(entry.expiresAt) {
+            cc.mu.Unlock()
+            return entry.body, true
+        }
+        delete(cc.entries, key)
+    }
+    cc.mu.Unlock()
+
+    if cc.shared == nil {
+        return nil, false
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    body, found, err := cc.shared.Get(ctx, key)
+    if err != nil {
+        log.Printf("⚠️  Catalog cache lookup failed: %v", err)
+        return nil, false
+    }
+    if found {
+        cc.putLocal(key, body)
+    }
+    return body, found
+}
+
+// Put caches a response body
+func (cc *CatalogCache) Put(ctx context.Context, key string, body []byte) {
+    if cc == nil {
+        return
+    }
+    cc.putLocal(key, body)
+
+    if cc.shared == nil {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    if err := cc.shared.Set(ctx, key, body, cc.config.TTL); err != nil {
+        log.Printf("⚠️  Catalog cache store failed: %v", err)
+    }
+}
+
+// InvalidateProduct drops a product and every product list (it may appear in any of them).
+// productID is empty for a new product, which only affects the lists.
+func (cc *CatalogCache) InvalidateProduct(ctx context.Context, productID string) {
+    if cc == nil {
+        return
+    }
+    keys := []string{}
+    if productID != "" {
+        keys = append(keys, catalogProductKey+productID)
+    }
+
+    cc.mu.Lock()
+    for _, key := range keys {
+        delete(cc.entries, key)
+    }
+    cc.deletePrefixLocked(catalogProductsKey)
+    cc.mu.Unlock()
+
+    cc.invalidateShared(ctx, func(ctx context.Context) error {
+        if len(keys) > 0 {
+            if err := cc.shared.Delete(ctx, keys...); err != nil {
+                return err
+            }
+        }
+        return cc.shared.DeletePrefix(ctx, catalogProductsKey)
+    })
+}
+
+// InvalidateCategories drops the category list and its pages, and everything built from the category tree
+func (cc *CatalogCache) InvalidateCategories(ctx context.Context) {
+    if cc == nil {
+        return
+    }
+
+    cc.mu.Lock()
+    delete(cc.entries, catalogCategoriesKey)
+    cc.deletePrefixLocked(catalogCategoryPagesKey)
+    cc.deletePrefixLocked(catalogCategoryTreeKey)
+    cc.mu.Unlock()
+
+    cc.invalidateShared(ctx, func(ctx context.Context) error {
+        if err := cc.shared.Delete(ctx, catalogCategoriesKey); err != nil {
+            return err
+        }
+        if err := cc.shared.DeletePrefix(ctx, catalogCategoryPagesKey); err != nil {
+            return err
+        }
+        return cc.shared.DeletePrefix(ctx, catalogCategoryTreeKey)
+    })
+}
+
+// Flush drops everything, e.g. after events may have been missed
+func (cc *CatalogCache) Flush(ctx context.Context) {
+    if cc == nil {
+        return
+    }
+
+    cc.mu.Lock()
+    cc.entries = make(map[string]cachedResponse)
+    cc.mu.Unlock()
+
+    cc.invalidateShared(ctx, func(ctx context.Context) error {
+        return cc.shared.DeletePrefix(ctx, "")
+    })
+}
+
+func (cc *CatalogCache) invalidateShared(ctx context.Context, invalidate func(ctx context.Context) error) {
+    if cc.shared == nil {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    // A failed delete leaves stale entries in Redis until the TTL; nothing else to do about it
+    if err := invalidate(ctx); err != nil {
+        log.Printf("⚠️  Catalog cache invalidation failed: %v", err)
+    }
+}
+
+func (cc *CatalogCache) putLocal(key string, body []byte) {
+    now := cc.now()
+
+    cc.mu.Lock()
+    defer cc.mu.Unlock()
+
+    if len(cc.entries) >= cc.config.MaxEntries {
+        cc.evictLocked(now)
+    }
+    cc.entries[key] = cachedResponse{body: body, expiresAt: now.Add(cc.config.TTL)}
+}
+
+// evictLocked drops expired entries, then arbitrary ones until there is room
+func (cc *CatalogCache) evictLocked(now time.Time) {
+    for key, entry := range cc.entries {
+        if !now.Before(entry.expiresAt) {
+            delete(cc.entries, key)
+        }
+    }
+    for key := range cc.entries {
+        if len(cc.entries) < cc.config.MaxEntries {
+            break
+        }
+        delete(cc.entries, key)
+    }
+}
+
+func (cc *CatalogCache) deletePrefixLocked(prefix string) {
+    for key := range cc.entries {
+        if strings.HasPrefix(key, prefix) {
+            delete(cc.entries, key)
+        }
+    }
+}
+
+// redisCatalogStore is the Redis-backed SharedCatalogStore
+type redisCatalogStore struct {
+    client *redis.Client
+}
+
+// NewRedisCatalogStore connects to Redis and returns a shared catalog store
+func NewRedisCatalogStore(redisURL string) (SharedCatalogStore, error) {
+    opts, err := redis.ParseURL(redisURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse redis url: %w", err)
+    }
+
+    client := redis.NewClient(opts)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, fmt.Errorf("failed to connect to redis: %w", err)
+    }
+
+    return &redisCatalogStore{client: client}, nil
+}
+
+func (rs *redisCatalogStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+    body, err := rs.client.Get(ctx, catalogKeyPrefix+key).Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, false, nil
+    }
+    if err != nil {
+        return nil, false, err
+    }
+    return body, true, nil
+}
+
+func (rs *redisCatalogStore) Set(ctx context.Context, key string, body []byte, ttl time.Duration) error {
+    return rs.client.Set(ctx, catalogKeyPrefix+key, body, ttl).Err()
+}
+
+func (rs *redisCatalogStore) Delete(ctx context.Context, keys ...string) error {
+    prefixed := make([]string, len(keys))
+    for i, key := range keys {
+        prefixed[i] = catalogKeyPrefix + key
+    }
+    return rs.client.Del(ctx, prefixed...).Err()
+}
+
+func (rs *redisCatalogStore) DeletePrefix(ctx context.Context, prefix string) error {
+    iter := rs.client.Scan(ctx, 0, catalogKeyPrefix+prefix+"*", 100).Iterator()
+    for iter.Next(ctx) {
+        if err := rs.client.Del(ctx, iter.Val()).Err(); err != nil {
+            return err
+        }
+    }
+    return iter.Err()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Catalog cache invalidation from product events.
+// Each gateway instance binds its own exclusive, auto-deleted queue to products.events, so
+// every instance hears every change. Delivery is best effort: while disconnected events are
+// lost, so the cache is flushed on every (re)connect.
+
+// catalogEventsExchange and the routing keys the products service publishes catalog changes on
+const catalogEventsExchange = "products.events"
+
+var catalogRoutingKeys = []string{"product.created", "product.updated", "product.deleted", "product.restored",
+    "category.merged", "category.products_assigned"}
+
+// catalogReconnectDelay is the wait between connection attempts to RabbitMQ (all gateway consumers)
+const catalogReconnectDelay = 5 * time.Second
+
+// catalogEvent is the part of a product event the cache needs
+type catalogEvent struct {
+    EventType     string `json:"event_type"`
+    AggregateID   string `json:"aggregate_id"` // product ID, or category ID for category events
+    AggregateType string `json:"aggregate_type"`
+}
+
+// ConsumeCatalogEvents invalidates cache entries on product events until ctx is done,
+// reconnecting to RabbitMQ whenever the connection drops
+func ConsumeCatalogEvents(ctx context.Context, rabbitURL string, cache *CatalogCache) {
+    consumeEvents(ctx, rabbitURL, eventSubscription{
+        name:        "Catalog",
+        exchange:    catalogEventsExchange,
+        routingKeys: catalogRoutingKeys,
+        onConnect: func() {
+            // Anything that changed while we were not listening is unknown
+            cache.Flush(ctx)
+            log.Println("✓ Catalog cache listening for product events")
+        },
+        handle: func(body []byte) {
+            handleCatalogEvent(ctx, cache, body)
+        },
+    })
+}
+
+// eventSubscription is one gateway consumer of a service exchange
+type eventSubscription struct {
+    name        string // for logs
+    exchange    string
+    routingKeys []string
+    onConnect   func() // called once the queue is bound, on every (re)connect
+    handle      func(body []byte)
+}
+
+// consumeEvents delivers a subscription's events to its handler until ctx is done,
+// reconnecting to RabbitMQ whenever the connection drops
+func consumeEvents(ctx context.Context, rabbitURL string, sub eventSubscription) {
+    for {
+        err := consumeEventsOnce(ctx, rabbitURL, sub)
+        if ctx.Err() != nil {
+            return
+        }
+        log.Printf("⚠️  %s event consumer disconnected, retrying in %s: %v", sub.name, catalogReconnectDelay, err)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-time.After(catalogReconnectDelay):
+        }
+    }
+}
+
+// consumeEventsOnce runs one connection's worth of consuming; it returns when the connection closes
+func consumeEventsOnce(ctx context.Context, rabbitURL string, sub eventSubscription) error {
+    conn, err := amqp.Dial(rabbitURL)
+    if err != nil {
+        return fmt.Errorf("failed to connect to rabbitmq: %w", err)
+    }
+    defer conn.Close()
+
+    ch, err := conn.Channel()
+    if err != nil {
+        return fmt.Errorf("failed to open channel: %w", err)
+    }
+    defer ch.Close()
+
+    // Same declaration as the services' topology, so binding works whichever starts first
+    if err := ch.ExchangeDeclare(sub.exchange, "topic", true, false, false, false, nil); err != nil {
+        return fmt.Errorf("failed to declare exchange: %w", err)
+    }
+
+    queue, err := ch.QueueDeclare("", false, true, true, false, nil)
+    if err != nil {
+        return fmt.Errorf("failed to declare queue: %w", err)
+    }
+    for _, key := range sub.routingKeys {
+        if err := ch.QueueBind(queue.Name, key, sub.exchange, false, nil); err != nil {
+            return fmt.Errorf("failed to bind %s: %w", key, err)
+        }
+    }
+
+    deliveries, err := ch.Consume(queue.Name, "", true, true, false, false, nil)
+    if err != nil {
+        return fmt.Errorf("failed to consume: %w", err)
+    }
+
+    if sub.onConnect != nil {
+        sub.onConnect()
+    }
+
+    closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+    for {
+        select {
+        case <-ctx.Done():
+            return nil
+        case amqpErr := <-closed:
+            return fmt.Errorf("connection closed: %v", amqpErr)
+        case delivery, ok := <-deliveries:
+            if !ok {
+                return fmt.Errorf("delivery channel closed")
+            }
+            sub.handle(delivery.Body)
+        }
+    }
+}
+
+func handleCatalogEvent(ctx context.Context, cache *CatalogCache, body []byte) {
+    var event catalogEvent
+    if err := json.Unmarshal(body, &event); err != nil || event.AggregateID == "" {
+        // Can't tell which product changed; drop everything rather than serve it stale
+        log.Printf("⚠️  Unreadable product event, flushing catalog cache: %v", err)
+        cache.Flush(ctx)
+        return
+    }
+
+    if event.AggregateType == "category" {
+        // A merge or bulk move changes the tree, many products and their lists; they are rare
+        cache.Flush(ctx)
+        log.Printf("✓ Catalog cache flushed for category %s (%s)", event.AggregateID, event.EventType)
+        return
+    }
+
+    cache.InvalidateProduct(ctx, event.AggregateID)
+    log.Printf("✓ Catalog cache invalidated for product %s (%s)", event.AggregateID, event.EventType)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+    "context"
+    "sync"
+)
+
+// Nested catalog fields
+// Why: `categories { products { ... } }` and `products { category { ... } }` resolve one field per
+// parent. Instead of a products service call per category (or per product), the first nested field
+// loads the whole product list or category list once, and every other parent in the same request
+// is answered from it. Both lists also go through the catalog cache.
+
+// catalogLoaderContextKey holds the *catalogLoader for the current GraphQL request
+const catalogLoaderContextKey ContextKey = "catalog_loader"
+
+// catalogLoader memoizes the catalog lists for one GraphQL request
+type catalogLoader struct {
+    mu                 sync.Mutex
+    productsByCategory map[int64][]map[string]interface{}
+    categoriesByID     map[int64]map[string]interface{}
+    childrenByParent   map[int64][]map[string]interface{}
+}
+
+// withCatalogLoader returns ctx carrying a fresh per-request catalog loader
+func withCatalogLoader(ctx context.Context) context.Context {
+    return context.WithValue(ctx, catalogLoaderContextKey, &catalogLoader{})
+}
+
+// catalogLoaderFrom returns the request's loader, or a throwaway one outside ExecuteQuery
+func catalogLoaderFrom(ctx context.Context) *catalogLoader {
+    if loader, ok := ctx.Value(catalogLoaderContextKey).(*catalogLoader); ok {
+        return loader
+    }
+    return &catalogLoader{}
+}
+
+// ProductsInCategory returns the products of a category, loading all products on first use
+func (cl *catalogLoader) ProductsInCategory(ctx context.Context, ps *ProductService, categoryID int64) ([]map[string]interface{}, error) {
+    cl.mu.Lock()
+    defer cl.mu.Unlock()
+
+    if err := cl.loadProductsLocked(ctx, ps); err != nil {
+        return nil, err
+    }
+
+    products := cl.productsByCategory[categoryID]
+    if products == nil {
+        return []map[string]interface{}{}, nil
+    }
+    return products, nil
+}
+
+// ProductsInCategoryTree returns the products of a category and all its subcategories,
+// from the same product and category lists
+func (cl *catalogLoader) ProductsInCategoryTree(ctx context.Context, ps *ProductService, categoryID int64) ([]map[string]interface{}, error) {
+    cl.mu.Lock()
+    defer cl.mu.Unlock()
+
+    if err := cl.loadProductsLocked(ctx, ps); err != nil {
+        return nil, err
+    }
+    if err := cl.loadCategoriesLocked(ctx, ps); err != nil {
+        return nil, err
+    }
+
+    products := []map[string]interface{}{}
+    seen := map[int64]bool{}
+    queue := []int64{categoryID}
+    for len(queue) > 0 {
+        id := queue[0]
+        queue = queue[1:]
+        if seen[id] {
+            continue // parent_id cycle
+        }
+        seen[id] = true
+
+        products = append(products, cl.productsByCategory[id]...)
+        for _, child := range cl.childrenByParent[id] {
+            if childID, ok := catalogID(child["id"]); ok {
+                queue = append(queue, childID)
+            }
+        }
+    }
+    return products, nil
+}
+
+// Category returns a category by ID, loading all categories on first use; nil if it doesn't exist
+func (cl *catalogLoader) Category(ctx context.Context, ps *ProductService, categoryID int64) (map[string]interface{}, error) {
+    cl.mu.Lock()
+    defer cl.mu.Unlock()
+
+    if err := cl.loadCategoriesLocked(ctx, ps); err != nil {
+        return nil, err
+    }
+
+    return cl.categoriesByID[categoryID], nil
+}
+
+// Children returns the direct subcategories of a category, loading all categories on first use
+func (cl *catalogLoader) Children(ctx context.Context, ps *ProductService, categoryID int64) ([]map[string]interface{}, error) {
+    cl.mu.Lock()
+    defer cl.mu.Unlock()
+
+    if err := cl.loadCategoriesLocked(ctx, ps); err != nil {
+        return nil, err
+    }
+
+    children := cl.childrenByParent[categoryID]
+    if children == nil {
+        return []map[string]interface{}{}, nil
+    }
+    return children, nil
+}
+
+func (cl *catalogLoader) loadProductsLocked(ctx context.Context, ps *ProductService) error {
+    if cl.productsByCategory != nil {
+        return nil
+    }
+
+    products, err := ps.GetProducts(ctx, nil)
+    if err != nil {
+        return err
+    }
+
+    byCategory := make(map[int64][]map[string]interface{})
+    for _, product := range products {
+        if id, ok := catalogID(product["category_id"]); ok {
+            byCategory[id] = append(byCategory[id], product)
+        }
+    }
+    cl.productsByCategory = byCategory
+    return nil
+}
+
+func (cl *catalogLoader) loadCategoriesLocked(ctx context.Context, ps *ProductService) error {
+    if cl.categoriesByID != nil {
+        return nil
+    }
+
+    categories, err := ps.GetCategories(ctx)
+    if err != nil {
+        return err
+    }
+
+    byID := make(map[int64]map[string]interface{}, len(categories))
+    byParent := make(map[int64][]map[string]interface{})
+    for _, category := range categories {
+        if id, ok := catalogID(category["id"]); ok {
+            byID[id] = category
+        }
+        if parentID, ok := catalogID(category["parent_id"]); ok {
+            byParent[parentID] = append(byParent[parentID], category)
+        }
+    }
+    cl.categoriesByID = byID
+    cl.childrenByParent = byParent
+    return nil
+}
+
+// catalogID reads an ID from decoded JSON (float64) or a resolver argument (int)
+func catalogID(value interface{}) (int64, bool) {
+    switch v := value.(type) {
+    case float64:
+        return int64(v), true
+    case int:
+        return int64(v), true
+    case int64:
+        return v, true
+    }
+    return 0, false
+}
+
+// findVariant returns the variant of a product payload with the given ID, or nil
+func findVariant(product map[string]interface{}, variantID int64) map[string]interface{} {
+    variants, _ := product["variants"].([]interface{})
+    for _, entry := range variants {
+        variant, _ := entry.(map[string]interface{})
+        if id, ok := catalogID(variant["id"]); ok && id == variantID {
+            return variant
+        }
+    }
+    return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+    "context"
+    "log"
+    "net/http"
+    "sort"
+    "sync/atomic"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Catalog cache warm-up
+// Why: after a deploy every instance starts with an empty catalog cache, and the first wave of
+// storefront traffic all misses at once and lands on the products service. The warm-up loads
+// the categories, the product lists and the top products through the cache before /ready
+// passes, so the instance takes traffic with a warm cache.
+
+// CatalogWarmupConfig controls the startup warm-up
+type CatalogWarmupConfig struct {
+    Enabled     bool
+    TopProducts int           // most-reviewed products cached individually
+    Timeout     time.Duration // the gateway becomes ready after this even if warm-up hasn't finished
+}
+
+// CatalogWarmup fills the catalog cache on startup and holds readiness until it is done
+type CatalogWarmup struct {
+    config   CatalogWarmupConfig
+    products *ProductService
+    done     atomic.Bool
+}
+
+// NewCatalogWarmup creates a catalog warm-up; without a cache there is nothing to warm,
+// so a disabled warm-up or a nil cache is ready immediately
+func NewCatalogWarmup(config CatalogWarmupConfig, products *ProductService) *CatalogWarmup {
+    cw := &CatalogWarmup{config: config, products: products}
+    if !config.Enabled || products.cache == nil {
+        cw.done.Store(true)
+    }
+    return cw
+}
+
+// Start runs the warm-up in the background unless it is already done
+func (cw *CatalogWarmup) Start(ctx context.Context) {
+    if cw.Ready() {
+        return
+    }
+    go cw.Run(ctx)
+}
+
+// Run warms the cache once and marks the warm-up done, whether or not it succeeded.
+// Whatever didn't load is fetched on first use, as without the warm-up.
+func (cw *CatalogWarmup) Run(ctx context.Context) {
+    defer cw.done.Store(true)
+
+    ctx, cancel := context.WithTimeout(ctx, cw.config.Timeout)
+    defer cancel()
+
+    start := time.Now()
+
+    categories, err := cw.products.GetCategories(ctx)
+    if err != nil {
+        log.Printf("⚠️  Catalog warm-up failed to load categories: %v", err)
+        return
+    }
+
+    products, err := cw.products.GetProducts(ctx, nil)
+    if err != nil {
+        log.Printf("⚠️  Catalog warm-up failed to load products: %v", err)
+        return
+    }
+
+    failed := 0
+    for _, category := range categories {
+        id, ok := catalogID(category["id"])
+        if !ok {
+            continue
+        }
+        if _, err := cw.products.GetProducts(ctx, &id); err != nil {
+            failed++
+        }
+    }
+
+    top := topProducts(products, cw.config.TopProducts)
+    for _, product := range top {
+        id, ok := catalogID(product["id"])
+        if !ok {
+            continue
+        }
+        if _, err := cw.products.GetProduct(ctx, id); err != nil {
+            failed++
+        }
+    }
+
+    if failed > 0 {
+        log.Printf("⚠️  Catalog warm-up failed to load %d catalog entries", failed)
+    }
+    log.Printf("✓ Catalog cache warmed: %d categories, %d products, top %d in %s",
+        len(categories), len(products), len(top), time.Since(start).Round(time.Millisecond))
+}
+
+// Ready reports whether the warm-up has finished (or has nothing to do)
+func (cw *CatalogWarmup) Ready() bool {
+    return cw.done.Load()
+}
+
+// readyHandler serves the readiness probe: 503 while the catalog cache is warming
+func (cw *CatalogWarmup) readyHandler(c *gin.Context) {
+    if !cw.Ready() {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"status": "warming_up"})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// topProducts returns the n most-reviewed products, keeping list order (newest first) for ties
+func topProducts(products []map[string]interface{}, n int) []map[string]interface{} {
+    ranked := make([]map[string]interface{}, len(products))
+    copy(ranked, products)
+    reviewCount := func(product map[string]interface{}) float64 {
+        count, _ := product["review_count"].(float64)
+        return count
+    }
+    sort.SliceStable(ranked, func(i, j int) bool {
+        return reviewCount(ranked[i]) > reviewCount(ranked[j])
+    })
+
+    if n >= 0 && n < len(ranked) {
+        ranked = ranked[:n]
+    }
+    return ranked
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+    "context"
+    "log"
+)
+
+// Checkout status.
+// checkout hands the client a correlation_id and the order is created asynchronously by the
+// orders saga. checkoutStatus(correlation_id) and Order.saga_state let clients follow it through
+// the gateway instead of polling the orders service. The saga's status is mapped to the
+// CheckoutPhase enum, so clients switch over a closed set; a status this gateway doesn't know
+// yet is UNKNOWN rather than an error.
+
+// CheckoutPhase values
+const (
+    PhasePending           = "PENDING"
+    PhaseOrderCreated      = "ORDER_CREATED"
+    PhaseCheckingInventory = "CHECKING_INVENTORY"
+    PhaseOrderPlaced       = "ORDER_PLACED"
+    PhaseCompleted         = "COMPLETED"
+    PhaseFailed            = "FAILED"
+    PhaseCancelled         = "CANCELLED"
+    PhaseUnknown           = "UNKNOWN"
+)
+
+// CheckoutOutcomeStatus values
+const (
+    OutcomePending   = "PENDING"
+    OutcomeSucceeded = "SUCCEEDED"
+    OutcomeFailed    = "FAILED"
+)
+
+// checkoutOutcomes maps the orders service checkout result outcomes
+var checkoutOutcomes = map[string]string{
+    "pending":   OutcomePending,
+    "succeeded": OutcomeSucceeded,
+    "failed":    OutcomeFailed,
+}
+
+// sagaPhases maps orders saga statuses to phases
+var sagaPhases = map[string]string{
+    "pending":            PhasePending,
+    "order_created":      PhaseOrderCreated,
+    "checking_inventory": PhaseCheckingInventory,
+    "order_placed":       PhaseOrderPlaced,
+    "completed":          PhaseCompleted,
+    "failed":             PhaseFailed,
+    "cancelled":          PhaseCancelled,
+}
+
+// checkoutPhase returns the phase of a saga status
+func checkoutPhase(status string) string {
+    if phase, ok := sagaPhases[status]; ok {
+        return phase
+    }
+    return PhaseUnknown
+}
+
+// checkoutStatusFromSaga builds a CheckoutStatus from an orders service saga state.
+// Why: the saga's payload and compensation log are internal; only what a client polls on is kept.
+func checkoutStatusFromSaga(saga map[string]interface{}) map[string]interface{} {
+    status, _ := saga["status"].(string)
+    phase := checkoutPhase(status)
+
+    return map[string]interface{}{
+        "correlation_id": saga["correlation_id"],
+        "phase":          phase,
+        "status":         status,
+        "description":    saga["status_description"],
+        "done":           phase == PhaseCompleted || phase == PhaseFailed || phase == PhaseCancelled,
+        "order_id":       saga["order_id"],
+        "failure_reason": saga["failure_reason"],
+        "retry_count":    saga["retry_count"],
+        "updated_at":     saga["updated_at"],
+    }
+}
+
+// fetchCheckoutStatus loads the checkout status of a saga for userID. A saga the orders service
+// hasn't started yet, or one of another user, is nil.
+func fetchCheckoutStatus(ctx context.Context, rc *ResolverContext, userID interface{}, correlationID string) (map[string]interface{}, error) {
+    saga, err := rc.OrderService.GetSagaState(ctx, correlationID)
+    if isNotFound(err) {
+        return nil, nil
+    }
+    if err != nil {
+        log.Printf("❌ Error fetching saga %s: %v", correlationID, err)
+        return nil, err
+    }
+
+    // Other users' checkouts look the same as missing ones
+    if saga["user_id"] != userID {
+        return nil, nil
+    }
+    return checkoutStatusFromSaga(saga), nil
+}
+
+// checkoutOutcomeFromResult builds a CheckoutOutcome from an orders service checkout result;
+// an outcome this gateway doesn't know is still PENDING, since neither terminal one was reported
+func checkoutOutcomeFromResult(result map[string]interface{}) map[string]interface{} {
+    raw, _ := result["outcome"].(string)
+    outcome, ok := checkoutOutcomes[raw]
+    if !ok {
+        outcome = OutcomePending
+    }
+
+    return map[string]interface{}{
+        "outcome":     outcome,
+        "order_id":    result["order_id"],
+        "order_ids":   result["order_ids"],
+        "checkout_id": result["checkout_id"],
+        "reason":      result["reason"],
+    }
+}
+
+// fetchCheckoutOutcome loads the outcome of a checkout the caller was handed the correlation ID
+// of. A saga the orders service hasn't started yet is PENDING: the checkout was accepted.
+func fetchCheckoutOutcome(ctx context.Context, rc *ResolverContext, correlationID string) (map[string]interface{}, error) {
+    result, err := rc.OrderService.GetCheckoutResult(ctx, correlationID)
+    if isNotFound(err) {
+        return map[string]interface{}{"outcome": OutcomePending}, nil
+    }
+    if err != nil {
+        log.Printf("❌ Error fetching checkout result %s: %v", correlationID, err)
+        return nil, err
+    }
+    return checkoutOutcomeFromResult(result), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestCheckoutStatusFromSaga(t *testing.T) {
+    tests := []struct {
+        status string
+        phase  string
+        done   bool
+    }{
+        {"pending", PhasePending, false},
+        {"checking_inventory", PhaseCheckingInventory, false},
+        {"order_placed", PhaseOrderPlaced, false},
+        {"completed", PhaseCompleted, true},
+        {"failed", PhaseFailed, true},
+        {"cancelled", PhaseCancelled, true},
+        {"compensating", PhaseUnknown, false},
+    }
+
+    for _, tt := range tests {
+        saga := map[string]interface{}{
+            "correlation_id":     "cid-1",
+            "status":             tt.status,
+            "order_id":           float64(42),
+            "status_description": "described " + tt.status,
+            "payload":            map[string]interface{}{"total": 10.0},
+        }
+
+        status := checkoutStatusFromSaga(saga)
+        if status["phase"] != tt.phase || status["done"] != tt.done {
+            t.Errorf("status %q: phase %v done %v, want %s %v", tt.status, status["phase"], status["done"], tt.phase, tt.done)
+        }
+        if status["status"] != tt.status || status["order_id"] != float64(42) || status["description"] != "described "+tt.status {
+            t.Errorf("status %q: got %v", tt.status, status)
+        }
+        if _, ok := status["payload"]; ok {
+            t.Errorf("status %q: the saga payload should not be exposed", tt.status)
+        }
+    }
+}
+
+func TestCheckoutOutcomeFromResult(t *testing.T) {
+    succeeded := checkoutOutcomeFromResult(map[string]interface{}{
+        "outcome": "succeeded", "order_id": float64(11), "order_ids": []interface{}{float64(11), float64(12)}, "user_id": "u1",
+    })
+    if succeeded["outcome"] != OutcomeSucceeded || succeeded["order_id"] != float64(11) {
+        t.Errorf("succeeded = %v", succeeded)
+    }
+    if _, ok := succeeded["user_id"]; ok {
+        t.Error("the owner should not be exposed")
+    }
+
+    failed := checkoutOutcomeFromResult(map[string]interface{}{"outcome": "failed", "reason": "out of stock"})
+    if failed["outcome"] != OutcomeFailed || failed["reason"] != "out of stock" {
+        t.Errorf("failed = %v", failed)
+    }
+
+    if unknown := checkoutOutcomeFromResult(map[string]interface{}{"outcome": "refunded"}); unknown["outcome"] != OutcomePending {
+        t.Errorf("unknown outcome = %v, want PENDING", unknown)
+    }
+}
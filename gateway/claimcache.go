@@ -0,0 +1,343 @@
+package main
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+)
+
+// Validated-claims cache
+// Why: parsing and verifying the JWT on every request is the gateway's main CPU cost under load.
+// Claims are cached by token hash for a short TTL, locally and (optionally) in Redis so all
+// gateway instances share them. Revocations go to a revocation list and are broadcast so a
+// logout takes effect on every instance immediately, not after the TTL.
+
+// ErrTokenRevoked is returned for tokens on the revocation list
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Redis keys and channel used by the shared tier
+const (
+    claimKeyPrefix     = "gateway:claims:"
+    revokedKeyPrefix   = "gateway:revoked:"
+    revocationChannel  = "gateway:revocations"
+    sharedStoreTimeout = 200 * time.Millisecond
+)
+
+// maxRevocationAge bounds revocations of tokens without an exp claim
+const maxRevocationAge = 24 * time.Hour
+
+// ClaimCacheConfig controls the validated-claims cache
+type ClaimCacheConfig struct {
+    Enabled    bool
+    TTL        time.Duration // how long validated claims are trusted without re-parsing
+    MaxEntries int           // local entries kept before evicting
+    RedisURL   string        // shared tier; empty keeps the cache and revocations local
+}
+
+// tokenKey hashes a raw token so tokens are never stored or logged as-is
+func tokenKey(tokenString string) string {
+    sum := sha256.Sum256([]byte(tokenString))
+    return hex.EncodeToString(sum[:])
+}
+
+// SharedClaimStore is the cross-instance tier of the claim cache
+type SharedClaimStore interface {
+    // Lookup returns cached claims (nil on miss) and whether the key is revoked
+    Lookup(ctx context.Context, key string) (*UserClaims, bool, error)
+    Store(ctx context.Context, key string, claims *UserClaims, ttl time.Duration) error
+    Revoke(ctx context.Context, key string, ttl time.Duration) error
+    // Revocations calls onRevoke for revocations made by any instance until ctx is done
+    Revocations(ctx context.Context, onRevoke func(key string))
+}
+
+// cachedClaims is one local cache entry
+type cachedClaims struct {
+    claims    *UserClaims
+    expiresAt time.Time
+}
+
+// ClaimCache caches validated claims in memory, backed by an optional shared store
+type ClaimCache struct {
+    config ClaimCacheConfig
+    shared SharedClaimStore // nil when running without Redis
+    now    func() time.Time
+
+    mu      sync.Mutex
+    entries map[string]cachedClaims
+    revoked map[string]time.Time // key -> rejected until
+}
+
+// NewClaimCache creates a claim cache; shared may be nil
+func NewClaimCache(config ClaimCacheConfig, shared SharedClaimStore) *ClaimCache {
+    return &ClaimCache{
+        config:  config,
+        shared:  shared,
+        now:     time.Now,
+        entries: make(map[string]cachedClaims),
+        revoked: make(map[string]time.Time),
+    }
+}
+
+// Start listens for revocations from other instances until ctx is done
+func (cc *ClaimCache) Start(ctx context.Context) {
+    if cc.shared == nil {
+        return
+    }
+    go cc.shared.Revocations(ctx, func(key string) {
+        // The revocation list in Redis covers later lookups; locally we only need
+        // to outlive entries that may still be cached or in flight
+        cc.revokeLocal(key, cc.now().Add(cc.config.TTL))
+    })
+}
+
+// Get returns cached claims for key. It returns ErrTokenRevoked for revoked tokens
+// and (nil, nil) on a miss.
+func (cc *ClaimCache) Get(ctx context.Context, key string) (*UserClaims, error) {
+    now := cc.now()
+
+    cc.mu.Lock()
+    if until, ok := cc.revoked[key]; ok {
+        if now.Before(until) {
+            cc.mu.Unlock()
+            return nil, ErrTokenRevoked
+        }
+        delete(cc.revoked, key)
+    }
+    if entry, ok := cc.entries[key]; ok {
+        if now.Before(entry.expiresAt) {
+            cc.mu.Unlock()
+            return entry.claims, nil
+        }
+        delete(cc.entries, key)
+    }
+    cc.mu.Unlock()
+
+    if cc.shared == nil {
+        return nil, nil
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    claims, revoked, err := cc.shared.Lookup(ctx, key)
+    if err != nil {
+        // Redis down: fall back to validating locally; local revocations still apply
+        log.Printf("⚠️  Claim cache lookup failed: %v", err)
+        return nil, nil
+    }
+    if revoked {
+        cc.revokeLocal(key, now.Add(cc.config.TTL))
+        return nil, ErrTokenRevoked
+    }
+    if claims != nil {
+        cc.putLocal(key, claims)
+    }
+    return claims, nil
+}
+
+// Put caches claims that were just validated
+func (cc *ClaimCache) Put(ctx context.Context, key string, claims *UserClaims) {
+    ttl := cc.ttlFor(claims)
+    if ttl <= 0 {
+        return
+    }
+    cc.putLocal(key, claims)
+
+    if cc.shared == nil {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    if err := cc.shared.Store(ctx, key, claims, ttl); err != nil {
+        log.Printf("⚠️  Claim cache store failed: %v", err)
+    }
+}
+
+// Revoke puts the token on the revocation list until it expires and broadcasts it
+func (cc *ClaimCache) Revoke(ctx context.Context, key string, claims *UserClaims) error {
+    until := expiryOf(claims)
+    if until.IsZero() {
+        until = cc.now().Add(maxRevocationAge)
+    }
+    cc.revokeLocal(key, until)
+
+    if cc.shared == nil {
+        return nil
+    }
+
+    ttl := until.Sub(cc.now())
+    if ttl <= 0 {
+        return nil
+    }
+    if err := cc.shared.Revoke(ctx, key, ttl); err != nil {
+        return fmt.Errorf("failed to revoke token: %w", err)
+    }
+    return nil
+}
+
+// ttlFor bounds the cache TTL by the token's own expiry
+func (cc *ClaimCache) ttlFor(claims *UserClaims) time.Duration {
+    ttl := cc.config.TTL
+    if exp := expiryOf(claims); !exp.IsZero() {
+        if remaining := exp.Sub(cc.now()); remaining < ttl {
+            ttl = remaining
+        }
+    }
+    return ttl
+}
+
+func (cc *ClaimCache) putLocal(key string, claims *UserClaims) {
+    ttl := cc.ttlFor(claims)
+    if ttl <= 0 {
+        return
+    }
+    now := cc.now()
+
+    cc.mu.Lock()
+    defer cc.mu.Unlock()
+
+    if len(cc.entries) >= cc.config.MaxEntries {
+        cc.evictLocked(now)
+    }
+    cc.entries[key] = cachedClaims{claims: claims, expiresAt: now.Add(ttl)}
+}
+
+// evictLocked drops expired entries, then arbitrary ones until there is room
+func (cc *ClaimCache) evictLocked(now time.Time) {
+    for key, entry := range cc.entries {
+        if !now.Before(entry.expiresAt) {
+            delete(cc.entries, key)
+        }
+    }
+    for key := range cc.entries {
+        if len(cc.entries) < cc.config.MaxEntries {
+            break
+        }
+        delete(cc.entries, key)
+    }
+}
+
+// revokeLocal drops the cached entry and rejects the key until the given time
+func (cc *ClaimCache) revokeLocal(key string, until time.Time) {
+    now := cc.now()
+
+    cc.mu.Lock()
+    defer cc.mu.Unlock()
+
+    for k, t := range cc.revoked {
+        if !now.Before(t) {
+            delete(cc.revoked, k)
+        }
+    }
+
+    delete(cc.entries, key)
+    if until.After(cc.revoked[key]) {
+        cc.revoked[key] = until
+    }
+}
+
+func expiryOf(claims *UserClaims) time.Time {
+    if claims == nil || claims.ExpiresAt == nil {
+        return time.Time{}
+    }
+    return claims.ExpiresAt.Time
+}
+
+// redisClaimStore is the Redis-backed SharedClaimStore
+type redisClaimStore struct {
+    client *redis.Client
+}
+
+// NewRedisClaimStore connects to Redis and returns a shared claim store
+func NewRedisClaimStore(redisURL string) (SharedClaimStore, error) {
+    opts, err := redis.ParseURL(redisURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse redis url: %w", err)
+    }
+
+    client := redis.NewClient(opts)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, fmt.Errorf("failed to connect to redis: %w", err)
+    }
+
+    return &redisClaimStore{client: client}, nil
+}
+
+func (rs *redisClaimStore) Lookup(ctx context.Context, key string) (*UserClaims, bool, error) {
+    pipe := rs.client.Pipeline()
+    revokedCmd := pipe.Exists(ctx, revokedKeyPrefix+key)
+    claimsCmd := pipe.Get(ctx, claimKeyPrefix+key)
+    if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+        return nil, false, err
+    }
+
+    if revokedCmd.Val() > 0 {
+        return nil, true, nil
+    }
+
+    data, err := claimsCmd.Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, false, nil
+    }
+    if err != nil {
+        return nil, false, err
+    }
+
+    var claims UserClaims
+    if err := json.Unmarshal(data, &claims); err != nil {
+        return nil, false, fmt.Errorf("failed to decode cached claims: %w", err)
+    }
+    return &claims, false, nil
+}
+
+func (rs *redisClaimStore) Store(ctx context.Context, key string, claims *UserClaims, ttl time.Duration) error {
+    data, err := json.Marshal(claims)
+    if err != nil {
+        return fmt.Errorf("failed to encode claims: %w", err)
+    }
+    return rs.client.Set(ctx, claimKeyPrefix+key, data, ttl).Err()
+}
+
+func (rs *redisClaimStore) Revoke(ctx context.Context, key string, ttl time.Duration) error {
+    pipe := rs.client.TxPipeline()
+    pipe.Set(ctx, revokedKeyPrefix+key, 1, ttl)
+    pipe.Del(ctx, claimKeyPrefix+key)
+    pipe.Publish(ctx, revocationChannel, key)
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+func (rs *redisClaimStore) Revocations(ctx context.Context, onRevoke func(key string)) {
+    pubsub := rs.client.Subscribe(ctx, revocationChannel)
+    defer pubsub.Close()
+
+    // The channel reconnects and resubscribes on its own after Redis restarts
+    messages := pubsub.Channel()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case msg, ok := <-messages:
+            if !ok {
+                return
+            }
+            onRevoke(strings.TrimSpace(msg.Payload))
+        }
+    }
+}
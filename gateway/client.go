@@ -4,65 +4,230 @@ import (
     "bytes"
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "io"
+    "math/rand"
     "net/http"
+    "net/url"
+    "sync"
     "time"
 )
 
 // HTTPClient wraps HTTP operations for calling downstream services
 type HTTPClient struct {
     client *http.Client
+    downstream DownstreamConfig
+    retry RetryConfig
+    breakerConfig BreakerConfig
+    mu sync.Mutex
+    breakers map[string]*CircuitBreaker // keyed by downstream host
 }
 
-// NewHTTPClient creates a new HTTP client
-func NewHTTPClient() *HTTPClient {
+// ServiceError is a non-2xx response from a downstream service.
+// Code and Message come from the services' ErrorResponse body when present.
+type ServiceError struct {
+    StatusCode int
+    Code       string
+    Message    string
+    Body       string
+}
+
+func newServiceError(status int, body []byte) *ServiceError {
+    se := &ServiceError{StatusCode: status, Body: string(body)}
+
+    var errResp struct {
+        Error   string `json:"error"`
+        Message string `json:"message"`
+    }
+    if err := json.Unmarshal(body, &errResp); err == nil {
+        se.Code = errResp.Error
+        se.Message = errResp.Message
+    }
+    return se
+}
+
+func (e *ServiceError) Error() string {
+    return fmt.Sprintf("service returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// RetryConfig controls retries of idempotent (GET) requests
+type RetryConfig struct {
+    MaxRetries int
+    BaseDelay time.Duration
+    MaxDelay time.Duration
+}
+
+// DefaultRetryConfig returns the retry policy used by the gateway
+func DefaultRetryConfig() RetryConfig {
+    return RetryConfig{
+        MaxRetries: 2,
+        BaseDelay: 100 * time.Millisecond,
+        MaxDelay: 1 * time.Second,
+    }
+}
+
+// NewHTTPClient creates a new HTTP client; each call is bounded by its service's timeout
+func NewHTTPClient(downstream DownstreamConfig) *HTTPClient {
     return &HTTPClient{
-        client: &http.Client{
-            Timeout: 10 * time.Second,
-        },
+        client: &http.Client{},
+        downstream: downstream,
+        retry: DefaultRetryConfig(),
+        breakerConfig: DefaultBreakerConfig(),
+        breakers: make(map[string]*CircuitBreaker),
+    }
+}
+
+// breakerFor returns the circuit breaker for the downstream service behind rawURL
+func (hc *HTTPClient) breakerFor(rawURL string) *CircuitBreaker {
+    key := rawURL
+    if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+        key = parsed.Host
+    }
+
+    hc.mu.Lock()
+    defer hc.mu.Unlock()
+
+    cb, ok := hc.breakers[key]
+    if !ok {
+        cb = NewCircuitBreaker(key, hc.breakerConfig)
+        hc.breakers[key] = cb
     }
+    return cb
 }
 
-// Request makes HTTP request to downstream service
+// Request makes HTTP request to downstream service.
+// Calls go through a per-service circuit breaker; GETs are retried with jitter.
 func (hc *HTTPClient) Request(ctx context.Context, method, url string, headers map[string]string, body interface{}) ([]byte, error) {
-    var bodyReader io.Reader
+    var bodyBytes []byte
 
     if body != nil {
-        bodyBytes, err := json.Marshal(body)
+        var err error
+        bodyBytes, err = json.Marshal(body)
         if err != nil {
             return nil, fmt.Errorf("failed to marshal body: %w", err)
         }
+    }
+
+    breaker := hc.breakerFor(url)
+    attempts := 1
+    if method == http.MethodGet {
+        attempts += hc.retry.MaxRetries
+    }
+
+    var lastErr error
+    for attempt := 0; attempt < attempts; attempt++ {
+        if attempt > 0 {
+            if err := sleepWithJitter(ctx, hc.retry, attempt); err != nil {
+                return nil, downstreamError(lastErr)
+            }
+        }
+
+        if !breaker.Allow() {
+            return nil, Unavailable("downstream service unavailable", fmt.Errorf("%w: %s", ErrServiceUnavailable, breaker.Name()))
+        }
+
+        respBody, retryable, err := hc.do(ctx, method, url, headers, bodyBytes)
+        if err == nil {
+            breaker.RecordSuccess()
+            return respBody, nil
+        }
+
+        if !retryable {
+            // A 4xx is an answer, so the service is up; a cancelled caller or a request that
+            // couldn't be built says nothing about it either way
+            var se *ServiceError
+            if errors.As(err, &se) {
+                breaker.RecordSuccess()
+                return nil, errorFromStatus(se)
+            }
+            breaker.Release()
+            return nil, err
+        }
+        breaker.RecordFailure()
+        lastErr = err
+    }
+
+    return nil, downstreamError(lastErr)
+}
+
+// downstreamError maps a failure that exhausted retries (5xx or network error) to the gateway error model
+func downstreamError(err error) error {
+    var se *ServiceError
+    if errors.As(err, &se) {
+        return errorFromStatus(se)
+    }
+    return Unavailable("downstream service unavailable", err)
+}
+
+// do performs a single HTTP round trip.
+// retryable reports whether the failure looks like a downstream outage (network error or 5xx).
+func (hc *HTTPClient) do(ctx context.Context, method, url string, headers map[string]string, bodyBytes []byte) ([]byte, bool, error) {
+    var bodyReader io.Reader
+    if bodyBytes != nil {
         bodyReader = bytes.NewReader(bodyBytes)
     }
 
-    req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+    // Why: the caller's deadline (the gateway request's budget) still applies if it is earlier
+    callCtx, cancel := context.WithTimeout(ctx, hc.downstream.timeoutFor(url))
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(callCtx, method, url, bodyReader)
     if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+        return nil, false, fmt.Errorf("failed to create request: %w", err)
     }
 
-    // Add headers
+    // Add headers; the caller's token goes on every call, unless headers names another
     req.Header.Set("Content-Type", "application/json")
+    if token, ok := ctx.Value(AuthTokenContextKey).(string); ok && token != "" {
+        req.Header.Set("Authorization", token)
+    }
     for k, v := range headers {
         req.Header.Set(k, v)
     }
+    if requestID := RequestIDFromContext(ctx); requestID != "" {
+        req.Header.Set(RequestIDHeader, requestID)
+    }
 
+    start := time.Now()
     resp, err := hc.client.Do(req)
     if err != nil {
-        return nil, fmt.Errorf("request failed: %w", err)
+        recordDownstream(ctx, method, url, 0, time.Since(start))
+        // Caller cancellation is not the downstream's fault
+        return nil, ctx.Err() == nil, fmt.Errorf("request failed: %w", err)
     }
     defer resp.Body.Close()
 
     respBody, err := io.ReadAll(resp.Body)
     if err != nil {
-        return nil, fmt.Errorf("failed to read response: %w", err)
+        return nil, ctx.Err() == nil, fmt.Errorf("failed to read response: %w", err)
     }
+    recordDownstream(ctx, method, url, resp.StatusCode, time.Since(start))
 
     if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-        return nil, fmt.Errorf("service returned status %d: %s", resp.StatusCode, string(respBody))
+        return nil, resp.StatusCode >= 500, newServiceError(resp.StatusCode, respBody)
+    }
+
+    return respBody, false, nil
+}
+
+// sleepWithJitter waits an exponential backoff with full jitter, or until ctx is done
+func sleepWithJitter(ctx context.Context, cfg RetryConfig, attempt int) error {
+    backoff := cfg.BaseDelay << (attempt - 1)
+    if backoff > cfg.MaxDelay || backoff <= 0 {
+        backoff = cfg.MaxDelay
     }
+    delay := time.Duration(rand.Int63n(int64(backoff) + 1))
+
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
 
-    return respBody, nil
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
 }
 
 // GET makes GET request
@@ -75,6 +240,11 @@ func (hc *HTTPClient) POST(ctx context.Context, url string, headers map[string]s
     return hc.Request(ctx, http.MethodPost, url, headers, body)
 }
 
+// PATCH makes PATCH request
+func (hc *HTTPClient) PATCH(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
+    return hc.Request(ctx, http.MethodPatch, url, headers, body)
+}
+
 // PUT makes PUT request
 func (hc *HTTPClient) PUT(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
     return hc.Request(ctx, http.MethodPut, url, headers, body)
@@ -0,0 +1,63 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// halfOpenBreaker opens hc's breaker for server and moves its clock past the open timeout, so
+// the next request is the half-open probe
+func halfOpenBreaker(hc *HTTPClient, server *httptest.Server) *CircuitBreaker {
+    breaker := hc.breakerFor(server.URL)
+    for i := 0; i < hc.breakerConfig.FailureThreshold; i++ {
+        breaker.RecordFailure()
+    }
+    opened := breaker.openedAt
+    breaker.now = func() time.Time { return opened.Add(hc.breakerConfig.OpenTimeout) }
+    return breaker
+}
+
+func TestCancelledProbeLeavesTheBreakerHalfOpen(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        <-r.Context().Done()
+    }))
+    defer server.Close()
+    hc := NewHTTPClient(DownstreamConfig{Timeout: 5 * time.Second})
+    breaker := halfOpenBreaker(hc, server)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if _, err := hc.POST(ctx, server.URL+"/orders", nil, nil); err == nil {
+        t.Fatal("a cancelled request succeeded")
+    }
+
+    if state := breaker.State(); state != BreakerHalfOpen {
+        t.Errorf("breaker is %s after a cancelled probe, want half-open", state)
+    }
+    if breaker.failures != hc.breakerConfig.FailureThreshold {
+        t.Errorf("failures = %d, want %d: a cancelled probe counts neither way", breaker.failures, hc.breakerConfig.FailureThreshold)
+    }
+    if !breaker.Allow() {
+        t.Error("the next probe isn't let through after a cancelled one")
+    }
+}
+
+func TestClientErrorProbeClosesTheBreaker(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNotFound)
+    }))
+    defer server.Close()
+    hc := NewHTTPClient(DownstreamConfig{Timeout: 5 * time.Second})
+    breaker := halfOpenBreaker(hc, server)
+
+    if _, err := hc.POST(context.Background(), server.URL+"/orders", nil, nil); err == nil {
+        t.Fatal("a 404 succeeded")
+    }
+
+    if state := breaker.State(); state != BreakerClosed {
+        t.Errorf("breaker is %s after a 404 probe, want closed", state)
+    }
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+)
+
+// Product comparison
+// compareProducts returns the products side by side as attribute rows: each row has one value
+// per product, in the order the IDs were given, so the storefront can render the table as is.
+
+// Products that can be compared at once
+const (
+    minCompareProducts = 2
+    maxCompareProducts = 4
+)
+
+// comparisonAttribute is one row of the comparison table
+type comparisonAttribute struct {
+    key   string
+    label string
+    value func(product map[string]interface{}) interface{}
+}
+
+// comparisonAttributes builds the rows; categoryName looks up a category by ID
+func comparisonAttributes(categoryName func(id int64) interface{}, withRatings bool) []comparisonAttribute {
+    attributes := []comparisonAttribute{
+        {"price", "Price", func(p map[string]interface{}) interface{} {
+            if price, ok := p["price"].(float64); ok {
+                return strconv.FormatFloat(price, 'f', 2, 64)
+            }
+            return nil
+        }},
+        {"category", "Category", func(p map[string]interface{}) interface{} {
+            if id, ok := catalogID(p["category_id"]); ok {
+                return categoryName(id)
+            }
+            return nil
+        }},
+        {"availability", "Availability", func(p map[string]interface{}) interface{} {
+            if stock, ok := catalogID(p["stock_quantity"]); ok && stock > 0 {
+                return "In stock"
+            }
+            return "Out of stock"
+        }},
+        {"sku", "SKU", func(p map[string]interface{}) interface{} {
+            return formatAttribute(p["sku"])
+        }},
+    }
+    if withRatings {
+        attributes = append(attributes,
+            comparisonAttribute{"average_rating", "Average rating", func(p map[string]interface{}) interface{} {
+                if rating, ok := p["average_rating"].(float64); ok {
+                    return strconv.FormatFloat(rating, 'f', 1, 64)
+                }
+                return nil
+            }},
+            comparisonAttribute{"review_count", "Reviews", func(p map[string]interface{}) interface{} {
+                return formatAttribute(p["review_count"])
+            }},
+        )
+    }
+    return attributes
+}
+
+// specAttributes adds a row per product attribute (spec) any of the products has, sorted by name
+func specAttributes(products []map[string]interface{}) []comparisonAttribute {
+    seen := map[string]bool{}
+    var names []string
+    for _, product := range products {
+        attributes, _ := product["attributes"].(map[string]interface{})
+        for name := range attributes {
+            if !seen[name] {
+                seen[name] = true
+                names = append(names, name)
+            }
+        }
+    }
+    sort.Strings(names)
+
+    rows := make([]comparisonAttribute, 0, len(names))
+    for _, name := range names {
+        name := name
+        rows = append(rows, comparisonAttribute{"attributes." + name, name, func(p map[string]interface{}) interface{} {
+            attributes, _ := p["attributes"].(map[string]interface{})
+            return formatAttribute(attributes[name])
+        }})
+    }
+    return rows
+}
+
+// buildComparison aligns the attributes of products (already in the requested order).
+// differs is false when every product has the same value, so the storefront can hide the row.
+func buildComparison(products []map[string]interface{}, attributes []comparisonAttribute) map[string]interface{} {
+    rows := make([]map[string]interface{}, 0, len(attributes))
+    for _, attribute := range attributes {
+        values := make([]interface{}, len(products))
+        differs := false
+        for i, product := range products {
+            values[i] = attribute.value(product)
+            if i > 0 && values[i] != values[0] {
+                differs = true
+            }
+        }
+        rows = append(rows, map[string]interface{}{
+            "key":     attribute.key,
+            "label":   attribute.label,
+            "values":  values,
+            "differs": differs,
+        })
+    }
+
+    return map[string]interface{}{
+        "products":   products,
+        "attributes": rows,
+    }
+}
+
+// compareProductIDs validates the requested IDs: 2 to 4 distinct products
+func compareProductIDs(args []interface{}) ([]int64, error) {
+    ids := make([]int64, 0, len(args))
+    seen := make(map[int64]bool, len(args))
+    for _, arg := range args {
+        id, ok := catalogID(arg)
+        if !ok {
+            continue
+        }
+        if seen[id] {
+            return nil, Validation(fmt.Sprintf("product %d is listed twice", id))
+        }
+        seen[id] = true
+        ids = append(ids, id)
+    }
+    if len(ids) < minCompareProducts || len(ids) > maxCompareProducts {
+        return nil, Validation(fmt.Sprintf("compare between %d and %d products", minCompareProducts, maxCompareProducts))
+    }
+    return ids, nil
+}
+
+// formatAttribute renders a decoded JSON value as a table cell; nil stays nil
+func formatAttribute(value interface{}) interface{} {
+    switch v := value.(type) {
+    case nil:
+        return nil
+    case string:
+        if v == "" {
+            return nil
+        }
+        return v
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    }
+    return fmt.Sprint(value)
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+    "bytes"
+    "compress/gzip"
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/andybalholm/brotli"
+    "github.com/gin-gonic/gin"
+)
+
+// Response compression and ETags
+// Why: catalog queries are the largest responses and JSON shrinks several times over when
+// compressed, and a client refreshing a page it already has downloaded it in full again.
+// Responses are compressed with brotli or gzip, whichever the client accepts, and GET /graphql
+// answers If-None-Match with an empty 304. Same HTTP_COMPRESSION_* and HTTP_ETAGS_ENABLED
+// variables as the services (shared/compression); the gateway can't import that package.
+
+// Content codings the gateway produces
+const (
+    encodingBrotli = "br"
+    encodingGzip   = "gzip"
+)
+
+// brotliQuality trades some ratio for speed; responses are compressed on every request
+const brotliQuality = 4
+
+// CompressionConfig holds the response compression and ETag settings
+type CompressionConfig struct {
+    Enabled  bool // compress responses the client accepts compressed
+    MinBytes int  // smaller responses are sent as they are
+    ETags    bool // tag GET /graphql responses and answer If-None-Match
+}
+
+// loadCompressionConfig reads the HTTP_COMPRESSION_* and HTTP_ETAGS_ENABLED variables
+func loadCompressionConfig() CompressionConfig {
+    config := CompressionConfig{
+        Enabled:  getEnvBool("HTTP_COMPRESSION_ENABLED", true),
+        MinBytes: getEnvInt("HTTP_COMPRESSION_MIN_BYTES", 1024),
+        ETags:    getEnvBool("HTTP_ETAGS_ENABLED", true),
+    }
+    if config.MinBytes < 0 {
+        log.Printf("⚠️  Invalid value for HTTP_COMPRESSION_MIN_BYTES, using default 1024")
+        config.MinBytes = 1024
+    }
+    return config
+}
+
+// compressionMiddleware compresses text-like responses of at least MinBytes when the request's
+// Accept-Encoding allows it, preferring brotli. Event streams are never compressed.
+func compressionMiddleware(config CompressionConfig) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !config.Enabled {
+            c.Next()
+            return
+        }
+
+        // The response depends on Accept-Encoding even when it isn't compressed this time
+        c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+        encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+        if encoding == "" || c.Request.Method == http.MethodHead {
+            c.Next()
+            return
+        }
+
+        w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minBytes: config.MinBytes}
+        c.Writer = w
+        defer w.close()
+
+        c.Next()
+    }
+}
+
+// negotiateEncoding picks brotli, then gzip, or "" from an Accept-Encoding header. A coding
+// with q=0 is refused; "*" stands for any coding not listed.
+func negotiateEncoding(acceptEncoding string) string {
+    q := map[string]float64{}
+    for _, part := range strings.Split(acceptEncoding, ",") {
+        name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+        name = strings.ToLower(strings.TrimSpace(name))
+        if name == "" {
+            continue
+        }
+        weight := 1.0
+        if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+            parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+            if err != nil {
+                continue
+            }
+            weight = parsed
+        }
+        q[name] = weight
+    }
+
+    best, bestQ := "", 0.0
+    for _, encoding := range []string{encodingBrotli, encodingGzip} {
+        weight, listed := q[encoding]
+        if !listed {
+            weight, listed = q["*"]
+        }
+        if listed && weight > bestQ {
+            best, bestQ = encoding, weight
+        }
+    }
+    return best
+}
+
+// compressibleType reports whether a response of contentType is worth compressing
+func compressibleType(contentType string) bool {
+    mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+    mediaType = strings.TrimSpace(mediaType)
+    switch {
+    case mediaType == "text/event-stream":
+        return false
+    case strings.HasPrefix(mediaType, "text/"),
+        strings.HasSuffix(mediaType, "+json"),
+        strings.HasSuffix(mediaType, "+xml"):
+        return true
+    }
+    switch mediaType {
+    case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+        return true
+    }
+    return false
+}
+
+// compressWriter holds back the first MinBytes of a response, then decides whether to
+// compress it; a response that ends sooner is sent as it is
+type compressWriter struct {
+    gin.ResponseWriter
+    encoding string
+    minBytes int
+
+    buf     []byte
+    decided bool
+    enc     io.WriteCloser // nil when the response is sent as it is
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+    if w.decided {
+        if w.enc != nil {
+            return w.enc.Write(data)
+        }
+        return w.ResponseWriter.Write(data)
+    }
+
+    w.buf = append(w.buf, data...)
+    if len(w.buf) >= w.minBytes {
+        if err := w.decide(true); err != nil {
+            return 0, err
+        }
+    }
+    return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+    return w.Write([]byte(s))
+}
+
+// Flush sends what was written so far; a response flushed before MinBytes isn't compressed
+func (w *compressWriter) Flush() {
+    if !w.decided {
+        if err := w.decide(false); err != nil {
+            return
+        }
+    }
+    if flusher, ok := w.enc.(interface{ Flush() error }); ok {
+        if err := flusher.Flush(); err != nil {
+            return
+        }
+    }
+    w.ResponseWriter.Flush()
+}
+
+// decide settles whether the response is compressed and writes what was held back
+func (w *compressWriter) decide(bigEnough bool) error {
+    w.decided = true
+    header := w.ResponseWriter.Header()
+
+    status := w.ResponseWriter.Status()
+    bodyAllowed := status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+    if bigEnough && bodyAllowed && header.Get("Content-Encoding") == "" && compressibleType(header.Get("Content-Type")) {
+        header.Set("Content-Encoding", w.encoding)
+        header.Del("Content-Length")
+        // The compressed body is a different representation of the same content
+        if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+            header.Set("ETag", "W/"+etag)
+        }
+
+        if w.encoding == encodingBrotli {
+            w.enc = brotli.NewWriterLevel(w.ResponseWriter, brotliQuality)
+        } else {
+            w.enc = gzip.NewWriter(w.ResponseWriter)
+        }
+    }
+
+    if len(w.buf) == 0 {
+        return nil
+    }
+    var err error
+    if w.enc != nil {
+        _, err = w.enc.Write(w.buf)
+    } else {
+        _, err = w.ResponseWriter.Write(w.buf)
+    }
+    w.buf = nil
+    return err
+}
+
+// close sends what's still held back as it is and finishes a compressed stream
+func (w *compressWriter) close() {
+    if !w.decided {
+        if err := w.decide(false); err != nil {
+            log.Printf("⚠️  Failed to write response: %v", err)
+            return
+        }
+    }
+    if w.enc != nil {
+        if err := w.enc.Close(); err != nil {
+            log.Printf("⚠️  Failed to finish %s response: %v", w.encoding, err)
+        }
+    }
+}
+
+// etagMiddleware tags a 200 response with a weak ETag hashed from its body and answers 304
+// Not Modified, without the body, when If-None-Match already has it. The query still runs;
+// what's saved is the transfer. Runs after compressionMiddleware, so the tag is taken before
+// compression.
+func etagMiddleware(config CompressionConfig) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !config.ETags || c.Request.Method != http.MethodGet {
+            c.Next()
+            return
+        }
+
+        original := c.Writer
+        w := &etagWriter{ResponseWriter: original}
+        c.Writer = w
+        c.Next()
+        c.Writer = original
+
+        // A handler that sent its headers itself can't be answered with a 304 any more
+        if !original.Written() && original.Status() == http.StatusOK && original.Header().Get("ETag") == "" {
+            etag := bodyETag(w.body.Bytes())
+            original.Header().Set("ETag", etag)
+            if etagMatches(c.GetHeader("If-None-Match"), etag) {
+                original.Header().Del("Content-Type")
+                original.Header().Del("Content-Length")
+                original.WriteHeader(http.StatusNotModified)
+                original.WriteHeaderNow()
+                return
+            }
+        }
+        if w.body.Len() > 0 {
+            _, _ = original.Write(w.body.Bytes())
+        }
+    }
+}
+
+// bodyETag returns the weak ETag of a response body
+func bodyETag(body []byte) string {
+    sum := sha256.Sum256(body)
+    return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly
+func etagMatches(ifNoneMatch, etag string) bool {
+    if ifNoneMatch == "" {
+        return false
+    }
+    want := strings.TrimPrefix(etag, "W/")
+    for _, candidate := range strings.Split(ifNoneMatch, ",") {
+        candidate = strings.TrimSpace(candidate)
+        if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+            return true
+        }
+    }
+    return false
+}
+
+// etagWriter keeps the body until the handler is done, so the tag covers all of it
+type etagWriter struct {
+    gin.ResponseWriter
+    body bytes.Buffer
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+    if w.ResponseWriter.Written() {
+        return w.ResponseWriter.Write(data)
+    }
+    return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+    return w.Write([]byte(s))
+}
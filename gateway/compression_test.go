@@ -0,0 +1,53 @@
+package main
+
+import (
+    "compress/gzip"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestCompression_GzipAndETag(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    config := CompressionConfig{Enabled: true, MinBytes: 1024, ETags: true}
+    router := gin.New()
+    router.Use(compressionMiddleware(config))
+    router.GET("/graphql", etagMiddleware(config), func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{"data": strings.Repeat("product ", 400)})
+    })
+    get := func(header map[string]string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+        for key, value := range header {
+            req.Header.Set(key, value)
+        }
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, req)
+        return rec
+    }
+
+    plain := get(nil)
+    gz := get(map[string]string{"Accept-Encoding": "gzip;q=1, br;q=0"})
+
+    if gz.Header().Get("Content-Encoding") != encodingGzip {
+        t.Fatalf("expected gzip, got %q", gz.Header().Get("Content-Encoding"))
+    }
+    reader, err := gzip.NewReader(gz.Body)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if body, _ := io.ReadAll(reader); string(body) != plain.Body.String() {
+        t.Fatal("gzip body doesn't decode to the response")
+    }
+
+    etag := plain.Header().Get("ETag")
+    if etag == "" || gz.Header().Get("ETag") != etag {
+        t.Fatalf("expected one ETag for both codings, got %q and %q", etag, gz.Header().Get("ETag"))
+    }
+    if again := get(map[string]string{"Accept-Encoding": "br", "If-None-Match": etag}); again.Code != http.StatusNotModified || again.Body.Len() != 0 {
+        t.Fatalf("expected an empty 304, got %d with %d bytes", again.Code, again.Body.Len())
+    }
+}
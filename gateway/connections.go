@@ -0,0 +1,137 @@
+package main
+
+import (
+    "github.com/graphql-go/graphql"
+)
+
+// Relay connections.
+// productsConnection, categoriesConnection, ordersConnection and productReviewsConnection
+// follow the Relay connection spec (edges { cursor node }, pageInfo) and page forward with
+// first/after, so infinite scrolling works the same on every long list. They are backed by
+// the services' keyset pages (GET ...?first=&after=, see shared/pagination): a page continues
+// after the last row seen, so rows added while scrolling don't shift or repeat it. Cursors are
+// opaque and only valid for the same field and arguments. The older list fields keep page/limit.
+
+// defaultConnectionPageSize is the page size when first is left out, as in the services
+const defaultConnectionPageSize = 20
+
+// newPageInfoType builds the Relay PageInfo type shared by every connection
+func newPageInfoType() *graphql.Object {
+    return graphql.NewObject(graphql.ObjectConfig{
+        Name: "PageInfo",
+        Fields: graphql.Fields{
+            "hasNextPage": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+            },
+            "hasPreviousPage": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "True when the page started after a cursor",
+            },
+            "startCursor": &graphql.Field{
+                Type: graphql.String,
+            },
+            "endCursor": &graphql.Field{
+                Type:        graphql.String,
+                Description: "Pass as after to get the next page",
+            },
+        },
+    })
+}
+
+// newConnectionType builds the <name>Connection type and its <name>Edge type for node.
+// extra fields sit next to edges and pageInfo.
+func newConnectionType(name string, node graphql.Output, pageInfo *graphql.Object, extra graphql.Fields) *graphql.Object {
+    edgeType := graphql.NewObject(graphql.ObjectConfig{
+        Name: name + "Edge",
+        Fields: graphql.Fields{
+            "cursor": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "node": &graphql.Field{
+                Type: graphql.NewNonNull(node),
+            },
+        },
+    })
+
+    fields := graphql.Fields{
+        "edges": &graphql.Field{
+            Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+        },
+        "pageInfo": &graphql.Field{
+            Type: graphql.NewNonNull(pageInfo),
+        },
+    }
+    for fieldName, field := range extra {
+        fields[fieldName] = field
+    }
+
+    return graphql.NewObject(graphql.ObjectConfig{
+        Name:   name + "Connection",
+        Fields: fields,
+    })
+}
+
+// connectionArgs adds the forward paging arguments to a connection field's own arguments
+func connectionArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+    if args == nil {
+        args = graphql.FieldConfigArgument{}
+    }
+    args["first"] = &graphql.ArgumentConfig{
+        Type:        graphql.Int,
+        Description: "Page size; the service caps it (100)",
+    }
+    args["after"] = &graphql.ArgumentConfig{
+        Type:        graphql.String,
+        Description: "endCursor of the previous page",
+    }
+    return args
+}
+
+// connectionPage reads first and after from a connection field's arguments
+func connectionPage(args map[string]interface{}) (int, string) {
+    first, _ := args["first"].(int)
+    after, _ := args["after"].(string)
+    return first, after
+}
+
+// relayConnection converts a service's keyset page (edges, page_info, total_count) to the
+// connection shape; other fields of the page, like a rating summary, are kept as they are
+func relayConnection(page map[string]interface{}) map[string]interface{} {
+    conn := make(map[string]interface{}, len(page)+1)
+    for key, value := range page {
+        conn[key] = value
+    }
+
+    if _, ok := conn["edges"].([]interface{}); !ok {
+        conn["edges"] = []interface{}{}
+    }
+
+    info, _ := page["page_info"].(map[string]interface{})
+    hasNext, _ := info["has_next_page"].(bool)
+    hasPrevious, _ := info["has_previous_page"].(bool)
+    conn["pageInfo"] = map[string]interface{}{
+        "hasNextPage":     hasNext,
+        "hasPreviousPage": hasPrevious,
+        "startCursor":     info["start_cursor"],
+        "endCursor":       info["end_cursor"],
+    }
+    delete(conn, "page_info")
+
+    if total, ok := page["total_count"]; ok {
+        conn["totalCount"] = total
+        delete(conn, "total_count")
+    }
+
+    return conn
+}
+
+// isConnectionType reports whether t is a connection, sized by its field's first argument
+// rather than its edges list (see querylimits.go)
+func isConnectionType(t graphql.Type) bool {
+    obj, ok := t.(*graphql.Object)
+    if !ok {
+        return false
+    }
+    fields := obj.Fields()
+    return fields["edges"] != nil && fields["pageInfo"] != nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+    "testing"
+
+    "github.com/graphql-go/graphql"
+    "github.com/graphql-go/graphql/language/ast"
+    "github.com/graphql-go/graphql/language/parser"
+)
+
+func TestRelayConnection_ConvertsServicePage(t *testing.T) {
+    page := map[string]interface{}{
+        "edges":          []interface{}{map[string]interface{}{"cursor": "c1", "node": map[string]interface{}{"id": float64(1)}}},
+        "page_info":      map[string]interface{}{"has_next_page": true, "has_previous_page": false, "start_cursor": "c1", "end_cursor": "c1"},
+        "total_count":    float64(12),
+        "average_rating": 4.5,
+    }
+
+    conn := relayConnection(page)
+
+    info, ok := conn["pageInfo"].(map[string]interface{})
+    if !ok || info["hasNextPage"] != true || info["hasPreviousPage"] != false || info["endCursor"] != "c1" {
+        t.Fatalf("unexpected pageInfo %v", conn["pageInfo"])
+    }
+    if conn["totalCount"] != float64(12) || conn["average_rating"] != 4.5 {
+        t.Fatalf("expected totalCount and extras to be kept, got %v", conn)
+    }
+    if _, ok := conn["page_info"]; ok {
+        t.Fatal("expected page_info to be replaced by pageInfo")
+    }
+
+    // An empty page from the service still resolves a non-null edges list
+    if edges, ok := relayConnection(map[string]interface{}{})["edges"].([]interface{}); !ok || len(edges) != 0 {
+        t.Fatalf("expected empty edges, got %v", edges)
+    }
+}
+
+func TestQueryLimits_ConnectionSizedByFirst(t *testing.T) {
+    productType := graphql.NewObject(graphql.ObjectConfig{
+        Name:   "Product",
+        Fields: graphql.Fields{"name": &graphql.Field{Type: graphql.String}},
+    })
+    connType := newConnectionType("Product", productType, newPageInfoType(), nil)
+    schema, err := graphql.NewSchema(graphql.SchemaConfig{
+        Query: graphql.NewObject(graphql.ObjectConfig{
+            Name: "Query",
+            Fields: graphql.Fields{
+                "productsConnection": &graphql.Field{Type: connType, Args: connectionArgs(nil)},
+            },
+        }),
+    })
+    if err != nil {
+        t.Fatalf("failed to build schema: %v", err)
+    }
+
+    cost := func(query string) int {
+        doc, err := parser.Parse(parser.ParseParams{Source: query})
+        if err != nil {
+            t.Fatalf("failed to parse %q: %v", query, err)
+        }
+        return newQueryScorer(&schema, doc, nil, 50).operationCost(doc.Definitions[0].(*ast.OperationDefinition)).Complexity
+    }
+
+    one := cost(`{ productsConnection(first: 1) { edges { node { name } } pageInfo { endCursor } } }`)
+    ten := cost(`{ productsConnection(first: 10) { edges { node { name } } pageInfo { endCursor } } }`)
+    if ten != 10*one {
+        t.Fatalf("expected first: 10 to cost 10 times first: 1, got %d and %d", ten, one)
+    }
+    // The edges list is sized by first, not multiplied again by the default list size
+    if one >= 50 {
+        t.Fatalf("expected edges to count once, got complexity %d", one)
+    }
+}
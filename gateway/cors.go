@@ -0,0 +1,160 @@
+package main
+
+import (
+    "log"
+    "net/http"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// CORS
+// Why: "*" with Allow-Credentials is refused by browsers for credentialed requests and lets any
+// site call the API. Allowed origins are listed per deployment with the same CORS_* variables
+// as the services (shared/cors); the gateway can't import that package.
+
+// CORSConfig is the gateway's CORS policy
+type CORSConfig struct {
+    AllowedOrigins   []string // exact origins, "*" for any, or regular expressions starting with "^"
+    AllowedMethods   []string
+    AllowedHeaders   []string
+    AllowCredentials bool          // never sent for an origin matched only by "*"
+    MaxAge           time.Duration // 0 omits Access-Control-Max-Age
+}
+
+// loadCORSConfig reads the CORS_* variables
+func loadCORSConfig() CORSConfig {
+    config := CORSConfig{
+        AllowedOrigins:   []string{"*"},
+        AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+        AllowedHeaders:   []string{"Content-Type", "Authorization", RequestIDHeader, GuestTokenHeader},
+        AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+        MaxAge:           time.Duration(getEnvInt("CORS_MAX_AGE_SECONDS", 600)) * time.Second,
+    }
+    if val := os.Getenv("CORS_ALLOWED_ORIGINS"); val != "" {
+        config.AllowedOrigins = splitCORSList(val)
+    }
+    if val := os.Getenv("CORS_ALLOWED_METHODS"); val != "" {
+        config.AllowedMethods = splitCORSList(strings.ToUpper(val))
+    }
+    if val := os.Getenv("CORS_ALLOWED_HEADERS"); val != "" {
+        config.AllowedHeaders = splitCORSList(val)
+    }
+    return config
+}
+
+func splitCORSList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// corsPolicy is a CORSConfig ready to match origins
+type corsPolicy struct {
+    anyOrigin   bool
+    origins     map[string]bool
+    patterns    []*regexp.Regexp
+    methods     string
+    headers     string
+    credentials bool
+    maxAge      string
+}
+
+// newCORSPolicy compiles the config; an invalid origin pattern is logged and matches nothing
+func newCORSPolicy(config CORSConfig) *corsPolicy {
+    p := &corsPolicy{
+        origins:     map[string]bool{},
+        methods:     strings.Join(config.AllowedMethods, ", "),
+        headers:     strings.Join(config.AllowedHeaders, ", "),
+        credentials: config.AllowCredentials,
+    }
+    if config.MaxAge > 0 {
+        p.maxAge = strconv.Itoa(int(config.MaxAge / time.Second))
+    }
+
+    for _, origin := range config.AllowedOrigins {
+        switch {
+        case origin == "*":
+            p.anyOrigin = true
+        case strings.HasPrefix(origin, "^"):
+            pattern, err := regexp.Compile("^(?:" + strings.TrimSuffix(origin[1:], "$") + ")$")
+            if err != nil {
+                log.Printf("⚠️  Invalid CORS origin pattern %q, ignoring it: %v", origin, err)
+                continue
+            }
+            p.patterns = append(p.patterns, pattern)
+        default:
+            p.origins[strings.TrimSuffix(origin, "/")] = true
+        }
+    }
+    return p
+}
+
+// matchOrigin reports whether origin is allowed, and whether it is listed rather than only covered by "*"
+func (p *corsPolicy) matchOrigin(origin string) (allowed, listed bool) {
+    if p.origins[origin] {
+        return true, true
+    }
+    for _, pattern := range p.patterns {
+        if pattern.MatchString(origin) {
+            return true, true
+        }
+    }
+    return p.anyOrigin, false
+}
+
+// corsMiddleware adds CORS headers for allowed origins and answers preflight requests.
+// A preflight from any other origin gets 403; its other requests are served without CORS headers.
+func corsMiddleware(config CORSConfig) gin.HandlerFunc {
+    p := newCORSPolicy(config)
+
+    return func(c *gin.Context) {
+        origin := c.GetHeader("Origin")
+        preflight := c.Request.Method == http.MethodOptions
+
+        if origin != "" {
+            header := c.Writer.Header()
+            allowed, listed := p.matchOrigin(origin)
+            if listed || !p.anyOrigin {
+                header.Add("Vary", "Origin")
+            }
+
+            if !allowed {
+                if preflight && c.GetHeader("Access-Control-Request-Method") != "" {
+                    c.AbortWithStatus(http.StatusForbidden)
+                    return
+                }
+            } else if listed {
+                header.Set("Access-Control-Allow-Origin", origin)
+                if p.credentials {
+                    header.Set("Access-Control-Allow-Credentials", "true")
+                }
+            } else {
+                header.Set("Access-Control-Allow-Origin", "*")
+            }
+
+            if allowed && preflight {
+                header.Set("Access-Control-Allow-Methods", p.methods)
+                header.Set("Access-Control-Allow-Headers", p.headers)
+                if p.maxAge != "" {
+                    header.Set("Access-Control-Max-Age", p.maxAge)
+                }
+            }
+        }
+
+        if preflight {
+            c.AbortWithStatus(http.StatusNoContent)
+            return
+        }
+
+        c.Next()
+    }
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+
+    "github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Gateway error model
+// Why: resolver errors used to reach clients as raw downstream strings. Every GraphQL error now
+// carries extensions.code so the frontend can branch on the kind of failure, and messages
+// are safe to show. Codes set elsewhere (APQ, query limits) are kept as they are.
+
+// Error codes returned in errors[].extensions.code
+const (
+    CodeNotFound     = "NOT_FOUND"
+    CodeUnauthorized = "UNAUTHORIZED" // no or invalid credentials
+    CodeForbidden    = "FORBIDDEN"    // authenticated but not allowed
+    CodeValidation   = "VALIDATION_ERROR"
+    CodeUnavailable  = "UNAVAILABLE"
+    CodeInternal     = "INTERNAL_ERROR"
+)
+
+// GatewayError is an error with a GraphQL error code and a client-safe message.
+// It implements gqlerrors.ExtendedError, so graphql-go copies the code into extensions.
+type GatewayError struct {
+    Code    string
+    Message string
+    Fields  []FieldError // invalid arguments of a VALIDATION_ERROR, sent as extensions.fields
+    Err     error        // underlying cause, for errors.Is/As and logs; never sent to clients
+}
+
+func (e *GatewayError) Error() string {
+    return e.Message
+}
+
+func (e *GatewayError) Unwrap() error {
+    return e.Err
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e *GatewayError) Extensions() map[string]interface{} {
+    extensions := map[string]interface{}{"code": e.Code}
+    if len(e.Fields) > 0 {
+        extensions["fields"] = e.Fields
+    }
+    return extensions
+}
+
+// NotFound returns a NOT_FOUND error
+func NotFound(message string) *GatewayError {
+    return &GatewayError{Code: CodeNotFound, Message: message}
+}
+
+// Unauthorized returns an UNAUTHORIZED error
+func Unauthorized(message string) *GatewayError {
+    return &GatewayError{Code: CodeUnauthorized, Message: message}
+}
+
+// Forbidden returns a FORBIDDEN error
+func Forbidden(message string) *GatewayError {
+    return &GatewayError{Code: CodeForbidden, Message: message}
+}
+
+// Validation returns a VALIDATION_ERROR error
+func Validation(message string) *GatewayError {
+    return &GatewayError{Code: CodeValidation, Message: message}
+}
+
+// Unavailable returns an UNAVAILABLE error wrapping the cause
+func Unavailable(message string, cause error) *GatewayError {
+    return &GatewayError{Code: CodeUnavailable, Message: message, Err: cause}
+}
+
+// errorFromStatus maps a downstream non-2xx response to a GatewayError.
+// The message is the service's short ErrorResponse.error; only validation failures add
+// ErrorResponse.message (e.g. which field failed binding), and 5xx bodies are never passed on.
+func errorFromStatus(se *ServiceError) *GatewayError {
+    message := se.Code
+    if message == "" {
+        message = http.StatusText(se.StatusCode)
+    }
+
+    code := CodeInternal
+    switch {
+    case se.StatusCode == http.StatusNotFound:
+        code = CodeNotFound
+    case se.StatusCode == http.StatusUnauthorized:
+        code = CodeUnauthorized
+    case se.StatusCode == http.StatusForbidden:
+        code = CodeForbidden
+    case se.StatusCode == http.StatusTooManyRequests, se.StatusCode >= 500:
+        code = CodeUnavailable
+        message = "downstream service unavailable"
+    case se.StatusCode >= 400:
+        // 400, 409, 422: the request was understood and refused
+        code = CodeValidation
+        if se.Message != "" {
+            message += ": " + se.Message
+        }
+    }
+
+    return &GatewayError{Code: code, Message: message, Err: se}
+}
+
+// formatError turns an executed GraphQL error into its response shape with extensions.code
+func formatError(err gqlerrors.FormattedError) map[string]interface{} {
+    formatted := map[string]interface{}{"message": err.Message}
+    if len(err.Path) > 0 {
+        formatted["path"] = err.Path
+    }
+
+    if _, ok := err.Extensions["code"]; ok {
+        formatted["extensions"] = err.Extensions
+        return formatted
+    }
+
+    cause := err.OriginalError()
+    if located, ok := cause.(*gqlerrors.Error); ok {
+        cause = located.OriginalError
+    }
+
+    var ge *GatewayError
+    switch {
+    case errors.As(cause, &ge):
+        formatted["message"] = ge.Message
+        formatted["extensions"] = ge.Extensions()
+    case errors.Is(cause, context.DeadlineExceeded), errors.Is(cause, ErrServiceUnavailable):
+        formatted["message"] = "downstream service unavailable"
+        formatted["extensions"] = map[string]interface{}{"code": CodeUnavailable}
+    case len(err.Path) == 0:
+        // Parse and schema validation errors are reported before execution, without a path
+        formatted["extensions"] = map[string]interface{}{"code": CodeValidation}
+    default:
+        log.Printf("❌ Unclassified resolver error at %v: %s", err.Path, err.Message)
+        formatted["message"] = "internal server error"
+        formatted["extensions"] = map[string]interface{}{"code": CodeInternal}
+    }
+
+    return formatted
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Event timeline.
+// eventTimeline(correlation_id) answers "what happened to this checkout" for support in one
+// chronological list. It merges the orders saga's status changes (each with the event that
+// caused it), the cart's side of the saga, the orders the saga created and their
+// compensations, and the stock reservations products holds for those orders. The saga itself
+// is required; every other source is best effort, and a failed one is listed in sources with
+// its error so a partial timeline isn't mistaken for a complete one.
+
+// Event timeline source names
+const (
+    timelineSourceSaga     = "orders.saga"
+    timelineSourceOrders   = "orders.records"
+    timelineSourceCart     = "cart"
+    timelineSourceProducts = "products"
+)
+
+// timelineEntry is one moment of the timeline before it's sorted
+type timelineEntry struct {
+    at     time.Time
+    fields map[string]interface{}
+}
+
+// fetchEventTimeline loads every source of a saga's timeline and merges them. Only a missing
+// or failing saga is an error.
+func fetchEventTimeline(ctx context.Context, rc *ResolverContext, correlationID string) (map[string]interface{}, error) {
+    saga, err := rc.OrderService.GetSagaTimeline(ctx, correlationID)
+    if err != nil {
+        return nil, err
+    }
+
+    var (
+        wg       sync.WaitGroup
+        records  map[string]interface{}
+        cartSaga map[string]interface{}
+        errs     = map[string]error{}
+        mu       sync.Mutex
+    )
+    fail := func(source string, err error) {
+        log.Printf("⚠️  Event timeline %s: %s unavailable: %v", correlationID, source, err)
+        mu.Lock()
+        errs[source] = err
+        mu.Unlock()
+    }
+
+    wg.Add(2)
+    go func() {
+        defer wg.Done()
+        var err error
+        if cartSaga, err = rc.CartService.GetSagaState(ctx, correlationID); err != nil && !isNotFound(err) {
+            fail(timelineSourceCart, err)
+        }
+    }()
+    go func() {
+        defer wg.Done()
+        var err error
+        if records, err = rc.OrderService.GetSagaRecords(ctx, correlationID); err != nil || records == nil {
+            if err != nil {
+                fail(timelineSourceOrders, err)
+            }
+            return
+        }
+
+        // Reservations are per order, so they wait for the orders
+        orders, _ := records["orders"].([]interface{})
+        reservations := make([][]interface{}, len(orders))
+        var orderWG sync.WaitGroup
+        for i, order := range orders {
+            orderID, ok := mapInt64(order, "id")
+            if !ok {
+                continue
+            }
+            orderWG.Add(1)
+            go func(i int, orderID int64) {
+                defer orderWG.Done()
+                list, err := rc.ProductService.GetOrderReservations(ctx, orderID)
+                if err != nil {
+                    fail(timelineSourceProducts, fmt.Errorf("order %d: %w", orderID, err))
+                    return
+                }
+                reservations[i] = list
+            }(i, orderID)
+        }
+        orderWG.Wait()
+
+        var all []interface{}
+        for _, list := range reservations {
+            all = append(all, list...)
+        }
+        records["reservations"] = all
+    }()
+    wg.Wait()
+
+    return buildEventTimeline(saga, records, cartSaga, errs), nil
+}
+
+// buildEventTimeline merges the sources into one timeline, oldest first. records carries the
+// saga's orders, compensations and reservations; records and cartSaga are nil when unavailable,
+// and errs holds the error of each failed source.
+func buildEventTimeline(saga, records, cartSaga map[string]interface{}, errs map[string]error) map[string]interface{} {
+    var entries []timelineEntry
+    add := func(at interface{}, source, kind, summary string, extra map[string]interface{}) {
+        when, ok := parseTimelineTime(at)
+        if !ok {
+            return
+        }
+        fields := map[string]interface{}{
+            "at":      when.Format(time.RFC3339Nano),
+            "source":  source,
+            "kind":    kind,
+            "summary": summary,
+        }
+        for key, value := range extra {
+            fields[key] = value
+        }
+        entries = append(entries, timelineEntry{at: when, fields: fields})
+    }
+
+    if cartSaga != nil {
+        add(cartSaga["created_at"], timelineSourceCart, "checkout.initiated",
+            "Checkout started from cart " + jsonText(cartSaga["cart_id"]), nil)
+        if cartSaga["updated_at"] != cartSaga["created_at"] {
+            add(cartSaga["updated_at"], timelineSourceCart, "cart.saga_updated",
+                "Cart last saw the checkout as " + jsonText(cartSaga["status"]), nil)
+        }
+    }
+
+    transitions, _ := saga["transitions"].([]interface{})
+    for _, raw := range transitions {
+        transition, _ := raw.(map[string]interface{})
+        summary := "Saga started as " + jsonText(transition["to_status"])
+        if from, ok := transition["from_status"].(string); ok && from != "" {
+            summary = "Saga " + from + " → " + jsonText(transition["to_status"])
+        }
+        if reason, ok := transition["reason"].(string); ok && reason != "" {
+            summary += ": " + reason
+        }
+        add(transition["created_at"], timelineSourceSaga, "saga.transition", summary, map[string]interface{}{
+            "event_id":      transition["event_id"],
+            "event_type":    transition["event_type"],
+            "actor_service": transition["actor_service"],
+        })
+    }
+
+    orders, _ := records["orders"].([]interface{})
+    for _, raw := range orders {
+        order, _ := raw.(map[string]interface{})
+        orderID := jsonText(order["id"])
+        ref := map[string]interface{}{"order_id": order["id"]}
+        items, _ := order["items"].([]interface{})
+        add(order["created_at"], timelineSourceOrders, "order.created",
+            fmt.Sprintf("Order #%s created: %d item(s), total %s", orderID, len(items), jsonText(order["total"])), ref)
+        add(order["shipped_at"], timelineSourceOrders, "order.shipped", "Order #" + orderID + " shipped", ref)
+        add(order["delivered_at"], timelineSourceOrders, "order.delivered", "Order #" + orderID + " delivered", ref)
+        add(order["cancelled_at"], timelineSourceOrders, "order.cancelled", "Order #" + orderID + " cancelled", ref)
+    }
+
+    compensations, _ := records["compensations"].([]interface{})
+    for _, raw := range compensations {
+        compensation, _ := raw.(map[string]interface{})
+        ref := map[string]interface{}{"order_id": compensation["order_id"], "event_type": compensation["compensation_event"]}
+        what := fmt.Sprintf("Compensation %s for order #%s", jsonText(compensation["compensation_event"]), jsonText(compensation["order_id"]))
+        add(compensation["created_at"], timelineSourceOrders, "compensation.started", what+" ("+jsonText(compensation["status"])+")", ref)
+        add(compensation["completed_at"], timelineSourceOrders, "compensation.completed", what+" completed", ref)
+    }
+
+    reservations, _ := records["reservations"].([]interface{})
+    for _, raw := range reservations {
+        reservation, _ := raw.(map[string]interface{})
+        what := fmt.Sprintf("%s × product %s", jsonText(reservation["quantity"]), jsonText(reservation["product_id"]))
+        if variantID, ok := reservation["variant_id"]; ok && variantID != nil {
+            what += " variant " + jsonText(variantID)
+        }
+        ref := map[string]interface{}{"order_id": reservation["order_id"]}
+        add(reservation["created_at"], timelineSourceProducts, "stock.reserved",
+            fmt.Sprintf("Reserved %s for order #%s", what, jsonText(reservation["order_id"])), ref)
+        if status, _ := reservation["status"].(string); status != "" && status != "reserved" {
+            add(reservation["released_at"], timelineSourceProducts, "stock."+status,
+                fmt.Sprintf("Reservation of %s %s", what, status), ref)
+        }
+    }
+
+    // Stable, so entries at the same moment keep the source order above
+    sort.SliceStable(entries, func(i, j int) bool {
+        return entries[i].at.Before(entries[j].at)
+    })
+    list := make([]map[string]interface{}, len(entries))
+    for i, entry := range entries {
+        list[i] = entry.fields
+    }
+
+    sources := []map[string]interface{}{}
+    for _, name := range []string{timelineSourceSaga, timelineSourceCart, timelineSourceOrders, timelineSourceProducts} {
+        source := map[string]interface{}{"name": name, "ok": errs[name] == nil}
+        if err := errs[name]; err != nil {
+            source["error"] = err.Error()
+        }
+        sources = append(sources, source)
+    }
+
+    return map[string]interface{}{
+        "correlation_id": saga["correlation_id"],
+        "status":         saga["status"],
+        "entries":        list,
+        "sources":        sources,
+        "complete":       len(errs) == 0,
+    }
+}
+
+// parseTimelineTime reads a service timestamp; nil and empty values have no moment
+func parseTimelineTime(value interface{}) (time.Time, bool) {
+    s, ok := value.(string)
+    if !ok || strings.TrimSpace(s) == "" {
+        return time.Time{}, false
+    }
+    t, err := time.Parse(time.RFC3339Nano, s)
+    if err != nil {
+        return time.Time{}, false
+    }
+    return t.UTC(), true
+}
+
+// jsonText formats a decoded JSON value for a summary; numbers without an exponent
+func jsonText(value interface{}) string {
+    switch v := value.(type) {
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    case nil:
+        return "?"
+    default:
+        return fmt.Sprint(v)
+    }
+}
+
+// mapInt64 reads a JSON number field of a decoded object
+func mapInt64(value interface{}, key string) (int64, bool) {
+    object, _ := value.(map[string]interface{})
+    n, ok := object[key].(float64)
+    return int64(n), ok
+}
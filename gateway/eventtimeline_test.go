@@ -0,0 +1,79 @@
+package main
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestBuildEventTimeline_MergesSourcesInOrder(t *testing.T) {
+    saga := map[string]interface{}{
+        "correlation_id": "cid-1",
+        "status":         "compensated",
+        "transitions": []interface{}{
+            map[string]interface{}{"to_status": "started", "created_at": "2025-03-01T10:00:01Z", "event_id": "e1", "event_type": "CheckoutInitiated", "actor_service": "cart"},
+            map[string]interface{}{"from_status": "started", "to_status": "failed", "reason": "out of stock", "created_at": "2025-03-01T10:00:03Z"},
+        },
+    }
+    cartSaga := map[string]interface{}{"cart_id": "cart-9", "status": "failed", "created_at": "2025-03-01T10:00:00Z", "updated_at": "2025-03-01T10:00:05Z"}
+    records := map[string]interface{}{
+        "orders": []interface{}{
+            map[string]interface{}{"id": float64(1234567), "total": 59.9, "items": []interface{}{map[string]interface{}{}}, "created_at": "2025-03-01T10:00:02Z", "cancelled_at": "2025-03-01T10:00:04Z"},
+        },
+        "reservations": []interface{}{
+            map[string]interface{}{"order_id": float64(1234567), "product_id": float64(7), "quantity": float64(2), "status": "released", "created_at": "2025-03-01T10:00:02.5Z", "released_at": "2025-03-01T10:00:04.5Z"},
+        },
+    }
+
+    timeline := buildEventTimeline(saga, records, cartSaga, map[string]error{})
+
+    entries := timeline["entries"].([]map[string]interface{})
+    var kinds []string
+    for _, entry := range entries {
+        kinds = append(kinds, entry["kind"].(string))
+    }
+    want := []string{"checkout.initiated", "saga.transition", "order.created", "stock.reserved", "saga.transition", "order.cancelled", "stock.released", "cart.saga_updated"}
+    if len(kinds) != len(want) {
+        t.Fatalf("expected %v, got %v", want, kinds)
+    }
+    for i := range want {
+        if kinds[i] != want[i] {
+            t.Fatalf("expected %v, got %v", want, kinds)
+        }
+    }
+
+    if got := entries[2]["summary"]; got != "Order #1234567 created: 1 item(s), total 59.9" {
+        t.Fatalf("unexpected order summary %q", got)
+    }
+    if got := entries[4]["summary"]; got != "Saga started → failed: out of stock" {
+        t.Fatalf("unexpected transition summary %q", got)
+    }
+    if entries[1]["event_id"] != "e1" || entries[1]["actor_service"] != "cart" {
+        t.Fatalf("expected the transition's event, got %v", entries[1])
+    }
+    if timeline["complete"] != true {
+        t.Fatal("expected a complete timeline")
+    }
+}
+
+func TestBuildEventTimeline_ReportsFailedSources(t *testing.T) {
+    saga := map[string]interface{}{"correlation_id": "cid-2", "status": "started"}
+    errs := map[string]error{timelineSourceProducts: errors.New("order 1: service unavailable")}
+
+    timeline := buildEventTimeline(saga, nil, nil, errs)
+
+    if timeline["complete"] != false {
+        t.Fatal("expected an incomplete timeline")
+    }
+    if entries := timeline["entries"].([]map[string]interface{}); len(entries) != 0 {
+        t.Fatalf("expected no entries, got %v", entries)
+    }
+    for _, source := range timeline["sources"].([]map[string]interface{}) {
+        failed := source["name"] == timelineSourceProducts
+        if source["ok"] == failed {
+            t.Fatalf("unexpected source %v", source)
+        }
+        if failed && source["error"] != "order 1: service unavailable" {
+            t.Fatalf("expected the source's error, got %v", source)
+        }
+    }
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+    "log"
+
+    "github.com/graphql-go/graphql"
+)
+
+// Schema feature toggles
+// Why: the same gateway binary serves tiers that don't offer every feature. A disabled section
+// is left out of the schema entirely, so its fields fail validation and don't show up in
+// introspection, instead of every resolver checking a flag.
+
+// SchemaFeatures selects the optional schema sections built by BuildSchema
+type SchemaFeatures struct {
+    Reviews        bool // productReviews(Connection), addReview and the Product rating fields
+    AdminQueries   bool // adminStats, funnel, sagaTimeline, eventTimeline, users, auditLogs
+    AdminMutations bool // catalog, inventory and user management mutations
+}
+
+// schemaSection lists the root fields that belong to one toggle
+type schemaSection struct {
+    name      string
+    queries   []string
+    mutations []string
+}
+
+var (
+    reviewsSection = schemaSection{
+        name:      "reviews",
+        queries:   []string{"productReviews", "productReviewsConnection"},
+        mutations: []string{"addReview"},
+    }
+    adminQueriesSection = schemaSection{
+        name:    "admin queries",
+        queries: []string{"adminStats", "funnel", "sagaTimeline", "eventTimeline", "users", "auditLogs"},
+    }
+    adminMutationsSection = schemaSection{
+        name: "admin mutations",
+        mutations: []string{
+            "createProduct", "updateProduct", "deleteProduct", "addVariant", "createCategory",
+            "createAttributeTemplate", "updateAttributeTemplate", "deleteAttributeTemplate",
+            "reserveInventory", "releaseInventory", "adjustInventory",
+            "disableUser", "enableUser", "forcePasswordReset", "updateUserRole",
+        },
+    }
+)
+
+// disabled returns the sections turned off in sf
+func (sf SchemaFeatures) disabled() []schemaSection {
+    var sections []schemaSection
+    if !sf.Reviews {
+        sections = append(sections, reviewsSection)
+    }
+    if !sf.AdminQueries {
+        sections = append(sections, adminQueriesSection)
+    }
+    if !sf.AdminMutations {
+        sections = append(sections, adminMutationsSection)
+    }
+    return sections
+}
+
+// queryFields removes the query fields of disabled sections
+func (sf SchemaFeatures) queryFields(fields graphql.Fields) graphql.Fields {
+    for _, section := range sf.disabled() {
+        for _, name := range section.queries {
+            delete(fields, name)
+        }
+    }
+    return fields
+}
+
+// mutationFields removes the mutation fields of disabled sections
+func (sf SchemaFeatures) mutationFields(fields graphql.Fields) graphql.Fields {
+    for _, section := range sf.disabled() {
+        for _, name := range section.mutations {
+            delete(fields, name)
+        }
+    }
+    return fields
+}
+
+// isDisabled reports whether a root field ("Query" or "Mutation" typeName) was left out by sf
+func (sf SchemaFeatures) isDisabled(typeName, name string) bool {
+    for _, section := range sf.disabled() {
+        fields := section.queries
+        if typeName == "Mutation" {
+            fields = section.mutations
+        }
+        for _, field := range fields {
+            if field == name {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func (sf SchemaFeatures) logDisabled() {
+    for _, section := range sf.disabled() {
+        log.Printf("⚠️  Schema section disabled: %s", section.name)
+    }
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+    "context"
+    "crypto/rand"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "regexp"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// Guest carts
+// Shoppers who haven't signed in get a guest token from createGuestToken: an HS256 JWT signed
+// with GUEST_TOKEN_SECRET whose subject is a random guest ID. The client sends it back as
+// X-Guest-Token and the gateway forwards it to the cart service, which keeps the guest's cart
+// under that ID and verifies the token itself. After login or register the gateway asks the
+// cart service to merge the guest cart into the user's.
+
+// GuestTokenHeader carries the guest token, to the gateway and on to the cart service
+const GuestTokenHeader = "X-Guest-Token"
+
+// GuestTokenContextKey holds the caller's verified raw guest token
+const GuestTokenContextKey ContextKey = "guest_token"
+
+// guestKind tells guest tokens apart from user tokens signed with the same secret
+const guestKind = "guest"
+
+// GuestTokenConfig holds guest token settings
+type GuestTokenConfig struct {
+    Secret string        // GUEST_TOKEN_SECRET; empty disables guest carts
+    TTL    time.Duration // GUEST_TOKEN_TTL_DAYS
+}
+
+type guestClaims struct {
+    Kind string `json:"kind"`
+    jwt.RegisteredClaims
+}
+
+// GuestTokens issues and verifies guest tokens
+type GuestTokens struct {
+    secret []byte
+    ttl    time.Duration
+    now    func() time.Time
+}
+
+// NewGuestTokens creates the guest token issuer; nil when no secret is configured
+func NewGuestTokens(config GuestTokenConfig) *GuestTokens {
+    if config.Secret == "" {
+        return nil
+    }
+    if config.TTL <= 0 {
+        config.TTL = 30 * 24 * time.Hour
+    }
+    return &GuestTokens{secret: []byte(config.Secret), ttl: config.TTL, now: time.Now}
+}
+
+// Issue creates a token for a new guest and returns it with the guest ID and expiry
+func (gt *GuestTokens) Issue() (string, string, time.Time, error) {
+    guestID, err := newGuestID()
+    if err != nil {
+        return "", "", time.Time{}, err
+    }
+    now := gt.now()
+    expiresAt := now.Add(gt.ttl)
+
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, guestClaims{
+        Kind: guestKind,
+        RegisteredClaims: jwt.RegisteredClaims{
+            Subject:   guestID,
+            IssuedAt:  jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(expiresAt),
+        },
+    })
+    signed, err := token.SignedString(gt.secret)
+    if err != nil {
+        return "", "", time.Time{}, fmt.Errorf("failed to sign guest token: %w", err)
+    }
+    return signed, guestID, expiresAt, nil
+}
+
+// Verify checks a guest token's signature and expiry and returns its guest ID
+func (gt *GuestTokens) Verify(tokenString string) (string, error) {
+    claims := &guestClaims{}
+    _, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+        return gt.secret, nil
+    }, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired(), jwt.WithTimeFunc(gt.now))
+    if err != nil {
+        return "", err
+    }
+    if claims.Kind != guestKind {
+        return "", errors.New("not a guest token")
+    }
+    // Why: the cart service keeps the guest ID in its user_id column, a UUID
+    if !guestIDPattern.MatchString(claims.Subject) {
+        return "", errors.New("guest ID is not a UUID")
+    }
+    return claims.Subject, nil
+}
+
+var guestIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// newGuestID returns a random (version 4) UUID
+func newGuestID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("failed to generate guest ID: %w", err)
+    }
+    b[6] = b[6]&0x0f | 0x40
+    b[8] = b[8]&0x3f | 0x80
+    return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// guestMiddleware checks X-Guest-Token before the resolvers run, so a bad token is a 401 like
+// a bad Authorization header. Without guest tokens configured the header is ignored.
+func guestMiddleware(guests *GuestTokens) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        token := c.GetHeader(GuestTokenHeader)
+        if token == "" || guests == nil {
+            c.Next()
+            return
+        }
+
+        if _, err := guests.Verify(token); err != nil {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid guest token"})
+            c.Abort()
+            return
+        }
+
+        c.Set("guest_token", token)
+        c.Next()
+    }
+}
+
+// guestTokenFromContext returns the caller's verified guest token, or ""
+func guestTokenFromContext(ctx context.Context) string {
+    token, _ := ctx.Value(GuestTokenContextKey).(string)
+    return token
+}
+
+// requireCartCaller lets signed-in users and guests use their cart
+func requireCartCaller(ctx context.Context) error {
+    if _, err := GetUserFromContext(ctx); err == nil {
+        return nil
+    }
+    if guestTokenFromContext(ctx) != "" {
+        return nil
+    }
+    return Unauthorized("sign in or create a guest token to use a cart")
+}
+
+// cartCallerName names the caller in cart logs
+func cartCallerName(ctx context.Context) string {
+    if user, err := GetUserFromContext(ctx); err == nil {
+        return fmt.Sprintf("User %s", user["id"])
+    }
+    return "Guest"
+}
+
+// mergeGuestCartAtLogin merges the caller's guest cart into the user's cart once login or
+// register succeeded. Why: a failed merge must not fail the login; the guest cart stays as it
+// was and the client can retry with mergeGuestCart.
+func mergeGuestCartAtLogin(ctx context.Context, carts *CartService, authResp *AuthResponse) {
+    guestToken := guestTokenFromContext(ctx)
+    if guestToken == "" || authResp == nil || authResp.Token == "" {
+        return
+    }
+
+    if _, err := carts.MergeGuestCart(ctx, "Bearer "+authResp.Token, guestToken); err != nil {
+        log.Printf("⚠️  Guest cart not merged at login: %v", err)
+        return
+    }
+    log.Printf("✓ Guest cart merged for user %v", authResp.User["id"])
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestGuestTokens_IssueVerify(t *testing.T) {
+    guests := NewGuestTokens(GuestTokenConfig{Secret: "guest-secret", TTL: time.Hour})
+
+    token, guestID, expiresAt, err := guests.Issue()
+    if err != nil {
+        t.Fatalf("Issue: %v", err)
+    }
+    if !guestIDPattern.MatchString(guestID) {
+        t.Fatalf("guest ID %q is not a UUID", guestID)
+    }
+    if until := time.Until(expiresAt); until <= 0 || until > time.Hour {
+        t.Fatalf("expires_at %v is not within the TTL", expiresAt)
+    }
+
+    verified, err := guests.Verify(token)
+    if err != nil || verified != guestID {
+        t.Fatalf("Verify = %q, %v; want %q", verified, err, guestID)
+    }
+
+    other := NewGuestTokens(GuestTokenConfig{Secret: "other-secret", TTL: time.Hour})
+    if _, err := other.Verify(token); err == nil {
+        t.Fatal("token verified with another secret")
+    }
+
+    guests.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+    if _, err := guests.Verify(token); err == nil {
+        t.Fatal("expired token verified")
+    }
+}
+
+func TestNewGuestTokens_DisabledWithoutSecret(t *testing.T) {
+    if guests := NewGuestTokens(GuestTokenConfig{}); guests != nil {
+        t.Fatal("guest tokens enabled without a secret")
+    }
+}
+
+func TestGuestMiddleware(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    guests := NewGuestTokens(GuestTokenConfig{Secret: "guest-secret", TTL: time.Hour})
+    token, _, _, err := guests.Issue()
+    if err != nil {
+        t.Fatalf("Issue: %v", err)
+    }
+
+    serve := func(guests *GuestTokens, header string) (int, string) {
+        var seen string
+        router := gin.New()
+        router.POST("/graphql", guestMiddleware(guests), func(c *gin.Context) {
+            seen = c.GetString("guest_token")
+            c.Status(http.StatusOK)
+        })
+        req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+        if header != "" {
+            req.Header.Set(GuestTokenHeader, header)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w.Code, seen
+    }
+
+    if code, seen := serve(guests, token); code != http.StatusOK || seen != token {
+        t.Errorf("valid guest token: status %d, token %q", code, seen)
+    }
+    if code, _ := serve(guests, "forged"); code != http.StatusUnauthorized {
+        t.Errorf("forged guest token: status %d, want 401", code)
+    }
+    if code, seen := serve(nil, token); code != http.StatusOK || seen != "" {
+        t.Errorf("guest carts disabled: status %d, token %q; want the header ignored", code, seen)
+    }
+}
+
+func TestRequireCartCaller(t *testing.T) {
+    if err := requireCartCaller(context.Background()); err == nil {
+        t.Error("anonymous caller allowed to use a cart")
+    }
+
+    guest := context.WithValue(context.Background(), GuestTokenContextKey, "token")
+    if err := requireCartCaller(guest); err != nil {
+        t.Errorf("guest refused: %v", err)
+    }
+    if headers := forwardCartHeaders(guest); headers[GuestTokenHeader] != "token" {
+        t.Errorf("guest token not forwarded: %v", headers)
+    }
+
+    user := context.WithValue(context.Background(), UserContextKey, &UserClaims{UserID: "user-1"})
+    if err := requireCartCaller(user); err != nil {
+        t.Errorf("user refused: %v", err)
+    }
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+    "context"
+    "crypto/rsa"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// JWT verification keys
+// Why: tokens carry a kid header so the users service can rotate keys: several keys verify at
+// once, and RS256 public keys can come from the users service's JWKS instead of a shared secret.
+// Same variables as the services (shared/jwtkeys); the gateway only verifies, so it never needs
+// a private key or JWT_SIGNING_KEY_ID.
+
+const (
+    // jwksMinRefetch limits refetches for unknown kids, so forged tokens can't hammer the users service
+    jwksMinRefetch = 30 * time.Second
+    jwksTimeout    = 5 * time.Second
+)
+
+// JWTKeyConfig is where the verification keys come from
+type JWTKeyConfig struct {
+    Secret      string        // JWT_SECRET: legacy HS256 secret for tokens without a kid
+    Keys        string        // JWT_KEYS: kid=HS256:secret;kid=RS256:/path/to/key.pem
+    JWKSURL     string        // JWKS_URL: fetch RS256 public keys from the users service
+    JWKSRefresh time.Duration // JWKS_REFRESH_SECONDS
+}
+
+type jwtKey struct {
+    algorithm string // HS256 or RS256
+    secret    []byte
+    public    *rsa.PublicKey
+}
+
+func (k *jwtKey) verifyKey() interface{} {
+    if k.algorithm == "RS256" {
+        return k.public
+    }
+    return k.secret
+}
+
+// JWTKeySet verifies tokens by their kid
+type JWTKeySet struct {
+    legacy *jwtKey            // tokens without a kid
+    keys   map[string]*jwtKey // by kid
+    jwks   *jwksClient        // nil: local keys only
+}
+
+// NewJWTKeySet builds the key set from config; PEM files are read now
+func NewJWTKeySet(config JWTKeyConfig) (*JWTKeySet, error) {
+    ks := &JWTKeySet{keys: map[string]*jwtKey{}}
+    if config.Secret != "" {
+        ks.legacy = &jwtKey{algorithm: "HS256", secret: []byte(config.Secret)}
+    }
+
+    for _, entry := range strings.Split(config.Keys, ";") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        kid, key, err := parseJWTKey(entry)
+        if err != nil {
+            return nil, err
+        }
+        if _, dup := ks.keys[kid]; dup {
+            return nil, fmt.Errorf("JWT_KEYS: key %q is listed twice", kid)
+        }
+        ks.keys[kid] = key
+    }
+
+    if config.JWKSURL != "" {
+        ks.jwks = newJWKSClient(config.JWKSURL, config.JWKSRefresh)
+    }
+    return ks, nil
+}
+
+// parseJWTKey parses one "kid=ALG:value" entry; value is the secret for HS256 and a PEM file
+// (public or private key) for RS256
+func parseJWTKey(entry string) (string, *jwtKey, error) {
+    kid, spec, ok := strings.Cut(entry, "=")
+    alg, value, hasValue := strings.Cut(spec, ":")
+    kid, alg = strings.TrimSpace(kid), strings.ToUpper(strings.TrimSpace(alg))
+    if !ok || !hasValue || kid == "" || value == "" {
+        return "", nil, fmt.Errorf("JWT_KEYS: %q: want kid=ALG:value", kid)
+    }
+
+    switch alg {
+    case "HS256":
+        return kid, &jwtKey{algorithm: alg, secret: []byte(value)}, nil
+    case "RS256":
+        pem, err := os.ReadFile(strings.TrimSpace(value))
+        if err != nil {
+            return "", nil, fmt.Errorf("JWT_KEYS: %q: %w", kid, err)
+        }
+        if private, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+            return kid, &jwtKey{algorithm: alg, public: &private.PublicKey}, nil
+        }
+        public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+        if err != nil {
+            return "", nil, fmt.Errorf("JWT_KEYS: %q: not an RSA private or public key", kid)
+        }
+        return kid, &jwtKey{algorithm: alg, public: public}, nil
+    default:
+        return "", nil, fmt.Errorf("JWT_KEYS: %q: unsupported algorithm %q (HS256 or RS256)", kid, alg)
+    }
+}
+
+// Configured reports whether any token could be verified
+func (ks *JWTKeySet) Configured() bool {
+    return ks.legacy != nil || len(ks.keys) > 0 || ks.jwks != nil
+}
+
+// keyfunc picks the verification key for a token by its kid. The token's alg must be the
+// key's, so a public RSA key is never used as an HMAC secret.
+func (ks *JWTKeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+    kid, _ := token.Header["kid"].(string)
+
+    var key *jwtKey
+    if kid == "" {
+        key = ks.legacy
+    } else if key = ks.keys[kid]; key == nil && ks.jwks != nil {
+        public, err := ks.jwks.Key(kid)
+        if err != nil {
+            return nil, err
+        }
+        key = &jwtKey{algorithm: "RS256", public: public}
+    }
+    if key == nil {
+        return nil, fmt.Errorf("unknown signing key %q", kid)
+    }
+
+    if token.Method.Alg() != key.algorithm {
+        return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+    }
+    return key.verifyKey(), nil
+}
+
+// jwksClient caches the public keys published at the users service's /.well-known/jwks.json
+type jwksClient struct {
+    url     string
+    refresh time.Duration
+    client  *http.Client
+
+    mu          sync.Mutex
+    keys        map[string]*rsa.PublicKey
+    fetchedAt   time.Time
+    lastAttempt time.Time
+}
+
+func newJWKSClient(url string, refresh time.Duration) *jwksClient {
+    if refresh <= 0 {
+        refresh = 5 * time.Minute
+    }
+    return &jwksClient{
+        url:     url,
+        refresh: refresh,
+        client:  &http.Client{Timeout: jwksTimeout},
+        keys:    map[string]*rsa.PublicKey{},
+    }
+}
+
+// Key returns the public key with the given kid. The JWKS is fetched when the cache is older
+// than the refresh interval, or for an unknown kid (a key just rotated in) at most every 30s.
+// When a refetch fails the cached keys are kept.
+func (c *jwksClient) Key(kid string) (*rsa.PublicKey, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    now := time.Now()
+    key, known := c.keys[kid]
+    stale := now.Sub(c.fetchedAt) >= c.refresh
+    if (stale || !known) && now.Sub(c.lastAttempt) >= jwksMinRefetch {
+        c.lastAttempt = now
+        keys, err := c.fetch()
+        if err != nil && !known {
+            return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+        }
+        if err == nil {
+            c.keys, c.fetchedAt = keys, now
+            key, known = keys[kid]
+        }
+    }
+
+    if !known {
+        return nil, fmt.Errorf("unknown signing key %q", kid)
+    }
+    return key, nil
+}
+
+func (c *jwksClient) fetch() (map[string]*rsa.PublicKey, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := c.client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("status %d", resp.StatusCode)
+    }
+
+    var doc struct {
+        Keys []struct {
+            KeyType   string `json:"kty"`
+            KeyID     string `json:"kid"`
+            Algorithm string `json:"alg"`
+            N         string `json:"n"`
+            E         string `json:"e"`
+        } `json:"keys"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+        return nil, fmt.Errorf("invalid JWKS: %w", err)
+    }
+
+    keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+    for _, jwk := range doc.Keys {
+        if jwk.KeyType != "RSA" || jwk.KeyID == "" || (jwk.Algorithm != "" && jwk.Algorithm != "RS256") {
+            continue
+        }
+        n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+        e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+        exponent := new(big.Int).SetBytes(e)
+        if errN != nil || errE != nil || !exponent.IsInt64() || exponent.Int64() < 3 {
+            continue
+        }
+        keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+    }
+    return keys, nil
+}
@@ -2,11 +2,13 @@ package main
 
 import (
     "context"
+    "encoding/json"
     "fmt"
     "log"
     "net/http"
     "os"
     "os/signal"
+    "strconv"
     "time"
 
     "github.com/gin-gonic/gin"
@@ -18,14 +20,33 @@ type ContextKey string
 
 const UserContextKey ContextKey = "user"
 
+// AuthTokenContextKey holds the caller's validated Authorization header; HTTPClient forwards it on every downstream call
+const AuthTokenContextKey ContextKey = "auth_token"
+
 // Config holds gateway configuration
 type Config struct {
     Port            string
+    Env string
     UsersServiceURL string
     ProductsServiceURL string
     CartServiceURL string
     OrdersServiceURL string
-    JWTSecret string
+    JWTKeys JWTKeyConfig
+    RateLimit RateLimitConfig
+    RequestLog RequestLogConfig
+    ClaimCache ClaimCacheConfig
+    PersistedQueries PersistedQueryConfig
+    QueryLimits QueryLimitsConfig
+    CatalogCache CatalogCacheConfig
+    CatalogWarmup CatalogWarmupConfig
+    RecentlyViewed RecentlyViewedConfig
+    SchemaFeatures SchemaFeatures
+    ServerLimits ServerLimitsConfig
+    CORS CORSConfig
+    Compression CompressionConfig
+    Downstream DownstreamConfig
+    GuestTokens GuestTokenConfig
+    RBAC *RBACPolicy
 }
 
 // Gateway represents the API gateway
@@ -34,33 +55,164 @@ type Gateway struct {
     router *gin.Engine
     httpClient *HTTPClient
     tokenValidator *TokenValidator
+    claimCache *ClaimCache
+    rateLimiter *RateLimiter
+    persistedQueries *PersistedQueries
+    catalogCache *CatalogCache
+    catalogWarmup *CatalogWarmup
+    announcements *AnnouncementHub
+    recentlyViewed *RecentlyViewed
+    guestTokens *GuestTokens
 }
 
 // NewGateway creates a new gateway instance
 func NewGateway(config *Config) *Gateway {
+    claimCache := newClaimCache(config.ClaimCache)
+
     return &Gateway{
         config: config,
         router: gin.Default(),
-        httpClient: NewHTTPClient(),
-        tokenValidator: NewTokenValidator(config.JWTSecret),
+        httpClient: NewHTTPClient(config.Downstream),
+        tokenValidator: NewTokenValidator(newJWTKeySet(config.JWTKeys), claimCache),
+        claimCache: claimCache,
+        rateLimiter: NewRateLimiter(config.RateLimit),
+        persistedQueries: newPersistedQueries(config.PersistedQueries),
+        catalogCache: newCatalogCache(config.CatalogCache),
+        announcements: NewAnnouncementHub(),
+        recentlyViewed: newRecentlyViewed(config.RecentlyViewed),
+        guestTokens: newGuestTokens(config.GuestTokens),
+    }
+}
+
+// newGuestTokens builds the guest token issuer; nil without GUEST_TOKEN_SECRET
+func newGuestTokens(config GuestTokenConfig) *GuestTokens {
+    guests := NewGuestTokens(config)
+    if guests == nil {
+        log.Println("⚠️  GUEST_TOKEN_SECRET not set, guest carts disabled")
+    }
+    return guests
+}
+
+// newJWTKeySet builds the token verification keys; a broken key configuration stops the gateway
+func newJWTKeySet(config JWTKeyConfig) *JWTKeySet {
+    keys, err := NewJWTKeySet(config)
+    if err != nil {
+        log.Fatalf("Invalid JWT key configuration: %v", err)
+    }
+    if !keys.Configured() {
+        log.Println("⚠️  JWT_SECRET, JWT_KEYS and JWKS_URL not set, every token will be rejected")
+    }
+    return keys
+}
+
+// newClaimCache builds the validated-claims cache, shared through Redis when configured.
+// Without Redis the cache and revocations are local to this instance.
+func newClaimCache(config ClaimCacheConfig) *ClaimCache {
+    if !config.Enabled {
+        log.Println("⚠️  Token claim cache disabled")
+        return nil
+    }
+
+    var shared SharedClaimStore
+    if config.RedisURL != "" {
+        store, err := NewRedisClaimStore(config.RedisURL)
+        if err != nil {
+            log.Printf("⚠️  Redis unavailable, token claim cache is local only: %v", err)
+        } else {
+            shared = store
+            log.Println("✓ Token claim cache shared via Redis")
+        }
+    }
+
+    return NewClaimCache(config, shared)
+}
+
+// newRecentlyViewed builds the view tracker, shared through Redis when configured.
+// Returns nil when disabled; recordProductView then returns false and recentlyViewed is empty.
+func newRecentlyViewed(config RecentlyViewedConfig) *RecentlyViewed {
+    if !config.Enabled {
+        log.Println("⚠️  Recently viewed products disabled")
+        return nil
+    }
+
+    var shared SharedViewStore
+    if config.RedisURL != "" {
+        store, err := NewRedisViewStore(config.RedisURL)
+        if err != nil {
+            log.Printf("⚠️  Redis unavailable, recently viewed products are local only: %v", err)
+        } else {
+            shared = store
+            log.Println("✓ Recently viewed products shared via Redis")
+        }
+    }
+
+    return NewRecentlyViewed(config, shared)
+}
+
+// newPersistedQueries builds the APQ store, shared through Redis when configured.
+// Returns nil when APQ is disabled; hash-only requests then get PERSISTED_QUERY_NOT_SUPPORTED.
+func newPersistedQueries(config PersistedQueryConfig) *PersistedQueries {
+    if !config.Enabled {
+        log.Println("⚠️  Persisted queries disabled")
+        return nil
+    }
+
+    var shared SharedQueryStore
+    if config.RedisURL != "" {
+        store, err := NewRedisQueryStore(config.RedisURL)
+        if err != nil {
+            log.Printf("⚠️  Redis unavailable, persisted queries are local only: %v", err)
+        } else {
+            shared = store
+            log.Println("✓ Persisted queries shared via Redis")
+        }
+    }
+
+    return NewPersistedQueries(config, shared)
+}
+
+// newCatalogCache builds the catalog response cache, shared through Redis when configured.
+// Returns nil when the cache is disabled.
+func newCatalogCache(config CatalogCacheConfig) *CatalogCache {
+    if !config.Enabled {
+        log.Println("⚠️  Catalog cache disabled")
+        return nil
+    }
+
+    var shared SharedCatalogStore
+    if config.RedisURL != "" {
+        store, err := NewRedisCatalogStore(config.RedisURL)
+        if err != nil {
+            log.Printf("⚠️  Redis unavailable, catalog cache is local only: %v", err)
+        } else {
+            shared = store
+            log.Println("✓ Catalog cache shared via Redis")
+        }
     }
+
+    return NewCatalogCache(config, shared)
 }
 
 // setupRoutes configures all gateway routes
 func (g *Gateway) setupRoutes() {
     // CORS middleware
-    g.router.Use(corsMiddleware())
+    g.router.Use(corsMiddleware(g.config.CORS))
+
+    // Response compression; see compression.go
+    g.router.Use(compressionMiddleware(g.config.Compression))
 
     // Build GraphQL schema
-    // schema := BuildSchema(g.httpClient, g.config)
-    schema := BuildSchema()
+    schema := BuildSchema(g.config.SchemaFeatures)
 
     // Create service clients
     userService := NewUserService(g.config.UsersServiceURL, g.httpClient)
-    productService := NewProductService(g.config.ProductsServiceURL, g.httpClient)
+    productService := NewProductService(g.config.ProductsServiceURL, g.httpClient, g.catalogCache)
     cartService := NewCartService(g.config.CartServiceURL, g.httpClient)
     orderService := NewOrderService(g.config.OrdersServiceURL, g.httpClient)
 
+    // Fills the catalog cache before /ready passes (started in Run)
+    g.catalogWarmup = NewCatalogWarmup(g.config.CatalogWarmup, productService)
+
     // Create resolver context
     resolverCtx := &ResolverContext{
         UserService:    userService,
@@ -68,13 +220,25 @@ func (g *Gateway) setupRoutes() {
         CartService:    cartService,
         OrderService:   orderService,
         TokenValidator: g.tokenValidator,
+        Announcements:  g.announcements,
+        RecentlyViewed: g.recentlyViewed,
+        GuestTokens:    g.guestTokens,
     }
 
     // Attach resolvers to schema
     AttachResolvers(schema, resolverCtx)
 
+    // Role checks on the fields listed in the RBAC policy
+    g.config.RBAC.authorizeFields(schema, g.config.SchemaFeatures)
+
+    // Structured operation logging (sensitive arguments read from the schema)
+    requestLogger := NewRequestLogger(g.config.RequestLog, schema)
+
     // GraphQL endpoint
-    g.router.POST("/graphql", authMiddleware(g.tokenValidator), func(c *gin.Context) {
+    limits := g.config.ServerLimits
+    limits.checkBudgets(g.config.Downstream)
+
+    g.router.POST("/graphql", requestIDMiddleware(), limits.limitsMiddleware("POST /graphql"), authMiddleware(g.tokenValidator), guestMiddleware(g.guestTokens), persistedQueryMiddleware(g.persistedQueries), rateLimitMiddleware(g.rateLimiter, limits.Limits("POST /graphql").MaxBodyBytes), func(c *gin.Context) {
         var query GraphQLQuery
 
         // Parse the JSON request body
@@ -87,6 +251,10 @@ func (g *Gateway) setupRoutes() {
         ctx := c.Request.Context()
         if val, ok := c.Get("user"); ok {
             ctx = context.WithValue(ctx, UserContextKey, val)
+            ctx = context.WithValue(ctx, AuthTokenContextKey, c.GetHeader("Authorization"))
+        }
+        if token := c.GetString("guest_token"); token != "" {
+            ctx = context.WithValue(ctx, GuestTokenContextKey, token)
         }
 
         // Create context with user claims
@@ -96,30 +264,78 @@ func (g *Gateway) setupRoutes() {
         // }
 
         // Execute query
-        result := ExecuteQuery(query.Query, query.Variables, schema, ctx)
+        start := time.Now()
+        result := ExecuteQuery(query.Query, query.Variables, schema, g.config.QueryLimits, ctx)
+        requestLogger.Log(ctx, query, callerFor(c), time.Since(start), result)
 
         c.JSON(http.StatusOK, FormatResult(result))
     })
 
-    // GraphQL introspection query 
-	g.router.GET("/graphql", func(c *gin.Context) {
-		queryStr := c.Query("query")
-		if queryStr == "" {
+    // GraphQL queries over GET; GraphiQL for browsers in dev
+	g.router.GET("/graphql", requestIDMiddleware(), limits.limitsMiddleware("GET /graphql"), rateLimitMiddleware(g.rateLimiter, limits.Limits("GET /graphql").MaxBodyBytes), etagMiddleware(g.config.Compression), func(c *gin.Context) {
+		if g.config.Env == "dev" && wantsPlayground(c) {
+			playgroundHandler(c)
+			return
+		}
+
+		query := GraphQLQuery{Query: c.Query("query"), OperationName: c.Query("operationName")}
+
+		// APQ over GET: ?extensions={"persistedQuery":{...}} lets CDNs cache by hash
+		if raw := c.Query("extensions"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &query.Extensions); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid extensions parameter"})
+				return
+			}
+			if raw := c.Query("variables"); raw != "" {
+				if err := json.Unmarshal([]byte(raw), &query.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variables parameter"})
+					return
+				}
+			}
+			if code, message := g.persistedQueries.Resolve(c.Request.Context(), &query); code != "" {
+				abortPersistedQuery(c, code, message)
+				return
+			}
+		}
+
+		if query.Query == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter required"})
 			return
 		}
 
-		result := ExecuteQuery(queryStr, nil, schema, c.Request.Context())
+		// A link or <img> could trigger a mutation over GET, so they must use POST
+		if isMutation(query.Query, query.OperationName) {
+			c.Header("Allow", http.MethodPost)
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "mutations must use POST"})
+			return
+		}
+
+		start := time.Now()
+		result := ExecuteQuery(query.Query, query.Variables, schema, g.config.QueryLimits, c.Request.Context())
+		requestLogger.Log(c.Request.Context(), query, callerFor(c), time.Since(start), result)
 		c.JSON(http.StatusOK, FormatResult(result))
 	})
 
     
+    // GraphQL subscriptions (server-sent events); long-lived, so no route limits
+    g.router.GET("/graphql/subscriptions", requestIDMiddleware(), subscriptionHandler(schema, g.config.QueryLimits))
+
     // Health check
-    g.router.GET("/health", func(c *gin.Context) {
+    g.router.GET("/health", limits.limitsMiddleware("GET /health"), func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"status": "healthy"})
     })
 
+    // Readiness: fails while the catalog cache is warming
+    g.router.GET("/ready", limits.limitsMiddleware("GET /ready"), g.catalogWarmup.readyHandler)
+
     
+    if g.config.Env == "dev" {
+        log.Println("✓ GraphiQL at GET /graphql")
+        if g.config.QueryLimits.NoIntrospection {
+            log.Println("⚠️  Introspection disabled, GraphiQL has no schema docs")
+        }
+    }
+
     log.Println("✓ Routes configured")
 }
 
@@ -127,13 +343,37 @@ func (g *Gateway) setupRoutes() {
 func (g *Gateway) Run() error {
     g.setupRoutes()
 
+    // Receive logouts from other gateway instances
+    cacheCtx, stopCache := context.WithCancel(context.Background())
+    defer stopCache()
+    if g.claimCache != nil {
+        g.claimCache.Start(cacheCtx)
+    }
+
+    // Drop cached catalog responses when products change
+    if g.catalogCache != nil {
+        if g.config.CatalogCache.RabbitMQURL != "" {
+            go ConsumeCatalogEvents(cacheCtx, g.config.CatalogCache.RabbitMQURL, g.catalogCache)
+        } else {
+            log.Println("⚠️  RABBITMQ_URL not set, catalog cache relies on its TTL alone")
+        }
+    }
+    g.catalogWarmup.Start(cacheCtx)
+
+    // Live announcements for subscriptions
+    if g.config.CatalogCache.RabbitMQURL != "" {
+        go ConsumeAnnouncementEvents(cacheCtx, g.config.CatalogCache.RabbitMQURL, g.announcements)
+    } else {
+        log.Println("⚠️  RABBITMQ_URL not set, announcementPublished subscriptions receive nothing")
+    }
+
     // Create HTTP server with graceful shutdown
     server := &http.Server{
         Addr:    ":" + g.config.Port,
         Handler: g.router,
-        ReadTimeout:  15 * time.Second,
-        WriteTimeout: 30 * time.Second,
-        IdleTimeout:  120 * time.Second,
+        ReadTimeout:  g.config.ServerLimits.ReadTimeout,
+        WriteTimeout: g.config.ServerLimits.WriteTimeout + writeGrace,
+        IdleTimeout:  g.config.ServerLimits.IdleTimeout,
     }
 
     // Start server in background
@@ -177,32 +417,159 @@ func loadConfig() *Config {
         log.Println("Using default port for gateway")
     }
 
+    serviceURLs := map[string]string{
+        "USERS": os.Getenv("USERS_SERVICE_URL"),
+        "PRODUCTS": os.Getenv("PRODUCTS_SERVICE_URL"),
+        "ORDERS": os.Getenv("ORDERS_SERVICE_URL"),
+        "CART": os.Getenv("CART_SERVICE_URL"),
+    }
+
+    // dev serves GraphiQL and allows introspection by default; unset means production
+    env := os.Getenv("GATEWAY_ENV")
+    if env == "" {
+        env = "production"
+    }
+
     return &Config{
         Port: port,
-        UsersServiceURL: os.Getenv("USERS_SERVICE_URL"),
-        ProductsServiceURL: os.Getenv("PRODUCTS_SERVICE_URL"),
-        OrdersServiceURL: os.Getenv("ORDERS_SERVICE_URL"),
-        CartServiceURL: os.Getenv("CART_SERVICE_URL"),
-
-        JWTSecret: os.Getenv("JWT_SECRET"),
+        Env: env,
+        UsersServiceURL: serviceURLs["USERS"],
+        ProductsServiceURL: serviceURLs["PRODUCTS"],
+        OrdersServiceURL: serviceURLs["ORDERS"],
+        CartServiceURL: serviceURLs["CART"],
+
+        // Token verification keys; see jwtkeys.go
+        JWTKeys: JWTKeyConfig{
+            Secret: os.Getenv("JWT_SECRET"),
+            Keys: os.Getenv("JWT_KEYS"),
+            JWKSURL: os.Getenv("JWKS_URL"),
+            JWKSRefresh: time.Duration(getEnvInt("JWKS_REFRESH_SECONDS", 300)) * time.Second,
+        },
+
+        // Rate limits: per user (JWT) or per client IP
+        RateLimit: RateLimitConfig{
+            Enabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+            QueryRate: getEnvFloat("RATE_LIMIT_QUERY_RPS", 10),
+            QueryBurst: getEnvInt("RATE_LIMIT_QUERY_BURST", 20),
+            MutationRate: getEnvFloat("RATE_LIMIT_MUTATION_RPS", 2),
+            MutationBurst: getEnvInt("RATE_LIMIT_MUTATION_BURST", 5),
+        },
+
+        // GraphQL operation logs; failed operations are always logged
+        RequestLog: RequestLogConfig{
+            Enabled: getEnvBool("GRAPHQL_LOG_ENABLED", true),
+            SampleRate: getEnvFloat("GRAPHQL_LOG_SAMPLE_RATE", 1.0),
+        },
+
+        // Validated JWT claims cache; logout revocations are shared through Redis
+        ClaimCache: ClaimCacheConfig{
+            Enabled: getEnvBool("TOKEN_CACHE_ENABLED", true),
+            TTL: time.Duration(getEnvInt("TOKEN_CACHE_TTL_SECONDS", 30)) * time.Second,
+            MaxEntries: getEnvInt("TOKEN_CACHE_MAX_ENTRIES", 10000),
+            RedisURL: os.Getenv("REDIS_URL"),
+        },
+
+        // Automatic persisted queries; query texts are shared through Redis
+        PersistedQueries: PersistedQueryConfig{
+            Enabled: getEnvBool("APQ_ENABLED", true),
+            MaxEntries: getEnvInt("APQ_MAX_ENTRIES", 1000),
+            MaxQueryBytes: getEnvInt("APQ_MAX_QUERY_BYTES", 64*1024),
+            TTL: time.Duration(getEnvInt("APQ_TTL_HOURS", 24)) * time.Hour,
+            RedisURL: os.Getenv("REDIS_URL"),
+        },
+
+        // Query depth/complexity budget, checked before any resolver runs; 0 disables a check
+        QueryLimits: QueryLimitsConfig{
+            MaxDepth: getEnvInt("GRAPHQL_MAX_DEPTH", 8),
+            MaxComplexity: getEnvInt("GRAPHQL_MAX_COMPLEXITY", 1000),
+            DefaultListSize: getEnvInt("GRAPHQL_DEFAULT_LIST_SIZE", 10),
+            NoIntrospection: !getEnvBool("GRAPHQL_INTROSPECTION_ENABLED", env == "dev"),
+        },
+
+        // Products/categories response cache, invalidated by product events
+        CatalogCache: CatalogCacheConfig{
+            Enabled: getEnvBool("CATALOG_CACHE_ENABLED", true),
+            TTL: time.Duration(getEnvInt("CATALOG_CACHE_TTL_SECONDS", 60)) * time.Second,
+            MaxEntries: getEnvInt("CATALOG_CACHE_MAX_ENTRIES", 1000),
+            RedisURL: os.Getenv("REDIS_URL"),
+            RabbitMQURL: os.Getenv("RABBITMQ_URL"),
+        },
+
+        // Optional startup warm-up of the catalog cache, gating /ready
+        CatalogWarmup: CatalogWarmupConfig{
+            Enabled: getEnvBool("CATALOG_WARMUP_ENABLED", false),
+            TopProducts: getEnvInt("CATALOG_WARMUP_TOP_PRODUCTS", 50),
+            Timeout: time.Duration(getEnvInt("CATALOG_WARMUP_TIMEOUT_SECONDS", 30)) * time.Second,
+        },
+
+        // Per-user recently viewed products, kept in Redis lists
+        RecentlyViewed: RecentlyViewedConfig{
+            Enabled: getEnvBool("RECENTLY_VIEWED_ENABLED", true),
+            Size: getEnvInt("RECENTLY_VIEWED_SIZE", 20),
+            TTL: time.Duration(getEnvInt("RECENTLY_VIEWED_TTL_DAYS", 30)) * 24 * time.Hour,
+            MaxUsers: getEnvInt("RECENTLY_VIEWED_MAX_USERS", 10000),
+            RedisURL: os.Getenv("REDIS_URL"),
+        },
+
+        // Optional schema sections, for serving different product tiers from one binary
+        SchemaFeatures: SchemaFeatures{
+            Reviews: getEnvBool("SCHEMA_REVIEWS_ENABLED", true),
+            AdminQueries: getEnvBool("SCHEMA_ADMIN_QUERIES_ENABLED", true),
+            AdminMutations: getEnvBool("SCHEMA_ADMIN_MUTATIONS_ENABLED", true),
+        },
+
+        // Server timeouts and body size (per-route overrides), and per-service call timeouts
+        ServerLimits: loadServerLimits(),
+        Downstream: loadDownstream(serviceURLs),
+
+        // Allowed browser origins; see cors.go
+        CORS: loadCORSConfig(),
+
+        // Response compression and ETags on GET /graphql; see compression.go
+        Compression: loadCompressionConfig(),
+
+        // Guest tokens for carts before login; see guest.go
+        GuestTokens: GuestTokenConfig{
+            Secret: os.Getenv("GUEST_TOKEN_SECRET"),
+            TTL: time.Duration(getEnvInt("GUEST_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour,
+        },
+
+        // Roles for the GraphQL fields listed in the shared RBAC policy; see rbac.go
+        RBAC: loadRBACPolicy(),
     }
 }
 
-// corsMiddleware adds CORS headers
-func corsMiddleware() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-        c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-        c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+// getEnvInt reads an int env var, falling back to the default
+func getEnvInt(key string, fallback int) int {
+    if val := os.Getenv(key); val != "" {
+        if parsed, err := strconv.Atoi(val); err == nil {
+            return parsed
+        }
+        log.Printf("⚠️  Invalid value for %s, using default %d", key, fallback)
+    }
+    return fallback
+}
 
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(http.StatusNoContent)
-            return
+// getEnvFloat reads a float env var, falling back to the default
+func getEnvFloat(key string, fallback float64) float64 {
+    if val := os.Getenv(key); val != "" {
+        if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+            return parsed
         }
+        log.Printf("⚠️  Invalid value for %s, using default %v", key, fallback)
+    }
+    return fallback
+}
 
-        c.Next()
+// getEnvBool reads a bool env var, falling back to the default
+func getEnvBool(key string, fallback bool) bool {
+    if val := os.Getenv(key); val != "" {
+        if parsed, err := strconv.ParseBool(val); err == nil {
+            return parsed
+        }
+        log.Printf("⚠️  Invalid value for %s, using default %v", key, fallback)
     }
+    return fallback
 }
 
 // authMiddleware validates JWT token and extracts user claims
@@ -215,7 +582,7 @@ func authMiddleware(validator *TokenValidator) gin.HandlerFunc {
             return
         }
 
-        claims, err := validator.ValidateToken(authHeader)
+        claims, err := validator.ValidateToken(c.Request.Context(), authHeader)
         if err != nil {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
             c.Abort()
@@ -0,0 +1,305 @@
+package main
+
+import (
+    "bytes"
+    "container/list"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "regexp"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/redis/go-redis/v9"
+)
+
+// Automatic Persisted Queries (APQ)
+// Why: mobile clients send the same large queries over and over. With APQ they send
+// only the query's SHA-256; the full text is sent once, after a PERSISTED_QUERY_NOT_FOUND,
+// and kept here (locally and optionally in Redis) for later requests.
+
+// APQ error codes returned in errors[].extensions.code (Apollo protocol)
+const (
+    PersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+    PersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+    PersistedQueryInvalid      = "PERSISTED_QUERY_INVALID"
+)
+
+// persistedQueryKeyPrefix prefixes query texts in Redis
+const persistedQueryKeyPrefix = "gateway:apq:"
+
+// sha256HashPattern matches a lowercase hex SHA-256
+var sha256HashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// QueryExtensions is the "extensions" object of a GraphQL request
+type QueryExtensions struct {
+    PersistedQuery *PersistedQueryExtension `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQueryExtension identifies a query by hash
+type PersistedQueryExtension struct {
+    Version    int    `json:"version"`
+    Sha256Hash string `json:"sha256Hash"`
+}
+
+// PersistedQueryConfig controls APQ
+type PersistedQueryConfig struct {
+    Enabled       bool
+    MaxEntries    int           // local LRU size
+    MaxQueryBytes int           // larger queries are executed but not stored
+    TTL           time.Duration // expiry in Redis; local entries only leave by eviction
+    RedisURL      string        // shared tier; empty keeps queries local
+}
+
+// SharedQueryStore is the cross-instance tier of the persisted query store
+type SharedQueryStore interface {
+    // Get returns the query text and whether it was found
+    Get(ctx context.Context, hash string) (string, bool, error)
+    Put(ctx context.Context, hash, query string, ttl time.Duration) error
+}
+
+// PersistedQueries stores query texts by hash in an LRU backed by an optional shared store
+type PersistedQueries struct {
+    config PersistedQueryConfig
+    shared SharedQueryStore // nil when running without Redis
+
+    mu      sync.Mutex
+    order   *list.List // front = most recently used
+    entries map[string]*list.Element
+}
+
+// persistedEntry is one LRU entry
+type persistedEntry struct {
+    hash  string
+    query string
+}
+
+// NewPersistedQueries creates a persisted query store; shared may be nil
+func NewPersistedQueries(config PersistedQueryConfig, shared SharedQueryStore) *PersistedQueries {
+    return &PersistedQueries{
+        config:  config,
+        shared:  shared,
+        order:   list.New(),
+        entries: make(map[string]*list.Element),
+    }
+}
+
+// Get returns the query stored under hash, checking the shared store on a local miss
+func (pq *PersistedQueries) Get(ctx context.Context, hash string) (string, bool) {
+    pq.mu.Lock()
+    if elem, ok := pq.entries[hash]; ok {
+        pq.order.MoveToFront(elem)
+        query := elem.Value.(*persistedEntry).query
+        pq.mu.Unlock()
+        return query, true
+    }
+    pq.mu.Unlock()
+
+    if pq.shared == nil {
+        return "", false
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    query, found, err := pq.shared.Get(ctx, hash)
+    if err != nil {
+        // Redis down: the client gets NOT_FOUND and resends the full query
+        log.Printf("⚠️  Persisted query lookup failed: %v", err)
+        return "", false
+    }
+    if !found || persistedQueryHash(query) != hash {
+        return "", false
+    }
+
+    pq.putLocal(hash, query)
+    return query, true
+}
+
+// Put stores a query whose hash was already verified
+func (pq *PersistedQueries) Put(ctx context.Context, hash, query string) {
+    if pq.config.MaxQueryBytes > 0 && len(query) > pq.config.MaxQueryBytes {
+        return
+    }
+    pq.putLocal(hash, query)
+
+    if pq.shared == nil {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedStoreTimeout)
+    defer cancel()
+
+    if err := pq.shared.Put(ctx, hash, query, pq.config.TTL); err != nil {
+        log.Printf("⚠️  Persisted query store failed: %v", err)
+    }
+}
+
+func (pq *PersistedQueries) putLocal(hash, query string) {
+    pq.mu.Lock()
+    defer pq.mu.Unlock()
+
+    if elem, ok := pq.entries[hash]; ok {
+        pq.order.MoveToFront(elem)
+        return
+    }
+
+    pq.entries[hash] = pq.order.PushFront(&persistedEntry{hash: hash, query: query})
+    for pq.order.Len() > pq.config.MaxEntries {
+        oldest := pq.order.Back()
+        pq.order.Remove(oldest)
+        delete(pq.entries, oldest.Value.(*persistedEntry).hash)
+    }
+}
+
+// persistedQueryHash is the hex SHA-256 clients use to identify a query
+func persistedQueryHash(query string) string {
+    sum := sha256.Sum256([]byte(query))
+    return hex.EncodeToString(sum[:])
+}
+
+// Resolve fills in query.Query for hash-only requests and stores new queries.
+// It returns an APQ error code ("" on success) and a message for the client.
+func (pq *PersistedQueries) Resolve(ctx context.Context, query *GraphQLQuery) (string, string) {
+    if query.Extensions == nil || query.Extensions.PersistedQuery == nil {
+        return "", ""
+    }
+    ext := query.Extensions.PersistedQuery
+
+    if pq == nil {
+        if query.Query == "" {
+            return PersistedQueryNotSupported, "PersistedQueryNotSupported"
+        }
+        // Plain query with a hash attached: just run it
+        return "", ""
+    }
+
+    if ext.Version != 1 || !sha256HashPattern.MatchString(ext.Sha256Hash) {
+        return PersistedQueryInvalid, "unsupported persisted query version or malformed sha256Hash"
+    }
+
+    if query.Query == "" {
+        stored, ok := pq.Get(ctx, ext.Sha256Hash)
+        if !ok {
+            return PersistedQueryNotFound, "PersistedQueryNotFound"
+        }
+        query.Query = stored
+        return "", ""
+    }
+
+    // Registration: the hash must be the query's, or one client could poison another's queries
+    if persistedQueryHash(query.Query) != ext.Sha256Hash {
+        return PersistedQueryInvalid, "provided sha256Hash does not match query"
+    }
+    pq.Put(ctx, ext.Sha256Hash, query.Query)
+    return "", ""
+}
+
+// persistedQueryMiddleware resolves APQ hashes in POST bodies before rate limiting and execution.
+// The body is rewritten with the full query so later middleware and the handler see it.
+func persistedQueryMiddleware(store *PersistedQueries) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if c.Request.Body == nil {
+            c.Next()
+            return
+        }
+
+        body, err := io.ReadAll(c.Request.Body)
+        c.Request.Body = io.NopCloser(bytes.NewReader(body))
+        if err != nil {
+            c.Next()
+            return
+        }
+
+        var query GraphQLQuery
+        if err := json.Unmarshal(body, &query); err != nil || query.Extensions == nil || query.Extensions.PersistedQuery == nil {
+            // Not an APQ request; the handler reports malformed bodies
+            c.Next()
+            return
+        }
+
+        registering := query.Query != ""
+        if code, message := store.Resolve(c.Request.Context(), &query); code != "" {
+            abortPersistedQuery(c, code, message)
+            return
+        }
+        if registering {
+            c.Next()
+            return
+        }
+
+        resolved, err := json.Marshal(query)
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve persisted query"})
+            return
+        }
+        c.Request.Body = io.NopCloser(bytes.NewReader(resolved))
+        c.Request.ContentLength = int64(len(resolved))
+
+        c.Next()
+    }
+}
+
+// abortPersistedQuery answers with a GraphQL error carrying the APQ code.
+// Why: Apollo clients look for the code in a 200 response and retry with the full query.
+func abortPersistedQuery(c *gin.Context, code, message string) {
+    status := http.StatusOK
+    if code == PersistedQueryInvalid {
+        status = http.StatusBadRequest
+    }
+    c.AbortWithStatusJSON(status, gin.H{
+        "errors": []map[string]interface{}{
+            {
+                "message":    message,
+                "extensions": map[string]interface{}{"code": code},
+            },
+        },
+    })
+}
+
+// redisQueryStore is the Redis-backed SharedQueryStore
+type redisQueryStore struct {
+    client *redis.Client
+}
+
+// NewRedisQueryStore connects to Redis and returns a shared persisted query store
+func NewRedisQueryStore(redisURL string) (SharedQueryStore, error) {
+    opts, err := redis.ParseURL(redisURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse redis url: %w", err)
+    }
+
+    client := redis.NewClient(opts)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, fmt.Errorf("failed to connect to redis: %w", err)
+    }
+
+    return &redisQueryStore{client: client}, nil
+}
+
+func (rs *redisQueryStore) Get(ctx context.Context, hash string) (string, bool, error) {
+    query, err := rs.client.Get(ctx, persistedQueryKeyPrefix+hash).Result()
+    if errors.Is(err, redis.Nil) {
+        return "", false, nil
+    }
+    if err != nil {
+        return "", false, err
+    }
+    return query, true, nil
+}
+
+func (rs *redisQueryStore) Put(ctx context.Context, hash, query string, ttl time.Duration) error {
+    return rs.client.Set(ctx, persistedQueryKeyPrefix+hash, query, ttl).Err()
+}
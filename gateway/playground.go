@@ -0,0 +1,100 @@
+package main
+
+import (
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/graphql-go/graphql/gqlerrors"
+    "github.com/graphql-go/graphql/language/ast"
+)
+
+// GraphiQL playground and introspection toggle
+// Why: GET /graphql used to run any query from the query string, including a full schema dump.
+// In dev a browser opening /graphql gets GraphiQL instead; elsewhere introspection can be turned
+// off so the schema isn't handed to anyone who asks. GET only runs queries, never mutations.
+
+// IntrospectionDisabled is returned in errors[].extensions.code when introspection is off
+const IntrospectionDisabled = "INTROSPECTION_DISABLED"
+
+// checkIntrospection rejects documents that select __schema or __type anywhere, fragments included.
+// __typename stays allowed: clients need it for unions and caching.
+func checkIntrospection(doc *ast.Document) *gqlerrors.FormattedError {
+    for _, def := range doc.Definitions {
+        var set *ast.SelectionSet
+        switch def := def.(type) {
+        case *ast.OperationDefinition:
+            set = def.SelectionSet
+        case *ast.FragmentDefinition:
+            set = def.SelectionSet
+        }
+
+        if field := findIntrospectionField(set); field != "" {
+            return &gqlerrors.FormattedError{
+                Message:    "introspection is disabled: " + field + " is not allowed",
+                Extensions: map[string]interface{}{"code": IntrospectionDisabled},
+            }
+        }
+    }
+    return nil
+}
+
+func findIntrospectionField(set *ast.SelectionSet) string {
+    if set == nil {
+        return ""
+    }
+
+    for _, sel := range set.Selections {
+        var nested *ast.SelectionSet
+        switch sel := sel.(type) {
+        case *ast.Field:
+            if sel.Name != nil && (sel.Name.Value == "__schema" || sel.Name.Value == "__type") {
+                return sel.Name.Value
+            }
+            nested = sel.SelectionSet
+        case *ast.InlineFragment:
+            nested = sel.SelectionSet
+        }
+
+        if field := findIntrospectionField(nested); field != "" {
+            return field
+        }
+    }
+    return ""
+}
+
+// wantsPlayground reports whether a GET /graphql is a browser opening the page, not a query
+func wantsPlayground(c *gin.Context) bool {
+    return c.Query("query") == "" && c.Query("extensions") == "" &&
+        strings.Contains(c.GetHeader("Accept"), "text/html")
+}
+
+// playgroundHandler serves GraphiQL; queries go to POST /graphql, so auth and limits apply as usual
+func playgroundHandler(c *gin.Context) {
+    c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(graphiQLPage))
+}
+
+// graphiQLPage loads GraphiQL from a CDN, so the gateway binary doesn't carry its assets.
+// An Authorization header can be set in the "Headers" tab.
+const graphiQLPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Prost GraphiQL</title>
+  <style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css" />
+</head>
+<body>
+  <div id="graphiql">Loading…</div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: window.location.pathname });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(
+      React.createElement(GraphiQL, { fetcher, defaultEditorToolsVisibility: true })
+    );
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,262 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/graphql-go/graphql"
+    "github.com/graphql-go/graphql/gqlerrors"
+    "github.com/graphql-go/graphql/language/ast"
+)
+
+// Query depth and complexity limits
+// Why: every object field can be a downstream call, and list fields multiply whatever is
+// nested under them. A deeply nested or wide query is scored from the parsed document and
+// rejected before any resolver runs.
+//
+// Scoring: leaf fields are free. A field with a selection set costs size * (1 + cost of its
+// selections), where size is 1 for single objects and the `limit`/`first` argument (or
+// DefaultListSize) for lists. A connection (connections.go) is sized by its `first` argument
+// and its edges list counts once. Introspection fields (__schema, __type) are not counted.
+
+// Query limit error codes returned in errors[].extensions.code
+const (
+    QueryTooDeep    = "QUERY_TOO_DEEP"
+    QueryTooComplex = "QUERY_TOO_COMPLEX"
+)
+
+// QueryLimitsConfig bounds the shape of GraphQL operations
+type QueryLimitsConfig struct {
+    MaxDepth        int // deepest field nesting allowed; 0 disables the check
+    MaxComplexity   int // highest score allowed; 0 disables the check
+    DefaultListSize int // assumed length of list fields without a limit argument
+    NoIntrospection bool // reject __schema and __type (see playground.go)
+}
+
+// queryCost is the score of one operation
+type queryCost struct {
+    Depth      int
+    Complexity int
+}
+
+// checkQueryLimits scores each operation in doc and returns an error for the first one over budget
+func checkQueryLimits(schema *graphql.Schema, doc *ast.Document, variables map[string]interface{}, limits QueryLimitsConfig) *gqlerrors.FormattedError {
+    if limits.NoIntrospection {
+        if err := checkIntrospection(doc); err != nil {
+            return err
+        }
+    }
+
+    if limits.MaxDepth <= 0 && limits.MaxComplexity <= 0 {
+        return nil
+    }
+
+    scorer := newQueryScorer(schema, doc, variables, limits.DefaultListSize)
+    for _, def := range doc.Definitions {
+        op, ok := def.(*ast.OperationDefinition)
+        if !ok {
+            continue
+        }
+
+        cost := scorer.operationCost(op)
+        if limits.MaxDepth > 0 && cost.Depth > limits.MaxDepth {
+            return queryLimitError(QueryTooDeep,
+                fmt.Sprintf("query depth %d exceeds the maximum of %d", cost.Depth, limits.MaxDepth),
+                cost.Depth, limits.MaxDepth)
+        }
+        if limits.MaxComplexity > 0 && cost.Complexity > limits.MaxComplexity {
+            return queryLimitError(QueryTooComplex,
+                fmt.Sprintf("query complexity %d exceeds the maximum of %d", cost.Complexity, limits.MaxComplexity),
+                cost.Complexity, limits.MaxComplexity)
+        }
+    }
+
+    return nil
+}
+
+func queryLimitError(code, message string, actual, max int) *gqlerrors.FormattedError {
+    return &gqlerrors.FormattedError{
+        Message: message,
+        Extensions: map[string]interface{}{
+            "code":   code,
+            "actual": actual,
+            "max":    max,
+        },
+    }
+}
+
+// queryScorer computes queryCost for operations of one document
+type queryScorer struct {
+    schema    *graphql.Schema
+    fragments map[string]*ast.FragmentDefinition
+    variables map[string]interface{}
+    listSize  int
+}
+
+func newQueryScorer(schema *graphql.Schema, doc *ast.Document, variables map[string]interface{}, listSize int) *queryScorer {
+    if listSize < 1 {
+        listSize = 1
+    }
+
+    fragments := map[string]*ast.FragmentDefinition{}
+    for _, def := range doc.Definitions {
+        if frag, ok := def.(*ast.FragmentDefinition); ok && frag.Name != nil {
+            fragments[frag.Name.Value] = frag
+        }
+    }
+
+    return &queryScorer{
+        schema:    schema,
+        fragments: fragments,
+        variables: variables,
+        listSize:  listSize,
+    }
+}
+
+func (qs *queryScorer) operationCost(op *ast.OperationDefinition) queryCost {
+    var root graphql.Type
+    switch op.Operation {
+    case ast.OperationTypeQuery:
+        root = qs.schema.QueryType()
+    case ast.OperationTypeMutation:
+        root = qs.schema.MutationType()
+    case ast.OperationTypeSubscription:
+        root = qs.schema.SubscriptionType()
+    }
+
+    return qs.selectionCost(op.SelectionSet, root, 0, map[string]bool{})
+}
+
+// selectionCost scores a selection set on parent, whose fields sit at depth+1.
+// visiting guards against fragment cycles (validation rejects them, but scoring runs on any document).
+func (qs *queryScorer) selectionCost(set *ast.SelectionSet, parent graphql.Type, depth int, visiting map[string]bool) queryCost {
+    total := queryCost{Depth: depth}
+    if set == nil {
+        return total
+    }
+
+    for _, sel := range set.Selections {
+        var cost queryCost
+
+        switch sel := sel.(type) {
+        case *ast.Field:
+            if sel.Name == nil || strings.HasPrefix(sel.Name.Value, "__") {
+                continue
+            }
+            cost = qs.fieldCost(sel, parent, depth+1, visiting)
+
+        case *ast.InlineFragment:
+            typ := parent
+            if sel.TypeCondition != nil && sel.TypeCondition.Name != nil {
+                typ = qs.schema.Type(sel.TypeCondition.Name.Value)
+            }
+            cost = qs.selectionCost(sel.SelectionSet, typ, depth, visiting)
+
+        case *ast.FragmentSpread:
+            if sel.Name == nil || visiting[sel.Name.Value] {
+                continue
+            }
+            frag, ok := qs.fragments[sel.Name.Value]
+            if !ok {
+                continue
+            }
+            typ := parent
+            if frag.TypeCondition != nil && frag.TypeCondition.Name != nil {
+                typ = qs.schema.Type(frag.TypeCondition.Name.Value)
+            }
+            visiting[sel.Name.Value] = true
+            cost = qs.selectionCost(frag.SelectionSet, typ, depth, visiting)
+            delete(visiting, sel.Name.Value)
+        }
+
+        if cost.Depth > total.Depth {
+            total.Depth = cost.Depth
+        }
+        total.Complexity += cost.Complexity
+    }
+
+    return total
+}
+
+// fieldCost scores one field at the given depth
+func (qs *queryScorer) fieldCost(field *ast.Field, parent graphql.Type, depth int, visiting map[string]bool) queryCost {
+    if field.SelectionSet == nil || len(field.SelectionSet.Selections) == 0 {
+        return queryCost{Depth: depth}
+    }
+
+    var fieldType graphql.Type
+    if def := fieldDefinition(parent, field.Name.Value); def != nil {
+        fieldType = def.Type
+    }
+
+    var named graphql.Type
+    if fieldType != nil {
+        named, _ = graphql.GetNamed(fieldType).(graphql.Type)
+    }
+
+    size := 1
+    if (isListType(fieldType) && !isConnectionType(parent)) || isConnectionType(named) {
+        size = qs.listSizeOf(field)
+    }
+    children := qs.selectionCost(field.SelectionSet, named, depth, visiting)
+
+    return queryCost{
+        Depth:      children.Depth,
+        Complexity: size * (1 + children.Complexity),
+    }
+}
+
+// listSizeOf reads the limit/first argument of a list or connection field, falling back to the default size
+func (qs *queryScorer) listSizeOf(field *ast.Field) int {
+    for _, arg := range field.Arguments {
+        if arg.Name == nil || (arg.Name.Value != "limit" && arg.Name.Value != "first") {
+            continue
+        }
+
+        var value interface{}
+        switch v := arg.Value.(type) {
+        case *ast.IntValue:
+            value = v.Value
+        case *ast.Variable:
+            if v.Name != nil {
+                value = qs.variables[v.Name.Value]
+            }
+        }
+
+        switch v := value.(type) {
+        case string:
+            var n int
+            if _, err := fmt.Sscan(v, &n); err == nil && n > 0 {
+                return n
+            }
+        case float64:
+            if v >= 1 {
+                return int(v)
+            }
+        case int:
+            if v > 0 {
+                return v
+            }
+        }
+    }
+    return qs.listSize
+}
+
+// fieldDefinition looks up a field on an object or interface type
+func fieldDefinition(parent graphql.Type, name string) *graphql.FieldDefinition {
+    switch t := parent.(type) {
+    case *graphql.Object:
+        return t.Fields()[name]
+    case *graphql.Interface:
+        return t.Fields()[name]
+    }
+    return nil
+}
+
+func isListType(t graphql.Type) bool {
+    if nonNull, ok := t.(*graphql.NonNull); ok {
+        t = nonNull.OfType
+    }
+    _, ok := t.(*graphql.List)
+    return ok
+}
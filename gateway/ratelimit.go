@@ -0,0 +1,213 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "math"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/graphql-go/graphql/language/ast"
+    "github.com/graphql-go/graphql/language/parser"
+)
+
+// RateLimitConfig holds token bucket settings for queries and mutations
+type RateLimitConfig struct {
+    Enabled bool
+    QueryRate float64 // tokens refilled per second
+    QueryBurst int
+    MutationRate float64
+    MutationBurst int
+    IdleTTL time.Duration // buckets unused for this long are evicted
+}
+
+// tokenBucket is a single client's bucket
+type tokenBucket struct {
+    tokens   float64
+    lastSeen time.Time
+}
+
+// take refills the bucket and tries to consume one token.
+// Returns the wait time until a token is available when empty.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) (bool, time.Duration) {
+    elapsed := now.Sub(b.lastSeen).Seconds()
+    b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+    b.lastSeen = now
+
+    if b.tokens >= 1 {
+        b.tokens--
+        return true, 0
+    }
+
+    if rate <= 0 {
+        return false, time.Minute
+    }
+    wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+    return false, wait
+}
+
+// RateLimiter keeps one bucket per client key and operation type
+type RateLimiter struct {
+    config  RateLimitConfig
+    mu      sync.Mutex
+    buckets map[string]*tokenBucket
+    now     func() time.Time
+}
+
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+    if config.IdleTTL <= 0 {
+        config.IdleTTL = 10 * time.Minute
+    }
+    rl := &RateLimiter{
+        config:  config,
+        buckets: make(map[string]*tokenBucket),
+        now:     time.Now,
+    }
+    go rl.cleanupLoop()
+    return rl
+}
+
+// Allow consumes a token for the client key and reports the Retry-After wait when limited
+func (rl *RateLimiter) Allow(clientKey string, isMutation bool) (bool, time.Duration) {
+    rate, burst, kind := rl.config.QueryRate, rl.config.QueryBurst, "query"
+    if isMutation {
+        rate, burst, kind = rl.config.MutationRate, rl.config.MutationBurst, "mutation"
+    }
+
+    key := kind + ":" + clientKey
+    now := rl.now()
+
+    rl.mu.Lock()
+    defer rl.mu.Unlock()
+
+    bucket, ok := rl.buckets[key]
+    if !ok {
+        bucket = &tokenBucket{tokens: float64(burst), lastSeen: now}
+        rl.buckets[key] = bucket
+    }
+
+    return bucket.take(now, rate, burst)
+}
+
+// cleanupLoop evicts idle buckets so the map doesn't grow unbounded
+func (rl *RateLimiter) cleanupLoop() {
+    ticker := time.NewTicker(rl.config.IdleTTL)
+    defer ticker.Stop()
+
+    for range ticker.C {
+        cutoff := rl.now().Add(-rl.config.IdleTTL)
+        rl.mu.Lock()
+        for key, bucket := range rl.buckets {
+            if bucket.lastSeen.Before(cutoff) {
+                delete(rl.buckets, key)
+            }
+        }
+        rl.mu.Unlock()
+    }
+}
+
+// rateLimitMiddleware limits GraphQL requests per user (JWT) or per client IP.
+// Must run after authMiddleware so the user claims are available. maxBodyBytes caps how much
+// of the body is read to classify the request.
+func rateLimitMiddleware(limiter *RateLimiter, maxBodyBytes int64) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if limiter == nil || !limiter.config.Enabled {
+            c.Next()
+            return
+        }
+
+        clientKey := "ip:" + c.ClientIP()
+        if val, ok := c.Get("user"); ok {
+            if claims, ok := val.(*UserClaims); ok && claims.UserID != "" {
+                clientKey = "user:" + claims.UserID
+            }
+        }
+
+        allowed, wait := limiter.Allow(clientKey, isMutationRequest(c, maxBodyBytes))
+        if !allowed {
+            retryAfter := int(math.Ceil(wait.Seconds()))
+            if retryAfter < 1 {
+                retryAfter = 1
+            }
+            c.Header("Retry-After", strconv.Itoa(retryAfter))
+            c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+                "errors": []map[string]interface{}{
+                    {"message": "rate limit exceeded, retry later"},
+                },
+            })
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// isMutationRequest peeks at the GraphQL body to decide whether it's a mutation.
+// The body is restored so the handler can still bind it. Anything that can't be read or parsed
+// counts as a mutation, so malformed bodies get the stricter bucket.
+func isMutationRequest(c *gin.Context, maxBodyBytes int64) bool {
+    // Mutations are refused over GET
+    if c.Request.Method == http.MethodGet {
+        return false
+    }
+    if c.Request.Body == nil {
+        return true
+    }
+
+    if maxBodyBytes > 0 {
+        c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes)
+    }
+    body, err := io.ReadAll(c.Request.Body)
+    c.Request.Body = io.NopCloser(bytes.NewReader(body))
+    if err != nil {
+        return true
+    }
+
+    var query GraphQLQuery
+    if err := json.Unmarshal(body, &query); err != nil {
+        return true
+    }
+
+    mutation, err := parseMutation(query.Query, query.OperationName)
+    return mutation || err != nil
+}
+
+// isMutation reports whether the operation to run (operationName, or any when empty) is a mutation.
+// A query that doesn't parse isn't one; execution reports the syntax error.
+func isMutation(queryText, operationName string) bool {
+    mutation, _ := parseMutation(queryText, operationName)
+    return mutation
+}
+
+// parseMutation is isMutation that also returns why the query couldn't be classified
+func parseMutation(queryText, operationName string) (bool, error) {
+    if queryText == "" {
+        return false, errors.New("empty query")
+    }
+
+    doc, err := parser.Parse(parser.ParseParams{Source: queryText})
+    if err != nil {
+        return false, err
+    }
+
+    for _, def := range doc.Definitions {
+        op, ok := def.(*ast.OperationDefinition)
+        if !ok {
+            continue
+        }
+        if operationName != "" && (op.Name == nil || op.Name.Value != operationName) {
+            continue
+        }
+        if op.Operation == ast.OperationTypeMutation {
+            return true, nil
+        }
+    }
+
+    return false, nil
+}
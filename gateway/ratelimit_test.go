@@ -0,0 +1,152 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    newRouter := func(maxBodyBytes int64) (*gin.Engine, *RateLimiter) {
+        limiter := NewRateLimiter(RateLimitConfig{Enabled: true, QueryRate: 1, QueryBurst: 2, MutationRate: 0.5, MutationBurst: 1})
+        now := time.Now()
+        limiter.now = func() time.Time { return now }
+
+        router := gin.New()
+        router.POST("/graphql", func(c *gin.Context) {
+            if userID := c.GetHeader("X-Test-User"); userID != "" {
+                c.Set("user", &UserClaims{UserID: userID})
+            }
+        }, rateLimitMiddleware(limiter, maxBodyBytes), func(c *gin.Context) {
+            var query GraphQLQuery
+            if err := c.BindJSON(&query); err != nil {
+                return
+            }
+            c.Status(http.StatusOK)
+        })
+        return router, limiter
+    }
+    serve := func(router *gin.Engine, body, userID, ip string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        req.RemoteAddr = ip + ":1234"
+        if userID != "" {
+            req.Header.Set("X-Test-User", userID)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    const query = `{"query":"{ products { id } }"}`
+    const mutation = `{"query":"mutation { addToCart(productId: \"p1\", quantity: 1) { id } }"}`
+
+    t.Run("query and mutation buckets", func(t *testing.T) {
+        router, _ := newRouter(1 << 20)
+
+        for i := 0; i < 2; i++ {
+            if w := serve(router, query, "", "10.0.0.1"); w.Code != http.StatusOK {
+                t.Fatalf("query %d: status %d, want 200", i, w.Code)
+            }
+        }
+        if w := serve(router, query, "", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+            t.Fatalf("query over burst: status %d, want 429", w.Code)
+        }
+
+        // Queries don't spend the mutation bucket
+        if w := serve(router, mutation, "", "10.0.0.1"); w.Code != http.StatusOK {
+            t.Fatalf("mutation: status %d, want 200", w.Code)
+        }
+        if w := serve(router, mutation, "", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+            t.Fatalf("mutation over burst: status %d, want 429", w.Code)
+        }
+    })
+
+    t.Run("user and IP keys", func(t *testing.T) {
+        router, _ := newRouter(1 << 20)
+
+        if w := serve(router, mutation, "u1", "10.0.0.1"); w.Code != http.StatusOK {
+            t.Fatalf("u1: status %d, want 200", w.Code)
+        }
+        // Same IP, other user: own bucket
+        if w := serve(router, mutation, "u2", "10.0.0.1"); w.Code != http.StatusOK {
+            t.Fatalf("u2: status %d, want 200", w.Code)
+        }
+        // Same user, other IP: the user's bucket follows them
+        if w := serve(router, mutation, "u1", "10.0.0.2"); w.Code != http.StatusTooManyRequests {
+            t.Fatalf("u1 from another IP: status %d, want 429", w.Code)
+        }
+        // Anonymous callers are keyed by IP, apart from users on that IP
+        if w := serve(router, mutation, "", "10.0.0.1"); w.Code != http.StatusOK {
+            t.Fatalf("anonymous: status %d, want 200", w.Code)
+        }
+        if w := serve(router, mutation, "", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+            t.Fatalf("anonymous over burst: status %d, want 429", w.Code)
+        }
+    })
+
+    t.Run("Retry-After", func(t *testing.T) {
+        router, limiter := newRouter(1 << 20)
+
+        serve(router, mutation, "u1", "10.0.0.1")
+        w := serve(router, mutation, "u1", "10.0.0.1")
+        if w.Code != http.StatusTooManyRequests {
+            t.Fatalf("status %d, want 429", w.Code)
+        }
+        // One token at 0.5/s is 2s away
+        if got := w.Header().Get("Retry-After"); got != "2" {
+            t.Fatalf("Retry-After = %q, want 2", got)
+        }
+
+        now := limiter.now().Add(2 * time.Second)
+        limiter.now = func() time.Time { return now }
+        if w := serve(router, mutation, "u1", "10.0.0.1"); w.Code != http.StatusOK {
+            t.Fatalf("after Retry-After: status %d, want 200", w.Code)
+        }
+    })
+
+    t.Run("unparseable bodies use the mutation bucket", func(t *testing.T) {
+        for name, body := range map[string]string{
+            "invalid JSON":  `{"query":`,
+            "invalid query": `{"query":"{ products { id "}`,
+            "empty query":   `{}`,
+            "over the cap":  `{"query":"{ products { id } }","operationName":"` + strings.Repeat("x", 256) + `"}`,
+        } {
+            router, _ := newRouter(128)
+            serve(router, body, "", "10.0.0.1")
+            if w := serve(router, mutation, "", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+                t.Errorf("%s: mutation after it got status %d, want 429", name, w.Code)
+            }
+            if w := serve(router, query, "", "10.0.0.1"); w.Code != http.StatusOK {
+                t.Errorf("%s: query after it got status %d, want 200", name, w.Code)
+            }
+        }
+    })
+}
+
+func TestIsMutation(t *testing.T) {
+    tests := []struct {
+        name          string
+        query         string
+        operationName string
+        want          bool
+    }{
+        {"query", "{ products { id } }", "", false},
+        {"mutation", "mutation { logout }", "", true},
+        {"named query among mutations", "query Q { me { id } } mutation M { logout }", "Q", false},
+        {"named mutation among queries", "query Q { me { id } } mutation M { logout }", "M", true},
+        {"syntax error", "{ products {", "", false},
+    }
+
+    for _, tt := range tests {
+        if got := isMutation(tt.query, tt.operationName); got != tt.want {
+            t.Errorf("%s: isMutation = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
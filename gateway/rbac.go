@@ -0,0 +1,151 @@
+package main
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "log"
+    "os"
+    "strings"
+
+    "github.com/graphql-go/graphql"
+    "gopkg.in/yaml.v3"
+)
+
+// RBAC policy for GraphQL root fields
+// Why: admin-only fields each checked the role in their resolver, and some forgot to. The roles
+// now come from the policy file the services load (shared/rbac/policy.yaml), so who can do what
+// is reviewed in one place. The gateway can't import shared, so this mirrors the part of
+// shared/rbac it needs; service routes are checked by the services themselves.
+
+// defaultRBACPolicyFile is the shared policy when the gateway runs from its directory in the repo
+const defaultRBACPolicyFile = "../shared/rbac/policy.yaml"
+
+// anyRole in a field's roles lets in any signed-in caller
+const anyRole = "*"
+
+// RBACPolicy is the policy file as far as the gateway is concerned
+type RBACPolicy struct {
+    Roles    []string              `yaml:"roles"`
+    Services map[string][]rbacRule `yaml:"services"` // enforced by the services
+    GraphQL  []rbacFieldRule       `yaml:"graphql"`
+}
+
+type rbacRule struct {
+    Route string   `yaml:"route"`
+    Roles []string `yaml:"roles"`
+}
+
+type rbacFieldRule struct {
+    Field string   `yaml:"field"` // Query.name or Mutation.name
+    Roles []string `yaml:"roles"`
+}
+
+// loadRBACPolicy loads RBAC_POLICY_FILE, or the shared policy next to the gateway directory.
+// A gateway without a valid policy doesn't start.
+func loadRBACPolicy() *RBACPolicy {
+    path := os.Getenv("RBAC_POLICY_FILE")
+    if path == "" {
+        path = defaultRBACPolicyFile
+    }
+    policy, err := LoadRBACPolicy(path)
+    if err != nil {
+        log.Fatalf("❌ Invalid RBAC policy: %v", err)
+    }
+    return policy
+}
+
+// LoadRBACPolicy reads and checks a policy file
+func LoadRBACPolicy(path string) (*RBACPolicy, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read RBAC policy: %w", err)
+    }
+    policy, err := ParseRBACPolicy(data)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return policy, nil
+}
+
+// ParseRBACPolicy decodes a policy, rejecting unknown keys, undeclared roles and malformed
+// field names like shared/rbac does
+func ParseRBACPolicy(data []byte) (*RBACPolicy, error) {
+    decoder := yaml.NewDecoder(bytes.NewReader(data))
+    decoder.KnownFields(true)
+
+    policy := &RBACPolicy{}
+    if err := decoder.Decode(policy); err != nil {
+        return nil, fmt.Errorf("failed to parse RBAC policy: %w", err)
+    }
+    if len(policy.Roles) == 0 {
+        return nil, errors.New("RBAC policy declares no roles")
+    }
+
+    for _, field := range policy.GraphQL {
+        typeName, name, ok := strings.Cut(field.Field, ".")
+        if !ok || (typeName != "Query" && typeName != "Mutation") || name == "" {
+            return nil, fmt.Errorf("graphql field %q must be Query.name or Mutation.name", field.Field)
+        }
+        if len(field.Roles) == 0 {
+            return nil, fmt.Errorf("graphql field %s: no roles", field.Field)
+        }
+        for _, role := range field.Roles {
+            if role != anyRole && !policy.declares(role) {
+                return nil, fmt.Errorf("graphql field %s: undeclared role %q", field.Field, role)
+            }
+        }
+    }
+
+    return policy, nil
+}
+
+func (rp *RBACPolicy) declares(role string) bool {
+    for _, known := range rp.Roles {
+        if known == role {
+            return true
+        }
+    }
+    return false
+}
+
+// authorizeFields wraps the resolvers of the fields the policy lists with a role check; it
+// must run after AttachResolvers. Fields of disabled schema sections are skipped, other fields
+// missing from the schema are logged so a typo in the policy is noticed.
+func (rp *RBACPolicy) authorizeFields(schema *graphql.Schema, features SchemaFeatures) {
+    roots := map[string]*graphql.Object{"Query": schema.QueryType(), "Mutation": schema.MutationType()}
+
+    for _, rule := range rp.GraphQL {
+        typeName, name, _ := strings.Cut(rule.Field, ".")
+        var field *graphql.FieldDefinition
+        if root := roots[typeName]; root != nil {
+            field = root.Fields()[name]
+        }
+        if field == nil {
+            if !features.isDisabled(typeName, name) {
+                log.Printf("⚠️  RBAC policy lists %s, which isn't in the schema", rule.Field)
+            }
+            continue
+        }
+        field.Resolve = requireRoles(rule.Roles, field.Resolve)
+    }
+}
+
+// requireRoles runs resolve only for a signed-in caller with one of roles
+func requireRoles(roles []string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+    if resolve == nil {
+        resolve = graphql.DefaultResolveFn
+    }
+    return func(p graphql.ResolveParams) (interface{}, error) {
+        user, err := GetUserFromContext(p.Context)
+        if err != nil {
+            return nil, err
+        }
+        for _, role := range roles {
+            if role == anyRole || role == user["role"] {
+                return resolve(p)
+            }
+        }
+        return nil, Forbidden(strings.Join(roles, " or ") + " role required")
+    }
+}
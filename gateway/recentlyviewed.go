@@ -0,0 +1,195 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+)
+
+// Recently viewed products
+// Each user's views are a short list, newest first: viewing a product again moves it to the
+// front and the oldest falls off once the list is full (a ring buffer per user). With Redis the
+// lists are shared by all instances (LPUSH + LTRIM under gateway:views:<user>); without it they
+// are kept per instance, for development.
+
+// recentlyViewedKeyPrefix namespaces the per-user lists in Redis
+const recentlyViewedKeyPrefix = "gateway:views:"
+
+// RecentlyViewedConfig holds view tracking settings
+type RecentlyViewedConfig struct {
+    Enabled  bool
+    Size     int           // products kept per user
+    TTL      time.Duration // a user's list expires this long after their last view
+    MaxUsers int           // users kept in memory without Redis
+    RedisURL string
+}
+
+// SharedViewStore keeps the lists in a store shared by all gateway instances
+type SharedViewStore interface {
+    // Push moves productID to the front of the user's list and trims it to size
+    Push(ctx context.Context, userID string, productID int64, size int, ttl time.Duration) error
+    // Recent returns up to limit product IDs, newest first
+    Recent(ctx context.Context, userID string, limit int) ([]int64, error)
+}
+
+// RecentlyViewed records and lists each user's recently viewed products
+type RecentlyViewed struct {
+    config RecentlyViewedConfig
+    shared SharedViewStore // nil: local only
+
+    mu    sync.Mutex
+    local map[string]*viewList
+    now   func() time.Time
+}
+
+type viewList struct {
+    productIDs []int64
+    expiresAt  time.Time
+}
+
+// NewRecentlyViewed creates the view tracker; shared may be nil
+func NewRecentlyViewed(config RecentlyViewedConfig, shared SharedViewStore) *RecentlyViewed {
+    if config.Size <= 0 {
+        config.Size = 20
+    }
+    return &RecentlyViewed{
+        config: config,
+        shared: shared,
+        local:  make(map[string]*viewList),
+        now:    time.Now,
+    }
+}
+
+// Record notes that the user viewed the product
+func (rv *RecentlyViewed) Record(ctx context.Context, userID string, productID int64) error {
+    if rv.shared != nil {
+        return rv.shared.Push(ctx, userID, productID, rv.config.Size, rv.config.TTL)
+    }
+
+    now := rv.now()
+    rv.mu.Lock()
+    defer rv.mu.Unlock()
+
+    list, ok := rv.local[userID]
+    if !ok || !now.Before(list.expiresAt) {
+        if len(rv.local) >= rv.config.MaxUsers {
+            rv.evictLocked(now)
+        }
+        list = &viewList{}
+        rv.local[userID] = list
+    }
+    list.productIDs = pushView(list.productIDs, productID, rv.config.Size)
+    list.expiresAt = now.Add(rv.config.TTL)
+    return nil
+}
+
+// Recent returns up to limit of the user's viewed product IDs, newest first
+func (rv *RecentlyViewed) Recent(ctx context.Context, userID string, limit int) ([]int64, error) {
+    if limit <= 0 || limit > rv.config.Size {
+        limit = rv.config.Size
+    }
+    if rv.shared != nil {
+        return rv.shared.Recent(ctx, userID, limit)
+    }
+
+    rv.mu.Lock()
+    defer rv.mu.Unlock()
+
+    list, ok := rv.local[userID]
+    if !ok || !rv.now().Before(list.expiresAt) {
+        return []int64{}, nil
+    }
+    if len(list.productIDs) < limit {
+        limit = len(list.productIDs)
+    }
+    return append([]int64(nil), list.productIDs[:limit]...), nil
+}
+
+// evictLocked drops expired lists, then arbitrary ones until there is room
+func (rv *RecentlyViewed) evictLocked(now time.Time) {
+    for userID, list := range rv.local {
+        if !now.Before(list.expiresAt) {
+            delete(rv.local, userID)
+        }
+    }
+    for userID := range rv.local {
+        if len(rv.local) < rv.config.MaxUsers {
+            break
+        }
+        delete(rv.local, userID)
+    }
+}
+
+// pushView puts productID first, removing an earlier view of it, and keeps at most size entries
+func pushView(productIDs []int64, productID int64, size int) []int64 {
+    list := make([]int64, 0, size)
+    list = append(list, productID)
+    for _, id := range productIDs {
+        if len(list) >= size {
+            break
+        }
+        if id != productID {
+            list = append(list, id)
+        }
+    }
+    return list
+}
+
+// redisViewStore is the Redis-backed SharedViewStore
+type redisViewStore struct {
+    client *redis.Client
+}
+
+// NewRedisViewStore connects to Redis and returns a shared view store
+func NewRedisViewStore(redisURL string) (SharedViewStore, error) {
+    opts, err := redis.ParseURL(redisURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse redis url: %w", err)
+    }
+
+    client := redis.NewClient(opts)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, fmt.Errorf("failed to connect to redis: %w", err)
+    }
+
+    return &redisViewStore{client: client}, nil
+}
+
+func (rs *redisViewStore) Push(ctx context.Context, userID string, productID int64, size int, ttl time.Duration) error {
+    key := recentlyViewedKeyPrefix + userID
+    // One transaction, so concurrent views of the same product can't leave a duplicate
+    _, err := rs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.LRem(ctx, key, 0, productID)
+        pipe.LPush(ctx, key, productID)
+        pipe.LTrim(ctx, key, 0, int64(size-1))
+        pipe.Expire(ctx, key, ttl)
+        return nil
+    })
+    return err
+}
+
+func (rs *redisViewStore) Recent(ctx context.Context, userID string, limit int) ([]int64, error) {
+    values, err := rs.client.LRange(ctx, recentlyViewedKeyPrefix+userID, 0, int64(limit-1)).Result()
+    if err != nil {
+        return nil, err
+    }
+
+    productIDs := make([]int64, 0, len(values))
+    for _, value := range values {
+        id, err := strconv.ParseInt(value, 10, 64)
+        if err != nil {
+            continue
+        }
+        productIDs = append(productIDs, id)
+    }
+    return productIDs, nil
+}
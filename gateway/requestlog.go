@@ -0,0 +1,326 @@
+package main
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "log"
+    "math"
+    mathrand "math/rand"
+    "net/url"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/graphql-go/graphql"
+    "github.com/graphql-go/graphql/language/ast"
+    "github.com/graphql-go/graphql/language/parser"
+)
+
+// Structured GraphQL request logging
+// Why: one JSON line per operation (name, duration, caller, errors, downstream calls)
+// makes slow or failing operations traceable across services via the request ID.
+
+// RequestIDHeader carries the request ID to clients and downstream services
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey holds the request ID for the current GraphQL request
+const RequestIDContextKey ContextKey = "request_id"
+
+// downstreamContextKey holds the *downstreamCalls recorder for the current request
+const downstreamContextKey ContextKey = "downstream_calls"
+
+// sensitiveTag marks schema arguments whose values must never be logged.
+// Put it at the start of the argument's Description.
+const sensitiveTag = "[sensitive]"
+
+// redactedValue replaces sensitive variable values in logs
+const redactedValue = "[REDACTED]"
+
+// sensitiveNamePattern catches sensitive variables the schema doesn't describe (nested inputs, typos)
+var sensitiveNamePattern = regexp.MustCompile(`(?i)password|passwd|token|secret|authorization|api_?key`)
+
+// RequestLogConfig controls GraphQL request logging
+type RequestLogConfig struct {
+    Enabled    bool
+    SampleRate float64 // 0..1 share of successful operations logged; errors are always logged
+}
+
+// downstreamCall is one HTTP call made while resolving an operation
+type downstreamCall struct {
+    Service    string  `json:"service"`
+    Method     string  `json:"method"`
+    Path       string  `json:"path"`
+    Status     int     `json:"status"` // 0 when the request failed before a response
+    DurationMs float64 `json:"duration_ms"`
+}
+
+// downstreamCalls collects the calls made for one request; resolvers may run concurrently
+type downstreamCalls struct {
+    mu    sync.Mutex
+    calls []downstreamCall
+}
+
+func (dc *downstreamCalls) add(call downstreamCall) {
+    dc.mu.Lock()
+    defer dc.mu.Unlock()
+    dc.calls = append(dc.calls, call)
+}
+
+func (dc *downstreamCalls) list() []downstreamCall {
+    dc.mu.Lock()
+    defer dc.mu.Unlock()
+    return append([]downstreamCall(nil), dc.calls...)
+}
+
+// operationLog is the structured log line for one GraphQL operation
+type operationLog struct {
+    RequestID     string                 `json:"request_id"`
+    OperationName string                 `json:"operation_name"`
+    OperationType string                 `json:"operation_type"`
+    RootFields    []string               `json:"root_fields"`
+    Caller        string                 `json:"caller"`
+    DurationMs    float64                `json:"duration_ms"`
+    ErrorCount    int                    `json:"error_count"`
+    Variables     map[string]interface{} `json:"variables,omitempty"`
+    Downstream    []downstreamCall       `json:"downstream,omitempty"`
+}
+
+// RequestLogger logs GraphQL operations with sensitive variables redacted
+type RequestLogger struct {
+    config    RequestLogConfig
+    schema    *graphql.Schema
+    sensitive map[string]map[string]bool // root field -> sensitive argument names
+}
+
+// NewRequestLogger creates a request logger; sensitive arguments are read from the schema once
+func NewRequestLogger(config RequestLogConfig, schema *graphql.Schema) *RequestLogger {
+    return &RequestLogger{
+        config:    config,
+        schema:    schema,
+        sensitive: sensitiveArguments(schema),
+    }
+}
+
+// sensitiveArguments indexes root field arguments tagged with sensitiveTag
+func sensitiveArguments(schema *graphql.Schema) map[string]map[string]bool {
+    index := make(map[string]map[string]bool)
+
+    for _, root := range []*graphql.Object{schema.QueryType(), schema.MutationType()} {
+        if root == nil {
+            continue
+        }
+        for name, field := range root.Fields() {
+            for _, arg := range field.Args {
+                if !strings.HasPrefix(arg.Description(), sensitiveTag) {
+                    continue
+                }
+                if index[name] == nil {
+                    index[name] = make(map[string]bool)
+                }
+                index[name][arg.Name()] = true
+            }
+        }
+    }
+
+    return index
+}
+
+// requestIDMiddleware assigns a request ID (reusing the caller's if sent),
+// echoes it in the response and attaches a downstream call recorder
+func requestIDMiddleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        requestID := c.GetHeader(RequestIDHeader)
+        if requestID == "" {
+            requestID = newRequestID()
+        }
+        c.Header(RequestIDHeader, requestID)
+
+        ctx := context.WithValue(c.Request.Context(), RequestIDContextKey, requestID)
+        ctx = context.WithValue(ctx, downstreamContextKey, &downstreamCalls{})
+        c.Request = c.Request.WithContext(ctx)
+
+        c.Next()
+    }
+}
+
+func newRequestID() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return time.Now().UTC().Format("20060102150405.000000000")
+    }
+    return hex.EncodeToString(b)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request
+func RequestIDFromContext(ctx context.Context) string {
+    requestID, _ := ctx.Value(RequestIDContextKey).(string)
+    return requestID
+}
+
+// recordDownstream notes a downstream HTTP call on the request's recorder, if any
+func recordDownstream(ctx context.Context, method, rawURL string, status int, duration time.Duration) {
+    recorder, ok := ctx.Value(downstreamContextKey).(*downstreamCalls)
+    if !ok {
+        return
+    }
+
+    call := downstreamCall{
+        Method:     method,
+        Status:     status,
+        DurationMs: roundMs(duration),
+    }
+    if u, err := url.Parse(rawURL); err == nil {
+        call.Service = u.Host
+        call.Path = u.Path
+    }
+    recorder.add(call)
+}
+
+// Log writes the structured line for one executed operation
+func (rl *RequestLogger) Log(ctx context.Context, query GraphQLQuery, caller string, duration time.Duration, result *graphql.Result) {
+    if !rl.config.Enabled {
+        return
+    }
+
+    errorCount := len(result.Errors)
+    if errorCount == 0 && !rl.sampled() {
+        return
+    }
+
+    entry := operationLog{
+        RequestID:     RequestIDFromContext(ctx),
+        OperationName: query.OperationName,
+        Caller:        caller,
+        DurationMs:    roundMs(duration),
+        ErrorCount:    errorCount,
+    }
+
+    sensitiveVars := map[string]bool{}
+    if op := rl.findOperation(query); op != nil {
+        entry.OperationType = op.Operation
+        if entry.OperationName == "" && op.Name != nil {
+            entry.OperationName = op.Name.Value
+        }
+        entry.RootFields, sensitiveVars = rl.inspectSelections(op)
+    }
+    entry.Variables = redactVariables(query.Variables, sensitiveVars)
+
+    if recorder, ok := ctx.Value(downstreamContextKey).(*downstreamCalls); ok {
+        entry.Downstream = recorder.list()
+    }
+
+    line, err := json.Marshal(entry)
+    if err != nil {
+        log.Printf("⚠️  Failed to encode request log: %v", err)
+        return
+    }
+    log.Printf("graphql %s", line)
+}
+
+func (rl *RequestLogger) sampled() bool {
+    if rl.config.SampleRate >= 1 {
+        return true
+    }
+    return mathrand.Float64() < rl.config.SampleRate
+}
+
+// findOperation parses the query and returns the operation that was executed
+func (rl *RequestLogger) findOperation(query GraphQLQuery) *ast.OperationDefinition {
+    doc, err := parser.Parse(parser.ParseParams{Source: query.Query})
+    if err != nil {
+        return nil
+    }
+
+    for _, def := range doc.Definitions {
+        op, ok := def.(*ast.OperationDefinition)
+        if !ok {
+            continue
+        }
+        if query.OperationName != "" && (op.Name == nil || op.Name.Value != query.OperationName) {
+            continue
+        }
+        return op
+    }
+    return nil
+}
+
+// inspectSelections returns the root field names and the variables bound to sensitive arguments
+func (rl *RequestLogger) inspectSelections(op *ast.OperationDefinition) ([]string, map[string]bool) {
+    var fields []string
+    sensitiveVars := map[string]bool{}
+
+    if op.SelectionSet == nil {
+        return fields, sensitiveVars
+    }
+
+    for _, sel := range op.SelectionSet.Selections {
+        field, ok := sel.(*ast.Field)
+        if !ok || field.Name == nil {
+            continue
+        }
+        fields = append(fields, field.Name.Value)
+
+        sensitiveArgs := rl.sensitive[field.Name.Value]
+        for _, arg := range field.Arguments {
+            if arg.Name == nil || !sensitiveArgs[arg.Name.Value] {
+                continue
+            }
+            if variable, ok := arg.Value.(*ast.Variable); ok && variable.Name != nil {
+                sensitiveVars[variable.Name.Value] = true
+            }
+        }
+    }
+
+    return fields, sensitiveVars
+}
+
+// redactVariables copies variables, replacing sensitive values (by schema or by name) with redactedValue
+func redactVariables(variables map[string]interface{}, sensitiveVars map[string]bool) map[string]interface{} {
+    if len(variables) == 0 {
+        return nil
+    }
+
+    redacted := make(map[string]interface{}, len(variables))
+    for name, value := range variables {
+        if sensitiveVars[name] || sensitiveNamePattern.MatchString(name) {
+            redacted[name] = redactedValue
+            continue
+        }
+        redacted[name] = redactValue(value)
+    }
+    return redacted
+}
+
+// redactValue walks nested input objects and lists
+func redactValue(value interface{}) interface{} {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        return redactVariables(v, nil)
+    case []interface{}:
+        out := make([]interface{}, len(v))
+        for i, item := range v {
+            out[i] = redactValue(item)
+        }
+        return out
+    default:
+        return v
+    }
+}
+
+// callerFor identifies the caller: user ID when authenticated, otherwise client IP
+func callerFor(c *gin.Context) string {
+    if val, ok := c.Get("user"); ok {
+        if claims, ok := val.(*UserClaims); ok && claims.UserID != "" {
+            return "user:" + claims.UserID
+        }
+    }
+    return "ip:" + c.ClientIP()
+}
+
+func roundMs(d time.Duration) float64 {
+    return math.Round(float64(d.Microseconds())/10) / 100
+}
@@ -4,6 +4,10 @@ import (
     "context"
     "fmt"
     "log"
+    "net/url"
+    "sort"
+    "strings"
+    "time"
 
     "github.com/graphql-go/graphql"
 )
@@ -15,24 +19,28 @@ type ResolverContext struct {
     CartService    *CartService
     OrderService   *OrderService
     TokenValidator *TokenValidator
+    Announcements  *AnnouncementHub
+    RecentlyViewed *RecentlyViewed // nil when view tracking is disabled
+    GuestTokens    *GuestTokens    // nil when guest carts are disabled
 }
 
 // GetUserFromContext extracts user from request context
 func GetUserFromContext(ctx context.Context) (map[string]interface{}, error) {
-    val := ctx.Value("user")
+    val := ctx.Value(UserContextKey)
     if val == nil {
-        return nil, fmt.Errorf("unauthenticated")
+        return nil, Unauthorized("authentication required")
     }
 
     claims, ok := val.(*UserClaims)
     if !ok {
-        return nil, fmt.Errorf("invalid user context")
+        return nil, Unauthorized("invalid user context")
     }
 
     return map[string]interface{}{
         "id":       claims.UserID,
         "email":    claims.Email,
         "username": claims.Username,
+        "role":     claims.Role,
     }, nil
 }
 
@@ -47,7 +55,7 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         meField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+                return nil, err
             }
 
             userID := user["id"].(string)
@@ -61,6 +69,49 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
+    // User.preferences - The authenticated user's preferences
+    if userType, ok := schema.Type("User").(*graphql.Object); ok {
+        if preferencesField, ok := userType.Fields()["preferences"]; ok {
+            preferencesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                user, err := GetUserFromContext(p.Context)
+                if err != nil {
+                    return nil, err
+                }
+
+                source, _ := p.Source.(map[string]interface{})
+                if source["id"] != user["id"] {
+                    return nil, Forbidden("preferences are only readable by their user")
+                }
+
+                prefs, err := ctx.UserService.GetPreferences(p.Context, user["id"].(string))
+                if err != nil {
+                    log.Printf("❌ Error fetching preferences: %v", err)
+                    return nil, err
+                }
+
+                return prefs, nil
+            }
+        }
+    }
+
+    // addresses - The current user's saved addresses
+    if addressesField, ok := queryFields["addresses"]; ok {
+        addressesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            addresses, err := ctx.UserService.GetAddresses(p.Context, user["id"].(string))
+            if err != nil {
+                log.Printf("❌ Error fetching addresses: %v", err)
+                return nil, err
+            }
+
+            return addresses, nil
+        }
+    }
+
     // products - List all products or filter by category
     if productsField, ok := queryFields["products"]; ok {
         productsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
@@ -72,7 +123,26 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
                 }
             }
 
-            products, err := ctx.ProductService.GetProducts(p.Context, categoryID)
+            var products []map[string]interface{}
+            var err error
+            includeSub, _ := p.Args["include_subcategories"].(bool)
+            if filters, _ := p.Args["attributes"].([]interface{}); len(filters) > 0 {
+                params, err := attributeFilterParams(filters)
+                if err != nil {
+                    return nil, err
+                }
+                products, err = ctx.ProductService.GetProductsByAttributes(p.Context, categoryID, includeSub, params)
+                if err != nil {
+                    log.Printf("❌ Error fetching products by attributes: %v", err)
+                    return nil, err
+                }
+                return products, nil
+            }
+            if includeSub && categoryID != nil {
+                products, err = ctx.ProductService.GetProductsInCategoryTree(p.Context, *categoryID)
+            } else {
+                products, err = ctx.ProductService.GetProducts(p.Context, categoryID)
+            }
             if err != nil {
                 log.Printf("❌ Error fetching products: %v", err)
                 return nil, err
@@ -82,11 +152,45 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
+    // productsConnection - Relay page of products, newest first, with the products filters
+    if productsConnectionField, ok := queryFields["productsConnection"]; ok {
+        productsConnectionField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            var categoryID *int64
+            if catID, ok := p.Args["category_id"].(int); ok {
+                id := int64(catID)
+                categoryID = &id
+            }
+            includeSub, _ := p.Args["include_subcategories"].(bool)
+
+            var params url.Values
+            if filters, _ := p.Args["attributes"].([]interface{}); len(filters) > 0 {
+                var err error
+                if params, err = attributeFilterParams(filters); err != nil {
+                    return nil, err
+                }
+            }
+
+            first, after := connectionPage(p.Args)
+            page, err := ctx.ProductService.GetProductsPage(p.Context, categoryID, includeSub, params, first, after)
+            if err != nil {
+                log.Printf("❌ Error fetching products page: %v", err)
+                return nil, err
+            }
+
+            return relayConnection(page), nil
+        }
+    }
+
     // product - Get single product by ID
     if productField, ok := queryFields["product"]; ok {
         productField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            id := p.Args["id"].(int)
-            product, err := ctx.ProductService.GetProduct(p.Context, int64(id))
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            product, err := ctx.ProductService.GetProduct(p.Context, id)
             if err != nil {
                 log.Printf("❌ Error fetching product: %v", err)
                 return nil, err
@@ -96,6 +200,126 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
+    // recentlyViewed - The current user's recently viewed products
+    if recentlyViewedField, ok := queryFields["recentlyViewed"]; ok {
+        recentlyViewedField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            if ctx.RecentlyViewed == nil {
+                return []map[string]interface{}{}, nil
+            }
+
+            limit, _ := p.Args["limit"].(int)
+            productIDs, err := ctx.RecentlyViewed.Recent(p.Context, user["id"].(string), limit)
+            if err != nil {
+                log.Printf("❌ Error fetching recently viewed: %v", err)
+                return nil, Unavailable("recently viewed products unavailable", err)
+            }
+
+            products := make([]map[string]interface{}, 0, len(productIDs))
+            for _, productID := range productIDs {
+                product, err := ctx.ProductService.GetProduct(p.Context, productID)
+                if isNotFound(err) {
+                    continue // deleted since it was viewed
+                }
+                if err != nil {
+                    log.Printf("❌ Error fetching product %d: %v", productID, err)
+                    return nil, err
+                }
+                products = append(products, product)
+            }
+
+            return products, nil
+        }
+    }
+
+    // compareProducts - Products side by side with aligned attributes
+    if compareField, ok := queryFields["compareProducts"]; ok {
+        _, withRatings := schema.Type("Product").(*graphql.Object).Fields()["average_rating"]
+
+        compareField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args, _ := p.Args["ids"].([]interface{})
+            productIDs, err := compareProductIDs(args)
+            if err != nil {
+                return nil, err
+            }
+
+            products := make([]map[string]interface{}, 0, len(productIDs))
+            for _, productID := range productIDs {
+                product, err := ctx.ProductService.GetProduct(p.Context, productID)
+                if err != nil {
+                    log.Printf("❌ Error fetching product %d for comparison: %v", productID, err)
+                    return nil, err
+                }
+                products = append(products, product)
+            }
+
+            loader := catalogLoaderFrom(p.Context)
+            var categoryErr error
+            categoryName := func(id int64) interface{} {
+                category, err := loader.Category(p.Context, ctx.ProductService, id)
+                if err != nil {
+                    categoryErr = err
+                    return nil
+                }
+                if category == nil {
+                    return nil
+                }
+                return category["name"]
+            }
+
+            comparison := buildComparison(products, append(comparisonAttributes(categoryName, withRatings), specAttributes(products)...))
+            if categoryErr != nil {
+                log.Printf("❌ Error fetching categories for comparison: %v", categoryErr)
+                return nil, categoryErr
+            }
+            return comparison, nil
+        }
+    }
+
+    // productReviews - Approved reviews for a product with its rating summary
+    if productReviewsField, ok := queryFields["productReviews"]; ok {
+        productReviewsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            page, _ := p.Args["page"].(int)
+            limit, _ := p.Args["limit"].(int)
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            reviews, err := ctx.ProductService.GetProductReviews(p.Context, productID, page, limit)
+            if err != nil {
+                log.Printf("❌ Error fetching reviews: %v", err)
+                return nil, err
+            }
+
+            return reviews, nil
+        }
+    }
+
+    // productReviewsConnection - Relay page of a product's approved reviews, newest first
+    if productReviewsConnectionField, ok := queryFields["productReviewsConnection"]; ok {
+        productReviewsConnectionField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+            first, after := connectionPage(p.Args)
+
+            page, err := ctx.ProductService.GetProductReviewsPage(p.Context, productID, first, after)
+            if err != nil {
+                log.Printf("❌ Error fetching reviews page: %v", err)
+                return nil, err
+            }
+
+            return relayConnection(page), nil
+        }
+    }
+
     // categories - List all categories
     if categoriesField, ok := queryFields["categories"]; ok {
         categoriesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
@@ -109,18 +333,213 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
-    // cart - Get current user's cart
+    // categoriesConnection - Relay page of the categories
+    if categoriesConnectionField, ok := queryFields["categoriesConnection"]; ok {
+        categoriesConnectionField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            first, after := connectionPage(p.Args)
+            page, err := ctx.ProductService.GetCategoriesPage(p.Context, first, after)
+            if err != nil {
+                log.Printf("❌ Error fetching categories page: %v", err)
+                return nil, err
+            }
+
+            return relayConnection(page), nil
+        }
+    }
+
+    // categoryTree - Top-level categories with nested children and product counts
+    if categoryTreeField, ok := queryFields["categoryTree"]; ok {
+        categoryTreeField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            tree, err := ctx.ProductService.GetCategoryTree(p.Context)
+            if err != nil {
+                log.Printf("❌ Error fetching category tree: %v", err)
+                return nil, err
+            }
+
+            return tree, nil
+        }
+    }
+
+    // Category.products - Products in a category, from one products list per request
+    if categoryType, ok := schema.Type("Category").(*graphql.Object); ok {
+        if productsField, ok := categoryType.Fields()["products"]; ok {
+            productsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                category, _ := p.Source.(map[string]interface{})
+                categoryID, ok := catalogID(category["id"])
+                if !ok {
+                    return []map[string]interface{}{}, nil
+                }
+
+                loader := catalogLoaderFrom(p.Context)
+                var products []map[string]interface{}
+                var err error
+                if includeSub, _ := p.Args["include_subcategories"].(bool); includeSub {
+                    products, err = loader.ProductsInCategoryTree(p.Context, ctx.ProductService, categoryID)
+                } else {
+                    products, err = loader.ProductsInCategory(p.Context, ctx.ProductService, categoryID)
+                }
+                if err != nil {
+                    log.Printf("❌ Error fetching category products: %v", err)
+                    return nil, err
+                }
+
+                return products, nil
+            }
+        }
+    }
+
+    // Category.children and Category.parent - from the categories list, unless the category
+    // came from categoryTree, which already nests its children
+    if categoryType, ok := schema.Type("Category").(*graphql.Object); ok {
+        if childrenField, ok := categoryType.Fields()["children"]; ok {
+            childrenField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                category, _ := p.Source.(map[string]interface{})
+                if children, ok := category["children"]; ok {
+                    return children, nil
+                }
+                categoryID, ok := catalogID(category["id"])
+                if !ok {
+                    return []map[string]interface{}{}, nil
+                }
+
+                children, err := catalogLoaderFrom(p.Context).Children(p.Context, ctx.ProductService, categoryID)
+                if err != nil {
+                    log.Printf("❌ Error fetching subcategories: %v", err)
+                    return nil, err
+                }
+
+                return children, nil
+            }
+        }
+        if parentField, ok := categoryType.Fields()["parent"]; ok {
+            parentField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                category, _ := p.Source.(map[string]interface{})
+                parentID, ok := catalogID(category["parent_id"])
+                if !ok {
+                    return nil, nil
+                }
+
+                parent, err := catalogLoaderFrom(p.Context).Category(p.Context, ctx.ProductService, parentID)
+                if err != nil {
+                    log.Printf("❌ Error fetching parent category: %v", err)
+                    return nil, err
+                }
+                if parent == nil {
+                    return nil, nil
+                }
+
+                return parent, nil
+            }
+        }
+    }
+
+    // Product.category - Category of a product, from one categories list per request
+    if productType, ok := schema.Type("Product").(*graphql.Object); ok {
+        if categoryField, ok := productType.Fields()["category"]; ok {
+            categoryField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                product, _ := p.Source.(map[string]interface{})
+                categoryID, ok := catalogID(product["category_id"])
+                if !ok {
+                    return nil, nil
+                }
+
+                category, err := catalogLoaderFrom(p.Context).Category(p.Context, ctx.ProductService, categoryID)
+                if err != nil {
+                    log.Printf("❌ Error fetching product category: %v", err)
+                    return nil, err
+                }
+                if category == nil {
+                    return nil, nil
+                }
+
+                return category, nil
+            }
+        }
+    }
+
+    // Product.variants - Embedded in the single-product payload; list payloads read it through the cached product
+    if productType, ok := schema.Type("Product").(*graphql.Object); ok {
+        if variantsField, ok := productType.Fields()["variants"]; ok {
+            variantsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                product, _ := p.Source.(map[string]interface{})
+                if variants, ok := product["variants"]; ok {
+                    return variants, nil
+                }
+
+                productID, ok := catalogID(product["id"])
+                if !ok {
+                    return nil, nil
+                }
+                full, err := ctx.ProductService.GetProduct(p.Context, productID)
+                if err != nil {
+                    log.Printf("❌ Error fetching product variants: %v", err)
+                    return nil, err
+                }
+                return full["variants"], nil
+            }
+        }
+    }
+
+    // Product.attributes - The attributes object as typed rows sorted by name
+    if productType, ok := schema.Type("Product").(*graphql.Object); ok {
+        if attributesField, ok := productType.Fields()["attributes"]; ok {
+            attributesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                product, _ := p.Source.(map[string]interface{})
+                attributes, _ := product["attributes"].(map[string]interface{})
+                return productAttributes(attributes), nil
+            }
+        }
+    }
+
+    // ProductVariant.attributes - The attributes object as a list sorted by name
+    if variantType, ok := schema.Type("ProductVariant").(*graphql.Object); ok {
+        if attributesField, ok := variantType.Fields()["attributes"]; ok {
+            attributesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                variant, _ := p.Source.(map[string]interface{})
+                attributes, _ := variant["attributes"].(map[string]interface{})
+
+                names := make([]string, 0, len(attributes))
+                for name := range attributes {
+                    names = append(names, name)
+                }
+                sort.Strings(names)
+
+                list := make([]map[string]interface{}, 0, len(names))
+                for _, name := range names {
+                    list = append(list, map[string]interface{}{"name": name, "value": fmt.Sprint(attributes[name])})
+                }
+                return list, nil
+            }
+        }
+    }
+
+    // attributeTemplates - Attribute templates that apply to a category
+    if attributeTemplatesField, ok := queryFields["attributeTemplates"]; ok {
+        attributeTemplatesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            categoryID := args.ID("category_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            templates, err := ctx.ProductService.GetAttributeTemplates(p.Context, categoryID)
+            if err != nil {
+                log.Printf("❌ Error fetching attribute templates: %v", err)
+                return nil, err
+            }
+
+            return templates, nil
+        }
+    }
+
+    // cart - Get current user's (or guest's) cart
     if cartField, ok := queryFields["cart"]; ok {
         cartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            user, err := GetUserFromContext(p.Context)
-            if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+            if err := requireCartCaller(p.Context); err != nil {
+                return nil, err
             }
 
-            userID := user["id"].(string)
-            cartID := userID // Simplified: use user ID as cart ID
-            
-            cart, err := ctx.CartService.GetCart(p.Context, cartID)
+            cart, err := ctx.CartService.GetCart(p.Context)
             if err != nil {
                 log.Printf("❌ Error fetching cart: %v", err)
                 return nil, err
@@ -135,125 +554,785 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         ordersField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+                return nil, err
             }
 
             userID := user["id"].(string)
-            orders, err := ctx.OrderService.GetOrders(p.Context, userID)
+            history, err := ctx.OrderService.GetOrders(p.Context, userID, orderHistoryFilterFromArgs(p.Args))
             if err != nil {
                 log.Printf("❌ Error fetching orders: %v", err)
                 return nil, err
             }
 
-            return orders, nil
+            return history.Orders, nil
+        }
+    }
+
+    // ordersConnection - Relay page of the user's order history with the orders filters
+    if ordersConnectionField, ok := queryFields["ordersConnection"]; ok {
+        ordersConnectionField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            filter := orderHistoryFilterFromArgs(p.Args)
+            filter.First, filter.After = connectionPage(p.Args)
+            if filter.First <= 0 {
+                filter.First = defaultConnectionPageSize
+            }
+
+            userID := user["id"].(string)
+            page, err := ctx.OrderService.GetOrdersPage(p.Context, userID, filter)
+            if err != nil {
+                log.Printf("❌ Error fetching orders page: %v", err)
+                return nil, err
+            }
+
+            return relayConnection(page), nil
         }
     }
 
     // order - Get single order by ID
     if orderField, ok := queryFields["order"]; ok {
         orderField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            id := p.Args["id"].(int)
-            order, err := ctx.OrderService.GetOrder(p.Context, int64(id))
+            // The orders service answers 403 for other users' orders, unless the caller is an admin
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            order, err := ctx.OrderService.GetOrder(p.Context, id)
             if err != nil {
                 log.Printf("❌ Error fetching order: %v", err)
                 return nil, err
             }
 
-            return order, nil
+            return order, nil
+        }
+    }
+
+    // checkout - Get a checkout with its orders and combined status
+    if checkoutField, ok := queryFields["checkout"]; ok {
+        checkoutField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            checkout, err := ctx.OrderService.GetCheckout(p.Context, id)
+            if isNotFound(err) || isForbidden(err) {
+                return nil, NotFound("checkout not found")
+            }
+            if err != nil {
+                log.Printf("❌ Error fetching checkout: %v", err)
+                return nil, err
+            }
+
+            // Other users' checkouts look the same as missing ones
+            if checkout["user_id"] != user["id"] {
+                return nil, NotFound("checkout not found")
+            }
+
+            return checkout, nil
+        }
+    }
+
+    // checkoutStatus - Progress of the current user's checkout saga
+    if checkoutStatusField, ok := queryFields["checkoutStatus"]; ok {
+        checkoutStatusField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            correlationID := args.String("correlation_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            return fetchCheckoutStatus(p.Context, ctx, user["id"], correlationID)
+        }
+    }
+
+    // Order.saga_state - The checkout saga behind an order
+    if orderType, ok := schema.Type("Order").(*graphql.Object); ok {
+        if sagaStateField, ok := orderType.Fields()["saga_state"]; ok {
+            sagaStateField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                order, _ := p.Source.(map[string]interface{})
+                correlationID, _ := order["saga_correlation_id"].(string)
+                if correlationID == "" {
+                    return nil, nil
+                }
+                return fetchCheckoutStatus(p.Context, ctx, order["user_id"], correlationID)
+            }
+        }
+    }
+
+    // CheckoutStatus.result and CheckoutAccepted.result - Outcome of the checkout
+    for _, typeName := range []string{"CheckoutStatus", "CheckoutAccepted"} {
+        if checkoutType, ok := schema.Type(typeName).(*graphql.Object); ok {
+            if resultField, ok := checkoutType.Fields()["result"]; ok {
+                resultField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+                    source, _ := p.Source.(map[string]interface{})
+                    correlationID, _ := source["correlation_id"].(string)
+                    return fetchCheckoutOutcome(p.Context, ctx, correlationID)
+                }
+            }
+        }
+    }
+
+    // inventory - Get product inventory status
+    if inventoryField, ok := queryFields["inventory"]; ok {
+        inventoryField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            inventory, err := ctx.ProductService.GetInventory(p.Context, productID)
+            if err != nil {
+                log.Printf("❌ Error fetching inventory: %v", err)
+                return nil, err
+            }
+
+            return inventory, nil
+        }
+    }
+
+    // adminStats - Sales stats for the admin dashboard (admin only)
+    if adminStatsField, ok := queryFields["adminStats"]; ok {
+        adminStatsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s fetching stats", user["email"])
+
+            period, _ := p.Args["period"].(string)
+            from, _ := p.Args["from"].(string)
+            to, _ := p.Args["to"].(string)
+            top, _ := p.Args["top"].(int)
+
+            stats, err := ctx.OrderService.GetAdminStats(p.Context, period, from, to, top)
+            if err != nil {
+                log.Printf("❌ Error fetching admin stats: %v", err)
+                return nil, err
+            }
+
+            return stats, nil
+        }
+    }
+
+    // funnel - Conversion funnel over the last N hours (admin only)
+    if funnelField, ok := queryFields["funnel"]; ok {
+        funnelField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s fetching funnel", user["email"])
+
+            hours, ok := p.Args["hours"].(int)
+            if !ok {
+                hours = 24
+            }
+
+            cartFunnel, err := ctx.CartService.GetAdminFunnel(p.Context, hours)
+            if err != nil {
+                log.Printf("❌ Error fetching cart funnel: %v", err)
+                return nil, err
+            }
+
+            orderFunnel, err := ctx.OrderService.GetAdminFunnel(p.Context, hours)
+            if err != nil {
+                log.Printf("❌ Error fetching order funnel: %v", err)
+                return nil, err
+            }
+
+            return mergeFunnel(cartFunnel, orderFunnel), nil
+        }
+    }
+
+    // users - Accounts for admins, filtered and paged by the users service (admin only)
+    if usersField, ok := queryFields["users"]; ok {
+        usersField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            filter := UserListFilter{
+                Role:   args.OptionalString("role"),
+                Status: args.OptionalString("status"),
+                Search: args.OptionalString("search"),
+            }
+            if page := args.OptionalInt("page"); page != nil {
+                filter.Page = *page
+            }
+            if limit := args.OptionalInt("limit"); limit != nil {
+                filter.Limit = *limit
+            }
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            page, err := ctx.UserService.ListUsers(p.Context, filter)
+            if err != nil {
+                log.Printf("❌ Error listing users: %v", err)
+                return nil, err
+            }
+
+            return page, nil
+        }
+    }
+
+    // auditLogs - One service's audit log (admin only)
+    if auditLogsField, ok := queryFields["auditLogs"]; ok {
+        auditLogsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            service := args.String("service")
+            filter := AuditLogFilter{
+                Action:     args.OptionalString("action"),
+                ActorID:    args.OptionalString("actor_id"),
+                TargetType: args.OptionalString("target_type"),
+                TargetID:   args.OptionalString("target_id"),
+            }
+            if page := args.OptionalInt("page"); page != nil {
+                filter.Page = *page
+            }
+            if limit := args.OptionalInt("limit"); limit != nil {
+                filter.Limit = *limit
+            }
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            var page map[string]interface{}
+            var err error
+            switch service {
+            case "catalog":
+                page, err = ctx.ProductService.GetAuditLogs(p.Context, filter)
+            case "users":
+                page, err = ctx.UserService.GetAuditLogs(p.Context, filter)
+            case "orders":
+                page, err = ctx.OrderService.GetAuditLogs(p.Context, filter)
+            default:
+                return nil, fmt.Errorf("unknown audit service %q", service)
+            }
+            if err != nil {
+                log.Printf("❌ Error getting %s audit logs: %v", service, err)
+                return nil, err
+            }
+
+            return page, nil
+        }
+    }
+
+    // sagaTimeline - Status history of a checkout saga (admin only)
+    if sagaTimelineField, ok := queryFields["sagaTimeline"]; ok {
+        sagaTimelineField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            args := readArgs(p)
+            correlationID := args.String("correlation_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s fetching saga timeline %s", user["email"], correlationID)
+
+            timeline, err := ctx.OrderService.GetSagaTimeline(p.Context, correlationID)
+            if isNotFound(err) {
+                return nil, NotFound("saga not found")
+            }
+            if err != nil {
+                log.Printf("❌ Error fetching saga timeline: %v", err)
+                return nil, err
+            }
+
+            return timeline, nil
+        }
+    }
+
+    // eventTimeline - Merged timeline of a checkout for support (admin only)
+    if eventTimelineField, ok := queryFields["eventTimeline"]; ok {
+        eventTimelineField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            args := readArgs(p)
+            correlationID := args.String("correlation_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s fetching event timeline %s", user["email"], correlationID)
+
+            timeline, err := fetchEventTimeline(p.Context, ctx, correlationID)
+            if isNotFound(err) {
+                return nil, NotFound("saga not found")
+            }
+            if err != nil {
+                log.Printf("❌ Error fetching event timeline: %v", err)
+                return nil, err
+            }
+
+            return timeline, nil
+        }
+    }
+
+    // announcements - Active storefront announcements
+    if announcementsField, ok := queryFields["announcements"]; ok {
+        announcementsField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            announcements, err := ctx.OrderService.GetAnnouncements(p.Context)
+            if err != nil {
+                log.Printf("❌ Error fetching announcements: %v", err)
+                return nil, err
+            }
+
+            return announcements, nil
+        }
+    }
+
+    // ========== MUTATION RESOLVERS ==========
+
+    mutationFields := schema.MutationType().Fields()
+
+    // register - Create new user account
+    if registerField, ok := mutationFields["register"]; ok {
+        registerField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            email := args.String("email")
+            args.Email("email", email)
+            username := args.String("username")
+            args.MaxLength("username", username, maxNameLength)
+            password := args.String("password")
+            args.MinLength("password", password, minPasswordLength)
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            authResp, err := ctx.UserService.Register(p.Context, email, username, password)
+            if err != nil {
+                log.Printf("❌ Registration error: %v", err)
+                return nil, err
+            }
+
+            mergeGuestCartAtLogin(p.Context, ctx.CartService, authResp)
+            return authResp, nil
+        }
+    }
+
+    // login - Authenticate user and get token
+    if loginField, ok := mutationFields["login"]; ok {
+        loginField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            email := args.String("email")
+            password := args.String("password")
+            newPassword := args.OptionalString("new_password")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            authResp, err := ctx.UserService.Login(p.Context, email, password, newPassword)
+            if err != nil {
+                log.Printf("❌ Login error: %v", err)
+                return nil, err
+            }
+
+            mergeGuestCartAtLogin(p.Context, ctx.CartService, authResp)
+            return authResp, nil
+        }
+    }
+
+    // createGuestToken - Start a guest session for a cart before sign-in
+    if createGuestTokenField, ok := mutationFields["createGuestToken"]; ok {
+        createGuestTokenField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if ctx.GuestTokens == nil {
+                return nil, Unavailable("guest carts are disabled", nil)
+            }
+
+            token, guestID, expiresAt, err := ctx.GuestTokens.Issue()
+            if err != nil {
+                log.Printf("❌ Error issuing guest token: %v", err)
+                return nil, err
+            }
+
+            return map[string]interface{}{
+                "token":      token,
+                "guest_id":   guestID,
+                "expires_at": expiresAt.UTC().Format(time.RFC3339),
+            }, nil
+        }
+    }
+
+    // mergeGuestCart - Fold the guest's cart into the signed-in user's
+    if mergeGuestCartField, ok := mutationFields["mergeGuestCart"]; ok {
+        mergeGuestCartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
+            }
+
+            guestToken := guestTokenFromContext(p.Context)
+            if guestToken == "" {
+                return nil, Validation("X-Guest-Token header required")
+            }
+
+            authHeader, _ := p.Context.Value(AuthTokenContextKey).(string)
+            cart, err := ctx.CartService.MergeGuestCart(p.Context, authHeader, guestToken)
+            if err != nil {
+                log.Printf("❌ Error merging guest cart: %v", err)
+                return nil, err
+            }
+
+            return cart, nil
+        }
+    }
+
+    // logout - Revoke the caller's token
+    if logoutField, ok := mutationFields["logout"]; ok {
+        logoutField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
+            }
+
+            token, _ := p.Context.Value(AuthTokenContextKey).(string)
+            if err := ctx.TokenValidator.RevokeToken(p.Context, token); err != nil {
+                log.Printf("❌ Logout error: %v", err)
+                return nil, err
+            }
+
+            return true, nil
+        }
+    }
+
+    // recordProductView - Track a product view for recentlyViewed
+    if recordViewField, ok := mutationFields["recordProductView"]; ok {
+        recordViewField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            if ctx.RecentlyViewed == nil {
+                return false, nil
+            }
+
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+            // Only real products are tracked; the lookup is usually a catalog cache hit
+            if _, err := ctx.ProductService.GetProduct(p.Context, productID); err != nil {
+                return nil, err
+            }
+
+            if err := ctx.RecentlyViewed.Record(p.Context, user["id"].(string), productID); err != nil {
+                log.Printf("❌ Error recording product view: %v", err)
+                return nil, Unavailable("failed to record view", err)
+            }
+
+            return true, nil
+        }
+    }
+
+    // updateProfile - Change the current user's email, username or password
+    if updateProfileField, ok := mutationFields["updateProfile"]; ok {
+        updateProfileField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            // The user ID comes from the token, so only the caller's own profile can change
+            changes := map[string]interface{}{}
+            for _, key := range []string{"email", "username", "current_password", "new_password"} {
+                if val, ok := p.Args[key]; ok {
+                    changes[key] = val
+                }
+            }
+            if len(changes) == 0 {
+                return nil, Validation("nothing to update")
+            }
+
+            profile, err := ctx.UserService.UpdateProfile(p.Context, user["id"].(string), changes)
+            if err != nil {
+                log.Printf("❌ Error updating profile: %v", err)
+                return nil, err
+            }
+
+            return profile, nil
+        }
+    }
+
+    // deleteAccount - Delete the current user's account
+    if deleteAccountField, ok := mutationFields["deleteAccount"]; ok {
+        deleteAccountField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            password, _ := p.Args["password"].(string)
+            if err := ctx.UserService.DeleteAccount(p.Context, user["id"].(string), password); err != nil {
+                log.Printf("❌ Error deleting account: %v", err)
+                return nil, err
+            }
+
+            // The account is gone either way; a token that outlives it only fails later lookups
+            token, _ := p.Context.Value(AuthTokenContextKey).(string)
+            if err := ctx.TokenValidator.RevokeToken(p.Context, token); err != nil {
+                log.Printf("⚠️  Failed to revoke token of deleted account %s: %v", user["id"], err)
+            }
+
+            log.Printf("✓ Account deleted: %s", user["id"])
+            return true, nil
+        }
+    }
+
+    // updatePreferences - Change the current user's preferences
+    if updatePreferencesField, ok := mutationFields["updatePreferences"]; ok {
+        updatePreferencesField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            // Only the arguments given are sent, so the others stay as they are
+            changes := map[string]interface{}{}
+            for _, key := range []string{"locale", "currency", "marketing_opt_in"} {
+                if val, ok := p.Args[key]; ok {
+                    changes[key] = val
+                }
+            }
+
+            prefs, err := ctx.UserService.UpdatePreferences(p.Context, user["id"].(string), changes)
+            if err != nil {
+                log.Printf("❌ Error updating preferences: %v", err)
+                return nil, err
+            }
+
+            return prefs, nil
+        }
+    }
+
+    // addAddress - Save an address for the current user
+    if addAddressField, ok := mutationFields["addAddress"]; ok {
+        addAddressField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            // The users service trims, validates and upper-cases the country itself
+            address := map[string]interface{}{}
+            for _, key := range []string{"label", "recipient_name", "line1", "line2", "city", "region", "postal_code", "country", "phone", "is_default"} {
+                if val, ok := p.Args[key]; ok {
+                    address[key] = val
+                }
+            }
+
+            created, err := ctx.UserService.AddAddress(p.Context, user["id"].(string), address)
+            if err != nil {
+                log.Printf("❌ Error adding address: %v", err)
+                return nil, err
+            }
+
+            return created, nil
+        }
+    }
+
+    // addToCart - Add product to user's (or guest's) cart
+    if addToCartField, ok := mutationFields["addToCart"]; ok {
+        addToCartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if err := requireCartCaller(p.Context); err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            quantity := args.Int("quantity")
+            variantID := args.OptionalID("variant_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            if quantity <= 0 || quantity > maxQuantity {
+                return cartRejected(ReasonInvalidQuantity, fmt.Sprintf("quantity must be between 1 and %d", maxQuantity), productID, nil), nil
+            }
+
+            // Check stock up front so the client gets OUT_OF_STOCK instead of a failed checkout later.
+            // If products is unavailable, let the cart decide rather than blocking the add.
+            // A variant has its own stock.
+            var inventory map[string]interface{}
+            var err error
+            if variantID != nil {
+                inventory, err = ctx.ProductService.GetVariantInventory(p.Context, productID, *variantID)
+            } else {
+                inventory, err = ctx.ProductService.GetInventory(p.Context, productID)
+            }
+            if err != nil {
+                if isNotFound(err) {
+                    return cartRejected(ReasonProductNotFound, "product not found", productID, nil), nil
+                }
+                log.Printf("⚠️  Skipping stock check for product %d: %v", productID, err)
+            } else if available, ok := inventory["available_quantity"].(float64); ok && int(available) < quantity {
+                availableQty := int(available)
+                return cartRejected(ReasonOutOfStock, fmt.Sprintf("only %d units available", availableQty), productID, &availableQty), nil
+            }
+
+            // The cart prices the line itself and rejects this price if it has moved since
+            product, err := ctx.ProductService.GetProduct(p.Context, productID)
+            if err != nil {
+                if isNotFound(err) {
+                    return cartRejected(ReasonProductNotFound, "product not found", productID, nil), nil
+                }
+                log.Printf("❌ Error fetching product price: %v", err)
+                return nil, err
+            }
+            price, _ := product["price"].(float64)
+            if variantID != nil {
+                variant := findVariant(product, *variantID)
+                if variant == nil {
+                    return cartRejected(ReasonProductNotFound, "variant not found", productID, nil), nil
+                }
+                price, _ = variant["price"].(float64)
+            }
+
+            log.Printf("✓ %s adding product %d to cart", cartCallerName(p.Context), productID)
+            cart, softLock, err := ctx.CartService.AddToCart(p.Context, productID, variantID, quantity, price)
+            if err != nil {
+                if reason, message, ok := classifyCartError(err); ok {
+                    return cartRejected(reason, message, productID, shortageAvailable(err)), nil
+                }
+                log.Printf("❌ Error adding to cart: %v", err)
+                return nil, err
+            }
+
+            return cartUpdated(cart, softLock), nil
         }
     }
 
-    // inventory - Get product inventory status
-    if inventoryField, ok := queryFields["inventory"]; ok {
-        inventoryField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            productID := p.Args["product_id"].(int)
+    // addReview - Review a product as the current user (pending until moderated)
+    if addReviewField, ok := mutationFields["addReview"]; ok {
+        addReviewField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            rating := args.Int("rating")
+            args.Check(rating >= 1 && rating <= 5, "rating", "must be between 1 and 5")
+            text := args.OptionalString("text")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            inventory, err := ctx.ProductService.GetInventory(p.Context, int64(productID))
+            review, err := ctx.ProductService.AddReview(p.Context, productID, rating, text)
             if err != nil {
-                log.Printf("❌ Error fetching inventory: %v", err)
+                log.Printf("❌ Error adding review: %v", err)
                 return nil, err
             }
 
-            return inventory, nil
+            return review, nil
         }
     }
 
-    // ========== MUTATION RESOLVERS ==========
-
-    mutationFields := schema.MutationType().Fields()
+    // notifyWhenInStock - Subscribe the current user to an out-of-stock product
+    if notifyField, ok := mutationFields["notifyWhenInStock"]; ok {
+        notifyField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
 
-    // register - Create new user account
-    if registerField, ok := mutationFields["register"]; ok {
-        registerField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            email := p.Args["email"].(string)
-            username := p.Args["username"].(string)
-            password := p.Args["password"].(string)
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            authResp, err := ctx.UserService.Register(p.Context, email, username, password)
-            if err != nil {
-                log.Printf("❌ Registration error: %v", err)
+            if err := ctx.ProductService.NotifyMe(p.Context, productID, user["id"].(string)); err != nil {
+                log.Printf("❌ Error subscribing to product %d: %v", productID, err)
                 return nil, err
             }
 
-            return authResp, nil
+            return true, nil
         }
     }
 
-    // login - Authenticate user and get token
-    if loginField, ok := mutationFields["login"]; ok {
-        loginField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            email := p.Args["email"].(string)
-            password := p.Args["password"].(string)
+    // removeFromCart - Remove product from user's (or guest's) cart
+    if removeFromCartField, ok := mutationFields["removeFromCart"]; ok {
+        removeFromCartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if err := requireCartCaller(p.Context); err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            variantID := args.OptionalID("variant_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            authResp, err := ctx.UserService.Login(p.Context, email, password)
+            cart, err := ctx.CartService.RemoveFromCart(p.Context, productID, variantID)
             if err != nil {
-                log.Printf("❌ Login error: %v", err)
+                log.Printf("❌ Error removing from cart: %v", err)
                 return nil, err
             }
 
-            return authResp, nil
+            return cart, nil
         }
     }
 
-    // addToCart - Add product to user's cart
-    if addToCartField, ok := mutationFields["addToCart"]; ok {
-        addToCartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            user, err := GetUserFromContext(p.Context)
-            if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+    // applyCoupon - Put a coupon code on user's cart
+    if applyCouponField, ok := mutationFields["applyCoupon"]; ok {
+        applyCouponField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
             }
 
-            userID := user["id"].(string)
-            cartID := userID // Simplified: use user ID as cart ID
-
-            productID := p.Args["product_id"].(int)
-            quantity := p.Args["quantity"].(int)
+            code, _ := p.Args["code"].(string)
+            code = strings.TrimSpace(code)
+            if code == "" {
+                return couponRejected(ReasonInvalidRequest, "code must not be blank"), nil
+            }
 
-            cart, err := ctx.CartService.AddToCart(p.Context, cartID, int64(productID), quantity)
+            cart, err := ctx.CartService.ApplyCoupon(p.Context, code)
             if err != nil {
-                log.Printf("❌ Error adding to cart: %v", err)
+                if reason, message, ok := classifyCouponError(err); ok {
+                    return couponRejected(reason, message), nil
+                }
+                log.Printf("❌ Error applying coupon: %v", err)
                 return nil, err
             }
 
-            return cart, nil
+            return cartUpdated(cart, nil), nil
         }
     }
 
-    // removeFromCart - Remove product from user's cart
-    if removeFromCartField, ok := mutationFields["removeFromCart"]; ok {
-        removeFromCartField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            user, err := GetUserFromContext(p.Context)
-            if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+    // removeCoupon - Take the coupon off user's cart
+    if removeCouponField, ok := mutationFields["removeCoupon"]; ok {
+        removeCouponField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
             }
 
-            userID := user["id"].(string)
-            cartID := userID // Simplified: use user ID as cart ID
-            productID := p.Args["product_id"].(int)
-
-            cart, err := ctx.CartService.RemoveFromCart(p.Context, cartID, int64(productID))
+            cart, err := ctx.CartService.RemoveCoupon(p.Context)
             if err != nil {
-                log.Printf("❌ Error removing from cart: %v", err)
+                log.Printf("❌ Error removing coupon: %v", err)
                 return nil, err
             }
 
@@ -264,31 +1343,44 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
     // checkout - Convert cart to order (triggers saga)
     if checkoutField, ok := mutationFields["checkout"]; ok {
         checkoutField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            user, err := GetUserFromContext(p.Context)
-            if err != nil {
-                return nil, fmt.Errorf("❌ %v", err)
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
             }
 
-            userID := user["id"].(string)
-            cartID := userID // Simplified: use user ID as cart ID
+            args := readArgs(p)
+            addressID := args.String("address_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            // Call checkout which initiates saga and returns order
-            result, err := ctx.CartService.Checkout(p.Context, cartID)
+            // Call checkout which initiates saga (order is created asynchronously)
+            result, err := ctx.CartService.Checkout(p.Context, addressID)
             if err != nil {
+                if reason, message, ok := classifyCheckoutError(err); ok {
+                    return withPriceValidation(checkoutRejected(reason, message), err), nil
+                }
                 log.Printf("❌ Checkout error: %v", err)
                 return nil, err
             }
 
-            return result, nil
+            return checkoutAccepted(result), nil
         }
     }
 
     // cancelOrder - Cancel an existing order
     if cancelOrderField, ok := mutationFields["cancelOrder"]; ok {
         cancelOrderField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            id := p.Args["id"].(int)
+            if _, err := GetUserFromContext(p.Context); err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            order, err := ctx.OrderService.CancelOrder(p.Context, int64(id))
+            order, err := ctx.OrderService.CancelOrder(p.Context, id)
             if err != nil {
                 log.Printf("❌ Error cancelling order: %v", err)
                 return nil, err
@@ -298,52 +1390,90 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
+    // retryPayment - Retry the payment of the user's payment_pending order
+    if retryPaymentField, ok := mutationFields["retryPayment"]; ok {
+        retryPaymentField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+
+            args := readArgs(p)
+            orderID := args.ID("order_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            order, err := ctx.OrderService.GetOrder(p.Context, orderID)
+            if isNotFound(err) || isForbidden(err) {
+                return nil, NotFound("order not found")
+            }
+            if err != nil {
+                log.Printf("❌ Error fetching order: %v", err)
+                return nil, err
+            }
+
+            // Other users' orders look the same as missing ones
+            if order["user_id"] != user["id"] {
+                return nil, NotFound("order not found")
+            }
+
+            retry, err := ctx.OrderService.RetryPayment(p.Context, orderID)
+            if err != nil {
+                log.Printf("❌ Error retrying payment: %v", err)
+                return nil, err
+            }
+
+            return retry, nil
+        }
+    }
+
     // createProduct - Create a new product (admin only)
     if createProductField, ok := mutationFields["createProduct"]; ok {
         createProductField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
             // Verify authentication (admin operation)
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ unauthenticated - admin operation")
+                return nil, Unauthorized("authentication required for admin operation")
             }
             log.Printf("✓ Admin user %s creating product", user["email"])
 
-            // Extract arguments
-            name := p.Args["name"].(string)
-            price := p.Args["price"].(float64)
-            
-            var description, sku *string
-            var stockQuantity, categoryID *int
-            
-            if desc, ok := p.Args["description"]; ok {
-                if d, ok := desc.(string); ok && d != "" {
-                    description = &d
-                }
+            // Extract and validate arguments
+            args := readArgs(p)
+            name := args.String("name")
+            args.MaxLength("name", name, maxNameLength)
+            price := args.Float("price")
+            args.Price("price", price)
+            description := args.OptionalString("description")
+            sku := args.OptionalString("sku")
+            stockQuantity := args.OptionalInt("stock_quantity")
+            if stockQuantity != nil {
+                args.NonNegative("stock_quantity", *stockQuantity)
             }
-            if s, ok := p.Args["sku"]; ok {
-                if sk, ok := s.(string); ok && sk != "" {
-                    sku = &sk
-                }
+            categoryID := args.OptionalInt("category_id")
+            if categoryID != nil {
+                args.Check(*categoryID > 0, "category_id", "must be a positive ID")
             }
-            if sq, ok := p.Args["stock_quantity"]; ok {
-                if st, ok := sq.(int); ok {
-                    stockQuantity = &st
-                }
+            if err := args.Err(); err != nil {
+                return nil, err
             }
-            if cid, ok := p.Args["category_id"]; ok {
-                if ci, ok := cid.(int); ok {
-                    categoryID = &ci
+
+            var attributes map[string]interface{}
+            if list, ok := p.Args["attributes"].([]interface{}); ok {
+                if attributes, err = attributeInputs(list); err != nil {
+                    return nil, err
                 }
             }
 
             product, err := ctx.ProductService.CreateProduct(
                 p.Context,
                 name,
-                *description,
+                description,
                 price,
-                *sku,
+                sku,
                 stockQuantity,
                 categoryID,
+                attributes,
             )
             if err != nil {
                 log.Printf("❌ Error creating product: %v", err)
@@ -361,51 +1491,54 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
             // Verify authentication (admin operation)
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ unauthenticated - admin operation")
+                return nil, Unauthorized("authentication required for admin operation")
             }
             log.Printf("✓ Admin user %s updating product", user["email"])
 
-            // Extract arguments
-            id := p.Args["id"].(int)
-            
+            // Extract and validate arguments; empty name and description leave them as they are
+            args := readArgs(p)
+            id := args.ID("id")
+
             var name, description *string
-            var price *float64
-            var stockQuantity, categoryID *int
-            
-            if n, ok := p.Args["name"]; ok {
-                if nm, ok := n.(string); ok && nm != "" {
-                    name = &nm
-                }
+            if nm := args.OptionalString("name"); nm != "" {
+                args.MaxLength("name", nm, maxNameLength)
+                name = &nm
             }
-            if d, ok := p.Args["description"]; ok {
-                if desc, ok := d.(string); ok && desc != "" {
-                    description = &desc
-                }
+            if desc := args.OptionalString("description"); desc != "" {
+                description = &desc
             }
-            if pr, ok := p.Args["price"]; ok {
-                if prc, ok := pr.(float64); ok && prc > 0 {
-                    price = &prc
-                }
+            price := args.OptionalFloat("price")
+            if price != nil {
+                args.Price("price", *price)
             }
-            if sq, ok := p.Args["stock_quantity"]; ok {
-                if st, ok := sq.(int); ok {
-                    stockQuantity = &st
-                }
+            stockQuantity := args.OptionalInt("stock_quantity")
+            if stockQuantity != nil {
+                args.NonNegative("stock_quantity", *stockQuantity)
+            }
+            categoryID := args.OptionalInt("category_id")
+            if categoryID != nil {
+                args.Check(*categoryID > 0, "category_id", "must be a positive ID")
             }
-            if cid, ok := p.Args["category_id"]; ok {
-                if ci, ok := cid.(int); ok {
-                    categoryID = &ci
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            var attributes map[string]interface{}
+            if list, ok := p.Args["attributes"].([]interface{}); ok {
+                if attributes, err = attributeInputs(list); err != nil {
+                    return nil, err
                 }
             }
 
             product, err := ctx.ProductService.UpdateProduct(
                 p.Context,
-                int64(id),
+                id,
                 name,
                 description,
                 price,
                 stockQuantity,
                 categoryID,
+                attributes,
             )
             if err != nil {
                 log.Printf("❌ Error updating product: %v", err)
@@ -423,13 +1556,17 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
             // Verify authentication (admin operation)
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ unauthenticated - admin operation")
+                return nil, Unauthorized("authentication required for admin operation")
             }
             log.Printf("✓ Admin user %s deleting product", user["email"])
 
-            id := p.Args["id"].(int)
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            message, err := ctx.ProductService.DeleteProduct(p.Context, int64(id))
+            message, err := ctx.ProductService.DeleteProduct(p.Context, id)
             if err != nil {
                 log.Printf("❌ Error deleting product: %v", err)
                 return nil, err
@@ -440,25 +1577,176 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
+    // addVariant - Add a variant to a product (admin only)
+    if addVariantField, ok := mutationFields["addVariant"]; ok {
+        addVariantField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s adding variant", user["email"])
+
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            sku := args.String("sku")
+            priceOverride := args.OptionalFloat("price_override")
+            if priceOverride != nil {
+                args.Check(*priceOverride > 0, "price_override", "must be greater than zero")
+            }
+            var stock int
+            if quantity := args.OptionalInt("stock_quantity"); quantity != nil {
+                stock = *quantity
+                args.NonNegative("stock_quantity", stock)
+            }
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            attributes := map[string]string{}
+            list, _ := p.Args["attributes"].([]interface{})
+            for _, entry := range list {
+                attribute, _ := entry.(map[string]interface{})
+                name, _ := attribute["name"].(string)
+                value, _ := attribute["value"].(string)
+                if name == "" {
+                    return nil, Validation("attribute names must not be empty")
+                }
+                if _, dup := attributes[name]; dup {
+                    return nil, Validation(fmt.Sprintf("attribute %q is given more than once", name))
+                }
+                attributes[name] = value
+            }
+            if len(attributes) == 0 {
+                return nil, Validation("a variant needs at least one attribute")
+            }
+
+            variant, err := ctx.ProductService.AddVariant(p.Context, productID, sku, attributes, priceOverride, stock)
+            if err != nil {
+                log.Printf("❌ Error adding variant: %v", err)
+                return nil, err
+            }
+
+            log.Printf("✓ Variant %s added to product %d", sku, productID)
+            return variant, nil
+        }
+    }
+
+    // createAttributeTemplate - Add an attribute template to a category (admin only)
+    if createTemplateField, ok := mutationFields["createAttributeTemplate"]; ok {
+        createTemplateField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s creating attribute template", user["email"])
+
+            args := readArgs(p)
+            categoryID := args.ID("category_id")
+            name := args.String("name")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            template := map[string]interface{}{
+                "name":     name,
+                "type":     p.Args["type"],
+                "required": p.Args["required"],
+            }
+            for _, field := range []string{"label", "unit", "options"} {
+                if value, ok := p.Args[field]; ok && value != nil {
+                    template[field] = value
+                }
+            }
+
+            created, err := ctx.ProductService.CreateAttributeTemplate(p.Context, categoryID, template)
+            if err != nil {
+                log.Printf("❌ Error creating attribute template: %v", err)
+                return nil, err
+            }
+
+            log.Printf("✓ Attribute template %s added to category %d", name, categoryID)
+            return created, nil
+        }
+    }
+
+    // updateAttributeTemplate - Change an attribute template (admin only)
+    if updateTemplateField, ok := mutationFields["updateAttributeTemplate"]; ok {
+        updateTemplateField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s updating attribute template", user["email"])
+
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            changes := map[string]interface{}{}
+            for _, field := range []string{"label", "unit", "options", "required"} {
+                if value, ok := p.Args[field]; ok && value != nil {
+                    changes[field] = value
+                }
+            }
+
+            updated, err := ctx.ProductService.UpdateAttributeTemplate(p.Context, id, changes)
+            if err != nil {
+                log.Printf("❌ Error updating attribute template: %v", err)
+                return nil, err
+            }
+
+            log.Printf("✓ Attribute template %d updated", id)
+            return updated, nil
+        }
+    }
+
+    // deleteAttributeTemplate - Remove an attribute template (admin only)
+    if deleteTemplateField, ok := mutationFields["deleteAttributeTemplate"]; ok {
+        deleteTemplateField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            user, err := GetUserFromContext(p.Context)
+            if err != nil {
+                return nil, err
+            }
+            log.Printf("✓ Admin user %s deleting attribute template", user["email"])
+
+            args := readArgs(p)
+            id := args.ID("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            if err := ctx.ProductService.DeleteAttributeTemplate(p.Context, id); err != nil {
+                log.Printf("❌ Error deleting attribute template: %v", err)
+                return nil, err
+            }
+
+            log.Printf("✓ Attribute template %d deleted", id)
+            return true, nil
+        }
+    }
+
     // createCategory - Create a new category (admin only)
     if createCategoryField, ok := mutationFields["createCategory"]; ok {
         createCategoryField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
             // Verify authentication (admin operation)
             user, err := GetUserFromContext(p.Context)
             if err != nil {
-                return nil, fmt.Errorf("❌ unauthenticated - admin operation")
+                return nil, Unauthorized("authentication required for admin operation")
             }
             log.Printf("✓ Admin user %s creating category", user["email"])
 
-            name := p.Args["name"].(string)
-            var description string
-            if desc, ok := p.Args["description"]; ok {
-                if d, ok := desc.(string); ok {
-                    description = d
-                }
+            args := readArgs(p)
+            name := args.String("name")
+            args.MaxLength("name", name, maxNameLength)
+            description := args.OptionalString("description")
+            parentID := args.OptionalID("parent_id")
+            if err := args.Err(); err != nil {
+                return nil, err
             }
 
-            category, err := ctx.ProductService.CreateCategory(p.Context, name, description)
+            category, err := ctx.ProductService.CreateCategory(p.Context, name, description, parentID)
             if err != nil {
                 log.Printf("❌ Error creating category: %v", err)
                 return nil, err
@@ -469,17 +1757,29 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
         }
     }
 
-    //reserveInventory - Reserve product inventory
+    // reserveInventory - Hold product inventory (admin only)
     if reserveField, ok := mutationFields["reserveInventory"]; ok {
         reserveField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            productId := p.Args["product_id"].(int)
-            quantity := p.Args["quantity"].(int)
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            quantity := args.Int("quantity")
+            args.Quantity("quantity", quantity)
+            ttlSeconds := 0
+            if ttl := args.OptionalInt("ttl_seconds"); ttl != nil {
+                args.Check(*ttl > 0, "ttl_seconds", "must be positive")
+                ttlSeconds = *ttl
+            }
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            result, err := ctx.ProductService.ReserveInventory(p.Context,int64(productId),quantity)
+            result, err := ctx.ProductService.ReserveInventory(p.Context, productID, quantity, ttlSeconds)
             if err != nil {
-                log.Printf("Error reserving inventory: %v", err)
+                log.Printf("❌ Error reserving inventory: %v", err)
+                return nil, err
             }
-            log.Printf("Reserved %d units of product %d", quantity, productId)
+
+            log.Printf("✓ Reserved %d units of product %d", quantity, productID)
             return result, nil
         }
     }
@@ -487,10 +1787,15 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
     // releaseInventory - Release reserved inventory
     if releaseField, ok := mutationFields["releaseInventory"]; ok {
         releaseField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
-            productID := p.Args["product_id"].(int)
-            quantity := p.Args["quantity"].(int)
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            quantity := args.Int("quantity")
+            args.Quantity("quantity", quantity)
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
 
-            result, err := ctx.ProductService.ReleaseInventory(p.Context, int64(productID), quantity)
+            result, err := ctx.ProductService.ReleaseInventory(p.Context, productID, quantity)
             if err != nil {
                 log.Printf("❌ Error releasing inventory: %v", err)
                 return nil, err
@@ -500,6 +1805,170 @@ func AttachResolvers(schema *graphql.Schema, ctx *ResolverContext) {
             return result, nil
         }
     }
-    
+
+    // adjustInventory - Change stock by hand for a reason (admin only)
+    if adjustField, ok := mutationFields["adjustInventory"]; ok {
+        adjustField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            productID := args.ID("product_id")
+            delta := args.Int("delta")
+            args.Check(delta != 0, "delta", "must not be 0")
+            reason := args.String("reason") // the products service checks it's a known reason
+            note := args.OptionalString("note")
+            variantID := args.OptionalID("variant_id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            movement, err := ctx.ProductService.AdjustInventory(p.Context, productID, variantID, delta, reason, note)
+            if err != nil {
+                log.Printf("❌ Error adjusting inventory: %v", err)
+                return nil, err
+            }
+
+            log.Printf("✓ Adjusted product %d stock by %d (%s)", productID, delta, reason)
+            return movement, nil
+        }
+    }
+
+    // disableUser, enableUser, forcePasswordReset - Admin account actions on the users service
+    adminUserActions := map[string]string{
+        "disableUser":        "disable",
+        "enableUser":         "enable",
+        "forcePasswordReset": "password-reset",
+    }
+    for fieldName, action := range adminUserActions {
+        field, ok := mutationFields[fieldName]
+        if !ok {
+            continue
+        }
+        field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            userID := args.String("id")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            user, err := ctx.UserService.AdminUserAction(p.Context, userID, action)
+            if err != nil {
+                log.Printf("❌ Error on user %s (%s): %v", userID, action, err)
+                return nil, err
+            }
+
+            log.Printf("✓ User %s: %s", userID, action)
+            return user, nil
+        }
+    }
+
+    // updateUserRole - Change an account's role (admin only)
+    if updateUserRoleField, ok := mutationFields["updateUserRole"]; ok {
+        updateUserRoleField.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+            args := readArgs(p)
+            userID := args.String("id")
+            role := args.String("role")
+            if err := args.Err(); err != nil {
+                return nil, err
+            }
+
+            user, err := ctx.UserService.UpdateUserRole(p.Context, userID, role)
+            if err != nil {
+                log.Printf("❌ Error updating role of user %s: %v", userID, err)
+                return nil, err
+            }
+
+            log.Printf("✓ Role of user %s set to %s", userID, role)
+            return user, nil
+        }
+    }
+
+    // ========== SUBSCRIPTION RESOLVERS ==========
+
+    // announcementPublished - Announcements as they are published (resolved from the hub's values)
+    if subscriptionType := schema.SubscriptionType(); subscriptionType != nil {
+        if publishedField, ok := subscriptionType.Fields()["announcementPublished"]; ok {
+            publishedField.Subscribe = func(p graphql.ResolveParams) (interface{}, error) {
+                return ctx.Announcements.Subscribe(p.Context), nil
+            }
+        }
+    }
+
     log.Println("✓ Resolvers attached to schema")
-}
\ No newline at end of file
+}
+
+// orderHistoryFilterFromArgs reads the orders field filter arguments
+func orderHistoryFilterFromArgs(args map[string]interface{}) OrderHistoryFilter {
+    var filter OrderHistoryFilter
+
+    if statuses, ok := args["status"].([]interface{}); ok {
+        for _, s := range statuses {
+            if status, ok := s.(string); ok && status != "" {
+                filter.Statuses = append(filter.Statuses, status)
+            }
+        }
+    }
+    if from, ok := args["from"].(string); ok {
+        filter.From = from
+    }
+    if to, ok := args["to"].(string); ok {
+        filter.To = to
+    }
+    if minTotal, ok := args["min_total"].(float64); ok {
+        filter.MinTotal = &minTotal
+    }
+    if maxTotal, ok := args["max_total"].(float64); ok {
+        filter.MaxTotal = &maxTotal
+    }
+    if page, ok := args["page"].(int); ok {
+        filter.Page = page
+    }
+    if limit, ok := args["limit"].(int); ok {
+        filter.Limit = limit
+    }
+    if sort, ok := args["sort"].(string); ok {
+        filter.Sort = sort
+    }
+
+    return filter
+}
+
+// mergeFunnel combines the cart and orders halves of the funnel and computes step rates
+func mergeFunnel(cartFunnel, orderFunnel map[string]interface{}) map[string]interface{} {
+    carts, _ := cartFunnel["carts_created"].(float64)
+    checkouts, _ := cartFunnel["checkouts_initiated"].(float64)
+    completed, _ := orderFunnel["sagas_completed"].(float64)
+
+    failures := []map[string]interface{}{}
+    if byReason, ok := orderFunnel["failures_by_reason"].(map[string]interface{}); ok {
+        for reason, count := range byReason {
+            failures = append(failures, map[string]interface{}{"reason": reason, "count": count})
+        }
+    }
+    sort.Slice(failures, func(i, j int) bool {
+        ci, _ := failures[i]["count"].(float64)
+        cj, _ := failures[j]["count"].(float64)
+        if ci != cj {
+            return ci > cj
+        }
+        return failures[i]["reason"].(string) < failures[j]["reason"].(string)
+    })
+
+    funnel := map[string]interface{}{
+        "from":                cartFunnel["from"],
+        "to":                  cartFunnel["to"],
+        "carts_created":       carts,
+        "checkouts_initiated": checkouts,
+        "sagas_completed":     completed,
+        "sagas_failed":        orderFunnel["sagas_failed"],
+        "failures_by_reason":  failures,
+        "revenue_confirmed":   orderFunnel["revenue_confirmed"],
+        "checkout_rate":       0.0,
+        "completion_rate":     0.0,
+    }
+    if carts > 0 {
+        funnel["checkout_rate"] = checkouts / carts
+    }
+    if checkouts > 0 {
+        funnel["completion_rate"] = completed / checkouts
+    }
+    return funnel
+}
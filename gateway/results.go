@@ -0,0 +1,431 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "github.com/graphql-go/graphql"
+)
+
+// Mutation result unions
+// Why: expected business failures (empty cart, out of stock, ...) are returned as
+// typed data the client can switch on, instead of opaque GraphQL errors.
+// Infrastructure failures (downstream 5xx, open breaker, auth) stay GraphQL errors.
+
+// Rejection reasons exposed through the RejectionReason enum
+const (
+    ReasonCartEmpty          = "CART_EMPTY"
+    ReasonCartNotFound       = "CART_NOT_FOUND"
+    ReasonOutOfStock         = "OUT_OF_STOCK"
+    ReasonProductNotFound    = "PRODUCT_NOT_FOUND"
+    ReasonInvalidQuantity    = "INVALID_QUANTITY"
+    ReasonInvalidRequest     = "INVALID_REQUEST"
+    ReasonPriceChanged       = "PRICE_CHANGED"
+    ReasonProductUnavailable = "PRODUCT_UNAVAILABLE"
+    ReasonCouponNotFound     = "COUPON_NOT_FOUND"
+    ReasonCouponExpired      = "COUPON_EXPIRED"
+    ReasonCouponUnavailable  = "COUPON_UNAVAILABLE"
+    ReasonAddressNotFound    = "ADDRESS_NOT_FOUND"
+)
+
+// typenameKey tags result maps with their concrete union member
+const typenameKey = "__typename"
+
+// MutationResultTypes holds the union types returned by business mutations
+type MutationResultTypes struct {
+    CheckoutResult    *graphql.Union
+    AddToCartResult   *graphql.Union
+    ApplyCouponResult *graphql.Union
+}
+
+// buildMutationResultTypes builds CheckoutResult, AddToCartResult and ApplyCouponResult
+func buildMutationResultTypes(cartType, checkoutOutcomeType *graphql.Object) MutationResultTypes {
+    reasonEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name:        "RejectionReason",
+        Description: "Why a mutation was rejected",
+        Values: graphql.EnumValueConfigMap{
+            ReasonCartEmpty:          &graphql.EnumValueConfig{Value: ReasonCartEmpty},
+            ReasonCartNotFound:       &graphql.EnumValueConfig{Value: ReasonCartNotFound},
+            ReasonOutOfStock:         &graphql.EnumValueConfig{Value: ReasonOutOfStock},
+            ReasonProductNotFound:    &graphql.EnumValueConfig{Value: ReasonProductNotFound},
+            ReasonInvalidQuantity:    &graphql.EnumValueConfig{Value: ReasonInvalidQuantity},
+            ReasonInvalidRequest:     &graphql.EnumValueConfig{Value: ReasonInvalidRequest},
+            ReasonPriceChanged:       &graphql.EnumValueConfig{Value: ReasonPriceChanged},
+            ReasonProductUnavailable: &graphql.EnumValueConfig{Value: ReasonProductUnavailable},
+            ReasonCouponNotFound:     &graphql.EnumValueConfig{Value: ReasonCouponNotFound},
+            ReasonCouponExpired:      &graphql.EnumValueConfig{Value: ReasonCouponExpired},
+            ReasonCouponUnavailable:  &graphql.EnumValueConfig{Value: ReasonCouponUnavailable},
+            ReasonAddressNotFound:    &graphql.EnumValueConfig{Value: ReasonAddressNotFound},
+        },
+    })
+
+    priceChangeType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "PriceChange",
+        Fields: graphql.Fields{
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "quantity": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "old_price": &graphql.Field{
+                Type: graphql.Float,
+            },
+            "new_price": &graphql.Field{
+                Type: graphql.Float,
+            },
+        },
+    })
+
+    checkoutAcceptedType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "CheckoutAccepted",
+        Fields: graphql.Fields{
+            "correlation_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "status": &graphql.Field{
+                Type: graphql.String,
+            },
+            "message": &graphql.Field{
+                Type: graphql.String,
+            },
+            "result": &graphql.Field{
+                Type:        graphql.NewNonNull(checkoutOutcomeType),
+                Description: "Outcome so far; PENDING right after checkout, poll checkoutStatus { result } for the final one",
+            },
+        },
+    })
+
+    checkoutRejectedType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "CheckoutRejected",
+        Fields: graphql.Fields{
+            "reason": &graphql.Field{
+                Type: graphql.NewNonNull(reasonEnum),
+            },
+            "message": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "price_changes": &graphql.Field{
+                Type:        graphql.NewList(priceChangeType),
+                Description: "Set for PRICE_CHANGED; the cart already holds the new prices",
+            },
+            "unavailable_product_ids": &graphql.Field{
+                Type: graphql.NewList(graphql.Int),
+            },
+            "new_total": &graphql.Field{
+                Type: graphql.Float,
+            },
+        },
+    })
+
+    softLockType := graphql.NewObject(graphql.ObjectConfig{
+        Name:        "SoftLock",
+        Description: "Stock held for the cart line after it was added; it is released on removal or when it expires",
+        Fields: graphql.Fields{
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "variant_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "quantity": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Units held for the whole line",
+            },
+            "expires_at": &graphql.Field{
+                Type: graphql.String,
+            },
+            "ttl_seconds": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "message": &graphql.Field{
+                Type: graphql.String,
+            },
+        },
+    })
+
+    cartUpdatedType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "CartUpdated",
+        Fields: graphql.Fields{
+            "cart": &graphql.Field{
+                Type: cartType,
+            },
+            "soft_lock": &graphql.Field{
+                Type:        softLockType,
+                Description: "Null when the cart service doesn't hold stock for added items",
+            },
+        },
+    })
+
+    cartRejectedType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "CartRejected",
+        Fields: graphql.Fields{
+            "reason": &graphql.Field{
+                Type: graphql.NewNonNull(reasonEnum),
+            },
+            "message": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "product_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "available_quantity": &graphql.Field{
+                Type: graphql.Int,
+            },
+        },
+    })
+
+    return MutationResultTypes{
+        CheckoutResult: graphql.NewUnion(graphql.UnionConfig{
+            Name:        "CheckoutResult",
+            Types:       []*graphql.Object{checkoutAcceptedType, checkoutRejectedType},
+            ResolveType: resolveByTypename(checkoutAcceptedType, checkoutRejectedType),
+        }),
+        AddToCartResult: graphql.NewUnion(graphql.UnionConfig{
+            Name:        "AddToCartResult",
+            Types:       []*graphql.Object{cartUpdatedType, cartRejectedType},
+            ResolveType: resolveByTypename(cartUpdatedType, cartRejectedType),
+        }),
+        ApplyCouponResult: graphql.NewUnion(graphql.UnionConfig{
+            Name:        "ApplyCouponResult",
+            Types:       []*graphql.Object{cartUpdatedType, cartRejectedType},
+            ResolveType: resolveByTypename(cartUpdatedType, cartRejectedType),
+        }),
+    }
+}
+
+// resolveByTypename picks the union member named by the result's __typename
+func resolveByTypename(types ...*graphql.Object) graphql.ResolveTypeFn {
+    return func(p graphql.ResolveTypeParams) *graphql.Object {
+        result, ok := p.Value.(map[string]interface{})
+        if !ok {
+            return nil
+        }
+        name, _ := result[typenameKey].(string)
+        for _, t := range types {
+            if t.Name() == name {
+                return t
+            }
+        }
+        return nil
+    }
+}
+
+// checkoutAccepted wraps the cart service checkout response
+func checkoutAccepted(resp map[string]interface{}) map[string]interface{} {
+    result := map[string]interface{}{
+        typenameKey:      "CheckoutAccepted",
+        "correlation_id": resp["correlation_id"],
+        "message":        resp["message"],
+    }
+    if saga, ok := resp["saga_state"].(map[string]interface{}); ok {
+        result["status"] = saga["status"]
+    }
+    return result
+}
+
+// checkoutRejected builds a CheckoutRejected result
+func checkoutRejected(reason, message string) map[string]interface{} {
+    return map[string]interface{}{
+        typenameKey: "CheckoutRejected",
+        "reason":    reason,
+        "message":   message,
+    }
+}
+
+// withPriceValidation copies the cart service's price validation payload onto a
+// CheckoutRejected result so clients can show what changed
+func withPriceValidation(result map[string]interface{}, err error) map[string]interface{} {
+    var se *ServiceError
+    if !errors.As(err, &se) || se.StatusCode != http.StatusConflict {
+        return result
+    }
+
+    var body struct {
+        Changes     []map[string]interface{} `json:"changes"`
+        Unavailable []int64                  `json:"unavailable"`
+        NewTotal    float64                  `json:"new_total"`
+    }
+    if json.Unmarshal([]byte(se.Body), &body) != nil {
+        return result
+    }
+
+    result["price_changes"] = body.Changes
+    result["unavailable_product_ids"] = body.Unavailable
+    result["new_total"] = body.NewTotal
+    return result
+}
+
+// cartUpdated wraps a cart, and the soft lock taken for the added line if any, in a CartUpdated result
+func cartUpdated(cart, softLock map[string]interface{}) map[string]interface{} {
+    result := map[string]interface{}{
+        typenameKey: "CartUpdated",
+        "cart":      cart,
+    }
+    if softLock != nil {
+        quantity, _ := softLock["quantity"].(float64)
+        if ttl, ok := softLock["ttl_seconds"].(float64); ok {
+            softLock["message"] = fmt.Sprintf("%d units held for %s", int(quantity), holdDuration(int(ttl)))
+        }
+        result["soft_lock"] = softLock
+    }
+    return result
+}
+
+// holdDuration renders a soft lock TTL for customers: whole minutes, or seconds under a minute
+func holdDuration(seconds int) string {
+    switch {
+    case seconds < 60:
+        return fmt.Sprintf("%d seconds", seconds)
+    case seconds < 120:
+        return "1 minute"
+    default:
+        return fmt.Sprintf("%d minutes", seconds/60)
+    }
+}
+
+// cartRejected builds a CartRejected result
+func cartRejected(reason, message string, productID int64, available *int) map[string]interface{} {
+    result := map[string]interface{}{
+        typenameKey:  "CartRejected",
+        "reason":     reason,
+        "message":    message,
+        "product_id": productID,
+    }
+    if available != nil {
+        result["available_quantity"] = *available
+    }
+    return result
+}
+
+// classifyCheckoutError maps a downstream business failure to a rejection reason.
+// ok is false when err is not an expected failure and should surface as a GraphQL error.
+func classifyCheckoutError(err error) (reason, message string, ok bool) {
+    var se *ServiceError
+    if !errors.As(err, &se) {
+        return "", "", false
+    }
+
+    code := strings.ToLower(se.Code)
+    switch {
+    case code == "price changed":
+        return ReasonPriceChanged, rejectionMessage(se, "prices changed, please review your cart"), true
+    case code == "coupon expired":
+        return ReasonCouponExpired, rejectionMessage(se, "coupon expired and was removed from the cart"), true
+    case code == "product unavailable":
+        return ReasonProductUnavailable, rejectionMessage(se, "some products are no longer available"), true
+    case code == "address not found":
+        return ReasonAddressNotFound, rejectionMessage(se, "address not found"), true
+    case strings.Contains(code, "cart is empty"):
+        return ReasonCartEmpty, rejectionMessage(se, "cannot checkout empty cart"), true
+    case se.StatusCode == http.StatusNotFound:
+        return ReasonCartNotFound, rejectionMessage(se, "cart not found"), true
+    case se.StatusCode == http.StatusBadRequest:
+        return ReasonInvalidRequest, rejectionMessage(se, "invalid checkout request"), true
+    }
+    return "", "", false
+}
+
+// classifyCartError maps a downstream add-to-cart failure to a rejection reason
+func classifyCartError(err error) (reason, message string, ok bool) {
+    var se *ServiceError
+    if !errors.As(err, &se) {
+        return "", "", false
+    }
+
+    switch se.StatusCode {
+    case http.StatusNotFound:
+        if strings.ToLower(se.Code) == "product unavailable" {
+            return ReasonProductUnavailable, rejectionMessage(se, "product unavailable"), true
+        }
+        return ReasonCartNotFound, rejectionMessage(se, "cart not found"), true
+    case http.StatusBadRequest:
+        return ReasonInvalidRequest, rejectionMessage(se, "invalid cart request"), true
+    case http.StatusConflict:
+        // The cart prices items itself; ours came from a stale catalog read
+        if strings.ToLower(se.Code) == "price mismatch" {
+            return ReasonPriceChanged, rejectionMessage(se, "price changed"), true
+        }
+        if strings.ToLower(se.Code) == "insufficient stock" {
+            if available := shortageAvailable(err); available != nil {
+                return ReasonOutOfStock, fmt.Sprintf("only %d units available", *available), true
+            }
+            return ReasonOutOfStock, rejectionMessage(se, "insufficient stock"), true
+        }
+    }
+    return "", "", false
+}
+
+// couponRejected builds a CartRejected result for a coupon that couldn't be applied
+func couponRejected(reason, message string) map[string]interface{} {
+    return map[string]interface{}{
+        typenameKey: "CartRejected",
+        "reason":    reason,
+        "message":   message,
+    }
+}
+
+// classifyCouponError maps a downstream apply-coupon failure to a rejection reason
+func classifyCouponError(err error) (reason, message string, ok bool) {
+    var se *ServiceError
+    if !errors.As(err, &se) {
+        return "", "", false
+    }
+
+    code := strings.ToLower(se.Code)
+    switch {
+    case code == "coupon not found":
+        return ReasonCouponNotFound, "coupon not found", true
+    case code == "cart not found":
+        return ReasonCartNotFound, rejectionMessage(se, "cart not found"), true
+    case code == "coupon expired":
+        return ReasonCouponExpired, "coupon expired", true
+    case se.StatusCode == http.StatusConflict:
+        return ReasonCouponUnavailable, rejectionMessage(se, "coupon can't be used"), true
+    case se.StatusCode == http.StatusBadRequest:
+        return ReasonInvalidRequest, rejectionMessage(se, "invalid coupon request"), true
+    }
+    return "", "", false
+}
+
+// shortageAvailable returns the units still available from the cart service's 409 when the
+// added line's stock couldn't be soft-locked, or nil for any other failure
+func shortageAvailable(err error) *int {
+    var se *ServiceError
+    if !errors.As(err, &se) || se.StatusCode != http.StatusConflict {
+        return nil
+    }
+
+    var body struct {
+        Available *int `json:"available"`
+    }
+    if json.Unmarshal([]byte(se.Body), &body) != nil {
+        return nil
+    }
+    return body.Available
+}
+
+// isNotFound reports whether err is a downstream 404
+func isNotFound(err error) bool {
+    var se *ServiceError
+    return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+// isForbidden reports whether err is a downstream 403
+func isForbidden(err error) bool {
+    var se *ServiceError
+    return errors.As(err, &se) && se.StatusCode == http.StatusForbidden
+}
+
+func rejectionMessage(se *ServiceError, fallback string) string {
+    if se.Message != "" {
+        return se.Message
+    }
+    if se.Code != "" {
+        return se.Code
+    }
+    return fallback
+}
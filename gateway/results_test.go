@@ -0,0 +1,96 @@
+package main
+
+import (
+    "net/http"
+    "testing"
+)
+
+func TestClassifyCartError_ShortageIsOutOfStock(t *testing.T) {
+    err := &ServiceError{
+        StatusCode: http.StatusConflict,
+        Code:       "insufficient stock",
+        Body:       `{"error":"insufficient stock","message":"insufficient stock for product 7","code":409,"product_id":7,"available":2}`,
+    }
+
+    reason, message, ok := classifyCartError(err)
+    if !ok || reason != ReasonOutOfStock {
+        t.Fatalf("expected OUT_OF_STOCK, got %q (ok=%v)", reason, ok)
+    }
+    if message != "only 2 units available" {
+        t.Fatalf("unexpected message %q", message)
+    }
+    if available := shortageAvailable(err); available == nil || *available != 2 {
+        t.Fatalf("expected 2 available, got %v", available)
+    }
+}
+
+func TestClassifyCartError_PriceAuthority(t *testing.T) {
+    tests := []struct {
+        err  *ServiceError
+        want string
+    }{
+        {&ServiceError{StatusCode: http.StatusConflict, Code: "price mismatch"}, ReasonPriceChanged},
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "product unavailable"}, ReasonProductUnavailable},
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "cart not found"}, ReasonCartNotFound},
+    }
+
+    for _, tt := range tests {
+        if reason, _, ok := classifyCartError(tt.err); !ok || reason != tt.want {
+            t.Errorf("classifyCartError(%q) = %q (ok=%v), want %q", tt.err.Code, reason, ok, tt.want)
+        }
+    }
+}
+
+func TestCartUpdated_SoftLockMessage(t *testing.T) {
+    softLock := map[string]interface{}{"product_id": float64(7), "quantity": float64(3), "ttl_seconds": float64(900)}
+
+    result := cartUpdated(map[string]interface{}{"id": "cart-1"}, softLock)
+    lock, ok := result["soft_lock"].(map[string]interface{})
+    if !ok {
+        t.Fatalf("expected a soft lock, got %v", result)
+    }
+    if lock["message"] != "3 units held for 15 minutes" {
+        t.Fatalf("unexpected message %q", lock["message"])
+    }
+
+    if _, ok := cartUpdated(map[string]interface{}{"id": "cart-1"}, nil)["soft_lock"]; ok {
+        t.Fatal("expected no soft lock when the cart service took none")
+    }
+}
+
+func TestClassifyCouponError(t *testing.T) {
+    tests := []struct {
+        err  *ServiceError
+        want string
+    }{
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "coupon not found"}, ReasonCouponNotFound},
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "cart not found"}, ReasonCartNotFound},
+        {&ServiceError{StatusCode: http.StatusConflict, Code: "coupon expired"}, ReasonCouponExpired},
+        {&ServiceError{StatusCode: http.StatusConflict, Code: "coupon has been used up"}, ReasonCouponUnavailable},
+    }
+
+    for _, tt := range tests {
+        if reason, _, ok := classifyCouponError(tt.err); !ok || reason != tt.want {
+            t.Errorf("classifyCouponError(%q) = %q (ok=%v), want %q", tt.err.Code, reason, ok, tt.want)
+        }
+    }
+    if _, _, ok := classifyCouponError(&ServiceError{StatusCode: http.StatusServiceUnavailable}); ok {
+        t.Error("expected a 503 to stay a GraphQL error")
+    }
+}
+
+func TestClassifyCheckoutError_AddressNotFound(t *testing.T) {
+    cases := []struct {
+        err  error
+        want string
+    }{
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "address not found"}, ReasonAddressNotFound},
+        {&ServiceError{StatusCode: http.StatusNotFound, Code: "cart not found"}, ReasonCartNotFound},
+    }
+    for _, tc := range cases {
+        reason, _, ok := classifyCheckoutError(tc.err)
+        if !ok || reason != tc.want {
+            t.Fatalf("%v: expected %s, got %s (ok=%v)", tc.err, tc.want, reason, ok)
+        }
+    }
+}
@@ -5,11 +5,16 @@ import (
 	"fmt"
 
 	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
 	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
 )
 
-// BuildSchema builds the complete GraphQL schema
-func BuildSchema() *graphql.Schema {
+// BuildSchema builds the GraphQL schema with the sections enabled in features
+func BuildSchema(features SchemaFeatures) *graphql.Schema {
+    features.logDisabled()
+
     timestampType := graphql.NewScalar(graphql.ScalarConfig{
         Name:        "Timestamp",
         Description: "RFC3339 timestamp",
@@ -43,6 +48,106 @@ func BuildSchema() *graphql.Schema {
         },
     })
 
+    // Preferences type
+    preferencesType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Preferences",
+        Fields: graphql.Fields{
+            "locale": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "currency": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "ISO 4217 code prices are shown in",
+            },
+            "marketing_opt_in": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+            },
+        },
+    })
+    userType.AddFieldConfig("preferences", &graphql.Field{
+        Type:        preferencesType,
+        Description: "Only readable for the authenticated user",
+    })
+
+    // Address type: a saved shipping address of the authenticated user
+    addressType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Address",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "label": &graphql.Field{
+                Type: graphql.String,
+            },
+            "recipient_name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "line1": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "line2": &graphql.Field{
+                Type: graphql.String,
+            },
+            "city": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "region": &graphql.Field{
+                Type: graphql.String,
+            },
+            "postal_code": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "country": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "ISO 3166-1 alpha-2 code",
+            },
+            "phone": &graphql.Field{
+                Type: graphql.String,
+            },
+            "is_default": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    // ShippingAddress type: the copy of an address an order ships to
+    shippingAddressType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "ShippingAddress",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "Saved address this was copied from; it may since have changed or been deleted",
+            },
+            "recipient_name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "line1": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "line2": &graphql.Field{
+                Type: graphql.String,
+            },
+            "city": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "region": &graphql.Field{
+                Type: graphql.String,
+            },
+            "postal_code": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "country": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "phone": &graphql.Field{
+                Type: graphql.String,
+            },
+        },
+    })
+
     // Category type
     categoryType := graphql.NewObject(graphql.ObjectConfig{
         Name: "Category",
@@ -56,8 +161,27 @@ func BuildSchema() *graphql.Schema {
             "description": &graphql.Field{
                 Type: graphql.String,
             },
+            "parent_id": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Null for top-level categories",
+            },
+            "product_count": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Products directly in this category (only from categoryTree)",
+            },
+            "total_product_count": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Products in this category and all subcategories (only from categoryTree)",
+            },
         },
     })
+    categoryType.AddFieldConfig("parent", &graphql.Field{
+        Type: categoryType,
+    })
+    categoryType.AddFieldConfig("children", &graphql.Field{
+        Type:        graphql.NewList(categoryType),
+        Description: "Direct subcategories",
+    })
 
     // Product type
     productType := graphql.NewObject(graphql.ObjectConfig{
@@ -93,6 +217,302 @@ func BuildSchema() *graphql.Schema {
         },
     })
 
+    // Rating fields belong to the reviews section
+    if features.Reviews {
+        productType.AddFieldConfig("average_rating", &graphql.Field{
+            Type:        graphql.Float,
+            Description: "Average of approved reviews (0 when unrated)",
+        })
+        productType.AddFieldConfig("review_count", &graphql.Field{
+            Type: graphql.Int,
+        })
+    }
+
+    // Nested catalog fields, added after both types exist since they reference each other
+    categoryType.AddFieldConfig("products", &graphql.Field{
+        Type:        graphql.NewList(productType),
+        Description: "Products in this category",
+        Args: graphql.FieldConfigArgument{
+            "include_subcategories": &graphql.ArgumentConfig{
+                Type:         graphql.Boolean,
+                DefaultValue: false,
+            },
+        },
+    })
+    productType.AddFieldConfig("category", &graphql.Field{
+        Type:        categoryType,
+        Description: "Category of this product (null when uncategorized)",
+    })
+
+    // Product variants (size, color, ...), each with its own SKU, price and stock
+    variantAttributeType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "VariantAttribute",
+        Fields: graphql.Fields{
+            "name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "value": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+        },
+    })
+    productVariantType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "ProductVariant",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "sku": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "attributes": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.NewList(variantAttributeType)),
+                Description: "Sorted by name, e.g. color: red, size: M",
+            },
+            "price_override": &graphql.Field{
+                Type:        graphql.Float,
+                Description: "Null when the variant sells at the product's price",
+            },
+            "price": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Float),
+                Description: "Price the variant sells at",
+            },
+            "stock_quantity": &graphql.Field{
+                Type: graphql.Int,
+            },
+        },
+    })
+    productType.AddFieldConfig("variants", &graphql.Field{
+        Type:        graphql.NewList(productVariantType),
+        Description: "Variants of this product (empty when it has none)",
+    })
+    variantAttributeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name: "VariantAttributeInput",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "name": &graphql.InputObjectFieldConfig{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "value": &graphql.InputObjectFieldConfig{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+        },
+    })
+
+    // Product attributes (specs), checked against the attribute templates of the product's category
+    productAttributeType := graphql.NewObject(graphql.ObjectConfig{
+        Name:        "ProductAttribute",
+        Description: "One spec of a product; exactly one typed value is set",
+        Fields: graphql.Fields{
+            "name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "value": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "The value as text, for display",
+            },
+            "string_value": &graphql.Field{
+                Type: graphql.String,
+            },
+            "number_value": &graphql.Field{
+                Type: graphql.Float,
+            },
+            "boolean_value": &graphql.Field{
+                Type: graphql.Boolean,
+            },
+        },
+    })
+    productType.AddFieldConfig("attributes", &graphql.Field{
+        Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productAttributeType))),
+        Description: "Specs sorted by name, e.g. screen_size: 15.6",
+    })
+    attributeTypeEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name: "AttributeType",
+        Values: graphql.EnumValueConfigMap{
+            "STRING":  &graphql.EnumValueConfig{Value: "string"},
+            "NUMBER":  &graphql.EnumValueConfig{Value: "number"},
+            "BOOLEAN": &graphql.EnumValueConfig{Value: "boolean"},
+            "ENUM":    &graphql.EnumValueConfig{Value: "enum", Description: "A string from the template's options"},
+        },
+    })
+    attributeTemplateType := graphql.NewObject(graphql.ObjectConfig{
+        Name:        "AttributeTemplate",
+        Description: "A spec the products of a category (and its subcategories) have",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "category_id": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Int),
+                Description: "Category the template is defined on; may be an ancestor of the requested one",
+            },
+            "name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "label": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "type": &graphql.Field{
+                Type: graphql.NewNonNull(attributeTypeEnum),
+            },
+            "unit": &graphql.Field{
+                Type: graphql.String,
+            },
+            "options": &graphql.Field{
+                Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+                Description: "Allowed values of an ENUM attribute",
+            },
+            "required": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+            },
+        },
+    })
+    productAttributeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name:        "ProductAttributeInput",
+        Description: "Set one typed value; with none set, updateProduct removes the attribute",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "name": &graphql.InputObjectFieldConfig{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "string_value": &graphql.InputObjectFieldConfig{
+                Type: graphql.String,
+            },
+            "number_value": &graphql.InputObjectFieldConfig{
+                Type: graphql.Float,
+            },
+            "boolean_value": &graphql.InputObjectFieldConfig{
+                Type: graphql.Boolean,
+            },
+        },
+    })
+    attributeFilterInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name:        "AttributeFilterInput",
+        Description: "value matches exactly (\"15.6\" matches the number 15.6); min and max bound a number",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "name": &graphql.InputObjectFieldConfig{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "value": &graphql.InputObjectFieldConfig{
+                Type: graphql.String,
+            },
+            "min": &graphql.InputObjectFieldConfig{
+                Type: graphql.Float,
+            },
+            "max": &graphql.InputObjectFieldConfig{
+                Type: graphql.Float,
+            },
+        },
+    })
+
+    // Product comparison (see compare.go)
+    comparisonAttributeType := graphql.NewObject(graphql.ObjectConfig{
+        Name:        "ComparisonAttribute",
+        Description: "One row of a product comparison",
+        Fields: graphql.Fields{
+            "key": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "label": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "values": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.NewList(graphql.String)),
+                Description: "One value per product, in the order of ProductComparison.products",
+            },
+            "differs": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "False when all products have the same value",
+            },
+        },
+    })
+    productComparisonType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "ProductComparison",
+        Fields: graphql.Fields{
+            "products": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.NewList(productType)),
+            },
+            "attributes": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.NewList(comparisonAttributeType)),
+            },
+        },
+    })
+
+    // Review type
+    reviewType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Review",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "user_id": &graphql.Field{
+                Type: graphql.String,
+            },
+            "rating": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "text": &graphql.Field{
+                Type: graphql.String,
+            },
+            "status": &graphql.Field{
+                Type:        graphql.String,
+                Description: "pending, approved or rejected",
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    // ReviewPage type
+    reviewPageType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "ReviewPage",
+        Fields: graphql.Fields{
+            "reviews": &graphql.Field{
+                Type: graphql.NewList(reviewType),
+            },
+            "count": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "total": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "page": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "limit": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "average_rating": &graphql.Field{
+                Type: graphql.Float,
+            },
+            "review_count": &graphql.Field{
+                Type: graphql.Int,
+            },
+        },
+    })
+
+    // Relay connections (see connections.go)
+    pageInfoType := newPageInfoType()
+    productConnectionType := newConnectionType("Product", productType, pageInfoType, nil)
+    categoryConnectionType := newConnectionType("Category", categoryType, pageInfoType, nil)
+    reviewConnectionType := newConnectionType("Review", reviewType, pageInfoType, graphql.Fields{
+        "totalCount": &graphql.Field{
+            Type:        graphql.NewNonNull(graphql.Int),
+            Description: "Reviews of the product across all pages",
+        },
+        "average_rating": &graphql.Field{
+            Type: graphql.Float,
+        },
+        "review_count": &graphql.Field{
+            Type: graphql.Int,
+        },
+    })
+
     // CartItem type
     cartItemType := graphql.NewObject(graphql.ObjectConfig{
         Name: "CartItem",
@@ -103,6 +523,9 @@ func BuildSchema() *graphql.Schema {
             "product_id": &graphql.Field{
                 Type: graphql.NewNonNull(graphql.Int),
             },
+            "variant_id": &graphql.Field{
+                Type: graphql.Int,
+            },
             "quantity": &graphql.Field{
                 Type: graphql.NewNonNull(graphql.Int),
             },
@@ -122,12 +545,27 @@ func BuildSchema() *graphql.Schema {
             "items": &graphql.Field{
                 Type: graphql.NewList(cartItemType),
             },
+            "subtotal": &graphql.Field{
+                Type:        graphql.Float,
+                Description: "Items before the coupon",
+            },
+            "coupon_code": &graphql.Field{
+                Type: graphql.String,
+            },
+            "discount": &graphql.Field{
+                Type:        graphql.Float,
+                Description: "Taken off the subtotal by the coupon",
+            },
             "total": &graphql.Field{
                 Type: graphql.NewNonNull(graphql.Float),
             },
             "status": &graphql.Field{
                 Type: graphql.String,
             },
+            "guest": &graphql.Field{
+                Type:        graphql.Boolean,
+                Description: "A guest's cart, before sign-in",
+            },
         },
     })
 
@@ -141,6 +579,9 @@ func BuildSchema() *graphql.Schema {
             "product_id": &graphql.Field{
                 Type: graphql.NewNonNull(graphql.Int),
             },
+            "variant_id": &graphql.Field{
+                Type: graphql.Int,
+            },
             "quantity": &graphql.Field{
                 Type: graphql.NewNonNull(graphql.Int),
             },
@@ -150,43 +591,290 @@ func BuildSchema() *graphql.Schema {
         },
     })
 
-    // Order type
-    orderType := graphql.NewObject(graphql.ObjectConfig{
-        Name: "Order",
+    // TaxLine type: one tax charged on an order
+    taxLineType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "TaxLine",
         Fields: graphql.Fields{
-            "id": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Int),
-            },
-            "items": &graphql.Field{
-                Type: graphql.NewList(orderItemType),
+            "name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
             },
-            "total": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Float),
+            "region": &graphql.Field{
+                Type: graphql.String,
             },
-            "status": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.String),
+            "rate": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Float),
+                Description: "Percent",
             },
-            "created_at": &graphql.Field{
-                Type: timestampType,
+            "amount": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
             },
         },
     })
 
-    //Inventory Type
-    inventoryType := graphql.NewObject(graphql.ObjectConfig{
-        Name: "Inventory",
+    // Checkout status: where the orders saga behind a checkout is
+    checkoutPhaseEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name:        "CheckoutPhase",
+        Description: "Phase of the orders saga behind a checkout",
+        Values: graphql.EnumValueConfigMap{
+            PhasePending:           &graphql.EnumValueConfig{Value: PhasePending, Description: "The orders service picked the checkout up"},
+            PhaseOrderCreated:      &graphql.EnumValueConfig{Value: PhaseOrderCreated},
+            PhaseCheckingInventory: &graphql.EnumValueConfig{Value: PhaseCheckingInventory},
+            PhaseOrderPlaced:       &graphql.EnumValueConfig{Value: PhaseOrderPlaced, Description: "Stock is reserved; waiting for payment"},
+            PhaseCompleted:         &graphql.EnumValueConfig{Value: PhaseCompleted},
+            PhaseFailed:            &graphql.EnumValueConfig{Value: PhaseFailed, Description: "See failure_reason"},
+            PhaseCancelled:         &graphql.EnumValueConfig{Value: PhaseCancelled},
+            PhaseUnknown:           &graphql.EnumValueConfig{Value: PhaseUnknown, Description: "A saga status this gateway doesn't know; see status"},
+        },
+    })
+    checkoutOutcomeStatusEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name: "CheckoutOutcomeStatus",
+        Values: graphql.EnumValueConfigMap{
+            OutcomePending:   &graphql.EnumValueConfig{Value: OutcomePending},
+            OutcomeSucceeded: &graphql.EnumValueConfig{Value: OutcomeSucceeded, Description: "Every order of the checkout was confirmed"},
+            OutcomeFailed:    &graphql.EnumValueConfig{Value: OutcomeFailed, Description: "See reason"},
+        },
+    })
+    checkoutOutcomeType := graphql.NewObject(graphql.ObjectConfig{
+        Name:        "CheckoutOutcome",
+        Description: "Terminal result of a checkout once it is SUCCEEDED or FAILED",
         Fields: graphql.Fields{
-            "product_id": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Int),
+            "outcome": &graphql.Field{
+                Type: graphql.NewNonNull(checkoutOutcomeStatusEnum),
             },
-            "total_quantity": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Int),
+            "order_id": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "When SUCCEEDED; the first order of a split checkout",
             },
-            "reserved_quantity": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Int),
+            "order_ids": &graphql.Field{
+                Type:        graphql.NewList(graphql.NewNonNull(graphql.Int)),
+                Description: "When SUCCEEDED",
             },
-            "available_quantity": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.Int),
+            "checkout_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "reason": &graphql.Field{
+                Type:        graphql.String,
+                Description: "When FAILED",
+            },
+        },
+    })
+    checkoutStatusType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "CheckoutStatus",
+        Fields: graphql.Fields{
+            "correlation_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "phase": &graphql.Field{
+                Type: graphql.NewNonNull(checkoutPhaseEnum),
+            },
+            "status": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "Raw saga status, for logging",
+            },
+            "description": &graphql.Field{
+                Type:        graphql.String,
+                Description: "What the checkout is doing, in words, from the orders service",
+            },
+            "done": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "COMPLETED, FAILED or CANCELLED: stop polling",
+            },
+            "order_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "failure_reason": &graphql.Field{
+                Type: graphql.String,
+            },
+            "retry_count": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "updated_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "result": &graphql.Field{
+                Type: graphql.NewNonNull(checkoutOutcomeType),
+            },
+        },
+    })
+
+    // Order type
+    orderType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Order",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "items": &graphql.Field{
+                Type: graphql.NewList(orderItemType),
+            },
+            "subtotal": &graphql.Field{
+                Type:        graphql.Float,
+                Description: "Total before tax; null for orders placed before taxes were charged",
+            },
+            "tax_total": &graphql.Field{
+                Type: graphql.Float,
+            },
+            "tax_lines": &graphql.Field{
+                Type: graphql.NewList(taxLineType),
+            },
+            "total": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Float),
+                Description: "Including tax",
+            },
+            "status": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "checkout_id": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Checkout this order was split from",
+            },
+            "warehouse": &graphql.Field{
+                Type: graphql.String,
+            },
+            "fulfillment_type": &graphql.Field{
+                Type:        graphql.String,
+                Description: "standard, digital or dropship",
+            },
+            "payment_attempts": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "payment_deadline": &graphql.Field{
+                Type:        timestampType,
+                Description: "While payment_pending: retryPayment works until then, after that the order fails",
+            },
+            "payment_failure_reason": &graphql.Field{
+                Type: graphql.String,
+            },
+            "saga_state": &graphql.Field{
+                Type:        checkoutStatusType,
+                Description: "The checkout saga that created this order",
+            },
+            "shipping_address": &graphql.Field{
+                Type:        shippingAddressType,
+                Description: "Where the order ships; null for orders placed before addresses were required",
+            },
+        },
+    })
+
+    // PaymentRetry type: a payment retry handed to the payment service
+    paymentRetryType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "PaymentRetry",
+        Fields: graphql.Fields{
+            "order_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "attempt": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "payment_deadline": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    orderConnectionType := newConnectionType("Order", orderType, pageInfoType, graphql.Fields{
+        "totalCount": &graphql.Field{
+            Type:        graphql.NewNonNull(graphql.Int),
+            Description: "Orders matching the filters across all pages",
+        },
+    })
+
+    // Checkout type: one cart checkout split into an order per warehouse/fulfillment type
+    checkoutType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Checkout",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "status": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "Combined status of the orders, e.g. partially_shipped",
+            },
+            "total": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+            "orders": &graphql.Field{
+                Type: graphql.NewList(orderType),
+            },
+            "saga_correlation_id": &graphql.Field{
+                Type: graphql.String,
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    // Inventory status type (see inventoryStatus for the products service fields it maps)
+    inventoryType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "InventoryStatus",
+        Fields: graphql.Fields{
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "variant_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "total_quantity": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "reserved_quantity": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "available_quantity": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "reservation_id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "The hold reserveInventory created",
+            },
+            "expires_at": &graphql.Field{
+                Type:        timestampType,
+                Description: "When the hold reserveInventory created expires",
+            },
+            "released": &graphql.Field{
+                Type:        graphql.Int,
+                Description: "Units releaseInventory gave back (up to the quantity asked)",
+            },
+        },
+    })
+
+    // Inventory movement type: one audited stock change, as adjustInventory records it
+    inventoryMovementType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "InventoryMovement",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "variant_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "delta": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Int),
+                Description: "Negative when stock was taken out",
+            },
+            "stock_after": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "reason": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "damage, recount or return (order_confirmed for orders)",
+            },
+            "note": &graphql.Field{
+                Type: graphql.String,
+            },
+            "actor_id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "Who adjusted the stock",
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
             },
         },
     })
@@ -198,35 +886,701 @@ func BuildSchema() *graphql.Schema {
             "user": &graphql.Field{
                 Type: graphql.NewNonNull(userType),
             },
-            "token": &graphql.Field{
-                Type: graphql.NewNonNull(graphql.String),
+            "token": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+        },
+    })
+
+    // Guest token type
+    guestTokenType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "GuestToken",
+        Fields: graphql.Fields{
+            "token": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "Send as the X-Guest-Token header, and keep it through login to merge the cart",
+            },
+            "guest_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "expires_at": &graphql.Field{
+                Type: graphql.NewNonNull(timestampType),
+            },
+        },
+    })
+
+    // Admin stats types
+    statsBucketType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "StatsBucket",
+        Fields: graphql.Fields{
+            "period_start": &graphql.Field{
+                Type: timestampType,
+            },
+            "order_count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "revenue": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+            "cancelled_count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+        },
+    })
+
+    statsTotalsType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "StatsTotals",
+        Fields: graphql.Fields{
+            "order_count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "revenue": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+            "cancelled_count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "cancellation_rate": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+            "average_order_value": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+        },
+    })
+
+    topProductType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "TopProduct",
+        Fields: graphql.Fields{
+            "product_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "quantity": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "revenue": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+        },
+    })
+
+    adminStatsType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "AdminStats",
+        Fields: graphql.Fields{
+            "period": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "from": &graphql.Field{
+                Type: timestampType,
+            },
+            "to": &graphql.Field{
+                Type: timestampType,
+            },
+            "buckets": &graphql.Field{
+                Type: graphql.NewList(statsBucketType),
+            },
+            "totals": &graphql.Field{
+                Type: statsTotalsType,
+            },
+            "top_products": &graphql.Field{
+                Type: graphql.NewList(topProductType),
+            },
+        },
+    })
+
+    failureReasonCountType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "FailureReasonCount",
+        Fields: graphql.Fields{
+            "reason": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+        },
+    })
+
+    funnelType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Funnel",
+        Fields: graphql.Fields{
+            "from": &graphql.Field{
+                Type: timestampType,
+            },
+            "to": &graphql.Field{
+                Type: timestampType,
+            },
+            "carts_created": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "checkouts_initiated": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "sagas_completed": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "sagas_failed": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "failures_by_reason": &graphql.Field{
+                Type: graphql.NewList(failureReasonCountType),
+            },
+            "revenue_confirmed": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Float),
+            },
+            "checkout_rate": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Float),
+                Description: "checkouts initiated / carts created, 0..1",
+            },
+            "completion_rate": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Float),
+                Description: "sagas completed / checkouts initiated, 0..1",
+            },
+        },
+    })
+
+    // One status change of a checkout saga
+    sagaTransitionType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "SagaTransition",
+        Fields: graphql.Fields{
+            "from_status": &graphql.Field{
+                Type:        graphql.String,
+                Description: "null for the saga's first status",
+            },
+            "to_status": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "event_id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "event that caused the change, null when orders changed it on its own",
+            },
+            "event_type": &graphql.Field{
+                Type: graphql.String,
+            },
+            "actor_service": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "service whose event, or action, caused the change",
+            },
+            "reason": &graphql.Field{
+                Type: graphql.String,
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    sagaTimelineType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "SagaTimeline",
+        Fields: graphql.Fields{
+            "correlation_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "status": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "order_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "last_completed_step": &graphql.Field{
+                Type: graphql.String,
+            },
+            "failure_reason": &graphql.Field{
+                Type: graphql.String,
+            },
+            "retry_count": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "updated_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "transitions": &graphql.Field{
+                Type:        graphql.NewList(sagaTransitionType),
+                Description: "oldest first",
+            },
+        },
+    })
+
+    // One moment of a checkout's event timeline (see eventtimeline.go)
+    eventTimelineEntryType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "EventTimelineEntry",
+        Fields: graphql.Fields{
+            "at": &graphql.Field{
+                Type: graphql.NewNonNull(timestampType),
+            },
+            "source": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "orders.saga, orders.records, cart or products",
+            },
+            "kind": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "e.g. saga.transition, order.created, stock.reserved, compensation.started",
+            },
+            "summary": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "order_id": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "event_id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "event behind a saga transition",
+            },
+            "event_type": &graphql.Field{
+                Type: graphql.String,
+            },
+            "actor_service": &graphql.Field{
+                Type: graphql.String,
+            },
+        },
+    })
+
+    eventTimelineSourceType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "EventTimelineSource",
+        Fields: graphql.Fields{
+            "name": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "ok": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+            },
+            "error": &graphql.Field{
+                Type: graphql.String,
+            },
+        },
+    })
+
+    eventTimelineType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "EventTimeline",
+        Fields: graphql.Fields{
+            "correlation_id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "status": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "current saga status",
+            },
+            "entries": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventTimelineEntryType))),
+                Description: "oldest first",
+            },
+            "sources": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventTimelineSourceType))),
+            },
+            "complete": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "false when a source failed and its entries are missing",
+            },
+        },
+    })
+
+    // Storefront banner published by an admin (see announcements.go)
+    announcementType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Announcement",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "kind": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "maintenance or promotion",
+            },
+            "title": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "message": &graphql.Field{
+                Type: graphql.String,
+            },
+            "starts_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "ends_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    // AdminUser type: an account as admins see it
+    adminUserType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "AdminUser",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "email": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "username": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "role": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "disabled_at": &graphql.Field{
+                Type:        timestampType,
+                Description: "Set while the account can't sign in",
+            },
+            "deleted_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "password_reset_required": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "The next login has to set a new password",
+            },
+            "created_at": &graphql.Field{
+                Type: timestampType,
+            },
+            "updated_at": &graphql.Field{
+                Type: timestampType,
+            },
+        },
+    })
+
+    // UserPage type: one page of the admin user list
+    userPageType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "UserPage",
+        Fields: graphql.Fields{
+            "users": &graphql.Field{
+                Type: graphql.NewList(adminUserType),
+            },
+            "count": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "total": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "page": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "limit": &graphql.Field{
+                Type: graphql.Int,
+            },
+        },
+    })
+
+    // Audit log: admin and security-relevant actions recorded by each service
+    jsonType := graphql.NewScalar(graphql.ScalarConfig{
+        Name:        "JSON",
+        Description: "Any JSON value",
+        ParseValue: func(value interface{}) interface{} {
+            return value
+        },
+        ParseLiteral: func(valueAST ast.Value) interface{} {
+            return valueAST
+        },
+        Serialize: func(value interface{}) interface{} {
+            return value
+        },
+    })
+    auditServiceEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name:        "AuditService",
+        Description: "Service whose audit log to read",
+        Values: graphql.EnumValueConfigMap{
+            "CATALOG": &graphql.EnumValueConfig{Value: "catalog", Description: "Product creates, updates and deletes"},
+            "USERS":   &graphql.EnumValueConfig{Value: "users", Description: "Admin actions on accounts, deleted accounts and failed logins"},
+            "ORDERS":  &graphql.EnumValueConfig{Value: "orders", Description: "Cancelled orders"},
+        },
+    })
+    auditLogEntryType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "AuditLogEntry",
+        Fields: graphql.Fields{
+            "id": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Int),
+            },
+            "service": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.String),
+            },
+            "action": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.String),
+                Description: "e.g. product.deleted, order.cancelled, user.role_changed, auth.login_failed",
+            },
+            "actor_id": &graphql.Field{
+                Type:        graphql.String,
+                Description: "Null for anonymous callers, e.g. failed logins",
+            },
+            "actor_role": &graphql.Field{
+                Type: graphql.String,
+            },
+            "ip": &graphql.Field{
+                Type: graphql.String,
+            },
+            "target_type": &graphql.Field{
+                Type:        graphql.String,
+                Description: "product, order or user",
+            },
+            "target_id": &graphql.Field{
+                Type: graphql.String,
+            },
+            "before": &graphql.Field{
+                Type:        jsonType,
+                Description: "The target before the action",
+            },
+            "after": &graphql.Field{
+                Type:        jsonType,
+                Description: "The target after the action",
+            },
+            "details": &graphql.Field{
+                Type:        jsonType,
+                Description: "Context that isn't target state, e.g. why a login failed",
+            },
+            "created_at": &graphql.Field{
+                Type: graphql.NewNonNull(timestampType),
+            },
+        },
+    })
+    auditLogPageType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "AuditLogPage",
+        Fields: graphql.Fields{
+            "entries": &graphql.Field{
+                Type: graphql.NewList(auditLogEntryType),
+            },
+            "count": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "total": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "page": &graphql.Field{
+                Type: graphql.Int,
+            },
+            "limit": &graphql.Field{
+                Type: graphql.Int,
+            },
+        },
+    })
+
+    // Mutation result unions (see results.go)
+    resultTypes := buildMutationResultTypes(cartType, checkoutOutcomeType)
+
+    // Query root
+    queryType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Query",
+        Fields: features.queryFields(graphql.Fields{
+            "me": &graphql.Field{
+                Type: userType,
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "addresses": &graphql.Field{
+                Type:        graphql.NewList(addressType),
+                Description: "The current user's saved addresses, the default first",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "products": &graphql.Field{
+                Type: graphql.NewList(productType),
+                Args: graphql.FieldConfigArgument{
+                    "category_id": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "include_subcategories": &graphql.ArgumentConfig{
+                        Type:         graphql.Boolean,
+                        DefaultValue: false,
+                        Description:  "With category_id, also list products of all its subcategories",
+                    },
+                    "attributes": &graphql.ArgumentConfig{
+                        Type:        graphql.NewList(graphql.NewNonNull(attributeFilterInputType)),
+                        Description: "Only products matching every filter",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "productsConnection": &graphql.Field{
+                Type:        graphql.NewNonNull(productConnectionType),
+                Description: "Products newest first, one page at a time; same filters as products",
+                Args: connectionArgs(graphql.FieldConfigArgument{
+                    "category_id": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "include_subcategories": &graphql.ArgumentConfig{
+                        Type:         graphql.Boolean,
+                        DefaultValue: false,
+                    },
+                    "attributes": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.NewNonNull(attributeFilterInputType)),
+                    },
+                }),
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "product": &graphql.Field{
+                Type: productType,
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "recentlyViewed": &graphql.Field{
+                Type:        graphql.NewList(productType),
+                Description: "The current user's recently viewed products, newest first",
+                Args: graphql.FieldConfigArgument{
+                    "limit": &graphql.ArgumentConfig{
+                        Type:         graphql.Int,
+                        DefaultValue: 10,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
             },
-        },
-    })
-
-    // Query root
-    queryType := graphql.NewObject(graphql.ObjectConfig{
-        Name: "Query",
-        Fields: graphql.Fields{
-            "me": &graphql.Field{
-                Type: userType,
+            "compareProducts": &graphql.Field{
+                Type:        productComparisonType,
+                Description: "2 to 4 products side by side",
+                Args: graphql.FieldConfigArgument{
+                    "ids": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int))),
+                    },
+                },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "products": &graphql.Field{
-                Type: graphql.NewList(productType),
+            "productReviews": &graphql.Field{
+                Type: reviewPageType,
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                    "page": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "limit": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "productReviewsConnection": &graphql.Field{
+                Type:        graphql.NewNonNull(reviewConnectionType),
+                Description: "Approved reviews of a product newest first, one page at a time, with its rating summary",
+                Args: connectionArgs(graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                }),
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "categories": &graphql.Field{
+                Type: graphql.NewList(categoryType),
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "categoriesConnection": &graphql.Field{
+                Type:        graphql.NewNonNull(categoryConnectionType),
+                Description: "Categories newest first, one page at a time",
+                Args:        connectionArgs(nil),
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "attributeTemplates": &graphql.Field{
+                Type:        graphql.NewList(attributeTemplateType),
+                Description: "Attribute templates of a category, including those inherited from its ancestors",
                 Args: graphql.FieldConfigArgument{
                     "category_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "categoryTree": &graphql.Field{
+                Type:        graphql.NewList(categoryType),
+                Description: "Top-level categories with nested children and product counts",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "cart": &graphql.Field{
+                Type: cartType,
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "orders": &graphql.Field{
+                Type: graphql.NewList(orderType),
+                Args: graphql.FieldConfigArgument{
+                    "status": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.String),
+                    },
+                    "from": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "RFC3339 timestamp or YYYY-MM-DD",
+                    },
+                    "to": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "RFC3339 timestamp or YYYY-MM-DD (inclusive day)",
+                    },
+                    "min_total": &graphql.ArgumentConfig{
+                        Type: graphql.Float,
+                    },
+                    "max_total": &graphql.ArgumentConfig{
+                        Type: graphql.Float,
+                    },
+                    "page": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "limit": &graphql.ArgumentConfig{
                         Type: graphql.Int,
                     },
+                    "sort": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "created_at_desc (default), created_at_asc, total_desc, total_asc",
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "product": &graphql.Field{
-                Type: productType,
+            "ordersConnection": &graphql.Field{
+                Type:        graphql.NewNonNull(orderConnectionType),
+                Description: "The current user's orders one page at a time; same filters and sort as orders",
+                Args: connectionArgs(graphql.FieldConfigArgument{
+                    "status": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.String),
+                    },
+                    "from": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "RFC3339 timestamp or YYYY-MM-DD",
+                    },
+                    "to": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "RFC3339 timestamp or YYYY-MM-DD (inclusive day)",
+                    },
+                    "min_total": &graphql.ArgumentConfig{
+                        Type: graphql.Float,
+                    },
+                    "max_total": &graphql.ArgumentConfig{
+                        Type: graphql.Float,
+                    },
+                    "sort": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "created_at_desc (default), created_at_asc, total_desc, total_asc; a cursor only continues its own sort",
+                    },
+                }),
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "order": &graphql.Field{
+                Type: orderType,
                 Args: graphql.FieldConfigArgument{
                     "id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
@@ -236,91 +1590,375 @@ func BuildSchema() *graphql.Schema {
                     return nil, nil
                 },
             },
-            "categories": &graphql.Field{
-                Type: graphql.NewList(categoryType),
+            "checkoutStatus": &graphql.Field{
+                Type:        checkoutStatusType,
+                Description: "Progress of a checkout by the correlation_id checkout returned; null until the orders service has picked it up",
+                Args: graphql.FieldConfigArgument{
+                    "correlation_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "checkout": &graphql.Field{
+                Type: checkoutType,
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "announcements": &graphql.Field{
+                Type:        graphql.NewList(announcementType),
+                Description: "Announcements currently shown on the storefront",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "funnel": &graphql.Field{
+                Type: funnelType,
+                Args: graphql.FieldConfigArgument{
+                    "hours": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "window ending now (default 24, max 720)",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "adminStats": &graphql.Field{
+                Type: adminStatsType,
+                Args: graphql.FieldConfigArgument{
+                    "period": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "daily (default) or weekly",
+                    },
+                    "from": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "to": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "top": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "number of top products (default 10)",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "sagaTimeline": &graphql.Field{
+                Type:        sagaTimelineType,
+                Description: "Every status change of a checkout saga, to see where it got stuck",
+                Args: graphql.FieldConfigArgument{
+                    "correlation_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "users": &graphql.Field{
+                Type:        userPageType,
+                Description: "Accounts newest first (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "role": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "customer or admin",
+                    },
+                    "status": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "active, disabled or deleted; default active and disabled",
+                    },
+                    "search": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: "Part of the email or username",
+                    },
+                    "page": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "limit": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "auditLogs": &graphql.Field{
+                Type:        auditLogPageType,
+                Description: "One service's audit log, newest first (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "service": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(auditServiceEnum),
+                    },
+                    "action": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "actor_id": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "target_type": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "target_id": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "page": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                    "limit": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "Default 50, capped at 200",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "eventTimeline": &graphql.Field{
+                Type:        eventTimelineType,
+                Description: "Everything that happened to a checkout across cart, orders and products, oldest first",
+                Args: graphql.FieldConfigArgument{
+                    "correlation_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
-            },
-            "cart": &graphql.Field{
-                Type: cartType,
+            },
+            "inventory": &graphql.Field{
+                Type: inventoryType,
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+        }),
+    })
+
+    // Mutation root
+    mutationType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Mutation",
+        Fields: features.mutationFields(graphql.Fields{
+            "register": &graphql.Field{
+                Type: authResponseType,
+                Args: graphql.FieldConfigArgument{
+                    "email": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "username": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "password": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.String),
+                        Description: sensitiveTag + " Never logged",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "login": &graphql.Field{
+                Type: authResponseType,
+                Args: graphql.FieldConfigArgument{
+                    "email": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "password": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.String),
+                        Description: sensitiveTag + " Never logged",
+                    },
+                    "new_password": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: sensitiveTag + " Required while an admin-forced password reset is pending",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "createGuestToken": &graphql.Field{
+                Type:        graphql.NewNonNull(guestTokenType),
+                Description: "Start a guest session, so items can go in a cart before sign-in",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "mergeGuestCart": &graphql.Field{
+                Type:        cartType,
+                Description: "Fold the X-Guest-Token cart into the signed-in user's cart; login and register do this already",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "logout": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "Revoke the current token on every gateway instance",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "recordProductView": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "Add a product to the current user's recently viewed list",
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "orders": &graphql.Field{
-                Type: graphql.NewList(orderType),
+            "updateProfile": &graphql.Field{
+                Type:        graphql.NewNonNull(userType),
+                Description: "Change the current user's email, username or password; omitted arguments are unchanged",
+                Args: graphql.FieldConfigArgument{
+                    "email": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "username": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "current_password": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: sensitiveTag + " Required with new_password unless the account has no password",
+                    },
+                    "new_password": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: sensitiveTag + " Never logged",
+                    },
+                },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "order": &graphql.Field{
-                Type: orderType,
+            "deleteAccount": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "Delete the current user's account and revoke the current token",
                 Args: graphql.FieldConfigArgument{
-                    "id": &graphql.ArgumentConfig{
-                        Type: graphql.NewNonNull(graphql.Int),
+                    "password": &graphql.ArgumentConfig{
+                        Type:        graphql.String,
+                        Description: sensitiveTag + " Required unless the account has no password",
                     },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "inventory": &graphql.Field{
-                Type: inventoryType,
+            "updatePreferences": &graphql.Field{
+                Type:        graphql.NewNonNull(preferencesType),
+                Description: "Change the current user's preferences; omitted arguments are unchanged",
                 Args: graphql.FieldConfigArgument{
-                    "product_id": &graphql.ArgumentConfig{
-                        Type: graphql.NewNonNull(graphql.Int),
+                    "locale": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "currency": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "marketing_opt_in": &graphql.ArgumentConfig{
+                        Type: graphql.Boolean,
                     },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-        },
-    })
-
-    // Mutation root
-    mutationType := graphql.NewObject(graphql.ObjectConfig{
-        Name: "Mutation",
-        Fields: graphql.Fields{
-            "register": &graphql.Field{
-                Type: authResponseType,
+            "addAddress": &graphql.Field{
+                Type:        graphql.NewNonNull(addressType),
+                Description: "Save an address for the current user; their first one becomes the default",
                 Args: graphql.FieldConfigArgument{
-                    "email": &graphql.ArgumentConfig{
+                    "label": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "recipient_name": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.String),
                     },
-                    "username": &graphql.ArgumentConfig{
+                    "line1": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.String),
                     },
-                    "password": &graphql.ArgumentConfig{
+                    "line2": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "city": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "region": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "postal_code": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.String),
                     },
+                    "country": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.String),
+                        Description: "ISO 3166-1 alpha-2 code",
+                    },
+                    "phone": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "is_default": &graphql.ArgumentConfig{
+                        Type: graphql.Boolean,
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "login": &graphql.Field{
-                Type: authResponseType,
+            "addToCart": &graphql.Field{
+                Type: graphql.NewNonNull(resultTypes.AddToCartResult),
                 Args: graphql.FieldConfigArgument{
-                    "email": &graphql.ArgumentConfig{
-                        Type: graphql.NewNonNull(graphql.String),
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
                     },
-                    "password": &graphql.ArgumentConfig{
-                        Type: graphql.NewNonNull(graphql.String),
+                    "variant_id": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "Required to buy a specific variant of the product",
+                    },
+                    "quantity": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
                     },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
-            "addToCart": &graphql.Field{
-                Type: cartType,
+            "addReview": &graphql.Field{
+                Type: reviewType,
                 Args: graphql.FieldConfigArgument{
                     "product_id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
                     },
-                    "quantity": &graphql.ArgumentConfig{
+                    "rating": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.Int),
+                        Description: "1 to 5",
+                    },
+                    "text": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "notifyWhenInStock": &graphql.Field{
+                Type:        graphql.NewNonNull(graphql.Boolean),
+                Description: "Notify the current user once an out-of-stock product is back",
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
                     },
                 },
@@ -334,13 +1972,40 @@ func BuildSchema() *graphql.Schema {
                     "product_id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
                     },
+                    "variant_id": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "Remove only this variant; all of the product's items when omitted",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "applyCoupon": &graphql.Field{
+                Type: graphql.NewNonNull(resultTypes.ApplyCouponResult),
+                Args: graphql.FieldConfigArgument{
+                    "code": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
                 },
+            },
+            "removeCoupon": &graphql.Field{
+                Type: cartType,
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
             "checkout": &graphql.Field{
-                Type: orderType,
+                Type: graphql.NewNonNull(resultTypes.CheckoutResult),
+                Args: graphql.FieldConfigArgument{
+                    "address_id": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.String),
+                        Description: "One of the user's saved addresses; the orders keep a copy of it",
+                    },
+                },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
@@ -356,6 +2021,18 @@ func BuildSchema() *graphql.Schema {
                     return nil, nil
                 },
             },
+            "retryPayment": &graphql.Field{
+                Type:        paymentRetryType,
+                Description: "Retry the payment of a payment_pending order; the result updates the order's status",
+                Args: graphql.FieldConfigArgument{
+                    "order_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
             "createProduct" : &graphql.Field{
                 Type: productType,
                 Args: graphql.FieldConfigArgument{
@@ -377,6 +2054,9 @@ func BuildSchema() *graphql.Schema {
                     "category_id": &graphql.ArgumentConfig{
                         Type: graphql.Int,
                     },
+                    "attributes": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.NewNonNull(productAttributeInputType)),
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
@@ -403,6 +2083,10 @@ func BuildSchema() *graphql.Schema {
                     "category_id": &graphql.ArgumentConfig{
                     Type: graphql.Int,
                     },
+                    "attributes": &graphql.ArgumentConfig{
+                        Type:        graphql.NewList(graphql.NewNonNull(productAttributeInputType)),
+                        Description: "Merged into the current attributes",
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
@@ -412,8 +2096,99 @@ func BuildSchema() *graphql.Schema {
                 Type: graphql.String,
                 Args: graphql.FieldConfigArgument{
                     "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "addVariant": &graphql.Field{
+                Type:        productVariantType,
+                Description: "Add a variant with its own SKU and stock to a product (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                    "sku": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "attributes": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(variantAttributeInputType))),
+                    },
+                    "price_override": &graphql.ArgumentConfig{
+                        Type: graphql.Float,
+                    },
+                    "stock_quantity": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "createAttributeTemplate": &graphql.Field{
+                Type:        attributeTemplateType,
+                Description: "Add an attribute template to a category (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "category_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                    "name": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.String),
                     },
+                    "label": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "type": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(attributeTypeEnum),
+                    },
+                    "unit": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "options": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+                    },
+                    "required": &graphql.ArgumentConfig{
+                        Type:         graphql.Boolean,
+                        DefaultValue: false,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "updateAttributeTemplate": &graphql.Field{
+                Type:        attributeTemplateType,
+                Description: "Change an attribute template; its name and type are fixed (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                    "label": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "unit": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "options": &graphql.ArgumentConfig{
+                        Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+                    },
+                    "required": &graphql.ArgumentConfig{
+                        Type: graphql.Boolean,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "deleteAttributeTemplate": &graphql.Field{
+                Type:        graphql.Boolean,
+                Description: "Remove an attribute template; products keep their values (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
@@ -426,7 +2201,10 @@ func BuildSchema() *graphql.Schema {
                         Type: graphql.NewNonNull(graphql.String),
                     },
                     "description": &graphql.ArgumentConfig{
-                        Type: graphql.NewNonNull(graphql.String),
+                        Type: graphql.String,
+                    },
+                    "parent_id": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
                     },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
@@ -434,7 +2212,8 @@ func BuildSchema() *graphql.Schema {
                 },
             },
             "reserveInventory": &graphql.Field{
-                Type: inventoryType,
+                Type:        inventoryType,
+                Description: "Hold units of a product by hand (admin only)",
                 Args: graphql.FieldConfigArgument{
                     "product_id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
@@ -442,13 +2221,18 @@ func BuildSchema() *graphql.Schema {
                     "quantity": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
                     },
+                    "ttl_seconds": &graphql.ArgumentConfig{
+                        Type:        graphql.Int,
+                        Description: "How long the hold lasts; default 24 hours, at most 7 days",
+                    },
                 },
                 Resolve: func(p graphql.ResolveParams) (interface{}, error) {
                     return nil, nil
                 },
             },
             "releaseInventory": &graphql.Field{
-                Type: inventoryType,
+                Type:        inventoryType,
+                Description: "Give back up to quantity units of a product's holds, oldest first (admin only)",
                 Args: graphql.FieldConfigArgument{
                     "product_id": &graphql.ArgumentConfig{
                         Type: graphql.NewNonNull(graphql.Int),
@@ -461,13 +2245,105 @@ func BuildSchema() *graphql.Schema {
                     return nil, nil
                 },
             },
+            "adjustInventory": &graphql.Field{
+                Type:        inventoryMovementType,
+                Description: "Change a product's (or variant's) stock by delta for a reason: damage, recount or return (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "product_id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.Int),
+                    },
+                    "delta": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.Int),
+                        Description: "Negative takes stock out; stock can't go below zero",
+                    },
+                    "reason": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "note": &graphql.ArgumentConfig{
+                        Type: graphql.String,
+                    },
+                    "variant_id": &graphql.ArgumentConfig{
+                        Type: graphql.Int,
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "disableUser": &graphql.Field{
+                Type:        adminUserType,
+                Description: "Stop an account from signing in; its tokens stay valid until they expire (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "enableUser": &graphql.Field{
+                Type:        adminUserType,
+                Description: "Let a disabled account sign in again (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "forcePasswordReset": &graphql.Field{
+                Type:        adminUserType,
+                Description: "Make an account set a new password at its next login (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+            "updateUserRole": &graphql.Field{
+                Type:        adminUserType,
+                Description: "Change an account's role; it applies from the account's next token (admin only)",
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{
+                        Type: graphql.NewNonNull(graphql.String),
+                    },
+                    "role": &graphql.ArgumentConfig{
+                        Type:        graphql.NewNonNull(graphql.String),
+                        Description: "customer or admin",
+                    },
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return nil, nil
+                },
+            },
+        }),
+    })
+
+    // Subscription root (served over SSE, see announcements.go)
+    subscriptionType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "Subscription",
+        Fields: graphql.Fields{
+            "announcementPublished": &graphql.Field{
+                Type:        announcementType,
+                Description: "Each announcement as it is published",
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return p.Source, nil
+                },
+            },
         },
     })
 
     // Create schema
     schema, err := graphql.NewSchema(graphql.SchemaConfig{
-        Query:    queryType,
-        Mutation: mutationType,
+        Query:        queryType,
+        Mutation:     mutationType,
+        Subscription: subscriptionType,
     })
 
     if err != nil {
@@ -483,18 +2359,82 @@ type GraphQLQuery struct {
     Query         string                 `json:"query"`
     Variables     map[string]interface{} `json:"variables,omitempty"`
     OperationName string                 `json:"operationName,omitempty"`
+    Extensions    *QueryExtensions       `json:"extensions,omitempty"`
+}
+
+// ExecuteQuery executes GraphQL query.
+// The document is parsed, validated (variables included) and checked against limits before any resolver runs.
+func ExecuteQuery(query string, variables map[string]interface{}, schema *graphql.Schema, limits QueryLimitsConfig, ctx context.Context) *graphql.Result {
+    doc, err := parser.Parse(parser.ParseParams{
+        Source: source.NewSource(&source.Source{
+            Body: []byte(query),
+            Name: "GraphQL request",
+        }),
+    })
+    if err != nil {
+        return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+    }
+
+    validation := graphql.ValidateDocument(schema, doc, nil)
+    if !validation.IsValid {
+        return &graphql.Result{Errors: validation.Errors}
+    }
+
+    if varErrs := validateVariables(schema, doc, variables); len(varErrs) > 0 {
+        return &graphql.Result{Errors: varErrs}
+    }
+
+    if limitErr := checkQueryLimits(schema, doc, variables, limits); limitErr != nil {
+        return &graphql.Result{Errors: []gqlerrors.FormattedError{*limitErr}}
+    }
+
+    return graphql.Execute(graphql.ExecuteParams{
+        Schema:  *schema,
+        AST:     doc,
+        Args:    variables,
+        Context: withCatalogLoader(ctx),
+    })
 }
 
-// ExecuteQuery executes GraphQL query
-func ExecuteQuery(query string, variables map[string]interface{}, schema *graphql.Schema, ctx context.Context) *graphql.Result {
-    result := graphql.Do(graphql.Params{
-        Schema:         *schema,
-        RequestString:  query,
-        VariableValues: variables,
-		Context: ctx,
+// ExecuteSubscription runs a subscription with the same parse, validation and limit checks as
+// ExecuteQuery. The channel yields one result per event and closes when ctx is done.
+func ExecuteSubscription(query string, variables map[string]interface{}, schema *graphql.Schema, limits QueryLimitsConfig, ctx context.Context) chan *graphql.Result {
+    single := func(result *graphql.Result) chan *graphql.Result {
+        results := make(chan *graphql.Result, 1)
+        results <- result
+        close(results)
+        return results
+    }
+
+    doc, err := parser.Parse(parser.ParseParams{
+        Source: source.NewSource(&source.Source{
+            Body: []byte(query),
+            Name: "GraphQL request",
+        }),
     })
+    if err != nil {
+        return single(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+    }
+
+    validation := graphql.ValidateDocument(schema, doc, nil)
+    if !validation.IsValid {
+        return single(&graphql.Result{Errors: validation.Errors})
+    }
+
+    if varErrs := validateVariables(schema, doc, variables); len(varErrs) > 0 {
+        return single(&graphql.Result{Errors: varErrs})
+    }
+
+    if limitErr := checkQueryLimits(schema, doc, variables, limits); limitErr != nil {
+        return single(&graphql.Result{Errors: []gqlerrors.FormattedError{*limitErr}})
+    }
 
-    return result
+    return graphql.ExecuteSubscription(graphql.ExecuteParams{
+        Schema:  *schema,
+        AST:     doc,
+        Args:    variables,
+        Context: ctx,
+    })
 }
 
 // FormatResult formats GraphQL result for HTTP response
@@ -504,9 +2444,7 @@ func FormatResult(result *graphql.Result) map[string]interface{} {
     if len(result.Errors) > 0 {
         errors := make([]map[string]interface{}, len(result.Errors))
         for i, err := range result.Errors {
-            errors[i] = map[string]interface{}{
-                "message": err.Error(),
-            }
+            errors[i] = formatError(err)
         }
         response["errors"] = errors
     }
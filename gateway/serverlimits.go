@@ -0,0 +1,202 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Server and downstream limits
+// Why: the gateway's own request budget, the per-service client timeouts and the services'
+// handler budgets used to be unrelated constants. A downstream call that outlives the
+// client's request is wasted work, so the request's deadline now bounds every downstream call,
+// and each service's timeout is checked against it at startup. The HTTP_* variables are the same
+// as in the services (shared/httpserver); the gateway can't import that package.
+
+// writeGrace is how long after the handler's deadline the response may still be written
+const writeGrace = time.Second
+
+// RouteLimits bounds one route
+type RouteLimits struct {
+    Timeout      time.Duration // read, handler and write budget; 0 uses the server's
+    MaxBodyBytes int64         // 0 uses the server's
+}
+
+// ServerLimitsConfig is the gateway's HTTP server limits
+type ServerLimitsConfig struct {
+    ReadTimeout  time.Duration
+    WriteTimeout time.Duration // also the default handler budget
+    IdleTimeout  time.Duration
+    MaxBodyBytes int64
+    Routes       map[string]RouteLimits // keyed by "METHOD /path"
+}
+
+// DownstreamConfig is the timeout of each call to a service
+type DownstreamConfig struct {
+    Timeout         time.Duration            // default for every service
+    ServiceTimeouts map[string]time.Duration // by service base URL
+}
+
+// loadServerLimits reads the HTTP_* variables
+func loadServerLimits() ServerLimitsConfig {
+    limits := ServerLimitsConfig{
+        ReadTimeout:  time.Duration(getEnvInt("HTTP_READ_TIMEOUT_SECONDS", 15)) * time.Second,
+        WriteTimeout: time.Duration(getEnvInt("HTTP_WRITE_TIMEOUT_SECONDS", 30)) * time.Second,
+        IdleTimeout:  time.Duration(getEnvInt("HTTP_IDLE_TIMEOUT_SECONDS", 120)) * time.Second,
+        MaxBodyBytes: int64(getEnvInt("HTTP_MAX_BODY_BYTES", 1<<20)),
+        Routes: map[string]RouteLimits{
+            "GET /health": {Timeout: 2 * time.Second},
+            "GET /ready":  {Timeout: 2 * time.Second},
+        },
+    }
+
+    if val := os.Getenv("HTTP_ROUTE_LIMITS"); val != "" {
+        overrides, err := parseRouteLimits(val)
+        if err != nil {
+            log.Printf("⚠️  Invalid value for HTTP_ROUTE_LIMITS, ignoring it: %v", err)
+        }
+        for route, routeLimits := range overrides {
+            limits.Routes[route] = routeLimits
+        }
+    }
+    return limits
+}
+
+// parseRouteLimits parses "METHOD /path=timeout[,max_body_bytes]" entries separated by ";",
+// e.g. "POST /graphql=45s,2097152;GET /health=2s". Valid entries are returned even when others fail.
+func parseRouteLimits(s string) (map[string]RouteLimits, error) {
+    routes := map[string]RouteLimits{}
+    var errs []string
+    for _, entry := range strings.Split(s, ";") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+
+        route, value, ok := strings.Cut(entry, "=")
+        method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+        if !ok || !hasPath || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+            errs = append(errs, fmt.Sprintf("%q: want \"METHOD /path=timeout[,max_body_bytes]\"", entry))
+            continue
+        }
+
+        var limits RouteLimits
+        timeout, body, hasBody := strings.Cut(value, ",")
+        parsed, err := time.ParseDuration(strings.TrimSpace(timeout))
+        if err != nil || parsed < 0 {
+            errs = append(errs, fmt.Sprintf("%q: invalid timeout", entry))
+            continue
+        }
+        limits.Timeout = parsed
+        if hasBody {
+            limits.MaxBodyBytes, err = strconv.ParseInt(strings.TrimSpace(body), 10, 64)
+            if err != nil || limits.MaxBodyBytes < 0 {
+                errs = append(errs, fmt.Sprintf("%q: invalid max_body_bytes", entry))
+                continue
+            }
+        }
+        routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = limits
+    }
+    if len(errs) > 0 {
+        return routes, fmt.Errorf("%s", strings.Join(errs, "; "))
+    }
+    return routes, nil
+}
+
+// loadDownstream reads DOWNSTREAM_TIMEOUT_SECONDS and the per-service <NAME>_SERVICE_TIMEOUT_SECONDS
+func loadDownstream(serviceURLs map[string]string) DownstreamConfig {
+    config := DownstreamConfig{
+        Timeout:         time.Duration(getEnvInt("DOWNSTREAM_TIMEOUT_SECONDS", 10)) * time.Second,
+        ServiceTimeouts: map[string]time.Duration{},
+    }
+    for name, baseURL := range serviceURLs {
+        key := name + "_SERVICE_TIMEOUT_SECONDS"
+        if os.Getenv(key) != "" && baseURL != "" {
+            config.ServiceTimeouts[baseURL] = time.Duration(getEnvInt(key, 0)) * time.Second
+        }
+    }
+    return config
+}
+
+// Limits returns the limits of a route; anything the route doesn't override comes from the server
+func (c ServerLimitsConfig) Limits(route string) RouteLimits {
+    limits := c.Routes[route]
+    if limits.Timeout == 0 {
+        limits.Timeout = c.WriteTimeout
+    }
+    if limits.MaxBodyBytes == 0 {
+        limits.MaxBodyBytes = c.MaxBodyBytes
+    }
+    return limits
+}
+
+// checkBudgets warns about downstream timeouts that the gateway's own request budget cuts short
+func (c ServerLimitsConfig) checkBudgets(downstream DownstreamConfig) {
+    budget := c.Limits("POST /graphql").Timeout
+    if downstream.Timeout >= budget {
+        log.Printf("⚠️  DOWNSTREAM_TIMEOUT_SECONDS (%s) is not below the POST /graphql budget (%s); clients time out first", downstream.Timeout, budget)
+    }
+    for baseURL, timeout := range downstream.ServiceTimeouts {
+        if timeout >= budget {
+            log.Printf("⚠️  Timeout for %s (%s) is not below the POST /graphql budget (%s); clients time out first", baseURL, timeout, budget)
+        }
+    }
+}
+
+// limitsMiddleware applies a route's limits: it rejects oversized bodies with 413, moves the
+// connection's read and write deadlines to the route's budget and bounds the request context
+// (and so every downstream call) by it
+func (c ServerLimitsConfig) limitsMiddleware(route string) gin.HandlerFunc {
+    limits := c.Limits(route)
+
+    return func(ctx *gin.Context) {
+        if limits.MaxBodyBytes > 0 && ctx.Request.Body != nil {
+            if ctx.Request.ContentLength > limits.MaxBodyBytes {
+                ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+                    "error": fmt.Sprintf("request body too large (limit is %d bytes)", limits.MaxBodyBytes),
+                })
+                return
+            }
+            ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limits.MaxBodyBytes)
+        }
+
+        deadline := time.Now().Add(limits.Timeout)
+        rc := http.NewResponseController(ctx.Writer)
+        if err := rc.SetReadDeadline(deadline); err != nil {
+            log.Printf("⚠️  Failed to set read deadline for %s: %v", route, err)
+        }
+        if err := rc.SetWriteDeadline(deadline.Add(writeGrace)); err != nil {
+            log.Printf("⚠️  Failed to set write deadline for %s: %v", route, err)
+        }
+
+        reqCtx, cancel := context.WithDeadline(ctx.Request.Context(), deadline)
+        defer cancel()
+        ctx.Request = ctx.Request.WithContext(reqCtx)
+
+        ctx.Next()
+    }
+}
+
+// timeoutFor returns the call timeout for a downstream URL
+func (c DownstreamConfig) timeoutFor(rawURL string) time.Duration {
+    for baseURL, timeout := range c.ServiceTimeouts {
+        if sameHost(baseURL, rawURL) {
+            return timeout
+        }
+    }
+    return c.Timeout
+}
+
+func sameHost(a, b string) bool {
+    pa, errA := url.Parse(a)
+    pb, errB := url.Parse(b)
+    return errA == nil && errB == nil && pa.Host != "" && pa.Host == pb.Host
+}
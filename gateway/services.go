@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 // ============ USER SERVICE ============
@@ -32,14 +35,16 @@ type RegisterRequest struct {
 
 // LoginRequest represents login request
 type LoginRequest struct {
-    Email    string `json:"email"`
-    Password string `json:"password"`
+    Email       string `json:"email"`
+    Password    string `json:"password"`
+    NewPassword string `json:"new_password,omitempty"`
 }
 
 // AuthResponse represents auth response
 type AuthResponse struct {
-    User  map[string]interface{} `json:"user"`
-    Token string                 `json:"token"`
+    User        map[string]interface{} `json:"user"`
+    Token       string                 `json:"token"`
+    AccessToken string                 `json:"access_token"` // what the users service sends; copied into Token
 }
 
 // Register calls users service registration endpoint
@@ -63,11 +68,12 @@ func (us *UserService) Register(ctx context.Context, email, username, password s
     return &authResp, nil
 }
 
-// Login calls users service login endpoint
-func (us *UserService) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
+// Login calls users service login endpoint; newPassword completes a password reset an admin forced
+func (us *UserService) Login(ctx context.Context, email, password, newPassword string) (*AuthResponse, error) {
     reqBody := LoginRequest{
-        Email:    email,
-        Password: password,
+        Email:       email,
+        Password:    password,
+        NewPassword: newPassword,
     }
 
     respBody, err := us.httpClient.POST(ctx, fmt.Sprintf("%s/login", us.baseURL), nil, reqBody)
@@ -79,11 +85,14 @@ func (us *UserService) Login(ctx context.Context, email, password string) (*Auth
     if err := json.Unmarshal(respBody, &authResp); err != nil {
         return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
+    if authResp.Token == "" {
+        authResp.Token = authResp.AccessToken
+    }
 
     return &authResp, nil
 }
 
-// GetProfile calls users service get profile endpoint
+// GetProfile calls users service get profile endpoint, forwarding the caller's token
 func (us *UserService) GetProfile(ctx context.Context, userID string) (map[string]interface{}, error) {
     respBody, err := us.httpClient.GET(ctx, fmt.Sprintf("%s/profile/%s", us.baseURL, url.PathEscape(userID)), nil)
     if err != nil {
@@ -98,26 +107,215 @@ func (us *UserService) GetProfile(ctx context.Context, userID string) (map[strin
     return profile, nil
 }
 
+// UpdateProfile calls users service profile patch endpoint; changes holds only the keys to set
+func (us *UserService) UpdateProfile(ctx context.Context, userID string, changes map[string]interface{}) (map[string]interface{}, error) {
+    respBody, err := us.httpClient.PATCH(ctx, fmt.Sprintf("%s/profile/%s", us.baseURL, url.PathEscape(userID)), nil, changes)
+    if err != nil {
+        return nil, err
+    }
+
+    var resp struct {
+        User map[string]interface{} `json:"user"`
+    }
+    if err := json.Unmarshal(respBody, &resp); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return resp.User, nil
+}
+
+// DeleteAccount calls users service profile delete endpoint with the password confirmation
+func (us *UserService) DeleteAccount(ctx context.Context, userID, password string) error {
+    body := map[string]interface{}{"password": password}
+    _, err := us.httpClient.Request(ctx, http.MethodDelete, fmt.Sprintf("%s/profile/%s", us.baseURL, url.PathEscape(userID)), nil, body)
+    return err
+}
+
+// GetPreferences calls users service preferences endpoint, forwarding the caller's token
+func (us *UserService) GetPreferences(ctx context.Context, userID string) (map[string]interface{}, error) {
+    respBody, err := us.httpClient.GET(ctx, fmt.Sprintf("%s/profile/%s/preferences", us.baseURL, url.PathEscape(userID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var prefs map[string]interface{}
+    if err := json.Unmarshal(respBody, &prefs); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return prefs, nil
+}
+
+// UpdatePreferences calls users service preferences patch endpoint; changes holds only the keys to set
+func (us *UserService) UpdatePreferences(ctx context.Context, userID string, changes map[string]interface{}) (map[string]interface{}, error) {
+    respBody, err := us.httpClient.PATCH(ctx, fmt.Sprintf("%s/profile/%s/preferences", us.baseURL, url.PathEscape(userID)), nil, changes)
+    if err != nil {
+        return nil, err
+    }
+
+    var prefs map[string]interface{}
+    if err := json.Unmarshal(respBody, &prefs); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return prefs, nil
+}
+
+// GetAddresses calls users service address list endpoint
+func (us *UserService) GetAddresses(ctx context.Context, userID string) ([]interface{}, error) {
+    respBody, err := us.httpClient.GET(ctx, fmt.Sprintf("%s/profile/%s/addresses", us.baseURL, url.PathEscape(userID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var list struct {
+        Addresses []interface{} `json:"addresses"`
+    }
+    if err := json.Unmarshal(respBody, &list); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return list.Addresses, nil
+}
+
+// AddAddress calls users service create address endpoint
+func (us *UserService) AddAddress(ctx context.Context, userID string, address map[string]interface{}) (map[string]interface{}, error) {
+    respBody, err := us.httpClient.POST(ctx, fmt.Sprintf("%s/profile/%s/addresses", us.baseURL, url.PathEscape(userID)), nil, address)
+    if err != nil {
+        return nil, err
+    }
+
+    var created map[string]interface{}
+    if err := json.Unmarshal(respBody, &created); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return created, nil
+}
+
+// UserListFilter filters and pages the admin user list (GET /users)
+type UserListFilter struct {
+    Role   string
+    Status string
+    Search string
+    Page   int
+    Limit  int
+}
+
+// ListUsers calls users service admin user list endpoint, forwarding the caller's token
+func (us *UserService) ListUsers(ctx context.Context, filter UserListFilter) (map[string]interface{}, error) {
+    params := url.Values{}
+    if filter.Role != "" {
+        params.Set("role", filter.Role)
+    }
+    if filter.Status != "" {
+        params.Set("status", filter.Status)
+    }
+    if filter.Search != "" {
+        params.Set("q", filter.Search)
+    }
+    if filter.Page > 0 {
+        params.Set("page", strconv.Itoa(filter.Page))
+    }
+    if filter.Limit > 0 {
+        params.Set("limit", strconv.Itoa(filter.Limit))
+    }
+
+    reqURL := fmt.Sprintf("%s/users", us.baseURL)
+    if encoded := params.Encode(); encoded != "" {
+        reqURL += "?" + encoded
+    }
+
+    respBody, err := us.httpClient.GET(ctx, reqURL, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// AdminUserAction calls one of the users service admin account endpoints
+// (POST /users/:id/disable, /enable or /password-reset) and returns the updated user
+func (us *UserService) AdminUserAction(ctx context.Context, userID, action string) (map[string]interface{}, error) {
+    respBody, err := us.httpClient.POST(ctx, fmt.Sprintf("%s/users/%s/%s", us.baseURL, url.PathEscape(userID), action), nil, nil)
+    if err != nil {
+        return nil, err
+    }
+    return decodeAdminUser(respBody)
+}
+
+// UpdateUserRole calls users service role endpoint and returns the updated user
+func (us *UserService) UpdateUserRole(ctx context.Context, userID, role string) (map[string]interface{}, error) {
+    reqBody := map[string]interface{}{"role": role}
+    respBody, err := us.httpClient.Request(ctx, http.MethodPut, fmt.Sprintf("%s/users/%s/role", us.baseURL, url.PathEscape(userID)), nil, reqBody)
+    if err != nil {
+        return nil, err
+    }
+    return decodeAdminUser(respBody)
+}
+
+func decodeAdminUser(respBody []byte) (map[string]interface{}, error) {
+    var resp struct {
+        User map[string]interface{} `json:"user"`
+    }
+    if err := json.Unmarshal(respBody, &resp); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    return resp.User, nil
+}
+
+// forwardCartHeaders passes the caller's guest token on, for the cart routes guests may use.
+// HTTPClient adds the Authorization header; with both, the cart service acts as the user.
+func forwardCartHeaders(ctx context.Context) map[string]string {
+    headers := map[string]string{}
+    if token := guestTokenFromContext(ctx); token != "" {
+        headers[GuestTokenHeader] = token
+    }
+    return headers
+}
+
 // ============ PRODUCT SERVICE ============
 
 // ProductService handles product-related operations
 type ProductService struct {
     baseURL    string
     httpClient *HTTPClient
+    cache      *CatalogCache // nil disables catalog caching
 }
 
-// NewProductService creates a new product service client
-func NewProductService(baseURL string, httpClient *HTTPClient) *ProductService {
+// NewProductService creates a new product service client; cache may be nil
+func NewProductService(baseURL string, httpClient *HTTPClient, cache *CatalogCache) *ProductService {
     return &ProductService{
         baseURL:    baseURL,
         httpClient: httpClient,
+        cache:      cache,
+    }
+}
+
+// getCatalog GETs a catalog endpoint through the catalog cache
+func (ps *ProductService) getCatalog(ctx context.Context, cacheKey, url string) ([]byte, error) {
+    if body, ok := ps.cache.Get(ctx, cacheKey); ok {
+        return body, nil
     }
+
+    respBody, err := ps.httpClient.GET(ctx, url, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    ps.cache.Put(ctx, cacheKey, respBody)
+    return respBody, nil
 }
 
 
 // GetProduct calls products service get endpoint
 func (ps *ProductService) GetProduct(ctx context.Context, id int64) (map[string]interface{}, error) {
-    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/products/%d", ps.baseURL, id), nil)
+    respBody, err := ps.getCatalog(ctx, fmt.Sprintf("%s%d", catalogProductKey, id), fmt.Sprintf("%s/products/%d", ps.baseURL, id))
     if err != nil {
         return nil, err
     }
@@ -135,11 +333,13 @@ func (ps *ProductService) GetProduct(ctx context.Context, id int64) (map[string]
 // GetProducts calls products service list endpoint
 func (ps *ProductService) GetProducts(ctx context.Context, categoryID *int64) ([]map[string]interface{}, error) {
     url := fmt.Sprintf("%s/products", ps.baseURL)
+    cacheKey := catalogProductsKey + "all"
     if categoryID != nil {
         url = fmt.Sprintf("%s?category_id=%d", url, *categoryID)
+        cacheKey = fmt.Sprintf("%scategory=%d", catalogProductsKey, *categoryID)
     }
 
-    respBody, err := ps.httpClient.GET(ctx, url, nil)
+    respBody, err := ps.getCatalog(ctx, cacheKey, url)
     if err != nil {
         return nil, err
     }
@@ -169,9 +369,186 @@ func (ps *ProductService) GetProducts(ctx context.Context, categoryID *int64) ([
     return products, nil
 }
 
+// GetProductsInCategoryTree calls products service list endpoint for a category and all its subcategories
+func (ps *ProductService) GetProductsInCategoryTree(ctx context.Context, categoryID int64) ([]map[string]interface{}, error) {
+    respBody, err := ps.getCatalog(ctx,
+        fmt.Sprintf("%scategory=%d", catalogCategoryTreeKey, categoryID),
+        fmt.Sprintf("%s/products?category_id=%d&include_subcategories=true", ps.baseURL, categoryID))
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Products []map[string]interface{} `json:"products"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    if response.Products == nil {
+        return []map[string]interface{}{}, nil
+    }
+
+    return response.Products, nil
+}
+
+// GetProductsByAttributes calls products service list endpoint with attribute filters
+// (attr[name], attr_min[name], attr_max[name]), optionally within a category
+func (ps *ProductService) GetProductsByAttributes(ctx context.Context, categoryID *int64, includeSubcategories bool, filters url.Values) ([]map[string]interface{}, error) {
+    params := url.Values{}
+    for key, values := range filters {
+        params[key] = values
+    }
+    if categoryID != nil {
+        params.Set("category_id", strconv.FormatInt(*categoryID, 10))
+        if includeSubcategories {
+            params.Set("include_subcategories", "true")
+        }
+    }
+
+    // Encode sorts the parameters, so the same filters share a cache entry
+    query := params.Encode()
+    respBody, err := ps.getCatalog(ctx, catalogProductsKey+"attributes:"+query, fmt.Sprintf("%s/products?%s", ps.baseURL, query))
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Products []map[string]interface{} `json:"products"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    if response.Products == nil {
+        return []map[string]interface{}{}, nil
+    }
+
+    return response.Products, nil
+}
+
+// GetProductsPage calls products service list endpoint for one keyset page (newest first),
+// with the same filters as GetProductsByAttributes
+func (ps *ProductService) GetProductsPage(ctx context.Context, categoryID *int64, includeSubcategories bool, filters url.Values, first int, after string) (map[string]interface{}, error) {
+    params := url.Values{}
+    for key, values := range filters {
+        params[key] = values
+    }
+    cacheKey := catalogProductsKey + "page:"
+    if categoryID != nil {
+        params.Set("category_id", strconv.FormatInt(*categoryID, 10))
+        if includeSubcategories {
+            params.Set("include_subcategories", "true")
+            cacheKey = catalogCategoryTreeKey + "page:"
+        }
+    }
+    // first is always sent so the service answers with a keyset page
+    if first <= 0 {
+        first = defaultConnectionPageSize
+    }
+    params.Set("first", strconv.Itoa(first))
+    if after != "" {
+        params.Set("after", after)
+    }
+
+    query := params.Encode()
+    respBody, err := ps.getCatalog(ctx, cacheKey+query, fmt.Sprintf("%s/products?%s", ps.baseURL, query))
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// GetProductReviewsPage calls products service review list endpoint for one keyset page of
+// approved reviews (newest first) with the rating summary
+func (ps *ProductService) GetProductReviewsPage(ctx context.Context, productID int64, first int, after string) (map[string]interface{}, error) {
+    if first <= 0 {
+        first = defaultConnectionPageSize
+    }
+    params := url.Values{}
+    params.Set("first", strconv.Itoa(first))
+    if after != "" {
+        params.Set("after", after)
+    }
+
+    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/products/%d/reviews?%s", ps.baseURL, productID, params.Encode()), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// GetProductReviews calls products service review list endpoint (approved reviews only)
+func (ps *ProductService) GetProductReviews(ctx context.Context, productID int64, page, limit int) (map[string]interface{}, error) {
+    params := url.Values{}
+    if page > 0 {
+        params.Set("page", strconv.Itoa(page))
+    }
+    if limit > 0 {
+        params.Set("limit", strconv.Itoa(limit))
+    }
+
+    reqURL := fmt.Sprintf("%s/products/%d/reviews", ps.baseURL, productID)
+    if encoded := params.Encode(); encoded != "" {
+        reqURL += "?" + encoded
+    }
+
+    respBody, err := ps.httpClient.GET(ctx, reqURL, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var result map[string]interface{}
+    if err := json.Unmarshal(respBody, &result); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return result, nil
+}
+
+// AddReview calls products service create review endpoint; the forwarded token names the reviewer
+func (ps *ProductService) AddReview(ctx context.Context, productID int64, rating int, text string) (map[string]interface{}, error) {
+    reqBody := map[string]interface{}{
+        "rating": rating,
+        "text":   text,
+    }
+
+    respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/products/%d/reviews", ps.baseURL, productID), nil, reqBody)
+    if err != nil {
+        return nil, err
+    }
+
+    var review map[string]interface{}
+    if err := json.Unmarshal(respBody, &review); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return review, nil
+}
+
+// NotifyMe calls products service back-in-stock subscription endpoint
+func (ps *ProductService) NotifyMe(ctx context.Context, productID int64, userID string) error {
+    reqBody := map[string]interface{}{
+        "user_id": userID,
+    }
+
+    _, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/products/%d/notify-me", ps.baseURL, productID), nil, reqBody)
+    return err
+}
+
 // GetCategories calls products service categories endpoint
 func (ps *ProductService) GetCategories(ctx context.Context) ([]map[string]interface{}, error) {
-    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/categories", ps.baseURL), nil)
+    respBody, err := ps.getCatalog(ctx, catalogCategoriesKey, fmt.Sprintf("%s/categories", ps.baseURL))
     if err != nil {
         return nil, err
     }
@@ -201,7 +578,53 @@ func (ps *ProductService) GetCategories(ctx context.Context) ([]map[string]inter
     return categories, nil
 }
 
-func (ps *ProductService) CreateProduct(ctx context.Context, name, description string, price float64, sku string, stockQuantity, categoryId *int) (map[string]interface{}, error) {
+// GetCategoriesPage calls products service categories endpoint for one keyset page (newest first)
+func (ps *ProductService) GetCategoriesPage(ctx context.Context, first int, after string) (map[string]interface{}, error) {
+    // first is always sent so the service answers with a keyset page
+    if first <= 0 {
+        first = defaultConnectionPageSize
+    }
+    params := url.Values{}
+    params.Set("first", strconv.Itoa(first))
+    if after != "" {
+        params.Set("after", after)
+    }
+
+    query := params.Encode()
+    respBody, err := ps.getCatalog(ctx, catalogCategoryPagesKey+query, fmt.Sprintf("%s/categories?%s", ps.baseURL, query))
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// GetCategoryTree calls products service category tree endpoint (top-level categories with nested children)
+func (ps *ProductService) GetCategoryTree(ctx context.Context) ([]map[string]interface{}, error) {
+    respBody, err := ps.getCatalog(ctx, catalogCategoryTreeKey+"all", fmt.Sprintf("%s/categories/tree", ps.baseURL))
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Categories []map[string]interface{} `json:"categories"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    if response.Categories == nil {
+        return []map[string]interface{}{}, nil
+    }
+
+    return response.Categories, nil
+}
+
+func (ps *ProductService) CreateProduct(ctx context.Context, name, description string, price float64, sku string, stockQuantity, categoryId *int, attributes map[string]interface{}) (map[string]interface{}, error) {
     reqBody :=  map[string]interface{}{
         "name": name,
         "price": price,
@@ -218,11 +641,16 @@ func (ps *ProductService) CreateProduct(ctx context.Context, name, description s
     if categoryId != nil {
         reqBody["category_id"] = *categoryId
     }
+    if attributes != nil {
+        reqBody["attributes"] = attributes
+    }
 
     respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/products", ps.baseURL), nil, reqBody)
     if err != nil {
         return nil, err
     }
+    // The ProductCreated event does the same on every instance; this covers our own reads right away
+    ps.cache.InvalidateProduct(ctx, "")
 
     var product map[string]interface{}
     if err := json.Unmarshal(respBody, &product); err != nil {
@@ -233,7 +661,7 @@ func (ps *ProductService) CreateProduct(ctx context.Context, name, description s
 }
 
 // UpdateProduct calls products service update endpoint
-func (ps *ProductService) UpdateProduct(ctx context.Context, id int64, name, description *string, price *float64, stockQuantity, categoryID *int) (map[string]interface{}, error) {
+func (ps *ProductService) UpdateProduct(ctx context.Context, id int64, name, description *string, price *float64, stockQuantity, categoryID *int, attributes map[string]interface{}) (map[string]interface{}, error) {
     reqBody := map[string]interface{}{}
     if name != nil {
         reqBody["name"] = *name
@@ -250,11 +678,15 @@ func (ps *ProductService) UpdateProduct(ctx context.Context, id int64, name, des
     if categoryID != nil {
         reqBody["category_id"] = *categoryID
     }
+    if attributes != nil {
+        reqBody["attributes"] = attributes
+    }
 
     respBody, err := ps.httpClient.PUT(ctx, fmt.Sprintf("%s/products/%d", ps.baseURL, id), nil, reqBody)
     if err != nil {
         return nil, err
     }
+    ps.cache.InvalidateProduct(ctx, strconv.FormatInt(id, 10))
 
     var product map[string]interface{}
     if err := json.Unmarshal(respBody, &product); err != nil {
@@ -270,23 +702,121 @@ func (ps *ProductService) DeleteProduct(ctx context.Context, id int64) (string,
     if err != nil {
         return "", err
     }
+    ps.cache.InvalidateProduct(ctx, strconv.FormatInt(id, 10))
+
+    var response struct {
+        Message string `json:"message"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return "", fmt.Errorf("failed to unmarshal response: %w", err)
+    }
 
-    return string(respBody), nil
+    return response.Message, nil
+}
+
+// AddVariant calls products service add variant endpoint
+func (ps *ProductService) AddVariant(ctx context.Context, productID int64, sku string, attributes map[string]string, priceOverride *float64, stock int) (map[string]interface{}, error) {
+    reqBody := map[string]interface{}{
+        "sku":        sku,
+        "attributes": attributes,
+        "stock":      stock,
+    }
+    if priceOverride != nil {
+        reqBody["price_override"] = *priceOverride
+    }
+
+    respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/products/%d/variants", ps.baseURL, productID), nil, reqBody)
+    if err != nil {
+        return nil, err
+    }
+    // The product payload embeds its variants; the ProductUpdated event covers other instances
+    ps.cache.InvalidateProduct(ctx, strconv.FormatInt(productID, 10))
+
+    var response struct {
+        Variant map[string]interface{} `json:"variant"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return response.Variant, nil
+}
+
+// GetAttributeTemplates calls products service endpoint listing the attribute templates of a
+// category, including those inherited from its ancestors
+func (ps *ProductService) GetAttributeTemplates(ctx context.Context, categoryID int64) ([]map[string]interface{}, error) {
+    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/categories/%d/attribute-templates", ps.baseURL, categoryID), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Templates []map[string]interface{} `json:"templates"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    if response.Templates == nil {
+        return []map[string]interface{}{}, nil
+    }
+
+    return response.Templates, nil
+}
+
+// CreateAttributeTemplate calls products service create attribute template endpoint
+func (ps *ProductService) CreateAttributeTemplate(ctx context.Context, categoryID int64, template map[string]interface{}) (map[string]interface{}, error) {
+    respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/categories/%d/attribute-templates", ps.baseURL, categoryID), nil, template)
+    if err != nil {
+        return nil, err
+    }
+
+    return parseAttributeTemplate(respBody)
+}
+
+// UpdateAttributeTemplate calls products service update attribute template endpoint
+func (ps *ProductService) UpdateAttributeTemplate(ctx context.Context, id int64, changes map[string]interface{}) (map[string]interface{}, error) {
+    respBody, err := ps.httpClient.PATCH(ctx, fmt.Sprintf("%s/attribute-templates/%d", ps.baseURL, id), nil, changes)
+    if err != nil {
+        return nil, err
+    }
+
+    return parseAttributeTemplate(respBody)
+}
+
+// DeleteAttributeTemplate calls products service delete attribute template endpoint
+func (ps *ProductService) DeleteAttributeTemplate(ctx context.Context, id int64) error {
+    _, err := ps.httpClient.DELETE(ctx, fmt.Sprintf("%s/attribute-templates/%d", ps.baseURL, id), nil)
+    return err
+}
+
+func parseAttributeTemplate(respBody []byte) (map[string]interface{}, error) {
+    var response struct {
+        Template map[string]interface{} `json:"template"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+    return response.Template, nil
 }
 
 // CreateCategory calls products service create category endpoint
-func (ps *ProductService) CreateCategory(ctx context.Context, name, description string) (map[string]interface{}, error) {
+func (ps *ProductService) CreateCategory(ctx context.Context, name, description string, parentID *int64) (map[string]interface{}, error) {
     reqBody := map[string]interface{}{
         "name": name,
     }
     if description != "" {
         reqBody["description"] = description
     }
+    if parentID != nil {
+        reqBody["parent_id"] = *parentID
+    }
 
     respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/categories", ps.baseURL), nil, reqBody)
     if err != nil {
         return nil, err
     }
+    // Categories have no events; only this instance sees the change before the TTL
+    ps.cache.InvalidateCategories(ctx)
 
     var category map[string]interface{}
     if err := json.Unmarshal(respBody, &category); err != nil {
@@ -296,56 +826,121 @@ func (ps *ProductService) CreateCategory(ctx context.Context, name, description
     return category, nil
 }
 
-func (ps *ProductService) GetInventory(ctx context.Context, productId int64)(map[string]interface{}, error){
-    respBody, err := ps.httpClient.GET(ctx,fmt.Sprintf("%s/inventory/%d", ps.baseURL,productId), nil)
+// GetInventory calls products service inventory endpoint
+func (ps *ProductService) GetInventory(ctx context.Context, productID int64) (map[string]interface{}, error) {
+    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/inventory/%d", ps.baseURL, productID), nil)
     if err != nil {
         return nil, err
     }
+    return decodeInventoryStatus(respBody)
+}
 
+// decodeInventoryStatus maps the products service's total_stock/reserved/available onto the
+// InventoryStatus fields; other fields (variant_id, reservation_id, ...) pass through
+func decodeInventoryStatus(respBody []byte) (map[string]interface{}, error) {
     var inventory map[string]interface{}
     if err := json.Unmarshal(respBody, &inventory); err != nil {
-        return nil, fmt.Errorf("failed to unmarshall response: %w", err)
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    for from, to := range map[string]string{
+        "total_stock": "total_quantity",
+        "reserved":    "reserved_quantity",
+        "available":   "available_quantity",
+    } {
+        if v, ok := inventory[from]; ok {
+            inventory[to] = v
+            delete(inventory, from)
+        }
     }
     return inventory, nil
 }
 
-// ReserveInventory calls products service reserve endpoint
-func (ps *ProductService) ReserveInventory(ctx context.Context, productID int64, quantity int) (map[string]interface{}, error) {
+// GetOrderReservations calls products service endpoint listing an order's stock reservations
+func (ps *ProductService) GetOrderReservations(ctx context.Context, orderID int64) ([]interface{}, error) {
+    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/inventory/orders/%d/reservations", ps.baseURL, orderID), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var list struct {
+        Reservations []interface{} `json:"reservations"`
+    }
+    if err := json.Unmarshal(respBody, &list); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return list.Reservations, nil
+}
+
+// GetVariantInventory calls products service inventory endpoint for one variant of a product
+func (ps *ProductService) GetVariantInventory(ctx context.Context, productID, variantID int64) (map[string]interface{}, error) {
+    respBody, err := ps.httpClient.GET(ctx, fmt.Sprintf("%s/inventory/%d?variant_id=%d", ps.baseURL, productID, variantID), nil)
+    if err != nil {
+        return nil, err
+    }
+    return decodeInventoryStatus(respBody)
+}
+
+// ReserveInventory calls products service reserve endpoint (an admin stock hold);
+// ttlSeconds 0 leaves the products service default
+func (ps *ProductService) ReserveInventory(ctx context.Context, productID int64, quantity, ttlSeconds int) (map[string]interface{}, error) {
     reqBody := map[string]interface{}{
         "product_id": productID,
         "quantity":   quantity,
     }
+    if ttlSeconds > 0 {
+        reqBody["ttl_seconds"] = ttlSeconds
+    }
 
     respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/inventory/reserve", ps.baseURL), nil, reqBody)
     if err != nil {
         return nil, err
     }
+    return decodeInventoryStatus(respBody)
+}
 
-    var result map[string]interface{}
-    if err := json.Unmarshal(respBody, &result); err != nil {
-        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+// ReleaseInventory calls products service release endpoint
+func (ps *ProductService) ReleaseInventory(ctx context.Context, productID int64, quantity int) (map[string]interface{}, error) {
+    reqBody := map[string]interface{}{
+        "product_id": productID,
+        "quantity":   quantity,
     }
 
-    return result, nil
+    respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/inventory/release", ps.baseURL), nil, reqBody)
+    if err != nil {
+        return nil, err
+    }
+    return decodeInventoryStatus(respBody)
 }
 
-// ReleaseInventory calls products service release endpoint
-func (ps *ProductService) ReleaseInventory(ctx context.Context, productId int64, quantity int)(map[string]interface{}, error){
+// AdjustInventory calls products service adjust endpoint: stock changes by delta for reason
+// (damage, recount, return). Returns the recorded inventory movement.
+func (ps *ProductService) AdjustInventory(ctx context.Context, productID int64, variantID *int64, delta int, reason, note string) (map[string]interface{}, error) {
     reqBody := map[string]interface{}{
-        "product_id": productId,
-        "quantity": quantity,
+        "delta":  delta,
+        "reason": reason,
+    }
+    if variantID != nil {
+        reqBody["variant_id"] = *variantID
+    }
+    if note != "" {
+        reqBody["note"] = note
     }
 
-    respBody, err := ps.httpClient.POST(ctx,fmt.Sprintf("%s/inventory/release",ps.baseURL),nil,reqBody)
+    respBody, err := ps.httpClient.POST(ctx, fmt.Sprintf("%s/inventory/%d/adjust", ps.baseURL, productID), nil, reqBody)
     if err != nil {
         return nil, err
     }
-    var result map[string]interface{}
-    if err := json.Unmarshal(respBody, &result); err != nil {
-        return nil, fmt.Errorf("failed to unmarshall response: %w", err)
+
+    var response struct {
+        Movement map[string]interface{} `json:"movement"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
 
-    return result, nil
+    return response.Movement, nil
 }
 
 // ============ CART SERVICE ============
@@ -364,59 +959,155 @@ func NewCartService(baseURL string, httpClient *HTTPClient) *CartService {
     }
 }
 
-// GetCart calls cart service get endpoint
-func (cs *CartService) GetCart(ctx context.Context, cartID string) (map[string]interface{}, error) {
-    respBody, err := cs.httpClient.GET(ctx, fmt.Sprintf("%s/carts/%s", cs.baseURL, url.PathEscape(cartID)), nil)
+// GetCart calls cart service get endpoint for the caller's (or guest's) active cart
+func (cs *CartService) GetCart(ctx context.Context) (map[string]interface{}, error) {
+    respBody, err := cs.httpClient.GET(ctx, fmt.Sprintf("%s/carts", cs.baseURL), forwardCartHeaders(ctx))
     if err != nil {
         return nil, err
     }
 
-    var cart map[string]interface{}
-    if err := json.Unmarshal(respBody, &cart); err != nil {
+    var response struct {
+        Cart map[string]interface{} `json:"cart"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
         return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
 
-    return cart, nil
+    return response.Cart, nil
 }
 
-// AddToCart calls cart service add item endpoint
-func (cs *CartService) AddToCart(ctx context.Context, cartID string, productID int64, quantity int) (map[string]interface{}, error) {
+// AddToCart calls cart service add item endpoint as the caller and returns the updated cart,
+// with the soft lock held for the line when the cart service took one.
+// The cart service creates the caller's cart on the first add.
+func (cs *CartService) AddToCart(ctx context.Context, productID int64, variantID *int64, quantity int, price float64) (map[string]interface{}, map[string]interface{}, error) {
     reqBody := map[string]interface{}{
         "product_id": productID,
         "quantity":   quantity,
+        "price":      price,
+    }
+    if variantID != nil {
+        reqBody["variant_id"] = *variantID
+    }
+
+    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/items", cs.baseURL), forwardCartHeaders(ctx), reqBody)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var response struct {
+        Cart     map[string]interface{} `json:"cart"`
+        SoftLock map[string]interface{} `json:"soft_lock"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return response.Cart, response.SoftLock, nil
+}
+
+// RemoveFromCart calls cart service remove item endpoint as the caller (or guest) and returns
+// the updated cart; with a variant ID only that variant is removed
+func (cs *CartService) RemoveFromCart(ctx context.Context, productID int64, variantID *int64) (map[string]interface{}, error) {
+    endpoint := fmt.Sprintf("%s/carts/items/%d", cs.baseURL, productID)
+    if variantID != nil {
+        endpoint = fmt.Sprintf("%s?variant_id=%d", endpoint, *variantID)
+    }
+    respBody, err := cs.httpClient.DELETE(ctx, endpoint, forwardCartHeaders(ctx))
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Cart map[string]interface{} `json:"cart"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return response.Cart, nil
+}
+
+// MergeGuestCart calls cart service merge endpoint: the guest's cart is folded into the user's
+// active cart, which is returned. authHeader is the user's "Bearer ..." token, since right after
+// login the request itself carries none.
+func (cs *CartService) MergeGuestCart(ctx context.Context, authHeader, guestToken string) (map[string]interface{}, error) {
+    headers := map[string]string{
+        "Authorization":  authHeader,
+        GuestTokenHeader: guestToken,
+    }
+    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/merge", cs.baseURL), headers, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var response struct {
+        Cart map[string]interface{} `json:"cart"`
     }
+    if err := json.Unmarshal(respBody, &response); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return response.Cart, nil
+}
 
-    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/%s/items", cs.baseURL, url.PathEscape(cartID)), nil, reqBody)
+// ApplyCoupon calls cart service apply coupon endpoint as the caller and returns the updated cart
+func (cs *CartService) ApplyCoupon(ctx context.Context, code string) (map[string]interface{}, error) {
+    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/apply-coupon", cs.baseURL), nil, map[string]interface{}{"code": code})
     if err != nil {
         return nil, err
     }
 
-    var cart map[string]interface{}
-    if err := json.Unmarshal(respBody, &cart); err != nil {
+    var response struct {
+        Cart map[string]interface{} `json:"cart"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
         return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
 
-    return cart, nil
+    return response.Cart, nil
 }
 
-// RemoveFromCart calls cart service remove item endpoint
-func (cs *CartService) RemoveFromCart(ctx context.Context, cartID string, productID int64) (map[string]interface{}, error) {
-    respBody, err := cs.httpClient.DELETE(ctx, fmt.Sprintf("%s/carts/%s/items/%d", cs.baseURL, url.PathEscape(cartID), productID), nil)
+// RemoveCoupon calls cart service remove coupon endpoint as the caller and returns the updated cart
+func (cs *CartService) RemoveCoupon(ctx context.Context) (map[string]interface{}, error) {
+    respBody, err := cs.httpClient.DELETE(ctx, fmt.Sprintf("%s/carts/coupon", cs.baseURL), nil)
     if err != nil {
         return nil, err
     }
 
-    var cart map[string]interface{}
-    if err := json.Unmarshal(respBody, &cart); err != nil {
+    var response struct {
+        Cart map[string]interface{} `json:"cart"`
+    }
+    if err := json.Unmarshal(respBody, &response); err != nil {
         return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
 
-    return cart, nil
+    return response.Cart, nil
+}
+
+// GetAdminFunnel calls cart service admin funnel endpoint, forwarding the caller's token
+func (cs *CartService) GetAdminFunnel(ctx context.Context, hours int) (map[string]interface{}, error) {
+    return getAdminFunnel(ctx, cs.httpClient, cs.baseURL, hours)
 }
 
-// Checkout calls cart service checkout endpoint
-func (cs *CartService) Checkout(ctx context.Context, cartID string) (map[string]interface{}, error) {
-    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/%s/checkout", cs.baseURL, url.PathEscape(cartID)), nil, nil)
+// GetSagaState calls cart service admin saga endpoint, forwarding the caller's token
+func (cs *CartService) GetSagaState(ctx context.Context, correlationID string) (map[string]interface{}, error) {
+    respBody, err := cs.httpClient.GET(ctx, fmt.Sprintf("%s/admin/sagas/%s", cs.baseURL, url.PathEscape(correlationID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var saga map[string]interface{}
+    if err := json.Unmarshal(respBody, &saga); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return saga, nil
+}
+
+// Checkout calls cart service checkout endpoint as the caller, shipping to one of their saved addresses
+func (cs *CartService) Checkout(ctx context.Context, addressID string) (map[string]interface{}, error) {
+    reqBody := map[string]interface{}{"address_id": addressID}
+    respBody, err := cs.httpClient.POST(ctx, fmt.Sprintf("%s/carts/checkout", cs.baseURL), nil, reqBody)
     if err != nil {
         return nil, err
     }
@@ -445,7 +1136,7 @@ func NewOrderService(baseURL string, httpClient *HTTPClient) *OrderService {
     }
 }
 
-// GetOrder calls orders service get endpoint
+// GetOrder calls orders service get endpoint as the caller, who must own the order or be an admin
 func (os *OrderService) GetOrder(ctx context.Context, orderID int64) (map[string]interface{}, error) {
     respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/orders/%d", os.baseURL, orderID), nil)
     if err != nil {
@@ -460,19 +1151,146 @@ func (os *OrderService) GetOrder(ctx context.Context, orderID int64) (map[string
     return order, nil
 }
 
-// GetOrders calls orders service list endpoint
-func (os *OrderService) GetOrders(ctx context.Context, userID string) ([]map[string]interface{}, error) {
-    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/users/%s/orders", os.baseURL, url.PathEscape(userID)), nil)
+// GetCheckout calls orders service get checkout endpoint
+func (os *OrderService) GetCheckout(ctx context.Context, checkoutID int64) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/checkouts/%d", os.baseURL, checkoutID), nil)
     if err != nil {
         return nil, err
     }
 
-    var orders []map[string]interface{}
-    if err := json.Unmarshal(respBody, &orders); err != nil {
+    var checkout map[string]interface{}
+    if err := json.Unmarshal(respBody, &checkout); err != nil {
         return nil, fmt.Errorf("failed to unmarshal response: %w", err)
     }
 
-    return orders, nil
+    return checkout, nil
+}
+
+// OrderHistoryFilter mirrors the orders service GET /orders query params
+type OrderHistoryFilter struct {
+    Statuses []string
+    From     string // RFC3339 or YYYY-MM-DD
+    To       string
+    MinTotal *float64
+    MaxTotal *float64
+    Page     int
+    Limit    int
+    Sort     string
+    First    int    // keyset page size; with After, replaces Page and Limit
+    After    string // cursor of the previous keyset page
+}
+
+// query encodes the filter as GET /users/:user_id/orders query params
+func (f OrderHistoryFilter) query() url.Values {
+    q := url.Values{}
+    if len(f.Statuses) > 0 {
+        q.Set("status", strings.Join(f.Statuses, ","))
+    }
+    if f.From != "" {
+        q.Set("from", f.From)
+    }
+    if f.To != "" {
+        q.Set("to", f.To)
+    }
+    if f.MinTotal != nil {
+        q.Set("min_total", strconv.FormatFloat(*f.MinTotal, 'f', -1, 64))
+    }
+    if f.MaxTotal != nil {
+        q.Set("max_total", strconv.FormatFloat(*f.MaxTotal, 'f', -1, 64))
+    }
+    if f.Page > 0 {
+        q.Set("page", strconv.Itoa(f.Page))
+    }
+    if f.Limit > 0 {
+        q.Set("limit", strconv.Itoa(f.Limit))
+    }
+    if f.Sort != "" {
+        q.Set("sort", f.Sort)
+    }
+    if f.First > 0 {
+        q.Set("first", strconv.Itoa(f.First))
+    }
+    if f.After != "" {
+        q.Set("after", f.After)
+    }
+    return q
+}
+
+// OrderHistory is one page of a user's order history, as the orders service lists it
+type OrderHistory struct {
+    Orders   []map[string]interface{} `json:"orders"`
+    Count    int                      `json:"count"`     // orders on this page
+    NextPage *int                     `json:"next_page"` // nil on the last page
+}
+
+// ordersURL is the orders service's history of userID, listed as that user or an admin
+func (os *OrderService) ordersURL(userID string, filter OrderHistoryFilter) string {
+    return fmt.Sprintf("%s/users/%s/orders?%s", os.baseURL, url.PathEscape(userID), filter.query().Encode())
+}
+
+// GetOrders calls orders service history endpoint with history filters, forwarding the caller's token
+func (os *OrderService) GetOrders(ctx context.Context, userID string, filter OrderHistoryFilter) (*OrderHistory, error) {
+    respBody, err := os.httpClient.GET(ctx, os.ordersURL(userID, filter), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var history OrderHistory
+    if err := json.Unmarshal(respBody, &history); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return &history, nil
+}
+
+// GetOrdersPage calls orders service history endpoint for one keyset page of order history;
+// filter.First or filter.After must be set
+func (os *OrderService) GetOrdersPage(ctx context.Context, userID string, filter OrderHistoryFilter) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, os.ordersURL(userID, filter), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// GetAdminStats calls orders service admin stats endpoint, forwarding the caller's token
+func (os *OrderService) GetAdminStats(ctx context.Context, period, from, to string, top int) (map[string]interface{}, error) {
+    q := url.Values{}
+    if period != "" {
+        q.Set("period", period)
+    }
+    if from != "" {
+        q.Set("from", from)
+    }
+    if to != "" {
+        q.Set("to", to)
+    }
+    if top > 0 {
+        q.Set("top", strconv.Itoa(top))
+    }
+
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/admin/stats?%s", os.baseURL, q.Encode()), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var stats map[string]interface{}
+    if err := json.Unmarshal(respBody, &stats); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return stats, nil
+}
+
+// GetAdminFunnel calls orders service admin funnel endpoint, forwarding the caller's token
+func (os *OrderService) GetAdminFunnel(ctx context.Context, hours int) (map[string]interface{}, error) {
+    return getAdminFunnel(ctx, os.httpClient, os.baseURL, hours)
 }
 
 // CancelOrder calls orders service cancel endpoint
@@ -490,9 +1308,41 @@ func (os *OrderService) CancelOrder(ctx context.Context, orderID int64) (map[str
     return order, nil
 }
 
+// RetryPayment calls orders service retry payment endpoint
+func (os *OrderService) RetryPayment(ctx context.Context, orderID int64) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.POST(ctx, fmt.Sprintf("%s/orders/%d/retry-payment", os.baseURL, orderID), nil, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var retry map[string]interface{}
+    if err := json.Unmarshal(respBody, &retry); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return retry, nil
+}
+
+// GetAnnouncements calls orders service list active announcements endpoint
+func (os *OrderService) GetAnnouncements(ctx context.Context) ([]map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/announcements", os.baseURL), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var list struct {
+        Announcements []map[string]interface{} `json:"announcements"`
+    }
+    if err := json.Unmarshal(respBody, &list); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return list.Announcements, nil
+}
+
 // GetSagaState calls orders service get saga state endpoint
 func (os *OrderService) GetSagaState(ctx context.Context, correlationID string) (map[string]interface{}, error) {
-    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/saga/%s", os.baseURL, url.PathEscape(correlationID)), nil)
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/sagas/%s", os.baseURL, url.PathEscape(correlationID)), nil)
     if err != nil {
         return nil, err
     }
@@ -503,4 +1353,128 @@ func (os *OrderService) GetSagaState(ctx context.Context, correlationID string)
     }
 
     return sagaState, nil
-}
\ No newline at end of file
+}
+
+// GetCheckoutResult calls orders service checkout result endpoint
+func (os *OrderService) GetCheckoutResult(ctx context.Context, correlationID string) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/checkout/%s/result", os.baseURL, url.PathEscape(correlationID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var result map[string]interface{}
+    if err := json.Unmarshal(respBody, &result); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return result, nil
+}
+
+// GetSagaTimeline calls orders service admin saga timeline endpoint, forwarding the caller's token
+func (os *OrderService) GetSagaTimeline(ctx context.Context, correlationID string) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/admin/sagas/%s/timeline", os.baseURL, url.PathEscape(correlationID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var timeline map[string]interface{}
+    if err := json.Unmarshal(respBody, &timeline); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return timeline, nil
+}
+
+// GetSagaRecords calls orders service admin endpoint listing a saga's orders and compensations,
+// forwarding the caller's token
+func (os *OrderService) GetSagaRecords(ctx context.Context, correlationID string) (map[string]interface{}, error) {
+    respBody, err := os.httpClient.GET(ctx, fmt.Sprintf("%s/admin/sagas/%s/records", os.baseURL, url.PathEscape(correlationID)), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var records map[string]interface{}
+    if err := json.Unmarshal(respBody, &records); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return records, nil
+}
+
+// GetAuditLogs calls users service admin audit log endpoint, forwarding the caller's token
+func (us *UserService) GetAuditLogs(ctx context.Context, filter AuditLogFilter) (map[string]interface{}, error) {
+    return getAuditLogs(ctx, us.httpClient, us.baseURL, filter)
+}
+
+// GetAuditLogs calls products service admin audit log endpoint, forwarding the caller's token
+func (ps *ProductService) GetAuditLogs(ctx context.Context, filter AuditLogFilter) (map[string]interface{}, error) {
+    return getAuditLogs(ctx, ps.httpClient, ps.baseURL, filter)
+}
+
+// GetAuditLogs calls orders service admin audit log endpoint, forwarding the caller's token
+func (os *OrderService) GetAuditLogs(ctx context.Context, filter AuditLogFilter) (map[string]interface{}, error) {
+    return getAuditLogs(ctx, os.httpClient, os.baseURL, filter)
+}
+
+// AuditLogFilter filters and pages an audit log (GET /admin/audit-logs)
+type AuditLogFilter struct {
+    Action     string
+    ActorID    string
+    TargetType string
+    TargetID   string
+    Page       int
+    Limit      int
+}
+
+// getAuditLogs fetches one page of GET /admin/audit-logs from a service
+func getAuditLogs(ctx context.Context, client *HTTPClient, baseURL string, filter AuditLogFilter) (map[string]interface{}, error) {
+    params := url.Values{}
+    for name, value := range map[string]string{
+        "action":      filter.Action,
+        "actor_id":    filter.ActorID,
+        "target_type": filter.TargetType,
+        "target_id":   filter.TargetID,
+    } {
+        if value != "" {
+            params.Set(name, value)
+        }
+    }
+    if filter.Page > 0 {
+        params.Set("page", strconv.Itoa(filter.Page))
+    }
+    if filter.Limit > 0 {
+        params.Set("limit", strconv.Itoa(filter.Limit))
+    }
+
+    reqURL := fmt.Sprintf("%s/admin/audit-logs", baseURL)
+    if encoded := params.Encode(); encoded != "" {
+        reqURL += "?" + encoded
+    }
+
+    respBody, err := client.GET(ctx, reqURL, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var page map[string]interface{}
+    if err := json.Unmarshal(respBody, &page); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return page, nil
+}
+
+// getAdminFunnel fetches GET /admin/funnel?hours= from a service
+func getAdminFunnel(ctx context.Context, client *HTTPClient, baseURL string, hours int) (map[string]interface{}, error) {
+    respBody, err := client.GET(ctx, fmt.Sprintf("%s/admin/funnel?hours=%d", baseURL, hours), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var funnel map[string]interface{}
+    if err := json.Unmarshal(respBody, &funnel); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+    }
+
+    return funnel, nil
+}
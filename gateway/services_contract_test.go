@@ -0,0 +1,333 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// Contract tests: each service client against a fake of its downstream service that checks the
+// request the real service expects (method, path, query, forwarded token, body) and answers with
+// the response shape the real service sends. A handler or client change that breaks the other
+// side fails here rather than at runtime.
+
+// contractCall is what the fake service saw
+type contractCall struct {
+    Method        string
+    Path          string
+    Query         string
+    Authorization string
+    Body          map[string]interface{}
+}
+
+// contractService serves status and response to every request and records the last one
+func contractService(t *testing.T, status int, response string) (*httptest.Server, *contractCall) {
+    t.Helper()
+    call := &contractCall{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        call.Method = r.Method
+        call.Path = r.URL.EscapedPath()
+        call.Query = r.URL.RawQuery
+        call.Authorization = r.Header.Get("Authorization")
+        call.Body = nil
+        if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+            if err := json.Unmarshal(data, &call.Body); err != nil {
+                t.Errorf("request body isn't a JSON object: %s", data)
+            }
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(status)
+        io.WriteString(w, response)
+    }))
+    t.Cleanup(server.Close)
+    return server, call
+}
+
+func contractClient() *HTTPClient {
+    return NewHTTPClient(DownstreamConfig{Timeout: 5 * time.Second})
+}
+
+// callerContext is a gateway request context carrying the caller's Authorization header
+func callerContext(header string) context.Context {
+    return context.WithValue(context.Background(), AuthTokenContextKey, header)
+}
+
+func TestUserServiceLoginContract(t *testing.T) {
+    // users service: POST /login → models.LoginResponse
+    server, call := contractService(t, http.StatusOK, `{
+        "user": {"id": "u-1", "email": "ada@example.test", "role": "customer"},
+        "access_token": "jwt-access",
+        "refresh_token": "jwt-refresh",
+        "expires_in": 3600,
+        "token_type": "Bearer"
+    }`)
+
+    auth, err := NewUserService(server.URL, contractClient()).Login(context.Background(), "ada@example.test", "hunter22", "")
+    if err != nil {
+        t.Fatalf("login: %v", err)
+    }
+
+    if call.Method != http.MethodPost || call.Path != "/login" {
+        t.Errorf("request = %s %s, want POST /login", call.Method, call.Path)
+    }
+    if call.Body["email"] != "ada@example.test" || call.Body["password"] != "hunter22" {
+        t.Errorf("body = %v", call.Body)
+    }
+    if _, ok := call.Body["new_password"]; ok {
+        t.Errorf("new_password sent without a pending reset: %v", call.Body)
+    }
+    if auth.Token != "jwt-access" || auth.User["id"] != "u-1" {
+        t.Errorf("auth = token %q, user %v", auth.Token, auth.User)
+    }
+}
+
+func TestUserServiceAuditLogsContract(t *testing.T) {
+    // users service: GET /admin/audit-logs → audit.ListResponse
+    server, call := contractService(t, http.StatusOK, `{"entries": [{"id": 1, "service": "users", "action": "user.disabled"}], "count": 1, "total": 1, "page": 2, "limit": 10}`)
+
+    page, err := NewUserService(server.URL, contractClient()).GetAuditLogs(callerContext("Bearer admin"), AuditLogFilter{
+        Action: "user.disabled",
+        Page:   2,
+        Limit:  10,
+    })
+    if err != nil {
+        t.Fatalf("audit logs: %v", err)
+    }
+
+    if call.Method != http.MethodGet || call.Path != "/admin/audit-logs" || call.Query != "action=user.disabled&limit=10&page=2" {
+        t.Errorf("request = %s %s?%s", call.Method, call.Path, call.Query)
+    }
+    if call.Authorization != "Bearer admin" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+    if entries, _ := page["entries"].([]interface{}); len(entries) != 1 || page["total"] != float64(1) {
+        t.Errorf("page = %v", page)
+    }
+}
+
+func TestHTTPClientForwardsTheCaller(t *testing.T) {
+    // Every call carries the caller's token, even to routes that don't require one
+    server, call := contractService(t, http.StatusOK, `{"id": 7}`)
+
+    if _, err := NewProductService(server.URL, contractClient(), nil).GetProduct(callerContext("Bearer shopper"), 7); err != nil {
+        t.Fatalf("get product: %v", err)
+    }
+    if call.Authorization != "Bearer shopper" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+
+    if _, err := NewProductService(server.URL, contractClient(), nil).GetProduct(context.Background(), 7); err != nil {
+        t.Fatalf("get product: %v", err)
+    }
+    if call.Authorization != "" {
+        t.Errorf("anonymous Authorization = %q, want none", call.Authorization)
+    }
+
+    // A header the call sets itself wins
+    if _, err := NewCartService(server.URL, contractClient()).MergeGuestCart(callerContext("Bearer stale"), "Bearer fresh", "guest-token"); err != nil {
+        t.Fatalf("merge: %v", err)
+    }
+    if call.Authorization != "Bearer fresh" {
+        t.Errorf("merge Authorization = %q, want the one passed in", call.Authorization)
+    }
+}
+
+func TestProductServiceCreateProductContract(t *testing.T) {
+    // products service: POST /products → 201 with the product
+    server, call := contractService(t, http.StatusCreated, `{"id": 42, "name": "Desk", "price": 120.5, "sku": "DESK-1"}`)
+
+    stock, category := 7, 3
+    product, err := NewProductService(server.URL, contractClient(), nil).CreateProduct(callerContext("Bearer admin"), "Desk", "", 120.5, "DESK-1", &stock, &category, nil)
+    if err != nil {
+        t.Fatalf("create product: %v", err)
+    }
+
+    if call.Method != http.MethodPost || call.Path != "/products" {
+        t.Errorf("request = %s %s, want POST /products", call.Method, call.Path)
+    }
+    if call.Authorization != "Bearer admin" {
+        t.Errorf("Authorization = %q, want the caller's (the audit log records it)", call.Authorization)
+    }
+    want := map[string]interface{}{"name": "Desk", "price": 120.5, "sku": "DESK-1", "stock_quantity": float64(7), "category_id": float64(3)}
+    for field, value := range want {
+        if call.Body[field] != value {
+            t.Errorf("body %s = %v, want %v", field, call.Body[field], value)
+        }
+    }
+    if _, ok := call.Body["description"]; ok {
+        t.Errorf("empty description sent: %v", call.Body)
+    }
+    if product["id"] != float64(42) {
+        t.Errorf("product = %v", product)
+    }
+}
+
+func TestProductServiceCategoriesPageContract(t *testing.T) {
+    // products service: GET /categories?first=&after= → a keyset page of categories
+    server, call := contractService(t, http.StatusOK, `{"edges": [{"cursor": "c2", "node": {"id": 2, "name": "Desks"}}], "page_info": {"has_next_page": true, "end_cursor": "c2"}}`)
+
+    page, err := NewProductService(server.URL, contractClient(), nil).GetCategoriesPage(context.Background(), 0, "c1")
+    if err != nil {
+        t.Fatalf("categories page: %v", err)
+    }
+
+    if call.Method != http.MethodGet || call.Path != "/categories" || call.Query != "after=c1&first=20" {
+        t.Errorf("request = %s %s?%s, want GET /categories with the default first and the cursor", call.Method, call.Path, call.Query)
+    }
+    conn := relayConnection(page)
+    if edges, _ := conn["edges"].([]interface{}); len(edges) != 1 {
+        t.Errorf("edges = %v", conn["edges"])
+    }
+    if info, _ := conn["pageInfo"].(map[string]interface{}); info["hasNextPage"] != true || info["endCursor"] != "c2" {
+        t.Errorf("pageInfo = %v", conn["pageInfo"])
+    }
+}
+
+func TestProductServiceAdjustInventoryContract(t *testing.T) {
+    // products service: POST /inventory/:product_id/adjust → 201 with the recorded movement
+    server, call := contractService(t, http.StatusCreated, `{"message": "Stock adjusted successfully", "movement": {"id": 90, "product_id": 42, "delta": -3, "stock_after": 4, "reason": "damage", "actor_id": "admin-1"}}`)
+
+    movement, err := NewProductService(server.URL, contractClient(), nil).AdjustInventory(callerContext("Bearer admin"), 42, nil, -3, "damage", "")
+    if err != nil {
+        t.Fatalf("adjust inventory: %v", err)
+    }
+
+    if call.Method != http.MethodPost || call.Path != "/inventory/42/adjust" {
+        t.Errorf("request = %s %s, want POST /inventory/42/adjust", call.Method, call.Path)
+    }
+    if call.Authorization != "Bearer admin" {
+        t.Errorf("Authorization = %q, want the caller's (the movement and audit log record it)", call.Authorization)
+    }
+    if call.Body["delta"] != float64(-3) || call.Body["reason"] != "damage" {
+        t.Errorf("body = %v, want delta -3 for damage", call.Body)
+    }
+    for _, field := range []string{"note", "variant_id"} {
+        if _, ok := call.Body[field]; ok {
+            t.Errorf("empty %s sent: %v", field, call.Body)
+        }
+    }
+    if movement["id"] != float64(90) || movement["stock_after"] != float64(4) {
+        t.Errorf("movement = %v", movement)
+    }
+}
+
+func TestCartServiceCheckoutContract(t *testing.T) {
+    // cart service: POST /carts/checkout → 202 with the saga's correlation ID
+    server, call := contractService(t, http.StatusAccepted, `{"message": "Checkout initiated", "correlation_id": "corr-1", "saga_state": {"status": "started"}}`)
+
+    result, err := NewCartService(server.URL, contractClient()).Checkout(callerContext("Bearer shopper"), "addr-9")
+    if err != nil {
+        t.Fatalf("checkout: %v", err)
+    }
+
+    if call.Method != http.MethodPost || call.Path != "/carts/checkout" {
+        t.Errorf("request = %s %s, want POST /carts/checkout", call.Method, call.Path)
+    }
+    if call.Authorization != "Bearer shopper" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+    if len(call.Body) != 1 || call.Body["address_id"] != "addr-9" {
+        t.Errorf("body = %v, want only address_id", call.Body)
+    }
+    if result["correlation_id"] != "corr-1" {
+        t.Errorf("result = %v", result)
+    }
+}
+
+func TestCartServiceCheckoutErrorContract(t *testing.T) {
+    // cart service refusals are models.ErrorResponse; a 4xx is the caller's to fix
+    server, _ := contractService(t, http.StatusBadRequest, `{"error": "cart is empty", "message": "cannot checkout empty cart", "code": 400}`)
+
+    _, err := NewCartService(server.URL, contractClient()).Checkout(callerContext("Bearer shopper"), "addr-9")
+
+    var gatewayErr *GatewayError
+    if !errors.As(err, &gatewayErr) {
+        t.Fatalf("err = %v, want a GatewayError", err)
+    }
+    if gatewayErr.Code != CodeValidation || gatewayErr.Message != "cart is empty: cannot checkout empty cart" {
+        t.Errorf("error = %s %q", gatewayErr.Code, gatewayErr.Message)
+    }
+}
+
+func TestOrderServiceCheckoutResultContract(t *testing.T) {
+    // orders service: GET /checkout/:correlation_id/result → models.CheckoutResult
+    server, call := contractService(t, http.StatusOK, `{"correlation_id": "corr/1", "user_id": "u-1", "outcome": "succeeded", "order_id": 77, "order_ids": [77], "saga_status": "completed"}`)
+
+    result, err := NewOrderService(server.URL, contractClient()).GetCheckoutResult(context.Background(), "corr/1")
+    if err != nil {
+        t.Fatalf("checkout result: %v", err)
+    }
+
+    if call.Method != http.MethodGet || call.Path != "/checkout/corr%2F1/result" {
+        t.Errorf("request = %s %s, want the correlation ID escaped", call.Method, call.Path)
+    }
+    if result["outcome"] != "succeeded" || result["order_id"] != float64(77) {
+        t.Errorf("result = %v", result)
+    }
+}
+
+func TestOrderServiceGetOrderContract(t *testing.T) {
+    // orders service: GET /orders/:id as the caller → the caller's order
+    server, call := contractService(t, http.StatusOK, `{"id": 77, "user_id": "u-1", "status": "placed"}`)
+
+    order, err := NewOrderService(server.URL, contractClient()).GetOrder(callerContext("Bearer shopper"), 77)
+    if err != nil {
+        t.Fatalf("get order: %v", err)
+    }
+
+    if call.Method != http.MethodGet || call.Path != "/orders/77" {
+        t.Errorf("request = %s %s, want GET /orders/77", call.Method, call.Path)
+    }
+    if call.Authorization != "Bearer shopper" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+    if order["user_id"] != "u-1" {
+        t.Errorf("order = %v", order)
+    }
+}
+
+func TestOrderServiceGetOrdersContract(t *testing.T) {
+    // orders service: GET /users/:user_id/orders as the caller → {orders, count, next_page}
+    server, call := contractService(t, http.StatusOK, `{"orders": [{"id": 77}], "count": 1, "next_page": 3}`)
+
+    history, err := NewOrderService(server.URL, contractClient()).GetOrders(callerContext("Bearer shopper"), "u-1", OrderHistoryFilter{Statuses: []string{"placed"}, Page: 2, Limit: 1})
+    if err != nil {
+        t.Fatalf("get orders: %v", err)
+    }
+
+    if call.Method != http.MethodGet || call.Path != "/users/u-1/orders" || call.Query != "limit=1&page=2&status=placed" {
+        t.Errorf("request = %s %s?%s, want GET /users/u-1/orders with the filters", call.Method, call.Path, call.Query)
+    }
+    if call.Authorization != "Bearer shopper" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+    if history.Count != 1 || len(history.Orders) != 1 || history.NextPage == nil || *history.NextPage != 3 {
+        t.Errorf("history = %+v", history)
+    }
+}
+
+func TestOrderServiceCancelOrderContract(t *testing.T) {
+    // orders service: POST /orders/:id/cancel → the cancelled order
+    server, call := contractService(t, http.StatusOK, `{"id": 77, "status": "cancelled"}`)
+
+    order, err := NewOrderService(server.URL, contractClient()).CancelOrder(callerContext("Bearer shopper"), 77)
+    if err != nil {
+        t.Fatalf("cancel: %v", err)
+    }
+
+    if call.Method != http.MethodPost || call.Path != "/orders/77/cancel" || call.Body != nil {
+        t.Errorf("request = %s %s %v, want POST /orders/77/cancel without a body", call.Method, call.Path, call.Body)
+    }
+    if call.Authorization != "Bearer shopper" {
+        t.Errorf("Authorization = %q, want the caller's", call.Authorization)
+    }
+    if order["status"] != "cancelled" {
+        t.Errorf("order = %v", order)
+    }
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestDecodeInventoryStatus(t *testing.T) {
+    inventory, err := decodeInventoryStatus([]byte(`{"product_id": 7, "variant_id": 3, "total_stock": 10, "reserved": 4, "available": 6}`))
+    if err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+
+    want := map[string]float64{
+        "product_id":         7,
+        "variant_id":         3,
+        "total_quantity":     10,
+        "reserved_quantity":  4,
+        "available_quantity": 6,
+    }
+    for field, value := range want {
+        if got, _ := inventory[field].(float64); got != value {
+            t.Fatalf("%s: expected %v, got %v", field, value, inventory[field])
+        }
+    }
+    for _, field := range []string{"total_stock", "reserved", "available"} {
+        if _, ok := inventory[field]; ok {
+            t.Fatalf("expected %s to be renamed", field)
+        }
+    }
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+    "fmt"
+    "net/mail"
+    "strings"
+
+    "github.com/graphql-go/graphql"
+)
+
+// Argument validation
+// Why: resolvers read p.Args with bare type assertions and passed whatever came through on to
+// the services, so a missing argument was a recovered panic (INTERNAL_ERROR) and a zero price or
+// a malformed email was a round trip to be refused downstream. Resolvers now read arguments
+// through an argReader, which never panics and collects every bad field, and the resolver returns
+// one VALIDATION_ERROR listing them in extensions.fields before any service is called.
+
+// Argument bounds checked at the gateway; the services check them again
+const (
+    maxQuantity       = 999 // per cart line or inventory call
+    minPasswordLength = 6   // same as the users service
+    maxNameLength     = 255
+)
+
+// FieldError is one invalid argument, listed in extensions.fields of a VALIDATION_ERROR
+type FieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// argReader reads resolver arguments without panicking and collects what's wrong with them
+type argReader struct {
+    args   map[string]interface{}
+    fields []FieldError
+}
+
+// readArgs starts reading a resolver's arguments
+func readArgs(p graphql.ResolveParams) *argReader {
+    return &argReader{args: p.Args}
+}
+
+// fail records a problem with an argument
+func (a *argReader) fail(field, message string) {
+    a.fields = append(a.fields, FieldError{Field: field, Message: message})
+}
+
+// Check records message for field unless ok
+func (a *argReader) Check(ok bool, field, message string) {
+    if !ok {
+        a.fail(field, message)
+    }
+}
+
+// present reports whether an argument was given with a non-null value
+func (a *argReader) present(name string) bool {
+    value, ok := a.args[name]
+    return ok && value != nil
+}
+
+// Int returns a required Int argument
+func (a *argReader) Int(name string) int {
+    value, ok := a.args[name].(int)
+    if !ok {
+        a.required(name, "an integer")
+    }
+    return value
+}
+
+// OptionalInt returns an Int argument, nil when it wasn't given
+func (a *argReader) OptionalInt(name string) *int {
+    if !a.present(name) {
+        return nil
+    }
+    value, ok := a.args[name].(int)
+    if !ok {
+        a.fail(name, "must be an integer")
+        return nil
+    }
+    return &value
+}
+
+// ID returns a required ID argument, which must be positive
+func (a *argReader) ID(name string) int64 {
+    id, ok := a.args[name].(int)
+    if !ok {
+        a.required(name, "an ID")
+        return 0
+    }
+    if id <= 0 {
+        a.fail(name, "must be a positive ID")
+    }
+    return int64(id)
+}
+
+// OptionalID returns an ID argument, nil when it wasn't given
+func (a *argReader) OptionalID(name string) *int64 {
+    value := a.OptionalInt(name)
+    if value == nil {
+        return nil
+    }
+    if *value <= 0 {
+        a.fail(name, "must be a positive ID")
+        return nil
+    }
+    id := int64(*value)
+    return &id
+}
+
+// String returns a required String argument, which must not be blank
+func (a *argReader) String(name string) string {
+    value, ok := a.args[name].(string)
+    if !ok {
+        a.required(name, "a string")
+        return ""
+    }
+    if strings.TrimSpace(value) == "" {
+        a.fail(name, "must not be blank")
+    }
+    return value
+}
+
+// OptionalString returns a String argument, "" when it wasn't given
+func (a *argReader) OptionalString(name string) string {
+    if !a.present(name) {
+        return ""
+    }
+    value, ok := a.args[name].(string)
+    if !ok {
+        a.fail(name, "must be a string")
+    }
+    return value
+}
+
+// Float returns a required Float argument
+func (a *argReader) Float(name string) float64 {
+    value, ok := a.args[name].(float64)
+    if !ok {
+        a.required(name, "a number")
+    }
+    return value
+}
+
+// OptionalFloat returns a Float argument, nil when it wasn't given
+func (a *argReader) OptionalFloat(name string) *float64 {
+    if !a.present(name) {
+        return nil
+    }
+    value, ok := a.args[name].(float64)
+    if !ok {
+        a.fail(name, "must be a number")
+        return nil
+    }
+    return &value
+}
+
+// required records a missing or mistyped required argument
+func (a *argReader) required(name, kind string) {
+    if !a.present(name) {
+        a.fail(name, "is required")
+        return
+    }
+    a.fail(name, "must be "+kind)
+}
+
+// Price checks a price is greater than zero
+func (a *argReader) Price(name string, price float64) {
+    a.Check(price > 0, name, "must be greater than zero")
+}
+
+// Quantity checks a quantity is between 1 and maxQuantity
+func (a *argReader) Quantity(name string, quantity int) {
+    a.Check(quantity >= 1 && quantity <= maxQuantity, name, fmt.Sprintf("must be between 1 and %d", maxQuantity))
+}
+
+// NonNegative checks a count such as stock isn't negative
+func (a *argReader) NonNegative(name string, value int) {
+    a.Check(value >= 0, name, "must not be negative")
+}
+
+// MinLength checks a string has at least min characters
+func (a *argReader) MinLength(name, value string, min int) {
+    if strings.TrimSpace(value) == "" {
+        return // reported as blank by String
+    }
+    a.Check(len([]rune(value)) >= min, name, fmt.Sprintf("must be at least %d characters", min))
+}
+
+// MaxLength checks a string is at most max characters
+func (a *argReader) MaxLength(name, value string, max int) {
+    a.Check(len([]rune(value)) <= max, name, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Email checks an email address is a plain address (no display name)
+func (a *argReader) Email(name, value string) {
+    if strings.TrimSpace(value) == "" {
+        return // reported as blank by String
+    }
+    address, err := mail.ParseAddress(value)
+    a.Check(err == nil && address.Address == value, name, "must be a valid email address")
+}
+
+// Err returns a VALIDATION_ERROR listing every problem found, or nil
+func (a *argReader) Err() error {
+    if len(a.fields) == 0 {
+        return nil
+    }
+    return invalidArguments(a.fields)
+}
+
+// invalidArguments builds the VALIDATION_ERROR for fields; the message names the first one
+func invalidArguments(fields []FieldError) *GatewayError {
+    message := fmt.Sprintf("%s %s", fields[0].Field, fields[0].Message)
+    if len(fields) > 1 {
+        message += fmt.Sprintf(" (and %d more)", len(fields)-1)
+    }
+    return &GatewayError{Code: CodeValidation, Message: message, Fields: fields}
+}
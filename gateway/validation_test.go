@@ -0,0 +1,128 @@
+package main
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/graphql-go/graphql"
+)
+
+func TestArgReaderCollectsEveryBadField(t *testing.T) {
+    args := readArgs(graphql.ResolveParams{Args: map[string]interface{}{
+        "name":           "  ",
+        "price":          0.0,
+        "stock_quantity": -1,
+    }})
+
+    args.String("name")
+    args.Price("price", args.Float("price"))
+    if stock := args.OptionalInt("stock_quantity"); stock != nil {
+        args.NonNegative("stock_quantity", *stock)
+    }
+    args.ID("id")
+    if sku := args.OptionalString("sku"); sku != "" {
+        t.Errorf("sku = %q, want empty when absent", sku)
+    }
+
+    var ge *GatewayError
+    if err := args.Err(); !errors.As(err, &ge) {
+        t.Fatalf("Err() = %v, want a GatewayError", err)
+    }
+    if ge.Code != CodeValidation {
+        t.Errorf("code = %s, want %s", ge.Code, CodeValidation)
+    }
+
+    want := []FieldError{
+        {Field: "name", Message: "must not be blank"},
+        {Field: "price", Message: "must be greater than zero"},
+        {Field: "stock_quantity", Message: "must not be negative"},
+        {Field: "id", Message: "is required"},
+    }
+    if len(ge.Fields) != len(want) {
+        t.Fatalf("fields = %v, want %v", ge.Fields, want)
+    }
+    for i := range want {
+        if ge.Fields[i] != want[i] {
+            t.Errorf("fields[%d] = %v, want %v", i, ge.Fields[i], want[i])
+        }
+    }
+    if ge.Message != "name must not be blank (and 3 more)" {
+        t.Errorf("message = %q", ge.Message)
+    }
+    if fields, ok := ge.Extensions()["fields"].([]FieldError); !ok || len(fields) != 4 {
+        t.Errorf("extensions = %v, want the fields listed", ge.Extensions())
+    }
+}
+
+func TestArgReaderRejectsMistypedArguments(t *testing.T) {
+    // Values that reach a resolver without going through the schema, e.g. from a test or a
+    // resolver reused for another field, must not panic
+    args := readArgs(graphql.ResolveParams{Args: map[string]interface{}{
+        "id":       "7",
+        "quantity": 2.5,
+        "email":    42,
+    }})
+
+    args.ID("id")
+    args.Int("quantity")
+    args.String("email")
+
+    err := args.Err()
+    var ge *GatewayError
+    if !errors.As(err, &ge) || len(ge.Fields) != 3 {
+        t.Fatalf("Err() = %v, want 3 field errors", err)
+    }
+    if ge.Fields[0].Message != "must be an ID" {
+        t.Errorf("id: %q", ge.Fields[0].Message)
+    }
+}
+
+func TestArgReaderValidArguments(t *testing.T) {
+    args := readArgs(graphql.ResolveParams{Args: map[string]interface{}{
+        "id":         3,
+        "quantity":   maxQuantity,
+        "email":      "ada@example.com",
+        "password":   "secret",
+        "variant_id": nil,
+    }})
+
+    if id := args.ID("id"); id != 3 {
+        t.Errorf("id = %d", id)
+    }
+    args.Quantity("quantity", args.Int("quantity"))
+    args.Email("email", args.String("email"))
+    args.MinLength("password", args.String("password"), minPasswordLength)
+    if variant := args.OptionalID("variant_id"); variant != nil {
+        t.Errorf("variant_id = %d, want nil for null", *variant)
+    }
+
+    if err := args.Err(); err != nil {
+        t.Fatalf("Err() = %v, want nil", err)
+    }
+}
+
+func TestArgReaderEmailAndBounds(t *testing.T) {
+    cases := []struct {
+        name  string
+        check func(a *argReader)
+        valid bool
+    }{
+        {"plain address", func(a *argReader) { a.Email("email", "ada@example.com") }, true},
+        {"no at sign", func(a *argReader) { a.Email("email", "ada.example.com") }, false},
+        {"display name", func(a *argReader) { a.Email("email", "Ada <ada@example.com>") }, false},
+        {"short password", func(a *argReader) { a.MinLength("password", "abc", minPasswordLength) }, false},
+        {"quantity zero", func(a *argReader) { a.Quantity("quantity", 0) }, false},
+        {"quantity over max", func(a *argReader) { a.Quantity("quantity", maxQuantity+1) }, false},
+        {"long name", func(a *argReader) { a.MaxLength("name", string(make([]rune, maxNameLength+1)), maxNameLength) }, false},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            args := readArgs(graphql.ResolveParams{})
+            tc.check(args)
+            if valid := args.Err() == nil; valid != tc.valid {
+                t.Errorf("valid = %v, want %v (%v)", valid, tc.valid, args.fields)
+            }
+        })
+    }
+}
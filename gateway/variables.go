@@ -0,0 +1,243 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "sort"
+    "strings"
+
+    "github.com/graphql-go/graphql"
+    "github.com/graphql-go/graphql/gqlerrors"
+    "github.com/graphql-go/graphql/language/ast"
+    "github.com/graphql-go/graphql/language/printer"
+)
+
+// Variable validation
+// Why: graphql-go reports a wrongly typed variable as one line like
+// `Variable "$input" got invalid value {...}. In field "price": Expected type "Float", found "abc".`
+// and stops at the first variable. Variables are now checked against the operation's definitions
+// before execution, with one error per bad value naming the variable, the path inside it and the
+// expected type. The accepted values are exactly graphql-go's (scalars use their ParseValue), so
+// only the messages change.
+
+// validateVariables checks variables against the variable definitions of the operation that will run.
+// Documents with several operations are left to graphql-go, which needs an operation name for them.
+func validateVariables(schema *graphql.Schema, doc *ast.Document, variables map[string]interface{}) []gqlerrors.FormattedError {
+    var op *ast.OperationDefinition
+    for _, def := range doc.Definitions {
+        if candidate, ok := def.(*ast.OperationDefinition); ok {
+            if op != nil {
+                return nil
+            }
+            op = candidate
+        }
+    }
+    if op == nil {
+        return nil
+    }
+
+    var errs []gqlerrors.FormattedError
+    for _, def := range op.VariableDefinitions {
+        if def == nil || def.Variable == nil || def.Variable.Name == nil {
+            continue
+        }
+        inputType, ok := inputTypeFromAST(schema, def.Type)
+        if !ok {
+            continue // unknown types are reported by document validation
+        }
+
+        name := def.Variable.Name.Value
+        value, provided := variables[name]
+        if !provided || value == nil {
+            if _, nonNull := inputType.(*graphql.NonNull); !nonNull || def.DefaultValue != nil {
+                continue
+            }
+            declared := fmt.Sprint(printer.Print(def.Type))
+            message := fmt.Sprintf("Variable \"$%s\" of type %s is required but was not provided", name, declared)
+            if provided {
+                message = fmt.Sprintf("Variable \"$%s\" of type %s must not be null", name, declared)
+            }
+            errs = append(errs, variableError(message, name, name, declared))
+            continue
+        }
+
+        v := variableValidator{name: name}
+        v.check(value, inputType, name)
+        errs = append(errs, v.errs...)
+    }
+
+    return errs
+}
+
+// variableValidator collects the errors of one variable
+type variableValidator struct {
+    name string
+    errs []gqlerrors.FormattedError
+}
+
+// check mirrors graphql-go's isValidInputValue, recording an error per invalid value
+func (v *variableValidator) check(value interface{}, inputType graphql.Input, path string) {
+    if value == nil {
+        if nonNull, ok := inputType.(*graphql.NonNull); ok {
+            v.fail(path, nonNull.String(), "must not be null")
+        }
+        return
+    }
+
+    switch t := inputType.(type) {
+    case *graphql.NonNull:
+        v.check(value, t.OfType, path)
+
+    case *graphql.List:
+        // A single value is accepted for a list and treated as a list of one
+        list := reflect.ValueOf(value)
+        if list.Kind() != reflect.Slice {
+            v.check(value, t.OfType, path)
+            return
+        }
+        for i := 0; i < list.Len(); i++ {
+            v.check(list.Index(i).Interface(), t.OfType, fmt.Sprintf("%s[%d]", path, i))
+        }
+
+    case *graphql.InputObject:
+        fields, ok := value.(map[string]interface{})
+        if !ok {
+            v.fail(path, t.Name(), "expected an object of type %s, got %s", t.Name(), describeValue(value))
+            return
+        }
+
+        provided := make([]string, 0, len(fields))
+        for name := range fields {
+            provided = append(provided, name)
+        }
+        sort.Strings(provided)
+        for _, name := range provided {
+            if _, ok := t.Fields()[name]; !ok {
+                v.fail(path+"."+name, "", "unknown field %q on %s", name, t.Name())
+            }
+        }
+
+        defined := make([]string, 0, len(t.Fields()))
+        for name := range t.Fields() {
+            defined = append(defined, name)
+        }
+        sort.Strings(defined)
+        for _, name := range defined {
+            field := t.Fields()[name]
+            fieldValue, ok := fields[name]
+            if _, nonNull := field.Type.(*graphql.NonNull); nonNull && (!ok || fieldValue == nil) {
+                if !ok {
+                    v.fail(path+"."+name, field.Type.String(), "missing required field %q of type %s", name, field.Type.String())
+                } else {
+                    v.fail(path+"."+name, field.Type.String(), "must not be null")
+                }
+                continue
+            }
+            v.check(fieldValue, field.Type, path+"."+name)
+        }
+
+    case *graphql.Enum:
+        if t.ParseValue(value) == nil {
+            values := make([]string, 0, len(t.Values()))
+            for _, enumValue := range t.Values() {
+                values = append(values, enumValue.Name)
+            }
+            sort.Strings(values)
+            v.fail(path, t.Name(), "expected one of %s, got %s", strings.Join(values, ", "), describeValue(value))
+        }
+
+    case *graphql.Scalar:
+        if isNullishValue(t.ParseValue(value)) {
+            v.fail(path, t.Name(), "expected %s, got %s", t.Name(), describeValue(value))
+        }
+    }
+}
+
+// fail records an error at path; the message is prefixed with the variable and path
+func (v *variableValidator) fail(path, expected, format string, args ...interface{}) {
+    detail := fmt.Sprintf(format, args...)
+    message := fmt.Sprintf("Variable \"$%s\" %s", v.name, detail)
+    if path != v.name {
+        message = fmt.Sprintf("Variable \"$%s\" has an invalid value at %s: %s", v.name, path, detail)
+    }
+    v.errs = append(v.errs, variableError(message, v.name, path, expected))
+}
+
+// variableError builds the error with the variable name and path in extensions
+func variableError(message, variable, path, expected string) gqlerrors.FormattedError {
+    extensions := map[string]interface{}{
+        "code":     CodeValidation,
+        "variable": variable,
+        "path":     path,
+    }
+    if expected != "" {
+        extensions["expected"] = expected
+    }
+    return gqlerrors.FormattedError{Message: message, Extensions: extensions}
+}
+
+// inputTypeFromAST resolves a variable's declared type against the schema
+func inputTypeFromAST(schema *graphql.Schema, typeAST ast.Type) (graphql.Input, bool) {
+    switch t := typeAST.(type) {
+    case *ast.NonNull:
+        inner, ok := inputTypeFromAST(schema, t.Type)
+        if !ok {
+            return nil, false
+        }
+        return graphql.NewNonNull(inner), true
+    case *ast.List:
+        inner, ok := inputTypeFromAST(schema, t.Type)
+        if !ok {
+            return nil, false
+        }
+        return graphql.NewList(inner), true
+    case *ast.Named:
+        if t.Name == nil {
+            return nil, false
+        }
+        inputType, ok := schema.Type(t.Name.Value).(graphql.Input)
+        if !ok || inputType == nil || !graphql.IsInputType(inputType) {
+            return nil, false
+        }
+        return inputType, true
+    }
+    return nil, false
+}
+
+// describeValue names the JSON kind of a value and shows it, e.g. `string "abc"`
+func describeValue(value interface{}) string {
+    shown, _ := json.Marshal(value)
+    if len(shown) > 40 {
+        shown = append(shown[:37], "..."...)
+    }
+
+    switch value.(type) {
+    case string:
+        return "string " + string(shown)
+    case bool:
+        return "boolean " + string(shown)
+    case float64, float32, int, int64, json.Number:
+        return "number " + string(shown)
+    case map[string]interface{}:
+        return "an object"
+    case []interface{}:
+        return "a list"
+    }
+    return string(shown)
+}
+
+// isNullishValue matches graphql-go's check on parsed scalar values (nil or NaN)
+func isNullishValue(value interface{}) bool {
+    if value == nil {
+        return true
+    }
+    rv := reflect.ValueOf(value)
+    if rv.Kind() == reflect.Ptr {
+        return rv.IsNil()
+    }
+    if rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
+        return rv.Float() != rv.Float()
+    }
+    return false
+}
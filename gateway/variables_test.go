@@ -0,0 +1,140 @@
+package main
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/graphql-go/graphql"
+    "github.com/graphql-go/graphql/language/parser"
+)
+
+func variablesTestSchema(t *testing.T) *graphql.Schema {
+    t.Helper()
+
+    sortEnum := graphql.NewEnum(graphql.EnumConfig{
+        Name: "Sort",
+        Values: graphql.EnumValueConfigMap{
+            "NEWEST": &graphql.EnumValueConfig{Value: "newest"},
+            "PRICE":  &graphql.EnumValueConfig{Value: "price"},
+        },
+    })
+    productInput := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name: "ProductInput",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "name":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+            "price": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+        },
+    })
+
+    schema, err := graphql.NewSchema(graphql.SchemaConfig{
+        Query: graphql.NewObject(graphql.ObjectConfig{
+            Name: "Query",
+            Fields: graphql.Fields{
+                "search": &graphql.Field{
+                    Type: graphql.String,
+                    Args: graphql.FieldConfigArgument{
+                        "ids":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+                        "sort":    &graphql.ArgumentConfig{Type: sortEnum},
+                        "product": &graphql.ArgumentConfig{Type: productInput},
+                        "limit":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+                    },
+                    Resolve: func(p graphql.ResolveParams) (interface{}, error) { return "ok", nil },
+                },
+            },
+        }),
+    })
+    if err != nil {
+        t.Fatalf("schema: %v", err)
+    }
+    return &schema
+}
+
+const variablesTestQuery = `query Search($ids: [Int!], $sort: Sort, $product: ProductInput, $limit: Int!) {
+    search(ids: $ids, sort: $sort, product: $product, limit: $limit)
+}`
+
+func checkTestVariables(t *testing.T, variables map[string]interface{}) []map[string]interface{} {
+    t.Helper()
+
+    doc, err := parser.Parse(parser.ParseParams{Source: variablesTestQuery})
+    if err != nil {
+        t.Fatalf("parse: %v", err)
+    }
+
+    var errs []map[string]interface{}
+    for _, err := range validateVariables(variablesTestSchema(t), doc, variables) {
+        errs = append(errs, map[string]interface{}{"message": err.Message, "extensions": err.Extensions})
+    }
+    return errs
+}
+
+func TestValidateVariables_CommonMistakes(t *testing.T) {
+    cases := []struct {
+        name      string
+        variables map[string]interface{}
+        variable  string
+        path      string
+        message   string
+    }{
+        {"string for Int", map[string]interface{}{"limit": "ten"}, "limit", "limit", `Variable "$limit" expected Int, got string "ten"`},
+        {"missing required", map[string]interface{}{}, "limit", "limit", `Variable "$limit" of type Int! is required but was not provided`},
+        {"null for non-null", map[string]interface{}{"limit": nil}, "limit", "limit", `Variable "$limit" of type Int! must not be null`},
+        {"bad list element", map[string]interface{}{"limit": 1.0, "ids": []interface{}{1.0, "two"}}, "ids", "ids[1]", `has an invalid value at ids[1]: expected Int, got string "two"`},
+        {"null list element", map[string]interface{}{"limit": 1.0, "ids": []interface{}{nil}}, "ids", "ids[0]", `at ids[0]: must not be null`},
+        {"unknown enum value", map[string]interface{}{"limit": 1.0, "sort": "CHEAPEST"}, "sort", "sort", `expected one of NEWEST, PRICE, got string "CHEAPEST"`},
+        {"object as scalar", map[string]interface{}{"limit": map[string]interface{}{"value": 1.0}}, "limit", "limit", `expected Int, got an object`},
+        {"unknown input field", map[string]interface{}{"limit": 1.0, "product": map[string]interface{}{"name": "Tea", "colour": "red"}}, "product", "product.colour", `unknown field "colour" on ProductInput`},
+        {"missing input field", map[string]interface{}{"limit": 1.0, "product": map[string]interface{}{"price": 1.5}}, "product", "product.name", `missing required field "name" of type String!`},
+        {"wrong input field type", map[string]interface{}{"limit": 1.0, "product": map[string]interface{}{"name": "Tea", "price": "cheap"}}, "product", "product.price", `at product.price: expected Float, got string "cheap"`},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            errs := checkTestVariables(t, tc.variables)
+            if len(errs) != 1 {
+                t.Fatalf("expected 1 error, got %v", errs)
+            }
+
+            extensions := errs[0]["extensions"].(map[string]interface{})
+            if extensions["code"] != CodeValidation || extensions["variable"] != tc.variable || extensions["path"] != tc.path {
+                t.Errorf("extensions = %v, want variable %q at %q", extensions, tc.variable, tc.path)
+            }
+            if message := errs[0]["message"].(string); !strings.Contains(message, tc.message) {
+                t.Errorf("message = %q, want it to contain %q", message, tc.message)
+            }
+        })
+    }
+}
+
+func TestValidateVariables_AcceptsWhatGraphQLGoAccepts(t *testing.T) {
+    variables := map[string]interface{}{
+        "limit":   10.0,
+        "ids":     2.0, // a single value for a list is a list of one
+        "sort":    "PRICE",
+        "product": map[string]interface{}{"name": "Tea"},
+    }
+
+    if errs := checkTestVariables(t, variables); len(errs) != 0 {
+        t.Fatalf("expected no errors, got %v", errs)
+    }
+}
+
+func TestValidateVariables_ReportsEveryBadVariable(t *testing.T) {
+    errs := checkTestVariables(t, map[string]interface{}{"limit": "ten", "sort": 3.0})
+    if len(errs) != 2 {
+        t.Fatalf("expected errors for $limit and $sort, got %v", errs)
+    }
+}
+
+func TestExecuteQuery_RejectsBadVariablesBeforeResolvers(t *testing.T) {
+    result := ExecuteQuery(variablesTestQuery, map[string]interface{}{"limit": "ten"}, variablesTestSchema(t), QueryLimitsConfig{}, context.Background())
+
+    if result.Data != nil || len(result.Errors) != 1 {
+        t.Fatalf("expected one error and no data, got %+v", result)
+    }
+    formatted := FormatResult(result)["errors"].([]map[string]interface{})[0]
+    if formatted["extensions"].(map[string]interface{})["variable"] != "limit" {
+        t.Errorf("expected the variable name in extensions, got %v", formatted)
+    }
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Check is one cross-schema assertion. Its query selects the rows that break it, as
+// (subject, detail) pairs; $1 is the grace cutoff, rows changed after it are left out.
+// Why: staging keeps taking traffic while the checker runs, and a saga that is still moving
+// looks exactly like a broken one for a few seconds.
+type Check struct {
+    Name        string
+    Description string
+    Query       string
+}
+
+// Checks are the assertions run by default, in report order
+var Checks = []Check{
+    {
+        Name:        "orders-have-items",
+        Description: "every order has at least one item",
+        Query: `
+            SELECT 'order ' || o.id, 'status ' || o.status || ', no items'
+            FROM orders.orders o
+            WHERE o.created_at < $1
+              AND NOT EXISTS (SELECT 1 FROM orders.order_items i WHERE i.order_id = o.id)`,
+    },
+    {
+        // Cart soft locks and channel reservations are stored with order_id 0
+        Name:        "reservations-have-live-orders",
+        Description: "every held order reservation belongs to a pending, placed or payment_pending order",
+        Query: `
+            SELECT 'reservation ' || r.reservation_id,
+                   'order ' || r.order_id || ' ' || COALESCE(o.status, 'missing') ||
+                   ', ' || r.quantity || ' units of product ' || r.product_id
+            FROM catalog.inventory_reservations r
+            LEFT JOIN orders.orders o ON o.id = r.order_id
+            WHERE r.status = 'reserved' AND r.order_id <> 0 AND r.updated_at < $1
+              AND (o.id IS NULL OR o.status NOT IN ('pending', 'placed', 'payment_pending'))`,
+    },
+    {
+        Name:        "cart-totals-match-items",
+        Description: "every cart's total is the sum of its items less its coupon discount",
+        Query: `
+            SELECT 'cart ' || c.id,
+                   'total ' || c.total || ', items ' || COALESCE(SUM(i.price * i.quantity), 0) ||
+                   ', discount ' || c.discount
+            FROM cart.carts c
+            LEFT JOIN cart.cart_items i ON i.cart_id = c.id
+            WHERE c.status <> 'abandoned' AND c.updated_at < $1
+            GROUP BY c.id
+            HAVING c.total <> COALESCE(SUM(i.price * i.quantity), 0) - c.discount`,
+    },
+    {
+        // A completed saga's orders are all confirmed or further along. A failed or cancelled
+        // saga has an order with that status, and none still waiting for stock.
+        Name:        "terminal-sagas-have-terminal-orders",
+        Description: "completed, failed and cancelled sagas have orders that match",
+        Query: `
+            SELECT 'saga ' || s.correlation_id, s.status || ' saga, order ' || o.id || ' ' || o.status
+            FROM orders.saga_states s
+            JOIN orders.orders o ON o.saga_correlation_id = s.correlation_id
+            WHERE s.updated_at < $1
+              AND ((s.status = 'completed' AND o.status NOT IN ('confirmed', 'shipped', 'delivered', 'cancelled'))
+                OR (s.status IN ('failed', 'cancelled') AND o.status = 'pending'))
+            UNION ALL
+            SELECT 'saga ' || s.correlation_id, s.status || ' saga, no ' || s.status || ' order'
+            FROM orders.saga_states s
+            WHERE s.updated_at < $1 AND s.status IN ('failed', 'cancelled')
+              AND EXISTS (SELECT 1 FROM orders.orders o WHERE o.saga_correlation_id = s.correlation_id)
+              AND NOT EXISTS (
+                  SELECT 1 FROM orders.orders o
+                  WHERE o.saga_correlation_id = s.correlation_id AND o.status = s.status
+              )`,
+    },
+}
+
+// selectChecks filters checks by the -check flag
+func selectChecks(checks []Check, only string) ([]Check, error) {
+    if only == "" {
+        return checks, nil
+    }
+
+    byName := make(map[string]Check, len(checks))
+    for _, check := range checks {
+        byName[check.Name] = check
+    }
+
+    selected := []Check{}
+    for _, name := range strings.Split(only, ",") {
+        check, ok := byName[strings.TrimSpace(name)]
+        if !ok {
+            return nil, fmt.Errorf("unknown check %q", name)
+        }
+        selected = append(selected, check)
+    }
+    return selected, nil
+}
+
+// Violation is one row breaking a check
+type Violation struct {
+    Subject string `json:"subject"`
+    Detail  string `json:"detail"`
+}
+
+// Result is the outcome of one check. Count is every violation; Samples holds at most the
+// -samples first ones by subject.
+type Result struct {
+    Check       string      `json:"check"`
+    Description string      `json:"description"`
+    Count       int         `json:"count"`
+    Samples     []Violation `json:"samples"`
+    Error       string      `json:"error,omitempty"`
+}
+
+// Run runs a check, counting every violation but reading only the first samples
+func (c Check) Run(ctx context.Context, db *sql.DB, cutoff time.Time, samples int) Result {
+    result := Result{Check: c.Name, Description: c.Description, Samples: []Violation{}}
+
+    query := `SELECT subject, detail, COUNT(*) OVER () FROM (` + c.Query + `) v(subject, detail) ORDER BY subject, detail LIMIT $2`
+    rows, err := db.QueryContext(ctx, query, cutoff, samples)
+    if err != nil {
+        result.Error = err.Error()
+        return result
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var violation Violation
+        if err := rows.Scan(&violation.Subject, &violation.Detail, &result.Count); err != nil {
+            result.Error = err.Error()
+            return result
+        }
+        result.Samples = append(result.Samples, violation)
+    }
+    if err := rows.Err(); err != nil {
+        result.Error = err.Error()
+    }
+    return result
+}
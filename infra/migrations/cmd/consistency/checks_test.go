@@ -0,0 +1,81 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "strings"
+    "testing"
+)
+
+func TestChecksAreWellFormed(t *testing.T) {
+    seen := make(map[string]bool)
+    for _, check := range Checks {
+        if seen[check.Name] {
+            t.Errorf("duplicate check %q", check.Name)
+        }
+        seen[check.Name] = true
+        if check.Description == "" {
+            t.Errorf("%s has no description", check.Name)
+        }
+        if !strings.Contains(check.Query, "$1") {
+            t.Errorf("%s ignores the grace cutoff", check.Name)
+        }
+        if strings.Contains(check.Query, "$2") {
+            t.Errorf("%s uses $2, which is the sample limit", check.Name)
+        }
+    }
+}
+
+func TestSelectChecks(t *testing.T) {
+    checks, err := selectChecks(Checks, "cart-totals-match-items, orders-have-items")
+    if err != nil {
+        t.Fatalf("selectChecks: %v", err)
+    }
+    if len(checks) != 2 || checks[0].Name != "cart-totals-match-items" || checks[1].Name != "orders-have-items" {
+        t.Errorf("got %v", checks)
+    }
+
+    if all, _ := selectChecks(Checks, ""); len(all) != len(Checks) {
+        t.Errorf("empty -check selected %d checks, want all %d", len(all), len(Checks))
+    }
+    if _, err := selectChecks(Checks, "orders-have-itemz"); err == nil {
+        t.Error("expected an unknown check to be rejected")
+    }
+}
+
+func TestReport(t *testing.T) {
+    report := &Report{Results: []Result{
+        {Check: "orders-have-items", Samples: []Violation{}},
+        {Check: "cart-totals-match-items", Description: "totals match", Count: 3, Samples: []Violation{
+            {Subject: "cart a", Detail: "total 10.00, items 12.00, discount 0.00"},
+        }},
+    }}
+
+    if code := report.ExitCode(); code != 1 {
+        t.Errorf("ExitCode = %d, want 1 on violations", code)
+    }
+
+    var text bytes.Buffer
+    if err := report.Write(&text, FormatText); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+    for _, want := range []string{"✓ orders-have-items", "✗ cart-totals-match-items: 3 violation(s)", "  - cart a: total 10.00", "... and 2 more", "2 check(s), 3 violation(s)"} {
+        if !strings.Contains(text.String(), want) {
+            t.Errorf("text report misses %q:\n%s", want, text.String())
+        }
+    }
+
+    var decoded Report
+    var out bytes.Buffer
+    if err := report.Write(&out, FormatJSON); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+    if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Results) != 2 || decoded.Results[1].Count != 3 {
+        t.Errorf("json report = %s (%v)", out.String(), err)
+    }
+
+    report.Results[0].Error = "relation does not exist"
+    if code := report.ExitCode(); code != 2 {
+        t.Errorf("ExitCode = %d, want 2 when a check failed to run", code)
+    }
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "time"
+)
+
+// Report formats
+const (
+    FormatText = "text"
+    FormatJSON = "json"
+)
+
+// Report is the outcome of a run
+type Report struct {
+    Cutoff  time.Time `json:"cutoff"`
+    Results []Result  `json:"results"`
+}
+
+// Violations counts the violations of every check
+func (r *Report) Violations() int {
+    total := 0
+    for _, result := range r.Results {
+        total += result.Count
+    }
+    return total
+}
+
+// Failed reports whether a check couldn't run
+func (r *Report) Failed() bool {
+    for _, result := range r.Results {
+        if result.Error != "" {
+            return true
+        }
+    }
+    return false
+}
+
+// ExitCode is 0 when every check passed, 1 on violations and 2 when a check couldn't run.
+// Why: CI must not read a check that never ran as a clean one.
+func (r *Report) ExitCode() int {
+    switch {
+    case r.Failed():
+        return 2
+    case r.Violations() > 0:
+        return 1
+    }
+    return 0
+}
+
+// Write prints the report as text or JSON
+func (r *Report) Write(w io.Writer, format string) error {
+    if format == FormatJSON {
+        encoder := json.NewEncoder(w)
+        encoder.SetIndent("", "  ")
+        return encoder.Encode(r)
+    }
+
+    for _, result := range r.Results {
+        switch {
+        case result.Error != "":
+            fmt.Fprintf(w, "❌ %s: %s\n", result.Check, result.Error)
+        case result.Count == 0:
+            fmt.Fprintf(w, "✓ %s\n", result.Check)
+        default:
+            fmt.Fprintf(w, "✗ %s: %d violation(s), %s\n", result.Check, result.Count, result.Description)
+            for _, violation := range result.Samples {
+                fmt.Fprintf(w, "  - %s: %s\n", violation.Subject, violation.Detail)
+            }
+            if more := result.Count - len(result.Samples); more > 0 {
+                fmt.Fprintf(w, "  ... and %d more\n", more)
+            }
+        }
+    }
+    fmt.Fprintf(w, "\n%d check(s), %d violation(s)\n", len(r.Results), r.Violations())
+    return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+)
+
+// Checkpoint records how far a job has copied
+type Checkpoint struct {
+    JobName     string
+    LastKey     *string // key of the last copied row; nil before the first batch
+    RowsCopied  int64
+    StartedAt   time.Time
+    UpdatedAt   time.Time
+    CompletedAt *time.Time
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// CheckpointStore persists checkpoints in datamigrate.checkpoints (migration 016)
+type CheckpointStore struct {
+    db *sql.DB
+}
+
+// NewCheckpointStore creates new checkpoint store
+func NewCheckpointStore(db *sql.DB) *CheckpointStore {
+    return &CheckpointStore{db: db}
+}
+
+// Load returns the job's checkpoint, creating an empty one on the first run
+func (cs *CheckpointStore) Load(ctx context.Context, jobName string) (*Checkpoint, error) {
+    _, err := cs.db.ExecContext(ctx, `
+        INSERT INTO datamigrate.checkpoints (job_name, rows_copied, started_at, updated_at)
+        VALUES ($1, 0, $2, $2)
+        ON CONFLICT (job_name) DO NOTHING
+    `, jobName, time.Now().UTC())
+    if err != nil {
+        return nil, fmt.Errorf("failed to create checkpoint: %w", err)
+    }
+
+    cp := &Checkpoint{}
+    err = cs.db.QueryRowContext(ctx, `
+        SELECT job_name, last_key, rows_copied, started_at, updated_at, completed_at
+        FROM datamigrate.checkpoints
+        WHERE job_name = $1
+    `, jobName).Scan(&cp.JobName, &cp.LastKey, &cp.RowsCopied, &cp.StartedAt, &cp.UpdatedAt, &cp.CompletedAt)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+    }
+
+    return cp, nil
+}
+
+// Save advances the checkpoint; pass the batch's transaction so rows and checkpoint commit together
+func (cs *CheckpointStore) Save(ctx context.Context, ex execer, cp *Checkpoint) error {
+    cp.UpdatedAt = time.Now().UTC()
+    _, err := ex.ExecContext(ctx, `
+        UPDATE datamigrate.checkpoints
+        SET last_key = $2, rows_copied = $3, updated_at = $4
+        WHERE job_name = $1
+    `, cp.JobName, cp.LastKey, cp.RowsCopied, cp.UpdatedAt)
+    if err != nil {
+        return fmt.Errorf("failed to save checkpoint: %w", err)
+    }
+    return nil
+}
+
+// Complete marks the job finished
+func (cs *CheckpointStore) Complete(ctx context.Context, cp *Checkpoint) error {
+    now := time.Now().UTC()
+    _, err := cs.db.ExecContext(ctx, `
+        UPDATE datamigrate.checkpoints
+        SET completed_at = $2, updated_at = $2
+        WHERE job_name = $1
+    `, cp.JobName, now)
+    if err != nil {
+        return fmt.Errorf("failed to complete checkpoint: %w", err)
+    }
+    cp.CompletedAt = &now
+    return nil
+}
+
+// Reset drops the job's checkpoint so the next run starts from the beginning
+func (cs *CheckpointStore) Reset(ctx context.Context, jobName string) error {
+    if _, err := cs.db.ExecContext(ctx, `DELETE FROM datamigrate.checkpoints WHERE job_name = $1`, jobName); err != nil {
+        return fmt.Errorf("failed to reset checkpoint: %w", err)
+    }
+    return nil
+}
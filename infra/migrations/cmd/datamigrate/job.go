@@ -0,0 +1,241 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+)
+
+// identPattern restricts schema, table and column names; they are spliced into SQL
+var identPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Conflict handling when a row with the same key already exists in the target
+const (
+    OnConflictSkip   = "skip"
+    OnConflictUpdate = "update"
+)
+
+// TableRef names a schema-qualified table
+type TableRef struct {
+    Schema string `json:"schema"`
+    Table  string `json:"table"`
+}
+
+func (t TableRef) String() string {
+    return t.Schema + "." + t.Table
+}
+
+// Column maps one target column to a source column or SQL expression
+// Why: transforms (casts, defaults, renamed columns) run in the source SELECT
+// so the tool doesn't need a Go-side expression language
+type Column struct {
+    Target string `json:"target"`
+    Source string `json:"source,omitempty"` // source column; defaults to Target
+    Expr   string `json:"expr,omitempty"`   // SQL expression over source columns; overrides Source
+}
+
+// Job copies rows from one table to another, batch by batch in key order
+type Job struct {
+    Name       string   `json:"name"`
+    Source     TableRef `json:"source"`
+    Target     TableRef `json:"target"`
+    Key        string   `json:"key"`             // unique, ordered column present in both tables
+    Columns    []Column `json:"columns"`         // must include Key
+    Where      string   `json:"where,omitempty"` // extra source filter
+    OnConflict string   `json:"on_conflict,omitempty"`
+}
+
+// JobFile is the JSON document passed with -jobs
+type JobFile struct {
+    Jobs []*Job `json:"jobs"`
+}
+
+// LoadJobs reads and validates a job file
+func LoadJobs(path string) ([]*Job, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read job file: %w", err)
+    }
+
+    var file JobFile
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse job file: %w", err)
+    }
+    if len(file.Jobs) == 0 {
+        return nil, fmt.Errorf("job file %s has no jobs", path)
+    }
+
+    seen := make(map[string]bool)
+    for _, job := range file.Jobs {
+        if err := job.Validate(); err != nil {
+            return nil, fmt.Errorf("job %q: %w", job.Name, err)
+        }
+        if seen[job.Name] {
+            return nil, fmt.Errorf("duplicate job name %q", job.Name)
+        }
+        seen[job.Name] = true
+    }
+
+    return file.Jobs, nil
+}
+
+// Validate checks the job and fills in defaults
+func (j *Job) Validate() error {
+    if j.Name == "" {
+        return fmt.Errorf("name is required")
+    }
+    for _, ident := range []string{j.Source.Schema, j.Source.Table, j.Target.Schema, j.Target.Table, j.Key} {
+        if !identPattern.MatchString(ident) {
+            return fmt.Errorf("invalid identifier %q", ident)
+        }
+    }
+    if j.Source == j.Target {
+        return fmt.Errorf("source and target are the same table")
+    }
+    if len(j.Columns) == 0 {
+        return fmt.Errorf("at least one column is required")
+    }
+
+    switch j.OnConflict {
+    case "":
+        j.OnConflict = OnConflictSkip
+    case OnConflictSkip, OnConflictUpdate:
+    default:
+        return fmt.Errorf("on_conflict must be %q or %q", OnConflictSkip, OnConflictUpdate)
+    }
+
+    hasKey := false
+    targets := make(map[string]bool)
+    for i := range j.Columns {
+        col := &j.Columns[i]
+        if !identPattern.MatchString(col.Target) {
+            return fmt.Errorf("invalid target column %q", col.Target)
+        }
+        if targets[col.Target] {
+            return fmt.Errorf("target column %q mapped twice", col.Target)
+        }
+        targets[col.Target] = true
+
+        if col.Expr == "" {
+            if col.Source == "" {
+                col.Source = col.Target
+            }
+            if !identPattern.MatchString(col.Source) {
+                return fmt.Errorf("invalid source column %q", col.Source)
+            }
+        }
+        if col.Target == j.Key {
+            hasKey = true
+        }
+    }
+    if !hasKey {
+        return fmt.Errorf("columns must include the key %q", j.Key)
+    }
+
+    return nil
+}
+
+// sourceExpr is the SELECT expression producing a target column
+func (c Column) sourceExpr() string {
+    if c.Expr != "" {
+        return "(" + c.Expr + ")"
+    }
+    return c.Source
+}
+
+// targetColumns lists target column names in job order
+func (j *Job) targetColumns() []string {
+    cols := make([]string, len(j.Columns))
+    for i, col := range j.Columns {
+        cols[i] = col.Target
+    }
+    return cols
+}
+
+// keyIndex is the position of the key in Columns
+func (j *Job) keyIndex() int {
+    for i, col := range j.Columns {
+        if col.Target == j.Key {
+            return i
+        }
+    }
+    return -1
+}
+
+// SelectBatchQuery reads the next batch from the source in key order.
+// With after, $1 is the last copied key and $2 the batch size; otherwise $1 is the batch size.
+// Why: the checkpoint key is passed as untyped text so Postgres reads it as the key's own
+// type (uuid, bigint, ...) and keeps the key's ordering
+func (j *Job) SelectBatchQuery(after bool) string {
+    exprs := make([]string, len(j.Columns))
+    for i, col := range j.Columns {
+        exprs[i] = col.sourceExpr() + " AS " + col.Target
+    }
+
+    // The key is compared on the source side, where the job's key column lives
+    sourceKey := j.Columns[j.keyIndex()].sourceExpr()
+
+    conditions := []string{}
+    limit := "$1"
+    if after {
+        conditions = append(conditions, sourceKey+" > $1")
+        limit = "$2"
+    }
+    if j.Where != "" {
+        conditions = append(conditions, "("+j.Where+")")
+    }
+
+    where := ""
+    if len(conditions) > 0 {
+        where = " WHERE " + strings.Join(conditions, " AND ")
+    }
+
+    return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %s",
+        strings.Join(exprs, ", "), j.Source, where, sourceKey, limit)
+}
+
+// InsertQuery writes rowCount rows to the target in one statement
+func (j *Job) InsertQuery(rowCount int) string {
+    cols := j.targetColumns()
+
+    var values strings.Builder
+    param := 1
+    for r := 0; r < rowCount; r++ {
+        if r > 0 {
+            values.WriteString(", ")
+        }
+        values.WriteString("(")
+        for c := range cols {
+            if c > 0 {
+                values.WriteString(", ")
+            }
+            fmt.Fprintf(&values, "$%d", param)
+            param++
+        }
+        values.WriteString(")")
+    }
+
+    conflict := fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", j.Key)
+    if j.OnConflict == OnConflictUpdate {
+        sets := []string{}
+        for _, col := range cols {
+            if col != j.Key {
+                sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+            }
+        }
+        if len(sets) > 0 {
+            conflict = fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", j.Key, strings.Join(sets, ", "))
+        }
+    }
+
+    return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s",
+        j.Target, strings.Join(cols, ", "), values.String(), conflict)
+}
+
+// SelectTargetQuery reads existing target rows for a batch's keys (dry-run diffing)
+func (j *Job) SelectTargetQuery() string {
+    return fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
+        strings.Join(j.targetColumns(), ", "), j.Target, j.Key)
+}
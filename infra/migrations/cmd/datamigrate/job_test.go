@@ -0,0 +1,113 @@
+package main
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+func testJob() *Job {
+    return &Job{
+        Name:   "locks",
+        Source: TableRef{Schema: "cart", Table: "inventory_locks"},
+        Target: TableRef{Schema: "inventory", Table: "inventory_locks"},
+        Key:    "id",
+        Columns: []Column{
+            {Target: "id"},
+            {Target: "qty", Source: "quantity"},
+            {Target: "origin", Expr: "'cart'"},
+        },
+    }
+}
+
+func TestJobValidate(t *testing.T) {
+    job := testJob()
+    if err := job.Validate(); err != nil {
+        t.Fatalf("Validate: %v", err)
+    }
+    if job.OnConflict != OnConflictSkip {
+        t.Errorf("OnConflict = %q, want default %q", job.OnConflict, OnConflictSkip)
+    }
+    if job.Columns[0].Source != "id" {
+        t.Errorf("Source defaults to Target, got %q", job.Columns[0].Source)
+    }
+
+    tests := map[string]func(j *Job){
+        "injected table":   func(j *Job) { j.Target.Table = "locks; DROP TABLE x" },
+        "missing key":      func(j *Job) { j.Columns = j.Columns[1:] },
+        "same table":       func(j *Job) { j.Target = j.Source },
+        "duplicate target": func(j *Job) { j.Columns = append(j.Columns, Column{Target: "qty"}) },
+        "bad on_conflict":  func(j *Job) { j.OnConflict = "replace" },
+    }
+    for name, mutate := range tests {
+        job := testJob()
+        mutate(job)
+        if err := job.Validate(); err == nil {
+            t.Errorf("%s: expected validation error", name)
+        }
+    }
+}
+
+func TestSelectBatchQuery(t *testing.T) {
+    job := testJob()
+    job.Where = "status = 'locked'"
+    if err := job.Validate(); err != nil {
+        t.Fatal(err)
+    }
+
+    first := job.SelectBatchQuery(false)
+    want := "SELECT id AS id, quantity AS qty, ('cart') AS origin FROM cart.inventory_locks WHERE (status = 'locked') ORDER BY id LIMIT $1"
+    if first != want {
+        t.Errorf("first batch query:\n got %s\nwant %s", first, want)
+    }
+
+    next := job.SelectBatchQuery(true)
+    if !strings.Contains(next, "WHERE id > $1 AND (status = 'locked')") || !strings.HasSuffix(next, "LIMIT $2") {
+        t.Errorf("resume query missing key condition: %s", next)
+    }
+}
+
+func TestInsertQuery(t *testing.T) {
+    job := testJob()
+    if err := job.Validate(); err != nil {
+        t.Fatal(err)
+    }
+
+    skip := job.InsertQuery(2)
+    want := "INSERT INTO inventory.inventory_locks (id, qty, origin) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (id) DO NOTHING"
+    if skip != want {
+        t.Errorf("insert query:\n got %s\nwant %s", skip, want)
+    }
+
+    job.OnConflict = OnConflictUpdate
+    update := job.InsertQuery(1)
+    if !strings.HasSuffix(update, "ON CONFLICT (id) DO UPDATE SET qty = EXCLUDED.qty, origin = EXCLUDED.origin") {
+        t.Errorf("update conflict clause wrong: %s", update)
+    }
+}
+
+func TestThrottleWait(t *testing.T) {
+    now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    var slept time.Duration
+
+    throttle := NewThrottle(100)
+    throttle.now = func() time.Time { return now }
+    throttle.sleep = func(d time.Duration) {
+        slept += d
+        now = now.Add(d)
+    }
+
+    // 50 rows at 100 rows/s should take half a second
+    throttle.Wait(50)
+    if slept != 500*time.Millisecond {
+        t.Errorf("slept %s, want 500ms", slept)
+    }
+
+    // The next batch took longer than its share; no extra sleep
+    now = now.Add(2 * time.Second)
+    slept = 0
+    throttle.Wait(50)
+    if slept != 0 {
+        t.Errorf("slept %s after a slow batch, want 0", slept)
+    }
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// maxParams is Postgres' limit on bind parameters in one statement
+const maxParams = 65535
+
+// Options control how jobs run
+type Options struct {
+    BatchSize     int
+    DiffSamples   int // differing rows printed per job in dry-run mode
+    RowsPerSecond float64
+}
+
+// Runner executes copy jobs
+type Runner struct {
+    db          *sql.DB
+    checkpoints *CheckpointStore
+    options     Options
+}
+
+// NewRunner creates new job runner
+func NewRunner(db *sql.DB, options Options) *Runner {
+    return &Runner{
+        db:          db,
+        checkpoints: NewCheckpointStore(db),
+        options:     options,
+    }
+}
+
+// batchSize keeps one multi-row INSERT under the bind parameter limit
+func (r *Runner) batchSize(job *Job) int {
+    size := r.options.BatchSize
+    if max := maxParams / len(job.Columns); size > max {
+        size = max
+    }
+    return size
+}
+
+// Run copies a job from its checkpoint to the end of the source table
+func (r *Runner) Run(ctx context.Context, job *Job) error {
+    cp, err := r.checkpoints.Load(ctx, job.Name)
+    if err != nil {
+        return err
+    }
+    if cp.CompletedAt != nil {
+        log.Printf("✓ %s already completed at %s (%d rows); use -reset to run it again", job.Name, cp.CompletedAt.Format(time.RFC3339), cp.RowsCopied)
+        return nil
+    }
+    if cp.LastKey != nil {
+        log.Printf("Resuming %s after key %s (%d rows copied)", job.Name, *cp.LastKey, cp.RowsCopied)
+    }
+
+    throttle := NewThrottle(r.options.RowsPerSecond)
+    batchSize := r.batchSize(job)
+    started := time.Now()
+
+    for {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+
+        n, err := r.copyBatch(ctx, job, cp, batchSize)
+        if err != nil {
+            return err
+        }
+        if n == 0 {
+            break
+        }
+
+        log.Printf("  %s: copied %d rows (total %d, last key %s)", job.Name, n, cp.RowsCopied, *cp.LastKey)
+        throttle.Wait(n)
+    }
+
+    if err := r.checkpoints.Complete(ctx, cp); err != nil {
+        return err
+    }
+
+    log.Printf("✓ %s completed: %d rows in %s", job.Name, cp.RowsCopied, time.Since(started).Round(time.Millisecond))
+    return nil
+}
+
+// copyBatch copies one batch and advances the checkpoint in the same transaction
+func (r *Runner) copyBatch(ctx context.Context, job *Job, cp *Checkpoint, batchSize int) (int, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    rows, err := fetchBatch(ctx, tx, job, cp.LastKey, batchSize)
+    if err != nil {
+        return 0, err
+    }
+    if len(rows) == 0 {
+        return 0, nil
+    }
+
+    args := make([]interface{}, 0, len(rows)*len(job.Columns))
+    for _, row := range rows {
+        args = append(args, row...)
+    }
+    if _, err := tx.ExecContext(ctx, job.InsertQuery(len(rows)), args...); err != nil {
+        return 0, fmt.Errorf("failed to insert batch into %s: %w", job.Target, err)
+    }
+
+    lastKey := valueString(rows[len(rows)-1][job.keyIndex()])
+    cp.LastKey = &lastKey
+    cp.RowsCopied += int64(len(rows))
+    if err := r.checkpoints.Save(ctx, tx, cp); err != nil {
+        return 0, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit batch: %w", err)
+    }
+    return len(rows), nil
+}
+
+// DiffSummary is what a dry run would do to the target
+type DiffSummary struct {
+    Insert    int // rows missing from the target
+    Update    int // rows present with different values (on_conflict=update)
+    Conflict  int // rows present with different values that would be skipped (on_conflict=skip)
+    Unchanged int
+}
+
+// DryRun compares the remaining source rows with the target without writing anything
+func (r *Runner) DryRun(ctx context.Context, job *Job) (*DiffSummary, error) {
+    lastKey, err := r.loadCheckpointReadOnly(ctx, job.Name)
+    if err != nil {
+        return nil, err
+    }
+
+    summary := &DiffSummary{}
+    throttle := NewThrottle(r.options.RowsPerSecond)
+    batchSize := r.batchSize(job)
+    samples := 0
+
+    for {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+
+        rows, err := fetchBatch(ctx, r.db, job, lastKey, batchSize)
+        if err != nil {
+            return nil, err
+        }
+        if len(rows) == 0 {
+            break
+        }
+
+        existing, err := fetchTargetRows(ctx, r.db, job, rows)
+        if err != nil {
+            return nil, err
+        }
+
+        for _, row := range rows {
+            key := valueString(row[job.keyIndex()])
+            target, ok := existing[key]
+            if !ok {
+                summary.Insert++
+                continue
+            }
+
+            changes := diffRow(job, target, row)
+            if len(changes) == 0 {
+                summary.Unchanged++
+                continue
+            }
+            if job.OnConflict == OnConflictUpdate {
+                summary.Update++
+            } else {
+                summary.Conflict++
+            }
+            if samples < r.options.DiffSamples {
+                log.Printf("  ~ %s %s=%s: %v", job.Name, job.Key, key, changes)
+                samples++
+            }
+        }
+
+        last := valueString(rows[len(rows)-1][job.keyIndex()])
+        lastKey = &last
+        throttle.Wait(len(rows))
+    }
+
+    return summary, nil
+}
+
+// loadCheckpointReadOnly returns the last copied key without creating a checkpoint row
+func (r *Runner) loadCheckpointReadOnly(ctx context.Context, jobName string) (*string, error) {
+    var lastKey *string
+    err := r.db.QueryRowContext(ctx, `SELECT last_key FROM datamigrate.checkpoints WHERE job_name = $1`, jobName).Scan(&lastKey)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+    }
+    return lastKey, nil
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx
+type querier interface {
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// fetchBatch reads up to limit source rows after lastKey, in target column order
+func fetchBatch(ctx context.Context, q querier, job *Job, lastKey *string, limit int) ([][]interface{}, error) {
+    var rows *sql.Rows
+    var err error
+    if lastKey == nil {
+        rows, err = q.QueryContext(ctx, job.SelectBatchQuery(false), limit)
+    } else {
+        rows, err = q.QueryContext(ctx, job.SelectBatchQuery(true), *lastKey, limit)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read batch from %s: %w", job.Source, err)
+    }
+    defer rows.Close()
+
+    return scanRows(rows)
+}
+
+// fetchTargetRows loads target rows with the batch's keys, indexed by key
+func fetchTargetRows(ctx context.Context, q querier, job *Job, batch [][]interface{}) (map[string][]interface{}, error) {
+    keys := make([]string, len(batch))
+    for i, row := range batch {
+        keys[i] = valueString(row[job.keyIndex()])
+    }
+
+    rows, err := q.QueryContext(ctx, job.SelectTargetQuery(), pq.Array(keys))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read target rows from %s: %w", job.Target, err)
+    }
+    defer rows.Close()
+
+    values, err := scanRows(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    existing := make(map[string][]interface{}, len(values))
+    for _, row := range values {
+        existing[valueString(row[job.keyIndex()])] = row
+    }
+    return existing, nil
+}
+
+// scanRows reads all rows as generic values.
+// Why: lib/pq returns text-like columns as []byte and would send them back as bytea,
+// so everything except real bytea columns is turned into a string
+func scanRows(rows *sql.Rows) ([][]interface{}, error) {
+    types, err := rows.ColumnTypes()
+    if err != nil {
+        return nil, fmt.Errorf("failed to get column types: %w", err)
+    }
+
+    result := [][]interface{}{}
+    for rows.Next() {
+        values := make([]interface{}, len(types))
+        ptrs := make([]interface{}, len(types))
+        for i := range values {
+            ptrs[i] = &values[i]
+        }
+        if err := rows.Scan(ptrs...); err != nil {
+            return nil, fmt.Errorf("failed to scan row: %w", err)
+        }
+
+        for i, v := range values {
+            if b, ok := v.([]byte); ok && types[i].DatabaseTypeName() != "BYTEA" {
+                values[i] = string(b)
+            }
+        }
+        result = append(result, values)
+    }
+
+    return result, rows.Err()
+}
+
+// diffRow lists "column: target -> source" for columns whose values differ
+func diffRow(job *Job, target, source []interface{}) []string {
+    changes := []string{}
+    for i, col := range job.Columns {
+        if t, s := valueString(target[i]), valueString(source[i]); t != s {
+            changes = append(changes, fmt.Sprintf("%s: %s -> %s", col.Target, t, s))
+        }
+    }
+    return changes
+}
+
+// valueString formats a scanned value for checkpoints and comparisons
+func valueString(v interface{}) string {
+    switch val := v.(type) {
+    case nil:
+        return "NULL"
+    case time.Time:
+        return val.UTC().Format(time.RFC3339Nano)
+    case []byte:
+        return fmt.Sprintf("\\x%x", val)
+    default:
+        return fmt.Sprint(val)
+    }
+}
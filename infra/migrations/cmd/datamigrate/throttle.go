@@ -0,0 +1,39 @@
+package main
+
+import "time"
+
+// Throttle caps copy throughput at a number of rows per second
+// Why: jobs run against the live database; an unthrottled copy competes with service traffic
+type Throttle struct {
+    rowsPerSecond float64 // 0 disables throttling
+    started       time.Time
+    rows          int64
+
+    now   func() time.Time
+    sleep func(time.Duration)
+}
+
+// NewThrottle creates a throttle; rowsPerSecond <= 0 disables it
+func NewThrottle(rowsPerSecond float64) *Throttle {
+    return &Throttle{
+        rowsPerSecond: rowsPerSecond,
+        now:           time.Now,
+        sleep:         time.Sleep,
+    }
+}
+
+// Wait records n copied rows and sleeps until the average rate is back under the cap
+func (t *Throttle) Wait(n int) {
+    if t.rowsPerSecond <= 0 {
+        return
+    }
+    if t.started.IsZero() {
+        t.started = t.now()
+    }
+
+    t.rows += int64(n)
+    expected := time.Duration(float64(t.rows) / t.rowsPerSecond * float64(time.Second))
+    if ahead := expected - t.now().Sub(t.started); ahead > 0 {
+        t.sleep(ahead)
+    }
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// migrationName is the part of a migration's file name after its version
+var migrationName = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// createMigration writes an empty up/down pair to dir, versioned by now in UTC
+// (20060102150405). Why: a timestamp sorts after every numbered migration and two branches
+// adding one at once don't pick the same version.
+func createMigration(dir, name string, now time.Time) (up, down string, err error) {
+    if !migrationName.MatchString(name) {
+        return "", "", fmt.Errorf("name %q must be lower_snake_case", name)
+    }
+    version := now.UTC().Format("20060102150405")
+
+    existing, err := filepath.Glob(filepath.Join(dir, version+"_*.sql"))
+    if err != nil {
+        return "", "", err
+    }
+    if len(existing) > 0 {
+        return "", "", fmt.Errorf("version %s already exists in %s", version, dir)
+    }
+
+    base := filepath.Join(dir, version+"_"+name)
+    up, down = base+".up.sql", base+".down.sql"
+    description := strings.ReplaceAll(name, "_", " ")
+    if err := writeNew(up, "-- "+description+"\n"); err != nil {
+        return "", "", err
+    }
+    if err := writeNew(down, "-- undo "+description+"\n"); err != nil {
+        os.Remove(up)
+        return "", "", err
+    }
+    return up, down, nil
+}
+
+// writeNew writes a file that mustn't exist yet
+func writeNew(path, content string) error {
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+    if err != nil {
+        return err
+    }
+    if _, err := f.WriteString(content); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
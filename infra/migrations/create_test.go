@@ -0,0 +1,60 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestCreateMigration(t *testing.T) {
+    dir := t.TempDir()
+    now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+
+    up, down, err := createMigration(dir, "add_order_notes", now)
+    if err != nil {
+        t.Fatalf("createMigration: %v", err)
+    }
+    if want := filepath.Join(dir, "20260304040607_add_order_notes.up.sql"); up != want {
+        t.Errorf("up = %s, want %s", up, want)
+    }
+    if want := filepath.Join(dir, "20260304040607_add_order_notes.down.sql"); down != want {
+        t.Errorf("down = %s, want %s", down, want)
+    }
+    for _, path := range []string{up, down} {
+        if _, err := os.Stat(path); err != nil {
+            t.Errorf("%s: %v", path, err)
+        }
+    }
+
+    if _, _, err := createMigration(dir, "add_order_tags", now); err == nil {
+        t.Error("created a second migration with the same version")
+    }
+}
+
+func TestCreateMigrationRejectsBadNames(t *testing.T) {
+    for _, name := range []string{"", "Add notes", "add-notes", "../notes", "notes_"} {
+        if _, _, err := createMigration(t.TempDir(), name, time.Now()); err == nil {
+            t.Errorf("createMigration(%q) succeeded", name)
+        }
+    }
+}
+
+func TestSelectTargets(t *testing.T) {
+    targets, err := selectTargets("all", true)
+    if err != nil {
+        t.Fatalf("selectTargets: %v", err)
+    }
+    if len(targets) != len(schemaMigrations) || targets[0].Schema != "datamigrate" || targets[len(targets)-1].Schema != "users" {
+        t.Errorf("rolling back all = %v, want every schema, datamigrate first", targets)
+    }
+
+    targets, err = selectTargets("products", false)
+    if err != nil || len(targets) != 1 || targets[0].Schema != "catalog" {
+        t.Errorf("products = %v, %v, want the catalog schema", targets, err)
+    }
+
+    if _, err := selectTargets("billing", false); err == nil {
+        t.Error("selected an unknown service")
+    }
+}
@@ -1,4 +0,0 @@
-DROP SCHEMA IF EXISTS catalog CASCADE;
-DROP SCHEMA IF EXISTS users CASCADE;
-DROP SCHEMA IF EXISTS cart CASCADE;
-DROP SCHEMA IF EXISTS orders CASCADE;
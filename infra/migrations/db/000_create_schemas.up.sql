@@ -1,4 +0,0 @@
-CREATE SCHEMA IF NOT EXISTS catalog;
-CREATE SCHEMA IF NOT EXISTS users;
-CREATE SCHEMA IF NOT EXISTS cart;
-CREATE SCHEMA IF NOT EXISTS orders;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS datamigrate.checkpoints;
+DROP SCHEMA IF EXISTS datamigrate;
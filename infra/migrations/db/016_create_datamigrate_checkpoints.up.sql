@@ -0,0 +1,11 @@
+-- Progress of cmd/datamigrate copy jobs, so an interrupted job resumes after its last batch
+CREATE SCHEMA IF NOT EXISTS datamigrate;
+
+CREATE TABLE IF NOT EXISTS datamigrate.checkpoints (
+    job_name VARCHAR(100) PRIMARY KEY,
+    last_key TEXT NULL,
+    rows_copied BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL
+);
@@ -2,10 +2,15 @@ module github.com/sanketh-sg/prost/infra/migrations
 
 go 1.25.4
 
-require github.com/golang-migrate/migrate/v4 v4.19.0
+require (
+	github.com/lib/pq v1.10.9
+	github.com/sanketh-sg/prost/shared v0.0.1
+)
 
 require (
+	github.com/golang-migrate/migrate/v4 v4.19.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
 )
+
+replace github.com/sanketh-sg/prost/shared v0.0.1 => ../../shared
@@ -0,0 +1,61 @@
+// Package addresses reads the shipping address a checkout ships to from the users service.
+package addresses
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/clients"
+    sharedModels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// ErrAddressNotFound is returned for an address the user doesn't have
+var ErrAddressNotFound = errors.New("address not found")
+
+// Lookup returns a copy of one of a user's saved addresses
+type Lookup interface {
+    // Get reads the address as the user: authorization is their Authorization header
+    Get(ctx context.Context, authorization, userID, addressID string) (*sharedModels.Address, error)
+}
+
+// Client reads addresses from the users service
+type Client struct {
+    users *clients.Users
+}
+
+// NewClient creates new users service address client
+func NewClient(baseURL string, timeout time.Duration) *Client {
+    return &Client{
+        users: clients.NewUsers(clients.Config{BaseURL: baseURL, Timeout: timeout}),
+    }
+}
+
+// Get calls GET /profile/:id/addresses/:address_id on the users service with the caller's
+// token, so only their own addresses are found
+func (c *Client) Get(ctx context.Context, authorization, userID, addressID string) (*sharedModels.Address, error) {
+    address, err := c.users.GetAddress(clients.WithHeader(ctx, "Authorization", authorization), userID, addressID)
+    if clients.IsNotFound(err) {
+        return nil, ErrAddressNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch address %s: %w", addressID, err)
+    }
+    return Snapshot(address), nil
+}
+
+// Snapshot is the copy of a saved address an order keeps
+func Snapshot(address *clients.Address) *sharedModels.Address {
+    return &sharedModels.Address{
+        ID:            address.ID,
+        RecipientName: address.RecipientName,
+        Line1:         address.Line1,
+        Line2:         address.Line2,
+        City:          address.City,
+        Region:        address.Region,
+        PostalCode:    address.PostalCode,
+        Country:       address.Country,
+        Phone:         address.Phone,
+    }
+}
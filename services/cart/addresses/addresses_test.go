@@ -0,0 +1,44 @@
+package addresses
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestGet_ForwardsTokenAndSnapshots(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/profile/user-1/addresses/addr-1" {
+            t.Errorf("unexpected path %s", r.URL.Path)
+        }
+        if r.Header.Get("Authorization") != "Bearer token" {
+            t.Errorf("expected the caller's token, got %q", r.Header.Get("Authorization"))
+        }
+        w.Write([]byte(`{"id":"addr-1","user_id":"user-1","label":"Home","recipient_name":"Ada","line1":"1 Main St","city":"Springfield","postal_code":"12345","country":"US","is_default":true}`))
+    }))
+    defer server.Close()
+
+    address, err := NewClient(server.URL, time.Second).Get(context.Background(), "Bearer token", "user-1", "addr-1")
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if address.ID != "addr-1" || address.RecipientName != "Ada" || address.Country != "US" {
+        t.Fatalf("unexpected address %+v", address)
+    }
+}
+
+func TestGet_NotFound(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNotFound)
+        w.Write([]byte(`{"error":"address not found"}`))
+    }))
+    defer server.Close()
+
+    _, err := NewClient(server.URL, time.Second).Get(context.Background(), "Bearer token", "user-1", "addr-1")
+    if !errors.Is(err, ErrAddressNotFound) {
+        t.Fatalf("expected ErrAddressNotFound, got %v", err)
+    }
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+    "database/sql"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/services/cart/repository"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// Funnel window limits (GET /admin/funnel?hours=)
+const (
+    defaultFunnelHours = 24
+    maxFunnelHours     = 24 * 30
+)
+
+// AdminHandler serves admin-only reporting and support endpoints
+type AdminHandler struct {
+    statsRepo repository.StatsRepositoryInterface
+    sagaRepo  repository.SagaStateRepositoryInterface
+}
+
+// NewAdminHandler creates new admin handler
+func NewAdminHandler(statsRepo repository.StatsRepositoryInterface, sagaRepo repository.SagaStateRepositoryInterface) *AdminHandler {
+    return &AdminHandler{statsRepo: statsRepo, sagaRepo: sagaRepo}
+}
+
+// GetFunnel returns carts created and checkouts initiated over the last ?hours= (default 24)
+func (ah *AdminHandler) GetFunnel(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    hours := defaultFunnelHours
+    if raw := c.Query("hours"); raw != "" {
+        parsed, err := strconv.Atoi(raw)
+        if err != nil || parsed < 1 || parsed > maxFunnelHours {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid hours",
+                Message: "hours must be between 1 and 720",
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        hours = parsed
+    }
+
+    to := time.Now().UTC()
+    funnel, err := ah.statsRepo.GetFunnel(ctx, to.Add(-time.Duration(hours)*time.Hour), to)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get funnel",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, funnel)
+}
+
+// GetSagaState returns the cart's side of a checkout saga: when checkout started, the cart it
+// came from and the status the cart last saw
+func (ah *AdminHandler) GetSagaState(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    saga, err := ah.sagaRepo.GetSagaState(ctx, c.Param("correlation_id"))
+    if err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, sql.ErrNoRows) {
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get saga state",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, saga)
+}
@@ -2,45 +2,69 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sanketh-sg/prost/services/cart/addresses"
+	"github.com/sanketh-sg/prost/services/cart/inventory"
 	"github.com/sanketh-sg/prost/services/cart/models"
+	"github.com/sanketh-sg/prost/services/cart/pricing"
 	"github.com/sanketh-sg/prost/services/cart/repository"
 	"github.com/sanketh-sg/prost/shared/db"
 	"github.com/sanketh-sg/prost/shared/events"
-	"github.com/sanketh-sg/prost/shared/messaging"
+	"github.com/sanketh-sg/prost/shared/metrics"
+	"github.com/sanketh-sg/prost/shared/reqctx"
+	"github.com/sanketh-sg/prost/shared/versioning"
 	sharedModels "github.com/sanketh-sg/prost/shared/models"
 )
 
+// EventPublisher publishes cart events; *messaging.Publisher in production
+type EventPublisher interface {
+	PublishCartEvent(ctx context.Context, event interface{}) error
+}
+
 // CartHandler handles cart-related HTTP requests
 type CartHandler struct {
-	cartRepo          *repository.CartRepository
-	sagaRepo          *repository.SagaStateRepository
-	inventoryLockRepo *repository.InventoryLockRepository
+	cartRepo          repository.CartRepositoryInterface
+	sagaRepo          repository.SagaStateRepositoryInterface
+	inventoryLockRepo repository.InventoryLockRepositoryInterface
 	idempotencyStore  *db.IdempotencyStore
-	eventPublisher    *messaging.Publisher
+	couponRepo        repository.CouponRepositoryInterface
+	eventPublisher    EventPublisher
+	prices            pricing.PriceLookup // nil trusts client prices and skips checkout price validation
+	locks             inventory.Locker    // nil adds items without soft-locking their stock
+	addresses         addresses.Lookup    // nil refuses checkouts, which need a shipping address
 }
 
 // NewCartHandler creates new cart handler
 func NewCartHandler(
-	cartRepo *repository.CartRepository,
-	sagaRepo *repository.SagaStateRepository,
-	inventoryLockRepo *repository.InventoryLockRepository,
+	cartRepo repository.CartRepositoryInterface,
+	sagaRepo repository.SagaStateRepositoryInterface,
+	inventoryLockRepo repository.InventoryLockRepositoryInterface,
 	idempotencyStore *db.IdempotencyStore,
-	eventPublisher *messaging.Publisher,
+	couponRepo repository.CouponRepositoryInterface,
+	eventPublisher EventPublisher,
+	prices pricing.PriceLookup,
+	locks inventory.Locker,
+	addressLookup addresses.Lookup,
 ) *CartHandler {
 	return &CartHandler{
 		cartRepo:          cartRepo,
 		sagaRepo:          sagaRepo,
 		inventoryLockRepo: inventoryLockRepo,
 		idempotencyStore:  idempotencyStore,
+		couponRepo:        couponRepo,
 		eventPublisher:    eventPublisher,
+		prices:            prices,
+		locks:             locks,
+		addresses:         addressLookup,
 	}
 }
 
@@ -70,7 +94,7 @@ func (ch *CartHandler) getUserIDFromContext(c *gin.Context) (string, error) {
 
 // CreateCart gets user's active cart or creates new one
 func (ch *CartHandler) CreateCart(c *gin.Context) {
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     userID, err := ch.getUserIDFromContext(c)
@@ -83,21 +107,8 @@ func (ch *CartHandler) CreateCart(c *gin.Context) {
         return
     }
 
-    // Get existing active cart
-    cart, err := ch.cartRepo.GetCartByUserID(ctx, userID)
-    if err == nil && cart != nil {
-        log.Printf("✓ Returning existing cart: %s for user %s", cart.ID, userID)
-        c.JSON(http.StatusOK, gin.H{
-            "message": "Cart retrieved successfully",
-            "cart":    cart,
-        })
-        return
-    }
-
-    // Create new cart if none exists
-    log.Println("Cart not found creating a new cart...")
-    newCart := models.NewCart(userID)
-    if err := ch.cartRepo.CreateCart(ctx, newCart); err != nil {
+    cart, created, err := ch.cartRepo.GetOrCreateActiveCart(ctx, userID, c.GetBool("guest"))
+    if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to create cart",
             Message: err.Error(),
@@ -106,17 +117,27 @@ func (ch *CartHandler) CreateCart(c *gin.Context) {
         return
     }
 
-    log.Printf("New cart created: %s for user %s", newCart.ID, userID)
+    if !created {
+        log.Printf("✓ Returning existing cart: %s for user %s", cart.ID, userID)
+        c.JSON(http.StatusOK, gin.H{
+            "message": "Cart retrieved successfully",
+            "cart":    cart,
+        })
+        return
+    }
+
+    log.Printf("New cart created: %s for user %s", cart.ID, userID)
+    metrics.Inc(metrics.CartsCreated, metrics.TraceIDFromRequest(c.Request))
 
     c.JSON(http.StatusCreated, gin.H{
         "message": "Cart created successfully",
-        "cart":    newCart,
+        "cart":    cart,
     })
 }
 
 // GetCart retrieves user's active cart
 func (ch *CartHandler) GetCart(c *gin.Context) {
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     userID, err := ch.getUserIDFromContext(c)
@@ -129,7 +150,43 @@ func (ch *CartHandler) GetCart(c *gin.Context) {
         return
     }
 
-    // Get existing active cart
+    ch.respondActiveCart(ctx, c, userID)
+}
+
+// GetCartByUser retrieves a user's active cart by user ID, for the user or an admin
+// (support looking at a customer's cart)
+func (ch *CartHandler) GetCartByUser(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    callerID, err := ch.getUserIDFromContext(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: err.Error(),
+            Code:    http.StatusUnauthorized,
+        })
+        return
+    }
+
+    userID := c.Param("user_id")
+    if userID != callerID && c.GetString("role") != adminRole {
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "not your cart",
+            Message: "",
+            Code:    http.StatusForbidden,
+        })
+        return
+    }
+
+    ch.respondActiveCart(ctx, c, userID)
+}
+
+// adminRole may read any user's cart
+const adminRole = "admin"
+
+// respondActiveCart answers with userID's active cart and its ETag, or 404
+func (ch *CartHandler) respondActiveCart(ctx context.Context, c *gin.Context, userID string) {
     cart, err := ch.cartRepo.GetCartByUserID(ctx, userID)
     if err != nil || cart == nil {
         c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -144,6 +201,7 @@ func (ch *CartHandler) GetCart(c *gin.Context) {
     }
 
     log.Printf("✓ Cart retrieved: %s for user %s", cart.ID, userID)
+    versioning.SetETag(c.Writer, cart.ID, cart.Version)
     c.JSON(http.StatusOK, gin.H{
         "message": "Cart retrieved successfully",
         "cart":    cart,
@@ -153,7 +211,7 @@ func (ch *CartHandler) GetCart(c *gin.Context) {
 
 // AddItem adds an item to user's cart
 func (ch *CartHandler) AddItem(c *gin.Context) {
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     userID, err := ch.getUserIDFromContext(c)
@@ -176,26 +234,61 @@ func (ch *CartHandler) AddItem(c *gin.Context) {
         return
     }
 
-    // Get user's active cart
-    cart, err := ch.cartRepo.GetCartByUserID(ctx, userID)
-    if err != nil || cart == nil {
-      newCart := models.NewCart(userID)
-        if err := ch.cartRepo.CreateCart(ctx, newCart); err != nil {
-            c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-                Error:   "failed to create cart",
-                Message: err.Error(),
-                Code:    http.StatusInternalServerError,
+    // The line is stored at the catalog price, whatever the client sent
+    price, ok := ch.resolveItemPrice(ctx, c, req)
+    if !ok {
+        return
+    }
+
+    // Get user's active cart, creating it on the first add
+    cart, created, err := ch.cartRepo.GetOrCreateActiveCart(ctx, userID, c.GetBool("guest"))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to create cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+    if created {
+        log.Printf("✓ New cart created for user %s: %s", userID, cart.ID)
+        metrics.Inc(metrics.CartsCreated, metrics.TraceIDFromRequest(c.Request))
+    }
+    if cartPreconditionFailed(c, cart) {
+        return
+    }
+
+    // Soft-lock the line's stock first, so an item that can't be had isn't added
+    var softLock *inventory.Lock
+    if ch.locks != nil {
+        quantity := cart.LineQuantity(req.ProductID, req.VariantID) + req.Quantity
+        softLock, err = ch.lockLine(ctx, cart.ID, req.ProductID, req.VariantID, quantity)
+        var shortage *inventory.ShortageError
+        if errors.As(err, &shortage) {
+            c.JSON(http.StatusConflict, models.StockShortageResponse{
+                Error:     "insufficient stock",
+                Message:   shortage.Error(),
+                Code:      http.StatusConflict,
+                ProductID: shortage.ProductID,
+                Available: shortage.Available,
             })
             return
         }
-        cart = newCart
-        log.Printf("✓ New cart created for user %s: %s", userID, cart.ID)
+        if err != nil {
+            // Why: the lock is a courtesy; products being down shouldn't stop customers adding items
+            log.Printf("⚠️  Adding product %d to cart %s without a soft lock: %v", req.ProductID, cart.ID, err)
+        }
     }
 
-
     // Create and add item
-    item := models.NewCartItem(cart.ID, req.ProductID, req.Quantity, req.Price)
-    if err := ch.cartRepo.AddItem(ctx, item); err != nil {
+    // Only to the version read above: a checkout that claimed the cart meanwhile wins
+    item := models.NewCartItem(cart.ID, req.ProductID, req.VariantID, req.Quantity, price)
+    if err := ch.cartRepo.AddItem(ctx, item, cart.Version); err != nil {
+        if errors.Is(err, repository.ErrCartVersionConflict) {
+            // The soft lock, if one was taken, expires on its own
+            respondCartConflict(c, err)
+            return
+        }
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to add item",
             Message: err.Error(),
@@ -210,20 +303,97 @@ func (ch *CartHandler) AddItem(c *gin.Context) {
     }
 
     // Get updated cart for response
-    updatedCart, _ := ch.cartRepo.GetCart(ctx, cart.ID)
+    updatedCart, err := ch.cartRepo.GetCart(ctx, cart.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
 
     log.Printf("✓ Item added to cart: Product %d, Quantity %d", req.ProductID, req.Quantity)
 
-    c.JSON(http.StatusCreated, gin.H{
+    response := gin.H{
         "message":   "Item added successfully",
         "item":      item,
         "new_total": updatedCart.Total,
-    })
+        "cart":      updatedCart,
+    }
+    if softLock != nil {
+        response["soft_lock"] = softLock
+    }
+    versioning.SetETag(c.Writer, updatedCart.ID, updatedCart.Version)
+    c.JSON(http.StatusCreated, response)
+}
+
+// lockLine soft-locks quantity (the line's whole quantity) in the products service and keeps
+// a copy in inventory_locks, replacing the line's earlier lock
+func (ch *CartHandler) lockLine(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int) (*inventory.Lock, error) {
+    lock, err := ch.locks.Lock(ctx, cartID, productID, variantID, quantity)
+    if err != nil {
+        return nil, err
+    }
+
+    // Products already released the previous lock when it took this one
+    for _, previous := range ch.lineLocks(ctx, cartID, productID, variantID) {
+        if err := ch.inventoryLockRepo.ReleaseLock(ctx, previous.ReservationID); err != nil {
+            log.Printf("⚠️  Failed to release replaced lock %s: %v", previous.ReservationID, err)
+        }
+    }
+
+    record := models.NewInventoryLock(cartID, productID, lock.Quantity, lock.ReservationID)
+    record.VariantID = variantID
+    record.ExpiresAt = lock.ExpiresAt
+    if err := ch.inventoryLockRepo.CreateLock(ctx, record); err != nil {
+        // The products lock still holds the stock and expires on its own
+        log.Printf("⚠️  Failed to record soft lock %s: %v", lock.ReservationID, err)
+    }
+
+    log.Printf("✓ Soft lock %s: %d unit(s) of product %d until %s", lock.ReservationID, lock.Quantity, productID, lock.ExpiresAt.Format(time.RFC3339))
+    return lock, nil
+}
+
+// releaseLineLocks gives back the soft locks of a product's lines; a nil variantID matches
+// every variant of the product, like RemoveItem.
+// Failures are only logged: the locks expire on their own.
+func (ch *CartHandler) releaseLineLocks(ctx context.Context, cartID string, productID int64, variantID *int64) {
+    for _, lock := range ch.lineLocks(ctx, cartID, productID, variantID) {
+        if err := ch.locks.Release(ctx, cartID, lock.ReservationID); err != nil {
+            log.Printf("⚠️  Failed to release soft lock %s: %v", lock.ReservationID, err)
+            continue
+        }
+        if err := ch.inventoryLockRepo.ReleaseLock(ctx, lock.ReservationID); err != nil {
+            log.Printf("⚠️  Failed to release lock record %s: %v", lock.ReservationID, err)
+        }
+    }
+}
+
+// lineLocks returns the cart's held locks on a product, or on one variant of it
+func (ch *CartHandler) lineLocks(ctx context.Context, cartID string, productID int64, variantID *int64) []*models.InventoryLock {
+    locks, err := ch.inventoryLockRepo.GetLocksByCartID(ctx, cartID)
+    if err != nil {
+        log.Printf("⚠️  Failed to get locks of cart %s: %v", cartID, err)
+        return nil
+    }
+
+    var matching []*models.InventoryLock
+    for _, lock := range locks {
+        if lock.ProductID != productID {
+            continue
+        }
+        if variantID != nil && (lock.VariantID == nil || *lock.VariantID != *variantID) {
+            continue
+        }
+        matching = append(matching, lock)
+    }
+    return matching
 }
 
 // RemoveItem removes an item from cart
 func (ch *CartHandler) RemoveItem(c *gin.Context) {
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     userID, err := ch.getUserIDFromContext(c)
@@ -245,6 +415,9 @@ func (ch *CartHandler) RemoveItem(c *gin.Context) {
         })
         return
     }
+    if cartPreconditionFailed(c, cart) {
+        return
+    }
 
     productIDStr := c.Param("product_id")
     productID, err := strconv.ParseInt(productIDStr, 10, 64)
@@ -257,11 +430,26 @@ func (ch *CartHandler) RemoveItem(c *gin.Context) {
         return
     }
 
+    // ?variant_id= removes only that variant of the product
+    var variantID *int64
+    if variantIDStr := c.Query("variant_id"); variantIDStr != "" {
+        id, err := strconv.ParseInt(variantIDStr, 10, 64)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid variant id",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        variantID = &id
+    }
+
     // Find the item being removed to get its quantity
     var itemQuantity int
     itemFound := false
     for _, item := range cart.Items {
-        if item.ProductID == productID {
+        if item.ProductID == productID && (variantID == nil || (item.VariantID != nil && *item.VariantID == *variantID)) {
             itemQuantity = item.Quantity
             itemFound = true
             break
@@ -280,7 +468,11 @@ func (ch *CartHandler) RemoveItem(c *gin.Context) {
     }
 
     // Remove item from cart
-    if err := ch.cartRepo.RemoveItem(ctx, cart.ID, productID); err != nil {
+    if err := ch.cartRepo.RemoveItem(ctx, cart.ID, productID, variantID, cart.Version); err != nil {
+        if errors.Is(err, repository.ErrCartVersionConflict) {
+            respondCartConflict(c, err)
+            return
+        }
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to remove item",
             Message: err.Error(),
@@ -293,6 +485,10 @@ func (ch *CartHandler) RemoveItem(c *gin.Context) {
         log.Printf("⚠️  Failed to update cart total: %v", err)
     }
 
+    if ch.locks != nil {
+        ch.releaseLineLocks(ctx, cart.ID, productID, variantID)
+    }
+
 
     // Get updated cart for response
     updatedCart, _ := ch.cartRepo.GetCart(ctx, cart.ID)
@@ -300,13 +496,64 @@ func (ch *CartHandler) RemoveItem(c *gin.Context) {
 
     log.Printf("Item removed from cart: Product %d, Quantity %d, New Total: %.2f", productID, itemQuantity, updatedCart.Total)
 
+    versioning.SetETag(c.Writer, updatedCart.ID, updatedCart.Version)
     c.JSON(http.StatusOK, gin.H{
         "message":   "Item removed successfully",
         "new_total": updatedCart.Total,
+        "cart":      updatedCart,
     })
 }
 
-// updateCartTotal recalculates and updates cart total based on current items
+// resolveItemPrice returns the price an added line is stored at, answering the request itself
+// when there is none. Without a products service the client's price is trusted.
+func (ch *CartHandler) resolveItemPrice(ctx context.Context, c *gin.Context, req models.AddItemRequest) (float64, bool) {
+    if ch.prices == nil {
+        if req.Price <= 0 {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "price required",
+                Message: "price is required when catalog prices can't be looked up",
+                Code:    http.StatusBadRequest,
+            })
+            return 0, false
+        }
+        return req.Price, true
+    }
+
+    price, err := pricing.Authoritative(ctx, ch.prices, req.ProductID, req.VariantID, req.Price)
+    var mismatch *pricing.PriceMismatchError
+    switch {
+    case err == nil:
+        return price, true
+    case errors.As(err, &mismatch):
+        log.Printf("⚠️  Rejected add of product %d: %v", req.ProductID, err)
+        c.JSON(http.StatusConflict, models.PriceMismatchResponse{
+            Error:        "price mismatch",
+            Message:      mismatch.Error(),
+            Code:         http.StatusConflict,
+            ProductID:    mismatch.ProductID,
+            VariantID:    mismatch.VariantID,
+            Price:        mismatch.ClientPrice,
+            CatalogPrice: mismatch.CatalogPrice,
+        })
+    case errors.Is(err, pricing.ErrProductUnavailable):
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product unavailable",
+            Message: fmt.Sprintf("product %d is not in the catalog", req.ProductID),
+            Code:    http.StatusNotFound,
+        })
+    default:
+        log.Printf("❌ Price lookup failed for product %d: %v", req.ProductID, err)
+        c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+            Error:   "price lookup unavailable",
+            Message: err.Error(),
+            Code:    http.StatusServiceUnavailable,
+        })
+    }
+    return 0, false
+}
+
+// updateCartTotal recalculates and updates cart total based on current items, less the
+// applied coupon's discount (a percent coupon follows the subtotal)
 // Why: Centralizes total calculation logic, prevents inconsistencies
 func (ch *CartHandler) updateCartTotal(ctx context.Context, cartID string) error {
     cart, err := ch.cartRepo.GetCart(ctx, cartID)
@@ -314,14 +561,18 @@ func (ch *CartHandler) updateCartTotal(ctx context.Context, cartID string) error
         return fmt.Errorf("failed to get cart: %w", err)
     }
 
-    // Recalculate total from all items
-    newTotal := 0.0
-    for _, item := range cart.Items {
-        newTotal += item.Price * float64(item.Quantity)
+    discount := 0.0
+    if cart.CouponCode != nil {
+        coupon, err := ch.couponRepo.GetCouponByCode(ctx, *cart.CouponCode)
+        if err != nil {
+            return fmt.Errorf("failed to get cart coupon: %w", err)
+        }
+        discount = coupon.Discount(cart.Subtotal)
     }
+    newTotal := math.Round((cart.Subtotal-discount)*100) / 100
 
     // Update in database
-    if err := ch.cartRepo.UpdateCartTotal(ctx, cartID, newTotal); err != nil {
+    if err := ch.cartRepo.UpdateCartTotal(ctx, cartID, discount, newTotal); err != nil {
         return fmt.Errorf("failed to update cart total: %w", err)
     }
 
@@ -331,8 +582,7 @@ func (ch *CartHandler) updateCartTotal(ctx context.Context, cartID string) error
 
 // DeleteCart deletes a cart
 func (ch *CartHandler) DeleteCart(c *gin.Context) {
-	// ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
 	defer cancel()
 
     userID, err := ch.getUserIDFromContext(c)
@@ -354,6 +604,9 @@ func (ch *CartHandler) DeleteCart(c *gin.Context) {
         })
         return
     }
+    if cartPreconditionFailed(c, cart) {
+        return
+    }
 
 	if err := ch.cartRepo.DeleteCart(ctx, cart.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -364,6 +617,21 @@ func (ch *CartHandler) DeleteCart(c *gin.Context) {
 		return
 	}
 
+	// The coupon's use goes back to it
+	if cart.CouponCode != nil {
+		if err := ch.couponRepo.RemoveCoupon(ctx, cart.ID); err != nil {
+			log.Printf("⚠️  Failed to release coupon %s of cart %s: %v", *cart.CouponCode, cart.ID, err)
+		}
+	}
+
+	if ch.locks != nil {
+		if err := ch.locks.ReleaseCart(ctx, cart.ID); err != nil {
+			log.Printf("⚠️  Failed to release soft locks of cart %s: %v", cart.ID, err)
+		} else if err := ch.inventoryLockRepo.ReleaseCartLocks(ctx, cart.ID); err != nil {
+			log.Printf("⚠️  Failed to release lock records of cart %s: %v", cart.ID, err)
+		}
+	}
+
 	log.Printf("Cart deleted: %s", cart.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -373,8 +641,7 @@ func (ch *CartHandler) DeleteCart(c *gin.Context) {
 
 // CheckoutCart initiates checkout saga
 func (ch *CartHandler) CheckoutCart(c *gin.Context) {
-	// ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
 	defer cancel()
 
 	userID, err := ch.getUserIDFromContext(c)
@@ -396,6 +663,11 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
         })
         return
     }
+	if cartPreconditionFailed(c, cart) {
+		return
+	}
+	// The version whose lines are validated below; the checkout claims exactly that one
+	version := cart.Version
 
 	var req models.CheckoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -416,6 +688,64 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 		return
 	}
 
+	// The order ships to a copy of the address taken now, so later edits don't move it
+	shippingAddress, ok := ch.lookupShippingAddress(c, userID, req.AddressID)
+	if !ok {
+		return
+	}
+
+	// Re-check price snapshots against the catalog
+	// Why: items keep the price from when they were added; a stale cart must not
+	// check out at an outdated price
+	if ch.prices != nil {
+		validation, err := pricing.Validate(ctx, ch.prices, cart.Items)
+		if err != nil {
+			log.Printf("❌ Price validation failed for cart %s: %v", cart.ID, err)
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "price validation unavailable",
+				Message: err.Error(),
+				Code:    http.StatusServiceUnavailable,
+			})
+			return
+		}
+
+		if !validation.OK() {
+			ch.repriceCart(ctx, cart.ID, validation)
+			c.JSON(http.StatusConflict, priceChangedResponse(validation))
+			return
+		}
+	}
+
+	// A coupon that expired or was switched off since it was applied comes off the cart
+	if cart.CouponCode != nil {
+		if _, err := ch.couponRepo.GetUsableCoupon(ctx, *cart.CouponCode); err != nil {
+			if !errors.Is(err, models.ErrCouponExpired) && !errors.Is(err, repository.ErrCouponNotFound) {
+				respondCouponError(c, "failed to check coupon", err)
+				return
+			}
+			ch.dropExpiredCoupon(c, cart)
+			return
+		}
+	}
+
+	// Recompute the totals from the (now validated) line prices rather than trusting the stored total
+	if err := ch.updateCartTotal(ctx, cart.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "failed to recalculate cart total",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if cart, err = ch.cartRepo.GetCart(ctx, cart.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "failed to get cart",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// Create saga state
 	correlationID := uuid.New().String()
 	saga := models.NewSagaState(cart.ID, userID, correlationID)
@@ -423,9 +753,35 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 	saga.Payload["cart_id"] = cart.ID
 	saga.Payload["user_id"] = userID
 	saga.Payload["items"] = cart.Items
+	saga.Payload["subtotal"] = cart.Subtotal
+	saga.Payload["discount"] = cart.Discount
 	saga.Payload["total"] = cart.Total
+	saga.Payload["shipping_address"] = shippingAddress
+	if cart.CouponCode != nil {
+		saga.Payload["coupon_code"] = *cart.CouponCode
+	}
+
+	// Claim the cart before the saga exists
+	// Why: an item added or removed since the cart was read bumped its version; the checkout
+	// is refused rather than charge for a cart the customer never saw
+	if err := ch.cartRepo.UpdateCartStatus(ctx, cart.ID, "checked_out", version); err != nil {
+		if errors.Is(err, repository.ErrCartVersionConflict) {
+			respondCartConflict(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "failed to check out cart",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
 
 	if err := ch.sagaRepo.CreateSagaState(ctx, saga); err != nil {
+		// Give the cart back so the customer can try again
+		if restoreErr := ch.cartRepo.UpdateCartStatus(ctx, cart.ID, "active", version+1); restoreErr != nil {
+			log.Printf("⚠️  Failed to reactivate cart %s: %v", cart.ID, restoreErr)
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "failed to create saga state",
 			Message: err.Error(),
@@ -434,9 +790,11 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 		return
 	}
 
-	// Update cart status
-	if err := ch.cartRepo.UpdateCartStatus(ctx, cart.ID, "checked_out"); err != nil {
-		log.Printf("⚠️  Failed to update cart status: %v", err)
+	// The coupon's use now belongs to this checkout; it's given back if the checkout fails
+	if cart.CouponCode != nil {
+		if err := ch.couponRepo.RedeemCoupon(ctx, cart.ID, correlationID); err != nil {
+			log.Printf("⚠️  Failed to redeem coupon %s of cart %s: %v", *cart.CouponCode, cart.ID, err)
+		}
 	}
 
 	// Publish CartCheckoutInitiated event (saga trigger)
@@ -444,8 +802,15 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 		BaseEvent: events.NewBaseEvent("CartCheckoutInitiated", cart.ID, "cart", correlationID),
 		CartID:    cart.ID,
 		UserID:    cart.UserID,
+		Subtotal:  cart.Subtotal,
+		Discount:  cart.Discount,
 		Total:     cart.Total,
 		Items:      ch.convertCartItemsToOrderItems(cart.Items),
+
+		ShippingAddress: shippingAddress,
+	}
+	if cart.CouponCode != nil {
+		event.CouponCode = *cart.CouponCode
 	}
 
 	if err := ch.eventPublisher.PublishCartEvent(ctx, event); err != nil {
@@ -453,6 +818,7 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 	}
 
 	log.Printf("✓ Checkout initiated: Cart %s, Correlation %s", cart.ID, correlationID)
+	metrics.Inc(metrics.CheckoutsInitiated, metrics.TraceIDFromRequest(c.Request))
 
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":        "Checkout initiated",
@@ -461,11 +827,122 @@ func (ch *CartHandler) CheckoutCart(c *gin.Context) {
 	})
 }
 
+// lookupShippingAddress copies the user's saved address from the users service, reading it
+// with the caller's own token, or writes the error response
+func (ch *CartHandler) lookupShippingAddress(c *gin.Context, userID, addressID string) (*sharedModels.Address, bool) {
+	if ch.addresses == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "address lookup unavailable",
+			Message: "USERS_SERVICE_URL is not configured",
+			Code:    http.StatusServiceUnavailable,
+		})
+		return nil, false
+	}
+
+	address, err := ch.addresses.Get(c.Request.Context(), c.GetHeader("Authorization"), userID, addressID)
+	if errors.Is(err, addresses.ErrAddressNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "address not found",
+			Message: fmt.Sprintf("address %s is not one of your saved addresses", addressID),
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("❌ Address lookup failed for user %s: %v", userID, err)
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "address lookup unavailable",
+			Message: err.Error(),
+			Code:    http.StatusServiceUnavailable,
+		})
+		return nil, false
+	}
+	return address, true
+}
+
+// repriceCart stores the current prices on the cart so the next checkout succeeds
+func (ch *CartHandler) repriceCart(ctx context.Context, cartID string, validation *models.PriceValidation) {
+	for _, change := range validation.Changes {
+		if err := ch.cartRepo.UpdateItemPrice(ctx, cartID, change.ProductID, change.VariantID, change.NewPrice); err != nil {
+			log.Printf("⚠️  Failed to reprice product %d in cart %s: %v", change.ProductID, cartID, err)
+		}
+	}
+
+	if err := ch.updateCartTotal(ctx, cartID); err != nil {
+		log.Printf("⚠️  Failed to update cart total after repricing: %v", err)
+	}
+
+	log.Printf("⚠️  Checkout blocked for cart %s: %d price change(s), %d unavailable product(s)",
+		cartID, len(validation.Changes), len(validation.Unavailable))
+}
+
+// dropExpiredCoupon removes a coupon that can no longer be used from the cart and answers the
+// checkout with 409, so the customer sees the new total before paying it
+func (ch *CartHandler) dropExpiredCoupon(c *gin.Context, cart *models.Cart) {
+	ctx := c.Request.Context()
+
+	if err := ch.couponRepo.RemoveCoupon(ctx, cart.ID); err != nil {
+		respondCouponError(c, "failed to remove expired coupon", err)
+		return
+	}
+	total := cart.Total + cart.Discount
+	if updated, err := ch.cartRepo.GetCart(ctx, cart.ID); err == nil {
+		total = updated.Total
+	}
+
+	log.Printf("⚠️  Checkout blocked for cart %s: coupon %s expired", cart.ID, *cart.CouponCode)
+	c.JSON(http.StatusConflict, models.ErrorResponse{
+		Error:   "coupon expired",
+		Message: fmt.Sprintf("coupon %s can no longer be used and was removed; cart total is now %.2f, please confirm and checkout again", *cart.CouponCode, total),
+		Code:    http.StatusConflict,
+	})
+}
+
+// cartPreconditionFailed answers 412 when the request's If-Match names another version of the
+// cart, and reports whether it did
+func cartPreconditionFailed(c *gin.Context, cart *models.Cart) bool {
+    if versioning.IfMatch(c.Request, cart.ID, cart.Version) {
+        return false
+    }
+    versioning.SetETag(c.Writer, cart.ID, cart.Version)
+    c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{
+        Error:   "cart has changed",
+        Message: "the cart is no longer at the version in If-Match; get it again and retry",
+        Code:    http.StatusPreconditionFailed,
+    })
+    return true
+}
+
+// respondCartConflict answers a write that lost a race with another change to the cart
+func respondCartConflict(c *gin.Context, err error) {
+    c.JSON(http.StatusConflict, models.ErrorResponse{
+        Error:   "cart changed",
+        Message: err.Error() + "; get the cart again and retry",
+        Code:    http.StatusConflict,
+    })
+}
+
+// priceChangedResponse builds the 409 body for a failed price validation
+func priceChangedResponse(validation *models.PriceValidation) models.PriceChangedResponse {
+	resp := models.PriceChangedResponse{
+		Error:           "price changed",
+		Message:         fmt.Sprintf("prices changed for %d item(s); cart repriced from %.2f to %.2f, please confirm and checkout again", len(validation.Changes), validation.OldTotal, validation.NewTotal),
+		Code:            http.StatusConflict,
+		PriceValidation: *validation,
+	}
+	if len(validation.Unavailable) > 0 {
+		resp.Error = "product unavailable"
+		resp.Message = fmt.Sprintf("%d product(s) are no longer available; remove them and checkout again", len(validation.Unavailable))
+	}
+	return resp
+}
+
 func (ch *CartHandler) convertCartItemsToOrderItems(cartItems []models.CartItem) []sharedModels.OrderItem{
     orderItems := make([]sharedModels.OrderItem, len(cartItems))
     for i, cartItem := range cartItems {
         orderItems[i] = sharedModels.OrderItem{
             ProductID: cartItem.ProductID,
+            VariantID: cartItem.VariantID,
             Quantity: cartItem.Quantity,
             Price: cartItem.Price,
         }
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/services/cart/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+    sharedModels "github.com/sanketh-sg/prost/shared/models"
+    "github.com/sanketh-sg/prost/shared/versioning"
+)
+
+const testUserID = "user-1"
+
+func newTestCartHandler(cartRepo *MockCartRepository, sagaRepo *MockSagaStateRepository, publisher *MockPublisher, addressLookup *MockAddresses) *CartHandler {
+    handler := NewCartHandler(cartRepo, sagaRepo, &MockInventoryLockRepository{}, nil, &MockCouponRepository{}, publisher, nil, nil, nil)
+    if addressLookup != nil {
+        handler.addresses = addressLookup
+    }
+    return handler
+}
+
+func TestAddItemStoresTheLineAndRecalculatesTheTotal(t *testing.T) {
+    cart := testCart(testUserID)
+    var added *models.CartItem
+    var total float64
+    cartRepo := &MockCartRepository{
+        GetOrCreateActiveCartFunc: func(ctx context.Context, userID string, guest bool) (*models.Cart, bool, error) {
+            return cart, false, nil
+        },
+        AddItemFunc: func(ctx context.Context, item *models.CartItem, version int) error {
+            added = item
+            return nil
+        },
+        GetCartFunc: func(ctx context.Context, cartID string) (*models.Cart, error) {
+            if added == nil {
+                return cart, nil
+            }
+            return testCart(testUserID, *added), nil
+        },
+        UpdateCartTotalFunc: func(ctx context.Context, cartID string, discount, newTotal float64) error {
+            total = newTotal
+            return nil
+        },
+    }
+    handler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, nil)
+
+    rec := serve(t, handler.AddItem, http.MethodPost, "/carts/items", testUserID, map[string]interface{}{
+        "product_id": 7,
+        "quantity":   2,
+        "price":      12.5,
+    })
+
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+    }
+    if added == nil || added.CartID != cart.ID || added.ProductID != 7 || added.Quantity != 2 || added.Price != 12.5 {
+        t.Errorf("added item = %+v, want 2 of product 7 at 12.5 in cart %s", added, cart.ID)
+    }
+    if total != 25 {
+        t.Errorf("cart total = %v, want 25", total)
+    }
+}
+
+func TestAddItemWithoutAPriceOrCatalogIsRejected(t *testing.T) {
+    added := false
+    cartRepo := &MockCartRepository{
+        AddItemFunc: func(ctx context.Context, item *models.CartItem, version int) error {
+            added = true
+            return nil
+        },
+    }
+    handler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, nil)
+
+    rec := serve(t, handler.AddItem, http.MethodPost, "/carts/items", testUserID, map[string]interface{}{
+        "product_id": 7,
+        "quantity":   1,
+    })
+
+    if rec.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400", rec.Code)
+    }
+    if added {
+        t.Error("item was added without a price")
+    }
+}
+
+func TestGetCartWithoutAnActiveCart(t *testing.T) {
+    handler := newTestCartHandler(&MockCartRepository{}, &MockSagaStateRepository{}, &MockPublisher{}, nil)
+
+    rec := serve(t, handler.GetCart, http.MethodGet, "/carts", testUserID, nil)
+
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", rec.Code)
+    }
+}
+
+func TestCheckoutCartRejectsAnEmptyCart(t *testing.T) {
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            return testCart(userID), nil
+        },
+    }
+    sagaCreated := false
+    sagaRepo := &MockSagaStateRepository{
+        CreateSagaStateFunc: func(ctx context.Context, saga *models.SagaState) error {
+            sagaCreated = true
+            return nil
+        },
+    }
+    publisher := &MockPublisher{}
+    handler := newTestCartHandler(cartRepo, sagaRepo, publisher, &MockAddresses{})
+
+    rec := serve(t, handler.CheckoutCart, http.MethodPost, "/carts/checkout", testUserID, map[string]string{"address_id": "addr-1"})
+
+    if rec.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400", rec.Code)
+    }
+    if sagaCreated || len(publisher.Events) != 0 {
+        t.Error("an empty cart started a checkout")
+    }
+}
+
+func TestCheckoutCartWithoutAddressLookup(t *testing.T) {
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            return testCart(userID, *models.NewCartItem("cart-1", 7, nil, 1, 10)), nil
+        },
+    }
+    handler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, nil)
+
+    rec := serve(t, handler.CheckoutCart, http.MethodPost, "/carts/checkout", testUserID, map[string]string{"address_id": "addr-1"})
+
+    if rec.Code != http.StatusServiceUnavailable {
+        t.Errorf("status = %d, want 503", rec.Code)
+    }
+}
+
+func TestCheckoutCartStartsTheSaga(t *testing.T) {
+    cart := testCart(testUserID, *models.NewCartItem("", 7, nil, 2, 12.5))
+    var status string
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        GetCartFunc: func(ctx context.Context, cartID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        UpdateCartStatusFunc: func(ctx context.Context, cartID string, newStatus string, version int) error {
+            status = newStatus
+            return nil
+        },
+    }
+    var saga *models.SagaState
+    sagaRepo := &MockSagaStateRepository{
+        CreateSagaStateFunc: func(ctx context.Context, created *models.SagaState) error {
+            saga = created
+            return nil
+        },
+    }
+    publisher := &MockPublisher{}
+    address := &sharedModels.Address{ID: "addr-1", City: "London", Country: "GB"}
+    handler := newTestCartHandler(cartRepo, sagaRepo, publisher, &MockAddresses{Address: address})
+
+    rec := serve(t, handler.CheckoutCart, http.MethodPost, "/carts/checkout", testUserID, map[string]string{"address_id": "addr-1"})
+
+    if rec.Code != http.StatusAccepted {
+        t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body)
+    }
+    var body struct {
+        CorrelationID string `json:"correlation_id"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if saga == nil || saga.CorrelationID != body.CorrelationID || saga.CartID != cart.ID {
+        t.Fatalf("saga = %+v, want one for cart %s with correlation %s", saga, cart.ID, body.CorrelationID)
+    }
+    if status != "checked_out" {
+        t.Errorf("cart status = %q, want checked_out", status)
+    }
+
+    if len(publisher.Events) != 1 {
+        t.Fatalf("published %d events, want 1", len(publisher.Events))
+    }
+    event, ok := publisher.Events[0].(events.CartCheckoutInitiatedEvent)
+    if !ok {
+        t.Fatalf("published %T, want CartCheckoutInitiatedEvent", publisher.Events[0])
+    }
+    if event.CorrelationID != body.CorrelationID || event.Total != 25 || len(event.Items) != 1 {
+        t.Errorf("event = %+v, want the cart's one line totalling 25", event)
+    }
+    if event.ShippingAddress == nil || event.ShippingAddress.City != "London" {
+        t.Errorf("shipping address = %+v, want the saved one", event.ShippingAddress)
+    }
+}
+
+func TestCheckoutCartLosingTheVersionRaceIsAConflict(t *testing.T) {
+    cart := testCart(testUserID, *models.NewCartItem("", 7, nil, 2, 12.5))
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        GetCartFunc: func(ctx context.Context, cartID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        UpdateCartStatusFunc: func(ctx context.Context, cartID string, newStatus string, version int) error {
+            return repository.ErrCartVersionConflict
+        },
+    }
+    sagaRepo := &MockSagaStateRepository{
+        CreateSagaStateFunc: func(ctx context.Context, created *models.SagaState) error {
+            t.Error("started a saga for a cart another request changed")
+            return nil
+        },
+    }
+    publisher := &MockPublisher{}
+    address := &sharedModels.Address{ID: "addr-1", City: "London", Country: "GB"}
+    handler := newTestCartHandler(cartRepo, sagaRepo, publisher, &MockAddresses{Address: address})
+
+    rec := serve(t, handler.CheckoutCart, http.MethodPost, "/carts/checkout", testUserID, map[string]string{"address_id": "addr-1"})
+
+    if rec.Code != http.StatusConflict {
+        t.Errorf("status = %d, want 409: %s", rec.Code, rec.Body)
+    }
+    if len(publisher.Events) != 0 {
+        t.Error("published an event for a checkout that lost the race")
+    }
+}
+
+func TestCheckoutCartWithAStaleIfMatch(t *testing.T) {
+    cart := testCart(testUserID, *models.NewCartItem("", 7, nil, 2, 12.5))
+    cart.Version = 3
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        UpdateCartStatusFunc: func(ctx context.Context, cartID string, newStatus string, version int) error {
+            t.Error("checked out a cart the client had a stale copy of")
+            return nil
+        },
+    }
+    address := &sharedModels.Address{ID: "addr-1", City: "London", Country: "GB"}
+    handler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, &MockAddresses{Address: address})
+
+    header := http.Header{"If-Match": {versioning.ETag(cart.ID, 2)}}
+    rec := serveWithHeader(t, handler.CheckoutCart, http.MethodPost, "/carts/checkout", testUserID, map[string]string{"address_id": "addr-1"}, header)
+
+    if rec.Code != http.StatusPreconditionFailed {
+        t.Errorf("status = %d, want 412: %s", rec.Code, rec.Body)
+    }
+    if etag := rec.Header().Get("ETag"); etag != versioning.ETag(cart.ID, 3) {
+        t.Errorf("ETag = %q, want the current version's", etag)
+    }
+}
+
+func TestGetCartByUser(t *testing.T) {
+    cart := testCart("user-2", *models.NewCartItem("", 7, nil, 1, 5))
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            if userID != "user-2" {
+                return nil, errors.New("cart not found")
+            }
+            return cart, nil
+        },
+    }
+    handler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, nil)
+
+    tests := []struct {
+        name   string
+        target string
+        caller string
+        role   string
+        want   int
+    }{
+        {"own cart", "/carts/by-user/user-2", "user-2", "customer", http.StatusOK},
+        {"another user's cart", "/carts/by-user/user-2", testUserID, "customer", http.StatusForbidden},
+        {"admin", "/carts/by-user/user-2", "admin-1", adminRole, http.StatusOK},
+        {"no active cart", "/carts/by-user/user-3", "admin-1", adminRole, http.StatusNotFound},
+    }
+    for _, tt := range tests {
+        rec := serveRoute(t, handler.GetCartByUser, http.MethodGet, "/carts/by-user/:user_id", tt.target, tt.caller, tt.role)
+        if rec.Code != tt.want {
+            t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+        }
+        if tt.want == http.StatusOK && rec.Header().Get("ETag") != versioning.ETag(cart.ID, cart.Version) {
+            t.Errorf("%s: ETag = %q, want the cart's", tt.name, rec.Header().Get("ETag"))
+        }
+    }
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "log"
+    "math"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/services/cart/pricing"
+    "github.com/sanketh-sg/prost/services/cart/repository"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// MergeGuestCart merges the guest cart of the X-Guest-Token header into the caller's active
+// cart; the gateway calls it after login. Quantities of the same product or variant are summed
+// and the merged lines are repriced at the current catalog price. Products gone from the
+// catalog are left out. Without price validation the guest's snapshots are kept; checkout
+// checks them again.
+func (ch *CartHandler) MergeGuestCart(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, err := ch.getUserIDFromContext(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: err.Error(),
+            Code:    http.StatusUnauthorized,
+        })
+        return
+    }
+
+    guestID := c.GetString("guest_id")
+    if guestID == "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "guest token required",
+            Message: "send the guest token in the X-Guest-Token header",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    guestCart, err := ch.cartRepo.GetCartByUserID(ctx, guestID)
+    if errors.Is(err, sql.ErrNoRows) || (err == nil && !guestCart.Guest) {
+        // Nothing to merge: the guest never added anything, or the cart was merged already
+        userCart, err := ch.cartRepo.GetCartByUserID(ctx, userID)
+        if err != nil {
+            userCart = nil
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "message":      "No guest cart to merge",
+            "cart":         userCart,
+            "merged_items": 0,
+        })
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get guest cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    userCart, _, err := ch.cartRepo.GetOrCreateActiveCart(ctx, userID, false)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to create cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    lines, changes, unavailable := ch.repriceMergedLines(ctx, models.MergeLines(userCart, guestCart))
+
+    if err := ch.cartRepo.MergeCart(ctx, guestCart.ID, userCart.ID, lines); err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, repository.ErrCartNotActive) {
+            // Another merge of the same guest cart won
+            status = http.StatusConflict
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to merge guest cart",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    if err := ch.updateCartTotal(ctx, userCart.ID); err != nil {
+        log.Printf("⚠️  Failed to update cart total: %v", err)
+    }
+
+    // The guest's soft locks go back; the merged lines are locked again on the user's cart
+    if ch.locks != nil {
+        if err := ch.locks.ReleaseCart(ctx, guestCart.ID); err != nil {
+            log.Printf("⚠️  Failed to release soft locks of guest cart %s: %v", guestCart.ID, err)
+        } else if err := ch.inventoryLockRepo.ReleaseCartLocks(ctx, guestCart.ID); err != nil {
+            log.Printf("⚠️  Failed to release lock records of guest cart %s: %v", guestCart.ID, err)
+        }
+        for _, line := range lines {
+            if _, err := ch.lockLine(ctx, userCart.ID, line.ProductID, line.VariantID, line.Quantity); err != nil {
+                log.Printf("⚠️  Merged product %d into cart %s without a soft lock: %v", line.ProductID, userCart.ID, err)
+            }
+        }
+    }
+
+    updatedCart, err := ch.cartRepo.GetCart(ctx, userCart.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    log.Printf("✓ Guest cart %s merged into cart %s: %d line(s), total %.2f", guestCart.ID, userCart.ID, len(lines), updatedCart.Total)
+    c.JSON(http.StatusOK, gin.H{
+        "message":       "Guest cart merged successfully",
+        "cart":          updatedCart,
+        "merged_items":  len(lines),
+        "price_changes": changes,
+        "unavailable":   unavailable,
+    })
+}
+
+// repriceMergedLines sets each line to its current catalog price and leaves out the lines
+// gone from the catalog. A price that can't be read keeps the guest's snapshot.
+func (ch *CartHandler) repriceMergedLines(ctx context.Context, lines []models.MergedLine) ([]models.MergedLine, []models.PriceChange, []int64) {
+    changes := []models.PriceChange{}
+    unavailable := []int64{}
+    if ch.prices == nil {
+        return lines, changes, unavailable
+    }
+
+    kept := []models.MergedLine{}
+    for _, line := range lines {
+        current, err := ch.prices.CurrentPrice(ctx, line.ProductID, line.VariantID)
+        if errors.Is(err, pricing.ErrProductUnavailable) {
+            unavailable = append(unavailable, line.ProductID)
+            continue
+        }
+        if err != nil {
+            log.Printf("⚠️  Merging product %d at its snapshot price: %v", line.ProductID, err)
+            kept = append(kept, line)
+            continue
+        }
+
+        if math.Round(current*100) != math.Round(line.Price*100) {
+            changes = append(changes, models.PriceChange{
+                ProductID: line.ProductID,
+                VariantID: line.VariantID,
+                Quantity:  line.Quantity,
+                OldPrice:  line.Price,
+                NewPrice:  current,
+            })
+        }
+        line.Price = current
+        kept = append(kept, line)
+    }
+    return kept, changes, unavailable
+}
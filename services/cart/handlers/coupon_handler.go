@@ -0,0 +1,253 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/services/cart/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+    "github.com/sanketh-sg/prost/shared/versioning"
+)
+
+// CouponHandler applies coupons to carts and manages them for admins
+type CouponHandler struct {
+    cartRepo   repository.CartRepositoryInterface
+    couponRepo repository.CouponRepositoryInterface
+}
+
+// NewCouponHandler creates new coupon handler
+func NewCouponHandler(cartRepo repository.CartRepositoryInterface, couponRepo repository.CouponRepositoryInterface) *CouponHandler {
+    return &CouponHandler{cartRepo: cartRepo, couponRepo: couponRepo}
+}
+
+// ApplyCoupon puts a coupon on the caller's active cart, replacing the one it had. The
+// redemption is recorded at once, so a coupon's last use can't go to two carts.
+func (cph *CouponHandler) ApplyCoupon(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.GetString("user_id")
+    if userID == "" {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: "user_id not found in context (missing auth?)",
+            Code:    http.StatusUnauthorized,
+        })
+        return
+    }
+
+    var req models.ApplyCouponRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    cart, err := cph.cartRepo.GetCartByUserID(ctx, userID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "cart not found",
+            Message: "No active cart exists for this user",
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+    if cartPreconditionFailed(c, cart) {
+        return
+    }
+
+    coupon, err := cph.couponRepo.ApplyCoupon(ctx, cart.ID, cart.UserID, req.Code)
+    if err != nil {
+        respondCouponError(c, "failed to apply coupon", err)
+        return
+    }
+
+    updatedCart, err := cph.cartRepo.GetCart(ctx, cart.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    log.Printf("✓ Coupon %s applied to cart %s: -%.2f, total %.2f", coupon.Code, cart.ID, updatedCart.Discount, updatedCart.Total)
+    versioning.SetETag(c.Writer, updatedCart.ID, updatedCart.Version)
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Coupon applied successfully",
+        "coupon":  coupon,
+        "cart":    updatedCart,
+    })
+}
+
+// RemoveCoupon takes the coupon off the caller's active cart and gives its use back
+func (cph *CouponHandler) RemoveCoupon(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.GetString("user_id")
+    if userID == "" {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: "user_id not found in context (missing auth?)",
+            Code:    http.StatusUnauthorized,
+        })
+        return
+    }
+
+    cart, err := cph.cartRepo.GetCartByUserID(ctx, userID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "cart not found",
+            Message: "No active cart exists for this user",
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+    if cartPreconditionFailed(c, cart) {
+        return
+    }
+
+    if err := cph.couponRepo.RemoveCoupon(ctx, cart.ID); err != nil {
+        respondCouponError(c, "failed to remove coupon", err)
+        return
+    }
+
+    updatedCart, err := cph.cartRepo.GetCart(ctx, cart.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get cart",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    log.Printf("✓ Coupon removed from cart %s", cart.ID)
+    versioning.SetETag(c.Writer, updatedCart.ID, updatedCart.Version)
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Coupon removed successfully",
+        "cart":    updatedCart,
+    })
+}
+
+// CreateCoupon creates a coupon code (admin)
+func (cph *CouponHandler) CreateCoupon(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.CreateCouponRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    coupon := models.NewCoupon(req)
+    if err := coupon.Validate(); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid coupon",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := cph.couponRepo.CreateCoupon(ctx, coupon); err != nil {
+        respondCouponError(c, "failed to create coupon", err)
+        return
+    }
+
+    log.Printf("✓ Coupon created: %s (%s %.2f)", coupon.Code, coupon.DiscountType, coupon.Amount)
+    c.JSON(http.StatusCreated, coupon)
+}
+
+// GetCoupons lists every coupon with its use count (admin)
+func (cph *CouponHandler) GetCoupons(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    coupons, err := cph.couponRepo.GetCoupons(ctx)
+    if err != nil {
+        respondCouponError(c, "failed to get coupons", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "coupons": coupons,
+        "count":   len(coupons),
+    })
+}
+
+// SetCouponActive switches a coupon on or off (admin); carts already holding a switched off
+// coupon lose it at checkout
+func (cph *CouponHandler) SetCouponActive(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req struct {
+        Active *bool `json:"active" binding:"required"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    code := c.Param("code")
+    if err := cph.couponRepo.SetCouponActive(ctx, code, *req.Active); err != nil {
+        respondCouponError(c, "failed to update coupon", err)
+        return
+    }
+
+    coupon, err := cph.couponRepo.GetCouponByCode(ctx, code)
+    if err != nil {
+        respondCouponError(c, "failed to get coupon", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, coupon)
+}
+
+// respondCouponError maps coupon errors to their status: unknown codes are 404, coupons that
+// can't be used (any more) are 409. Their error names the reason, so clients can tell them apart.
+func respondCouponError(c *gin.Context, message string, err error) {
+    status := db.ErrorStatus(err)
+    for _, known := range []error{
+        models.ErrCouponExpired,
+        repository.ErrCouponExhausted,
+        repository.ErrCouponUserLimit,
+        repository.ErrCouponCodeTaken,
+        repository.ErrCartNotActive,
+    } {
+        if errors.Is(err, known) {
+            status = http.StatusConflict
+            message = known.Error()
+        }
+    }
+    switch {
+    case errors.Is(err, repository.ErrCouponNotFound):
+        status = http.StatusNotFound
+        message = repository.ErrCouponNotFound.Error()
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   message,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
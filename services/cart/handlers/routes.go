@@ -0,0 +1,28 @@
+package handlers
+
+import (
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/middleware"
+)
+
+// RegisterCartRoutes registers the /carts routes on carts, a group that has already identified
+// the caller (middleware.CartAuthMiddleware). Every route acts on the caller's own cart, so none
+// takes a cart ID; the gateway's CartService calls the same paths.
+func RegisterCartRoutes(carts *gin.RouterGroup, cartHandler *CartHandler, couponHandler *CouponHandler) {
+    carts.POST("", cartHandler.CreateCart)
+    carts.GET("", cartHandler.GetCart)
+    carts.GET("/by-user/:user_id", cartHandler.GetCartByUser)
+    carts.POST("/items", cartHandler.AddItem)
+    carts.DELETE("/items/:product_id", cartHandler.RemoveItem)
+    carts.DELETE("", cartHandler.DeleteCart)
+
+    // Coupon codes on the caller's cart
+    carts.POST("/apply-coupon", middleware.RequireUser(), couponHandler.ApplyCoupon)
+    carts.DELETE("/coupon", middleware.RequireUser(), couponHandler.RemoveCoupon)
+
+    // Checkout endpoint (initiates saga)
+    carts.POST("/checkout", middleware.RequireUser(), cartHandler.CheckoutCart)
+
+    // Guest cart into the signed-in caller's cart, after login
+    carts.POST("/merge", middleware.RequireUser(), cartHandler.MergeGuestCart)
+}
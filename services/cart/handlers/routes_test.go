@@ -0,0 +1,114 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/models"
+)
+
+// cartRouter mounts the /carts routes behind a stand-in for CartAuthMiddleware: the caller is
+// userID, a guest if guest is set
+func cartRouter(cartRepo *MockCartRepository, userID string, guest bool) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    cartHandler := newTestCartHandler(cartRepo, &MockSagaStateRepository{}, &MockPublisher{}, &MockAddresses{})
+    couponHandler := NewCouponHandler(cartRepo, &MockCouponRepository{})
+
+    router := gin.New()
+    carts := router.Group("/carts", func(c *gin.Context) {
+        c.Set("user_id", userID)
+        c.Set("guest", guest)
+    })
+    RegisterCartRoutes(carts, cartHandler, couponHandler)
+    return router
+}
+
+func TestCartRoutes(t *testing.T) {
+    want := map[string]string{
+        "POST /carts":                     "(*CartHandler).CreateCart",
+        "GET /carts":                      "(*CartHandler).GetCart",
+        "GET /carts/by-user/:user_id":     "(*CartHandler).GetCartByUser",
+        "POST /carts/items":               "(*CartHandler).AddItem",
+        "DELETE /carts/items/:product_id": "(*CartHandler).RemoveItem",
+        "DELETE /carts":                   "(*CartHandler).DeleteCart",
+        "POST /carts/apply-coupon":        "(*CouponHandler).ApplyCoupon",
+        "DELETE /carts/coupon":            "(*CouponHandler).RemoveCoupon",
+        "POST /carts/checkout":            "(*CartHandler).CheckoutCart",
+        "POST /carts/merge":               "(*CartHandler).MergeGuestCart",
+    }
+
+    routes := cartRouter(&MockCartRepository{}, testUserID, false).Routes()
+    if len(routes) != len(want) {
+        t.Errorf("%d routes, want %d", len(routes), len(want))
+    }
+    for _, route := range routes {
+        key := route.Method + " " + route.Path
+        handler, ok := want[key]
+        if !ok {
+            t.Errorf("unexpected route %s", key)
+            continue
+        }
+        if !strings.Contains(route.Handler, handler) {
+            t.Errorf("%s is served by %s, want %s", key, route.Handler, handler)
+        }
+        if strings.Contains(route.Path, ":id") {
+            t.Errorf("%s takes a cart ID; cart routes act on the caller's cart", key)
+        }
+    }
+}
+
+func TestCartRoutesActOnTheCallersCart(t *testing.T) {
+    cart := testCart(testUserID, models.CartItem{ProductID: 7, Quantity: 1, Price: 5})
+    var request string // the route being served, for the failure messages
+    cartRepo := &MockCartRepository{
+        GetCartByUserIDFunc: func(ctx context.Context, userID string) (*models.Cart, error) {
+            if userID != testUserID {
+                t.Errorf("%s loaded the cart of %q, want the caller's", request, userID)
+            }
+            return cart, nil
+        },
+        GetCartFunc: func(ctx context.Context, cartID string) (*models.Cart, error) {
+            return cart, nil
+        },
+        RemoveItemFunc: func(ctx context.Context, cartID string, productID int64, variantID *int64, version int) error {
+            if cartID != cart.ID || productID != 7 {
+                t.Errorf("%s removed product %d from cart %s, want 7 from %s", request, productID, cartID, cart.ID)
+            }
+            return nil
+        },
+    }
+    router := cartRouter(cartRepo, testUserID, false)
+
+    for _, target := range []struct{ method, path string }{
+        {http.MethodGet, "/carts"},
+        {http.MethodDelete, "/carts/items/7"},
+    } {
+        request = target.method + " " + target.path
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, httptest.NewRequest(target.method, target.path, nil))
+        if rec.Code != http.StatusOK {
+            t.Errorf("%s = %d, want 200: %s", request, rec.Code, rec.Body)
+        }
+    }
+}
+
+func TestCartRoutesRefuseGuestsWhereAnAccountIsNeeded(t *testing.T) {
+    router := cartRouter(&MockCartRepository{}, "guest-1", true)
+
+    for _, target := range []struct{ method, path string }{
+        {http.MethodPost, "/carts/apply-coupon"},
+        {http.MethodDelete, "/carts/coupon"},
+        {http.MethodPost, "/carts/checkout"},
+        {http.MethodPost, "/carts/merge"},
+    } {
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, httptest.NewRequest(target.method, target.path, nil))
+        if rec.Code != http.StatusForbidden {
+            t.Errorf("%s %s as a guest = %d, want 403", target.method, target.path, rec.Code)
+        }
+    }
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/cart/models"
+    sharedModels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// MockCartRepository is a mock implementation of CartRepository
+type MockCartRepository struct {
+    CreateCartFunc            func(ctx context.Context, cart *models.Cart) error
+    GetOrCreateActiveCartFunc func(ctx context.Context, userID string, guest bool) (*models.Cart, bool, error)
+    GetCartFunc               func(ctx context.Context, cartID string) (*models.Cart, error)
+    GetCartByUserIDFunc       func(ctx context.Context, userID string) (*models.Cart, error)
+    AddItemFunc               func(ctx context.Context, item *models.CartItem, version int) error
+    RemoveItemFunc            func(ctx context.Context, cartID string, productID int64, variantID *int64, version int) error
+    UpdateCartStatusFunc      func(ctx context.Context, cartID string, status string, version int) error
+    UpdateItemPriceFunc       func(ctx context.Context, cartID string, productID int64, variantID *int64, price float64) error
+    MergeCartFunc             func(ctx context.Context, guestCartID, userCartID string, lines []models.MergedLine) error
+    UpdateCartTotalFunc       func(ctx context.Context, cartID string, discount, total float64) error
+    DeleteCartFunc            func(ctx context.Context, cartID string) error
+    ClearCartFunc             func(ctx context.Context, cartID string) error
+}
+
+func (m *MockCartRepository) CreateCart(ctx context.Context, cart *models.Cart) error {
+    if m.CreateCartFunc != nil {
+        return m.CreateCartFunc(ctx, cart)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) GetOrCreateActiveCart(ctx context.Context, userID string, guest bool) (*models.Cart, bool, error) {
+    if m.GetOrCreateActiveCartFunc != nil {
+        return m.GetOrCreateActiveCartFunc(ctx, userID, guest)
+    }
+    return models.NewCart(userID), true, nil
+}
+
+func (m *MockCartRepository) GetCart(ctx context.Context, cartID string) (*models.Cart, error) {
+    if m.GetCartFunc != nil {
+        return m.GetCartFunc(ctx, cartID)
+    }
+    return nil, errors.New("cart not found")
+}
+
+func (m *MockCartRepository) GetCartByUserID(ctx context.Context, userID string) (*models.Cart, error) {
+    if m.GetCartByUserIDFunc != nil {
+        return m.GetCartByUserIDFunc(ctx, userID)
+    }
+    return nil, errors.New("cart not found")
+}
+
+func (m *MockCartRepository) AddItem(ctx context.Context, item *models.CartItem, version int) error {
+    if m.AddItemFunc != nil {
+        return m.AddItemFunc(ctx, item, version)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) RemoveItem(ctx context.Context, cartID string, productID int64, variantID *int64, version int) error {
+    if m.RemoveItemFunc != nil {
+        return m.RemoveItemFunc(ctx, cartID, productID, variantID, version)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) UpdateCartStatus(ctx context.Context, cartID string, status string, version int) error {
+    if m.UpdateCartStatusFunc != nil {
+        return m.UpdateCartStatusFunc(ctx, cartID, status, version)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) UpdateItemPrice(ctx context.Context, cartID string, productID int64, variantID *int64, price float64) error {
+    if m.UpdateItemPriceFunc != nil {
+        return m.UpdateItemPriceFunc(ctx, cartID, productID, variantID, price)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) MergeCart(ctx context.Context, guestCartID, userCartID string, lines []models.MergedLine) error {
+    if m.MergeCartFunc != nil {
+        return m.MergeCartFunc(ctx, guestCartID, userCartID, lines)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) UpdateCartTotal(ctx context.Context, cartID string, discount, total float64) error {
+    if m.UpdateCartTotalFunc != nil {
+        return m.UpdateCartTotalFunc(ctx, cartID, discount, total)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) DeleteCart(ctx context.Context, cartID string) error {
+    if m.DeleteCartFunc != nil {
+        return m.DeleteCartFunc(ctx, cartID)
+    }
+    return nil
+}
+
+func (m *MockCartRepository) ClearCart(ctx context.Context, cartID string) error {
+    if m.ClearCartFunc != nil {
+        return m.ClearCartFunc(ctx, cartID)
+    }
+    return nil
+}
+
+// MockSagaStateRepository is a mock implementation of SagaStateRepository
+type MockSagaStateRepository struct {
+    CreateSagaStateFunc   func(ctx context.Context, saga *models.SagaState) error
+    GetSagaStateFunc      func(ctx context.Context, correlationID string) (*models.SagaState, error)
+    UpdateSagaStatusFunc  func(ctx context.Context, correlationID string, status string) error
+    AddCompensationFunc   func(ctx context.Context, correlationID string, compensation string) error
+    UpdateSagaPayloadFunc func(ctx context.Context, correlationID string, payload map[string]interface{}) error
+}
+
+func (m *MockSagaStateRepository) CreateSagaState(ctx context.Context, saga *models.SagaState) error {
+    if m.CreateSagaStateFunc != nil {
+        return m.CreateSagaStateFunc(ctx, saga)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error) {
+    if m.GetSagaStateFunc != nil {
+        return m.GetSagaStateFunc(ctx, correlationID)
+    }
+    return nil, errors.New("saga state not found")
+}
+
+func (m *MockSagaStateRepository) UpdateSagaStatus(ctx context.Context, correlationID string, status string) error {
+    if m.UpdateSagaStatusFunc != nil {
+        return m.UpdateSagaStatusFunc(ctx, correlationID, status)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) AddCompensation(ctx context.Context, correlationID string, compensation string) error {
+    if m.AddCompensationFunc != nil {
+        return m.AddCompensationFunc(ctx, correlationID, compensation)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) UpdateSagaPayload(ctx context.Context, correlationID string, payload map[string]interface{}) error {
+    if m.UpdateSagaPayloadFunc != nil {
+        return m.UpdateSagaPayloadFunc(ctx, correlationID, payload)
+    }
+    return nil
+}
+
+// MockInventoryLockRepository is a mock implementation of InventoryLockRepository
+type MockInventoryLockRepository struct {
+    CreateLockFunc       func(ctx context.Context, lock *models.InventoryLock) error
+    GetLocksByCartIDFunc func(ctx context.Context, cartID string) ([]*models.InventoryLock, error)
+    ReleaseLockFunc      func(ctx context.Context, reservationID string) error
+    ReleaseCartLocksFunc func(ctx context.Context, cartID string) error
+    ExpireLocksFunc      func(ctx context.Context) (int64, error)
+}
+
+func (m *MockInventoryLockRepository) CreateLock(ctx context.Context, lock *models.InventoryLock) error {
+    if m.CreateLockFunc != nil {
+        return m.CreateLockFunc(ctx, lock)
+    }
+    return nil
+}
+
+func (m *MockInventoryLockRepository) GetLocksByCartID(ctx context.Context, cartID string) ([]*models.InventoryLock, error) {
+    if m.GetLocksByCartIDFunc != nil {
+        return m.GetLocksByCartIDFunc(ctx, cartID)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryLockRepository) ReleaseLock(ctx context.Context, reservationID string) error {
+    if m.ReleaseLockFunc != nil {
+        return m.ReleaseLockFunc(ctx, reservationID)
+    }
+    return nil
+}
+
+func (m *MockInventoryLockRepository) ReleaseCartLocks(ctx context.Context, cartID string) error {
+    if m.ReleaseCartLocksFunc != nil {
+        return m.ReleaseCartLocksFunc(ctx, cartID)
+    }
+    return nil
+}
+
+func (m *MockInventoryLockRepository) ExpireLocks(ctx context.Context) (int64, error) {
+    if m.ExpireLocksFunc != nil {
+        return m.ExpireLocksFunc(ctx)
+    }
+    return 0, nil
+}
+
+// MockCouponRepository is a mock implementation of CouponRepository
+type MockCouponRepository struct {
+    CreateCouponFunc          func(ctx context.Context, coupon *models.Coupon) error
+    GetCouponsFunc            func(ctx context.Context) ([]*models.Coupon, error)
+    GetCouponByCodeFunc       func(ctx context.Context, code string) (*models.Coupon, error)
+    GetUsableCouponFunc       func(ctx context.Context, code string) (*models.Coupon, error)
+    SetCouponActiveFunc       func(ctx context.Context, code string, active bool) error
+    ApplyCouponFunc           func(ctx context.Context, cartID, userID, code string) (*models.Coupon, error)
+    RemoveCouponFunc          func(ctx context.Context, cartID string) error
+    RedeemCouponFunc          func(ctx context.Context, cartID, correlationID string) error
+    ReleaseCheckoutCouponFunc func(ctx context.Context, correlationID string) (bool, error)
+}
+
+func (m *MockCouponRepository) CreateCoupon(ctx context.Context, coupon *models.Coupon) error {
+    if m.CreateCouponFunc != nil {
+        return m.CreateCouponFunc(ctx, coupon)
+    }
+    return nil
+}
+
+func (m *MockCouponRepository) GetCoupons(ctx context.Context) ([]*models.Coupon, error) {
+    if m.GetCouponsFunc != nil {
+        return m.GetCouponsFunc(ctx)
+    }
+    return nil, nil
+}
+
+func (m *MockCouponRepository) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+    if m.GetCouponByCodeFunc != nil {
+        return m.GetCouponByCodeFunc(ctx, code)
+    }
+    return nil, errors.New("coupon not found")
+}
+
+func (m *MockCouponRepository) GetUsableCoupon(ctx context.Context, code string) (*models.Coupon, error) {
+    if m.GetUsableCouponFunc != nil {
+        return m.GetUsableCouponFunc(ctx, code)
+    }
+    return nil, errors.New("coupon not found")
+}
+
+func (m *MockCouponRepository) SetCouponActive(ctx context.Context, code string, active bool) error {
+    if m.SetCouponActiveFunc != nil {
+        return m.SetCouponActiveFunc(ctx, code, active)
+    }
+    return nil
+}
+
+func (m *MockCouponRepository) ApplyCoupon(ctx context.Context, cartID, userID, code string) (*models.Coupon, error) {
+    if m.ApplyCouponFunc != nil {
+        return m.ApplyCouponFunc(ctx, cartID, userID, code)
+    }
+    return nil, errors.New("coupon not found")
+}
+
+func (m *MockCouponRepository) RemoveCoupon(ctx context.Context, cartID string) error {
+    if m.RemoveCouponFunc != nil {
+        return m.RemoveCouponFunc(ctx, cartID)
+    }
+    return nil
+}
+
+func (m *MockCouponRepository) RedeemCoupon(ctx context.Context, cartID, correlationID string) error {
+    if m.RedeemCouponFunc != nil {
+        return m.RedeemCouponFunc(ctx, cartID, correlationID)
+    }
+    return nil
+}
+
+func (m *MockCouponRepository) ReleaseCheckoutCoupon(ctx context.Context, correlationID string) (bool, error) {
+    if m.ReleaseCheckoutCouponFunc != nil {
+        return m.ReleaseCheckoutCouponFunc(ctx, correlationID)
+    }
+    return false, nil
+}
+
+// MockPublisher records the events it's asked to publish
+type MockPublisher struct {
+    Events []interface{}
+    Err    error
+}
+
+func (m *MockPublisher) PublishCartEvent(ctx context.Context, event interface{}) error {
+    m.Events = append(m.Events, event)
+    return m.Err
+}
+
+// MockAddresses returns one saved address for every user
+type MockAddresses struct {
+    Address *sharedModels.Address
+    Err     error
+}
+
+func (m *MockAddresses) Get(ctx context.Context, authorization, userID, addressID string) (*sharedModels.Address, error) {
+    return m.Address, m.Err
+}
+
+// serve runs one request through handler with user_id set as the auth middleware would, and
+// returns the recorded response
+func serve(t *testing.T, handler gin.HandlerFunc, method, path, userID string, body interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    return serveWithHeader(t, handler, method, path, userID, body, nil)
+}
+
+// serveWithHeader is serve with extra request headers
+func serveWithHeader(t *testing.T, handler gin.HandlerFunc, method, path, userID string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    var payload bytes.Buffer
+    if body != nil {
+        if err := json.NewEncoder(&payload).Encode(body); err != nil {
+            t.Fatalf("encode body: %v", err)
+        }
+    }
+
+    router := gin.New()
+    router.Handle(method, path, func(c *gin.Context) {
+        if userID != "" {
+            c.Set("user_id", userID)
+        }
+        handler(c)
+    })
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(method, path, &payload)
+    req.Header.Set("Content-Type", "application/json")
+    for name, values := range header {
+        req.Header[name] = values
+    }
+    router.ServeHTTP(rec, req)
+    return rec
+}
+
+// serveRoute serves target on the route pattern, as userID with role
+func serveRoute(t *testing.T, handler gin.HandlerFunc, method, pattern, target, userID, role string) *httptest.ResponseRecorder {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    router := gin.New()
+    router.Handle(method, pattern, func(c *gin.Context) {
+        c.Set("user_id", userID)
+        c.Set("role", role)
+        handler(c)
+    })
+
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+    return rec
+}
+
+// testCart is an active cart of userID holding items
+func testCart(userID string, items ...models.CartItem) *models.Cart {
+    cart := models.NewCart(userID)
+    cart.Items = items
+    for _, item := range items {
+        cart.Subtotal += item.Price * float64(item.Quantity)
+    }
+    cart.Total = cart.Subtotal
+    return cart
+}
@@ -0,0 +1,132 @@
+// Package inventory soft-locks stock in the products service for lines added to a cart.
+package inventory
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "time"
+)
+
+// ShortageError is returned by Lock when the products service doesn't have the quantity
+type ShortageError struct {
+    ProductID int64
+    Requested int
+    Available int
+}
+
+func (e *ShortageError) Error() string {
+    return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d", e.ProductID, e.Requested, e.Available)
+}
+
+// Lock is a soft lock held by the products service for a cart line
+type Lock struct {
+    ReservationID string    `json:"reservation_id"`
+    ProductID     int64     `json:"product_id"`
+    VariantID     *int64    `json:"variant_id,omitempty"`
+    Quantity      int       `json:"quantity"`
+    ExpiresAt     time.Time `json:"expires_at"`
+    TTLSeconds    int       `json:"ttl_seconds"`
+}
+
+// Locker holds and gives back soft locks on cart lines
+type Locker interface {
+    // Lock holds quantity (the line's whole quantity) for the cart, replacing its earlier lock on the line
+    Lock(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int) (*Lock, error)
+    Release(ctx context.Context, cartID, reservationID string) error
+    ReleaseCart(ctx context.Context, cartID string) error
+}
+
+// Client takes soft locks through the products service HTTP API
+type Client struct {
+    baseURL    string
+    ttl        time.Duration
+    httpClient *http.Client
+}
+
+// NewClient creates new products service soft lock client; locks are asked for ttl
+func NewClient(baseURL string, ttl, timeout time.Duration) *Client {
+    return &Client{
+        baseURL:    baseURL,
+        ttl:        ttl,
+        httpClient: &http.Client{Timeout: timeout},
+    }
+}
+
+// Lock calls POST /inventory/cart-locks. The products service may cap the TTL, so the lock's
+// own ExpiresAt and TTLSeconds are what the customer gets.
+func (c *Client) Lock(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int) (*Lock, error) {
+    body, err := json.Marshal(map[string]interface{}{
+        "cart_id":     cartID,
+        "product_id":  productID,
+        "variant_id":  variantID,
+        "quantity":    quantity,
+        "ttl_seconds": int(c.ttl.Seconds()),
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode lock request: %w", err)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/inventory/cart-locks", bytes.NewReader(body))
+    if err != nil {
+        return nil, fmt.Errorf("failed to build lock request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock stock of product %d: %w", productID, err)
+    }
+    defer resp.Body.Close()
+
+    switch resp.StatusCode {
+    case http.StatusCreated:
+        lock := &Lock{}
+        if err := json.NewDecoder(resp.Body).Decode(lock); err != nil {
+            return nil, fmt.Errorf("failed to decode lock of product %d: %w", productID, err)
+        }
+        return lock, nil
+    case http.StatusConflict:
+        var shortage struct {
+            Available int `json:"available"`
+        }
+        if err := json.NewDecoder(resp.Body).Decode(&shortage); err != nil {
+            return nil, fmt.Errorf("failed to decode shortage of product %d: %w", productID, err)
+        }
+        return nil, &ShortageError{ProductID: productID, Requested: quantity, Available: shortage.Available}
+    default:
+        return nil, fmt.Errorf("products service returned %d locking product %d", resp.StatusCode, productID)
+    }
+}
+
+// Release calls DELETE /inventory/cart-locks/:reservation_id
+func (c *Client) Release(ctx context.Context, cartID, reservationID string) error {
+    endpoint := fmt.Sprintf("%s/inventory/cart-locks/%s?cart_id=%s", c.baseURL, url.PathEscape(reservationID), url.QueryEscape(cartID))
+    return c.delete(ctx, endpoint)
+}
+
+// ReleaseCart calls DELETE /inventory/carts/:cart_id/locks
+func (c *Client) ReleaseCart(ctx context.Context, cartID string) error {
+    return c.delete(ctx, fmt.Sprintf("%s/inventory/carts/%s/locks", c.baseURL, url.PathEscape(cartID)))
+}
+
+func (c *Client) delete(ctx context.Context, endpoint string) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+    if err != nil {
+        return fmt.Errorf("failed to build release request: %w", err)
+    }
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("failed to release soft lock: %w", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+        return fmt.Errorf("products service returned %d releasing soft lock", resp.StatusCode)
+    }
+    return nil
+}
@@ -0,0 +1,68 @@
+package inventory
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestLock_ReturnsLock(t *testing.T) {
+    expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost || r.URL.Path != "/inventory/cart-locks" {
+            t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+        }
+        var req map[string]interface{}
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            t.Errorf("failed to decode request: %v", err)
+        }
+        if req["cart_id"] != "cart-1" || req["quantity"] != float64(3) || req["ttl_seconds"] != float64(600) {
+            t.Errorf("unexpected lock request %v", req)
+        }
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(Lock{ReservationID: "res-1", ProductID: 7, Quantity: 3, ExpiresAt: expires, TTLSeconds: 600})
+    }))
+    defer server.Close()
+
+    lock, err := NewClient(server.URL, 10*time.Minute, time.Second).Lock(context.Background(), "cart-1", 7, nil, 3)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if lock.ReservationID != "res-1" || !lock.ExpiresAt.Equal(expires) || lock.TTLSeconds != 600 {
+        t.Fatalf("unexpected lock %+v", lock)
+    }
+}
+
+func TestLock_Shortage(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusConflict)
+        w.Write([]byte(`{"error":"insufficient stock","available":2}`))
+    }))
+    defer server.Close()
+
+    _, err := NewClient(server.URL, 10*time.Minute, time.Second).Lock(context.Background(), "cart-1", 7, nil, 3)
+    var shortage *ShortageError
+    if !errors.As(err, &shortage) {
+        t.Fatalf("expected a shortage, got %v", err)
+    }
+    if shortage.Available != 2 || shortage.Requested != 3 {
+        t.Fatalf("unexpected shortage %+v", shortage)
+    }
+}
+
+func TestLock_ServiceError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer server.Close()
+
+    _, err := NewClient(server.URL, 10*time.Minute, time.Second).Lock(context.Background(), "cart-1", 7, nil, 3)
+    var shortage *ShortageError
+    if err == nil || errors.As(err, &shortage) {
+        t.Fatalf("expected a non-shortage error, got %v", err)
+    }
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/shared/identity"
+    "github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+// GuestTokenHeader carries the guest token the gateway issues to shoppers who aren't signed in
+const GuestTokenHeader = "X-Guest-Token"
+
+// guestKind tells guest tokens apart from any other token signed with the same secret
+const guestKind = "guest"
+
+// guestClaims are the claims of a gateway guest token; the subject is the guest ID
+type guestClaims struct {
+    Kind string `json:"kind"`
+    jwt.RegisteredClaims
+}
+
+// GuestTokens verifies gateway guest tokens (HS256, GUEST_TOKEN_SECRET)
+type GuestTokens struct {
+    secret []byte
+}
+
+// NewGuestTokens creates a verifier for tokens signed with secret
+func NewGuestTokens(secret string) *GuestTokens {
+    return &GuestTokens{secret: []byte(secret)}
+}
+
+// Verify checks a guest token's signature and expiry and returns its guest ID
+func (gt *GuestTokens) Verify(tokenString string) (string, error) {
+    claims := &guestClaims{}
+    _, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+        return gt.secret, nil
+    }, jwt.WithValidMethods([]string{jwtkeys.HS256}), jwt.WithExpirationRequired())
+    if err != nil {
+        return "", fmt.Errorf("invalid guest token: %w", err)
+    }
+    if claims.Kind != guestKind {
+        return "", errors.New("invalid guest token: not a guest token")
+    }
+    // Why: the guest ID is stored as the cart's user_id, a UUID column
+    if _, err := uuid.Parse(claims.Subject); err != nil {
+        return "", errors.New("invalid guest token: guest ID is not a UUID")
+    }
+    return claims.Subject, nil
+}
+
+// CartAuthMiddleware is identity.Require for the cart routes, which guests may use too. Without
+// an Authorization header, a valid guest token lets the request through as the guest: user_id
+// is the guest ID and guest is set. With both, the user's token decides and the guest ID is
+// set as guest_id, for merging the guest cart at login. guests nil disables guest carts.
+func CartAuthMiddleware(keys *jwtkeys.KeySet, guests *GuestTokens) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if keys == nil {
+            c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+                "error":   "cart endpoints disabled",
+                "message": "JWT keys not configured",
+            })
+            return
+        }
+
+        if token := c.GetHeader(GuestTokenHeader); token != "" && guests != nil {
+            guestID, err := guests.Verify(token)
+            if err != nil {
+                c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+                    "error": "invalid guest token",
+                })
+                return
+            }
+            c.Set("guest_id", guestID)
+
+            if c.GetHeader("Authorization") == "" {
+                c.Set("user_id", guestID)
+                c.Set("guest", true)
+                c.Next()
+                return
+            }
+        }
+
+        claims, err := identity.FromRequest(c.Request, keys)
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+                "error": err.Error(),
+            })
+            return
+        }
+
+        c.Set(identity.UserIDKey, claims.UserID)
+        c.Set(identity.RoleKey, claims.Role)
+        c.Next()
+    }
+}
+
+// RequireUser refuses guests with 403 on the cart routes that need an account
+func RequireUser() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if c.GetBool("guest") {
+            c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+                "error":   "sign in required",
+                "message": "guests can't use this endpoint; sign in and merge the guest cart first",
+            })
+            return
+        }
+        c.Next()
+    }
+}
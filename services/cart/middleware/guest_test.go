@@ -0,0 +1,131 @@
+package middleware
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/sanketh-sg/prost/shared/identity"
+    "github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+const (
+    testGuestSecret = "guest-secret"
+    testGuestID     = "3f1c2b9e-5d7a-4c1e-9b2f-8a6d4e0c1f23"
+)
+
+func guestToken(t *testing.T, secret, kind, subject string, expiresAt time.Time) string {
+    t.Helper()
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, guestClaims{
+        Kind:             kind,
+        RegisteredClaims: jwt.RegisteredClaims{Subject: subject, ExpiresAt: jwt.NewNumericDate(expiresAt)},
+    })
+    signed, err := token.SignedString([]byte(secret))
+    if err != nil {
+        t.Fatalf("sign guest token: %v", err)
+    }
+    return signed
+}
+
+func TestGuestTokensVerify(t *testing.T) {
+    guests := NewGuestTokens(testGuestSecret)
+    later := time.Now().Add(time.Hour)
+
+    guestID, err := guests.Verify(guestToken(t, testGuestSecret, guestKind, testGuestID, later))
+    if err != nil || guestID != testGuestID {
+        t.Fatalf("Verify = %q, %v; want %q", guestID, err, testGuestID)
+    }
+
+    rejected := map[string]string{
+        "wrong secret": guestToken(t, "other-secret", guestKind, testGuestID, later),
+        "expired":      guestToken(t, testGuestSecret, guestKind, testGuestID, time.Now().Add(-time.Minute)),
+        "not a guest":  guestToken(t, testGuestSecret, "", testGuestID, later),
+        "not a UUID":   guestToken(t, testGuestSecret, guestKind, "alice", later),
+        "garbage":      "not-a-token",
+    }
+    for name, token := range rejected {
+        if _, err := guests.Verify(token); err == nil {
+            t.Errorf("Verify accepted a token that is %s", name)
+        }
+    }
+}
+
+// serveCart runs a request through CartAuthMiddleware and RequireUser (when requireUser is set)
+// and returns the status and what the handler saw
+func serveCart(t *testing.T, headers map[string]string, requireUser bool) (int, map[string]string) {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    seen := map[string]string{}
+    handlers := []gin.HandlerFunc{CartAuthMiddleware(jwtkeys.NewHMACKeySet("jwt-secret"), NewGuestTokens(testGuestSecret))}
+    if requireUser {
+        handlers = append(handlers, RequireUser())
+    }
+    handlers = append(handlers, func(c *gin.Context) {
+        seen["user_id"] = c.GetString("user_id")
+        seen["guest_id"] = c.GetString("guest_id")
+        if c.GetBool("guest") {
+            seen["guest"] = "true"
+        }
+        c.Status(http.StatusOK)
+    })
+
+    router := gin.New()
+    router.GET("/carts", handlers...)
+    req := httptest.NewRequest(http.MethodGet, "/carts", nil)
+    for key, value := range headers {
+        req.Header.Set(key, value)
+    }
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w.Code, seen
+}
+
+func TestCartAuthMiddlewareGuest(t *testing.T) {
+    token := guestToken(t, testGuestSecret, guestKind, testGuestID, time.Now().Add(time.Hour))
+
+    code, seen := serveCart(t, map[string]string{GuestTokenHeader: token}, false)
+    if code != http.StatusOK {
+        t.Fatalf("guest request status = %d, want 200", code)
+    }
+    if seen["user_id"] != testGuestID || seen["guest"] != "true" {
+        t.Errorf("guest request set %v, want the guest ID as user_id and guest", seen)
+    }
+
+    if code, _ := serveCart(t, map[string]string{GuestTokenHeader: token}, true); code != http.StatusForbidden {
+        t.Errorf("guest request to a sign-in route status = %d, want 403", code)
+    }
+
+    forged := guestToken(t, "other-secret", guestKind, testGuestID, time.Now().Add(time.Hour))
+    if code, _ := serveCart(t, map[string]string{GuestTokenHeader: forged}, false); code != http.StatusUnauthorized {
+        t.Errorf("forged guest token status = %d, want 401", code)
+    }
+
+    if code, _ := serveCart(t, nil, false); code != http.StatusUnauthorized {
+        t.Errorf("request without tokens status = %d, want 401", code)
+    }
+}
+
+func TestCartAuthMiddlewareUserWithGuestToken(t *testing.T) {
+    keys := jwtkeys.NewHMACKeySet("jwt-secret")
+    userToken, err := keys.Sign(identity.Claims{
+        UserID:           "user-1",
+        Role:             "customer",
+        RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+    })
+    if err != nil {
+        t.Fatalf("sign user token: %v", err)
+    }
+    guest := guestToken(t, testGuestSecret, guestKind, testGuestID, time.Now().Add(time.Hour))
+
+    code, seen := serveCart(t, map[string]string{"Authorization": "Bearer " + userToken, GuestTokenHeader: guest}, true)
+    if code != http.StatusOK {
+        t.Fatalf("user request status = %d, want 200", code)
+    }
+    if seen["user_id"] != "user-1" || seen["guest_id"] != testGuestID || seen["guest"] != "" {
+        t.Errorf("user request set %v, want the user's ID with the guest ID beside it", seen)
+    }
+}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS cart.idx_carts_one_active_per_user;
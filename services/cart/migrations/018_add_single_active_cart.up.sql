@@ -0,0 +1,13 @@
+-- At most one active cart per user, so get-or-create can't race into two
+-- Older duplicates (from concurrent first adds) are abandoned before the index is built
+UPDATE cart.carts c
+SET status = 'abandoned', abandoned_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE c.status = 'active'
+  AND EXISTS (
+      SELECT 1 FROM cart.carts newer
+      WHERE newer.user_id = c.user_id
+        AND newer.status = 'active'
+        AND (newer.created_at, newer.id) > (c.created_at, c.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_one_active_per_user ON cart.carts (user_id) WHERE status = 'active';
@@ -0,0 +1,2 @@
+ALTER TABLE cart.cart_items
+    DROP COLUMN IF EXISTS variant_id;
@@ -0,0 +1,2 @@
+ALTER TABLE cart.cart_items
+    ADD COLUMN IF NOT EXISTS variant_id BIGINT NULL;
@@ -0,0 +1 @@
+ALTER TABLE cart.inventory_locks DROP COLUMN IF EXISTS variant_id;
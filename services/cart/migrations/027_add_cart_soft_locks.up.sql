@@ -0,0 +1,2 @@
+-- The cart's copy of its soft locks, one per product or variant line
+ALTER TABLE cart.inventory_locks ADD COLUMN IF NOT EXISTS variant_id BIGINT NULL;
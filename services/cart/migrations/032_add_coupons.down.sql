@@ -0,0 +1,4 @@
+ALTER TABLE cart.carts DROP COLUMN IF EXISTS discount;
+ALTER TABLE cart.carts DROP COLUMN IF EXISTS coupon_code;
+DROP TABLE IF EXISTS cart.coupon_redemptions;
+DROP TABLE IF EXISTS cart.coupons;
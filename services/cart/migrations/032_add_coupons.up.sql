@@ -0,0 +1,37 @@
+-- Coupon codes customers apply to their cart (POST /carts/apply-coupon). A percent coupon
+-- takes amount percent off the cart's subtotal, a fixed one takes amount off, never more than
+-- the subtotal. NULL limits and expiry mean unlimited.
+CREATE TABLE IF NOT EXISTS cart.coupons (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE, -- upper case
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    expires_at TIMESTAMP NULL,
+    max_redemptions INT NULL CHECK (max_redemptions > 0),
+    max_per_user INT NULL CHECK (max_per_user > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type = 'fixed' OR amount <= 100)
+);
+
+-- One row per use of a coupon: applied while it sits on a cart, redeemed once the cart checks
+-- out, released when it's removed, the cart is deleted or the checkout fails. Applied and
+-- redeemed rows count against the coupon's limits.
+CREATE TABLE IF NOT EXISTS cart.coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id BIGINT NOT NULL REFERENCES cart.coupons(id),
+    cart_id UUID NOT NULL REFERENCES cart.carts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'applied' CHECK (status IN ('applied', 'redeemed', 'released')),
+    correlation_id UUID NULL, -- checkout saga that redeemed it
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coupon_redemptions_one_per_cart ON cart.coupon_redemptions(cart_id) WHERE status = 'applied';
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_coupon_user ON cart.coupon_redemptions(coupon_id, user_id) WHERE status <> 'released';
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_correlation_id ON cart.coupon_redemptions(correlation_id) WHERE correlation_id IS NOT NULL;
+
+-- The applied coupon and its discount; total is the item subtotal minus discount
+ALTER TABLE cart.carts ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(50) NULL;
+ALTER TABLE cart.carts ADD COLUMN IF NOT EXISTS discount DECIMAL(12, 2) NOT NULL DEFAULT 0.00;
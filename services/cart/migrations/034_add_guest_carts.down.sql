@@ -0,0 +1,2 @@
+ALTER TABLE cart.carts DROP COLUMN IF EXISTS merged_into;
+ALTER TABLE cart.carts DROP COLUMN IF EXISTS guest;
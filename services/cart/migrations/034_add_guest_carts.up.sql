@@ -0,0 +1,4 @@
+-- Guest carts: user_id holds the guest ID from the gateway's guest token until the cart is
+-- merged into the shopper's own cart at login
+ALTER TABLE cart.carts ADD COLUMN IF NOT EXISTS guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE cart.carts ADD COLUMN IF NOT EXISTS merged_into UUID NULL REFERENCES cart.carts(id);
@@ -0,0 +1 @@
+ALTER TABLE cart.carts DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every change to a cart, its lines or its coupon bumps the version.
+-- Writes that race (adding or removing a line, checking out) only apply to the version they
+-- read, and /carts exposes it as the ETag.
+ALTER TABLE cart.carts ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -9,10 +9,15 @@ import (
 // Cart represents a shopping cart
 type Cart struct {
     ID          string      `json:"id"`
-    UserID      string      `json:"user_id"`
+    UserID      string      `json:"user_id"` // the guest ID for guest carts
+    Guest       bool        `json:"guest,omitempty"`
     Items       []CartItem  `json:"items"`
-    Total       float64     `json:"total"`
-    Status      string      `json:"status"` // active, checked_out, abandoned
+    Subtotal    float64     `json:"subtotal"` // items before the coupon
+    CouponCode  *string     `json:"coupon_code,omitempty"`
+    Discount    float64     `json:"discount"`
+    Total       float64     `json:"total"` // subtotal minus discount
+    Status      string      `json:"status"` // active, checked_out, abandoned, merged
+    Version     int         `json:"version"` // bumped by every change; the ETag of /carts
     CreatedAt   time.Time   `json:"created_at"`
     UpdatedAt   time.Time   `json:"updated_at"`
     AbandonedAt *time.Time  `json:"abandoned_at,omitempty"`
@@ -23,6 +28,7 @@ type CartItem struct {
     ID        string    `json:"id"`
     CartID    string    `json:"cart_id"`
     ProductID int64     `json:"product_id"`
+    VariantID *int64    `json:"variant_id,omitempty"` // nil for products without variants
     Quantity  int       `json:"quantity"`
     Price     float64   `json:"price"` // Price snapshot at time of adding
     CreatedAt time.Time `json:"created_at"`
@@ -34,6 +40,7 @@ type InventoryLock struct {
     ID            string     `json:"id"`
     CartID        string     `json:"cart_id"`
     ProductID     int64      `json:"product_id"`
+    VariantID     *int64     `json:"variant_id,omitempty"`
     Quantity      int        `json:"quantity"`
     ReservationID string     `json:"reservation_id"`
     Status        string     `json:"status"` // locked, released, expired
@@ -65,8 +72,9 @@ type CreateCartRequest struct {
 // AddItemRequest request to add item to cart
 type AddItemRequest struct {
     ProductID int64   `json:"product_id" binding:"required"`
+    VariantID *int64  `json:"variant_id"`
     Quantity  int     `json:"quantity" binding:"required,gt=0"`
-    Price     float64 `json:"price" binding:"required,gt=0"`
+    Price     float64 `json:"price" binding:"omitempty,gt=0"` // optional; must match the catalog when sent
 }
 
 // RemoveItemRequest request to remove item from cart
@@ -79,9 +87,63 @@ type UpdateCartRequest struct {
     Status string `json:"status"`
 }
 
-// CheckoutRequest request to checkout cart
+// CheckoutRequest request to checkout cart; address_id is one of the user's saved addresses
 type CheckoutRequest struct {
-    OrderID int64  `json:"order_id" binding:"required"`
+    AddressID string `json:"address_id" binding:"required,max=64"`
+    OrderID   int64  `json:"order_id"` // optional, recorded on the saga only; the orders service assigns order IDs
+}
+
+// PriceChange is a cart item whose catalog price moved since it was added
+type PriceChange struct {
+    ProductID int64   `json:"product_id"`
+    VariantID *int64  `json:"variant_id,omitempty"`
+    Quantity  int     `json:"quantity"`
+    OldPrice  float64 `json:"old_price"` // snapshot in the cart
+    NewPrice  float64 `json:"new_price"` // current catalog price
+}
+
+// PriceValidation is the result of re-checking a cart's prices at checkout
+type PriceValidation struct {
+    Changes     []PriceChange `json:"changes"`
+    Unavailable []int64       `json:"unavailable"` // products removed from the catalog
+    OldTotal    float64       `json:"old_total"`
+    NewTotal    float64       `json:"new_total"`
+}
+
+// OK reports whether the cart can check out at its stored prices
+func (pv *PriceValidation) OK() bool {
+    return len(pv.Changes) == 0 && len(pv.Unavailable) == 0
+}
+
+// PriceChangedResponse is returned with 409 when checkout finds stale prices.
+// The cart has already been repriced; the client confirms and checks out again.
+type PriceChangedResponse struct {
+    Error   string `json:"error"`
+    Message string `json:"message"`
+    Code    int    `json:"code"`
+    PriceValidation
+}
+
+// StockShortageResponse is returned with 409 when add-to-cart can't soft-lock the line's stock.
+// Available is what the whole line can have, including what the cart already holds.
+type StockShortageResponse struct {
+    Error     string `json:"error"`
+    Message   string `json:"message"`
+    Code      int    `json:"code"`
+    ProductID int64  `json:"product_id"`
+    Available int    `json:"available"`
+}
+
+// PriceMismatchResponse is returned with 409 when add-to-cart was sent a price that isn't the
+// catalog's; the item is not added
+type PriceMismatchResponse struct {
+    Error        string  `json:"error"`
+    Message      string  `json:"message"`
+    Code         int     `json:"code"`
+    ProductID    int64   `json:"product_id"`
+    VariantID    *int64  `json:"variant_id,omitempty"`
+    Price        float64 `json:"price"`
+    CatalogPrice float64 `json:"catalog_price"`
 }
 
 // ErrorResponse standard error response
@@ -91,6 +153,24 @@ type ErrorResponse struct {
     Code    int    `json:"code"`
 }
 
+// LineQuantity is the quantity of a product (or one of its variants) across the cart's items
+func (c *Cart) LineQuantity(productID int64, variantID *int64) int {
+    quantity := 0
+    for _, item := range c.Items {
+        if item.ProductID == productID && sameVariant(item.VariantID, variantID) {
+            quantity += item.Quantity
+        }
+    }
+    return quantity
+}
+
+func sameVariant(a, b *int64) bool {
+    if a == nil || b == nil {
+        return a == nil && b == nil
+    }
+    return *a == *b
+}
+
 // NewCart creates new cart
 func NewCart(userID string) *Cart {
     now := time.Now().UTC()
@@ -100,18 +180,20 @@ func NewCart(userID string) *Cart {
         Items:     []CartItem{},
         Total:     0.00,
         Status:    "active",
+        Version:   1,
         CreatedAt: now,
         UpdatedAt: now,
     }
 }
 
 // NewCartItem creates new cart item
-func NewCartItem(cartID string, productID int64, quantity int, price float64) *CartItem {
+func NewCartItem(cartID string, productID int64, variantID *int64, quantity int, price float64) *CartItem {
     now := time.Now().UTC()
     return &CartItem{
         ID:        uuid.New().String(),
         CartID:    cartID,
         ProductID: productID,
+        VariantID: variantID,
         Quantity:  quantity,
         Price:     price,
         CreatedAt: now,
@@ -134,6 +216,11 @@ func NewInventoryLock(cartID string, productID int64, quantity int, reservationI
     }
 }
 
+// IsExpired reports whether a still-held lock has passed its expiry at the given time
+func (l *InventoryLock) IsExpired(now time.Time) bool {
+    return l.Status == "locked" && now.After(l.ExpiresAt)
+}
+
 // NewSagaState creates new saga state
 func NewSagaState(cartID, userID, correlationID string) *SagaState {
     now := time.Now().UTC()
@@ -150,4 +237,11 @@ func NewSagaState(cartID, userID, correlationID string) *SagaState {
         UpdatedAt:       now,
         ExpiresAt:       now.Add(24 * time.Hour),
     }
-}
\ No newline at end of file
+}
+// CartFunnel counts the cart side of the conversion funnel over a window
+type CartFunnel struct {
+    From               time.Time `json:"from"`
+    To                 time.Time `json:"to"`
+    CartsCreated       int       `json:"carts_created"`
+    CheckoutsInitiated int       `json:"checkouts_initiated"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+    "errors"
+    "math"
+    "strings"
+    "time"
+)
+
+// Coupon discount types
+const (
+    DiscountPercent = "percent"
+    DiscountFixed   = "fixed"
+)
+
+// Coupon redemption statuses
+const (
+    RedemptionApplied  = "applied"  // on a cart
+    RedemptionRedeemed = "redeemed" // the cart checked out
+    RedemptionReleased = "released" // removed, cart deleted or checkout failed
+)
+
+// ErrCouponExpired is returned for a coupon past its expiry or switched off
+var ErrCouponExpired = errors.New("coupon expired")
+
+// Coupon is a code that takes money off a cart
+type Coupon struct {
+    ID             int64      `json:"id"`
+    Code           string     `json:"code"`
+    DiscountType   string     `json:"discount_type"` // percent, fixed
+    Amount         float64    `json:"amount"`        // percent off, or money off
+    ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+    MaxRedemptions *int       `json:"max_redemptions,omitempty"` // nil is unlimited
+    MaxPerUser     *int       `json:"max_per_user,omitempty"`    // nil is unlimited
+    Active         bool       `json:"active"`
+    Redemptions    int        `json:"redemptions"` // applied and redeemed uses
+    CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreateCouponRequest request to create a coupon
+type CreateCouponRequest struct {
+    Code           string     `json:"code" binding:"required,max=50"`
+    DiscountType   string     `json:"discount_type" binding:"required,oneof=percent fixed"`
+    Amount         float64    `json:"amount" binding:"required,gt=0"`
+    ExpiresAt      *time.Time `json:"expires_at"`
+    MaxRedemptions *int       `json:"max_redemptions" binding:"omitempty,gt=0"`
+    MaxPerUser     *int       `json:"max_per_user" binding:"omitempty,gt=0"`
+}
+
+// ApplyCouponRequest request to apply a coupon to the caller's cart
+type ApplyCouponRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+// NormalizeCouponCode is the stored form of a code: codes aren't case sensitive
+func NormalizeCouponCode(code string) string {
+    return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NewCoupon creates new coupon from a create request
+func NewCoupon(req CreateCouponRequest) *Coupon {
+    return &Coupon{
+        Code:           NormalizeCouponCode(req.Code),
+        DiscountType:   req.DiscountType,
+        Amount:         req.Amount,
+        ExpiresAt:      req.ExpiresAt,
+        MaxRedemptions: req.MaxRedemptions,
+        MaxPerUser:     req.MaxPerUser,
+        Active:         true,
+        CreatedAt:      time.Now().UTC(),
+    }
+}
+
+// Validate checks the coupon's own terms
+func (cp *Coupon) Validate() error {
+    if cp.Code == "" {
+        return errors.New("code must not be blank")
+    }
+    if cp.DiscountType == DiscountPercent && cp.Amount > 100 {
+        return errors.New("a percent discount can't be over 100")
+    }
+    return nil
+}
+
+// Check reports whether the coupon can be used at now
+func (cp *Coupon) Check(now time.Time) error {
+    if !cp.Active || (cp.ExpiresAt != nil && !now.Before(*cp.ExpiresAt)) {
+        return ErrCouponExpired
+    }
+    return nil
+}
+
+// Discount is what the coupon takes off a subtotal, in whole cents and never more than it
+func (cp *Coupon) Discount(subtotal float64) float64 {
+    subtotalCents := math.Round(subtotal * 100)
+    var cents float64
+    switch cp.DiscountType {
+    case DiscountPercent:
+        cents = math.Round(subtotalCents * cp.Amount / 100)
+    case DiscountFixed:
+        cents = math.Round(cp.Amount * 100)
+    }
+    return math.Min(cents, math.Max(subtotalCents, 0)) / 100
+}
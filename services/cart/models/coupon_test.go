@@ -0,0 +1,47 @@
+package models
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestCouponDiscount(t *testing.T) {
+    tests := []struct {
+        name     string
+        coupon   Coupon
+        subtotal float64
+        want     float64
+    }{
+        {"percent rounds to the cent", Coupon{DiscountType: DiscountPercent, Amount: 15}, 33.33, 5.00},
+        {"fixed under the subtotal", Coupon{DiscountType: DiscountFixed, Amount: 10}, 59.98, 10},
+        {"fixed capped at the subtotal", Coupon{DiscountType: DiscountFixed, Amount: 50}, 29.99, 29.99},
+        {"empty cart", Coupon{DiscountType: DiscountPercent, Amount: 20}, 0, 0},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := tt.coupon.Discount(tt.subtotal); got != tt.want {
+                t.Errorf("Discount(%v) = %v, want %v", tt.subtotal, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestCouponCheck(t *testing.T) {
+    now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+    expiry := now.Add(time.Hour)
+    coupon := Coupon{Active: true, ExpiresAt: &expiry}
+
+    if err := coupon.Check(now); err != nil {
+        t.Fatalf("Check before expiry: %v", err)
+    }
+    if err := coupon.Check(expiry); !errors.Is(err, ErrCouponExpired) {
+        t.Errorf("Check at expiry = %v, want ErrCouponExpired", err)
+    }
+
+    coupon.Active = false
+    if err := coupon.Check(now); !errors.Is(err, ErrCouponExpired) {
+        t.Errorf("Check when inactive = %v, want ErrCouponExpired", err)
+    }
+}
@@ -0,0 +1,40 @@
+package models
+
+// MergedLine is a product (or variant) line of a guest cart, merged into a user's cart
+type MergedLine struct {
+    ProductID int64
+    VariantID *int64
+    Quantity  int     // the user's quantity of the line plus the guest's
+    Price     float64 // the guest's newest price snapshot, until revalidated
+}
+
+// MergeLines sums each line of the guest cart with the same line of the user's cart, in the
+// order the guest added them. Lines only the user has are left out; they don't change.
+func MergeLines(user, guest *Cart) []MergedLine {
+    lines := []MergedLine{}
+    for _, item := range guest.Items {
+        merged := false
+        for i := range lines {
+            if lines[i].ProductID == item.ProductID && sameVariant(lines[i].VariantID, item.VariantID) {
+                lines[i].Quantity += item.Quantity
+                lines[i].Price = item.Price
+                merged = true
+                break
+            }
+        }
+        if !merged {
+            lines = append(lines, MergedLine{
+                ProductID: item.ProductID,
+                VariantID: item.VariantID,
+                Quantity:  user.LineQuantity(item.ProductID, item.VariantID) + item.Quantity,
+                Price:     item.Price,
+            })
+        }
+    }
+    return lines
+}
+
+// Item returns the line as an item of cartID
+func (ml MergedLine) Item(cartID string) *CartItem {
+    return NewCartItem(cartID, ml.ProductID, ml.VariantID, ml.Quantity, ml.Price)
+}
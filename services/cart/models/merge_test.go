@@ -0,0 +1,46 @@
+package models
+
+import "testing"
+
+func TestMergeLines(t *testing.T) {
+    blue, red := int64(3), int64(4)
+    user := &Cart{Items: []CartItem{
+        {ProductID: 1, Quantity: 2, Price: 10},
+        {ProductID: 2, VariantID: &blue, Quantity: 1, Price: 20},
+        {ProductID: 5, Quantity: 1, Price: 7},
+    }}
+    guest := &Cart{Items: []CartItem{
+        {ProductID: 1, Quantity: 1, Price: 9.5},
+        {ProductID: 2, VariantID: &red, Quantity: 2, Price: 22},
+        {ProductID: 2, VariantID: &red, Quantity: 1, Price: 21},
+        {ProductID: 8, Quantity: 4, Price: 3},
+    }}
+
+    lines := MergeLines(user, guest)
+    want := []struct {
+        productID int64
+        variantID *int64
+        quantity  int
+        price     float64
+    }{
+        {1, nil, 3, 9.5},
+        {2, &red, 3, 21},
+        {8, nil, 4, 3},
+    }
+    if len(lines) != len(want) {
+        t.Fatalf("MergeLines returned %d lines, want %d: %+v", len(lines), len(want), lines)
+    }
+    for i, w := range want {
+        got := lines[i]
+        if got.ProductID != w.productID || !sameVariant(got.VariantID, w.variantID) || got.Quantity != w.quantity || got.Price != w.price {
+            t.Errorf("line %d = %+v, want product %d variant %v quantity %d price %v", i, got, w.productID, w.variantID, w.quantity, w.price)
+        }
+    }
+}
+
+func TestMergeLinesEmptyGuestCart(t *testing.T) {
+    user := &Cart{Items: []CartItem{{ProductID: 1, Quantity: 2, Price: 10}}}
+    if lines := MergeLines(user, &Cart{}); len(lines) != 0 {
+        t.Errorf("MergeLines with an empty guest cart = %+v, want none", lines)
+    }
+}
@@ -0,0 +1,130 @@
+// Package pricing prices cart lines from the catalog and re-checks their snapshots at checkout.
+package pricing
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clients"
+)
+
+// ErrProductUnavailable is returned when the product was deleted from the catalog
+var ErrProductUnavailable = errors.New("product unavailable")
+
+// PriceLookup returns the current catalog price of a product, or of one of its variants
+type PriceLookup interface {
+    CurrentPrice(ctx context.Context, productID int64, variantID *int64) (float64, error)
+}
+
+// Client reads current prices from the products service
+type Client struct {
+    products *clients.Products
+}
+
+// NewClient creates new products service price client
+func NewClient(baseURL string, timeout time.Duration) *Client {
+    return &Client{
+        products: clients.NewProducts(clients.Config{BaseURL: baseURL, Timeout: timeout}),
+    }
+}
+
+// CurrentPrice calls GET /products/:id on the products service. A variant sells at its own
+// price; a variant that is gone is unavailable like a deleted product.
+func (c *Client) CurrentPrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+    product, err := c.products.GetProduct(ctx, productID)
+    if clients.IsNotFound(err) {
+        return 0, ErrProductUnavailable
+    }
+    if err != nil {
+        return 0, fmt.Errorf("failed to fetch price for product %d: %w", productID, err)
+    }
+
+    if variantID == nil {
+        return product.Price, nil
+    }
+    if variant := product.Variant(*variantID); variant != nil {
+        return variant.Price, nil
+    }
+    return 0, ErrProductUnavailable
+}
+
+// PriceMismatchError is returned by Authoritative when the client sent a price that isn't the catalog's
+type PriceMismatchError struct {
+    ProductID    int64
+    VariantID    *int64
+    ClientPrice  float64
+    CatalogPrice float64
+}
+
+func (e *PriceMismatchError) Error() string {
+    return fmt.Sprintf("price %.2f for product %d doesn't match the catalog price %.2f", e.ClientPrice, e.ProductID, e.CatalogPrice)
+}
+
+// Authoritative returns the catalog price a cart line is stored at. clientPrice is what the
+// client sent, 0 when it sent none; a different price is a *PriceMismatchError.
+// Why: the cart total is built from stored line prices, so they must never come from the client
+func Authoritative(ctx context.Context, lookup PriceLookup, productID int64, variantID *int64, clientPrice float64) (float64, error) {
+    current, err := lookup.CurrentPrice(ctx, productID, variantID)
+    if err != nil {
+        return 0, err
+    }
+    if clientPrice != 0 && toCents(clientPrice) != toCents(current) {
+        return 0, &PriceMismatchError{
+            ProductID:    productID,
+            VariantID:    variantID,
+            ClientPrice:  clientPrice,
+            CatalogPrice: current,
+        }
+    }
+    return current, nil
+}
+
+// Validate compares each item's price snapshot with the current catalog price.
+// Lookup failures other than ErrProductUnavailable abort validation.
+func Validate(ctx context.Context, lookup PriceLookup, items []models.CartItem) (*models.PriceValidation, error) {
+    result := &models.PriceValidation{
+        Changes:     []models.PriceChange{},
+        Unavailable: []int64{},
+    }
+
+    for _, item := range items {
+        current, err := lookup.CurrentPrice(ctx, item.ProductID, item.VariantID)
+        if errors.Is(err, ErrProductUnavailable) {
+            result.Unavailable = append(result.Unavailable, item.ProductID)
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+
+        result.OldTotal += item.Price * float64(item.Quantity)
+        result.NewTotal += current * float64(item.Quantity)
+
+        if toCents(current) != toCents(item.Price) {
+            result.Changes = append(result.Changes, models.PriceChange{
+                ProductID: item.ProductID,
+                VariantID: item.VariantID,
+                Quantity:  item.Quantity,
+                OldPrice:  item.Price,
+                NewPrice:  current,
+            })
+        }
+    }
+
+    result.OldTotal = roundCents(result.OldTotal)
+    result.NewTotal = roundCents(result.NewTotal)
+    return result, nil
+}
+
+// Why: prices are DECIMAL(10,2); compare in cents so float noise isn't a "change"
+func toCents(price float64) int64 {
+    return int64(math.Round(price * 100))
+}
+
+func roundCents(amount float64) float64 {
+    return float64(toCents(amount)) / 100
+}
@@ -0,0 +1,114 @@
+package pricing
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+)
+
+type fakeLookup map[int64]float64
+
+func (fl fakeLookup) CurrentPrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+    price, ok := fl[productID]
+    if !ok {
+        return 0, ErrProductUnavailable
+    }
+    return price, nil
+}
+
+func TestValidate_DetectsChangesAndUnavailable(t *testing.T) {
+    items := []models.CartItem{
+        {ProductID: 1, Quantity: 2, Price: 10.00},
+        {ProductID: 2, Quantity: 1, Price: 5.10}, // float noise must not count as a change
+        {ProductID: 3, Quantity: 1, Price: 7.00},
+    }
+    lookup := fakeLookup{1: 12.50, 2: 5.1}
+
+    got, err := Validate(context.Background(), lookup, items)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if got.OK() {
+        t.Fatal("expected validation to fail")
+    }
+    if len(got.Changes) != 1 || got.Changes[0].ProductID != 1 || got.Changes[0].NewPrice != 12.50 {
+        t.Fatalf("expected one change for product 1, got %+v", got.Changes)
+    }
+    if len(got.Unavailable) != 1 || got.Unavailable[0] != 3 {
+        t.Fatalf("expected product 3 unavailable, got %v", got.Unavailable)
+    }
+    if got.OldTotal != 25.10 || got.NewTotal != 30.10 {
+        t.Fatalf("expected totals 25.10 -> 30.10, got %.2f -> %.2f", got.OldTotal, got.NewTotal)
+    }
+}
+
+type failingLookup struct{}
+
+func (failingLookup) CurrentPrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+    return 0, errors.New("connection refused")
+}
+
+func TestValidate_AbortsOnLookupFailure(t *testing.T) {
+    _, err := Validate(context.Background(), failingLookup{}, []models.CartItem{{ProductID: 1, Quantity: 1, Price: 1}})
+    if err == nil {
+        t.Fatal("expected lookup failure to abort validation")
+    }
+}
+
+type variantLookup map[int64]float64
+
+func (vl variantLookup) CurrentPrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+    if variantID == nil {
+        return 10.00, nil
+    }
+    price, ok := vl[*variantID]
+    if !ok {
+        return 0, ErrProductUnavailable
+    }
+    return price, nil
+}
+
+func TestValidate_PricesVariantsSeparately(t *testing.T) {
+    small, large := int64(11), int64(12)
+    items := []models.CartItem{
+        {ProductID: 1, Quantity: 1, Price: 10.00},
+        {ProductID: 1, VariantID: &small, Quantity: 1, Price: 10.00},
+        {ProductID: 1, VariantID: &large, Quantity: 1, Price: 12.00},
+    }
+
+    got, err := Validate(context.Background(), variantLookup{11: 10.00, 12: 14.00}, items)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if len(got.Changes) != 1 || got.Changes[0].VariantID == nil || *got.Changes[0].VariantID != large {
+        t.Fatalf("expected one change for variant 12, got %+v", got.Changes)
+    }
+}
+
+func TestAuthoritative_UsesCatalogPrice(t *testing.T) {
+    lookup := fakeLookup{1: 12.50}
+
+    price, err := Authoritative(context.Background(), lookup, 1, nil, 0)
+    if err != nil || price != 12.50 {
+        t.Fatalf("without a client price: got %.2f, %v", price, err)
+    }
+
+    price, err = Authoritative(context.Background(), lookup, 1, nil, 12.5000001)
+    if err != nil || price != 12.50 {
+        t.Fatalf("with a matching client price: got %.2f, %v", price, err)
+    }
+
+    _, err = Authoritative(context.Background(), lookup, 1, nil, 9.99)
+    var mismatch *PriceMismatchError
+    if !errors.As(err, &mismatch) || mismatch.ClientPrice != 9.99 || mismatch.CatalogPrice != 12.50 {
+        t.Fatalf("expected a price mismatch, got %v", err)
+    }
+
+    if _, err := Authoritative(context.Background(), lookup, 2, nil, 0); !errors.Is(err, ErrProductUnavailable) {
+        t.Fatalf("expected product 2 unavailable, got %v", err)
+    }
+}
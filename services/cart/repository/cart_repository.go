@@ -2,42 +2,51 @@ package repository
 
 import (
     "context"
+    "database/sql"
+    "errors"
     "fmt"
     "log"
-    "time"
+    "math"
 
     "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clock"
     "github.com/sanketh-sg/prost/shared/db"
 )
 
+// ErrCartVersionConflict is returned by a write that expected a version of the cart another
+// write has already replaced, or that expected an active cart that no longer is
+var ErrCartVersionConflict = errors.New("cart was changed by another request")
+
 // CartRepository handles cart database operations
 type CartRepository struct {
-    conn *db.Connection
+    conn  *db.Connection
+    clock clock.Clock
 }
 
 // NewCartRepository creates new cart repository
-func NewCartRepository(conn *db.Connection) *CartRepository {
-    return &CartRepository{conn: conn}
+func NewCartRepository(conn *db.Connection, clk clock.Clock) *CartRepository {
+    return &CartRepository{conn: conn, clock: clk}
 }
 
 // CreateCart creates a new cart
 func (cr *CartRepository) CreateCart(ctx context.Context, cart *models.Cart) error {
     query := `
-        INSERT INTO $schema.carts (id, user_id, status, total, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, user_id, status, total, created_at, updated_at
+        INSERT INTO $schema.carts (id, user_id, guest, status, total, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, user_id, guest, status, total, version, created_at, updated_at
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     err := cr.conn.QueryRowContext(ctx, query,
         cart.ID,
         cart.UserID,
+        cart.Guest,
         cart.Status,
         cart.Total,
         cart.CreatedAt,
         cart.UpdatedAt,
-    ).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.Total, &cart.CreatedAt, &cart.UpdatedAt)
+    ).Scan(&cart.ID, &cart.UserID, &cart.Guest, &cart.Status, &cart.Total, &cart.Version, &cart.CreatedAt, &cart.UpdatedAt)
 
     if err != nil {
         log.Printf("Error creating cart: %v", err)
@@ -47,22 +56,70 @@ func (cr *CartRepository) CreateCart(ctx context.Context, cart *models.Cart) err
     return nil
 }
 
+// GetOrCreateActiveCart returns the user's (or guest's) active cart, creating an empty one if
+// there is none. created reports whether this call created it.
+// Why: two concurrent first adds used to create two active carts. The insert is a single
+// statement guarded by the one-active-cart-per-user index, so the loser of a race falls
+// through to reading the winner's cart.
+func (cr *CartRepository) GetOrCreateActiveCart(ctx context.Context, userID string, guest bool) (cart *models.Cart, created bool, err error) {
+    newCart := models.NewCart(userID)
+    newCart.Guest = guest
+
+    query := `
+        INSERT INTO $schema.carts (id, user_id, guest, status, total, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (user_id) WHERE status = 'active' DO NOTHING
+        RETURNING id, user_id, guest, status, total, version, created_at, updated_at
+    `
+
+    query = cr.conn.Qualify(query)
+
+    err = cr.conn.QueryRowContext(ctx, query,
+        newCart.ID,
+        newCart.UserID,
+        newCart.Guest,
+        newCart.Status,
+        newCart.Total,
+        newCart.CreatedAt,
+        newCart.UpdatedAt,
+    ).Scan(&newCart.ID, &newCart.UserID, &newCart.Guest, &newCart.Status, &newCart.Total, &newCart.Version, &newCart.CreatedAt, &newCart.UpdatedAt)
+
+    switch {
+    case err == nil:
+        return newCart, true, nil
+    case errors.Is(err, sql.ErrNoRows):
+        // An active cart already exists
+        cart, err = cr.GetCartByUserID(ctx, userID)
+        if err != nil {
+            return nil, false, err
+        }
+        return cart, false, nil
+    default:
+        log.Printf("Error creating cart: %v", err)
+        return nil, false, fmt.Errorf("failed to create cart: %w", err)
+    }
+}
+
 // GetCart retrieves a cart with items
 func (cr *CartRepository) GetCart(ctx context.Context, cartID string) (*models.Cart, error) {
     query := `
-        SELECT id, user_id, status, total, created_at, updated_at, abandoned_at
+        SELECT id, user_id, guest, status, coupon_code, discount, total, version, created_at, updated_at, abandoned_at
         FROM $schema.carts
         WHERE id = $1 AND status != 'abandoned'
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     cart := &models.Cart{}
     err := cr.conn.QueryRowContext(ctx, query, cartID).Scan(
         &cart.ID,
         &cart.UserID,
+        &cart.Guest,
         &cart.Status,
+        &cart.CouponCode,
+        &cart.Discount,
         &cart.Total,
+        &cart.Version,
         &cart.CreatedAt,
         &cart.UpdatedAt,
         &cart.AbandonedAt,
@@ -74,13 +131,13 @@ func (cr *CartRepository) GetCart(ctx context.Context, cartID string) (*models.C
 
     // Get cart items
     itemsQuery := `
-        SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
+        SELECT id, cart_id, product_id, variant_id, quantity, price, created_at, updated_at
         FROM $schema.cart_items
         WHERE cart_id = $1
         ORDER BY created_at ASC
     `
 
-    itemsQuery = replaceSchema(itemsQuery, cr.conn.Schema)
+    itemsQuery = cr.conn.Qualify(itemsQuery)
 
     rows, err := cr.conn.QueryContext(ctx, itemsQuery, cartID)
     if err != nil {
@@ -90,12 +147,14 @@ func (cr *CartRepository) GetCart(ctx context.Context, cartID string) (*models.C
 
     for rows.Next() {
         item := &models.CartItem{}
-        err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+        err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
         if err != nil {
             return nil, fmt.Errorf("failed to scan cart item: %w", err)
         }
         cart.Items = append(cart.Items, *item)
+        cart.Subtotal += item.Price * float64(item.Quantity)
     }
+    cart.Subtotal = math.Round(cart.Subtotal*100) / 100
 
     return cart, nil
 }
@@ -103,21 +162,25 @@ func (cr *CartRepository) GetCart(ctx context.Context, cartID string) (*models.C
 // GetCartByUserID retrieves user's active cart
 func (cr *CartRepository) GetCartByUserID(ctx context.Context, userID string) (*models.Cart, error) {
     query := `
-        SELECT id, user_id, status, total, created_at, updated_at, abandoned_at
+        SELECT id, user_id, guest, status, coupon_code, discount, total, version, created_at, updated_at, abandoned_at
         FROM $schema.carts
         WHERE user_id = $1 AND status = 'active'
         ORDER BY created_at DESC
         LIMIT 1
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     cart := &models.Cart{}
     err := cr.conn.QueryRowContext(ctx, query, userID).Scan(
         &cart.ID,
         &cart.UserID,
+        &cart.Guest,
         &cart.Status,
+        &cart.CouponCode,
+        &cart.Discount,
         &cart.Total,
+        &cart.Version,
         &cart.CreatedAt,
         &cart.UpdatedAt,
         &cart.AbandonedAt,
@@ -129,13 +192,13 @@ func (cr *CartRepository) GetCartByUserID(ctx context.Context, userID string) (*
 
     // Get cart items
     itemsQuery := `
-        SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
+        SELECT id, cart_id, product_id, variant_id, quantity, price, created_at, updated_at
         FROM $schema.cart_items
         WHERE cart_id = $1
         ORDER BY created_at ASC
     `
 
-    itemsQuery = replaceSchema(itemsQuery, cr.conn.Schema)
+    itemsQuery = cr.conn.Qualify(itemsQuery)
 
     rows, err := cr.conn.QueryContext(ctx, itemsQuery, cart.ID)
     if err != nil {
@@ -145,53 +208,80 @@ func (cr *CartRepository) GetCartByUserID(ctx context.Context, userID string) (*
 
     for rows.Next() {
         item := &models.CartItem{}
-        err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+        err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
         if err != nil {
             return nil, fmt.Errorf("failed to scan cart item: %w", err)
         }
         cart.Items = append(cart.Items, *item)
+        cart.Subtotal += item.Price * float64(item.Quantity)
     }
+    cart.Subtotal = math.Round(cart.Subtotal*100) / 100
 
     return cart, nil
 }
 
-// AddItem adds an item to cart
-func (cr *CartRepository) AddItem(ctx context.Context, item *models.CartItem) error {
+// AddItem adds an item to the cart at version, moving it to the next version
+func (cr *CartRepository) AddItem(ctx context.Context, item *models.CartItem, version int) error {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if err := cr.bumpVersion(ctx, tx, item.CartID, version); err != nil {
+        return err
+    }
+
     query := `
-        INSERT INTO $schema.cart_items (id, cart_id, product_id, quantity, price, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, cart_id, product_id, quantity, price, created_at, updated_at
+        INSERT INTO $schema.cart_items (id, cart_id, product_id, variant_id, quantity, price, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, cart_id, product_id, variant_id, quantity, price, created_at, updated_at
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
-    err := cr.conn.QueryRowContext(ctx, query,
+    err = tx.QueryRowContext(ctx, query,
         item.ID,
         item.CartID,
         item.ProductID,
+        item.VariantID,
         item.Quantity,
         item.Price,
         item.CreatedAt,
         item.UpdatedAt,
-    ).Scan(&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+    ).Scan(&item.ID, &item.CartID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
 
     if err != nil {
         return fmt.Errorf("failed to add item: %w", err)
     }
 
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit item: %w", err)
+    }
     return nil
 }
 
-// RemoveItem removes an item from cart
-func (cr *CartRepository) RemoveItem(ctx context.Context, cartID string, productID int64) error {
+// RemoveItem removes a product from the cart at version, moving it to the next version; with a
+// variant ID only that variant's items
+func (cr *CartRepository) RemoveItem(ctx context.Context, cartID string, productID int64, variantID *int64, version int) error {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if err := cr.bumpVersion(ctx, tx, cartID, version); err != nil {
+        return err
+    }
+
     query := `
         DELETE FROM $schema.cart_items
-        WHERE cart_id = $1 AND product_id = $2
+        WHERE cart_id = $1 AND product_id = $2 AND ($3::BIGINT IS NULL OR variant_id = $3)
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
-    result, err := cr.conn.ExecContext(ctx, query, cartID, productID)
+    result, err := tx.ExecContext(ctx, query, cartID, productID, variantID)
     if err != nil {
         return fmt.Errorf("failed to remove item: %w", err)
     }
@@ -205,20 +295,51 @@ func (cr *CartRepository) RemoveItem(ctx context.Context, cartID string, product
         return fmt.Errorf("item not found in cart")
     }
 
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit item removal: %w", err)
+    }
     return nil
 }
 
-// UpdateCartStatus updates cart status
-func (cr *CartRepository) UpdateCartStatus(ctx context.Context, cartID string, status string) error {
+// bumpVersion moves an active cart from version to the next, or returns ErrCartVersionConflict
+// when another write got there first
+// Why: it runs first in the write's transaction, so the row lock it takes holds off a
+// concurrent checkout until the lines are in place
+func (cr *CartRepository) bumpVersion(ctx context.Context, tx *sql.Tx, cartID string, version int) error {
+    query := cr.conn.Qualify(`
+        UPDATE $schema.carts
+        SET version = version + 1, updated_at = $1
+        WHERE id = $2 AND version = $3 AND status = 'active'
+    `)
+
+    result, err := tx.ExecContext(ctx, query, cr.clock.Now(), cartID, version)
+    if err != nil {
+        return fmt.Errorf("failed to update cart version: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected == 0 {
+        return ErrCartVersionConflict
+    }
+    return nil
+}
+
+// UpdateCartStatus moves the cart from version to status and the next version; checkout uses it
+// to claim exactly the cart it validated
+func (cr *CartRepository) UpdateCartStatus(ctx context.Context, cartID string, status string, version int) error {
     query := `
         UPDATE $schema.carts
-        SET status = $1, updated_at = $2
-        WHERE id = $3
+        SET status = $1, version = version + 1, updated_at = $2
+        WHERE id = $3 AND version = $4
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
-    result, err := cr.conn.ExecContext(ctx, query, status, time.Now().UTC(), cartID)
+    result, err := cr.conn.ExecContext(ctx, query, status, cr.clock.Now(), cartID, version)
     if err != nil {
         return fmt.Errorf("failed to update cart status: %w", err)
     }
@@ -229,23 +350,104 @@ func (cr *CartRepository) UpdateCartStatus(ctx context.Context, cartID string, s
     }
 
     if rowsAffected == 0 {
-        return fmt.Errorf("cart not found")
+        return ErrCartVersionConflict
     }
 
     return nil
 }
 
-// UpdateCartTotal updates cart total
-func (cr *CartRepository) UpdateCartTotal(ctx context.Context, cartID string, total float64) error {
+// UpdateItemPrice replaces the price snapshot of a product's items, or of one of its variants
+func (cr *CartRepository) UpdateItemPrice(ctx context.Context, cartID string, productID int64, variantID *int64, price float64) error {
     query := `
+        WITH cart AS (
+            UPDATE $schema.carts SET version = version + 1, updated_at = $2 WHERE id = $3
+        )
+        UPDATE $schema.cart_items
+        SET price = $1, updated_at = $2
+        WHERE cart_id = $3 AND product_id = $4 AND variant_id IS NOT DISTINCT FROM $5
+    `
+
+    query = cr.conn.Qualify(query)
+
+    _, err := cr.conn.ExecContext(ctx, query, price, cr.clock.Now(), cartID, productID, variantID)
+    if err != nil {
+        return fmt.Errorf("failed to update item price: %w", err)
+    }
+
+    return nil
+}
+
+// MergeCart moves a guest cart's lines into the user's cart in one transaction: each line
+// replaces the user's items of the same product or variant, and the guest cart is marked
+// merged. The guest cart is locked first, so a merge retried or run twice only applies once;
+// the loser gets ErrCartNotActive.
+func (cr *CartRepository) MergeCart(ctx context.Context, guestCartID, userCartID string, lines []models.MergedLine) error {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var status string
+    lockQuery := cr.conn.Qualify(`SELECT status FROM $schema.carts WHERE id = $1 FOR UPDATE`)
+    if err := tx.QueryRowContext(ctx, lockQuery, guestCartID).Scan(&status); err != nil {
+        return fmt.Errorf("failed to lock guest cart: %w", err)
+    }
+    if status != "active" {
+        return ErrCartNotActive
+    }
+
+    deleteQuery := cr.conn.Qualify(`
+        DELETE FROM $schema.cart_items
+        WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3
+    `)
+    insertQuery := cr.conn.Qualify(`
+        INSERT INTO $schema.cart_items (id, cart_id, product_id, variant_id, quantity, price, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `)
+    for _, line := range lines {
+        if _, err := tx.ExecContext(ctx, deleteQuery, userCartID, line.ProductID, line.VariantID); err != nil {
+            return fmt.Errorf("failed to replace item of product %d: %w", line.ProductID, err)
+        }
+        item := line.Item(userCartID)
+        if _, err := tx.ExecContext(ctx, insertQuery,
+            item.ID, item.CartID, item.ProductID, item.VariantID, item.Quantity, item.Price, item.CreatedAt, item.UpdatedAt,
+        ); err != nil {
+            return fmt.Errorf("failed to merge item of product %d: %w", line.ProductID, err)
+        }
+    }
+
+    mergedQuery := cr.conn.Qualify(`
         UPDATE $schema.carts
-        SET total = $1, updated_at = $2
+        SET status = 'merged', merged_into = $1, version = version + 1, updated_at = $2
         WHERE id = $3
+    `)
+    if _, err := tx.ExecContext(ctx, mergedQuery, userCartID, cr.clock.Now(), guestCartID); err != nil {
+        return fmt.Errorf("failed to mark guest cart merged: %w", err)
+    }
+    userCartQuery := cr.conn.Qualify(`UPDATE $schema.carts SET version = version + 1, updated_at = $1 WHERE id = $2`)
+    if _, err := tx.ExecContext(ctx, userCartQuery, cr.clock.Now(), userCartID); err != nil {
+        return fmt.Errorf("failed to update user cart version: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit merge: %w", err)
+    }
+    return nil
+}
+
+// UpdateCartTotal updates cart total and the coupon discount taken off it. The total follows
+// from the lines and the coupon, whose changes bump the version, so this doesn't.
+func (cr *CartRepository) UpdateCartTotal(ctx context.Context, cartID string, discount, total float64) error {
+    query := `
+        UPDATE $schema.carts
+        SET discount = $1, total = $2, updated_at = $3
+        WHERE id = $4
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
-    _, err := cr.conn.ExecContext(ctx, query, total, time.Now().UTC(), cartID)
+    _, err := cr.conn.ExecContext(ctx, query, discount, total, cr.clock.Now(), cartID)
     if err != nil {
         return fmt.Errorf("failed to update cart total: %w", err)
     }
@@ -257,13 +459,14 @@ func (cr *CartRepository) UpdateCartTotal(ctx context.Context, cartID string, to
 func (cr *CartRepository) DeleteCart(ctx context.Context, cartID string) error {
     query := `
         UPDATE $schema.carts
-        SET status = 'abandoned', abandoned_at = $1, updated_at = $2
+        SET status = 'abandoned', abandoned_at = $1, version = version + 1, updated_at = $2
         WHERE id = $3
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
-    result, err := cr.conn.ExecContext(ctx, query, time.Now().UTC(), time.Now().UTC(), cartID)
+    now := cr.clock.Now()
+    result, err := cr.conn.ExecContext(ctx, query, now, now, cartID)
     if err != nil {
         return fmt.Errorf("failed to delete cart: %w", err)
     }
@@ -282,24 +485,18 @@ func (cr *CartRepository) DeleteCart(ctx context.Context, cartID string) error {
 
 // ClearCart removes all items from cart
 func (cr *CartRepository) ClearCart(ctx context.Context, cartID string) error {
-    query := `DELETE FROM $schema.cart_items WHERE cart_id = $1`
-    query = replaceSchema(query, cr.conn.Schema)
+    query := `
+        WITH cart AS (
+            UPDATE $schema.carts SET version = version + 1, updated_at = $2 WHERE id = $1
+        )
+        DELETE FROM $schema.cart_items WHERE cart_id = $1
+    `
+    query = cr.conn.Qualify(query)
 
-    _, err := cr.conn.ExecContext(ctx, query, cartID)
+    _, err := cr.conn.ExecContext(ctx, query, cartID, cr.clock.Now())
     if err != nil {
         return fmt.Errorf("failed to clear cart items: %w", err)
     }
 
     return nil
 }
-
-
-// Helper function
-func replaceSchema(query, schema string) string {
-    for i := 0; i < len(query)-len("$schema"); i++ {
-        if query[i:i+len("$schema")] == "$schema" {
-            query = query[:i] + schema + query[i+len("$schema"):]
-        }
-    }
-    return query
-}
\ No newline at end of file
@@ -0,0 +1,36 @@
+package repository
+
+import (
+    "context"
+    "testing"
+
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/fixtures"
+)
+
+func TestGetOrCreateActiveCartReturnsTheExistingCart(t *testing.T) {
+    database := fixtures.Open(t)
+    loaded := database.Load(t, fixtures.Set{
+        Users:    []fixtures.User{{Key: "alice"}, {Key: "bob"}},
+        Products: []fixtures.Product{{Key: "mouse", Price: 29.99}},
+        Carts: []fixtures.Cart{
+            {Key: "old", User: "alice", Status: "abandoned", Items: []fixtures.Item{{Product: "mouse"}}},
+            {Key: "current", User: "alice", Items: []fixtures.Item{{Product: "mouse", Quantity: 2}}},
+        },
+    })
+    repo := NewCartRepository(database.Connect(t, "cart"), clock.New())
+    ctx := context.Background()
+
+    cart, created, err := repo.GetOrCreateActiveCart(ctx, loaded.Users["alice"], false)
+    if err != nil {
+        t.Fatalf("GetOrCreateActiveCart: %v", err)
+    }
+    if created || cart.ID != loaded.Carts["current"] || cart.Total != 59.98 {
+        t.Errorf("cart = %+v (created %v), want the active one", cart, created)
+    }
+
+    cart, created, err = repo.GetOrCreateActiveCart(ctx, loaded.Users["bob"], false)
+    if err != nil || !created {
+        t.Errorf("bob's cart = %+v (created %v, %v), want a new one", cart, created, err)
+    }
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "math"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// Coupon errors, returned as they are or wrapped
+var (
+    ErrCouponNotFound  = errors.New("coupon not found")
+    ErrCouponCodeTaken = errors.New("coupon code already exists")
+    ErrCouponExhausted = errors.New("coupon has been used up")
+    ErrCouponUserLimit = errors.New("coupon already used the allowed number of times")
+    ErrCartNotActive   = errors.New("cart is not active")
+)
+
+// CouponRepository handles coupons and their redemptions
+type CouponRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewCouponRepository creates new coupon repository
+func NewCouponRepository(conn *db.Connection, clk clock.Clock) *CouponRepository {
+    return &CouponRepository{conn: conn, clock: clk}
+}
+
+// couponColumns are read by scanCoupon; redemptions counts the applied and redeemed uses
+const couponColumns = `
+    c.id, c.code, c.discount_type, c.amount, c.expires_at, c.max_redemptions, c.max_per_user, c.active, c.created_at,
+    (SELECT COUNT(*) FROM $schema.coupon_redemptions r WHERE r.coupon_id = c.id AND r.status <> 'released')
+`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanCoupon(row rowScanner) (*models.Coupon, error) {
+    coupon := &models.Coupon{}
+    err := row.Scan(
+        &coupon.ID,
+        &coupon.Code,
+        &coupon.DiscountType,
+        &coupon.Amount,
+        &coupon.ExpiresAt,
+        &coupon.MaxRedemptions,
+        &coupon.MaxPerUser,
+        &coupon.Active,
+        &coupon.CreatedAt,
+        &coupon.Redemptions,
+    )
+    return coupon, err
+}
+
+// CreateCoupon creates a coupon; a code that exists already is ErrCouponCodeTaken
+func (cr *CouponRepository) CreateCoupon(ctx context.Context, coupon *models.Coupon) error {
+    query := cr.conn.Qualify(`
+        INSERT INTO $schema.coupons (code, discount_type, amount, expires_at, max_redemptions, max_per_user, active, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (code) DO NOTHING
+        RETURNING id
+    `)
+
+    err := cr.conn.QueryRowContext(ctx, query,
+        coupon.Code,
+        coupon.DiscountType,
+        coupon.Amount,
+        coupon.ExpiresAt,
+        coupon.MaxRedemptions,
+        coupon.MaxPerUser,
+        coupon.Active,
+        coupon.CreatedAt,
+    ).Scan(&coupon.ID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return fmt.Errorf("%w: %s", ErrCouponCodeTaken, coupon.Code)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create coupon: %w", err)
+    }
+
+    return nil
+}
+
+// GetCoupons lists every coupon, newest first
+func (cr *CouponRepository) GetCoupons(ctx context.Context) ([]*models.Coupon, error) {
+    query := cr.conn.Qualify(`SELECT ` + couponColumns + ` FROM $schema.coupons c ORDER BY c.created_at DESC, c.id DESC`)
+
+    rows, err := cr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get coupons: %w", err)
+    }
+    defer rows.Close()
+
+    coupons := []*models.Coupon{}
+    for rows.Next() {
+        coupon, err := scanCoupon(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan coupon: %w", err)
+        }
+        coupons = append(coupons, coupon)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read coupons: %w", err)
+    }
+
+    return coupons, nil
+}
+
+// GetCouponByCode retrieves a coupon; an unknown code is ErrCouponNotFound
+func (cr *CouponRepository) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+    query := cr.conn.Qualify(`SELECT ` + couponColumns + ` FROM $schema.coupons c WHERE c.code = $1`)
+
+    coupon, err := scanCoupon(cr.conn.QueryRowContext(ctx, query, models.NormalizeCouponCode(code)))
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrCouponNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get coupon: %w", err)
+    }
+
+    return coupon, nil
+}
+
+// GetUsableCoupon retrieves a coupon that can be used now: an expired or inactive one is
+// models.ErrCouponExpired
+func (cr *CouponRepository) GetUsableCoupon(ctx context.Context, code string) (*models.Coupon, error) {
+    coupon, err := cr.GetCouponByCode(ctx, code)
+    if err != nil {
+        return nil, err
+    }
+    if err := coupon.Check(cr.clock.Now()); err != nil {
+        return nil, err
+    }
+    return coupon, nil
+}
+
+// SetCouponActive switches a coupon on or off; carts holding it lose it at checkout
+func (cr *CouponRepository) SetCouponActive(ctx context.Context, code string, active bool) error {
+    query := cr.conn.Qualify(`UPDATE $schema.coupons SET active = $1 WHERE code = $2`)
+
+    result, err := cr.conn.ExecContext(ctx, query, active, models.NormalizeCouponCode(code))
+    if err != nil {
+        return fmt.Errorf("failed to update coupon: %w", err)
+    }
+    if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+        return ErrCouponNotFound
+    }
+
+    return nil
+}
+
+// ApplyCoupon puts a coupon on the user's active cart and records the redemption, replacing
+// the coupon the cart had. The coupon row is locked while its limits are counted, so two
+// carts can't both take its last use. Applying the coupon the cart already has only
+// recomputes the discount.
+func (cr *CouponRepository) ApplyCoupon(ctx context.Context, cartID, userID, code string) (*models.Coupon, error) {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Counted after the lock, in a statement of its own, so uses committed while waiting count
+    var couponID int64
+    lockCoupon := cr.conn.Qualify(`SELECT id FROM $schema.coupons WHERE code = $1 FOR UPDATE`)
+    err = tx.QueryRowContext(ctx, lockCoupon, models.NormalizeCouponCode(code)).Scan(&couponID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrCouponNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock coupon: %w", err)
+    }
+    coupon, err := scanCoupon(tx.QueryRowContext(ctx, cr.conn.Qualify(`SELECT `+couponColumns+` FROM $schema.coupons c WHERE c.id = $1`), couponID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get coupon: %w", err)
+    }
+    if err := coupon.Check(cr.clock.Now()); err != nil {
+        return nil, err
+    }
+
+    var status string
+    var current sql.NullString
+    lockCart := cr.conn.Qualify(`SELECT status, coupon_code FROM $schema.carts WHERE id = $1 FOR UPDATE`)
+    if err := tx.QueryRowContext(ctx, lockCart, cartID).Scan(&status, &current); err != nil {
+        return nil, fmt.Errorf("failed to lock cart: %w", err)
+    }
+    if status != "active" {
+        return nil, ErrCartNotActive
+    }
+
+    if current.String != coupon.Code {
+        if coupon.MaxRedemptions != nil && coupon.Redemptions >= *coupon.MaxRedemptions {
+            return nil, ErrCouponExhausted
+        }
+        if coupon.MaxPerUser != nil {
+            var used int
+            countQuery := cr.conn.Qualify(`
+                SELECT COUNT(*) FROM $schema.coupon_redemptions
+                WHERE coupon_id = $1 AND user_id = $2 AND status <> 'released'
+            `)
+            if err := tx.QueryRowContext(ctx, countQuery, coupon.ID, userID).Scan(&used); err != nil {
+                return nil, fmt.Errorf("failed to count coupon uses: %w", err)
+            }
+            if used >= *coupon.MaxPerUser {
+                return nil, ErrCouponUserLimit
+            }
+        }
+
+        now := cr.clock.Now()
+        releaseQuery := cr.conn.Qualify(`
+            UPDATE $schema.coupon_redemptions SET status = 'released', updated_at = $1
+            WHERE cart_id = $2 AND status = 'applied'
+        `)
+        if _, err := tx.ExecContext(ctx, releaseQuery, now, cartID); err != nil {
+            return nil, fmt.Errorf("failed to release previous coupon: %w", err)
+        }
+
+        insertQuery := cr.conn.Qualify(`
+            INSERT INTO $schema.coupon_redemptions (coupon_id, cart_id, user_id, status, created_at, updated_at)
+            VALUES ($1, $2, $3, 'applied', $4, $4)
+        `)
+        if _, err := tx.ExecContext(ctx, insertQuery, coupon.ID, cartID, userID, now); err != nil {
+            return nil, fmt.Errorf("failed to record coupon redemption: %w", err)
+        }
+        coupon.Redemptions++
+    }
+
+    var subtotal float64
+    subtotalQuery := cr.conn.Qualify(`SELECT COALESCE(SUM(price * quantity), 0) FROM $schema.cart_items WHERE cart_id = $1`)
+    if err := tx.QueryRowContext(ctx, subtotalQuery, cartID).Scan(&subtotal); err != nil {
+        return nil, fmt.Errorf("failed to get cart subtotal: %w", err)
+    }
+    discount := coupon.Discount(subtotal)
+    total := math.Round((subtotal-discount)*100) / 100
+
+    updateCart := cr.conn.Qualify(`UPDATE $schema.carts SET coupon_code = $1, discount = $2, total = $3, version = version + 1, updated_at = $4 WHERE id = $5`)
+    if _, err := tx.ExecContext(ctx, updateCart, coupon.Code, discount, total, cr.clock.Now(), cartID); err != nil {
+        return nil, fmt.Errorf("failed to apply coupon to cart: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit coupon: %w", err)
+    }
+
+    return coupon, nil
+}
+
+// RemoveCoupon takes the coupon off a cart and releases its redemption; the cart's total goes
+// back to its subtotal. A cart without a coupon is left as it is.
+func (cr *CouponRepository) RemoveCoupon(ctx context.Context, cartID string) error {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    now := cr.clock.Now()
+    releaseQuery := cr.conn.Qualify(`
+        UPDATE $schema.coupon_redemptions SET status = 'released', updated_at = $1
+        WHERE cart_id = $2 AND status = 'applied'
+    `)
+    if _, err := tx.ExecContext(ctx, releaseQuery, now, cartID); err != nil {
+        return fmt.Errorf("failed to release coupon: %w", err)
+    }
+
+    updateCart := cr.conn.Qualify(`
+        UPDATE $schema.carts SET coupon_code = NULL, total = total + discount, discount = 0, version = version + 1, updated_at = $1
+        WHERE id = $2 AND coupon_code IS NOT NULL
+    `)
+    if _, err := tx.ExecContext(ctx, updateCart, now, cartID); err != nil {
+        return fmt.Errorf("failed to remove coupon from cart: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit coupon removal: %w", err)
+    }
+
+    return nil
+}
+
+// RedeemCoupon marks the cart's applied coupon as used by a checkout
+func (cr *CouponRepository) RedeemCoupon(ctx context.Context, cartID, correlationID string) error {
+    query := cr.conn.Qualify(`
+        UPDATE $schema.coupon_redemptions SET status = 'redeemed', correlation_id = $1, updated_at = $2
+        WHERE cart_id = $3 AND status = 'applied'
+    `)
+
+    if _, err := cr.conn.ExecContext(ctx, query, correlationID, cr.clock.Now(), cartID); err != nil {
+        return fmt.Errorf("failed to redeem coupon: %w", err)
+    }
+
+    return nil
+}
+
+// ReleaseCheckoutCoupon gives back the use of a coupon redeemed by a checkout that failed.
+// Returns whether there was one.
+func (cr *CouponRepository) ReleaseCheckoutCoupon(ctx context.Context, correlationID string) (bool, error) {
+    query := cr.conn.Qualify(`
+        UPDATE $schema.coupon_redemptions SET status = 'released', updated_at = $1
+        WHERE correlation_id = $2 AND status = 'redeemed'
+    `)
+
+    result, err := cr.conn.ExecContext(ctx, query, cr.clock.Now(), correlationID)
+    if err != nil {
+        return false, fmt.Errorf("failed to release checkout coupon: %w", err)
+    }
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    return rows > 0, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/fixtures"
+)
+
+func TestApplyCouponDiscountsTheCartAndEnforcesTheLimit(t *testing.T) {
+    database := fixtures.Open(t)
+    loaded := database.Load(t, fixtures.Set{
+        Users:    []fixtures.User{{Key: "alice"}, {Key: "bob"}},
+        Products: []fixtures.Product{{Key: "mouse", Price: 29.99}},
+        Carts: []fixtures.Cart{
+            {Key: "alice", User: "alice", Items: []fixtures.Item{{Product: "mouse", Quantity: 2}}},
+            {Key: "bob", User: "bob", Items: []fixtures.Item{{Product: "mouse"}}},
+        },
+    })
+    conn := database.Connect(t, "cart")
+    clk := clock.NewFake(time.Now().UTC())
+    carts := NewCartRepository(conn, clk)
+    coupons := NewCouponRepository(conn, clk)
+    ctx := context.Background()
+
+    once := 1
+    coupon := models.NewCoupon(models.CreateCouponRequest{Code: "save10", DiscountType: models.DiscountFixed, Amount: 10, MaxRedemptions: &once})
+    if err := coupons.CreateCoupon(ctx, coupon); err != nil {
+        t.Fatalf("CreateCoupon: %v", err)
+    }
+
+    if _, err := coupons.ApplyCoupon(ctx, loaded.Carts["alice"], loaded.Users["alice"], "SAVE10"); err != nil {
+        t.Fatalf("ApplyCoupon: %v", err)
+    }
+    cart, err := carts.GetCart(ctx, loaded.Carts["alice"])
+    if err != nil {
+        t.Fatalf("GetCart: %v", err)
+    }
+    if cart.CouponCode == nil || *cart.CouponCode != "SAVE10" || cart.Discount != 10 || cart.Total != 49.98 {
+        t.Errorf("cart = %+v, want SAVE10 taking 10 off 59.98", cart)
+    }
+
+    if _, err := coupons.ApplyCoupon(ctx, loaded.Carts["bob"], loaded.Users["bob"], "save10"); !errors.Is(err, ErrCouponExhausted) {
+        t.Errorf("second ApplyCoupon = %v, want ErrCouponExhausted", err)
+    }
+
+    // Removing it gives the use back
+    if err := coupons.RemoveCoupon(ctx, loaded.Carts["alice"]); err != nil {
+        t.Fatalf("RemoveCoupon: %v", err)
+    }
+    if _, err := coupons.ApplyCoupon(ctx, loaded.Carts["bob"], loaded.Users["bob"], "save10"); err != nil {
+        t.Errorf("ApplyCoupon after release: %v", err)
+    }
+}
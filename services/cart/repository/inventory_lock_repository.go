@@ -4,32 +4,33 @@ import (
     "context"
     "fmt"
     "log"
-    "time"
 
     "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clock"
     "github.com/sanketh-sg/prost/shared/db"
 )
 
 // InventoryLockRepository handles inventory lock database operations
 type InventoryLockRepository struct {
-    conn *db.Connection
+    conn  *db.Connection
+    clock clock.Clock
 }
 
 // NewInventoryLockRepository creates new inventory lock repository
-func NewInventoryLockRepository(conn *db.Connection) *InventoryLockRepository {
-    return &InventoryLockRepository{conn: conn}
+func NewInventoryLockRepository(conn *db.Connection, clk clock.Clock) *InventoryLockRepository {
+    return &InventoryLockRepository{conn: conn, clock: clk}
 }
 
 // CreateLock creates a new inventory lock
 func (ilr *InventoryLockRepository) CreateLock(ctx context.Context, lock *models.InventoryLock) error {
     query := `
         INSERT INTO $schema.inventory_locks 
-        (id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        RETURNING id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at
+        (id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at, variant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at, variant_id
     `
 
-    query = replaceSchema(query, ilr.conn.Schema)
+    query = ilr.conn.Qualify(query)
 
     err := ilr.conn.QueryRowContext(ctx, query,
         lock.ID,
@@ -40,6 +41,7 @@ func (ilr *InventoryLockRepository) CreateLock(ctx context.Context, lock *models
         lock.Status,
         lock.LockedAt,
         lock.ExpiresAt,
+        lock.VariantID,
     ).Scan(
         &lock.ID,
         &lock.CartID,
@@ -49,6 +51,7 @@ func (ilr *InventoryLockRepository) CreateLock(ctx context.Context, lock *models
         &lock.Status,
         &lock.LockedAt,
         &lock.ExpiresAt,
+        &lock.VariantID,
     )
 
     if err != nil {
@@ -62,12 +65,12 @@ func (ilr *InventoryLockRepository) CreateLock(ctx context.Context, lock *models
 // GetLocksByCartID retrieves all locks for a cart
 func (ilr *InventoryLockRepository) GetLocksByCartID(ctx context.Context, cartID string) ([]*models.InventoryLock, error) {
     query := `
-        SELECT id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at, released_at
+        SELECT id, cart_id, product_id, quantity, reservation_id, status, locked_at, expires_at, released_at, variant_id
         FROM $schema.inventory_locks
         WHERE cart_id = $1 AND status = 'locked'
     `
 
-    query = replaceSchema(query, ilr.conn.Schema)
+    query = ilr.conn.Qualify(query)
 
     rows, err := ilr.conn.QueryContext(ctx, query, cartID)
     if err != nil {
@@ -88,6 +91,7 @@ func (ilr *InventoryLockRepository) GetLocksByCartID(ctx context.Context, cartID
             &lock.LockedAt,
             &lock.ExpiresAt,
             &lock.ReleasedAt,
+            &lock.VariantID,
         )
         if err != nil {
             return nil, fmt.Errorf("failed to scan lock: %w", err)
@@ -106,9 +110,9 @@ func (ilr *InventoryLockRepository) ReleaseLock(ctx context.Context, reservation
         WHERE reservation_id = $2 AND status = 'locked'
     `
 
-    query = replaceSchema(query, ilr.conn.Schema)
+    query = ilr.conn.Qualify(query)
 
-    result, err := ilr.conn.ExecContext(ctx, query, time.Now().UTC(), reservationID)
+    result, err := ilr.conn.ExecContext(ctx, query, ilr.clock.Now(), reservationID)
     if err != nil {
         return fmt.Errorf("failed to release lock: %w", err)
     }
@@ -133,9 +137,9 @@ func (ilr *InventoryLockRepository) ReleaseCartLocks(ctx context.Context, cartID
         WHERE cart_id = $2 AND status = 'locked'
     `
 
-    query = replaceSchema(query, ilr.conn.Schema)
+    query = ilr.conn.Qualify(query)
 
-    _, err := ilr.conn.ExecContext(ctx, query, time.Now().UTC(), cartID)
+    _, err := ilr.conn.ExecContext(ctx, query, ilr.clock.Now(), cartID)
     if err != nil {
         return fmt.Errorf("failed to release cart locks: %w", err)
     }
@@ -144,16 +148,17 @@ func (ilr *InventoryLockRepository) ReleaseCartLocks(ctx context.Context, cartID
 }
 
 // ExpireLocks expires old locks
+// Why: compare against the injected clock instead of NOW() so expiry is testable
 func (ilr *InventoryLockRepository) ExpireLocks(ctx context.Context) (int64, error) {
     query := `
         UPDATE $schema.inventory_locks
         SET status = 'expired'
-        WHERE status = 'locked' AND expires_at < NOW()
+        WHERE status = 'locked' AND expires_at < $1
     `
 
-    query = replaceSchema(query, ilr.conn.Schema)
+    query = ilr.conn.Qualify(query)
 
-    result, err := ilr.conn.ExecContext(ctx, query)
+    result, err := ilr.conn.ExecContext(ctx, query, ilr.clock.Now())
     if err != nil {
         return 0, fmt.Errorf("failed to expire locks: %w", err)
     }
@@ -0,0 +1,60 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+)
+
+// CartRepositoryInterface defines the contract for cart repository operations
+type CartRepositoryInterface interface {
+    CreateCart(ctx context.Context, cart *models.Cart) error
+    GetOrCreateActiveCart(ctx context.Context, userID string, guest bool) (*models.Cart, bool, error)
+    GetCart(ctx context.Context, cartID string) (*models.Cart, error)
+    GetCartByUserID(ctx context.Context, userID string) (*models.Cart, error)
+    AddItem(ctx context.Context, item *models.CartItem, version int) error
+    RemoveItem(ctx context.Context, cartID string, productID int64, variantID *int64, version int) error
+    UpdateCartStatus(ctx context.Context, cartID string, status string, version int) error
+    UpdateItemPrice(ctx context.Context, cartID string, productID int64, variantID *int64, price float64) error
+    MergeCart(ctx context.Context, guestCartID, userCartID string, lines []models.MergedLine) error
+    UpdateCartTotal(ctx context.Context, cartID string, discount, total float64) error
+    DeleteCart(ctx context.Context, cartID string) error
+    ClearCart(ctx context.Context, cartID string) error
+}
+
+// SagaStateRepositoryInterface defines the contract for the cart's copy of checkout sagas
+type SagaStateRepositoryInterface interface {
+    CreateSagaState(ctx context.Context, saga *models.SagaState) error
+    GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error)
+    UpdateSagaStatus(ctx context.Context, correlationID string, status string) error
+    AddCompensation(ctx context.Context, correlationID string, compensation string) error
+    UpdateSagaPayload(ctx context.Context, correlationID string, payload map[string]interface{}) error
+}
+
+// InventoryLockRepositoryInterface defines the contract for inventory lock operations
+type InventoryLockRepositoryInterface interface {
+    CreateLock(ctx context.Context, lock *models.InventoryLock) error
+    GetLocksByCartID(ctx context.Context, cartID string) ([]*models.InventoryLock, error)
+    ReleaseLock(ctx context.Context, reservationID string) error
+    ReleaseCartLocks(ctx context.Context, cartID string) error
+    ExpireLocks(ctx context.Context) (int64, error)
+}
+
+// CouponRepositoryInterface defines the contract for coupon operations
+type CouponRepositoryInterface interface {
+    CreateCoupon(ctx context.Context, coupon *models.Coupon) error
+    GetCoupons(ctx context.Context) ([]*models.Coupon, error)
+    GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error)
+    GetUsableCoupon(ctx context.Context, code string) (*models.Coupon, error)
+    SetCouponActive(ctx context.Context, code string, active bool) error
+    ApplyCoupon(ctx context.Context, cartID, userID, code string) (*models.Coupon, error)
+    RemoveCoupon(ctx context.Context, cartID string) error
+    RedeemCoupon(ctx context.Context, cartID, correlationID string) error
+    ReleaseCheckoutCoupon(ctx context.Context, correlationID string) (bool, error)
+}
+
+// StatsRepositoryInterface defines the contract for cart statistics
+type StatsRepositoryInterface interface {
+    GetFunnel(ctx context.Context, from, to time.Time) (*models.CartFunnel, error)
+}
@@ -38,7 +38,7 @@ func (sr *SagaStateRepository) CreateSagaState(ctx context.Context, saga *models
         RETURNING id, correlation_id, saga_type, status, cart_id, payload, compensation_log, created_at, updated_at, expires_at
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     var payloadJSONResp []byte
     var compensationLogResp pq.StringArray
@@ -83,7 +83,7 @@ func (sr *SagaStateRepository) GetSagaState(ctx context.Context, correlationID s
         WHERE correlation_id = $1
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     saga := &models.SagaState{}
     var payloadJSON []byte
@@ -125,7 +125,7 @@ func (sr *SagaStateRepository) UpdateSagaStatus(ctx context.Context, correlation
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     result, err := sr.conn.ExecContext(ctx, query, status, time.Now().UTC(), correlationID)
     if err != nil {
@@ -152,7 +152,7 @@ func (sr *SagaStateRepository) AddCompensation(ctx context.Context, correlationI
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     _, err := sr.conn.ExecContext(ctx, query, compensation, time.Now().UTC(), correlationID)
     if err != nil {
@@ -175,7 +175,7 @@ func (sr *SagaStateRepository) UpdateSagaPayload(ctx context.Context, correlatio
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     _, err = sr.conn.ExecContext(ctx, query, payloadJSON, time.Now().UTC(), correlationID)
     if err != nil {
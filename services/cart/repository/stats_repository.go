@@ -0,0 +1,39 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// StatsRepository handles cart reporting queries
+type StatsRepository struct {
+    conn *db.Connection
+}
+
+// NewStatsRepository creates new stats repository
+func NewStatsRepository(conn *db.Connection) *StatsRepository {
+    return &StatsRepository{conn: conn}
+}
+
+// GetFunnel counts carts created and checkouts started in [from, to)
+func (sr *StatsRepository) GetFunnel(ctx context.Context, from, to time.Time) (*models.CartFunnel, error) {
+    query := `
+        SELECT
+            (SELECT COUNT(*) FROM $schema.carts WHERE created_at >= $1 AND created_at < $2),
+            (SELECT COUNT(*) FROM $schema.saga_states WHERE created_at >= $1 AND created_at < $2)
+    `
+
+    query = sr.conn.Qualify(query)
+
+    funnel := &models.CartFunnel{From: from, To: to}
+    err := sr.conn.QueryRowContext(ctx, query, from, to).Scan(&funnel.CartsCreated, &funnel.CheckoutsInitiated)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get cart funnel: %w", err)
+    }
+
+    return funnel, nil
+}
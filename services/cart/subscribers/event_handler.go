@@ -2,12 +2,14 @@ package subscribers
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"time"
 
+	"github.com/sanketh-sg/prost/services/cart/inventory"
 	"github.com/sanketh-sg/prost/services/cart/models"
 	"github.com/sanketh-sg/prost/services/cart/repository"
 	"github.com/sanketh-sg/prost/shared/db"
@@ -19,7 +21,9 @@ type EventHandler struct {
     cartRepo          *repository.CartRepository
     sagaRepo          *repository.SagaStateRepository
     inventoryLockRepo *repository.InventoryLockRepository
+    couponRepo        *repository.CouponRepository
     idempotencyStore  *db.IdempotencyStore
+    locks             inventory.Locker // nil when soft locks are disabled
 }
 
 // NewEventHandler creates new event handler
@@ -27,28 +31,28 @@ func NewEventHandler(
     cartRepo *repository.CartRepository,
     sagaRepo *repository.SagaStateRepository,
     inventoryLockRepo *repository.InventoryLockRepository,
+    couponRepo *repository.CouponRepository,
     idempotencyStore *db.IdempotencyStore,
+    locks inventory.Locker,
 ) *EventHandler {
     return &EventHandler{
         cartRepo:          cartRepo,
         sagaRepo:          sagaRepo,
         inventoryLockRepo: inventoryLockRepo,
+        couponRepo:        couponRepo,
         idempotencyStore:  idempotencyStore,
+        locks:             locks,
     }
 }
 
 // HandleEvent processes incoming events
 // Why: Events from Products and Orders services need to update cart state
-// Events can be: StockReserved, StockReleased, OrderPlaced, OrderFailed
+// Events can be: StockReserved, StockReleased, OrderPlaced, OrderFailed, OrderCancelled, UserDeactivated
 func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
     // Extract event type
-    var baseEvent struct {
-        EventID   string `json:"event_id"`
-        EventType string `json:"event_type"`
-    }
-
-    if err := json.Unmarshal(message, &baseEvent); err != nil {
-        return fmt.Errorf("failed to unmarshal base event: %w", err)
+    baseEvent, err := events.ReadEnvelope(message)
+    if err != nil {
+        return err
     }
 
     eventID := baseEvent.EventID
@@ -79,6 +83,8 @@ func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
         handlerErr = eh.handleOrderFailed(ctx, message)
     case "OrderCancelled":
         handlerErr = eh.handleOrderCancelled(ctx, message)
+    case "UserDeactivated":
+        handlerErr = eh.handleUserDeactivated(ctx, message)
     default:
         log.Printf("Unknown event type: %s", eventType)
         return nil
@@ -101,32 +107,33 @@ func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
 // Why: When Products service reserves inventory, we create an inventory lock in cart
 // This prevents double-selling if multiple orders try to buy the same item
 func (eh *EventHandler) handleStockReserved(ctx context.Context, message []byte) error {
-    var event events.StockReservedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.StockReservedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal StockReservedEvent: %w", err)
     }
 
-    log.Printf("📨 StockReservedEvent received: Product %d, Quantity %d, Reservation %s",
-        event.ProductID, event.Quantity, event.ReservationID)
+    log.Printf("📨 StockReservedEvent received: Order %d, %d item(s)", event.OrderID, len(event.Items))
 
-    // If event has order_id, create inventory lock in our database
+    // If event has order_id, create inventory locks in our database
     if event.OrderID > 0 {
-        lock := &models.InventoryLock{
-            CartID:        fmt.Sprintf("order-%d", event.OrderID), // Link to order
-            ProductID:     event.ProductID,
-            Quantity:      event.Quantity,
-            ReservationID: event.ReservationID,
-            Status:        "locked", // locked = inventory reserved in products service
-            LockedAt:      time.Now(),
-        }
-
-        if err := eh.inventoryLockRepo.CreateLock(ctx, lock); err != nil {
-            log.Printf("❌ Failed to create inventory lock: %v", err)
-            return fmt.Errorf("failed to create inventory lock: %w", err)
+        for _, item := range event.Items {
+            lock := &models.InventoryLock{
+                CartID:        fmt.Sprintf("order-%d", event.OrderID), // Link to order
+                ProductID:     item.ProductID,
+                Quantity:      item.Quantity,
+                ReservationID: item.ReservationID,
+                Status:        "locked", // locked = inventory reserved in products service
+                LockedAt:      time.Now(),
+            }
+
+            if err := eh.inventoryLockRepo.CreateLock(ctx, lock); err != nil {
+                log.Printf("❌ Failed to create inventory lock: %v", err)
+                return fmt.Errorf("failed to create inventory lock: %w", err)
+            }
+
+            log.Printf("✓ Inventory lock created: Product %d, Reservation %s", item.ProductID, item.ReservationID)
         }
 
-        log.Printf("✓ Inventory lock created: Product %d, Reservation %s", event.ProductID, event.ReservationID)
-
         // Update saga state to reflect inventory locked
         if err := eh.sagaRepo.UpdateSagaStatus(ctx, event.CorrelationID, "inventory_locked"); err != nil {
             log.Printf("Failed to update saga status: %v", err)
@@ -140,8 +147,8 @@ func (eh *EventHandler) handleStockReserved(ctx context.Context, message []byte)
 // Why: When an order fails or is cancelled, Products service releases inventory
 // We need to remove the lock from our records
 func (eh *EventHandler) handleStockReleased(ctx context.Context, message []byte) error {
-    var event events.StockReleasedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.StockReleasedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal StockReleasedEvent: %w", err)
     }
 
@@ -170,8 +177,8 @@ func (eh *EventHandler) handleStockReleased(ctx context.Context, message []byte)
 // Why: Order was successfully created and inventory is reserved
 // We can now mark the saga as complete and clear the cart
 func (eh *EventHandler) handleOrderPlaced(ctx context.Context, message []byte) error {
-    var event events.OrderPlacedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderPlacedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderPlacedEvent: %w", err)
     }
 
@@ -202,8 +209,8 @@ func (eh *EventHandler) handleOrderPlaced(ctx context.Context, message []byte) e
 // Why: Order creation failed for some reason (payment, inventory issue, etc.)
 // We need to release all inventory locks and mark saga as compensating
 func (eh *EventHandler) handleOrderFailed(ctx context.Context, message []byte) error {
-    var event events.OrderFailedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderFailedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderFailedEvent: %w", err)
     }
 
@@ -227,12 +234,21 @@ func (eh *EventHandler) handleOrderFailed(ctx context.Context, message []byte) e
         // We just mark the saga state for our records
     }
 
+    // The order wasn't placed, so the coupon's use goes back to it
+    released, err := eh.couponRepo.ReleaseCheckoutCoupon(ctx, event.CorrelationID)
+    if err != nil {
+        return fmt.Errorf("failed to release coupon: %w", err)
+    }
+    if released {
+        log.Printf("✓ Coupon released for failed checkout %s", event.CorrelationID)
+    }
+
     return nil
 }
 
 func (eh *EventHandler) handleOrderCancelled(ctx context.Context, message []byte) error {
-    var event events.OrderCancelledEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderCancelledEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderCancelledEvent: %w", err)
     }
 
@@ -253,3 +269,47 @@ func (eh *EventHandler) handleOrderCancelled(ctx context.Context, message []byte
 
     return nil
 }
+
+// handleUserDeactivated handles UserDeactivatedEvent from the users service
+// Why: a deleted account's cart would keep its soft locks and coupon use until they expire, and
+// show up as an active cart. The stock and coupon go back first, so a failure is retried while
+// the cart is still active.
+func (eh *EventHandler) handleUserDeactivated(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.UserDeactivatedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal UserDeactivatedEvent: %w", err)
+    }
+
+    log.Printf("📨 UserDeactivatedEvent received: User %s, Reason: %s", event.UserID, event.Reason)
+
+    cart, err := eh.cartRepo.GetCartByUserID(ctx, event.UserID)
+    if errors.Is(err, sql.ErrNoRows) {
+        log.Printf("User %s has no active cart", event.UserID)
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get cart: %w", err)
+    }
+
+    if eh.locks != nil {
+        if err := eh.locks.ReleaseCart(ctx, cart.ID); err != nil {
+            return fmt.Errorf("failed to release soft locks of cart %s: %w", cart.ID, err)
+        }
+        if err := eh.inventoryLockRepo.ReleaseCartLocks(ctx, cart.ID); err != nil {
+            return fmt.Errorf("failed to release lock records of cart %s: %w", cart.ID, err)
+        }
+    }
+
+    if cart.CouponCode != nil {
+        if err := eh.couponRepo.RemoveCoupon(ctx, cart.ID); err != nil {
+            return fmt.Errorf("failed to release coupon of cart %s: %w", cart.ID, err)
+        }
+    }
+
+    if err := eh.cartRepo.DeleteCart(ctx, cart.ID); err != nil {
+        return fmt.Errorf("failed to abandon cart %s: %w", cart.ID, err)
+    }
+
+    log.Printf("✓ Cart %s of deactivated user %s abandoned", cart.ID, event.UserID)
+    return nil
+}
@@ -0,0 +1,47 @@
+package workers
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// LockExpirer expires inventory locks past their expires_at
+type LockExpirer interface {
+    ExpireLocks(ctx context.Context) (int64, error)
+}
+
+// LockExpiryWorker periodically expires stale cart inventory locks
+type LockExpiryWorker struct {
+    repo     LockExpirer
+    clock    clock.Clock
+    interval time.Duration
+}
+
+// NewLockExpiryWorker creates new lock expiry worker
+func NewLockExpiryWorker(repo LockExpirer, clk clock.Clock, interval time.Duration) *LockExpiryWorker {
+    return &LockExpiryWorker{
+        repo:     repo,
+        clock:    clk,
+        interval: interval,
+    }
+}
+
+// Start launches the worker loop; it stops when ctx is cancelled.
+func (w *LockExpiryWorker) Start(ctx context.Context) <-chan struct{} {
+    return clock.Every(ctx, w.clock, w.interval, false, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce expires locks a single time
+func (w *LockExpiryWorker) RunOnce(ctx context.Context) {
+    expired, err := w.repo.ExpireLocks(ctx)
+    if err != nil {
+        log.Printf("❌ Failed to expire inventory locks: %v", err)
+        return
+    }
+    if expired > 0 {
+        log.Printf("✓ Expired %d inventory lock(s)", expired)
+    }
+}
@@ -0,0 +1,77 @@
+package workers
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/cart/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// fakeLockRepo expires in-memory locks using the shared fake clock
+type fakeLockRepo struct {
+    clock *clock.Fake
+    locks []*models.InventoryLock
+    calls chan struct{}
+}
+
+func (r *fakeLockRepo) ExpireLocks(ctx context.Context) (int64, error) {
+    var expired int64
+    for _, lock := range r.locks {
+        if lock.IsExpired(r.clock.Now()) {
+            lock.Status = "expired"
+            expired++
+        }
+    }
+    r.calls <- struct{}{}
+    return expired, nil
+}
+
+func TestLockExpiryWorker_ExpiresOnlyPastDeadline(t *testing.T) {
+    // Arrange
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    lock := &models.InventoryLock{
+        Status:    "locked",
+        LockedAt:  fc.Now(),
+        ExpiresAt: fc.Now().Add(time.Hour),
+    }
+    repo := &fakeLockRepo{clock: fc, locks: []*models.InventoryLock{lock}, calls: make(chan struct{}, 1)}
+    worker := NewLockExpiryWorker(repo, fc, 30*time.Minute)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := worker.Start(ctx)
+    defer func() {
+        cancel()
+        <-done
+    }()
+
+    // Act: first tick at +30m, lock still valid
+    fc.Advance(30 * time.Minute)
+    waitForCall(t, repo.calls)
+
+    // Assert
+    if lock.Status != "locked" {
+        t.Fatalf("lock expired too early: %s", lock.Status)
+    }
+
+    // Act: second and third ticks at +60m and +90m
+    fc.Advance(30 * time.Minute)
+    waitForCall(t, repo.calls)
+    fc.Advance(30 * time.Minute)
+    waitForCall(t, repo.calls)
+
+    // Assert
+    if lock.Status != "expired" {
+        t.Fatalf("expected lock to be expired, got %s", lock.Status)
+    }
+}
+
+func waitForCall(t *testing.T, calls <-chan struct{}) {
+    t.Helper()
+    select {
+    case <-calls:
+    case <-time.After(time.Second):
+        t.Fatal("worker did not run")
+    }
+}
@@ -0,0 +1,36 @@
+package autoconfirm
+
+import "time"
+
+// Config holds the auto-confirmation policy
+type Config struct {
+    Window    time.Duration // time after placement before an unheld order is confirmed; 0 disables
+    Interval  time.Duration // how often the worker looks for orders to confirm
+    BatchSize int           // orders confirmed per run
+}
+
+// Enabled reports whether placed orders are auto-confirmed
+func (c Config) Enabled() bool {
+    return c.Window > 0
+}
+
+// DefaultConfig returns the policy defaults for an environment (ORDERS_ENV).
+// Why: development confirms quickly so flows can be tried end to end; production
+// leaves payment and fraud checks time to put a hold on the order first.
+func DefaultConfig(environment string) Config {
+    config := Config{
+        Window:    30 * time.Minute,
+        Interval:  1 * time.Minute,
+        BatchSize: 100,
+    }
+
+    switch environment {
+    case "development", "dev", "local":
+        config.Window = 2 * time.Minute
+        config.Interval = 15 * time.Second
+    case "staging":
+        config.Window = 10 * time.Minute
+    }
+
+    return config
+}
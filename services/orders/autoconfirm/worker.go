@@ -0,0 +1,91 @@
+package autoconfirm
+
+import (
+    "context"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// Store finds and confirms placed orders
+type Store interface {
+    ListAutoConfirmable(ctx context.Context, placedBefore time.Time, limit int) ([]*models.PlacedOrder, error)
+    ConfirmIfUnheld(ctx context.Context, orderID int64) (bool, error)
+    RevertConfirmation(ctx context.Context, orderID int64) error
+}
+
+// Publisher publishes order events
+type Publisher interface {
+    PublishOrderEventReliable(ctx context.Context, event interface{}) error
+}
+
+// Worker confirms placed orders once the window has passed without a payment/fraud hold
+type Worker struct {
+    store     Store
+    publisher Publisher
+    clock     clock.Clock
+    config    Config
+}
+
+// NewWorker creates new auto-confirmation worker
+func NewWorker(store Store, publisher Publisher, clk clock.Clock, config Config) *Worker {
+    return &Worker{
+        store:     store,
+        publisher: publisher,
+        clock:     clk,
+        config:    config,
+    }
+}
+
+// Start launches the worker loop; it stops when ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) <-chan struct{} {
+    return clock.Every(ctx, w.clock, w.config.Interval, false, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce confirms due orders a single time and returns how many were confirmed.
+// Why: the order is flipped to confirmed before publishing so the next run, or another
+// instance, can't publish OrderConfirmed for it twice.
+func (w *Worker) RunOnce(ctx context.Context) int {
+    due, err := w.store.ListAutoConfirmable(ctx, w.clock.Now().Add(-w.config.Window), w.config.BatchSize)
+    if err != nil {
+        log.Printf("❌ Failed to list orders for auto-confirmation: %v", err)
+        return 0
+    }
+
+    confirmed := 0
+    for _, order := range due {
+        ok, err := w.store.ConfirmIfUnheld(ctx, order.OrderID)
+        if err != nil {
+            log.Printf("❌ Failed to auto-confirm order %d: %v", order.OrderID, err)
+            continue
+        }
+        if !ok {
+            // Held, cancelled or confirmed since it was listed
+            continue
+        }
+
+        event := events.OrderConfirmedEvent{
+            BaseEvent: events.NewBaseEvent("OrderConfirmed", strconv.FormatInt(order.OrderID, 10), "order", order.SagaCorrelationID),
+            OrderID:   order.OrderID,
+        }
+        if err := w.publisher.PublishOrderEventReliable(ctx, event); err != nil {
+            // Put it back to placed so the next run retries instead of leaving it confirmed silently
+            log.Printf("❌ Failed to publish OrderConfirmedEvent for order %d: %v", order.OrderID, err)
+            if err := w.store.RevertConfirmation(ctx, order.OrderID); err != nil {
+                log.Printf("❌ Failed to revert auto-confirmation of order %d: %v", order.OrderID, err)
+            }
+            continue
+        }
+
+        confirmed++
+    }
+
+    if confirmed > 0 {
+        log.Printf("✓ Auto-confirmed %d order(s)", confirmed)
+    }
+    return confirmed
+}
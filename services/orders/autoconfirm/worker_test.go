@@ -0,0 +1,157 @@
+package autoconfirm
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// fakeOrder is a placed order in the fake store
+type fakeOrder struct {
+    placed models.PlacedOrder
+    status string
+    held   bool
+}
+
+type fakeStore struct {
+    orders map[int64]*fakeOrder
+}
+
+func (s *fakeStore) ListAutoConfirmable(ctx context.Context, placedBefore time.Time, limit int) ([]*models.PlacedOrder, error) {
+    due := []*models.PlacedOrder{}
+    for _, o := range s.orders {
+        if o.status == "placed" && !o.held && !o.placed.PlacedAt.After(placedBefore) {
+            placed := o.placed
+            due = append(due, &placed)
+        }
+    }
+    return due, nil
+}
+
+func (s *fakeStore) ConfirmIfUnheld(ctx context.Context, orderID int64) (bool, error) {
+    o := s.orders[orderID]
+    if o.status != "placed" || o.held {
+        return false, nil
+    }
+    o.status = "confirmed"
+    return true, nil
+}
+
+func (s *fakeStore) RevertConfirmation(ctx context.Context, orderID int64) error {
+    if o := s.orders[orderID]; o.status == "confirmed" {
+        o.status = "placed"
+    }
+    return nil
+}
+
+type fakePublisher struct {
+    published []events.OrderConfirmedEvent
+    err       error
+}
+
+func (p *fakePublisher) PublishOrderEventReliable(ctx context.Context, event interface{}) error {
+    if p.err != nil {
+        return p.err
+    }
+    p.published = append(p.published, event.(events.OrderConfirmedEvent))
+    return nil
+}
+
+func newTestWorker(fc *clock.Fake, orders ...*fakeOrder) (*Worker, *fakeStore, *fakePublisher) {
+    store := &fakeStore{orders: map[int64]*fakeOrder{}}
+    for _, o := range orders {
+        store.orders[o.placed.OrderID] = o
+    }
+    publisher := &fakePublisher{}
+    config := Config{Window: 30 * time.Minute, Interval: time.Minute, BatchSize: 10}
+    return NewWorker(store, publisher, fc, config), store, publisher
+}
+
+func TestWorker_ConfirmsOnlyAfterWindow(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        placed: models.PlacedOrder{OrderID: 1, SagaCorrelationID: "corr-1", PlacedAt: fc.Now()},
+        status: "placed",
+    }
+    worker, _, publisher := newTestWorker(fc, order)
+
+    fc.Advance(29 * time.Minute)
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Fatalf("confirmed %d orders inside the window, want 0", n)
+    }
+
+    fc.Advance(time.Minute)
+    if n := worker.RunOnce(context.Background()); n != 1 {
+        t.Fatalf("confirmed %d orders after the window, want 1", n)
+    }
+    if order.status != "confirmed" {
+        t.Errorf("status = %q, want confirmed", order.status)
+    }
+    if len(publisher.published) != 1 {
+        t.Fatalf("published %d events, want 1", len(publisher.published))
+    }
+    event := publisher.published[0]
+    if event.OrderID != 1 || event.EventType != "OrderConfirmed" || event.CorrelationID != "corr-1" {
+        t.Errorf("unexpected event: %+v", event)
+    }
+
+    // Already confirmed: nothing is published again
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Errorf("second run confirmed %d orders, want 0", n)
+    }
+}
+
+func TestWorker_SkipsHeldOrdersUntilReleased(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        placed: models.PlacedOrder{OrderID: 2, PlacedAt: fc.Now()},
+        status: "placed",
+        held:   true,
+    }
+    worker, _, publisher := newTestWorker(fc, order)
+
+    fc.Advance(time.Hour)
+    if n := worker.RunOnce(context.Background()); n != 0 || len(publisher.published) != 0 {
+        t.Fatalf("held order was confirmed")
+    }
+
+    order.held = false
+    if n := worker.RunOnce(context.Background()); n != 1 {
+        t.Fatalf("confirmed %d orders after release, want 1", n)
+    }
+}
+
+func TestWorker_RevertsWhenPublishFails(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        placed: models.PlacedOrder{OrderID: 3, PlacedAt: fc.Now()},
+        status: "placed",
+    }
+    worker, _, publisher := newTestWorker(fc, order)
+    publisher.err = errors.New("broker down")
+
+    fc.Advance(time.Hour)
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Fatalf("confirmed %d orders with a failing publisher, want 0", n)
+    }
+    if order.status != "placed" {
+        t.Errorf("status = %q, want placed so the next run retries", order.status)
+    }
+}
+
+func TestDefaultConfig_PerEnvironment(t *testing.T) {
+    if dev, prod := DefaultConfig("development"), DefaultConfig("production"); dev.Window >= prod.Window {
+        t.Errorf("development window %s should be shorter than production %s", dev.Window, prod.Window)
+    }
+    if !DefaultConfig("").Enabled() {
+        t.Error("default config should be enabled")
+    }
+    if (Config{}).Enabled() {
+        t.Error("zero window should disable auto-confirmation")
+    }
+}
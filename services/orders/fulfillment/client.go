@@ -0,0 +1,110 @@
+package fulfillment
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "time"
+
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body
+const SignatureHeader = "X-Prost-Signature"
+
+// Config holds 3PL integration settings
+type Config struct {
+    Endpoint string        // 3PL order intake URL; empty disables the integration
+    Secret   string        // shared secret for signing outbound and verifying inbound payloads
+    SLA      time.Duration // promised time from placement to ship
+    Timeout  time.Duration
+}
+
+// Enabled reports whether an outbound endpoint is configured
+func (c Config) Enabled() bool {
+    return c.Endpoint != ""
+}
+
+// OrderPayload is what the 3PL receives for a placed order
+type OrderPayload struct {
+    OrderID         int64                    `json:"order_id"`
+    UserID          string                   `json:"user_id"`
+    CorrelationID   string                   `json:"correlation_id"`
+    Items           []sharedmodels.OrderItem `json:"items"`
+    Total           float64                  `json:"total"`
+    ShippingAddress *sharedmodels.Address    `json:"shipping_address,omitempty"` // nil for orders from before addresses
+    PlacedAt        time.Time                `json:"placed_at"`
+    ShipBy          time.Time                `json:"ship_by"` // SLA deadline
+}
+
+// Client pushes placed orders to the 3PL
+type Client struct {
+    config     Config
+    httpClient *http.Client
+}
+
+// NewClient creates new 3PL client
+func NewClient(config Config) *Client {
+    if config.Timeout <= 0 {
+        config.Timeout = 10 * time.Second
+    }
+    return &Client{
+        config:     config,
+        httpClient: &http.Client{Timeout: config.Timeout},
+    }
+}
+
+// SLA returns the configured ship-by window
+func (c *Client) SLA() time.Duration {
+    return c.config.SLA
+}
+
+// PushOrder sends the order payload to the 3PL endpoint
+func (c *Client) PushOrder(ctx context.Context, payload OrderPayload) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return fmt.Errorf("failed to marshal fulfillment payload: %w", err)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Endpoint, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("failed to create fulfillment request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(SignatureHeader, Sign(body, c.config.Secret))
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("failed to push order to 3PL: %w", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        return fmt.Errorf("3PL returned status %d: %s", resp.StatusCode, string(respBody))
+    }
+
+    return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body using secret
+func Sign(body []byte, secret string) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write(body)
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks an inbound webhook signature in constant time
+func VerifySignature(body []byte, signature, secret string) bool {
+    if secret == "" || signature == "" {
+        return false
+    }
+    expected := Sign(body, secret)
+    return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/services/orders/saga"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// Admin stats defaults
+const (
+    defaultStatsDays   = 30
+    defaultStatsWeeks  = 12
+    defaultTopProducts = 10
+    maxTopProducts     = 50
+    defaultFunnelHours = 24
+    maxFunnelHours     = 24 * 30
+)
+
+// AdminHandler serves admin-only reporting endpoints
+type AdminHandler struct {
+    statsRepo *repository.StatsRepository
+}
+
+// NewAdminHandler creates new admin handler
+func NewAdminHandler(statsRepo *repository.StatsRepository) *AdminHandler {
+    return &AdminHandler{statsRepo: statsRepo}
+}
+
+// GetStats returns order counts, revenue, cancellation rate and top products
+// Query params: period=daily|weekly, from, to (RFC3339 or YYYY-MM-DD), top
+func (ah *AdminHandler) GetStats(c *gin.Context) {
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout)
+    defer cancel()
+
+    period := c.DefaultQuery("period", models.StatsPeriodDaily)
+    if period != models.StatsPeriodDaily && period != models.StatsPeriodWeekly {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid period",
+            Message: "period must be daily or weekly",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    from, to, err := parseStatsWindow(c, period)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid date range",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    top := defaultTopProducts
+    if raw := c.Query("top"); raw != "" {
+        top, err = strconv.Atoi(raw)
+        if err != nil || top < 1 {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid top",
+                Message: "top must be a positive integer",
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        if top > maxTopProducts {
+            top = maxTopProducts
+        }
+    }
+
+    buckets, err := ah.statsRepo.GetOrderBuckets(ctx, period, from, to)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get order stats",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    topProducts, err := ah.statsRepo.GetTopProducts(ctx, from, to, top)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get top products",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.AdminStats{
+        Period:      period,
+        From:        from,
+        To:          to,
+        Buckets:     buckets,
+        Totals:      models.NewStatsTotals(buckets),
+        TopProducts: topProducts,
+    })
+}
+
+// GetFunnel returns sagas completed, failed (by reason category) and confirmed revenue
+// over the last ?hours= (default 24)
+func (ah *AdminHandler) GetFunnel(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    hours := defaultFunnelHours
+    if raw := c.Query("hours"); raw != "" {
+        parsed, err := strconv.Atoi(raw)
+        if err != nil || parsed < 1 || parsed > maxFunnelHours {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid hours",
+                Message: "hours must be between 1 and 720",
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        hours = parsed
+    }
+
+    to := time.Now().UTC()
+    funnel, failures, err := ah.statsRepo.GetSagaOutcomes(ctx, to.Add(-time.Duration(hours)*time.Hour), to)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get funnel",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    // Same categories as prost_sagas_failed_total{reason}
+    for reason, count := range failures {
+        funnel.FailuresByReason[saga.FailureCategory(reason)] += count
+    }
+
+    c.JSON(http.StatusOK, funnel)
+}
+
+// parseStatsWindow reads from/to, defaulting to the last 30 days (daily) or 12 weeks (weekly)
+func parseStatsWindow(c *gin.Context, period string) (time.Time, time.Time, error) {
+    now := time.Now().UTC()
+    to := now
+    if raw := c.Query("to"); raw != "" {
+        t, dateOnly, err := parseFilterTime(raw)
+        if err != nil {
+            return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+        }
+        if dateOnly {
+            t = t.AddDate(0, 0, 1)
+        }
+        to = t
+    }
+
+    from := to.AddDate(0, 0, -defaultStatsDays)
+    if period == models.StatsPeriodWeekly {
+        from = to.AddDate(0, 0, -7*defaultStatsWeeks)
+    }
+    if raw := c.Query("from"); raw != "" {
+        t, _, err := parseFilterTime(raw)
+        if err != nil {
+            return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+        }
+        from = t
+    }
+
+    if !from.Before(to) {
+        return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+    }
+    return from, to, nil
+}
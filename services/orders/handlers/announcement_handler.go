@@ -0,0 +1,110 @@
+package handlers
+
+import (
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// AnnouncementHandler publishes and lists storefront announcements
+type AnnouncementHandler struct {
+    announcementRepo *repository.AnnouncementRepository
+    eventPublisher   *messaging.Publisher
+    clock            clock.Clock
+}
+
+// NewAnnouncementHandler creates new announcement handler
+func NewAnnouncementHandler(announcementRepo *repository.AnnouncementRepository, eventPublisher *messaging.Publisher, clk clock.Clock) *AnnouncementHandler {
+    return &AnnouncementHandler{
+        announcementRepo: announcementRepo,
+        eventPublisher:   eventPublisher,
+        clock:            clk,
+    }
+}
+
+// PublishAnnouncement stores an announcement and broadcasts AnnouncementPublished
+func (ah *AnnouncementHandler) PublishAnnouncement(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.PublishAnnouncementRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    announcement, ok := models.NewAnnouncement(&req, c.GetString("user_id"), ah.clock.Now())
+    if !ok {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid announcement window",
+            Message: "ends_at must be after starts_at and in the future",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := ah.announcementRepo.CreateAnnouncement(ctx, announcement); err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to publish announcement",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    // Why: a plain publish, not PublishReliable; announcement listeners come and go (gateway
+    // instances), and an announcement nobody hears is still stored and listed.
+    event := events.AnnouncementPublishedEvent{
+        BaseEvent:      events.NewBaseEvent("AnnouncementPublished", strconv.FormatInt(announcement.ID, 10), "announcement", fmt.Sprintf("announcement-%d", announcement.ID)),
+        AnnouncementID: announcement.ID,
+        Kind:           announcement.Kind,
+        Title:          announcement.Title,
+        Message:        announcement.Message,
+        StartsAt:       announcement.StartsAt,
+        EndsAt:         announcement.EndsAt,
+    }
+    if err := ah.eventPublisher.PublishOrderEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish AnnouncementPublished for announcement %d: %v", announcement.ID, err)
+    }
+
+    log.Printf("✓ Announcement published: %d (%s: %s)", announcement.ID, announcement.Kind, announcement.Title)
+
+    c.JSON(http.StatusCreated, announcement)
+}
+
+// GetAnnouncements lists the announcements currently shown on the storefront
+func (ah *AnnouncementHandler) GetAnnouncements(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    announcements, err := ah.announcementRepo.ListActiveAnnouncements(ctx, ah.clock.Now())
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to list announcements",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "announcements": announcements,
+        "count":         len(announcements),
+    })
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// CheckoutHandler serves checkouts split into several orders
+type CheckoutHandler struct {
+    checkoutRepo *repository.CheckoutRepository
+}
+
+// NewCheckoutHandler creates new checkout handler
+func NewCheckoutHandler(checkoutRepo *repository.CheckoutRepository) *CheckoutHandler {
+    return &CheckoutHandler{checkoutRepo: checkoutRepo}
+}
+
+// GetCheckout returns a checkout with its orders and combined status, to its user or an admin
+func (ch *CheckoutHandler) GetCheckout(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    checkoutID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid checkout id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    checkout, err := ch.checkoutRepo.GetCheckout(ctx, checkoutID)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        switch {
+        case errors.Is(err, repository.ErrCheckoutNotFound):
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get checkout",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+    if historyAccessDenied(c, checkout.UserID) {
+        return
+    }
+
+    c.JSON(http.StatusOK, checkout)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/fulfillment"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ShipmentPublisher publishes OrderShipped; *messaging.Publisher in production
+type ShipmentPublisher interface {
+    PublishOrderEvent(ctx context.Context, event interface{}) error
+}
+
+// FulfillmentHandler handles inbound 3PL webhooks
+type FulfillmentHandler struct {
+    orderRepo      repository.OrderRepositoryInterface
+    eventPublisher ShipmentPublisher
+    webhookSecret  string
+}
+
+// NewFulfillmentHandler creates new fulfillment handler
+func NewFulfillmentHandler(
+    orderRepo repository.OrderRepositoryInterface,
+    eventPublisher ShipmentPublisher,
+    webhookSecret string,
+) *FulfillmentHandler {
+    return &FulfillmentHandler{
+        orderRepo:      orderRepo,
+        eventPublisher: eventPublisher,
+        webhookSecret:  webhookSecret,
+    }
+}
+
+// ShipmentCallback advances an order to shipped with the 3PL's tracking data
+// Why: the body is HMAC-signed with the shared secret so only the 3PL can ship orders
+func (fh *FulfillmentHandler) ShipmentCallback(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    body, err := io.ReadAll(c.Request.Body)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if !fulfillment.VerifySignature(body, c.GetHeader(fulfillment.SignatureHeader), fh.webhookSecret) {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "invalid signature",
+            Message: "webhook signature verification failed",
+            Code:    http.StatusUnauthorized,
+        })
+        return
+    }
+
+    // Restore body so gin validation tags apply
+    c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+    var req models.ShipmentCallbackRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    shippedAt := time.Now().UTC()
+    if req.ShippedAt != nil {
+        shippedAt = req.ShippedAt.UTC()
+    }
+
+    order, err := fh.orderRepo.GetOrder(ctx, req.OrderID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "order not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    // A redelivered callback for an order already shipped (or delivered) changes nothing and
+    // mustn't publish OrderShipped again
+    if order.Status == "shipped" || order.Status == "delivered" {
+        log.Printf("Order %d already %s, ignoring repeated shipment callback", req.OrderID, order.Status)
+        c.JSON(http.StatusOK, gin.H{
+            "message":         "Order already shipped",
+            "order_id":        req.OrderID,
+            "tracking_number": order.TrackingNumber,
+        })
+        return
+    }
+
+    if err := fh.orderRepo.MarkOrderShipped(ctx, req.OrderID, req.TrackingNumber, req.Carrier, shippedAt); err != nil {
+        c.JSON(http.StatusConflict, models.ErrorResponse{
+            Error:   "failed to mark order shipped",
+            Message: err.Error(),
+            Code:    http.StatusConflict,
+        })
+        return
+    }
+
+    shippedEvent := events.OrderShippedEvent{
+        BaseEvent:      events.NewBaseEvent("OrderShipped", fmt.Sprintf("%d", req.OrderID), "order", order.SagaCorrelationID),
+        OrderID:        req.OrderID,
+        TrackingNumber: req.TrackingNumber,
+        Carrier:        req.Carrier,
+        ShippedAt:      shippedAt,
+    }
+    if err := fh.eventPublisher.PublishOrderEvent(ctx, shippedEvent); err != nil {
+        log.Printf("Failed to publish OrderShippedEvent: %v", err)
+    }
+
+    log.Printf("✓ Order %d shipped via %s (tracking %s)", req.OrderID, req.Carrier, req.TrackingNumber)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":         "Order marked as shipped",
+        "order_id":        req.OrderID,
+        "tracking_number": req.TrackingNumber,
+    })
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/fulfillment"
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+const testWebhookSecret = "3pl-secret"
+
+// shipmentCallback posts a signed 3PL shipment callback with body to handler
+func shipmentCallback(t *testing.T, handler *FulfillmentHandler, body string) *httptest.ResponseRecorder {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.POST("/webhooks/fulfillment/shipments", handler.ShipmentCallback)
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPost, "/webhooks/fulfillment/shipments", strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(fulfillment.SignatureHeader, fulfillment.Sign([]byte(body), testWebhookSecret))
+    router.ServeHTTP(rec, req)
+    return rec
+}
+
+func TestShipmentCallbackShipsAPlacedOrder(t *testing.T) {
+    orders := &MockOrderRepository{
+        GetOrderFunc: func(ctx context.Context, orderID int64) (*models.Order, error) {
+            return &models.Order{ID: orderID, Status: "confirmed"}, nil
+        },
+    }
+    shipped := 0
+    orders.MarkOrderShippedFunc = func(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error {
+        shipped++
+        return nil
+    }
+    publisher := &MockPublisher{}
+
+    rec := shipmentCallback(t, NewFulfillmentHandler(orders, publisher, testWebhookSecret), `{"order_id": 9, "tracking_number": "1Z9", "carrier": "ups"}`)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    if shipped != 1 || len(publisher.Events) != 1 {
+        t.Errorf("marked shipped %d times and published %d events, want 1 and 1", shipped, len(publisher.Events))
+    }
+}
+
+func TestShipmentCallbackRedeliveredIsANoOp(t *testing.T) {
+    tracking := "1Z9"
+    orders := &MockOrderRepository{
+        GetOrderFunc: func(ctx context.Context, orderID int64) (*models.Order, error) {
+            return &models.Order{ID: orderID, Status: "shipped", TrackingNumber: &tracking}, nil
+        },
+        MarkOrderShippedFunc: func(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error {
+            t.Error("an order already shipped was marked shipped again")
+            return nil
+        },
+    }
+    publisher := &MockPublisher{}
+
+    rec := shipmentCallback(t, NewFulfillmentHandler(orders, publisher, testWebhookSecret), `{"order_id": 9, "tracking_number": "1Z9", "carrier": "ups"}`)
+
+    if rec.Code != http.StatusOK {
+        t.Errorf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    if len(publisher.Events) != 0 {
+        t.Errorf("published %d events for a redelivered callback, want none", len(publisher.Events))
+    }
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// HoldHandler manages payment/fraud holds that block auto-confirmation
+type HoldHandler struct {
+    holdRepo *repository.HoldRepository
+}
+
+// NewHoldHandler creates new order hold handler
+func NewHoldHandler(holdRepo *repository.HoldRepository) *HoldHandler {
+    return &HoldHandler{holdRepo: holdRepo}
+}
+
+// GetHolds lists an order's holds, active and released
+func (hh *HoldHandler) GetHolds(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, ok := parseOrderID(c)
+    if !ok {
+        return
+    }
+
+    holds, err := hh.holdRepo.ListHolds(ctx, orderID)
+    if err != nil {
+        respondHoldError(c, "failed to list holds", err)
+        return
+    }
+
+    active := 0
+    for _, hold := range holds {
+        if hold.IsActive() {
+            active++
+        }
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "order_id": orderID,
+        "holds":    holds,
+        "active":   active,
+    })
+}
+
+// ListActiveHolds lists the holds still blocking orders, optionally of one reason
+// (GET /admin/holds?reason=account_closed is the review queue of deleted accounts)
+func (hh *HoldHandler) ListActiveHolds(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    reason := c.Query("reason")
+    switch reason {
+    case "", models.HoldReasonPayment, models.HoldReasonFraud, models.HoldReasonAccountClosed:
+    default:
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid reason",
+            Message: "reason must be payment, fraud or account_closed",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    limit := 100
+    if raw := c.Query("limit"); raw != "" {
+        n, err := strconv.Atoi(raw)
+        if err != nil || n < 1 || n > 500 {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid limit",
+                Message: "limit must be between 1 and 500",
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        limit = n
+    }
+
+    holds, err := hh.holdRepo.ListActiveHolds(ctx, reason, limit)
+    if err != nil {
+        respondHoldError(c, "failed to list holds", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "holds": holds,
+        "count": len(holds),
+    })
+}
+
+// PlaceHold puts a placed order on hold so it isn't auto-confirmed
+func (hh *HoldHandler) PlaceHold(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, ok := parseOrderID(c)
+    if !ok {
+        return
+    }
+
+    var req models.PlaceHoldRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    hold, err := hh.holdRepo.PlaceHold(ctx, orderID, req.Reason, req.Note, c.GetString("user_id"))
+    if err != nil {
+        respondHoldError(c, "failed to place hold", err)
+        return
+    }
+
+    log.Printf("✓ Order %d put on %s hold", orderID, hold.Reason)
+
+    c.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseHold releases the order's active hold for a reason, or all of them.
+// The order auto-confirms on a later run once its window has passed.
+func (hh *HoldHandler) ReleaseHold(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, ok := parseOrderID(c)
+    if !ok {
+        return
+    }
+
+    var req models.ReleaseHoldRequest
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid request body",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    released, err := hh.holdRepo.ReleaseHolds(ctx, orderID, req.Reason, c.GetString("user_id"))
+    if err != nil {
+        respondHoldError(c, "failed to release hold", err)
+        return
+    }
+
+    log.Printf("✓ Released %d hold(s) on order %d", len(released), orderID)
+
+    c.JSON(http.StatusOK, gin.H{
+        "order_id": orderID,
+        "released": released,
+    })
+}
+
+func parseOrderID(c *gin.Context) (int64, bool) {
+    orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid order id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return 0, false
+    }
+    return orderID, true
+}
+
+// respondHoldError maps repository errors to HTTP statuses
+func respondHoldError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrOrderNotFound), errors.Is(err, repository.ErrHoldNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrOrderNotHoldable), errors.Is(err, repository.ErrHoldExists):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
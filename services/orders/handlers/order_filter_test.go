@@ -0,0 +1,103 @@
+package handlers
+
+import (
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+func filterFromQuery(t *testing.T, rawQuery string) (models.OrderFilter, error) {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+    c, _ := gin.CreateTestContext(httptest.NewRecorder())
+    c.Request = httptest.NewRequest("GET", "/orders?"+rawQuery, nil)
+    return parseOrderFilter(c)
+}
+
+func TestParseOrderFilter_Defaults(t *testing.T) {
+    filter, err := filterFromQuery(t, "user_id=u1")
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if filter.Page != 1 || filter.Limit != models.DefaultOrderPageLimit || filter.Sort != models.SortCreatedDesc {
+        t.Fatalf("unexpected defaults: %+v", filter)
+    }
+    if filter.Offset() != 0 {
+        t.Fatalf("expected offset 0, got %d", filter.Offset())
+    }
+}
+
+func TestParseOrderFilter_AllParams(t *testing.T) {
+    filter, err := filterFromQuery(t,
+        "user_id=u1&status=placed,Shipped&status=cancelled&from=2025-01-01&to=2025-01-31"+
+            "&min_total=10&max_total=99.5&page=3&limit=500&sort=total_asc")
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if len(filter.Statuses) != 3 || filter.Statuses[1] != "shipped" {
+        t.Fatalf("unexpected statuses: %v", filter.Statuses)
+    }
+    if !filter.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+        t.Fatalf("unexpected from: %v", filter.From)
+    }
+    // Date-only "to" includes the whole day
+    if !filter.To.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+        t.Fatalf("unexpected to: %v", filter.To)
+    }
+    if *filter.MinTotal != 10 || *filter.MaxTotal != 99.5 {
+        t.Fatalf("unexpected totals: %v %v", *filter.MinTotal, *filter.MaxTotal)
+    }
+    if filter.Limit != models.MaxOrderPageLimit {
+        t.Fatalf("expected limit capped at %d, got %d", models.MaxOrderPageLimit, filter.Limit)
+    }
+    if filter.Offset() != 2*models.MaxOrderPageLimit {
+        t.Fatalf("unexpected offset %d", filter.Offset())
+    }
+    if filter.Sort != models.SortTotalAsc {
+        t.Fatalf("unexpected sort %s", filter.Sort)
+    }
+}
+
+func TestParseOrderFilter_Invalid(t *testing.T) {
+    cases := []string{
+        "user_id=u1&status=lost",
+        "user_id=u1&from=yesterday",
+        "user_id=u1&from=2025-02-01&to=2025-01-01",
+        "user_id=u1&min_total=50&max_total=10",
+        "user_id=u1&page=0",
+        "user_id=u1&limit=abc",
+        "user_id=u1&sort=id%3B%20DROP%20TABLE%20orders",
+    }
+
+    for _, query := range cases {
+        if _, err := filterFromQuery(t, query); err == nil {
+            t.Errorf("expected error for %q", query)
+        }
+    }
+}
+
+func TestGetOrdersPage_RejectsCursorOfAnotherSort(t *testing.T) {
+    // Arrange: a cursor taken while sorting by total
+    after := models.OrderCursor(models.SortTotalDesc)(&models.Order{ID: 7, Total: 42.5}).Encode()
+    gin.SetMode(gin.TestMode)
+    rec := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(rec)
+    c.Request = httptest.NewRequest("GET", "/orders?user_id=u1&first=10&after="+after, nil)
+    filter, err := parseOrderFilter(c)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    // Act: continue it in the default, newest-first order
+    (&OrderHandler{}).getOrdersPage(c.Request.Context(), c, filter)
+
+    // Assert
+    if rec.Code != 400 {
+        t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+    }
+}
@@ -3,9 +3,11 @@ package handlers
 
 import (
     "context"
+    "errors"
     "log"
     "net/http"
     "strconv"
+    "strings"
     "time"
     "fmt"
 
@@ -13,30 +15,34 @@ import (
     "github.com/sanketh-sg/prost/services/orders/models"
     "github.com/sanketh-sg/prost/services/orders/repository"
     "github.com/sanketh-sg/prost/services/orders/saga"
+    "github.com/sanketh-sg/prost/shared/audit"
     "github.com/sanketh-sg/prost/shared/db"
-    "github.com/sanketh-sg/prost/shared/messaging"
     "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/pagination"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+    "github.com/sanketh-sg/prost/shared/versioning"
 )
 
 // OrderHandler handles order-related HTTP requests
 type OrderHandler struct {
-    orderRepo         *repository.OrderRepository
-    sagaRepo          *repository.SagaStateRepository
-    compensationRepo  *repository.CompensationLogRepository
-    inventoryResRepo  *repository.InventoryReservationRepository
+    orderRepo         repository.OrderRepositoryInterface
+    sagaRepo          repository.SagaStateRepositoryInterface
+    compensationRepo  repository.CompensationLogRepositoryInterface
+    inventoryResRepo  repository.InventoryReservationRepositoryInterface
     idempotencyStore  *db.IdempotencyStore
-    eventPublisher    *messaging.Publisher
+    eventPublisher    saga.Publisher
     sagaOrchestrator  *saga.SagaOrchestrator
+    audit             audit.Recorder // nil records no audit entries
 }
 
 // NewOrderHandler creates new order handler
 func NewOrderHandler(
-    orderRepo *repository.OrderRepository,
-    sagaRepo *repository.SagaStateRepository,
-    compensationRepo *repository.CompensationLogRepository,
-    inventoryResRepo *repository.InventoryReservationRepository,
+    orderRepo repository.OrderRepositoryInterface,
+    sagaRepo repository.SagaStateRepositoryInterface,
+    compensationRepo repository.CompensationLogRepositoryInterface,
+    inventoryResRepo repository.InventoryReservationRepositoryInterface,
     idempotencyStore *db.IdempotencyStore,
-    eventPublisher *messaging.Publisher,
+    eventPublisher saga.Publisher,
     sagaOrchestrator *saga.SagaOrchestrator,
 ) *OrderHandler {
     return &OrderHandler{
@@ -50,6 +56,11 @@ func NewOrderHandler(
     }
 }
 
+// SetAuditRecorder makes the handler record cancelled orders in the audit log
+func (oh *OrderHandler) SetAuditRecorder(recorder audit.Recorder) {
+    oh.audit = recorder
+}
+
 // Health handles health check
 func (oh *OrderHandler) Health(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{
@@ -61,8 +72,7 @@ func (oh *OrderHandler) Health(c *gin.Context) {
 
 // GetOrder retrieves an order
 func (oh *OrderHandler) GetOrder(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     orderIDStr := c.Param("id")
@@ -85,16 +95,19 @@ func (oh *OrderHandler) GetOrder(c *gin.Context) {
         })
         return
     }
+    if orderAccessDenied(c, order) {
+        return
+    }
 
+    versioning.SetETag(c.Writer, strconv.FormatInt(order.ID, 10), order.Version)
     c.JSON(http.StatusOK, order)
 }
 
-// GetOrders retrieves orders for a user
+// GetOrders retrieves ?user_id='s order history; like GetUserOrders only the user themselves
+// or an admin may list it.
+// Supports status, from/to, min_total/max_total, page/limit and sort query params;
+// first/after return keyset pages instead.
 func (oh *OrderHandler) GetOrders(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-    defer cancel()
-
     userID := c.Query("user_id")
     if userID == "" {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -104,8 +117,47 @@ func (oh *OrderHandler) GetOrders(c *gin.Context) {
         })
         return
     }
+    if historyAccessDenied(c, userID) {
+        return
+    }
+
+    oh.listOrders(c, userID)
+}
+
+// GetUserOrders retrieves a user's order history, with the same query params as GetOrders.
+// Only the user themselves or an admin may list it.
+func (oh *OrderHandler) GetUserOrders(c *gin.Context) {
+    userID := c.Param("user_id")
+    if historyAccessDenied(c, userID) {
+        return
+    }
 
-    orders, err := oh.orderRepo.GetOrdersByUserID(ctx, userID)
+    oh.listOrders(c, userID)
+}
+
+// listOrders answers GetOrders and GetUserOrders for userID's order history
+func (oh *OrderHandler) listOrders(c *gin.Context, userID string) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    filter, err := parseOrderFilter(c)
+    filter.UserID = userID
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid filter",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    // ?first= and ?after= ask for a keyset page instead of ?page=
+    if c.Query("first") != "" || c.Query("after") != "" {
+        oh.getOrdersPage(ctx, c, filter)
+        return
+    }
+
+    orders, total, err := oh.orderRepo.GetOrdersByUserIDFiltered(ctx, filter)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to get orders",
@@ -115,16 +167,194 @@ func (oh *OrderHandler) GetOrders(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "orders": orders,
-        "count":  len(orders),
+    // Why: facets are a nice-to-have, so a failure here shouldn't fail the listing
+    statusCounts, err := oh.orderRepo.GetOrderStatusCounts(ctx, filter)
+    if err != nil {
+        log.Printf("⚠️  Failed to get order status counts: %v", err)
+        statusCounts = map[string]int{}
+    }
+
+    if orders == nil {
+        orders = []*models.Order{}
+    }
+
+    c.JSON(http.StatusOK, models.OrderHistoryResponse{
+        Orders:       orders,
+        Count:        len(orders),
+        NextPage:     filter.NextPage(total),
+        Total:        total,
+        Page:         filter.Page,
+        Limit:        filter.Limit,
+        StatusCounts: statusCounts,
     })
 }
 
+// getOrdersPage lists one keyset page of order history as a Relay-style connection with the
+// status facets. A cursor only continues the sort it was taken in.
+func (oh *OrderHandler) getOrdersPage(ctx context.Context, c *gin.Context, filter models.OrderFilter) {
+    page, err := pagination.ParsePage(c.Query("first"), c.Query("after"), models.DefaultOrderPageLimit, models.MaxOrderPageLimit)
+    if err == nil && page.After != nil && page.After.Sort != filter.Sort {
+        err = fmt.Errorf("cursor is for sort %q, not %q", page.After.Sort, filter.Sort)
+    }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid paging",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    orders, total, err := oh.orderRepo.GetOrdersPageByUserID(ctx, filter, page)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get orders",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    statusCounts, err := oh.orderRepo.GetOrderStatusCounts(ctx, filter)
+    if err != nil {
+        log.Printf("⚠️  Failed to get order status counts: %v", err)
+        statusCounts = map[string]int{}
+    }
+
+    c.JSON(http.StatusOK, models.OrderConnection{
+        Connection:   pagination.NewConnection(page, orders, models.OrderCursor(filter.Sort)),
+        TotalCount:   total,
+        StatusCounts: statusCounts,
+    })
+}
+
+// orderStatuses are the statuses accepted by the status filter
+var orderStatuses = map[string]bool{
+    "pending":         true,
+    "placed":          true,
+    "payment_pending": true,
+    "confirmed":       true,
+    "shipped":         true,
+    "delivered":       true,
+    "cancelled":       true,
+    "failed":          true,
+}
+
+// parseOrderFilter reads order history query params into an OrderFilter
+func parseOrderFilter(c *gin.Context) (models.OrderFilter, error) {
+    filter := models.OrderFilter{
+        UserID: c.Query("user_id"),
+        Page:   1,
+        Limit:  models.DefaultOrderPageLimit,
+        Sort:   models.SortCreatedDesc,
+    }
+
+    // status=placed,shipped or status=placed&status=shipped
+    for _, raw := range c.QueryArray("status") {
+        for _, status := range strings.Split(raw, ",") {
+            status = strings.ToLower(strings.TrimSpace(status))
+            if status == "" {
+                continue
+            }
+            if !orderStatuses[status] {
+                return filter, fmt.Errorf("unknown status %q", status)
+            }
+            filter.Statuses = append(filter.Statuses, status)
+        }
+    }
+
+    if raw := c.Query("from"); raw != "" {
+        from, _, err := parseFilterTime(raw)
+        if err != nil {
+            return filter, fmt.Errorf("invalid from: %w", err)
+        }
+        filter.From = &from
+    }
+
+    if raw := c.Query("to"); raw != "" {
+        to, dateOnly, err := parseFilterTime(raw)
+        if err != nil {
+            return filter, fmt.Errorf("invalid to: %w", err)
+        }
+        // to=2025-01-31 includes the whole day
+        if dateOnly {
+            to = to.AddDate(0, 0, 1)
+        }
+        filter.To = &to
+    }
+
+    if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+        return filter, fmt.Errorf("from must be before to")
+    }
+
+    var err error
+    if filter.MinTotal, err = parseOptionalFloat(c.Query("min_total")); err != nil {
+        return filter, fmt.Errorf("invalid min_total: %w", err)
+    }
+    if filter.MaxTotal, err = parseOptionalFloat(c.Query("max_total")); err != nil {
+        return filter, fmt.Errorf("invalid max_total: %w", err)
+    }
+    if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+        return filter, fmt.Errorf("min_total must not exceed max_total")
+    }
+
+    if raw := c.Query("page"); raw != "" {
+        page, err := strconv.Atoi(raw)
+        if err != nil || page < 1 {
+            return filter, fmt.Errorf("page must be a positive integer")
+        }
+        filter.Page = page
+    }
+
+    if raw := c.Query("limit"); raw != "" {
+        limit, err := strconv.Atoi(raw)
+        if err != nil || limit < 1 {
+            return filter, fmt.Errorf("limit must be a positive integer")
+        }
+        if limit > models.MaxOrderPageLimit {
+            limit = models.MaxOrderPageLimit
+        }
+        filter.Limit = limit
+    }
+
+    if raw := c.Query("sort"); raw != "" {
+        switch raw {
+        case models.SortCreatedDesc, models.SortCreatedAsc, models.SortTotalDesc, models.SortTotalAsc:
+            filter.Sort = raw
+        default:
+            return filter, fmt.Errorf("unknown sort %q", raw)
+        }
+    }
+
+    return filter, nil
+}
+
+// parseFilterTime accepts RFC3339 timestamps or YYYY-MM-DD dates (UTC)
+func parseFilterTime(raw string) (time.Time, bool, error) {
+    if t, err := time.Parse(time.RFC3339, raw); err == nil {
+        return t.UTC(), false, nil
+    }
+    t, err := time.Parse("2006-01-02", raw)
+    if err != nil {
+        return time.Time{}, false, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %q", raw)
+    }
+    return t, true, nil
+}
+
+func parseOptionalFloat(raw string) (*float64, error) {
+    if raw == "" {
+        return nil, nil
+    }
+    v, err := strconv.ParseFloat(raw, 64)
+    if err != nil {
+        return nil, err
+    }
+    return &v, nil
+}
+
 // GetSagaState retrieves saga state
 func (oh *OrderHandler) GetSagaState(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     correlationID := c.Param("correlation_id")
@@ -147,13 +377,156 @@ func (oh *OrderHandler) GetSagaState(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, saga)
+    c.JSON(http.StatusOK, models.NewSagaStateResponse(saga))
+}
+
+// GetCheckoutResult reports the outcome of a checkout by its correlation ID: pending while the
+// saga runs, succeeded with the order IDs once it completed, or failed with the reason
+func (oh *OrderHandler) GetCheckoutResult(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    correlationID := c.Param("correlation_id")
+    saga, err := oh.sagaRepo.GetSagaState(ctx, correlationID)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        switch {
+        case errors.Is(err, repository.ErrSagaNotFound):
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get checkout result",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    var orders []*models.Order
+    if saga.Status == "completed" {
+        if orders, err = oh.orderRepo.GetOrdersByCorrelationID(ctx, correlationID); err != nil {
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+                Error:   "failed to get checkout orders",
+                Message: err.Error(),
+                Code:    http.StatusInternalServerError,
+            })
+            return
+        }
+    }
+
+    c.JSON(http.StatusOK, models.NewCheckoutResult(saga, orders))
+}
+
+// GetSagaTimeline returns the saga's current state and every status change, with the event
+// and service behind each, so support can see where a checkout got stuck
+func (oh *OrderHandler) GetSagaTimeline(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    timeline, err := oh.sagaRepo.GetSagaTimeline(ctx, c.Param("correlation_id"))
+    if err != nil {
+        status := db.ErrorStatus(err)
+        switch {
+        case errors.Is(err, repository.ErrSagaNotFound):
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get saga timeline",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, timeline)
+}
+
+// GetSagaRecords returns the orders a saga created and their compensations, for support's
+// event timeline; a correlation ID without orders gets empty lists
+func (oh *OrderHandler) GetSagaRecords(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    correlationID := c.Param("correlation_id")
+    orders, err := oh.orderRepo.GetOrdersByCorrelationID(ctx, correlationID)
+    if err == nil && orders == nil {
+        orders = []*models.Order{}
+    }
+    var compensations []*models.CompensationLog
+    if err == nil {
+        compensations, err = oh.compensationRepo.GetCompensationLogsByCorrelationID(ctx, correlationID)
+    }
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get saga records",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.SagaRecords{
+        CorrelationID: correlationID,
+        Orders:        orders,
+        Compensations: compensations,
+    })
+}
+
+// ResumeSaga restarts a failed saga from its last completed step
+// Why: transient failures (DB/broker hiccups) shouldn't force the user to rebuild their cart
+func (oh *OrderHandler) ResumeSaga(c *gin.Context) {
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout)
+    defer cancel()
+
+    correlationID := c.Param("correlation_id")
+    if correlationID == "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "correlation_id required",
+            Message: "",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := oh.sagaRepo.GetSagaState(ctx, correlationID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "saga not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    step, err := oh.sagaOrchestrator.Resume(ctx, correlationID)
+    if err != nil {
+        if errors.Is(err, saga.ErrSagaNotResumable) {
+            c.JSON(http.StatusConflict, models.ErrorResponse{
+                Error:   "saga not resumable",
+                Message: err.Error(),
+                Code:    http.StatusConflict,
+            })
+            return
+        }
+        log.Printf("❌ Failed to resume saga %s: %v", correlationID, err)
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to resume saga",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusAccepted, gin.H{
+        "correlation_id": correlationID,
+        "resumed_step":   step,
+        "message":        "saga resumed",
+    })
 }
 
 // CancelOrder cancels an order
 func (oh *OrderHandler) CancelOrder(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     orderIDStr := c.Param("id")
@@ -188,9 +561,31 @@ func (oh *OrderHandler) CancelOrder(c *gin.Context) {
         })
         return
     }
+    if orderAccessDenied(c, order) {
+        return
+    }
+
+    etagID := strconv.FormatInt(order.ID, 10)
+    if !versioning.IfMatch(c.Request, etagID, order.Version) {
+        versioning.SetETag(c.Writer, etagID, order.Version)
+        c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{
+            Error:   "order has changed",
+            Message: "the order is no longer at the version in If-Match; get it again and retry",
+            Code:    http.StatusPreconditionFailed,
+        })
+        return
+    }
 
-    // Cancel order
-    if err := oh.orderRepo.CancelOrder(ctx, orderID); err != nil {
+    // Cancel order, only at the version read above
+    if err := oh.orderRepo.CancelOrder(ctx, orderID, order.Version); err != nil {
+        if errors.Is(err, repository.ErrOrderVersionConflict) {
+            c.JSON(http.StatusConflict, models.ErrorResponse{
+                Error:   "order changed",
+                Message: "the order was shipped, delivered or changed by another request; get it again and retry",
+                Code:    http.StatusConflict,
+            })
+            return
+        }
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to cancel order",
             Message: err.Error(),
@@ -215,15 +610,54 @@ func (oh *OrderHandler) CancelOrder(c *gin.Context) {
         OrderID:   fmt.Sprintf("%d", orderID),
         Reason:    req.Reason, // provided by user
     }
-    if err := oh.eventPublisher.PublishOrderEvent(ctx, cancelledEvent); err != nil {
+    if err := oh.eventPublisher.PublishOrderEventReliable(ctx, cancelledEvent); err != nil {
         log.Printf("Failed to publish OrderCancelledEvent: %v", err)
     }
 
     log.Printf("✓ Order cancelled: %d, Reason: %s", orderID, req.Reason)
+    audit.Write(ctx, oh.audit, audit.Entry{
+        Action:     audit.ActionOrderCancelled,
+        TargetType: "order",
+        TargetID:   orderIDStr,
+        Before:     audit.JSON(map[string]string{"status": order.Status}),
+        After:      audit.JSON(map[string]string{"status": "cancelled"}),
+        Details:    audit.JSON(map[string]string{"reason": req.Reason, "user_id": order.UserID}),
+    })
 
     c.JSON(http.StatusOK, gin.H{
         "message": "Order cancelled successfully",
         "order_id": orderID,
         "saga_correlation_id": order.SagaCorrelationID,
     })
-}
\ No newline at end of file
+}
+
+// adminRole may read and cancel any user's order
+const adminRole = "admin"
+
+// historyAccessDenied answers 403 unless the caller (identity.Require's user_id and role) is
+// userID or an admin, and reports whether it did
+func historyAccessDenied(c *gin.Context, userID string) bool {
+    if c.GetString("role") == adminRole || (userID != "" && c.GetString("user_id") == userID) {
+        return false
+    }
+    c.JSON(http.StatusForbidden, models.ErrorResponse{
+        Error:   "not your orders",
+        Message: "",
+        Code:    http.StatusForbidden,
+    })
+    return true
+}
+
+// orderAccessDenied answers 403 unless the caller (identity.Require's user_id and role) owns
+// order or is an admin, and reports whether it did
+func orderAccessDenied(c *gin.Context, order *models.Order) bool {
+    if c.GetString("role") == adminRole || (order.UserID != "" && c.GetString("user_id") == order.UserID) {
+        return false
+    }
+    c.JSON(http.StatusForbidden, models.ErrorResponse{
+        Error:   "not your order",
+        Message: "",
+        Code:    http.StatusForbidden,
+    })
+    return true
+}
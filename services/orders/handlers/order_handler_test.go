@@ -0,0 +1,337 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "strings"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+const testUserID = "user-1"
+
+type orderMocks struct {
+    orders       *MockOrderRepository
+    sagas        *MockSagaStateRepository
+    reservations *MockInventoryReservationRepository
+    publisher    *MockPublisher
+}
+
+func newTestOrderHandler() (*OrderHandler, *orderMocks) {
+    mocks := &orderMocks{
+        orders:       &MockOrderRepository{},
+        sagas:        &MockSagaStateRepository{},
+        reservations: &MockInventoryReservationRepository{},
+        publisher:    &MockPublisher{},
+    }
+    handler := NewOrderHandler(mocks.orders, mocks.sagas, &MockCompensationLogRepository{}, mocks.reservations, nil, mocks.publisher, nil)
+    return handler, mocks
+}
+
+func checkoutResultOf(t *testing.T, handler *OrderHandler, correlationID string) (int, models.CheckoutResult) {
+    t.Helper()
+    rec := serve(t, handler.GetCheckoutResult, http.MethodGet, "/checkout/:correlation_id/result", "/checkout/"+correlationID+"/result", nil)
+    var result models.CheckoutResult
+    if rec.Code == http.StatusOK {
+        if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+            t.Fatalf("decode response: %v", err)
+        }
+    }
+    return rec.Code, result
+}
+
+func TestGetCheckoutResultUnknownSaga(t *testing.T) {
+    handler, _ := newTestOrderHandler()
+
+    status, _ := checkoutResultOf(t, handler, "corr-1")
+
+    if status != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", status)
+    }
+}
+
+func TestGetCheckoutResultPending(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.sagas.GetSagaStateFunc = func(ctx context.Context, correlationID string) (*models.SagaState, error) {
+        return &models.SagaState{CorrelationID: correlationID, Status: "pending"}, nil
+    }
+    mocks.orders.GetOrdersByCorrelationIDFunc = func(ctx context.Context, correlationID string) ([]*models.Order, error) {
+        t.Error("orders were read for a saga still running")
+        return nil, nil
+    }
+
+    status, result := checkoutResultOf(t, handler, "corr-1")
+
+    if status != http.StatusOK || result.Outcome != models.CheckoutOutcomePending {
+        t.Errorf("result = %d %+v, want 200 pending", status, result)
+    }
+}
+
+func TestGetCheckoutResultSucceeded(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.sagas.GetSagaStateFunc = func(ctx context.Context, correlationID string) (*models.SagaState, error) {
+        return &models.SagaState{CorrelationID: correlationID, Status: "completed"}, nil
+    }
+    mocks.orders.GetOrdersByCorrelationIDFunc = func(ctx context.Context, correlationID string) ([]*models.Order, error) {
+        return []*models.Order{{ID: 4}, {ID: 5}}, nil
+    }
+
+    status, result := checkoutResultOf(t, handler, "corr-1")
+
+    if status != http.StatusOK || result.Outcome != models.CheckoutOutcomeSucceeded {
+        t.Fatalf("result = %d %+v, want 200 succeeded", status, result)
+    }
+    if len(result.OrderIDs) != 2 || result.OrderID == nil || *result.OrderID != 4 {
+        t.Errorf("orders = %v (first %v), want 4 and 5", result.OrderIDs, result.OrderID)
+    }
+}
+
+func TestGetSagaStateDescribesTheStep(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    orderID := int64(4)
+    mocks.sagas.GetSagaStateFunc = func(ctx context.Context, correlationID string) (*models.SagaState, error) {
+        return &models.SagaState{CorrelationID: correlationID, Status: "checking_inventory", OrderID: &orderID, LastCompletedStep: "inventory_requested"}, nil
+    }
+
+    rec := serve(t, handler.GetSagaState, http.MethodGet, "/sagas/:correlation_id", "/sagas/corr-1", nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    var saga map[string]interface{}
+    if err := json.Unmarshal(rec.Body.Bytes(), &saga); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if saga["order_id"] != float64(4) || saga["status"] != "checking_inventory" {
+        t.Errorf("saga = %v", saga)
+    }
+    if saga["status_description"] != models.SagaStepDescription("checking_inventory") || saga["last_completed_step_description"] != models.SagaStepDescription("inventory_requested") {
+        t.Errorf("descriptions = %q, %q", saga["status_description"], saga["last_completed_step_description"])
+    }
+}
+
+func TestGetOrderNotFound(t *testing.T) {
+    handler, _ := newTestOrderHandler()
+
+    rec := serve(t, handler.GetOrder, http.MethodGet, "/orders/:id", "/orders/9", nil)
+
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", rec.Code)
+    }
+}
+
+func TestCancelOrderReleasesReservationsAndPublishes(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: testUserID, Status: "placed", SagaCorrelationID: "corr-1"}, nil
+    }
+    var cancelled int64
+    mocks.orders.CancelOrderFunc = func(ctx context.Context, orderID int64, version int) error {
+        cancelled = orderID
+        return nil
+    }
+    mocks.reservations.GetReservationsByOrderIDFunc = func(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error) {
+        return []*models.InventoryReservation{{ReservationID: "res-1"}, {ReservationID: "res-2"}}, nil
+    }
+    var released []string
+    mocks.reservations.ReleaseReservationFunc = func(ctx context.Context, reservationID string) error {
+        released = append(released, reservationID)
+        return nil
+    }
+
+    rec := serveAs(t, handler.CancelOrder, http.MethodPost, "/orders/:id/cancel", "/orders/9/cancel", testUserID, "customer", map[string]string{"reason": "changed my mind"})
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    if cancelled != 9 {
+        t.Errorf("cancelled order %d, want 9", cancelled)
+    }
+    if len(released) != 2 {
+        t.Errorf("released %v, want both reservations", released)
+    }
+    if len(mocks.publisher.Events) != 1 {
+        t.Fatalf("published %d events, want 1", len(mocks.publisher.Events))
+    }
+    event, ok := mocks.publisher.Events[0].(events.OrderCancelledEvent)
+    if !ok || event.OrderID != "9" || event.CorrelationID != "corr-1" || event.Reason != "changed my mind" {
+        t.Errorf("published %+v, want OrderCancelled for order 9 in saga corr-1", mocks.publisher.Events[0])
+    }
+}
+
+func TestCancelOrderNotFound(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.CancelOrderFunc = func(ctx context.Context, orderID int64, version int) error {
+        t.Error("cancelled an order that wasn't found")
+        return nil
+    }
+
+    rec := serveAs(t, handler.CancelOrder, http.MethodPost, "/orders/:id/cancel", "/orders/9/cancel", testUserID, "customer", map[string]string{})
+
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", rec.Code)
+    }
+    if len(mocks.publisher.Events) != 0 {
+        t.Error("published an event for an order that wasn't found")
+    }
+}
+
+func TestCancelOrderLosingTheVersionRaceIsAConflict(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: testUserID, Status: "placed", Version: 4}, nil
+    }
+    var cancelledAt int
+    mocks.orders.CancelOrderFunc = func(ctx context.Context, orderID int64, version int) error {
+        cancelledAt = version
+        return repository.ErrOrderVersionConflict
+    }
+
+    rec := serveAs(t, handler.CancelOrder, http.MethodPost, "/orders/:id/cancel", "/orders/9/cancel", testUserID, "customer", map[string]string{})
+
+    if rec.Code != http.StatusConflict {
+        t.Errorf("status = %d, want 409: %s", rec.Code, rec.Body)
+    }
+    if cancelledAt != 4 {
+        t.Errorf("cancelled at version %d, want the one read (4)", cancelledAt)
+    }
+    if len(mocks.publisher.Events) != 0 {
+        t.Error("published an event for a cancel that lost the race")
+    }
+}
+
+func TestGetOrderOfAnotherUserIsForbidden(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: "user-2", Status: "placed"}, nil
+    }
+
+    rec := serveAs(t, handler.GetOrder, http.MethodGet, "/orders/:id", "/orders/9", testUserID, "customer", nil)
+
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("status = %d, want 403", rec.Code)
+    }
+}
+
+func TestGetOrderAsAdmin(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: "user-2", Status: "placed", Version: 2}, nil
+    }
+
+    rec := serveAs(t, handler.GetOrder, http.MethodGet, "/orders/:id", "/orders/9", "admin-1", adminRole, nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    if etag := rec.Header().Get("ETag"); etag != `"9.2"` {
+        t.Errorf("ETag = %q, want the order's version", etag)
+    }
+}
+
+func TestCancelOrderOfAnotherUserIsForbidden(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: "user-2", Status: "placed"}, nil
+    }
+    mocks.orders.CancelOrderFunc = func(ctx context.Context, orderID int64, version int) error {
+        t.Error("cancelled another user's order")
+        return nil
+    }
+
+    rec := serveAs(t, handler.CancelOrder, http.MethodPost, "/orders/:id/cancel", "/orders/9/cancel", testUserID, "customer", map[string]string{})
+
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("status = %d, want 403", rec.Code)
+    }
+    if len(mocks.publisher.Events) != 0 {
+        t.Error("published an event for another user's order")
+    }
+}
+
+func TestRetryPaymentOfAnotherUserIsForbidden(t *testing.T) {
+    _, mocks := newTestOrderHandler()
+    mocks.orders.GetOrderFunc = func(ctx context.Context, orderID int64) (*models.Order, error) {
+        return &models.Order{ID: orderID, UserID: "user-2", Status: "payment_pending"}, nil
+    }
+    // No payment repository: the retry must be refused before it's requested
+    handler := NewPaymentHandler(mocks.orders, nil, nil)
+
+    rec := serveAs(t, handler.RetryPayment, http.MethodPost, "/orders/:id/retry-payment", "/orders/9/retry-payment", testUserID, "customer", nil)
+
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("status = %d, want 403", rec.Code)
+    }
+}
+
+func TestGetUserOrders(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrdersByUserIDFilteredFunc = func(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+        if filter.UserID != testUserID {
+            t.Errorf("listed orders of %q, want %q", filter.UserID, testUserID)
+        }
+        return []*models.Order{{ID: 1, UserID: testUserID}, {ID: 2, UserID: testUserID}}, 5, nil
+    }
+
+    rec := serveAs(t, handler.GetUserOrders, http.MethodGet, "/users/:user_id/orders", "/users/user-1/orders?page=2&limit=2", testUserID, "customer", nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    var history models.OrderHistoryResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if history.Count != 2 || history.NextPage == nil || *history.NextPage != 3 {
+        t.Errorf("count = %d, next_page = %v, want 2 and 3", history.Count, history.NextPage)
+    }
+
+    // The last page has no next one
+    rec = serveAs(t, handler.GetUserOrders, http.MethodGet, "/users/:user_id/orders", "/users/user-1/orders?page=3&limit=2", testUserID, "customer", nil)
+    if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"next_page":null`) {
+        t.Errorf("last page: %d %s", rec.Code, rec.Body)
+    }
+}
+
+func TestGetUserOrdersOfAnotherUser(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrdersByUserIDFilteredFunc = func(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+        return nil, 0, nil
+    }
+
+    rec := serveAs(t, handler.GetUserOrders, http.MethodGet, "/users/:user_id/orders", "/users/user-2/orders", testUserID, "customer", nil)
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("customer: status = %d, want 403", rec.Code)
+    }
+
+    rec = serveAs(t, handler.GetUserOrders, http.MethodGet, "/users/:user_id/orders", "/users/user-2/orders", "admin-1", adminRole, nil)
+    if rec.Code != http.StatusOK {
+        t.Errorf("admin: status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+}
+
+func TestGetOrdersOfAnotherUser(t *testing.T) {
+    handler, mocks := newTestOrderHandler()
+    mocks.orders.GetOrdersByUserIDFilteredFunc = func(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+        return nil, 0, nil
+    }
+
+    rec := serveAs(t, handler.GetOrders, http.MethodGet, "/orders", "/orders?user_id=user-2", testUserID, "customer", nil)
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("customer: status = %d, want 403", rec.Code)
+    }
+
+    rec = serveAs(t, handler.GetOrders, http.MethodGet, "/orders", "/orders?user_id=user-2", "", "", nil)
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("no caller: status = %d, want 403", rec.Code)
+    }
+
+    rec = serveAs(t, handler.GetOrders, http.MethodGet, "/orders", "/orders?user_id=user-2", "admin-1", adminRole, nil)
+    if rec.Code != http.StatusOK {
+        t.Errorf("admin: status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+}
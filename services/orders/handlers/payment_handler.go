@@ -0,0 +1,112 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// PaymentHandler lets customers retry the payment of a payment_pending order
+type PaymentHandler struct {
+    orderRepo      repository.OrderRepositoryInterface
+    paymentRepo    *repository.PaymentRepository
+    eventPublisher *messaging.Publisher
+}
+
+// NewPaymentHandler creates new payment handler
+func NewPaymentHandler(orderRepo repository.OrderRepositoryInterface, paymentRepo *repository.PaymentRepository, eventPublisher *messaging.Publisher) *PaymentHandler {
+    return &PaymentHandler{
+        orderRepo:      orderRepo,
+        paymentRepo:    paymentRepo,
+        eventPublisher: eventPublisher,
+    }
+}
+
+// RetryPayment asks the payment service to charge a payment_pending order again.
+// The result comes back as PaymentProcessed or PaymentFailed; the order's deadline doesn't move.
+func (ph *PaymentHandler) RetryPayment(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, ok := parseOrderID(c)
+    if !ok {
+        return
+    }
+
+    // Only the order's owner (or an admin) may have it charged again
+    order, err := ph.orderRepo.GetOrder(ctx, orderID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "order not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+    if orderAccessDenied(c, order) {
+        return
+    }
+
+    retry, err := ph.paymentRepo.RequestRetry(ctx, orderID)
+    if err != nil {
+        respondPaymentError(c, "failed to retry payment", err)
+        return
+    }
+
+    retryEvent := events.PaymentRetryRequestedEvent{
+        BaseEvent: events.NewBaseEvent("PaymentRetryRequested", strconv.FormatInt(orderID, 10), "order", retry.SagaCorrelationID),
+        OrderID:   orderID,
+        UserID:    retry.UserID,
+        Amount:    retry.Total,
+        Attempt:   retry.Attempt,
+    }
+    if err := ph.eventPublisher.PublishOrderEventReliable(ctx, retryEvent); err != nil {
+        log.Printf("❌ Failed to publish PaymentRetryRequestedEvent for order %d: %v", orderID, err)
+        if err := ph.paymentRepo.RevertRetry(ctx, orderID, retry.Attempt); err != nil {
+            log.Printf("❌ Failed to revert payment retry of order %d: %v", orderID, err)
+        }
+        c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+            Error:   "failed to retry payment",
+            Message: err.Error(),
+            Code:    http.StatusServiceUnavailable,
+        })
+        return
+    }
+
+    log.Printf("✓ Payment retry %d requested for order %d", retry.Attempt, orderID)
+
+    c.JSON(http.StatusAccepted, gin.H{
+        "message":          "Payment retry requested",
+        "order_id":         orderID,
+        "attempt":          retry.Attempt,
+        "payment_deadline": retry.Deadline,
+    })
+}
+
+// respondPaymentError maps repository errors to HTTP statuses
+func respondPaymentError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrOrderNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrPaymentNotPending),
+        errors.Is(err, repository.ErrPaymentWindowClosed),
+        errors.Is(err, repository.ErrPaymentRetryInProgress):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/services/orders/segmentation"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// SegmentHandler serves customer segment lookups for pricing/promotion engines
+type SegmentHandler struct {
+    segmentService *segmentation.Service
+    segmentRepo    *repository.SegmentRepository
+}
+
+// NewSegmentHandler creates new segment handler
+func NewSegmentHandler(segmentService *segmentation.Service, segmentRepo *repository.SegmentRepository) *SegmentHandler {
+    return &SegmentHandler{
+        segmentService: segmentService,
+        segmentRepo:    segmentRepo,
+    }
+}
+
+// GetUserSegments returns a user's segment tags
+func (sh *SegmentHandler) GetUserSegments(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.Param("user_id")
+    if userID == "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "user_id required",
+            Message: "",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if historyAccessDenied(c, userID) {
+        return
+    }
+
+    segments, err := sh.segmentService.GetSegments(ctx, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get segments",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, segments)
+}
+
+// GetSegmentUsers lists users in a segment (highest spend first)
+// Why: promotion campaigns target a segment rather than individual users
+func (sh *SegmentHandler) GetSegmentUsers(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    segment := c.Param("segment")
+
+    limit := 100
+    if raw := c.Query("limit"); raw != "" {
+        parsed, err := strconv.Atoi(raw)
+        if err != nil || parsed < 1 || parsed > 1000 {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid limit",
+                Message: "limit must be between 1 and 1000",
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        limit = parsed
+    }
+
+    userIDs, err := sh.segmentRepo.GetUserIDsBySegment(ctx, segment, limit)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get segment users",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "segment":  segment,
+        "user_ids": userIDs,
+        "count":    len(userIDs),
+    })
+}
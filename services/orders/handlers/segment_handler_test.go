@@ -0,0 +1,16 @@
+package handlers
+
+import (
+    "net/http"
+    "testing"
+)
+
+func TestGetUserSegmentsOfAnotherUser(t *testing.T) {
+    // Refused before the segments are computed, so no service is needed
+    handler := NewSegmentHandler(nil, nil)
+
+    rec := serveAs(t, handler.GetUserSegments, http.MethodGet, "/users/:user_id/segments", "/users/user-2/segments", testUserID, "customer", nil)
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("customer: status = %d, want 403", rec.Code)
+    }
+}
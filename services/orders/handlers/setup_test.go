@@ -0,0 +1,362 @@
+package handlers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+// MockOrderRepository is a mock implementation of OrderRepository
+type MockOrderRepository struct {
+    CreateOrderFunc               func(ctx context.Context, order *models.Order) error
+    GetOrderFunc                  func(ctx context.Context, orderID int64) (*models.Order, error)
+    GetOrdersByUserIDFunc         func(ctx context.Context, userID string) ([]*models.Order, error)
+    GetOrdersByCorrelationIDFunc  func(ctx context.Context, correlationID string) ([]*models.Order, error)
+    GetOrdersByUserIDFilteredFunc func(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error)
+    GetOrdersPageByUserIDFunc     func(ctx context.Context, filter models.OrderFilter, page pagination.Page) ([]*models.Order, int, error)
+    GetOrderStatusCountsFunc      func(ctx context.Context, filter models.OrderFilter) (map[string]int, error)
+    UpdateOrderStatusFunc         func(ctx context.Context, orderID int64, status string) error
+    CancelOrderFunc               func(ctx context.Context, orderID int64, version int) error
+    MarkOrderShippedFunc          func(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error
+    MarkOrderDeliveredFunc        func(ctx context.Context, orderID int64, deliveredAt time.Time) error
+}
+
+func (m *MockOrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+    if m.CreateOrderFunc != nil {
+        return m.CreateOrderFunc(ctx, order)
+    }
+    return nil
+}
+
+func (m *MockOrderRepository) GetOrder(ctx context.Context, orderID int64) (*models.Order, error) {
+    if m.GetOrderFunc != nil {
+        return m.GetOrderFunc(ctx, orderID)
+    }
+    return nil, repository.ErrOrderNotFound
+}
+
+func (m *MockOrderRepository) GetOrdersByUserID(ctx context.Context, userID string) ([]*models.Order, error) {
+    if m.GetOrdersByUserIDFunc != nil {
+        return m.GetOrdersByUserIDFunc(ctx, userID)
+    }
+    return nil, nil
+}
+
+func (m *MockOrderRepository) GetOrdersByCorrelationID(ctx context.Context, correlationID string) ([]*models.Order, error) {
+    if m.GetOrdersByCorrelationIDFunc != nil {
+        return m.GetOrdersByCorrelationIDFunc(ctx, correlationID)
+    }
+    return nil, nil
+}
+
+func (m *MockOrderRepository) GetOrdersByUserIDFiltered(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+    if m.GetOrdersByUserIDFilteredFunc != nil {
+        return m.GetOrdersByUserIDFilteredFunc(ctx, filter)
+    }
+    return nil, 0, nil
+}
+
+func (m *MockOrderRepository) GetOrdersPageByUserID(ctx context.Context, filter models.OrderFilter, page pagination.Page) ([]*models.Order, int, error) {
+    if m.GetOrdersPageByUserIDFunc != nil {
+        return m.GetOrdersPageByUserIDFunc(ctx, filter, page)
+    }
+    return nil, 0, nil
+}
+
+func (m *MockOrderRepository) GetOrderStatusCounts(ctx context.Context, filter models.OrderFilter) (map[string]int, error) {
+    if m.GetOrderStatusCountsFunc != nil {
+        return m.GetOrderStatusCountsFunc(ctx, filter)
+    }
+    return nil, nil
+}
+
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status string) error {
+    if m.UpdateOrderStatusFunc != nil {
+        return m.UpdateOrderStatusFunc(ctx, orderID, status)
+    }
+    return nil
+}
+
+func (m *MockOrderRepository) CancelOrder(ctx context.Context, orderID int64, version int) error {
+    if m.CancelOrderFunc != nil {
+        return m.CancelOrderFunc(ctx, orderID, version)
+    }
+    return nil
+}
+
+func (m *MockOrderRepository) MarkOrderShipped(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error {
+    if m.MarkOrderShippedFunc != nil {
+        return m.MarkOrderShippedFunc(ctx, orderID, trackingNumber, carrier, shippedAt)
+    }
+    return nil
+}
+
+func (m *MockOrderRepository) MarkOrderDelivered(ctx context.Context, orderID int64, deliveredAt time.Time) error {
+    if m.MarkOrderDeliveredFunc != nil {
+        return m.MarkOrderDeliveredFunc(ctx, orderID, deliveredAt)
+    }
+    return nil
+}
+
+// MockSagaStateRepository is a mock implementation of SagaStateRepository
+type MockSagaStateRepository struct {
+    CreateSagaStateFunc           func(ctx context.Context, saga *models.SagaState) error
+    GetSagaStateFunc              func(ctx context.Context, correlationID string) (*models.SagaState, error)
+    UpdateSagaStatusFunc          func(ctx context.Context, correlationID, status string) error
+    UpdateSagaOrderIDFunc         func(ctx context.Context, correlationID string, orderID int64) error
+    AddCompensationFunc           func(ctx context.Context, correlationID, compensation string) error
+    UpdateSagaPayloadFunc         func(ctx context.Context, correlationID string, payload map[string]interface{}) error
+    RecordCheckpointFunc          func(ctx context.Context, correlationID, step string) error
+    MarkSagaFailedFunc            func(ctx context.Context, correlationID, reason string) error
+    BeginResumeFunc               func(ctx context.Context, correlationID, status string) error
+    StartReservationTrackingFunc  func(ctx context.Context, correlationID string, progress *models.ReservationProgress) error
+    RecordReservedStockFunc       func(ctx context.Context, correlationID string, orderID int64, reserved []models.ReservedLine) (*models.ReservationProgress, error)
+    ListReservationTimedOutFunc   func(ctx context.Context, now time.Time, limit int) ([]string, error)
+    FailIfReservationTimedOutFunc func(ctx context.Context, correlationID string, now time.Time, reason string) (*models.ReservationProgress, error)
+    RevertReservationTimeoutFunc  func(ctx context.Context, correlationID string) error
+    GetSagaTimelineFunc           func(ctx context.Context, correlationID string) (*models.SagaTimeline, error)
+    ListTransitionsFunc           func(ctx context.Context, correlationID string) ([]models.SagaTransition, error)
+}
+
+func (m *MockSagaStateRepository) CreateSagaState(ctx context.Context, saga *models.SagaState) error {
+    if m.CreateSagaStateFunc != nil {
+        return m.CreateSagaStateFunc(ctx, saga)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error) {
+    if m.GetSagaStateFunc != nil {
+        return m.GetSagaStateFunc(ctx, correlationID)
+    }
+    return nil, repository.ErrSagaNotFound
+}
+
+func (m *MockSagaStateRepository) UpdateSagaStatus(ctx context.Context, correlationID, status string) error {
+    if m.UpdateSagaStatusFunc != nil {
+        return m.UpdateSagaStatusFunc(ctx, correlationID, status)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) UpdateSagaOrderID(ctx context.Context, correlationID string, orderID int64) error {
+    if m.UpdateSagaOrderIDFunc != nil {
+        return m.UpdateSagaOrderIDFunc(ctx, correlationID, orderID)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) AddCompensation(ctx context.Context, correlationID, compensation string) error {
+    if m.AddCompensationFunc != nil {
+        return m.AddCompensationFunc(ctx, correlationID, compensation)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) UpdateSagaPayload(ctx context.Context, correlationID string, payload map[string]interface{}) error {
+    if m.UpdateSagaPayloadFunc != nil {
+        return m.UpdateSagaPayloadFunc(ctx, correlationID, payload)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) RecordCheckpoint(ctx context.Context, correlationID, step string) error {
+    if m.RecordCheckpointFunc != nil {
+        return m.RecordCheckpointFunc(ctx, correlationID, step)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) MarkSagaFailed(ctx context.Context, correlationID, reason string) error {
+    if m.MarkSagaFailedFunc != nil {
+        return m.MarkSagaFailedFunc(ctx, correlationID, reason)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) BeginResume(ctx context.Context, correlationID, status string) error {
+    if m.BeginResumeFunc != nil {
+        return m.BeginResumeFunc(ctx, correlationID, status)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) StartReservationTracking(ctx context.Context, correlationID string, progress *models.ReservationProgress) error {
+    if m.StartReservationTrackingFunc != nil {
+        return m.StartReservationTrackingFunc(ctx, correlationID, progress)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) RecordReservedStock(ctx context.Context, correlationID string, orderID int64, reserved []models.ReservedLine) (*models.ReservationProgress, error) {
+    if m.RecordReservedStockFunc != nil {
+        return m.RecordReservedStockFunc(ctx, correlationID, orderID, reserved)
+    }
+    return nil, nil
+}
+
+func (m *MockSagaStateRepository) ListReservationTimedOut(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    if m.ListReservationTimedOutFunc != nil {
+        return m.ListReservationTimedOutFunc(ctx, now, limit)
+    }
+    return nil, nil
+}
+
+func (m *MockSagaStateRepository) FailIfReservationTimedOut(ctx context.Context, correlationID string, now time.Time, reason string) (*models.ReservationProgress, error) {
+    if m.FailIfReservationTimedOutFunc != nil {
+        return m.FailIfReservationTimedOutFunc(ctx, correlationID, now, reason)
+    }
+    return nil, nil
+}
+
+func (m *MockSagaStateRepository) RevertReservationTimeout(ctx context.Context, correlationID string) error {
+    if m.RevertReservationTimeoutFunc != nil {
+        return m.RevertReservationTimeoutFunc(ctx, correlationID)
+    }
+    return nil
+}
+
+func (m *MockSagaStateRepository) GetSagaTimeline(ctx context.Context, correlationID string) (*models.SagaTimeline, error) {
+    if m.GetSagaTimelineFunc != nil {
+        return m.GetSagaTimelineFunc(ctx, correlationID)
+    }
+    return nil, repository.ErrSagaNotFound
+}
+
+func (m *MockSagaStateRepository) ListTransitions(ctx context.Context, correlationID string) ([]models.SagaTransition, error) {
+    if m.ListTransitionsFunc != nil {
+        return m.ListTransitionsFunc(ctx, correlationID)
+    }
+    return nil, nil
+}
+
+// MockCompensationLogRepository is a mock implementation of CompensationLogRepository
+type MockCompensationLogRepository struct {
+    CreateCompensationLogFunc              func(ctx context.Context, log *models.CompensationLog) error
+    GetCompensationLogsByOrderIDFunc       func(ctx context.Context, orderID int64) ([]*models.CompensationLog, error)
+    GetCompensationLogsByCorrelationIDFunc func(ctx context.Context, correlationID string) ([]*models.CompensationLog, error)
+    UpdateCompensationStatusFunc           func(ctx context.Context, logID, status string) error
+}
+
+func (m *MockCompensationLogRepository) CreateCompensationLog(ctx context.Context, log *models.CompensationLog) error {
+    if m.CreateCompensationLogFunc != nil {
+        return m.CreateCompensationLogFunc(ctx, log)
+    }
+    return nil
+}
+
+func (m *MockCompensationLogRepository) GetCompensationLogsByOrderID(ctx context.Context, orderID int64) ([]*models.CompensationLog, error) {
+    if m.GetCompensationLogsByOrderIDFunc != nil {
+        return m.GetCompensationLogsByOrderIDFunc(ctx, orderID)
+    }
+    return nil, nil
+}
+
+func (m *MockCompensationLogRepository) GetCompensationLogsByCorrelationID(ctx context.Context, correlationID string) ([]*models.CompensationLog, error) {
+    if m.GetCompensationLogsByCorrelationIDFunc != nil {
+        return m.GetCompensationLogsByCorrelationIDFunc(ctx, correlationID)
+    }
+    return nil, nil
+}
+
+func (m *MockCompensationLogRepository) UpdateCompensationStatus(ctx context.Context, logID, status string) error {
+    if m.UpdateCompensationStatusFunc != nil {
+        return m.UpdateCompensationStatusFunc(ctx, logID, status)
+    }
+    return nil
+}
+
+// MockInventoryReservationRepository is a mock implementation of InventoryReservationRepository
+type MockInventoryReservationRepository struct {
+    CreateReservationFunc        func(ctx context.Context, res *models.InventoryReservation) error
+    GetReservationsByOrderIDFunc func(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error)
+    UpdateReservationStatusFunc  func(ctx context.Context, reservationID, status string) error
+    ReleaseReservationFunc       func(ctx context.Context, reservationID string) error
+}
+
+func (m *MockInventoryReservationRepository) CreateReservation(ctx context.Context, res *models.InventoryReservation) error {
+    if m.CreateReservationFunc != nil {
+        return m.CreateReservationFunc(ctx, res)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) GetReservationsByOrderID(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error) {
+    if m.GetReservationsByOrderIDFunc != nil {
+        return m.GetReservationsByOrderIDFunc(ctx, orderID)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) UpdateReservationStatus(ctx context.Context, reservationID, status string) error {
+    if m.UpdateReservationStatusFunc != nil {
+        return m.UpdateReservationStatusFunc(ctx, reservationID, status)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseReservation(ctx context.Context, reservationID string) error {
+    if m.ReleaseReservationFunc != nil {
+        return m.ReleaseReservationFunc(ctx, reservationID)
+    }
+    return nil
+}
+
+// MockPublisher records the events it's asked to publish
+type MockPublisher struct {
+    Events []interface{}
+    Err    error
+}
+
+func (m *MockPublisher) PublishOrderEventReliable(ctx context.Context, event interface{}) error {
+    m.Events = append(m.Events, event)
+    return m.Err
+}
+
+func (m *MockPublisher) PublishOrderEvent(ctx context.Context, event interface{}) error {
+    m.Events = append(m.Events, event)
+    return m.Err
+}
+
+// serve runs one request for target through handler, routed at pattern so path
+// parameters are set, and returns the recorded response
+func serve(t *testing.T, handler gin.HandlerFunc, method, pattern, target string, body interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    return serveAs(t, handler, method, pattern, target, "", "", body)
+}
+
+// serveAs is serve for a caller identity.Require let through as userID with role
+func serveAs(t *testing.T, handler gin.HandlerFunc, method, pattern, target, userID, role string, body interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    var payload bytes.Buffer
+    if body != nil {
+        if err := json.NewEncoder(&payload).Encode(body); err != nil {
+            t.Fatalf("encode body: %v", err)
+        }
+    }
+
+    router := gin.New()
+    router.Handle(method, pattern, func(c *gin.Context) {
+        if userID != "" {
+            c.Set("user_id", userID)
+            c.Set("role", role)
+        }
+        handler(c)
+    })
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(method, target, &payload)
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(rec, req)
+    return rec
+}
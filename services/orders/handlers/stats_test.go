@@ -0,0 +1,36 @@
+package handlers
+
+import (
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+func TestNewStatsTotals(t *testing.T) {
+    // 10 orders: 4 counted as revenue, 2 cancelled, 4 pending or failed
+    totals := models.NewStatsTotals([]models.StatsBucket{
+        {OrderCount: 6, Revenue: 300, RevenueCount: 3, CancelledCount: 1},
+        {OrderCount: 4, Revenue: 100, RevenueCount: 1, CancelledCount: 1},
+    })
+
+    if totals.OrderCount != 10 || totals.Revenue != 400 || totals.CancelledCount != 2 {
+        t.Fatalf("unexpected totals: %+v", totals)
+    }
+    if totals.CancellationRate != 0.2 {
+        t.Fatalf("expected cancellation rate 0.2, got %v", totals.CancellationRate)
+    }
+    if totals.AverageOrderValue != 100 {
+        t.Fatalf("expected average order value 100 (revenue over revenue orders), got %v", totals.AverageOrderValue)
+    }
+}
+
+func TestNewStatsTotals_NoRevenue(t *testing.T) {
+    totals := models.NewStatsTotals([]models.StatsBucket{{OrderCount: 3, CancelledCount: 1}})
+
+    if totals.AverageOrderValue != 0 {
+        t.Fatalf("expected average order value 0, got %v", totals.AverageOrderValue)
+    }
+    if empty := models.NewStatsTotals(nil); empty != (models.StatsTotals{}) {
+        t.Fatalf("expected zero totals, got %+v", empty)
+    }
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS orders.idx_orders_tracking_number;
+
+ALTER TABLE orders.orders DROP COLUMN IF EXISTS carrier;
+ALTER TABLE orders.orders DROP COLUMN IF EXISTS tracking_number;
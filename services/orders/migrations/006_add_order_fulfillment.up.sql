@@ -0,0 +1,5 @@
+-- Tracking data reported by the 3PL shipment webhook
+ALTER TABLE orders.orders ADD COLUMN IF NOT EXISTS tracking_number VARCHAR(100) NULL;
+ALTER TABLE orders.orders ADD COLUMN IF NOT EXISTS carrier VARCHAR(100) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_tracking_number ON orders.orders(tracking_number);
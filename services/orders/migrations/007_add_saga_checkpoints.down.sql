@@ -0,0 +1,3 @@
+ALTER TABLE orders.saga_states DROP COLUMN IF EXISTS retry_count;
+ALTER TABLE orders.saga_states DROP COLUMN IF EXISTS failure_reason;
+ALTER TABLE orders.saga_states DROP COLUMN IF EXISTS last_completed_step;
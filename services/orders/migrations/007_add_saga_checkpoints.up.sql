@@ -0,0 +1,4 @@
+-- Step checkpoints so failed sagas can resume instead of fully compensating
+ALTER TABLE orders.saga_states ADD COLUMN IF NOT EXISTS last_completed_step VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE orders.saga_states ADD COLUMN IF NOT EXISTS failure_reason TEXT NULL;
+ALTER TABLE orders.saga_states ADD COLUMN IF NOT EXISTS retry_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS orders.idx_orders_user_id_created_at;
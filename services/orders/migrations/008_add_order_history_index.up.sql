@@ -0,0 +1,2 @@
+-- Order history: filter by user, newest first
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at ON orders.orders(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders.idx_user_segments_segments;
+DROP TABLE IF EXISTS orders.user_segments;
@@ -0,0 +1,11 @@
+-- Customer segment tags derived from order history (refreshed on order events)
+CREATE TABLE IF NOT EXISTS orders.user_segments (
+    user_id VARCHAR(255) PRIMARY KEY,
+    segments JSONB NOT NULL DEFAULT '[]',
+    order_count INT NOT NULL DEFAULT 0,
+    lifetime_spend DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    last_order_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_segments_segments ON orders.user_segments USING GIN (segments);
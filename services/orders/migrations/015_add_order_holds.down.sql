@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS orders.idx_order_holds_order_id;
+DROP INDEX IF EXISTS orders.idx_order_holds_active;
+DROP TABLE IF EXISTS orders.order_holds;
+
+DROP INDEX IF EXISTS orders.idx_orders_placed_at;
+ALTER TABLE orders.orders DROP COLUMN IF EXISTS placed_at;
@@ -0,0 +1,22 @@
+-- When the order reached 'placed'; the auto-confirmation window starts here
+ALTER TABLE orders.orders ADD COLUMN IF NOT EXISTS placed_at TIMESTAMP NULL;
+
+UPDATE orders.orders SET placed_at = updated_at WHERE status = 'placed' AND placed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_placed_at ON orders.orders(placed_at) WHERE status = 'placed';
+
+-- Payment/fraud holds that stop a placed order from auto-confirming until released
+CREATE TABLE IF NOT EXISTS orders.order_holds (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders.orders(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('payment', 'fraud')),
+    note TEXT NULL,
+    created_by VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP NULL,
+    released_by VARCHAR(255) NULL
+);
+
+-- At most one active hold per order and reason
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_holds_active ON orders.order_holds(order_id, reason) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_order_holds_order_id ON orders.order_holds(order_id);
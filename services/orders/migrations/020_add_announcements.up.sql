@@ -0,0 +1,14 @@
+-- Storefront announcements (maintenance windows, promotions) published by admins
+CREATE TABLE IF NOT EXISTS orders.announcements (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('maintenance', 'promotion')),
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NULL,
+    created_by VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_window ON orders.announcements(starts_at, ends_at);
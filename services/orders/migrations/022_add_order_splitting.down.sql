@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS orders.idx_orders_checkout_id;
+
+ALTER TABLE orders.orders
+    DROP COLUMN IF EXISTS fulfillment_type,
+    DROP COLUMN IF EXISTS warehouse,
+    DROP COLUMN IF EXISTS checkout_id;
+
+DROP TABLE IF EXISTS orders.checkouts;
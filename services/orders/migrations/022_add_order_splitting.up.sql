@@ -0,0 +1,19 @@
+-- Parent record of a checkout; its child orders are reserved, placed and shipped separately
+CREATE TABLE IF NOT EXISTS orders.checkouts (
+    id BIGINT PRIMARY KEY,
+    user_id UUID NOT NULL,
+    cart_id UUID NOT NULL,
+    total DECIMAL(12, 2) NOT NULL,
+    saga_correlation_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Orders created before splitting have no checkout
+ALTER TABLE orders.orders
+    ADD COLUMN IF NOT EXISTS checkout_id BIGINT NULL REFERENCES orders.checkouts(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS warehouse VARCHAR(50) NULL,
+    ADD COLUMN IF NOT EXISTS fulfillment_type VARCHAR(20) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_checkout_id ON orders.orders(checkout_id);
+CREATE INDEX IF NOT EXISTS idx_checkouts_user_id ON orders.checkouts(user_id);
+CREATE INDEX IF NOT EXISTS idx_checkouts_saga_correlation_id ON orders.checkouts(saga_correlation_id);
@@ -0,0 +1,2 @@
+ALTER TABLE orders.order_items
+    ADD COLUMN IF NOT EXISTS variant_id BIGINT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS orders.idx_orders_payment_deadline;
+
+ALTER TABLE orders.orders
+    DROP COLUMN IF EXISTS payment_retry_requested_at,
+    DROP COLUMN IF EXISTS payment_attempts,
+    DROP COLUMN IF EXISTS payment_failure_reason,
+    DROP COLUMN IF EXISTS payment_deadline;
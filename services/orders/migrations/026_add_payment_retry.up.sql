@@ -0,0 +1,9 @@
+-- A placed order whose payment failed waits in 'payment_pending' until payment_deadline for the
+-- customer to retry; after that it is failed and its stock released
+ALTER TABLE orders.orders
+    ADD COLUMN IF NOT EXISTS payment_deadline TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS payment_failure_reason TEXT NULL,
+    ADD COLUMN IF NOT EXISTS payment_attempts INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS payment_retry_requested_at TIMESTAMP NULL; -- set while a retry waits for the payment result
+
+CREATE INDEX IF NOT EXISTS idx_orders_payment_deadline ON orders.orders(payment_deadline) WHERE status = 'payment_pending';
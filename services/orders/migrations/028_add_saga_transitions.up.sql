@@ -0,0 +1,16 @@
+-- Every status change of an orders saga, so support can see where a checkout got stuck.
+-- event_id and event_type are the event that caused the change; both are NULL for changes made
+-- by orders itself (resume, reservation timeout)
+CREATE TABLE IF NOT EXISTS orders.saga_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    correlation_id UUID NOT NULL REFERENCES orders.saga_states(correlation_id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NULL, -- NULL when the saga was created
+    to_status VARCHAR(50) NOT NULL,
+    event_id VARCHAR(100) NULL,
+    event_type VARCHAR(100) NULL,
+    actor_service VARCHAR(50) NOT NULL,
+    reason TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saga_transitions_correlation_id ON orders.saga_transitions(correlation_id, id);
@@ -0,0 +1,4 @@
+ALTER TABLE orders.orders
+    DROP COLUMN IF EXISTS tax_lines,
+    DROP COLUMN IF EXISTS tax_total,
+    DROP COLUMN IF EXISTS subtotal;
@@ -0,0 +1,6 @@
+-- Taxes charged on each order; total is subtotal plus tax_total.
+-- Orders from before tax calculation have no subtotal and no tax.
+ALTER TABLE orders.orders
+    ADD COLUMN IF NOT EXISTS subtotal DECIMAL(12, 2) NULL,
+    ADD COLUMN IF NOT EXISTS tax_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_lines JSONB NOT NULL DEFAULT '[]';
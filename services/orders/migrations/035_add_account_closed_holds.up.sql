@@ -0,0 +1,4 @@
+-- Open orders of deleted accounts are held for review (reason 'account_closed')
+ALTER TABLE orders.order_holds DROP CONSTRAINT IF EXISTS order_holds_reason_check;
+ALTER TABLE orders.order_holds ADD CONSTRAINT order_holds_reason_check
+    CHECK (reason IN ('payment', 'fraud', 'account_closed'));
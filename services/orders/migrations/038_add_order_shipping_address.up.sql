@@ -0,0 +1,3 @@
+-- Copy of the address an order ships to, taken at checkout. Orders from before addresses
+-- have none.
+ALTER TABLE orders.orders ADD COLUMN IF NOT EXISTS shipping_address JSONB NULL;
@@ -0,0 +1,21 @@
+-- Audit log of admin and security-relevant actions (shared/audit). actor_id is NULL for
+-- anonymous callers such as failed logins.
+
+CREATE TABLE IF NOT EXISTS orders.audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor_id VARCHAR(255),
+    actor_role VARCHAR(50),
+    ip VARCHAR(64),
+    target_type VARCHAR(50),
+    target_id VARCHAR(255),
+    before JSONB,
+    after JSONB,
+    details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON orders.audit_logs(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON orders.audit_logs(action, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON orders.audit_logs(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON orders.audit_logs(target_type, target_id, created_at DESC);
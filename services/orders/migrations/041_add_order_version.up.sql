@@ -0,0 +1,3 @@
+-- Optimistic concurrency: every change to an order bumps the version. Cancelling only applies
+-- to the version the caller read, and /orders/:id exposes it as the ETag.
+ALTER TABLE orders.orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders.idx_pending_fulfillment_pushes_updated_at;
+DROP TABLE IF EXISTS orders.pending_fulfillment_pushes;
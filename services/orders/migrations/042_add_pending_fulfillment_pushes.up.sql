@@ -0,0 +1,12 @@
+-- 3PL pushes that failed, kept until a retry gets through. The payload is the one that was
+-- refused, so the retry sends what OrderPlaced described.
+CREATE TABLE IF NOT EXISTS orders.pending_fulfillment_pushes (
+    order_id BIGINT PRIMARY KEY REFERENCES orders.orders(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 1,
+    last_error TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_fulfillment_pushes_updated_at ON orders.pending_fulfillment_pushes(updated_at);
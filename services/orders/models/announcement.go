@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Announcement kinds
+const (
+    AnnouncementMaintenance = "maintenance"
+    AnnouncementPromotion   = "promotion"
+)
+
+// Announcement is a storefront banner (maintenance window, promotion) published by an admin
+type Announcement struct {
+    ID        int64      `json:"id"`
+    Kind      string     `json:"kind"` // maintenance, promotion
+    Title     string     `json:"title"`
+    Message   string     `json:"message"`
+    StartsAt  time.Time  `json:"starts_at"`
+    EndsAt    *time.Time `json:"ends_at,omitempty"` // nil: shown until removed
+    CreatedBy *string    `json:"created_by,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// PublishAnnouncementRequest request body for publishing an announcement
+type PublishAnnouncementRequest struct {
+    Kind     string     `json:"kind" binding:"required,oneof=maintenance promotion"`
+    Title    string     `json:"title" binding:"required,max=255"`
+    Message  string     `json:"message"`
+    StartsAt *time.Time `json:"starts_at"` // default: now
+    EndsAt   *time.Time `json:"ends_at"`
+}
+
+// NewAnnouncement builds the announcement for a request; StartsAt defaults to now.
+// ok is false when the window ends before it starts (or already ended).
+func NewAnnouncement(req *PublishAnnouncementRequest, createdBy string, now time.Time) (*Announcement, bool) {
+    a := &Announcement{
+        Kind:      req.Kind,
+        Title:     req.Title,
+        Message:   req.Message,
+        StartsAt:  now,
+        EndsAt:    req.EndsAt,
+        CreatedAt: now,
+    }
+    if req.StartsAt != nil {
+        a.StartsAt = req.StartsAt.UTC()
+    }
+    if createdBy != "" {
+        a.CreatedBy = &createdBy
+    }
+    if a.EndsAt != nil && (!a.EndsAt.After(a.StartsAt) || !a.EndsAt.After(now)) {
+        return nil, false
+    }
+    return a, true
+}
+
+// IsActive reports whether the announcement should be shown at now
+func (a *Announcement) IsActive(now time.Time) bool {
+    return !now.Before(a.StartsAt) && (a.EndsAt == nil || now.Before(*a.EndsAt))
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+    "testing"
+    "time"
+)
+
+func TestNewAnnouncement_Window(t *testing.T) {
+    now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+    later := now.Add(2 * time.Hour)
+    earlier := now.Add(-time.Hour)
+
+    // Starts now by default and is shown until it ends
+    a, ok := NewAnnouncement(&PublishAnnouncementRequest{Kind: AnnouncementMaintenance, Title: "Down for upgrades", EndsAt: &later}, "admin-1", now)
+    if !ok {
+        t.Fatal("expected a valid announcement")
+    }
+    if !a.StartsAt.Equal(now) || *a.CreatedBy != "admin-1" {
+        t.Errorf("announcement = %+v", a)
+    }
+    if !a.IsActive(now) || a.IsActive(later) {
+        t.Error("expected active from now until ends_at (exclusive)")
+    }
+
+    // A window that already ended is rejected
+    if _, ok := NewAnnouncement(&PublishAnnouncementRequest{Kind: AnnouncementPromotion, Title: "Sale", StartsAt: &earlier, EndsAt: &earlier}, "", now); ok {
+        t.Error("expected an empty window to be rejected")
+    }
+}
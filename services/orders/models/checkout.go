@@ -0,0 +1,153 @@
+package models
+
+import "time"
+
+// Checkout is the parent of the orders one cart checkout was split into, one per warehouse and
+// fulfillment type. Each child order is reserved, placed and shipped on its own.
+type Checkout struct {
+    ID                int64     `json:"id"`
+    UserID            string    `json:"user_id"`
+    CartID            string    `json:"cart_id"`
+    Total             float64   `json:"total"`
+    Status            string    `json:"status"` // combined status of the orders, see CombinedStatus
+    SagaCorrelationID string    `json:"saga_correlation_id"`
+    Orders            []*Order  `json:"orders"`
+    CreatedAt         time.Time `json:"created_at"`
+}
+
+// Combined checkout statuses on top of the order statuses
+const (
+    CheckoutPartiallyShipped   = "partially_shipped"
+    CheckoutPartiallyDelivered = "partially_delivered"
+)
+
+// orderProgress ranks the statuses an active order moves through
+var orderProgress = map[string]int{
+    "pending":         0,
+    "payment_pending": 1, // waiting for the customer to retry a failed payment
+    "placed":          2,
+    "confirmed":       3,
+    "shipped":         4,
+    "delivered":       5,
+}
+
+// CombinedStatus summarizes the statuses of a checkout's orders. A failed order fails the
+// checkout; cancelled orders are ignored unless all are cancelled. Otherwise the checkout is
+// as far along as its slowest order, except that it is partially shipped (or delivered) once
+// some but not all orders have shipped (or been delivered).
+func CombinedStatus(statuses []string) string {
+    lowest, highest := -1, -1
+    var lowestStatus, highestStatus string
+    cancelled := 0
+
+    for _, status := range statuses {
+        if status == "failed" {
+            return "failed"
+        }
+        if status == "cancelled" {
+            cancelled++
+            continue
+        }
+
+        rank, ok := orderProgress[status]
+        if !ok {
+            continue
+        }
+        if lowest < 0 || rank < lowest {
+            lowest, lowestStatus = rank, status
+        }
+        if rank > highest {
+            highest, highestStatus = rank, status
+        }
+    }
+
+    switch {
+    case lowest < 0 && cancelled > 0:
+        return "cancelled"
+    case lowest < 0:
+        return "pending"
+    case lowest == highest:
+        return lowestStatus
+    case highestStatus == "delivered" && lowestStatus == "shipped":
+        return CheckoutPartiallyDelivered
+    case highest >= orderProgress["shipped"]:
+        return CheckoutPartiallyShipped
+    default:
+        return lowestStatus
+    }
+}
+
+// Checkout result outcomes
+const (
+    CheckoutOutcomePending   = "pending"
+    CheckoutOutcomeSucceeded = "succeeded"
+    CheckoutOutcomeFailed    = "failed"
+)
+
+// CheckoutResult is the outcome of a checkout saga, polled by the correlation ID the cart
+// service returned. Succeeded and failed are terminal.
+type CheckoutResult struct {
+    CorrelationID string  `json:"correlation_id"`
+    UserID        string  `json:"user_id"`
+    Outcome       string  `json:"outcome"` // pending, succeeded, failed
+    OrderID       *int64  `json:"order_id,omitempty"`  // when succeeded; the first order of a split checkout
+    OrderIDs      []int64 `json:"order_ids,omitempty"` // when succeeded; every order of the checkout
+    CheckoutID    *int64  `json:"checkout_id,omitempty"`
+    Reason        *string `json:"reason,omitempty"`    // when failed
+    SagaStatus    string  `json:"saga_status"`
+}
+
+// NewCheckoutResult derives a checkout's outcome from its saga and the orders it created.
+// Why: a placed order can still fail on payment, so the checkout only succeeds once the saga
+// completed (every order confirmed); a saga that's failed now may still be resumed, but the
+// client has to start over either way.
+func NewCheckoutResult(saga *SagaState, orders []*Order) *CheckoutResult {
+    result := &CheckoutResult{
+        CorrelationID: saga.CorrelationID,
+        UserID:        saga.UserID,
+        Outcome:       CheckoutOutcomePending,
+        SagaStatus:    saga.Status,
+    }
+
+    switch saga.Status {
+    case "completed":
+        result.Outcome = CheckoutOutcomeSucceeded
+        for _, order := range orders {
+            result.OrderIDs = append(result.OrderIDs, order.ID)
+            if result.CheckoutID == nil {
+                result.CheckoutID = order.CheckoutID
+            }
+        }
+        if len(result.OrderIDs) > 0 {
+            result.OrderID = &result.OrderIDs[0]
+        } else {
+            result.OrderID = saga.OrderID
+        }
+    case "failed":
+        result.Outcome = CheckoutOutcomeFailed
+        reason := "checkout failed"
+        if saga.FailureReason != nil && *saga.FailureReason != "" {
+            reason = *saga.FailureReason
+        }
+        result.Reason = &reason
+    case "cancelled":
+        result.Outcome = CheckoutOutcomeFailed
+        reason := "order cancelled"
+        result.Reason = &reason
+    }
+    return result
+}
+
+// NewCheckout creates new checkout
+func NewCheckout(id int64, userID, cartID string, total float64, sagaCorrelationID string) *Checkout {
+    return &Checkout{
+        ID:                id,
+        UserID:            userID,
+        CartID:            cartID,
+        Total:             total,
+        Status:            "pending",
+        SagaCorrelationID: sagaCorrelationID,
+        Orders:            []*Order{},
+        CreatedAt:         time.Now().UTC(),
+    }
+}
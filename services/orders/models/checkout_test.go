@@ -0,0 +1,57 @@
+package models
+
+import "testing"
+
+func TestCombinedStatus(t *testing.T) {
+    cases := []struct {
+        statuses []string
+        want     string
+    }{
+        {[]string{"placed"}, "placed"},
+        {[]string{"pending", "placed"}, "pending"},
+        {[]string{"confirmed", "placed"}, "placed"},
+        {[]string{"shipped", "confirmed"}, CheckoutPartiallyShipped},
+        {[]string{"delivered", "placed"}, CheckoutPartiallyShipped},
+        {[]string{"delivered", "shipped"}, CheckoutPartiallyDelivered},
+        {[]string{"delivered", "delivered"}, "delivered"},
+        {[]string{"shipped", "failed"}, "failed"},
+        {[]string{"cancelled", "shipped"}, "shipped"},
+        {[]string{"cancelled", "cancelled"}, "cancelled"},
+        {nil, "pending"},
+    }
+
+    for _, tc := range cases {
+        if got := CombinedStatus(tc.statuses); got != tc.want {
+            t.Errorf("CombinedStatus(%v) = %q, want %q", tc.statuses, got, tc.want)
+        }
+    }
+}
+
+func TestNewCheckoutResult(t *testing.T) {
+    checkoutID := int64(9)
+    orders := []*Order{{ID: 11, CheckoutID: &checkoutID}, {ID: 12, CheckoutID: &checkoutID}}
+
+    for _, status := range []string{"pending", "order_created", "order_placed"} {
+        result := NewCheckoutResult(&SagaState{CorrelationID: "cid", Status: status}, nil)
+        if result.Outcome != CheckoutOutcomePending || result.OrderID != nil || result.Reason != nil {
+            t.Errorf("saga %s: result = %+v, want pending", status, result)
+        }
+    }
+
+    result := NewCheckoutResult(&SagaState{CorrelationID: "cid", UserID: "u1", Status: "completed"}, orders)
+    if result.Outcome != CheckoutOutcomeSucceeded || result.OrderID == nil || *result.OrderID != 11 ||
+        len(result.OrderIDs) != 2 || result.CheckoutID == nil || *result.CheckoutID != 9 || result.UserID != "u1" {
+        t.Errorf("completed saga: result = %+v", result)
+    }
+
+    reason := "insufficient inventory for product 7"
+    result = NewCheckoutResult(&SagaState{Status: "failed", FailureReason: &reason}, nil)
+    if result.Outcome != CheckoutOutcomeFailed || result.Reason == nil || *result.Reason != reason {
+        t.Errorf("failed saga: result = %+v", result)
+    }
+
+    result = NewCheckoutResult(&SagaState{Status: "cancelled"}, nil)
+    if result.Outcome != CheckoutOutcomeFailed || result.Reason == nil || *result.Reason != "order cancelled" {
+        t.Errorf("cancelled saga: result = %+v", result)
+    }
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// PendingFulfillmentPush is a 3PL push that failed, waiting for a retry
+type PendingFulfillmentPush struct {
+    OrderID   int64
+    Payload   json.RawMessage // the fulfillment.OrderPayload that was refused
+    Attempts  int
+    LastError string
+    CreatedAt time.Time
+}
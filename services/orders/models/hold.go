@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Hold reasons
+const (
+    HoldReasonPayment       = "payment"
+    HoldReasonFraud         = "fraud"
+    HoldReasonAccountClosed = "account_closed" // the customer deleted their account; placed by UserDeactivated
+)
+
+// OrderHold stops a placed order from auto-confirming until it is released
+type OrderHold struct {
+    ID         int64      `json:"id"`
+    OrderID    int64      `json:"order_id"`
+    Reason     string     `json:"reason"` // payment, fraud, account_closed
+    Note       *string    `json:"note,omitempty"`
+    CreatedBy  *string    `json:"created_by,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    ReleasedAt *time.Time `json:"released_at,omitempty"`
+    ReleasedBy *string    `json:"released_by,omitempty"`
+}
+
+// IsActive reports whether the hold still blocks confirmation
+func (h *OrderHold) IsActive() bool {
+    return h.ReleasedAt == nil
+}
+
+// PlaceHoldRequest request body for putting an order on hold
+type PlaceHoldRequest struct {
+    Reason string `json:"reason" binding:"required,oneof=payment fraud"`
+    Note   string `json:"note"`
+}
+
+// ReleaseHoldRequest request body for releasing holds; an empty reason releases all of them
+type ReleaseHoldRequest struct {
+    Reason string `json:"reason" binding:"omitempty,oneof=payment fraud account_closed"`
+}
+
+// PlacedOrder is a placed order waiting for auto-confirmation
+type PlacedOrder struct {
+    OrderID           int64
+    SagaCorrelationID string
+    PlacedAt          time.Time
+}
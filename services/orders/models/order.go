@@ -4,6 +4,7 @@ import (
     "time"
 
     "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/shared/pagination"
 )
 
 // Order represents an order
@@ -12,14 +13,27 @@ type Order struct {
     UserID             string     `json:"user_id"`
     CartID             string     `json:"cart_id"`
     Items              []OrderItem `json:"items"`
-    Total              float64    `json:"total"`
-    Status             string     `json:"status"` // pending, confirmed, shipped, delivered, cancelled
+    Subtotal           *float64   `json:"subtotal,omitempty"` // before tax; nil for orders from before tax calculation
+    TaxTotal           float64    `json:"tax_total"`
+    TaxLines           TaxLines   `json:"tax_lines"`
+    Total              float64    `json:"total"` // subtotal plus tax
+    Status             string     `json:"status"` // pending, placed, payment_pending, confirmed, shipped, delivered, cancelled, failed
     SagaCorrelationID  string     `json:"saga_correlation_id"`
     CreatedAt          time.Time  `json:"created_at"`
     UpdatedAt          time.Time  `json:"updated_at"`
     ShippedAt          *time.Time `json:"shipped_at,omitempty"`
     DeliveredAt        *time.Time `json:"delivered_at,omitempty"`
     CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+    TrackingNumber     *string    `json:"tracking_number,omitempty"`
+    Carrier            *string    `json:"carrier,omitempty"`
+    CheckoutID         *int64     `json:"checkout_id,omitempty"`      // parent checkout; nil for orders from before splitting
+    Warehouse          *string    `json:"warehouse,omitempty"`        // where every item of the order ships from
+    FulfillmentType    *string    `json:"fulfillment_type,omitempty"` // standard, digital or dropship
+    PaymentAttempts    int        `json:"payment_attempts"`
+    PaymentDeadline    *time.Time `json:"payment_deadline,omitempty"`       // while payment_pending: when the order fails unless paid
+    PaymentFailureReason *string  `json:"payment_failure_reason,omitempty"` // why the last payment attempt failed
+    ShippingAddress    *ShippingAddress `json:"shipping_address,omitempty"` // copied at checkout; nil for orders from before addresses
+    Version            int        `json:"version"` // bumped on every write; the order's ETag
 }
 
 // OrderItem represents a line item in an order
@@ -27,6 +41,7 @@ type OrderItem struct {
     ID        int64     `json:"id"`
     OrderID   int64     `json:"order_id"`
     ProductID int64     `json:"product_id"`
+    VariantID *int64    `json:"variant_id,omitempty"` // nil for products without variants
     Quantity  int       `json:"quantity"`
     Price     float64   `json:"price"` // Price at time of purchase
     CreatedAt time.Time `json:"created_at"`
@@ -43,11 +58,47 @@ type SagaState struct {
     UserID           string                 `json:"user_id"`
     Payload          map[string]interface{} `json:"payload"`
     CompensationLog  []string               `json:"compensation_log"` // list of compensation actions
+    LastCompletedStep string                `json:"last_completed_step"` // checkpoint used by resume
+    FailureReason    *string                `json:"failure_reason,omitempty"`
+    RetryCount       int                    `json:"retry_count"`
     CreatedAt        time.Time              `json:"created_at"`
     UpdatedAt        time.Time              `json:"updated_at"`
     ExpiresAt        time.Time              `json:"expires_at"`
 }
 
+// sagaStepDescriptions describe saga statuses and step checkpoints for people reading a saga
+var sagaStepDescriptions = map[string]string{
+    "pending":             "Checkout received, creating the orders",
+    "order_created":       "Orders created",
+    "inventory_requested": "Stock requested from the products service",
+    "checking_inventory":  "Waiting for the products service to reserve stock",
+    "order_placed":        "Stock reserved, waiting for payment",
+    "completed":           "Payment received, orders confirmed",
+    "failed":              "Checkout failed",
+    "cancelled":           "Checkout cancelled",
+}
+
+// SagaStepDescription describes a saga status or step checkpoint; "" for one it doesn't know
+func SagaStepDescription(step string) string {
+    return sagaStepDescriptions[step]
+}
+
+// SagaStateResponse is a saga state with its status and last step described
+type SagaStateResponse struct {
+    *SagaState
+    StatusDescription            string `json:"status_description"`
+    LastCompletedStepDescription string `json:"last_completed_step_description,omitempty"`
+}
+
+// NewSagaStateResponse describes saga for GET /sagas/:correlation_id
+func NewSagaStateResponse(saga *SagaState) SagaStateResponse {
+    return SagaStateResponse{
+        SagaState:                    saga,
+        StatusDescription:            SagaStepDescription(saga.Status),
+        LastCompletedStepDescription: SagaStepDescription(saga.LastCompletedStep),
+    }
+}
+
 // CompensationLog tracks compensating actions
 type CompensationLog struct {
     ID                  string                 `json:"id"`
@@ -95,6 +146,87 @@ type CancelOrderRequest struct {
     Reason string `json:"reason"`
 }
 
+// Order history paging defaults
+const (
+    DefaultOrderPageLimit = 20
+    MaxOrderPageLimit     = 100
+)
+
+// Order history sort orders (GET /orders?sort=)
+const (
+    SortCreatedDesc = "created_at_desc"
+    SortCreatedAsc  = "created_at_asc"
+    SortTotalDesc   = "total_desc"
+    SortTotalAsc    = "total_asc"
+)
+
+// OrderFilter filters and pages a user's order history
+type OrderFilter struct {
+    UserID   string
+    Statuses []string
+    From     *time.Time // created_at >= From
+    To       *time.Time // created_at < To
+    MinTotal *float64
+    MaxTotal *float64
+    Page     int // 1-based
+    Limit    int
+    Sort     string
+}
+
+// Offset returns the row offset for the filter's page
+func (f OrderFilter) Offset() int {
+    if f.Page <= 1 {
+        return 0
+    }
+    return (f.Page - 1) * f.Limit
+}
+
+// NextPage returns the page after the filter's, or nil if total matches end on this one
+func (f OrderFilter) NextPage(total int) *int {
+    if f.Offset()+f.Limit >= total {
+        return nil
+    }
+    next := f.Page + 1
+    return &next
+}
+
+// OrderHistoryResponse is one page of order history with status facets
+type OrderHistoryResponse struct {
+    Orders       []*Order       `json:"orders"`
+    Count        int            `json:"count"`       // orders on this page
+    NextPage     *int           `json:"next_page"`   // null on the last page
+    Total        int            `json:"total"`       // orders matching the filter
+    Page         int            `json:"page"`
+    Limit        int            `json:"limit"`
+    StatusCounts map[string]int `json:"status_counts"` // matches per status, ignoring the status filter
+}
+
+// OrderConnection is one keyset page of order history with status facets
+type OrderConnection struct {
+    pagination.Connection[*Order]
+    TotalCount   int            `json:"total_count"`   // orders matching the filter
+    StatusCounts map[string]int `json:"status_counts"` // matches per status, ignoring the status filter
+}
+
+// OrderCursor returns the cursor of an order in the history listed by sort
+func OrderCursor(sort string) func(*Order) pagination.Cursor {
+    return func(order *Order) pagination.Cursor {
+        key := pagination.TimeKey(order.CreatedAt)
+        if sort == SortTotalDesc || sort == SortTotalAsc {
+            key = pagination.NumberKey(order.Total)
+        }
+        return pagination.Cursor{Sort: sort, Key: key, ID: order.ID}
+    }
+}
+
+// ShipmentCallbackRequest is the 3PL shipment webhook payload
+type ShipmentCallbackRequest struct {
+    OrderID        int64      `json:"order_id" binding:"required"`
+    TrackingNumber string     `json:"tracking_number" binding:"required"`
+    Carrier        string     `json:"carrier"`
+    ShippedAt      *time.Time `json:"shipped_at"`
+}
+
 // ErrorResponse standard error response
 type ErrorResponse struct {
     Error   string `json:"error"`
@@ -110,11 +242,13 @@ func NewOrder(userID, cartID string, orderID int64, total float64, sagaCorrelati
         UserID:            userID,
         CartID:            cartID,
         Items:             []OrderItem{},
+        TaxLines:          TaxLines{},
         Total:             total,
         Status:            "pending",
         SagaCorrelationID: sagaCorrelationID,
         CreatedAt:         now,
         UpdatedAt:         now,
+        Version:           1,
     }
 }
 
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// PaymentPendingOrder is an order whose payment failed, waiting for the customer to retry
+type PaymentPendingOrder struct {
+    OrderID           int64
+    SagaCorrelationID string
+    FailureReason     string
+    Deadline          time.Time
+}
+
+// PaymentRetry is a retry the customer requested for a payment_pending order
+type PaymentRetry struct {
+    OrderID           int64
+    UserID            string
+    Total             float64
+    SagaCorrelationID string
+    Attempt           int
+    Deadline          time.Time
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+    "fmt"
+    "time"
+)
+
+// ReservationLine is the stock the saga waits for on one order line
+type ReservationLine struct {
+    OrderID   int64  `json:"order_id"`
+    ProductID int64  `json:"product_id"`
+    VariantID *int64 `json:"variant_id,omitempty"`
+    Expected  int    `json:"expected"`
+    Reserved  int    `json:"reserved"`
+}
+
+// ReservedLine is one reservation confirmed by a StockReserved event
+type ReservedLine struct {
+    ProductID     int64
+    VariantID     *int64
+    Quantity      int
+    ReservationID string
+    EventID       string // the StockReserved event; keys the line when ReservationID is empty
+}
+
+// key identifies the reservation so a redelivery isn't counted again: its ReservationID, or
+// the event and the line's product, variant and quantity. Empty when neither ID is set.
+func (r ReservedLine) key() string {
+    if r.ReservationID != "" {
+        return r.ReservationID
+    }
+    if r.EventID == "" {
+        return ""
+    }
+    variant := "-"
+    if r.VariantID != nil {
+        variant = fmt.Sprint(*r.VariantID)
+    }
+    return fmt.Sprintf("%s/%d/%s/%d", r.EventID, r.ProductID, variant, r.Quantity)
+}
+
+// ReservationProgress is kept in the saga payload under "reservations": every line of the
+// checkout's orders with the quantity expected and the quantity products confirmed so far.
+// Why: a StockReserved only proves the lines it lists were reserved; orders are placed once
+// every line has its full quantity, and the saga fails if that doesn't happen by Deadline.
+type ReservationProgress struct {
+    Lines          []ReservationLine `json:"lines"`
+    ReservationIDs []string          `json:"reservation_ids"` // keys already counted, so redeliveries aren't
+    Deadline       time.Time         `json:"deadline"`
+}
+
+// NewReservationProgress expects every item of orders, with lines of the same product and
+// variant added together
+func NewReservationProgress(orders []*Order, deadline time.Time) *ReservationProgress {
+    progress := &ReservationProgress{Lines: []ReservationLine{}, ReservationIDs: []string{}, Deadline: deadline}
+    for _, order := range orders {
+        for _, item := range order.Items {
+            if line := progress.line(order.ID, item.ProductID, item.VariantID); line != nil {
+                line.Expected += item.Quantity
+                continue
+            }
+            progress.Lines = append(progress.Lines, ReservationLine{
+                OrderID:   order.ID,
+                ProductID: item.ProductID,
+                VariantID: item.VariantID,
+                Expected:  item.Quantity,
+            })
+        }
+    }
+    return progress
+}
+
+// Record counts reservations confirmed for orderID and returns how many were new.
+// Reservations already counted, for lines the order doesn't have, or with neither a
+// reservation nor an event ID (a redelivery couldn't be told apart) are ignored.
+func (p *ReservationProgress) Record(orderID int64, reserved []ReservedLine) int {
+    counted := 0
+    for _, r := range reserved {
+        key := r.key()
+        if key == "" || p.seen(key) {
+            continue
+        }
+        line := p.line(orderID, r.ProductID, r.VariantID)
+        if line == nil {
+            continue
+        }
+        line.Reserved += r.Quantity
+        p.ReservationIDs = append(p.ReservationIDs, key)
+        counted++
+    }
+    return counted
+}
+
+// Complete reports whether every line has its full quantity reserved
+func (p *ReservationProgress) Complete() bool {
+    return len(p.Missing()) == 0
+}
+
+// Missing returns the lines still short of their expected quantity
+func (p *ReservationProgress) Missing() []ReservationLine {
+    var missing []ReservationLine
+    for _, line := range p.Lines {
+        if line.Reserved < line.Expected {
+            missing = append(missing, line)
+        }
+    }
+    return missing
+}
+
+func (p *ReservationProgress) line(orderID, productID int64, variantID *int64) *ReservationLine {
+    for i := range p.Lines {
+        line := &p.Lines[i]
+        if line.OrderID == orderID && line.ProductID == productID && sameVariant(line.VariantID, variantID) {
+            return line
+        }
+    }
+    return nil
+}
+
+func (p *ReservationProgress) seen(key string) bool {
+    for _, id := range p.ReservationIDs {
+        if id == key {
+            return true
+        }
+    }
+    return false
+}
+
+func sameVariant(a, b *int64) bool {
+    if a == nil || b == nil {
+        return a == nil && b == nil
+    }
+    return *a == *b
+}
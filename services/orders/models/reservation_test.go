@@ -0,0 +1,71 @@
+package models
+
+import (
+    "testing"
+    "time"
+)
+
+func TestReservationProgress(t *testing.T) {
+    variant := int64(7)
+    orders := []*Order{
+        {ID: 1, Items: []OrderItem{{ProductID: 10, Quantity: 2}, {ProductID: 10, Quantity: 1}, {ProductID: 11, VariantID: &variant, Quantity: 1}}},
+        {ID: 2, Items: []OrderItem{{ProductID: 12, Quantity: 4}}},
+    }
+    progress := NewReservationProgress(orders, time.Now())
+    if len(progress.Lines) != 3 || progress.Lines[0].Expected != 3 {
+        t.Fatalf("lines = %+v, want product 10 added together", progress.Lines)
+    }
+
+    // The first StockReserved only covers part of the checkout
+    if n := progress.Record(1, []ReservedLine{{ProductID: 10, Quantity: 3, ReservationID: "r1"}, {ProductID: 11, VariantID: &variant, Quantity: 1, ReservationID: "r2"}}); n != 2 {
+        t.Errorf("Record = %d, want 2", n)
+    }
+    if progress.Complete() {
+        t.Fatal("complete with order 2 unreserved")
+    }
+
+    // Redeliveries and lines of another order don't count
+    progress.Record(1, []ReservedLine{{ProductID: 10, Quantity: 3, ReservationID: "r1"}})
+    progress.Record(1, []ReservedLine{{ProductID: 12, Quantity: 4, ReservationID: "r3"}})
+    progress.Record(2, []ReservedLine{{ProductID: 12, Quantity: 3, ReservationID: "r4"}})
+    if missing := progress.Missing(); len(missing) != 1 || missing[0].OrderID != 2 || missing[0].Reserved != 3 {
+        t.Fatalf("missing = %+v, want order 2 short by one", missing)
+    }
+    if progress.Lines[0].Reserved != 3 {
+        t.Errorf("product 10 reserved %d, want the redelivery ignored", progress.Lines[0].Reserved)
+    }
+
+    progress.Record(2, []ReservedLine{{ProductID: 12, Quantity: 1, ReservationID: "r5"}})
+    if !progress.Complete() {
+        t.Errorf("not complete: %+v", progress.Missing())
+    }
+}
+
+func TestReservationProgress_LinesWithoutReservationID(t *testing.T) {
+    variant := int64(7)
+    orders := []*Order{{ID: 1, Items: []OrderItem{{ProductID: 10, Quantity: 4}, {ProductID: 11, VariantID: &variant, Quantity: 2}}}}
+    progress := NewReservationProgress(orders, time.Now())
+
+    first := []ReservedLine{{ProductID: 10, Quantity: 2, EventID: "e1"}, {ProductID: 11, VariantID: &variant, Quantity: 2, EventID: "e1"}}
+    if n := progress.Record(1, first); n != 2 {
+        t.Fatalf("Record = %d, want 2", n)
+    }
+
+    // A redelivery of the same event is deduped by product, variant and quantity
+    if n := progress.Record(1, first); n != 0 {
+        t.Errorf("redelivery Record = %d, want 0", n)
+    }
+    // Without any ID a redelivery couldn't be told apart, so the line is rejected
+    if n := progress.Record(1, []ReservedLine{{ProductID: 10, Quantity: 2}}); n != 0 {
+        t.Errorf("Record without IDs = %d, want 0", n)
+    }
+    if progress.Complete() {
+        t.Fatalf("complete with product 10 reserved %d of 4", progress.Lines[0].Reserved)
+    }
+
+    // The same line in another event is another reservation
+    progress.Record(1, []ReservedLine{{ProductID: 10, Quantity: 2, EventID: "e2"}})
+    if !progress.Complete() {
+        t.Errorf("not complete: %+v", progress.Missing())
+    }
+}
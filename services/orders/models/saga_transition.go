@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ActorOrders is the actor of saga transitions orders makes on its own (resume, timeouts)
+const ActorOrders = "orders"
+
+// SagaTransition is one status change of a saga
+type SagaTransition struct {
+    ID            int64     `json:"id"`
+    CorrelationID string    `json:"correlation_id"`
+    FromStatus    *string   `json:"from_status"` // nil for the saga's first status
+    ToStatus      string    `json:"to_status"`
+    EventID       *string   `json:"event_id,omitempty"`
+    EventType     *string   `json:"event_type,omitempty"`
+    ActorService  string    `json:"actor_service"` // service whose event, or action, caused the change
+    Reason        *string   `json:"reason,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+
+// TransitionCause is what the saga's next status changes are attributed to
+type TransitionCause struct {
+    EventID      string // empty when no event caused the change
+    EventType    string
+    ActorService string
+}
+
+// SagaTimeline is a saga's current state and every status change that led to it, oldest first
+type SagaTimeline struct {
+    CorrelationID     string           `json:"correlation_id"`
+    Status            string           `json:"status"`
+    OrderID           *int64           `json:"order_id"`
+    LastCompletedStep string           `json:"last_completed_step"`
+    FailureReason     *string          `json:"failure_reason,omitempty"`
+    RetryCount        int              `json:"retry_count"`
+    CreatedAt         time.Time        `json:"created_at"`
+    UpdatedAt         time.Time        `json:"updated_at"`
+    Transitions       []SagaTransition `json:"transitions"`
+}
+
+// SagaRecords is what orders stored for a saga besides its timeline: the orders it created and
+// the compensations run for them, oldest first
+type SagaRecords struct {
+    CorrelationID string             `json:"correlation_id"`
+    Orders        []*Order           `json:"orders"`
+    Compensations []*CompensationLog `json:"compensations"`
+}
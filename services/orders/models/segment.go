@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// UserOrderStats summarises a user's order history for segmentation
+type UserOrderStats struct {
+    UserID         string     `json:"user_id"`
+    OrderCount     int        `json:"order_count"`     // orders that weren't cancelled/failed
+    CancelledCount int        `json:"cancelled_count"`
+    LifetimeSpend  float64    `json:"lifetime_spend"`
+    LastOrderAt    *time.Time `json:"last_order_at,omitempty"`
+}
+
+// UserSegments are the segment tags currently assigned to a user
+type UserSegments struct {
+    UserID        string     `json:"user_id"`
+    Segments      []string   `json:"segments"`
+    OrderCount    int        `json:"order_count"`
+    LifetimeSpend float64    `json:"lifetime_spend"`
+    LastOrderAt   *time.Time `json:"last_order_at,omitempty"`
+    UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// HasSegment reports whether the user carries the given segment tag
+func (us *UserSegments) HasSegment(segment string) bool {
+    for _, s := range us.Segments {
+        if s == segment {
+            return true
+        }
+    }
+    return false
+}
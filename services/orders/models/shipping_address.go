@@ -0,0 +1,36 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// ShippingAddress is the copy of the user's address an order ships to, taken at checkout and
+// stored as JSONB
+type ShippingAddress sharedmodels.Address
+
+// Scan reads the JSONB column; NULL (orders from before addresses) leaves the pointer nil
+func (a *ShippingAddress) Scan(src interface{}) error {
+    var raw []byte
+    switch v := src.(type) {
+    case []byte:
+        raw = v
+    case string:
+        raw = []byte(v)
+    default:
+        return fmt.Errorf("cannot scan %T into shipping address", src)
+    }
+
+    if err := json.Unmarshal(raw, a); err != nil {
+        return fmt.Errorf("failed to decode shipping address: %w", err)
+    }
+    return nil
+}
+
+// Value writes the JSONB column; a nil address is stored as NULL
+func (a ShippingAddress) Value() (driver.Value, error) {
+    return json.Marshal(sharedmodels.Address(a))
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Admin stats periods (GET /admin/stats?period=)
+const (
+    StatsPeriodDaily  = "daily"
+    StatsPeriodWeekly = "weekly"
+)
+
+// StatsBucket aggregates orders created within one day/week
+type StatsBucket struct {
+    PeriodStart    time.Time `json:"period_start"`
+    OrderCount     int       `json:"order_count"`
+    Revenue        float64   `json:"revenue"`
+    RevenueCount   int       `json:"revenue_count"` // orders counted in Revenue
+    CancelledCount int       `json:"cancelled_count"`
+}
+
+// StatsTotals aggregates the whole reporting window
+type StatsTotals struct {
+    OrderCount        int     `json:"order_count"`
+    Revenue           float64 `json:"revenue"`
+    CancelledCount    int     `json:"cancelled_count"`
+    CancellationRate  float64 `json:"cancellation_rate"` // cancelled / all orders, 0..1
+    AverageOrderValue float64 `json:"average_order_value"`
+}
+
+// TopProduct is a best-selling product in the reporting window
+type TopProduct struct {
+    ProductID int64   `json:"product_id"`
+    Quantity  int     `json:"quantity"`
+    Revenue   float64 `json:"revenue"`
+}
+
+// AdminStats is the GET /admin/stats response
+type AdminStats struct {
+    Period      string        `json:"period"`
+    From        time.Time     `json:"from"`
+    To          time.Time     `json:"to"`
+    Buckets     []StatsBucket `json:"buckets"`
+    Totals      StatsTotals   `json:"totals"`
+    TopProducts []TopProduct  `json:"top_products"`
+}
+
+// NewStatsTotals sums buckets into totals
+func NewStatsTotals(buckets []StatsBucket) StatsTotals {
+    var totals StatsTotals
+    var revenueCount int
+    for _, b := range buckets {
+        totals.OrderCount += b.OrderCount
+        totals.Revenue += b.Revenue
+        revenueCount += b.RevenueCount
+        totals.CancelledCount += b.CancelledCount
+    }
+
+    if totals.OrderCount > 0 {
+        totals.CancellationRate = float64(totals.CancelledCount) / float64(totals.OrderCount)
+    }
+    // Pending and failed orders are neither revenue nor cancelled, so they can't be in the divisor
+    if revenueCount > 0 {
+        totals.AverageOrderValue = totals.Revenue / float64(revenueCount)
+    }
+    return totals
+}
+
+// OrderFunnel is the saga side of the conversion funnel over a window (GET /admin/funnel)
+type OrderFunnel struct {
+    From             time.Time      `json:"from"`
+    To               time.Time      `json:"to"`
+    SagasCompleted   int            `json:"sagas_completed"`
+    SagasFailed      int            `json:"sagas_failed"`
+    FailuresByReason map[string]int `json:"failures_by_reason"` // keyed by saga failure category
+    RevenueConfirmed float64        `json:"revenue_confirmed"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "math"
+
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// TaxLines are the taxes charged on an order, stored as JSONB
+type TaxLines []sharedmodels.TaxLine
+
+// Scan reads the JSONB column
+func (tl *TaxLines) Scan(src interface{}) error {
+    var raw []byte
+    switch v := src.(type) {
+    case nil:
+        *tl = TaxLines{}
+        return nil
+    case []byte:
+        raw = v
+    case string:
+        raw = []byte(v)
+    default:
+        return fmt.Errorf("cannot scan %T into tax lines", src)
+    }
+
+    lines := TaxLines{}
+    if err := json.Unmarshal(raw, &lines); err != nil {
+        return fmt.Errorf("failed to decode tax lines: %w", err)
+    }
+    *tl = lines
+    return nil
+}
+
+// Value writes the JSONB column; nil is stored as an empty array
+func (tl TaxLines) Value() (driver.Value, error) {
+    if tl == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal([]sharedmodels.TaxLine(tl))
+}
+
+// ApplyTax adds taxes to the order: its total so far becomes the subtotal, and the total
+// is the subtotal plus the tax lines
+func (o *Order) ApplyTax(lines []sharedmodels.TaxLine, taxTotal float64) {
+    subtotal := o.Total
+    o.Subtotal = &subtotal
+    o.TaxLines = TaxLines(lines)
+    if o.TaxLines == nil {
+        o.TaxLines = TaxLines{}
+    }
+    o.TaxTotal = taxTotal
+    o.Total = math.Round((subtotal+taxTotal)*100) / 100
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+    "testing"
+
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+func TestApplyTax(t *testing.T) {
+    order := NewOrder("user-1", "cart-1", 1, 59.98, "saga-1")
+    order.ApplyTax([]sharedmodels.TaxLine{{Name: "sales tax", Rate: 8.875, Amount: 5.32}}, 5.32)
+
+    if order.Subtotal == nil || *order.Subtotal != 59.98 {
+        t.Fatalf("subtotal = %v, want 59.98", order.Subtotal)
+    }
+    if order.Total != 65.30 || order.TaxTotal != 5.32 || len(order.TaxLines) != 1 {
+        t.Errorf("order = %+v, want 65.30 with one tax line", order)
+    }
+
+    untaxed := NewOrder("user-1", "cart-2", 1, 10, "saga-2")
+    untaxed.ApplyTax(nil, 0)
+    if untaxed.TaxLines == nil || untaxed.Total != 10 {
+        t.Errorf("untaxed order = %+v, want total 10 and empty tax lines", untaxed)
+    }
+}
+
+func TestTaxLinesRoundTrip(t *testing.T) {
+    value, err := TaxLines(nil).Value()
+    if err != nil || string(value.([]byte)) != "[]" {
+        t.Fatalf("nil Value = %s, %v; want []", value, err)
+    }
+
+    want := TaxLines{{Name: "vat", Region: "eu", Rate: 20, Amount: 2}}
+    value, err = want.Value()
+    if err != nil {
+        t.Fatalf("Value: %v", err)
+    }
+    var got TaxLines
+    if err := got.Scan(value); err != nil {
+        t.Fatalf("Scan: %v", err)
+    }
+    if len(got) != 1 || got[0] != want[0] {
+        t.Errorf("got %+v, want %+v", got, want)
+    }
+    if err := got.Scan(42); err == nil {
+        t.Error("expected an int to be rejected")
+    }
+}
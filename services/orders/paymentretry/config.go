@@ -0,0 +1,36 @@
+package paymentretry
+
+import "time"
+
+// Config holds the payment retry policy
+type Config struct {
+    Window    time.Duration // how long after a failed payment the customer may retry; 0 fails the order at once
+    Interval  time.Duration // how often the worker looks for lapsed windows
+    BatchSize int           // orders failed per run
+}
+
+// Enabled reports whether failed payments may be retried
+func (c Config) Enabled() bool {
+    return c.Window > 0
+}
+
+// DefaultConfig returns the policy defaults for an environment (ORDERS_ENV).
+// Why: development lapses quickly so the compensation path can be tried end to end;
+// production gives the customer time to fetch another card.
+func DefaultConfig(environment string) Config {
+    config := Config{
+        Window:    30 * time.Minute,
+        Interval:  1 * time.Minute,
+        BatchSize: 100,
+    }
+
+    switch environment {
+    case "development", "dev", "local":
+        config.Window = 3 * time.Minute
+        config.Interval = 15 * time.Second
+    case "staging":
+        config.Window = 10 * time.Minute
+    }
+
+    return config
+}
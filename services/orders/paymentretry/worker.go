@@ -0,0 +1,98 @@
+package paymentretry
+
+import (
+    "context"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/saga"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// Store finds and fails payment_pending orders whose retry window lapsed
+type Store interface {
+    ListPaymentExpired(ctx context.Context, now time.Time, limit int) ([]*models.PaymentPendingOrder, error)
+    FailIfPaymentExpired(ctx context.Context, orderID int64, now time.Time) (bool, error)
+    RevertPaymentFailure(ctx context.Context, orderID int64) error
+}
+
+// Publisher publishes order events
+type Publisher interface {
+    PublishOrderEventReliable(ctx context.Context, event interface{}) error
+}
+
+// Worker fails payment_pending orders once their retry window lapsed, which releases their stock
+type Worker struct {
+    store     Store
+    publisher Publisher
+    clock     clock.Clock
+    config    Config
+}
+
+// NewWorker creates new payment retry worker
+func NewWorker(store Store, publisher Publisher, clk clock.Clock, config Config) *Worker {
+    return &Worker{
+        store:     store,
+        publisher: publisher,
+        clock:     clk,
+        config:    config,
+    }
+}
+
+// Start launches the worker loop; it stops when ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) <-chan struct{} {
+    return clock.Every(ctx, w.clock, w.config.Interval, false, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce fails lapsed orders a single time and returns how many were failed.
+// Why: the order is flipped to failed before publishing so a retry can't slip in after
+// OrderFailed went out, and the next run, or another instance, can't publish it twice.
+func (w *Worker) RunOnce(ctx context.Context) int {
+    now := w.clock.Now()
+    due, err := w.store.ListPaymentExpired(ctx, now, w.config.BatchSize)
+    if err != nil {
+        log.Printf("❌ Failed to list orders with lapsed payment windows: %v", err)
+        return 0
+    }
+
+    failed := 0
+    for _, order := range due {
+        ok, err := w.store.FailIfPaymentExpired(ctx, order.OrderID, now)
+        if err != nil {
+            log.Printf("❌ Failed to fail order %d after its payment window: %v", order.OrderID, err)
+            continue
+        }
+        if !ok {
+            // Paid or cancelled since it was listed
+            continue
+        }
+
+        reason := saga.ReasonPaymentFailed + ": retry window lapsed"
+        if order.FailureReason != "" {
+            reason = saga.ReasonPaymentFailed + ": " + order.FailureReason + "; retry window lapsed"
+        }
+        event := events.OrderFailedEvent{
+            BaseEvent: events.NewBaseEvent("OrderFailed", strconv.FormatInt(order.OrderID, 10), "order", order.SagaCorrelationID),
+            OrderID:   strconv.FormatInt(order.OrderID, 10),
+            Reason:    reason,
+        }
+        if err := w.publisher.PublishOrderEventReliable(ctx, event); err != nil {
+            // Put it back to payment_pending so the next run retries instead of leaving the stock held
+            log.Printf("❌ Failed to publish OrderFailedEvent for order %d: %v", order.OrderID, err)
+            if err := w.store.RevertPaymentFailure(ctx, order.OrderID); err != nil {
+                log.Printf("❌ Failed to revert payment failure of order %d: %v", order.OrderID, err)
+            }
+            continue
+        }
+
+        failed++
+    }
+
+    if failed > 0 {
+        log.Printf("✓ Failed %d order(s) after their payment retry window", failed)
+    }
+    return failed
+}
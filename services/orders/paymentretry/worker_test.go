@@ -0,0 +1,145 @@
+package paymentretry
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// fakeOrder is a payment_pending order in the fake store
+type fakeOrder struct {
+    pending models.PaymentPendingOrder
+    status  string
+}
+
+type fakeStore struct {
+    orders map[int64]*fakeOrder
+}
+
+func (s *fakeStore) ListPaymentExpired(ctx context.Context, now time.Time, limit int) ([]*models.PaymentPendingOrder, error) {
+    due := []*models.PaymentPendingOrder{}
+    for _, o := range s.orders {
+        if o.status == "payment_pending" && !o.pending.Deadline.After(now) {
+            pending := o.pending
+            due = append(due, &pending)
+        }
+    }
+    return due, nil
+}
+
+func (s *fakeStore) FailIfPaymentExpired(ctx context.Context, orderID int64, now time.Time) (bool, error) {
+    o := s.orders[orderID]
+    if o.status != "payment_pending" || o.pending.Deadline.After(now) {
+        return false, nil
+    }
+    o.status = "failed"
+    return true, nil
+}
+
+func (s *fakeStore) RevertPaymentFailure(ctx context.Context, orderID int64) error {
+    if o := s.orders[orderID]; o.status == "failed" {
+        o.status = "payment_pending"
+    }
+    return nil
+}
+
+type fakePublisher struct {
+    published []events.OrderFailedEvent
+    err       error
+}
+
+func (p *fakePublisher) PublishOrderEventReliable(ctx context.Context, event interface{}) error {
+    if p.err != nil {
+        return p.err
+    }
+    p.published = append(p.published, event.(events.OrderFailedEvent))
+    return nil
+}
+
+func newTestWorker(fc *clock.Fake, orders ...*fakeOrder) (*Worker, *fakePublisher) {
+    store := &fakeStore{orders: map[int64]*fakeOrder{}}
+    for _, o := range orders {
+        store.orders[o.pending.OrderID] = o
+    }
+    publisher := &fakePublisher{}
+    config := Config{Window: 30 * time.Minute, Interval: time.Minute, BatchSize: 10}
+    return NewWorker(store, publisher, fc, config), publisher
+}
+
+func TestWorker_FailsOnlyAfterDeadline(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        pending: models.PaymentPendingOrder{OrderID: 1, SagaCorrelationID: "corr-1", FailureReason: "card declined", Deadline: fc.Now().Add(30 * time.Minute)},
+        status:  "payment_pending",
+    }
+    worker, publisher := newTestWorker(fc, order)
+
+    fc.Advance(29 * time.Minute)
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Fatalf("failed %d orders inside the window, want 0", n)
+    }
+
+    fc.Advance(time.Minute)
+    if n := worker.RunOnce(context.Background()); n != 1 {
+        t.Fatalf("failed %d orders after the window, want 1", n)
+    }
+    if order.status != "failed" {
+        t.Errorf("status = %q, want failed", order.status)
+    }
+    if len(publisher.published) != 1 {
+        t.Fatalf("published %d events, want 1", len(publisher.published))
+    }
+    event := publisher.published[0]
+    if event.OrderID != "1" || event.CorrelationID != "corr-1" || event.Reason != "payment failed: card declined; retry window lapsed" {
+        t.Errorf("unexpected event: %+v", event)
+    }
+
+    // Already failed: nothing is published again
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Errorf("second run failed %d orders, want 0", n)
+    }
+}
+
+func TestWorker_SkipsPaidOrders(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        pending: models.PaymentPendingOrder{OrderID: 2, Deadline: fc.Now()},
+        status:  "placed",
+    }
+    worker, publisher := newTestWorker(fc, order)
+
+    fc.Advance(time.Hour)
+    if n := worker.RunOnce(context.Background()); n != 0 || len(publisher.published) != 0 {
+        t.Fatalf("paid order was failed")
+    }
+}
+
+func TestWorker_RevertsWhenPublishFails(t *testing.T) {
+    fc := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    order := &fakeOrder{
+        pending: models.PaymentPendingOrder{OrderID: 3, Deadline: fc.Now()},
+        status:  "payment_pending",
+    }
+    worker, publisher := newTestWorker(fc, order)
+    publisher.err = errors.New("broker down")
+
+    if n := worker.RunOnce(context.Background()); n != 0 {
+        t.Fatalf("failed %d orders while publishing fails, want 0", n)
+    }
+    if order.status != "payment_pending" {
+        t.Fatalf("status = %q, want payment_pending after revert", order.status)
+    }
+
+    publisher.err = nil
+    if n := worker.RunOnce(context.Background()); n != 1 {
+        t.Fatalf("failed %d orders once publishing recovered, want 1", n)
+    }
+    if publisher.published[0].Reason != "payment failed: retry window lapsed" {
+        t.Errorf("reason = %q", publisher.published[0].Reason)
+    }
+}
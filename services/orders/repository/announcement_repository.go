@@ -0,0 +1,74 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+const announcementColumns = `id, kind, title, message, starts_at, ends_at, created_by, created_at`
+
+// AnnouncementRepository handles storefront announcements
+type AnnouncementRepository struct {
+    conn *db.Connection
+}
+
+// NewAnnouncementRepository creates new announcement repository
+func NewAnnouncementRepository(conn *db.Connection) *AnnouncementRepository {
+    return &AnnouncementRepository{conn: conn}
+}
+
+// CreateAnnouncement stores an announcement and sets its ID
+func (ar *AnnouncementRepository) CreateAnnouncement(ctx context.Context, a *models.Announcement) error {
+    query := ar.conn.Qualify(`
+        INSERT INTO $schema.announcements (kind, title, message, starts_at, ends_at, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `)
+
+    err := ar.conn.QueryRowContext(ctx, query,
+        a.Kind,
+        a.Title,
+        a.Message,
+        a.StartsAt,
+        a.EndsAt,
+        a.CreatedBy,
+        a.CreatedAt,
+    ).Scan(&a.ID)
+    if err != nil {
+        return fmt.Errorf("failed to create announcement: %w", err)
+    }
+    return nil
+}
+
+// ListActiveAnnouncements returns the announcements shown at now, newest first
+func (ar *AnnouncementRepository) ListActiveAnnouncements(ctx context.Context, now time.Time) ([]*models.Announcement, error) {
+    query := ar.conn.Qualify(`
+        SELECT ` + announcementColumns + `
+        FROM $schema.announcements
+        WHERE starts_at <= $1 AND (ends_at IS NULL OR ends_at > $1)
+        ORDER BY starts_at DESC, id DESC
+    `)
+
+    rows, err := ar.conn.QueryContext(ctx, query, now)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list announcements: %w", err)
+    }
+    defer rows.Close()
+
+    announcements := []*models.Announcement{}
+    for rows.Next() {
+        a := &models.Announcement{}
+        if err := rows.Scan(&a.ID, &a.Kind, &a.Title, &a.Message, &a.StartsAt, &a.EndsAt, &a.CreatedBy, &a.CreatedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan announcement: %w", err)
+        }
+        announcements = append(announcements, a)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read announcements: %w", err)
+    }
+    return announcements, nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// ErrCheckoutNotFound is returned when no checkout has the given ID
+var ErrCheckoutNotFound = errors.New("checkout not found")
+
+// CheckoutRepository handles checkouts split into several child orders
+type CheckoutRepository struct {
+    conn *db.Connection
+}
+
+// NewCheckoutRepository creates new checkout repository
+func NewCheckoutRepository(conn *db.Connection) *CheckoutRepository {
+    return &CheckoutRepository{conn: conn}
+}
+
+// CreateCheckout creates the checkout and all its child orders in one transaction
+// Why: a checkout missing some of its orders would be placed without their items
+func (cr *CheckoutRepository) CreateCheckout(ctx context.Context, checkout *models.Checkout) error {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO $schema.checkouts (id, user_id, cart_id, total, saga_correlation_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+    query = cr.conn.Qualify(query)
+
+    _, err = tx.ExecContext(ctx, query,
+        checkout.ID,
+        checkout.UserID,
+        checkout.CartID,
+        checkout.Total,
+        checkout.SagaCorrelationID,
+        checkout.CreatedAt,
+    )
+    if err != nil {
+        return fmt.Errorf("failed to create checkout: %w", err)
+    }
+
+    for _, order := range checkout.Orders {
+        order.CheckoutID = &checkout.ID
+        if err := insertOrder(ctx, tx, cr.conn, order); err != nil {
+            return err
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit checkout: %w", err)
+    }
+
+    return nil
+}
+
+// GetCheckout retrieves a checkout with its orders and their items, and its combined status
+func (cr *CheckoutRepository) GetCheckout(ctx context.Context, checkoutID int64) (*models.Checkout, error) {
+    query := `
+        SELECT id, user_id, cart_id, total, saga_correlation_id, created_at
+        FROM $schema.checkouts
+        WHERE id = $1
+    `
+
+    query = cr.conn.Qualify(query)
+
+    checkout := &models.Checkout{}
+    err := cr.conn.QueryRowContext(ctx, query, checkoutID).Scan(
+        &checkout.ID,
+        &checkout.UserID,
+        &checkout.CartID,
+        &checkout.Total,
+        &checkout.SagaCorrelationID,
+        &checkout.CreatedAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, ErrCheckoutNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get checkout: %w", err)
+    }
+
+    orders, err := cr.GetCheckoutOrders(ctx, checkoutID)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]string, len(orders))
+    for i, order := range orders {
+        statuses[i] = order.Status
+    }
+    checkout.Orders = orders
+    checkout.Status = models.CombinedStatus(statuses)
+
+    return checkout, nil
+}
+
+// GetCheckoutOrders retrieves the child orders of a checkout with their items, oldest first
+func (cr *CheckoutRepository) GetCheckoutOrders(ctx context.Context, checkoutID int64) ([]*models.Order, error) {
+    query := `
+        SELECT id, user_id, cart_id, total, status, saga_correlation_id,
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
+        FROM $schema.orders
+        WHERE checkout_id = $1
+        ORDER BY id ASC
+    `
+
+    query = cr.conn.Qualify(query)
+
+    rows, err := cr.conn.QueryContext(ctx, query, checkoutID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get checkout orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders, err := scanOrders(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    orderRepo := NewOrderRepository(cr.conn)
+    for _, order := range orders {
+        items, err := orderRepo.orderItems(ctx, order.ID)
+        if err != nil {
+            return nil, err
+        }
+        order.Items = items
+    }
+
+    return orders, nil
+}
+
+// PlaceCheckoutIfReserved places every pending order of the checkout once all of them have
+// reserved stock, and returns the IDs of the orders it placed. It returns nothing while some
+// orders are still waiting for stock.
+// Why: the checkout row is locked so two StockReserved events handled at once can't both see
+// the other child as unreserved and leave the checkout pending forever
+func (cr *CheckoutRepository) PlaceCheckoutIfReserved(ctx context.Context, checkoutID int64) ([]int64, error) {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    lockQuery := cr.conn.Qualify(`SELECT id FROM $schema.checkouts WHERE id = $1 FOR UPDATE`)
+    if err := tx.QueryRowContext(ctx, lockQuery, checkoutID).Scan(&checkoutID); err != nil {
+        if err == sql.ErrNoRows {
+            return nil, ErrCheckoutNotFound
+        }
+        return nil, fmt.Errorf("failed to lock checkout: %w", err)
+    }
+
+    waitingQuery := `
+        SELECT COUNT(*)
+        FROM $schema.orders o
+        WHERE o.checkout_id = $1
+          AND NOT EXISTS (
+              SELECT 1 FROM $schema.inventory_reservations r
+              WHERE r.order_id = o.id AND r.status = 'reserved'
+          )
+    `
+
+    waitingQuery = cr.conn.Qualify(waitingQuery)
+
+    var waiting int
+    if err := tx.QueryRowContext(ctx, waitingQuery, checkoutID).Scan(&waiting); err != nil {
+        return nil, fmt.Errorf("failed to count unreserved orders: %w", err)
+    }
+    if waiting > 0 {
+        return nil, nil
+    }
+
+    placeQuery := `
+        UPDATE $schema.orders
+        SET status = 'placed', placed_at = COALESCE(placed_at, $2), updated_at = $2, version = version + 1
+        WHERE checkout_id = $1 AND status = 'pending'
+        RETURNING id
+    `
+
+    placeQuery = cr.conn.Qualify(placeQuery)
+
+    rows, err := tx.QueryContext(ctx, placeQuery, checkoutID, time.Now().UTC())
+    if err != nil {
+        return nil, fmt.Errorf("failed to place checkout orders: %w", err)
+    }
+
+    var placed []int64
+    for rows.Next() {
+        var id int64
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return nil, fmt.Errorf("failed to scan placed order: %w", err)
+        }
+        placed = append(placed, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to place checkout orders: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit checkout placement: %w", err)
+    }
+
+    return placed, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
     "context"
+    "database/sql"
     "encoding/json"
     "fmt"
     "time"
@@ -33,7 +34,7 @@ func (clr *CompensationLogRepository) CreateCompensationLog(ctx context.Context,
         RETURNING id
     `
 
-    query = replaceSchema(query, clr.conn.Schema)
+    query = clr.conn.Qualify(query)
 
     err = clr.conn.QueryRowContext(ctx, query,
         log.ID,
@@ -62,7 +63,7 @@ func (clr *CompensationLogRepository) GetCompensationLogsByOrderID(ctx context.C
         ORDER BY created_at ASC
     `
 
-    query = replaceSchema(query, clr.conn.Schema)
+    query = clr.conn.Qualify(query)
 
     rows, err := clr.conn.QueryContext(ctx, query, orderID)
     if err != nil {
@@ -70,6 +71,34 @@ func (clr *CompensationLogRepository) GetCompensationLogsByOrderID(ctx context.C
     }
     defer rows.Close()
 
+    return scanCompensationLogs(rows)
+}
+
+// GetCompensationLogsByCorrelationID retrieves the compensation logs of every order of a saga, oldest first
+func (clr *CompensationLogRepository) GetCompensationLogsByCorrelationID(ctx context.Context, correlationID string) ([]*models.CompensationLog, error) {
+    query := `
+        SELECT id, order_id, saga_correlation_id, compensation_event, compensation_payload, status, created_at, completed_at
+        FROM $schema.compensation_log
+        WHERE saga_correlation_id = $1
+        ORDER BY created_at ASC
+    `
+
+    query = clr.conn.Qualify(query)
+
+    rows, err := clr.conn.QueryContext(ctx, query, correlationID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get compensation logs: %w", err)
+    }
+    defer rows.Close()
+
+    logs, err := scanCompensationLogs(rows)
+    if logs == nil && err == nil {
+        logs = []*models.CompensationLog{}
+    }
+    return logs, err
+}
+
+func scanCompensationLogs(rows *sql.Rows) ([]*models.CompensationLog, error) {
     var logs []*models.CompensationLog
     for rows.Next() {
         log := &models.CompensationLog{}
@@ -108,7 +137,7 @@ func (clr *CompensationLogRepository) UpdateCompensationStatus(ctx context.Conte
         WHERE id = $3
     `
 
-    query = replaceSchema(query, clr.conn.Schema)
+    query = clr.conn.Qualify(query)
 
     _, err := clr.conn.ExecContext(ctx, query, status, time.Now().UTC(), logID)
     if err != nil {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// FulfillmentRepository keeps the 3PL pushes that failed until a retry gets through
+type FulfillmentRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewFulfillmentRepository creates new fulfillment repository
+func NewFulfillmentRepository(conn *db.Connection, clk clock.Clock) *FulfillmentRepository {
+    return &FulfillmentRepository{conn: conn, clock: clk}
+}
+
+// SavePendingPush records a failed push of the order, or counts another failed attempt of one
+// already recorded
+func (fr *FulfillmentRepository) SavePendingPush(ctx context.Context, orderID int64, payload json.RawMessage, reason string) error {
+    query := `
+        INSERT INTO $schema.pending_fulfillment_pushes (order_id, payload, last_error, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $4)
+        ON CONFLICT (order_id) DO UPDATE
+        SET attempts = pending_fulfillment_pushes.attempts + 1,
+            last_error = EXCLUDED.last_error,
+            updated_at = EXCLUDED.updated_at
+    `
+    query = fr.conn.Qualify(query)
+
+    if _, err := fr.conn.ExecContext(ctx, query, orderID, []byte(payload), reason, fr.clock.Now()); err != nil {
+        return fmt.Errorf("failed to save pending fulfillment push: %w", err)
+    }
+    return nil
+}
+
+// ListPendingPushes returns up to limit failed pushes, least recently tried first
+func (fr *FulfillmentRepository) ListPendingPushes(ctx context.Context, limit int) ([]*models.PendingFulfillmentPush, error) {
+    query := `
+        SELECT order_id, payload, attempts, last_error, created_at
+        FROM $schema.pending_fulfillment_pushes
+        ORDER BY updated_at ASC
+        LIMIT $1
+    `
+    query = fr.conn.Qualify(query)
+
+    rows, err := fr.conn.QueryContext(ctx, query, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list pending fulfillment pushes: %w", err)
+    }
+    defer rows.Close()
+
+    pushes := []*models.PendingFulfillmentPush{}
+    for rows.Next() {
+        push := &models.PendingFulfillmentPush{}
+        if err := rows.Scan(&push.OrderID, &push.Payload, &push.Attempts, &push.LastError, &push.CreatedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan pending fulfillment push: %w", err)
+        }
+        pushes = append(pushes, push)
+    }
+
+    return pushes, rows.Err()
+}
+
+// DeletePendingPush forgets the failed push of the order once it got through or isn't needed
+func (fr *FulfillmentRepository) DeletePendingPush(ctx context.Context, orderID int64) error {
+    query := `DELETE FROM $schema.pending_fulfillment_pushes WHERE order_id = $1`
+    query = fr.conn.Qualify(query)
+
+    if _, err := fr.conn.ExecContext(ctx, query, orderID); err != nil {
+        return fmt.Errorf("failed to delete pending fulfillment push: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrOrderNotFound is returned when no order has the given ID
+    ErrOrderNotFound = errors.New("order not found")
+    // ErrOrderVersionConflict is returned when an order changed since the version a write was based on
+    ErrOrderVersionConflict = errors.New("order was changed by another request")
+    // ErrOrderNotHoldable is returned when holding an order that is no longer placed
+    ErrOrderNotHoldable = errors.New("only placed orders can be put on hold")
+    // ErrHoldExists is returned when the order already has an active hold for the reason
+    ErrHoldExists = errors.New("order already has an active hold for this reason")
+    // ErrHoldNotFound is returned when releasing an order without matching active holds
+    ErrHoldNotFound = errors.New("no active hold found")
+)
+
+const holdColumns = `id, order_id, reason, note, created_by, created_at, released_at, released_by`
+
+// HoldRepository handles payment/fraud holds on placed orders
+type HoldRepository struct {
+    conn *db.Connection
+}
+
+// NewHoldRepository creates new order hold repository
+func NewHoldRepository(conn *db.Connection) *HoldRepository {
+    return &HoldRepository{conn: conn}
+}
+
+// PlaceHold puts a placed order on hold
+// Why: the order row is locked so a hold can't slip in after the auto-confirm worker confirmed it
+func (hr *HoldRepository) PlaceHold(ctx context.Context, orderID int64, reason, note, createdBy string) (*models.OrderHold, error) {
+    query := `
+        INSERT INTO $schema.order_holds (order_id, reason, note, created_by, created_at)
+        SELECT id, $2, NULLIF($3, ''), NULLIF($4, ''), $5
+        FROM $schema.orders
+        WHERE id = $1 AND status = 'placed'
+        FOR UPDATE
+        ON CONFLICT DO NOTHING
+        RETURNING ` + holdColumns
+    query = hr.conn.Qualify(query)
+
+    hold, err := scanHold(hr.conn.QueryRowContext(ctx, query, orderID, reason, note, createdBy, time.Now().UTC()))
+    if err == sql.ErrNoRows {
+        return nil, hr.explainMissedHold(ctx, orderID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to place hold: %w", err)
+    }
+
+    return hold, nil
+}
+
+// HoldUserOrders puts every open order of a user on hold for reason and returns the holds
+// placed. Unlike PlaceHold it also holds pending and payment_pending orders: the hold stays
+// active when they reach placed, so they aren't auto-confirmed either. Orders already held
+// for reason are skipped, so running it twice places nothing new.
+func (hr *HoldRepository) HoldUserOrders(ctx context.Context, userID, reason, note, createdBy string) ([]*models.OrderHold, error) {
+    query := `
+        INSERT INTO $schema.order_holds (order_id, reason, note, created_by, created_at)
+        SELECT id, $2, NULLIF($3, ''), NULLIF($4, ''), $5
+        FROM $schema.orders
+        WHERE user_id = $1 AND status IN ('pending', 'payment_pending', 'placed')
+        ON CONFLICT DO NOTHING
+        RETURNING ` + holdColumns
+    query = hr.conn.Qualify(query)
+
+    rows, err := hr.conn.QueryContext(ctx, query, userID, reason, note, createdBy, time.Now().UTC())
+    if err != nil {
+        return nil, fmt.Errorf("failed to hold user orders: %w", err)
+    }
+    defer rows.Close()
+
+    return scanHolds(rows)
+}
+
+// ListActiveHolds lists the holds still blocking orders, oldest first; an empty reason lists all
+func (hr *HoldRepository) ListActiveHolds(ctx context.Context, reason string, limit int) ([]*models.OrderHold, error) {
+    query := hr.conn.Qualify(`
+        SELECT ` + holdColumns + `
+        FROM $schema.order_holds
+        WHERE released_at IS NULL AND ($1 = '' OR reason = $1)
+        ORDER BY created_at ASC, id ASC
+        LIMIT $2
+    `)
+
+    rows, err := hr.conn.QueryContext(ctx, query, reason, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list active holds: %w", err)
+    }
+    defer rows.Close()
+
+    return scanHolds(rows)
+}
+
+// explainMissedHold works out why PlaceHold inserted nothing
+func (hr *HoldRepository) explainMissedHold(ctx context.Context, orderID int64) error {
+    query := hr.conn.Qualify(`SELECT status FROM $schema.orders WHERE id = $1`)
+
+    var status string
+    err := hr.conn.QueryRowContext(ctx, query, orderID).Scan(&status)
+    if err == sql.ErrNoRows {
+        return ErrOrderNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get order status: %w", err)
+    }
+    if status != "placed" {
+        return ErrOrderNotHoldable
+    }
+    return ErrHoldExists
+}
+
+// ReleaseHolds releases the order's active holds for reason (all reasons when empty)
+func (hr *HoldRepository) ReleaseHolds(ctx context.Context, orderID int64, reason, releasedBy string) ([]*models.OrderHold, error) {
+    query := `
+        UPDATE $schema.order_holds
+        SET released_at = $3, released_by = NULLIF($4, '')
+        WHERE order_id = $1 AND released_at IS NULL AND ($2 = '' OR reason = $2)
+        RETURNING ` + holdColumns
+    query = hr.conn.Qualify(query)
+
+    rows, err := hr.conn.QueryContext(ctx, query, orderID, reason, time.Now().UTC(), releasedBy)
+    if err != nil {
+        return nil, fmt.Errorf("failed to release holds: %w", err)
+    }
+    defer rows.Close()
+
+    holds, err := scanHolds(rows)
+    if err != nil {
+        return nil, err
+    }
+    if len(holds) == 0 {
+        return nil, ErrHoldNotFound
+    }
+    return holds, nil
+}
+
+// ListHolds lists an order's holds, newest first
+func (hr *HoldRepository) ListHolds(ctx context.Context, orderID int64) ([]*models.OrderHold, error) {
+    query := hr.conn.Qualify(`SELECT `+holdColumns+` FROM $schema.order_holds WHERE order_id = $1 ORDER BY created_at DESC, id DESC`)
+
+    rows, err := hr.conn.QueryContext(ctx, query, orderID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list holds: %w", err)
+    }
+    defer rows.Close()
+
+    return scanHolds(rows)
+}
+
+// ListAutoConfirmable returns placed orders older than placedBefore with no active hold
+func (hr *HoldRepository) ListAutoConfirmable(ctx context.Context, placedBefore time.Time, limit int) ([]*models.PlacedOrder, error) {
+    query := `
+        SELECT o.id, o.saga_correlation_id, o.placed_at
+        FROM $schema.orders o
+        WHERE o.status = 'placed'
+          AND o.placed_at <= $1
+          AND NOT EXISTS (
+              SELECT 1 FROM $schema.order_holds h
+              WHERE h.order_id = o.id AND h.released_at IS NULL
+          )
+        ORDER BY o.placed_at ASC
+        LIMIT $2
+    `
+    query = hr.conn.Qualify(query)
+
+    rows, err := hr.conn.QueryContext(ctx, query, placedBefore, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list auto-confirmable orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders := []*models.PlacedOrder{}
+    for rows.Next() {
+        order := &models.PlacedOrder{}
+        if err := rows.Scan(&order.OrderID, &order.SagaCorrelationID, &order.PlacedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan placed order: %w", err)
+        }
+        orders = append(orders, order)
+    }
+
+    return orders, rows.Err()
+}
+
+// ConfirmIfUnheld moves a placed order without active holds to confirmed.
+// It returns false when the order was held, cancelled or confirmed in the meantime.
+func (hr *HoldRepository) ConfirmIfUnheld(ctx context.Context, orderID int64) (bool, error) {
+    query := `
+        UPDATE $schema.orders o
+        SET status = 'confirmed', updated_at = $2, version = o.version + 1
+        WHERE o.id = $1
+          AND o.status = 'placed'
+          AND NOT EXISTS (
+              SELECT 1 FROM $schema.order_holds h
+              WHERE h.order_id = o.id AND h.released_at IS NULL
+          )
+    `
+    query = hr.conn.Qualify(query)
+
+    result, err := hr.conn.ExecContext(ctx, query, orderID, time.Now().UTC())
+    if err != nil {
+        return false, fmt.Errorf("failed to confirm order: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    return rowsAffected > 0, nil
+}
+
+// RevertConfirmation moves an auto-confirmed order back to placed when its event couldn't be published
+func (hr *HoldRepository) RevertConfirmation(ctx context.Context, orderID int64) error {
+    query := hr.conn.Qualify(`UPDATE $schema.orders SET status = 'placed', updated_at = $2, version = version + 1 WHERE id = $1 AND status = 'confirmed'`)
+
+    if _, err := hr.conn.ExecContext(ctx, query, orderID, time.Now().UTC()); err != nil {
+        return fmt.Errorf("failed to revert order confirmation: %w", err)
+    }
+    return nil
+}
+
+func scanHolds(rows *sql.Rows) ([]*models.OrderHold, error) {
+    holds := []*models.OrderHold{}
+    for rows.Next() {
+        hold, err := scanHold(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan hold: %w", err)
+        }
+        holds = append(holds, hold)
+    }
+    return holds, rows.Err()
+}
+
+func scanHold(row interface{ Scan(...interface{}) error }) (*models.OrderHold, error) {
+    hold := &models.OrderHold{}
+    err := row.Scan(
+        &hold.ID,
+        &hold.OrderID,
+        &hold.Reason,
+        &hold.Note,
+        &hold.CreatedBy,
+        &hold.CreatedAt,
+        &hold.ReleasedAt,
+        &hold.ReleasedBy,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return hold, nil
+}
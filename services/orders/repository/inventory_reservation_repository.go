@@ -29,7 +29,7 @@ func (irr *InventoryReservationRepository) CreateReservation(ctx context.Context
         RETURNING id
     `
 
-    query = replaceSchema(query, irr.conn.Schema)
+    query = irr.conn.Qualify(query)
 
     err := irr.conn.QueryRowContext(ctx, query,
         res.ID,
@@ -58,7 +58,7 @@ func (irr *InventoryReservationRepository) GetReservationsByOrderID(ctx context.
         WHERE order_id = $1
     `
 
-    query = replaceSchema(query, irr.conn.Schema)
+    query = irr.conn.Qualify(query)
 
     rows, err := irr.conn.QueryContext(ctx, query, orderID)
     if err != nil {
@@ -98,7 +98,7 @@ func (irr *InventoryReservationRepository) UpdateReservationStatus(ctx context.C
         WHERE reservation_id = $2
     `
 
-    query = replaceSchema(query, irr.conn.Schema)
+    query = irr.conn.Qualify(query)
 
     _, err := irr.conn.ExecContext(ctx, query, status, reservationID)
     if err != nil {
@@ -116,7 +116,7 @@ func (irr *InventoryReservationRepository) ReleaseReservation(ctx context.Contex
         WHERE reservation_id = $2 AND status = 'reserved'
     `
 
-    query = replaceSchema(query, irr.conn.Schema)
+    query = irr.conn.Qualify(query)
 
     result, err := irr.conn.ExecContext(ctx, query, time.Now().UTC(), reservationID)
     if err != nil {
@@ -2,12 +2,15 @@ package repository
 
 import (
     "context"
+    "database/sql"
     "fmt"
     "log"
+    "strings"
     "time"
 
     "github.com/sanketh-sg/prost/services/orders/models"
     "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/pagination"
 )
 
 // OrderRepository handles order database operations
@@ -20,18 +23,39 @@ func NewOrderRepository(conn *db.Connection) *OrderRepository {
     return &OrderRepository{conn: conn}
 }
 
-// CreateOrder creates a new order
+// CreateOrder creates a new order and its items in one transaction
+// Why: an order without its items can't be reserved, shown or reported on, so either both are stored or neither
 func (or *OrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+    tx, err := or.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if err := insertOrder(ctx, tx, or.conn, order); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit order: %w", err)
+    }
+
+    return nil
+}
+
+// insertOrder inserts an order and its items inside tx
+func insertOrder(ctx context.Context, tx *sql.Tx, conn *db.Connection, order *models.Order) error {
     query := `
         INSERT INTO $schema.orders 
-        (id, user_id, cart_id, total, status, saga_correlation_id, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        RETURNING id, user_id, cart_id, total, status, saga_correlation_id, created_at, updated_at
+        (id, user_id, cart_id, total, status, saga_correlation_id, created_at, updated_at,
+         checkout_id, warehouse, fulfillment_type, subtotal, tax_total, tax_lines, shipping_address)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+        RETURNING id, user_id, cart_id, total, status, saga_correlation_id, created_at, updated_at, version
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = conn.Qualify(query)
 
-    err := or.conn.QueryRowContext(ctx, query,
+    err := tx.QueryRowContext(ctx, query,
         order.ID,
         order.UserID,
         order.CartID,
@@ -40,6 +64,13 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, order *models.Order)
         order.SagaCorrelationID,
         order.CreatedAt,
         order.UpdatedAt,
+        order.CheckoutID,
+        order.Warehouse,
+        order.FulfillmentType,
+        order.Subtotal,
+        order.TaxTotal,
+        order.TaxLines,
+        order.ShippingAddress,
     ).Scan(
         &order.ID,
         &order.UserID,
@@ -49,6 +80,7 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, order *models.Order)
         &order.SagaCorrelationID,
         &order.CreatedAt,
         &order.UpdatedAt,
+        &order.Version,
     )
 
     if err != nil {
@@ -56,6 +88,33 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, order *models.Order)
         return fmt.Errorf("failed to create order: %w", err)
     }
 
+    itemQuery := `
+        INSERT INTO $schema.order_items (order_id, product_id, variant_id, quantity, price, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, order_id, product_id, variant_id, quantity, price, created_at
+    `
+
+    itemQuery = conn.Qualify(itemQuery)
+
+    for i := range order.Items {
+        item := &order.Items[i]
+        item.OrderID = order.ID
+        item.CreatedAt = order.CreatedAt
+
+        err := tx.QueryRowContext(ctx, itemQuery,
+            item.OrderID,
+            item.ProductID,
+            item.VariantID,
+            item.Quantity,
+            item.Price,
+            item.CreatedAt,
+        ).Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.CreatedAt)
+
+        if err != nil {
+            return fmt.Errorf("failed to add order item %d: %w", item.ProductID, err)
+        }
+    }
+
     return nil
 }
 
@@ -63,12 +122,15 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, order *models.Order)
 func (or *OrderRepository) GetOrder(ctx context.Context, orderID int64) (*models.Order, error) {
     query := `
         SELECT id, user_id, cart_id, total, status, saga_correlation_id, 
-               created_at, updated_at, shipped_at, delivered_at, cancelled_at
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
         FROM $schema.orders
         WHERE id = $1
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = or.conn.Qualify(query)
 
     order := &models.Order{}
     err := or.conn.QueryRowContext(ctx, query, orderID).Scan(
@@ -83,21 +145,44 @@ func (or *OrderRepository) GetOrder(ctx context.Context, orderID int64) (*models
         &order.ShippedAt,
         &order.DeliveredAt,
         &order.CancelledAt,
+        &order.TrackingNumber,
+        &order.Carrier,
+        &order.CheckoutID,
+        &order.Warehouse,
+        &order.FulfillmentType,
+        &order.PaymentAttempts,
+        &order.PaymentDeadline,
+        &order.PaymentFailureReason,
+        &order.Subtotal,
+        &order.TaxTotal,
+        &order.TaxLines,
+        &order.ShippingAddress,
+        &order.Version,
     )
 
     if err != nil {
         return nil, fmt.Errorf("failed to get order: %w", err)
     }
 
-    // Get order items
+    items, err := or.orderItems(ctx, orderID)
+    if err != nil {
+        return nil, err
+    }
+    order.Items = items
+
+    return order, nil
+}
+
+// orderItems returns an order's line items
+func (or *OrderRepository) orderItems(ctx context.Context, orderID int64) ([]models.OrderItem, error) {
     itemsQuery := `
-        SELECT id, order_id, product_id, quantity, price, created_at
+        SELECT id, order_id, product_id, variant_id, quantity, price, created_at
         FROM $schema.order_items
         WHERE order_id = $1
         ORDER BY created_at ASC
     `
 
-    itemsQuery = replaceSchema(itemsQuery, or.conn.Schema)
+    itemsQuery = or.conn.Qualify(itemsQuery)
 
     rows, err := or.conn.QueryContext(ctx, itemsQuery, orderID)
     if err != nil {
@@ -105,29 +190,33 @@ func (or *OrderRepository) GetOrder(ctx context.Context, orderID int64) (*models
     }
     defer rows.Close()
 
+    var items []models.OrderItem
     for rows.Next() {
-        item := &models.OrderItem{}
-        err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt)
+        item := models.OrderItem{}
+        err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.CreatedAt)
         if err != nil {
             return nil, fmt.Errorf("failed to scan order item: %w", err)
         }
-        order.Items = append(order.Items, *item) //store the list of items in order
+        items = append(items, item)
     }
 
-    return order, nil
+    return items, rows.Err()
 }
 
 // GetOrdersByUserID retrieves all orders for a user
 func (or *OrderRepository) GetOrdersByUserID(ctx context.Context, userID string) ([]*models.Order, error) {
     query := `
         SELECT id, user_id, cart_id, total, status, saga_correlation_id, 
-               created_at, updated_at, shipped_at, delivered_at, cancelled_at
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
         FROM $schema.orders
         WHERE user_id = $1
         ORDER BY created_at DESC
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = or.conn.Qualify(query)
 
     rows, err := or.conn.QueryContext(ctx, query, userID)
     if err != nil {
@@ -135,6 +224,229 @@ func (or *OrderRepository) GetOrdersByUserID(ctx context.Context, userID string)
     }
     defer rows.Close()
 
+    return scanOrders(rows)
+}
+
+// GetOrdersByCorrelationID retrieves the orders a checkout saga created, with their items, oldest first
+func (or *OrderRepository) GetOrdersByCorrelationID(ctx context.Context, correlationID string) ([]*models.Order, error) {
+    query := `
+        SELECT id, user_id, cart_id, total, status, saga_correlation_id,
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
+        FROM $schema.orders
+        WHERE saga_correlation_id = $1
+        ORDER BY id ASC
+    `
+
+    query = or.conn.Qualify(query)
+
+    rows, err := or.conn.QueryContext(ctx, query, correlationID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get orders by correlation id: %w", err)
+    }
+    defer rows.Close()
+
+    orders, err := scanOrders(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    for _, order := range orders {
+        items, err := or.orderItems(ctx, order.ID)
+        if err != nil {
+            return nil, err
+        }
+        order.Items = items
+    }
+
+    return orders, nil
+}
+
+// orderSortClauses whitelists ORDER BY clauses for order history
+var orderSortClauses = map[string]string{
+    models.SortCreatedDesc: "created_at DESC, id DESC",
+    models.SortCreatedAsc:  "created_at ASC, id ASC",
+    models.SortTotalDesc:   "total DESC, id DESC",
+    models.SortTotalAsc:    "total ASC, id ASC",
+}
+
+// orderKeysets are the sort key column of each order history sort and whether it descends;
+// the order ID breaks ties in the same direction
+var orderKeysets = map[string]struct {
+    column string
+    desc   bool
+}{
+    models.SortCreatedDesc: {"created_at", true},
+    models.SortCreatedAsc:  {"created_at", false},
+    models.SortTotalDesc:   {"total", true},
+    models.SortTotalAsc:    {"total", false},
+}
+
+// GetOrdersByUserIDFiltered retrieves one page of a user's orders matching filter,
+// along with the total number of matching orders
+func (or *OrderRepository) GetOrdersByUserIDFiltered(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+    where, args := buildOrderFilter(filter, true)
+
+    countQuery := or.conn.Qualify(`SELECT COUNT(*) FROM $schema.orders WHERE `+where)
+
+    var total int
+    if err := or.conn.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+        return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+    }
+
+    sortClause, ok := orderSortClauses[filter.Sort]
+    if !ok {
+        sortClause = orderSortClauses[models.SortCreatedDesc]
+    }
+
+    query := `
+        SELECT id, user_id, cart_id, total, status, saga_correlation_id, 
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
+        FROM $schema.orders
+        WHERE ` + where + `
+        ORDER BY ` + sortClause + fmt.Sprintf(`
+        LIMIT $%d OFFSET $%d
+    `, len(args)+1, len(args)+2)
+
+    query = or.conn.Qualify(query)
+    args = append(args, filter.Limit, filter.Offset())
+
+    rows, err := or.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to get filtered orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders, err := scanOrders(rows)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return orders, total, nil
+}
+
+// GetOrdersPageByUserID retrieves one keyset page of a user's orders matching filter, in
+// filter.Sort order, along with the total number of matching orders. It returns up to
+// page.Limit() orders.
+func (or *OrderRepository) GetOrdersPageByUserID(ctx context.Context, filter models.OrderFilter, page pagination.Page) ([]*models.Order, int, error) {
+    where, args := buildOrderFilter(filter, true)
+
+    countQuery := or.conn.Qualify(`SELECT COUNT(*) FROM $schema.orders WHERE `+where)
+
+    var total int
+    if err := or.conn.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+        return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+    }
+
+    sort := filter.Sort
+    if _, ok := orderKeysets[sort]; !ok {
+        sort = models.SortCreatedDesc
+    }
+    keyset := orderKeysets[sort]
+    after, afterArgs := page.Condition(keyset.column, "id", keyset.desc, len(args)+1)
+    args = append(args, afterArgs...)
+    args = append(args, page.Limit())
+
+    query := `
+        SELECT id, user_id, cart_id, total, status, saga_correlation_id, 
+               created_at, updated_at, shipped_at, delivered_at, cancelled_at,
+               tracking_number, carrier, checkout_id, warehouse, fulfillment_type,
+               payment_attempts, payment_deadline, payment_failure_reason,
+               subtotal, tax_total, tax_lines, shipping_address, version
+        FROM $schema.orders
+        WHERE ` + where + ` AND ` + after + `
+        ORDER BY ` + orderSortClauses[sort] + fmt.Sprintf(`
+        LIMIT $%d
+    `, len(args))
+
+    query = or.conn.Qualify(query)
+
+    rows, err := or.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to get orders page: %w", err)
+    }
+    defer rows.Close()
+
+    orders, err := scanOrders(rows)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return orders, total, nil
+}
+
+// GetOrderStatusCounts returns per-status counts of a user's orders matching filter.
+// The status filter itself is ignored so clients can render every facet.
+func (or *OrderRepository) GetOrderStatusCounts(ctx context.Context, filter models.OrderFilter) (map[string]int, error) {
+    where, args := buildOrderFilter(filter, false)
+
+    query := or.conn.Qualify(`
+        SELECT status, COUNT(*)
+        FROM $schema.orders
+        WHERE `+where+`
+        GROUP BY status
+    `)
+
+    rows, err := or.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get order status counts: %w", err)
+    }
+    defer rows.Close()
+
+    counts := make(map[string]int)
+    for rows.Next() {
+        var status string
+        var count int
+        if err := rows.Scan(&status, &count); err != nil {
+            return nil, fmt.Errorf("failed to scan status count: %w", err)
+        }
+        counts[status] = count
+    }
+
+    return counts, rows.Err()
+}
+
+// buildOrderFilter builds the WHERE clause and args for an order filter
+func buildOrderFilter(filter models.OrderFilter, includeStatus bool) (string, []interface{}) {
+    conditions := []string{"user_id = $1"}
+    args := []interface{}{filter.UserID}
+
+    add := func(condition string, arg interface{}) {
+        args = append(args, arg)
+        conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+    }
+
+    if includeStatus && len(filter.Statuses) > 0 {
+        placeholders := make([]string, len(filter.Statuses))
+        for i, status := range filter.Statuses {
+            args = append(args, status)
+            placeholders[i] = fmt.Sprintf("$%d", len(args))
+        }
+        conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+    }
+    if filter.From != nil {
+        add("created_at >= $%d", *filter.From)
+    }
+    if filter.To != nil {
+        add("created_at < $%d", *filter.To)
+    }
+    if filter.MinTotal != nil {
+        add("total >= $%d", *filter.MinTotal)
+    }
+    if filter.MaxTotal != nil {
+        add("total <= $%d", *filter.MaxTotal)
+    }
+
+    return strings.Join(conditions, " AND "), args
+}
+
+// scanOrders scans order rows (column order of the order list queries)
+func scanOrders(rows *sql.Rows) ([]*models.Order, error) {
     var orders []*models.Order
     for rows.Next() {
         order := &models.Order{}
@@ -150,6 +462,19 @@ func (or *OrderRepository) GetOrdersByUserID(ctx context.Context, userID string)
             &order.ShippedAt,
             &order.DeliveredAt,
             &order.CancelledAt,
+            &order.TrackingNumber,
+            &order.Carrier,
+            &order.CheckoutID,
+            &order.Warehouse,
+            &order.FulfillmentType,
+            &order.PaymentAttempts,
+            &order.PaymentDeadline,
+            &order.PaymentFailureReason,
+            &order.Subtotal,
+            &order.TaxTotal,
+            &order.TaxLines,
+            &order.ShippingAddress,
+            &order.Version,
         )
         if err != nil {
             return nil, fmt.Errorf("failed to scan order: %w", err)
@@ -157,47 +482,52 @@ func (or *OrderRepository) GetOrdersByUserID(ctx context.Context, userID string)
         orders = append(orders, order)
     }
 
-    return orders, nil
+    return orders, rows.Err()
 }
 
-// AddOrderItem adds an item to an order
-func (or *OrderRepository) AddOrderItem(ctx context.Context, item *models.OrderItem) error {
+// UpdateOrderStatus updates order status
+// Why: placed_at is kept from the first time the order was placed; it starts the auto-confirmation window
+func (or *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status string) error {
     query := `
-        INSERT INTO $schema.order_items (order_id, product_id, quantity, price, created_at)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id, order_id, product_id, quantity, price, created_at
+        UPDATE $schema.orders
+        SET status = $1, updated_at = $2, version = version + 1,
+            placed_at = CASE WHEN $1 = 'placed' THEN COALESCE(placed_at, $2) ELSE placed_at END
+        WHERE id = $3
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = or.conn.Qualify(query)
 
-    err := or.conn.QueryRowContext(ctx, query,
-        item.OrderID,
-        item.ProductID,
-        item.Quantity,
-        item.Price,
-        item.CreatedAt,
-    ).Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt)
+    result, err := or.conn.ExecContext(ctx, query, status, time.Now().UTC(), orderID)
+    if err != nil {
+        return fmt.Errorf("failed to update order status: %w", err)
+    }
 
+    rowsAffected, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("failed to add order item: %w", err)
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected == 0 {
+        return ErrOrderNotFound
     }
 
     return nil
 }
 
-// UpdateOrderStatus updates order status
-func (or *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status string) error {
+// CancelOrder cancels an order still at version. It returns ErrOrderVersionConflict when the
+// order has changed since it was read, or was delivered.
+func (or *OrderRepository) CancelOrder(ctx context.Context, orderID int64, version int) error {
     query := `
         UPDATE $schema.orders
-        SET status = $1, updated_at = $2
-        WHERE id = $3
+        SET status = 'cancelled', cancelled_at = $1, updated_at = $2, version = version + 1
+        WHERE id = $3 AND version = $4 AND status != 'delivered'
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = or.conn.Qualify(query)
 
-    result, err := or.conn.ExecContext(ctx, query, status, time.Now().UTC(), orderID)
+    result, err := or.conn.ExecContext(ctx, query, time.Now().UTC(), time.Now().UTC(), orderID, version)
     if err != nil {
-        return fmt.Errorf("failed to update order status: %w", err)
+        return fmt.Errorf("failed to cancel order: %w", err)
     }
 
     rowsAffected, err := result.RowsAffected()
@@ -206,25 +536,25 @@ func (or *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64,
     }
 
     if rowsAffected == 0 {
-        return fmt.Errorf("order not found")
+        return ErrOrderVersionConflict
     }
 
     return nil
 }
 
-// CancelOrder cancels an order
-func (or *OrderRepository) CancelOrder(ctx context.Context, orderID int64) error {
+// MarkOrderShipped advances a placed/confirmed order to shipped with tracking data
+func (or *OrderRepository) MarkOrderShipped(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error {
     query := `
         UPDATE $schema.orders
-        SET status = 'cancelled', cancelled_at = $1, updated_at = $2
-        WHERE id = $3 AND status != 'delivered'
+        SET status = 'shipped', shipped_at = $1, tracking_number = $2, carrier = $3, updated_at = $4, version = version + 1
+        WHERE id = $5 AND status IN ('placed', 'confirmed', 'shipped')
     `
 
-    query = replaceSchema(query, or.conn.Schema)
+    query = or.conn.Qualify(query)
 
-    result, err := or.conn.ExecContext(ctx, query, time.Now().UTC(), time.Now().UTC(), orderID)
+    result, err := or.conn.ExecContext(ctx, query, shippedAt, trackingNumber, carrier, time.Now().UTC(), orderID)
     if err != nil {
-        return fmt.Errorf("failed to cancel order: %w", err)
+        return fmt.Errorf("failed to mark order shipped: %w", err)
     }
 
     rowsAffected, err := result.RowsAffected()
@@ -233,18 +563,35 @@ func (or *OrderRepository) CancelOrder(ctx context.Context, orderID int64) error
     }
 
     if rowsAffected == 0 {
-        return fmt.Errorf("order not found or already delivered")
+        return fmt.Errorf("order not found or not shippable")
     }
 
     return nil
 }
 
-// Helper function
-func replaceSchema(query, schema string) string {
-    for i := 0; i < len(query)-len("$schema"); i++ {
-        if query[i:i+len("$schema")] == "$schema" {
-            query = query[:i] + schema + query[i+len("$schema"):]
-        }
+// MarkOrderDelivered advances a shipped order to delivered
+func (or *OrderRepository) MarkOrderDelivered(ctx context.Context, orderID int64, deliveredAt time.Time) error {
+    query := `
+        UPDATE $schema.orders
+        SET status = 'delivered', delivered_at = $1, updated_at = $2, version = version + 1
+        WHERE id = $3 AND status IN ('shipped', 'delivered')
+    `
+
+    query = or.conn.Qualify(query)
+
+    result, err := or.conn.ExecContext(ctx, query, deliveredAt, time.Now().UTC(), orderID)
+    if err != nil {
+        return fmt.Errorf("failed to mark order delivered: %w", err)
     }
-    return query
-}
\ No newline at end of file
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected == 0 {
+        return fmt.Errorf("order not found or not shipped")
+    }
+
+    return nil
+}
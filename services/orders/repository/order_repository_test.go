@@ -0,0 +1,93 @@
+package repository
+
+import (
+    "context"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/fixtures"
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+func TestGetOrderReadsItems(t *testing.T) {
+    database := fixtures.Open(t)
+    loaded := database.Load(t, fixtures.Set{
+        Users:    []fixtures.User{{Key: "alice"}},
+        Products: []fixtures.Product{{Key: "laptop", Price: 1299.99}, {Key: "mouse", Price: 29.99}},
+        Orders: []fixtures.Order{{
+            Key:   "first",
+            User:  "alice",
+            Items: []fixtures.Item{{Product: "laptop"}, {Product: "mouse", Quantity: 2}},
+        }},
+    })
+    repo := NewOrderRepository(database.Connect(t, "orders"))
+
+    order, err := repo.GetOrder(context.Background(), loaded.Orders["first"])
+    if err != nil {
+        t.Fatalf("GetOrder: %v", err)
+    }
+    if order.UserID != loaded.Users["alice"] || order.Total != 1359.97 || order.Status != "placed" {
+        t.Errorf("order = %+v", order)
+    }
+    quantities := map[int64]int{}
+    for _, item := range order.Items {
+        quantities[item.ProductID] = item.Quantity
+    }
+    if len(order.Items) != 2 || quantities[loaded.Products["laptop"]] != 1 || quantities[loaded.Products["mouse"]] != 2 {
+        t.Errorf("items = %+v", order.Items)
+    }
+}
+
+func TestGetOrdersByUserIDOnlyReturnsTheirOrders(t *testing.T) {
+    database := fixtures.Open(t)
+    loaded := database.Load(t, fixtures.Set{
+        Users:    []fixtures.User{{Key: "alice"}, {Key: "bob"}},
+        Products: []fixtures.Product{{Key: "mouse", Price: 29.99}},
+        Orders: []fixtures.Order{
+            {Key: "alice-1", User: "alice", Items: []fixtures.Item{{Product: "mouse"}}},
+            {Key: "alice-2", User: "alice", Status: "cancelled", Items: []fixtures.Item{{Product: "mouse"}}},
+            {Key: "bob-1", User: "bob", Items: []fixtures.Item{{Product: "mouse"}}},
+        },
+    })
+    repo := NewOrderRepository(database.Connect(t, "orders"))
+
+    orders, err := repo.GetOrdersByUserID(context.Background(), loaded.Users["alice"])
+    if err != nil {
+        t.Fatalf("GetOrdersByUserID: %v", err)
+    }
+    if len(orders) != 2 {
+        t.Fatalf("got %d orders, want alice's 2", len(orders))
+    }
+    for _, order := range orders {
+        if order.UserID != loaded.Users["alice"] {
+            t.Errorf("order %d belongs to %s", order.ID, order.UserID)
+        }
+    }
+}
+
+func TestCreateOrderKeepsTaxLines(t *testing.T) {
+    database := fixtures.Open(t)
+    loaded := database.Load(t, fixtures.Set{Users: []fixtures.User{{Key: "alice"}}})
+    repo := NewOrderRepository(database.Connect(t, "orders"))
+    ctx := context.Background()
+
+    order := models.NewOrder(loaded.Users["alice"], "cart-1", 4242, 100.00, "saga-1")
+    order.ApplyTax([]sharedmodels.TaxLine{
+        {Name: "state", Region: "main", Rate: 6.25, Amount: 6.25},
+        {Name: "county", Region: "main", Rate: 1.5, Amount: 1.50},
+    }, 7.75)
+    if err := repo.CreateOrder(ctx, order); err != nil {
+        t.Fatalf("CreateOrder: %v", err)
+    }
+
+    saved, err := repo.GetOrder(ctx, order.ID)
+    if err != nil {
+        t.Fatalf("GetOrder: %v", err)
+    }
+    if saved.Subtotal == nil || *saved.Subtotal != 100 || saved.TaxTotal != 7.75 || saved.Total != 107.75 {
+        t.Errorf("order = %+v, want 100.00 + 7.75 tax", saved)
+    }
+    if len(saved.TaxLines) != 2 || saved.TaxLines[0].Name != "state" || saved.TaxLines[1].Amount != 1.50 {
+        t.Errorf("tax lines = %+v", saved.TaxLines)
+    }
+}
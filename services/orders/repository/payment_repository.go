@@ -0,0 +1,229 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrPaymentNotPending is returned when retrying the payment of an order that isn't payment_pending
+    ErrPaymentNotPending = errors.New("order is not waiting for a payment retry")
+    // ErrPaymentWindowClosed is returned when retrying after the order's payment deadline
+    ErrPaymentWindowClosed = errors.New("payment retry window has closed")
+    // ErrPaymentRetryInProgress is returned when the previous retry hasn't got a payment result yet
+    ErrPaymentRetryInProgress = errors.New("a payment retry is already in progress")
+)
+
+// PaymentRepository handles the payment retry window of orders whose payment failed
+type PaymentRepository struct {
+    conn *db.Connection
+}
+
+// NewPaymentRepository creates new payment repository
+func NewPaymentRepository(conn *db.Connection) *PaymentRepository {
+    return &PaymentRepository{conn: conn}
+}
+
+// MarkPaymentPending moves a placed order whose payment attempt failed to payment_pending and
+// returns its payment deadline. It returns false when the order is no longer placed or the
+// failure is for an older attempt than the latest retry (attempt 0 matches any).
+// Why: the deadline is set by the first failure only, so failed retries don't extend the window
+func (pr *PaymentRepository) MarkPaymentPending(ctx context.Context, orderID int64, attempt int, reason string, deadline time.Time) (time.Time, bool, error) {
+    query := `
+        UPDATE $schema.orders
+        SET status = 'payment_pending',
+            payment_failure_reason = $2,
+            payment_deadline = COALESCE(payment_deadline, $3),
+            payment_retry_requested_at = NULL,
+            updated_at = $4,
+            version = version + 1
+        WHERE id = $1
+          AND status IN ('placed', 'payment_pending')
+          AND ($5 = 0 OR payment_attempts = $5)
+        RETURNING payment_deadline
+    `
+    query = pr.conn.Qualify(query)
+
+    var marked time.Time
+    err := pr.conn.QueryRowContext(ctx, query, orderID, reason, deadline, time.Now().UTC(), attempt).Scan(&marked)
+    if err == sql.ErrNoRows {
+        return time.Time{}, false, nil
+    }
+    if err != nil {
+        return time.Time{}, false, fmt.Errorf("failed to mark payment pending: %w", err)
+    }
+
+    return marked, true, nil
+}
+
+// MarkPaymentProcessed moves a payment_pending order back to placed once a retry succeeded.
+// It returns false when the order wasn't payment_pending.
+func (pr *PaymentRepository) MarkPaymentProcessed(ctx context.Context, orderID int64) (bool, error) {
+    query := `
+        UPDATE $schema.orders
+        SET status = 'placed',
+            payment_deadline = NULL,
+            payment_failure_reason = NULL,
+            payment_retry_requested_at = NULL,
+            updated_at = $2,
+            version = version + 1
+        WHERE id = $1 AND status = 'payment_pending'
+    `
+    query = pr.conn.Qualify(query)
+
+    result, err := pr.conn.ExecContext(ctx, query, orderID, time.Now().UTC())
+    if err != nil {
+        return false, fmt.Errorf("failed to mark payment processed: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    return rowsAffected > 0, nil
+}
+
+// RequestRetry records a payment retry of a payment_pending order before its deadline.
+// Why: only one retry may wait for a payment result at a time, so a double click can't charge twice
+func (pr *PaymentRepository) RequestRetry(ctx context.Context, orderID int64) (*models.PaymentRetry, error) {
+    query := `
+        UPDATE $schema.orders
+        SET payment_attempts = payment_attempts + 1,
+            payment_retry_requested_at = $2,
+            updated_at = $2,
+            version = version + 1
+        WHERE id = $1
+          AND status = 'payment_pending'
+          AND payment_deadline > $2
+          AND payment_retry_requested_at IS NULL
+        RETURNING id, user_id, total, saga_correlation_id, payment_attempts, payment_deadline
+    `
+    query = pr.conn.Qualify(query)
+
+    now := time.Now().UTC()
+    retry := &models.PaymentRetry{}
+    err := pr.conn.QueryRowContext(ctx, query, orderID, now).Scan(
+        &retry.OrderID,
+        &retry.UserID,
+        &retry.Total,
+        &retry.SagaCorrelationID,
+        &retry.Attempt,
+        &retry.Deadline,
+    )
+    if err == sql.ErrNoRows {
+        return nil, pr.explainMissedRetry(ctx, orderID, now)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to request payment retry: %w", err)
+    }
+
+    return retry, nil
+}
+
+// explainMissedRetry works out why RequestRetry updated nothing
+func (pr *PaymentRepository) explainMissedRetry(ctx context.Context, orderID int64, now time.Time) error {
+    query := pr.conn.Qualify(`SELECT status, payment_deadline, payment_retry_requested_at FROM $schema.orders WHERE id = $1`)
+
+    var status string
+    var deadline, requestedAt *time.Time
+    err := pr.conn.QueryRowContext(ctx, query, orderID).Scan(&status, &deadline, &requestedAt)
+    if err == sql.ErrNoRows {
+        return ErrOrderNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get order status: %w", err)
+    }
+
+    switch {
+    case status != "payment_pending":
+        return ErrPaymentNotPending
+    case deadline == nil || !deadline.After(now):
+        return ErrPaymentWindowClosed
+    default:
+        return ErrPaymentRetryInProgress
+    }
+}
+
+// RevertRetry undoes RequestRetry when the retry couldn't be handed to the payment service
+func (pr *PaymentRepository) RevertRetry(ctx context.Context, orderID int64, attempt int) error {
+    query := `
+        UPDATE $schema.orders
+        SET payment_attempts = payment_attempts - 1, payment_retry_requested_at = NULL, updated_at = $3, version = version + 1
+        WHERE id = $1 AND status = 'payment_pending' AND payment_attempts = $2
+    `
+    query = pr.conn.Qualify(query)
+
+    if _, err := pr.conn.ExecContext(ctx, query, orderID, attempt, time.Now().UTC()); err != nil {
+        return fmt.Errorf("failed to revert payment retry: %w", err)
+    }
+    return nil
+}
+
+// ListPaymentExpired returns payment_pending orders whose deadline passed before now, oldest first
+func (pr *PaymentRepository) ListPaymentExpired(ctx context.Context, now time.Time, limit int) ([]*models.PaymentPendingOrder, error) {
+    query := `
+        SELECT id, saga_correlation_id, COALESCE(payment_failure_reason, ''), payment_deadline
+        FROM $schema.orders
+        WHERE status = 'payment_pending' AND payment_deadline <= $1
+        ORDER BY payment_deadline ASC
+        LIMIT $2
+    `
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, now, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list expired payment_pending orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders := []*models.PaymentPendingOrder{}
+    for rows.Next() {
+        order := &models.PaymentPendingOrder{}
+        if err := rows.Scan(&order.OrderID, &order.SagaCorrelationID, &order.FailureReason, &order.Deadline); err != nil {
+            return nil, fmt.Errorf("failed to scan payment_pending order: %w", err)
+        }
+        orders = append(orders, order)
+    }
+
+    return orders, rows.Err()
+}
+
+// FailIfPaymentExpired moves a payment_pending order past its deadline to failed.
+// It returns false when the order was paid, cancelled or failed in the meantime.
+func (pr *PaymentRepository) FailIfPaymentExpired(ctx context.Context, orderID int64, now time.Time) (bool, error) {
+    query := `
+        UPDATE $schema.orders
+        SET status = 'failed', updated_at = $2, version = version + 1
+        WHERE id = $1 AND status = 'payment_pending' AND payment_deadline <= $2
+    `
+    query = pr.conn.Qualify(query)
+
+    result, err := pr.conn.ExecContext(ctx, query, orderID, now)
+    if err != nil {
+        return false, fmt.Errorf("failed to fail order: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    return rowsAffected > 0, nil
+}
+
+// RevertPaymentFailure moves an expired order back to payment_pending when its event couldn't be published
+func (pr *PaymentRepository) RevertPaymentFailure(ctx context.Context, orderID int64) error {
+    query := pr.conn.Qualify(`UPDATE $schema.orders SET status = 'payment_pending', updated_at = $2, version = version + 1 WHERE id = $1 AND status = 'failed'`)
+
+    if _, err := pr.conn.ExecContext(ctx, query, orderID, time.Now().UTC()); err != nil {
+        return fmt.Errorf("failed to revert payment failure: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+// OrderRepositoryInterface defines the contract for order repository operations
+type OrderRepositoryInterface interface {
+    CreateOrder(ctx context.Context, order *models.Order) error
+    GetOrder(ctx context.Context, orderID int64) (*models.Order, error)
+    GetOrdersByUserID(ctx context.Context, userID string) ([]*models.Order, error)
+    GetOrdersByCorrelationID(ctx context.Context, correlationID string) ([]*models.Order, error)
+    GetOrdersByUserIDFiltered(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error)
+    GetOrdersPageByUserID(ctx context.Context, filter models.OrderFilter, page pagination.Page) ([]*models.Order, int, error)
+    GetOrderStatusCounts(ctx context.Context, filter models.OrderFilter) (map[string]int, error)
+    UpdateOrderStatus(ctx context.Context, orderID int64, status string) error
+    CancelOrder(ctx context.Context, orderID int64, version int) error
+    MarkOrderShipped(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error
+    MarkOrderDelivered(ctx context.Context, orderID int64, deliveredAt time.Time) error
+}
+
+// SagaStateRepositoryInterface defines the contract for checkout saga state operations
+type SagaStateRepositoryInterface interface {
+    CreateSagaState(ctx context.Context, saga *models.SagaState) error
+    GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error)
+    UpdateSagaStatus(ctx context.Context, correlationID, status string) error
+    UpdateSagaOrderID(ctx context.Context, correlationID string, orderID int64) error
+    AddCompensation(ctx context.Context, correlationID, compensation string) error
+    UpdateSagaPayload(ctx context.Context, correlationID string, payload map[string]interface{}) error
+    RecordCheckpoint(ctx context.Context, correlationID, step string) error
+    MarkSagaFailed(ctx context.Context, correlationID, reason string) error
+    BeginResume(ctx context.Context, correlationID, status string) error
+    StartReservationTracking(ctx context.Context, correlationID string, progress *models.ReservationProgress) error
+    RecordReservedStock(ctx context.Context, correlationID string, orderID int64, reserved []models.ReservedLine) (*models.ReservationProgress, error)
+    ListReservationTimedOut(ctx context.Context, now time.Time, limit int) ([]string, error)
+    FailIfReservationTimedOut(ctx context.Context, correlationID string, now time.Time, reason string) (*models.ReservationProgress, error)
+    RevertReservationTimeout(ctx context.Context, correlationID string) error
+    GetSagaTimeline(ctx context.Context, correlationID string) (*models.SagaTimeline, error)
+    ListTransitions(ctx context.Context, correlationID string) ([]models.SagaTransition, error)
+}
+
+// CompensationLogRepositoryInterface defines the contract for compensation log operations
+type CompensationLogRepositoryInterface interface {
+    CreateCompensationLog(ctx context.Context, log *models.CompensationLog) error
+    GetCompensationLogsByOrderID(ctx context.Context, orderID int64) ([]*models.CompensationLog, error)
+    GetCompensationLogsByCorrelationID(ctx context.Context, correlationID string) ([]*models.CompensationLog, error)
+    UpdateCompensationStatus(ctx context.Context, logID, status string) error
+}
+
+// InventoryReservationRepositoryInterface defines the contract for the orders service's
+// record of stock reservations
+type InventoryReservationRepositoryInterface interface {
+    CreateReservation(ctx context.Context, res *models.InventoryReservation) error
+    GetReservationsByOrderID(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error)
+    UpdateReservationStatus(ctx context.Context, reservationID, status string) error
+    ReleaseReservation(ctx context.Context, reservationID string) error
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
     "context"
+    "database/sql"
     "encoding/json"
+    "errors"
     "fmt"
     "log"
     "time"
@@ -12,6 +14,9 @@ import (
     "github.com/sanketh-sg/prost/shared/db"
 )
 
+// ErrSagaFailed is returned when reservations arrive for a saga that already failed
+var ErrSagaFailed = errors.New("saga already failed")
+
 // SagaStateRepository handles saga state database operations
 type SagaStateRepository struct {
     conn *db.Connection
@@ -38,13 +43,19 @@ func (sr *SagaStateRepository) CreateSagaState(ctx context.Context, saga *models
         RETURNING id, correlation_id, saga_type, status, order_id, payload, compensation_log, created_at, updated_at, expires_at
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     var orderID *int64
     var payloadResp []byte
     var compensationLogResp pq.StringArray
 
-    err = sr.conn.QueryRowContext(ctx, query,
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(ctx, query,
         saga.ID,
         saga.CorrelationID,
         saga.SagaType,
@@ -73,6 +84,14 @@ func (sr *SagaStateRepository) CreateSagaState(ctx context.Context, saga *models
         return fmt.Errorf("failed to create saga state: %w", err)
     }
 
+    if err := sr.recordTransition(ctx, tx, saga.CorrelationID, nil, saga.Status, nil, saga.CreatedAt); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit saga state: %w", err)
+    }
+
     saga.OrderID = orderID
     return nil
 }
@@ -80,12 +99,13 @@ func (sr *SagaStateRepository) CreateSagaState(ctx context.Context, saga *models
 // GetSagaState retrieves saga state by correlation ID
 func (sr *SagaStateRepository) GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error) {
     query := `
-        SELECT id, correlation_id, saga_type, status, order_id, payload, compensation_log, created_at, updated_at, expires_at
+        SELECT id, correlation_id, saga_type, status, order_id, payload, compensation_log,
+               last_completed_step, failure_reason, retry_count, created_at, updated_at, expires_at
         FROM $schema.saga_states
         WHERE correlation_id = $1
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     saga := &models.SagaState{}
     var payloadJSON []byte
@@ -99,11 +119,17 @@ func (sr *SagaStateRepository) GetSagaState(ctx context.Context, correlationID s
         &saga.OrderID,
         &payloadJSON,
         &compensationLog,
+        &saga.LastCompletedStep,
+        &saga.FailureReason,
+        &saga.RetryCount,
         &saga.CreatedAt,
         &saga.UpdatedAt,
         &saga.ExpiresAt,
     )
 
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("%w: %s", ErrSagaNotFound, correlationID)
+    }
     if err != nil {
         return nil, fmt.Errorf("failed to get saga state: %w", err)
     }
@@ -115,36 +141,66 @@ func (sr *SagaStateRepository) GetSagaState(ctx context.Context, correlationID s
 
     saga.CompensationLog = []string(compensationLog)
 
+    // Why: saga_states has no user or cart column; the checkout's are kept in the payload
+    saga.UserID, _ = saga.Payload["user_id"].(string)
+    saga.CartID, _ = saga.Payload["cart_id"].(string)
+
     return saga, nil
 }
 
-// UpdateSagaStatus updates saga status
+// UpdateSagaStatus updates saga status and records the transition
 func (sr *SagaStateRepository) UpdateSagaStatus(ctx context.Context, correlationID, status string) error {
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    from, err := sr.lockStatus(ctx, tx, correlationID)
+    if err != nil {
+        return err
+    }
+
     query := `
         UPDATE $schema.saga_states
         SET status = $1, updated_at = $2
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
-    result, err := sr.conn.ExecContext(ctx, query, status, time.Now().UTC(), correlationID)
-    if err != nil {
+    now := time.Now().UTC()
+    if _, err := tx.ExecContext(ctx, query, status, now, correlationID); err != nil {
         return fmt.Errorf("failed to update saga status: %w", err)
     }
 
-    rowsAffected, err := result.RowsAffected()
-    if err != nil {
-        return fmt.Errorf("failed to get rows affected: %w", err)
+    if err := sr.recordTransition(ctx, tx, correlationID, &from, status, nil, now); err != nil {
+        return err
     }
 
-    if rowsAffected == 0 {
-        return fmt.Errorf("saga state not found")
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit saga status: %w", err)
     }
 
     return nil
 }
 
+// lockStatus locks the saga row in tx and returns its status
+func (sr *SagaStateRepository) lockStatus(ctx context.Context, tx *sql.Tx, correlationID string) (string, error) {
+    query := sr.conn.Qualify(`SELECT status FROM $schema.saga_states WHERE correlation_id = $1 FOR UPDATE`)
+
+    var status string
+    err := tx.QueryRowContext(ctx, query, correlationID).Scan(&status)
+    if err == sql.ErrNoRows {
+        return "", fmt.Errorf("saga state not found")
+    }
+    if err != nil {
+        return "", fmt.Errorf("failed to lock saga state: %w", err)
+    }
+
+    return status, nil
+}
+
 // UpdateSagaOrderID updates order ID in saga
 func (sr *SagaStateRepository) UpdateSagaOrderID(ctx context.Context, correlationID string, orderID int64) error {
     query := `
@@ -153,7 +209,7 @@ func (sr *SagaStateRepository) UpdateSagaOrderID(ctx context.Context, correlatio
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     _, err := sr.conn.ExecContext(ctx, query, orderID, time.Now().UTC(), correlationID)
     if err != nil {
@@ -171,7 +227,7 @@ func (sr *SagaStateRepository) AddCompensation(ctx context.Context, correlationI
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     _, err := sr.conn.ExecContext(ctx, query, compensation, time.Now().UTC(), correlationID)
     if err != nil {
@@ -194,7 +250,7 @@ func (sr *SagaStateRepository) UpdateSagaPayload(ctx context.Context, correlatio
         WHERE correlation_id = $3
     `
 
-    query = replaceSchema(query, sr.conn.Schema)
+    query = sr.conn.Qualify(query)
 
     _, err = sr.conn.ExecContext(ctx, query, payloadJSON, time.Now().UTC(), correlationID)
     if err != nil {
@@ -202,4 +258,314 @@ func (sr *SagaStateRepository) UpdateSagaPayload(ctx context.Context, correlatio
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+// RecordCheckpoint stores the last saga step that completed successfully
+func (sr *SagaStateRepository) RecordCheckpoint(ctx context.Context, correlationID, step string) error {
+    query := `
+        UPDATE $schema.saga_states
+        SET last_completed_step = $1, updated_at = $2
+        WHERE correlation_id = $3
+    `
+
+    query = sr.conn.Qualify(query)
+
+    _, err := sr.conn.ExecContext(ctx, query, step, time.Now().UTC(), correlationID)
+    if err != nil {
+        return fmt.Errorf("failed to record saga checkpoint: %w", err)
+    }
+
+    return nil
+}
+
+// MarkSagaFailed sets status failed and records why
+func (sr *SagaStateRepository) MarkSagaFailed(ctx context.Context, correlationID, reason string) error {
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    from, err := sr.lockStatus(ctx, tx, correlationID)
+    if err != nil {
+        return err
+    }
+
+    query := `
+        UPDATE $schema.saga_states
+        SET status = 'failed', failure_reason = $1, updated_at = $2
+        WHERE correlation_id = $3
+    `
+
+    query = sr.conn.Qualify(query)
+
+    now := time.Now().UTC()
+    if _, err := tx.ExecContext(ctx, query, reason, now, correlationID); err != nil {
+        return fmt.Errorf("failed to mark saga failed: %w", err)
+    }
+
+    if err := sr.recordTransition(ctx, tx, correlationID, &from, "failed", &reason, now); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit saga failure: %w", err)
+    }
+
+    return nil
+}
+
+// BeginResume moves a failed saga back to the given status, clears the failure and bumps retry_count.
+// Only succeeds while the saga is still failed, so concurrent resumes can't both win.
+func (sr *SagaStateRepository) BeginResume(ctx context.Context, correlationID, status string) error {
+    query := `
+        UPDATE $schema.saga_states
+        SET status = $1, failure_reason = NULL, retry_count = retry_count + 1, updated_at = $2
+        WHERE correlation_id = $3 AND status = 'failed'
+    `
+
+    query = sr.conn.Qualify(query)
+
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    now := time.Now().UTC()
+    result, err := tx.ExecContext(ctx, query, status, now, correlationID)
+    if err != nil {
+        return fmt.Errorf("failed to resume saga: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected == 0 {
+        return fmt.Errorf("saga not found or no longer failed")
+    }
+
+    from := "failed"
+    if err := sr.recordTransition(ctx, tx, correlationID, &from, status, nil, now); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit saga resume: %w", err)
+    }
+
+    return nil
+}
+
+// StartReservationTracking stores what the saga expects products to reserve, replacing the
+// progress of an earlier attempt
+func (sr *SagaStateRepository) StartReservationTracking(ctx context.Context, correlationID string, progress *models.ReservationProgress) error {
+    progressJSON, err := json.Marshal(progress)
+    if err != nil {
+        return fmt.Errorf("failed to marshal reservation progress: %w", err)
+    }
+
+    query := `
+        UPDATE $schema.saga_states
+        SET payload = jsonb_set(payload, '{reservations}', $1::jsonb), updated_at = $2
+        WHERE correlation_id = $3
+    `
+
+    query = sr.conn.Qualify(query)
+
+    if _, err := sr.conn.ExecContext(ctx, query, progressJSON, time.Now().UTC(), correlationID); err != nil {
+        return fmt.Errorf("failed to start reservation tracking: %w", err)
+    }
+
+    return nil
+}
+
+// RecordReservedStock adds the reservations of one StockReserved event to the saga's progress
+// and returns it. It returns nil when the saga doesn't track reservations (started before it
+// did), in which case the order counts as reserved. Once every line is reserved the saga moves
+// to inventory_reserved, out of reach of the reservation timeout.
+// Why: the saga row is locked so the StockReserved events of a split checkout, handled at
+// once, can't overwrite each other's counts, and a timeout can't fail the saga while it's placed
+func (sr *SagaStateRepository) RecordReservedStock(ctx context.Context, correlationID string, orderID int64, reserved []models.ReservedLine) (*models.ReservationProgress, error) {
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    lockQuery := sr.conn.Qualify(`SELECT status, payload->'reservations' FROM $schema.saga_states WHERE correlation_id = $1 FOR UPDATE`)
+
+    var status string
+    var progressJSON []byte
+    if err := tx.QueryRowContext(ctx, lockQuery, correlationID).Scan(&status, &progressJSON); err != nil {
+        return nil, fmt.Errorf("failed to lock saga state: %w", err)
+    }
+    if status == "failed" {
+        return nil, ErrSagaFailed
+    }
+    if progressJSON == nil {
+        return nil, nil
+    }
+
+    var progress models.ReservationProgress
+    if err := json.Unmarshal(progressJSON, &progress); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal reservation progress: %w", err)
+    }
+    if progress.Record(orderID, reserved) == 0 {
+        return &progress, nil
+    }
+
+    if progressJSON, err = json.Marshal(&progress); err != nil {
+        return nil, fmt.Errorf("failed to marshal reservation progress: %w", err)
+    }
+
+    from := status
+    if progress.Complete() {
+        status = "inventory_reserved"
+    }
+
+    updateQuery := sr.conn.Qualify(`
+        UPDATE $schema.saga_states
+        SET payload = jsonb_set(payload, '{reservations}', $1::jsonb), status = $2, updated_at = $3
+        WHERE correlation_id = $4
+    `)
+
+    now := time.Now().UTC()
+    if _, err := tx.ExecContext(ctx, updateQuery, progressJSON, status, now, correlationID); err != nil {
+        return nil, fmt.Errorf("failed to record reserved stock: %w", err)
+    }
+
+    if err := sr.recordTransition(ctx, tx, correlationID, &from, status, nil, now); err != nil {
+        return nil, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit reserved stock: %w", err)
+    }
+
+    return &progress, nil
+}
+
+// ListReservationTimedOut returns the correlation IDs of sagas still waiting for stock past
+// their reservation deadline
+func (sr *SagaStateRepository) ListReservationTimedOut(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    query := `
+        SELECT correlation_id
+        FROM $schema.saga_states
+        WHERE status = 'checking_inventory'
+          AND payload ? 'reservations'
+          AND (payload->'reservations'->>'deadline')::timestamptz <= $1
+        ORDER BY updated_at
+        LIMIT $2
+    `
+
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, now, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list timed out reservations: %w", err)
+    }
+    defer rows.Close()
+
+    var correlationIDs []string
+    for rows.Next() {
+        var correlationID string
+        if err := rows.Scan(&correlationID); err != nil {
+            return nil, fmt.Errorf("failed to scan saga state: %w", err)
+        }
+        correlationIDs = append(correlationIDs, correlationID)
+    }
+
+    return correlationIDs, rows.Err()
+}
+
+// FailIfReservationTimedOut marks the saga failed if it is still waiting for stock past its
+// deadline, and returns the progress it timed out with. It returns nil when the saga moved on,
+// or another instance failed it first.
+func (sr *SagaStateRepository) FailIfReservationTimedOut(ctx context.Context, correlationID string, now time.Time, reason string) (*models.ReservationProgress, error) {
+    query := `
+        UPDATE $schema.saga_states
+        SET status = 'failed', failure_reason = $1, updated_at = $2
+        WHERE correlation_id = $3
+          AND status = 'checking_inventory'
+          AND (payload->'reservations'->>'deadline')::timestamptz <= $2
+        RETURNING payload->'reservations'
+    `
+
+    query = sr.conn.Qualify(query)
+
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var progressJSON []byte
+    err = tx.QueryRowContext(ctx, query, reason, now, correlationID).Scan(&progressJSON)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to fail timed out saga: %w", err)
+    }
+
+    from := "checking_inventory"
+    if err := sr.recordTransition(ctx, tx, correlationID, &from, "failed", &reason, now); err != nil {
+        return nil, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit saga timeout: %w", err)
+    }
+
+    var progress models.ReservationProgress
+    if err := json.Unmarshal(progressJSON, &progress); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal reservation progress: %w", err)
+    }
+
+    return &progress, nil
+}
+
+// RevertReservationTimeout puts a saga failed by FailIfReservationTimedOut back to waiting
+// for stock, so the next run tries again
+func (sr *SagaStateRepository) RevertReservationTimeout(ctx context.Context, correlationID string) error {
+    query := `
+        UPDATE $schema.saga_states
+        SET status = 'checking_inventory', failure_reason = NULL, updated_at = $1
+        WHERE correlation_id = $2 AND status = 'failed'
+    `
+
+    query = sr.conn.Qualify(query)
+
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    now := time.Now().UTC()
+    result, err := tx.ExecContext(ctx, query, now, correlationID)
+    if err != nil {
+        return fmt.Errorf("failed to revert reservation timeout: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected > 0 {
+        from := "failed"
+        if err := sr.recordTransition(ctx, tx, correlationID, &from, "checking_inventory", nil, now); err != nil {
+            return err
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit reservation timeout revert: %w", err)
+    }
+
+    return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+// ErrSagaNotFound is returned for a correlation ID no saga has
+var ErrSagaNotFound = errors.New("saga not found")
+
+type transitionCauseKey struct{}
+
+// WithTransitionCause attributes the saga status changes made with ctx to cause
+// Why: the status changes happen deep in the saga steps; the event being handled is known
+// where it's dispatched, so it travels with the context instead of through every step
+func WithTransitionCause(ctx context.Context, cause models.TransitionCause) context.Context {
+    return context.WithValue(ctx, transitionCauseKey{}, cause)
+}
+
+// transitionCause returns the cause set on ctx, or orders itself
+func transitionCause(ctx context.Context) models.TransitionCause {
+    if cause, ok := ctx.Value(transitionCauseKey{}).(models.TransitionCause); ok && cause.ActorService != "" {
+        return cause
+    }
+    return models.TransitionCause{ActorService: models.ActorOrders}
+}
+
+// recordTransition stores a status change of the saga in tx, attributed to the cause on ctx.
+// Nothing is stored when the status didn't change.
+func (sr *SagaStateRepository) recordTransition(ctx context.Context, tx *sql.Tx, correlationID string, from *string, to string, reason *string, at time.Time) error {
+    if from != nil && *from == to {
+        return nil
+    }
+
+    cause := transitionCause(ctx)
+
+    query := `
+        INSERT INTO $schema.saga_transitions
+        (correlation_id, from_status, to_status, event_id, event_type, actor_service, reason, created_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8)
+    `
+
+    query = sr.conn.Qualify(query)
+
+    if _, err := tx.ExecContext(ctx, query, correlationID, from, to, cause.EventID, cause.EventType, cause.ActorService, reason, at); err != nil {
+        return fmt.Errorf("failed to record saga transition: %w", err)
+    }
+
+    return nil
+}
+
+// GetSagaTimeline returns the saga's current state with its status changes
+func (sr *SagaStateRepository) GetSagaTimeline(ctx context.Context, correlationID string) (*models.SagaTimeline, error) {
+    // Correlation IDs are UUIDs; anything else can't name a saga
+    if _, err := uuid.Parse(correlationID); err != nil {
+        return nil, fmt.Errorf("%w: %s", ErrSagaNotFound, correlationID)
+    }
+
+    query := `
+        SELECT correlation_id, status, order_id, COALESCE(last_completed_step, ''), failure_reason, retry_count, created_at, updated_at
+        FROM $schema.saga_states
+        WHERE correlation_id = $1
+    `
+
+    query = sr.conn.Qualify(query)
+
+    timeline := &models.SagaTimeline{}
+    err := sr.conn.QueryRowContext(ctx, query, correlationID).Scan(
+        &timeline.CorrelationID,
+        &timeline.Status,
+        &timeline.OrderID,
+        &timeline.LastCompletedStep,
+        &timeline.FailureReason,
+        &timeline.RetryCount,
+        &timeline.CreatedAt,
+        &timeline.UpdatedAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("%w: %s", ErrSagaNotFound, correlationID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get saga state: %w", err)
+    }
+
+    timeline.Transitions, err = sr.ListTransitions(ctx, correlationID)
+    if err != nil {
+        return nil, err
+    }
+
+    return timeline, nil
+}
+
+// ListTransitions returns every status change of the saga, oldest first
+func (sr *SagaStateRepository) ListTransitions(ctx context.Context, correlationID string) ([]models.SagaTransition, error) {
+    query := `
+        SELECT id, correlation_id, from_status, to_status, event_id, event_type, actor_service, reason, created_at
+        FROM $schema.saga_transitions
+        WHERE correlation_id = $1
+        ORDER BY id
+    `
+
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, correlationID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list saga transitions: %w", err)
+    }
+    defer rows.Close()
+
+    transitions := []models.SagaTransition{}
+    for rows.Next() {
+        var transition models.SagaTransition
+        if err := rows.Scan(
+            &transition.ID,
+            &transition.CorrelationID,
+            &transition.FromStatus,
+            &transition.ToStatus,
+            &transition.EventID,
+            &transition.EventType,
+            &transition.ActorService,
+            &transition.Reason,
+            &transition.CreatedAt,
+        ); err != nil {
+            return nil, fmt.Errorf("failed to scan saga transition: %w", err)
+        }
+        transitions = append(transitions, transition)
+    }
+
+    return transitions, rows.Err()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/fixtures"
+)
+
+func TestSagaTimelineRecordsEveryStatusChange(t *testing.T) {
+    database := fixtures.Open(t)
+    repo := NewSagaStateRepository(database.Connect(t, "orders"))
+    ctx := context.Background()
+
+    correlationID := uuid.New().String()
+    if err := repo.CreateSagaState(ctx, models.NewSagaState("cart-1", "user-1", correlationID)); err != nil {
+        t.Fatalf("CreateSagaState: %v", err)
+    }
+    if err := repo.UpdateSagaStatus(ctx, correlationID, "checking_inventory"); err != nil {
+        t.Fatalf("UpdateSagaStatus: %v", err)
+    }
+    // Same status again: not a transition
+    if err := repo.UpdateSagaStatus(ctx, correlationID, "checking_inventory"); err != nil {
+        t.Fatalf("UpdateSagaStatus: %v", err)
+    }
+    failedBy := WithTransitionCause(ctx, models.TransitionCause{EventID: "evt-1", EventType: "StockReservationFailed", ActorService: "products"})
+    if err := repo.MarkSagaFailed(failedBy, correlationID, "Insufficient inventory for product 42"); err != nil {
+        t.Fatalf("MarkSagaFailed: %v", err)
+    }
+
+    timeline, err := repo.GetSagaTimeline(ctx, correlationID)
+    if err != nil {
+        t.Fatalf("GetSagaTimeline: %v", err)
+    }
+    if timeline.Status != "failed" || len(timeline.Transitions) != 3 {
+        t.Fatalf("timeline = %+v", timeline)
+    }
+
+    created, checking, failed := timeline.Transitions[0], timeline.Transitions[1], timeline.Transitions[2]
+    if created.FromStatus != nil || created.ToStatus != "pending" || created.ActorService != models.ActorOrders || created.EventID != nil {
+        t.Errorf("first transition = %+v", created)
+    }
+    if checking.FromStatus == nil || *checking.FromStatus != "pending" || checking.ToStatus != "checking_inventory" {
+        t.Errorf("second transition = %+v", checking)
+    }
+    if failed.ToStatus != "failed" || failed.ActorService != "products" ||
+        failed.EventID == nil || *failed.EventID != "evt-1" ||
+        failed.Reason == nil || *failed.Reason != "Insufficient inventory for product 42" {
+        t.Errorf("third transition = %+v", failed)
+    }
+}
+
+func TestGetSagaTimelineUnknownSaga(t *testing.T) {
+    database := fixtures.Open(t)
+    repo := NewSagaStateRepository(database.Connect(t, "orders"))
+
+    for _, correlationID := range []string{uuid.New().String(), "not-a-uuid"} {
+        if _, err := repo.GetSagaTimeline(context.Background(), correlationID); !errors.Is(err, ErrSagaNotFound) {
+            t.Errorf("GetSagaTimeline(%q) err = %v, want ErrSagaNotFound", correlationID, err)
+        }
+    }
+}
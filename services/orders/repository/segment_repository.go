@@ -0,0 +1,151 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// ErrSegmentsNotFound is returned when a user has no computed segments yet
+var ErrSegmentsNotFound = errors.New("user segments not found")
+
+// SegmentRepository handles user segment database operations
+type SegmentRepository struct {
+    conn *db.Connection
+}
+
+// NewSegmentRepository creates new segment repository
+func NewSegmentRepository(conn *db.Connection) *SegmentRepository {
+    return &SegmentRepository{conn: conn}
+}
+
+// GetUserOrderStats aggregates a user's order history
+func (sr *SegmentRepository) GetUserOrderStats(ctx context.Context, userID string) (*models.UserOrderStats, error) {
+    query := `
+        SELECT COUNT(*) FILTER (WHERE status NOT IN ('cancelled', 'failed')),
+               COUNT(*) FILTER (WHERE status = 'cancelled'),
+               COALESCE(SUM(total) FILTER (WHERE status NOT IN ('cancelled', 'failed', 'pending')), 0),
+               MAX(created_at) FILTER (WHERE status NOT IN ('cancelled', 'failed'))
+        FROM $schema.orders
+        WHERE user_id = $1
+    `
+
+    query = sr.conn.Qualify(query)
+
+    stats := &models.UserOrderStats{UserID: userID}
+    err := sr.conn.QueryRowContext(ctx, query, userID).Scan(
+        &stats.OrderCount,
+        &stats.CancelledCount,
+        &stats.LifetimeSpend,
+        &stats.LastOrderAt,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user order stats: %w", err)
+    }
+
+    return stats, nil
+}
+
+// UpsertUserSegments stores the segments computed for a user
+func (sr *SegmentRepository) UpsertUserSegments(ctx context.Context, segments *models.UserSegments) error {
+    segmentsJSON, err := json.Marshal(segments.Segments)
+    if err != nil {
+        return fmt.Errorf("failed to marshal segments: %w", err)
+    }
+
+    query := `
+        INSERT INTO $schema.user_segments (user_id, segments, order_count, lifetime_spend, last_order_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (user_id) DO UPDATE
+        SET segments = EXCLUDED.segments,
+            order_count = EXCLUDED.order_count,
+            lifetime_spend = EXCLUDED.lifetime_spend,
+            last_order_at = EXCLUDED.last_order_at,
+            updated_at = EXCLUDED.updated_at
+    `
+
+    query = sr.conn.Qualify(query)
+
+    _, err = sr.conn.ExecContext(ctx, query,
+        segments.UserID,
+        segmentsJSON,
+        segments.OrderCount,
+        segments.LifetimeSpend,
+        segments.LastOrderAt,
+        segments.UpdatedAt,
+    )
+    if err != nil {
+        return fmt.Errorf("failed to upsert user segments: %w", err)
+    }
+
+    return nil
+}
+
+// GetUserSegments retrieves the stored segments for a user
+func (sr *SegmentRepository) GetUserSegments(ctx context.Context, userID string) (*models.UserSegments, error) {
+    query := `
+        SELECT user_id, segments, order_count, lifetime_spend, last_order_at, updated_at
+        FROM $schema.user_segments
+        WHERE user_id = $1
+    `
+
+    query = sr.conn.Qualify(query)
+
+    segments := &models.UserSegments{}
+    var segmentsJSON []byte
+    err := sr.conn.QueryRowContext(ctx, query, userID).Scan(
+        &segments.UserID,
+        &segmentsJSON,
+        &segments.OrderCount,
+        &segments.LifetimeSpend,
+        &segments.LastOrderAt,
+        &segments.UpdatedAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, ErrSegmentsNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user segments: %w", err)
+    }
+
+    if err := json.Unmarshal(segmentsJSON, &segments.Segments); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal segments: %w", err)
+    }
+
+    return segments, nil
+}
+
+// GetUserIDsBySegment lists users carrying a segment tag (for promotion targeting)
+func (sr *SegmentRepository) GetUserIDsBySegment(ctx context.Context, segment string, limit int) ([]string, error) {
+    query := `
+        SELECT user_id
+        FROM $schema.user_segments
+        WHERE segments ? $1
+        ORDER BY lifetime_spend DESC
+        LIMIT $2
+    `
+
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, segment, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get users by segment: %w", err)
+    }
+    defer rows.Close()
+
+    userIDs := []string{}
+    for rows.Next() {
+        var userID string
+        if err := rows.Scan(&userID); err != nil {
+            return nil, fmt.Errorf("failed to scan user id: %w", err)
+        }
+        userIDs = append(userIDs, userID)
+    }
+
+    return userIDs, rows.Err()
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// revenueStatuses are the order statuses that count towards revenue
+const revenueStatuses = `'placed', 'confirmed', 'shipped', 'delivered'`
+
+// StatsRepository computes admin reporting aggregates
+type StatsRepository struct {
+    conn *db.Connection
+}
+
+// NewStatsRepository creates new stats repository
+func NewStatsRepository(conn *db.Connection) *StatsRepository {
+    return &StatsRepository{conn: conn}
+}
+
+// GetOrderBuckets returns order count, revenue (and the orders in it) and cancellations per day or week in [from, to)
+func (sr *StatsRepository) GetOrderBuckets(ctx context.Context, period string, from, to time.Time) ([]models.StatsBucket, error) {
+    truncUnit := "day"
+    if period == models.StatsPeriodWeekly {
+        truncUnit = "week"
+    }
+
+    query := `
+        SELECT date_trunc($1, created_at) AS bucket,
+               COUNT(*),
+               COALESCE(SUM(total) FILTER (WHERE status IN (` + revenueStatuses + `)), 0),
+               COUNT(*) FILTER (WHERE status IN (` + revenueStatuses + `)),
+               COUNT(*) FILTER (WHERE status = 'cancelled')
+        FROM $schema.orders
+        WHERE created_at >= $2 AND created_at < $3
+        GROUP BY bucket
+        ORDER BY bucket
+    `
+
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, truncUnit, from, to)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get order stats: %w", err)
+    }
+    defer rows.Close()
+
+    buckets := []models.StatsBucket{}
+    for rows.Next() {
+        var b models.StatsBucket
+        if err := rows.Scan(&b.PeriodStart, &b.OrderCount, &b.Revenue, &b.RevenueCount, &b.CancelledCount); err != nil {
+            return nil, fmt.Errorf("failed to scan order stats: %w", err)
+        }
+        buckets = append(buckets, b)
+    }
+
+    return buckets, rows.Err()
+}
+
+// GetTopProducts returns the best-selling products by quantity in [from, to)
+func (sr *StatsRepository) GetTopProducts(ctx context.Context, from, to time.Time, limit int) ([]models.TopProduct, error) {
+    query := `
+        SELECT oi.product_id, SUM(oi.quantity), SUM(oi.quantity * oi.price)
+        FROM $schema.order_items oi
+        JOIN $schema.orders o ON o.id = oi.order_id
+        WHERE o.created_at >= $1 AND o.created_at < $2
+          AND o.status IN (` + revenueStatuses + `)
+        GROUP BY oi.product_id
+        ORDER BY 2 DESC, 3 DESC
+        LIMIT $3
+    `
+
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, from, to, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get top products: %w", err)
+    }
+    defer rows.Close()
+
+    products := []models.TopProduct{}
+    for rows.Next() {
+        var p models.TopProduct
+        if err := rows.Scan(&p.ProductID, &p.Quantity, &p.Revenue); err != nil {
+            return nil, fmt.Errorf("failed to scan top product: %w", err)
+        }
+        products = append(products, p)
+    }
+
+    return products, rows.Err()
+}
+
+// GetSagaOutcomes returns sagas completed in [from, to), the revenue of their orders,
+// and failed sagas counted by raw failure reason
+func (sr *StatsRepository) GetSagaOutcomes(ctx context.Context, from, to time.Time) (*models.OrderFunnel, map[string]int, error) {
+    completedQuery := `
+        SELECT COUNT(*), COALESCE(SUM(o.total), 0)
+        FROM $schema.saga_states s
+        LEFT JOIN $schema.orders o ON o.id = s.order_id
+        WHERE s.status = 'completed' AND s.updated_at >= $1 AND s.updated_at < $2
+    `
+    completedQuery = sr.conn.Qualify(completedQuery)
+
+    funnel := &models.OrderFunnel{From: from, To: to, FailuresByReason: map[string]int{}}
+    err := sr.conn.QueryRowContext(ctx, completedQuery, from, to).Scan(&funnel.SagasCompleted, &funnel.RevenueConfirmed)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to get completed sagas: %w", err)
+    }
+
+    failedQuery := `
+        SELECT COALESCE(failure_reason, ''), COUNT(*)
+        FROM $schema.saga_states
+        WHERE status = 'failed' AND updated_at >= $1 AND updated_at < $2
+        GROUP BY 1
+    `
+    failedQuery = sr.conn.Qualify(failedQuery)
+
+    rows, err := sr.conn.QueryContext(ctx, failedQuery, from, to)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to get failed sagas: %w", err)
+    }
+    defer rows.Close()
+
+    failures := map[string]int{}
+    for rows.Next() {
+        var reason string
+        var count int
+        if err := rows.Scan(&reason, &count); err != nil {
+            return nil, nil, fmt.Errorf("failed to scan failed sagas: %w", err)
+        }
+        failures[reason] = count
+        funnel.SagasFailed += count
+    }
+
+    return funnel, failures, rows.Err()
+}
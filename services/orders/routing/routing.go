@@ -0,0 +1,139 @@
+// Package routing decides where each checkout item ships from and splits a checkout into one
+// order per warehouse and fulfillment type.
+package routing
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/clients"
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// ErrProductUnavailable is returned when the product was deleted from the catalog
+var ErrProductUnavailable = errors.New("product unavailable")
+
+// Route is where an item ships from and how it is fulfilled
+type Route struct {
+    Warehouse       string `json:"warehouse"`
+    FulfillmentType string `json:"fulfillment_type"` // standard, digital or dropship
+}
+
+// DefaultRoute matches the catalog defaults; used for products without routing data
+var DefaultRoute = Route{Warehouse: "main", FulfillmentType: "standard"}
+
+// Lookup returns the route of a product
+type Lookup interface {
+    Route(ctx context.Context, productID int64) (Route, error)
+}
+
+// Client reads product routes from the products service
+type Client struct {
+    products *clients.Products
+}
+
+// NewClient creates new products service routing client
+func NewClient(baseURL string, timeout time.Duration) *Client {
+    return &Client{
+        products: clients.NewProducts(clients.Config{BaseURL: baseURL, Timeout: timeout}),
+    }
+}
+
+// Route calls GET /products/:id on the products service
+func (c *Client) Route(ctx context.Context, productID int64) (Route, error) {
+    product, err := c.products.GetProduct(ctx, productID)
+    if clients.IsNotFound(err) {
+        return Route{}, ErrProductUnavailable
+    }
+    if err != nil {
+        return Route{}, fmt.Errorf("failed to fetch route for product %d: %w", productID, err)
+    }
+    return Route{Warehouse: product.Warehouse, FulfillmentType: product.FulfillmentType}, nil
+}
+
+// Resolve looks up the route of every product in items. Deleted products get DefaultRoute;
+// their reservation fails anyway. Other lookup errors abort.
+func Resolve(ctx context.Context, lookup Lookup, items []sharedmodels.OrderItem) (map[int64]Route, error) {
+    routes := make(map[int64]Route, len(items))
+    for _, item := range items {
+        if _, done := routes[item.ProductID]; done {
+            continue
+        }
+
+        route, err := lookup.Route(ctx, item.ProductID)
+        if errors.Is(err, ErrProductUnavailable) {
+            route = DefaultRoute
+        } else if err != nil {
+            return nil, err
+        }
+        if route.Warehouse == "" {
+            route.Warehouse = DefaultRoute.Warehouse
+        }
+        if route.FulfillmentType == "" {
+            route.FulfillmentType = DefaultRoute.FulfillmentType
+        }
+        routes[item.ProductID] = route
+    }
+    return routes, nil
+}
+
+// Group is the items of one child order
+type Group struct {
+    Route Route
+    Items []sharedmodels.OrderItem
+    Total float64
+}
+
+// Split groups items by route, in the order each route first appears; items without a route
+// get DefaultRoute. The cart total can include discounts, so group totals are the groups'
+// subtotals scaled to total, in cents, with the rounding remainder on the last group.
+func Split(items []sharedmodels.OrderItem, routes map[int64]Route, total float64) []Group {
+    var groups []Group
+    index := map[Route]int{}
+    for _, item := range items {
+        route, ok := routes[item.ProductID]
+        if !ok {
+            route = DefaultRoute
+        }
+
+        i, seen := index[route]
+        if !seen {
+            i = len(groups)
+            index[route] = i
+            groups = append(groups, Group{Route: route})
+        }
+        groups[i].Items = append(groups[i].Items, item)
+    }
+
+    subtotals := make([]float64, len(groups))
+    var sum float64
+    for i, group := range groups {
+        for _, item := range group.Items {
+            subtotals[i] += item.Price * float64(item.Quantity)
+        }
+        sum += subtotals[i]
+    }
+
+    totalCents := toCents(total)
+    var allocated int64
+    for i := range groups {
+        cents := totalCents - allocated
+        if i < len(groups)-1 {
+            cents = 0
+            if sum > 0 {
+                cents = int64(math.Round(float64(totalCents) * subtotals[i] / sum))
+            }
+        }
+        allocated += cents
+        groups[i].Total = float64(cents) / 100
+    }
+
+    return groups
+}
+
+func toCents(amount float64) int64 {
+    return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,79 @@
+package routing
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+type fakeLookup map[int64]Route
+
+func (fl fakeLookup) Route(ctx context.Context, productID int64) (Route, error) {
+    route, ok := fl[productID]
+    if !ok {
+        return Route{}, ErrProductUnavailable
+    }
+    return route, nil
+}
+
+var digital = Route{Warehouse: "main", FulfillmentType: "digital"}
+
+func TestSplit_GroupsByRouteAndKeepsTotal(t *testing.T) {
+    items := []sharedmodels.OrderItem{
+        {ProductID: 1, Quantity: 2, Price: 10.00},
+        {ProductID: 2, Quantity: 1, Price: 5.00},
+        {ProductID: 3, Quantity: 1, Price: 5.00},
+    }
+    routes, err := Resolve(context.Background(), fakeLookup{1: DefaultRoute, 2: digital, 3: {Warehouse: "main"}}, items)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    // 10% discount on the cart: 30.00 -> 27.00
+    groups := Split(items, routes, 27.00)
+    if len(groups) != 2 {
+        t.Fatalf("expected 2 groups, got %+v", groups)
+    }
+    if groups[0].Route != DefaultRoute || len(groups[0].Items) != 2 {
+        t.Errorf("expected products 1 and 3 together (3 defaults to standard), got %+v", groups[0])
+    }
+    if groups[1].Route != digital || groups[1].Items[0].ProductID != 2 {
+        t.Errorf("expected product 2 shipped digitally, got %+v", groups[1])
+    }
+    if groups[0].Total != 22.50 || groups[1].Total != 4.50 {
+        t.Errorf("expected totals 22.50 + 4.50, got %.2f + %.2f", groups[0].Total, groups[1].Total)
+    }
+}
+
+func TestSplit_RemainderOnLastGroup(t *testing.T) {
+    items := []sharedmodels.OrderItem{
+        {ProductID: 1, Quantity: 1, Price: 1.00},
+        {ProductID: 2, Quantity: 1, Price: 1.00},
+        {ProductID: 3, Quantity: 1, Price: 1.00},
+    }
+    routes := map[int64]Route{1: DefaultRoute, 2: digital, 3: {Warehouse: "east", FulfillmentType: "standard"}}
+
+    groups := Split(items, routes, 1.00)
+    var sum float64
+    for _, group := range groups {
+        sum += group.Total
+    }
+    if len(groups) != 3 || toCents(sum) != 100 {
+        t.Fatalf("expected 3 groups adding up to 1.00, got %+v", groups)
+    }
+}
+
+type failingLookup struct{}
+
+func (failingLookup) Route(ctx context.Context, productID int64) (Route, error) {
+    return Route{}, errors.New("connection refused")
+}
+
+func TestResolve_AbortsOnLookupFailure(t *testing.T) {
+    _, err := Resolve(context.Background(), failingLookup{}, []sharedmodels.OrderItem{{ProductID: 1, Quantity: 1, Price: 1}})
+    if err == nil {
+        t.Fatal("expected lookup failure to abort")
+    }
+}
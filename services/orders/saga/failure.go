@@ -0,0 +1,39 @@
+package saga
+
+import "strings"
+
+// Saga failure categories (prost_sagas_failed_total{reason} and the admin funnel)
+// Why: failure reasons are free text with IDs in them; metrics need a small fixed set
+const (
+    FailureOrderCreate           = "order_create_failed"
+    FailureInsufficientInventory = "insufficient_inventory"
+    FailureInventoryReservation  = "inventory_reservation_failed"
+    FailurePublish               = "publish_failed"
+    FailureTimeout               = "timeout"
+    FailurePayment               = "payment_failed"
+    FailureOther                 = "other"
+)
+
+// failureCategoryPrefixes maps reason prefixes (lowercased) to categories
+var failureCategoryPrefixes = []struct {
+    prefix   string
+    category string
+}{
+    {ReasonOrderCreateFailed, FailureOrderCreate},
+    {"insufficient inventory", FailureInsufficientInventory},
+    {"failed to reserve inventory", FailureInventoryReservation},
+    {"failed to publish", FailurePublish},
+    {"timeout", FailureTimeout},
+    {ReasonPaymentFailed, FailurePayment},
+}
+
+// FailureCategory buckets a saga failure reason into one of the Failure* categories
+func FailureCategory(reason string) string {
+    reason = strings.ToLower(strings.TrimSpace(reason))
+    for _, p := range failureCategoryPrefixes {
+        if strings.HasPrefix(reason, p.prefix) {
+            return p.category
+        }
+    }
+    return FailureOther
+}
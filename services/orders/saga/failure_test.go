@@ -0,0 +1,58 @@
+package saga
+
+import (
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+func TestFailureCategory(t *testing.T) {
+    cases := map[string]string{
+        ReasonOrderCreateFailed + ": db down":           FailureOrderCreate,
+        "Insufficient inventory for product 42":         FailureInsufficientInventory,
+        "failed to reserve inventory: connection reset": FailureInventoryReservation,
+        "Failed to publish OrderPlaced event":           FailurePublish,
+        "timeout waiting for StockReserved":             FailureTimeout,
+        ReasonPaymentFailed + ": card declined":         FailurePayment,
+        "payment declined":                              FailureOther,
+        "":                                              FailureOther,
+    }
+
+    for reason, want := range cases {
+        if got := FailureCategory(reason); got != want {
+            t.Errorf("FailureCategory(%q) = %q, want %q", reason, got, want)
+        }
+    }
+}
+
+func TestTimeoutReason(t *testing.T) {
+    progress := &models.ReservationProgress{Lines: []models.ReservationLine{
+        {OrderID: 1, ProductID: 10, Expected: 3, Reserved: 3},
+        {OrderID: 1, ProductID: 11, Expected: 2, Reserved: 1},
+        {OrderID: 2, ProductID: 12, Expected: 1},
+    }}
+
+    reason := TimeoutReason(progress)
+    if want := "timeout waiting for StockReserved: order 1 product 11 reserved 1 of 2 (1 more line(s) short)"; reason != want {
+        t.Errorf("TimeoutReason = %q, want %q", reason, want)
+    }
+    if FailureCategory(reason) != FailureTimeout || !IsRetryableReason(reason) {
+        t.Errorf("%q should be a retryable timeout", reason)
+    }
+}
+
+func TestEventSource(t *testing.T) {
+    cases := map[string]string{
+        "CartCheckoutInitiated": "cart",
+        "StockReserved":         "products",
+        "OrderShipped":          "shipping",
+        "PaymentFailed":         "payments",
+        "OrderFailed":           models.ActorOrders,
+    }
+
+    for eventType, want := range cases {
+        if got := eventSource(eventType); got != want {
+            t.Errorf("eventSource(%q) = %q, want %q", eventType, got, want)
+        }
+    }
+}
@@ -0,0 +1,405 @@
+package saga
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// fakeStore keeps the orders schema in memory and implements every store the orchestrator uses,
+// with the same outcomes as the repositories
+type fakeStore struct {
+    // Repository methods only the handlers call; the saga calling one panics
+    repository.OrderRepositoryInterface
+    repository.SagaStateRepositoryInterface
+    repository.CompensationLogRepositoryInterface
+    repository.InventoryReservationRepositoryInterface
+
+    orders       map[int64]*models.Order
+    checkouts    map[int64][]int64 // checkout ID -> order IDs
+    sagas        map[string]*models.SagaState
+    statuses     map[string][]string // every status each saga went through
+    progress     map[string]*models.ReservationProgress
+    reservations []*models.InventoryReservation
+    compensation []*models.CompensationLog
+    processed    map[string]bool
+    pushes       map[int64]*models.PendingFulfillmentPush // failed 3PL pushes by order ID
+
+    createCheckoutErr error // returned by CreateCheckout when set
+}
+
+func newFakeStore() *fakeStore {
+    return &fakeStore{
+        orders:    map[int64]*models.Order{},
+        checkouts: map[int64][]int64{},
+        sagas:     map[string]*models.SagaState{},
+        statuses:  map[string][]string{},
+        progress:  map[string]*models.ReservationProgress{},
+        processed: map[string]bool{},
+        pushes:    map[int64]*models.PendingFulfillmentPush{},
+    }
+}
+
+// Orders
+
+func (s *fakeStore) GetOrder(ctx context.Context, orderID int64) (*models.Order, error) {
+    order, ok := s.orders[orderID]
+    if !ok {
+        return nil, repository.ErrOrderNotFound
+    }
+    copied := *order
+    return &copied, nil
+}
+
+func (s *fakeStore) UpdateOrderStatus(ctx context.Context, orderID int64, status string) error {
+    order, ok := s.orders[orderID]
+    if !ok {
+        return repository.ErrOrderNotFound
+    }
+    order.Status = status
+    return nil
+}
+
+func (s *fakeStore) MarkOrderShipped(ctx context.Context, orderID int64, trackingNumber, carrier string, shippedAt time.Time) error {
+    return s.UpdateOrderStatus(ctx, orderID, "shipped")
+}
+
+func (s *fakeStore) MarkOrderDelivered(ctx context.Context, orderID int64, deliveredAt time.Time) error {
+    return s.UpdateOrderStatus(ctx, orderID, "delivered")
+}
+
+// CheckoutStore
+
+func (s *fakeStore) CreateCheckout(ctx context.Context, checkout *models.Checkout) error {
+    if s.createCheckoutErr != nil {
+        return s.createCheckoutErr
+    }
+    for _, order := range checkout.Orders {
+        checkoutID := checkout.ID
+        order.CheckoutID = &checkoutID
+        copied := *order
+        s.orders[order.ID] = &copied
+        s.checkouts[checkout.ID] = append(s.checkouts[checkout.ID], order.ID)
+    }
+    return nil
+}
+
+func (s *fakeStore) GetCheckoutOrders(ctx context.Context, checkoutID int64) ([]*models.Order, error) {
+    var orders []*models.Order
+    for _, orderID := range s.checkouts[checkoutID] {
+        order, _ := s.GetOrder(ctx, orderID)
+        orders = append(orders, order)
+    }
+    return orders, nil
+}
+
+func (s *fakeStore) PlaceCheckoutIfReserved(ctx context.Context, checkoutID int64) ([]int64, error) {
+    orderIDs, ok := s.checkouts[checkoutID]
+    if !ok {
+        return nil, repository.ErrCheckoutNotFound
+    }
+    for _, orderID := range orderIDs {
+        if !s.reserved(orderID) {
+            return nil, nil
+        }
+    }
+
+    var placed []int64
+    for _, orderID := range orderIDs {
+        if order := s.orders[orderID]; order.Status == "pending" {
+            order.Status = "placed"
+            placed = append(placed, orderID)
+        }
+    }
+    return placed, nil
+}
+
+func (s *fakeStore) reserved(orderID int64) bool {
+    for _, res := range s.reservations {
+        if res.OrderID == orderID && res.Status == "reserved" {
+            return true
+        }
+    }
+    return false
+}
+
+// Saga state
+
+func (s *fakeStore) CreateSagaState(ctx context.Context, saga *models.SagaState) error {
+    if _, ok := s.sagas[saga.CorrelationID]; ok {
+        return fmt.Errorf("saga %s already exists", saga.CorrelationID)
+    }
+    copied := *saga
+    s.sagas[saga.CorrelationID] = &copied
+    s.statuses[saga.CorrelationID] = []string{saga.Status}
+    return nil
+}
+
+func (s *fakeStore) GetSagaState(ctx context.Context, correlationID string) (*models.SagaState, error) {
+    saga, ok := s.sagas[correlationID]
+    if !ok {
+        return nil, fmt.Errorf("saga state not found")
+    }
+    copied := *saga
+    copied.Payload = map[string]interface{}{}
+    for key, value := range saga.Payload {
+        copied.Payload[key] = value
+    }
+    if progress := s.progress[correlationID]; progress != nil {
+        copied.Payload["reservations"] = copyProgress(progress)
+    }
+    return &copied, nil
+}
+
+func (s *fakeStore) setStatus(correlationID, status string) error {
+    saga, ok := s.sagas[correlationID]
+    if !ok {
+        return fmt.Errorf("saga state not found")
+    }
+    saga.Status = status
+    s.statuses[correlationID] = append(s.statuses[correlationID], status)
+    return nil
+}
+
+func (s *fakeStore) UpdateSagaStatus(ctx context.Context, correlationID, status string) error {
+    return s.setStatus(correlationID, status)
+}
+
+func (s *fakeStore) UpdateSagaOrderID(ctx context.Context, correlationID string, orderID int64) error {
+    saga, ok := s.sagas[correlationID]
+    if !ok {
+        return fmt.Errorf("saga state not found")
+    }
+    saga.OrderID = &orderID
+    return nil
+}
+
+func (s *fakeStore) RecordCheckpoint(ctx context.Context, correlationID, step string) error {
+    saga, ok := s.sagas[correlationID]
+    if !ok {
+        return fmt.Errorf("saga state not found")
+    }
+    saga.LastCompletedStep = step
+    return nil
+}
+
+func (s *fakeStore) MarkSagaFailed(ctx context.Context, correlationID, reason string) error {
+    if err := s.setStatus(correlationID, "failed"); err != nil {
+        return err
+    }
+    s.sagas[correlationID].FailureReason = &reason
+    return nil
+}
+
+func (s *fakeStore) BeginResume(ctx context.Context, correlationID, status string) error {
+    saga, ok := s.sagas[correlationID]
+    if !ok || saga.Status != "failed" {
+        return fmt.Errorf("saga not found or no longer failed")
+    }
+    saga.FailureReason = nil
+    saga.RetryCount++
+    return s.setStatus(correlationID, status)
+}
+
+func (s *fakeStore) StartReservationTracking(ctx context.Context, correlationID string, progress *models.ReservationProgress) error {
+    s.progress[correlationID] = copyProgress(progress)
+    return nil
+}
+
+func (s *fakeStore) RecordReservedStock(ctx context.Context, correlationID string, orderID int64, reserved []models.ReservedLine) (*models.ReservationProgress, error) {
+    saga, ok := s.sagas[correlationID]
+    if !ok {
+        return nil, fmt.Errorf("failed to lock saga state: not found")
+    }
+    if saga.Status == "failed" {
+        return nil, repository.ErrSagaFailed
+    }
+    progress := s.progress[correlationID]
+    if progress == nil {
+        return nil, nil
+    }
+    if progress.Record(orderID, reserved) > 0 && progress.Complete() {
+        if err := s.setStatus(correlationID, "inventory_reserved"); err != nil {
+            return nil, err
+        }
+    }
+    return copyProgress(progress), nil
+}
+
+func (s *fakeStore) ListReservationTimedOut(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    var due []string
+    for correlationID := range s.sagas {
+        if s.timedOut(correlationID, now) && len(due) < limit {
+            due = append(due, correlationID)
+        }
+    }
+    return due, nil
+}
+
+func (s *fakeStore) FailIfReservationTimedOut(ctx context.Context, correlationID string, now time.Time, reason string) (*models.ReservationProgress, error) {
+    if !s.timedOut(correlationID, now) {
+        return nil, nil
+    }
+    if err := s.MarkSagaFailed(ctx, correlationID, reason); err != nil {
+        return nil, err
+    }
+    return copyProgress(s.progress[correlationID]), nil
+}
+
+func (s *fakeStore) timedOut(correlationID string, now time.Time) bool {
+    progress := s.progress[correlationID]
+    return s.sagas[correlationID].Status == "checking_inventory" && progress != nil && !progress.Deadline.After(now)
+}
+
+func (s *fakeStore) RevertReservationTimeout(ctx context.Context, correlationID string) error {
+    saga, ok := s.sagas[correlationID]
+    if !ok || saga.Status != "failed" {
+        return nil
+    }
+    saga.FailureReason = nil
+    return s.setStatus(correlationID, "checking_inventory")
+}
+
+// Compensation log
+
+func (s *fakeStore) CreateCompensationLog(ctx context.Context, log *models.CompensationLog) error {
+    s.compensation = append(s.compensation, log)
+    return nil
+}
+
+func (s *fakeStore) GetCompensationLogsByOrderID(ctx context.Context, orderID int64) ([]*models.CompensationLog, error) {
+    var logs []*models.CompensationLog
+    for _, log := range s.compensation {
+        if log.OrderID == orderID {
+            logs = append(logs, log)
+        }
+    }
+    return logs, nil
+}
+
+// Inventory reservations
+
+func (s *fakeStore) CreateReservation(ctx context.Context, res *models.InventoryReservation) error {
+    s.reservations = append(s.reservations, res)
+    return nil
+}
+
+func (s *fakeStore) ReleaseReservation(ctx context.Context, reservationID string) error {
+    for _, res := range s.reservations {
+        if res.ReservationID == reservationID && res.Status == "reserved" {
+            res.Status = "released"
+            return nil
+        }
+    }
+    return fmt.Errorf("reservation not found or already released")
+}
+
+// IdempotencyStore
+
+func (s *fakeStore) IsProcessed(ctx context.Context, eventID, serviceName string) (bool, error) {
+    return s.processed[serviceName+"/"+eventID], nil
+}
+
+func (s *fakeStore) RecordProcessed(ctx context.Context, eventID, serviceName, action, result string) error {
+    s.processed[serviceName+"/"+eventID] = true
+    return nil
+}
+
+// FulfillmentStore
+
+func (s *fakeStore) SavePendingPush(ctx context.Context, orderID int64, payload json.RawMessage, reason string) error {
+    if push, ok := s.pushes[orderID]; ok {
+        push.Attempts++
+        push.LastError = reason
+        return nil
+    }
+    s.pushes[orderID] = &models.PendingFulfillmentPush{OrderID: orderID, Payload: payload, Attempts: 1, LastError: reason}
+    return nil
+}
+
+func (s *fakeStore) ListPendingPushes(ctx context.Context, limit int) ([]*models.PendingFulfillmentPush, error) {
+    var pushes []*models.PendingFulfillmentPush
+    for _, push := range s.pushes {
+        if len(pushes) < limit {
+            copied := *push
+            pushes = append(pushes, &copied)
+        }
+    }
+    return pushes, nil
+}
+
+func (s *fakeStore) DeletePendingPush(ctx context.Context, orderID int64) error {
+    delete(s.pushes, orderID)
+    return nil
+}
+
+// PaymentStore
+
+func (s *fakeStore) MarkPaymentPending(ctx context.Context, orderID int64, attempt int, reason string, deadline time.Time) (time.Time, bool, error) {
+    order, ok := s.orders[orderID]
+    if !ok || order.Status != "placed" {
+        return time.Time{}, false, nil
+    }
+    order.Status = "payment_pending"
+    return deadline, true, nil
+}
+
+func (s *fakeStore) MarkPaymentProcessed(ctx context.Context, orderID int64) (bool, error) {
+    order, ok := s.orders[orderID]
+    if !ok || order.Status != "payment_pending" {
+        return false, nil
+    }
+    order.Status = "placed"
+    return true, nil
+}
+
+// HoldStore
+
+func (s *fakeStore) HoldUserOrders(ctx context.Context, userID, reason, note, createdBy string) ([]*models.OrderHold, error) {
+    return nil, nil
+}
+
+func copyProgress(progress *models.ReservationProgress) *models.ReservationProgress {
+    copied := *progress
+    copied.Lines = append([]models.ReservationLine{}, progress.Lines...)
+    copied.ReservationIDs = append([]string{}, progress.ReservationIDs...)
+    return &copied
+}
+
+// publishedEvent is one event the orchestrator published, as it went on the wire
+type publishedEvent struct {
+    routingKey string
+    body       []byte
+}
+
+// fakePublisher records published events in order
+type fakePublisher struct {
+    events []publishedEvent
+    err    error // returned by every publish when set
+}
+
+func (p *fakePublisher) PublishOrderEventReliable(ctx context.Context, event interface{}) error {
+    if p.err != nil {
+        return p.err
+    }
+    typed, ok := event.(events.Event)
+    if !ok {
+        return errors.New("not an event")
+    }
+    routingKey, err := events.RoutingKey(typed)
+    if err != nil {
+        return err
+    }
+    body, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    p.events = append(p.events, publishedEvent{routingKey: routingKey, body: body})
+    return nil
+}
@@ -0,0 +1,604 @@
+package saga
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/fulfillment"
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// Golden event flows
+// Why: the saga is the most critical business flow and its behaviour is spread over a dozen
+// handlers. Each flow feeds the events of one canonical scenario through the orchestrator,
+// against in-memory stores, and compares what it published and the final order and saga
+// states with testdata/<flow>.golden.json. After an intended change, rerun with -update and
+// review the diff of the golden files.
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const (
+    flowCorrelationID = "saga-flow-1"
+    flowUserID        = "user-1"
+    flowCartID        = "cart-1"
+)
+
+// flowHarness runs one saga. Every published event goes back to the orchestrator, like the
+// order.* events it consumes from the bus, and the test plays the other services.
+type flowHarness struct {
+    t         *testing.T
+    ctx       context.Context
+    store     *fakeStore
+    publisher *fakePublisher
+    saga      *SagaOrchestrator
+    clock     *clock.Fake
+    delivered int      // published events already looped back
+    handled   []string // every event handled, with its error
+    orderIDs  []int64  // in the order they were first seen, named order-1, order-2...
+}
+
+func newFlowHarness(t *testing.T) *flowHarness {
+    store := newFakeStore()
+    publisher := &fakePublisher{}
+    clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+    return &flowHarness{
+        t:         t,
+        ctx:       context.Background(),
+        store:     store,
+        publisher: publisher,
+        saga: NewSagaOrchestrator(store, store, store, store, store, store, publisher,
+            nil, nil, nil, store, store, store, 0, 5*time.Minute, clk),
+        clock:     clk,
+    }
+}
+
+// send handles an event another service published, then everything the saga published in turn
+func (h *flowHarness) send(event interface{}) {
+    h.t.Helper()
+    h.handle(event)
+    h.deliver()
+}
+
+// deliver loops back everything the saga published since the last delivery
+func (h *flowHarness) deliver() {
+    h.t.Helper()
+    for h.delivered < len(h.publisher.events) {
+        published := h.publisher.events[h.delivered]
+        h.delivered++
+        h.handleMessage(published.body)
+    }
+}
+
+func (h *flowHarness) handle(event interface{}) {
+    h.t.Helper()
+    body, err := json.Marshal(event)
+    if err != nil {
+        h.t.Fatalf("failed to marshal %T: %v", event, err)
+    }
+    h.handleMessage(body)
+}
+
+func (h *flowHarness) handleMessage(body []byte) {
+    h.t.Helper()
+    envelope, err := events.ReadEnvelope(body)
+    if err != nil {
+        h.t.Fatalf("bad event: %v", err)
+    }
+    result := envelope.EventType
+    if err := h.saga.HandleEvent(h.ctx, body); err != nil {
+        result += ": " + err.Error()
+    }
+    h.handled = append(h.handled, result)
+}
+
+// checkout starts the saga the way the cart service does
+func (h *flowHarness) checkout(items ...sharedmodels.OrderItem) {
+    h.t.Helper()
+    var total float64
+    for _, item := range items {
+        total += item.Price * float64(item.Quantity)
+    }
+    h.send(events.CartCheckoutInitiatedEvent{
+        BaseEvent: events.NewBaseEvent("CartCheckoutInitiated", flowCartID, "cart", flowCorrelationID),
+        CartID:    flowCartID,
+        UserID:    flowUserID,
+        Total:     total,
+        Items:     items,
+    })
+}
+
+// orderCreated returns the OrderCreated events published so far
+func (h *flowHarness) orderCreated() []events.OrderCreatedEvent {
+    h.t.Helper()
+    var created []events.OrderCreatedEvent
+    for _, published := range h.publisher.events {
+        envelope, _ := events.ReadEnvelope(published.body)
+        if envelope.EventType != "OrderCreated" {
+            continue
+        }
+        event, err := events.Unmarshal[events.OrderCreatedEvent](published.body)
+        if err != nil {
+            h.t.Fatalf("bad OrderCreated: %v", err)
+        }
+        created = append(created, event)
+    }
+    if len(created) == 0 {
+        h.t.Fatal("no OrderCreated published")
+    }
+    return created
+}
+
+// reserveStock answers OrderCreated like products when it has the stock
+func (h *flowHarness) reserveStock() {
+    h.t.Helper()
+    for _, created := range h.orderCreated() {
+        reserved := events.StockReservedEvent{
+            BaseEvent: events.NewBaseEvent("StockReserved", strconv.FormatInt(created.OrderID, 10), "order", created.CorrelationID),
+            OrderID:   created.OrderID,
+        }
+        for _, item := range created.Items {
+            reserved.Items = append(reserved.Items, events.ReservedStock{
+                ProductID:     item.ProductID,
+                VariantID:     item.VariantID,
+                Quantity:      item.Quantity,
+                ReservationID: fmt.Sprintf("res-%d", item.ProductID),
+            })
+        }
+        h.send(reserved)
+    }
+}
+
+// releaseStock releases the order's reservations like products does after a cancellation
+func (h *flowHarness) releaseStock(reason string) {
+    h.t.Helper()
+    for _, res := range h.store.reservations {
+        h.send(events.StockReleasedEvent{
+            BaseEvent:     events.NewBaseEvent("StockReleased", strconv.FormatInt(res.ProductID, 10), "product", flowCorrelationID),
+            ProductID:     res.ProductID,
+            Quantity:      res.Quantity,
+            ReservationID: res.ReservationID,
+            Reason:        reason,
+        })
+    }
+}
+
+// firstOrderID is the ID of the checkout's first order
+func (h *flowHarness) firstOrderID() int64 {
+    h.t.Helper()
+    return h.orderCreated()[0].OrderID
+}
+
+// orderName names an order ID by when it was first seen, since IDs are random
+func (h *flowHarness) orderName(orderID int64) string {
+    return fmt.Sprintf("order-%d", h.orderIndex(orderID)+1)
+}
+
+func (h *flowHarness) orderIndex(orderID int64) int {
+    for i, id := range h.orderIDs {
+        if id == orderID {
+            return i
+        }
+    }
+    h.orderIDs = append(h.orderIDs, orderID)
+    return len(h.orderIDs) - 1
+}
+
+// normalize replaces order IDs with their names in a decoded JSON value
+func (h *flowHarness) normalize(value interface{}) interface{} {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        for key, field := range v {
+            if key == "order_id" || key == "aggregate_id" {
+                if id, ok := h.knownOrderID(field); ok {
+                    v[key] = h.orderName(id)
+                    continue
+                }
+            }
+            v[key] = h.normalize(field)
+        }
+        return v
+    case []interface{}:
+        for i := range v {
+            v[i] = h.normalize(v[i])
+        }
+        return v
+    case string:
+        // Failure reasons name orders
+        for i, id := range h.orderIDs {
+            v = strings.ReplaceAll(v, strconv.FormatInt(id, 10), fmt.Sprintf("order-%d", i+1))
+        }
+        return v
+    default:
+        return v
+    }
+}
+
+// knownOrderID reads an order ID field, a number or a numeric string, registering new IDs
+func (h *flowHarness) knownOrderID(field interface{}) (int64, bool) {
+    var raw string
+    switch v := field.(type) {
+    case json.Number:
+        raw = v.String()
+    case string:
+        raw = v
+    default:
+        return 0, false
+    }
+    id, err := strconv.ParseInt(raw, 10, 64)
+    if err != nil || id == 0 {
+        return 0, false
+    }
+    h.orderIndex(id)
+    return id, true
+}
+
+// decode turns v into generic JSON, keeping numbers exact
+func decode(t *testing.T, v interface{}) interface{} {
+    t.Helper()
+    body, err := json.Marshal(v)
+    if err != nil {
+        t.Fatalf("failed to marshal: %v", err)
+    }
+    decoder := json.NewDecoder(bytes.NewReader(body))
+    decoder.UseNumber()
+    var value interface{}
+    if err := decoder.Decode(&value); err != nil {
+        t.Fatalf("failed to decode: %v", err)
+    }
+    return value
+}
+
+// snapshot is what a flow is compared on
+type snapshot struct {
+    Handled      []string                 `json:"handled"`
+    Published    []map[string]interface{} `json:"published"`
+    Orders       []interface{}            `json:"orders"`
+    Saga         interface{}              `json:"saga"`
+    Reservations []interface{}            `json:"reservations"`
+    Compensation []interface{}            `json:"compensation"`
+}
+
+func (h *flowHarness) snapshot() snapshot {
+    t := h.t
+    t.Helper()
+
+    snap := snapshot{
+        Handled:      h.handled,
+        Published:    []map[string]interface{}{},
+        Orders:       []interface{}{},
+        Reservations: []interface{}{},
+        Compensation: []interface{}{},
+    }
+
+    // Published events, without what changes on every run
+    for _, published := range h.publisher.events {
+        event := decode(t, json.RawMessage(published.body)).(map[string]interface{})
+        delete(event, "event_id")
+        delete(event, "timestamp")
+        snap.Published = append(snap.Published, map[string]interface{}{
+            "routing_key": published.routingKey,
+            "event":       h.normalize(event),
+        })
+    }
+
+    orderIDs := make([]int64, 0, len(h.store.orders))
+    for id := range h.store.orders {
+        orderIDs = append(orderIDs, id)
+    }
+    sort.Slice(orderIDs, func(i, j int) bool { return h.orderIndex(orderIDs[i]) < h.orderIndex(orderIDs[j]) })
+    for _, id := range orderIDs {
+        order := h.store.orders[id]
+        items := []interface{}{}
+        for _, item := range order.Items {
+            items = append(items, map[string]interface{}{
+                "product_id": item.ProductID,
+                "quantity":   item.Quantity,
+                "price":      item.Price,
+            })
+        }
+        snap.Orders = append(snap.Orders, h.normalize(decode(t, map[string]interface{}{
+            "order_id": order.ID,
+            "status":   order.Status,
+            "total":    order.Total,
+            "items":    items,
+        })))
+    }
+
+    if saga, ok := h.store.sagas[flowCorrelationID]; ok {
+        state := map[string]interface{}{
+            "status":              saga.Status,
+            "statuses":            h.store.statuses[flowCorrelationID],
+            "last_completed_step": saga.LastCompletedStep,
+            "failure_reason":      saga.FailureReason,
+            "order_id":            saga.OrderID,
+        }
+        if progress := h.store.progress[flowCorrelationID]; progress != nil {
+            state["reservation_lines"] = progress.Lines
+        }
+        snap.Saga = h.normalize(decode(t, state))
+    }
+
+    for _, res := range h.store.reservations {
+        snap.Reservations = append(snap.Reservations, h.normalize(decode(t, map[string]interface{}{
+            "order_id":       res.OrderID,
+            "product_id":     res.ProductID,
+            "quantity":       res.Quantity,
+            "reservation_id": res.ReservationID,
+            "status":         res.Status,
+        })))
+    }
+
+    for _, log := range h.store.compensation {
+        snap.Compensation = append(snap.Compensation, h.normalize(decode(t, map[string]interface{}{
+            "order_id": log.OrderID,
+            "event":    log.CompensationEvent,
+            "payload":  log.CompensationPayload,
+        })))
+    }
+
+    return snap
+}
+
+// assertGolden compares the flow with testdata/<name>.golden.json, or rewrites it with -update
+func (h *flowHarness) assertGolden(name string) {
+    t := h.t
+    t.Helper()
+
+    // Name the orders in the order they were published so the goldens read top to bottom
+    h.orderIDs = nil
+    for _, published := range h.publisher.events {
+        h.normalize(decode(t, json.RawMessage(published.body)))
+    }
+
+    got, err := json.MarshalIndent(h.snapshot(), "", "  ")
+    if err != nil {
+        t.Fatalf("failed to marshal snapshot: %v", err)
+    }
+    got = append(got, '\n')
+
+    path := filepath.Join("testdata", name+".golden.json")
+    if *update {
+        if err := os.MkdirAll("testdata", 0o755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(path, got, 0o644); err != nil {
+            t.Fatal(err)
+        }
+        return
+    }
+
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("%s differs from the golden file; run go test ./saga -update and review the diff\n--- got\n%s", path, got)
+    }
+}
+
+var flowItems = []sharedmodels.OrderItem{
+    {ProductID: 1, Quantity: 2, Price: 10.00},
+    {ProductID: 2, Quantity: 1, Price: 24.50},
+}
+
+func TestFlowHappyPath(t *testing.T) {
+    h := newFlowHarness(t)
+
+    h.checkout(flowItems...)
+    h.reserveStock()
+    orderID := h.firstOrderID()
+    h.send(events.OrderConfirmedEvent{
+        BaseEvent: events.NewBaseEvent("OrderConfirmed", strconv.FormatInt(orderID, 10), "order", flowCorrelationID),
+        OrderID:   orderID,
+    })
+
+    h.assertGolden("happy_path")
+}
+
+func TestFlowStockShortfall(t *testing.T) {
+    h := newFlowHarness(t)
+
+    h.checkout(flowItems...)
+    orderID := h.firstOrderID()
+    h.send(events.StockReservationFailedEvent{
+        BaseEvent: events.NewBaseEvent("StockReservationFailed", strconv.FormatInt(orderID, 10), "order", flowCorrelationID),
+        OrderID:   orderID,
+        ProductID: 2,
+        Requested: 1,
+        Available: 0,
+        Reason:    "Insufficient inventory for product 2",
+    })
+
+    h.assertGolden("stock_shortfall")
+}
+
+func TestFlowOrderCreateFailure(t *testing.T) {
+    h := newFlowHarness(t)
+    h.store.createCheckoutErr = errors.New("failed to create checkout: connection refused")
+
+    h.checkout(flowItems...)
+
+    h.assertGolden("order_create_failure")
+
+    // The saga is failed, not stuck, so it can be resumed
+    if saga := h.store.sagas[flowCorrelationID]; saga.Status != "failed" {
+        t.Errorf("saga status = %s, want failed", saga.Status)
+    }
+}
+
+func TestFlowCancelMidFlight(t *testing.T) {
+    h := newFlowHarness(t)
+
+    h.checkout(flowItems...)
+    h.reserveStock()
+    orderID := h.firstOrderID()
+    h.send(events.OrderCancelledEvent{
+        BaseEvent: events.NewBaseEvent("OrderCancelled", strconv.FormatInt(orderID, 10), "order", flowCorrelationID),
+        OrderID:   strconv.FormatInt(orderID, 10),
+        Reason:    "customer changed their mind",
+    })
+    h.releaseStock("order cancelled")
+
+    h.assertGolden("cancel_mid_flight")
+}
+
+func TestFlowReservationTimeout(t *testing.T) {
+    h := newFlowHarness(t)
+
+    h.checkout(flowItems...)
+
+    // The deadline is 5 minutes of the orchestrator's clock after the orders were created
+    h.clock.Advance(5*time.Minute - time.Second)
+    if failed := h.saga.ExpireReservations(h.ctx, h.clock.Now(), 10); failed != 0 {
+        t.Fatalf("failed %d saga(s) before the deadline", failed)
+    }
+    h.clock.Advance(time.Second)
+    if failed := h.saga.ExpireReservations(h.ctx, h.clock.Now(), 10); failed != 1 {
+        t.Fatalf("failed %d saga(s) past the deadline, want 1", failed)
+    }
+    h.deliver()
+
+    h.assertGolden("reservation_timeout")
+}
+
+func TestFlowShippingAddressCopiedToOrders(t *testing.T) {
+    h := newFlowHarness(t)
+    address := &sharedmodels.Address{ID: "addr-1", RecipientName: "Ada", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"}
+
+    h.send(events.CartCheckoutInitiatedEvent{
+        BaseEvent:       events.NewBaseEvent("CartCheckoutInitiated", flowCartID, "cart", flowCorrelationID),
+        CartID:          flowCartID,
+        UserID:          flowUserID,
+        Total:           44.50,
+        Items:           flowItems,
+        ShippingAddress: address,
+    })
+
+    order := h.store.orders[h.firstOrderID()]
+    if order == nil || order.ShippingAddress == nil {
+        t.Fatalf("expected the order to keep the shipping address, got %+v", order)
+    }
+    if got := sharedmodels.Address(*order.ShippingAddress); got != *address {
+        t.Fatalf("expected %+v, got %+v", *address, got)
+    }
+}
+
+func TestFlowShippingAddressSentToFulfillment(t *testing.T) {
+    var payload fulfillment.OrderPayload
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+            t.Errorf("bad fulfillment payload: %v", err)
+        }
+    }))
+    defer server.Close()
+
+    h := newFlowHarness(t)
+    h.saga.fulfillmentClient = fulfillment.NewClient(fulfillment.Config{Endpoint: server.URL, Secret: "secret", SLA: 48 * time.Hour})
+    address := &sharedmodels.Address{RecipientName: "Ada", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"}
+
+    h.send(events.CartCheckoutInitiatedEvent{
+        BaseEvent:       events.NewBaseEvent("CartCheckoutInitiated", flowCartID, "cart", flowCorrelationID),
+        CartID:          flowCartID,
+        UserID:          flowUserID,
+        Total:           44.50,
+        Items:           flowItems,
+        ShippingAddress: address,
+    })
+    h.reserveStock()
+
+    if payload.OrderID != h.firstOrderID() {
+        t.Fatalf("3PL got order %d, want %d", payload.OrderID, h.firstOrderID())
+    }
+    if payload.ShippingAddress == nil || *payload.ShippingAddress != *address {
+        t.Errorf("3PL got shipping address %+v, want %+v", payload.ShippingAddress, *address)
+    }
+}
+
+func TestFlowFailedFulfillmentPushIsRetried(t *testing.T) {
+    var pushed []int64
+    down := true
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if down {
+            http.Error(w, "maintenance", http.StatusServiceUnavailable)
+            return
+        }
+        var payload fulfillment.OrderPayload
+        if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+            t.Errorf("bad fulfillment payload: %v", err)
+        }
+        pushed = append(pushed, payload.OrderID)
+    }))
+    defer server.Close()
+
+    h := newFlowHarness(t)
+    h.saga.fulfillmentClient = fulfillment.NewClient(fulfillment.Config{Endpoint: server.URL, Secret: "secret", SLA: 48 * time.Hour})
+
+    h.checkout(flowItems...)
+    h.reserveStock()
+    orderID := h.firstOrderID()
+
+    // OrderPlaced is still handled, with the push kept for the worker
+    for _, handled := range h.handled {
+        if strings.HasPrefix(handled, "OrderPlaced:") {
+            t.Errorf("OrderPlaced failed: %s", handled)
+        }
+    }
+    if push := h.store.pushes[orderID]; push == nil || push.Attempts != 1 {
+        t.Fatalf("pending push = %+v, want one attempt of order %d", push, orderID)
+    }
+
+    if got := h.saga.RetryFulfillment(h.ctx, 10); got != 0 {
+        t.Fatalf("pushed %d while the 3PL is down, want 0", got)
+    }
+    if push := h.store.pushes[orderID]; push == nil || push.Attempts != 2 {
+        t.Fatalf("pending push = %+v, want 2 attempts", push)
+    }
+
+    down = false
+    if got := h.saga.RetryFulfillment(h.ctx, 10); got != 1 {
+        t.Fatalf("pushed %d, want 1", got)
+    }
+    if len(pushed) != 1 || pushed[0] != orderID {
+        t.Errorf("3PL got orders %v, want [%d]", pushed, orderID)
+    }
+    if len(h.store.pushes) != 0 {
+        t.Errorf("pending pushes left: %v", h.store.pushes)
+    }
+}
+
+func TestFlowFulfillmentRetryDropsCancelledOrders(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        t.Errorf("cancelled order pushed to the 3PL")
+    }))
+    defer server.Close()
+
+    h := newFlowHarness(t)
+    h.saga.fulfillmentClient = fulfillment.NewClient(fulfillment.Config{Endpoint: server.URL, Secret: "secret", SLA: 48 * time.Hour})
+    h.checkout(flowItems...)
+    orderID := h.firstOrderID()
+    h.store.orders[orderID].Status = "cancelled"
+    h.store.pushes[orderID] = &models.PendingFulfillmentPush{OrderID: orderID, Payload: json.RawMessage(`{}`), Attempts: 1}
+
+    if got := h.saga.RetryFulfillment(h.ctx, 10); got != 0 {
+        t.Fatalf("pushed %d, want 0", got)
+    }
+    if len(h.store.pushes) != 0 {
+        t.Errorf("pending push of the cancelled order kept")
+    }
+}
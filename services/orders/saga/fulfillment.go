@@ -0,0 +1,91 @@
+package saga
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/fulfillment"
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// 3PL push retries
+// Why: OrderPlaced is recorded as processed even when its handler fails, so a push the 3PL
+// refused would never be sent again. The refused payload is kept instead, and retried every
+// interval until the 3PL takes it.
+
+// deferPush records a push that failed for StartFulfillmentRetry
+func (so *SagaOrchestrator) deferPush(ctx context.Context, payload fulfillment.OrderPayload, pushErr error) error {
+    if so.fulfillmentRepo == nil {
+        return pushErr
+    }
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return fmt.Errorf("failed to marshal fulfillment payload: %w", err)
+    }
+    if err := so.fulfillmentRepo.SavePendingPush(ctx, payload.OrderID, body, pushErr.Error()); err != nil {
+        return fmt.Errorf("%w (and failed to keep it for a retry: %v)", pushErr, err)
+    }
+    return nil
+}
+
+// StartFulfillmentRetry retries the failed 3PL pushes every interval until ctx is cancelled
+func (so *SagaOrchestrator) StartFulfillmentRetry(ctx context.Context, interval time.Duration, batchSize int) <-chan struct{} {
+    return clock.Every(ctx, so.clock, interval, false, func(ctx context.Context) {
+        so.RetryFulfillment(ctx, batchSize)
+    })
+}
+
+// RetryFulfillment pushes up to limit failed pushes again and returns how many got through.
+// Orders cancelled or failed since are dropped instead of sent.
+func (so *SagaOrchestrator) RetryFulfillment(ctx context.Context, limit int) int {
+    if so.fulfillmentClient == nil || so.fulfillmentRepo == nil {
+        return 0
+    }
+
+    pending, err := so.fulfillmentRepo.ListPendingPushes(ctx, limit)
+    if err != nil {
+        log.Printf("❌ Failed to list pending 3PL pushes: %v", err)
+        return 0
+    }
+
+    pushed := 0
+    for _, push := range pending {
+        order, err := so.orderRepo.GetOrder(ctx, push.OrderID)
+        if err != nil {
+            log.Printf("❌ Failed to load order %d for a 3PL retry: %v", push.OrderID, err)
+            continue
+        }
+        if order.Status == "cancelled" || order.Status == "failed" {
+            if err := so.fulfillmentRepo.DeletePendingPush(ctx, push.OrderID); err != nil {
+                log.Printf("❌ Failed to drop 3PL push of order %d: %v", push.OrderID, err)
+            }
+            continue
+        }
+
+        var payload fulfillment.OrderPayload
+        if err := json.Unmarshal(push.Payload, &payload); err != nil {
+            log.Printf("❌ Bad pending 3PL push of order %d: %v", push.OrderID, err)
+            continue
+        }
+
+        if err := so.fulfillmentClient.PushOrder(ctx, payload); err != nil {
+            log.Printf("⚠️  3PL push of order %d failed again (attempt %d): %v", push.OrderID, push.Attempts+1, err)
+            if err := so.fulfillmentRepo.SavePendingPush(ctx, push.OrderID, push.Payload, err.Error()); err != nil {
+                log.Printf("❌ Failed to record 3PL push attempt of order %d: %v", push.OrderID, err)
+            }
+            continue
+        }
+
+        if err := so.fulfillmentRepo.DeletePendingPush(ctx, push.OrderID); err != nil {
+            // Pushed again on the next run: the same payload, so the 3PL sees the same order_id
+            log.Printf("❌ Failed to clear 3PL push of order %d: %v", push.OrderID, err)
+        }
+        log.Printf("✓ Order %d sent to 3PL after %d failed attempt(s)", push.OrderID, push.Attempts)
+        pushed++
+    }
+
+    return pushed
+}
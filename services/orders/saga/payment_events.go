@@ -0,0 +1,86 @@
+package saga
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// Payment results (payments.events)
+// Why: a declined card shouldn't cost the customer their cart. The order waits in
+// payment_pending with its stock held until the retry window lapses; only then the
+// paymentretry worker fails it, which releases the stock like any other failure.
+
+// handlePaymentFailed moves the order to payment_pending, or fails it right away when retries are disabled
+func (so *SagaOrchestrator) handlePaymentFailed(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.PaymentFailedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal PaymentFailedEvent: %w", err)
+    }
+
+    log.Printf("PaymentFailedEvent received: Order %d, Attempt %d, Reason: %s", event.OrderID, event.Attempt, event.Reason)
+
+    if so.paymentRetryWindow <= 0 {
+        order, err := so.orderRepo.GetOrder(ctx, event.OrderID)
+        if err != nil {
+            return fmt.Errorf("failed to get order: %w", err)
+        }
+        if order.Status != "placed" {
+            log.Printf("Order %d is %s, ignoring PaymentFailed", event.OrderID, order.Status)
+            return nil
+        }
+        return so.publishOrderFailed(ctx, event.CorrelationID, event.OrderID, ReasonPaymentFailed+": "+event.Reason)
+    }
+
+    deadline, ok, err := so.paymentRepo.MarkPaymentPending(ctx, event.OrderID, event.Attempt, event.Reason, so.clock.Now().Add(so.paymentRetryWindow))
+    if err != nil {
+        return err
+    }
+    if !ok {
+        // Cancelled, failed or paid since, or a late result of an attempt that was already retried
+        log.Printf("Order %d is not awaiting payment attempt %d, ignoring PaymentFailed", event.OrderID, event.Attempt)
+        return nil
+    }
+
+    // Products extends the order's reservations to the deadline
+    pendingEvent := events.OrderPaymentPendingEvent{
+        BaseEvent: events.NewBaseEvent("OrderPaymentPending", strconv.FormatInt(event.OrderID, 10), "order", event.CorrelationID),
+        OrderID:   event.OrderID,
+        Reason:    event.Reason,
+        HoldUntil: deadline,
+    }
+    if err := so.eventPublisher.PublishOrderEventReliable(ctx, pendingEvent); err != nil {
+        return fmt.Errorf("failed to publish OrderPaymentPendingEvent: %w", err)
+    }
+
+    log.Printf("✓ Order %d awaiting payment retry until %s", event.OrderID, deadline.Format(time.RFC3339))
+    return nil
+}
+
+// handlePaymentProcessed moves a payment_pending order back to placed after a successful retry
+func (so *SagaOrchestrator) handlePaymentProcessed(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.PaymentProcessedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal PaymentProcessedEvent: %w", err)
+    }
+
+    ok, err := so.paymentRepo.MarkPaymentProcessed(ctx, event.OrderID)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        order, err := so.orderRepo.GetOrder(ctx, event.OrderID)
+        if err == nil && (order.Status == "failed" || order.Status == "cancelled") {
+            // The window lapsed (or the customer cancelled) while the payment went through
+            log.Printf("⚠️  Payment attempt %d processed for %s order %d, it needs a refund", event.Attempt, order.Status, event.OrderID)
+        }
+        return nil
+    }
+
+    log.Printf("✓ Payment attempt %d processed, order %d placed again", event.Attempt, event.OrderID)
+    return nil
+}
@@ -0,0 +1,140 @@
+package saga
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// ReasonReservationTimeout prefixes the failure of a saga whose stock wasn't all reserved in
+// time; it's a timeout, so the saga may be resumed
+const ReasonReservationTimeout = "timeout waiting for StockReserved"
+
+// StartReservationExpiry fails sagas that are still waiting for stock past their deadline,
+// every interval until ctx is cancelled.
+func (so *SagaOrchestrator) StartReservationExpiry(ctx context.Context, interval time.Duration, batchSize int) <-chan struct{} {
+    return clock.Every(ctx, so.clock, interval, false, func(ctx context.Context) {
+        so.ExpireReservations(ctx, so.clock.Now(), batchSize)
+    })
+}
+
+// ExpireReservations fails up to limit sagas past their reservation deadline and returns how
+// many were failed. Every order of the checkout gets OrderFailed, so products releases whatever
+// part of the stock it did reserve.
+// Why: the saga is flipped to failed before publishing so a late StockReserved can't place it,
+// and the next run, or another instance, can't fail it twice
+func (so *SagaOrchestrator) ExpireReservations(ctx context.Context, now time.Time, limit int) int {
+    due, err := so.sagaRepo.ListReservationTimedOut(ctx, now, limit)
+    if err != nil {
+        log.Printf("❌ Failed to list sagas waiting for stock: %v", err)
+        return 0
+    }
+
+    failed := 0
+    for _, correlationID := range due {
+        saga, err := so.sagaRepo.GetSagaState(ctx, correlationID)
+        if err != nil {
+            log.Printf("❌ Failed to load saga %s: %v", correlationID, err)
+            continue
+        }
+        if saga.OrderID == nil {
+            continue
+        }
+        progress, err := payloadReservations(saga.Payload)
+        if err != nil {
+            log.Printf("❌ Saga %s: %v", correlationID, err)
+            continue
+        }
+
+        reason := TimeoutReason(progress)
+        progress, err = so.sagaRepo.FailIfReservationTimedOut(ctx, correlationID, now, reason)
+        if err != nil {
+            log.Printf("❌ Failed to time out saga %s: %v", correlationID, err)
+            continue
+        }
+        if progress == nil {
+            // Reserved or failed since it was listed
+            continue
+        }
+
+        if err := so.failCheckout(ctx, correlationID, *saga.OrderID, reason); err != nil {
+            // Back to waiting so the next run retries instead of leaving the stock held
+            log.Printf("❌ Failed to fail orders of saga %s: %v", correlationID, err)
+            if err := so.sagaRepo.RevertReservationTimeout(ctx, correlationID); err != nil {
+                log.Printf("❌ Failed to revert reservation timeout of saga %s: %v", correlationID, err)
+            }
+            continue
+        }
+
+        log.Printf("⚠️  Saga %s failed: %s", correlationID, reason)
+        failed++
+    }
+
+    return failed
+}
+
+// failCheckout publishes OrderFailed for every order of the checkout that hasn't failed yet
+func (so *SagaOrchestrator) failCheckout(ctx context.Context, correlationID string, orderID int64, reason string) error {
+    orders, err := so.checkoutOrders(ctx, orderID)
+    if err != nil {
+        return err
+    }
+    for _, order := range orders {
+        if order.Status == "failed" || order.Status == "cancelled" {
+            continue
+        }
+        if err := so.publishOrderFailed(ctx, correlationID, order.ID, reason); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// TimeoutReason is the failure reason of a saga that timed out with progress
+func TimeoutReason(progress *models.ReservationProgress) string {
+    missing := progress.Missing()
+    if len(missing) == 0 {
+        return ReasonReservationTimeout
+    }
+    line := missing[0]
+    reason := fmt.Sprintf("%s: order %d product %d reserved %d of %d", ReasonReservationTimeout, line.OrderID, line.ProductID, line.Reserved, line.Expected)
+    if len(missing) > 1 {
+        reason += fmt.Sprintf(" (%d more line(s) short)", len(missing)-1)
+    }
+    return reason
+}
+
+// reservedLines converts the reservations of a StockReserved event
+func reservedLines(eventID string, items []events.ReservedStock) []models.ReservedLine {
+    lines := make([]models.ReservedLine, len(items))
+    for i, item := range items {
+        lines[i] = models.ReservedLine{
+            ProductID:     item.ProductID,
+            VariantID:     item.VariantID,
+            Quantity:      item.Quantity,
+            ReservationID: item.ReservationID,
+            EventID:       eventID,
+        }
+    }
+    return lines
+}
+
+// payloadReservations decodes the reservation progress stored in the saga payload (JSON round trip)
+func payloadReservations(payload map[string]interface{}) (*models.ReservationProgress, error) {
+    raw, err := json.Marshal(payload["reservations"])
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal reservation progress: %w", err)
+    }
+
+    progress := &models.ReservationProgress{}
+    if err := json.Unmarshal(raw, progress); err != nil {
+        return nil, fmt.Errorf("failed to decode reservation progress: %w", err)
+    }
+    return progress, nil
+}
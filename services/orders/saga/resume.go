@@ -0,0 +1,208 @@
+package saga
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/events"
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// Saga step checkpoints, in execution order
+const (
+    StepOrderCreated       = "order_created"
+    StepInventoryRequested = "inventory_requested"
+    StepOrderPlaced        = "order_placed"
+)
+
+// Failure reasons emitted by the saga participants
+const (
+    ReasonOrderCreateFailed = "failed to create order record"
+    ReasonPaymentFailed     = "payment failed"
+)
+
+// MaxResumeAttempts caps how many times a single saga may be resumed
+const MaxResumeAttempts = 3
+
+// retryableReasonPrefixes are failure reasons caused by transient infrastructure
+// problems; resuming is safe because nothing about the order itself is wrong.
+// Business failures (e.g. insufficient inventory, user cancellation) are never resumed.
+var retryableReasonPrefixes = []string{
+    ReasonOrderCreateFailed,
+    "failed to reserve inventory",
+    "failed to publish",
+    "timeout",
+}
+
+var (
+    // ErrSagaNotResumable is returned when the saga state or failure reason forbids resume
+    ErrSagaNotResumable = errors.New("saga not resumable")
+)
+
+// IsRetryableReason reports whether a failure reason allows resume
+func IsRetryableReason(reason string) bool {
+    reason = strings.ToLower(strings.TrimSpace(reason))
+    for _, prefix := range retryableReasonPrefixes {
+        if strings.HasPrefix(reason, prefix) {
+            return true
+        }
+    }
+    return false
+}
+
+// Resume restarts a failed saga from the step after its last checkpoint.
+// Returns the step that was re-executed.
+func (so *SagaOrchestrator) Resume(ctx context.Context, correlationID string) (string, error) {
+    saga, err := so.sagaRepo.GetSagaState(ctx, correlationID)
+    if err != nil {
+        return "", fmt.Errorf("saga not found: %w", err)
+    }
+
+    if err := checkResumable(saga); err != nil {
+        return "", err
+    }
+
+    userID, _ := saga.Payload["user_id"].(string)
+    cartID, _ := saga.Payload["cart_id"].(string)
+    total, _ := saga.Payload["total"].(float64)
+    items, err := payloadItems(saga.Payload)
+    if err != nil {
+        return "", err
+    }
+    shippingAddress, err := payloadShippingAddress(saga.Payload)
+    if err != nil {
+        return "", err
+    }
+
+    switch saga.LastCompletedStep {
+    case "":
+        // Order was never created: run the saga from the start
+        if err := so.sagaRepo.BeginResume(ctx, correlationID, "pending"); err != nil {
+            return "", err
+        }
+        orders, err := so.createOrderStep(ctx, correlationID, userID, cartID, total, items, shippingAddress)
+        if err != nil {
+            return "", err
+        }
+        return StepOrderCreated, so.requestInventoryStep(ctx, correlationID, userID, orders)
+
+    case StepOrderCreated, StepInventoryRequested:
+        // Orders exist; ask products to reserve again
+        if saga.OrderID == nil {
+            return "", fmt.Errorf("%w: checkpoint %s has no order_id", ErrSagaNotResumable, saga.LastCompletedStep)
+        }
+        orders, err := so.checkoutOrders(ctx, *saga.OrderID)
+        if err != nil {
+            return "", err
+        }
+        if err := so.reopenOrders(ctx, correlationID, orders, "order_created"); err != nil {
+            return "", err
+        }
+        return StepInventoryRequested, so.requestInventoryStep(ctx, correlationID, userID, orders)
+
+    case StepOrderPlaced:
+        // Inventory is held; re-announce the placed orders to downstream consumers
+        if saga.OrderID == nil {
+            return "", fmt.Errorf("%w: checkpoint %s has no order_id", ErrSagaNotResumable, saga.LastCompletedStep)
+        }
+        orders, err := so.checkoutOrders(ctx, *saga.OrderID)
+        if err != nil {
+            return "", err
+        }
+        if err := so.sagaRepo.BeginResume(ctx, correlationID, "order_placed"); err != nil {
+            return "", err
+        }
+        for _, order := range orders {
+            if err := so.orderRepo.UpdateOrderStatus(ctx, order.ID, "placed"); err != nil {
+                return "", fmt.Errorf("failed to reopen order: %w", err)
+            }
+            placedEvent := events.OrderPlacedEvent{
+                BaseEvent: events.NewBaseEvent("OrderPlaced", strconv.FormatInt(order.ID, 10), "order", correlationID),
+                OrderID:   order.ID,
+                UserID:    userID,
+                Total:     order.Total,
+                Items:     eventItems(order.Items),
+            }
+            if err := so.eventPublisher.PublishOrderEventReliable(ctx, placedEvent); err != nil {
+                return "", fmt.Errorf("failed to publish OrderPlacedEvent: %w", err)
+            }
+        }
+        return StepOrderPlaced, nil
+
+    default:
+        return "", fmt.Errorf("%w: unknown checkpoint %q", ErrSagaNotResumable, saga.LastCompletedStep)
+    }
+}
+
+// checkResumable applies the resume guard rails
+func checkResumable(saga *models.SagaState) error {
+    if saga.Status != "failed" {
+        return fmt.Errorf("%w: status is %s, only failed sagas can be resumed", ErrSagaNotResumable, saga.Status)
+    }
+
+    reason := ""
+    if saga.FailureReason != nil {
+        reason = *saga.FailureReason
+    }
+    if !IsRetryableReason(reason) {
+        return fmt.Errorf("%w: failure reason %q is not retryable", ErrSagaNotResumable, reason)
+    }
+
+    if saga.RetryCount >= MaxResumeAttempts {
+        return fmt.Errorf("%w: already resumed %d times", ErrSagaNotResumable, saga.RetryCount)
+    }
+
+    return nil
+}
+
+// reopenOrders flips saga and orders back from failed before re-running a step
+func (so *SagaOrchestrator) reopenOrders(ctx context.Context, correlationID string, orders []*models.Order, sagaStatus string) error {
+    if err := so.sagaRepo.BeginResume(ctx, correlationID, sagaStatus); err != nil {
+        return err
+    }
+    for _, order := range orders {
+        if err := so.orderRepo.UpdateOrderStatus(ctx, order.ID, "pending"); err != nil {
+            return fmt.Errorf("failed to reopen order: %w", err)
+        }
+        log.Printf("✓ Saga %s resumed for order %d", correlationID, order.ID)
+    }
+    return nil
+}
+
+// payloadItems decodes the items stored in the saga payload (JSON round trip)
+func payloadItems(payload map[string]interface{}) ([]sharedmodels.OrderItem, error) {
+    raw, err := json.Marshal(payload["items"])
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal saga items: %w", err)
+    }
+
+    var items []sharedmodels.OrderItem
+    if err := json.Unmarshal(raw, &items); err != nil {
+        return nil, fmt.Errorf("failed to decode saga items: %w", err)
+    }
+    return items, nil
+}
+
+// payloadShippingAddress decodes the saga payload's shipping address; nil for sagas started
+// before addresses
+func payloadShippingAddress(payload map[string]interface{}) (*models.ShippingAddress, error) {
+    if payload["shipping_address"] == nil {
+        return nil, nil
+    }
+    raw, err := json.Marshal(payload["shipping_address"])
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal saga shipping address: %w", err)
+    }
+
+    var address models.ShippingAddress
+    if err := json.Unmarshal(raw, &address); err != nil {
+        return nil, fmt.Errorf("failed to decode saga shipping address: %w", err)
+    }
+    return &address, nil
+}
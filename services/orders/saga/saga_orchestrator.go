@@ -2,59 +2,90 @@ package saga
 
 import (
     "context"
-    "encoding/json"
+    "errors"
     "fmt"
     "log"
+    "math"
     "strconv"
+    "time"
 
     "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/orders/fulfillment"
     "github.com/sanketh-sg/prost/services/orders/models"
     sharedmodels "github.com/sanketh-sg/prost/shared/models"
     "github.com/sanketh-sg/prost/services/orders/repository"
-    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/services/orders/routing"
+    "github.com/sanketh-sg/prost/services/orders/tax"
+    "github.com/sanketh-sg/prost/shared/clock"
     "github.com/sanketh-sg/prost/shared/events"
-    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/metrics"
 )
 
 // SagaOrchestrator orchestrates order creation saga
 type SagaOrchestrator struct {
-    orderRepo         *repository.OrderRepository
-    sagaRepo          *repository.SagaStateRepository
-    compensationRepo  *repository.CompensationLogRepository
-    inventoryResRepo  *repository.InventoryReservationRepository
-    idempotencyStore  *db.IdempotencyStore
-    eventPublisher    *messaging.Publisher
+    orderRepo         repository.OrderRepositoryInterface
+    checkoutRepo      CheckoutStore
+    sagaRepo          repository.SagaStateRepositoryInterface
+    compensationRepo  repository.CompensationLogRepositoryInterface
+    inventoryResRepo  repository.InventoryReservationRepositoryInterface
+    idempotencyStore  IdempotencyStore
+    eventPublisher    Publisher
+    fulfillmentClient *fulfillment.Client // nil when no 3PL is configured
+    routingClient     *routing.Client     // nil when checkouts aren't split
+    taxCalculator     tax.Calculator
+    paymentRepo       PaymentStore
+    holdRepo          HoldStore
+    fulfillmentRepo   FulfillmentStore
+    paymentRetryWindow time.Duration // how long a failed payment may be retried; 0 fails the order at once
+    reservationTimeout time.Duration // how long orders wait for all their stock before the saga fails
+    clock              clock.Clock
 }
 
 // NewSagaOrchestrator creates new saga orchestrator
 func NewSagaOrchestrator(
-    orderRepo *repository.OrderRepository,
-    sagaRepo *repository.SagaStateRepository,
-    compensationRepo *repository.CompensationLogRepository,
-    inventoryResRepo *repository.InventoryReservationRepository,
-    idempotencyStore *db.IdempotencyStore,
-    eventPublisher *messaging.Publisher,
+    orderRepo repository.OrderRepositoryInterface,
+    checkoutRepo CheckoutStore,
+    sagaRepo repository.SagaStateRepositoryInterface,
+    compensationRepo repository.CompensationLogRepositoryInterface,
+    inventoryResRepo repository.InventoryReservationRepositoryInterface,
+    idempotencyStore IdempotencyStore,
+    eventPublisher Publisher,
+    fulfillmentClient *fulfillment.Client,
+    routingClient *routing.Client,
+    taxCalculator tax.Calculator,
+    paymentRepo PaymentStore,
+    holdRepo HoldStore,
+    fulfillmentRepo FulfillmentStore,
+    paymentRetryWindow time.Duration,
+    reservationTimeout time.Duration,
+    clk clock.Clock,
 ) *SagaOrchestrator {
     return &SagaOrchestrator{
-        orderRepo:        orderRepo,
-        sagaRepo:         sagaRepo,
-        compensationRepo: compensationRepo,
-        inventoryResRepo: inventoryResRepo,
-        idempotencyStore: idempotencyStore,
-        eventPublisher:   eventPublisher,
+        orderRepo:         orderRepo,
+        checkoutRepo:      checkoutRepo,
+        sagaRepo:          sagaRepo,
+        compensationRepo:  compensationRepo,
+        inventoryResRepo:  inventoryResRepo,
+        idempotencyStore:  idempotencyStore,
+        eventPublisher:    eventPublisher,
+        fulfillmentClient: fulfillmentClient,
+        routingClient:     routingClient,
+        taxCalculator:     taxCalculator,
+        paymentRepo:       paymentRepo,
+        holdRepo:          holdRepo,
+        fulfillmentRepo:   fulfillmentRepo,
+        paymentRetryWindow: paymentRetryWindow,
+        reservationTimeout: reservationTimeout,
+        clock:              clk,
     }
 }
 
 // HandleEvent processes incoming events for saga
 func (so *SagaOrchestrator) HandleEvent(ctx context.Context, message []byte) error {
     // Extract event type
-    var baseEvent struct {
-        EventID   string `json:"event_id"`
-        EventType string `json:"event_type"`
-    }
-
-    if err := json.Unmarshal(message, &baseEvent); err != nil {
-        return fmt.Errorf("failed to unmarshal base event: %w", err)
+    baseEvent, err := events.ReadEnvelope(message)
+    if err != nil {
+        return err
     }
 
     eventID := baseEvent.EventID
@@ -71,6 +102,13 @@ func (so *SagaOrchestrator) HandleEvent(ctx context.Context, message []byte) err
         return nil
     }
 
+    // Status changes made while handling the event are attributed to it (saga_transitions)
+    ctx = repository.WithTransitionCause(ctx, models.TransitionCause{
+        EventID:      eventID,
+        EventType:    eventType,
+        ActorService: eventSource(eventType),
+    })
+
     // Route to handler based on event type
     var handlerErr error
 
@@ -79,14 +117,28 @@ func (so *SagaOrchestrator) HandleEvent(ctx context.Context, message []byte) err
         handlerErr = so.handleCartCheckoutInitiated(ctx, message)
     case "StockReserved":
         handlerErr = so.handleStockReserved(ctx, message)
+    case "StockReservationFailed":
+        handlerErr = so.handleStockReservationFailed(ctx, message)
     case "StockReleased":
         handlerErr = so.handleStockReleased(ctx, message)
+    case "OrderPlaced":
+        handlerErr = so.handleOrderPlaced(ctx, message)
     case "OrderConfirmed":
         handlerErr = so.handleOrderConfirmed(ctx, message)
     case "OrderFailed":
         handlerErr = so.handleOrderFailed(ctx, message)
     case "OrderCancelled":
         handlerErr = so.handleOrderCancelled(ctx, message)
+    case "OrderShipped":
+        handlerErr = so.handleOrderShipped(ctx, message)
+    case "OrderDelivered":
+        handlerErr = so.handleOrderDelivered(ctx, message)
+    case "PaymentFailed":
+        handlerErr = so.handlePaymentFailed(ctx, message)
+    case "PaymentProcessed":
+        handlerErr = so.handlePaymentProcessed(ctx, message)
+    case "UserDeactivated":
+        handlerErr = so.handleUserDeactivated(ctx, message)
     default:
         log.Printf("Unknown event type: %s", eventType)
         return nil
@@ -105,10 +157,31 @@ func (so *SagaOrchestrator) HandleEvent(ctx context.Context, message []byte) err
     return handlerErr
 }
 
+// eventSource returns the service that publishes eventType
+// Why: events don't carry their publisher; the routing key names the aggregate, and order
+// events come from several services
+func eventSource(eventType string) string {
+    switch eventType {
+    case "CartCheckoutInitiated":
+        return "cart"
+    case "StockReserved", "StockReservationFailed", "StockReleased":
+        return "products"
+    case "OrderShipped", "OrderDelivered":
+        // Also published by orders for 3PL shipments, which shipping tracks all the same
+        return "shipping"
+    case "PaymentProcessed", "PaymentFailed":
+        return "payments"
+    case "UserDeactivated":
+        return "users"
+    default:
+        return models.ActorOrders
+    }
+}
+
 // handleCartCheckoutInitiated handles CartCheckoutInitiatedEvent (saga initiator)
 func (so *SagaOrchestrator) handleCartCheckoutInitiated(ctx context.Context, message []byte) error {
-    var event events.CartCheckoutInitiatedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.CartCheckoutInitiatedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal CartCheckoutInitiatedEvent: %w", err)
     }
 
@@ -123,80 +196,208 @@ func (so *SagaOrchestrator) handleCartCheckoutInitiated(ctx context.Context, mes
         saga = models.NewSagaState(event.CartID, event.UserID, correlationID)
         saga.Payload["items"] = event.Items
         saga.Payload["total"] = event.Total
+        saga.Payload["user_id"] = event.UserID
+        saga.Payload["cart_id"] = event.CartID
+        if event.ShippingAddress != nil {
+            saga.Payload["shipping_address"] = event.ShippingAddress
+        }
+        if event.CouponCode != "" {
+            saga.Payload["coupon_code"] = event.CouponCode
+            saga.Payload["discount"] = event.Discount
+        }
 
         if err := so.sagaRepo.CreateSagaState(ctx, saga); err != nil {
             return fmt.Errorf("failed to create saga state: %w", err)
         }
     }
 
-    // Step 1: Create order (pending state)
-    // orderID := int64(uuid.New().ID()[:8])
-	orderID := int64(uuid.New().ID())
+    // Step 1: Create the checkout and its orders (pending state)
+    var shippingAddress *models.ShippingAddress
+    if event.ShippingAddress != nil {
+        address := models.ShippingAddress(*event.ShippingAddress)
+        shippingAddress = &address
+    }
+    orders, err := so.createOrderStep(ctx, correlationID, event.UserID, event.CartID, event.Total, event.Items, shippingAddress)
+    if err != nil {
+        return err
+    }
 
-    order := models.NewOrder(event.UserID, event.CartID, orderID, event.Total, correlationID)
-    order.Status = "pending"
+    // Step 2: Publish OrderCreatedEvent per order (triggers inventory reservation in products service)
+    return so.requestInventoryStep(ctx, correlationID, event.UserID, orders)
+}
 
-    if err := so.orderRepo.CreateOrder(ctx, order); err != nil {
-        log.Printf("Failed to create order: %v", err)
-        // Publish OrderFailedEvent to trigger compensation
-        failedEvent := events.OrderFailedEvent{
-            BaseEvent: events.NewBaseEvent("OrderFailed", strconv.FormatInt(orderID, 10), "order", correlationID),
-            OrderID:   strconv.FormatInt(orderID, 10),
-            Reason:    "failed to create order record",
+// createOrderStep creates the checkout with one pending order per warehouse and fulfillment
+// type, taxes added, and checkpoints StepOrderCreated. The saga's order_id is the first order;
+// the others are found through its checkout. The checkout's total includes the tax.
+// Every order ships to the same copy of the checkout's address.
+func (so *SagaOrchestrator) createOrderStep(ctx context.Context, correlationID, userID, cartID string, total float64, items []sharedmodels.OrderItem, shippingAddress *models.ShippingAddress) ([]*models.Order, error) {
+    checkout := models.NewCheckout(int64(uuid.New().ID()), userID, cartID, total, correlationID)
+    var checkoutTotal float64
+
+    for _, group := range so.splitItems(ctx, items, total) {
+        order := models.NewOrder(userID, cartID, int64(uuid.New().ID()), group.Total, correlationID)
+        order.Status = "pending"
+        order.ShippingAddress = shippingAddress
+        if group.Route != (routing.Route{}) {
+            warehouse, fulfillmentType := group.Route.Warehouse, group.Route.FulfillmentType
+            order.Warehouse = &warehouse
+            order.FulfillmentType = &fulfillmentType
         }
-        if pubErr := so.eventPublisher.PublishOrderEvent(ctx, failedEvent); pubErr != nil {
-            log.Printf("Failed to publish OrderFailedEvent: %v", pubErr)
+        // Line items are stored with the order (used by order detail, OrderPlacedEvent and admin reporting)
+        for _, item := range group.Items {
+            order.Items = append(order.Items, models.OrderItem{
+                ProductID: item.ProductID,
+                VariantID: item.VariantID,
+                Quantity:  item.Quantity,
+                Price:     item.Price,
+            })
         }
-        return err
+        if err := so.applyTax(ctx, order); err != nil {
+            log.Printf("Failed to calculate tax: %v", err)
+            return nil, so.failOrderCreation(ctx, correlationID, order.ID, err)
+        }
+        checkoutTotal += order.Total
+        checkout.Orders = append(checkout.Orders, order)
+    }
+    checkout.Total = math.Round(checkoutTotal*100) / 100
+
+    orderID := checkout.Orders[0].ID
+    if err := so.checkoutRepo.CreateCheckout(ctx, checkout); err != nil {
+        log.Printf("Failed to create checkout: %v", err)
+        return nil, so.failOrderCreation(ctx, correlationID, orderID, err)
     }
 
-    log.Printf("Order created: %d", orderID)
+    log.Printf("Checkout created: %d (%d order(s), %d items)", checkout.ID, len(checkout.Orders), len(items))
 
     // Update saga with order ID
     if err := so.sagaRepo.UpdateSagaOrderID(ctx, correlationID, orderID); err != nil {
         log.Printf("Failed to update saga with order_id: %v", err)
-        return fmt.Errorf("failed to update saga status: %w", err)
+        return nil, fmt.Errorf("failed to update saga status: %w", err)
     }
 
     // Update saga status to order_created
     if err := so.sagaRepo.UpdateSagaStatus(ctx, correlationID, "order_created"); err != nil {
         log.Printf("Failed to update saga status: %v", err)
-        return fmt.Errorf("failed to update saga status: %w", err)
+        return nil, fmt.Errorf("failed to update saga status: %w", err)
     }
 
-    // Step 2: Publish OrderPlacedEvent (triggers inventory reservation in products service)
-    orderCreatedEvent := events.OrderCreatedEvent{
-        BaseEvent: events.NewBaseEvent("OrderCreated", strconv.FormatInt(orderID, 10), "order", correlationID),
-        OrderID:   orderID,
-        UserID:    event.UserID,
-        Total:     event.Total,
-        Items:     event.Items,
+    so.checkpoint(ctx, correlationID, StepOrderCreated)
+    return checkout.Orders, nil
+}
+
+// applyTax adds the order's taxes to its total. The tax region is the warehouse the order
+// ships from; an order without a route is taxed as region "".
+func (so *SagaOrchestrator) applyTax(ctx context.Context, order *models.Order) error {
+    if so.taxCalculator == nil {
+        return nil
+    }
+
+    region := ""
+    if order.Warehouse != nil {
+        region = *order.Warehouse
+    }
+    lines, err := so.taxCalculator.Calculate(ctx, region, order.Total)
+    if err != nil {
+        return fmt.Errorf("failed to calculate tax for order %d: %w", order.ID, err)
     }
+    order.ApplyTax(lines, tax.Total(lines))
+    return nil
+}
 
-    if err := so.eventPublisher.PublishOrderEvent(ctx, orderCreatedEvent); err != nil {
-        log.Printf("Failed to publish OrderCreatedEvent: %v", err)
+// failOrderCreation publishes OrderFailedEvent for a checkout whose orders couldn't be
+// created, to trigger compensation, and returns err
+func (so *SagaOrchestrator) failOrderCreation(ctx context.Context, correlationID string, orderID int64, err error) error {
+    failedEvent := events.OrderFailedEvent{
+        BaseEvent: events.NewBaseEvent("OrderFailed", strconv.FormatInt(orderID, 10), "order", correlationID),
+        OrderID:   strconv.FormatInt(orderID, 10),
+        Reason:    ReasonOrderCreateFailed,
+    }
+    if pubErr := so.eventPublisher.PublishOrderEventReliable(ctx, failedEvent); pubErr != nil {
+        log.Printf("Failed to publish OrderFailedEvent: %v", pubErr)
+    }
+    return err
+}
+
+// splitItems groups the checkout items by warehouse and fulfillment type
+// Why: without routing data the checkout still goes through, as a single order with no route
+func (so *SagaOrchestrator) splitItems(ctx context.Context, items []sharedmodels.OrderItem, total float64) []routing.Group {
+    single := []routing.Group{{Items: items, Total: total}}
+    if so.routingClient == nil {
+        return single
+    }
+
+    routes, err := routing.Resolve(ctx, so.routingClient, items)
+    if err != nil {
+        log.Printf("⚠️  Failed to resolve item routes, not splitting checkout: %v", err)
+        return single
+    }
+    return routing.Split(items, routes, total)
+}
+
+// requestInventoryStep publishes OrderCreatedEvent for every order and checkpoints StepInventoryRequested
+// Why: products reserves each order on its own, so a split checkout gets one reservation per order.
+// What the saga expects is stored first, so no StockReserved can arrive before it.
+func (so *SagaOrchestrator) requestInventoryStep(ctx context.Context, correlationID, userID string, orders []*models.Order) error {
+    progress := models.NewReservationProgress(orders, so.clock.Now().Add(so.reservationTimeout))
+    if err := so.sagaRepo.StartReservationTracking(ctx, correlationID, progress); err != nil {
+        log.Printf("Failed to start reservation tracking: %v", err)
         return err
     }
 
-    log.Printf("OrderCreatedEvent published for order: %d", orderID)
+    for _, order := range orders {
+        orderCreatedEvent := events.OrderCreatedEvent{
+            BaseEvent: events.NewBaseEvent("OrderCreated", strconv.FormatInt(order.ID, 10), "order", correlationID),
+            OrderID:   order.ID,
+            UserID:    userID,
+            TaxTotal:  order.TaxTotal,
+            TaxLines:  order.TaxLines,
+            Total:     order.Total,
+            Items:     eventItems(order.Items),
+            CartID:    order.CartID,
+        }
+        if order.Subtotal != nil {
+            orderCreatedEvent.Subtotal = *order.Subtotal
+        }
+
+        if err := so.eventPublisher.PublishOrderEventReliable(ctx, orderCreatedEvent); err != nil {
+            log.Printf("Failed to publish OrderCreatedEvent: %v", err)
+            return err
+        }
+
+        log.Printf("OrderCreatedEvent published for order: %d", order.ID)
+    }
+
     // Update saga to waiting for inventory
     if err := so.sagaRepo.UpdateSagaStatus(ctx, correlationID, "checking_inventory"); err != nil {
         log.Printf("Failed to update saga status: %v", err)
         return fmt.Errorf("failed to update saga status: %w", err)
     }
 
+    so.checkpoint(ctx, correlationID, StepInventoryRequested)
     return nil
 }
 
+// checkoutOrders returns every order of the checkout orderID belongs to, or just the order
+// when it was created before checkouts were split
+func (so *SagaOrchestrator) checkoutOrders(ctx context.Context, orderID int64) ([]*models.Order, error) {
+    order, err := so.orderRepo.GetOrder(ctx, orderID)
+    if err != nil {
+        return nil, err
+    }
+    if order.CheckoutID == nil {
+        return []*models.Order{order}, nil
+    }
+    return so.checkoutRepo.GetCheckoutOrders(ctx, *order.CheckoutID)
+}
+
 // handleStockReserved handles StockReservedEvent (saga step 2)
 func (so *SagaOrchestrator) handleStockReserved(ctx context.Context, message []byte) error {
-    var event events.StockReservedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.StockReservedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal StockReservedEvent: %w", err)
     }
 
-    log.Printf("StockReservedEvent received: Product %d, Quantity %d, Reservation %s", 
-        event.ProductID, event.Quantity, event.ReservationID)
+    log.Printf("StockReservedEvent received: Order %d, %d item(s)", event.OrderID, len(event.Items))
 
     // Get saga to check inventory status
     saga, err := so.sagaRepo.GetSagaState(ctx, event.CorrelationID)
@@ -204,10 +405,22 @@ func (so *SagaOrchestrator) handleStockReserved(ctx context.Context, message []b
         return fmt.Errorf("saga not found: %s", event.CorrelationID)
     }
 
+    orders, err := so.checkoutOrders(ctx, event.OrderID)
+    if err != nil {
+        return err
+    }
 
-    // Create inventory reservation in orders schema
-    if event.OrderID > 0 {
-        res := models.NewInventoryReservation(event.OrderID, event.ProductID, event.Quantity, event.ReservationID)
+    // Why: another order of the checkout already failed, so products must release this stock too
+    for _, order := range orders {
+        if order.Status == "failed" || order.Status == "cancelled" {
+            log.Printf("Order %d reserved after order %d was %s, failing it", event.OrderID, order.ID, order.Status)
+            return so.publishOrderFailed(ctx, event.CorrelationID, event.OrderID, "checkout failed: order "+strconv.FormatInt(order.ID, 10)+" was "+order.Status)
+        }
+    }
+
+    // Create inventory reservations in orders schema
+    for _, item := range event.Items {
+        res := models.NewInventoryReservation(event.OrderID, item.ProductID, item.Quantity, item.ReservationID)
         if err := so.inventoryResRepo.CreateReservation(ctx, res); err != nil {
             log.Printf("Failed to create inventory reservation: %v", err)
         }
@@ -218,9 +431,9 @@ func (so *SagaOrchestrator) handleStockReserved(ctx context.Context, message []b
             event.CorrelationID,
             "StockReleased",
             map[string]interface{}{
-                "reservation_id": event.ReservationID,
-                "product_id":     event.ProductID,
-                "quantity":       event.Quantity,
+                "reservation_id": item.ReservationID,
+                "product_id":     item.ProductID,
+                "quantity":       item.Quantity,
             },
         )
         if err := so.compensationRepo.CreateCompensationLog(ctx, compensation); err != nil {
@@ -228,48 +441,121 @@ func (so *SagaOrchestrator) handleStockReserved(ctx context.Context, message []b
         }
     }
 
-    // Get order to transition to placed
-    orderID, ok := saga.Payload["order_id"].(int64)
-    if !ok {
-        return fmt.Errorf("order_id not found in saga")
+    // Count the reserved quantities; orders are placed once every line of the checkout has its stock
+    progress, err := so.sagaRepo.RecordReservedStock(ctx, event.CorrelationID, event.OrderID, reservedLines(event.EventID, event.Items))
+    if errors.Is(err, repository.ErrSagaFailed) {
+        // Timed out: every order was failed, which releases this stock too
+        log.Printf("Order %d reserved after saga %s failed, ignoring", event.OrderID, event.CorrelationID)
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if progress != nil && !progress.Complete() {
+        log.Printf("Order %d reserved, saga %s still waiting for %d line(s)", event.OrderID, event.CorrelationID, len(progress.Missing()))
+        return nil
     }
-    // Update it to order placed
-    if err := so.orderRepo.UpdateOrderStatus(ctx, orderID, "placed"); err != nil {
+
+    // Update it to order placed; a split checkout is placed once all its orders have stock
+    placed := []int64{event.OrderID}
+    if checkoutID := orders[0].CheckoutID; checkoutID != nil {
+        placed, err = so.checkoutRepo.PlaceCheckoutIfReserved(ctx, *checkoutID)
+        if err != nil {
+            log.Printf("Failed to place checkout %d: %v", *checkoutID, err)
+            return err
+        }
+        if len(placed) == 0 {
+            log.Printf("Order %d reserved, checkout %d still waiting for inventory", event.OrderID, *checkoutID)
+            return nil
+        }
+    } else if err := so.orderRepo.UpdateOrderStatus(ctx, event.OrderID, "placed"); err != nil {
         log.Printf("Failed to update order status to placed: %v", err)
         return err
     }
 
-    log.Printf("Order transitioned to PLACED: %d (all inventory reserved)", orderID)
+    // Step 3: Publish OrderPlacedEvent (now order is officially placed with confirmed inventory)
+    for _, orderID := range placed {
+        log.Printf("Order transitioned to PLACED: %d (all inventory reserved)", orderID)
 
+        order, err := so.orderRepo.GetOrder(ctx, orderID)
+        if err != nil {
+            return err
+        }
+        orderPlacedEvent := events.OrderPlacedEvent{
+            BaseEvent: events.NewBaseEvent("OrderPlaced", strconv.FormatInt(orderID, 10), "order", event.CorrelationID),
+            OrderID:   orderID,
+            UserID:    order.UserID,
+            Total:     order.Total,
+            Items:     eventItems(order.Items),
+        }
 
-    // Step 3: Publish OrderPlacedEvent (now order is officially placed with confirmed inventory)
-    orderPlacedEvent := events.OrderPlacedEvent{
-        BaseEvent: events.NewBaseEvent("OrderPlaced", strconv.FormatInt(orderID, 10), "order", event.CorrelationID),
-        OrderID:   orderID,
-        UserID:    saga.Payload["user_id"].(string),
-        Total:     saga.Payload["total"].(float64),
-        Items:     saga.Payload["items"].([]sharedmodels.OrderItem),
-    }
+        if err := so.eventPublisher.PublishOrderEventReliable(ctx, orderPlacedEvent); err != nil {
+            log.Printf("Failed to publish OrderPlacedEvent: %v", err)
+        }
 
-    if err := so.eventPublisher.PublishOrderEvent(ctx, orderPlacedEvent); err != nil {
-        log.Printf("Failed to publish OrderPlacedEvent: %v", err)
+        log.Printf("✓ OrderPlacedEvent published: %d", orderID)
     }
 
-    log.Printf("✓ OrderPlacedEvent published: %d", orderID)
-
     // Update saga status
     if err := so.sagaRepo.UpdateSagaStatus(ctx, event.CorrelationID, "order_placed"); err != nil {
         log.Printf("Failed to update saga status: %v", err)
     }
+    so.checkpoint(ctx, event.CorrelationID, StepOrderPlaced)
 
     return nil
 }
 
 
+// handleStockReservationFailed handles StockReservationFailedEvent
+// Why: the products service held nothing for the order, so failing it is all that's left;
+// OrderFailed marks the order and saga failed here and lets the cart release its locks
+func (so *SagaOrchestrator) handleStockReservationFailed(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.StockReservationFailedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal StockReservationFailedEvent: %w", err)
+    }
+
+    log.Printf("StockReservationFailedEvent received: Order %d, Reason: %s", event.OrderID, event.Reason)
+
+    if err := so.publishOrderFailed(ctx, event.CorrelationID, event.OrderID, event.Reason); err != nil {
+        return err
+    }
+
+    // A split checkout is all or nothing: fail the other orders so their stock is released
+    orders, err := so.checkoutOrders(ctx, event.OrderID)
+    if err != nil {
+        log.Printf("⚠️  Failed to load checkout of order %d: %v", event.OrderID, err)
+        return nil
+    }
+    for _, order := range orders {
+        if order.ID == event.OrderID || order.Status == "failed" || order.Status == "cancelled" {
+            continue
+        }
+        if err := so.publishOrderFailed(ctx, event.CorrelationID, order.ID, event.Reason); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// publishOrderFailed publishes OrderFailedEvent for one order
+func (so *SagaOrchestrator) publishOrderFailed(ctx context.Context, correlationID string, orderID int64, reason string) error {
+    failedEvent := events.OrderFailedEvent{
+        BaseEvent: events.NewBaseEvent("OrderFailed", strconv.FormatInt(orderID, 10), "order", correlationID),
+        OrderID:   strconv.FormatInt(orderID, 10),
+        Reason:    reason,
+    }
+    if err := so.eventPublisher.PublishOrderEventReliable(ctx, failedEvent); err != nil {
+        return fmt.Errorf("failed to publish OrderFailedEvent: %w", err)
+    }
+    return nil
+}
+
 // handleStockReleased handles StockReleasedEvent (saga compensation)
 func (so *SagaOrchestrator) handleStockReleased(ctx context.Context, message []byte) error {
-    var event events.StockReleasedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.StockReleasedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal StockReleasedEvent: %w", err)
     }
 
@@ -284,11 +570,51 @@ func (so *SagaOrchestrator) handleStockReleased(ctx context.Context, message []b
 }
 
 
+// handleOrderPlaced handles OrderPlacedEvent
+// Why: once inventory is held the warehouse/3PL can start picking; their shipment
+// callback (POST /webhooks/fulfillment/shipments) advances the order to shipped
+func (so *SagaOrchestrator) handleOrderPlaced(ctx context.Context, message []byte) error {
+    if so.fulfillmentClient == nil {
+        return nil
+    }
+
+    event, err := events.Unmarshal[events.OrderPlacedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal OrderPlacedEvent: %w", err)
+    }
+
+    // OrderPlaced doesn't carry the shipping address; the order has the copy taken at checkout
+    order, err := so.orderRepo.GetOrder(ctx, event.OrderID)
+    if err != nil {
+        return fmt.Errorf("failed to get order: %w", err)
+    }
+
+    payload := fulfillment.OrderPayload{
+        OrderID:         event.OrderID,
+        UserID:          event.UserID,
+        CorrelationID:   event.CorrelationID,
+        Items:           event.Items,
+        Total:           event.Total,
+        ShippingAddress: (*sharedmodels.Address)(order.ShippingAddress),
+        PlacedAt:        event.Timestamp,
+        ShipBy:          event.Timestamp.Add(so.fulfillmentClient.SLA()),
+    }
+
+    if err := so.fulfillmentClient.PushOrder(ctx, payload); err != nil {
+        // Recorded for the fulfillment retry worker, so the event isn't the only copy
+        log.Printf("❌ Failed to push order %d to 3PL, will retry: %v", event.OrderID, err)
+        return so.deferPush(ctx, payload, err)
+    }
+
+    log.Printf("✓ Order %d sent to 3PL (ship by %s)", event.OrderID, payload.ShipBy.Format("2006-01-02 15:04"))
+    return nil
+}
+
 // handleOrderConfirmed handles OrderConfirmedEvent (saga step 3 - confirmation)
 // Why: When all items are confirmed and payment succeeds, mark saga as completed
 func (so *SagaOrchestrator) handleOrderConfirmed(ctx context.Context, message []byte) error {
-    var event events.OrderConfirmedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderConfirmedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderConfirmedEvent: %w", err)
     }
 
@@ -302,6 +628,29 @@ func (so *SagaOrchestrator) handleOrderConfirmed(ctx context.Context, message []
 
     log.Printf("Order status updated to confirmed: %d", event.OrderID)
 
+    orders, err := so.checkoutOrders(ctx, event.OrderID)
+    if err != nil {
+        log.Printf("⚠️  Failed to load order %d for revenue metric: %v", event.OrderID, err)
+    }
+
+    // Funnel metrics; the correlation ID is the exemplar linking to the saga's logs
+    // The saga completes once every order of the checkout is confirmed (or further along)
+    waiting := 0
+    for _, order := range orders {
+        if order.ID == event.OrderID {
+            metrics.Add(metrics.RevenueConfirmed, order.Total, event.CorrelationID)
+        }
+        switch order.Status {
+        case "confirmed", "shipped", "delivered", "cancelled":
+        default:
+            waiting++
+        }
+    }
+    if waiting > 0 {
+        log.Printf("Order %d confirmed, checkout still waiting for %d order(s)", event.OrderID, waiting)
+        return nil
+    }
+
     // Update saga status to "completed"
     if err := so.sagaRepo.UpdateSagaStatus(ctx, event.CorrelationID, "completed"); err != nil {
         log.Printf("Failed to update saga status to completed: %v", err)
@@ -309,6 +658,7 @@ func (so *SagaOrchestrator) handleOrderConfirmed(ctx context.Context, message []
     }
 
     log.Printf("✓ Saga completed for order: %d", event.OrderID)
+    metrics.Inc(metrics.SagasCompleted, event.CorrelationID)
 
     return nil
 }
@@ -316,8 +666,8 @@ func (so *SagaOrchestrator) handleOrderConfirmed(ctx context.Context, message []
 // handleOrderFailed handles OrderFailedEvent (saga failure/compensation)
 // Why: When order fails at any step, release reserved inventory and mark order as failed
 func (so *SagaOrchestrator) handleOrderFailed(ctx context.Context, message []byte) error {
-    var event events.OrderFailedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderFailedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderFailedEvent: %w", err)
     }
 
@@ -330,13 +680,19 @@ func (so *SagaOrchestrator) handleOrderFailed(ctx context.Context, message []byt
         event.OrderID, event.Reason, event.CorrelationID)
 
     // Update order status to "failed"
-    if err := so.orderRepo.UpdateOrderStatus(ctx, orderID, "failed"); err != nil {
+    // Why: when the checkout couldn't be created the order doesn't exist, but the saga must
+    // still be marked failed so it can be resumed
+    err = so.orderRepo.UpdateOrderStatus(ctx, orderID, "failed")
+    switch {
+    case errors.Is(err, repository.ErrOrderNotFound):
+        log.Printf("Order %d was never created, failing saga only", orderID)
+    case err != nil:
         log.Printf("Failed to update order status to failed: %v", err)
         return fmt.Errorf("failed to update order status: %w", err)
+    default:
+        log.Printf("✓ Order status updated to failed: %d", orderID)
     }
 
-    log.Printf("✓ Order status updated to failed: %d", orderID)
-
     // Get all compensation logs for this order
     compensationLogs, err := so.compensationRepo.GetCompensationLogsByOrderID(ctx, orderID)
     if err != nil {
@@ -352,13 +708,14 @@ func (so *SagaOrchestrator) handleOrderFailed(ctx context.Context, message []byt
         // via StockReleasedEvent from order failure
     }
 
-    // Update saga status to "failed"
-    if err := so.sagaRepo.UpdateSagaStatus(ctx, event.CorrelationID, "failed"); err != nil {
+    // Update saga status to "failed", keeping the reason so resume can decide if it's retryable
+    if err := so.sagaRepo.MarkSagaFailed(ctx, event.CorrelationID, event.Reason); err != nil {
         log.Printf("Failed to update saga status to failed: %v", err)
         return fmt.Errorf("failed to update saga status: %w", err)
     }
 
     log.Printf("✓ Saga marked as failed for order: %d, Reason: %s", orderID, event.Reason)
+    metrics.Inc(metrics.SagasFailed.WithLabelValues(FailureCategory(event.Reason)), event.CorrelationID)
 
     return nil
 }
@@ -366,8 +723,8 @@ func (so *SagaOrchestrator) handleOrderFailed(ctx context.Context, message []byt
 // handleOrderCancelled handles OrderCancelledEvent (saga cancellation)
 // Why: When user/admin cancels order, release reserved inventory and mark order as cancelled
 func (so *SagaOrchestrator) handleOrderCancelled(ctx context.Context, message []byte) error {
-    var event events.OrderCancelledEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderCancelledEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderCancelledEvent: %w", err)
     }
 
@@ -411,4 +768,19 @@ func (so *SagaOrchestrator) handleOrderCancelled(ctx context.Context, message []
     log.Printf("aga marked as cancelled for order: %d, Reason: %s", orderID, event.Reason)
 
     return nil
-}
\ No newline at end of file
+}
+// checkpoint records the last completed step; failures only cost resumability, so they're logged
+func (so *SagaOrchestrator) checkpoint(ctx context.Context, correlationID, step string) {
+    if err := so.sagaRepo.RecordCheckpoint(ctx, correlationID, step); err != nil {
+        log.Printf("⚠️  Failed to record saga checkpoint %s: %v", step, err)
+    }
+}
+
+// eventItems converts stored order items for events
+func eventItems(items []models.OrderItem) []sharedmodels.OrderItem {
+    converted := make([]sharedmodels.OrderItem, len(items))
+    for i, item := range items {
+        converted[i] = sharedmodels.OrderItem(item)
+    }
+    return converted
+}
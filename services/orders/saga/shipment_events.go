@@ -0,0 +1,55 @@
+package saga
+
+import (
+    "context"
+    "fmt"
+    "log"
+
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// Shipment lifecycle (after the saga completes)
+// Why: the shipping service owns shipments; orders only mirrors shipped/delivered
+// so order history and the gateway show tracking without calling shipping.
+// The 3PL webhook publishes OrderShipped on orders.events as well, so these
+// handlers must tolerate orders that are already shipped or delivered.
+
+// handleOrderShipped records tracking data from an OrderShippedEvent
+func (so *SagaOrchestrator) handleOrderShipped(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.OrderShippedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal OrderShippedEvent: %w", err)
+    }
+
+    order, err := so.orderRepo.GetOrder(ctx, event.OrderID)
+    if err != nil {
+        return fmt.Errorf("failed to get order: %w", err)
+    }
+
+    if order.Status == "delivered" {
+        log.Printf("Order %d already delivered, ignoring OrderShipped", event.OrderID)
+        return nil
+    }
+
+    if err := so.orderRepo.MarkOrderShipped(ctx, event.OrderID, event.TrackingNumber, event.Carrier, event.ShippedAt.UTC()); err != nil {
+        return fmt.Errorf("failed to mark order shipped: %w", err)
+    }
+
+    log.Printf("✓ Order %d shipped (tracking %s)", event.OrderID, event.TrackingNumber)
+    return nil
+}
+
+// handleOrderDelivered records delivery from an OrderDeliveredEvent
+func (so *SagaOrchestrator) handleOrderDelivered(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.OrderDeliveredEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal OrderDeliveredEvent: %w", err)
+    }
+
+    if err := so.orderRepo.MarkOrderDelivered(ctx, event.OrderID, event.DeliveredAt.UTC()); err != nil {
+        return fmt.Errorf("failed to mark order delivered: %w", err)
+    }
+
+    log.Printf("✓ Order %d delivered", event.OrderID)
+    return nil
+}
@@ -0,0 +1,50 @@
+package saga
+
+import (
+    "context"
+    "encoding/json"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+// Stores the orchestrator works through, besides the order, saga, compensation and reservation
+// repository interfaces it shares with the handlers
+// Why: the repositories implement them against Postgres; the golden flow tests (flow_test.go)
+// run whole sagas against in-memory fakes, without a database or a broker.
+
+// CheckoutStore creates checkouts and places them once all their orders have stock
+type CheckoutStore interface {
+    CreateCheckout(ctx context.Context, checkout *models.Checkout) error
+    GetCheckoutOrders(ctx context.Context, checkoutID int64) ([]*models.Order, error)
+    PlaceCheckoutIfReserved(ctx context.Context, checkoutID int64) ([]int64, error)
+}
+
+// IdempotencyStore records the events already handled
+type IdempotencyStore interface {
+    IsProcessed(ctx context.Context, eventID, serviceName string) (bool, error)
+    RecordProcessed(ctx context.Context, eventID, serviceName, action, result string) error
+}
+
+// PaymentStore tracks payment retries
+type PaymentStore interface {
+    MarkPaymentPending(ctx context.Context, orderID int64, attempt int, reason string, deadline time.Time) (time.Time, bool, error)
+    MarkPaymentProcessed(ctx context.Context, orderID int64) (bool, error)
+}
+
+// HoldStore puts orders on hold
+type HoldStore interface {
+    HoldUserOrders(ctx context.Context, userID, reason, note, createdBy string) ([]*models.OrderHold, error)
+}
+
+// FulfillmentStore keeps the 3PL pushes that failed until a retry gets through
+type FulfillmentStore interface {
+    SavePendingPush(ctx context.Context, orderID int64, payload json.RawMessage, reason string) error
+    ListPendingPushes(ctx context.Context, limit int) ([]*models.PendingFulfillmentPush, error)
+    DeletePendingPush(ctx context.Context, orderID int64) error
+}
+
+// Publisher publishes order events
+type Publisher interface {
+    PublishOrderEventReliable(ctx context.Context, event interface{}) error
+}
@@ -0,0 +1,36 @@
+package saga
+
+import (
+    "context"
+    "fmt"
+    "log"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// handleUserDeactivated holds the open orders of a deleted account for review. Why: a hold
+// only stops auto-confirmation; whether to ship, refund or cancel is left to an admin, who
+// releases it with reason account_closed.
+func (so *SagaOrchestrator) handleUserDeactivated(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.UserDeactivatedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal UserDeactivatedEvent: %w", err)
+    }
+
+    log.Printf("UserDeactivatedEvent received: User %s, Reason: %s", event.UserID, event.Reason)
+
+    note := "account deactivated"
+    if event.Reason != "" {
+        note = "account deactivated: " + event.Reason
+    }
+    holds, err := so.holdRepo.HoldUserOrders(ctx, event.UserID, models.HoldReasonAccountClosed, note, "users")
+    if err != nil {
+        return err
+    }
+
+    for _, hold := range holds {
+        log.Printf("✓ Order %d held for review, user %s deactivated", hold.OrderID, event.UserID)
+    }
+    return nil
+}
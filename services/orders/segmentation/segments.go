@@ -0,0 +1,80 @@
+// Package segmentation tags users with customer segments derived from their
+// order history. Tags are refreshed on order events and read by pricing and
+// promotion logic through the Lookup interface.
+package segmentation
+
+import (
+    "sort"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+// Segment tags
+const (
+    SegmentNewCustomer    = "new_customer"
+    SegmentFirstTimeBuyer = "first_time_buyer"
+    SegmentRepeatCustomer = "repeat_customer"
+    SegmentLoyal          = "loyal"
+    SegmentVIP            = "vip"
+    SegmentHighCanceller  = "high_canceller"
+)
+
+// Rule thresholds
+const (
+    LoyalMinOrders        = 5
+    VIPMinLifetimeSpend   = 1000.0
+    HighCancellerMinCount = 2
+    HighCancellerMinRate  = 0.5
+)
+
+// Rule assigns a segment when Match returns true
+type Rule struct {
+    Segment string
+    Match   func(stats models.UserOrderStats) bool
+}
+
+// DefaultRules returns the built-in segmentation rules
+func DefaultRules() []Rule {
+    return []Rule{
+        {
+            Segment: SegmentNewCustomer,
+            Match:   func(s models.UserOrderStats) bool { return s.OrderCount == 0 },
+        },
+        {
+            Segment: SegmentFirstTimeBuyer,
+            Match:   func(s models.UserOrderStats) bool { return s.OrderCount == 1 },
+        },
+        {
+            Segment: SegmentRepeatCustomer,
+            Match:   func(s models.UserOrderStats) bool { return s.OrderCount >= 2 },
+        },
+        {
+            Segment: SegmentLoyal,
+            Match:   func(s models.UserOrderStats) bool { return s.OrderCount >= LoyalMinOrders },
+        },
+        {
+            Segment: SegmentVIP,
+            Match:   func(s models.UserOrderStats) bool { return s.LifetimeSpend >= VIPMinLifetimeSpend },
+        },
+        {
+            Segment: SegmentHighCanceller,
+            Match: func(s models.UserOrderStats) bool {
+                all := s.OrderCount + s.CancelledCount
+                return s.CancelledCount >= HighCancellerMinCount &&
+                    float64(s.CancelledCount)/float64(all) >= HighCancellerMinRate
+            },
+        },
+    }
+}
+
+// Evaluate returns the sorted segment tags whose rules match stats
+func Evaluate(stats models.UserOrderStats, rules []Rule) []string {
+    segments := []string{}
+    for _, rule := range rules {
+        if rule.Match(stats) {
+            segments = append(segments, rule.Segment)
+        }
+    }
+    sort.Strings(segments)
+    return segments
+}
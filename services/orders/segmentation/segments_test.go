@@ -0,0 +1,118 @@
+package segmentation
+
+import (
+    "context"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+func TestEvaluate_DefaultRules(t *testing.T) {
+    cases := []struct {
+        name  string
+        stats models.UserOrderStats
+        want  []string
+    }{
+        {"no orders", models.UserOrderStats{}, []string{SegmentNewCustomer}},
+        {"one order", models.UserOrderStats{OrderCount: 1, LifetimeSpend: 50}, []string{SegmentFirstTimeBuyer}},
+        {"big spender", models.UserOrderStats{OrderCount: 6, LifetimeSpend: 1500},
+            []string{SegmentLoyal, SegmentRepeatCustomer, SegmentVIP}},
+        {"cancels a lot", models.UserOrderStats{OrderCount: 2, CancelledCount: 3},
+            []string{SegmentHighCanceller, SegmentRepeatCustomer}},
+        {"cancelled once", models.UserOrderStats{OrderCount: 0, CancelledCount: 1}, []string{SegmentNewCustomer}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            got := Evaluate(tc.stats, DefaultRules())
+            if !reflect.DeepEqual(got, tc.want) {
+                t.Fatalf("expected %v, got %v", tc.want, got)
+            }
+        })
+    }
+}
+
+type fakeStore struct {
+    stats  map[string]*models.UserOrderStats
+    stored map[string]*models.UserSegments
+}
+
+func (fs *fakeStore) GetUserOrderStats(ctx context.Context, userID string) (*models.UserOrderStats, error) {
+    if s, ok := fs.stats[userID]; ok {
+        return s, nil
+    }
+    return &models.UserOrderStats{UserID: userID}, nil
+}
+
+func (fs *fakeStore) UpsertUserSegments(ctx context.Context, segments *models.UserSegments) error {
+    fs.stored[segments.UserID] = segments
+    return nil
+}
+
+func (fs *fakeStore) GetUserSegments(ctx context.Context, userID string) (*models.UserSegments, error) {
+    if s, ok := fs.stored[userID]; ok {
+        return s, nil
+    }
+    return nil, repository.ErrSegmentsNotFound
+}
+
+type fakeOrders map[int64]*models.Order
+
+func (fo fakeOrders) GetOrder(ctx context.Context, orderID int64) (*models.Order, error) {
+    if o, ok := fo[orderID]; ok {
+        return o, nil
+    }
+    return nil, repository.ErrSegmentsNotFound
+}
+
+func TestEventHandler_RefreshesOrderOwner(t *testing.T) {
+    now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+    store := &fakeStore{
+        stats:  map[string]*models.UserOrderStats{"u1": {UserID: "u1", OrderCount: 1, LifetimeSpend: 20}},
+        stored: map[string]*models.UserSegments{},
+    }
+    service := NewService(store, DefaultRules(), clock.NewFake(now))
+    handler := NewEventHandler(service, fakeOrders{42: {ID: 42, UserID: "u1"}})
+
+    // OrderCancelled carries order_id as a string
+    if err := handler.HandleEvent(context.Background(), []byte(`{"event_type":"OrderCancelled","order_id":"42"}`)); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    got, ok := store.stored["u1"]
+    if !ok {
+        t.Fatal("segments not stored for order owner")
+    }
+    if !got.HasSegment(SegmentFirstTimeBuyer) || !got.UpdatedAt.Equal(now) {
+        t.Fatalf("unexpected segments: %+v", got)
+    }
+}
+
+func TestEventHandler_IgnoresUnrelatedEvents(t *testing.T) {
+    store := &fakeStore{stored: map[string]*models.UserSegments{}}
+    handler := NewEventHandler(NewService(store, DefaultRules(), clock.New()), fakeOrders{})
+
+    if err := handler.HandleEvent(context.Background(), []byte(`{"event_type":"OrderCreated","order_id":1}`)); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if len(store.stored) != 0 {
+        t.Fatalf("expected no refresh, got %v", store.stored)
+    }
+}
+
+func TestService_GetSegmentsComputesOnMiss(t *testing.T) {
+    store := &fakeStore{stored: map[string]*models.UserSegments{}}
+    service := NewService(store, DefaultRules(), clock.New())
+
+    segments, err := service.SegmentsFor(context.Background(), "new-user")
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if !reflect.DeepEqual(segments, []string{SegmentNewCustomer}) {
+        t.Fatalf("expected new_customer, got %v", segments)
+    }
+}
@@ -0,0 +1,85 @@
+package segmentation
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+    "github.com/sanketh-sg/prost/services/orders/repository"
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// Lookup is what pricing and promotion engines depend on to target segments
+type Lookup interface {
+    SegmentsFor(ctx context.Context, userID string) ([]string, error)
+}
+
+// Store persists user segments (implemented by repository.SegmentRepository)
+type Store interface {
+    GetUserOrderStats(ctx context.Context, userID string) (*models.UserOrderStats, error)
+    UpsertUserSegments(ctx context.Context, segments *models.UserSegments) error
+    GetUserSegments(ctx context.Context, userID string) (*models.UserSegments, error)
+}
+
+// Service computes and serves user segments
+type Service struct {
+    store Store
+    rules []Rule
+    clock clock.Clock
+}
+
+// NewService creates new segmentation service
+func NewService(store Store, rules []Rule, clk clock.Clock) *Service {
+    return &Service{
+        store: store,
+        rules: rules,
+        clock: clk,
+    }
+}
+
+// RefreshUser recomputes a user's segments from their order history
+func (s *Service) RefreshUser(ctx context.Context, userID string) (*models.UserSegments, error) {
+    stats, err := s.store.GetUserOrderStats(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    segments := &models.UserSegments{
+        UserID:        userID,
+        Segments:      Evaluate(*stats, s.rules),
+        OrderCount:    stats.OrderCount,
+        LifetimeSpend: stats.LifetimeSpend,
+        LastOrderAt:   stats.LastOrderAt,
+        UpdatedAt:     s.clock.Now(),
+    }
+
+    if err := s.store.UpsertUserSegments(ctx, segments); err != nil {
+        return nil, err
+    }
+
+    log.Printf("✓ Segments refreshed for user %s: %v", userID, segments.Segments)
+    return segments, nil
+}
+
+// GetSegments returns stored segments, computing them on first lookup
+func (s *Service) GetSegments(ctx context.Context, userID string) (*models.UserSegments, error) {
+    segments, err := s.store.GetUserSegments(ctx, userID)
+    if errors.Is(err, repository.ErrSegmentsNotFound) {
+        return s.RefreshUser(ctx, userID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get segments: %w", err)
+    }
+    return segments, nil
+}
+
+// SegmentsFor implements Lookup
+func (s *Service) SegmentsFor(ctx context.Context, userID string) ([]string, error) {
+    segments, err := s.GetSegments(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    return segments.Segments, nil
+}
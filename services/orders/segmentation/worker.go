@@ -0,0 +1,94 @@
+package segmentation
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strconv"
+
+    "github.com/sanketh-sg/prost/services/orders/models"
+)
+
+// OrderLookup resolves the owner of an order
+type OrderLookup interface {
+    GetOrder(ctx context.Context, orderID int64) (*models.Order, error)
+}
+
+// refreshEvents are the order events that can change a user's segments
+var refreshEvents = map[string]bool{
+    "OrderPlaced":    true,
+    "OrderConfirmed": true,
+    "OrderCancelled": true,
+    "OrderFailed":    true,
+    "OrderShipped":   true,
+    "OrderDelivered": true,
+}
+
+// EventHandler refreshes segments when order events arrive (orders.segments.queue)
+type EventHandler struct {
+    service *Service
+    orders  OrderLookup
+}
+
+// NewEventHandler creates new segmentation event handler
+func NewEventHandler(service *Service, orders OrderLookup) *EventHandler {
+    return &EventHandler{
+        service: service,
+        orders:  orders,
+    }
+}
+
+// HandleEvent refreshes the segments of the user who owns the event's order.
+// Refreshing recomputes from the orders table, so redelivery is harmless.
+func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
+    var event struct {
+        EventType string          `json:"event_type"`
+        UserID    string          `json:"user_id"`
+        OrderID   json.RawMessage `json:"order_id"` // int64 or string depending on event
+    }
+    if err := json.Unmarshal(message, &event); err != nil {
+        return fmt.Errorf("failed to unmarshal event: %w", err)
+    }
+
+    if !refreshEvents[event.EventType] {
+        return nil
+    }
+
+    userID := event.UserID
+    if userID == "" {
+        orderID, err := parseOrderID(event.OrderID)
+        if err != nil {
+            return fmt.Errorf("%s: %w", event.EventType, err)
+        }
+
+        order, err := eh.orders.GetOrder(ctx, orderID)
+        if err != nil {
+            // Order may never have been created (e.g. OrderFailed before insert)
+            log.Printf("⚠️  Skipping segment refresh for order %d: %v", orderID, err)
+            return nil
+        }
+        userID = order.UserID
+    }
+
+    _, err := eh.service.RefreshUser(ctx, userID)
+    return err
+}
+
+// parseOrderID accepts both numeric and string order ids
+func parseOrderID(raw json.RawMessage) (int64, error) {
+    var id int64
+    if err := json.Unmarshal(raw, &id); err == nil {
+        return id, nil
+    }
+
+    var idStr string
+    if err := json.Unmarshal(raw, &idStr); err != nil {
+        return 0, fmt.Errorf("invalid order_id: %s", string(raw))
+    }
+    id, err := strconv.ParseInt(idStr, 10, 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid order_id: %q", idStr)
+    }
+    return id, nil
+}
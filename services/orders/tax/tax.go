@@ -0,0 +1,162 @@
+// Package tax works out the taxes added to an order at checkout.
+package tax
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/sanketh-sg/prost/shared/config"
+    sharedmodels "github.com/sanketh-sg/prost/shared/models"
+)
+
+// AnyRegion is the region table key that applies to regions without rates of their own
+const AnyRegion = "*"
+
+// Calculator works out the taxes on an order's taxable amount in a region.
+// Why: an interface so a tax service (with its own latency and failures) can replace the
+// built-in tables without touching the saga.
+type Calculator interface {
+    Calculate(ctx context.Context, region string, amount float64) ([]sharedmodels.TaxLine, error)
+}
+
+// NewCalculator creates the calculator TAX_MODE selects
+func NewCalculator(cfg config.Tax) (Calculator, error) {
+    switch cfg.Mode {
+    case "", "none":
+        return None{}, nil
+    case "flat":
+        return FlatRate{Name: cfg.FlatName, Rate: cfg.FlatRate}, nil
+    case "region":
+        return ParseRegionTable(cfg.RegionRates)
+    }
+    return nil, fmt.Errorf("unknown tax mode %q", cfg.Mode)
+}
+
+// None charges no tax
+type None struct{}
+
+// Calculate returns no tax lines
+func (None) Calculate(ctx context.Context, region string, amount float64) ([]sharedmodels.TaxLine, error) {
+    return nil, nil
+}
+
+// FlatRate charges one rate everywhere
+type FlatRate struct {
+    Name string
+    Rate float64 // percent
+}
+
+// Calculate returns the one tax line, or none at a zero rate
+func (fr FlatRate) Calculate(ctx context.Context, region string, amount float64) ([]sharedmodels.TaxLine, error) {
+    if fr.Rate == 0 {
+        return nil, nil
+    }
+    return []sharedmodels.TaxLine{line(Rate{Name: fr.Name, Rate: fr.Rate}, region, amount)}, nil
+}
+
+// Rate is one tax of a region, e.g. its state or county sales tax
+type Rate struct {
+    Name string
+    Rate float64 // percent
+}
+
+// RegionTable charges each region its own taxes
+type RegionTable struct {
+    rates map[string][]Rate
+}
+
+// NewRegionTable creates a table from rates by region; AnyRegion applies to regions without rates
+func NewRegionTable(rates map[string][]Rate) *RegionTable {
+    return &RegionTable{rates: rates}
+}
+
+// ParseRegionTable reads TAX_REGION_RATES: regions separated by ";", each region's taxes
+// by ",", e.g. main=state:6.25,county:1.5;eu=vat:20;*=sales:5
+func ParseRegionTable(spec string) (*RegionTable, error) {
+    rates := map[string][]Rate{}
+    for _, entry := range strings.Split(spec, ";") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        region, taxes, ok := strings.Cut(entry, "=")
+        region = strings.TrimSpace(region)
+        if !ok || region == "" {
+            return nil, fmt.Errorf("tax region %q: expected region=name:percent,...", entry)
+        }
+        if _, dup := rates[region]; dup {
+            return nil, fmt.Errorf("tax region %q is listed twice", region)
+        }
+
+        regionRates := []Rate{}
+        for _, tax := range strings.Split(taxes, ",") {
+            name, percent, ok := strings.Cut(strings.TrimSpace(tax), ":")
+            name = strings.TrimSpace(name)
+            if !ok || name == "" {
+                return nil, fmt.Errorf("tax region %q: expected name:percent, got %q", region, tax)
+            }
+            rate, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+            if err != nil || rate < 0 || rate > 100 {
+                return nil, fmt.Errorf("tax region %q: %s must be a percent between 0 and 100", region, name)
+            }
+            regionRates = append(regionRates, Rate{Name: name, Rate: rate})
+        }
+        rates[region] = regionRates
+    }
+    if len(rates) == 0 {
+        return nil, fmt.Errorf("no tax regions in %q", spec)
+    }
+    return NewRegionTable(rates), nil
+}
+
+// Regions lists the table's regions, sorted
+func (rt *RegionTable) Regions() []string {
+    regions := make([]string, 0, len(rt.rates))
+    for region := range rt.rates {
+        regions = append(regions, region)
+    }
+    sort.Strings(regions)
+    return regions
+}
+
+// Calculate returns a line per tax of the region, falling back to AnyRegion's; a region
+// with neither is charged no tax
+func (rt *RegionTable) Calculate(ctx context.Context, region string, amount float64) ([]sharedmodels.TaxLine, error) {
+    rates, ok := rt.rates[region]
+    if !ok {
+        rates = rt.rates[AnyRegion]
+    }
+
+    var lines []sharedmodels.TaxLine
+    for _, rate := range rates {
+        if rate.Rate == 0 {
+            continue
+        }
+        lines = append(lines, line(rate, region, amount))
+    }
+    return lines, nil
+}
+
+// Total sums tax lines in cents
+func Total(lines []sharedmodels.TaxLine) float64 {
+    var cents int64
+    for _, line := range lines {
+        cents += toCents(line.Amount)
+    }
+    return float64(cents) / 100
+}
+
+// line taxes amount at rate, rounded half away from zero to the cent. Each tax is rounded on
+// its own, the way it's reported to its authority.
+func line(rate Rate, region string, amount float64) sharedmodels.TaxLine {
+    cents := math.Round(float64(toCents(amount)) * rate.Rate / 100)
+    return sharedmodels.TaxLine{Name: rate.Name, Region: region, Rate: rate.Rate, Amount: cents / 100}
+}
+
+func toCents(amount float64) int64 {
+    return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,62 @@
+package tax
+
+import (
+    "context"
+    "testing"
+
+    "github.com/sanketh-sg/prost/shared/config"
+)
+
+func TestParseRegionTable(t *testing.T) {
+    table, err := ParseRegionTable("main=state:6.25,county:1.5; eu=vat:20; *=sales:5")
+    if err != nil {
+        t.Fatalf("ParseRegionTable: %v", err)
+    }
+    ctx := context.Background()
+
+    lines, _ := table.Calculate(ctx, "main", 19.99)
+    if len(lines) != 2 || lines[0].Name != "state" || lines[0].Amount != 1.25 || lines[1].Name != "county" || lines[1].Amount != 0.30 {
+        t.Errorf("main lines = %+v, want state 1.25 and county 0.30", lines)
+    }
+    if total := Total(lines); total != 1.55 {
+        t.Errorf("Total = %v, want 1.55", total)
+    }
+
+    if lines, _ := table.Calculate(ctx, "east", 10); len(lines) != 1 || lines[0].Name != "sales" || lines[0].Region != "east" || lines[0].Amount != 0.50 {
+        t.Errorf("east lines = %+v, want the * rate", lines)
+    }
+
+    for _, spec := range []string{"", "main", "main=state", "main=state:abc", "main=state:120", "main=a:1;main=b:2"} {
+        if _, err := ParseRegionTable(spec); err == nil {
+            t.Errorf("ParseRegionTable(%q) = nil error, want it rejected", spec)
+        }
+    }
+}
+
+func TestRegionTableWithoutFallbackChargesNothing(t *testing.T) {
+    table := NewRegionTable(map[string][]Rate{"main": {{Name: "state", Rate: 6}}})
+
+    if lines, _ := table.Calculate(context.Background(), "", 100); len(lines) != 0 {
+        t.Errorf("lines = %+v, want no tax outside the table", lines)
+    }
+}
+
+func TestNewCalculator(t *testing.T) {
+    calculator, err := NewCalculator(config.Tax{Mode: "flat", FlatName: "sales tax", FlatRate: 8.875})
+    if err != nil {
+        t.Fatalf("NewCalculator: %v", err)
+    }
+    lines, _ := calculator.Calculate(context.Background(), "main", 59.98)
+    if len(lines) != 1 || lines[0].Amount != 5.32 || lines[0].Rate != 8.875 {
+        t.Errorf("flat lines = %+v, want 5.32 at 8.875%%", lines)
+    }
+
+    if calculator, _ := NewCalculator(config.Tax{Mode: "none"}); calculator == nil {
+        t.Error("expected a calculator for TAX_MODE=none")
+    } else if lines, _ := calculator.Calculate(context.Background(), "main", 59.98); len(lines) != 0 {
+        t.Errorf("none lines = %+v", lines)
+    }
+    if _, err := NewCalculator(config.Tax{Mode: "region", RegionRates: "main"}); err == nil {
+        t.Error("expected a bad region table to be rejected")
+    }
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// CreateAttributeTemplate adds an attribute template to a category
+func (ph *ProductHandler) CreateAttributeTemplate(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid category id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.CreateAttributeTemplateRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := ph.categoryRepo.GetCategory(ctx, categoryID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "category not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    template := models.NewAttributeTemplate(categoryID, req.Name, req.Label, req.Type, req.Unit, req.Options, req.Required)
+    if err := template.Validate(); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attribute template",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := ph.attributeRepo.CreateTemplate(ctx, template); err != nil {
+        respondAttributeTemplateError(c, "failed to create attribute template", err)
+        return
+    }
+
+    log.Printf("✓ Attribute template created: %s for category %d (ID: %d)", template.Name, categoryID, template.ID)
+
+    c.JSON(http.StatusCreated, gin.H{
+        "message":  "Attribute template created successfully",
+        "template": template,
+    })
+}
+
+// GetAttributeTemplates lists the templates that apply to a category, including inherited ones
+func (ph *ProductHandler) GetAttributeTemplates(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid category id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := ph.categoryRepo.GetCategory(ctx, categoryID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "category not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    templates, err := ph.attributeRepo.GetTemplatesForCategory(ctx, categoryID)
+    if err != nil {
+        respondAttributeTemplateError(c, "failed to get attribute templates", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "templates": templates,
+        "count":     len(templates),
+    })
+}
+
+// UpdateAttributeTemplate changes a template's label, unit, options or required flag
+func (ph *ProductHandler) UpdateAttributeTemplate(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attribute template id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.UpdateAttributeTemplateRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    template, err := ph.attributeRepo.GetTemplate(ctx, id)
+    if err != nil {
+        respondAttributeTemplateError(c, "attribute template not found", err)
+        return
+    }
+
+    if req.Label != nil && *req.Label != "" {
+        template.Label = *req.Label
+    }
+    if req.Unit != nil {
+        template.Unit = *req.Unit
+    }
+    if req.Options != nil {
+        template.Options = req.Options
+    }
+    if req.Required != nil {
+        template.Required = *req.Required
+    }
+
+    if err := template.Validate(); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attribute template",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := ph.attributeRepo.UpdateTemplate(ctx, template); err != nil {
+        respondAttributeTemplateError(c, "failed to update attribute template", err)
+        return
+    }
+
+    log.Printf("✓ Attribute template updated: %s (ID: %d)", template.Name, template.ID)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":  "Attribute template updated successfully",
+        "template": template,
+    })
+}
+
+// DeleteAttributeTemplate removes an attribute template
+func (ph *ProductHandler) DeleteAttributeTemplate(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attribute template id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := ph.attributeRepo.DeleteTemplate(ctx, id); err != nil {
+        respondAttributeTemplateError(c, "failed to delete attribute template", err)
+        return
+    }
+
+    log.Printf("✓ Attribute template deleted: ID: %d", id)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Attribute template deleted successfully",
+    })
+}
+
+// checkAttributes validates a product's attributes against its category's templates and answers
+// 400 when they don't match; it reports whether the request may go on
+func (ph *ProductHandler) checkAttributes(ctx context.Context, c *gin.Context, categoryID *int64, attributes models.Attributes) bool {
+    var templates []*models.AttributeTemplate
+    if categoryID != nil {
+        var err error
+        templates, err = ph.attributeRepo.GetTemplatesForCategory(ctx, *categoryID)
+        if err != nil {
+            respondAttributeTemplateError(c, "failed to get attribute templates", err)
+            return false
+        }
+    }
+
+    if err := models.ValidateAttributes(templates, attributes); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attributes",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return false
+    }
+    return true
+}
+
+// respondAttributeTemplateError maps repository errors to HTTP statuses
+func respondAttributeTemplateError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrAttributeTemplateNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrDuplicateAttributeTemplate):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// CartLockHandler handles the soft locks the cart service takes on add-to-cart
+type CartLockHandler struct {
+    inventoryRepo *repository.InventoryReservationRepository
+}
+
+// NewCartLockHandler creates new cart soft lock handler
+func NewCartLockHandler(inventoryRepo *repository.InventoryReservationRepository) *CartLockHandler {
+    return &CartLockHandler{inventoryRepo: inventoryRepo}
+}
+
+// Lock soft-locks a cart line's stock for the requested TTL (capped at MaxCartLockTTL).
+// A shortage is a 409 with the units still available.
+func (h *CartLockHandler) Lock(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.CartLockRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    ttl := req.TTL()
+    reservation, err := h.inventoryRepo.LockForCart(ctx, req.CartID, req.ProductID, req.VariantID, req.Quantity, ttl)
+    var shortage *repository.StockShortageError
+    if errors.As(err, &shortage) {
+        c.JSON(http.StatusConflict, gin.H{
+            "error":     "insufficient stock",
+            "message":   shortage.Error(),
+            "code":      http.StatusConflict,
+            "available": max(shortage.Available, 0),
+        })
+        return
+    }
+    if err != nil {
+        respondCartLockError(c, "failed to lock stock", err)
+        return
+    }
+
+    log.Printf("✓ Cart %s soft-locked %d unit(s) of product %d for %s", req.CartID, reservation.Quantity, reservation.ProductID, ttl)
+
+    c.JSON(http.StatusCreated, models.CartLock{
+        ReservationID: reservation.ReservationID,
+        ProductID:     reservation.ProductID,
+        VariantID:     reservation.VariantID,
+        Quantity:      reservation.Quantity,
+        ExpiresAt:     reservation.ExpiresAt,
+        TTLSeconds:    int(ttl.Seconds()),
+    })
+}
+
+// Release gives back one soft lock of ?cart_id=; releasing it twice is fine
+func (h *CartLockHandler) Release(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    cartID := c.Query("cart_id")
+    if cartID == "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "cart_id is required",
+            Message: "pass the cart owning the lock as ?cart_id=",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := h.inventoryRepo.ReleaseCartLock(ctx, cartID, c.Param("reservation_id")); err != nil {
+        respondCartLockError(c, "failed to release lock", err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+// ReleaseCart gives back every soft lock of a cart
+func (h *CartLockHandler) ReleaseCart(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    released, err := h.inventoryRepo.ReleaseCartLocks(ctx, c.Param("cart_id"))
+    if err != nil {
+        respondCartLockError(c, "failed to release cart locks", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"released": released})
+}
+
+func respondCartLockError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrUnknownProduct):
+        status = http.StatusNotFound
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
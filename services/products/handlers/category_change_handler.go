@@ -0,0 +1,312 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// MergeCategory merges the category in the path into target_id: its products, subcategories
+// and the attribute templates the target doesn't have move over, then it is deleted.
+// With dry_run nothing changes and the preview is returned.
+func (ph *ProductHandler) MergeCategory(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    sourceID, ok := parseCategoryID(c)
+    if !ok {
+        return
+    }
+
+    var req models.MergeCategoryRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    categories, err := ph.categoryRepo.GetAllCategories(ctx)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get categories", err)
+        return
+    }
+    found := map[int64]bool{}
+    for _, category := range categories {
+        found[category.ID] = true
+    }
+    if !found[sourceID] || !found[req.TargetID] {
+        respondCategoryChangeError(c, "failed to merge category", repository.ErrCategoryNotFound)
+        return
+    }
+    if models.CategorySubtree(categories, sourceID)[req.TargetID] {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid merge target",
+            Message: "a category can't be merged into itself or one of its subcategories",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    // Preview
+    products, err := ph.productRepo.GetAllProducts(ctx, &sourceID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get category products", err)
+        return
+    }
+    targetProducts, err := ph.productRepo.GetAllProducts(ctx, &req.TargetID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get category products", err)
+        return
+    }
+    targetTemplates, err := ph.attributeRepo.GetTemplatesForCategory(ctx, req.TargetID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get attribute templates", err)
+        return
+    }
+    sourceTemplates, err := ph.attributeRepo.GetTemplatesForCategory(ctx, sourceID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get attribute templates", err)
+        return
+    }
+    var sourceOwn []*models.AttributeTemplate
+    for _, template := range sourceTemplates {
+        if template.CategoryID == sourceID {
+            sourceOwn = append(sourceOwn, template)
+        }
+    }
+    templates, moved, dropped := models.MergeTemplates(targetTemplates, sourceOwn)
+
+    change := &models.CategoryChange{
+        DryRun:             req.DryRun,
+        SourceCategoryID:   &sourceID,
+        TargetCategoryID:   req.TargetID,
+        ProductIDs:         productIDs(products),
+        MovedTemplates:     moved,
+        DroppedTemplates:   dropped,
+        AttributeProblems:  models.CheckCategoryAttributes(templates, products),
+        TargetProductCount: len(targetProducts) + len(products),
+    }
+    for _, category := range categories {
+        if category.ParentID != nil && *category.ParentID == sourceID {
+            change.SubcategoryIDs = append(change.SubcategoryIDs, category.ID)
+        }
+    }
+
+    if req.DryRun {
+        c.JSON(http.StatusOK, change)
+        return
+    }
+    if !ph.attributeProblemsAllowed(c, change, req.Force) {
+        return
+    }
+
+    change.ProductIDs, change.SubcategoryIDs, err = ph.categoryRepo.MergeCategory(ctx, sourceID, req.TargetID, moved)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to merge category", err)
+        return
+    }
+    change.TargetProductCount = ph.directProductCount(ctx, req.TargetID, change.TargetProductCount)
+
+    event := events.CategoryMergedEvent{
+        BaseEvent:        events.NewBaseEvent("CategoryMerged", strconv.FormatInt(sourceID, 10), "category", ""),
+        SourceCategoryID: sourceID,
+        TargetCategoryID: req.TargetID,
+        ProductIDs:       change.ProductIDs,
+        SubcategoryIDs:   change.SubcategoryIDs,
+    }
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish CategoryMerged event: %v", err)
+    }
+
+    log.Printf("✓ Category %d merged into %d: %d product(s), %d subcategory(ies)", sourceID, req.TargetID, len(change.ProductIDs), len(change.SubcategoryIDs))
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Category merged successfully",
+        "result":  change,
+    })
+}
+
+// AssignProducts moves a list of products into the category in the path.
+// With dry_run nothing changes and the preview is returned.
+func (ph *ProductHandler) AssignProducts(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    categoryID, ok := parseCategoryID(c)
+    if !ok {
+        return
+    }
+
+    var req models.AssignProductsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := ph.categoryRepo.GetCategory(ctx, categoryID); err != nil {
+        respondCategoryChangeError(c, "failed to assign products", repository.ErrCategoryNotFound)
+        return
+    }
+
+    // Preview
+    products, err := ph.productRepo.GetProductsByIDs(ctx, req.ProductIDs)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get products", err)
+        return
+    }
+    templates, err := ph.attributeRepo.GetTemplatesForCategory(ctx, categoryID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get attribute templates", err)
+        return
+    }
+    targetProducts, err := ph.productRepo.GetAllProducts(ctx, &categoryID)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to get category products", err)
+        return
+    }
+
+    change := &models.CategoryChange{
+        DryRun:           req.DryRun,
+        TargetCategoryID: categoryID,
+        ProductIDs:       []int64{},
+    }
+    found := map[int64]bool{}
+    var moving []*models.Product
+    for _, product := range products {
+        found[product.ID] = true
+        if product.CategoryID != nil && *product.CategoryID == categoryID {
+            change.UnchangedProductIDs = append(change.UnchangedProductIDs, product.ID)
+            continue
+        }
+        moving = append(moving, product)
+        change.ProductIDs = append(change.ProductIDs, product.ID)
+    }
+    for _, id := range req.ProductIDs {
+        if !found[id] {
+            change.MissingProductIDs = append(change.MissingProductIDs, id)
+            found[id] = true // listed once even if requested twice
+        }
+    }
+    change.AttributeProblems = models.CheckCategoryAttributes(templates, moving)
+    change.TargetProductCount = len(targetProducts) + len(moving)
+
+    if req.DryRun {
+        c.JSON(http.StatusOK, change)
+        return
+    }
+    if len(change.MissingProductIDs) > 0 {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "unknown products",
+            Message: fmt.Sprintf("products not found or deleted: %v", change.MissingProductIDs),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if !ph.attributeProblemsAllowed(c, change, req.Force) {
+        return
+    }
+
+    change.ProductIDs, err = ph.categoryRepo.AssignProducts(ctx, categoryID, change.ProductIDs)
+    if err != nil {
+        respondCategoryChangeError(c, "failed to assign products", err)
+        return
+    }
+    change.TargetProductCount = ph.directProductCount(ctx, categoryID, change.TargetProductCount)
+
+    if len(change.ProductIDs) > 0 {
+        event := events.CategoryProductsAssignedEvent{
+            BaseEvent:  events.NewBaseEvent("CategoryProductsAssigned", strconv.FormatInt(categoryID, 10), "category", ""),
+            CategoryID: categoryID,
+            ProductIDs: change.ProductIDs,
+        }
+        if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+            log.Printf("⚠️  Failed to publish CategoryProductsAssigned event: %v", err)
+        }
+    }
+
+    log.Printf("✓ %d product(s) assigned to category %d", len(change.ProductIDs), categoryID)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Products assigned successfully",
+        "result":  change,
+    })
+}
+
+// attributeProblemsAllowed answers 409 when products wouldn't fit their new category's
+// attribute templates, unless the admin forces the change
+func (ph *ProductHandler) attributeProblemsAllowed(c *gin.Context, change *models.CategoryChange, force bool) bool {
+    if len(change.AttributeProblems) == 0 || force {
+        return true
+    }
+    c.JSON(http.StatusConflict, models.ErrorResponse{
+        Error:   "attributes don't fit the target category",
+        Message: fmt.Sprintf("%d product(s) don't fit the target's attribute templates; see dry_run, or pass force", len(change.AttributeProblems)),
+        Code:    http.StatusConflict,
+    })
+    return false
+}
+
+// directProductCount returns the products directly in a category after a change, falling back
+// to the preview's count if it can't be read (the change is already committed)
+func (ph *ProductHandler) directProductCount(ctx context.Context, categoryID int64, fallback int) int {
+    counts, err := ph.categoryRepo.GetProductCounts(ctx)
+    if err != nil {
+        log.Printf("⚠️  Failed to count products of category %d: %v", categoryID, err)
+        return fallback
+    }
+    return counts[categoryID]
+}
+
+func productIDs(products []*models.Product) []int64 {
+    ids := make([]int64, 0, len(products))
+    for _, product := range products {
+        ids = append(ids, product.ID)
+    }
+    return ids
+}
+
+// parseCategoryID reads the :id path parameter, answering 400 if it isn't a number
+func parseCategoryID(c *gin.Context) (int64, bool) {
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid category id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return 0, false
+    }
+    return id, true
+}
+
+// respondCategoryChangeError maps repository errors to HTTP statuses
+func respondCategoryChangeError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrCategoryNotFound):
+        status = http.StatusNotFound
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/middleware"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// defaultReportPeriod is the channel report window when ?from= is not given
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+// ChannelHandler handles external sales channels and their stock reservations
+type ChannelHandler struct {
+    channelRepo   *repository.ChannelRepository
+    inventoryRepo *repository.InventoryReservationRepository
+    clock         clock.Clock
+}
+
+// NewChannelHandler creates new sales channel handler
+func NewChannelHandler(channelRepo *repository.ChannelRepository, inventoryRepo *repository.InventoryReservationRepository, clk clock.Clock) *ChannelHandler {
+    return &ChannelHandler{
+        channelRepo:   channelRepo,
+        inventoryRepo: inventoryRepo,
+        clock:         clk,
+    }
+}
+
+// CreateChannel registers a sales channel and returns its API key.
+// Only the key's hash is stored, so this is the one time it can be read.
+func (ch *ChannelHandler) CreateChannel(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.CreateChannelRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    apiKey, err := newChannelKey()
+    if err != nil {
+        respondChannelError(c, "failed to generate channel key", err)
+        return
+    }
+
+    channel := &models.SalesChannel{
+        ID:             req.ID,
+        Name:           req.Name,
+        Quota:          req.Quota,
+        MaxHoldMinutes: req.MaxHoldMinutes,
+    }
+    if channel.MaxHoldMinutes == 0 {
+        channel.MaxHoldMinutes = models.DefaultChannelMaxHoldMinutes
+    }
+
+    if err := ch.channelRepo.CreateChannel(ctx, channel, models.HashChannelKey(apiKey)); err != nil {
+        respondChannelError(c, "failed to create sales channel", err)
+        return
+    }
+
+    log.Printf("✓ Sales channel registered: %s (quota %d)", channel.ID, channel.Quota)
+
+    c.JSON(http.StatusCreated, gin.H{
+        "channel": channel,
+        "api_key": apiKey,
+    })
+}
+
+// GetChannels lists sales channels
+func (ch *ChannelHandler) GetChannels(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    channels, err := ch.channelRepo.ListChannels(ctx)
+    if err != nil {
+        respondChannelError(c, "failed to list sales channels", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "channels": channels,
+        "count":    len(channels),
+    })
+}
+
+// GetChannelReport summarizes a channel's reservations by status.
+// ?from= and ?to= are RFC3339; the default is the last 30 days.
+func (ch *ChannelHandler) GetChannelReport(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    to := ch.clock.Now()
+    from := to.Add(-defaultReportPeriod)
+    for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+        if val := c.Query(param); val != "" {
+            parsed, err := time.Parse(time.RFC3339, val)
+            if err != nil {
+                c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                    Error:   "invalid " + param,
+                    Message: err.Error(),
+                    Code:    http.StatusBadRequest,
+                })
+                return
+            }
+            *target = parsed
+        }
+    }
+
+    channel, err := ch.channelRepo.GetChannel(ctx, c.Param("id"))
+    if err != nil {
+        respondChannelError(c, "failed to get sales channel", err)
+        return
+    }
+
+    report, err := ch.inventoryRepo.GetChannelReport(ctx, channel, from, to)
+    if err != nil {
+        respondChannelError(c, "failed to get channel report", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, report)
+}
+
+// Reserve holds stock for the authenticated channel
+func (ch *ChannelHandler) Reserve(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.ChannelReserveRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    channel := channelFromContext(c)
+    reservation, created, err := ch.inventoryRepo.ReserveForChannel(ctx, channel, &req)
+    if err != nil {
+        respondChannelError(c, "failed to reserve stock", err)
+        return
+    }
+
+    if !created {
+        c.JSON(http.StatusOK, reservation)
+        return
+    }
+
+    log.Printf("✓ Channel %s reserved %d units of product %d (%s)", channel.ID, reservation.Quantity, reservation.ProductID, reservation.ReservationID)
+
+    c.JSON(http.StatusCreated, reservation)
+}
+
+// GetReservation returns one of the channel's reservations
+func (ch *ChannelHandler) GetReservation(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    reservation, err := ch.inventoryRepo.GetChannelReservation(ctx, channelFromContext(c).ID, c.Param("reservation_id"))
+    if err != nil {
+        respondChannelError(c, "failed to get reservation", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, reservation)
+}
+
+// Extend pushes a held reservation's expiry out, capped at the channel's max hold
+func (ch *ChannelHandler) Extend(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.ChannelExtendRequest
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid request body",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    channel := channelFromContext(c)
+    reservation, err := ch.inventoryRepo.ExtendChannelReservation(ctx, channel.ID, c.Param("reservation_id"), channel.HoldFor(req.HoldMinutes))
+    if err != nil {
+        respondChannelError(c, "failed to extend reservation", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, reservation)
+}
+
+// Commit turns a held reservation into a sale and takes the units out of stock
+func (ch *ChannelHandler) Commit(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    channel := channelFromContext(c)
+    reservation, err := ch.inventoryRepo.CommitChannelReservation(ctx, channel.ID, c.Param("reservation_id"))
+    if err != nil {
+        respondChannelError(c, "failed to commit reservation", err)
+        return
+    }
+
+    log.Printf("✓ Channel %s committed reservation %s", channel.ID, reservation.ReservationID)
+
+    c.JSON(http.StatusOK, reservation)
+}
+
+// Release gives held units back to available stock
+func (ch *ChannelHandler) Release(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    channel := channelFromContext(c)
+    reservation, err := ch.inventoryRepo.ReleaseChannelReservation(ctx, channel.ID, c.Param("reservation_id"))
+    if err != nil {
+        respondChannelError(c, "failed to release reservation", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, reservation)
+}
+
+// channelFromContext returns the channel set by ChannelAuthMiddleware
+func channelFromContext(c *gin.Context) *models.SalesChannel {
+    return c.MustGet(middleware.ChannelContextKey).(*models.SalesChannel)
+}
+
+// newChannelKey returns a random API key for a sales channel
+func newChannelKey() (string, error) {
+    b := make([]byte, 24)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return "chk_" + hex.EncodeToString(b), nil
+}
+
+// respondChannelError maps repository errors to HTTP statuses
+func respondChannelError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrChannelNotFound), errors.Is(err, repository.ErrReservationNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrDuplicateChannel),
+        errors.Is(err, repository.ErrReservationNotHeld),
+        errors.Is(err, repository.ErrInsufficientStock):
+        status = http.StatusConflict
+    case errors.Is(err, repository.ErrChannelQuotaExceeded):
+        status = http.StatusTooManyRequests
+    case errors.Is(err, repository.ErrUnknownProduct):
+        status = http.StatusBadRequest
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
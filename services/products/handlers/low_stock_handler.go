@@ -0,0 +1,44 @@
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// LowStockHandler lists the products below their low-stock threshold
+type LowStockHandler struct {
+    lowStockRepo *repository.LowStockRepository
+}
+
+// NewLowStockHandler creates new low stock handler
+func NewLowStockHandler(lowStockRepo *repository.LowStockRepository) *LowStockHandler {
+    return &LowStockHandler{lowStockRepo: lowStockRepo}
+}
+
+// GetLowStock lists the products whose available stock is below their threshold right now,
+// fewest available first; alerted_at is set once LowStock was published for the dip
+func (lh *LowStockHandler) GetLowStock(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    products, err := lh.lowStockRepo.GetLowStockProducts(ctx)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get low stock products",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "products": products,
+        "count":    len(products),
+    })
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
     "context"
+    "errors"
     "log"
     "net/http"
     "strconv"
@@ -10,36 +11,70 @@ import (
     "github.com/gin-gonic/gin"
     "github.com/sanketh-sg/prost/services/products/models"
     "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/audit"
     "github.com/sanketh-sg/prost/shared/db"
-    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/pagination"
+    "github.com/sanketh-sg/prost/shared/reqctx"
 )
 
+// EventPublisher publishes product events; *messaging.Publisher in production
+type EventPublisher interface {
+    PublishProductEvent(ctx context.Context, event interface{}) error
+}
+
 // ProductHandler handles product-related HTTP requests
 type ProductHandler struct {
-    productRepo     *repository.ProductRepository
-    categoryRepo    *repository.CategoryRepository
-    inventoryRepo   *repository.InventoryReservationRepository
+    productRepo     repository.ProductRepositoryInterface
+    categoryRepo    repository.CategoryRepositoryInterface
+    variantRepo     repository.VariantRepositoryInterface
+    attributeRepo   repository.AttributeTemplateRepositoryInterface
+    inventoryRepo   repository.InventoryReservationRepositoryInterface
     idempotencyStore *db.IdempotencyStore
-    eventPublisher  *messaging.Publisher
+    eventPublisher  EventPublisher
+    backInStock     *StockSubscriptionHandler
+    audit           audit.Recorder // nil records no audit entries
 }
 
 // NewProductHandler creates new product handler
 func NewProductHandler(
-    productRepo *repository.ProductRepository,
-    categoryRepo *repository.CategoryRepository,
-    inventoryRepo *repository.InventoryReservationRepository,
+    productRepo repository.ProductRepositoryInterface,
+    categoryRepo repository.CategoryRepositoryInterface,
+    variantRepo repository.VariantRepositoryInterface,
+    attributeRepo repository.AttributeTemplateRepositoryInterface,
+    inventoryRepo repository.InventoryReservationRepositoryInterface,
     idempotencyStore *db.IdempotencyStore,
-    eventPublisher *messaging.Publisher,
+    eventPublisher EventPublisher,
+    backInStock *StockSubscriptionHandler,
 ) *ProductHandler {
     return &ProductHandler{
         productRepo:      productRepo,
         categoryRepo:     categoryRepo,
+        variantRepo:      variantRepo,
+        attributeRepo:    attributeRepo,
         inventoryRepo:    inventoryRepo,
         idempotencyStore: idempotencyStore,
         eventPublisher:   eventPublisher,
+        backInStock:      backInStock,
     }
 }
 
+// SetAuditRecorder makes the handler record product creates, updates and deletes in the audit log
+func (ph *ProductHandler) SetAuditRecorder(recorder audit.Recorder) {
+    ph.audit = recorder
+}
+
+// auditProduct records an action on a product, with the product before and after
+func (ph *ProductHandler) auditProduct(ctx context.Context, action string, id int64, before, after interface{}) {
+    audit.Write(ctx, ph.audit, audit.Entry{
+        Action:     action,
+        TargetType: "product",
+        TargetID:   strconv.FormatInt(id, 10),
+        Before:     audit.JSON(before),
+        After:      audit.JSON(after),
+    })
+}
+
 // Health handles health check
 func (ph *ProductHandler) Health(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{
@@ -51,8 +86,7 @@ func (ph *ProductHandler) Health(c *gin.Context) {
 
 // CreateCategory creates a new category
 func (ph *ProductHandler) CreateCategory(c *gin.Context) {
-    // ctx := context.Background() // Empty context does not hold any values
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
     // Parse request data
     var req models.CreateCategoryRequest
@@ -65,7 +99,18 @@ func (ph *ProductHandler) CreateCategory(c *gin.Context) {
         return
     }
 
-    category := models.NewCategory(req.Name, req.Description)
+    if req.ParentID != nil {
+        if _, err := ph.categoryRepo.GetCategory(ctx, *req.ParentID); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "parent category not found",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    category := models.NewCategory(req.Name, req.Description, req.ParentID)
     if err := ph.categoryRepo.CreateCategory(ctx, category); err != nil {  // Use the created timeout context for database operations
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to create category",
@@ -85,8 +130,7 @@ func (ph *ProductHandler) CreateCategory(c *gin.Context) {
 
 // GetCategory retrieves a category
 func (ph *ProductHandler) GetCategory(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -114,10 +158,15 @@ func (ph *ProductHandler) GetCategory(c *gin.Context) {
 
 // GetCategories retrieves all categories
 func (ph *ProductHandler) GetCategories(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
+    // ?first= and ?after= ask for a keyset page instead of the whole list
+    if c.Query("first") != "" || c.Query("after") != "" {
+        ph.getCategoriesPage(ctx, c)
+        return
+    }
+
     categories, err := ph.categoryRepo.GetAllCategories(ctx)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -134,10 +183,54 @@ func (ph *ProductHandler) GetCategories(c *gin.Context) {
     })
 }
 
+// getCategoriesPage lists one keyset page of categories, newest first, as a Relay-style connection
+func (ph *ProductHandler) getCategoriesPage(ctx context.Context, c *gin.Context) {
+    page, err := pagination.ParsePage(c.Query("first"), c.Query("after"), models.DefaultCategoryPageSize, models.MaxCategoryPageSize)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid paging",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    categories, err := ph.categoryRepo.GetCategoriesPage(ctx, page)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get categories",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, pagination.NewConnection(page, categories, models.CategoryCursor))
+}
+
+// GetCategoryTree retrieves all categories nested under their parents, with product counts
+func (ph *ProductHandler) GetCategoryTree(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    tree, err := ph.categoryRepo.GetCategoryTree(ctx)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get category tree",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "categories": tree,
+    })
+}
+
 // CreateProduct creates a new product
 func (ph *ProductHandler) CreateProduct(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     var req models.CreateProductRequest
@@ -159,6 +252,19 @@ func (ph *ProductHandler) CreateProduct(c *gin.Context) {
         req.Stock,
         req.ImageURL,
     )
+    if req.Warehouse != "" {
+        product.Warehouse = req.Warehouse
+    }
+    if req.FulfillmentType != "" {
+        product.FulfillmentType = req.FulfillmentType
+    }
+    if req.Attributes != nil {
+        product.Attributes = models.MergeAttributes(nil, req.Attributes)
+    }
+    product.LowStockThreshold = req.LowStockThreshold
+    if !ph.checkAttributes(ctx, c, product.CategoryID, product.Attributes) {
+        return
+    }
 
     if err := ph.productRepo.CreateProduct(ctx, product); err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -169,22 +275,23 @@ func (ph *ProductHandler) CreateProduct(c *gin.Context) {
         return
     }
 
-    // // Publish ProductCreated event not required for now
-    // event := events.ProductCreatedEvent{
-    //     BaseEvent: events.NewBaseEvent("ProductCreated", strconv.FormatInt(product.ID, 10), "product", ""),
-    //     Name:      product.Name,
-    //     Description: product.Description,
-    //     Price:     product.Price,
-    //     SKU:       product.SKU,
-    //     CategoryID: product.CategoryID,
-    //     ImageURL:  product.ImageURL,
-    // }
+    // Publish ProductCreated so caches (gateway catalog cache) pick up the new product
+    event := events.ProductCreatedEvent{
+        BaseEvent:   events.NewBaseEvent("ProductCreated", strconv.FormatInt(product.ID, 10), "product", ""),
+        Name:        product.Name,
+        Description: product.Description,
+        Price:       product.Price,
+        SKU:         product.SKU,
+        CategoryID:  product.CategoryID,
+        ImageURL:    product.ImageURL,
+    }
 
-    // if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
-    //     log.Printf("⚠️  Failed to publish ProductCreated event: %v", err)
-    // }
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish ProductCreated event: %v", err)
+    }
 
     log.Printf("✓ Product created: %s (ID: %d)", product.Name, product.ID)
+    ph.auditProduct(ctx, audit.ActionProductCreated, product.ID, nil, product)
 
     c.JSON(http.StatusCreated, gin.H{
         "message": "Product created successfully",
@@ -194,8 +301,7 @@ func (ph *ProductHandler) CreateProduct(c *gin.Context) {
 
 // GetProduct retrieves a product
 func (ph *ProductHandler) GetProduct(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -218,13 +324,25 @@ func (ph *ProductHandler) GetProduct(c *gin.Context) {
         return
     }
 
+    variants, err := ph.variantRepo.GetVariantsByProduct(ctx, id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get variants",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+    product.Variants = variants
+
     c.JSON(http.StatusOK, product)
 }
 
-// GetProducts retrieves all products
+// GetProducts retrieves all products; category_id filters by category, and with
+// include_subcategories=true by the category and all its subcategories.
+// attr[name]=value, attr_min[name]=n and attr_max[name]=n filter by attributes.
 func (ph *ProductHandler) GetProducts(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     var categoryID *int64
@@ -235,7 +353,32 @@ func (ph *ProductHandler) GetProducts(c *gin.Context) {
         }
     }
 
-    products, err := ph.productRepo.GetAllProducts(ctx, categoryID)
+    filters, err := models.ParseAttributeFilters(c.QueryMap("attr"), c.QueryMap("attr_min"), c.QueryMap("attr_max"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid attribute filter",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    includeSubcategories := c.Query("include_subcategories") == "true"
+
+    // ?first= and ?after= ask for a keyset page instead of the whole list
+    if c.Query("first") != "" || c.Query("after") != "" {
+        ph.getProductsPage(ctx, c, categoryID, includeSubcategories, filters)
+        return
+    }
+
+    var products []*models.Product
+    if len(filters) > 0 {
+        products, err = ph.productRepo.GetProductsByAttributes(ctx, categoryID, includeSubcategories, filters)
+    } else if categoryID != nil && includeSubcategories {
+        products, err = ph.productRepo.GetProductsInCategoryTree(ctx, *categoryID)
+    } else {
+        products, err = ph.productRepo.GetAllProducts(ctx, categoryID)
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to get products",
@@ -251,10 +394,34 @@ func (ph *ProductHandler) GetProducts(c *gin.Context) {
     })
 }
 
+// getProductsPage lists one keyset page of products, newest first, as a Relay-style connection
+func (ph *ProductHandler) getProductsPage(ctx context.Context, c *gin.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) {
+    page, err := pagination.ParsePage(c.Query("first"), c.Query("after"), models.DefaultProductPageSize, models.MaxProductPageSize)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid paging",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    products, err := ph.productRepo.GetProductsPage(ctx, categoryID, includeSubcategories, filters, page)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get products",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, pagination.NewConnection(page, products, models.ProductCursor))
+}
+
 // UpdateProduct updates a product
 func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -286,6 +453,7 @@ func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
         })
         return
     }
+    before := audit.JSON(product) // the fields below change product in place
 
     // Update fields if provided
     if req.Name != "" {
@@ -303,6 +471,23 @@ func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
     if req.ImageURL != "" {
         product.ImageURL = req.ImageURL
     }
+    if req.Warehouse != "" {
+        product.Warehouse = req.Warehouse
+    }
+    if req.FulfillmentType != "" {
+        product.FulfillmentType = req.FulfillmentType
+    }
+    if req.LowStockThreshold != nil {
+        product.LowStockThreshold = *req.LowStockThreshold
+    }
+    // Only checked when attributes change, so a template made required later doesn't block
+    // unrelated updates of existing products
+    if req.Attributes != nil {
+        product.Attributes = models.MergeAttributes(product.Attributes, req.Attributes)
+        if !ph.checkAttributes(ctx, c, product.CategoryID, product.Attributes) {
+            return
+        }
+    }
 
     if err := ph.productRepo.UpdateProduct(ctx, product); err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -313,20 +498,24 @@ func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
         return
     }
 
-    // // Publish ProductUpdated event not required for now
-    // event := events.ProductUpdatedEvent{
-    //     BaseEvent: events.NewBaseEvent("ProductUpdated", strconv.FormatInt(product.ID, 10), "product", ""),
-    //     Name:      product.Name,
-    //     Description: product.Description,
-    //     Price:     product.Price,
-    //     ImageURL:  product.ImageURL,
-    // }
+    // Publish ProductUpdated so caches drop the old details
+    event := events.ProductUpdatedEvent{
+        BaseEvent:   events.NewBaseEvent("ProductUpdated", strconv.FormatInt(product.ID, 10), "product", ""),
+        Name:        product.Name,
+        Description: product.Description,
+        Price:       product.Price,
+        ImageURL:    product.ImageURL,
+    }
+
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish ProductUpdated event: %v", err)
+    }
 
-    // if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
-    //     log.Printf("⚠️  Failed to publish ProductUpdated event: %v", err)
-    // }
+    // A stock adjustment can bring the product back
+    ph.backInStock.NotifyBackInStock(ctx, product.ID, product.StockQuantity, "")
 
     log.Printf("✓ Product updated: %s (ID: %d)", product.Name, product.ID)
+    ph.auditProduct(ctx, audit.ActionProductUpdated, product.ID, before, product)
 
     c.JSON(http.StatusOK, gin.H{
         "message": "Product updated successfully",
@@ -336,8 +525,7 @@ func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
 
 // DeleteProduct deletes a product
 func (ph *ProductHandler) DeleteProduct(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -350,6 +538,11 @@ func (ph *ProductHandler) DeleteProduct(c *gin.Context) {
         return
     }
 
+    var before *models.Product
+    if ph.audit != nil {
+        before, _ = ph.productRepo.GetProduct(ctx, id)
+    }
+
     if err := ph.productRepo.DeleteProduct(ctx, id); err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to delete product",
@@ -359,17 +552,103 @@ func (ph *ProductHandler) DeleteProduct(c *gin.Context) {
         return
     }
 
+    event := events.ProductDeletedEvent{
+        BaseEvent: events.NewBaseEvent("ProductDeleted", strconv.FormatInt(id, 10), "product", ""),
+    }
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish ProductDeleted event: %v", err)
+    }
+
     log.Printf("✓ Product deleted: ID: %d", id)
+    ph.auditProduct(ctx, audit.ActionProductDeleted, id, before, nil)
 
     c.JSON(http.StatusOK, gin.H{
         "message": "Product deleted successfully",
     })
 }
 
+// GetDeletedProducts lists soft-deleted products so admins can restore them
+func (ph *ProductHandler) GetDeletedProducts(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    products, err := ph.productRepo.GetDeletedProducts(ctx)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get deleted products",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "products": products,
+        "count":    len(products),
+    })
+}
+
+// RestoreProduct brings a soft-deleted product back into the catalog
+func (ph *ProductHandler) RestoreProduct(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if err := ph.productRepo.RestoreProduct(ctx, id); err != nil {
+        status := http.StatusInternalServerError
+        switch {
+        case errors.Is(err, repository.ErrUnknownProduct):
+            status = http.StatusNotFound
+        case errors.Is(err, repository.ErrProductNotDeleted):
+            status = http.StatusConflict
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to restore product",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    product, err := ph.productRepo.GetProduct(ctx, id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get restored product",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    event := events.ProductRestoredEvent{
+        BaseEvent: events.NewBaseEvent("ProductRestored", strconv.FormatInt(id, 10), "product", ""),
+        Name:      product.Name,
+        Price:     product.Price,
+    }
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish ProductRestored event: %v", err)
+    }
+
+    log.Printf("✓ Product restored: %s (ID: %d)", product.Name, id)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Product restored successfully",
+        "product": product,
+    })
+}
+
 // GetInventory gets current inventory for a product
 func (ph *ProductHandler) GetInventory(c *gin.Context) {
-    // ctx := context.Background()
-    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    ctx, cancel := reqctx.New(c.Request)
     defer cancel()
 
     productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
@@ -392,7 +671,19 @@ func (ph *ProductHandler) GetInventory(c *gin.Context) {
         return
     }
 
-    reserved, err := ph.inventoryRepo.GetProductReservations(ctx, productID)
+    // With variant_id, the variant's own stock
+    if variantIDStr := c.Query("variant_id"); variantIDStr != "" {
+        ph.getVariantInventory(ctx, c, product, variantIDStr)
+        return
+    }
+
+    ph.respondInventory(ctx, c, http.StatusOK, product, nil)
+}
+
+// respondInventory writes a product's stock, reservations and availability, with extra
+// fields merged in
+func (ph *ProductHandler) respondInventory(ctx context.Context, c *gin.Context, status int, product *models.Product, extra gin.H) {
+    reserved, err := ph.inventoryRepo.GetProductReservations(ctx, product.ID)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "failed to get reservations",
@@ -404,10 +695,304 @@ func (ph *ProductHandler) GetInventory(c *gin.Context) {
 
     available := product.StockQuantity - reserved
 
-    c.JSON(http.StatusOK, gin.H{
-        "product_id": productID,
+    body := gin.H{
+        "product_id": product.ID,
         "total_stock": product.StockQuantity,
         "reserved": reserved,
         "available": available,
+    }
+    for k, v := range extra {
+        body[k] = v
+    }
+    c.JSON(status, body)
+}
+
+// ReserveInventory holds units of a product by hand (admin); a shortage is a 409 with the
+// units still available. The hold expires after ttl_seconds (default 24h, at most 7 days).
+func (ph *ProductHandler) ReserveInventory(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.StockHoldRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    hold, err := ph.inventoryRepo.HoldStock(ctx, req.ProductID, req.Quantity, req.TTL())
+    var shortage *repository.StockShortageError
+    if errors.As(err, &shortage) {
+        c.JSON(http.StatusConflict, gin.H{
+            "error":     "insufficient stock",
+            "message":   shortage.Error(),
+            "code":      http.StatusConflict,
+            "available": max(shortage.Available, 0),
+        })
+        return
+    }
+    if err != nil {
+        respondCartLockError(c, "failed to hold stock", err)
+        return
+    }
+
+    product, err := ph.productRepo.GetProduct(ctx, req.ProductID)
+    if err != nil {
+        respondCartLockError(c, "failed to get product", err)
+        return
+    }
+
+    log.Printf("✓ Held %d unit(s) of product %d until %s", hold.Quantity, hold.ProductID, hold.ExpiresAt)
+
+    ph.respondInventory(ctx, c, http.StatusCreated, product, gin.H{
+        "reservation_id": hold.ReservationID,
+        "expires_at":     hold.ExpiresAt,
+    })
+}
+
+// ReleaseInventory gives back up to quantity units of a product's admin holds (see
+// ReserveInventory); releasing more than is held releases what there is
+func (ph *ProductHandler) ReleaseInventory(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.StockHoldRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    product, err := ph.productRepo.GetProduct(ctx, req.ProductID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    released, err := ph.inventoryRepo.ReleaseHeldStock(ctx, req.ProductID, req.Quantity)
+    if err != nil {
+        respondCartLockError(c, "failed to release stock", err)
+        return
+    }
+
+    log.Printf("✓ Released %d held unit(s) of product %d", released, req.ProductID)
+
+    ph.respondInventory(ctx, c, http.StatusOK, product, gin.H{"released": released})
+}
+
+// AdjustInventory changes a product's (or variant's) stock by delta for a reason (damage,
+// recount, return), e.g. POST /inventory/42/adjust {"delta": -3, "reason": "damage"}.
+// The stock change and its inventory movement commit together; the audit entry and the
+// StockAdjusted event follow. Taking out more than is in stock is a 409.
+func (ph *ProductHandler) AdjustInventory(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.StockAdjustmentRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if err := req.Validate(); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid stock adjustment",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    actorID := audit.ActorFrom(ctx).ID
+    movement, err := ph.inventoryRepo.AdjustStock(ctx, productID, &req, actorID)
+    if err != nil {
+        respondStockAdjustmentError(c, err)
+        return
+    }
+
+    event := events.StockAdjustedEvent{
+        BaseEvent:     events.NewBaseEvent("StockAdjusted", strconv.FormatInt(productID, 10), "product", ""),
+        ProductID:     productID,
+        VariantID:     movement.VariantID,
+        Delta:         movement.Delta,
+        StockQuantity: movement.StockAfter,
+        Reason:        movement.Reason,
+        MovementID:    movement.ID,
+        AdjustedBy:    actorID,
+    }
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish StockAdjusted event: %v", err)
+    }
+
+    // Subscribers wait on the product's own stock, not a variant's
+    if movement.VariantID == nil && movement.Delta > 0 {
+        ph.backInStock.NotifyBackInStock(ctx, productID, movement.StockAfter, "")
+    }
+
+    audit.Write(ctx, ph.audit, audit.Entry{
+        Action:     audit.ActionInventoryAdjusted,
+        TargetType: "product",
+        TargetID:   strconv.FormatInt(productID, 10),
+        Before:     audit.JSON(gin.H{"stock_quantity": movement.StockAfter - movement.Delta}),
+        After:      audit.JSON(gin.H{"stock_quantity": movement.StockAfter}),
+        Details:    audit.JSON(movement),
+    })
+
+    log.Printf("✓ Stock of product %d adjusted by %d (%s), now %d", productID, movement.Delta, movement.Reason, movement.StockAfter)
+
+    c.JSON(http.StatusCreated, gin.H{
+        "message":  "Stock adjusted successfully",
+        "movement": movement,
+    })
+}
+
+// respondStockAdjustmentError maps AdjustStock errors: stock that would go below zero is a 409
+// with what is in stock, an unknown product or variant a 404
+func respondStockAdjustmentError(c *gin.Context, err error) {
+    var shortage *repository.StockShortageError
+    if errors.As(err, &shortage) {
+        c.JSON(http.StatusConflict, gin.H{
+            "error":     "insufficient stock",
+            "message":   shortage.Error(),
+            "code":      http.StatusConflict,
+            "available": max(shortage.Available, 0),
+        })
+        return
+    }
+
+    status := db.ErrorStatus(err)
+    msg := "failed to adjust stock"
+    if errors.Is(err, repository.ErrUnknownProduct) {
+        status = http.StatusNotFound
+        msg = "product not found"
+    }
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
+
+// GetInventoryMovements lists a product's audited stock changes, newest first; ?variant_id=
+// narrows it to one variant and ?limit= caps the count (default 50, at most 200)
+func (ph *ProductHandler) GetInventoryMovements(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var variantID *int64
+    if raw := c.Query("variant_id"); raw != "" {
+        id, err := strconv.ParseInt(raw, 10, 64)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid variant id",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        variantID = &id
+    }
+
+    limit, err := models.ParseMovementLimit(c.Query("limit"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid limit",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := ph.productRepo.GetProduct(ctx, productID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    movements, err := ph.inventoryRepo.GetProductMovements(ctx, productID, variantID, limit)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get inventory movements",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "product_id": productID,
+        "movements":  movements,
+        "count":      len(movements),
+    })
+}
+
+// GetOrderReservations lists the stock reservations taken for an order, oldest first, in any
+// status; support tooling uses it to see whether an order's stock was held, committed or let go
+func (ph *ProductHandler) GetOrderReservations(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid order id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    reservations, err := ph.inventoryRepo.GetReservationsByOrderID(ctx, orderID)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get reservations",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "order_id":     orderID,
+        "reservations": reservations,
+        "count":        len(reservations),
     })
 }
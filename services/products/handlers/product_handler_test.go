@@ -0,0 +1,303 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/audit"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+type productMocks struct {
+    products     *MockProductRepository
+    categories   *MockCategoryRepository
+    variants     *MockVariantRepository
+    attributes   *MockAttributeTemplateRepository
+    reservations *MockInventoryReservationRepository
+    publisher    *MockPublisher
+}
+
+func newTestProductHandler() (*ProductHandler, *productMocks) {
+    mocks := &productMocks{
+        products:     &MockProductRepository{},
+        categories:   &MockCategoryRepository{},
+        variants:     &MockVariantRepository{},
+        attributes:   &MockAttributeTemplateRepository{},
+        reservations: &MockInventoryReservationRepository{},
+        publisher:    &MockPublisher{},
+    }
+    handler := NewProductHandler(mocks.products, mocks.categories, mocks.variants, mocks.attributes, mocks.reservations, nil, mocks.publisher, nil)
+    return handler, mocks
+}
+
+func TestGetProductReturnsItsVariants(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    mocks.products.GetProductFunc = func(ctx context.Context, id int64) (*models.Product, error) {
+        product := models.NewProduct("Lamp", "", 25, "LAMP-1", nil, 5, "")
+        product.ID = id
+        return product, nil
+    }
+    mocks.variants.GetVariantsByProductFunc = func(ctx context.Context, productID int64) ([]*models.ProductVariant, error) {
+        return []*models.ProductVariant{{ID: 3, ProductID: productID}}, nil
+    }
+
+    rec := serve(t, handler.GetProduct, http.MethodGet, "/products/:id", "/products/7", nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    var product models.Product
+    if err := json.Unmarshal(rec.Body.Bytes(), &product); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if product.ID != 7 || len(product.Variants) != 1 || product.Variants[0].ID != 3 {
+        t.Errorf("product = %+v, want product 7 with variant 3", product)
+    }
+}
+
+func TestGetProductNotFound(t *testing.T) {
+    handler, _ := newTestProductHandler()
+
+    rec := serve(t, handler.GetProduct, http.MethodGet, "/products/:id", "/products/7", nil)
+
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", rec.Code)
+    }
+}
+
+func TestGetProductRejectsABadID(t *testing.T) {
+    handler, _ := newTestProductHandler()
+
+    rec := serve(t, handler.GetProduct, http.MethodGet, "/products/:id", "/products/lamp", nil)
+
+    if rec.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400", rec.Code)
+    }
+}
+
+func TestCreateProductPublishesProductCreated(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    var created *models.Product
+    mocks.products.CreateProductFunc = func(ctx context.Context, product *models.Product) error {
+        product.ID = 11
+        created = product
+        return nil
+    }
+
+    rec := serve(t, handler.CreateProduct, http.MethodPost, "/products", "/products", map[string]interface{}{
+        "name":  "Lamp",
+        "price": 25,
+        "sku":   "LAMP-1",
+        "stock": 5,
+    })
+
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+    }
+    if created == nil || created.SKU != "LAMP-1" || created.StockQuantity != 5 {
+        t.Fatalf("created = %+v, want LAMP-1 with 5 in stock", created)
+    }
+    if len(mocks.publisher.Events) != 1 {
+        t.Fatalf("published %d events, want 1", len(mocks.publisher.Events))
+    }
+    event, ok := mocks.publisher.Events[0].(events.ProductCreatedEvent)
+    if !ok || event.AggregateID != "11" || event.SKU != "LAMP-1" {
+        t.Errorf("published %+v, want ProductCreated for product 11", mocks.publisher.Events[0])
+    }
+}
+
+func TestCreateProductChecksCategoryAttributes(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    mocks.attributes.GetTemplatesForCategoryFunc = func(ctx context.Context, categoryID int64) ([]*models.AttributeTemplate, error) {
+        return []*models.AttributeTemplate{{CategoryID: categoryID, Name: "wattage", Type: "number", Required: true}}, nil
+    }
+    created := false
+    mocks.products.CreateProductFunc = func(ctx context.Context, product *models.Product) error {
+        created = true
+        return nil
+    }
+
+    rec := serve(t, handler.CreateProduct, http.MethodPost, "/products", "/products", map[string]interface{}{
+        "name":        "Lamp",
+        "price":       25,
+        "sku":         "LAMP-1",
+        "stock":       5,
+        "category_id": 2,
+    })
+
+    if rec.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400: %s", rec.Code, rec.Body)
+    }
+    if created || len(mocks.publisher.Events) != 0 {
+        t.Error("a product missing a required attribute was created")
+    }
+}
+
+func TestGetInventorySubtractsReservations(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    mocks.products.GetProductFunc = func(ctx context.Context, id int64) (*models.Product, error) {
+        product := models.NewProduct("Lamp", "", 25, "LAMP-1", nil, 5, "")
+        product.ID = id
+        return product, nil
+    }
+    mocks.reservations.GetProductReservationsFunc = func(ctx context.Context, productID int64) (int, error) {
+        return 2, nil
+    }
+
+    rec := serve(t, handler.GetInventory, http.MethodGet, "/inventory/:product_id", "/inventory/7", nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    var body struct {
+        TotalStock int `json:"total_stock"`
+        Reserved   int `json:"reserved"`
+        Available  int `json:"available"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if body.TotalStock != 5 || body.Reserved != 2 || body.Available != 3 {
+        t.Errorf("inventory = %+v, want 5 in stock, 2 reserved, 3 available", body)
+    }
+}
+
+// auditRecorder keeps the audit entries it's asked to record
+type auditRecorder struct {
+    entries []audit.Entry
+}
+
+func (r *auditRecorder) Record(ctx context.Context, entry audit.Entry) error {
+    r.entries = append(r.entries, entry)
+    return nil
+}
+
+func TestAdjustInventoryRecordsTheMovement(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    recorder := &auditRecorder{}
+    handler.SetAuditRecorder(recorder)
+    mocks.reservations.AdjustStockFunc = func(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+        if productID != 7 || req.Delta != -3 || req.Reason != models.MovementReasonDamage || req.Note != "dropped" {
+            t.Errorf("adjusted product %d by %+v, want product 7 by -3 for damage", productID, req)
+        }
+        return &models.InventoryMovement{ID: 90, ProductID: productID, Delta: req.Delta, StockAfter: 2, Reason: req.Reason}, nil
+    }
+
+    rec := serve(t, handler.AdjustInventory, http.MethodPost, "/inventory/:product_id/adjust", "/inventory/7/adjust", map[string]interface{}{
+        "delta":  -3,
+        "reason": "damage",
+        "note":   "dropped",
+    })
+
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+    }
+    if len(mocks.publisher.Events) != 1 {
+        t.Fatalf("published %d events, want 1", len(mocks.publisher.Events))
+    }
+    event, ok := mocks.publisher.Events[0].(events.StockAdjustedEvent)
+    if !ok || event.ProductID != 7 || event.Delta != -3 || event.StockQuantity != 2 || event.MovementID != 90 {
+        t.Errorf("published %+v, want StockAdjusted for product 7 down to 2", mocks.publisher.Events[0])
+    }
+    if len(recorder.entries) != 1 || recorder.entries[0].Action != audit.ActionInventoryAdjusted || recorder.entries[0].TargetID != "7" {
+        t.Errorf("audit entries = %+v, want one inventory.adjusted for product 7", recorder.entries)
+    }
+}
+
+func TestAdjustInventoryRejectsABadAdjustment(t *testing.T) {
+    for name, body := range map[string]map[string]interface{}{
+        "zero delta":     {"delta": 0, "reason": "recount"},
+        "unknown reason": {"delta": 2, "reason": "found"},
+    } {
+        t.Run(name, func(t *testing.T) {
+            handler, mocks := newTestProductHandler()
+            mocks.reservations.AdjustStockFunc = func(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+                t.Error("an invalid adjustment reached the repository")
+                return nil, nil
+            }
+
+            rec := serve(t, handler.AdjustInventory, http.MethodPost, "/inventory/:product_id/adjust", "/inventory/7/adjust", body)
+
+            if rec.Code != http.StatusBadRequest {
+                t.Errorf("status = %d, want 400: %s", rec.Code, rec.Body)
+            }
+        })
+    }
+}
+
+func TestAdjustInventoryBelowZeroIsAConflict(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    mocks.reservations.AdjustStockFunc = func(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+        return nil, &repository.StockShortageError{ProductID: productID, Requested: -req.Delta, Available: 2}
+    }
+
+    rec := serve(t, handler.AdjustInventory, http.MethodPost, "/inventory/:product_id/adjust", "/inventory/7/adjust", map[string]interface{}{
+        "delta":  -5,
+        "reason": "recount",
+    })
+
+    if rec.Code != http.StatusConflict {
+        t.Errorf("status = %d, want 409: %s", rec.Code, rec.Body)
+    }
+    if len(mocks.publisher.Events) != 0 {
+        t.Error("a refused adjustment published an event")
+    }
+}
+
+func TestAdjustInventoryOfAnUnknownProduct(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    mocks.reservations.AdjustStockFunc = func(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+        return nil, fmt.Errorf("%w: %d", repository.ErrUnknownProduct, productID)
+    }
+
+    rec := serve(t, handler.AdjustInventory, http.MethodPost, "/inventory/:product_id/adjust", "/inventory/7/adjust", map[string]interface{}{
+        "delta":  4,
+        "reason": "return",
+    })
+
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404: %s", rec.Code, rec.Body)
+    }
+}
+
+func TestGetCategoriesPage(t *testing.T) {
+    handler, mocks := newTestProductHandler()
+    var requested pagination.Page
+    mocks.categories.GetCategoriesPageFunc = func(ctx context.Context, page pagination.Page) ([]*models.Category, error) {
+        requested = page
+        // One more than first: there is a next page
+        return []*models.Category{{ID: 3, Name: "Lamps"}, {ID: 2, Name: "Desks"}, {ID: 1, Name: "Chairs"}}, nil
+    }
+
+    rec := serve(t, handler.GetCategories, http.MethodGet, "/categories", "/categories?first=2", nil)
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+    }
+    if requested.First != 2 || requested.After != nil {
+        t.Errorf("page = %+v, want the first 2", requested)
+    }
+    var conn pagination.Connection[models.Category]
+    if err := json.Unmarshal(rec.Body.Bytes(), &conn); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if len(conn.Edges) != 2 || !conn.PageInfo.HasNextPage || conn.PageInfo.EndCursor != conn.Edges[1].Cursor {
+        t.Errorf("connection = %+v, want 2 edges and a next page", conn)
+    }
+}
+
+func TestGetCategoriesPageRejectsABadCursor(t *testing.T) {
+    handler, _ := newTestProductHandler()
+
+    rec := serve(t, handler.GetCategories, http.MethodGet, "/categories", "/categories?after=nope", nil)
+
+    if rec.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400", rec.Code)
+    }
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+    "errors"
+    "io"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/services/products/storage"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ProductImageHandler handles product image uploads
+type ProductImageHandler struct {
+    imageRepo      *repository.ProductImageRepository
+    productRepo    *repository.ProductRepository
+    store          storage.Store
+    eventPublisher *messaging.Publisher
+    clock          clock.Clock
+    maxBytes       int64
+}
+
+// NewProductImageHandler creates new product image handler
+func NewProductImageHandler(imageRepo *repository.ProductImageRepository, productRepo *repository.ProductRepository, store storage.Store, eventPublisher *messaging.Publisher, clk clock.Clock, maxBytes int64) *ProductImageHandler {
+    if maxBytes <= 0 {
+        maxBytes = models.DefaultMaxImageBytes
+    }
+    return &ProductImageHandler{
+        imageRepo:      imageRepo,
+        productRepo:    productRepo,
+        store:          store,
+        eventPublisher: eventPublisher,
+        clock:          clk,
+        maxBytes:       maxBytes,
+    }
+}
+
+// UploadImage stores a multipart image (form field "image") for a product and returns it with
+// its public URL. The type is sniffed from the content: JPEG, PNG, GIF or WebP, else 415.
+// The first image, or one sent with primary=true, becomes the product's image_url.
+func (ih *ProductImageHandler) UploadImage(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    fileHeader, err := c.FormFile("image")
+    if err != nil {
+        var tooLarge *http.MaxBytesError
+        if errors.As(err, &tooLarge) {
+            ih.imageTooLarge(c)
+            return
+        }
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "image is required",
+            Message: "send the file as the multipart form field \"image\"",
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if fileHeader.Size > ih.maxBytes {
+        ih.imageTooLarge(c)
+        return
+    }
+
+    file, err := fileHeader.Open()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "failed to read image",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    defer file.Close()
+
+    data, err := io.ReadAll(io.LimitReader(file, ih.maxBytes+1))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "failed to read image",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if int64(len(data)) > ih.maxBytes {
+        ih.imageTooLarge(c)
+        return
+    }
+    if len(data) == 0 {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error: "image is empty",
+            Code:  http.StatusBadRequest,
+        })
+        return
+    }
+
+    contentType, ext, ok := models.DetectImageType(data)
+    if !ok {
+        c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+            Error:   "unsupported image type",
+            Message: contentType + " is not one of image/jpeg, image/png, image/gif, image/webp",
+            Code:    http.StatusUnsupportedMediaType,
+        })
+        return
+    }
+
+    product, err := ih.productRepo.GetProduct(ctx, productID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    key := storage.ProductImageKey(productID, ext)
+    if err := ih.store.Put(ctx, key, contentType, data); err != nil {
+        log.Printf("❌ Failed to store image for product %d: %v", productID, err)
+        c.JSON(http.StatusBadGateway, models.ErrorResponse{
+            Error:   "failed to store image",
+            Message: err.Error(),
+            Code:    http.StatusBadGateway,
+        })
+        return
+    }
+
+    image := &models.ProductImage{
+        ProductID:   productID,
+        StorageKey:  key,
+        URL:         ih.store.URL(key),
+        ContentType: contentType,
+        SizeBytes:   int64(len(data)),
+        CreatedAt:   ih.clock.Now(),
+    }
+    primary, _ := strconv.ParseBool(c.PostForm("primary"))
+    if err := ih.imageRepo.AddImage(ctx, image, primary); err != nil {
+        // Why: the key is new and unreferenced, so the file would only be an orphan
+        if delErr := ih.store.Delete(ctx, key); delErr != nil {
+            log.Printf("⚠️  Failed to delete unrecorded image %s: %v", key, delErr)
+        }
+        status := http.StatusInternalServerError
+        if errors.Is(err, repository.ErrUnknownProduct) {
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to save image",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    if image.Primary {
+        // Publish ProductUpdated so caches drop the old image_url
+        event := events.ProductUpdatedEvent{
+            BaseEvent:   events.NewBaseEvent("ProductUpdated", strconv.FormatInt(productID, 10), "product", ""),
+            Name:        product.Name,
+            Description: product.Description,
+            Price:       product.Price,
+            ImageURL:    image.URL,
+        }
+        if err := ih.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+            log.Printf("⚠️  Failed to publish ProductUpdated event: %v", err)
+        }
+    }
+
+    log.Printf("✓ Image uploaded for product %d: %s", productID, image.URL)
+
+    c.JSON(http.StatusCreated, image)
+}
+
+// GetImages lists a product's uploaded images, oldest first
+func (ih *ProductImageHandler) GetImages(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    images, err := ih.imageRepo.GetImagesByProduct(ctx, productID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get product images",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "images": images,
+        "count":  len(images),
+    })
+}
+
+func (ih *ProductImageHandler) imageTooLarge(c *gin.Context) {
+    c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+        Error:   "image too large",
+        Message: "limit is " + strconv.FormatInt(ih.maxBytes, 10) + " bytes",
+        Code:    http.StatusRequestEntityTooLarge,
+    })
+}
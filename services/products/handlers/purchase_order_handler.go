@@ -0,0 +1,227 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// PurchaseOrderHandler handles supplier purchase orders and stock receipts
+type PurchaseOrderHandler struct {
+    poRepo         *repository.PurchaseOrderRepository
+    eventPublisher *messaging.Publisher
+    backInStock    *StockSubscriptionHandler
+}
+
+// NewPurchaseOrderHandler creates new purchase order handler
+func NewPurchaseOrderHandler(poRepo *repository.PurchaseOrderRepository, eventPublisher *messaging.Publisher, backInStock *StockSubscriptionHandler) *PurchaseOrderHandler {
+    return &PurchaseOrderHandler{
+        poRepo:         poRepo,
+        eventPublisher: eventPublisher,
+        backInStock:    backInStock,
+    }
+}
+
+// CreatePurchaseOrder records expected inbound stock from a supplier
+func (ph *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.CreatePurchaseOrderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    po := models.NewPurchaseOrder(req.Supplier, req.Reference, req.ExpectedAt, req.Notes)
+    for _, line := range req.Lines {
+        po.Lines = append(po.Lines, models.PurchaseOrderLine{
+            ProductID:        line.ProductID,
+            ExpectedQuantity: line.ExpectedQuantity,
+        })
+    }
+
+    if err := ph.poRepo.CreatePurchaseOrder(ctx, po); err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, repository.ErrUnknownProduct) {
+            status = http.StatusBadRequest
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to create purchase order",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    log.Printf("✓ Purchase order created: %d (supplier: %s, lines: %d)", po.ID, po.Supplier, len(po.Lines))
+
+    c.JSON(http.StatusCreated, po)
+}
+
+// GetPurchaseOrder returns a purchase order with its lines and receipts
+func (ph *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parsePurchaseOrderID(c)
+    if !ok {
+        return
+    }
+
+    po, err := ph.poRepo.GetPurchaseOrder(ctx, id)
+    if err != nil {
+        respondPurchaseOrderError(c, "failed to get purchase order", err)
+        return
+    }
+
+    receipts, err := ph.poRepo.GetReceipts(ctx, id)
+    if err != nil {
+        respondPurchaseOrderError(c, "failed to get receipts", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "purchase_order": po,
+        "receipts":       receipts,
+        "discrepancies":  po.Discrepancies(),
+    })
+}
+
+// GetPurchaseOrders lists purchase orders, optionally filtered by ?status=
+func (ph *PurchaseOrderHandler) GetPurchaseOrders(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orders, err := ph.poRepo.ListPurchaseOrders(ctx, c.Query("status"))
+    if err != nil {
+        respondPurchaseOrderError(c, "failed to list purchase orders", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "purchase_orders": orders,
+        "count":           len(orders),
+    })
+}
+
+// ReceivePurchaseOrder adds delivered stock against a purchase order and announces
+// each replenished product so backorders can be allocated
+func (ph *PurchaseOrderHandler) ReceivePurchaseOrder(c *gin.Context) {
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout)
+    defer cancel()
+
+    id, ok := parsePurchaseOrderID(c)
+    if !ok {
+        return
+    }
+
+    var req models.ReceivePurchaseOrderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    po, received, err := ph.poRepo.ReceivePurchaseOrder(ctx, id, &req)
+    if err != nil {
+        respondPurchaseOrderError(c, "failed to receive purchase order", err)
+        return
+    }
+
+    log.Printf("✓ Purchase order %d received: %d items, status %s", id, len(received), po.Status)
+
+    // Why: stock is already committed; a failed publish is logged, not returned,
+    // so the receiving clerk isn't asked to book the same delivery twice
+    correlationID := fmt.Sprintf("po-%d", id)
+    for _, stock := range received {
+        event := events.StockReplenishedEvent{
+            BaseEvent:       events.NewBaseEvent("StockReplenished", strconv.FormatInt(stock.ProductID, 10), "product", correlationID),
+            ProductID:       stock.ProductID,
+            Quantity:        stock.Quantity,
+            StockQuantity:   stock.StockQuantity,
+            PurchaseOrderID: id,
+        }
+        if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+            log.Printf("⚠️  Failed to publish StockReplenished for product %d: %v", stock.ProductID, err)
+        }
+        ph.backInStock.NotifyBackInStock(ctx, stock.ProductID, stock.StockQuantity, correlationID)
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "purchase_order": po,
+        "received":       received,
+        "discrepancies":  po.Discrepancies(),
+    })
+}
+
+// CancelPurchaseOrder cancels an open purchase order with nothing received
+func (ph *PurchaseOrderHandler) CancelPurchaseOrder(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parsePurchaseOrderID(c)
+    if !ok {
+        return
+    }
+
+    if err := ph.poRepo.CancelPurchaseOrder(ctx, id); err != nil {
+        respondPurchaseOrderError(c, "failed to cancel purchase order", err)
+        return
+    }
+
+    log.Printf("✓ Purchase order cancelled: %d", id)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Purchase order cancelled successfully",
+    })
+}
+
+func parsePurchaseOrderID(c *gin.Context) (int64, bool) {
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid purchase order id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return 0, false
+    }
+    return id, true
+}
+
+// respondPurchaseOrderError maps repository errors to HTTP statuses
+func respondPurchaseOrderError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrPurchaseOrderNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrPurchaseOrderClosed), errors.Is(err, repository.ErrPurchaseOrderReceiving):
+        status = http.StatusConflict
+    case errors.Is(err, repository.ErrUnknownProduct):
+        status = http.StatusBadRequest
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// maxPlanningDays caps each day count of the reorder report's query
+const maxPlanningDays = 365
+
+// ReorderHandler serves the reorder suggestions report
+type ReorderHandler struct {
+    forecastRepo *repository.ForecastRepository
+    policy       models.ReorderPolicy // defaults for the query parameters
+    windowDays   int
+}
+
+// NewReorderHandler creates new reorder handler
+func NewReorderHandler(forecastRepo *repository.ForecastRepository, policy models.ReorderPolicy, windowDays int) *ReorderHandler {
+    return &ReorderHandler{forecastRepo: forecastRepo, policy: policy, windowDays: windowDays}
+}
+
+// GetReorderSuggestions lists the products to reorder, closest to running out first, from the
+// forecast worker's last run. ?lead_time_days=, ?safety_stock_days= and ?cover_days= override
+// the configured policy for this report only; ReorderSuggested events keep using the config.
+func (rh *ReorderHandler) GetReorderSuggestions(c *gin.Context) {
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout)
+    defer cancel()
+
+    policy := rh.policy
+    for param, target := range map[string]*int{
+        "lead_time_days":    &policy.LeadTimeDays,
+        "safety_stock_days": &policy.SafetyStockDays,
+        "cover_days":        &policy.CoverDays,
+    } {
+        if val := c.Query(param); val != "" {
+            days, err := strconv.Atoi(val)
+            if err != nil || days < 0 || days > maxPlanningDays {
+                c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                    Error:   "invalid " + param,
+                    Message: "must be a number of days between 0 and " + strconv.Itoa(maxPlanningDays),
+                    Code:    http.StatusBadRequest,
+                })
+                return
+            }
+            *target = days
+        }
+    }
+
+    forecasts, err := rh.forecastRepo.ListForecasts(ctx)
+    if err != nil {
+        status := db.ErrorStatus(err)
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to get reorder forecasts",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.NewReorderReport(policy, rh.windowDays, forecasts))
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ReturnHandler handles customer returns: receiving, inspection, disposition and reporting
+type ReturnHandler struct {
+    returnRepo     *repository.ReturnRepository
+    eventPublisher *messaging.Publisher
+    clock          clock.Clock
+    backInStock    *StockSubscriptionHandler
+}
+
+// NewReturnHandler creates new return handler
+func NewReturnHandler(returnRepo *repository.ReturnRepository, eventPublisher *messaging.Publisher, clk clock.Clock, backInStock *StockSubscriptionHandler) *ReturnHandler {
+    return &ReturnHandler{
+        returnRepo:     returnRepo,
+        eventPublisher: eventPublisher,
+        clock:          clk,
+        backInStock:    backInStock,
+    }
+}
+
+// CreateReturn records a received return; the units don't count as stock until restocked
+func (rh *ReturnHandler) CreateReturn(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.CreateReturnRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    ret, err := rh.returnRepo.CreateReturn(ctx, &req)
+    if err != nil {
+        respondReturnError(c, "failed to create return", err)
+        return
+    }
+
+    log.Printf("✓ Return received: %d (product %d, quantity %d)", ret.ID, ret.ProductID, ret.Quantity)
+
+    c.JSON(http.StatusCreated, ret)
+}
+
+// GetReturn returns a return with its ledger entries
+func (rh *ReturnHandler) GetReturn(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseReturnID(c)
+    if !ok {
+        return
+    }
+
+    ret, err := rh.returnRepo.GetReturn(ctx, id)
+    if err != nil {
+        respondReturnError(c, "failed to get return", err)
+        return
+    }
+
+    ledger, err := rh.returnRepo.GetLedger(ctx, id)
+    if err != nil {
+        respondReturnError(c, "failed to get ledger", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "return": ret,
+        "ledger": ledger,
+    })
+}
+
+// GetReturns lists returns, optionally filtered by ?status= and ?product_id=
+func (rh *ReturnHandler) GetReturns(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var productID *int64
+    if val := c.Query("product_id"); val != "" {
+        id, err := strconv.ParseInt(val, 10, 64)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid product_id",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+        productID = &id
+    }
+
+    returns, err := rh.returnRepo.ListReturns(ctx, c.Query("status"), productID)
+    if err != nil {
+        respondReturnError(c, "failed to list returns", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "returns": returns,
+        "count":   len(returns),
+    })
+}
+
+// InspectReturn records the condition of the returned units and the inspector's notes
+func (rh *ReturnHandler) InspectReturn(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseReturnID(c)
+    if !ok {
+        return
+    }
+
+    var req models.InspectReturnRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    ret, err := rh.returnRepo.InspectReturn(ctx, id, &req)
+    if err != nil {
+        respondReturnError(c, "failed to inspect return", err)
+        return
+    }
+
+    log.Printf("✓ Return %d inspected: %s", id, ret.Condition)
+
+    c.JSON(http.StatusOK, ret)
+}
+
+// DispositionReturn restocks, writes off or sends an inspected return for refurbishment
+func (rh *ReturnHandler) DispositionReturn(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseReturnID(c)
+    if !ok {
+        return
+    }
+
+    var req models.DispositionReturnRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    ret, entry, stock, err := rh.returnRepo.DispositionReturn(ctx, id, &req)
+    if err != nil {
+        respondReturnError(c, "failed to disposition return", err)
+        return
+    }
+
+    log.Printf("✓ Return %d dispositioned as %s (stock delta %d)", id, req.Disposition, entry.StockDelta)
+
+    // Why: the disposition is already committed; a failed publish is logged, not returned,
+    // so the same units aren't dispositioned twice
+    rh.publishDisposition(ctx, ret, req, stock)
+
+    c.JSON(http.StatusOK, gin.H{
+        "return":         ret,
+        "ledger_entry":   entry,
+        "stock_quantity": stock,
+    })
+}
+
+// GetReturnRateReport reports returned vs sold units per product.
+// ?from= and ?to= are RFC3339; the default is the last 30 days.
+func (rh *ReturnHandler) GetReturnRateReport(c *gin.Context) {
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout)
+    defer cancel()
+
+    to := rh.clock.Now()
+    from := to.Add(-defaultReportPeriod)
+    for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+        if val := c.Query(param); val != "" {
+            parsed, err := time.Parse(time.RFC3339, val)
+            if err != nil {
+                c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                    Error:   "invalid " + param,
+                    Message: err.Error(),
+                    Code:    http.StatusBadRequest,
+                })
+                return
+            }
+            *target = parsed
+        }
+    }
+
+    report, err := rh.returnRepo.GetReturnRateReport(ctx, from, to)
+    if err != nil {
+        respondReturnError(c, "failed to get return rate report", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, report)
+}
+
+// publishDisposition announces the disposition; a restock is also a StockReplenished so
+// backordered demand can be allocated, and reaches back-in-stock subscribers
+func (rh *ReturnHandler) publishDisposition(ctx context.Context, ret *models.ProductReturn, req models.DispositionReturnRequest, stock int) {
+    aggregateID := strconv.FormatInt(ret.ID, 10)
+    correlationID := fmt.Sprintf("return-%d", ret.ID)
+    disposition := events.ReturnDisposition{
+        ReturnID:  ret.ID,
+        ProductID: ret.ProductID,
+        Quantity:  ret.Quantity,
+        Condition: ret.Condition,
+        Note:      req.Note,
+    }
+    if ret.OrderID != nil {
+        disposition.OrderID = *ret.OrderID
+    }
+
+    var published []interface{}
+    switch req.Disposition {
+    case models.DispositionRestock:
+        published = append(published,
+            events.ReturnRestockedEvent{
+                BaseEvent:         events.NewBaseEvent("ReturnRestocked", aggregateID, "return", correlationID),
+                ReturnDisposition: disposition,
+                StockQuantity:     stock,
+            },
+            events.StockReplenishedEvent{
+                BaseEvent:     events.NewBaseEvent("StockReplenished", strconv.FormatInt(ret.ProductID, 10), "product", correlationID),
+                ProductID:     ret.ProductID,
+                Quantity:      ret.Quantity,
+                StockQuantity: stock,
+                ReturnID:      ret.ID,
+            },
+        )
+    case models.DispositionWriteOff:
+        published = append(published, events.ReturnWrittenOffEvent{
+            BaseEvent:         events.NewBaseEvent("ReturnWrittenOff", aggregateID, "return", correlationID),
+            ReturnDisposition: disposition,
+        })
+    case models.DispositionRefurbish:
+        published = append(published, events.ReturnRefurbishingEvent{
+            BaseEvent:         events.NewBaseEvent("ReturnRefurbishing", aggregateID, "return", correlationID),
+            ReturnDisposition: disposition,
+        })
+    }
+
+    for _, event := range published {
+        if err := rh.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+            log.Printf("⚠️  Failed to publish %T for return %d: %v", event, ret.ID, err)
+        }
+    }
+
+    if req.Disposition == models.DispositionRestock {
+        rh.backInStock.NotifyBackInStock(ctx, ret.ProductID, stock, correlationID)
+    }
+}
+
+func parseReturnID(c *gin.Context) (int64, bool) {
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid return id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return 0, false
+    }
+    return id, true
+}
+
+// respondReturnError maps repository errors to HTTP statuses
+func respondReturnError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrReturnNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrInvalidReturnTransition):
+        status = http.StatusConflict
+    case errors.Is(err, repository.ErrUnknownProduct):
+        status = http.StatusBadRequest
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
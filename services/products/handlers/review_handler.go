@@ -0,0 +1,267 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/pagination"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ReviewHandler handles product reviews and moderation
+type ReviewHandler struct {
+    reviewRepo  *repository.ReviewRepository
+    productRepo *repository.ProductRepository
+}
+
+// NewReviewHandler creates new review handler
+func NewReviewHandler(reviewRepo *repository.ReviewRepository, productRepo *repository.ProductRepository) *ReviewHandler {
+    return &ReviewHandler{
+        reviewRepo:  reviewRepo,
+        productRepo: productRepo,
+    }
+}
+
+// CreateReview adds a review for a product; it stays pending until moderated
+func (rh *ReviewHandler) CreateReview(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.CreateReviewRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := rh.productRepo.GetProduct(ctx, productID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    review := models.NewReview(productID, c.GetString("user_id"), req.Rating, req.Text)
+    if err := rh.reviewRepo.CreateReview(ctx, review); err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, repository.ErrDuplicateReview) {
+            status = http.StatusConflict
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to create review",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    log.Printf("✓ Review created: %d (product: %d, rating: %d)", review.ID, productID, review.Rating)
+
+    c.JSON(http.StatusCreated, review)
+}
+
+// GetReviews lists a product's reviews with paging.
+// Only approved reviews are listed unless ?status= asks for pending or rejected ones.
+func (rh *ReviewHandler) GetReviews(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    status := c.DefaultQuery("status", models.ReviewStatusApproved)
+    switch status {
+    case models.ReviewStatusApproved, models.ReviewStatusPending, models.ReviewStatusRejected:
+    default:
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid status",
+            Message: fmt.Sprintf("status must be one of %s, %s, %s", models.ReviewStatusApproved, models.ReviewStatusPending, models.ReviewStatusRejected),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    // ?first= and ?after= ask for a keyset page instead of ?page=
+    if c.Query("first") != "" || c.Query("after") != "" {
+        rh.getReviewsPage(ctx, c, productID, status)
+        return
+    }
+
+    page, limit, err := parseReviewPage(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid paging",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    reviews, total, err := rh.reviewRepo.GetReviewsByProduct(ctx, productID, status, page, limit)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get reviews",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    summary, err := rh.reviewRepo.GetRatingSummary(ctx, productID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get rating summary",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.ReviewPage{
+        Reviews:       reviews,
+        Count:         len(reviews),
+        Total:         total,
+        Page:          page,
+        Limit:         limit,
+        RatingSummary: summary,
+    })
+}
+
+// getReviewsPage lists one keyset page of a product's reviews, newest first, as a Relay-style
+// connection with the rating summary
+func (rh *ReviewHandler) getReviewsPage(ctx context.Context, c *gin.Context, productID int64, status string) {
+    page, err := pagination.ParsePage(c.Query("first"), c.Query("after"), models.DefaultReviewPageLimit, models.MaxReviewPageLimit)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid paging",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    reviews, total, err := rh.reviewRepo.GetReviewsPageByProduct(ctx, productID, status, page)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get reviews",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    summary, err := rh.reviewRepo.GetRatingSummary(ctx, productID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get rating summary",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.ReviewConnection{
+        Connection:    pagination.NewConnection(page, reviews, models.ReviewCursor),
+        TotalCount:    total,
+        RatingSummary: summary,
+    })
+}
+
+// ModerateReview approves or rejects a review
+func (rh *ReviewHandler) ModerateReview(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid review id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.ModerateReviewRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    review, err := rh.reviewRepo.ModerateReview(ctx, id, req.Status, req.Note)
+    if err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, repository.ErrReviewNotFound) {
+            status = http.StatusNotFound
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to moderate review",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    log.Printf("✓ Review %d %s", review.ID, review.Status)
+
+    c.JSON(http.StatusOK, review)
+}
+
+// parseReviewPage reads ?page= (1-based) and ?limit=, applying defaults and the max limit
+func parseReviewPage(c *gin.Context) (int, int, error) {
+    page, limit := 1, models.DefaultReviewPageLimit
+
+    if raw := c.Query("page"); raw != "" {
+        p, err := strconv.Atoi(raw)
+        if err != nil || p < 1 {
+            return 0, 0, fmt.Errorf("page must be a positive integer")
+        }
+        page = p
+    }
+
+    if raw := c.Query("limit"); raw != "" {
+        l, err := strconv.Atoi(raw)
+        if err != nil || l < 1 {
+            return 0, 0, fmt.Errorf("limit must be a positive integer")
+        }
+        if l > models.MaxReviewPageLimit {
+            l = models.MaxReviewPageLimit
+        }
+        limit = l
+    }
+
+    return page, limit, nil
+}
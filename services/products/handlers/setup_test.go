@@ -0,0 +1,525 @@
+package handlers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+// MockProductRepository is a mock implementation of ProductRepository
+type MockProductRepository struct {
+    CreateProductFunc             func(ctx context.Context, product *models.Product) error
+    GetProductFunc                func(ctx context.Context, id int64) (*models.Product, error)
+    GetProductBySKUFunc           func(ctx context.Context, sku string) (*models.Product, error)
+    GetAllProductsFunc            func(ctx context.Context, categoryID *int64) ([]*models.Product, error)
+    GetProductsInCategoryTreeFunc func(ctx context.Context, categoryID int64) ([]*models.Product, error)
+    GetProductsByIDsFunc          func(ctx context.Context, ids []int64) ([]*models.Product, error)
+    GetProductsByAttributesFunc   func(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) ([]*models.Product, error)
+    GetProductsPageFunc           func(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter, page pagination.Page) ([]*models.Product, error)
+    UpdateProductFunc             func(ctx context.Context, product *models.Product) error
+    DeleteProductFunc             func(ctx context.Context, id int64) error
+    GetDeletedProductsFunc        func(ctx context.Context) ([]*models.Product, error)
+    RestoreProductFunc            func(ctx context.Context, id int64) error
+    DecrementStockFunc            func(ctx context.Context, productID int64, quantity int) error
+    IncrementStockFunc            func(ctx context.Context, productID int64, quantity int) error
+}
+
+func (m *MockProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
+    if m.CreateProductFunc != nil {
+        return m.CreateProductFunc(ctx, product)
+    }
+    return nil
+}
+
+func (m *MockProductRepository) GetProduct(ctx context.Context, id int64) (*models.Product, error) {
+    if m.GetProductFunc != nil {
+        return m.GetProductFunc(ctx, id)
+    }
+    return nil, errors.New("product not found")
+}
+
+func (m *MockProductRepository) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+    if m.GetProductBySKUFunc != nil {
+        return m.GetProductBySKUFunc(ctx, sku)
+    }
+    return nil, errors.New("product not found")
+}
+
+func (m *MockProductRepository) GetAllProducts(ctx context.Context, categoryID *int64) ([]*models.Product, error) {
+    if m.GetAllProductsFunc != nil {
+        return m.GetAllProductsFunc(ctx, categoryID)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) GetProductsInCategoryTree(ctx context.Context, categoryID int64) ([]*models.Product, error) {
+    if m.GetProductsInCategoryTreeFunc != nil {
+        return m.GetProductsInCategoryTreeFunc(ctx, categoryID)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) GetProductsByIDs(ctx context.Context, ids []int64) ([]*models.Product, error) {
+    if m.GetProductsByIDsFunc != nil {
+        return m.GetProductsByIDsFunc(ctx, ids)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) GetProductsByAttributes(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) ([]*models.Product, error) {
+    if m.GetProductsByAttributesFunc != nil {
+        return m.GetProductsByAttributesFunc(ctx, categoryID, includeSubcategories, filters)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) GetProductsPage(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter, page pagination.Page) ([]*models.Product, error) {
+    if m.GetProductsPageFunc != nil {
+        return m.GetProductsPageFunc(ctx, categoryID, includeSubcategories, filters, page)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
+    if m.UpdateProductFunc != nil {
+        return m.UpdateProductFunc(ctx, product)
+    }
+    return nil
+}
+
+func (m *MockProductRepository) DeleteProduct(ctx context.Context, id int64) error {
+    if m.DeleteProductFunc != nil {
+        return m.DeleteProductFunc(ctx, id)
+    }
+    return nil
+}
+
+func (m *MockProductRepository) GetDeletedProducts(ctx context.Context) ([]*models.Product, error) {
+    if m.GetDeletedProductsFunc != nil {
+        return m.GetDeletedProductsFunc(ctx)
+    }
+    return nil, nil
+}
+
+func (m *MockProductRepository) RestoreProduct(ctx context.Context, id int64) error {
+    if m.RestoreProductFunc != nil {
+        return m.RestoreProductFunc(ctx, id)
+    }
+    return nil
+}
+
+func (m *MockProductRepository) DecrementStock(ctx context.Context, productID int64, quantity int) error {
+    if m.DecrementStockFunc != nil {
+        return m.DecrementStockFunc(ctx, productID, quantity)
+    }
+    return nil
+}
+
+func (m *MockProductRepository) IncrementStock(ctx context.Context, productID int64, quantity int) error {
+    if m.IncrementStockFunc != nil {
+        return m.IncrementStockFunc(ctx, productID, quantity)
+    }
+    return nil
+}
+
+// MockCategoryRepository is a mock implementation of CategoryRepository
+type MockCategoryRepository struct {
+    CreateCategoryFunc    func(ctx context.Context, category *models.Category) error
+    GetCategoryFunc       func(ctx context.Context, id int64) (*models.Category, error)
+    GetAllCategoriesFunc  func(ctx context.Context) ([]*models.Category, error)
+    GetCategoriesPageFunc func(ctx context.Context, page pagination.Page) ([]*models.Category, error)
+    GetCategoryTreeFunc   func(ctx context.Context) ([]*models.CategoryNode, error)
+    GetProductCountsFunc  func(ctx context.Context) (map[int64]int, error)
+    UpdateCategoryFunc    func(ctx context.Context, category *models.Category) error
+    DeleteCategoryFunc    func(ctx context.Context, id int64) error
+    MergeCategoryFunc     func(ctx context.Context, sourceID, targetID int64, templates []string) ([]int64, []int64, error)
+    AssignProductsFunc    func(ctx context.Context, categoryID int64, productIDs []int64) ([]int64, error)
+}
+
+func (m *MockCategoryRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+    if m.CreateCategoryFunc != nil {
+        return m.CreateCategoryFunc(ctx, category)
+    }
+    return nil
+}
+
+func (m *MockCategoryRepository) GetCategory(ctx context.Context, id int64) (*models.Category, error) {
+    if m.GetCategoryFunc != nil {
+        return m.GetCategoryFunc(ctx, id)
+    }
+    return nil, errors.New("category not found")
+}
+
+func (m *MockCategoryRepository) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
+    if m.GetAllCategoriesFunc != nil {
+        return m.GetAllCategoriesFunc(ctx)
+    }
+    return nil, nil
+}
+
+func (m *MockCategoryRepository) GetCategoriesPage(ctx context.Context, page pagination.Page) ([]*models.Category, error) {
+    if m.GetCategoriesPageFunc != nil {
+        return m.GetCategoriesPageFunc(ctx, page)
+    }
+    return nil, nil
+}
+
+func (m *MockCategoryRepository) GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error) {
+    if m.GetCategoryTreeFunc != nil {
+        return m.GetCategoryTreeFunc(ctx)
+    }
+    return nil, nil
+}
+
+func (m *MockCategoryRepository) GetProductCounts(ctx context.Context) (map[int64]int, error) {
+    if m.GetProductCountsFunc != nil {
+        return m.GetProductCountsFunc(ctx)
+    }
+    return nil, nil
+}
+
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+    if m.UpdateCategoryFunc != nil {
+        return m.UpdateCategoryFunc(ctx, category)
+    }
+    return nil
+}
+
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+    if m.DeleteCategoryFunc != nil {
+        return m.DeleteCategoryFunc(ctx, id)
+    }
+    return nil
+}
+
+func (m *MockCategoryRepository) MergeCategory(ctx context.Context, sourceID, targetID int64, templates []string) ([]int64, []int64, error) {
+    if m.MergeCategoryFunc != nil {
+        return m.MergeCategoryFunc(ctx, sourceID, targetID, templates)
+    }
+    return nil, nil, nil
+}
+
+func (m *MockCategoryRepository) AssignProducts(ctx context.Context, categoryID int64, productIDs []int64) ([]int64, error) {
+    if m.AssignProductsFunc != nil {
+        return m.AssignProductsFunc(ctx, categoryID, productIDs)
+    }
+    return nil, nil
+}
+
+// MockVariantRepository is a mock implementation of VariantRepository
+type MockVariantRepository struct {
+    CreateVariantFunc        func(ctx context.Context, variant *models.ProductVariant) error
+    GetVariantFunc           func(ctx context.Context, productID, variantID int64) (*models.ProductVariant, error)
+    GetVariantsByProductFunc func(ctx context.Context, productID int64) ([]*models.ProductVariant, error)
+}
+
+func (m *MockVariantRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant) error {
+    if m.CreateVariantFunc != nil {
+        return m.CreateVariantFunc(ctx, variant)
+    }
+    return nil
+}
+
+func (m *MockVariantRepository) GetVariant(ctx context.Context, productID, variantID int64) (*models.ProductVariant, error) {
+    if m.GetVariantFunc != nil {
+        return m.GetVariantFunc(ctx, productID, variantID)
+    }
+    return nil, errors.New("variant not found")
+}
+
+func (m *MockVariantRepository) GetVariantsByProduct(ctx context.Context, productID int64) ([]*models.ProductVariant, error) {
+    if m.GetVariantsByProductFunc != nil {
+        return m.GetVariantsByProductFunc(ctx, productID)
+    }
+    return nil, nil
+}
+
+// MockAttributeTemplateRepository is a mock implementation of AttributeTemplateRepository
+type MockAttributeTemplateRepository struct {
+    CreateTemplateFunc          func(ctx context.Context, template *models.AttributeTemplate) error
+    GetTemplateFunc             func(ctx context.Context, id int64) (*models.AttributeTemplate, error)
+    GetTemplatesForCategoryFunc func(ctx context.Context, categoryID int64) ([]*models.AttributeTemplate, error)
+    UpdateTemplateFunc          func(ctx context.Context, template *models.AttributeTemplate) error
+    DeleteTemplateFunc          func(ctx context.Context, id int64) error
+}
+
+func (m *MockAttributeTemplateRepository) CreateTemplate(ctx context.Context, template *models.AttributeTemplate) error {
+    if m.CreateTemplateFunc != nil {
+        return m.CreateTemplateFunc(ctx, template)
+    }
+    return nil
+}
+
+func (m *MockAttributeTemplateRepository) GetTemplate(ctx context.Context, id int64) (*models.AttributeTemplate, error) {
+    if m.GetTemplateFunc != nil {
+        return m.GetTemplateFunc(ctx, id)
+    }
+    return nil, errors.New("attribute template not found")
+}
+
+func (m *MockAttributeTemplateRepository) GetTemplatesForCategory(ctx context.Context, categoryID int64) ([]*models.AttributeTemplate, error) {
+    if m.GetTemplatesForCategoryFunc != nil {
+        return m.GetTemplatesForCategoryFunc(ctx, categoryID)
+    }
+    return nil, nil
+}
+
+func (m *MockAttributeTemplateRepository) UpdateTemplate(ctx context.Context, template *models.AttributeTemplate) error {
+    if m.UpdateTemplateFunc != nil {
+        return m.UpdateTemplateFunc(ctx, template)
+    }
+    return nil
+}
+
+func (m *MockAttributeTemplateRepository) DeleteTemplate(ctx context.Context, id int64) error {
+    if m.DeleteTemplateFunc != nil {
+        return m.DeleteTemplateFunc(ctx, id)
+    }
+    return nil
+}
+
+// MockInventoryReservationRepository is a mock implementation of InventoryReservationRepository
+type MockInventoryReservationRepository struct {
+    CreateReservationFunc                func(ctx context.Context, reservation *models.InventoryReservation) error
+    GetReservationFunc                   func(ctx context.Context, reservationID string) (*models.InventoryReservation, error)
+    GetReservationsByOrderIDFunc         func(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error)
+    ReleaseReservationFunc               func(ctx context.Context, reservationID string) error
+    ExpireReservationsFunc               func(ctx context.Context) (int64, error)
+    GetProductReservationsFunc           func(ctx context.Context, productID int64) (int, error)
+    GetVariantReservationsFunc           func(ctx context.Context, variantID int64) (int, error)
+    UpdateReservationStatusByOrderIDFunc func(ctx context.Context, orderID string, status string) error
+    UpdateReservationStatusFunc          func(ctx context.Context, orderID int64, status string) error
+    ReserveForOrderFunc                  func(ctx context.Context, orderID int64, cartID string, lines []models.OrderLine, ttl time.Duration) ([]*models.InventoryReservation, error)
+    CommitOrderReservationsFunc          func(ctx context.Context, orderID int64) (int, error)
+    ExtendOrderReservationsFunc          func(ctx context.Context, orderID int64, until time.Time) (int, error)
+    LockForCartFunc                      func(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error)
+    ReleaseCartLockFunc                  func(ctx context.Context, cartID, reservationID string) error
+    ReleaseCartLocksFunc                 func(ctx context.Context, cartID string) (int64, error)
+    ReserveForChannelFunc                func(ctx context.Context, channel *models.SalesChannel, req *models.ChannelReserveRequest) (*models.ChannelReservation, bool, error)
+    GetChannelReservationFunc            func(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    ExtendChannelReservationFunc         func(ctx context.Context, channelID, reservationID string, hold time.Duration) (*models.ChannelReservation, error)
+    CommitChannelReservationFunc         func(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    ReleaseChannelReservationFunc        func(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    GetChannelReportFunc                 func(ctx context.Context, channel *models.SalesChannel, from, to time.Time) (*models.ChannelReport, error)
+    GetProductMovementsFunc              func(ctx context.Context, productID int64, variantID *int64, limit int) ([]*models.InventoryMovement, error)
+    HoldStockFunc                        func(ctx context.Context, productID int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error)
+    ReleaseHeldStockFunc                 func(ctx context.Context, productID int64, quantity int) (int, error)
+    AdjustStockFunc                      func(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error)
+}
+
+func (m *MockInventoryReservationRepository) CreateReservation(ctx context.Context, reservation *models.InventoryReservation) error {
+    if m.CreateReservationFunc != nil {
+        return m.CreateReservationFunc(ctx, reservation)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) GetReservation(ctx context.Context, reservationID string) (*models.InventoryReservation, error) {
+    if m.GetReservationFunc != nil {
+        return m.GetReservationFunc(ctx, reservationID)
+    }
+    return nil, errors.New("reservation not found")
+}
+
+func (m *MockInventoryReservationRepository) GetReservationsByOrderID(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error) {
+    if m.GetReservationsByOrderIDFunc != nil {
+        return m.GetReservationsByOrderIDFunc(ctx, orderID)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseReservation(ctx context.Context, reservationID string) error {
+    if m.ReleaseReservationFunc != nil {
+        return m.ReleaseReservationFunc(ctx, reservationID)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) ExpireReservations(ctx context.Context) (int64, error) {
+    if m.ExpireReservationsFunc != nil {
+        return m.ExpireReservationsFunc(ctx)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) GetProductReservations(ctx context.Context, productID int64) (int, error) {
+    if m.GetProductReservationsFunc != nil {
+        return m.GetProductReservationsFunc(ctx, productID)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) GetVariantReservations(ctx context.Context, variantID int64) (int, error) {
+    if m.GetVariantReservationsFunc != nil {
+        return m.GetVariantReservationsFunc(ctx, variantID)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) UpdateReservationStatusByOrderID(ctx context.Context, orderID string, status string) error {
+    if m.UpdateReservationStatusByOrderIDFunc != nil {
+        return m.UpdateReservationStatusByOrderIDFunc(ctx, orderID, status)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) UpdateReservationStatus(ctx context.Context, orderID int64, status string) error {
+    if m.UpdateReservationStatusFunc != nil {
+        return m.UpdateReservationStatusFunc(ctx, orderID, status)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) ReserveForOrder(ctx context.Context, orderID int64, cartID string, lines []models.OrderLine, ttl time.Duration) ([]*models.InventoryReservation, error) {
+    if m.ReserveForOrderFunc != nil {
+        return m.ReserveForOrderFunc(ctx, orderID, cartID, lines, ttl)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) CommitOrderReservations(ctx context.Context, orderID int64) (int, error) {
+    if m.CommitOrderReservationsFunc != nil {
+        return m.CommitOrderReservationsFunc(ctx, orderID)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) ExtendOrderReservations(ctx context.Context, orderID int64, until time.Time) (int, error) {
+    if m.ExtendOrderReservationsFunc != nil {
+        return m.ExtendOrderReservationsFunc(ctx, orderID, until)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) LockForCart(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error) {
+    if m.LockForCartFunc != nil {
+        return m.LockForCartFunc(ctx, cartID, productID, variantID, quantity, ttl)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseCartLock(ctx context.Context, cartID, reservationID string) error {
+    if m.ReleaseCartLockFunc != nil {
+        return m.ReleaseCartLockFunc(ctx, cartID, reservationID)
+    }
+    return nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseCartLocks(ctx context.Context, cartID string) (int64, error) {
+    if m.ReleaseCartLocksFunc != nil {
+        return m.ReleaseCartLocksFunc(ctx, cartID)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) ReserveForChannel(ctx context.Context, channel *models.SalesChannel, req *models.ChannelReserveRequest) (*models.ChannelReservation, bool, error) {
+    if m.ReserveForChannelFunc != nil {
+        return m.ReserveForChannelFunc(ctx, channel, req)
+    }
+    return nil, false, nil
+}
+
+func (m *MockInventoryReservationRepository) GetChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    if m.GetChannelReservationFunc != nil {
+        return m.GetChannelReservationFunc(ctx, channelID, reservationID)
+    }
+    return nil, errors.New("reservation not found")
+}
+
+func (m *MockInventoryReservationRepository) ExtendChannelReservation(ctx context.Context, channelID, reservationID string, hold time.Duration) (*models.ChannelReservation, error) {
+    if m.ExtendChannelReservationFunc != nil {
+        return m.ExtendChannelReservationFunc(ctx, channelID, reservationID, hold)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) CommitChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    if m.CommitChannelReservationFunc != nil {
+        return m.CommitChannelReservationFunc(ctx, channelID, reservationID)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    if m.ReleaseChannelReservationFunc != nil {
+        return m.ReleaseChannelReservationFunc(ctx, channelID, reservationID)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) GetChannelReport(ctx context.Context, channel *models.SalesChannel, from, to time.Time) (*models.ChannelReport, error) {
+    if m.GetChannelReportFunc != nil {
+        return m.GetChannelReportFunc(ctx, channel, from, to)
+    }
+    return nil, errors.New("reservation not found")
+}
+
+func (m *MockInventoryReservationRepository) GetProductMovements(ctx context.Context, productID int64, variantID *int64, limit int) ([]*models.InventoryMovement, error) {
+    if m.GetProductMovementsFunc != nil {
+        return m.GetProductMovementsFunc(ctx, productID, variantID, limit)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) HoldStock(ctx context.Context, productID int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error) {
+    if m.HoldStockFunc != nil {
+        return m.HoldStockFunc(ctx, productID, quantity, ttl)
+    }
+    return nil, nil
+}
+
+func (m *MockInventoryReservationRepository) ReleaseHeldStock(ctx context.Context, productID int64, quantity int) (int, error) {
+    if m.ReleaseHeldStockFunc != nil {
+        return m.ReleaseHeldStockFunc(ctx, productID, quantity)
+    }
+    return 0, nil
+}
+
+func (m *MockInventoryReservationRepository) AdjustStock(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+    if m.AdjustStockFunc != nil {
+        return m.AdjustStockFunc(ctx, productID, req, actorID)
+    }
+    return nil, errors.New("product not found")
+}
+
+// MockPublisher records the events it's asked to publish
+type MockPublisher struct {
+    Events []interface{}
+    Err    error
+}
+
+func (m *MockPublisher) PublishProductEvent(ctx context.Context, event interface{}) error {
+    m.Events = append(m.Events, event)
+    return m.Err
+}
+
+// serve runs one request for target through handler, routed at pattern so path
+// parameters are set, and returns the recorded response
+func serve(t *testing.T, handler gin.HandlerFunc, method, pattern, target string, body interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    var payload bytes.Buffer
+    if body != nil {
+        if err := json.NewEncoder(&payload).Encode(body); err != nil {
+            t.Fatalf("encode body: %v", err)
+        }
+    }
+
+    router := gin.New()
+    router.Handle(method, pattern, handler)
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(method, target, &payload)
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(rec, req)
+    return rec
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// StockSubscriptionHandler handles back-in-stock subscriptions and announces restocks to them
+type StockSubscriptionHandler struct {
+    subscriptionRepo *repository.StockSubscriptionRepository
+    productRepo      *repository.ProductRepository
+    eventPublisher   *messaging.Publisher
+    maxSubscribers   int
+}
+
+// NewStockSubscriptionHandler creates new stock subscription handler
+func NewStockSubscriptionHandler(
+    subscriptionRepo *repository.StockSubscriptionRepository,
+    productRepo *repository.ProductRepository,
+    eventPublisher *messaging.Publisher,
+    maxSubscribers int,
+) *StockSubscriptionHandler {
+    if maxSubscribers <= 0 {
+        maxSubscribers = models.DefaultMaxStockSubscribers
+    }
+    return &StockSubscriptionHandler{
+        subscriptionRepo: subscriptionRepo,
+        productRepo:      productRepo,
+        eventPublisher:   eventPublisher,
+        maxSubscribers:   maxSubscribers,
+    }
+}
+
+// NotifyMe subscribes a user to an out-of-stock product. Subscribing again while waiting
+// returns the existing subscription with 200.
+func (sh *StockSubscriptionHandler) NotifyMe(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.NotifyMeRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    sub, created, err := sh.subscriptionRepo.Subscribe(ctx, productID, req.UserID, sh.maxSubscribers)
+    if err != nil {
+        respondStockSubscriptionError(c, "failed to subscribe", err)
+        return
+    }
+
+    status := http.StatusOK
+    if created {
+        status = http.StatusCreated
+        log.Printf("✓ Back-in-stock subscription: product %d, user %s", productID, req.UserID)
+    }
+
+    c.JSON(status, sub)
+}
+
+// NotifyBackInStock publishes a BackInStock event for the product's waiting subscribers once it
+// has stock. Called after stock went up; errors are logged, since the stock change is committed
+// and the subscribers stay waiting for the next restock.
+func (sh *StockSubscriptionHandler) NotifyBackInStock(ctx context.Context, productID int64, stock int, correlationID string) {
+    if stock <= 0 {
+        return
+    }
+
+    notified, err := sh.subscriptionRepo.NotifySubscribers(ctx, productID, func(userIDs []string) error {
+        var name string
+        if product, err := sh.productRepo.GetProduct(ctx, productID); err == nil {
+            name = product.Name
+        }
+
+        event := events.BackInStockEvent{
+            BaseEvent:     events.NewBaseEvent("BackInStock", strconv.FormatInt(productID, 10), "product", correlationID),
+            ProductID:     productID,
+            ProductName:   name,
+            StockQuantity: stock,
+            UserIDs:       userIDs,
+        }
+        return sh.eventPublisher.PublishProductEvent(ctx, event)
+    })
+    if err != nil {
+        log.Printf("⚠️  Failed to notify back-in-stock subscribers of product %d: %v", productID, err)
+        return
+    }
+    if notified > 0 {
+        log.Printf("✓ Product %d back in stock: %d subscribers notified", productID, notified)
+    }
+}
+
+// respondStockSubscriptionError maps repository errors to HTTP statuses
+func respondStockSubscriptionError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrUnknownProduct):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrProductInStock):
+        status = http.StatusConflict
+    case errors.Is(err, repository.ErrSubscriberCapReached):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// AddVariant adds a variant (size, color, ...) with its own SKU and stock to a product
+func (ph *ProductHandler) AddVariant(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    var req models.CreateVariantRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    product, err := ph.productRepo.GetProduct(ctx, productID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    variant := models.NewProductVariant(productID, req.SKU, req.Attributes, req.PriceOverride, req.Stock)
+    if err := ph.variantRepo.CreateVariant(ctx, variant); err != nil {
+        respondVariantError(c, "failed to create variant", err)
+        return
+    }
+    variant.Price = variant.EffectivePrice(product.Price)
+
+    // The product payload embeds its variants, so caches must drop it
+    event := events.ProductUpdatedEvent{
+        BaseEvent:   events.NewBaseEvent("ProductUpdated", strconv.FormatInt(product.ID, 10), "product", ""),
+        Name:        product.Name,
+        Description: product.Description,
+        Price:       product.Price,
+        ImageURL:    product.ImageURL,
+    }
+
+    if err := ph.eventPublisher.PublishProductEvent(ctx, event); err != nil {
+        log.Printf("⚠️  Failed to publish ProductUpdated event: %v", err)
+    }
+
+    log.Printf("✓ Variant created: %s for product %d (ID: %d)", variant.SKU, productID, variant.ID)
+
+    c.JSON(http.StatusCreated, gin.H{
+        "message": "Variant created successfully",
+        "variant": variant,
+    })
+}
+
+// GetVariants lists the variants of a product
+func (ph *ProductHandler) GetVariants(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid product id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if _, err := ph.productRepo.GetProduct(ctx, productID); err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "product not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    variants, err := ph.variantRepo.GetVariantsByProduct(ctx, productID)
+    if err != nil {
+        respondVariantError(c, "failed to get variants", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "variants": variants,
+        "count":    len(variants),
+    })
+}
+
+// getVariantInventory answers GET /inventory/:product_id?variant_id= with the variant's stock
+func (ph *ProductHandler) getVariantInventory(ctx context.Context, c *gin.Context, product *models.Product, variantIDStr string) {
+    variantID, err := strconv.ParseInt(variantIDStr, 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid variant id",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    variant, err := ph.variantRepo.GetVariant(ctx, product.ID, variantID)
+    if err != nil {
+        respondVariantError(c, "variant not found", err)
+        return
+    }
+
+    reserved, err := ph.inventoryRepo.GetVariantReservations(ctx, variantID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get reservations",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "product_id":  product.ID,
+        "variant_id":  variantID,
+        "total_stock": variant.StockQuantity,
+        "reserved":    reserved,
+        "available":   variant.StockQuantity - reserved,
+    })
+}
+
+// respondVariantError maps repository errors to HTTP statuses
+func respondVariantError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrVariantNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrDuplicateVariantSKU):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
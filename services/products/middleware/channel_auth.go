@@ -0,0 +1,56 @@
+package middleware
+
+import (
+    "context"
+    "errors"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ChannelKeyHeader carries a sales channel's API key
+const ChannelKeyHeader = "X-Channel-Key"
+
+// ChannelContextKey is where the authenticated *models.SalesChannel is stored
+const ChannelContextKey = "channel"
+
+// ChannelLookup finds the active channel for an API key hash
+type ChannelLookup interface {
+    GetActiveChannelByKeyHash(ctx context.Context, apiKeyHash string) (*models.SalesChannel, error)
+}
+
+// ChannelAuthMiddleware only lets through requests bearing an active channel's API key
+func ChannelAuthMiddleware(channels ChannelLookup) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        apiKey := c.GetHeader(ChannelKeyHeader)
+        if apiKey == "" {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+                "error": ChannelKeyHeader + " header required",
+            })
+            return
+        }
+
+        ctx, cancel := reqctx.New(c.Request)
+        defer cancel()
+
+        channel, err := channels.GetActiveChannelByKeyHash(ctx, models.HashChannelKey(apiKey))
+        if errors.Is(err, repository.ErrChannelNotFound) {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+                "error": "invalid channel key",
+            })
+            return
+        }
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+                "error": "failed to authenticate channel",
+            })
+            return
+        }
+
+        c.Set(ChannelContextKey, channel)
+        c.Next()
+    }
+}
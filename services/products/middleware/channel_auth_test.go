@@ -0,0 +1,52 @@
+package middleware
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// lookupFunc adapts a function to ChannelLookup
+type lookupFunc func(ctx context.Context, apiKeyHash string) (*models.SalesChannel, error)
+
+func (f lookupFunc) GetActiveChannelByKeyHash(ctx context.Context, apiKeyHash string) (*models.SalesChannel, error) {
+    return f(ctx, apiKeyHash)
+}
+
+func TestChannelAuthPropagatesRequestCancellation(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    var lookupCtx context.Context
+    lookup := lookupFunc(func(ctx context.Context, apiKeyHash string) (*models.SalesChannel, error) {
+        lookupCtx = ctx
+        return nil, ctx.Err()
+    })
+
+    requestCtx, cancelRequest := context.WithCancel(context.Background())
+    cancelRequest() // client disconnected
+
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Request = httptest.NewRequest(http.MethodPost, "/channels/reservations", nil).WithContext(requestCtx)
+    c.Request.Header.Set(ChannelKeyHeader, "key")
+
+    ChannelAuthMiddleware(lookup)(c)
+
+    if lookupCtx == nil {
+        t.Fatal("lookup not called")
+    }
+    if !errors.Is(lookupCtx.Err(), context.Canceled) {
+        t.Errorf("lookup ctx err = %v, want context.Canceled", lookupCtx.Err())
+    }
+    if _, ok := lookupCtx.Deadline(); !ok {
+        t.Error("lookup ctx has no deadline")
+    }
+    if w.Code != http.StatusInternalServerError {
+        t.Errorf("status = %d, want 500", w.Code)
+    }
+}
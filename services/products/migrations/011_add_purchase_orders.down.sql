@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS catalog.purchase_order_receipts;
+DROP TABLE IF EXISTS catalog.purchase_order_lines;
+DROP TABLE IF EXISTS catalog.purchase_orders;
@@ -0,0 +1,37 @@
+-- Supplier purchase orders: expected inbound stock
+CREATE TABLE IF NOT EXISTS catalog.purchase_orders (
+    id BIGSERIAL PRIMARY KEY,
+    supplier VARCHAR(255) NOT NULL,
+    reference VARCHAR(100),
+    status VARCHAR(50) NOT NULL DEFAULT 'open', -- open, partially_received, received, cancelled
+    expected_at TIMESTAMP NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    received_at TIMESTAMP NULL
+);
+
+-- Expected vs received quantity per product on a purchase order
+CREATE TABLE IF NOT EXISTS catalog.purchase_order_lines (
+    id BIGSERIAL PRIMARY KEY,
+    purchase_order_id BIGINT NOT NULL REFERENCES catalog.purchase_orders(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id),
+    expected_quantity INT NOT NULL DEFAULT 0,
+    received_quantity INT NOT NULL DEFAULT 0,
+    UNIQUE(purchase_order_id, product_id)
+);
+
+-- One row per delivery against a purchase order line
+CREATE TABLE IF NOT EXISTS catalog.purchase_order_receipts (
+    id BIGSERIAL PRIMARY KEY,
+    purchase_order_id BIGINT NOT NULL REFERENCES catalog.purchase_orders(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id),
+    quantity INT NOT NULL,
+    note TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON catalog.purchase_orders(status);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON catalog.purchase_orders(supplier);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_product_id ON catalog.purchase_order_lines(product_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_receipts_po_id ON catalog.purchase_order_receipts(purchase_order_id);
@@ -0,0 +1,17 @@
+-- Product reviews; only approved reviews count towards ratings
+CREATE TABLE IF NOT EXISTS catalog.product_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    moderation_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    moderated_at TIMESTAMP NULL,
+    UNIQUE(product_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_reviews_product_status ON catalog.product_reviews(product_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_reviews_status ON catalog.product_reviews(status);
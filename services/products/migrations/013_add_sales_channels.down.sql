@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS catalog.idx_inventory_reservations_channel_ref;
+DROP INDEX IF EXISTS catalog.idx_inventory_reservations_channel_status;
+
+ALTER TABLE catalog.inventory_reservations
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS committed_at,
+    DROP COLUMN IF EXISTS external_ref,
+    DROP COLUMN IF EXISTS channel_id;
+
+DROP TABLE IF EXISTS catalog.sales_channels;
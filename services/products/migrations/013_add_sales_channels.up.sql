@@ -0,0 +1,23 @@
+-- External sales channels (POS, marketplaces) that hold stock through inventory reservations
+CREATE TABLE IF NOT EXISTS catalog.sales_channels (
+    id VARCHAR(50) PRIMARY KEY, -- e.g. pos-store-12, marketplace-acme
+    name VARCHAR(255) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL UNIQUE, -- sha256 hex; the key itself is only shown once
+    quota INT NOT NULL DEFAULT 100, -- max units held in active reservations at once
+    max_hold_minutes INT NOT NULL DEFAULT 60,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Channel attribution on reservations; order saga reservations keep channel_id NULL and
+-- channel reservations use order_id 0
+ALTER TABLE catalog.inventory_reservations
+    ADD COLUMN IF NOT EXISTS channel_id VARCHAR(50) NULL REFERENCES catalog.sales_channels(id),
+    ADD COLUMN IF NOT EXISTS external_ref VARCHAR(255) NULL,
+    ADD COLUMN IF NOT EXISTS committed_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_channel_status ON catalog.inventory_reservations(channel_id, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inventory_reservations_channel_ref
+    ON catalog.inventory_reservations(channel_id, external_ref) WHERE external_ref IS NOT NULL;
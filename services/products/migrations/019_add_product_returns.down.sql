@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS catalog.stock_ledger;
+DROP TABLE IF EXISTS catalog.product_returns;
@@ -0,0 +1,33 @@
+-- Customer returns: received, inspected, then dispositioned
+CREATE TABLE IF NOT EXISTS catalog.product_returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NULL,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    reason TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'received', -- received, inspected, refurbishing, restocked, written_off
+    condition VARCHAR(50) NULL,                     -- set by inspection: new, good, damaged, defective
+    inspection_notes TEXT,
+    inspected_at TIMESTAMP NULL,
+    disposition_note TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP NULL
+);
+
+-- Stock movements that don't come from orders or purchase orders; one row per disposition
+CREATE TABLE IF NOT EXISTS catalog.stock_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id),
+    return_id BIGINT NULL REFERENCES catalog.product_returns(id),
+    entry_type VARCHAR(50) NOT NULL, -- return_restock, return_write_off, return_refurbish
+    quantity INT NOT NULL,           -- units the entry is about
+    stock_delta INT NOT NULL,        -- change to products.stock_quantity
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_returns_product_received ON catalog.product_returns(product_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_product_returns_status ON catalog.product_returns(status);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_product_id ON catalog.stock_ledger(product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_return_id ON catalog.stock_ledger(return_id);
@@ -0,0 +1,11 @@
+-- Back-in-stock subscriptions; notified_at is set when the subscriber is handed to a BackInStock event
+CREATE TABLE IF NOT EXISTS catalog.stock_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP NULL
+);
+
+-- One waiting subscription per user and product; a notified user can subscribe again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_subscriptions_pending ON catalog.stock_subscriptions(product_id, user_id) WHERE notified_at IS NULL;
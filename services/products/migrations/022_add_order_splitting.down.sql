@@ -0,0 +1,3 @@
+ALTER TABLE catalog.products
+    DROP COLUMN IF EXISTS fulfillment_type,
+    DROP COLUMN IF EXISTS warehouse;
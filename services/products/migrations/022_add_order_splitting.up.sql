@@ -0,0 +1,5 @@
+-- Where each product ships from; checkouts are split into one order per warehouse and fulfillment type
+ALTER TABLE catalog.products
+    ADD COLUMN IF NOT EXISTS warehouse VARCHAR(50) NOT NULL DEFAULT 'main',
+    ADD COLUMN IF NOT EXISTS fulfillment_type VARCHAR(20) NOT NULL DEFAULT 'standard'
+        CHECK (fulfillment_type IN ('standard', 'digital', 'dropship'));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS catalog.idx_categories_parent_id;
+
+ALTER TABLE catalog.categories
+    DROP CONSTRAINT IF EXISTS categories_parent_not_self,
+    DROP COLUMN IF EXISTS parent_id;
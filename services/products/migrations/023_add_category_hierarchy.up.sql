@@ -0,0 +1,6 @@
+-- Categories form a tree; top-level categories have no parent
+ALTER TABLE catalog.categories
+    ADD COLUMN IF NOT EXISTS parent_id BIGINT NULL REFERENCES catalog.categories(id) ON DELETE SET NULL,
+    ADD CONSTRAINT categories_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON catalog.categories(parent_id);
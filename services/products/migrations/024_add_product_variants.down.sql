@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS catalog.idx_inventory_reservations_variant_id;
+
+ALTER TABLE catalog.inventory_reservations
+    DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS catalog.product_variants;
@@ -0,0 +1,21 @@
+-- Sellable variants of a product (size, color, ...), each with its own SKU and stock;
+-- price_override NULL means the variant sells at the product's price
+CREATE TABLE IF NOT EXISTS catalog.product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id) ON DELETE CASCADE,
+    sku VARCHAR(100) NOT NULL UNIQUE,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    price_override DECIMAL(10, 2) NULL CHECK (price_override IS NULL OR price_override > 0),
+    stock_quantity INT NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON catalog.product_variants(product_id);
+
+-- Lines without a variant keep variant_id NULL and hold the product's own stock
+ALTER TABLE catalog.inventory_reservations
+    ADD COLUMN IF NOT EXISTS variant_id BIGINT NULL REFERENCES catalog.product_variants(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_variant_id ON catalog.inventory_reservations(variant_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS catalog.attribute_templates;
+
+DROP INDEX IF EXISTS catalog.idx_products_attributes;
+
+ALTER TABLE catalog.products
+    DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,20 @@
+-- Free-form product specs, e.g. {"screen_size": 15.6, "color": "black", "touchscreen": false}
+ALTER TABLE catalog.products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON catalog.products USING GIN (attributes);
+
+-- Attribute templates describe the specs of a category's products; subcategories inherit them
+CREATE TABLE IF NOT EXISTS catalog.attribute_templates (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES catalog.categories(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    unit VARCHAR(50) NOT NULL DEFAULT '',
+    options JSONB NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (category_id, name)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS catalog.idx_inventory_reservations_cart_id;
+
+ALTER TABLE catalog.inventory_reservations DROP COLUMN IF EXISTS cart_id;
@@ -0,0 +1,6 @@
+-- Soft locks: short holds products keeps for a cart's line between add-to-cart and checkout.
+-- They count against available stock like any reservation; the order reservation of the
+-- cart's checkout takes their place
+ALTER TABLE catalog.inventory_reservations ADD COLUMN IF NOT EXISTS cart_id VARCHAR(64) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_cart_id ON catalog.inventory_reservations(cart_id) WHERE status = 'reserved';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS catalog.idx_inventory_reservations_committed_at;
+
+DROP TABLE IF EXISTS catalog.reorder_forecasts;
@@ -0,0 +1,14 @@
+-- Latest stock forecast per product, written by the reorder forecast worker. suggested_at is set
+-- when ReorderSuggested was published for the current dip and cleared once the product recovers
+CREATE TABLE IF NOT EXISTS catalog.reorder_forecasts (
+    product_id BIGINT PRIMARY KEY REFERENCES catalog.products(id) ON DELETE CASCADE,
+    sold_units INT NOT NULL DEFAULT 0,
+    window_days INT NOT NULL,
+    available INT NOT NULL DEFAULT 0,
+    on_order INT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP NOT NULL,
+    suggested_at TIMESTAMP NULL
+);
+
+-- Sales over the velocity window are read from committed reservations
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_committed_at ON catalog.inventory_reservations(product_id, committed_at) WHERE status = 'committed';
@@ -0,0 +1,3 @@
+ALTER TABLE catalog.products
+    DROP COLUMN IF EXISTS low_stock_alerted_at,
+    DROP COLUMN IF EXISTS low_stock_threshold;
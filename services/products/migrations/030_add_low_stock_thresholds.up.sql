@@ -0,0 +1,5 @@
+-- Per-product low-stock threshold; 0 means no alerts. low_stock_alerted_at is set when LowStock
+-- was published for the current dip and cleared once available stock is back at the threshold
+ALTER TABLE catalog.products
+    ADD COLUMN IF NOT EXISTS low_stock_threshold INT NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+    ADD COLUMN IF NOT EXISTS low_stock_alerted_at TIMESTAMP NULL;
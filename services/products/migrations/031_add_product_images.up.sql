@@ -0,0 +1,14 @@
+-- Images uploaded through POST /products/:id/images. storage_key locates the file in the
+-- configured storage backend; url is its public (CDN) address. products.image_url keeps the
+-- primary image's url.
+CREATE TABLE IF NOT EXISTS catalog.product_images (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON catalog.product_images(product_id);
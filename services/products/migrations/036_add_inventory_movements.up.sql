@@ -0,0 +1,17 @@
+-- Audit trail of stock_quantity changes. delta is signed (negative takes stock out) and
+-- stock_after is the product's or variant's stock once it was applied. A confirmed order
+-- writes one row per committed reservation (reason 'order_confirmed').
+CREATE TABLE IF NOT EXISTS catalog.inventory_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES catalog.products(id) ON DELETE CASCADE,
+    variant_id BIGINT REFERENCES catalog.product_variants(id) ON DELETE CASCADE,
+    delta INT NOT NULL CHECK (delta <> 0),
+    stock_after INT NOT NULL,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('order_confirmed')),
+    order_id BIGINT,
+    reservation_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product ON catalog.inventory_movements(product_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_order ON catalog.inventory_movements(order_id) WHERE order_id IS NOT NULL;
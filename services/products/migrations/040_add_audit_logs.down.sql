@@ -0,0 +1 @@
+DROP TABLE IF EXISTS catalog.audit_logs;
@@ -0,0 +1,6 @@
+ALTER TABLE catalog.inventory_movements DROP COLUMN IF EXISTS note;
+ALTER TABLE catalog.inventory_movements DROP COLUMN IF EXISTS actor_id;
+DELETE FROM catalog.inventory_movements WHERE reason <> 'order_confirmed';
+ALTER TABLE catalog.inventory_movements DROP CONSTRAINT IF EXISTS inventory_movements_reason_check;
+ALTER TABLE catalog.inventory_movements ADD CONSTRAINT inventory_movements_reason_check
+    CHECK (reason IN ('order_confirmed'));
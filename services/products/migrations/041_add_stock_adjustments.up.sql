@@ -0,0 +1,7 @@
+-- Admin stock adjustments (POST /inventory/:product_id/adjust) are inventory movements too:
+-- reason says why (damage, recount, return), actor_id who made them and note anything else.
+ALTER TABLE catalog.inventory_movements DROP CONSTRAINT IF EXISTS inventory_movements_reason_check;
+ALTER TABLE catalog.inventory_movements ADD CONSTRAINT inventory_movements_reason_check
+    CHECK (reason IN ('order_confirmed', 'damage', 'recount', 'return'));
+ALTER TABLE catalog.inventory_movements ADD COLUMN IF NOT EXISTS actor_id VARCHAR(255);
+ALTER TABLE catalog.inventory_movements ADD COLUMN IF NOT EXISTS note TEXT;
@@ -0,0 +1,326 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Attribute template types
+const (
+    AttributeString  = "string"
+    AttributeNumber  = "number"
+    AttributeBoolean = "boolean"
+    AttributeEnum    = "enum" // a string from the template's options
+)
+
+// attributeName keeps names usable as filter keys, e.g. screen_size
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// Attributes are a product's specs keyed by name; values are strings, numbers or booleans
+type Attributes map[string]interface{}
+
+// Scan reads the JSONB column
+func (a *Attributes) Scan(src interface{}) error {
+    var raw []byte
+    switch v := src.(type) {
+    case nil:
+        *a = Attributes{}
+        return nil
+    case []byte:
+        raw = v
+    case string:
+        raw = []byte(v)
+    default:
+        return fmt.Errorf("cannot scan %T into attributes", src)
+    }
+
+    attributes := Attributes{}
+    if err := json.Unmarshal(raw, &attributes); err != nil {
+        return fmt.Errorf("failed to decode attributes: %w", err)
+    }
+    *a = attributes
+    return nil
+}
+
+// Value writes the JSONB column; nil is stored as an empty object
+func (a Attributes) Value() (driver.Value, error) {
+    if a == nil {
+        return []byte("{}"), nil
+    }
+    return json.Marshal(a)
+}
+
+// AttributeTemplate describes one spec of the products in a category and its subcategories
+type AttributeTemplate struct {
+    ID         int64     `json:"id"`
+    CategoryID int64     `json:"category_id"`
+    Name       string    `json:"name"`    // key in Product.Attributes
+    Label      string    `json:"label"`   // shown to shoppers, e.g. "Screen size"
+    Type       string    `json:"type"`    // string, number, boolean or enum
+    Unit       string    `json:"unit"`    // e.g. "in"; empty when unitless
+    Options    []string  `json:"options"` // allowed values of an enum
+    Required   bool      `json:"required"`
+    CreatedAt  time.Time `json:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreateAttributeTemplateRequest request body for adding an attribute template to a category
+type CreateAttributeTemplateRequest struct {
+    Name     string   `json:"name" binding:"required"`
+    Label    string   `json:"label"` // default: the name
+    Type     string   `json:"type" binding:"required,oneof=string number boolean enum"`
+    Unit     string   `json:"unit"`
+    Options  []string `json:"options"`
+    Required bool     `json:"required"`
+}
+
+// UpdateAttributeTemplateRequest request body for changing an attribute template; omitted fields are kept.
+// The name and type can't change, since products already hold values for them.
+type UpdateAttributeTemplateRequest struct {
+    Label    *string  `json:"label"`
+    Unit     *string  `json:"unit"`
+    Options  []string `json:"options"`
+    Required *bool    `json:"required"`
+}
+
+// NewAttributeTemplate creates new attribute template
+func NewAttributeTemplate(categoryID int64, name, label, attributeType, unit string, options []string, required bool) *AttributeTemplate {
+    now := time.Now().UTC()
+    if label == "" {
+        label = name
+    }
+    if options == nil {
+        options = []string{}
+    }
+    return &AttributeTemplate{
+        CategoryID: categoryID,
+        Name:       name,
+        Label:      label,
+        Type:       attributeType,
+        Unit:       unit,
+        Options:    options,
+        Required:   required,
+        CreatedAt:  now,
+        UpdatedAt:  now,
+    }
+}
+
+// Validate checks the template itself: an enum needs distinct options, other types take none
+func (t *AttributeTemplate) Validate() error {
+    if !attributeName.MatchString(t.Name) {
+        return fmt.Errorf("attribute name %q must be lowercase letters, digits and underscores, starting with a letter", t.Name)
+    }
+    if t.Type != AttributeEnum {
+        if len(t.Options) > 0 {
+            return fmt.Errorf("only enum attributes take options")
+        }
+        return nil
+    }
+
+    if len(t.Options) == 0 {
+        return fmt.Errorf("an enum attribute needs at least one option")
+    }
+    seen := make(map[string]bool, len(t.Options))
+    for _, option := range t.Options {
+        if option == "" || seen[option] {
+            return fmt.Errorf("enum options must be distinct and not empty")
+        }
+        seen[option] = true
+    }
+    return nil
+}
+
+// AttributeError lists every invalid attribute of a product
+type AttributeError struct {
+    Problems []string
+}
+
+func (e *AttributeError) Error() string {
+    return "invalid attributes: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateAttributes checks a product's attributes against the templates of its category.
+// Attributes without a template are allowed as free-form specs, as long as the value is a
+// string, number or boolean.
+func ValidateAttributes(templates []*AttributeTemplate, attributes Attributes) error {
+    byName := make(map[string]*AttributeTemplate, len(templates))
+    for _, template := range templates {
+        byName[template.Name] = template
+    }
+
+    var problems []string
+    names := make([]string, 0, len(attributes))
+    for name := range attributes {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    for _, name := range names {
+        value := attributes[name]
+        if !attributeName.MatchString(name) {
+            problems = append(problems, fmt.Sprintf("%q is not a valid attribute name", name))
+            continue
+        }
+
+        template, ok := byName[name]
+        if !ok {
+            switch value.(type) {
+            case string, float64, bool:
+            default:
+                problems = append(problems, fmt.Sprintf("%s must be a string, number or boolean", name))
+            }
+            continue
+        }
+
+        if problem := template.check(value); problem != "" {
+            problems = append(problems, fmt.Sprintf("%s %s", name, problem))
+        }
+    }
+
+    for _, template := range templates {
+        if _, ok := attributes[template.Name]; template.Required && !ok {
+            problems = append(problems, fmt.Sprintf("%s is required", template.Name))
+        }
+    }
+
+    if len(problems) > 0 {
+        return &AttributeError{Problems: problems}
+    }
+    return nil
+}
+
+// check returns what is wrong with a value of this attribute, or ""
+func (t *AttributeTemplate) check(value interface{}) string {
+    switch t.Type {
+    case AttributeNumber:
+        if _, ok := value.(float64); !ok {
+            return "must be a number"
+        }
+    case AttributeBoolean:
+        if _, ok := value.(bool); !ok {
+            return "must be a boolean"
+        }
+    case AttributeEnum:
+        s, ok := value.(string)
+        if !ok {
+            return "must be a string"
+        }
+        for _, option := range t.Options {
+            if s == option {
+                return ""
+            }
+        }
+        return fmt.Sprintf("must be one of %s", strings.Join(t.Options, ", "))
+    default:
+        if _, ok := value.(string); !ok {
+            return "must be a string"
+        }
+    }
+    return ""
+}
+
+// MergeAttributes applies changes to a product's attributes; a nil value removes the attribute
+func MergeAttributes(current, changes Attributes) Attributes {
+    merged := make(Attributes, len(current)+len(changes))
+    for name, value := range current {
+        merged[name] = value
+    }
+    for name, value := range changes {
+        if value == nil {
+            delete(merged, name)
+            continue
+        }
+        merged[name] = value
+    }
+    return merged
+}
+
+// AttributeFilter matches products on one attribute: Value for equality, Min and Max for a
+// numeric range (inclusive)
+type AttributeFilter struct {
+    Name  string
+    Value *string
+    Min   *float64
+    Max   *float64
+}
+
+// Candidates returns the JSON values an equality filter matches
+// Why: filters come in as query strings, so "15.6" must match the number 15.6 and "true" the
+// boolean, while still matching a string attribute spelled the same way
+func (f AttributeFilter) Candidates() []string {
+    if f.Value == nil {
+        return nil
+    }
+    encoded, _ := json.Marshal(*f.Value)
+    candidates := []string{string(encoded)}
+
+    if number, err := strconv.ParseFloat(*f.Value, 64); err == nil {
+        candidates = append(candidates, strconv.FormatFloat(number, 'f', -1, 64))
+    }
+    if *f.Value == "true" || *f.Value == "false" {
+        candidates = append(candidates, *f.Value)
+    }
+    return candidates
+}
+
+// ParseAttributeFilters reads attr[name]=value, attr_min[name]=n and attr_max[name]=n query
+// parameters, sorted by name
+func ParseAttributeFilters(values, mins, maxes map[string]string) ([]AttributeFilter, error) {
+    filters := make(map[string]*AttributeFilter)
+    filter := func(name string) (*AttributeFilter, error) {
+        if !attributeName.MatchString(name) {
+            return nil, fmt.Errorf("%q is not a valid attribute name", name)
+        }
+        if filters[name] == nil {
+            filters[name] = &AttributeFilter{Name: name}
+        }
+        return filters[name], nil
+    }
+
+    for name, value := range values {
+        f, err := filter(name)
+        if err != nil {
+            return nil, err
+        }
+        value := value
+        f.Value = &value
+    }
+    for _, bound := range []struct {
+        params map[string]string
+        max    bool
+    }{{mins, false}, {maxes, true}} {
+        for name, raw := range bound.params {
+            f, err := filter(name)
+            if err != nil {
+                return nil, err
+            }
+            number, err := strconv.ParseFloat(raw, 64)
+            if err != nil {
+                return nil, fmt.Errorf("range bound for %s must be a number", name)
+            }
+            if bound.max {
+                f.Max = &number
+            } else {
+                f.Min = &number
+            }
+        }
+    }
+
+    names := make([]string, 0, len(filters))
+    for name := range filters {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    parsed := make([]AttributeFilter, 0, len(names))
+    for _, name := range names {
+        parsed = append(parsed, *filters[name])
+    }
+    return parsed, nil
+}
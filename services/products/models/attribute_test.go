@@ -0,0 +1,121 @@
+package models
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func laptopTemplates() []*AttributeTemplate {
+    return []*AttributeTemplate{
+        NewAttributeTemplate(1, "screen_size", "Screen size", AttributeNumber, "in", nil, true),
+        NewAttributeTemplate(1, "touchscreen", "Touchscreen", AttributeBoolean, "", nil, false),
+        NewAttributeTemplate(2, "panel", "Panel", AttributeEnum, "", []string{"IPS", "OLED"}, false),
+    }
+}
+
+func TestValidateAttributes_AcceptsTypedAndFreeFormValues(t *testing.T) {
+    attributes := Attributes{"screen_size": 15.6, "touchscreen": false, "panel": "OLED", "color": "black"}
+
+    if err := ValidateAttributes(laptopTemplates(), attributes); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
+
+func TestValidateAttributes_ReportsEveryProblem(t *testing.T) {
+    attributes := Attributes{"touchscreen": "yes", "panel": "TN", "Bad Name": 1.0, "specs": map[string]interface{}{}}
+
+    err := ValidateAttributes(laptopTemplates(), attributes)
+    attrErr, ok := err.(*AttributeError)
+    if !ok {
+        t.Fatalf("expected *AttributeError, got %v", err)
+    }
+
+    want := []string{
+        `"Bad Name" is not a valid attribute name`,
+        "panel must be one of IPS, OLED",
+        "specs must be a string, number or boolean",
+        "touchscreen must be a boolean",
+        "screen_size is required",
+    }
+    if !reflect.DeepEqual(attrErr.Problems, want) {
+        t.Errorf("problems = %q, want %q", attrErr.Problems, want)
+    }
+}
+
+func TestAttributeTemplateValidate(t *testing.T) {
+    tests := []struct {
+        name     string
+        template *AttributeTemplate
+        wantErr  string
+    }{
+        {"valid enum", NewAttributeTemplate(1, "panel", "", AttributeEnum, "", []string{"IPS"}, false), ""},
+        {"enum without options", NewAttributeTemplate(1, "panel", "", AttributeEnum, "", nil, false), "at least one option"},
+        {"duplicate options", NewAttributeTemplate(1, "panel", "", AttributeEnum, "", []string{"IPS", "IPS"}, false), "distinct"},
+        {"options on a number", NewAttributeTemplate(1, "weight", "", AttributeNumber, "kg", []string{"1"}, false), "only enum"},
+        {"invalid name", NewAttributeTemplate(1, "Screen Size", "", AttributeNumber, "", nil, false), "lowercase"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := tt.template.Validate()
+            if tt.wantErr == "" {
+                if err != nil {
+                    t.Fatalf("unexpected error: %v", err)
+                }
+                return
+            }
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestMergeAttributes_NilRemoves(t *testing.T) {
+    current := Attributes{"color": "black", "weight": 1.2}
+
+    merged := MergeAttributes(current, Attributes{"color": nil, "touchscreen": true})
+
+    want := Attributes{"weight": 1.2, "touchscreen": true}
+    if !reflect.DeepEqual(merged, want) {
+        t.Errorf("merged = %v, want %v", merged, want)
+    }
+    if _, ok := current["color"]; !ok {
+        t.Error("expected the current attributes to be left alone")
+    }
+}
+
+func TestParseAttributeFilters(t *testing.T) {
+    filters, err := ParseAttributeFilters(
+        map[string]string{"touchscreen": "true"},
+        map[string]string{"screen_size": "13"},
+        map[string]string{"screen_size": "15.6"},
+    )
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if len(filters) != 2 || filters[0].Name != "screen_size" || filters[1].Name != "touchscreen" {
+        t.Fatalf("expected filters sorted by name, got %+v", filters)
+    }
+    if *filters[0].Min != 13 || *filters[0].Max != 15.6 || filters[0].Value != nil {
+        t.Errorf("screen_size filter = %+v, want range 13..15.6", filters[0])
+    }
+    if got := filters[1].Candidates(); !reflect.DeepEqual(got, []string{`"true"`, "true"}) {
+        t.Errorf("touchscreen candidates = %q, want the string and the boolean", got)
+    }
+
+    if _, err := ParseAttributeFilters(nil, map[string]string{"screen_size": "big"}, nil); err == nil {
+        t.Error("expected a non-numeric range bound to be rejected")
+    }
+}
+
+func TestAttributeFilterCandidates_MatchesNumbers(t *testing.T) {
+    value := "15.60"
+    got := AttributeFilter{Name: "screen_size", Value: &value}.Candidates()
+
+    want := []string{`"15.60"`, "15.6"}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("candidates = %q, want %q", got, want)
+    }
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// MaxCartLockTTL caps how long a cart's soft lock holds stock, whatever the cart asks for
+const MaxCartLockTTL = 30 * time.Minute
+
+// CartLockRequest request body for soft-locking a cart line's stock.
+// Quantity is the line's whole quantity; the cart's earlier lock on the line is replaced.
+type CartLockRequest struct {
+    CartID     string `json:"cart_id" binding:"required,max=64"`
+    ProductID  int64  `json:"product_id" binding:"required"`
+    VariantID  *int64 `json:"variant_id"`
+    Quantity   int    `json:"quantity" binding:"required,gt=0"`
+    TTLSeconds int    `json:"ttl_seconds" binding:"required,gt=0"`
+}
+
+// TTL is the requested hold, capped at MaxCartLockTTL
+func (r *CartLockRequest) TTL() time.Duration {
+    ttl := time.Duration(r.TTLSeconds) * time.Second
+    if ttl > MaxCartLockTTL {
+        return MaxCartLockTTL
+    }
+    return ttl
+}
+
+// CartLock is the soft lock handed back to the cart service
+type CartLock struct {
+    ReservationID string    `json:"reservation_id"`
+    ProductID     int64     `json:"product_id"`
+    VariantID     *int64    `json:"variant_id,omitempty"`
+    Quantity      int       `json:"quantity"`
+    ExpiresAt     time.Time `json:"expires_at"`
+    TTLSeconds    int       `json:"ttl_seconds"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import "sort"
+
+// Catalog restructuring: merging a category into another and moving products between categories.
+// Both run as a dry-run preview first, so an admin sees what moves and which products would no
+// longer fit their new category's attribute templates before anything changes.
+
+// MergeCategoryRequest request body for merging the category in the path into TargetID
+type MergeCategoryRequest struct {
+    TargetID int64 `json:"target_id" binding:"required"`
+    DryRun   bool  `json:"dry_run"`
+    Force    bool  `json:"force"` // move products even if their attributes don't fit the target's templates
+}
+
+// AssignProductsRequest request body for moving products into the category in the path
+type AssignProductsRequest struct {
+    ProductIDs []int64 `json:"product_ids" binding:"required,min=1,max=1000"` // at most 1000 per call
+    DryRun     bool    `json:"dry_run"`
+    Force      bool    `json:"force"`
+}
+
+// ProductAttributeProblems lists why a product's attributes don't fit its new category
+type ProductAttributeProblems struct {
+    ProductID int64    `json:"product_id"`
+    Problems  []string `json:"problems"`
+}
+
+// CategoryChange is the preview or the result of a merge or a bulk assignment
+type CategoryChange struct {
+    DryRun              bool                       `json:"dry_run"`
+    SourceCategoryID    *int64                     `json:"source_category_id,omitempty"` // merge only
+    TargetCategoryID    int64                      `json:"target_category_id"`
+    ProductIDs          []int64                    `json:"product_ids"`                     // products that move
+    UnchangedProductIDs []int64                    `json:"unchanged_product_ids,omitempty"` // already in the target
+    MissingProductIDs   []int64                    `json:"missing_product_ids,omitempty"`   // unknown or deleted
+    SubcategoryIDs      []int64                    `json:"subcategory_ids,omitempty"`       // re-parented under the target
+    MovedTemplates      []string                   `json:"moved_templates,omitempty"`       // source templates the target takes over
+    DroppedTemplates    []string                   `json:"dropped_templates,omitempty"`     // source templates the target already has
+    AttributeProblems   []ProductAttributeProblems `json:"attribute_problems"`
+    TargetProductCount  int                        `json:"target_product_count"` // products directly in the target afterwards
+}
+
+// CategorySubtree returns the IDs of category rootID and all its subcategories.
+// Why: merging a category into its own subcategory would leave that subcategory its own ancestor.
+func CategorySubtree(categories []*Category, rootID int64) map[int64]bool {
+    children := make(map[int64][]int64, len(categories))
+    for _, category := range categories {
+        if category.ParentID != nil {
+            children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+        }
+    }
+
+    subtree := map[int64]bool{rootID: true}
+    queue := []int64{rootID}
+    for len(queue) > 0 {
+        id := queue[0]
+        queue = queue[1:]
+        for _, child := range children[id] {
+            if !subtree[child] {
+                subtree[child] = true
+                queue = append(queue, child)
+            }
+        }
+    }
+    return subtree
+}
+
+// MergeTemplates works out which of the source category's own templates the target takes over:
+// those whose name none of the target's templates (own or inherited) already uses. It returns
+// the templates that apply to the target after the merge, and the moved and dropped names.
+func MergeTemplates(target, sourceOwn []*AttributeTemplate) (merged []*AttributeTemplate, moved, dropped []string) {
+    names := make(map[string]bool, len(target))
+    merged = append(merged, target...)
+    for _, template := range target {
+        names[template.Name] = true
+    }
+
+    for _, template := range sourceOwn {
+        if names[template.Name] {
+            dropped = append(dropped, template.Name)
+            continue
+        }
+        names[template.Name] = true
+        merged = append(merged, template)
+        moved = append(moved, template.Name)
+    }
+
+    sort.Strings(moved)
+    sort.Strings(dropped)
+    return merged, moved, dropped
+}
+
+// CheckCategoryAttributes returns the products whose attributes don't fit templates
+func CheckCategoryAttributes(templates []*AttributeTemplate, products []*Product) []ProductAttributeProblems {
+    problems := []ProductAttributeProblems{}
+    for _, product := range products {
+        err := ValidateAttributes(templates, product.Attributes)
+        if attrErr, ok := err.(*AttributeError); ok {
+            problems = append(problems, ProductAttributeProblems{ProductID: product.ID, Problems: attrErr.Problems})
+        }
+    }
+    return problems
+}
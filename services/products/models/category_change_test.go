@@ -0,0 +1,59 @@
+package models
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestCategorySubtree(t *testing.T) {
+    id := func(v int64) *int64 { return &v }
+    categories := []*Category{
+        {ID: 1},
+        {ID: 2, ParentID: id(1)},
+        {ID: 3, ParentID: id(2)},
+        {ID: 4, ParentID: id(1)},
+        {ID: 5},
+        {ID: 6, ParentID: id(7)}, // cycle
+        {ID: 7, ParentID: id(6)},
+    }
+
+    want := map[int64]bool{2: true, 3: true}
+    if got := CategorySubtree(categories, 2); !reflect.DeepEqual(got, want) {
+        t.Errorf("subtree of 2 = %v, want %v", got, want)
+    }
+    if got := CategorySubtree(categories, 6); len(got) != 2 {
+        t.Errorf("subtree of a cycle = %v, want both categories once", got)
+    }
+}
+
+func TestMergeTemplates(t *testing.T) {
+    target := []*AttributeTemplate{NewAttributeTemplate(2, "screen_size", "", AttributeNumber, "in", nil, true)}
+    source := []*AttributeTemplate{
+        NewAttributeTemplate(1, "screen_size", "", AttributeNumber, "cm", nil, false),
+        NewAttributeTemplate(1, "touchscreen", "", AttributeBoolean, "", nil, false),
+    }
+
+    merged, moved, dropped := MergeTemplates(target, source)
+
+    if len(merged) != 2 || merged[0] != target[0] || merged[1] != source[1] {
+        t.Errorf("merged = %+v, want the target's screen_size and the source's touchscreen", merged)
+    }
+    if !reflect.DeepEqual(moved, []string{"touchscreen"}) || !reflect.DeepEqual(dropped, []string{"screen_size"}) {
+        t.Errorf("moved = %q, dropped = %q", moved, dropped)
+    }
+}
+
+func TestCheckCategoryAttributes(t *testing.T) {
+    templates := []*AttributeTemplate{NewAttributeTemplate(2, "screen_size", "", AttributeNumber, "in", nil, true)}
+    products := []*Product{
+        {ID: 1, Attributes: Attributes{"screen_size": 15.6}},
+        {ID: 2, Attributes: Attributes{"color": "black"}},
+    }
+
+    problems := CheckCategoryAttributes(templates, products)
+
+    want := []ProductAttributeProblems{{ProductID: 2, Problems: []string{"screen_size is required"}}}
+    if !reflect.DeepEqual(problems, want) {
+        t.Errorf("problems = %+v, want %+v", problems, want)
+    }
+}
@@ -0,0 +1,70 @@
+package models
+
+// CategoryNode is a category with its subcategories and product counts
+type CategoryNode struct {
+    *Category
+    ProductCount      int             `json:"product_count"`       // products directly in this category
+    TotalProductCount int             `json:"total_product_count"` // including all subcategories
+    Children          []*CategoryNode `json:"children"`
+}
+
+// BuildCategoryTree nests categories under their parents and adds up product counts
+// (productCounts is keyed by category ID). Categories whose parent is missing (deleted)
+// become roots. Roots and children keep the order of categories.
+// Why: a parent_id cycle can't be inserted through the API, but a category in one would
+// never be reached from a root; those are returned as roots too so nothing disappears.
+func BuildCategoryTree(categories []*Category, productCounts map[int64]int) []*CategoryNode {
+    nodes := make(map[int64]*CategoryNode, len(categories))
+    for _, category := range categories {
+        nodes[category.ID] = &CategoryNode{
+            Category:     category,
+            ProductCount: productCounts[category.ID],
+            Children:     []*CategoryNode{},
+        }
+    }
+
+    roots := []*CategoryNode{}
+    for _, category := range categories {
+        var parent *CategoryNode
+        if category.ParentID != nil {
+            parent = nodes[*category.ParentID]
+        }
+        if parent != nil {
+            parent.Children = append(parent.Children, nodes[category.ID])
+        } else {
+            roots = append(roots, nodes[category.ID])
+        }
+    }
+
+    visited := make(map[int64]bool, len(nodes))
+    for _, root := range roots {
+        sumProducts(root, visited)
+    }
+    for _, category := range categories {
+        if !visited[category.ID] {
+            roots = append(roots, nodes[category.ID])
+            sumProducts(nodes[category.ID], visited)
+        }
+    }
+
+    return roots
+}
+
+// sumProducts fills TotalProductCount bottom-up, dropping children already in the tree
+// so a cycle can't nest forever
+func sumProducts(node *CategoryNode, visited map[int64]bool) int {
+    visited[node.ID] = true
+    node.TotalProductCount = node.ProductCount
+
+    children := node.Children[:0]
+    for _, child := range node.Children {
+        if visited[child.ID] {
+            continue
+        }
+        node.TotalProductCount += sumProducts(child, visited)
+        children = append(children, child)
+    }
+    node.Children = children
+
+    return node.TotalProductCount
+}
@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func testCategory(id int64, parentID *int64) *Category {
+    return &Category{ID: id, ParentID: parentID}
+}
+
+func int64Ptr(id int64) *int64 { return &id }
+
+func TestBuildCategoryTree_NestsAndSumsCounts(t *testing.T) {
+    categories := []*Category{
+        testCategory(1, nil),     // Electronics
+        testCategory(2, int64Ptr(1)),  // Phones
+        testCategory(3, int64Ptr(2)),  // Android
+        testCategory(4, nil),     // Books
+        testCategory(5, int64Ptr(99)), // parent deleted
+    }
+
+    roots := BuildCategoryTree(categories, map[int64]int{1: 1, 2: 2, 3: 4, 4: 8, 5: 16})
+
+    if len(roots) != 3 || roots[0].ID != 1 || roots[1].ID != 4 || roots[2].ID != 5 {
+        t.Fatalf("expected roots 1, 4, 5, got %+v", roots)
+    }
+    electronics := roots[0]
+    if electronics.ProductCount != 1 || electronics.TotalProductCount != 7 {
+        t.Errorf("electronics counts = %d/%d, want 1/7", electronics.ProductCount, electronics.TotalProductCount)
+    }
+    phones := electronics.Children[0]
+    if phones.ID != 2 || phones.TotalProductCount != 6 || phones.Children[0].ID != 3 {
+        t.Errorf("phones = %+v, want id 2 with total 6 and child 3", phones)
+    }
+    if roots[2].TotalProductCount != 16 {
+        t.Errorf("orphan total = %d, want 16", roots[2].TotalProductCount)
+    }
+}
+
+func TestBuildCategoryTree_BreaksCycles(t *testing.T) {
+    categories := []*Category{testCategory(1, int64Ptr(2)), testCategory(2, int64Ptr(1))}
+
+    roots := BuildCategoryTree(categories, map[int64]int{1: 1, 2: 2})
+
+    if len(roots) != 1 || roots[0].TotalProductCount != 3 {
+        t.Fatalf("expected one root counting both categories, got %+v", roots)
+    }
+    if child := roots[0].Children[0]; len(child.Children) != 0 {
+        t.Errorf("cycle not broken: %+v", child.Children)
+    }
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "time"
+)
+
+// Reservation statuses
+// Why: order saga reservations end as confirmed; channel reservations end as committed
+// once the sale happened on the channel and stock was taken out
+const (
+    ReservationStatusReserved  = "reserved"
+    ReservationStatusReleased  = "released"
+    ReservationStatusExpired   = "expired"
+    ReservationStatusConfirmed = "confirmed"
+    ReservationStatusCommitted = "committed"
+)
+
+// Channel hold defaults
+const (
+    DefaultChannelHold           = 15 * time.Minute // hold when a reservation doesn't ask for one
+    DefaultChannelMaxHoldMinutes = 60               // max_hold_minutes for new channels
+)
+
+// SalesChannel is an external channel (POS, marketplace) allowed to reserve stock
+type SalesChannel struct {
+    ID             string    `json:"id"`
+    Name           string    `json:"name"`
+    Quota          int       `json:"quota"` // max units held in active reservations at once
+    MaxHoldMinutes int       `json:"max_hold_minutes"`
+    Active         bool      `json:"active"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ChannelReservation is a reservation held by a sales channel
+type ChannelReservation struct {
+    InventoryReservation
+    ChannelID   string     `json:"channel_id"`
+    ExternalRef string     `json:"external_ref,omitempty"` // channel's own order/basket reference
+    CommittedAt *time.Time `json:"committed_at,omitempty"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateChannelRequest request body for registering a sales channel
+type CreateChannelRequest struct {
+    ID             string `json:"id" binding:"required,max=50"`
+    Name           string `json:"name" binding:"required"`
+    Quota          int    `json:"quota" binding:"required,gt=0"`
+    MaxHoldMinutes int    `json:"max_hold_minutes" binding:"omitempty,gt=0"`
+}
+
+// ChannelReserveRequest request body for a channel reservation
+// Why: external_ref makes retries safe; the same ref returns the existing reservation
+type ChannelReserveRequest struct {
+    ProductID   int64  `json:"product_id" binding:"required"`
+    Quantity    int    `json:"quantity" binding:"required,gt=0"`
+    ExternalRef string `json:"external_ref" binding:"max=255"`
+    HoldMinutes int    `json:"hold_minutes" binding:"omitempty,gt=0"`
+}
+
+// ChannelExtendRequest request body for extending a channel reservation
+type ChannelExtendRequest struct {
+    HoldMinutes int `json:"hold_minutes" binding:"omitempty,gt=0"`
+}
+
+// ChannelStatusTotals counts reservations and units in one status
+type ChannelStatusTotals struct {
+    Reservations int `json:"reservations"`
+    Units        int `json:"units"`
+}
+
+// ChannelReport summarizes a channel's reservations over a period
+type ChannelReport struct {
+    ChannelID      string                         `json:"channel_id"`
+    From           time.Time                      `json:"from"`
+    To             time.Time                      `json:"to"`
+    Quota          int                            `json:"quota"`
+    HeldUnits      int                            `json:"held_units"` // currently reserved, regardless of period
+    ByStatus       map[string]ChannelStatusTotals `json:"by_status"`
+    ConversionRate float64                        `json:"conversion_rate"` // committed / all reservations in period
+}
+
+// HoldFor returns how long to hold a reservation: the requested minutes
+// (or DefaultChannelHold) capped at the channel's maximum
+func (ch *SalesChannel) HoldFor(requestedMinutes int) time.Duration {
+    hold := DefaultChannelHold
+    if requestedMinutes > 0 {
+        hold = time.Duration(requestedMinutes) * time.Minute
+    }
+    if max := time.Duration(ch.MaxHoldMinutes) * time.Minute; max > 0 && hold > max {
+        hold = max
+    }
+    return hold
+}
+
+// NewChannelReport builds a report from per-status totals
+func NewChannelReport(ch *SalesChannel, from, to time.Time, heldUnits int, byStatus map[string]ChannelStatusTotals) *ChannelReport {
+    report := &ChannelReport{
+        ChannelID: ch.ID,
+        From:      from,
+        To:        to,
+        Quota:     ch.Quota,
+        HeldUnits: heldUnits,
+        ByStatus:  byStatus,
+    }
+
+    total := 0
+    for _, totals := range byStatus {
+        total += totals.Reservations
+    }
+    if total > 0 {
+        report.ConversionRate = float64(byStatus[ReservationStatusCommitted].Reservations) / float64(total)
+    }
+
+    return report
+}
+
+// HashChannelKey hashes a channel API key for storage and lookup
+func HashChannelKey(apiKey string) string {
+    sum := sha256.Sum256([]byte(apiKey))
+    return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+    "testing"
+    "time"
+)
+
+func TestSalesChannel_HoldFor(t *testing.T) {
+    ch := &SalesChannel{MaxHoldMinutes: 30}
+
+    cases := []struct {
+        requested int
+        want      time.Duration
+    }{
+        {0, DefaultChannelHold},
+        {10, 10 * time.Minute},
+        {90, 30 * time.Minute}, // capped at the channel max
+    }
+    for _, tc := range cases {
+        if got := ch.HoldFor(tc.requested); got != tc.want {
+            t.Fatalf("HoldFor(%d): expected %s, got %s", tc.requested, tc.want, got)
+        }
+    }
+}
+
+func TestNewChannelReport_ConversionRate(t *testing.T) {
+    ch := &SalesChannel{ID: "pos-1", Quota: 50}
+    now := time.Now()
+
+    report := NewChannelReport(ch, now.Add(-time.Hour), now, 4, map[string]ChannelStatusTotals{
+        ReservationStatusCommitted: {Reservations: 3, Units: 6},
+        ReservationStatusExpired:   {Reservations: 1, Units: 2},
+        ReservationStatusReserved:  {Reservations: 2, Units: 4},
+        ReservationStatusReleased:  {Reservations: 2, Units: 3},
+    })
+
+    if report.ConversionRate != 3.0/8.0 {
+        t.Fatalf("expected conversion rate %v, got %v", 3.0/8.0, report.ConversionRate)
+    }
+    if report.Quota != 50 || report.HeldUnits != 4 {
+        t.Fatalf("unexpected quota/held: %+v", report)
+    }
+
+    empty := NewChannelReport(ch, now, now, 0, map[string]ChannelStatusTotals{})
+    if empty.ConversionRate != 0 {
+        t.Fatalf("expected 0 conversion for no reservations, got %v", empty.ConversionRate)
+    }
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// Inventory movement reasons; all but order_confirmed are admin stock adjustments
+const (
+    MovementReasonOrderConfirmed = "order_confirmed"
+    MovementReasonDamage         = "damage"
+    MovementReasonRecount        = "recount"
+    MovementReasonReturn         = "return"
+)
+
+// adjustmentReasons are the reasons an admin may adjust stock for
+var adjustmentReasons = map[string]bool{
+    MovementReasonDamage:  true,
+    MovementReasonRecount: true,
+    MovementReasonReturn:  true,
+}
+
+// Page sizes of GET /inventory/:product_id/movements
+const (
+    DefaultMovementLimit = 50
+    MaxMovementLimit     = 200
+)
+
+// InventoryMovement is one audited change of a product's (or variant's) stock_quantity
+type InventoryMovement struct {
+    ID            int64     `json:"id"`
+    ProductID     int64     `json:"product_id"`
+    VariantID     *int64    `json:"variant_id,omitempty"` // nil when the product's own stock moved
+    Delta         int       `json:"delta"`                // negative takes stock out
+    StockAfter    int       `json:"stock_after"`
+    Reason        string    `json:"reason"` // order_confirmed, damage, recount, return
+    OrderID       *int64    `json:"order_id,omitempty"`
+    ReservationID *string   `json:"reservation_id,omitempty"`
+    ActorID       *string   `json:"actor_id,omitempty"` // who adjusted the stock; nil for order movements
+    Note          *string   `json:"note,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockAdjustmentRequest is the body of POST /inventory/:product_id/adjust
+type StockAdjustmentRequest struct {
+    VariantID *int64 `json:"variant_id"` // adjusts the variant's stock instead of the product's
+    Delta     int    `json:"delta"`      // negative takes stock out
+    Reason    string `json:"reason"`     // damage, recount, return
+    Note      string `json:"note"`
+}
+
+// Validate checks the adjustment changes stock for a known reason
+func (r *StockAdjustmentRequest) Validate() error {
+    if r.Delta == 0 {
+        return fmt.Errorf("delta must not be 0")
+    }
+    if !adjustmentReasons[r.Reason] {
+        return fmt.Errorf("reason must be damage, recount or return, not %q", r.Reason)
+    }
+    return nil
+}
+
+// ParseMovementLimit reads ?limit= for the movements list; empty is the default and larger
+// values are capped at MaxMovementLimit
+func ParseMovementLimit(raw string) (int, error) {
+    if raw == "" {
+        return DefaultMovementLimit, nil
+    }
+    limit, err := strconv.Atoi(raw)
+    if err != nil || limit < 1 {
+        return 0, fmt.Errorf("limit must be a positive integer")
+    }
+    if limit > MaxMovementLimit {
+        limit = MaxMovementLimit
+    }
+    return limit, nil
+}
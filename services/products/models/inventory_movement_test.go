@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestParseMovementLimit(t *testing.T) {
+    tests := []struct {
+        raw     string
+        want    int
+        wantErr bool
+    }{
+        {"", DefaultMovementLimit, false},
+        {"10", 10, false},
+        {"5000", MaxMovementLimit, false},
+        {"0", 0, true},
+        {"ten", 0, true},
+    }
+
+    for _, tt := range tests {
+        got, err := ParseMovementLimit(tt.raw)
+        if (err != nil) != tt.wantErr || got != tt.want {
+            t.Errorf("ParseMovementLimit(%q) = %d, %v; want %d (error %v)", tt.raw, got, err, tt.want, tt.wantErr)
+        }
+    }
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LowStockProduct is a product whose available stock is below its low-stock threshold
+type LowStockProduct struct {
+    ProductID     int64      `json:"product_id"`
+    Name          string     `json:"name"`
+    SKU           string     `json:"sku"`
+    StockQuantity int        `json:"stock_quantity"`
+    Reserved      int        `json:"reserved"`
+    Available     int        `json:"available"` // stock_quantity - reserved
+    Threshold     int        `json:"threshold"`
+    AlertedAt     *time.Time `json:"alerted_at"` // LowStock was published for the current dip
+}
@@ -1,44 +1,87 @@
 package models
 
 import (
+    "sort"
     "time"
 
     "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/shared/pagination"
 )
 
 // Category represents a product category
 type Category struct {
-    ID          int64     `json:"id"`
-    Name        string    `json:"name"`
-    Description string    `json:"description"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID          int64      `json:"id"`
+    Name        string     `json:"name"`
+    Description string     `json:"description"`
+    ParentID    *int64     `json:"parent_id"` // nil for top-level categories
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
     DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Product represents a product in the catalog
 type Product struct {
-    ID            int64      `json:"id"`
-    Name          string     `json:"name"`
-    Description   string     `json:"description"`
-    Price         float64    `json:"price"`
-    SKU           string     `json:"sku"`
-    CategoryID    *int64     `json:"category_id"`
-    StockQuantity int        `json:"stock_quantity"`
-    ImageURL      string     `json:"image_url"`
-    CreatedAt     time.Time  `json:"created_at"`
-    UpdatedAt     time.Time  `json:"updated_at"`
-    DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+    ID              int64      `json:"id"`
+    Name            string     `json:"name"`
+    Description     string     `json:"description"`
+    Price           float64    `json:"price"`
+    SKU             string     `json:"sku"`
+    CategoryID      *int64     `json:"category_id"`
+    StockQuantity   int        `json:"stock_quantity"`
+    ImageURL        string     `json:"image_url"`
+    Warehouse       string     `json:"warehouse"`        // where the product ships from
+    FulfillmentType string     `json:"fulfillment_type"` // standard, digital or dropship
+    Attributes      Attributes `json:"attributes"`       // specs, checked against the category's attribute templates
+    LowStockThreshold int    `json:"low_stock_threshold"` // LowStock fires when available stock falls below it; 0 turns it off
+    AverageRating   float64    `json:"average_rating"`   // approved reviews only
+    ReviewCount     int        `json:"review_count"`
+    CreatedAt       time.Time  `json:"created_at"`
+    UpdatedAt       time.Time  `json:"updated_at"`
+    DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+    Variants        []*ProductVariant `json:"variants,omitempty"` // only on single-product reads
+}
+
+// Category list keyset paging (?first= and ?after=)
+const (
+    DefaultCategoryPageSize = 20
+    MaxCategoryPageSize     = 100
+)
+
+// CategoryCursor is the category's position in the newest-first category list
+func CategoryCursor(category *Category) pagination.Cursor {
+    return pagination.Cursor{Key: pagination.TimeKey(category.CreatedAt), ID: category.ID}
 }
 
+// Product list keyset paging (?first= and ?after=)
+const (
+    DefaultProductPageSize = 20
+    MaxProductPageSize     = 100
+)
+
+// ProductCursor is the product's position in the newest-first product list
+func ProductCursor(product *Product) pagination.Cursor {
+    return pagination.Cursor{Key: pagination.TimeKey(product.CreatedAt), ID: product.ID}
+}
+
+// Fulfillment routing defaults
+// Why: checkouts are split into one order per warehouse and fulfillment type (see the orders
+// service), so every product needs both; existing products ship from the main warehouse.
+const (
+    DefaultWarehouse    = "main"
+    FulfillmentStandard = "standard"
+    FulfillmentDigital  = "digital"
+    FulfillmentDropship = "dropship"
+)
+
 // InventoryReservation tracks reserved inventory for orders
 type InventoryReservation struct {
     ID            string     `json:"id"`
     ProductID     int64      `json:"product_id"`
+    VariantID     *int64     `json:"variant_id,omitempty"` // nil when the product's own stock is held
     Quantity      int        `json:"quantity"`
     OrderID       int64      `json:"order_id"`
     ReservationID string     `json:"reservation_id"`
-    Status        string     `json:"status"` // reserved, released, expired
+    Status        string     `json:"status"` // reserved, released, expired, committed
     CreatedAt     time.Time  `json:"created_at"`
     ExpiresAt     time.Time  `json:"expires_at"`
     ReleasedAt    *time.Time `json:"released_at,omitempty"`
@@ -46,28 +89,37 @@ type InventoryReservation struct {
 
 // CreateProductRequest request body for creating product
 type CreateProductRequest struct {
-    Name        string   `json:"name" binding:"required"`
-    Description string   `json:"description"`
-    Price       float64  `json:"price" binding:"required,gt=0"`
-    SKU         string   `json:"sku" binding:"required"`
-    CategoryID  *int64   `json:"category_id"`
-    Stock       int      `json:"stock" binding:"required,gte=0"`
-    ImageURL    string   `json:"image_url"`
+    Name            string  `json:"name" binding:"required"`
+    Description     string  `json:"description"`
+    Price           float64 `json:"price" binding:"required,gt=0"`
+    SKU             string  `json:"sku" binding:"required"`
+    CategoryID      *int64  `json:"category_id"`
+    Stock           int     `json:"stock" binding:"required,gte=0"`
+    ImageURL        string  `json:"image_url"`
+    Warehouse       string  `json:"warehouse"`                                                            // default: main
+    FulfillmentType string  `json:"fulfillment_type" binding:"omitempty,oneof=standard digital dropship"` // default: standard
+    Attributes      Attributes `json:"attributes"`
+    LowStockThreshold int   `json:"low_stock_threshold" binding:"gte=0"` // default: 0, no alerts
 }
 
 // UpdateProductRequest request body for updating product
 type UpdateProductRequest struct {
-    Name        string   `json:"name"`
-    Description string   `json:"description"`
-    Price       float64  `json:"price"`
-    Stock       int      `json:"stock"`
-    ImageURL    string   `json:"image_url"`
+    Name            string  `json:"name"`
+    Description     string  `json:"description"`
+    Price           float64 `json:"price"`
+    Stock           int     `json:"stock"`
+    ImageURL        string  `json:"image_url"`
+    Warehouse       string  `json:"warehouse"`
+    FulfillmentType string  `json:"fulfillment_type" binding:"omitempty,oneof=standard digital dropship"`
+    Attributes      Attributes `json:"attributes"` // merged into the current attributes; null removes one
+    LowStockThreshold *int  `json:"low_stock_threshold" binding:"omitempty,gte=0"` // 0 turns low-stock alerts off
 }
 
 // CreateCategoryRequest request body for creating category
 type CreateCategoryRequest struct {
     Name        string `json:"name" binding:"required"`
     Description string `json:"description"`
+    ParentID    *int64 `json:"parent_id"`
 }
 
 // ReserveInventoryRequest request to reserve inventory
@@ -100,11 +152,12 @@ type ErrorResponse struct {
 }
 
 // NewCategory creates new category
-func NewCategory(name, description string) *Category {
+func NewCategory(name, description string, parentID *int64) *Category {
     now := time.Now().UTC()
     return &Category{
         Name:        name,
         Description: description,
+        ParentID:    parentID,
         CreatedAt:   now,
         UpdatedAt:   now,
     }
@@ -114,15 +167,18 @@ func NewCategory(name, description string) *Category {
 func NewProduct(name, description string, price float64, sku string, categoryID *int64, stock int, imageURL string) *Product {
     now := time.Now().UTC()
     return &Product{
-        Name:          name,
-        Description:   description,
-        Price:         price,
-        SKU:           sku,
-        CategoryID:    categoryID,
-        StockQuantity: stock,
-        ImageURL:      imageURL,
-        CreatedAt:     now,
-        UpdatedAt:     now,
+        Name:            name,
+        Description:     description,
+        Price:           price,
+        SKU:             sku,
+        CategoryID:      categoryID,
+        StockQuantity:   stock,
+        ImageURL:        imageURL,
+        Warehouse:       DefaultWarehouse,
+        FulfillmentType: FulfillmentStandard,
+        Attributes:      Attributes{},
+        CreatedAt:       now,
+        UpdatedAt:       now,
     }
 }
 
@@ -139,4 +195,41 @@ func NewInventoryReservation(productID int64, quantity int, orderID int64, reser
         CreatedAt:     now,
         ExpiresAt:     now.Add(24 * time.Hour),
     }
-}
\ No newline at end of file
+}
+
+// IsExpired reports whether a still-held reservation has passed its expiry at the given time
+func (r *InventoryReservation) IsExpired(now time.Time) bool {
+    return r.Status == "reserved" && now.After(r.ExpiresAt)
+}
+
+// OrderLine is one product line of an order to reserve stock for
+type OrderLine struct {
+    ProductID int64 `json:"product_id"`
+    VariantID int64 `json:"variant_id,omitempty"` // 0 for the product itself
+    Quantity  int   `json:"quantity"`
+}
+
+// MergeOrderLines sums the quantities of lines for the same product and variant and sorts
+// the result by product ID, then variant ID, the order rows are locked in when reserving
+func MergeOrderLines(lines []OrderLine) []OrderLine {
+    type lineKey struct{ productID, variantID int64 }
+    totals := make(map[lineKey]int, len(lines))
+    var merged []OrderLine
+    for _, line := range lines {
+        key := lineKey{line.ProductID, line.VariantID}
+        if _, seen := totals[key]; !seen {
+            merged = append(merged, OrderLine{ProductID: line.ProductID, VariantID: line.VariantID})
+        }
+        totals[key] += line.Quantity
+    }
+    for i := range merged {
+        merged[i].Quantity = totals[lineKey{merged[i].ProductID, merged[i].VariantID}]
+    }
+    sort.Slice(merged, func(i, j int) bool {
+        if merged[i].ProductID != merged[j].ProductID {
+            return merged[i].ProductID < merged[j].ProductID
+        }
+        return merged[i].VariantID < merged[j].VariantID
+    })
+    return merged
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+    "net/http"
+    "time"
+)
+
+// DefaultMaxImageBytes is the upload size cap when none is configured
+const DefaultMaxImageBytes = 5 << 20
+
+// imageExtensions are the accepted image types, by sniffed content type
+var imageExtensions = map[string]string{
+    "image/jpeg": ".jpg",
+    "image/png":  ".png",
+    "image/gif":  ".gif",
+    "image/webp": ".webp",
+}
+
+// ProductImage is an uploaded product image
+type ProductImage struct {
+    ID          int64     `json:"id"`
+    ProductID   int64     `json:"product_id"`
+    StorageKey  string    `json:"storage_key"` // path of the file in the storage backend
+    URL         string    `json:"url"`
+    ContentType string    `json:"content_type"`
+    SizeBytes   int64     `json:"size_bytes"`
+    Primary     bool      `json:"primary"` // its url is the product's image_url
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+// DetectImageType sniffs the content type of an upload from its first bytes and returns it
+// with the file extension to store it under; ok is false for anything but JPEG, PNG, GIF and WebP.
+// Why: the client's Content-Type and file name are not trusted, since the file is served
+// back to shoppers as-is.
+func DetectImageType(data []byte) (contentType, ext string, ok bool) {
+    contentType = http.DetectContentType(data)
+    ext, ok = imageExtensions[contentType]
+    return contentType, ext, ok
+}
@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestDetectImageType(t *testing.T) {
+    png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+    if contentType, ext, ok := DetectImageType(png); !ok || contentType != "image/png" || ext != ".png" {
+        t.Fatalf("expected a png, got %q %q %v", contentType, ext, ok)
+    }
+
+    jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'}
+    if _, ext, ok := DetectImageType(jpeg); !ok || ext != ".jpg" {
+        t.Fatalf("expected a jpeg, got %q %v", ext, ok)
+    }
+
+    // Sniffed, so an HTML page named photo.png is still rejected
+    for _, data := range [][]byte{[]byte("<html><script>alert(1)</script></html>"), []byte("%PDF-1.7"), nil} {
+        if contentType, _, ok := DetectImageType(data); ok {
+            t.Errorf("expected %q to be rejected, got %q", data, contentType)
+        }
+    }
+}
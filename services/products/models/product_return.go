@@ -0,0 +1,149 @@
+package models
+
+import (
+    "sort"
+    "time"
+)
+
+// Return statuses
+const (
+    ReturnStatusReceived     = "received"
+    ReturnStatusInspected    = "inspected"
+    ReturnStatusRefurbishing = "refurbishing"
+    ReturnStatusRestocked    = "restocked"
+    ReturnStatusWrittenOff   = "written_off"
+)
+
+// Return dispositions
+const (
+    DispositionRestock   = "restock"
+    DispositionWriteOff  = "write_off"
+    DispositionRefurbish = "refurbish"
+)
+
+// Stock ledger entry types
+const (
+    LedgerReturnRestock   = "return_restock"
+    LedgerReturnWriteOff  = "return_write_off"
+    LedgerReturnRefurbish = "return_refurbish"
+)
+
+// ProductReturn is units of one product sent back by a customer
+type ProductReturn struct {
+    ID              int64      `json:"id"`
+    OrderID         *int64     `json:"order_id,omitempty"`
+    ProductID       int64      `json:"product_id"`
+    Quantity        int        `json:"quantity"`
+    Reason          string     `json:"reason"`
+    Status          string     `json:"status"` // received, inspected, refurbishing, restocked, written_off
+    Condition       string     `json:"condition,omitempty"`
+    InspectionNotes string     `json:"inspection_notes,omitempty"`
+    InspectedAt     *time.Time `json:"inspected_at,omitempty"`
+    DispositionNote string     `json:"disposition_note,omitempty"`
+    ReceivedAt      time.Time  `json:"received_at"`
+    UpdatedAt       time.Time  `json:"updated_at"`
+    ClosedAt        *time.Time `json:"closed_at,omitempty"`
+}
+
+// StockLedgerEntry is one stock movement recorded for a return disposition
+type StockLedgerEntry struct {
+    ID         int64     `json:"id"`
+    ProductID  int64     `json:"product_id"`
+    ReturnID   *int64    `json:"return_id,omitempty"`
+    EntryType  string    `json:"entry_type"` // return_restock, return_write_off, return_refurbish
+    Quantity   int       `json:"quantity"`
+    StockDelta int       `json:"stock_delta"` // change to stock_quantity
+    Note       string    `json:"note"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateReturnRequest request body for receiving a return
+type CreateReturnRequest struct {
+    OrderID   *int64 `json:"order_id"`
+    ProductID int64  `json:"product_id" binding:"required"`
+    Quantity  int    `json:"quantity" binding:"required,gt=0"`
+    Reason    string `json:"reason"`
+}
+
+// InspectReturnRequest request body for recording a return's condition
+type InspectReturnRequest struct {
+    Condition string `json:"condition" binding:"required,oneof=new good damaged defective"`
+    Notes     string `json:"notes"`
+}
+
+// DispositionReturnRequest request body for deciding what happens to returned units
+type DispositionReturnRequest struct {
+    Disposition string `json:"disposition" binding:"required,oneof=restock write_off refurbish"`
+    Note        string `json:"note"`
+}
+
+// CanInspect reports whether the return can (still) be inspected
+func (r *ProductReturn) CanInspect() bool {
+    return r.Status == ReturnStatusReceived || r.Status == ReturnStatusInspected
+}
+
+// CanDisposition reports whether the disposition is allowed from the return's status.
+// Why: units are only dispositioned once inspected; refurbished units end up restocked or
+// written off, and a restock or write-off closes the return.
+func (r *ProductReturn) CanDisposition(disposition string) bool {
+    switch r.Status {
+    case ReturnStatusInspected:
+        return disposition == DispositionRestock || disposition == DispositionWriteOff || disposition == DispositionRefurbish
+    case ReturnStatusRefurbishing:
+        return disposition == DispositionRestock || disposition == DispositionWriteOff
+    }
+    return false
+}
+
+// DispositionEffect returns the status a disposition moves a return to, its ledger entry
+// type and the change to stock_quantity
+func DispositionEffect(disposition string, quantity int) (status, entryType string, stockDelta int) {
+    switch disposition {
+    case DispositionRestock:
+        return ReturnStatusRestocked, LedgerReturnRestock, quantity
+    case DispositionWriteOff:
+        return ReturnStatusWrittenOff, LedgerReturnWriteOff, 0
+    default:
+        return ReturnStatusRefurbishing, LedgerReturnRefurbish, 0
+    }
+}
+
+// ReturnRate is returned and sold units of a product over a report period
+type ReturnRate struct {
+    ProductID     int64          `json:"product_id"`
+    SoldUnits     int            `json:"sold_units"`
+    ReturnedUnits int            `json:"returned_units"`
+    ReturnRate    float64        `json:"return_rate"` // returned / sold; 0 when nothing was sold
+    ByStatus      map[string]int `json:"by_status"`   // returned units per return status
+}
+
+// ReturnRateReport is the per-product return rates for a period
+type ReturnRateReport struct {
+    From     time.Time     `json:"from"`
+    To       time.Time     `json:"to"`
+    Products []*ReturnRate `json:"products"`
+}
+
+// NewReturnRateReport computes the rates and sorts products by return rate, highest first
+// (then by returned units, then product ID)
+func NewReturnRateReport(from, to time.Time, rates map[int64]*ReturnRate) *ReturnRateReport {
+    report := &ReturnRateReport{From: from, To: to, Products: []*ReturnRate{}}
+    for _, rate := range rates {
+        if rate.SoldUnits > 0 {
+            rate.ReturnRate = float64(rate.ReturnedUnits) / float64(rate.SoldUnits)
+        }
+        report.Products = append(report.Products, rate)
+    }
+
+    sort.Slice(report.Products, func(i, j int) bool {
+        a, b := report.Products[i], report.Products[j]
+        if a.ReturnRate != b.ReturnRate {
+            return a.ReturnRate > b.ReturnRate
+        }
+        if a.ReturnedUnits != b.ReturnedUnits {
+            return a.ReturnedUnits > b.ReturnedUnits
+        }
+        return a.ProductID < b.ProductID
+    })
+    return report
+}
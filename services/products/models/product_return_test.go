@@ -0,0 +1,60 @@
+package models
+
+import (
+    "testing"
+    "time"
+)
+
+func TestProductReturn_CanDisposition(t *testing.T) {
+    cases := []struct {
+        status      string
+        disposition string
+        want        bool
+    }{
+        {ReturnStatusReceived, DispositionRestock, false}, // not inspected yet
+        {ReturnStatusInspected, DispositionRestock, true},
+        {ReturnStatusInspected, DispositionWriteOff, true},
+        {ReturnStatusInspected, DispositionRefurbish, true},
+        {ReturnStatusRefurbishing, DispositionRestock, true},
+        {ReturnStatusRefurbishing, DispositionRefurbish, false},
+        {ReturnStatusRestocked, DispositionWriteOff, false},
+        {ReturnStatusWrittenOff, DispositionRestock, false},
+    }
+
+    for _, tc := range cases {
+        r := &ProductReturn{Status: tc.status}
+        if got := r.CanDisposition(tc.disposition); got != tc.want {
+            t.Errorf("%s -> %s: got %v, want %v", tc.status, tc.disposition, got, tc.want)
+        }
+    }
+}
+
+func TestDispositionEffect(t *testing.T) {
+    if status, entry, delta := DispositionEffect(DispositionRestock, 3); status != ReturnStatusRestocked || entry != LedgerReturnRestock || delta != 3 {
+        t.Errorf("restock = %s %s %d", status, entry, delta)
+    }
+    if status, entry, delta := DispositionEffect(DispositionWriteOff, 3); status != ReturnStatusWrittenOff || entry != LedgerReturnWriteOff || delta != 0 {
+        t.Errorf("write_off = %s %s %d", status, entry, delta)
+    }
+    if status, entry, delta := DispositionEffect(DispositionRefurbish, 3); status != ReturnStatusRefurbishing || entry != LedgerReturnRefurbish || delta != 0 {
+        t.Errorf("refurbish = %s %s %d", status, entry, delta)
+    }
+}
+
+func TestNewReturnRateReport(t *testing.T) {
+    from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    rates := map[int64]*ReturnRate{
+        1: {ProductID: 1, SoldUnits: 10, ReturnedUnits: 1},
+        2: {ProductID: 2, SoldUnits: 4, ReturnedUnits: 2},
+        3: {ProductID: 3, SoldUnits: 0, ReturnedUnits: 1}, // sold before the period
+    }
+
+    report := NewReturnRateReport(from, from.AddDate(0, 1, 0), rates)
+
+    if len(report.Products) != 3 || report.Products[0].ProductID != 2 || report.Products[1].ProductID != 1 {
+        t.Fatalf("products = %+v, want 2, 1, 3", report.Products)
+    }
+    if report.Products[0].ReturnRate != 0.5 || report.Products[2].ReturnRate != 0 {
+        t.Errorf("rates = %v, %v", report.Products[0].ReturnRate, report.Products[2].ReturnRate)
+    }
+}
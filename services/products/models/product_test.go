@@ -0,0 +1,46 @@
+package models
+
+import "testing"
+
+func TestMergeOrderLines_SumsDuplicatesInProductOrder(t *testing.T) {
+    lines := []OrderLine{
+        {ProductID: 7, Quantity: 1},
+        {ProductID: 3, Quantity: 2},
+        {ProductID: 7, Quantity: 4},
+    }
+
+    merged := MergeOrderLines(lines)
+
+    if len(merged) != 2 {
+        t.Fatalf("merged = %v, want 2 lines", merged)
+    }
+    if merged[0] != (OrderLine{ProductID: 3, Quantity: 2}) || merged[1] != (OrderLine{ProductID: 7, Quantity: 5}) {
+        t.Errorf("merged = %v, want [{3 2} {7 5}]", merged)
+    }
+}
+
+func TestMergeOrderLines_KeepsVariantsApart(t *testing.T) {
+    lines := []OrderLine{
+        {ProductID: 3, VariantID: 12, Quantity: 1},
+        {ProductID: 3, Quantity: 2},
+        {ProductID: 3, VariantID: 11, Quantity: 1},
+        {ProductID: 3, VariantID: 12, Quantity: 3},
+    }
+
+    merged := MergeOrderLines(lines)
+
+    want := []OrderLine{
+        {ProductID: 3, Quantity: 2},
+        {ProductID: 3, VariantID: 11, Quantity: 1},
+        {ProductID: 3, VariantID: 12, Quantity: 4},
+    }
+    if len(merged) != len(want) {
+        t.Fatalf("merged = %v, want %v", merged, want)
+    }
+    for i := range want {
+        if merged[i] != want[i] {
+            t.Errorf("merged = %v, want %v", merged, want)
+            break
+        }
+    }
+}
@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+// Purchase order statuses
+const (
+    POStatusOpen              = "open"
+    POStatusPartiallyReceived = "partially_received"
+    POStatusReceived          = "received"
+    POStatusCancelled         = "cancelled"
+)
+
+// PurchaseOrder is expected inbound stock from a supplier
+type PurchaseOrder struct {
+    ID         int64               `json:"id"`
+    Supplier   string              `json:"supplier"`
+    Reference  string              `json:"reference"` // supplier's PO number
+    Status     string              `json:"status"`    // open, partially_received, received, cancelled
+    ExpectedAt *time.Time          `json:"expected_at,omitempty"`
+    Notes      string              `json:"notes"`
+    Lines      []PurchaseOrderLine `json:"lines"`
+    CreatedAt  time.Time           `json:"created_at"`
+    UpdatedAt  time.Time           `json:"updated_at"`
+    ReceivedAt *time.Time          `json:"received_at,omitempty"`
+}
+
+// PurchaseOrderLine is the expected and received quantity for one product
+type PurchaseOrderLine struct {
+    ID               int64 `json:"id"`
+    PurchaseOrderID  int64 `json:"purchase_order_id"`
+    ProductID        int64 `json:"product_id"`
+    ExpectedQuantity int   `json:"expected_quantity"`
+    ReceivedQuantity int   `json:"received_quantity"`
+    Discrepancy      int   `json:"discrepancy"` // received - expected: negative is short, positive is over
+}
+
+// PurchaseOrderReceipt records one delivery against a purchase order
+type PurchaseOrderReceipt struct {
+    ID              int64     `json:"id"`
+    PurchaseOrderID int64     `json:"purchase_order_id"`
+    ProductID       int64     `json:"product_id"`
+    Quantity        int       `json:"quantity"`
+    Note            string    `json:"note"`
+    ReceivedAt      time.Time `json:"received_at"`
+}
+
+// CreatePurchaseOrderRequest request body for creating a purchase order
+type CreatePurchaseOrderRequest struct {
+    Supplier   string                     `json:"supplier" binding:"required"`
+    Reference  string                     `json:"reference"`
+    ExpectedAt *time.Time                 `json:"expected_at"`
+    Notes      string                     `json:"notes"`
+    Lines      []PurchaseOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// PurchaseOrderLineRequest expected quantity of a product on a purchase order
+type PurchaseOrderLineRequest struct {
+    ProductID        int64 `json:"product_id" binding:"required"`
+    ExpectedQuantity int   `json:"expected_quantity" binding:"required,gt=0"`
+}
+
+// ReceivePurchaseOrderRequest request body for receiving stock against a purchase order
+type ReceivePurchaseOrderRequest struct {
+    Items []ReceiveItemRequest `json:"items" binding:"required,min=1,dive"`
+    Note  string               `json:"note"`
+    Close bool                 `json:"close"` // mark received even if lines are short
+}
+
+// ReceiveItemRequest quantity of a product that arrived
+// Why: products not on the PO are accepted and tracked as over-delivery
+type ReceiveItemRequest struct {
+    ProductID int64 `json:"product_id" binding:"required"`
+    Quantity  int   `json:"quantity" binding:"required,gt=0"`
+}
+
+// NewPurchaseOrder creates new open purchase order
+func NewPurchaseOrder(supplier, reference string, expectedAt *time.Time, notes string) *PurchaseOrder {
+    now := time.Now().UTC()
+    return &PurchaseOrder{
+        Supplier:   supplier,
+        Reference:  reference,
+        Status:     POStatusOpen,
+        ExpectedAt: expectedAt,
+        Notes:      notes,
+        Lines:      []PurchaseOrderLine{},
+        CreatedAt:  now,
+        UpdatedAt:  now,
+    }
+}
+
+// Discrepancies returns the lines whose received quantity differs from expected
+func (po *PurchaseOrder) Discrepancies() []PurchaseOrderLine {
+    var lines []PurchaseOrderLine
+    for _, line := range po.Lines {
+        if line.Discrepancy != 0 {
+            lines = append(lines, line)
+        }
+    }
+    return lines
+}
+
+// FullyReceived reports whether every line received at least its expected quantity
+func (po *PurchaseOrder) FullyReceived() bool {
+    for _, line := range po.Lines {
+        if line.ReceivedQuantity < line.ExpectedQuantity {
+            return false
+        }
+    }
+    return true
+}
+
+// ReceivingStatus returns the status after a receipt
+func (po *PurchaseOrder) ReceivingStatus(close bool) string {
+    if close || po.FullyReceived() {
+        return POStatusReceived
+    }
+    return POStatusPartiallyReceived
+}
+
+// ReceivedStock is the stock level of a product after a purchase order receipt
+type ReceivedStock struct {
+    ProductID     int64 `json:"product_id"`
+    Quantity      int   `json:"quantity"`       // units received in this delivery
+    StockQuantity int   `json:"stock_quantity"` // stock after the delivery
+}
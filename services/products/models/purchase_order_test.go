@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestPurchaseOrder_ReceivingStatus(t *testing.T) {
+    po := &PurchaseOrder{Lines: []PurchaseOrderLine{
+        {ProductID: 1, ExpectedQuantity: 10, ReceivedQuantity: 10},
+        {ProductID: 2, ExpectedQuantity: 5, ReceivedQuantity: 3, Discrepancy: -2},
+    }}
+
+    if got := po.ReceivingStatus(false); got != POStatusPartiallyReceived {
+        t.Fatalf("expected %s, got %s", POStatusPartiallyReceived, got)
+    }
+    if got := po.ReceivingStatus(true); got != POStatusReceived {
+        t.Fatalf("expected close to force %s, got %s", POStatusReceived, got)
+    }
+
+    discrepancies := po.Discrepancies()
+    if len(discrepancies) != 1 || discrepancies[0].ProductID != 2 {
+        t.Fatalf("expected short line for product 2, got %+v", discrepancies)
+    }
+
+    // Over-delivery of an unexpected product still completes the PO
+    po.Lines[1].ReceivedQuantity = 5
+    po.Lines[1].Discrepancy = 0
+    po.Lines = append(po.Lines, PurchaseOrderLine{ProductID: 3, ReceivedQuantity: 4, Discrepancy: 4})
+
+    if got := po.ReceivingStatus(false); got != POStatusReceived {
+        t.Fatalf("expected %s, got %s", POStatusReceived, got)
+    }
+    if got := po.Discrepancies(); len(got) != 1 || got[0].Discrepancy != 4 {
+        t.Fatalf("expected over-delivery discrepancy, got %+v", got)
+    }
+}
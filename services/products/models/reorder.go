@@ -0,0 +1,118 @@
+package models
+
+import (
+    "math"
+    "sort"
+    "time"
+)
+
+// ReorderPolicy is how far ahead stock is planned, in days of sales
+type ReorderPolicy struct {
+    LeadTimeDays    int `json:"lead_time_days"`    // supplier lead time
+    SafetyStockDays int `json:"safety_stock_days"` // buffer on top of the lead time
+    CoverDays       int `json:"cover_days"`        // sales a suggested order covers once it arrives
+}
+
+// StockForecast is a product's sales and stock position as the forecast worker last computed them
+type StockForecast struct {
+    ProductID   int64
+    ProductName string
+    SoldUnits   int // committed over the last WindowDays
+    WindowDays  int
+    Available   int // stock minus active reservations
+    OnOrder     int // units on open purchase orders not received yet
+    ComputedAt  time.Time
+    SuggestedAt *time.Time // ReorderSuggested was published for the current dip
+}
+
+// DailyVelocity is units sold per day over the window
+func (sf *StockForecast) DailyVelocity() float64 {
+    if sf.WindowDays <= 0 {
+        return 0
+    }
+    return float64(sf.SoldUnits) / float64(sf.WindowDays)
+}
+
+// unitsFor is the sales expected over days, rounded up
+// Why: integer arithmetic, so 10 sold in 28 days doesn't round 7 days of sales to 3.0000001
+func (sf *StockForecast) unitsFor(days int) int {
+    if sf.WindowDays <= 0 {
+        return 0
+    }
+    return (sf.SoldUnits*days + sf.WindowDays - 1) / sf.WindowDays
+}
+
+// ReorderSuggestion is a product to reorder and how much
+type ReorderSuggestion struct {
+    ProductID         int64   `json:"product_id"`
+    ProductName       string  `json:"product_name"`
+    DailyVelocity     float64 `json:"daily_velocity"`
+    Available         int     `json:"available"`
+    OnOrder           int     `json:"on_order"`
+    DaysOfStock       float64 `json:"days_of_stock"` // available / daily_velocity
+    ReorderPoint      int     `json:"reorder_point"` // sales over lead time + safety stock
+    SuggestedQuantity int     `json:"suggested_quantity"`
+}
+
+// Suggest returns what to reorder for forecast. A product needs reordering when it sells and
+// its available plus on-order units don't exceed the reorder point; the suggestion tops it up
+// to the reorder point plus CoverDays of sales.
+func (rp ReorderPolicy) Suggest(forecast *StockForecast) (*ReorderSuggestion, bool) {
+    velocity := forecast.DailyVelocity()
+    if velocity <= 0 {
+        return nil, false
+    }
+
+    reorderPoint := forecast.unitsFor(rp.LeadTimeDays + rp.SafetyStockDays)
+    position := forecast.Available + forecast.OnOrder
+    if position > reorderPoint {
+        return nil, false
+    }
+
+    quantity := forecast.unitsFor(rp.LeadTimeDays+rp.SafetyStockDays+rp.CoverDays) - position
+    if quantity <= 0 {
+        return nil, false
+    }
+
+    return &ReorderSuggestion{
+        ProductID:         forecast.ProductID,
+        ProductName:       forecast.ProductName,
+        DailyVelocity:     math.Round(velocity*1000) / 1000,
+        Available:         forecast.Available,
+        OnOrder:           forecast.OnOrder,
+        DaysOfStock:       math.Round(float64(forecast.Available)/velocity*10) / 10,
+        ReorderPoint:      reorderPoint,
+        SuggestedQuantity: quantity,
+    }, true
+}
+
+// ReorderReport is the products to reorder under a policy
+type ReorderReport struct {
+    ReorderPolicy
+    WindowDays  int                  `json:"window_days"`
+    ComputedAt  *time.Time           `json:"computed_at"` // latest forecast run; nil before the first
+    Suggestions []*ReorderSuggestion `json:"suggestions"`
+}
+
+// NewReorderReport suggests reorders for forecasts, the products closest to running out first
+func NewReorderReport(policy ReorderPolicy, windowDays int, forecasts []*StockForecast) *ReorderReport {
+    report := &ReorderReport{ReorderPolicy: policy, WindowDays: windowDays, Suggestions: []*ReorderSuggestion{}}
+    for _, forecast := range forecasts {
+        if report.ComputedAt == nil || forecast.ComputedAt.After(*report.ComputedAt) {
+            computedAt := forecast.ComputedAt
+            report.ComputedAt = &computedAt
+        }
+        if suggestion, ok := policy.Suggest(forecast); ok {
+            report.Suggestions = append(report.Suggestions, suggestion)
+        }
+    }
+
+    sort.Slice(report.Suggestions, func(i, j int) bool {
+        a, b := report.Suggestions[i], report.Suggestions[j]
+        if a.DaysOfStock != b.DaysOfStock {
+            return a.DaysOfStock < b.DaysOfStock
+        }
+        return a.ProductID < b.ProductID
+    })
+    return report
+}
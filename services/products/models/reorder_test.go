@@ -0,0 +1,55 @@
+package models
+
+import (
+    "testing"
+    "time"
+)
+
+func TestReorderPolicy_Suggest(t *testing.T) {
+    policy := ReorderPolicy{LeadTimeDays: 7, SafetyStockDays: 3, CoverDays: 30}
+
+    // 1 unit a day: reorder point 10, topped up to 40 days of sales
+    suggestion, ok := policy.Suggest(&StockForecast{ProductID: 1, SoldUnits: 28, WindowDays: 28, Available: 8})
+    if !ok || suggestion.ReorderPoint != 10 || suggestion.SuggestedQuantity != 32 || suggestion.DaysOfStock != 8 {
+        t.Fatalf("expected reorder point 10 and 32 units, got %+v (%v)", suggestion, ok)
+    }
+
+    // Partial units round up: 10 sold in 28 days is 3.6 units over 10 days
+    suggestion, ok = policy.Suggest(&StockForecast{ProductID: 2, SoldUnits: 10, WindowDays: 28, Available: 2, OnOrder: 1})
+    if !ok || suggestion.ReorderPoint != 4 || suggestion.SuggestedQuantity != 12 || suggestion.DaysOfStock != 5.6 {
+        t.Fatalf("expected reorder point 4 and 12 units, got %+v (%v)", suggestion, ok)
+    }
+
+    for name, forecast := range map[string]*StockForecast{
+        "above reorder point": {SoldUnits: 28, WindowDays: 28, Available: 30},
+        "covered by open PO":  {SoldUnits: 28, WindowDays: 28, Available: 5, OnOrder: 20},
+        "not selling":         {SoldUnits: 0, WindowDays: 28, Available: 0},
+    } {
+        if suggestion, ok := policy.Suggest(forecast); ok {
+            t.Errorf("%s: expected no suggestion, got %+v", name, suggestion)
+        }
+    }
+
+    // At the reorder point with no cover days there's nothing to top up
+    noCover := ReorderPolicy{LeadTimeDays: 7, SafetyStockDays: 3}
+    if suggestion, ok := noCover.Suggest(&StockForecast{SoldUnits: 28, WindowDays: 28, Available: 10}); ok {
+        t.Errorf("expected no suggestion without cover days, got %+v", suggestion)
+    }
+}
+
+func TestNewReorderReport_SortsByDaysOfStock(t *testing.T) {
+    earlier := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+    later := earlier.Add(time.Hour)
+    report := NewReorderReport(ReorderPolicy{LeadTimeDays: 7, SafetyStockDays: 3, CoverDays: 30}, 28, []*StockForecast{
+        {ProductID: 1, SoldUnits: 28, WindowDays: 28, Available: 8, ComputedAt: earlier},
+        {ProductID: 2, SoldUnits: 56, WindowDays: 28, Available: 4, ComputedAt: later},
+        {ProductID: 3, SoldUnits: 28, WindowDays: 28, Available: 100, ComputedAt: earlier},
+    })
+
+    if len(report.Suggestions) != 2 || report.Suggestions[0].ProductID != 2 || report.Suggestions[1].ProductID != 1 {
+        t.Fatalf("expected products 2 then 1, got %+v", report.Suggestions)
+    }
+    if report.ComputedAt == nil || !report.ComputedAt.Equal(later) {
+        t.Fatalf("expected computed_at %v, got %v", later, report.ComputedAt)
+    }
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+// Review moderation statuses
+const (
+    ReviewStatusPending  = "pending"
+    ReviewStatusApproved = "approved"
+    ReviewStatusRejected = "rejected"
+)
+
+// Review paging defaults
+const (
+    DefaultReviewPageLimit = 20
+    MaxReviewPageLimit     = 100
+)
+
+// Review is a customer's rating of a product
+type Review struct {
+    ID             int64      `json:"id"`
+    ProductID      int64      `json:"product_id"`
+    UserID         string     `json:"user_id"`
+    Rating         int        `json:"rating"` // 1-5
+    Text           string     `json:"text"`
+    Status         string     `json:"status"` // pending, approved, rejected
+    ModerationNote *string    `json:"moderation_note,omitempty"`
+    CreatedAt      time.Time  `json:"created_at"`
+    UpdatedAt      time.Time  `json:"updated_at"`
+    ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+}
+
+// RatingSummary is the aggregate of a product's approved reviews
+type RatingSummary struct {
+    AverageRating float64 `json:"average_rating"`
+    ReviewCount   int     `json:"review_count"`
+}
+
+// CreateReviewRequest request body for reviewing a product; the reviewer is the caller's token
+type CreateReviewRequest struct {
+    Rating int    `json:"rating" binding:"required,min=1,max=5"`
+    Text   string `json:"text" binding:"max=5000"`
+}
+
+// ModerateReviewRequest request body for approving or rejecting a review
+type ModerateReviewRequest struct {
+    Status string `json:"status" binding:"required,oneof=approved rejected"`
+    Note   string `json:"note"`
+}
+
+// ReviewPage is one page of a product's reviews with its rating summary
+type ReviewPage struct {
+    Reviews []*Review `json:"reviews"`
+    Count   int       `json:"count"` // reviews on this page
+    Total   int       `json:"total"` // reviews matching the status
+    Page    int       `json:"page"`
+    Limit   int       `json:"limit"`
+    RatingSummary
+}
+
+// ReviewConnection is one keyset page of a product's reviews with its rating summary
+type ReviewConnection struct {
+    pagination.Connection[*Review]
+    TotalCount int `json:"total_count"` // reviews matching the status
+    RatingSummary
+}
+
+// ReviewCursor is the review's position in the newest-first listing
+func ReviewCursor(review *Review) pagination.Cursor {
+    return pagination.Cursor{Key: pagination.TimeKey(review.CreatedAt), ID: review.ID}
+}
+
+// NewReview creates new review awaiting moderation
+func NewReview(productID int64, userID string, rating int, text string) *Review {
+    now := time.Now().UTC()
+    return &Review{
+        ProductID: productID,
+        UserID:    userID,
+        Rating:    rating,
+        Text:      text,
+        Status:    ReviewStatusPending,
+        CreatedAt: now,
+        UpdatedAt: now,
+    }
+}
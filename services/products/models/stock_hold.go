@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// DefaultStockHoldTTL is how long an admin stock hold lasts when the request doesn't say
+const DefaultStockHoldTTL = 24 * time.Hour
+
+// MaxStockHoldTTL caps an admin stock hold, whatever the request asks for
+const MaxStockHoldTTL = 7 * 24 * time.Hour
+
+// StockHoldRequest request body for holding or releasing a product's stock by hand
+type StockHoldRequest struct {
+    ProductID  int64 `json:"product_id" binding:"required"`
+    Quantity   int   `json:"quantity" binding:"required,gt=0"`
+    TTLSeconds int   `json:"ttl_seconds" binding:"omitempty,gt=0"`
+}
+
+// TTL is the requested hold, DefaultStockHoldTTL when unset and capped at MaxStockHoldTTL
+func (r *StockHoldRequest) TTL() time.Duration {
+    if r.TTLSeconds == 0 {
+        return DefaultStockHoldTTL
+    }
+    ttl := time.Duration(r.TTLSeconds) * time.Second
+    if ttl > MaxStockHoldTTL {
+        return MaxStockHoldTTL
+    }
+    return ttl
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DefaultMaxStockSubscribers caps the waiting subscribers of one product
+const DefaultMaxStockSubscribers = 1000
+
+// StockSubscription is a user waiting for an out-of-stock product
+type StockSubscription struct {
+    ID         int64      `json:"id"`
+    ProductID  int64      `json:"product_id"`
+    UserID     string     `json:"user_id"`
+    CreatedAt  time.Time  `json:"created_at"`
+    NotifiedAt *time.Time `json:"notified_at,omitempty"`
+}
+
+// NotifyMeRequest request body for subscribing to a product's restock
+type NotifyMeRequest struct {
+    UserID string `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// ProductVariant is a sellable variant of a product (e.g. size M, color red) with its own
+// SKU and stock
+type ProductVariant struct {
+    ID            int64             `json:"id"`
+    ProductID     int64             `json:"product_id"`
+    SKU           string            `json:"sku"`
+    Attributes    map[string]string `json:"attributes"`     // e.g. {"size": "M", "color": "red"}
+    PriceOverride *float64          `json:"price_override"` // nil sells at the product's price
+    Price         float64           `json:"price"`          // price_override or the product's price
+    StockQuantity int               `json:"stock_quantity"`
+    CreatedAt     time.Time         `json:"created_at"`
+    UpdatedAt     time.Time         `json:"updated_at"`
+    DeletedAt     *time.Time        `json:"deleted_at,omitempty"`
+}
+
+// CreateVariantRequest request body for adding a variant to a product
+type CreateVariantRequest struct {
+    SKU           string            `json:"sku" binding:"required"`
+    Attributes    map[string]string `json:"attributes" binding:"required,min=1"`
+    PriceOverride *float64          `json:"price_override" binding:"omitempty,gt=0"`
+    Stock         int               `json:"stock" binding:"gte=0"`
+}
+
+// NewProductVariant creates new product variant
+func NewProductVariant(productID int64, sku string, attributes map[string]string, priceOverride *float64, stock int) *ProductVariant {
+    now := time.Now().UTC()
+    return &ProductVariant{
+        ProductID:     productID,
+        SKU:           sku,
+        Attributes:    attributes,
+        PriceOverride: priceOverride,
+        StockQuantity: stock,
+        CreatedAt:     now,
+        UpdatedAt:     now,
+    }
+}
+
+// EffectivePrice returns the price the variant sells at: its override, or the product's price
+func (v *ProductVariant) EffectivePrice(productPrice float64) float64 {
+    if v.PriceOverride != nil {
+        return *v.PriceOverride
+    }
+    return productPrice
+}
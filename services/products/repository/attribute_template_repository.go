@@ -0,0 +1,215 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrAttributeTemplateNotFound is returned when no attribute template has the given ID
+    ErrAttributeTemplateNotFound = errors.New("attribute template not found")
+    // ErrDuplicateAttributeTemplate is returned when the category already has a template with the name
+    ErrDuplicateAttributeTemplate = errors.New("attribute template already exists")
+)
+
+// maxCategoryDepth bounds the walk up the category tree in case parent_id ever forms a cycle
+const maxCategoryDepth = 32
+
+const attributeTemplateColumns = `t.id, t.category_id, t.name, t.label, t.type, t.unit, t.options, t.required, t.created_at, t.updated_at`
+
+// AttributeTemplateRepository handles category attribute template database operations
+type AttributeTemplateRepository struct {
+    conn *db.Connection
+}
+
+// NewAttributeTemplateRepository creates new attribute template repository
+func NewAttributeTemplateRepository(conn *db.Connection) *AttributeTemplateRepository {
+    return &AttributeTemplateRepository{conn: conn}
+}
+
+// CreateTemplate inserts a template; names are unique within a category
+func (ar *AttributeTemplateRepository) CreateTemplate(ctx context.Context, template *models.AttributeTemplate) error {
+    options, err := json.Marshal(template.Options)
+    if err != nil {
+        return fmt.Errorf("failed to encode attribute options: %w", err)
+    }
+
+    query := `
+        INSERT INTO $schema.attribute_templates (category_id, name, label, type, unit, options, required, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (category_id, name) DO NOTHING
+        RETURNING id
+    `
+
+    query = ar.conn.Qualify(query)
+
+    err = ar.conn.QueryRowContext(ctx, query,
+        template.CategoryID,
+        template.Name,
+        template.Label,
+        template.Type,
+        template.Unit,
+        options,
+        template.Required,
+        template.CreatedAt,
+        template.UpdatedAt,
+    ).Scan(&template.ID)
+
+    if err == sql.ErrNoRows {
+        return ErrDuplicateAttributeTemplate
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create attribute template: %w", err)
+    }
+
+    return nil
+}
+
+// GetTemplate retrieves an attribute template by ID
+func (ar *AttributeTemplateRepository) GetTemplate(ctx context.Context, id int64) (*models.AttributeTemplate, error) {
+    query := `
+        SELECT ` + attributeTemplateColumns + `
+        FROM $schema.attribute_templates t
+        WHERE t.id = $1
+    `
+
+    query = ar.conn.Qualify(query)
+
+    template, err := scanAttributeTemplate(ar.conn.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrAttributeTemplateNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get attribute template: %w", err)
+    }
+
+    return template, nil
+}
+
+// GetTemplatesForCategory retrieves the templates that apply to a category: its own and those
+// of its ancestors, sorted by name. A subcategory's template overrides an ancestor's of the same name.
+func (ar *AttributeTemplateRepository) GetTemplatesForCategory(ctx context.Context, categoryID int64) ([]*models.AttributeTemplate, error) {
+    query := `
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id, 0 AS depth FROM $schema.categories WHERE id = $1 AND deleted_at IS NULL
+            UNION
+            SELECT c.id, c.parent_id, a.depth + 1 FROM $schema.categories c
+            JOIN ancestors a ON c.id = a.parent_id
+            WHERE c.deleted_at IS NULL AND a.depth < $2
+        )
+        SELECT DISTINCT ON (t.name) ` + attributeTemplateColumns + `
+        FROM $schema.attribute_templates t
+        JOIN ancestors a ON a.id = t.category_id
+        ORDER BY t.name, a.depth
+    `
+
+    query = ar.conn.Qualify(query)
+
+    rows, err := ar.conn.QueryContext(ctx, query, categoryID, maxCategoryDepth)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get attribute templates: %w", err)
+    }
+    defer rows.Close()
+
+    templates := []*models.AttributeTemplate{}
+    for rows.Next() {
+        template, err := scanAttributeTemplate(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan attribute template: %w", err)
+        }
+        templates = append(templates, template)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read attribute templates: %w", err)
+    }
+
+    return templates, nil
+}
+
+// UpdateTemplate saves a template's label, unit, options and required flag
+func (ar *AttributeTemplateRepository) UpdateTemplate(ctx context.Context, template *models.AttributeTemplate) error {
+    options, err := json.Marshal(template.Options)
+    if err != nil {
+        return fmt.Errorf("failed to encode attribute options: %w", err)
+    }
+
+    query := `
+        UPDATE $schema.attribute_templates
+        SET label = $1, unit = $2, options = $3, required = $4, updated_at = $5
+        WHERE id = $6
+        RETURNING updated_at
+    `
+
+    query = ar.conn.Qualify(query)
+
+    err = ar.conn.QueryRowContext(ctx, query,
+        template.Label,
+        template.Unit,
+        options,
+        template.Required,
+        time.Now().UTC(),
+        template.ID,
+    ).Scan(&template.UpdatedAt)
+
+    if err == sql.ErrNoRows {
+        return ErrAttributeTemplateNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to update attribute template: %w", err)
+    }
+
+    return nil
+}
+
+// DeleteTemplate removes a template; products keep their values as free-form attributes
+func (ar *AttributeTemplateRepository) DeleteTemplate(ctx context.Context, id int64) error {
+    query := `DELETE FROM $schema.attribute_templates WHERE id = $1`
+
+    query = ar.conn.Qualify(query)
+
+    result, err := ar.conn.ExecContext(ctx, query, id)
+    if err != nil {
+        return fmt.Errorf("failed to delete attribute template: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rowsAffected == 0 {
+        return ErrAttributeTemplateNotFound
+    }
+
+    return nil
+}
+
+func scanAttributeTemplate(row interface{ Scan(...interface{}) error }) (*models.AttributeTemplate, error) {
+    template := &models.AttributeTemplate{}
+    var options []byte
+    err := row.Scan(
+        &template.ID,
+        &template.CategoryID,
+        &template.Name,
+        &template.Label,
+        &template.Type,
+        &template.Unit,
+        &options,
+        &template.Required,
+        &template.CreatedAt,
+        &template.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(options, &template.Options); err != nil {
+        return nil, fmt.Errorf("failed to decode attribute options: %w", err)
+    }
+    return template, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// LockForCart soft-locks quantity units of a product (or of one of its variants) for a cart
+// line, replacing the cart's earlier lock on that line, so the customer has a grace window
+// between add-to-cart and checkout. Stock is checked under the product or variant row lock
+// like ReserveForOrder, without counting the lock being replaced.
+// The lock is an ordinary reservation with cart_id set: it counts against available stock and
+// the reservation expiry worker expires it after ttl.
+func (ir *InventoryReservationRepository) LockForCart(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var stock int
+    if variantID != nil {
+        lockVariant := ir.conn.Qualify(`
+            SELECT v.stock_quantity
+            FROM $schema.product_variants v
+            JOIN $schema.products p ON p.id = v.product_id
+            WHERE v.id = $1 AND v.product_id = $2 AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+            FOR UPDATE OF v
+        `)
+        err = tx.QueryRowContext(ctx, lockVariant, *variantID, productID).Scan(&stock)
+    } else {
+        lockProduct := ir.conn.Qualify(`SELECT stock_quantity FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+        err = tx.QueryRowContext(ctx, lockProduct, productID).Scan(&stock)
+    }
+    if err == sql.ErrNoRows {
+        if variantID != nil {
+            return nil, fmt.Errorf("%w: %d variant %d", ErrUnknownProduct, productID, *variantID)
+        }
+        return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, productID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    now := ir.clock.Now()
+    replaceQuery := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'released', released_at = $1, updated_at = $1
+        WHERE cart_id = $2 AND product_id = $3 AND variant_id IS NOT DISTINCT FROM $4 AND status = 'reserved'
+    `)
+    if _, err := tx.ExecContext(ctx, replaceQuery, now, cartID, productID, variantID); err != nil {
+        return nil, fmt.Errorf("failed to release previous cart lock: %w", err)
+    }
+
+    var reserved int
+    if variantID != nil {
+        reservedQuery := ir.conn.Qualify(`
+            SELECT COALESCE(SUM(quantity), 0)
+            FROM $schema.inventory_reservations
+            WHERE variant_id = $1 AND status = 'reserved'
+        `)
+        err = tx.QueryRowContext(ctx, reservedQuery, *variantID).Scan(&reserved)
+    } else {
+        reservedQuery := ir.conn.Qualify(`
+            SELECT COALESCE(SUM(quantity), 0)
+            FROM $schema.inventory_reservations
+            WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved'
+        `)
+        err = tx.QueryRowContext(ctx, reservedQuery, productID).Scan(&reserved)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get product reservations: %w", err)
+    }
+    if available := stock - reserved; available < quantity {
+        shortage := &StockShortageError{ProductID: productID, Requested: quantity, Available: available}
+        if variantID != nil {
+            shortage.VariantID = *variantID
+        }
+        return nil, shortage
+    }
+
+    insertQuery := ir.conn.Qualify(`
+        INSERT INTO $schema.inventory_reservations
+        (product_id, variant_id, quantity, order_id, reservation_id, status, created_at, expires_at, cart_id, updated_at)
+        VALUES ($1, $2, $3, 0, $4, 'reserved', $5, $6, $7, $5)
+        RETURNING ` + orderReservationColumns)
+
+    reservation, err := scanOrderReservation(tx.QueryRowContext(ctx, insertQuery,
+        productID,
+        variantID,
+        quantity,
+        uuid.New().String(),
+        now,
+        now.Add(ttl),
+        cartID,
+    ))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create cart lock: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit cart lock: %w", err)
+    }
+
+    return reservation, nil
+}
+
+// ReleaseCartLock gives back one of the cart's soft locks. Releasing a lock that was already
+// released or expired is a no-op.
+func (ir *InventoryReservationRepository) ReleaseCartLock(ctx context.Context, cartID, reservationID string) error {
+    query := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'released', released_at = $1, updated_at = $1
+        WHERE cart_id = $2 AND reservation_id::text = $3 AND status = 'reserved'
+    `)
+
+    if _, err := ir.conn.ExecContext(ctx, query, ir.clock.Now(), cartID, reservationID); err != nil {
+        return fmt.Errorf("failed to release cart lock: %w", err)
+    }
+    return nil
+}
+
+// ReleaseCartLocks gives back every soft lock of the cart and returns how many were held
+func (ir *InventoryReservationRepository) ReleaseCartLocks(ctx context.Context, cartID string) (int64, error) {
+    query := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'released', released_at = $1, updated_at = $1
+        WHERE cart_id = $2 AND status = 'reserved'
+    `)
+
+    result, err := ir.conn.ExecContext(ctx, query, ir.clock.Now(), cartID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to release cart locks: %w", err)
+    }
+    return result.RowsAffected()
+}
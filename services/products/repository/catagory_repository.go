@@ -2,14 +2,21 @@ package repository
 
 import (
     "context"
+    "database/sql"
+    "errors"
     "fmt"
     "log"
     "time"
 
+    "github.com/lib/pq"
     "github.com/sanketh-sg/prost/services/products/models"
     "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/pagination"
 )
 
+// ErrCategoryNotFound is returned when a merge or assignment names a missing or deleted category
+var ErrCategoryNotFound = errors.New("category not found")
+
 // CategoryRepository handles category database operations
 type CategoryRepository struct {
     conn *db.Connection
@@ -23,19 +30,20 @@ func NewCategoryRepository(conn *db.Connection) *CategoryRepository {
 // CreateCategory creates a new category
 func (cr *CategoryRepository) CreateCategory(ctx context.Context, category *models.Category) error {
     query := `
-        INSERT INTO $schema.categories (name, description, created_at, updated_at)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, name, description, created_at, updated_at
+        INSERT INTO $schema.categories (name, description, parent_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, name, description, parent_id, created_at, updated_at
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     err := cr.conn.QueryRowContext(ctx, query,
         category.Name,
         category.Description,
+        category.ParentID,
         category.CreatedAt,
         category.UpdatedAt,
-    ).Scan(&category.ID, &category.Name, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+    ).Scan(&category.ID, &category.Name, &category.Description, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
 
     if err != nil {
         log.Printf("Error creating category: %v", err)
@@ -48,18 +56,19 @@ func (cr *CategoryRepository) CreateCategory(ctx context.Context, category *mode
 // GetCategory retrieves a category by ID
 func (cr *CategoryRepository) GetCategory(ctx context.Context, id int64) (*models.Category, error) {
     query := `
-        SELECT id, name, description, created_at, updated_at, deleted_at
+        SELECT id, name, description, parent_id, created_at, updated_at, deleted_at
         FROM $schema.categories
         WHERE id = $1 AND deleted_at IS NULL
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     category := &models.Category{}
     err := cr.conn.QueryRowContext(ctx, query, id).Scan(
         &category.ID,
         &category.Name,
         &category.Description,
+        &category.ParentID,
         &category.CreatedAt,
         &category.UpdatedAt,
         &category.DeletedAt,
@@ -75,18 +84,47 @@ func (cr *CategoryRepository) GetCategory(ctx context.Context, id int64) (*model
 // GetAllCategories retrieves all categories
 func (cr *CategoryRepository) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
     query := `
-        SELECT id, name, description, created_at, updated_at, deleted_at
+        SELECT id, name, description, parent_id, created_at, updated_at, deleted_at
         FROM $schema.categories
         WHERE deleted_at IS NULL
         ORDER BY created_at DESC
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     rows, err := cr.conn.QueryContext(ctx, query)
     if err != nil {
         return nil, fmt.Errorf("failed to get categories: %w", err)
     }
+
+    return scanCategories(rows)
+}
+
+// GetCategoriesPage retrieves one keyset page of the categories, newest first like
+// GetAllCategories. It returns up to page.Limit() categories.
+func (cr *CategoryRepository) GetCategoriesPage(ctx context.Context, page pagination.Page) ([]*models.Category, error) {
+    after, args := page.Condition("created_at", "id", true, 1)
+    args = append(args, page.Limit())
+
+    query := `
+        SELECT id, name, description, parent_id, created_at, updated_at, deleted_at
+        FROM $schema.categories
+        WHERE deleted_at IS NULL AND ` + after + `
+        ORDER BY created_at DESC, id DESC
+        ` + fmt.Sprintf("LIMIT $%d", len(args))
+
+    query = cr.conn.Qualify(query)
+
+    rows, err := cr.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get categories page: %w", err)
+    }
+
+    return scanCategories(rows)
+}
+
+// scanCategories reads and closes rows of category columns
+func scanCategories(rows *sql.Rows) ([]*models.Category, error) {
     defer rows.Close()
 
     var categories []*models.Category
@@ -96,6 +134,7 @@ func (cr *CategoryRepository) GetAllCategories(ctx context.Context) ([]*models.C
             &category.ID,
             &category.Name,
             &category.Description,
+            &category.ParentID,
             &category.CreatedAt,
             &category.UpdatedAt,
             &category.DeletedAt,
@@ -106,7 +145,53 @@ func (cr *CategoryRepository) GetAllCategories(ctx context.Context) ([]*models.C
         categories = append(categories, category)
     }
 
-    return categories, nil
+    return categories, rows.Err()
+}
+
+// GetCategoryTree retrieves all categories nested under their parents, with the number of
+// products in each category and in each category and its subcategories
+func (cr *CategoryRepository) GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error) {
+    categories, err := cr.GetAllCategories(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    counts, err := cr.GetProductCounts(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return models.BuildCategoryTree(categories, counts), nil
+}
+
+// GetProductCounts returns the number of products directly in each category
+func (cr *CategoryRepository) GetProductCounts(ctx context.Context) (map[int64]int, error) {
+    query := `
+        SELECT category_id, COUNT(*)
+        FROM $schema.products
+        WHERE deleted_at IS NULL AND category_id IS NOT NULL
+        GROUP BY category_id
+    `
+
+    query = cr.conn.Qualify(query)
+
+    rows, err := cr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to count category products: %w", err)
+    }
+    defer rows.Close()
+
+    counts := make(map[int64]int)
+    for rows.Next() {
+        var categoryID int64
+        var count int
+        if err := rows.Scan(&categoryID, &count); err != nil {
+            return nil, fmt.Errorf("failed to scan category product count: %w", err)
+        }
+        counts[categoryID] = count
+    }
+
+    return counts, rows.Err()
 }
 
 // UpdateCategory updates a category
@@ -115,17 +200,17 @@ func (cr *CategoryRepository) UpdateCategory(ctx context.Context, category *mode
         UPDATE $schema.categories
         SET name = $1, description = $2, updated_at = $3
         WHERE id = $4 AND deleted_at IS NULL
-        RETURNING id, name, description, created_at, updated_at
+        RETURNING id, name, description, parent_id, created_at, updated_at
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     err := cr.conn.QueryRowContext(ctx, query,
         category.Name,
         category.Description,
         time.Now().UTC(),
         category.ID,
-    ).Scan(&category.ID, &category.Name, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+    ).Scan(&category.ID, &category.Name, &category.Description, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
 
     if err != nil {
         return fmt.Errorf("failed to update category: %w", err)
@@ -142,7 +227,7 @@ func (cr *CategoryRepository) DeleteCategory(ctx context.Context, id int64) erro
         WHERE id = $2
     `
 
-    query = replaceSchema(query, cr.conn.Schema)
+    query = cr.conn.Qualify(query)
 
     result, err := cr.conn.ExecContext(ctx, query, time.Now().UTC(), id)
     if err != nil {
@@ -159,4 +244,142 @@ func (cr *CategoryRepository) DeleteCategory(ctx context.Context, id int64) erro
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+// MergeCategory moves everything in category sourceID to targetID in one transaction: its
+// products (deleted ones too, so a restore doesn't land in a deleted category), its
+// subcategories and the named templates, then soft deletes the source. It returns the moved
+// live products and the re-parented subcategories.
+func (cr *CategoryRepository) MergeCategory(ctx context.Context, sourceID, targetID int64, templates []string) (productIDs, subcategoryIDs []int64, err error) {
+    tx, err := cr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Locks both categories so a concurrent merge or delete can't interleave
+    lockQuery := cr.conn.Qualify(`SELECT id FROM $schema.categories WHERE id IN ($1, $2) AND deleted_at IS NULL FOR UPDATE`)
+    rows, err := tx.QueryContext(ctx, lockQuery, sourceID, targetID)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to lock categories: %w", err)
+    }
+    locked := 0
+    for rows.Next() {
+        locked++
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, nil, fmt.Errorf("failed to lock categories: %w", err)
+    }
+    if locked != 2 {
+        return nil, nil, ErrCategoryNotFound
+    }
+
+    now := time.Now().UTC()
+
+    productQuery := `
+        UPDATE $schema.products
+        SET category_id = $2, updated_at = $3
+        WHERE category_id = $1
+        RETURNING id, deleted_at IS NULL
+    `
+    rows, err = tx.QueryContext(ctx, cr.conn.Qualify(productQuery), sourceID, targetID, now)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to move products: %w", err)
+    }
+    productIDs = []int64{}
+    for rows.Next() {
+        var id int64
+        var live bool
+        if err := rows.Scan(&id, &live); err != nil {
+            rows.Close()
+            return nil, nil, fmt.Errorf("failed to scan moved product: %w", err)
+        }
+        if live {
+            productIDs = append(productIDs, id)
+        }
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, nil, fmt.Errorf("failed to move products: %w", err)
+    }
+
+    subcategoryQuery := `
+        UPDATE $schema.categories
+        SET parent_id = $2, updated_at = $3
+        WHERE parent_id = $1 AND deleted_at IS NULL
+        RETURNING id
+    `
+    rows, err = tx.QueryContext(ctx, cr.conn.Qualify(subcategoryQuery), sourceID, targetID, now)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to move subcategories: %w", err)
+    }
+    subcategoryIDs = []int64{}
+    for rows.Next() {
+        var id int64
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return nil, nil, fmt.Errorf("failed to scan moved subcategory: %w", err)
+        }
+        subcategoryIDs = append(subcategoryIDs, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, nil, fmt.Errorf("failed to move subcategories: %w", err)
+    }
+
+    if len(templates) > 0 {
+        templateQuery := `
+            UPDATE $schema.attribute_templates
+            SET category_id = $2, updated_at = $3
+            WHERE category_id = $1 AND name = ANY($4)
+        `
+        if _, err := tx.ExecContext(ctx, cr.conn.Qualify(templateQuery), sourceID, targetID, now, pq.Array(templates)); err != nil {
+            return nil, nil, fmt.Errorf("failed to move attribute templates: %w", err)
+        }
+    }
+
+    deleteQuery := cr.conn.Qualify(`UPDATE $schema.categories SET deleted_at = $2, updated_at = $2 WHERE id = $1`)
+    if _, err := tx.ExecContext(ctx, deleteQuery, sourceID, now); err != nil {
+        return nil, nil, fmt.Errorf("failed to delete merged category: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, nil, fmt.Errorf("failed to commit category merge: %w", err)
+    }
+
+    return productIDs, subcategoryIDs, nil
+}
+
+// AssignProducts moves the given live products into categoryID and returns the ones that
+// moved; products already in it are left alone
+func (cr *CategoryRepository) AssignProducts(ctx context.Context, categoryID int64, productIDs []int64) ([]int64, error) {
+    query := `
+        UPDATE $schema.products
+        SET category_id = $1, updated_at = $3
+        WHERE id = ANY($2)
+          AND deleted_at IS NULL
+          AND category_id IS DISTINCT FROM $1
+          AND EXISTS (SELECT 1 FROM $schema.categories WHERE id = $1 AND deleted_at IS NULL)
+        RETURNING id
+    `
+    query = cr.conn.Qualify(query)
+
+    rows, err := cr.conn.QueryContext(ctx, query, categoryID, pq.Array(productIDs), time.Now().UTC())
+    if err != nil {
+        return nil, fmt.Errorf("failed to assign products: %w", err)
+    }
+    defer rows.Close()
+
+    moved := []int64{}
+    for rows.Next() {
+        var id int64
+        if err := rows.Scan(&id); err != nil {
+            return nil, fmt.Errorf("failed to scan assigned product: %w", err)
+        }
+        moved = append(moved, id)
+    }
+
+    return moved, rows.Err()
+}
+
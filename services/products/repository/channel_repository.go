@@ -0,0 +1,127 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrChannelNotFound is returned when no sales channel has the given ID or API key
+    ErrChannelNotFound = errors.New("sales channel not found")
+    // ErrDuplicateChannel is returned when registering a channel ID that already exists
+    ErrDuplicateChannel = errors.New("sales channel already exists")
+)
+
+const channelColumns = `id, name, quota, max_hold_minutes, active, created_at, updated_at`
+
+// ChannelRepository handles external sales channels
+type ChannelRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewChannelRepository creates new sales channel repository
+func NewChannelRepository(conn *db.Connection, clk clock.Clock) *ChannelRepository {
+    return &ChannelRepository{conn: conn, clock: clk}
+}
+
+// CreateChannel registers a channel with the hash of its API key
+func (cr *ChannelRepository) CreateChannel(ctx context.Context, channel *models.SalesChannel, apiKeyHash string) error {
+    query := `
+        INSERT INTO $schema.sales_channels (id, name, api_key_hash, quota, max_hold_minutes, active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, TRUE, $6, $6)
+        ON CONFLICT (id) DO NOTHING
+        RETURNING ` + channelColumns
+    query = cr.conn.Qualify(query)
+
+    created, err := scanChannel(cr.conn.QueryRowContext(ctx, query,
+        channel.ID,
+        channel.Name,
+        apiKeyHash,
+        channel.Quota,
+        channel.MaxHoldMinutes,
+        cr.clock.Now(),
+    ))
+    if err == sql.ErrNoRows {
+        return ErrDuplicateChannel
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create sales channel: %w", err)
+    }
+
+    *channel = *created
+    return nil
+}
+
+// GetChannel retrieves a channel by ID
+func (cr *ChannelRepository) GetChannel(ctx context.Context, id string) (*models.SalesChannel, error) {
+    query := cr.conn.Qualify(`SELECT `+channelColumns+` FROM $schema.sales_channels WHERE id = $1`)
+
+    channel, err := scanChannel(cr.conn.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrChannelNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get sales channel: %w", err)
+    }
+    return channel, nil
+}
+
+// GetActiveChannelByKeyHash retrieves the active channel owning an API key
+func (cr *ChannelRepository) GetActiveChannelByKeyHash(ctx context.Context, apiKeyHash string) (*models.SalesChannel, error) {
+    query := cr.conn.Qualify(`SELECT `+channelColumns+` FROM $schema.sales_channels WHERE api_key_hash = $1 AND active`)
+
+    channel, err := scanChannel(cr.conn.QueryRowContext(ctx, query, apiKeyHash))
+    if err == sql.ErrNoRows {
+        return nil, ErrChannelNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get sales channel: %w", err)
+    }
+    return channel, nil
+}
+
+// ListChannels lists all channels
+func (cr *ChannelRepository) ListChannels(ctx context.Context) ([]*models.SalesChannel, error) {
+    query := cr.conn.Qualify(`SELECT `+channelColumns+` FROM $schema.sales_channels ORDER BY id`)
+
+    rows, err := cr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list sales channels: %w", err)
+    }
+    defer rows.Close()
+
+    channels := []*models.SalesChannel{}
+    for rows.Next() {
+        channel, err := scanChannel(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan sales channel: %w", err)
+        }
+        channels = append(channels, channel)
+    }
+
+    return channels, rows.Err()
+}
+
+func scanChannel(row interface{ Scan(...interface{}) error }) (*models.SalesChannel, error) {
+    channel := &models.SalesChannel{}
+    err := row.Scan(
+        &channel.ID,
+        &channel.Name,
+        &channel.Quota,
+        &channel.MaxHoldMinutes,
+        &channel.Active,
+        &channel.CreatedAt,
+        &channel.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return channel, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+var (
+    // ErrReservationNotFound is returned when a channel has no reservation with the given ID
+    ErrReservationNotFound = errors.New("reservation not found")
+    // ErrReservationNotHeld is returned when extending or committing a released/expired reservation
+    ErrReservationNotHeld = errors.New("reservation is no longer held")
+    // ErrInsufficientStock is returned when available stock can't cover a reservation
+    ErrInsufficientStock = errors.New("insufficient stock")
+    // ErrChannelQuotaExceeded is returned when a reservation would exceed the channel's quota
+    ErrChannelQuotaExceeded = errors.New("channel quota exceeded")
+)
+
+const channelReservationColumns = `id, product_id, quantity, order_id, reservation_id, status, created_at, expires_at,
+    released_at, channel_id, COALESCE(external_ref, ''), committed_at, updated_at`
+
+// ReserveForChannel holds stock for a sales channel. Stock and quota are checked under
+// row locks on the product and channel so concurrent reservations can't oversell.
+// A reservation with the same external_ref is returned as-is (created is false).
+func (ir *InventoryReservationRepository) ReserveForChannel(ctx context.Context, channel *models.SalesChannel, req *models.ChannelReserveRequest) (*models.ChannelReservation, bool, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Lock the channel first so quota checks for the same channel serialize
+    lockChannel := ir.conn.Qualify(`SELECT quota FROM $schema.sales_channels WHERE id = $1 AND active FOR UPDATE`)
+    var quota int
+    err = tx.QueryRowContext(ctx, lockChannel, channel.ID).Scan(&quota)
+    if err == sql.ErrNoRows {
+        return nil, false, ErrChannelNotFound
+    }
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to lock sales channel: %w", err)
+    }
+
+    if req.ExternalRef != "" {
+        existingQuery := ir.conn.Qualify(`
+            SELECT `+channelReservationColumns+`
+            FROM $schema.inventory_reservations
+            WHERE channel_id = $1 AND external_ref = $2
+        `)
+        existing, err := scanChannelReservation(tx.QueryRowContext(ctx, existingQuery, channel.ID, req.ExternalRef))
+        if err == nil {
+            return existing, false, nil
+        }
+        if err != sql.ErrNoRows {
+            return nil, false, fmt.Errorf("failed to check existing reservation: %w", err)
+        }
+    }
+
+    var held int
+    heldQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE channel_id = $1 AND status = 'reserved'
+    `)
+    if err := tx.QueryRowContext(ctx, heldQuery, channel.ID).Scan(&held); err != nil {
+        return nil, false, fmt.Errorf("failed to get channel holdings: %w", err)
+    }
+    if held+req.Quantity > quota {
+        return nil, false, fmt.Errorf("%w: holding %d of %d units", ErrChannelQuotaExceeded, held, quota)
+    }
+
+    var stock int
+    lockProduct := ir.conn.Qualify(`SELECT stock_quantity FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+    err = tx.QueryRowContext(ctx, lockProduct, req.ProductID).Scan(&stock)
+    if err == sql.ErrNoRows {
+        return nil, false, fmt.Errorf("%w: %d", ErrUnknownProduct, req.ProductID)
+    }
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    var reserved int
+    reservedQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved'
+    `)
+    if err := tx.QueryRowContext(ctx, reservedQuery, req.ProductID).Scan(&reserved); err != nil {
+        return nil, false, fmt.Errorf("failed to get product reservations: %w", err)
+    }
+    if available := stock - reserved; available < req.Quantity {
+        return nil, false, fmt.Errorf("%w: %d available", ErrInsufficientStock, available)
+    }
+
+    now := ir.clock.Now()
+    insertQuery := ir.conn.Qualify(`
+        INSERT INTO $schema.inventory_reservations
+        (product_id, quantity, order_id, reservation_id, status, created_at, expires_at, channel_id, external_ref, updated_at)
+        VALUES ($1, $2, 0, $3, 'reserved', $4, $5, $6, NULLIF($7, ''), $4)
+        RETURNING `+channelReservationColumns)
+
+    reservation, err := scanChannelReservation(tx.QueryRowContext(ctx, insertQuery,
+        req.ProductID,
+        req.Quantity,
+        uuid.New().String(),
+        now,
+        now.Add(channel.HoldFor(req.HoldMinutes)),
+        channel.ID,
+        req.ExternalRef,
+    ))
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to create channel reservation: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, false, fmt.Errorf("failed to commit channel reservation: %w", err)
+    }
+
+    return reservation, true, nil
+}
+
+// GetChannelReservation retrieves a reservation owned by the channel
+func (ir *InventoryReservationRepository) GetChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    query := ir.conn.Qualify(`
+        SELECT `+channelReservationColumns+`
+        FROM $schema.inventory_reservations
+        WHERE reservation_id::text = $1 AND channel_id = $2
+    `)
+
+    reservation, err := scanChannelReservation(ir.conn.QueryRowContext(ctx, query, reservationID, channelID))
+    if err == sql.ErrNoRows {
+        return nil, ErrReservationNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get channel reservation: %w", err)
+    }
+    return reservation, nil
+}
+
+// ExtendChannelReservation moves the expiry of a held reservation to now + hold
+func (ir *InventoryReservationRepository) ExtendChannelReservation(ctx context.Context, channelID, reservationID string, hold time.Duration) (*models.ChannelReservation, error) {
+    now := ir.clock.Now()
+    query := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET expires_at = $1, updated_at = $2
+        WHERE reservation_id::text = $3 AND channel_id = $4 AND status = 'reserved' AND expires_at > $2
+        RETURNING `+channelReservationColumns)
+
+    reservation, err := scanChannelReservation(ir.conn.QueryRowContext(ctx, query, now.Add(hold), now, reservationID, channelID))
+    if err == sql.ErrNoRows {
+        return nil, ir.notHeldError(ctx, channelID, reservationID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to extend channel reservation: %w", err)
+    }
+    return reservation, nil
+}
+
+// CommitChannelReservation records the channel's sale: the reserved units are taken
+// out of stock and the reservation is marked committed. Committing twice is a no-op.
+func (ir *InventoryReservationRepository) CommitChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    lockQuery := ir.conn.Qualify(`
+        SELECT `+channelReservationColumns+`
+        FROM $schema.inventory_reservations
+        WHERE reservation_id::text = $1 AND channel_id = $2
+        FOR UPDATE
+    `)
+    reservation, err := scanChannelReservation(tx.QueryRowContext(ctx, lockQuery, reservationID, channelID))
+    if err == sql.ErrNoRows {
+        return nil, ErrReservationNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock channel reservation: %w", err)
+    }
+
+    now := ir.clock.Now()
+    if reservation.Status == models.ReservationStatusCommitted {
+        return reservation, nil
+    }
+    if reservation.Status != models.ReservationStatusReserved || reservation.IsExpired(now) {
+        return nil, fmt.Errorf("%w (%s)", ErrReservationNotHeld, reservation.Status)
+    }
+
+    stockQuery := ir.conn.Qualify(`
+        UPDATE $schema.products
+        SET stock_quantity = stock_quantity - $1, updated_at = $2
+        WHERE id = $3 AND stock_quantity >= $1
+    `)
+    result, err := tx.ExecContext(ctx, stockQuery, reservation.Quantity, now, reservation.ProductID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decrement stock: %w", err)
+    }
+    if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+        return nil, fmt.Errorf("%w: product %d", ErrInsufficientStock, reservation.ProductID)
+    }
+
+    commitQuery := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'committed', committed_at = $1, updated_at = $1
+        WHERE id = $2
+        RETURNING `+channelReservationColumns)
+    reservation, err = scanChannelReservation(tx.QueryRowContext(ctx, commitQuery, now, reservation.ID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to commit channel reservation: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+
+    return reservation, nil
+}
+
+// ReleaseChannelReservation gives held stock back. Releasing twice is a no-op.
+func (ir *InventoryReservationRepository) ReleaseChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error) {
+    now := ir.clock.Now()
+    query := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'released', released_at = $1, updated_at = $1
+        WHERE reservation_id::text = $2 AND channel_id = $3 AND status = 'reserved'
+        RETURNING `+channelReservationColumns)
+
+    reservation, err := scanChannelReservation(ir.conn.QueryRowContext(ctx, query, now, reservationID, channelID))
+    if err == sql.ErrNoRows {
+        existing, getErr := ir.GetChannelReservation(ctx, channelID, reservationID)
+        if getErr != nil {
+            return nil, getErr
+        }
+        if existing.Status == models.ReservationStatusReleased || existing.Status == models.ReservationStatusExpired {
+            return existing, nil
+        }
+        return nil, fmt.Errorf("%w (%s)", ErrReservationNotHeld, existing.Status)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to release channel reservation: %w", err)
+    }
+    return reservation, nil
+}
+
+// GetChannelReport totals a channel's reservations created in [from, to) by status
+func (ir *InventoryReservationRepository) GetChannelReport(ctx context.Context, channel *models.SalesChannel, from, to time.Time) (*models.ChannelReport, error) {
+    query := ir.conn.Qualify(`
+        SELECT status, COUNT(*), COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE channel_id = $1 AND created_at >= $2 AND created_at < $3
+        GROUP BY status
+    `)
+
+    rows, err := ir.conn.QueryContext(ctx, query, channel.ID, from, to)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get channel report: %w", err)
+    }
+    defer rows.Close()
+
+    byStatus := make(map[string]models.ChannelStatusTotals)
+    for rows.Next() {
+        var status string
+        var totals models.ChannelStatusTotals
+        if err := rows.Scan(&status, &totals.Reservations, &totals.Units); err != nil {
+            return nil, fmt.Errorf("failed to scan channel report: %w", err)
+        }
+        byStatus[status] = totals
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read channel report: %w", err)
+    }
+
+    var held int
+    heldQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE channel_id = $1 AND status = 'reserved'
+    `)
+    if err := ir.conn.QueryRowContext(ctx, heldQuery, channel.ID).Scan(&held); err != nil {
+        return nil, fmt.Errorf("failed to get channel holdings: %w", err)
+    }
+
+    return models.NewChannelReport(channel, from, to, held, byStatus), nil
+}
+
+// notHeldError explains why a reservation could not be updated
+func (ir *InventoryReservationRepository) notHeldError(ctx context.Context, channelID, reservationID string) error {
+    existing, err := ir.GetChannelReservation(ctx, channelID, reservationID)
+    if err != nil {
+        return err
+    }
+    if existing.Status == models.ReservationStatusReserved {
+        return fmt.Errorf("%w (expired at %s)", ErrReservationNotHeld, existing.ExpiresAt.Format(time.RFC3339))
+    }
+    return fmt.Errorf("%w (%s)", ErrReservationNotHeld, existing.Status)
+}
+
+func scanChannelReservation(row interface{ Scan(...interface{}) error }) (*models.ChannelReservation, error) {
+    reservation := &models.ChannelReservation{}
+    err := row.Scan(
+        &reservation.ID,
+        &reservation.ProductID,
+        &reservation.Quantity,
+        &reservation.OrderID,
+        &reservation.ReservationID,
+        &reservation.Status,
+        &reservation.CreatedAt,
+        &reservation.ExpiresAt,
+        &reservation.ReleasedAt,
+        &reservation.ChannelID,
+        &reservation.ExternalRef,
+        &reservation.CommittedAt,
+        &reservation.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return reservation, nil
+}
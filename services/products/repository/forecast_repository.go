@@ -0,0 +1,158 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/lib/pq"
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// ForecastRepository stores the reorder forecast of each product
+type ForecastRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewForecastRepository creates new forecast repository
+func NewForecastRepository(conn *db.Connection, clk clock.Clock) *ForecastRepository {
+    return &ForecastRepository{conn: conn, clock: clk}
+}
+
+// RefreshForecasts recomputes the forecast of every product and returns them all.
+// Sold units are reservations committed by OrderConfirmed (and channel commits) over the last
+// windowDays; available is stock minus active reservations, and on order is what open purchase
+// orders still expect. Like product stock, only product-level reservations count: variant
+// stock isn't forecast.
+func (fr *ForecastRepository) RefreshForecasts(ctx context.Context, windowDays int) ([]*models.StockForecast, error) {
+    now := fr.clock.Now()
+
+    tx, err := fr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    upsertQuery := fr.conn.Qualify(`
+        INSERT INTO $schema.reorder_forecasts (product_id, sold_units, window_days, available, on_order, computed_at)
+        SELECT p.id,
+               COALESCE(sold.units, 0),
+               $2,
+               p.stock_quantity - COALESCE(held.units, 0),
+               COALESCE(ordered.units, 0),
+               $1
+        FROM $schema.products p
+        LEFT JOIN (
+            SELECT product_id, SUM(quantity) AS units
+            FROM $schema.inventory_reservations
+            WHERE status = 'committed' AND variant_id IS NULL AND committed_at >= $3
+            GROUP BY product_id
+        ) sold ON sold.product_id = p.id
+        LEFT JOIN (
+            SELECT product_id, SUM(quantity) AS units
+            FROM $schema.inventory_reservations
+            WHERE status = 'reserved' AND variant_id IS NULL
+            GROUP BY product_id
+        ) held ON held.product_id = p.id
+        LEFT JOIN (
+            SELECT l.product_id, SUM(GREATEST(l.expected_quantity - l.received_quantity, 0)) AS units
+            FROM $schema.purchase_order_lines l
+            JOIN $schema.purchase_orders po ON po.id = l.purchase_order_id
+            WHERE po.status IN ('open', 'partially_received')
+            GROUP BY l.product_id
+        ) ordered ON ordered.product_id = p.id
+        WHERE p.deleted_at IS NULL
+        ON CONFLICT (product_id) DO UPDATE
+        SET sold_units = EXCLUDED.sold_units,
+            window_days = EXCLUDED.window_days,
+            available = EXCLUDED.available,
+            on_order = EXCLUDED.on_order,
+            computed_at = EXCLUDED.computed_at
+    `)
+    if _, err := tx.ExecContext(ctx, upsertQuery, now, windowDays, now.AddDate(0, 0, -windowDays)); err != nil {
+        return nil, fmt.Errorf("failed to refresh forecasts: %w", err)
+    }
+
+    deletedQuery := fr.conn.Qualify(`
+        DELETE FROM $schema.reorder_forecasts f
+        USING $schema.products p
+        WHERE p.id = f.product_id AND p.deleted_at IS NOT NULL
+    `)
+    if _, err := tx.ExecContext(ctx, deletedQuery); err != nil {
+        return nil, fmt.Errorf("failed to drop forecasts of deleted products: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit forecasts: %w", err)
+    }
+
+    return fr.ListForecasts(ctx)
+}
+
+// ListForecasts returns the stored forecast of every product, by product ID
+func (fr *ForecastRepository) ListForecasts(ctx context.Context) ([]*models.StockForecast, error) {
+    query := fr.conn.Qualify(`
+        SELECT f.product_id, p.name, f.sold_units, f.window_days, f.available, f.on_order, f.computed_at, f.suggested_at
+        FROM $schema.reorder_forecasts f
+        JOIN $schema.products p ON p.id = f.product_id
+        WHERE p.deleted_at IS NULL
+        ORDER BY f.product_id
+    `)
+
+    rows, err := fr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list forecasts: %w", err)
+    }
+    defer rows.Close()
+
+    forecasts := []*models.StockForecast{}
+    for rows.Next() {
+        forecast := &models.StockForecast{}
+        if err := rows.Scan(
+            &forecast.ProductID,
+            &forecast.ProductName,
+            &forecast.SoldUnits,
+            &forecast.WindowDays,
+            &forecast.Available,
+            &forecast.OnOrder,
+            &forecast.ComputedAt,
+            &forecast.SuggestedAt,
+        ); err != nil {
+            return nil, fmt.Errorf("failed to scan forecast: %w", err)
+        }
+        forecasts = append(forecasts, forecast)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read forecasts: %w", err)
+    }
+
+    return forecasts, nil
+}
+
+// MarkReorderSuggested records that ReorderSuggested was published for the product's current dip
+func (fr *ForecastRepository) MarkReorderSuggested(ctx context.Context, productID int64, at time.Time) error {
+    query := fr.conn.Qualify(`UPDATE $schema.reorder_forecasts SET suggested_at = $1 WHERE product_id = $2`)
+
+    if _, err := fr.conn.ExecContext(ctx, query, at, productID); err != nil {
+        return fmt.Errorf("failed to mark reorder suggested: %w", err)
+    }
+    return nil
+}
+
+// ClearReorderSuggested forgets the suggestions of products that recovered, so their next
+// dip is suggested again
+func (fr *ForecastRepository) ClearReorderSuggested(ctx context.Context, productIDs []int64) error {
+    if len(productIDs) == 0 {
+        return nil
+    }
+
+    query := fr.conn.Qualify(`UPDATE $schema.reorder_forecasts SET suggested_at = NULL WHERE product_id = ANY($1)`)
+
+    if _, err := fr.conn.ExecContext(ctx, query, pq.Array(productIDs)); err != nil {
+        return fmt.Errorf("failed to clear reorder suggestions: %w", err)
+    }
+    return nil
+}
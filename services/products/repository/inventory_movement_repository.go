@@ -0,0 +1,163 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+const inventoryMovementColumns = `id, product_id, variant_id, delta, stock_after, reason, order_id, reservation_id, actor_id, note, created_at`
+
+// recordMovement writes an inventory movement in the transaction that changed the stock,
+// so the audit trail can't disagree with stock_quantity
+func (ir *InventoryReservationRepository) recordMovement(ctx context.Context, tx *sql.Tx, movement *models.InventoryMovement) error {
+    query := ir.conn.Qualify(`
+        INSERT INTO $schema.inventory_movements
+        (product_id, variant_id, delta, stock_after, reason, order_id, reservation_id, actor_id, note, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id
+    `)
+
+    err := tx.QueryRowContext(ctx, query,
+        movement.ProductID,
+        movement.VariantID,
+        movement.Delta,
+        movement.StockAfter,
+        movement.Reason,
+        movement.OrderID,
+        movement.ReservationID,
+        movement.ActorID,
+        movement.Note,
+        movement.CreatedAt,
+    ).Scan(&movement.ID)
+    if err != nil {
+        return fmt.Errorf("failed to record inventory movement for product %d: %w", movement.ProductID, err)
+    }
+    return nil
+}
+
+// AdjustStock changes a product's (or, with req.VariantID, a variant's) stock_quantity by
+// req.Delta by hand and records the movement with its reason, actor and note in the same
+// transaction. Stock can't go below zero: that is a StockShortageError.
+func (ir *InventoryReservationRepository) AdjustStock(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var stock int
+    if req.VariantID != nil {
+        lockVariant := ir.conn.Qualify(`
+            SELECT v.stock_quantity
+            FROM $schema.product_variants v
+            JOIN $schema.products p ON p.id = v.product_id
+            WHERE v.id = $1 AND v.product_id = $2 AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+            FOR UPDATE OF v
+        `)
+        err = tx.QueryRowContext(ctx, lockVariant, *req.VariantID, productID).Scan(&stock)
+    } else {
+        lockProduct := ir.conn.Qualify(`SELECT stock_quantity FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+        err = tx.QueryRowContext(ctx, lockProduct, productID).Scan(&stock)
+    }
+    if err == sql.ErrNoRows {
+        if req.VariantID != nil {
+            return nil, fmt.Errorf("%w: %d variant %d", ErrUnknownProduct, productID, *req.VariantID)
+        }
+        return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, productID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    stockAfter := stock + req.Delta
+    if stockAfter < 0 {
+        shortage := &StockShortageError{ProductID: productID, Requested: -req.Delta, Available: stock}
+        if req.VariantID != nil {
+            shortage.VariantID = *req.VariantID
+        }
+        return nil, shortage
+    }
+
+    now := ir.clock.Now()
+    if req.VariantID != nil {
+        updateVariant := ir.conn.Qualify(`UPDATE $schema.product_variants SET stock_quantity = $1, updated_at = $2 WHERE id = $3`)
+        _, err = tx.ExecContext(ctx, updateVariant, stockAfter, now, *req.VariantID)
+    } else {
+        updateProduct := ir.conn.Qualify(`UPDATE $schema.products SET stock_quantity = $1, updated_at = $2 WHERE id = $3`)
+        _, err = tx.ExecContext(ctx, updateProduct, stockAfter, now, productID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to adjust stock: %w", err)
+    }
+
+    movement := &models.InventoryMovement{
+        ProductID:  productID,
+        VariantID:  req.VariantID,
+        Delta:      req.Delta,
+        StockAfter: stockAfter,
+        Reason:     req.Reason,
+        CreatedAt:  now,
+    }
+    if actorID != "" {
+        movement.ActorID = &actorID
+    }
+    if req.Note != "" {
+        note := req.Note
+        movement.Note = &note
+    }
+    if err := ir.recordMovement(ctx, tx, movement); err != nil {
+        return nil, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit stock adjustment: %w", err)
+    }
+
+    return movement, nil
+}
+
+// GetProductMovements returns a product's inventory movements, newest first. With a variant ID
+// only that variant's movements are returned.
+func (ir *InventoryReservationRepository) GetProductMovements(ctx context.Context, productID int64, variantID *int64, limit int) ([]*models.InventoryMovement, error) {
+    query := ir.conn.Qualify(`
+        SELECT ` + inventoryMovementColumns + `
+        FROM $schema.inventory_movements
+        WHERE product_id = $1 AND ($2::BIGINT IS NULL OR variant_id = $2)
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3
+    `)
+
+    rows, err := ir.conn.QueryContext(ctx, query, productID, variantID, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get inventory movements: %w", err)
+    }
+    defer rows.Close()
+
+    movements := []*models.InventoryMovement{}
+    for rows.Next() {
+        movement := &models.InventoryMovement{}
+        if err := rows.Scan(
+            &movement.ID,
+            &movement.ProductID,
+            &movement.VariantID,
+            &movement.Delta,
+            &movement.StockAfter,
+            &movement.Reason,
+            &movement.OrderID,
+            &movement.ReservationID,
+            &movement.ActorID,
+            &movement.Note,
+            &movement.CreatedAt,
+        ); err != nil {
+            return nil, fmt.Errorf("failed to scan inventory movement: %w", err)
+        }
+        movements = append(movements, movement)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read inventory movements: %w", err)
+    }
+    return movements, nil
+}
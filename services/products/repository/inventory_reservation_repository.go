@@ -4,20 +4,21 @@ import (
     "context"
     "fmt"
     "log"
-    "time"
 
     "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
     "github.com/sanketh-sg/prost/shared/db"
 )
 
 // InventoryReservationRepository handles inventory reservation database operations
 type InventoryReservationRepository struct {
-    conn *db.Connection
+    conn  *db.Connection
+    clock clock.Clock
 }
 
 // NewInventoryReservationRepository creates new inventory repository
-func NewInventoryReservationRepository(conn *db.Connection) *InventoryReservationRepository {
-    return &InventoryReservationRepository{conn: conn}
+func NewInventoryReservationRepository(conn *db.Connection, clk clock.Clock) *InventoryReservationRepository {
+    return &InventoryReservationRepository{conn: conn, clock: clk}
 }
 
 // CreateReservation creates a new inventory reservation
@@ -29,7 +30,7 @@ func (ir *InventoryReservationRepository) CreateReservation(ctx context.Context,
         RETURNING id, product_id, quantity, order_id, reservation_id, status, created_at, expires_at
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     err := ir.conn.QueryRowContext(ctx, query,
         reservation.ProductID,
@@ -66,7 +67,7 @@ func (ir *InventoryReservationRepository) GetReservation(ctx context.Context, re
         WHERE reservation_id = $1
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     reservation := &models.InventoryReservation{}
     err := ir.conn.QueryRowContext(ctx, query, reservationID).Scan(
@@ -88,15 +89,16 @@ func (ir *InventoryReservationRepository) GetReservation(ctx context.Context, re
     return reservation, nil
 }
 
-// GetReservationsByOrderID retrieves all reservations for an order
+// GetReservationsByOrderID retrieves all reservations for an order, oldest first
 func (ir *InventoryReservationRepository) GetReservationsByOrderID(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error) {
     query := `
-        SELECT id, product_id, quantity, order_id, reservation_id, status, created_at, expires_at, released_at
+        SELECT id, product_id, variant_id, quantity, order_id, reservation_id, status, created_at, expires_at, released_at
         FROM $schema.inventory_reservations
         WHERE order_id = $1
+        ORDER BY created_at ASC, id ASC
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     rows, err := ir.conn.QueryContext(ctx, query, orderID)
     if err != nil {
@@ -104,12 +106,13 @@ func (ir *InventoryReservationRepository) GetReservationsByOrderID(ctx context.C
     }
     defer rows.Close()
 
-    var reservations []*models.InventoryReservation
+    reservations := []*models.InventoryReservation{}
     for rows.Next() {
         reservation := &models.InventoryReservation{}
         err := rows.Scan(
             &reservation.ID,
             &reservation.ProductID,
+            &reservation.VariantID,
             &reservation.Quantity,
             &reservation.OrderID,
             &reservation.ReservationID,
@@ -135,9 +138,9 @@ func (ir *InventoryReservationRepository) ReleaseReservation(ctx context.Context
         WHERE reservation_id = $2 AND status = 'reserved'
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
-    result, err := ir.conn.ExecContext(ctx, query, time.Now().UTC(), reservationID)
+    result, err := ir.conn.ExecContext(ctx, query, ir.clock.Now(), reservationID)
     if err != nil {
         return fmt.Errorf("failed to release reservation: %w", err)
     }
@@ -155,16 +158,17 @@ func (ir *InventoryReservationRepository) ReleaseReservation(ctx context.Context
 }
 
 // ExpireReservations expires old reservations
+// Why: compare against the injected clock instead of NOW() so expiry is testable
 func (ir *InventoryReservationRepository) ExpireReservations(ctx context.Context) (int64, error) {
     query := `
         UPDATE $schema.inventory_reservations
         SET status = 'expired'
-        WHERE status = 'reserved' AND expires_at < NOW()
+        WHERE status = 'reserved' AND expires_at < $1
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
-    result, err := ir.conn.ExecContext(ctx, query)
+    result, err := ir.conn.ExecContext(ctx, query, ir.clock.Now())
     if err != nil {
         return 0, fmt.Errorf("failed to expire reservations: %w", err)
     }
@@ -172,15 +176,15 @@ func (ir *InventoryReservationRepository) ExpireReservations(ctx context.Context
     return result.RowsAffected()
 }
 
-// GetProductReservations gets active reservations for a product
+// GetProductReservations gets active reservations for a product's own stock (not its variants')
 func (ir *InventoryReservationRepository) GetProductReservations(ctx context.Context, productID int64) (int, error) {
     query := `
         SELECT COALESCE(SUM(quantity), 0)
         FROM $schema.inventory_reservations
-        WHERE product_id = $1 AND status = 'reserved'
+        WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved'
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     var totalReserved int
     err := ir.conn.QueryRowContext(ctx, query, productID).Scan(&totalReserved)
@@ -191,6 +195,25 @@ func (ir *InventoryReservationRepository) GetProductReservations(ctx context.Con
     return totalReserved, nil
 }
 
+// GetVariantReservations gets the units held in active reservations for a variant
+func (ir *InventoryReservationRepository) GetVariantReservations(ctx context.Context, variantID int64) (int, error) {
+    query := `
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE variant_id = $1 AND status = 'reserved'
+    `
+
+    query = ir.conn.Qualify(query)
+
+    var totalReserved int
+    err := ir.conn.QueryRowContext(ctx, query, variantID).Scan(&totalReserved)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get variant reservations: %w", err)
+    }
+
+    return totalReserved, nil
+}
+
 // UpdateReservationStatusByOrderID updates all reservations for an order to a new status
 // Used when order is confirmed, failed, or cancelled
 func (ir *InventoryReservationRepository) UpdateReservationStatusByOrderID(ctx context.Context, orderID string, status string) error {
@@ -200,7 +223,7 @@ func (ir *InventoryReservationRepository) UpdateReservationStatusByOrderID(ctx c
         WHERE order_id::text = $2
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     result, err := ir.conn.ExecContext(ctx, query, status, orderID)
     if err != nil {
@@ -231,7 +254,7 @@ func (ir *InventoryReservationRepository) UpdateReservationStatus(ctx context.Co
         WHERE order_id = $2
     `
 
-    query = replaceSchema(query, ir.conn.Schema)
+    query = ir.conn.Qualify(query)
 
     result, err := ir.conn.ExecContext(ctx, query, status, orderID)
     if err != nil {
@@ -259,7 +282,7 @@ func(ir *InventoryReservationRepository) GetProductInventory(ctx context.Context
         FROM $schema.products
         WHERE id = $1
     `
-    productQuery = replaceSchema(productQuery, ir.conn.Schema)
+    productQuery = ir.conn.Qualify(productQuery)
     
     var id int64
     var stockQuantity int
@@ -0,0 +1,106 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// heldStockJoin sums the product-level units held by active reservations
+const heldStockJoin = `LEFT JOIN (
+            SELECT product_id, SUM(quantity) AS units
+            FROM $schema.inventory_reservations
+            WHERE status = 'reserved' AND variant_id IS NULL
+            GROUP BY product_id
+        ) held ON held.product_id = p.id`
+
+// LowStockRepository finds products whose available stock is below their low-stock threshold
+type LowStockRepository struct {
+    conn *db.Connection
+}
+
+// NewLowStockRepository creates new low stock repository
+func NewLowStockRepository(conn *db.Connection) *LowStockRepository {
+    return &LowStockRepository{conn: conn}
+}
+
+// GetLowStockProducts returns the live products with a threshold whose available stock
+// (stock minus active reservations, like GET /inventory/:product_id) is below it, fewest
+// available first. Variant stock isn't checked.
+func (lr *LowStockRepository) GetLowStockProducts(ctx context.Context) ([]*models.LowStockProduct, error) {
+    query := lr.conn.Qualify(`
+        SELECT p.id, p.name, p.sku, p.stock_quantity, COALESCE(held.units, 0),
+               p.stock_quantity - COALESCE(held.units, 0) AS available,
+               p.low_stock_threshold, p.low_stock_alerted_at
+        FROM $schema.products p
+        ` + heldStockJoin + `
+        WHERE p.deleted_at IS NULL
+          AND p.low_stock_threshold > 0
+          AND p.stock_quantity - COALESCE(held.units, 0) < p.low_stock_threshold
+        ORDER BY available, p.id
+    `)
+
+    rows, err := lr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get low stock products: %w", err)
+    }
+    defer rows.Close()
+
+    products := []*models.LowStockProduct{}
+    for rows.Next() {
+        product := &models.LowStockProduct{}
+        if err := rows.Scan(
+            &product.ProductID,
+            &product.Name,
+            &product.SKU,
+            &product.StockQuantity,
+            &product.Reserved,
+            &product.Available,
+            &product.Threshold,
+            &product.AlertedAt,
+        ); err != nil {
+            return nil, fmt.Errorf("failed to scan low stock product: %w", err)
+        }
+        products = append(products, product)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read low stock products: %w", err)
+    }
+
+    return products, nil
+}
+
+// MarkLowStockAlerted records that LowStock was published for the product's current dip
+func (lr *LowStockRepository) MarkLowStockAlerted(ctx context.Context, productID int64, at time.Time) error {
+    query := lr.conn.Qualify(`UPDATE $schema.products SET low_stock_alerted_at = $1 WHERE id = $2`)
+
+    if _, err := lr.conn.ExecContext(ctx, query, at, productID); err != nil {
+        return fmt.Errorf("failed to mark low stock alerted: %w", err)
+    }
+    return nil
+}
+
+// ClearRecoveredLowStock forgets the alerts of products that are back at their threshold (or
+// no longer have one), so their next dip alerts again. It returns how many were cleared.
+func (lr *LowStockRepository) ClearRecoveredLowStock(ctx context.Context) (int64, error) {
+    query := lr.conn.Qualify(`
+        UPDATE $schema.products
+        SET low_stock_alerted_at = NULL
+        WHERE id IN (
+            SELECT p.id
+            FROM $schema.products p
+            ` + heldStockJoin + `
+            WHERE p.low_stock_alerted_at IS NOT NULL
+              AND (p.low_stock_threshold = 0 OR p.stock_quantity - COALESCE(held.units, 0) >= p.low_stock_threshold)
+        )
+    `)
+
+    result, err := lr.conn.ExecContext(ctx, query)
+    if err != nil {
+        return 0, fmt.Errorf("failed to clear recovered low stock alerts: %w", err)
+    }
+    return result.RowsAffected()
+}
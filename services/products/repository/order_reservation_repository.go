@@ -0,0 +1,321 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// StockShortageError reports the product (and variant, if any) that couldn't be reserved for an order
+type StockShortageError struct {
+    ProductID int64
+    VariantID int64 // 0 for the product itself
+    Requested int
+    Available int
+}
+
+func (e *StockShortageError) Error() string {
+    if e.VariantID != 0 {
+        return fmt.Sprintf("insufficient stock for product %d variant %d: requested %d, available %d", e.ProductID, e.VariantID, e.Requested, e.Available)
+    }
+    return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d", e.ProductID, e.Requested, e.Available)
+}
+
+// Unwrap lets callers match the shortage with errors.Is(err, ErrInsufficientStock)
+func (e *StockShortageError) Unwrap() error {
+    return ErrInsufficientStock
+}
+
+const orderReservationColumns = `id, product_id, variant_id, quantity, order_id, reservation_id, status, created_at, expires_at, released_at`
+
+// ReserveForOrder holds stock for every line of an order, or for none of them.
+// Why: reserving line by line let two orders each take part of the same stock and then
+// both fail; here all products are locked (in product ID order, so concurrent orders
+// can't deadlock) and checked before anything is inserted.
+// A line with a variant holds the variant's stock instead of the product's; lines are sorted
+// by product then variant, so the variant rows are locked in a consistent order too.
+// An order that already has held reservations gets those back (redelivered OrderCreated).
+// The soft locks of cartID (see LockForCart) are released in the same transaction, so the
+// customer's own holds don't count against the order they turn into.
+func (ir *InventoryReservationRepository) ReserveForOrder(ctx context.Context, orderID int64, cartID string, lines []models.OrderLine, ttl time.Duration) ([]*models.InventoryReservation, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    lines = models.MergeOrderLines(lines)
+
+    lockProduct := ir.conn.Qualify(`SELECT stock_quantity FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+    lockVariant := ir.conn.Qualify(`
+        SELECT v.stock_quantity
+        FROM $schema.product_variants v
+        JOIN $schema.products p ON p.id = v.product_id
+        WHERE v.id = $1 AND v.product_id = $2 AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+        FOR UPDATE OF v
+    `)
+    reservedQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved'
+    `)
+    variantReservedQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE variant_id = $1 AND status = 'reserved'
+    `)
+    stock := make([]int, len(lines))
+    for i, line := range lines {
+        var err error
+        if line.VariantID != 0 {
+            err = tx.QueryRowContext(ctx, lockVariant, line.VariantID, line.ProductID).Scan(&stock[i])
+        } else {
+            err = tx.QueryRowContext(ctx, lockProduct, line.ProductID).Scan(&stock[i])
+        }
+        if err == sql.ErrNoRows {
+            if line.VariantID != 0 {
+                return nil, fmt.Errorf("%w: %d variant %d", ErrUnknownProduct, line.ProductID, line.VariantID)
+            }
+            return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, line.ProductID)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to lock product: %w", err)
+        }
+    }
+
+    // Checked under the product locks, so a concurrent delivery for this order has committed
+    existing, err := ir.heldOrderReservations(ctx, tx, orderID)
+    if err != nil {
+        return nil, err
+    }
+    if len(existing) > 0 {
+        return existing, nil
+    }
+
+    if cartID != "" {
+        releaseCartLocks := ir.conn.Qualify(`
+            UPDATE $schema.inventory_reservations
+            SET status = 'released', released_at = $1, updated_at = $1
+            WHERE cart_id = $2 AND status = 'reserved'
+        `)
+        if _, err := tx.ExecContext(ctx, releaseCartLocks, ir.clock.Now(), cartID); err != nil {
+            return nil, fmt.Errorf("failed to release cart locks: %w", err)
+        }
+    }
+
+    for i, line := range lines {
+        var reserved int
+        var err error
+        if line.VariantID != 0 {
+            err = tx.QueryRowContext(ctx, variantReservedQuery, line.VariantID).Scan(&reserved)
+        } else {
+            err = tx.QueryRowContext(ctx, reservedQuery, line.ProductID).Scan(&reserved)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to get product reservations: %w", err)
+        }
+        if available := stock[i] - reserved; available < line.Quantity {
+            return nil, &StockShortageError{ProductID: line.ProductID, VariantID: line.VariantID, Requested: line.Quantity, Available: available}
+        }
+    }
+
+    now := ir.clock.Now()
+    insertQuery := ir.conn.Qualify(`
+        INSERT INTO $schema.inventory_reservations
+        (product_id, variant_id, quantity, order_id, reservation_id, status, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5, 'reserved', $6, $7)
+        RETURNING ` + orderReservationColumns)
+
+    reservations := make([]*models.InventoryReservation, 0, len(lines))
+    for _, line := range lines {
+        var variantID *int64
+        if line.VariantID != 0 {
+            variantID = &line.VariantID
+        }
+        reservation, err := scanOrderReservation(tx.QueryRowContext(ctx, insertQuery,
+            line.ProductID,
+            variantID,
+            line.Quantity,
+            orderID,
+            uuid.New().String(),
+            now,
+            now.Add(ttl),
+        ))
+        if err != nil {
+            return nil, fmt.Errorf("failed to create reservation for product %d: %w", line.ProductID, err)
+        }
+        reservations = append(reservations, reservation)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit order reservation: %w", err)
+    }
+
+    return reservations, nil
+}
+
+// CommitOrderReservations takes an order's held units out of stock, marks the reservations
+// committed and writes an order_confirmed inventory movement for each, all in one transaction.
+// Committing twice is a no-op; it returns the number committed.
+func (ir *InventoryReservationRepository) CommitOrderReservations(ctx context.Context, orderID int64) (int, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    lockQuery := ir.conn.Qualify(`
+        SELECT ` + orderReservationColumns + `
+        FROM $schema.inventory_reservations
+        WHERE order_id = $1 AND channel_id IS NULL AND status = 'reserved'
+        ORDER BY product_id
+        FOR UPDATE
+    `)
+    rows, err := tx.QueryContext(ctx, lockQuery, orderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to lock order reservations: %w", err)
+    }
+    var reservations []*models.InventoryReservation
+    for rows.Next() {
+        reservation, err := scanOrderReservation(rows)
+        if err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("failed to scan reservation: %w", err)
+        }
+        reservations = append(reservations, reservation)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("failed to read order reservations: %w", err)
+    }
+
+    now := ir.clock.Now()
+    stockQuery := ir.conn.Qualify(`
+        UPDATE $schema.products
+        SET stock_quantity = stock_quantity - $1, updated_at = $2
+        WHERE id = $3 AND stock_quantity >= $1
+        RETURNING stock_quantity
+    `)
+    variantStockQuery := ir.conn.Qualify(`
+        UPDATE $schema.product_variants
+        SET stock_quantity = stock_quantity - $1, updated_at = $2
+        WHERE id = $3 AND stock_quantity >= $1
+        RETURNING stock_quantity
+    `)
+    commitQuery := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'committed', committed_at = $1, updated_at = $1
+        WHERE id = $2
+    `)
+    for _, reservation := range reservations {
+        var stockAfter int
+        var err error
+        if reservation.VariantID != nil {
+            err = tx.QueryRowContext(ctx, variantStockQuery, reservation.Quantity, now, *reservation.VariantID).Scan(&stockAfter)
+        } else {
+            err = tx.QueryRowContext(ctx, stockQuery, reservation.Quantity, now, reservation.ProductID).Scan(&stockAfter)
+        }
+        if err == sql.ErrNoRows {
+            return 0, fmt.Errorf("%w: product %d", ErrInsufficientStock, reservation.ProductID)
+        }
+        if err != nil {
+            return 0, fmt.Errorf("failed to decrement stock: %w", err)
+        }
+        if _, err := tx.ExecContext(ctx, commitQuery, now, reservation.ID); err != nil {
+            return 0, fmt.Errorf("failed to commit reservation %s: %w", reservation.ReservationID, err)
+        }
+
+        orderID, reservationID := reservation.OrderID, reservation.ReservationID
+        if err := ir.recordMovement(ctx, tx, &models.InventoryMovement{
+            ProductID:     reservation.ProductID,
+            VariantID:     reservation.VariantID,
+            Delta:         -reservation.Quantity,
+            StockAfter:    stockAfter,
+            Reason:        models.MovementReasonOrderConfirmed,
+            OrderID:       &orderID,
+            ReservationID: &reservationID,
+            CreatedAt:     now,
+        }); err != nil {
+            return 0, err
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+
+    return len(reservations), nil
+}
+
+// ExtendOrderReservations keeps an order's held reservations until at least until, so they
+// don't expire while the order waits for a payment retry. It returns the number extended.
+func (ir *InventoryReservationRepository) ExtendOrderReservations(ctx context.Context, orderID int64, until time.Time) (int, error) {
+    query := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET expires_at = GREATEST(expires_at, $1), updated_at = $2
+        WHERE order_id = $3 AND channel_id IS NULL AND status = 'reserved'
+    `)
+
+    result, err := ir.conn.ExecContext(ctx, query, until, ir.clock.Now(), orderID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to extend order reservations: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    return int(rowsAffected), nil
+}
+
+// heldOrderReservations returns the reserved or committed reservations of an order
+func (ir *InventoryReservationRepository) heldOrderReservations(ctx context.Context, tx *sql.Tx, orderID int64) ([]*models.InventoryReservation, error) {
+    query := ir.conn.Qualify(`
+        SELECT ` + orderReservationColumns + `
+        FROM $schema.inventory_reservations
+        WHERE order_id = $1 AND channel_id IS NULL AND status IN ('reserved', 'committed')
+        ORDER BY product_id
+    `)
+    rows, err := tx.QueryContext(ctx, query, orderID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get order reservations: %w", err)
+    }
+    defer rows.Close()
+
+    var reservations []*models.InventoryReservation
+    for rows.Next() {
+        reservation, err := scanOrderReservation(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan reservation: %w", err)
+        }
+        reservations = append(reservations, reservation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read order reservations: %w", err)
+    }
+    return reservations, nil
+}
+
+func scanOrderReservation(row interface{ Scan(...interface{}) error }) (*models.InventoryReservation, error) {
+    reservation := &models.InventoryReservation{}
+    err := row.Scan(
+        &reservation.ID,
+        &reservation.ProductID,
+        &reservation.VariantID,
+        &reservation.Quantity,
+        &reservation.OrderID,
+        &reservation.ReservationID,
+        &reservation.Status,
+        &reservation.CreatedAt,
+        &reservation.ExpiresAt,
+        &reservation.ReleasedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return reservation, nil
+}
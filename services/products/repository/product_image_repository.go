@@ -0,0 +1,112 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+// ProductImageRepository handles uploaded product image database operations
+type ProductImageRepository struct {
+    conn *db.Connection
+}
+
+// NewProductImageRepository creates new product image repository
+func NewProductImageRepository(conn *db.Connection) *ProductImageRepository {
+    return &ProductImageRepository{conn: conn}
+}
+
+// AddImage records an uploaded image of a live product. It becomes the product's image_url
+// when primary is set or the product has no image yet; image.Primary tells which.
+// The product row is locked so two first uploads can't both become primary.
+func (ir *ProductImageRepository) AddImage(ctx context.Context, image *models.ProductImage, primary bool) error {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var imageURL string
+    lockProduct := ir.conn.Qualify(`SELECT image_url FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+    err = tx.QueryRowContext(ctx, lockProduct, image.ProductID).Scan(&imageURL)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("%w: %d", ErrUnknownProduct, image.ProductID)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    insertQuery := ir.conn.Qualify(`
+        INSERT INTO $schema.product_images (product_id, storage_key, url, content_type, size_bytes, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id
+    `)
+    err = tx.QueryRowContext(ctx, insertQuery,
+        image.ProductID,
+        image.StorageKey,
+        image.URL,
+        image.ContentType,
+        image.SizeBytes,
+        image.CreatedAt,
+    ).Scan(&image.ID)
+    if err != nil {
+        return fmt.Errorf("failed to create product image: %w", err)
+    }
+
+    image.Primary = primary || imageURL == ""
+    if image.Primary {
+        updateQuery := ir.conn.Qualify(`UPDATE $schema.products SET image_url = $1, updated_at = $2 WHERE id = $3`)
+        if _, err := tx.ExecContext(ctx, updateQuery, image.URL, image.CreatedAt, image.ProductID); err != nil {
+            return fmt.Errorf("failed to set product image: %w", err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit product image: %w", err)
+    }
+
+    return nil
+}
+
+// GetImagesByProduct retrieves the uploaded images of a product, oldest first
+func (ir *ProductImageRepository) GetImagesByProduct(ctx context.Context, productID int64) ([]*models.ProductImage, error) {
+    query := ir.conn.Qualify(`
+        SELECT i.id, i.product_id, i.storage_key, i.url, i.content_type, i.size_bytes, i.url = p.image_url, i.created_at
+        FROM $schema.product_images i
+        JOIN $schema.products p ON p.id = i.product_id
+        WHERE i.product_id = $1
+        ORDER BY i.id ASC
+    `)
+
+    rows, err := ir.conn.QueryContext(ctx, query, productID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get product images: %w", err)
+    }
+    defer rows.Close()
+
+    images := []*models.ProductImage{}
+    for rows.Next() {
+        image := &models.ProductImage{}
+        if err := rows.Scan(
+            &image.ID,
+            &image.ProductID,
+            &image.StorageKey,
+            &image.URL,
+            &image.ContentType,
+            &image.SizeBytes,
+            &image.Primary,
+            &image.CreatedAt,
+        ); err != nil {
+            return nil, fmt.Errorf("failed to scan product image: %w", err)
+        }
+        images = append(images, image)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read product images: %w", err)
+    }
+
+    return images, nil
+}
@@ -2,19 +2,48 @@ package repository
 
 import (
     "context"
+    "database/sql"
+    "errors"
     "fmt"
     "log"
+    "strings"
     "time"
 
+    "github.com/lib/pq"
     "github.com/sanketh-sg/prost/services/products/models"
     "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/pagination"
 )
 
+// ErrProductNotDeleted is returned when restoring a product that isn't deleted
+var ErrProductNotDeleted = errors.New("product is not deleted")
+
 // ProductRepository handles product database operations
 type ProductRepository struct {
     conn *db.Connection
 }
 
+// productColumns are the product fields read by GetProduct, GetProductBySKU and GetAllProducts
+const productColumns = `p.id, p.name, p.description, p.price, p.category_id, p.sku, p.stock_quantity, p.image_url,
+        p.warehouse, p.fulfillment_type, p.attributes, p.low_stock_threshold, COALESCE(r.average_rating, 0), COALESCE(r.review_count, 0), p.created_at, p.updated_at, p.deleted_at`
+
+// categoryTreeCTE lists category $1 and all its subcategories as tree(id)
+const categoryTreeCTE = `WITH RECURSIVE tree AS (
+            SELECT id FROM $schema.categories WHERE id = $1 AND deleted_at IS NULL
+            UNION
+            SELECT c.id FROM $schema.categories c
+            JOIN tree t ON c.parent_id = t.id
+            WHERE c.deleted_at IS NULL
+        )`
+
+// ratingJoin aggregates approved reviews per product
+const ratingJoin = `LEFT JOIN (
+            SELECT product_id, ROUND(AVG(rating), 2)::float8 AS average_rating, COUNT(*) AS review_count
+            FROM $schema.product_reviews
+            WHERE status = 'approved'
+            GROUP BY product_id
+        ) r ON r.product_id = p.id`
+
 // NewProductRepository creates new product repository
 func NewProductRepository(conn *db.Connection) *ProductRepository {
     return &ProductRepository{conn: conn}
@@ -24,12 +53,12 @@ func NewProductRepository(conn *db.Connection) *ProductRepository {
 func (pr *ProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
     query := `
         INSERT INTO $schema.products 
-        (name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-        RETURNING id, name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at
+        (name, description, price, category_id, sku, stock_quantity, image_url, warehouse, fulfillment_type, attributes, low_stock_threshold, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        RETURNING id, name, description, price, category_id, sku, stock_quantity, image_url, warehouse, fulfillment_type, attributes, low_stock_threshold, created_at, updated_at
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     err := pr.conn.QueryRowContext(ctx, query,
         product.Name,
@@ -39,6 +68,10 @@ func (pr *ProductRepository) CreateProduct(ctx context.Context, product *models.
         product.SKU,
         product.StockQuantity,
         product.ImageURL,
+        product.Warehouse,
+        product.FulfillmentType,
+        product.Attributes,
+        product.LowStockThreshold,
         product.CreatedAt,
         product.UpdatedAt,
     ).Scan(
@@ -50,6 +83,10 @@ func (pr *ProductRepository) CreateProduct(ctx context.Context, product *models.
         &product.SKU,
         &product.StockQuantity,
         &product.ImageURL,
+        &product.Warehouse,
+        &product.FulfillmentType,
+        &product.Attributes,
+        &product.LowStockThreshold,
         &product.CreatedAt,
         &product.UpdatedAt,
     )
@@ -65,12 +102,13 @@ func (pr *ProductRepository) CreateProduct(ctx context.Context, product *models.
 // GetProduct retrieves a product by ID
 func (pr *ProductRepository) GetProduct(ctx context.Context, id int64) (*models.Product, error) {
     query := `
-        SELECT id, name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at, deleted_at
-        FROM $schema.products
-        WHERE id = $1 AND deleted_at IS NULL
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.id = $1 AND p.deleted_at IS NULL
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     product := &models.Product{}
     err := pr.conn.QueryRowContext(ctx, query, id).Scan(
@@ -82,6 +120,12 @@ func (pr *ProductRepository) GetProduct(ctx context.Context, id int64) (*models.
         &product.SKU,
         &product.StockQuantity,
         &product.ImageURL,
+        &product.Warehouse,
+        &product.FulfillmentType,
+        &product.Attributes,
+        &product.LowStockThreshold,
+        &product.AverageRating,
+        &product.ReviewCount,
         &product.CreatedAt,
         &product.UpdatedAt,
         &product.DeletedAt,
@@ -97,12 +141,13 @@ func (pr *ProductRepository) GetProduct(ctx context.Context, id int64) (*models.
 // GetProductBySKU retrieves a product by SKU
 func (pr *ProductRepository) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
     query := `
-        SELECT id, name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at, deleted_at
-        FROM $schema.products
-        WHERE sku = $1 AND deleted_at IS NULL
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.sku = $1 AND p.deleted_at IS NULL
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     product := &models.Product{}
     err := pr.conn.QueryRowContext(ctx, query, sku).Scan(
@@ -114,6 +159,12 @@ func (pr *ProductRepository) GetProductBySKU(ctx context.Context, sku string) (*
         &product.SKU,
         &product.StockQuantity,
         &product.ImageURL,
+        &product.Warehouse,
+        &product.FulfillmentType,
+        &product.Attributes,
+        &product.LowStockThreshold,
+        &product.AverageRating,
+        &product.ReviewCount,
         &product.CreatedAt,
         &product.UpdatedAt,
         &product.DeletedAt,
@@ -129,21 +180,22 @@ func (pr *ProductRepository) GetProductBySKU(ctx context.Context, sku string) (*
 // GetAllProducts retrieves all products with optional category filter
 func (pr *ProductRepository) GetAllProducts(ctx context.Context, categoryID *int64) ([]*models.Product, error) {
     query := `
-        SELECT id, name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at, deleted_at
-        FROM $schema.products
-        WHERE deleted_at IS NULL
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.deleted_at IS NULL
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     var rows interface{}
     var err error
 
     if categoryID != nil {
-        query += ` AND category_id = $1 ORDER BY created_at DESC`
+        query += ` AND p.category_id = $1 ORDER BY p.created_at DESC`
         rows, err = pr.conn.QueryContext(ctx, query, *categoryID)
     } else {
-        query += ` ORDER BY created_at DESC`
+        query += ` ORDER BY p.created_at DESC`
         rows, err = pr.conn.QueryContext(ctx, query)
     }
 
@@ -158,16 +210,160 @@ func (pr *ProductRepository) GetAllProducts(ctx context.Context, categoryID *int
     }))
 }
 
+// GetProductsInCategoryTree retrieves the products of a category and all its subcategories
+// Why: UNION (not UNION ALL) stops the recursion if parent_id ever forms a cycle
+func (pr *ProductRepository) GetProductsInCategoryTree(ctx context.Context, categoryID int64) ([]*models.Product, error) {
+    query := `
+        ` + categoryTreeCTE + `
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.deleted_at IS NULL AND p.category_id IN (SELECT id FROM tree)
+        ORDER BY p.created_at DESC
+    `
+
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, categoryID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get products in category tree: %w", err)
+    }
+
+    return scanProducts(rows)
+}
+
+// GetProductsByIDs retrieves the live products among ids, in id order
+func (pr *ProductRepository) GetProductsByIDs(ctx context.Context, ids []int64) ([]*models.Product, error) {
+    query := `
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.deleted_at IS NULL AND p.id = ANY($1)
+        ORDER BY p.id
+    `
+
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, pq.Array(ids))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get products: %w", err)
+    }
+
+    return scanProducts(rows)
+}
+
+// GetProductsByAttributes retrieves the products matching every attribute filter, optionally
+// within a category (and with includeSubcategories, all its subcategories)
+func (pr *ProductRepository) GetProductsByAttributes(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) ([]*models.Product, error) {
+    cte, where, args := productListConditions(categoryID, includeSubcategories, filters)
+
+    query := `
+        ` + cte + `
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE ` + where + `
+        ORDER BY p.created_at DESC
+    `
+
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get products by attributes: %w", err)
+    }
+
+    return scanProducts(rows)
+}
+
+// GetProductsPage retrieves one keyset page of the product list, newest first, with the same
+// filters as GetProductsByAttributes. It returns up to page.Limit() products.
+func (pr *ProductRepository) GetProductsPage(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter, page pagination.Page) ([]*models.Product, error) {
+    cte, where, args := productListConditions(categoryID, includeSubcategories, filters)
+    after, afterArgs := page.Condition("p.created_at", "p.id", true, len(args)+1)
+    args = append(args, afterArgs...)
+    args = append(args, page.Limit())
+
+    query := `
+        ` + cte + `
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE ` + where + ` AND ` + after + `
+        ORDER BY p.created_at DESC, p.id DESC
+        ` + fmt.Sprintf("LIMIT $%d", len(args))
+
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get products page: %w", err)
+    }
+
+    return scanProducts(rows)
+}
+
+// productListConditions builds the WHERE conditions of the product list: live products,
+// optionally in a category (with includeSubcategories, its whole subtree, listed by the
+// returned CTE), matching every attribute filter
+func productListConditions(categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) (string, string, []interface{}) {
+    var args []interface{}
+    cte, where := "", "p.deleted_at IS NULL"
+    if categoryID != nil {
+        args = append(args, *categoryID)
+        where += " AND p.category_id = $1"
+        if includeSubcategories {
+            cte = categoryTreeCTE
+            where = "p.deleted_at IS NULL AND p.category_id IN (SELECT id FROM tree)"
+        }
+    }
+
+    conditions, args := attributeConditions(filters, args)
+    return cte, where + conditions, args
+}
+
+// attributeConditions builds the WHERE conditions of attribute filters, numbering parameters after args
+// Why: equality uses @> so the GIN index on attributes applies; a range only matches number
+// values, and the CASE keeps the cast away from strings
+func attributeConditions(filters []models.AttributeFilter, args []interface{}) (string, []interface{}) {
+    var conditions strings.Builder
+    for _, filter := range filters {
+        args = append(args, filter.Name)
+        name := len(args)
+
+        if candidates := filter.Candidates(); len(candidates) > 0 {
+            matches := make([]string, len(candidates))
+            for i, candidate := range candidates {
+                args = append(args, candidate)
+                matches[i] = fmt.Sprintf("p.attributes @> jsonb_build_object($%d::text, $%d::jsonb)", name, len(args))
+            }
+            fmt.Fprintf(&conditions, " AND (%s)", strings.Join(matches, " OR "))
+        }
+
+        number := fmt.Sprintf("CASE WHEN jsonb_typeof(p.attributes -> $%d::text) = 'number' THEN (p.attributes ->> $%d::text)::numeric END", name, name)
+        if filter.Min != nil {
+            args = append(args, *filter.Min)
+            fmt.Fprintf(&conditions, " AND %s >= $%d::numeric", number, len(args))
+        }
+        if filter.Max != nil {
+            args = append(args, *filter.Max)
+            fmt.Fprintf(&conditions, " AND %s <= $%d::numeric", number, len(args))
+        }
+    }
+    return conditions.String(), args
+}
+
 // UpdateProduct updates a product
 func (pr *ProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
     query := `
         UPDATE $schema.products
-        SET name = $1, description = $2, price = $3, stock_quantity = $4, image_url = $5, updated_at = $6
-        WHERE id = $7 AND deleted_at IS NULL
-        RETURNING id, name, description, price, category_id, sku, stock_quantity, image_url, created_at, updated_at
+        SET name = $1, description = $2, price = $3, stock_quantity = $4, image_url = $5,
+            warehouse = $6, fulfillment_type = $7, attributes = $8, low_stock_threshold = $9, updated_at = $10
+        WHERE id = $11 AND deleted_at IS NULL
+        RETURNING id, name, description, price, category_id, sku, stock_quantity, image_url, warehouse, fulfillment_type, attributes, low_stock_threshold, created_at, updated_at
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     err := pr.conn.QueryRowContext(ctx, query,
         product.Name,
@@ -175,6 +371,10 @@ func (pr *ProductRepository) UpdateProduct(ctx context.Context, product *models.
         product.Price,
         product.StockQuantity,
         product.ImageURL,
+        product.Warehouse,
+        product.FulfillmentType,
+        product.Attributes,
+        product.LowStockThreshold,
         time.Now().UTC(),
         product.ID,
     ).Scan(
@@ -186,6 +386,10 @@ func (pr *ProductRepository) UpdateProduct(ctx context.Context, product *models.
         &product.SKU,
         &product.StockQuantity,
         &product.ImageURL,
+        &product.Warehouse,
+        &product.FulfillmentType,
+        &product.Attributes,
+        &product.LowStockThreshold,
         &product.CreatedAt,
         &product.UpdatedAt,
     )
@@ -205,7 +409,7 @@ func (pr *ProductRepository) DeleteProduct(ctx context.Context, id int64) error
         WHERE id = $3
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     result, err := pr.conn.ExecContext(ctx, query, time.Now().UTC(), time.Now().UTC(), id)
     if err != nil {
@@ -224,6 +428,61 @@ func (pr *ProductRepository) DeleteProduct(ctx context.Context, id int64) error
     return nil
 }
 
+// GetDeletedProducts retrieves soft-deleted products, most recently deleted first
+func (pr *ProductRepository) GetDeletedProducts(ctx context.Context) ([]*models.Product, error) {
+    query := `
+        SELECT ` + productColumns + `
+        FROM $schema.products p
+        ` + ratingJoin + `
+        WHERE p.deleted_at IS NOT NULL
+        ORDER BY p.deleted_at DESC
+    `
+
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get deleted products: %w", err)
+    }
+
+    return scanProducts(rows)
+}
+
+// RestoreProduct undoes a soft delete; the product keeps its stock, reviews and attributes
+func (pr *ProductRepository) RestoreProduct(ctx context.Context, id int64) error {
+    query := `
+        UPDATE $schema.products
+        SET deleted_at = NULL, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NOT NULL
+    `
+
+    query = pr.conn.Qualify(query)
+
+    result, err := pr.conn.ExecContext(ctx, query, time.Now().UTC(), id)
+    if err != nil {
+        return fmt.Errorf("failed to restore product: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rowsAffected > 0 {
+        return nil
+    }
+
+    // Nothing restored: missing, or not deleted in the first place
+    var deletedAt *time.Time
+    err = pr.conn.QueryRowContext(ctx, pr.conn.Qualify(`SELECT deleted_at FROM $schema.products WHERE id = $1`), id).Scan(&deletedAt)
+    if err == sql.ErrNoRows {
+        return ErrUnknownProduct
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get product: %w", err)
+    }
+    return ErrProductNotDeleted
+}
+
 // DecrementStock decrements product stock
 func (pr *ProductRepository) DecrementStock(ctx context.Context, productID int64, quantity int) error {
     query := `
@@ -232,7 +491,7 @@ func (pr *ProductRepository) DecrementStock(ctx context.Context, productID int64
         WHERE id = $3 AND stock_quantity >= $1 AND deleted_at IS NULL
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     result, err := pr.conn.ExecContext(ctx, query, quantity, time.Now().UTC(), productID)
     if err != nil {
@@ -259,7 +518,7 @@ func (pr *ProductRepository) IncrementStock(ctx context.Context, productID int64
         WHERE id = $3 AND deleted_at IS NULL
     `
 
-    query = replaceSchema(query, pr.conn.Schema)
+    query = pr.conn.Qualify(query)
 
     result, err := pr.conn.ExecContext(ctx, query, quantity, time.Now().UTC(), productID)
     if err != nil {
@@ -278,15 +537,6 @@ func (pr *ProductRepository) IncrementStock(ctx context.Context, productID int64
     return nil
 }
 
-// Helper function
-func replaceSchema(query, schema string) string {
-    for i := 0; i < len(query)-len("$schema"); i++ {
-        if query[i:i+len("$schema")] == "$schema" {
-            query = query[:i] + schema + query[i+len("$schema"):]
-        }
-    }
-    return query
-}
 
 func scanProducts(rows interface {
     Scan(...interface{}) error
@@ -307,6 +557,12 @@ func scanProducts(rows interface {
             &product.SKU,
             &product.StockQuantity,
             &product.ImageURL,
+            &product.Warehouse,
+            &product.FulfillmentType,
+            &product.Attributes,
+            &product.LowStockThreshold,
+            &product.AverageRating,
+            &product.ReviewCount,
             &product.CreatedAt,
             &product.UpdatedAt,
             &product.DeletedAt,
@@ -318,4 +574,4 @@ func scanProducts(rows interface {
     }
 
     return products, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,363 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrPurchaseOrderNotFound is returned when no purchase order has the given ID
+    ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+    // ErrPurchaseOrderClosed is returned when receiving or cancelling a received/cancelled PO
+    ErrPurchaseOrderClosed = errors.New("purchase order is closed")
+    // ErrPurchaseOrderReceiving is returned when cancelling a PO that already has receipts
+    ErrPurchaseOrderReceiving = errors.New("purchase order has received stock")
+    // ErrUnknownProduct is returned when a PO line or receipt references a missing product
+    ErrUnknownProduct = errors.New("product not found")
+)
+
+// PurchaseOrderRepository handles supplier purchase orders and stock receipts
+type PurchaseOrderRepository struct {
+    conn *db.Connection
+}
+
+// NewPurchaseOrderRepository creates new purchase order repository
+func NewPurchaseOrderRepository(conn *db.Connection) *PurchaseOrderRepository {
+    return &PurchaseOrderRepository{conn: conn}
+}
+
+// CreatePurchaseOrder inserts a purchase order and its lines
+func (pr *PurchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, po *models.PurchaseOrder) error {
+    tx, err := pr.conn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO $schema.purchase_orders (supplier, reference, status, expected_at, notes, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+    query = pr.conn.Qualify(query)
+
+    err = tx.QueryRowContext(ctx, query,
+        po.Supplier,
+        po.Reference,
+        po.Status,
+        po.ExpectedAt,
+        po.Notes,
+        po.CreatedAt,
+        po.UpdatedAt,
+    ).Scan(&po.ID)
+    if err != nil {
+        return fmt.Errorf("failed to create purchase order: %w", err)
+    }
+
+    lineQuery := `
+        INSERT INTO $schema.purchase_order_lines AS l (purchase_order_id, product_id, expected_quantity)
+        SELECT $1, p.id, $3
+        FROM $schema.products p
+        WHERE p.id = $2 AND p.deleted_at IS NULL
+        ON CONFLICT (purchase_order_id, product_id)
+        DO UPDATE SET expected_quantity = l.expected_quantity + EXCLUDED.expected_quantity
+        RETURNING id, expected_quantity
+    `
+    lineQuery = pr.conn.Qualify(lineQuery)
+
+    for i := range po.Lines {
+        line := &po.Lines[i]
+        line.PurchaseOrderID = po.ID
+
+        err := tx.QueryRowContext(ctx, lineQuery, po.ID, line.ProductID, line.ExpectedQuantity).
+            Scan(&line.ID, &line.ExpectedQuantity)
+        if err == sql.ErrNoRows {
+            return fmt.Errorf("%w: %d", ErrUnknownProduct, line.ProductID)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to create purchase order line: %w", err)
+        }
+        line.Discrepancy = -line.ExpectedQuantity
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit purchase order: %w", err)
+    }
+
+    return nil
+}
+
+// GetPurchaseOrder retrieves a purchase order with its lines
+func (pr *PurchaseOrderRepository) GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+    query := `
+        SELECT id, supplier, COALESCE(reference, ''), status, expected_at, COALESCE(notes, ''),
+               created_at, updated_at, received_at
+        FROM $schema.purchase_orders
+        WHERE id = $1
+    `
+    query = pr.conn.Qualify(query)
+
+    po, err := scanPurchaseOrder(pr.conn.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrPurchaseOrderNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get purchase order: %w", err)
+    }
+
+    lines, err := pr.getLines(ctx, pr.conn.DB, id)
+    if err != nil {
+        return nil, err
+    }
+    po.Lines = lines
+
+    return po, nil
+}
+
+// ListPurchaseOrders retrieves purchase orders, optionally filtered by status, newest first.
+// Lines are not loaded.
+func (pr *PurchaseOrderRepository) ListPurchaseOrders(ctx context.Context, status string) ([]*models.PurchaseOrder, error) {
+    query := `
+        SELECT id, supplier, COALESCE(reference, ''), status, expected_at, COALESCE(notes, ''),
+               created_at, updated_at, received_at
+        FROM $schema.purchase_orders
+        WHERE ($1 = '' OR status = $1)
+        ORDER BY created_at DESC
+    `
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, status)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list purchase orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders := []*models.PurchaseOrder{}
+    for rows.Next() {
+        po, err := scanPurchaseOrder(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan purchase order: %w", err)
+        }
+        orders = append(orders, po)
+    }
+
+    return orders, rows.Err()
+}
+
+// ReceivePurchaseOrder increments stock for delivered items and records the receipt
+// against the PO in one transaction. Items not on the PO get a line with zero expected
+// quantity so the over-delivery shows up as a discrepancy.
+func (pr *PurchaseOrderRepository) ReceivePurchaseOrder(ctx context.Context, id int64, req *models.ReceivePurchaseOrderRequest) (*models.PurchaseOrder, []models.ReceivedStock, error) {
+    tx, err := pr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Lock the PO so concurrent receipts serialize
+    var status string
+    lockQuery := pr.conn.Qualify(`SELECT status FROM $schema.purchase_orders WHERE id = $1 FOR UPDATE`)
+    err = tx.QueryRowContext(ctx, lockQuery, id).Scan(&status)
+    if err == sql.ErrNoRows {
+        return nil, nil, ErrPurchaseOrderNotFound
+    }
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to lock purchase order: %w", err)
+    }
+    if status == models.POStatusReceived || status == models.POStatusCancelled {
+        return nil, nil, fmt.Errorf("%w (%s)", ErrPurchaseOrderClosed, status)
+    }
+
+    now := time.Now().UTC()
+
+    stockQuery := pr.conn.Qualify(`
+        UPDATE $schema.products
+        SET stock_quantity = stock_quantity + $1, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+        RETURNING stock_quantity
+    `)
+
+    lineQuery := pr.conn.Qualify(`
+        INSERT INTO $schema.purchase_order_lines AS l (purchase_order_id, product_id, expected_quantity, received_quantity)
+        VALUES ($1, $2, 0, $3)
+        ON CONFLICT (purchase_order_id, product_id)
+        DO UPDATE SET received_quantity = l.received_quantity + EXCLUDED.received_quantity
+    `)
+
+    receiptQuery := pr.conn.Qualify(`
+        INSERT INTO $schema.purchase_order_receipts (purchase_order_id, product_id, quantity, note, received_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `)
+
+    received := make([]models.ReceivedStock, 0, len(req.Items))
+    for _, item := range req.Items {
+        stock := models.ReceivedStock{ProductID: item.ProductID, Quantity: item.Quantity}
+
+        err := tx.QueryRowContext(ctx, stockQuery, item.Quantity, now, item.ProductID).Scan(&stock.StockQuantity)
+        if err == sql.ErrNoRows {
+            return nil, nil, fmt.Errorf("%w: %d", ErrUnknownProduct, item.ProductID)
+        }
+        if err != nil {
+            return nil, nil, fmt.Errorf("failed to increment stock: %w", err)
+        }
+
+        if _, err := tx.ExecContext(ctx, lineQuery, id, item.ProductID, item.Quantity); err != nil {
+            return nil, nil, fmt.Errorf("failed to update purchase order line: %w", err)
+        }
+
+        if _, err := tx.ExecContext(ctx, receiptQuery, id, item.ProductID, item.Quantity, req.Note, now); err != nil {
+            return nil, nil, fmt.Errorf("failed to record receipt: %w", err)
+        }
+
+        received = append(received, stock)
+    }
+
+    lines, err := pr.getLines(ctx, tx, id)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    po := &models.PurchaseOrder{ID: id, Lines: lines}
+    newStatus := po.ReceivingStatus(req.Close)
+
+    updateQuery := pr.conn.Qualify(`
+        UPDATE $schema.purchase_orders
+        SET status = $1, updated_at = $2,
+            received_at = CASE WHEN $1 = 'received' THEN $2 ELSE received_at END
+        WHERE id = $3
+    `)
+    if _, err := tx.ExecContext(ctx, updateQuery, newStatus, now, id); err != nil {
+        return nil, nil, fmt.Errorf("failed to update purchase order status: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, nil, fmt.Errorf("failed to commit receipt: %w", err)
+    }
+
+    po, err = pr.GetPurchaseOrder(ctx, id)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return po, received, nil
+}
+
+// CancelPurchaseOrder cancels an open purchase order that has nothing received yet
+func (pr *PurchaseOrderRepository) CancelPurchaseOrder(ctx context.Context, id int64) error {
+    query := `
+        UPDATE $schema.purchase_orders
+        SET status = $1, updated_at = $2
+        WHERE id = $3 AND status = $4
+    `
+    query = pr.conn.Qualify(query)
+
+    result, err := pr.conn.ExecContext(ctx, query, models.POStatusCancelled, time.Now().UTC(), id, models.POStatusOpen)
+    if err != nil {
+        return fmt.Errorf("failed to cancel purchase order: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+
+    if rowsAffected == 0 {
+        po, err := pr.GetPurchaseOrder(ctx, id)
+        if err != nil {
+            return err
+        }
+        if po.Status == models.POStatusPartiallyReceived {
+            return ErrPurchaseOrderReceiving
+        }
+        return fmt.Errorf("%w (%s)", ErrPurchaseOrderClosed, po.Status)
+    }
+
+    return nil
+}
+
+// GetReceipts retrieves the deliveries recorded against a purchase order
+func (pr *PurchaseOrderRepository) GetReceipts(ctx context.Context, id int64) ([]*models.PurchaseOrderReceipt, error) {
+    query := `
+        SELECT id, purchase_order_id, product_id, quantity, COALESCE(note, ''), received_at
+        FROM $schema.purchase_order_receipts
+        WHERE purchase_order_id = $1
+        ORDER BY received_at, id
+    `
+    query = pr.conn.Qualify(query)
+
+    rows, err := pr.conn.QueryContext(ctx, query, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get receipts: %w", err)
+    }
+    defer rows.Close()
+
+    receipts := []*models.PurchaseOrderReceipt{}
+    for rows.Next() {
+        r := &models.PurchaseOrderReceipt{}
+        if err := rows.Scan(&r.ID, &r.PurchaseOrderID, &r.ProductID, &r.Quantity, &r.Note, &r.ReceivedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan receipt: %w", err)
+        }
+        receipts = append(receipts, r)
+    }
+
+    return receipts, rows.Err()
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getLines loads the lines of a purchase order and computes their discrepancies
+func (pr *PurchaseOrderRepository) getLines(ctx context.Context, q queryer, id int64) ([]models.PurchaseOrderLine, error) {
+    query := `
+        SELECT id, purchase_order_id, product_id, expected_quantity, received_quantity
+        FROM $schema.purchase_order_lines
+        WHERE purchase_order_id = $1
+        ORDER BY id
+    `
+    query = pr.conn.Qualify(query)
+
+    rows, err := q.QueryContext(ctx, query, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get purchase order lines: %w", err)
+    }
+    defer rows.Close()
+
+    lines := []models.PurchaseOrderLine{}
+    for rows.Next() {
+        var line models.PurchaseOrderLine
+        if err := rows.Scan(&line.ID, &line.PurchaseOrderID, &line.ProductID, &line.ExpectedQuantity, &line.ReceivedQuantity); err != nil {
+            return nil, fmt.Errorf("failed to scan purchase order line: %w", err)
+        }
+        line.Discrepancy = line.ReceivedQuantity - line.ExpectedQuantity
+        lines = append(lines, line)
+    }
+
+    return lines, rows.Err()
+}
+
+func scanPurchaseOrder(row interface{ Scan(...interface{}) error }) (*models.PurchaseOrder, error) {
+    po := &models.PurchaseOrder{Lines: []models.PurchaseOrderLine{}}
+    err := row.Scan(
+        &po.ID,
+        &po.Supplier,
+        &po.Reference,
+        &po.Status,
+        &po.ExpectedAt,
+        &po.Notes,
+        &po.CreatedAt,
+        &po.UpdatedAt,
+        &po.ReceivedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return po, nil
+}
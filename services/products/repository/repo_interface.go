@@ -0,0 +1,87 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+// ProductRepositoryInterface defines the contract for product repository operations
+type ProductRepositoryInterface interface {
+    CreateProduct(ctx context.Context, product *models.Product) error
+    GetProduct(ctx context.Context, id int64) (*models.Product, error)
+    GetProductBySKU(ctx context.Context, sku string) (*models.Product, error)
+    GetAllProducts(ctx context.Context, categoryID *int64) ([]*models.Product, error)
+    GetProductsInCategoryTree(ctx context.Context, categoryID int64) ([]*models.Product, error)
+    GetProductsByIDs(ctx context.Context, ids []int64) ([]*models.Product, error)
+    GetProductsByAttributes(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter) ([]*models.Product, error)
+    GetProductsPage(ctx context.Context, categoryID *int64, includeSubcategories bool, filters []models.AttributeFilter, page pagination.Page) ([]*models.Product, error)
+    UpdateProduct(ctx context.Context, product *models.Product) error
+    DeleteProduct(ctx context.Context, id int64) error
+    GetDeletedProducts(ctx context.Context) ([]*models.Product, error)
+    RestoreProduct(ctx context.Context, id int64) error
+    DecrementStock(ctx context.Context, productID int64, quantity int) error
+    IncrementStock(ctx context.Context, productID int64, quantity int) error
+}
+
+// CategoryRepositoryInterface defines the contract for category repository operations
+type CategoryRepositoryInterface interface {
+    CreateCategory(ctx context.Context, category *models.Category) error
+    GetCategory(ctx context.Context, id int64) (*models.Category, error)
+    GetAllCategories(ctx context.Context) ([]*models.Category, error)
+    GetCategoriesPage(ctx context.Context, page pagination.Page) ([]*models.Category, error)
+    GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error)
+    GetProductCounts(ctx context.Context) (map[int64]int, error)
+    UpdateCategory(ctx context.Context, category *models.Category) error
+    DeleteCategory(ctx context.Context, id int64) error
+    MergeCategory(ctx context.Context, sourceID, targetID int64, templates []string) (productIDs, subcategoryIDs []int64, err error)
+    AssignProducts(ctx context.Context, categoryID int64, productIDs []int64) ([]int64, error)
+}
+
+// VariantRepositoryInterface defines the contract for variant repository operations
+type VariantRepositoryInterface interface {
+    CreateVariant(ctx context.Context, variant *models.ProductVariant) error
+    GetVariant(ctx context.Context, productID, variantID int64) (*models.ProductVariant, error)
+    GetVariantsByProduct(ctx context.Context, productID int64) ([]*models.ProductVariant, error)
+}
+
+// AttributeTemplateRepositoryInterface defines the contract for attribute template operations
+type AttributeTemplateRepositoryInterface interface {
+    CreateTemplate(ctx context.Context, template *models.AttributeTemplate) error
+    GetTemplate(ctx context.Context, id int64) (*models.AttributeTemplate, error)
+    GetTemplatesForCategory(ctx context.Context, categoryID int64) ([]*models.AttributeTemplate, error)
+    UpdateTemplate(ctx context.Context, template *models.AttributeTemplate) error
+    DeleteTemplate(ctx context.Context, id int64) error
+}
+
+// InventoryReservationRepositoryInterface defines the contract for stock reservations: order
+// reservations, cart soft locks, channel reservations and admin holds
+type InventoryReservationRepositoryInterface interface {
+    CreateReservation(ctx context.Context, reservation *models.InventoryReservation) error
+    GetReservation(ctx context.Context, reservationID string) (*models.InventoryReservation, error)
+    GetReservationsByOrderID(ctx context.Context, orderID int64) ([]*models.InventoryReservation, error)
+    ReleaseReservation(ctx context.Context, reservationID string) error
+    ExpireReservations(ctx context.Context) (int64, error)
+    GetProductReservations(ctx context.Context, productID int64) (int, error)
+    GetVariantReservations(ctx context.Context, variantID int64) (int, error)
+    UpdateReservationStatusByOrderID(ctx context.Context, orderID string, status string) error
+    UpdateReservationStatus(ctx context.Context, orderID int64, status string) error
+    ReserveForOrder(ctx context.Context, orderID int64, cartID string, lines []models.OrderLine, ttl time.Duration) ([]*models.InventoryReservation, error)
+    CommitOrderReservations(ctx context.Context, orderID int64) (int, error)
+    ExtendOrderReservations(ctx context.Context, orderID int64, until time.Time) (int, error)
+    LockForCart(ctx context.Context, cartID string, productID int64, variantID *int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error)
+    ReleaseCartLock(ctx context.Context, cartID, reservationID string) error
+    ReleaseCartLocks(ctx context.Context, cartID string) (int64, error)
+    ReserveForChannel(ctx context.Context, channel *models.SalesChannel, req *models.ChannelReserveRequest) (*models.ChannelReservation, bool, error)
+    GetChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    ExtendChannelReservation(ctx context.Context, channelID, reservationID string, hold time.Duration) (*models.ChannelReservation, error)
+    CommitChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    ReleaseChannelReservation(ctx context.Context, channelID, reservationID string) (*models.ChannelReservation, error)
+    GetChannelReport(ctx context.Context, channel *models.SalesChannel, from, to time.Time) (*models.ChannelReport, error)
+    GetProductMovements(ctx context.Context, productID int64, variantID *int64, limit int) ([]*models.InventoryMovement, error)
+    AdjustStock(ctx context.Context, productID int64, req *models.StockAdjustmentRequest, actorID string) (*models.InventoryMovement, error)
+    HoldStock(ctx context.Context, productID int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error)
+    ReleaseHeldStock(ctx context.Context, productID int64, quantity int) (int, error)
+}
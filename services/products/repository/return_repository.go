@@ -0,0 +1,341 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrReturnNotFound is returned when no return has the given ID
+    ErrReturnNotFound = errors.New("return not found")
+    // ErrInvalidReturnTransition is returned when inspecting or dispositioning a return in the wrong state
+    ErrInvalidReturnTransition = errors.New("return can't move to that state")
+)
+
+const returnColumns = `id, order_id, product_id, quantity, COALESCE(reason, ''), status, COALESCE(condition, ''),
+    COALESCE(inspection_notes, ''), inspected_at, COALESCE(disposition_note, ''), received_at, updated_at, closed_at`
+
+// ReturnRepository handles customer returns and their stock ledger entries
+type ReturnRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewReturnRepository creates new return repository
+func NewReturnRepository(conn *db.Connection, clk clock.Clock) *ReturnRepository {
+    return &ReturnRepository{conn: conn, clock: clk}
+}
+
+// CreateReturn records returned units as received; stock is untouched until disposition
+func (rr *ReturnRepository) CreateReturn(ctx context.Context, req *models.CreateReturnRequest) (*models.ProductReturn, error) {
+    now := rr.clock.Now()
+    query := rr.conn.Qualify(`
+        INSERT INTO $schema.product_returns (order_id, product_id, quantity, reason, status, received_at, updated_at)
+        SELECT $1, p.id, $3, $4, 'received', $5, $5
+        FROM $schema.products p
+        WHERE p.id = $2 AND p.deleted_at IS NULL
+        RETURNING ` + returnColumns)
+
+    ret, err := scanReturn(rr.conn.QueryRowContext(ctx, query, req.OrderID, req.ProductID, req.Quantity, req.Reason, now))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, req.ProductID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to create return: %w", err)
+    }
+    return ret, nil
+}
+
+// GetReturn retrieves a return by ID
+func (rr *ReturnRepository) GetReturn(ctx context.Context, id int64) (*models.ProductReturn, error) {
+    query := rr.conn.Qualify(`SELECT ` + returnColumns + ` FROM $schema.product_returns WHERE id = $1`)
+
+    ret, err := scanReturn(rr.conn.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrReturnNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get return: %w", err)
+    }
+    return ret, nil
+}
+
+// ListReturns lists returns newest first, optionally filtered by status and product
+func (rr *ReturnRepository) ListReturns(ctx context.Context, status string, productID *int64) ([]*models.ProductReturn, error) {
+    query := rr.conn.Qualify(`
+        SELECT ` + returnColumns + `
+        FROM $schema.product_returns
+        WHERE ($1 = '' OR status = $1) AND ($2::BIGINT IS NULL OR product_id = $2)
+        ORDER BY received_at DESC
+    `)
+
+    rows, err := rr.conn.QueryContext(ctx, query, status, productID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list returns: %w", err)
+    }
+    defer rows.Close()
+
+    returns := []*models.ProductReturn{}
+    for rows.Next() {
+        ret, err := scanReturn(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan return: %w", err)
+        }
+        returns = append(returns, ret)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read returns: %w", err)
+    }
+    return returns, nil
+}
+
+// InspectReturn records the condition of the returned units. Re-inspecting before
+// disposition replaces the earlier condition and notes.
+func (rr *ReturnRepository) InspectReturn(ctx context.Context, id int64, req *models.InspectReturnRequest) (*models.ProductReturn, error) {
+    tx, err := rr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    ret, err := rr.lockReturn(ctx, tx, id)
+    if err != nil {
+        return nil, err
+    }
+    if !ret.CanInspect() {
+        return nil, fmt.Errorf("%w: %s return can't be inspected", ErrInvalidReturnTransition, ret.Status)
+    }
+
+    now := rr.clock.Now()
+    query := rr.conn.Qualify(`
+        UPDATE $schema.product_returns
+        SET status = 'inspected', condition = $1, inspection_notes = $2, inspected_at = $3, updated_at = $3
+        WHERE id = $4
+        RETURNING ` + returnColumns)
+
+    ret, err = scanReturn(tx.QueryRowContext(ctx, query, req.Condition, req.Notes, now, id))
+    if err != nil {
+        return nil, fmt.Errorf("failed to inspect return: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit inspection: %w", err)
+    }
+    return ret, nil
+}
+
+// DispositionReturn applies a disposition: the return's status, its ledger entry and any
+// stock change are written in one transaction. It returns the product's stock afterwards.
+func (rr *ReturnRepository) DispositionReturn(ctx context.Context, id int64, req *models.DispositionReturnRequest) (*models.ProductReturn, *models.StockLedgerEntry, int, error) {
+    tx, err := rr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    ret, err := rr.lockReturn(ctx, tx, id)
+    if err != nil {
+        return nil, nil, 0, err
+    }
+    if !ret.CanDisposition(req.Disposition) {
+        return nil, nil, 0, fmt.Errorf("%w: %s return can't be dispositioned as %s", ErrInvalidReturnTransition, ret.Status, req.Disposition)
+    }
+
+    status, entryType, stockDelta := models.DispositionEffect(req.Disposition, ret.Quantity)
+    now := rr.clock.Now()
+
+    // Lock the product row even when stock doesn't change, so the reported stock is consistent
+    var stock int
+    stockQuery := rr.conn.Qualify(`
+        UPDATE $schema.products
+        SET stock_quantity = stock_quantity + $1,
+            updated_at = CASE WHEN $1 <> 0 THEN $2 ELSE updated_at END
+        WHERE id = $3
+        RETURNING stock_quantity
+    `)
+    if err := tx.QueryRowContext(ctx, stockQuery, stockDelta, now, ret.ProductID).Scan(&stock); err != nil {
+        return nil, nil, 0, fmt.Errorf("failed to update stock: %w", err)
+    }
+
+    entry := &models.StockLedgerEntry{
+        ProductID:  ret.ProductID,
+        ReturnID:   &ret.ID,
+        EntryType:  entryType,
+        Quantity:   ret.Quantity,
+        StockDelta: stockDelta,
+        Note:       req.Note,
+        CreatedAt:  now,
+    }
+    ledgerQuery := rr.conn.Qualify(`
+        INSERT INTO $schema.stock_ledger (product_id, return_id, entry_type, quantity, stock_delta, note, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `)
+    err = tx.QueryRowContext(ctx, ledgerQuery,
+        entry.ProductID,
+        entry.ReturnID,
+        entry.EntryType,
+        entry.Quantity,
+        entry.StockDelta,
+        entry.Note,
+        entry.CreatedAt,
+    ).Scan(&entry.ID)
+    if err != nil {
+        return nil, nil, 0, fmt.Errorf("failed to record ledger entry: %w", err)
+    }
+
+    updateQuery := rr.conn.Qualify(`
+        UPDATE $schema.product_returns
+        SET status = $1, disposition_note = $2, updated_at = $3,
+            closed_at = CASE WHEN $1 IN ('restocked', 'written_off') THEN $3 ELSE closed_at END
+        WHERE id = $4
+        RETURNING ` + returnColumns)
+    ret, err = scanReturn(tx.QueryRowContext(ctx, updateQuery, status, req.Note, now, id))
+    if err != nil {
+        return nil, nil, 0, fmt.Errorf("failed to update return: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, nil, 0, fmt.Errorf("failed to commit disposition: %w", err)
+    }
+    return ret, entry, stock, nil
+}
+
+// GetLedger returns the ledger entries of a return, oldest first
+func (rr *ReturnRepository) GetLedger(ctx context.Context, returnID int64) ([]*models.StockLedgerEntry, error) {
+    query := rr.conn.Qualify(`
+        SELECT id, product_id, return_id, entry_type, quantity, stock_delta, COALESCE(note, ''), created_at
+        FROM $schema.stock_ledger
+        WHERE return_id = $1
+        ORDER BY created_at, id
+    `)
+
+    rows, err := rr.conn.QueryContext(ctx, query, returnID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get ledger: %w", err)
+    }
+    defer rows.Close()
+
+    entries := []*models.StockLedgerEntry{}
+    for rows.Next() {
+        entry := &models.StockLedgerEntry{}
+        if err := rows.Scan(&entry.ID, &entry.ProductID, &entry.ReturnID, &entry.EntryType,
+            &entry.Quantity, &entry.StockDelta, &entry.Note, &entry.CreatedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+        }
+        entries = append(entries, entry)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read ledger: %w", err)
+    }
+    return entries, nil
+}
+
+// GetReturnRateReport totals units returned in [from, to) per product and status against
+// units sold in the same period. Sold units are committed reservations (orders and channels).
+func (rr *ReturnRepository) GetReturnRateReport(ctx context.Context, from, to time.Time) (*models.ReturnRateReport, error) {
+    rates := make(map[int64]*models.ReturnRate)
+    rate := func(productID int64) *models.ReturnRate {
+        if r, ok := rates[productID]; ok {
+            return r
+        }
+        r := &models.ReturnRate{ProductID: productID, ByStatus: map[string]int{}}
+        rates[productID] = r
+        return r
+    }
+
+    returnedQuery := rr.conn.Qualify(`
+        SELECT product_id, status, SUM(quantity)
+        FROM $schema.product_returns
+        WHERE received_at >= $1 AND received_at < $2
+        GROUP BY product_id, status
+    `)
+    rows, err := rr.conn.QueryContext(ctx, returnedQuery, from, to)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get returned units: %w", err)
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var productID int64
+        var status string
+        var units int
+        if err := rows.Scan(&productID, &status, &units); err != nil {
+            return nil, fmt.Errorf("failed to scan returned units: %w", err)
+        }
+        r := rate(productID)
+        r.ReturnedUnits += units
+        r.ByStatus[status] = units
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read returned units: %w", err)
+    }
+
+    soldQuery := rr.conn.Qualify(`
+        SELECT product_id, SUM(quantity)
+        FROM $schema.inventory_reservations
+        WHERE status = 'committed' AND committed_at >= $1 AND committed_at < $2
+        GROUP BY product_id
+    `)
+    soldRows, err := rr.conn.QueryContext(ctx, soldQuery, from, to)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get sold units: %w", err)
+    }
+    defer soldRows.Close()
+    for soldRows.Next() {
+        var productID int64
+        var units int
+        if err := soldRows.Scan(&productID, &units); err != nil {
+            return nil, fmt.Errorf("failed to scan sold units: %w", err)
+        }
+        rate(productID).SoldUnits = units
+    }
+    if err := soldRows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read sold units: %w", err)
+    }
+
+    return models.NewReturnRateReport(from, to, rates), nil
+}
+
+// lockReturn loads a return FOR UPDATE so transitions on the same return serialize
+func (rr *ReturnRepository) lockReturn(ctx context.Context, tx *sql.Tx, id int64) (*models.ProductReturn, error) {
+    query := rr.conn.Qualify(`SELECT ` + returnColumns + ` FROM $schema.product_returns WHERE id = $1 FOR UPDATE`)
+
+    ret, err := scanReturn(tx.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrReturnNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock return: %w", err)
+    }
+    return ret, nil
+}
+
+func scanReturn(row interface{ Scan(...interface{}) error }) (*models.ProductReturn, error) {
+    ret := &models.ProductReturn{}
+    err := row.Scan(
+        &ret.ID,
+        &ret.OrderID,
+        &ret.ProductID,
+        &ret.Quantity,
+        &ret.Reason,
+        &ret.Status,
+        &ret.Condition,
+        &ret.InspectionNotes,
+        &ret.InspectedAt,
+        &ret.DispositionNote,
+        &ret.ReceivedAt,
+        &ret.UpdatedAt,
+        &ret.ClosedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return ret, nil
+}
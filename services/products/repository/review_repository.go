@@ -0,0 +1,208 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/pagination"
+)
+
+var (
+    // ErrReviewNotFound is returned when no review has the given ID
+    ErrReviewNotFound = errors.New("review not found")
+    // ErrDuplicateReview is returned when the user already reviewed the product
+    ErrDuplicateReview = errors.New("user already reviewed this product")
+)
+
+// reviewColumns are the review fields read by scanReview
+const reviewColumns = `id, product_id, user_id, rating, body, status, moderation_note, created_at, updated_at, moderated_at`
+
+// ReviewRepository handles product review database operations
+type ReviewRepository struct {
+    conn *db.Connection
+}
+
+// NewReviewRepository creates new review repository
+func NewReviewRepository(conn *db.Connection) *ReviewRepository {
+    return &ReviewRepository{conn: conn}
+}
+
+// CreateReview inserts a review; one review per user and product
+func (rr *ReviewRepository) CreateReview(ctx context.Context, review *models.Review) error {
+    query := `
+        INSERT INTO $schema.product_reviews (product_id, user_id, rating, body, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (product_id, user_id) DO NOTHING
+        RETURNING id
+    `
+
+    query = rr.conn.Qualify(query)
+
+    err := rr.conn.QueryRowContext(ctx, query,
+        review.ProductID,
+        review.UserID,
+        review.Rating,
+        review.Text,
+        review.Status,
+        review.CreatedAt,
+        review.UpdatedAt,
+    ).Scan(&review.ID)
+
+    if err == sql.ErrNoRows {
+        return ErrDuplicateReview
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create review: %w", err)
+    }
+
+    return nil
+}
+
+// GetReviewsByProduct returns one page of a product's reviews with the given status, newest first
+func (rr *ReviewRepository) GetReviewsByProduct(ctx context.Context, productID int64, status string, page, limit int) ([]*models.Review, int, error) {
+    total, err := rr.countReviews(ctx, productID, status)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    query := `
+        SELECT ` + reviewColumns + `
+        FROM $schema.product_reviews
+        WHERE product_id = $1 AND status = $2
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3 OFFSET $4
+    `
+    query = rr.conn.Qualify(query)
+
+    rows, err := rr.conn.QueryContext(ctx, query, productID, status, limit, (page-1)*limit)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to get reviews: %w", err)
+    }
+
+    reviews, err := scanReviews(rows)
+    return reviews, total, err
+}
+
+// GetReviewsPageByProduct returns one keyset page of a product's reviews with the given status,
+// newest first, and how many reviews have that status. It returns up to page.Limit() reviews.
+func (rr *ReviewRepository) GetReviewsPageByProduct(ctx context.Context, productID int64, status string, page pagination.Page) ([]*models.Review, int, error) {
+    total, err := rr.countReviews(ctx, productID, status)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    after, afterArgs := page.Condition("created_at", "id", true, 3)
+    args := append([]interface{}{productID, status}, afterArgs...)
+    args = append(args, page.Limit())
+
+    query := `
+        SELECT ` + reviewColumns + `
+        FROM $schema.product_reviews
+        WHERE product_id = $1 AND status = $2 AND ` + after + `
+        ORDER BY created_at DESC, id DESC
+        ` + fmt.Sprintf("LIMIT $%d", len(args))
+    query = rr.conn.Qualify(query)
+
+    rows, err := rr.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to get reviews page: %w", err)
+    }
+
+    reviews, err := scanReviews(rows)
+    return reviews, total, err
+}
+
+// countReviews counts a product's reviews with the given status
+func (rr *ReviewRepository) countReviews(ctx context.Context, productID int64, status string) (int, error) {
+    query := `
+        SELECT COUNT(*)
+        FROM $schema.product_reviews
+        WHERE product_id = $1 AND status = $2
+    `
+    query = rr.conn.Qualify(query)
+
+    var total int
+    if err := rr.conn.QueryRowContext(ctx, query, productID, status).Scan(&total); err != nil {
+        return 0, fmt.Errorf("failed to count reviews: %w", err)
+    }
+    return total, nil
+}
+
+// scanReviews reads every row and closes rows
+func scanReviews(rows *sql.Rows) ([]*models.Review, error) {
+    defer rows.Close()
+
+    reviews := []*models.Review{}
+    for rows.Next() {
+        review, err := scanReview(rows)
+        if err != nil {
+            return nil, err
+        }
+        reviews = append(reviews, review)
+    }
+
+    return reviews, rows.Err()
+}
+
+// GetRatingSummary aggregates a product's approved reviews
+func (rr *ReviewRepository) GetRatingSummary(ctx context.Context, productID int64) (models.RatingSummary, error) {
+    query := `
+        SELECT COALESCE(ROUND(AVG(rating), 2), 0)::float8, COUNT(*)
+        FROM $schema.product_reviews
+        WHERE product_id = $1 AND status = 'approved'
+    `
+    query = rr.conn.Qualify(query)
+
+    var summary models.RatingSummary
+    if err := rr.conn.QueryRowContext(ctx, query, productID).Scan(&summary.AverageRating, &summary.ReviewCount); err != nil {
+        return summary, fmt.Errorf("failed to get rating summary: %w", err)
+    }
+
+    return summary, nil
+}
+
+// ModerateReview sets a review's moderation status
+func (rr *ReviewRepository) ModerateReview(ctx context.Context, id int64, status, note string) (*models.Review, error) {
+    query := `
+        UPDATE $schema.product_reviews
+        SET status = $1, moderation_note = NULLIF($2, ''), moderated_at = $3, updated_at = $3
+        WHERE id = $4
+        RETURNING ` + reviewColumns + `
+    `
+    query = rr.conn.Qualify(query)
+
+    review, err := scanReview(rr.conn.QueryRowContext(ctx, query, status, note, time.Now().UTC(), id))
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrReviewNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    return review, nil
+}
+
+func scanReview(row interface{ Scan(...interface{}) error }) (*models.Review, error) {
+    review := &models.Review{}
+    err := row.Scan(
+        &review.ID,
+        &review.ProductID,
+        &review.UserID,
+        &review.Rating,
+        &review.Text,
+        &review.Status,
+        &review.ModerationNote,
+        &review.CreatedAt,
+        &review.UpdatedAt,
+        &review.ModeratedAt,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("failed to scan review: %w", err)
+    }
+    return review, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// Admin holds are reservations that belong to no order, cart or channel
+const stockHoldFilter = `order_id = 0 AND cart_id IS NULL AND channel_id IS NULL`
+
+// HoldStock sets quantity units of a product aside by hand (e.g. for a store display or a
+// phone order), checked under the product row lock like LockForCart. The hold is an ordinary
+// reservation: it counts against available stock and the reservation expiry worker expires it
+// after ttl.
+func (ir *InventoryReservationRepository) HoldStock(ctx context.Context, productID int64, quantity int, ttl time.Duration) (*models.InventoryReservation, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var stock int
+    lockProduct := ir.conn.Qualify(`SELECT stock_quantity FROM $schema.products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+    err = tx.QueryRowContext(ctx, lockProduct, productID).Scan(&stock)
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, productID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    var reserved int
+    reservedQuery := ir.conn.Qualify(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM $schema.inventory_reservations
+        WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved'
+    `)
+    if err := tx.QueryRowContext(ctx, reservedQuery, productID).Scan(&reserved); err != nil {
+        return nil, fmt.Errorf("failed to get product reservations: %w", err)
+    }
+    if available := stock - reserved; available < quantity {
+        return nil, &StockShortageError{ProductID: productID, Requested: quantity, Available: available}
+    }
+
+    now := ir.clock.Now()
+    insertQuery := ir.conn.Qualify(`
+        INSERT INTO $schema.inventory_reservations
+        (product_id, quantity, order_id, reservation_id, status, created_at, expires_at, updated_at)
+        VALUES ($1, $2, 0, $3, 'reserved', $4, $5, $4)
+        RETURNING ` + orderReservationColumns)
+
+    reservation, err := scanOrderReservation(tx.QueryRowContext(ctx, insertQuery,
+        productID,
+        quantity,
+        uuid.New().String(),
+        now,
+        now.Add(ttl),
+    ))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create stock hold: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to commit stock hold: %w", err)
+    }
+
+    return reservation, nil
+}
+
+// ReleaseHeldStock gives back up to quantity units of a product's admin holds, oldest first,
+// shrinking the last hold it touches rather than releasing more than asked.
+// Returns how many units were released; with nothing held that is 0.
+func (ir *InventoryReservationRepository) ReleaseHeldStock(ctx context.Context, productID int64, quantity int) (int, error) {
+    tx, err := ir.conn.BeginTx(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    holdsQuery := ir.conn.Qualify(`
+        SELECT id, quantity
+        FROM $schema.inventory_reservations
+        WHERE product_id = $1 AND variant_id IS NULL AND status = 'reserved' AND ` + stockHoldFilter + `
+        ORDER BY created_at, id
+        FOR UPDATE
+    `)
+    rows, err := tx.QueryContext(ctx, holdsQuery, productID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get stock holds: %w", err)
+    }
+
+    type hold struct {
+        id       int64
+        quantity int
+    }
+    var holds []hold
+    for rows.Next() {
+        var h hold
+        if err := rows.Scan(&h.id, &h.quantity); err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("failed to scan stock hold: %w", err)
+        }
+        holds = append(holds, h)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("failed to read stock holds: %w", err)
+    }
+
+    now := ir.clock.Now()
+    releaseQuery := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET status = 'released', released_at = $1, updated_at = $1
+        WHERE id = $2
+    `)
+    shrinkQuery := ir.conn.Qualify(`
+        UPDATE $schema.inventory_reservations
+        SET quantity = quantity - $1, updated_at = $2
+        WHERE id = $3
+    `)
+
+    released := 0
+    for _, h := range holds {
+        remaining := quantity - released
+        if remaining == 0 {
+            break
+        }
+        if h.quantity <= remaining {
+            if _, err := tx.ExecContext(ctx, releaseQuery, now, h.id); err != nil {
+                return 0, fmt.Errorf("failed to release stock hold: %w", err)
+            }
+            released += h.quantity
+            continue
+        }
+        if _, err := tx.ExecContext(ctx, shrinkQuery, remaining, now, h.id); err != nil {
+            return 0, fmt.Errorf("failed to shrink stock hold: %w", err)
+        }
+        released += remaining
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit stock release: %w", err)
+    }
+
+    return released, nil
+}
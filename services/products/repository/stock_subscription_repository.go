@@ -0,0 +1,150 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrProductInStock is returned when subscribing to a product that can be bought now
+    ErrProductInStock = errors.New("product is in stock")
+    // ErrSubscriberCapReached is returned when a product already has the maximum waiting subscribers
+    ErrSubscriberCapReached = errors.New("product has too many subscribers")
+)
+
+// StockSubscriptionRepository handles back-in-stock subscriptions
+type StockSubscriptionRepository struct {
+    conn  *db.Connection
+    clock clock.Clock
+}
+
+// NewStockSubscriptionRepository creates new stock subscription repository
+func NewStockSubscriptionRepository(conn *db.Connection, clk clock.Clock) *StockSubscriptionRepository {
+    return &StockSubscriptionRepository{conn: conn, clock: clk}
+}
+
+// Subscribe adds the user to the product's waiting subscribers. A user already waiting gets
+// their existing subscription back (created is false) and doesn't count twice against the cap.
+func (sr *StockSubscriptionRepository) Subscribe(ctx context.Context, productID int64, userID string, maxSubscribers int) (*models.StockSubscription, bool, error) {
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Lock the product row: it serializes the cap check, and a restock can't slip in between
+    // the stock check and the insert
+    var stock int
+    productQuery := sr.conn.Qualify(`
+        SELECT stock_quantity FROM $schema.products
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `)
+    err = tx.QueryRowContext(ctx, productQuery, productID).Scan(&stock)
+    if err == sql.ErrNoRows {
+        return nil, false, fmt.Errorf("%w: %d", ErrUnknownProduct, productID)
+    }
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to lock product: %w", err)
+    }
+
+    sub := &models.StockSubscription{ProductID: productID, UserID: userID}
+    existingQuery := sr.conn.Qualify(`
+        SELECT id, created_at FROM $schema.stock_subscriptions
+        WHERE product_id = $1 AND user_id = $2 AND notified_at IS NULL
+    `)
+    err = tx.QueryRowContext(ctx, existingQuery, productID, userID).Scan(&sub.ID, &sub.CreatedAt)
+    if err == nil {
+        return sub, false, nil
+    }
+    if err != sql.ErrNoRows {
+        return nil, false, fmt.Errorf("failed to get subscription: %w", err)
+    }
+
+    if stock > 0 {
+        return nil, false, ErrProductInStock
+    }
+
+    var waiting int
+    countQuery := sr.conn.Qualify(`
+        SELECT COUNT(*) FROM $schema.stock_subscriptions
+        WHERE product_id = $1 AND notified_at IS NULL
+    `)
+    if err := tx.QueryRowContext(ctx, countQuery, productID).Scan(&waiting); err != nil {
+        return nil, false, fmt.Errorf("failed to count subscriptions: %w", err)
+    }
+    if waiting >= maxSubscribers {
+        return nil, false, fmt.Errorf("%w (limit %d)", ErrSubscriberCapReached, maxSubscribers)
+    }
+
+    sub.CreatedAt = sr.clock.Now()
+    insertQuery := sr.conn.Qualify(`
+        INSERT INTO $schema.stock_subscriptions (product_id, user_id, created_at)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `)
+    if err := tx.QueryRowContext(ctx, insertQuery, productID, userID, sub.CreatedAt).Scan(&sub.ID); err != nil {
+        return nil, false, fmt.Errorf("failed to create subscription: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return sub, true, nil
+}
+
+// NotifySubscribers hands the product's waiting subscribers to notify and marks them notified.
+// The rows stay locked until notify returns: concurrent restocks each get different
+// subscribers, and when notify fails nobody is marked, so the next restock tries again.
+// Returns how many subscribers were notified.
+func (sr *StockSubscriptionRepository) NotifySubscribers(ctx context.Context, productID int64, notify func(userIDs []string) error) (int, error) {
+    tx, err := sr.conn.BeginTx(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := sr.conn.Qualify(`
+        UPDATE $schema.stock_subscriptions
+        SET notified_at = $1
+        WHERE product_id = $2 AND notified_at IS NULL
+        RETURNING user_id
+    `)
+
+    rows, err := tx.QueryContext(ctx, query, sr.clock.Now(), productID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to claim subscriptions: %w", err)
+    }
+
+    var userIDs []string
+    for rows.Next() {
+        var userID string
+        if err := rows.Scan(&userID); err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("failed to scan subscription: %w", err)
+        }
+        userIDs = append(userIDs, userID)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("failed to claim subscriptions: %w", err)
+    }
+
+    if len(userIDs) == 0 {
+        return 0, nil
+    }
+    if err := notify(userIDs); err != nil {
+        return 0, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return len(userIDs), nil
+}
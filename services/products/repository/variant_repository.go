@@ -0,0 +1,148 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrVariantNotFound is returned when the product has no variant with the given ID
+    ErrVariantNotFound = errors.New("variant not found")
+    // ErrDuplicateVariantSKU is returned when another variant already uses the SKU
+    ErrDuplicateVariantSKU = errors.New("variant sku already exists")
+)
+
+// variantColumns are the variant fields read with the product's price as the fallback price
+const variantColumns = `v.id, v.product_id, v.sku, v.attributes, v.price_override, COALESCE(v.price_override, p.price),
+        v.stock_quantity, v.created_at, v.updated_at, v.deleted_at`
+
+// VariantRepository handles product variant database operations
+type VariantRepository struct {
+    conn *db.Connection
+}
+
+// NewVariantRepository creates new variant repository
+func NewVariantRepository(conn *db.Connection) *VariantRepository {
+    return &VariantRepository{conn: conn}
+}
+
+// CreateVariant inserts a variant; variant SKUs are unique
+func (vr *VariantRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant) error {
+    attributes, err := json.Marshal(variant.Attributes)
+    if err != nil {
+        return fmt.Errorf("failed to encode variant attributes: %w", err)
+    }
+
+    query := `
+        INSERT INTO $schema.product_variants (product_id, sku, attributes, price_override, stock_quantity, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (sku) DO NOTHING
+        RETURNING id
+    `
+
+    query = vr.conn.Qualify(query)
+
+    err = vr.conn.QueryRowContext(ctx, query,
+        variant.ProductID,
+        variant.SKU,
+        attributes,
+        variant.PriceOverride,
+        variant.StockQuantity,
+        variant.CreatedAt,
+        variant.UpdatedAt,
+    ).Scan(&variant.ID)
+
+    if err == sql.ErrNoRows {
+        return ErrDuplicateVariantSKU
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create variant: %w", err)
+    }
+
+    return nil
+}
+
+// GetVariant retrieves a variant of a product
+func (vr *VariantRepository) GetVariant(ctx context.Context, productID, variantID int64) (*models.ProductVariant, error) {
+    query := `
+        SELECT ` + variantColumns + `
+        FROM $schema.product_variants v
+        JOIN $schema.products p ON p.id = v.product_id
+        WHERE v.id = $1 AND v.product_id = $2 AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+    `
+
+    query = vr.conn.Qualify(query)
+
+    variant, err := scanVariant(vr.conn.QueryRowContext(ctx, query, variantID, productID))
+    if err == sql.ErrNoRows {
+        return nil, ErrVariantNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get variant: %w", err)
+    }
+
+    return variant, nil
+}
+
+// GetVariantsByProduct retrieves the variants of a product, oldest first
+func (vr *VariantRepository) GetVariantsByProduct(ctx context.Context, productID int64) ([]*models.ProductVariant, error) {
+    query := `
+        SELECT ` + variantColumns + `
+        FROM $schema.product_variants v
+        JOIN $schema.products p ON p.id = v.product_id
+        WHERE v.product_id = $1 AND v.deleted_at IS NULL
+        ORDER BY v.id ASC
+    `
+
+    query = vr.conn.Qualify(query)
+
+    rows, err := vr.conn.QueryContext(ctx, query, productID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get variants: %w", err)
+    }
+    defer rows.Close()
+
+    variants := []*models.ProductVariant{}
+    for rows.Next() {
+        variant, err := scanVariant(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan variant: %w", err)
+        }
+        variants = append(variants, variant)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read variants: %w", err)
+    }
+
+    return variants, nil
+}
+
+func scanVariant(row interface{ Scan(...interface{}) error }) (*models.ProductVariant, error) {
+    variant := &models.ProductVariant{}
+    var attributes []byte
+    err := row.Scan(
+        &variant.ID,
+        &variant.ProductID,
+        &variant.SKU,
+        &attributes,
+        &variant.PriceOverride,
+        &variant.Price,
+        &variant.StockQuantity,
+        &variant.CreatedAt,
+        &variant.UpdatedAt,
+        &variant.DeletedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(attributes, &variant.Attributes); err != nil {
+        return nil, fmt.Errorf("failed to decode variant attributes: %w", err)
+    }
+    return variant, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+// LocalStore keeps files on the local disk; the service serves them itself (see main.go)
+type LocalStore struct {
+    dir     string
+    baseURL string
+}
+
+// NewLocalStore creates new local disk store
+func NewLocalStore(dir, baseURL string) *LocalStore {
+    return &LocalStore{dir: dir, baseURL: baseURL}
+}
+
+// Put writes the file to a temporary name and renames it, so it's never served half written
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+    path := filepath.Join(s.dir, filepath.FromSlash(key))
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return fmt.Errorf("failed to create image directory: %w", err)
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+    if err != nil {
+        return fmt.Errorf("failed to create image file: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return fmt.Errorf("failed to write image: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("failed to write image: %w", err)
+    }
+    if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+        return fmt.Errorf("failed to write image: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return fmt.Errorf("failed to store image: %w", err)
+    }
+    return nil
+}
+
+// Delete removes the file; a missing file is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+    err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+        return fmt.Errorf("failed to delete image: %w", err)
+    }
+    return nil
+}
+
+// URL returns the file's public URL
+func (s *LocalStore) URL(key string) string {
+    return joinURL(s.baseURL, key)
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// S3Store keeps files in a bucket of an S3-compatible object store. Requests use path-style
+// URLs ({endpoint}/{bucket}/{key}), which every S3-compatible store accepts, and are signed
+// with AWS Signature Version 4.
+type S3Store struct {
+    config     Config
+    endpoint   *url.URL
+    clock      clock.Clock
+    httpClient *http.Client
+}
+
+// NewS3Store creates new S3-compatible store. Without a public base URL the files are
+// addressed at the bucket itself.
+func NewS3Store(config Config, clk clock.Clock) *S3Store {
+    if config.Timeout <= 0 {
+        config.Timeout = 30 * time.Second
+    }
+    if config.S3Region == "" {
+        config.S3Region = "us-east-1"
+    }
+    if config.PublicBaseURL == "" {
+        config.PublicBaseURL = joinURL(config.S3Endpoint, config.S3Bucket)
+    }
+    endpoint, err := url.Parse(config.S3Endpoint)
+    if err != nil {
+        endpoint = &url.URL{}
+    }
+    return &S3Store{
+        config:     config,
+        endpoint:   endpoint,
+        clock:      clk,
+        httpClient: &http.Client{Timeout: config.Timeout},
+    }
+}
+
+// Put uploads the file
+func (s *S3Store) Put(ctx context.Context, key, contentType string, data []byte) error {
+    req, err := s.newRequest(ctx, http.MethodPut, key, data)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", contentType)
+    return s.do(req, "upload")
+}
+
+// Delete removes the file; S3 answers 204 for a missing key too
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+    req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+    if err != nil {
+        return err
+    }
+    return s.do(req, "delete")
+}
+
+// URL returns the file's public URL
+func (s *S3Store) URL(key string) string {
+    return joinURL(s.config.PublicBaseURL, key)
+}
+
+func (s *S3Store) do(req *http.Request, action string) error {
+    resp, err := s.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("failed to %s image: %w", action, err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("failed to %s image: object store returned %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+    }
+    return nil
+}
+
+// newRequest builds a signed request for the key
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+    target := *s.endpoint
+    target.Path = strings.TrimRight(target.Path, "/") + "/" + s.config.S3Bucket + "/" + key
+    target.RawPath = ""
+
+    req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create object store request: %w", err)
+    }
+    s.sign(req, body)
+    return req, nil
+}
+
+// sign adds the SigV4 headers: x-amz-date, x-amz-content-sha256 and Authorization
+func (s *S3Store) sign(req *http.Request, body []byte) {
+    now := s.clock.Now().UTC()
+    amzDate := now.Format("20060102T150405Z")
+    day := now.Format("20060102")
+    payloadHash := sha256Hex(body)
+
+    req.Header.Set("x-amz-date", amzDate)
+    req.Header.Set("x-amz-content-sha256", payloadHash)
+
+    const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+    canonicalRequest := strings.Join([]string{
+        req.Method,
+        req.URL.EscapedPath(),
+        "", // no query string
+        "host:" + req.URL.Host,
+        "x-amz-content-sha256:" + payloadHash,
+        "x-amz-date:" + amzDate,
+        "",
+        signedHeaders,
+        payloadHash,
+    }, "\n")
+
+    scope := day + "/" + s.config.S3Region + "/s3/aws4_request"
+    stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+    signingKey := hmacSHA256([]byte("AWS4"+s.config.S3SecretKey), day)
+    signingKey = hmacSHA256(signingKey, s.config.S3Region)
+    signingKey = hmacSHA256(signingKey, "s3")
+    signingKey = hmacSHA256(signingKey, "aws4_request")
+    signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+    req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+        s.config.S3AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+    mac := hmac.New(sha256.New, key)
+    mac.Write([]byte(data))
+    return mac.Sum(nil)
+}
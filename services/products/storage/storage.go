@@ -0,0 +1,74 @@
+// Package storage stores uploaded product images in a pluggable backend: the local disk or an
+// S3-compatible object store (AWS S3, MinIO, R2, ...).
+//
+// Why: clients used to send a bare image_url pointing anywhere. Uploads now go through the
+// products service, which validates them and writes them under keys it picks, and the URL kept
+// on the product is the backend's public (CDN) address for that key.
+package storage
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// Backend names for Config.Backend
+const (
+    BackendLocal = "local"
+    BackendS3    = "s3"
+)
+
+// LocalURLPath is where the service serves local images when no public base URL is set
+const LocalURLPath = "/images"
+
+// Store writes and removes files by key and tells where they are served from
+type Store interface {
+    Put(ctx context.Context, key, contentType string, data []byte) error
+    Delete(ctx context.Context, key string) error
+    URL(key string) string
+}
+
+// Config selects and configures the storage backend
+type Config struct {
+    Backend       string // BackendLocal or BackendS3
+    PublicBaseURL string // prefix of the returned URLs, e.g. a CDN in front of the bucket
+
+    LocalDir string // local: directory the files are written under
+
+    S3Endpoint  string // s3: e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+    S3Bucket    string
+    S3Region    string
+    S3AccessKey string
+    S3SecretKey string
+    Timeout     time.Duration
+}
+
+// New creates the configured backend
+func New(config Config, clk clock.Clock) (Store, error) {
+    switch config.Backend {
+    case BackendLocal, "":
+        if config.PublicBaseURL == "" {
+            config.PublicBaseURL = LocalURLPath
+        }
+        return NewLocalStore(config.LocalDir, config.PublicBaseURL), nil
+    case BackendS3:
+        return NewS3Store(config, clk), nil
+    default:
+        return nil, fmt.Errorf("unknown image storage backend %q", config.Backend)
+    }
+}
+
+// ProductImageKey returns a new key for an image of the product. Keys are never reused, so a
+// CDN can cache the URLs forever.
+func ProductImageKey(productID int64, ext string) string {
+    return fmt.Sprintf("products/%d/%s%s", productID, uuid.New().String(), ext)
+}
+
+// joinURL joins a base URL and a key with a single slash
+func joinURL(base, key string) string {
+    return strings.TrimRight(base, "/") + "/" + key
+}
@@ -1,8 +1,8 @@
-package handlers
+package subscribers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -22,6 +22,9 @@ type EventHandler struct {
     eventPublisher   *messaging.Publisher
 }
 
+// ReservationTTL is how long stock stays reserved for an order before it expires
+const ReservationTTL = 5 * time.Minute
+
 // NewEventHandler creates new event handler
 func NewEventHandler(
 	inventoryRepo *repository.InventoryReservationRepository,
@@ -38,13 +41,9 @@ func NewEventHandler(
 // HandleEvent processes incoming events
 func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
 	// Extract event type
-	var baseEvent struct {
-		EventID   string `json:"event_id"`
-		EventType string `json:"event_type"`
-	}
-
-	if err := json.Unmarshal(message, &baseEvent); err != nil {
-		return fmt.Errorf("failed to unmarshal base event: %w", err)
+	baseEvent, err := events.ReadEnvelope(message)
+	if err != nil {
+		return err
 	}
 
 	eventID := baseEvent.EventID
@@ -73,6 +72,8 @@ func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
         handlerErr = eh.handleOrderFailed(ctx, message)
     case "OrderCancelled":
         handlerErr = eh.handleOrderCancelled(ctx, message)
+    case "OrderPaymentPending":
+        handlerErr = eh.handleOrderPaymentPending(ctx, message)
     default:
         log.Printf("Unknown event type: %s, skipping", eventType)
         return nil
@@ -91,114 +92,138 @@ func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
 	return handlerErr
 }
 
-// handleOrderPlaced handles OrderPlacedEvent
-// Why: When order is placed, we need to reserve the stock
-// This prevents double-selling when multiple orders try to buy same item
+// handleOrderCreated handles OrderCreatedEvent
+// Why: the order saga waits on one answer per order. All lines are reserved together or not
+// at all, then a single StockReserved (with every reservation) or StockReservationFailed is
+// published, tagged with the saga's correlation ID.
 func (eh *EventHandler) handleOrderCreated(ctx context.Context, message []byte) error {
-    var event events.OrderCreatedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderCreatedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderCreatedEvent: %w", err)
     }
 
     log.Printf("Processing OrderCreatedEvent: OrderID=%d, Items=%d", event.OrderID, len(event.Items))
 
-    insufficientInventory := false
-    // First: Check if all items have sufficient inventory
+    lines := make([]models.OrderLine, 0, len(event.Items))
     for _, item := range event.Items {
-        inventory, err := eh.inventoryRepo.GetProductInventory(ctx, item.ProductID)
-        if err != nil || inventory == nil || inventory.AvailableQuantity < item.Quantity {
-            log.Printf("Insufficient inventory for product %d: need %d, have %d", 
-                item.ProductID, item.Quantity, 
-                func() int { //anonymous function to get available quantity
-                    if inventory != nil {
-                        return inventory.AvailableQuantity
-                    }
-                    return 0
-                }())
-                insufficientInventory = true
-            break
+        line := models.OrderLine{ProductID: item.ProductID, Quantity: item.Quantity}
+        if item.VariantID != nil {
+            line.VariantID = *item.VariantID
         }
+        lines = append(lines, line)
     }
 
-    if insufficientInventory{
-        // Publish OrderFailedEvent to trigger compensation
-            failedEvent := events.OrderFailedEvent{
-                BaseEvent: events.NewBaseEvent("OrderFailed", fmt.Sprintf("%d", event.OrderID), "order", event.CorrelationID),
-                OrderID:   fmt.Sprintf("%d", event.OrderID),
-                Reason:    "Insufficient inventory for product",
-            }
-            if err := eh.eventPublisher.PublishProductEvent(ctx, failedEvent); err != nil {
-                log.Printf("Failed to publish OrderFailedEvent: %v", err)
-            }
-            return fmt.Errorf("insufficient inventory for products")
-    } 
-    // Reserve stock for each item in the order
-    for _, item := range event.Items {
-        reservation := &models.InventoryReservation{
-            ProductID:     item.ProductID,
-            Quantity:      item.Quantity,
-            OrderID:       event.OrderID,
-            ReservationID: fmt.Sprintf("res-%d-%d", event.OrderID, item.ProductID), // Generate unique ID
-            Status:        "reserved",
-            CreatedAt: time.Now(),
-            ExpiresAt: time.Now().Add(5*time.Minute),
+    reservations, err := eh.inventoryRepo.ReserveForOrder(ctx, event.OrderID, event.CartID, lines, ReservationTTL)
+    if err != nil {
+        failedEvent, ok := reservationFailure(event, err)
+        if !ok {
+            // Database trouble: let the message be redelivered rather than fail the order
+            return fmt.Errorf("failed to reserve stock for order %d: %w", event.OrderID, err)
         }
 
-        if err := eh.inventoryRepo.CreateReservation(ctx, reservation); err != nil {
-            // Cleanup: Release already-reserved items
-            eh.releaseReservationsForOrder(ctx, event.OrderID)
-            
-            // Publish ONE OrderFailedEvent
-            failedEvent := events.OrderFailedEvent{
-                BaseEvent:    events.NewBaseEvent("OrderFailed", fmt.Sprintf("%d", event.OrderID), "order", event.CorrelationID),
-                OrderID:      fmt.Sprintf("%d", event.OrderID),
-                Reason:       fmt.Sprintf("failed to reserve inventory for product %d", item.ProductID),
-            }
-            if err := eh.eventPublisher.PublishProductEvent(ctx, failedEvent); err != nil {
-                log.Printf("Failed to publish OrderFailedEvent: %v", err)
-            }
-            return fmt.Errorf("failed to create reservation for product %d: %w", item.ProductID, err)
+        log.Printf("Reservation failed for order %d: %s", event.OrderID, failedEvent.Reason)
+        if err := eh.eventPublisher.PublishProductEvent(ctx, failedEvent); err != nil {
+            return fmt.Errorf("failed to publish StockReservationFailedEvent: %w", err)
         }
+        return nil
+    }
 
-        log.Printf("Reserved %d units of product %d for order %d", item.Quantity, item.ProductID, event.OrderID)
-
-        // Publish StockReservedEvent for each item
-        stockEvent := events.StockReservedEvent{
-            BaseEvent:     events.NewBaseEvent("StockReserved", fmt.Sprintf("%d", item.ProductID), "product", fmt.Sprintf("%d", event.OrderID)),
-            ProductID:     item.ProductID,
-            Quantity:      item.Quantity,
-            OrderID:       event.OrderID,
-            ReservationID: reservation.ReservationID,
-        }
+    stockEvent := events.StockReservedEvent{
+        BaseEvent: events.NewBaseEvent("StockReserved", fmt.Sprintf("%d", event.OrderID), "order", event.CorrelationID),
+        OrderID:   event.OrderID,
+    }
+    for _, res := range reservations {
+        stockEvent.Items = append(stockEvent.Items, events.ReservedStock{
+            ProductID:     res.ProductID,
+            VariantID:     res.VariantID,
+            Quantity:      res.Quantity,
+            ReservationID: res.ReservationID,
+        })
+    }
 
-        if err := eh.eventPublisher.PublishProductEvent(ctx, stockEvent); err != nil {
-            log.Printf("Failed to publish StockReservedEvent: %v", err)
-            // Don't fail - idempotency will handle retry
-        }
+    if err := eh.eventPublisher.PublishProductEvent(ctx, stockEvent); err != nil {
+        return fmt.Errorf("failed to publish StockReservedEvent: %w", err)
     }
 
+    log.Printf("Reserved %d product(s) for order %d", len(reservations), event.OrderID)
     return nil
 }
 
+// reservationFailure builds the StockReservationFailed event for a reservation error that
+// fails the order (a shortage or an unknown product); other errors are retryable
+func reservationFailure(event events.OrderCreatedEvent, err error) (events.StockReservationFailedEvent, bool) {
+    failed := events.StockReservationFailedEvent{
+        BaseEvent: events.NewBaseEvent("StockReservationFailed", fmt.Sprintf("%d", event.OrderID), "order", event.CorrelationID),
+        OrderID:   event.OrderID,
+    }
+
+    var shortage *repository.StockShortageError
+    switch {
+    case errors.As(err, &shortage):
+        failed.ProductID = shortage.ProductID
+        failed.Requested = shortage.Requested
+        failed.Available = shortage.Available
+        failed.Reason = fmt.Sprintf("insufficient inventory for product %d: requested %d, available %d",
+            shortage.ProductID, shortage.Requested, shortage.Available)
+        if shortage.VariantID != 0 {
+            variantID := shortage.VariantID
+            failed.VariantID = &variantID
+            failed.Reason = fmt.Sprintf("insufficient inventory for product %d variant %d: requested %d, available %d",
+                shortage.ProductID, shortage.VariantID, shortage.Requested, shortage.Available)
+        }
+    case errors.Is(err, repository.ErrUnknownProduct):
+        failed.Reason = fmt.Sprintf("failed to reserve inventory: %v", err)
+    default:
+        return failed, false
+    }
+    return failed, true
+}
 
 // handleOrderConfirmed handles OrderConfirmedEvent
-// Why: When order is confirmed, mark the reservation as "confirmed"/"sold"
-// This indicates the stock has been permanently allocated to the order
+// Why: confirmation is the sale; the reserved units are taken out of stock and the
+// reservations marked committed, so they stop counting as held
 func (eh *EventHandler) handleOrderConfirmed(ctx context.Context, message []byte) error {
-    var event events.OrderConfirmedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderConfirmedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderConfirmedEvent: %w", err)
     }
 
     log.Printf("✓ Processing OrderConfirmedEvent: OrderID=%d", event.OrderID)
 
-    // Update reservation status to "confirmed"
-    if err := eh.inventoryRepo.UpdateReservationStatusByOrderID(ctx, fmt.Sprintf("%d", event.OrderID), "confirmed"); err != nil {
-        log.Printf("Failed to update reservation status to confirmed: %v", err)
-        return fmt.Errorf("failed to update reservation status: %w", err)
+    committed, err := eh.inventoryRepo.CommitOrderReservations(ctx, event.OrderID)
+    if err != nil {
+        log.Printf("Failed to commit reservations for order %d: %v", event.OrderID, err)
+        return fmt.Errorf("failed to commit reservations: %w", err)
     }
 
-    log.Printf("✓ Reservation confirmed for order: %d", event.OrderID)
+    if committed == 0 {
+        log.Printf("⚠️  No held reservations to commit for order %d", event.OrderID)
+        return nil
+    }
+    log.Printf("✓ Stock decremented for order %d (%d reservation(s))", event.OrderID, committed)
+    return nil
+}
+
+// handleOrderPaymentPending handles OrderPaymentPendingEvent
+// Why: the customer may retry a failed payment until HoldUntil; the reservations are kept
+// that long so the stock is still there, and the OrderFailed sent after the window releases them
+func (eh *EventHandler) handleOrderPaymentPending(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.OrderPaymentPendingEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal OrderPaymentPendingEvent: %w", err)
+    }
+
+    extended, err := eh.inventoryRepo.ExtendOrderReservations(ctx, event.OrderID, event.HoldUntil)
+    if err != nil {
+        log.Printf("Failed to extend reservations for order %d: %v", event.OrderID, err)
+        return fmt.Errorf("failed to extend reservations: %w", err)
+    }
+
+    if extended == 0 {
+        log.Printf("⚠️  No held reservations to extend for order %d", event.OrderID)
+        return nil
+    }
+    log.Printf("✓ Held %d reservation(s) of order %d until %s for a payment retry", extended, event.OrderID, event.HoldUntil.Format(time.RFC3339))
     return nil
 }
 
@@ -206,8 +231,8 @@ func (eh *EventHandler) handleOrderConfirmed(ctx context.Context, message []byte
 // Why: When order fails, release the reserved inventory
 // This allows stock to be sold to other customers
 func (eh *EventHandler) handleOrderFailed(ctx context.Context, message []byte) error {
-    var event events.OrderFailedEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderFailedEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderFailedEvent: %w", err)
     }
 
@@ -255,8 +280,8 @@ func (eh *EventHandler) handleOrderFailed(ctx context.Context, message []byte) e
 // Why: When order is cancelled by user/admin, release the reserved inventory
 // This allows the stock to be allocated to other orders
 func (eh *EventHandler) handleOrderCancelled(ctx context.Context, message []byte) error {
-    var event events.OrderCancelledEvent
-    if err := json.Unmarshal(message, &event); err != nil {
+    event, err := events.Unmarshal[events.OrderCancelledEvent](message)
+    if err != nil {
         return fmt.Errorf("failed to unmarshal OrderCancelledEvent: %w", err)
     }
 
@@ -299,19 +324,3 @@ func (eh *EventHandler) handleOrderCancelled(ctx context.Context, message []byte
 
     return nil
 }
-
-// releaseReservationsForOrder releases all reservations for an order
-// Used when order fails after partial reservations
-func (eh *EventHandler) releaseReservationsForOrder(ctx context.Context, orderID int64) {
-    reservations, err := eh.inventoryRepo.GetReservationsByOrderID(ctx, orderID)
-    if err != nil {
-        log.Printf("Failed to get reservations for cleanup: %v", err)
-        return
-    }
-
-    for _, res := range reservations {
-        if err := eh.inventoryRepo.ReleaseReservation(ctx, res.ReservationID); err != nil {
-            log.Printf("Failed to release reservation %s during cleanup: %v", res.ReservationID, err)
-        }
-    }
-}
\ No newline at end of file
@@ -0,0 +1,59 @@
+package subscribers
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/sanketh-sg/prost/services/products/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+func TestReservationFailure(t *testing.T) {
+    order := events.OrderCreatedEvent{
+        BaseEvent: events.NewBaseEvent("OrderCreated", "42", "order", "corr-1"),
+        OrderID:   42,
+    }
+
+    // A shortage fails the order and names the short product
+    shortage := &repository.StockShortageError{ProductID: 7, Requested: 3, Available: 1}
+    failed, ok := reservationFailure(order, shortage)
+    if !ok {
+        t.Fatal("shortage should fail the order")
+    }
+    if failed.CorrelationID != "corr-1" || failed.OrderID != 42 || failed.ProductID != 7 || failed.Available != 1 {
+        t.Errorf("failed event = %+v", failed)
+    }
+    if !strings.HasPrefix(failed.Reason, "insufficient inventory") {
+        t.Errorf("reason = %q, want an insufficient inventory reason", failed.Reason)
+    }
+
+    // So does an unknown product
+    if _, ok := reservationFailure(order, fmt.Errorf("%w: 9", repository.ErrUnknownProduct)); !ok {
+        t.Error("unknown product should fail the order")
+    }
+
+    // Anything else is retried
+    if _, ok := reservationFailure(order, errors.New("connection reset")); ok {
+        t.Error("database error should not fail the order")
+    }
+}
+
+func TestReservationFailure_NamesShortVariant(t *testing.T) {
+    order := events.OrderCreatedEvent{
+        BaseEvent: events.NewBaseEvent("OrderCreated", "42", "order", "corr-1"),
+        OrderID:   42,
+    }
+
+    failed, ok := reservationFailure(order, &repository.StockShortageError{ProductID: 7, VariantID: 70, Requested: 2, Available: 0})
+    if !ok {
+        t.Fatal("variant shortage should fail the order")
+    }
+    if failed.ProductID != 7 || failed.VariantID == nil || *failed.VariantID != 70 {
+        t.Errorf("failed event = %+v, want product 7 variant 70", failed)
+    }
+    if !strings.Contains(failed.Reason, "variant 70") {
+        t.Errorf("reason = %q, want it to name the variant", failed.Reason)
+    }
+}
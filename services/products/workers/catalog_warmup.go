@@ -0,0 +1,160 @@
+package workers
+
+import (
+    "context"
+    "encoding/json"
+    "log"
+    "net/http"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// Catalog warm-up
+// Why: right after a deploy the pool has no connections and Postgres has none of the catalog
+// pages in memory, so the first storefront requests pay for both. The warm-up replays the
+// catalog reads (categories, the product list and the top products) before /ready passes, so
+// that cost is paid before the instance takes traffic.
+
+// warmupConcurrency matches the default idle pool size, so the opened connections stay pooled
+const warmupConcurrency = 5
+
+// CategoryLister lists categories
+type CategoryLister interface {
+    GetAllCategories(ctx context.Context) ([]*models.Category, error)
+}
+
+// ProductReader reads products
+type ProductReader interface {
+    GetAllProducts(ctx context.Context, categoryID *int64) ([]*models.Product, error)
+    GetProduct(ctx context.Context, id int64) (*models.Product, error)
+}
+
+// CatalogWarmupConfig controls the startup warm-up
+type CatalogWarmupConfig struct {
+    Enabled     bool
+    TopProducts int           // most-reviewed products read individually
+    Timeout     time.Duration // the instance becomes ready after this even if warm-up hasn't finished
+}
+
+// CatalogWarmup preloads the catalog on startup and holds readiness until it is done
+type CatalogWarmup struct {
+    config     CatalogWarmupConfig
+    categories CategoryLister
+    products   ProductReader
+    done       atomic.Bool
+}
+
+// NewCatalogWarmup creates a catalog warm-up; a disabled one is ready immediately
+func NewCatalogWarmup(config CatalogWarmupConfig, categories CategoryLister, products ProductReader) *CatalogWarmup {
+    cw := &CatalogWarmup{
+        config:     config,
+        categories: categories,
+        products:   products,
+    }
+    if !config.Enabled {
+        cw.done.Store(true)
+    }
+    return cw
+}
+
+// Start runs the warm-up in the background; the returned channel closes when it is done
+func (cw *CatalogWarmup) Start(ctx context.Context) <-chan struct{} {
+    done := make(chan struct{})
+    if !cw.config.Enabled {
+        close(done)
+        return done
+    }
+
+    go func() {
+        defer close(done)
+        cw.Run(ctx)
+    }()
+
+    return done
+}
+
+// Run warms the catalog once and marks the warm-up done, whether or not it succeeded.
+// A failed warm-up only means a cold start, which is no reason to stay out of rotation.
+func (cw *CatalogWarmup) Run(ctx context.Context) {
+    defer cw.done.Store(true)
+
+    ctx, cancel := context.WithTimeout(ctx, cw.config.Timeout)
+    defer cancel()
+
+    start := time.Now()
+
+    categories, err := cw.categories.GetAllCategories(ctx)
+    if err != nil {
+        log.Printf("⚠️  Catalog warm-up failed to load categories: %v", err)
+        return
+    }
+
+    products, err := cw.products.GetAllProducts(ctx, nil)
+    if err != nil {
+        log.Printf("⚠️  Catalog warm-up failed to load products: %v", err)
+        return
+    }
+
+    top := TopProducts(products, cw.config.TopProducts)
+
+    var wg sync.WaitGroup
+    var failed atomic.Int32
+    sem := make(chan struct{}, warmupConcurrency)
+    for _, product := range top {
+        wg.Add(1)
+        sem <- struct{}{}
+        go func(id int64) {
+            defer wg.Done()
+            defer func() { <-sem }()
+
+            if _, err := cw.products.GetProduct(ctx, id); err != nil {
+                failed.Add(1)
+            }
+        }(product.ID)
+    }
+    wg.Wait()
+
+    if n := failed.Load(); n > 0 {
+        log.Printf("⚠️  Catalog warm-up failed to load %d of %d top products", n, len(top))
+    }
+    log.Printf("✓ Catalog warmed: %d categories, %d products, top %d in %s",
+        len(categories), len(products), len(top), time.Since(start).Round(time.Millisecond))
+}
+
+// Ready reports whether the warm-up has finished (or is disabled)
+func (cw *CatalogWarmup) Ready() bool {
+    return cw.done.Load()
+}
+
+// ReadyGate answers 503 until the warm-up is done, then defers to next (e.g. the watchdog)
+func (cw *CatalogWarmup) ReadyGate(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+        if !cw.Ready() {
+            rw.Header().Set("Content-Type", "application/json")
+            rw.WriteHeader(http.StatusServiceUnavailable)
+            json.NewEncoder(rw).Encode(map[string]interface{}{
+                "status": "warming_up",
+            })
+            return
+        }
+        next.ServeHTTP(rw, r)
+    })
+}
+
+// TopProducts returns the n most-reviewed products, keeping list order (newest first) for ties
+func TopProducts(products []*models.Product, n int) []*models.Product {
+    ranked := make([]*models.Product, len(products))
+    copy(ranked, products)
+    sort.SliceStable(ranked, func(i, j int) bool {
+        return ranked[i].ReviewCount > ranked[j].ReviewCount
+    })
+
+    if n >= 0 && n < len(ranked) {
+        ranked = ranked[:n]
+    }
+    return ranked
+}
@@ -0,0 +1,89 @@
+package workers
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+)
+
+// fakeCatalog serves a fixed catalog and records which products were read individually
+type fakeCatalog struct {
+    products []*models.Product
+
+    mu   sync.Mutex
+    read []int64
+}
+
+func (f *fakeCatalog) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
+    return []*models.Category{{ID: 1, Name: "Shoes"}}, nil
+}
+
+func (f *fakeCatalog) GetAllProducts(ctx context.Context, categoryID *int64) ([]*models.Product, error) {
+    return f.products, nil
+}
+
+func (f *fakeCatalog) GetProduct(ctx context.Context, id int64) (*models.Product, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.read = append(f.read, id)
+    return &models.Product{ID: id}, nil
+}
+
+func TestCatalogWarmup_ReadsTopProductsAndGatesReadiness(t *testing.T) {
+    // Arrange: newest first, as GetAllProducts lists them
+    catalog := &fakeCatalog{products: []*models.Product{
+        {ID: 4, ReviewCount: 0},
+        {ID: 3, ReviewCount: 7},
+        {ID: 2, ReviewCount: 2},
+        {ID: 1, ReviewCount: 7},
+    }}
+    warmup := NewCatalogWarmup(CatalogWarmupConfig{Enabled: true, TopProducts: 3, Timeout: time.Second}, catalog, catalog)
+    probe := warmup.ReadyGate(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+        rw.WriteHeader(http.StatusOK)
+    }))
+
+    // Assert: not ready before the warm-up ran
+    w := httptest.NewRecorder()
+    probe.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+    if w.Code != http.StatusServiceUnavailable {
+        t.Fatalf("status before warm-up = %d, want 503", w.Code)
+    }
+
+    // Act
+    <-warmup.Start(context.Background())
+
+    // Assert: the three most-reviewed products were read, and the probe now defers to next
+    sort.Slice(catalog.read, func(i, j int) bool { return catalog.read[i] < catalog.read[j] })
+    if len(catalog.read) != 3 || catalog.read[0] != 1 || catalog.read[1] != 2 || catalog.read[2] != 3 {
+        t.Errorf("read products = %v, want [1 2 3]", catalog.read)
+    }
+
+    w = httptest.NewRecorder()
+    probe.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+    if w.Code != http.StatusOK {
+        t.Errorf("status after warm-up = %d, want 200", w.Code)
+    }
+}
+
+func TestTopProducts_KeepsListOrderForTies(t *testing.T) {
+    products := []*models.Product{
+        {ID: 3, ReviewCount: 1},
+        {ID: 2, ReviewCount: 5},
+        {ID: 1, ReviewCount: 1},
+    }
+
+    top := TopProducts(products, 2)
+
+    if len(top) != 2 || top[0].ID != 2 || top[1].ID != 3 {
+        t.Errorf("top = %v, want products 2 and 3", top)
+    }
+    if products[0].ID != 3 {
+        t.Error("TopProducts reordered its input")
+    }
+}
@@ -0,0 +1,105 @@
+package workers
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// LowStockStore finds low-stock products and remembers which were alerted
+type LowStockStore interface {
+    GetLowStockProducts(ctx context.Context) ([]*models.LowStockProduct, error)
+    MarkLowStockAlerted(ctx context.Context, productID int64, at time.Time) error
+    ClearRecoveredLowStock(ctx context.Context) (int64, error)
+}
+
+// LowStockWorker periodically checks available stock against each product's low-stock
+// threshold and publishes LowStock for the products that fell below it
+// Why: available stock drops on every reservation path (orders, cart locks, channels) and on
+// stock edits; one periodic check covers them all without hooking each of them
+type LowStockWorker struct {
+    store     LowStockStore
+    publisher ProductEventPublisher
+    clock     clock.Clock
+    interval  time.Duration
+}
+
+// NewLowStockWorker creates new low stock worker; a zero interval disables it
+func NewLowStockWorker(store LowStockStore, publisher ProductEventPublisher, clk clock.Clock, interval time.Duration) *LowStockWorker {
+    return &LowStockWorker{
+        store:     store,
+        publisher: publisher,
+        clock:     clk,
+        interval:  interval,
+    }
+}
+
+// Start checks once right away, then every interval until ctx is cancelled. A zero interval
+// disables it; the returned channel is closed at once.
+func (w *LowStockWorker) Start(ctx context.Context) <-chan struct{} {
+    done := make(chan struct{})
+    if w.interval <= 0 {
+        close(done)
+        return done
+    }
+
+    return clock.Every(ctx, w.clock, w.interval, true, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce publishes LowStock for the products that fell below their threshold since the last
+// check and returns how many were published. Recovered products are cleared first, so a
+// product that recovered and dipped again between two checks isn't alerted twice.
+// A failed publish leaves the product unmarked for the next check.
+func (w *LowStockWorker) RunOnce(ctx context.Context) int {
+    if _, err := w.store.ClearRecoveredLowStock(ctx); err != nil {
+        log.Printf("❌ Failed to clear recovered low stock alerts: %v", err)
+    }
+
+    products, err := w.store.GetLowStockProducts(ctx)
+    if err != nil {
+        log.Printf("❌ Failed to check low stock: %v", err)
+        return 0
+    }
+
+    published := 0
+    for _, product := range products {
+        if product.AlertedAt != nil {
+            continue
+        }
+
+        if err := w.publish(ctx, product); err != nil {
+            log.Printf("⚠️  Failed to publish LowStock for product %d: %v", product.ProductID, err)
+            continue
+        }
+        if err := w.store.MarkLowStockAlerted(ctx, product.ProductID, w.clock.Now()); err != nil {
+            log.Printf("❌ Failed to mark product %d low stock alerted: %v", product.ProductID, err)
+        }
+        published++
+    }
+
+    if published > 0 {
+        log.Printf("✓ Alerted low stock for %d product(s)", published)
+    }
+    return published
+}
+
+func (w *LowStockWorker) publish(ctx context.Context, product *models.LowStockProduct) error {
+    productID := strconv.FormatInt(product.ProductID, 10)
+    event := events.LowStockEvent{
+        BaseEvent:     events.NewBaseEvent("LowStock", productID, "product", fmt.Sprintf("low-stock-%s-%d", productID, w.clock.Now().Unix())),
+        ProductID:     product.ProductID,
+        ProductName:   product.Name,
+        SKU:           product.SKU,
+        StockQuantity: product.StockQuantity,
+        Reserved:      product.Reserved,
+        Available:     product.Available,
+        Threshold:     product.Threshold,
+    }
+    return w.publisher.PublishProductEvent(ctx, event)
+}
@@ -0,0 +1,115 @@
+package workers
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// fakeLowStock keeps the low-stock products in memory; recovered are cleared on the next check
+type fakeLowStock struct {
+    products  []*models.LowStockProduct
+    recovered map[int64]bool
+}
+
+func (f *fakeLowStock) GetLowStockProducts(ctx context.Context) ([]*models.LowStockProduct, error) {
+    var low []*models.LowStockProduct
+    for _, product := range f.products {
+        if !f.recovered[product.ProductID] {
+            low = append(low, product)
+        }
+    }
+    return low, nil
+}
+
+func (f *fakeLowStock) MarkLowStockAlerted(ctx context.Context, productID int64, at time.Time) error {
+    for _, product := range f.products {
+        if product.ProductID == productID {
+            product.AlertedAt = &at
+        }
+    }
+    return nil
+}
+
+func (f *fakeLowStock) ClearRecoveredLowStock(ctx context.Context) (int64, error) {
+    var cleared int64
+    for _, product := range f.products {
+        if f.recovered[product.ProductID] && product.AlertedAt != nil {
+            product.AlertedAt = nil
+            cleared++
+        }
+    }
+    return cleared, nil
+}
+
+// fakeLowStockPublisher records LowStock events and fails for the products in failFor
+type fakeLowStockPublisher struct {
+    published []events.LowStockEvent
+    failFor   map[int64]bool
+}
+
+func (f *fakeLowStockPublisher) PublishProductEvent(ctx context.Context, event interface{}) error {
+    low := event.(events.LowStockEvent)
+    if f.failFor[low.ProductID] {
+        return errors.New("broker down")
+    }
+    f.published = append(f.published, low)
+    return nil
+}
+
+func TestLowStockWorker_AlertsOncePerDip(t *testing.T) {
+    // Arrange
+    now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+    earlier := now.Add(-time.Hour)
+    store := &fakeLowStock{products: []*models.LowStockProduct{
+        {ProductID: 1, Name: "Mouse", SKU: "MS-1", StockQuantity: 6, Reserved: 2, Available: 4, Threshold: 5},
+        {ProductID: 2, Available: 1, Threshold: 5, AlertedAt: &earlier},
+        {ProductID: 3, Available: 0, Threshold: 2},
+    }}
+    publisher := &fakeLowStockPublisher{failFor: map[int64]bool{3: true}}
+    worker := NewLowStockWorker(store, publisher, clock.NewFake(now), time.Minute)
+
+    // Act
+    published := worker.RunOnce(context.Background())
+
+    // Assert: 1 is new, 2 was already alerted, 3 failed to publish
+    if published != 1 || len(publisher.published) != 1 {
+        t.Fatalf("expected 1 event, got %d (%+v)", published, publisher.published)
+    }
+    event := publisher.published[0]
+    if event.ProductID != 1 || event.SKU != "MS-1" || event.Available != 4 || event.Reserved != 2 || event.Threshold != 5 {
+        t.Fatalf("unexpected event %+v", event)
+    }
+    if store.products[2].AlertedAt != nil {
+        t.Fatal("product 3 marked alerted although publishing failed")
+    }
+
+    // Act: the next check retries 3 and doesn't repeat 1
+    publisher.failFor = nil
+    if published := worker.RunOnce(context.Background()); published != 1 || publisher.published[1].ProductID != 3 {
+        t.Fatalf("expected only product 3 on the second check, got %d (%+v)", published, publisher.published)
+    }
+
+    // Act: 1 recovers, then dips again
+    store.recovered = map[int64]bool{1: true}
+    worker.RunOnce(context.Background())
+    store.recovered = nil
+    if published := worker.RunOnce(context.Background()); published != 1 || publisher.published[2].ProductID != 1 {
+        t.Fatalf("expected product 1 alerted again after recovering, got %d (%+v)", published, publisher.published)
+    }
+}
+
+func TestLowStockWorker_ZeroIntervalDisables(t *testing.T) {
+    worker := NewLowStockWorker(&fakeLowStock{}, &fakeLowStockPublisher{}, clock.NewFake(time.Now()), 0)
+
+    select {
+    case <-worker.Start(context.Background()):
+    default:
+        t.Fatal("expected a disabled worker to be done at once")
+    }
+}
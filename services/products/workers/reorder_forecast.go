@@ -0,0 +1,124 @@
+package workers
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// ForecastStore recomputes and stores the reorder forecasts
+type ForecastStore interface {
+    RefreshForecasts(ctx context.Context, windowDays int) ([]*models.StockForecast, error)
+    MarkReorderSuggested(ctx context.Context, productID int64, at time.Time) error
+    ClearReorderSuggested(ctx context.Context, productIDs []int64) error
+}
+
+// ProductEventPublisher publishes product events
+type ProductEventPublisher interface {
+    PublishProductEvent(ctx context.Context, event interface{}) error
+}
+
+// ReorderForecastConfig configures the reorder forecast worker
+type ReorderForecastConfig struct {
+    Interval   time.Duration // 0 disables the worker
+    WindowDays int           // days of sales the velocity is averaged over
+    Policy     models.ReorderPolicy
+}
+
+// ReorderForecastWorker periodically recomputes sales velocity and days of stock left per
+// product, and publishes ReorderSuggested when a product falls to its reorder point
+type ReorderForecastWorker struct {
+    store     ForecastStore
+    publisher ProductEventPublisher
+    clock     clock.Clock
+    config    ReorderForecastConfig
+}
+
+// NewReorderForecastWorker creates new reorder forecast worker
+func NewReorderForecastWorker(store ForecastStore, publisher ProductEventPublisher, clk clock.Clock, config ReorderForecastConfig) *ReorderForecastWorker {
+    return &ReorderForecastWorker{
+        store:     store,
+        publisher: publisher,
+        clock:     clk,
+        config:    config,
+    }
+}
+
+// Start runs the forecast once right away, then every interval until ctx is cancelled. A zero
+// interval disables it; the returned channel is closed at once.
+func (w *ReorderForecastWorker) Start(ctx context.Context) <-chan struct{} {
+    done := make(chan struct{})
+    if w.config.Interval <= 0 {
+        close(done)
+        return done
+    }
+
+    return clock.Every(ctx, w.clock, w.config.Interval, true, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce refreshes the forecasts and returns how many ReorderSuggested were published.
+// A product is suggested once per dip: it's marked when the event is published and cleared
+// when it's back above its reorder point. A failed publish leaves it unmarked for the next run.
+func (w *ReorderForecastWorker) RunOnce(ctx context.Context) int {
+    forecasts, err := w.store.RefreshForecasts(ctx, w.config.WindowDays)
+    if err != nil {
+        log.Printf("❌ Failed to refresh reorder forecasts: %v", err)
+        return 0
+    }
+
+    published := 0
+    var recovered []int64
+    for _, forecast := range forecasts {
+        suggestion, ok := w.config.Policy.Suggest(forecast)
+        if !ok {
+            if forecast.SuggestedAt != nil {
+                recovered = append(recovered, forecast.ProductID)
+            }
+            continue
+        }
+        if forecast.SuggestedAt != nil {
+            continue
+        }
+
+        if err := w.publish(ctx, suggestion); err != nil {
+            log.Printf("⚠️  Failed to publish ReorderSuggested for product %d: %v", forecast.ProductID, err)
+            continue
+        }
+        if err := w.store.MarkReorderSuggested(ctx, forecast.ProductID, w.clock.Now()); err != nil {
+            log.Printf("❌ Failed to mark product %d reorder suggested: %v", forecast.ProductID, err)
+        }
+        published++
+    }
+
+    if err := w.store.ClearReorderSuggested(ctx, recovered); err != nil {
+        log.Printf("❌ Failed to clear reorder suggestions: %v", err)
+    }
+    if published > 0 {
+        log.Printf("✓ Suggested reordering %d product(s)", published)
+    }
+    return published
+}
+
+func (w *ReorderForecastWorker) publish(ctx context.Context, suggestion *models.ReorderSuggestion) error {
+    productID := strconv.FormatInt(suggestion.ProductID, 10)
+    event := events.ReorderSuggestedEvent{
+        BaseEvent:         events.NewBaseEvent("ReorderSuggested", productID, "product", fmt.Sprintf("reorder-%s-%d", productID, w.clock.Now().Unix())),
+        ProductID:         suggestion.ProductID,
+        ProductName:       suggestion.ProductName,
+        DailyVelocity:     suggestion.DailyVelocity,
+        Available:         suggestion.Available,
+        OnOrder:           suggestion.OnOrder,
+        DaysOfStock:       suggestion.DaysOfStock,
+        ReorderPoint:      suggestion.ReorderPoint,
+        SuggestedQuantity: suggestion.SuggestedQuantity,
+        LeadTimeDays:      w.config.Policy.LeadTimeDays,
+        SafetyStockDays:   w.config.Policy.SafetyStockDays,
+    }
+    return w.publisher.PublishProductEvent(ctx, event)
+}
@@ -0,0 +1,96 @@
+package workers
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/products/models"
+    "github.com/sanketh-sg/prost/shared/clock"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// fakeForecasts keeps the forecasts in memory like reorder_forecasts
+type fakeForecasts struct {
+    forecasts []*models.StockForecast
+    cleared   []int64
+}
+
+func (f *fakeForecasts) RefreshForecasts(ctx context.Context, windowDays int) ([]*models.StockForecast, error) {
+    return f.forecasts, nil
+}
+
+func (f *fakeForecasts) MarkReorderSuggested(ctx context.Context, productID int64, at time.Time) error {
+    for _, forecast := range f.forecasts {
+        if forecast.ProductID == productID {
+            forecast.SuggestedAt = &at
+        }
+    }
+    return nil
+}
+
+func (f *fakeForecasts) ClearReorderSuggested(ctx context.Context, productIDs []int64) error {
+    f.cleared = append(f.cleared, productIDs...)
+    for _, id := range productIDs {
+        for _, forecast := range f.forecasts {
+            if forecast.ProductID == id {
+                forecast.SuggestedAt = nil
+            }
+        }
+    }
+    return nil
+}
+
+// fakePublisher records events and fails for the products in failFor
+type fakePublisher struct {
+    published []events.ReorderSuggestedEvent
+    failFor   map[int64]bool
+}
+
+func (f *fakePublisher) PublishProductEvent(ctx context.Context, event interface{}) error {
+    suggested := event.(events.ReorderSuggestedEvent)
+    if f.failFor[suggested.ProductID] {
+        return errors.New("broker down")
+    }
+    f.published = append(f.published, suggested)
+    return nil
+}
+
+func TestReorderForecastWorker_SuggestsOncePerDip(t *testing.T) {
+    // Arrange: 1 unit a day, reorder point 10
+    earlier := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+    store := &fakeForecasts{forecasts: []*models.StockForecast{
+        {ProductID: 1, ProductName: "Mouse", SoldUnits: 28, WindowDays: 28, Available: 4},
+        {ProductID: 2, SoldUnits: 28, WindowDays: 28, Available: 2, SuggestedAt: &earlier},
+        {ProductID: 3, SoldUnits: 28, WindowDays: 28, Available: 50, SuggestedAt: &earlier},
+        {ProductID: 4, SoldUnits: 28, WindowDays: 28, Available: 1},
+    }}
+    publisher := &fakePublisher{failFor: map[int64]bool{4: true}}
+    policy := models.ReorderPolicy{LeadTimeDays: 7, SafetyStockDays: 3, CoverDays: 30}
+    worker := NewReorderForecastWorker(store, publisher, clock.NewFake(earlier.Add(time.Hour)), ReorderForecastConfig{WindowDays: 28, Policy: policy})
+
+    // Act
+    published := worker.RunOnce(context.Background())
+
+    // Assert: 1 is new, 2 was already suggested, 3 recovered, 4 failed to publish
+    if published != 1 || len(publisher.published) != 1 {
+        t.Fatalf("expected 1 event, got %d (%+v)", published, publisher.published)
+    }
+    event := publisher.published[0]
+    if event.ProductID != 1 || event.ProductName != "Mouse" || event.ReorderPoint != 10 || event.SuggestedQuantity != 36 || event.LeadTimeDays != 7 {
+        t.Fatalf("unexpected event %+v", event)
+    }
+    if len(store.cleared) != 1 || store.cleared[0] != 3 {
+        t.Fatalf("expected product 3 cleared, got %v", store.cleared)
+    }
+    if store.forecasts[3].SuggestedAt != nil {
+        t.Fatal("product 4 marked suggested although publishing failed")
+    }
+
+    // Act: the next run retries 4 and doesn't repeat 1
+    publisher.failFor = nil
+    if published := worker.RunOnce(context.Background()); published != 1 || publisher.published[1].ProductID != 4 {
+        t.Fatalf("expected only product 4 on the second run, got %d (%+v)", published, publisher.published)
+    }
+}
@@ -0,0 +1,47 @@
+package workers
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "github.com/sanketh-sg/prost/shared/clock"
+)
+
+// ReservationExpirer expires inventory reservations past their expires_at
+type ReservationExpirer interface {
+    ExpireReservations(ctx context.Context) (int64, error)
+}
+
+// ReservationExpiryWorker periodically expires stale order reservations so stock is freed
+type ReservationExpiryWorker struct {
+    repo     ReservationExpirer
+    clock    clock.Clock
+    interval time.Duration
+}
+
+// NewReservationExpiryWorker creates new reservation expiry worker
+func NewReservationExpiryWorker(repo ReservationExpirer, clk clock.Clock, interval time.Duration) *ReservationExpiryWorker {
+    return &ReservationExpiryWorker{
+        repo:     repo,
+        clock:    clk,
+        interval: interval,
+    }
+}
+
+// Start launches the worker loop; it stops when ctx is cancelled.
+func (w *ReservationExpiryWorker) Start(ctx context.Context) <-chan struct{} {
+    return clock.Every(ctx, w.clock, w.interval, false, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// RunOnce expires reservations a single time
+func (w *ReservationExpiryWorker) RunOnce(ctx context.Context) {
+    expired, err := w.repo.ExpireReservations(ctx)
+    if err != nil {
+        log.Printf("❌ Failed to expire inventory reservations: %v", err)
+        return
+    }
+    if expired > 0 {
+        log.Printf("✓ Expired %d inventory reservation(s)", expired)
+    }
+}
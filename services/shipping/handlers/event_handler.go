@@ -0,0 +1,91 @@
+package handlers
+
+import (
+    "context"
+    "fmt"
+    "log"
+
+    "github.com/sanketh-sg/prost/services/shipping/models"
+    "github.com/sanketh-sg/prost/services/shipping/repository"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/events"
+)
+
+// EventHandler creates shipments from order events (shipping.events.queue)
+type EventHandler struct {
+    shipmentRepo     *repository.ShipmentRepository
+    idempotencyStore *db.IdempotencyStore
+    defaultCarrier   string
+}
+
+// NewEventHandler creates new shipping event handler
+func NewEventHandler(shipmentRepo *repository.ShipmentRepository, idempotencyStore *db.IdempotencyStore, defaultCarrier string) *EventHandler {
+    return &EventHandler{
+        shipmentRepo:     shipmentRepo,
+        idempotencyStore: idempotencyStore,
+        defaultCarrier:   defaultCarrier,
+    }
+}
+
+// HandleEvent routes an incoming event by type
+func (eh *EventHandler) HandleEvent(ctx context.Context, message []byte) error {
+    baseEvent, err := events.ReadEnvelope(message)
+    if err != nil {
+        return err
+    }
+
+    processed, err := eh.idempotencyStore.IsProcessed(ctx, baseEvent.EventID, "shipping")
+    if err != nil {
+        log.Printf("Failed to check idempotency: %v", err)
+    }
+    if processed {
+        log.Printf("Event %s already processed, skipping", baseEvent.EventID)
+        return nil
+    }
+
+    var handlerErr error
+    switch baseEvent.EventType {
+    case "OrderConfirmed":
+        handlerErr = eh.handleOrderConfirmed(ctx, message)
+    default:
+        log.Printf("Ignoring event type: %s", baseEvent.EventType)
+        return nil
+    }
+
+    result := "success"
+    if handlerErr != nil {
+        result = "failed"
+    }
+    if err := eh.idempotencyStore.RecordProcessed(ctx, baseEvent.EventID, "shipping", baseEvent.EventType, result); err != nil {
+        log.Printf("Failed to record idempotency: %v", err)
+    }
+
+    return handlerErr
+}
+
+// handleOrderConfirmed creates a pending shipment with a tracking number
+// Why: shipments are keyed by order ID, so a redelivered event reuses the existing one
+func (eh *EventHandler) handleOrderConfirmed(ctx context.Context, message []byte) error {
+    event, err := events.Unmarshal[events.OrderConfirmedEvent](message)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal OrderConfirmedEvent: %w", err)
+    }
+
+    shipment, err := models.NewShipment(event.OrderID, event.CorrelationID, eh.defaultCarrier)
+    if err != nil {
+        return err
+    }
+
+    created, err := eh.shipmentRepo.CreateShipment(ctx, shipment)
+    if err != nil {
+        return fmt.Errorf("failed to create shipment for order %d: %w", event.OrderID, err)
+    }
+
+    if created {
+        log.Printf("✓ Shipment %d created for order %d (tracking %s)", shipment.ID, event.OrderID, shipment.TrackingNumber)
+    } else {
+        log.Printf("Shipment already exists for order %d", event.OrderID)
+    }
+
+    return nil
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/shipping/models"
+    "github.com/sanketh-sg/prost/services/shipping/repository"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/messaging"
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ShipmentHandler handles shipment HTTP requests
+type ShipmentHandler struct {
+    shipmentRepo   *repository.ShipmentRepository
+    eventPublisher *messaging.Publisher
+}
+
+// NewShipmentHandler creates new shipment handler
+func NewShipmentHandler(shipmentRepo *repository.ShipmentRepository, eventPublisher *messaging.Publisher) *ShipmentHandler {
+    return &ShipmentHandler{
+        shipmentRepo:   shipmentRepo,
+        eventPublisher: eventPublisher,
+    }
+}
+
+// Health handles health check
+func (sh *ShipmentHandler) Health(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{
+        "status":  "healthy",
+        "service": "shipping",
+        "time":    time.Now().UTC(),
+    })
+}
+
+// GetShipment returns a shipment by ID
+func (sh *ShipmentHandler) GetShipment(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseID(c, "id", "invalid shipment id")
+    if !ok {
+        return
+    }
+
+    shipment, err := sh.shipmentRepo.GetShipment(ctx, id)
+    if err != nil {
+        respondShipmentError(c, "failed to get shipment", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, shipment)
+}
+
+// GetShipmentByOrder returns the shipment for an order
+func (sh *ShipmentHandler) GetShipmentByOrder(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    orderID, ok := parseID(c, "order_id", "invalid order id")
+    if !ok {
+        return
+    }
+
+    shipment, err := sh.shipmentRepo.GetShipmentByOrderID(ctx, orderID)
+    if err != nil {
+        respondShipmentError(c, "failed to get shipment", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, shipment)
+}
+
+// GetShipments lists shipments, optionally filtered by ?status=
+func (sh *ShipmentHandler) GetShipments(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    shipments, err := sh.shipmentRepo.ListShipments(ctx, c.Query("status"))
+    if err != nil {
+        respondShipmentError(c, "failed to list shipments", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "shipments": shipments,
+        "count":     len(shipments),
+    })
+}
+
+// Ship hands a pending shipment to the carrier and publishes OrderShipped
+func (sh *ShipmentHandler) Ship(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseID(c, "id", "invalid shipment id")
+    if !ok {
+        return
+    }
+
+    var req models.ShipRequest
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid request body",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    shippedAt := time.Now().UTC()
+    if req.ShippedAt != nil {
+        shippedAt = req.ShippedAt.UTC()
+    }
+
+    shipment, err := sh.shipmentRepo.MarkShipped(ctx, id, req.Carrier, shippedAt)
+    if err != nil {
+        respondShipmentError(c, "failed to ship shipment", err)
+        return
+    }
+
+    // Why: the shipment is already shipped; a failed publish is logged, not returned,
+    // so the warehouse isn't asked to ship the parcel twice
+    shippedEvent := events.OrderShippedEvent{
+        BaseEvent:      events.NewBaseEvent("OrderShipped", fmt.Sprintf("%d", shipment.OrderID), "order", shipment.CorrelationID),
+        OrderID:        shipment.OrderID,
+        TrackingNumber: shipment.TrackingNumber,
+        Carrier:        shipment.Carrier,
+        ShippedAt:      *shipment.ShippedAt,
+    }
+    if err := sh.eventPublisher.PublishOrderEvent(ctx, shippedEvent); err != nil {
+        log.Printf("⚠️  Failed to publish OrderShippedEvent: %v", err)
+    }
+
+    log.Printf("✓ Shipment %d shipped via %s (order %d, tracking %s)", shipment.ID, shipment.Carrier, shipment.OrderID, shipment.TrackingNumber)
+
+    c.JSON(http.StatusOK, shipment)
+}
+
+// Deliver marks a shipped shipment delivered and publishes OrderDelivered
+func (sh *ShipmentHandler) Deliver(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    id, ok := parseID(c, "id", "invalid shipment id")
+    if !ok {
+        return
+    }
+
+    var req models.DeliverRequest
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid request body",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    deliveredAt := time.Now().UTC()
+    if req.DeliveredAt != nil {
+        deliveredAt = req.DeliveredAt.UTC()
+    }
+
+    shipment, err := sh.shipmentRepo.MarkDelivered(ctx, id, deliveredAt)
+    if err != nil {
+        respondShipmentError(c, "failed to deliver shipment", err)
+        return
+    }
+
+    deliveredEvent := events.OrderDeliveredEvent{
+        BaseEvent:      events.NewBaseEvent("OrderDelivered", fmt.Sprintf("%d", shipment.OrderID), "order", shipment.CorrelationID),
+        OrderID:        shipment.OrderID,
+        TrackingNumber: shipment.TrackingNumber,
+        DeliveredAt:    *shipment.DeliveredAt,
+    }
+    if err := sh.eventPublisher.PublishOrderEvent(ctx, deliveredEvent); err != nil {
+        log.Printf("⚠️  Failed to publish OrderDeliveredEvent: %v", err)
+    }
+
+    log.Printf("✓ Shipment %d delivered (order %d)", shipment.ID, shipment.OrderID)
+
+    c.JSON(http.StatusOK, shipment)
+}
+
+func parseID(c *gin.Context, param, msg string) (int64, bool) {
+    id, err := strconv.ParseInt(c.Param(param), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   msg,
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return 0, false
+    }
+    return id, true
+}
+
+// respondShipmentError maps repository errors to HTTP statuses
+func respondShipmentError(c *gin.Context, msg string, err error) {
+    status := db.ErrorStatus(err)
+    switch {
+    case errors.Is(err, repository.ErrShipmentNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, repository.ErrInvalidTransition):
+        status = http.StatusConflict
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   msg,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/services/shipping/handlers"
+	"github.com/sanketh-sg/prost/services/shipping/migrations"
+	"github.com/sanketh-sg/prost/services/shipping/repository"
+	"github.com/sanketh-sg/prost/shared/clock"
+	"github.com/sanketh-sg/prost/shared/config"
+	"github.com/sanketh-sg/prost/shared/compression"
+	"github.com/sanketh-sg/prost/shared/cors"
+	"github.com/sanketh-sg/prost/shared/db"
+	"github.com/sanketh-sg/prost/shared/httpserver"
+	"github.com/sanketh-sg/prost/shared/messaging"
+	"github.com/sanketh-sg/prost/shared/metrics"
+)
+
+func main() {
+    // Configuration: flags, then the environment, then .env (see shared/config)
+    var cfg config.Shipping
+    config.LoadOrDie(&cfg)
+
+    // Gin mode (GIN_MODE): release unless set, so production logs no route table or debug warnings
+    gin.SetMode(cfg.HTTP.GinMode)
+
+    log.Println("=== Shipping Service Starting ===")
+    log.Printf("Service: %s", cfg.Name)
+    log.Printf("Port: %s", cfg.Port)
+    log.Printf("Schema: %s", cfg.Schema)
+
+    // Database connection
+    log.Println("\nConnecting to PostgreSQL...")
+    dbConn, err := db.NewDBConnection(cfg.Database.Connection(cfg.Schema))
+    if err != nil {
+        log.Fatalf("Database connection failed: %v", err)
+    }
+    defer dbConn.DBConnClose()
+    metrics.RegisterDBStats(cfg.Schema, dbConn)
+    if cfg.Database.AutoMigrate {
+        if err := dbConn.Migrate(context.Background(), migrations.FS); err != nil {
+            log.Fatalf("Database migration failed: %v", err)
+        }
+    }
+
+    log.Println("✓ Database connected")
+
+    // RabbitMQ connection
+    log.Println("\nConnecting to RabbitMQ...")
+    rmqConn, err := messaging.NewRmqConnection(cfg.RabbitMQURL)
+    if err != nil {
+        log.Fatalf("RabbitMQ connection failed: %v", err)
+    }
+    defer rmqConn.Close()
+
+    // Setup RabbitMQ topology
+    topology := messaging.GetProstTopology()
+    if err := rmqConn.SetupRabbitMQ(topology); err != nil {
+        log.Fatalf("RabbitMQ setup failed: %v", err)
+    }
+    log.Println("✓ RabbitMQ connected and topology ready")
+
+    // Initialize repositories
+    shipmentRepo := repository.NewShipmentRepository(dbConn)
+    idempotencyStore := db.NewIdempotencyStore(dbConn)
+
+    // Initialize event publisher (for shipping.events exchange)
+    publisher := messaging.NewPublisher(rmqConn, "shipping.events")
+    defer publisher.Close()
+
+    // Initialize event subscriber (OrderConfirmed from orders.events)
+    subscriber := messaging.NewSubscriber(rmqConn, "shipping.events.queue")
+
+    // Flips /ready to failing when the subscriber is alive but not progressing
+    watchdog := messaging.NewWatchdog(messaging.DefaultWatchdogConfig(), clock.New(), subscriber)
+
+    // Initialize handlers
+    shipmentHandler := handlers.NewShipmentHandler(shipmentRepo, publisher)
+    eventHandler := handlers.NewEventHandler(shipmentRepo, idempotencyStore, cfg.DefaultCarrier)
+
+    // HTTP limits: timeouts and body size, with per-route overrides (HTTP_* settings)
+    httpDefaults := httpserver.DefaultConfig()
+    httpDefaults.WriteTimeout = 30 * time.Second
+    httpDefaults.IdleTimeout = 120 * time.Second
+    httpConfig, err := httpserver.Configure(httpDefaults, cfg.HTTP)
+    if err != nil {
+        log.Fatalf("Invalid HTTP configuration: %v", err)
+    }
+
+    // Create Gin router
+    router := gin.New()
+
+    // Add middleware
+    router.Use(gin.Logger())
+    router.Use(gin.Recovery())
+    router.Use(cors.NewPolicy(cors.LoadConfig(cors.DefaultConfig())).Middleware())
+    router.Use(httpConfig.Middleware())
+    router.Use(compression.LoadConfig(compression.DefaultConfig()).Middleware())
+
+    // Public routes
+    router.GET("/health", shipmentHandler.Health)
+    router.GET("/ready", gin.WrapH(watchdog))
+    router.GET("/metrics", gin.WrapH(metrics.Handler()))
+    router.GET("/shipments", shipmentHandler.GetShipments)
+    router.GET("/shipments/:id", shipmentHandler.GetShipment)
+    router.GET("/orders/:order_id/shipment", shipmentHandler.GetShipmentByOrder)
+
+    // Warehouse/carrier updates
+    router.POST("/shipments/:id/ship", shipmentHandler.Ship)
+    router.POST("/shipments/:id/deliver", shipmentHandler.Deliver)
+
+    // Server setup
+    srv := httpConfig.NewServer(":"+cfg.Port, router)
+
+    // Start event subscriber in background
+    log.Println("\nStarting event subscriber...")
+    go func() {
+        if err := subscriber.Subscribe(func(message []byte) error {
+            ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+            defer cancel()
+
+            return eventHandler.HandleEvent(ctx, message)
+        }); err != nil {
+            log.Printf("Subscriber error: %v", err)
+        }
+    }()
+
+    // Start subscriber watchdog
+    watchdogCtx, stopWatchdog := context.WithCancel(context.Background())
+    defer stopWatchdog()
+    watchdog.Start(watchdogCtx)
+
+    // Start server in goroutine
+    log.Printf("\n✓ Shipping service listening on :%s", cfg.Port)
+    log.Println("\n=== Service Ready ===")
+
+    go func() {
+        if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+            log.Fatalf("Server error: %v", err)
+        }
+    }()
+
+    // Graceful shutdown
+    sigChan := make(chan os.Signal, 1)
+    signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+    sig := <-sigChan
+    log.Printf("\nReceived signal: %v", sig)
+    log.Println("Shutting down gracefully...")
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    // Stop consuming and let in-flight handlers finish; the deferred closes then
+    // shut the AMQP connection and the database, in that order
+    if err := messaging.DrainSubscribers(ctx, subscriber); err != nil {
+        log.Printf("Drain error: %v", err)
+    }
+
+    if err := srv.Shutdown(ctx); err != nil {
+        log.Printf("Shutdown error: %v", err)
+    }
+
+    log.Println("✓ Service stopped")
+}
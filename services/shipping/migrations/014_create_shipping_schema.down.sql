@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shipping.idempotency_records;
+DROP TABLE IF EXISTS shipping.shipments;
+DROP SCHEMA IF EXISTS shipping;
@@ -0,0 +1,29 @@
+CREATE SCHEMA IF NOT EXISTS shipping;
+
+-- One shipment per confirmed order
+CREATE TABLE IF NOT EXISTS shipping.shipments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL UNIQUE,
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    tracking_number VARCHAR(100) NOT NULL UNIQUE,
+    carrier VARCHAR(100) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- pending, shipped, delivered
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    shipped_at TIMESTAMP NULL,
+    delivered_at TIMESTAMP NULL
+);
+
+-- Create idempotency records table
+CREATE TABLE IF NOT EXISTS shipping.idempotency_records (
+    id SERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    service_name VARCHAR(50) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    result VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(event_id, service_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_status ON shipping.shipments(status);
+CREATE INDEX IF NOT EXISTS idx_shipping_idempotency_records_event_id ON shipping.idempotency_records(event_id, service_name);
@@ -0,0 +1,11 @@
+// Package migrations embeds the shipping schema's migrations, so the service can apply them at
+// startup (AUTO_MIGRATE)
+package migrations
+
+import "embed"
+
+// FS holds the NNN_name.up.sql and NNN_name.down.sql files; versions keep the numbers of the
+// single sequence they were split from
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,88 @@
+package models
+
+import (
+    "crypto/rand"
+    "fmt"
+    "math/big"
+    "time"
+)
+
+// Shipment statuses
+const (
+    ShipmentStatusPending   = "pending"
+    ShipmentStatusShipped   = "shipped"
+    ShipmentStatusDelivered = "delivered"
+)
+
+// trackingPrefix starts every tracking number issued by this service
+const trackingPrefix = "PRST"
+
+// Shipment is the shipment of one confirmed order
+type Shipment struct {
+    ID             int64      `json:"id"`
+    OrderID        int64      `json:"order_id"`
+    CorrelationID  string     `json:"correlation_id"` // order saga correlation ID
+    TrackingNumber string     `json:"tracking_number"`
+    Carrier        string     `json:"carrier"`
+    Status         string     `json:"status"` // pending, shipped, delivered
+    CreatedAt      time.Time  `json:"created_at"`
+    UpdatedAt      time.Time  `json:"updated_at"`
+    ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+    DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// ShipRequest request body for handing a shipment to the carrier
+type ShipRequest struct {
+    Carrier   string     `json:"carrier"` // overrides the carrier picked at creation
+    ShippedAt *time.Time `json:"shipped_at"`
+}
+
+// DeliverRequest request body for confirming delivery
+type DeliverRequest struct {
+    DeliveredAt *time.Time `json:"delivered_at"`
+}
+
+// ErrorResponse standard error response
+type ErrorResponse struct {
+    Error   string `json:"error"`
+    Message string `json:"message"`
+    Code    int    `json:"code"`
+}
+
+// NewShipment creates a pending shipment with a fresh tracking number
+func NewShipment(orderID int64, correlationID, carrier string) (*Shipment, error) {
+    trackingNumber, err := NewTrackingNumber()
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now().UTC()
+    return &Shipment{
+        OrderID:        orderID,
+        CorrelationID:  correlationID,
+        TrackingNumber: trackingNumber,
+        Carrier:        carrier,
+        Status:         ShipmentStatusPending,
+        CreatedAt:      now,
+        UpdatedAt:      now,
+    }, nil
+}
+
+// NewTrackingNumber returns a random tracking number like PRST4821930475
+func NewTrackingNumber() (string, error) {
+    n, err := rand.Int(rand.Reader, big.NewInt(1e10))
+    if err != nil {
+        return "", fmt.Errorf("failed to generate tracking number: %w", err)
+    }
+    return fmt.Sprintf("%s%010d", trackingPrefix, n.Int64()), nil
+}
+
+// CanShip reports whether the shipment can be handed to the carrier
+func (s *Shipment) CanShip() bool {
+    return s.Status == ShipmentStatusPending
+}
+
+// CanDeliver reports whether the shipment can be marked delivered
+func (s *Shipment) CanDeliver() bool {
+    return s.Status == ShipmentStatusShipped
+}
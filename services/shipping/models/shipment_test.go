@@ -0,0 +1,45 @@
+package models
+
+import (
+    "regexp"
+    "testing"
+)
+
+func TestNewShipment(t *testing.T) {
+    s, err := NewShipment(42, "corr-1", "prost-express")
+    if err != nil {
+        t.Fatalf("NewShipment: %v", err)
+    }
+
+    if !regexp.MustCompile(`^PRST\d{10}$`).MatchString(s.TrackingNumber) {
+        t.Errorf("tracking number %q does not match PRST + 10 digits", s.TrackingNumber)
+    }
+    if s.Status != ShipmentStatusPending {
+        t.Errorf("status = %q, want %q", s.Status, ShipmentStatusPending)
+    }
+    if s.OrderID != 42 || s.CorrelationID != "corr-1" || s.Carrier != "prost-express" {
+        t.Errorf("unexpected shipment fields: %+v", s)
+    }
+}
+
+func TestShipmentTransitions(t *testing.T) {
+    tests := []struct {
+        status     string
+        canShip    bool
+        canDeliver bool
+    }{
+        {ShipmentStatusPending, true, false},
+        {ShipmentStatusShipped, false, true},
+        {ShipmentStatusDelivered, false, false},
+    }
+
+    for _, tt := range tests {
+        s := &Shipment{Status: tt.status}
+        if got := s.CanShip(); got != tt.canShip {
+            t.Errorf("%s: CanShip() = %v, want %v", tt.status, got, tt.canShip)
+        }
+        if got := s.CanDeliver(); got != tt.canDeliver {
+            t.Errorf("%s: CanDeliver() = %v, want %v", tt.status, got, tt.canDeliver)
+        }
+    }
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/shipping/models"
+    "github.com/sanketh-sg/prost/shared/db"
+)
+
+var (
+    // ErrShipmentNotFound is returned when no shipment matches
+    ErrShipmentNotFound = errors.New("shipment not found")
+    // ErrInvalidTransition is returned when shipping a non-pending or delivering a non-shipped shipment
+    ErrInvalidTransition = errors.New("invalid shipment status transition")
+)
+
+const shipmentColumns = `id, order_id, correlation_id, tracking_number, carrier, status,
+    created_at, updated_at, shipped_at, delivered_at`
+
+// ShipmentRepository handles shipment database operations
+type ShipmentRepository struct {
+    conn *db.Connection
+}
+
+// NewShipmentRepository creates new shipment repository
+func NewShipmentRepository(conn *db.Connection) *ShipmentRepository {
+    return &ShipmentRepository{conn: conn}
+}
+
+// CreateShipment inserts a shipment for an order. If the order already has one
+// (redelivered OrderConfirmed) the existing shipment is loaded and created is false.
+func (sr *ShipmentRepository) CreateShipment(ctx context.Context, shipment *models.Shipment) (bool, error) {
+    query := `
+        INSERT INTO $schema.shipments
+        (order_id, correlation_id, tracking_number, carrier, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (order_id) DO NOTHING
+        RETURNING ` + shipmentColumns
+    query = sr.conn.Qualify(query)
+
+    created, err := scanShipment(sr.conn.QueryRowContext(ctx, query,
+        shipment.OrderID,
+        shipment.CorrelationID,
+        shipment.TrackingNumber,
+        shipment.Carrier,
+        shipment.Status,
+        shipment.CreatedAt,
+        shipment.UpdatedAt,
+    ))
+    if err == sql.ErrNoRows {
+        existing, err := sr.GetShipmentByOrderID(ctx, shipment.OrderID)
+        if err != nil {
+            return false, err
+        }
+        *shipment = *existing
+        return false, nil
+    }
+    if err != nil {
+        return false, fmt.Errorf("failed to create shipment: %w", err)
+    }
+
+    *shipment = *created
+    return true, nil
+}
+
+// GetShipment retrieves a shipment by ID
+func (sr *ShipmentRepository) GetShipment(ctx context.Context, id int64) (*models.Shipment, error) {
+    query := sr.conn.Qualify(`SELECT `+shipmentColumns+` FROM $schema.shipments WHERE id = $1`)
+
+    shipment, err := scanShipment(sr.conn.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, ErrShipmentNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get shipment: %w", err)
+    }
+    return shipment, nil
+}
+
+// GetShipmentByOrderID retrieves the shipment for an order
+func (sr *ShipmentRepository) GetShipmentByOrderID(ctx context.Context, orderID int64) (*models.Shipment, error) {
+    query := sr.conn.Qualify(`SELECT `+shipmentColumns+` FROM $schema.shipments WHERE order_id = $1`)
+
+    shipment, err := scanShipment(sr.conn.QueryRowContext(ctx, query, orderID))
+    if err == sql.ErrNoRows {
+        return nil, ErrShipmentNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get shipment: %w", err)
+    }
+    return shipment, nil
+}
+
+// ListShipments lists shipments, newest first, optionally filtered by status
+func (sr *ShipmentRepository) ListShipments(ctx context.Context, status string) ([]*models.Shipment, error) {
+    query := `SELECT ` + shipmentColumns + ` FROM $schema.shipments`
+    args := []interface{}{}
+    if status != "" {
+        query += ` WHERE status = $1`
+        args = append(args, status)
+    }
+    query += ` ORDER BY created_at DESC`
+    query = sr.conn.Qualify(query)
+
+    rows, err := sr.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list shipments: %w", err)
+    }
+    defer rows.Close()
+
+    shipments := []*models.Shipment{}
+    for rows.Next() {
+        shipment, err := scanShipment(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan shipment: %w", err)
+        }
+        shipments = append(shipments, shipment)
+    }
+
+    return shipments, rows.Err()
+}
+
+// MarkShipped moves a pending shipment to shipped
+func (sr *ShipmentRepository) MarkShipped(ctx context.Context, id int64, carrier string, shippedAt time.Time) (*models.Shipment, error) {
+    query := `
+        UPDATE $schema.shipments
+        SET status = 'shipped', carrier = COALESCE(NULLIF($1, ''), carrier), shipped_at = $2, updated_at = $3
+        WHERE id = $4 AND status = 'pending'
+        RETURNING ` + shipmentColumns
+    query = sr.conn.Qualify(query)
+
+    shipment, err := scanShipment(sr.conn.QueryRowContext(ctx, query, carrier, shippedAt, time.Now().UTC(), id))
+    if err == sql.ErrNoRows {
+        return nil, sr.transitionError(ctx, id, models.ShipmentStatusShipped)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to mark shipment shipped: %w", err)
+    }
+    return shipment, nil
+}
+
+// MarkDelivered moves a shipped shipment to delivered
+func (sr *ShipmentRepository) MarkDelivered(ctx context.Context, id int64, deliveredAt time.Time) (*models.Shipment, error) {
+    query := `
+        UPDATE $schema.shipments
+        SET status = 'delivered', delivered_at = $1, updated_at = $2
+        WHERE id = $3 AND status = 'shipped'
+        RETURNING ` + shipmentColumns
+    query = sr.conn.Qualify(query)
+
+    shipment, err := scanShipment(sr.conn.QueryRowContext(ctx, query, deliveredAt, time.Now().UTC(), id))
+    if err == sql.ErrNoRows {
+        return nil, sr.transitionError(ctx, id, models.ShipmentStatusDelivered)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to mark shipment delivered: %w", err)
+    }
+    return shipment, nil
+}
+
+// transitionError explains why a status update matched no rows
+func (sr *ShipmentRepository) transitionError(ctx context.Context, id int64, to string) error {
+    existing, err := sr.GetShipment(ctx, id)
+    if err != nil {
+        return err
+    }
+    return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, existing.Status, to)
+}
+
+func scanShipment(row interface{ Scan(...interface{}) error }) (*models.Shipment, error) {
+    shipment := &models.Shipment{}
+    err := row.Scan(
+        &shipment.ID,
+        &shipment.OrderID,
+        &shipment.CorrelationID,
+        &shipment.TrackingNumber,
+        &shipment.Carrier,
+        &shipment.Status,
+        &shipment.CreatedAt,
+        &shipment.UpdatedAt,
+        &shipment.ShippedAt,
+        &shipment.DeliveredAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return shipment, nil
+}
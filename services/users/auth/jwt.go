@@ -5,11 +5,12 @@ import (
     "time"
 
     "github.com/golang-jwt/jwt/v5"
+    "github.com/sanketh-sg/prost/shared/jwtkeys"
 )
 
 // JWTManager handles JWT token generation and validation
 type JWTManager struct {
-    secret string
+    keys *jwtkeys.KeySet
 }
 
 // Claims extends jwt.RegisteredClaims with custom claims
@@ -17,6 +18,7 @@ type Claims struct {
     UserID   string `json:"user_id"`
     Email    string `json:"email"`
     Username string `json:"username"`
+    Role     string `json:"role,omitempty"`
     jwt.RegisteredClaims  // It includes standard claims like ExpiresAt, IssuedAt, etc.
 }
 
@@ -26,19 +28,36 @@ type RefreshClaims struct {
     jwt.RegisteredClaims
 }
 
-// NewJWTManager creates a new JWT manager
+// NewJWTManager creates a new JWT manager signing with a single HS256 secret
 func NewJWTManager(secret string) *JWTManager {
-    return &JWTManager{secret: secret}
+    return &JWTManager{keys: jwtkeys.NewHMACKeySet(secret)}
+}
+
+// NewJWTManagerWithKeys creates a JWT manager that signs with the key set's signing key
+// (kid header set) and accepts tokens signed by any of its keys
+func NewJWTManagerWithKeys(keys *jwtkeys.KeySet) *JWTManager {
+    return &JWTManager{keys: keys}
+}
+
+// JWKS returns the public keys other services verify tokens with
+func (jm *JWTManager) JWKS() jwtkeys.JWKS {
+    return jm.keys.JWKS()
 }
 
 // GenerateToken generates a new JWT token with user claims and expiration
 func (jm *JWTManager) GenerateToken(userID, email, username string, expiresIn time.Duration) (string, time.Time, error) {
+    return jm.GenerateTokenWithRole(userID, email, username, "customer", expiresIn)
+}
+
+// GenerateTokenWithRole generates a JWT token that also carries the user's role
+func (jm *JWTManager) GenerateTokenWithRole(userID, email, username, role string, expiresIn time.Duration) (string, time.Time, error) {
     expiresAt := time.Now().UTC().Add(expiresIn)
 
     claims := Claims{
         UserID:   userID,
         Email:    email,
         Username: username,
+        Role:     role,
         RegisteredClaims: jwt.RegisteredClaims{
             ExpiresAt: jwt.NewNumericDate(expiresAt),
             IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
@@ -46,8 +65,7 @@ func (jm *JWTManager) GenerateToken(userID, email, username string, expiresIn ti
             Issuer:    "prost-users-service",
         },
     }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString([]byte(jm.secret))
+    tokenString, err := jm.keys.Sign(claims)
     if err != nil {
         return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
     }
@@ -68,8 +86,7 @@ func (jm *JWTManager) GenerateRefreshToken(userID string, expiresIn time.Duratio
             Issuer:    "prost-users-service",
         },
     }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString([]byte(jm.secret))
+    tokenString, err := jm.keys.Sign(claims)
     if err != nil {
         return "", time.Time{}, fmt.Errorf("failed to sign refresh token: %w", err)
     }
@@ -82,12 +99,7 @@ func (jm *JWTManager) GenerateRefreshToken(userID string, expiresIn time.Duratio
 func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
     claims := &Claims{}
 
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(jm.secret), nil
-    })
+    token, err := jm.keys.ParseWithClaims(tokenString, claims)
 
     if err != nil {
         return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -104,12 +116,7 @@ func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 func (jm *JWTManager) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
     claims := &RefreshClaims{}
 
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(jm.secret), nil
-    })
+    token, err := jm.keys.ParseWithClaims(tokenString, claims)
 
     if err != nil {
         return nil, fmt.Errorf("failed to parse refresh token: %w", err)
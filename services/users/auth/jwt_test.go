@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,4 +31,21 @@ func TestValidateToken(t *testing.T){
 	assert.Equal(t, "user123", claims.UserID)
 	assert.Equal(t, "test@example.com",claims.Email)
 
-}
\ No newline at end of file
+}
+func TestRotatedKeyStillValidates(t *testing.T){
+	before, err := jwtkeys.NewKeySet(jwtkeys.Config{Keys: "k1=HS256:first"})
+	assert.NoError(t, err)
+	after, err := jwtkeys.NewKeySet(jwtkeys.Config{Keys: "k1=HS256:first;k2=HS256:second", SigningKeyID: "k2"})
+	assert.NoError(t, err)
+
+	oldToken, _, err := NewJWTManagerWithKeys(before).GenerateToken("user123", "test@example.com", "testuser", 1*time.Hour)
+	assert.NoError(t, err)
+
+	// Tokens signed before the rotation keep working until k1 is removed
+	claims, err := NewJWTManagerWithKeys(after).ValidateToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+
+	_, err = NewJWTManager("first").ValidateToken(oldToken)
+	assert.Error(t, err, "a kid token must not fall back to the legacy secret")
+}
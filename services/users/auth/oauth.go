@@ -7,9 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
+
+	"github.com/sanketh-sg/prost/shared/config"
 )
 
 // OAuthManager handles OAuth 2.0 with Auth0
@@ -50,12 +51,12 @@ type UserInfo struct {
     Verified bool   `json:"email_verified"`
 }
 
-func NewOAuthManager() *OAuthManager {
+func NewOAuthManager(auth0 config.Auth0) *OAuthManager {
 	return &OAuthManager{
-		clientID: os.Getenv("AUTH0_CLIENT_ID"),
-		clientSecret: os.Getenv("AUTH0_CLIENT_SECRET"),
-		redirectURI: os.Getenv("AUTH0_REDIRECT_URI"),
-		auth0Domain: os.Getenv("AUTH0_DOMAIN"),
+		clientID: auth0.ClientID,
+		clientSecret: auth0.ClientSecret,
+		redirectURI: auth0.RedirectURI,
+		auth0Domain: auth0.Domain,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
         },
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/config"
+	"github.com/sanketh-sg/prost/shared/metrics"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// argon2id salt and key sizes, from the RFC 9106 recommendations
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHasher hashes new passwords with the configured algorithm and verifies hashes made
+// with any supported one. Why: raising the bcrypt cost or moving to argon2id must not lock out
+// users whose hashes were made before; they are rehashed the next time they log in.
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
+
+// argon2Params are the parameters of an argon2id hash
+type argon2Params struct {
+	memory      uint32 // KiB
+	iterations  uint32
+	parallelism uint8
+	keyLength   uint32
+}
+
+// NewPasswordHasher creates a hasher from the PASSWORD_* settings
+func NewPasswordHasher(cfg config.PasswordHashing) *PasswordHasher {
+	return &PasswordHasher{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2: argon2Params{
+			memory:      uint32(cfg.Argon2MemoryKiB),
+			iterations:  uint32(cfg.Argon2Iterations),
+			parallelism: uint8(cfg.Argon2Parallelism),
+			keyLength:   argon2KeyLength,
+		},
+	}
+}
+
+// Algorithm returns the algorithm new hashes are made with
+func (ph *PasswordHasher) Algorithm() string {
+	return ph.algorithm
+}
+
+// Hash hashes a password with the configured algorithm
+func (ph *PasswordHasher) Hash(password string) (string, error) {
+	defer observeHashing(ph.algorithm, "hash", time.Now())
+
+	if ph.algorithm == AlgorithmArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+		return encodeArgon2(ph.argon2, salt, deriveArgon2(ph.argon2, salt, password)), nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), ph.bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify checks a password against a bcrypt or argon2id hash
+func (ph *PasswordHasher) Verify(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		defer observeHashing(AlgorithmArgon2id, "verify", time.Now())
+
+		params, salt, key, err := decodeArgon2(hash)
+		if err != nil {
+			return false
+		}
+		return subtle.ConstantTimeCompare(key, deriveArgon2(params, salt, password)) == 1
+	}
+
+	defer observeHashing(AlgorithmBcrypt, "verify", time.Now())
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash reports whether a stored hash should be replaced: it was made with another
+// algorithm, or with a lower cost, less memory or fewer passes than configured
+func (ph *PasswordHasher) NeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if ph.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2(hash)
+		if err != nil {
+			return true
+		}
+		return params.memory < ph.argon2.memory ||
+			params.iterations < ph.argon2.iterations ||
+			params.keyLength < ph.argon2.keyLength
+	}
+
+	if ph.algorithm != AlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < ph.bcryptCost
+}
+
+func observeHashing(algorithm, operation string, start time.Time) {
+	metrics.PasswordHashDuration.WithLabelValues(algorithm, operation).Observe(time.Since(start).Seconds())
+}
+
+func deriveArgon2(params argon2Params, salt []byte, password string) []byte {
+	return argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, params.keyLength)
+}
+
+// encodeArgon2 writes the PHC string format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func encodeArgon2(params argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+func decodeArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	params.keyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
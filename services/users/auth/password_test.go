@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sanketh-sg/prost/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testHashing(algorithm string) config.PasswordHashing {
+	return config.PasswordHashing{Algorithm: algorithm, BcryptCost: 4, Argon2MemoryKiB: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+}
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	ph := NewPasswordHasher(testHashing(AlgorithmArgon2id))
+
+	hash, err := ph.Hash("password123")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"), hash)
+	assert.True(t, ph.Verify(hash, "password123"))
+	assert.False(t, ph.Verify(hash, "password124"))
+	assert.False(t, ph.NeedsRehash(hash))
+}
+
+func TestPasswordHasherVerifiesEitherAlgorithm(t *testing.T) {
+	bcryptHash, _ := NewPasswordHasher(testHashing(AlgorithmBcrypt)).Hash("password123")
+	argon2Hash, _ := NewPasswordHasher(testHashing(AlgorithmArgon2id)).Hash("password123")
+
+	for _, algorithm := range []string{AlgorithmBcrypt, AlgorithmArgon2id} {
+		ph := NewPasswordHasher(testHashing(algorithm))
+		assert.True(t, ph.Verify(bcryptHash, "password123"), algorithm)
+		assert.True(t, ph.Verify(argon2Hash, "password123"), algorithm)
+	}
+	assert.False(t, NewPasswordHasher(testHashing(AlgorithmBcrypt)).Verify("$argon2id$garbage", "password123"))
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	weak := testHashing(AlgorithmBcrypt)
+	bcryptHash, _ := NewPasswordHasher(weak).Hash("password123")
+	argon2Hash, _ := NewPasswordHasher(testHashing(AlgorithmArgon2id)).Hash("password123")
+
+	stronger := weak
+	stronger.BcryptCost = 5
+	assert.True(t, NewPasswordHasher(stronger).NeedsRehash(bcryptHash), "lower bcrypt cost")
+	assert.True(t, NewPasswordHasher(testHashing(AlgorithmArgon2id)).NeedsRehash(bcryptHash), "bcrypt to argon2id")
+	assert.True(t, NewPasswordHasher(weak).NeedsRehash(argon2Hash), "argon2id to bcrypt")
+
+	moreMemory := testHashing(AlgorithmArgon2id)
+	moreMemory.Argon2MemoryKiB = 128
+	assert.True(t, NewPasswordHasher(moreMemory).NeedsRehash(argon2Hash), "less argon2id memory")
+
+	weaker := stronger
+	weaker.BcryptCost = 4
+	hash, _ := NewPasswordHasher(stronger).Hash("password123")
+	assert.False(t, NewPasswordHasher(weaker).NeedsRehash(hash), "a stronger hash is kept")
+}
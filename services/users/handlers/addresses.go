@@ -0,0 +1,237 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+    "github.com/sanketh-sg/prost/services/users/models"
+    "github.com/sanketh-sg/prost/services/users/repository"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// GetAddresses handles listing the caller's saved addresses
+// @Summary List addresses
+// @Description Saved shipping addresses, the default first (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} models.ErrorResponse
+// @Router /profile/{id}/addresses [get]
+func (uh *UserHandler) GetAddresses(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    addresses, err := uh.userRepo.ListAddresses(ctx, userID)
+    if err != nil {
+        respondAddressError(c, "failed to list addresses", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "addresses": addresses,
+        "count":     len(addresses),
+    })
+}
+
+// GetAddress handles reading one of the caller's addresses
+// @Summary Get an address
+// @Tags profile
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} models.Address
+// @Failure 404 {object} models.ErrorResponse
+// @Router /profile/{id}/addresses/{address_id} [get]
+func (uh *UserHandler) GetAddress(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    address, err := uh.userRepo.GetAddress(ctx, userID, c.Param("address_id"))
+    if err != nil {
+        respondAddressError(c, "failed to get address", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, address)
+}
+
+// CreateAddress handles saving a new address for the caller
+// @Summary Add an address
+// @Description The first address becomes the default; is_default moves the default to the new one (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.AddressRequest true "Address"
+// @Success 201 {object} models.Address
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /profile/{id}/addresses [post]
+func (uh *UserHandler) CreateAddress(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    req, ok := bindAddressRequest(c)
+    if !ok {
+        return
+    }
+
+    now := time.Now().UTC()
+    address := &models.Address{
+        ID:        uuid.New().String(),
+        UserID:    userID,
+        CreatedAt: now,
+        UpdatedAt: now,
+    }
+    req.Apply(address)
+
+    if err := uh.userRepo.CreateAddress(ctx, address); err != nil {
+        respondAddressError(c, "failed to create address", err)
+        return
+    }
+
+    log.Printf("✓ Address %s added for user %s", address.ID, userID)
+
+    c.JSON(http.StatusCreated, address)
+}
+
+// UpdateAddress handles replacing one of the caller's addresses
+// @Summary Update an address
+// @Description Replaces every field; orders already placed keep their copy (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Param request body models.AddressRequest true "Address"
+// @Success 200 {object} models.Address
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /profile/{id}/addresses/{address_id} [put]
+func (uh *UserHandler) UpdateAddress(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    req, ok := bindAddressRequest(c)
+    if !ok {
+        return
+    }
+
+    address := &models.Address{
+        ID:        c.Param("address_id"),
+        UserID:    userID,
+        UpdatedAt: time.Now().UTC(),
+    }
+    req.Apply(address)
+
+    if err := uh.userRepo.UpdateAddress(ctx, address); err != nil {
+        respondAddressError(c, "failed to update address", err)
+        return
+    }
+
+    log.Printf("✓ Address %s updated for user %s", address.ID, userID)
+
+    c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress handles removing one of the caller's addresses
+// @Summary Delete an address
+// @Tags profile
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Router /profile/{id}/addresses/{address_id} [delete]
+func (uh *UserHandler) DeleteAddress(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    if err := uh.userRepo.DeleteAddress(ctx, userID, c.Param("address_id")); err != nil {
+        respondAddressError(c, "failed to delete address", err)
+        return
+    }
+
+    log.Printf("✓ Address %s deleted for user %s", c.Param("address_id"), userID)
+
+    c.Status(http.StatusNoContent)
+}
+
+// bindAddressRequest decodes, normalizes and validates an address body, or writes the 400
+func bindAddressRequest(c *gin.Context) (models.AddressRequest, bool) {
+    var req models.AddressRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return req, false
+    }
+
+    req.Normalize()
+    if valid, msg := req.Validate(); !valid {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid address",
+            Message: msg,
+            Code:    http.StatusBadRequest,
+        })
+        return req, false
+    }
+    return req, true
+}
+
+func respondAddressError(c *gin.Context, message string, err error) {
+    status := http.StatusInternalServerError
+    switch {
+    case errors.Is(err, repository.ErrAddressNotFound):
+        status = http.StatusNotFound
+        message = "address not found"
+    case errors.Is(err, repository.ErrUserNotFound):
+        status = http.StatusNotFound
+        message = "user not found"
+    case errors.Is(err, repository.ErrAddressLimit):
+        status = http.StatusConflict
+        message = "address limit reached"
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   message,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
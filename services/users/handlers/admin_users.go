@@ -0,0 +1,288 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/users/models"
+    "github.com/sanketh-sg/prost/services/users/repository"
+    "github.com/sanketh-sg/prost/shared/audit"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// ListUsers lists accounts for admins
+// @Summary List users
+// @Description Accounts newest first, filtered by role, status and an email/username search (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param role query string false "customer or admin"
+// @Param status query string false "active, disabled or deleted; default active and disabled"
+// @Param q query string false "Case-insensitive part of the email or username"
+// @Param page query int false "1-based page"
+// @Param limit query int false "Page size, default 20, capped at 100"
+// @Success 200 {object} models.UserListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /users [get]
+func (uh *UserHandler) ListUsers(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    filter, err := parseUserFilter(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid filter",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    users, total, err := uh.userRepo.ListUsers(ctx, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to list users",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, models.UserListResponse{
+        Users: users,
+        Count: len(users),
+        Total: total,
+        Page:  filter.Page,
+        Limit: filter.Limit,
+    })
+}
+
+// parseUserFilter reads the GET /users query parameters
+func parseUserFilter(c *gin.Context) (models.UserFilter, error) {
+    filter := models.UserFilter{
+        Role:   c.Query("role"),
+        Status: c.Query("status"),
+        Search: strings.TrimSpace(c.Query("q")),
+        Page:   1,
+        Limit:  models.DefaultUserPageLimit,
+    }
+
+    if filter.Role != "" && !models.IsValidRole(filter.Role) {
+        return filter, errors.New("role must be customer or admin")
+    }
+
+    switch filter.Status {
+    case "", models.UserStatusActive, models.UserStatusDisabled, models.UserStatusDeleted:
+    default:
+        return filter, errors.New("status must be active, disabled or deleted")
+    }
+
+    if len(filter.Search) > 100 {
+        return filter, errors.New("q is too long")
+    }
+
+    if raw := c.Query("page"); raw != "" {
+        page, err := strconv.Atoi(raw)
+        if err != nil || page < 1 {
+            return filter, errors.New("page must be a positive integer")
+        }
+        filter.Page = page
+    }
+
+    if raw := c.Query("limit"); raw != "" {
+        limit, err := strconv.Atoi(raw)
+        if err != nil || limit < 1 {
+            return filter, errors.New("limit must be a positive integer")
+        }
+        if limit > models.MaxUserPageLimit {
+            limit = models.MaxUserPageLimit
+        }
+        filter.Limit = limit
+    }
+
+    return filter, nil
+}
+
+// DisableUser stops an account from signing in
+// @Summary Disable user
+// @Description The account can no longer log in or refresh its token; tokens already issued stay valid until they expire (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /users/{id}/disable [post]
+func (uh *UserHandler) DisableUser(c *gin.Context) {
+    uh.setUserDisabled(c, true)
+}
+
+// EnableUser lets a disabled account sign in again
+// @Summary Enable user
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /users/{id}/enable [post]
+func (uh *UserHandler) EnableUser(c *gin.Context) {
+    uh.setUserDisabled(c, false)
+}
+
+func (uh *UserHandler) setUserDisabled(c *gin.Context, disabled bool) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.Param("id")
+    // Why: an admin disabling their own account would lock themselves out halfway through
+    if disabled && !uh.notSelf(c, userID, "cannot disable your own account") {
+        return
+    }
+
+    before := uh.auditBefore(ctx, userID)
+    user, err := uh.userRepo.SetUserDisabled(ctx, userID, disabled)
+    if err != nil {
+        respondAdminUserError(c, "failed to update user", err)
+        return
+    }
+
+    message, action := "User enabled successfully", audit.ActionUserEnabled
+    if disabled {
+        message, action = "User disabled successfully", audit.ActionUserDisabled
+    }
+    log.Printf("✓ %s: %s (by %s)", message, userID, c.GetString("user_id"))
+    uh.auditUserChange(ctx, action, userID, before, user)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": message,
+        "user":    user,
+    })
+}
+
+// ForcePasswordReset makes an account set a new password at its next login
+// @Summary Force password reset
+// @Description The next login needs the current password and a new_password (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /users/{id}/password-reset [post]
+func (uh *UserHandler) ForcePasswordReset(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.Param("id")
+    before := uh.auditBefore(ctx, userID)
+    user, err := uh.userRepo.RequirePasswordReset(ctx, userID)
+    if err != nil {
+        respondAdminUserError(c, "failed to force password reset", err)
+        return
+    }
+
+    log.Printf("✓ Password reset forced for user %s (by %s)", userID, c.GetString("user_id"))
+    uh.auditUserChange(ctx, audit.ActionUserPasswordReset, userID, before, user)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Password reset required at next login",
+        "user":    user,
+    })
+}
+
+// UpdateUserRole changes an account's role
+// @Summary Change user role
+// @Description Takes effect with the next token the account gets (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.UpdateRoleRequest true "New role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /users/{id}/role [put]
+func (uh *UserHandler) UpdateUserRole(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    var req models.UpdateRoleRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+    if valid, msg := req.Validate(); !valid {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "validation error",
+            Message: msg,
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    userID := c.Param("id")
+    // Why: the last admin demoting themselves would leave nobody to undo it
+    if !uh.notSelf(c, userID, "cannot change your own role") {
+        return
+    }
+
+    before := uh.auditBefore(ctx, userID)
+    user, err := uh.userRepo.UpdateRole(ctx, userID, req.Role)
+    if err != nil {
+        respondAdminUserError(c, "failed to update role", err)
+        return
+    }
+
+    log.Printf("✓ Role of user %s set to %s (by %s)", userID, req.Role, c.GetString("user_id"))
+    uh.auditUserChange(ctx, audit.ActionUserRoleChanged, userID, before, user)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Role updated successfully",
+        "user":    user,
+    })
+}
+
+// notSelf refuses an admin action on the caller's own account with 409
+func (uh *UserHandler) notSelf(c *gin.Context, userID, message string) bool {
+    if c.GetString("user_id") != userID {
+        return true
+    }
+    c.JSON(http.StatusConflict, models.ErrorResponse{
+        Error:   message,
+        Message: "",
+        Code:    http.StatusConflict,
+    })
+    return false
+}
+
+func respondAdminUserError(c *gin.Context, message string, err error) {
+    status := http.StatusInternalServerError
+    switch {
+    case errors.Is(err, repository.ErrUserNotFound):
+        status = http.StatusNotFound
+        message = "user not found"
+    case errors.Is(err, repository.ErrNoPassword):
+        status = http.StatusConflict
+        message = "account has no password"
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   message,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
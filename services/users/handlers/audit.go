@@ -0,0 +1,66 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+
+    "github.com/sanketh-sg/prost/services/users/models"
+    "github.com/sanketh-sg/prost/shared/audit"
+)
+
+// SetAuditRecorder makes the handler record admin actions on accounts, deleted accounts and
+// failed logins in the audit log
+func (uh *UserHandler) SetAuditRecorder(recorder audit.Recorder) {
+    uh.audit = recorder
+}
+
+// userAuditState is the part of an account the audited actions change; never the password hash
+func userAuditState(user *models.User) json.RawMessage {
+    if user == nil {
+        return nil
+    }
+    return audit.JSON(map[string]interface{}{
+        "email":                   user.Email,
+        "role":                    user.Role,
+        "disabled_at":             user.DisabledAt,
+        "deleted_at":              user.DeletedAt,
+        "password_reset_required": user.PasswordResetRequired,
+    })
+}
+
+// auditBefore reads an account ahead of an audited change, nil when nothing is recorded or it
+// can't be read (the change itself reports that)
+func (uh *UserHandler) auditBefore(ctx context.Context, userID string) *models.User {
+    if uh.audit == nil {
+        return nil
+    }
+    user, err := uh.userRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return nil
+    }
+    return user
+}
+
+// auditUserChange records an action on an account, with its state before and after
+func (uh *UserHandler) auditUserChange(ctx context.Context, action, userID string, before, after *models.User) {
+    audit.Write(ctx, uh.audit, audit.Entry{
+        Action:     action,
+        TargetType: "user",
+        TargetID:   userID,
+        Before:     userAuditState(before),
+        After:      userAuditState(after),
+    })
+}
+
+// auditLoginFailed records a refused login; userID is empty when no account has the email
+func (uh *UserHandler) auditLoginFailed(ctx context.Context, email, userID, reason string) {
+    audit.Write(ctx, uh.audit, audit.Entry{
+        Action:     audit.ActionLoginFailed,
+        TargetType: "user",
+        TargetID:   userID,
+        Details: audit.JSON(map[string]string{
+            "email":  email,
+            "reason": reason,
+        }),
+    })
+}
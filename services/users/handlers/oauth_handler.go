@@ -13,6 +13,7 @@ import (
 	"github.com/sanketh-sg/prost/services/users/auth"
 	"github.com/sanketh-sg/prost/services/users/models"
 	"github.com/sanketh-sg/prost/services/users/repository"
+	"github.com/sanketh-sg/prost/shared/reqctx"
 )
 
 type OAuthHandler struct {
@@ -99,7 +100,8 @@ func (oh *OAuthHandler) OAuthCallback(c *gin.Context) {
 
     code := c.Query("code")
     state := c.Query("state")
-    ctx := c.Request.Context()
+    ctx, cancel := reqctx.WithTimeout(c.Request, reqctx.LongTimeout) // includes the Auth0 code exchange
+    defer cancel()
 
     if errorParam := c.Query("error"); errorParam != "" {
         errorDesc := c.Query("error_description")
@@ -204,11 +206,18 @@ func (oh *OAuthHandler) OAuthCallback(c *gin.Context) {
         }
         log.Printf("OAuth provider linked to user: %s", user.ID)
     }
+    if user.DisabledAt != nil {
+        log.Printf("OAuth login refused, account disabled: %s", user.ID)
+        c.JSON(http.StatusForbidden, gin.H{"error": "account disabled"})
+        return
+    }
+
     // Step 6: Generate JWT access token
-    accessToken, expiresAt, err := oh.jwtManager.GenerateToken(
+    accessToken, expiresAt, err := oh.jwtManager.GenerateTokenWithRole(
         user.ID,
         user.Email,
         user.Username,
+        user.Role,
         24*time.Hour,
     )
     if err != nil {
@@ -290,19 +299,25 @@ func (oh *OAuthHandler) RefreshToken(c *gin.Context) {
     }
 
     // Get user details
-    ctx := c.Request.Context()
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
     user, err := oh.userRepo.GetUserByID(ctx, claims.UserID)
     if err != nil {
         log.Printf("User not found: %v", err)
         c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
         return
     }
+    if user.DisabledAt != nil {
+        c.JSON(http.StatusForbidden, gin.H{"error": "account disabled"})
+        return
+    }
 
     // Generate new access token
-    accessToken, expiresAt, err := oh.jwtManager.GenerateToken(
+    accessToken, expiresAt, err := oh.jwtManager.GenerateTokenWithRole(
         user.ID,
         user.Email,
         user.Username,
+        user.Role,
         24*time.Hour,
     )
     if err != nil {
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/users/models"
+    "github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// GetPreferences handles reading the caller's preferences
+// @Summary Get user preferences
+// @Description Locale, currency and marketing opt-in; unset keys have their defaults (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.Preferences
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /profile/{id}/preferences [get]
+func (uh *UserHandler) GetPreferences(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    prefs, err := uh.userRepo.GetPreferences(ctx, userID)
+    if err != nil {
+        respondPreferencesError(c, "failed to get preferences", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles changing some of the caller's preferences
+// @Summary Update user preferences
+// @Description Set any of locale, currency, marketing_opt_in; omitted keys are unchanged (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.UpdatePreferencesRequest true "Preferences to change"
+// @Success 200 {object} models.Preferences
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /profile/{id}/preferences [patch]
+func (uh *UserHandler) UpdatePreferences(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    // Unknown keys are rejected rather than silently stored
+    var req models.UpdatePreferencesRequest
+    decoder := json.NewDecoder(c.Request.Body)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid request body",
+            Message: err.Error(),
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    if valid, msg := req.Validate(); !valid {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid preferences",
+            Message: msg,
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
+    prefs, err := uh.userRepo.UpdatePreferences(ctx, userID, req)
+    if err != nil {
+        respondPreferencesError(c, "failed to update preferences", err)
+        return
+    }
+
+    log.Printf("✓ User preferences updated: %s", userID)
+
+    c.JSON(http.StatusOK, prefs)
+}
+
+// authorizeSelf returns the :id path parameter if it is the authenticated user, or writes the error response
+func (uh *UserHandler) authorizeSelf(c *gin.Context) (string, bool) {
+    userID := c.Param("id")
+    if userID == "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "user id required",
+            Message: "",
+            Code:    http.StatusBadRequest,
+        })
+        return "", false
+    }
+
+    authUserID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "user not authenticated",
+            Message: "",
+            Code:    http.StatusUnauthorized,
+        })
+        return "", false
+    }
+
+    if authUserID.(string) != userID {
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "cannot access other users",
+            Message: "",
+            Code:    http.StatusForbidden,
+        })
+        return "", false
+    }
+
+    return userID, true
+}
+
+func respondPreferencesError(c *gin.Context, message string, err error) {
+    status := http.StatusInternalServerError
+    if errors.Is(err, sql.ErrNoRows) {
+        status = http.StatusNotFound
+        message = "user not found"
+    }
+
+    c.JSON(status, models.ErrorResponse{
+        Error:   message,
+        Message: err.Error(),
+        Code:    status,
+    })
+}
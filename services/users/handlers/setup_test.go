@@ -4,18 +4,39 @@ import (
     "context"
     "errors"
 
+    "github.com/sanketh-sg/prost/services/users/auth"
     "github.com/sanketh-sg/prost/services/users/models"
+    "github.com/sanketh-sg/prost/services/users/repository"
+    "github.com/sanketh-sg/prost/shared/config"
 )
 
+// testPasswordHasher uses bcrypt's lowest cost so the tests stay fast
+var testPasswordHasher = auth.NewPasswordHasher(config.PasswordHashing{Algorithm: auth.AlgorithmBcrypt, BcryptCost: 4})
+
 // MockUserRepository is a mock implementation of UserRepository
 type MockUserRepository struct {
     CreateUserFunc     func(ctx context.Context, user *models.User) error
     GetUserByEmailFunc func(ctx context.Context, email string) (*models.User, error)
     GetUserByIDFunc    func(ctx context.Context, userID string) (*models.User, error)
     UpdateUserFunc     func(ctx context.Context, user *models.User) error
+    UpdatePasswordHashFunc func(ctx context.Context, id, oldHash, newHash string) (bool, error)
     EmailExistsFunc    func(ctx context.Context, email string) (bool, error)
     UsernameExistsFunc func(ctx context.Context, username string) (bool, error)
 	DeleteUserFunc     func(ctx context.Context, id string) error
+    GetPreferencesFunc    func(ctx context.Context, userID string) (*models.Preferences, error)
+    UpdatePreferencesFunc func(ctx context.Context, userID string, req models.UpdatePreferencesRequest) (*models.Preferences, error)
+    GetDeletedUsersFunc   func(ctx context.Context) ([]*models.User, error)
+    RestoreUserFunc       func(ctx context.Context, id string) error
+    ListAddressesFunc     func(ctx context.Context, userID string) ([]*models.Address, error)
+    GetAddressFunc        func(ctx context.Context, userID, addressID string) (*models.Address, error)
+    CreateAddressFunc     func(ctx context.Context, address *models.Address) error
+    UpdateAddressFunc     func(ctx context.Context, address *models.Address) error
+    DeleteAddressFunc     func(ctx context.Context, userID, addressID string) error
+    ResetPasswordFunc        func(ctx context.Context, id, oldHash, newHash string) (bool, error)
+    ListUsersFunc            func(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error)
+    SetUserDisabledFunc      func(ctx context.Context, id string, disabled bool) (*models.User, error)
+    RequirePasswordResetFunc func(ctx context.Context, id string) (*models.User, error)
+    UpdateRoleFunc           func(ctx context.Context, id, role string) (*models.User, error)
 // function stubs are good when there are different outcomes in a function
 //the function fields are just a way to ensure the method exists AND let us inject custom behavior.
 }
@@ -48,6 +69,13 @@ func (m *MockUserRepository) UpdateUser(ctx context.Context, user *models.User)
     return nil
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+    if m.UpdatePasswordHashFunc != nil {
+        return m.UpdatePasswordHashFunc(ctx, id, oldHash, newHash)
+    }
+    return true, nil
+}
+
 func (m *MockUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
     if m.EmailExistsFunc != nil {
         return m.EmailExistsFunc(ctx, email)
@@ -67,4 +95,118 @@ func(m *MockUserRepository) DeleteUser(ctx context.Context, id string)(error){
 		return m.DeleteUserFunc(ctx, id)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (m *MockUserRepository) GetDeletedUsers(ctx context.Context) ([]*models.User, error) {
+    if m.GetDeletedUsersFunc != nil {
+        return m.GetDeletedUsersFunc(ctx)
+    }
+    return []*models.User{}, nil
+}
+
+func (m *MockUserRepository) RestoreUser(ctx context.Context, id string) error {
+    if m.RestoreUserFunc != nil {
+        return m.RestoreUserFunc(ctx, id)
+    }
+    return nil
+}
+
+func (m *MockUserRepository) GetPreferences(ctx context.Context, userID string) (*models.Preferences, error) {
+    if m.GetPreferencesFunc != nil {
+        return m.GetPreferencesFunc(ctx, userID)
+    }
+    prefs := models.DefaultPreferences()
+    return &prefs, nil
+}
+
+func (m *MockUserRepository) UpdatePreferences(ctx context.Context, userID string, req models.UpdatePreferencesRequest) (*models.Preferences, error) {
+    if m.UpdatePreferencesFunc != nil {
+        return m.UpdatePreferencesFunc(ctx, userID, req)
+    }
+    prefs := models.DefaultPreferences()
+    return &prefs, nil
+}
+
+func (m *MockUserRepository) ListAddresses(ctx context.Context, userID string) ([]*models.Address, error) {
+    if m.ListAddressesFunc != nil {
+        return m.ListAddressesFunc(ctx, userID)
+    }
+    return []*models.Address{}, nil
+}
+
+func (m *MockUserRepository) GetAddress(ctx context.Context, userID, addressID string) (*models.Address, error) {
+    if m.GetAddressFunc != nil {
+        return m.GetAddressFunc(ctx, userID, addressID)
+    }
+    return nil, repository.ErrAddressNotFound
+}
+
+func (m *MockUserRepository) CreateAddress(ctx context.Context, address *models.Address) error {
+    if m.CreateAddressFunc != nil {
+        return m.CreateAddressFunc(ctx, address)
+    }
+    return nil
+}
+
+func (m *MockUserRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+    if m.UpdateAddressFunc != nil {
+        return m.UpdateAddressFunc(ctx, address)
+    }
+    return nil
+}
+
+func (m *MockUserRepository) DeleteAddress(ctx context.Context, userID, addressID string) error {
+    if m.DeleteAddressFunc != nil {
+        return m.DeleteAddressFunc(ctx, userID, addressID)
+    }
+    return nil
+}
+
+func (m *MockUserRepository) ResetPassword(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+    if m.ResetPasswordFunc != nil {
+        return m.ResetPasswordFunc(ctx, id, oldHash, newHash)
+    }
+    return true, nil
+}
+
+func (m *MockUserRepository) ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error) {
+    if m.ListUsersFunc != nil {
+        return m.ListUsersFunc(ctx, filter)
+    }
+    return []*models.User{}, 0, nil
+}
+
+func (m *MockUserRepository) SetUserDisabled(ctx context.Context, id string, disabled bool) (*models.User, error) {
+    if m.SetUserDisabledFunc != nil {
+        return m.SetUserDisabledFunc(ctx, id, disabled)
+    }
+    return nil, repository.ErrUserNotFound
+}
+
+func (m *MockUserRepository) RequirePasswordReset(ctx context.Context, id string) (*models.User, error) {
+    if m.RequirePasswordResetFunc != nil {
+        return m.RequirePasswordResetFunc(ctx, id)
+    }
+    return nil, repository.ErrUserNotFound
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id, role string) (*models.User, error) {
+    if m.UpdateRoleFunc != nil {
+        return m.UpdateRoleFunc(ctx, id, role)
+    }
+    return nil, repository.ErrUserNotFound
+}
+
+// MockEventPublisher records the user events the handler publishes
+type MockEventPublisher struct {
+    Published []interface{}
+    Err       error
+}
+
+func (m *MockEventPublisher) PublishUserEventReliable(ctx context.Context, event interface{}) error {
+    if m.Err != nil {
+        return m.Err
+    }
+    m.Published = append(m.Published, event)
+    return nil
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+    "context"
+    "errors"
     "log"
     "net/http"
     "time"
@@ -9,6 +11,10 @@ import (
     "github.com/sanketh-sg/prost/services/users/auth"
     "github.com/sanketh-sg/prost/services/users/models"
     "github.com/sanketh-sg/prost/services/users/repository"
+    "github.com/sanketh-sg/prost/shared/audit"
+    "github.com/sanketh-sg/prost/shared/events"
+    "github.com/sanketh-sg/prost/shared/metrics"
+    "github.com/sanketh-sg/prost/shared/reqctx"
 
 )
 
@@ -16,16 +22,30 @@ import (
 type UserHandler struct {
     userRepo         repository.UserRepositoryInterface // Takes any implementation of UserRepositoryInterface
     jwtManager       *auth.JWTManager
+    passwordHasher   *auth.PasswordHasher
+    events           EventPublisher // nil publishes no user events
+    audit            audit.Recorder // nil records no audit entries
+}
+
+// EventPublisher publishes user events (users.events)
+type EventPublisher interface {
+    PublishUserEventReliable(ctx context.Context, event interface{}) error
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userRepo repository.UserRepositoryInterface,jwtSecret string,) *UserHandler {
+func NewUserHandler(userRepo repository.UserRepositoryInterface, jwtManager *auth.JWTManager, passwordHasher *auth.PasswordHasher) *UserHandler {
     return &UserHandler{
         userRepo:         userRepo,
-        jwtManager:       auth.NewJWTManager(jwtSecret),
+        jwtManager:       jwtManager,
+        passwordHasher:   passwordHasher,
     }
 }
 
+// SetEventPublisher makes the handler publish user events, e.g. UserDeactivated for deleted accounts
+func (uh *UserHandler) SetEventPublisher(events EventPublisher) {
+    uh.events = events
+}
+
 // Register handles user registration
 // @Summary Register a new user
 // @Description Create a new user account
@@ -37,8 +57,8 @@ func NewUserHandler(userRepo repository.UserRepositoryInterface,jwtSecret string
 // @Failure 400 {object} models.ErrorResponse
 // @Router /register [post]
 func (uh *UserHandler) Register(c *gin.Context) {
-    // ctx := context.Background() // No timeout 
-     ctx := c.Request.Context()  // Inherits HTTP server timeout
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
 
     var req models.CreateUserRequest
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -99,7 +119,7 @@ func (uh *UserHandler) Register(c *gin.Context) {
     }
 
     // Hash password
-    passwordHash, err := repository.HashPassword(req.Password)
+    passwordHash, err := uh.passwordHasher.Hash(req.Password)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "password hashing failed",
@@ -143,8 +163,8 @@ func (uh *UserHandler) Register(c *gin.Context) {
 // @Failure 401 {object} models.ErrorResponse
 // @Router /login [post]
 func (uh *UserHandler) Login(c *gin.Context) {
-    // ctx := context.Background()
-     ctx := c.Request.Context()  // Inherits HTTP server timeout
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
 
     var req models.LoginRequest
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -170,6 +190,7 @@ func (uh *UserHandler) Login(c *gin.Context) {
     log.Printf("%+v",req)
     user, err := uh.userRepo.GetUserByEmail(ctx, req.Email)
     if err != nil {
+        uh.auditLoginFailed(ctx, req.Email, "", "unknown_email")
         c.JSON(http.StatusUnauthorized, models.ErrorResponse{
             Error:   "invalid credentials",
             Message: "",
@@ -179,7 +200,8 @@ func (uh *UserHandler) Login(c *gin.Context) {
     }
 
     // Verify password
-    if !repository.VerifyPassword(user.PasswordHash, req.Password) {
+    if !uh.passwordHasher.Verify(user.PasswordHash, req.Password) {
+        uh.auditLoginFailed(ctx, req.Email, user.ID, "invalid_password")
         c.JSON(http.StatusUnauthorized, models.ErrorResponse{
             Error:   "invalid credentials",
             Message: "",
@@ -188,8 +210,28 @@ func (uh *UserHandler) Login(c *gin.Context) {
         return
     }
     log.Println("Password verified")
+
+    // Why: checked only after the password, so a stranger can't probe which accounts are disabled
+    if user.DisabledAt != nil {
+        uh.auditLoginFailed(ctx, req.Email, user.ID, "account_disabled")
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "account disabled",
+            Message: "",
+            Code:    http.StatusForbidden,
+        })
+        return
+    }
+
+    if user.PasswordResetRequired {
+        if !uh.completePasswordReset(ctx, c, user, req) {
+            return
+        }
+    } else if uh.passwordHasher.NeedsRehash(user.PasswordHash) {
+        // The password is at hand only now, so hashes from older settings are upgraded here
+        uh.rehashPassword(ctx, user, req.Password)
+    }
     // Generate JWT token
-    accessToken, _, err := uh.jwtManager.GenerateToken(user.ID, user.Email, user.Username, 24*time.Hour)
+    accessToken, _, err := uh.jwtManager.GenerateTokenWithRole(user.ID, user.Email, user.Username, user.Role, 24*time.Hour)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{
             Error:   "token generation failed",
@@ -239,8 +281,8 @@ func (uh *UserHandler) Login(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse
 // @Router /profile/{id} [get]
 func (uh *UserHandler) GetProfile(c *gin.Context) {
-    // ctx := context.Background()
-     ctx := c.Request.Context()  // Inherits HTTP server timeout
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
 
     userID := c.Param("id")
     if userID == "" {
@@ -285,8 +327,8 @@ func (uh *UserHandler) GetProfile(c *gin.Context) {
 // @Failure 401 {object} models.ErrorResponse
 // @Router /profile/{id} [patch]
 func (uh *UserHandler) UpdateProfile(c *gin.Context) {
-    // ctx := context.Background()
-     ctx := c.Request.Context()  // Inherits HTTP server timeout
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
 
     userID := c.Param("id")
     if userID == "" {
@@ -329,6 +371,15 @@ func (uh *UserHandler) UpdateProfile(c *gin.Context) {
         return
     }
 
+    if valid, msg := req.Validate(); !valid {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "validation error",
+            Message: msg,
+            Code:    http.StatusBadRequest,
+        })
+        return
+    }
+
     // Get current user
     user, err := uh.userRepo.GetUserByID(ctx, userID)
     if err != nil {
@@ -347,6 +398,27 @@ func (uh *UserHandler) UpdateProfile(c *gin.Context) {
     if req.Username != "" {
         user.Username = req.Username
     }
+    if req.NewPassword != "" {
+        if !uh.passwordConfirmed(user, req.CurrentPassword) {
+            c.JSON(http.StatusForbidden, models.ErrorResponse{
+                Error:   "invalid current password",
+                Message: "",
+                Code:    http.StatusForbidden,
+            })
+            return
+        }
+
+        passwordHash, err := uh.passwordHasher.Hash(req.NewPassword)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+                Error:   "password hashing failed",
+                Message: err.Error(),
+                Code:    http.StatusInternalServerError,
+            })
+            return
+        }
+        user.PasswordHash = passwordHash
+    }
 
     // Update user
     if err := uh.userRepo.UpdateUser(ctx, user); err != nil {
@@ -367,9 +439,277 @@ func (uh *UserHandler) UpdateProfile(c *gin.Context) {
             "email":    user.Email,
             "username": user.Username,
         },
+        "password_changed": req.NewPassword != "",
+    })
+}
+
+// DeleteAccount handles soft-deleting the caller's account
+// @Summary Delete account
+// @Description Soft delete the caller's account; needs the password unless the account has none (requires JWT)
+// @Tags profile
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.DeleteAccountRequest false "Password confirmation"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /profile/{id} [delete]
+func (uh *UserHandler) DeleteAccount(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID, ok := uh.authorizeSelf(c)
+    if !ok {
+        return
+    }
+
+    // The body is optional for accounts without a password
+    var req models.DeleteAccountRequest
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{
+                Error:   "invalid request body",
+                Message: err.Error(),
+                Code:    http.StatusBadRequest,
+            })
+            return
+        }
+    }
+
+    user, err := uh.userRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, models.ErrorResponse{
+            Error:   "user not found",
+            Message: err.Error(),
+            Code:    http.StatusNotFound,
+        })
+        return
+    }
+
+    if !uh.passwordConfirmed(user, req.Password) {
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "invalid password",
+            Message: "",
+            Code:    http.StatusForbidden,
+        })
+        return
+    }
+
+    if err := uh.userRepo.DeleteUser(ctx, userID); err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to delete user",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    log.Printf("✓ User account deleted: %s", userID)
+    uh.auditUserChange(ctx, audit.ActionUserDeleted, userID, user, nil)
+    uh.publishDeactivated(ctx, userID, "account_deleted")
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Account deleted successfully",
+    })
+}
+
+// publishDeactivated tells the other services the account is gone: cart abandons its carts and
+// releases their stock, orders holds its open orders for review.
+// A failure is only logged; the account stays deleted.
+func (uh *UserHandler) publishDeactivated(ctx context.Context, userID, reason string) {
+    if uh.events == nil {
+        return
+    }
+
+    event := events.UserDeactivatedEvent{
+        BaseEvent:     events.NewBaseEvent("UserDeactivated", userID, "user", ""),
+        UserID:        userID,
+        Reason:        reason,
+        DeactivatedAt: time.Now().UTC(),
+    }
+    if err := uh.events.PublishUserEventReliable(ctx, event); err != nil {
+        log.Printf("❌ Failed to publish UserDeactivated for user %s: %v", userID, err)
+    }
+}
+
+// passwordConfirmed checks the password the caller typed for a sensitive change.
+// Why: a stolen token alone must not be enough to take over or delete an account;
+// accounts created through OAuth have no password, so there is nothing to confirm.
+func (uh *UserHandler) passwordConfirmed(user *models.User, password string) bool {
+    if user.PasswordHash == "" {
+        return true
+    }
+    return uh.passwordHasher.Verify(user.PasswordHash, password)
+}
+
+// completePasswordReset sets the new password of an account an admin forced to reset it, or
+// writes the error response. The login carries on only once the old password is replaced.
+func (uh *UserHandler) completePasswordReset(ctx context.Context, c *gin.Context, user *models.User, req models.LoginRequest) bool {
+    if req.NewPassword == "" {
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "password reset required",
+            Message: "log in again with new_password set",
+            Code:    http.StatusForbidden,
+        })
+        return false
+    }
+    if req.NewPassword == req.Password {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "validation error",
+            Message: "new_password must differ from the current password",
+            Code:    http.StatusBadRequest,
+        })
+        return false
+    }
+
+    passwordHash, err := uh.passwordHasher.Hash(req.NewPassword)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "password hashing failed",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return false
+    }
+
+    // Why: conditional on the old hash, so two logins racing to reset can't both win
+    updated, err := uh.userRepo.ResetPassword(ctx, user.ID, user.PasswordHash, passwordHash)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to reset password",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return false
+    }
+    if !updated {
+        c.JSON(http.StatusConflict, models.ErrorResponse{
+            Error:   "password changed meanwhile",
+            Message: "log in with the new password",
+            Code:    http.StatusConflict,
+        })
+        return false
+    }
+
+    log.Printf("✓ Forced password reset completed for user %s", user.ID)
+    return true
+}
+
+// rehashPassword replaces a verified password's hash with one made with the current settings.
+// A failure is only logged: the login goes ahead and the next one tries again.
+func (uh *UserHandler) rehashPassword(ctx context.Context, user *models.User, password string) {
+    passwordHash, err := uh.passwordHasher.Hash(password)
+    if err != nil {
+        log.Printf("⚠️  Failed to rehash password of user %s: %v", user.ID, err)
+        return
+    }
+
+    updated, err := uh.userRepo.UpdatePasswordHash(ctx, user.ID, user.PasswordHash, passwordHash)
+    if err != nil {
+        log.Printf("⚠️  Failed to save rehashed password of user %s: %v", user.ID, err)
+        return
+    }
+    if updated {
+        metrics.PasswordsRehashed.WithLabelValues(uh.passwordHasher.Algorithm()).Inc()
+        log.Printf("✓ Password of user %s rehashed with %s", user.ID, uh.passwordHasher.Algorithm())
+    }
+}
+
+// GetDeletedUsers lists soft-deleted accounts
+// @Summary List deleted users
+// @Description Soft-deleted accounts, most recently deleted first (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} models.ErrorResponse
+// @Router /users/deleted [get]
+func (uh *UserHandler) GetDeletedUsers(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    users, err := uh.userRepo.GetDeletedUsers(ctx)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get deleted users",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "users": users,
+        "count": len(users),
+    })
+}
+
+// RestoreUser undoes the soft delete of an account
+// @Summary Restore deleted user
+// @Description Reactivate a soft-deleted account (requires an admin JWT)
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /users/{id}/restore [post]
+func (uh *UserHandler) RestoreUser(c *gin.Context) {
+    ctx, cancel := reqctx.New(c.Request)
+    defer cancel()
+
+    userID := c.Param("id")
+    if err := uh.userRepo.RestoreUser(ctx, userID); err != nil {
+        status := http.StatusInternalServerError
+        switch {
+        case errors.Is(err, repository.ErrUserNotFound):
+            status = http.StatusNotFound
+        case errors.Is(err, repository.ErrUserNotDeleted):
+            status = http.StatusConflict
+        }
+        c.JSON(status, models.ErrorResponse{
+            Error:   "failed to restore user",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+
+    user, err := uh.userRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "failed to get user",
+            Message: err.Error(),
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+
+    log.Printf("✓ User account restored: %s", userID)
+    uh.auditUserChange(ctx, audit.ActionUserRestored, userID, nil, user)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "User restored successfully",
+        "user":    user,
     })
 }
 
+// JWKS serves the public keys of the RS256 signing keys
+// @Summary JSON Web Key Set
+// @Description Public keys other services verify access tokens with; HS256 secrets are never listed
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (uh *UserHandler) JWKS(c *gin.Context) {
+    // Verifiers refetch on an unknown kid, so a rotated-in key is picked up before this expires
+    c.Header("Cache-Control", "public, max-age=300")
+    c.JSON(http.StatusOK, uh.jwtManager.JWKS())
+}
+
 // Health handles health check
 // @Summary Health check
 // @Description Check service health
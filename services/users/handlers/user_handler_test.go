@@ -6,13 +6,18 @@ import (
     "encoding/json"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
     "time"
 	"errors"
 
     "github.com/gin-gonic/gin"
+    "github.com/sanketh-sg/prost/services/users/auth"
     "github.com/sanketh-sg/prost/services/users/models"
     "github.com/sanketh-sg/prost/services/users/repository"
+    "github.com/sanketh-sg/prost/shared/audit"
+    "github.com/sanketh-sg/prost/shared/config"
+    "github.com/sanketh-sg/prost/shared/events"
     "github.com/stretchr/testify/assert"
 )
 
@@ -32,7 +37,7 @@ func TestRegisterSuccess(t *testing.T) {
         },
     }
 
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder() // This is required to record HTTP responses
     c, _ := gin.CreateTestContext(w) // Create a Gin context for testing with the recorder
 
@@ -59,7 +64,7 @@ func TestRegisterSuccess(t *testing.T) {
 func TestRegisterInvalidJSON(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -79,7 +84,7 @@ func TestRegisterInvalidJSON(t *testing.T) {
 func TestRegisterMissingEmail(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -106,7 +111,7 @@ func TestRegisterMissingEmail(t *testing.T) {
 func TestRegisterPasswordTooShort(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -136,7 +141,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
             return true, nil // Email already exists
         },
     }
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -169,7 +174,7 @@ func TestRegisterDuplicateUsername(t *testing.T) {
             return true, nil // Username already exists
         },
     }
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -196,7 +201,7 @@ func TestRegisterDuplicateUsername(t *testing.T) {
 
 func TestLoginSuccess(t *testing.T) {
     // Arrange
-    hashedPassword, _ := repository.HashPassword("password123")
+    hashedPassword, _ := testPasswordHasher.Hash("password123")
     mockUser := &models.User{
         ID:           "user123",
         Email:        "test@example.com",
@@ -212,7 +217,7 @@ func TestLoginSuccess(t *testing.T) {
         },
     }
 
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -239,7 +244,7 @@ func TestLoginSuccess(t *testing.T) {
 func TestLoginInvalidJSON(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -259,7 +264,7 @@ func TestLoginInvalidJSON(t *testing.T) {
 func TestLoginMissingEmail(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -288,7 +293,7 @@ func TestLoginUserNotFound(t *testing.T) {
             return nil, errors.New("user not found")
         },
     }
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -312,7 +317,7 @@ func TestLoginUserNotFound(t *testing.T) {
 
 func TestLoginWrongPassword(t *testing.T) {
     // Arrange
-    hashedPassword, _ := repository.HashPassword("correctpassword")
+    hashedPassword, _ := testPasswordHasher.Hash("correctpassword")
     mockUser := &models.User{
         ID:           "user123",
         Email:        "test@example.com",
@@ -328,7 +333,7 @@ func TestLoginWrongPassword(t *testing.T) {
         },
     }
 
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
 
@@ -350,6 +355,46 @@ func TestLoginWrongPassword(t *testing.T) {
     assert.Equal(t, "invalid credentials", response.Error)
 }
 
+func TestLoginRehashesWeakerPassword(t *testing.T) {
+    // Arrange: a hash made before the switch to argon2id
+    hashedPassword, _ := testPasswordHasher.Hash("password123")
+    mockUser := &models.User{
+        ID:           "user123",
+        Email:        "test@example.com",
+        Username:     "testuser",
+        PasswordHash: hashedPassword,
+    }
+
+    var savedHash string
+    mockRepo := &MockUserRepository{
+        GetUserByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+            return mockUser, nil
+        },
+        UpdatePasswordHashFunc: func(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+            assert.Equal(t, hashedPassword, oldHash)
+            savedHash = newHash
+            return true, nil
+        },
+    }
+
+    argon2Hasher := auth.NewPasswordHasher(config.PasswordHashing{Algorithm: auth.AlgorithmArgon2id, Argon2MemoryKiB: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), argon2Hasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+
+    body, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+    c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    // Act
+    handler.Login(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.True(t, strings.HasPrefix(savedHash, "$argon2id$"), "expected an argon2id hash, got %q", savedHash)
+    assert.True(t, argon2Hasher.Verify(savedHash, "password123"))
+}
+
 // ===== GET PROFILE TESTS =====
 
 func TestGetProfileSuccess(t *testing.T) {
@@ -368,7 +413,7 @@ func TestGetProfileSuccess(t *testing.T) {
         },
     }
 
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
     c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
@@ -386,10 +431,38 @@ func TestGetProfileSuccess(t *testing.T) {
     assert.Equal(t, "testuser", response["username"])
 }
 
+func TestGetProfilePropagatesRequestCancellation(t *testing.T) {
+    // Arrange
+    var repoCtx context.Context
+    mockRepo := &MockUserRepository{
+        GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+            repoCtx = ctx
+            return nil, ctx.Err()
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    requestCtx, cancelRequest := context.WithCancel(context.Background())
+    cancelRequest() // client disconnected
+    c.Request = httptest.NewRequest(http.MethodGet, "/profile/user123", nil).WithContext(requestCtx)
+
+    // Act
+    handler.GetProfile(c)
+
+    // Assert
+    assert.NotNil(t, repoCtx)
+    assert.ErrorIs(t, repoCtx.Err(), context.Canceled)
+    _, hasDeadline := repoCtx.Deadline()
+    assert.True(t, hasDeadline, "repository context should be bounded")
+}
+
 func TestGetProfileMissingID(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
     c.Request = httptest.NewRequest(http.MethodGet, "/profile/", nil)
@@ -412,7 +485,7 @@ func TestGetProfileNotFound(t *testing.T) {
         },
     }
 
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
     c.Params = gin.Params{gin.Param{Key: "id", Value: "nonexistent"}}
@@ -428,12 +501,684 @@ func TestGetProfileNotFound(t *testing.T) {
     assert.Equal(t, "user not found", response.Error)
 }
 
+// ===== PROFILE UPDATE / DELETE TESTS =====
+
+func TestUpdateProfileChangesPassword(t *testing.T) {
+    // Arrange
+    currentHash, _ := testPasswordHasher.Hash("old-password")
+    var saved *models.User
+    mockRepo := &MockUserRepository{
+        GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+            return &models.User{ID: userID, Email: "test@example.com", Username: "testuser", PasswordHash: currentHash}, nil
+        },
+        UpdateUserFunc: func(ctx context.Context, user *models.User) error {
+            saved = user
+            return nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "user123")
+    c.Request = httptest.NewRequest(http.MethodPatch, "/profile/user123", bytes.NewBufferString(`{"current_password":"old-password","new_password":"new-password"}`))
+
+    // Act
+    handler.UpdateProfile(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.True(t, testPasswordHasher.Verify(saved.PasswordHash, "new-password"))
+}
+
+func TestUpdateProfileWrongCurrentPassword(t *testing.T) {
+    // Arrange
+    currentHash, _ := testPasswordHasher.Hash("old-password")
+    mockRepo := &MockUserRepository{
+        GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+            return &models.User{ID: userID, PasswordHash: currentHash}, nil
+        },
+        UpdateUserFunc: func(ctx context.Context, user *models.User) error {
+            t.Fatal("user must not be updated")
+            return nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "user123")
+    c.Request = httptest.NewRequest(http.MethodPatch, "/profile/user123", bytes.NewBufferString(`{"current_password":"guess","new_password":"new-password"}`))
+
+    // Act
+    handler.UpdateProfile(c)
+
+    // Assert
+    assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteAccount(t *testing.T) {
+    currentHash, _ := testPasswordHasher.Hash("password123")
+    cases := map[string]struct {
+        authUser string
+        body     string
+        want     int
+    }{
+        "with password":  {"user123", `{"password":"password123"}`, http.StatusOK},
+        "wrong password": {"user123", `{"password":"guess"}`, http.StatusForbidden},
+        "no password":    {"user123", "", http.StatusForbidden},
+        "other user":     {"someone-else", `{"password":"password123"}`, http.StatusForbidden},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            deleted := false
+            mockRepo := &MockUserRepository{
+                GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+                    return &models.User{ID: userID, PasswordHash: currentHash}, nil
+                },
+                DeleteUserFunc: func(ctx context.Context, id string) error {
+                    deleted = true
+                    return nil
+                },
+            }
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+            c.Set("user_id", tc.authUser)
+            c.Request = httptest.NewRequest(http.MethodDelete, "/profile/user123", bytes.NewBufferString(tc.body))
+
+            // Act
+            handler.DeleteAccount(c)
+
+            // Assert
+            assert.Equal(t, tc.want, w.Code)
+            assert.Equal(t, tc.want == http.StatusOK, deleted)
+        })
+    }
+}
+
+func TestDeleteAccountPublishesUserDeactivated(t *testing.T) {
+    currentHash, _ := testPasswordHasher.Hash("password123")
+    cases := map[string]struct {
+        body      string
+        published int
+    }{
+        "deleted":        {`{"password":"password123"}`, 1},
+        "wrong password": {`{"password":"guess"}`, 0},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            mockRepo := &MockUserRepository{
+                GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+                    return &models.User{ID: userID, PasswordHash: currentHash}, nil
+                },
+                DeleteUserFunc: func(ctx context.Context, id string) error {
+                    return nil
+                },
+            }
+            publisher := &MockEventPublisher{}
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            handler.SetEventPublisher(publisher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+            c.Set("user_id", "user123")
+            c.Request = httptest.NewRequest(http.MethodDelete, "/profile/user123", bytes.NewBufferString(tc.body))
+
+            // Act
+            handler.DeleteAccount(c)
+
+            // Assert
+            assert.Len(t, publisher.Published, tc.published)
+            if tc.published > 0 {
+                event, ok := publisher.Published[0].(events.UserDeactivatedEvent)
+                assert.True(t, ok)
+                assert.Equal(t, "user123", event.UserID)
+                assert.Equal(t, "UserDeactivated", event.EventType)
+                assert.Equal(t, "account_deleted", event.Reason)
+            }
+        })
+    }
+}
+
+func TestDeleteAccountSucceedsWhenPublishFails(t *testing.T) {
+    // Arrange
+    mockRepo := &MockUserRepository{
+        GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+            return &models.User{ID: userID}, nil
+        },
+        DeleteUserFunc: func(ctx context.Context, id string) error {
+            return nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    handler.SetEventPublisher(&MockEventPublisher{Err: errors.New("broker down")})
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "user123")
+    c.Request = httptest.NewRequest(http.MethodDelete, "/profile/user123", nil)
+
+    // Act
+    handler.DeleteAccount(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// ===== ADMIN TESTS =====
+
+func TestRestoreUser(t *testing.T) {
+    cases := map[string]struct {
+        restoreErr error
+        want       int
+    }{
+        "deleted user": {nil, http.StatusOK},
+        "live user":    {repository.ErrUserNotDeleted, http.StatusConflict},
+        "unknown user": {repository.ErrUserNotFound, http.StatusNotFound},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            mockRepo := &MockUserRepository{
+                RestoreUserFunc: func(ctx context.Context, id string) error {
+                    return tc.restoreErr
+                },
+                GetUserByIDFunc: func(ctx context.Context, userID string) (*models.User, error) {
+                    return &models.User{ID: userID, Email: "test@example.com"}, nil
+                },
+            }
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+            c.Request = httptest.NewRequest(http.MethodPost, "/users/user123/restore", nil)
+
+            // Act
+            handler.RestoreUser(c)
+
+            // Assert
+            assert.Equal(t, tc.want, w.Code)
+        })
+    }
+}
+
+// ===== PREFERENCES TESTS =====
+
+func TestUpdatePreferencesSuccess(t *testing.T) {
+    // Arrange
+    var gotReq models.UpdatePreferencesRequest
+    mockRepo := &MockUserRepository{
+        UpdatePreferencesFunc: func(ctx context.Context, userID string, req models.UpdatePreferencesRequest) (*models.Preferences, error) {
+            gotReq = req
+            return &models.Preferences{Locale: "de-DE", Currency: "EUR"}, nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "user123")
+    c.Request = httptest.NewRequest(http.MethodPatch, "/profile/user123/preferences", bytes.NewBufferString(`{"locale":"de-DE","currency":"EUR"}`))
+
+    // Act
+    handler.UpdatePreferences(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "de-DE", *gotReq.Locale)
+    assert.Nil(t, gotReq.MarketingOptIn)
+    var response models.Preferences
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "EUR", response.Currency)
+}
+
+func TestUpdatePreferencesRejectsInvalid(t *testing.T) {
+    cases := map[string]string{
+        "unknown key":          `{"theme":"dark"}`,
+        "unsupported currency": `{"currency":"XYZ"}`,
+        "unsupported locale":   `{"locale":"xx"}`,
+        "no keys":              `{}`,
+    }
+
+    for name, body := range cases {
+        t.Run(name, func(t *testing.T) {
+            handler := NewUserHandler(&MockUserRepository{}, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+            c.Set("user_id", "user123")
+            c.Request = httptest.NewRequest(http.MethodPatch, "/profile/user123/preferences", bytes.NewBufferString(body))
+
+            handler.UpdatePreferences(c)
+
+            assert.Equal(t, http.StatusBadRequest, w.Code)
+        })
+    }
+}
+
+func TestGetPreferencesOtherUser(t *testing.T) {
+    // Arrange
+    handler := NewUserHandler(&MockUserRepository{}, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "someone-else")
+    c.Request = httptest.NewRequest(http.MethodGet, "/profile/user123/preferences", nil)
+
+    // Act
+    handler.GetPreferences(c)
+
+    // Assert
+    assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// ===== ADDRESS TESTS =====
+
+func TestCreateAddressSuccess(t *testing.T) {
+    // Arrange
+    var saved *models.Address
+    mockRepo := &MockUserRepository{
+        CreateAddressFunc: func(ctx context.Context, address *models.Address) error {
+            saved = address
+            return nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Set("user_id", "user123")
+    body := `{"recipient_name":" Ada Lovelace ","line1":"12 St James's Square","city":"London","postal_code":"SW1Y 4JH","country":"gb"}`
+    c.Request = httptest.NewRequest(http.MethodPost, "/profile/user123/addresses", bytes.NewBufferString(body))
+
+    // Act
+    handler.CreateAddress(c)
+
+    // Assert
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "user123", saved.UserID)
+    assert.Equal(t, "Ada Lovelace", saved.RecipientName)
+    assert.Equal(t, "GB", saved.Country)
+    assert.NotEmpty(t, saved.ID)
+}
+
+func TestCreateAddressErrors(t *testing.T) {
+    valid := `{"recipient_name":"Ada","line1":"1 Main St","city":"Springfield","postal_code":"12345","country":"US"}`
+    cases := []struct {
+        name string
+        body string
+        err  error
+        want int
+    }{
+        {"missing city", `{"recipient_name":"Ada","line1":"1 Main St","postal_code":"12345","country":"US"}`, nil, http.StatusBadRequest},
+        {"bad country", `{"recipient_name":"Ada","line1":"1 Main St","city":"Springfield","postal_code":"12345","country":"USA"}`, nil, http.StatusBadRequest},
+        {"limit reached", valid, repository.ErrAddressLimit, http.StatusConflict},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            mockRepo := &MockUserRepository{
+                CreateAddressFunc: func(ctx context.Context, address *models.Address) error {
+                    return tc.err
+                },
+            }
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+            c.Set("user_id", "user123")
+            c.Request = httptest.NewRequest(http.MethodPost, "/profile/user123/addresses", bytes.NewBufferString(tc.body))
+
+            handler.CreateAddress(c)
+
+            assert.Equal(t, tc.want, w.Code)
+        })
+    }
+}
+
+func TestGetAddressNotFound(t *testing.T) {
+    // Arrange: the mock has no addresses
+    handler := NewUserHandler(&MockUserRepository{}, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}, gin.Param{Key: "address_id", Value: "addr-1"}}
+    c.Set("user_id", "user123")
+    c.Request = httptest.NewRequest(http.MethodGet, "/profile/user123/addresses/addr-1", nil)
+
+    // Act
+    handler.GetAddress(c)
+
+    // Assert
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// ===== ADMIN USER MANAGEMENT TESTS =====
+
+func TestLoginDisabledAccount(t *testing.T) {
+    // Arrange
+    hashedPassword, _ := testPasswordHasher.Hash("password123")
+    disabledAt := time.Now().UTC()
+    mockRepo := &MockUserRepository{
+        GetUserByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+            return &models.User{ID: "user123", Email: email, PasswordHash: hashedPassword, DisabledAt: &disabledAt}, nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+
+    body, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+    c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    // Act
+    handler.Login(c)
+
+    // Assert
+    assert.Equal(t, http.StatusForbidden, w.Code)
+    assert.Contains(t, w.Body.String(), "account disabled")
+}
+
+func TestLoginForcedPasswordReset(t *testing.T) {
+    hashedPassword, _ := testPasswordHasher.Hash("password123")
+    cases := map[string]struct {
+        newPassword string
+        want        int
+        wantReset   bool
+    }{
+        "without new password": {"", http.StatusForbidden, false},
+        "same password":        {"password123", http.StatusBadRequest, false},
+        "new password":         {"new-secret", http.StatusOK, true},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            var savedHash string
+            mockRepo := &MockUserRepository{
+                GetUserByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+                    return &models.User{ID: "user123", Email: email, PasswordHash: hashedPassword, PasswordResetRequired: true}, nil
+                },
+                ResetPasswordFunc: func(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+                    assert.Equal(t, hashedPassword, oldHash)
+                    savedHash = newHash
+                    return true, nil
+                },
+            }
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+
+            body, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123", NewPassword: tc.newPassword})
+            c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+            c.Request.Header.Set("Content-Type", "application/json")
+
+            // Act
+            handler.Login(c)
+
+            // Assert
+            assert.Equal(t, tc.want, w.Code)
+            assert.Equal(t, tc.wantReset, savedHash != "")
+            if tc.wantReset {
+                assert.True(t, testPasswordHasher.Verify(savedHash, tc.newPassword))
+            }
+        })
+    }
+}
+
+func TestListUsersFilter(t *testing.T) {
+    // Arrange
+    var gotFilter models.UserFilter
+    mockRepo := &MockUserRepository{
+        ListUsersFunc: func(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error) {
+            gotFilter = filter
+            return []*models.User{{ID: "user123", Role: models.RoleAdmin}}, 41, nil
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Request = httptest.NewRequest(http.MethodGet, "/users?role=admin&status=disabled&q=%20ada%20&page=3&limit=500", nil)
+
+    // Act
+    handler.ListUsers(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, models.UserFilter{Role: "admin", Status: "disabled", Search: "ada", Page: 3, Limit: models.MaxUserPageLimit}, gotFilter)
+    var response models.UserListResponse
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 1, response.Count)
+    assert.Equal(t, 41, response.Total)
+}
+
+func TestListUsersRejectsInvalidFilter(t *testing.T) {
+    for _, query := range []string{"role=owner", "status=banned", "page=0", "limit=x"} {
+        t.Run(query, func(t *testing.T) {
+            handler := NewUserHandler(&MockUserRepository{}, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Request = httptest.NewRequest(http.MethodGet, "/users?"+query, nil)
+
+            handler.ListUsers(c)
+
+            assert.Equal(t, http.StatusBadRequest, w.Code)
+        })
+    }
+}
+
+func TestDisableUser(t *testing.T) {
+    cases := map[string]struct {
+        target string
+        err    error
+        want   int
+    }{
+        "other user":   {"user123", nil, http.StatusOK},
+        "own account":  {"admin1", nil, http.StatusConflict},
+        "unknown user": {"user404", repository.ErrUserNotFound, http.StatusNotFound},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            var called bool
+            mockRepo := &MockUserRepository{
+                SetUserDisabledFunc: func(ctx context.Context, id string, disabled bool) (*models.User, error) {
+                    called = true
+                    assert.True(t, disabled)
+                    if tc.err != nil {
+                        return nil, tc.err
+                    }
+                    disabledAt := time.Now().UTC()
+                    return &models.User{ID: id, DisabledAt: &disabledAt}, nil
+                },
+            }
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Set("user_id", "admin1")
+            c.Params = gin.Params{gin.Param{Key: "id", Value: tc.target}}
+            c.Request = httptest.NewRequest(http.MethodPost, "/users/"+tc.target+"/disable", nil)
+
+            // Act
+            handler.DisableUser(c)
+
+            // Assert
+            assert.Equal(t, tc.want, w.Code)
+            assert.Equal(t, tc.target != "admin1", called)
+        })
+    }
+}
+
+func TestForcePasswordResetWithoutPassword(t *testing.T) {
+    // Arrange: an account signed up through OAuth
+    mockRepo := &MockUserRepository{
+        RequirePasswordResetFunc: func(ctx context.Context, id string) (*models.User, error) {
+            return nil, repository.ErrNoPassword
+        },
+    }
+
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Request = httptest.NewRequest(http.MethodPost, "/users/user123/password-reset", nil)
+
+    // Act
+    handler.ForcePasswordReset(c)
+
+    // Assert
+    assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+    cases := map[string]struct {
+        target string
+        body   string
+        want   int
+    }{
+        "promote":      {"user123", `{"role":"admin"}`, http.StatusOK},
+        "unknown role": {"user123", `{"role":"owner"}`, http.StatusBadRequest},
+        "own role":     {"admin1", `{"role":"customer"}`, http.StatusConflict},
+    }
+
+    for name, tc := range cases {
+        t.Run(name, func(t *testing.T) {
+            // Arrange
+            var gotRole string
+            mockRepo := &MockUserRepository{
+                UpdateRoleFunc: func(ctx context.Context, id, role string) (*models.User, error) {
+                    gotRole = role
+                    return &models.User{ID: id, Role: role}, nil
+                },
+            }
+
+            handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+            w := httptest.NewRecorder()
+            c, _ := gin.CreateTestContext(w)
+            c.Set("user_id", "admin1")
+            c.Params = gin.Params{gin.Param{Key: "id", Value: tc.target}}
+            c.Request = httptest.NewRequest(http.MethodPut, "/users/"+tc.target+"/role", strings.NewReader(tc.body))
+            c.Request.Header.Set("Content-Type", "application/json")
+
+            // Act
+            handler.UpdateUserRole(c)
+
+            // Assert
+            assert.Equal(t, tc.want, w.Code)
+            if tc.want == http.StatusOK {
+                assert.Equal(t, models.RoleAdmin, gotRole)
+            } else {
+                assert.Empty(t, gotRole)
+            }
+        })
+    }
+}
+
+// ===== AUDIT TESTS =====
+
+// auditLog keeps the entries a handler records
+type auditLog []audit.Entry
+
+func (l *auditLog) Record(ctx context.Context, entry audit.Entry) error {
+    *l = append(*l, entry)
+    return nil
+}
+
+func TestUpdateUserRoleAudited(t *testing.T) {
+    // Arrange
+    mockRepo := &MockUserRepository{
+        GetUserByIDFunc: func(ctx context.Context, id string) (*models.User, error) {
+            return &models.User{ID: id, Role: models.RoleCustomer, PasswordHash: "secret-hash"}, nil
+        },
+        UpdateRoleFunc: func(ctx context.Context, id, role string) (*models.User, error) {
+            return &models.User{ID: id, Role: role}, nil
+        },
+    }
+
+    entries := &auditLog{}
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    handler.SetAuditRecorder(entries)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Set("user_id", "admin1")
+    c.Params = gin.Params{gin.Param{Key: "id", Value: "user123"}}
+    c.Request = httptest.NewRequest(http.MethodPut, "/users/user123/role", strings.NewReader(`{"role":"admin"}`))
+    c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{ID: "admin1", Role: "admin", IP: "10.0.0.1"}))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    // Act
+    handler.UpdateUserRole(c)
+
+    // Assert
+    assert.Equal(t, http.StatusOK, w.Code)
+    if assert.Len(t, *entries, 1) {
+        entry := (*entries)[0]
+        assert.Equal(t, audit.ActionUserRoleChanged, entry.Action)
+        assert.Equal(t, "admin1", entry.ActorID)
+        assert.Equal(t, "user123", entry.TargetID)
+        assert.Contains(t, string(entry.Before), `"role":"customer"`)
+        assert.Contains(t, string(entry.After), `"role":"admin"`)
+        assert.NotContains(t, string(entry.Before), "secret-hash")
+    }
+}
+
+func TestLoginFailureAudited(t *testing.T) {
+    // Arrange
+    mockRepo := &MockUserRepository{
+        GetUserByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+            return nil, repository.ErrUserNotFound
+        },
+    }
+
+    entries := &auditLog{}
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
+    handler.SetAuditRecorder(entries)
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+
+    body, _ := json.Marshal(models.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+    c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    // Act
+    handler.Login(c)
+
+    // Assert
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+    if assert.Len(t, *entries, 1) {
+        entry := (*entries)[0]
+        assert.Equal(t, audit.ActionLoginFailed, entry.Action)
+        assert.Empty(t, entry.ActorID)
+        assert.JSONEq(t, `{"email":"nobody@example.com","reason":"unknown_email"}`, string(entry.Details))
+    }
+}
+
 // ===== HEALTH CHECK TEST =====
 
 func TestHealth(t *testing.T) {
     // Arrange
     mockRepo := &MockUserRepository{}
-    handler := NewUserHandler(mockRepo, "test-secret")
+    handler := NewUserHandler(mockRepo, auth.NewJWTManager("test-secret"), testPasswordHasher)
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
     c.Request = httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -9,90 +9,75 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/gin-gonic/gin"
 	"github.com/sanketh-sg/prost/services/users/handlers"
 	"github.com/sanketh-sg/prost/services/users/middleware"
+	"github.com/sanketh-sg/prost/services/users/migrations"
     "github.com/sanketh-sg/prost/services/users/auth"
 	"github.com/sanketh-sg/prost/services/users/repository"
+	"github.com/sanketh-sg/prost/shared/audit"
+	"github.com/sanketh-sg/prost/shared/config"
+	"github.com/sanketh-sg/prost/shared/compression"
+	"github.com/sanketh-sg/prost/shared/cors"
 	"github.com/sanketh-sg/prost/shared/db"
+	"github.com/sanketh-sg/prost/shared/httpserver"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+	"github.com/sanketh-sg/prost/shared/messaging"
+	"github.com/sanketh-sg/prost/shared/metrics"
+	"github.com/sanketh-sg/prost/shared/rbac"
 )
 
 func main() {
-    
-    err := godotenv.Load(".env")
-	
-    if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
-    
-	// Load environment variables
-    serviceName := os.Getenv("SERVICE_NAME")
-    if serviceName == "" {
-        serviceName = "users"
-    }
-
-	port := os.Getenv("PORT_USER")
-	if port == "" {
-		port = "8083"
-	}
+    // Configuration: flags, then the environment, then .env (see shared/config)
+    var cfg config.Users
+    config.LoadOrDie(&cfg)
 
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-        log.Fatal("DATABASE_URL not set")
+	// Signing keys: JWT_SECRET and/or JWT_KEYS, signing with JWT_SIGNING_KEY_ID
+	jwtConfig := jwtkeys.ConfigFromEnv()
+    if jwtConfig.Secret == "" && jwtConfig.Keys == "" {
+        log.Println("JWT_SECRET not set, using default (INSECURE)")
+        jwtConfig.Secret = "default-secret-change-in-production"
     }
-
-    dbSchema := os.Getenv("DB_SCHEMA")
-    if dbSchema == "" {
-        dbSchema = "users"
-        log.Println("DATABASE_SCHEMA not set using default 'users'")
-        
+    jwtKeys, err := jwtkeys.NewKeySet(jwtConfig)
+    if err != nil {
+        log.Fatalf("Invalid JWT key configuration: %v", err)
     }
-
-	jwtSecret := os.Getenv("JWT_SECRET")
-    if jwtSecret == "" {
-        log.Println("JWT_SECRET not set, using default (INSECURE)")
-        jwtSecret = "default-secret-change-in-production"
+    if !jwtKeys.SigningKey().CanSign() {
+        log.Fatalf("JWT signing key %q has no private key", jwtKeys.SigningKey().ID)
     }
 
-    // Validate OAuth environment variables
-    auth0Domain := os.Getenv("AUTH0_DOMAIN")
-    auth0ClientID := os.Getenv("AUTH0_CLIENT_ID")
-    auth0ClientSecret := os.Getenv("AUTH0_CLIENT_SECRET")
-    auth0RedirectURI := os.Getenv("AUTH0_REDIRECT_URI")
-
-        if auth0Domain == "" || auth0ClientID == "" || auth0ClientSecret == "" || auth0RedirectURI == "" {
+    // OAuth login needs every AUTH0_* setting
+    if !cfg.Auth0.Configured() {
         log.Println("WARNING: OAuth environment variables not fully configured")
-        log.Printf("   AUTH0_DOMAIN: %v", auth0Domain != "")
-        log.Printf("   AUTH0_CLIENT_ID: %v", auth0ClientID != "")
-        log.Printf("   AUTH0_CLIENT_SECRET: %v", auth0ClientSecret != "")
-        log.Printf("   AUTH0_REDIRECT_URI: %v", auth0RedirectURI != "")
+        log.Printf("   AUTH0_DOMAIN: %v", cfg.Auth0.Domain != "")
+        log.Printf("   AUTH0_CLIENT_ID: %v", cfg.Auth0.ClientID != "")
+        log.Printf("   AUTH0_CLIENT_SECRET: %v", cfg.Auth0.ClientSecret != "")
+        log.Printf("   AUTH0_REDIRECT_URI: %v", cfg.Auth0.RedirectURI != "")
     }
 
-	// Set Gin mode
-    gin.SetMode(gin.ReleaseMode)  // Disables debug logging, colorised output, better and faster
+	// Gin mode (GIN_MODE): release unless set, so production logs no route table or debug warnings
+	gin.SetMode(cfg.HTTP.GinMode)
 
 	log.Println("=== Users Service Starting ===")
-    log.Printf("Service: %s", serviceName)
-    log.Printf("Port: %s", port)
-    log.Printf("Schema: %s", dbSchema)
-    log.Printf("Database URL: %s", dbURL)
+    log.Printf("Service: %s", cfg.Name)
+    log.Printf("Port: %s", cfg.Port)
+    log.Printf("Schema: %s", cfg.Schema)
+    log.Printf("Database URL: %s", cfg.DatabaseURL)
 
 
 	// Database connection
     log.Println("\nConnecting to PostgreSQL...")
-    dbConn, err := db.NewDBConnection(db.Config{
-        Host:     os.Getenv("HOST"),
-        Port:     os.Getenv("PORT_DB"),
-        User:     os.Getenv("USER"),
-        Password: os.Getenv("PASSWORD"),
-        DBName:   os.Getenv("DBNAME"),
-        Schema:   dbSchema,
-    })
+    dbConn, err := db.NewDBConnection(cfg.Database.Connection(cfg.Schema))
     if err != nil {
         log.Fatalf("Database connection failed: %v", err)
     }
     defer dbConn.DBConnClose()
+    metrics.RegisterDBStats(cfg.Schema, dbConn)
+    if cfg.Database.AutoMigrate {
+        if err := dbConn.Migrate(context.Background(), migrations.FS); err != nil {
+            log.Fatalf("Database migration failed: %v", err)
+        }
+    }
     log.Println("✓ Database connected")
 
 
@@ -101,25 +86,69 @@ func main() {
     oauthProviderRepo := repository.NewOAuthProviderRepository(dbConn)
 
     // Initialize auth managers
-    jwtManager := auth.NewJWTManager(jwtSecret)
-    oauthManager := auth.NewOAuthManager()
+    jwtManager := auth.NewJWTManagerWithKeys(jwtKeys)
+    oauthManager := auth.NewOAuthManager(cfg.Auth0)
+    passwordHasher := auth.NewPasswordHasher(cfg.PasswordHashing)
+    log.Printf("✓ Passwords hashed with %s", passwordHasher.Algorithm())
 
     //Initialize Handlers
-    userHandler := handlers.NewUserHandler(userRepo, jwtSecret)
+    userHandler := handlers.NewUserHandler(userRepo, jwtManager, passwordHasher)
+
+    // Audit log (users.audit_logs): admin actions on accounts, deleted accounts, failed logins
+    auditStore := audit.NewStore(dbConn, "users")
+    userHandler.SetAuditRecorder(auditStore)
+
+    // User events (users.events): deleted accounts are cascaded to cart and orders
+    if cfg.RabbitMQURL != "" {
+        log.Println("\nConnecting to RabbitMQ...")
+        rmqConn, err := messaging.NewRmqConnection(cfg.RabbitMQURL)
+        if err != nil {
+            log.Fatalf("RabbitMQ connection failed: %v", err)
+        }
+        defer rmqConn.Close()
+        if err := rmqConn.SetupRabbitMQ(messaging.GetProstTopology()); err != nil {
+            log.Fatalf("RabbitMQ setup failed: %v", err)
+        }
+
+        publisher := messaging.NewPublisher(rmqConn, "users.events")
+        defer publisher.Close()
+        userHandler.SetEventPublisher(publisher)
+        log.Println("✓ RabbitMQ connected, user events enabled")
+    } else {
+        log.Println("⚠️  RABBITMQ_URL not set, user events disabled; deleted accounts keep their carts and orders")
+    }
     oauthHandler := handlers.NewOAuthHandler(oauthManager, jwtManager, oauthProviderRepo, userRepo)
 
+	// Roles allowed on the JWT-protected routes (shared/rbac/policy.yaml, or RBAC_POLICY_FILE)
+    rbacPolicy, err := rbac.Load()
+    if err != nil {
+        log.Fatalf("Invalid RBAC policy: %v", err)
+    }
+    access := rbacPolicy.Service("users")
+
+	// HTTP limits: timeouts and body size, with per-route overrides (HTTP_* settings)
+	httpConfig, err := httpserver.Configure(httpserver.DefaultConfig(), cfg.HTTP)
+	if err != nil {
+		log.Fatalf("Invalid HTTP configuration: %v", err)
+	}
+
 	//Create Gin router
 	router := gin.New()
 	
 	//Add Middleware
     router.Use(gin.Logger()) // Logs each request concurrently
     router.Use(gin.Recovery())  // Catches panics independently
-    router.Use(middleware.CORSMiddleware()) // Takes care of CORS headers
+    router.Use(cors.NewPolicy(cors.LoadConfig(cors.DefaultConfig())).Middleware())
+    router.Use(httpConfig.Middleware())
+    router.Use(compression.LoadConfig(compression.DefaultConfig()).Middleware())
+    router.Use(audit.Middleware(nil)) // the caller of audited actions; set by the JWT middleware below
 
 	// Public routes
     router.POST("/register", userHandler.Register)
     router.POST("/login", userHandler.Login)
     router.GET("/health", userHandler.Health)
+    router.GET("/metrics", gin.WrapH(metrics.Handler()))
+    router.GET("/.well-known/jwks.json", userHandler.JWKS)
 
     // Public routes - OAuth (Auth0)
     router.GET("/oauth/login", oauthHandler.InitiateOAuth)
@@ -127,25 +156,39 @@ func main() {
     router.GET("/oauth/callback", oauthHandler.OAuthCallback)
     router.POST("/oauth/refresh", oauthHandler.RefreshToken)
 
-	// Protected routes (require JWT)
+	// Protected routes (require JWT; roles from the RBAC policy)
     protected := router.Group("/")
-    protected.Use(middleware.AuthMiddleware(jwtSecret))
+    protected.Use(middleware.AuthMiddleware(jwtManager), access.Middleware())
     {
         protected.GET("profile/:id", userHandler.GetProfile)
         protected.PATCH("profile/:id", userHandler.UpdateProfile)
+        protected.DELETE("profile/:id", userHandler.DeleteAccount)
+        protected.GET("profile/:id/preferences", userHandler.GetPreferences)
+        protected.PATCH("profile/:id/preferences", userHandler.UpdatePreferences)
+        protected.GET("profile/:id/addresses", userHandler.GetAddresses)
+        protected.POST("profile/:id/addresses", userHandler.CreateAddress)
+        protected.GET("profile/:id/addresses/:address_id", userHandler.GetAddress)
+        protected.PUT("profile/:id/addresses/:address_id", userHandler.UpdateAddress)
+        protected.DELETE("profile/:id/addresses/:address_id", userHandler.DeleteAddress)
+
+        // Admin routes
+        admin := protected.Group("users")
+        admin.GET("", userHandler.ListUsers)
+        admin.GET("/deleted", userHandler.GetDeletedUsers)
+        admin.POST("/:id/restore", userHandler.RestoreUser)
+        admin.POST("/:id/disable", userHandler.DisableUser)
+        admin.POST("/:id/enable", userHandler.EnableUser)
+        admin.POST("/:id/password-reset", userHandler.ForcePasswordReset)
+        admin.PUT("/:id/role", userHandler.UpdateUserRole)
+
+        protected.GET("admin/audit-logs", audit.ListHandler(auditStore))
     }
 
 	//Server Setup
-	server := &http.Server{
-		Addr:         ":" + port,
-        Handler:      router,
-        ReadTimeout:  15 * time.Second,
-        WriteTimeout: 15 * time.Second,
-        IdleTimeout:  60 * time.Second,
-	}
+	server := httpConfig.NewServer(":"+cfg.Port, router)
 
 	// Start server in goroutine
-    log.Printf("\n Users service listening on :%s", port)
+    log.Printf("\n Users service listening on :%s", cfg.Port)
     log.Println("\n=== Service Ready ===")
 	go func() {
         if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -9,9 +9,7 @@ import (
 )
 
 // AuthMiddleware validates JWT token
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
-    jwtManager := auth.NewJWTManager(jwtSecret)
-
+func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
@@ -43,7 +41,8 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
         c.Set("user_id", claims.UserID)
         c.Set("email", claims.Email)
         c.Set("username", claims.Username)
+        c.Set("role", claims.Role)
 
         c.Next()
     }
-}
\ No newline at end of file
+}
@@ -8,6 +8,7 @@ import (
 
     "github.com/gin-gonic/gin"
     "github.com/sanketh-sg/prost/services/users/auth"
+    "github.com/sanketh-sg/prost/shared/rbac"
     "github.com/stretchr/testify/assert"
 )
 
@@ -18,7 +19,7 @@ func TestAuthMiddlewareSuccess(t *testing.T) {
 
     // Create test router
     router := gin.New()
-    router.Use(AuthMiddleware("test-secret"))
+    router.Use(AuthMiddleware(auth.NewJWTManager("test-secret")))
     router.GET("/test", func(c *gin.Context) {
         userID, exists := c.Get("user_id")
         if !exists {
@@ -42,7 +43,7 @@ func TestAuthMiddlewareSuccess(t *testing.T) {
 func TestAuthMiddlewareMissingHeader(t *testing.T) {
     // Arrange
     router := gin.New()
-    router.Use(AuthMiddleware("test-secret"))
+    router.Use(AuthMiddleware(auth.NewJWTManager("test-secret")))
     router.GET("/test", func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"message": "ok"})
     })
@@ -61,7 +62,7 @@ func TestAuthMiddlewareMissingHeader(t *testing.T) {
 func TestAuthMiddlewareInvalidToken(t *testing.T) {
     // Arrange
     router := gin.New()
-    router.Use(AuthMiddleware("test-secret"))
+    router.Use(AuthMiddleware(auth.NewJWTManager("test-secret")))
     router.GET("/test", func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"message": "ok"})
     })
@@ -83,7 +84,7 @@ func TestAuthMiddlewareExpiredToken(t *testing.T) {
     token, _, _ := jwtManager.GenerateToken("user123", "test@example.com", "testuser", -1*time.Hour) // Expired
 
     router := gin.New()
-    router.Use(AuthMiddleware("test-secret"))
+    router.Use(AuthMiddleware(auth.NewJWTManager("test-secret")))
     router.GET("/test", func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"message": "ok"})
     })
@@ -105,7 +106,7 @@ func TestAuthMiddlewareContextValues(t *testing.T) {
     token, _, _ := jwtManager.GenerateToken("user123", "test@example.com", "testuser", 1*time.Hour)
 
     router := gin.New()
-    router.Use(AuthMiddleware("test-secret"))
+    router.Use(AuthMiddleware(auth.NewJWTManager("test-secret")))
     router.GET("/test", func(c *gin.Context) {
         userID, _ := c.Get("user_id")
         email, _ := c.Get("email")
@@ -129,4 +130,30 @@ func TestAuthMiddlewareContextValues(t *testing.T) {
     assert.Contains(t, w.Body.String(), "user123")
     assert.Contains(t, w.Body.String(), "test@example.com")
     assert.Contains(t, w.Body.String(), "testuser")
-}
\ No newline at end of file
+}
+
+func TestAdminRoutesNeedAdminRole(t *testing.T) {
+    policy, err := rbac.Load()
+    if err != nil {
+        t.Fatalf("failed to load RBAC policy: %v", err)
+    }
+
+    jwtManager := auth.NewJWTManager("test-secret")
+    adminToken, _, _ := jwtManager.GenerateTokenWithRole("admin1", "admin@example.com", "admin", "admin", 1*time.Hour)
+    customerToken, _, _ := jwtManager.GenerateTokenWithRole("user123", "test@example.com", "testuser", "customer", 1*time.Hour)
+
+    router := gin.New()
+    router.Use(AuthMiddleware(jwtManager), policy.Service("users").Middleware())
+    router.GET("/users/deleted", func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{"message": "ok"})
+    })
+
+    for token, want := range map[string]int{adminToken: http.StatusOK, customerToken: http.StatusForbidden} {
+        w := httptest.NewRecorder()
+        req := httptest.NewRequest(http.MethodGet, "/users/deleted", nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        router.ServeHTTP(w, req)
+
+        assert.Equal(t, want, w.Code)
+    }
+}
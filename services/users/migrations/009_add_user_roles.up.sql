@@ -0,0 +1,2 @@
+-- Roles carried in JWT claims; admin unlocks reporting endpoints
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'customer';
@@ -0,0 +1,2 @@
+-- Per-user preferences (locale, currency, marketing opt-in); keys are validated by the users service
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS preferences JSONB NOT NULL DEFAULT '{}';
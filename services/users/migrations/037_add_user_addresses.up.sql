@@ -0,0 +1,22 @@
+-- Saved shipping addresses. country is ISO 3166-1 alpha-2; a user has at most one default
+-- address. Orders keep a copy of the address they ship to (038), so editing or deleting an
+-- address doesn't change past orders.
+CREATE TABLE IF NOT EXISTS users.addresses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users.users(id) ON DELETE CASCADE,
+    label VARCHAR(50) NOT NULL DEFAULT '',
+    recipient_name VARCHAR(100) NOT NULL,
+    line1 VARCHAR(200) NOT NULL,
+    line2 VARCHAR(200) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(20) NOT NULL,
+    country CHAR(2) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_user_id ON users.addresses(user_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_one_default ON users.addresses(user_id) WHERE is_default;
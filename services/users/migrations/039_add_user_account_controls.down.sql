@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users.idx_users_role;
+ALTER TABLE users.users DROP COLUMN IF EXISTS password_reset_required;
+ALTER TABLE users.users DROP COLUMN IF EXISTS disabled_at;
@@ -0,0 +1,6 @@
+-- Admin account controls: a disabled account can't sign in, and one with
+-- password_reset_required has to set a new password at its next login
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP NULL;
+ALTER TABLE users.users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users.users(role);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS users.audit_logs;
@@ -0,0 +1,105 @@
+package models
+
+import (
+    "strings"
+    "time"
+)
+
+// MaxAddressesPerUser caps how many addresses one account can save
+const MaxAddressesPerUser = 20
+
+// Address is a saved shipping address of a user
+type Address struct {
+    ID            string    `json:"id"`
+    UserID        string    `json:"user_id"`
+    Label         string    `json:"label"` // e.g. "Home", "Work"; may be empty
+    RecipientName string    `json:"recipient_name"`
+    Line1         string    `json:"line1"`
+    Line2         string    `json:"line2"`
+    City          string    `json:"city"`
+    Region        string    `json:"region"` // state, province or county; may be empty
+    PostalCode    string    `json:"postal_code"`
+    Country       string    `json:"country"` // ISO 3166-1 alpha-2, upper case
+    Phone         string    `json:"phone"`
+    IsDefault     bool      `json:"is_default"`
+    CreatedAt     time.Time `json:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AddressRequest request body for POST /profile/:id/addresses and PUT /profile/:id/addresses/:address_id.
+// PUT replaces every field.
+type AddressRequest struct {
+    Label         string `json:"label"`
+    RecipientName string `json:"recipient_name"`
+    Line1         string `json:"line1"`
+    Line2         string `json:"line2"`
+    City          string `json:"city"`
+    Region        string `json:"region"`
+    PostalCode    string `json:"postal_code"`
+    Country       string `json:"country"`
+    Phone         string `json:"phone"`
+    IsDefault     bool   `json:"is_default"`
+}
+
+// Normalize trims every field and upper-cases the country code
+func (r *AddressRequest) Normalize() {
+    for _, field := range []*string{&r.Label, &r.RecipientName, &r.Line1, &r.Line2, &r.City, &r.Region, &r.PostalCode, &r.Country, &r.Phone} {
+        *field = strings.TrimSpace(*field)
+    }
+    r.Country = strings.ToUpper(r.Country)
+}
+
+// Validate validates AddressRequest (after Normalize)
+func (r AddressRequest) Validate() (bool, string) {
+    required := []struct{ name, value string }{
+        {"recipient_name", r.RecipientName},
+        {"line1", r.Line1},
+        {"city", r.City},
+        {"postal_code", r.PostalCode},
+        {"country", r.Country},
+    }
+    for _, field := range required {
+        if field.value == "" {
+            return false, field.name + " is required"
+        }
+    }
+
+    limits := []struct {
+        name  string
+        value string
+        max   int
+    }{
+        {"label", r.Label, 50},
+        {"recipient_name", r.RecipientName, 100},
+        {"line1", r.Line1, 200},
+        {"line2", r.Line2, 200},
+        {"city", r.City, 100},
+        {"region", r.Region, 100},
+        {"postal_code", r.PostalCode, 20},
+        {"phone", r.Phone, 30},
+    }
+    for _, field := range limits {
+        if len(field.value) > field.max {
+            return false, field.name + " is too long"
+        }
+    }
+
+    if len(r.Country) != 2 || strings.Trim(r.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+        return false, "country must be a two-letter ISO 3166-1 code"
+    }
+    return true, ""
+}
+
+// Apply copies the request's fields onto an address
+func (r AddressRequest) Apply(address *Address) {
+    address.Label = r.Label
+    address.RecipientName = r.RecipientName
+    address.Line1 = r.Line1
+    address.Line2 = r.Line2
+    address.City = r.City
+    address.Region = r.Region
+    address.PostalCode = r.PostalCode
+    address.Country = r.Country
+    address.Phone = r.Phone
+    address.IsDefault = r.IsDefault
+}
@@ -0,0 +1,58 @@
+package models
+
+// Account statuses admins filter the user list by (GET /users?status=)
+const (
+    UserStatusActive   = "active"
+    UserStatusDisabled = "disabled"
+    UserStatusDeleted  = "deleted"
+)
+
+// User list paging defaults
+const (
+    DefaultUserPageLimit = 20
+    MaxUserPageLimit     = 100
+)
+
+// UserFilter filters and pages the admin user list
+type UserFilter struct {
+    Role   string // empty for every role
+    Status string // empty for active and disabled accounts; deleted ones only with UserStatusDeleted
+    Search string // case-insensitive substring of email or username
+    Page   int    // 1-based
+    Limit  int
+}
+
+// Offset returns the row offset for the filter's page
+func (f UserFilter) Offset() int {
+    if f.Page <= 1 {
+        return 0
+    }
+    return (f.Page - 1) * f.Limit
+}
+
+// UserListResponse is one page of the admin user list
+type UserListResponse struct {
+    Users []*User `json:"users"`
+    Count int     `json:"count"` // users on this page
+    Total int     `json:"total"` // users matching the filter
+    Page  int     `json:"page"`
+    Limit int     `json:"limit"`
+}
+
+// IsValidRole reports whether role is one the JWT role claim may carry
+func IsValidRole(role string) bool {
+    return role == RoleCustomer || role == RoleAdmin
+}
+
+// UpdateRoleRequest request body for PUT /users/:id/role
+type UpdateRoleRequest struct {
+    Role string `json:"role"`
+}
+
+// Validate validates UpdateRoleRequest
+func (r UpdateRoleRequest) Validate() (bool, string) {
+    if !IsValidRole(r.Role) {
+        return false, "role must be customer or admin"
+    }
+    return true, ""
+}
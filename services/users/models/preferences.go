@@ -0,0 +1,61 @@
+package models
+
+// Preference defaults, used for keys a user never set
+const (
+    DefaultLocale   = "en-US"
+    DefaultCurrency = "USD"
+)
+
+// SupportedLocales lists the locales the storefront is translated into
+var SupportedLocales = map[string]bool{
+    "en-US": true,
+    "en-GB": true,
+    "de-DE": true,
+    "fr-FR": true,
+    "es-ES": true,
+    "hi-IN": true,
+}
+
+// SupportedCurrencies lists the ISO 4217 currencies prices can be shown in
+var SupportedCurrencies = map[string]bool{
+    "USD": true,
+    "EUR": true,
+    "GBP": true,
+    "INR": true,
+}
+
+// Preferences are a user's display and notification settings, stored as JSONB on users.users
+type Preferences struct {
+    Locale         string `json:"locale"`
+    Currency       string `json:"currency"`
+    MarketingOptIn bool   `json:"marketing_opt_in"`
+}
+
+// DefaultPreferences returns the preferences of a user who never set any
+func DefaultPreferences() Preferences {
+    return Preferences{
+        Locale:   DefaultLocale,
+        Currency: DefaultCurrency,
+    }
+}
+
+// UpdatePreferencesRequest request body for PATCH /profile/:id/preferences; omitted keys are unchanged
+type UpdatePreferencesRequest struct {
+    Locale         *string `json:"locale,omitempty"`
+    Currency       *string `json:"currency,omitempty"`
+    MarketingOptIn *bool   `json:"marketing_opt_in,omitempty"`
+}
+
+// Validate validates UpdatePreferencesRequest
+func (r UpdatePreferencesRequest) Validate() (bool, string) {
+    if r.Locale == nil && r.Currency == nil && r.MarketingOptIn == nil {
+        return false, "at least one of locale, currency, marketing_opt_in is required"
+    }
+    if r.Locale != nil && !SupportedLocales[*r.Locale] {
+        return false, "unsupported locale: " + *r.Locale
+    }
+    if r.Currency != nil && !SupportedCurrencies[*r.Currency] {
+        return false, "unsupported currency: " + *r.Currency
+    }
+    return true, ""
+}
@@ -6,6 +6,12 @@ import (
     "github.com/google/uuid"
 )
 
+// User roles
+const (
+    RoleCustomer = "customer"
+    RoleAdmin    = "admin"
+)
+
 // User represents a user in the system
 type User struct {
     ID           string    `json:"id"`
@@ -15,6 +21,9 @@ type User struct {
     CreatedAt    time.Time `json:"created_at"`
     UpdatedAt    time.Time `json:"updated_at"`
     DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+    DisabledAt   *time.Time `json:"disabled_at,omitempty"` // set by an admin; a disabled account can't sign in
+    PasswordResetRequired bool `json:"password_reset_required"` // the next login has to set a new password
+    Role         string    `json:"role"` // customer, admin
     OAuthProviders []OAuthProvider `json:"oauth_providers,omitempty"`
 }
 
@@ -39,8 +48,9 @@ type CreateUserRequest struct {
 
 // LoginRequest request body for user login
 type LoginRequest struct {
-    Email    string `json:"email"`
-    Password string `json:"password"`
+    Email       string `json:"email"`
+    Password    string `json:"password"`
+    NewPassword string `json:"new_password,omitempty"` // required while an admin-forced password reset is pending
 }
 
 // LoginResponse response containing JWT token
@@ -51,10 +61,18 @@ type LoginResponse struct {
     ExpiresIn    int    `json:"expires_in"`
     TokenType    string `json:"token_type"`
 }
-// UpdateProfileRequest request body for updating user profile
+// UpdateProfileRequest request body for updating user profile.
+// Changing the password needs current_password, unless the account has none (OAuth sign-up).
 type UpdateProfileRequest struct {
-    Email    string `json:"email,omitempty"`
-    Username string `json:"username,omitempty"`
+    Email           string `json:"email,omitempty"`
+    Username        string `json:"username,omitempty"`
+    CurrentPassword string `json:"current_password,omitempty"`
+    NewPassword     string `json:"new_password,omitempty"`
+}
+
+// DeleteAccountRequest request body for deleting the caller's account
+type DeleteAccountRequest struct {
+    Password string `json:"password"` // required unless the account has no password
 }
 
 // ErrorResponse standard error response
@@ -89,6 +107,17 @@ func (r LoginRequest) Validate() (bool, string) {
     if r.Password == "" {
         return false, "password is required"
     }
+    if r.NewPassword != "" && len(r.NewPassword) < 6 {
+        return false, "password must be at least 6 characters"
+    }
+    return true, ""
+}
+
+// Validate validates UpdateProfileRequest
+func (r UpdateProfileRequest) Validate() (bool, string) {
+    if r.NewPassword != "" && len(r.NewPassword) < 6 {
+        return false, "password must be at least 6 characters"
+    }
     return true, ""
 }
 
@@ -100,6 +129,7 @@ func NewUser(email, username, passwordHash string) *User {
         Email:        email,
         Username:     username,
         PasswordHash: passwordHash,
+        Role:         RoleCustomer,
         CreatedAt:    now,
         UpdatedAt:    now,
     }
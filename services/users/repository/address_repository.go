@@ -0,0 +1,237 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+
+    "github.com/sanketh-sg/prost/services/users/models"
+)
+
+var (
+    // ErrAddressNotFound is returned for an address that doesn't exist or belongs to another user
+    ErrAddressNotFound = errors.New("address not found")
+    // ErrAddressLimit is returned when a user already has models.MaxAddressesPerUser addresses
+    ErrAddressLimit = errors.New("address limit reached")
+)
+
+const addressColumns = `id, user_id, label, recipient_name, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanAddress(row rowScanner) (*models.Address, error) {
+    address := &models.Address{}
+    err := row.Scan(
+        &address.ID,
+        &address.UserID,
+        &address.Label,
+        &address.RecipientName,
+        &address.Line1,
+        &address.Line2,
+        &address.City,
+        &address.Region,
+        &address.PostalCode,
+        &address.Country,
+        &address.Phone,
+        &address.IsDefault,
+        &address.CreatedAt,
+        &address.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return address, nil
+}
+
+// ListAddresses returns a user's addresses, the default first, then oldest first
+func (userRepo *UserRepository) ListAddresses(ctx context.Context, userID string) ([]*models.Address, error) {
+    query := userRepo.dbConn.Qualify(`
+        SELECT ` + addressColumns + `
+        FROM $schema.addresses
+        WHERE user_id = $1
+        ORDER BY is_default DESC, created_at, id
+    `)
+
+    rows, err := userRepo.dbConn.QueryContext(ctx, query, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list addresses: %w", err)
+    }
+    defer rows.Close()
+
+    addresses := []*models.Address{}
+    for rows.Next() {
+        address, err := scanAddress(rows)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan address: %w", err)
+        }
+        addresses = append(addresses, address)
+    }
+    return addresses, rows.Err()
+}
+
+// GetAddress returns one of a user's addresses
+func (userRepo *UserRepository) GetAddress(ctx context.Context, userID, addressID string) (*models.Address, error) {
+    query := userRepo.dbConn.Qualify(`
+        SELECT ` + addressColumns + `
+        FROM $schema.addresses
+        WHERE id::text = $1 AND user_id = $2
+    `)
+
+    address, err := scanAddress(userRepo.dbConn.QueryRowContext(ctx, query, addressID, userID))
+    if err == sql.ErrNoRows {
+        return nil, ErrAddressNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get address: %w", err)
+    }
+    return address, nil
+}
+
+// CreateAddress saves a new address. A user's first address becomes their default; a new
+// default takes over from the old one in the same transaction.
+func (userRepo *UserRepository) CreateAddress(ctx context.Context, address *models.Address) error {
+    tx, err := userRepo.dbConn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Why: lock the user row so two concurrent creates can't both pass the limit check
+    lockUser := userRepo.dbConn.Qualify(`SELECT id FROM $schema.users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)
+    var lockedID string
+    if err := tx.QueryRowContext(ctx, lockUser, address.UserID).Scan(&lockedID); err != nil {
+        if err == sql.ErrNoRows {
+            return ErrUserNotFound
+        }
+        return fmt.Errorf("failed to lock user: %w", err)
+    }
+
+    var count int
+    countQuery := userRepo.dbConn.Qualify(`SELECT COUNT(*) FROM $schema.addresses WHERE user_id = $1`)
+    if err := tx.QueryRowContext(ctx, countQuery, address.UserID).Scan(&count); err != nil {
+        return fmt.Errorf("failed to count addresses: %w", err)
+    }
+    if count >= models.MaxAddressesPerUser {
+        return ErrAddressLimit
+    }
+    if count == 0 {
+        address.IsDefault = true
+    }
+
+    if address.IsDefault {
+        if err := userRepo.clearDefaultAddress(ctx, tx, address.UserID); err != nil {
+            return err
+        }
+    }
+
+    insertQuery := userRepo.dbConn.Qualify(`
+        INSERT INTO $schema.addresses
+        (id, user_id, label, recipient_name, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+        RETURNING ` + addressColumns)
+
+    created, err := scanAddress(tx.QueryRowContext(ctx, insertQuery,
+        address.ID,
+        address.UserID,
+        address.Label,
+        address.RecipientName,
+        address.Line1,
+        address.Line2,
+        address.City,
+        address.Region,
+        address.PostalCode,
+        address.Country,
+        address.Phone,
+        address.IsDefault,
+        address.CreatedAt,
+    ))
+    if err != nil {
+        return fmt.Errorf("failed to create address: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit address: %w", err)
+    }
+
+    *address = *created
+    return nil
+}
+
+// UpdateAddress replaces the fields of one of a user's addresses; making it the default
+// takes that over from the old one in the same transaction
+func (userRepo *UserRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+    tx, err := userRepo.dbConn.BeginTx(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if address.IsDefault {
+        if err := userRepo.clearDefaultAddress(ctx, tx, address.UserID); err != nil {
+            return err
+        }
+    }
+
+    updateQuery := userRepo.dbConn.Qualify(`
+        UPDATE $schema.addresses
+        SET label = $1, recipient_name = $2, line1 = $3, line2 = $4, city = $5, region = $6,
+            postal_code = $7, country = $8, phone = $9, is_default = $10, updated_at = $11
+        WHERE id::text = $12 AND user_id = $13
+        RETURNING ` + addressColumns)
+
+    updated, err := scanAddress(tx.QueryRowContext(ctx, updateQuery,
+        address.Label,
+        address.RecipientName,
+        address.Line1,
+        address.Line2,
+        address.City,
+        address.Region,
+        address.PostalCode,
+        address.Country,
+        address.Phone,
+        address.IsDefault,
+        address.UpdatedAt,
+        address.ID,
+        address.UserID,
+    ))
+    if err == sql.ErrNoRows {
+        return ErrAddressNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to update address: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit address: %w", err)
+    }
+
+    *address = *updated
+    return nil
+}
+
+// DeleteAddress removes one of a user's addresses. Deleting the default leaves the user
+// without one until they pick another.
+func (userRepo *UserRepository) DeleteAddress(ctx context.Context, userID, addressID string) error {
+    query := userRepo.dbConn.Qualify(`DELETE FROM $schema.addresses WHERE id::text = $1 AND user_id = $2`)
+
+    result, err := userRepo.dbConn.ExecContext(ctx, query, addressID, userID)
+    if err != nil {
+        return fmt.Errorf("failed to delete address: %w", err)
+    }
+    if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+        return ErrAddressNotFound
+    }
+    return nil
+}
+
+// clearDefaultAddress unsets the user's default address inside tx
+func (userRepo *UserRepository) clearDefaultAddress(ctx context.Context, tx *sql.Tx, userID string) error {
+    query := userRepo.dbConn.Qualify(`UPDATE $schema.addresses SET is_default = FALSE WHERE user_id = $1 AND is_default`)
+    if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+        return fmt.Errorf("failed to clear default address: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/users/models"
+)
+
+// ErrNoPassword is returned when forcing a password reset on an account without a password (OAuth sign-up)
+var ErrNoPassword = errors.New("account has no password")
+
+const adminUserColumns = `id, email, username, role, created_at, updated_at, deleted_at, disabled_at, password_reset_required`
+
+func scanAdminUser(row rowScanner) (*models.User, error) {
+    user := &models.User{}
+    err := row.Scan(
+        &user.ID,
+        &user.Email,
+        &user.Username,
+        &user.Role,
+        &user.CreatedAt,
+        &user.UpdatedAt,
+        &user.DeletedAt,
+        &user.DisabledAt,
+        &user.PasswordResetRequired,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return user, nil
+}
+
+// ListUsers returns one page of users matching filter, newest first, and how many match in all
+func (userRepo *UserRepository) ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error) {
+    var conditions []string
+    var args []interface{}
+    arg := func(value interface{}) string {
+        args = append(args, value)
+        return fmt.Sprintf("$%d", len(args))
+    }
+
+    switch filter.Status {
+    case models.UserStatusActive:
+        conditions = append(conditions, "deleted_at IS NULL", "disabled_at IS NULL")
+    case models.UserStatusDisabled:
+        conditions = append(conditions, "deleted_at IS NULL", "disabled_at IS NOT NULL")
+    case models.UserStatusDeleted:
+        conditions = append(conditions, "deleted_at IS NOT NULL")
+    default:
+        conditions = append(conditions, "deleted_at IS NULL")
+    }
+    if filter.Role != "" {
+        conditions = append(conditions, "role = "+arg(filter.Role))
+    }
+    if filter.Search != "" {
+        pattern := arg("%" + escapeLike(filter.Search) + "%")
+        conditions = append(conditions, "(email ILIKE "+pattern+" OR username ILIKE "+pattern+")")
+    }
+    where := strings.Join(conditions, " AND ")
+
+    var total int
+    countQuery := userRepo.dbConn.Qualify(`SELECT COUNT(*) FROM $schema.users WHERE ` + where)
+    if err := userRepo.dbConn.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+        return nil, 0, fmt.Errorf("failed to count users: %w", err)
+    }
+
+    query := userRepo.dbConn.Qualify(`
+        SELECT ` + adminUserColumns + `
+        FROM $schema.users
+        WHERE ` + where + `
+        ORDER BY created_at DESC, id
+        LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset()))
+
+    rows, err := userRepo.dbConn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to list users: %w", err)
+    }
+    defer rows.Close()
+
+    users := []*models.User{}
+    for rows.Next() {
+        user, err := scanAdminUser(rows)
+        if err != nil {
+            return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+        }
+        users = append(users, user)
+    }
+    return users, total, rows.Err()
+}
+
+// SetUserDisabled disables or re-enables an account. Disabling one that already is keeps its
+// original disabled_at.
+func (userRepo *UserRepository) SetUserDisabled(ctx context.Context, id string, disabled bool) (*models.User, error) {
+    query := userRepo.dbConn.Qualify(`
+        UPDATE $schema.users
+        SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, $2) END, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+        RETURNING ` + adminUserColumns)
+
+    user, err := scanAdminUser(userRepo.dbConn.QueryRowContext(ctx, query, disabled, time.Now().UTC(), id))
+    if err == sql.ErrNoRows {
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to update user: %w", err)
+    }
+    return user, nil
+}
+
+// RequirePasswordReset makes the account's next login set a new password
+func (userRepo *UserRepository) RequirePasswordReset(ctx context.Context, id string) (*models.User, error) {
+    query := userRepo.dbConn.Qualify(`
+        UPDATE $schema.users
+        SET password_reset_required = TRUE, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NULL AND COALESCE(password_hash, '') <> ''
+        RETURNING ` + adminUserColumns)
+
+    user, err := scanAdminUser(userRepo.dbConn.QueryRowContext(ctx, query, time.Now().UTC(), id))
+    if err == nil {
+        return user, nil
+    }
+    if err != sql.ErrNoRows {
+        return nil, fmt.Errorf("failed to update user: %w", err)
+    }
+
+    // Nothing updated: unknown id or an account signed up through OAuth
+    var exists bool
+    err = userRepo.dbConn.QueryRowContext(ctx, userRepo.dbConn.Qualify(`SELECT true FROM $schema.users WHERE id = $1 AND deleted_at IS NULL`), id).Scan(&exists)
+    if err == sql.ErrNoRows {
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+    return nil, ErrNoPassword
+}
+
+// UpdateRole changes the account's role. Tokens already issued keep the old role until they expire.
+func (userRepo *UserRepository) UpdateRole(ctx context.Context, id, role string) (*models.User, error) {
+    query := userRepo.dbConn.Qualify(`
+        UPDATE $schema.users
+        SET role = $1, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+        RETURNING ` + adminUserColumns)
+
+    user, err := scanAdminUser(userRepo.dbConn.QueryRowContext(ctx, query, role, time.Now().UTC(), id))
+    if err == sql.ErrNoRows {
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to update user role: %w", err)
+    }
+    return user, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, so a search for "a_b" doesn't match "axb"
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
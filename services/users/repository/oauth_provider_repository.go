@@ -29,7 +29,7 @@ func (opr *OAuthProviderRepository) GetByProviderSub(ctx context.Context, provid
         FROM $schema.oauth_providers
         WHERE provider = $1 AND provider_sub = $2
     `
-    query = replaceSchema(query, opr.conn.Schema)
+    query = opr.conn.Qualify(query)
 
     var oauthProvider models.OAuthProvider
 
@@ -58,7 +58,7 @@ func (opr *OAuthProviderRepository) CreateOAuthProvider(ctx context.Context, oau
         VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING id, user_id, provider, provider_sub, provider_email, created_at, updated_at
     `
-    query = replaceSchema(query, opr.conn.Schema)
+    query = opr.conn.Qualify(query)
 
     now := time.Now().UTC()
     oauthProvider.ID = uuid.New().String()
@@ -98,7 +98,7 @@ func (opr *OAuthProviderRepository) GetByUserID(ctx context.Context, userID stri
         FROM $schema.oauth_providers
         WHERE user_id = $1
     `
-    query = replaceSchema(query, opr.conn.Schema)
+    query = opr.conn.Qualify(query)
 
     rows, err := opr.conn.QueryContext(ctx, query, userID)
     if err != nil {
@@ -0,0 +1,62 @@
+package repository
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/sanketh-sg/prost/services/users/models"
+)
+
+// GetPreferences returns a user's preferences, with defaults for keys never set.
+// A missing user returns an error wrapping sql.ErrNoRows.
+func (userRepo *UserRepository) GetPreferences(ctx context.Context, userID string) (*models.Preferences, error) {
+    query := `
+        SELECT preferences
+        FROM $schema.users
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    var raw []byte
+    if err := userRepo.dbConn.QueryRowContext(ctx, query, userID).Scan(&raw); err != nil {
+        return nil, fmt.Errorf("failed to get preferences: %w", err)
+    }
+
+    return decodePreferences(raw)
+}
+
+// UpdatePreferences merges the keys set in req into the stored preferences and returns the result.
+// Why: the merge happens in one UPDATE (jsonb ||), so concurrent PATCHes of different keys don't
+// overwrite each other.
+func (userRepo *UserRepository) UpdatePreferences(ctx context.Context, userID string, req models.UpdatePreferencesRequest) (*models.Preferences, error) {
+    patch, err := json.Marshal(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal preferences: %w", err)
+    }
+
+    query := `
+        UPDATE $schema.users
+        SET preferences = preferences || $1::jsonb, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+        RETURNING preferences
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    var raw []byte
+    if err := userRepo.dbConn.QueryRowContext(ctx, query, patch, time.Now().UTC(), userID).Scan(&raw); err != nil {
+        return nil, fmt.Errorf("failed to update preferences: %w", err)
+    }
+
+    return decodePreferences(raw)
+}
+
+// decodePreferences decodes stored preferences over the defaults
+func decodePreferences(raw []byte) (*models.Preferences, error) {
+    prefs := models.DefaultPreferences()
+    if err := json.Unmarshal(raw, &prefs); err != nil {
+        return nil, fmt.Errorf("failed to decode preferences: %w", err)
+    }
+    return &prefs, nil
+}
@@ -12,7 +12,22 @@ type UserRepositoryInterface interface {
     GetUserByEmail(ctx context.Context, email string) (*models.User, error)
     GetUserByID(ctx context.Context, userID string) (*models.User, error)
     UpdateUser(ctx context.Context, user *models.User) error
+    UpdatePasswordHash(ctx context.Context, id, oldHash, newHash string) (bool, error)
+    ResetPassword(ctx context.Context, id, oldHash, newHash string) (bool, error)
     DeleteUser(ctx context.Context, id string) error
+    GetDeletedUsers(ctx context.Context) ([]*models.User, error)
+    RestoreUser(ctx context.Context, id string) error
+    ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error)
+    SetUserDisabled(ctx context.Context, id string, disabled bool) (*models.User, error)
+    RequirePasswordReset(ctx context.Context, id string) (*models.User, error)
+    UpdateRole(ctx context.Context, id, role string) (*models.User, error)
     EmailExists(ctx context.Context, email string) (bool, error)
     UsernameExists(ctx context.Context, username string) (bool, error)
+    GetPreferences(ctx context.Context, userID string) (*models.Preferences, error)
+    UpdatePreferences(ctx context.Context, userID string, req models.UpdatePreferencesRequest) (*models.Preferences, error)
+    ListAddresses(ctx context.Context, userID string) ([]*models.Address, error)
+    GetAddress(ctx context.Context, userID, addressID string) (*models.Address, error)
+    CreateAddress(ctx context.Context, address *models.Address) error
+    UpdateAddress(ctx context.Context, address *models.Address) error
+    DeleteAddress(ctx context.Context, userID, addressID string) error
 }
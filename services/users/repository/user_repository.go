@@ -2,14 +2,21 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/sanketh-sg/prost/services/users/models"
 	"github.com/sanketh-sg/prost/shared/db"
-	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+    // ErrUserNotFound is returned when no user, deleted or not, has the given id
+    ErrUserNotFound = errors.New("user not found")
+    // ErrUserNotDeleted is returned when restoring a user that isn't soft-deleted
+    ErrUserNotDeleted = errors.New("user is not deleted")
 )
 
 // UserRepository handles user database operations
@@ -29,9 +36,9 @@ func (userRepo *UserRepository) CreateUser(ctx context.Context, user *models.Use
 	query := `
         INSERT INTO $schema.users (id, email, username, password_hash, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, email, username, created_at, updated_at
+        RETURNING id, email, username, role, created_at, updated_at
     `
-	query = replaceSchema(query, userRepo.dbConn.Schema)
+	query = userRepo.dbConn.Qualify(query)
 
 	err := userRepo.dbConn.QueryRowContext(ctx, query, 
 		user.ID,
@@ -40,7 +47,7 @@ func (userRepo *UserRepository) CreateUser(ctx context.Context, user *models.Use
 		user.PasswordHash,
 		user.CreatedAt,
 		user.UpdatedAt,
-	).Scan(&user.ID,&user.Email,&user.Username,&user.CreatedAt,&user.UpdatedAt) //copies the matched row to dest and Converts bytes to proper types
+	).Scan(&user.ID,&user.Email,&user.Username,&user.Role,&user.CreatedAt,&user.UpdatedAt) //copies the matched row to dest and Converts bytes to proper types
 
     if err != nil {
         log.Printf("Error creating user: %v", err)
@@ -53,12 +60,12 @@ func (userRepo *UserRepository) CreateUser(ctx context.Context, user *models.Use
 // GetUserByEmail retrieves a user by email
 func (userRepo *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-	 	SELECT id, email, username, password_hash, created_at, updated_at
+	 	SELECT id, email, username, password_hash, role, created_at, updated_at, disabled_at, password_reset_required
         FROM $schema.users
         WHERE email = $1 AND deleted_at IS NULL
 	`
 
-	query = replaceSchema(query, userRepo.dbConn.Schema)
+	query = userRepo.dbConn.Qualify(query)
     log.Println(query)
 
 	user := &models.User{}
@@ -67,8 +74,11 @@ func (userRepo *UserRepository) GetUserByEmail(ctx context.Context, email string
         &user.Email,
         &user.Username,
         &user.PasswordHash,
+        &user.Role,
         &user.CreatedAt,
         &user.UpdatedAt,
+        &user.DisabledAt,
+        &user.PasswordResetRequired,
     )
 
     if err != nil {
@@ -82,11 +92,11 @@ func (userRepo *UserRepository) GetUserByEmail(ctx context.Context, email string
 // GetUserByID retrieves a user by ID
 func (userRepo *UserRepository) GetUserByID(ctx context.Context, userId string)(*models.User, error){
 	query := ` 
-		SELECT id, email, username, password_hash, created_at, updated_at, deleted_at
+		SELECT id, email, username, password_hash, role, created_at, updated_at, deleted_at, disabled_at, password_reset_required
         FROM $schema.users
         WHERE id = $1 AND deleted_at IS NULL
 	`
-	query = replaceSchema(query,userRepo.dbConn.Schema)
+	query = userRepo.dbConn.Qualify(query)
     log.Println(query)
 	user := &models.User{}
 	err := userRepo.dbConn.QueryRowContext(ctx,query,userId).Scan(
@@ -94,9 +104,12 @@ func (userRepo *UserRepository) GetUserByID(ctx context.Context, userId string)(
         &user.Email,
         &user.Username,
         &user.PasswordHash,
+        &user.Role,
         &user.CreatedAt,
         &user.UpdatedAt,
         &user.DeletedAt,
+        &user.DisabledAt,
+        &user.PasswordResetRequired,
 	)
 	if err != nil {
         return nil, fmt.Errorf("failed to get user by id: %w", err)
@@ -104,23 +117,26 @@ func (userRepo *UserRepository) GetUserByID(ctx context.Context, userId string)(
 
     return user, nil
 }
-// UpdateUser updates user profile information
+// UpdateUser updates user profile information, including the password hash.
+// A new password hash also settles a pending forced password reset.
 func (userRepo *UserRepository) UpdateUser(ctx context.Context, user *models.User) error {
     query := `
         UPDATE $schema.users
-        SET email = $1, username = $2, updated_at = $3
-        WHERE id = $4 AND deleted_at IS NULL
-        RETURNING id, email, username, created_at, updated_at
+        SET email = $1, username = $2, password_hash = $3, updated_at = $4,
+            password_reset_required = password_reset_required AND password_hash IS NOT DISTINCT FROM $3
+        WHERE id = $5 AND deleted_at IS NULL
+        RETURNING id, email, username, role, created_at, updated_at
     `
 
-    query = replaceSchema(query, userRepo.dbConn.Schema)
+    query = userRepo.dbConn.Qualify(query)
 
     err := userRepo.dbConn.QueryRowContext(ctx, query,
         user.Email,
         user.Username,
+        user.PasswordHash,
         time.Now().UTC(),
         user.ID,
-    ).Scan(&user.ID, &user.Email, &user.Username, &user.CreatedAt, &user.UpdatedAt)
+    ).Scan(&user.ID, &user.Email, &user.Username, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
     if err != nil {
         return fmt.Errorf("failed to update user: %w", err)
@@ -128,6 +144,52 @@ func (userRepo *UserRepository) UpdateUser(ctx context.Context, user *models.Use
 
     return nil
 }
+
+// UpdatePasswordHash replaces a user's password hash if it is still oldHash, and reports
+// whether it was replaced.
+// Why: a login rehashing the password must not undo a password change made meanwhile
+func (userRepo *UserRepository) UpdatePasswordHash(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+    query := `
+        UPDATE $schema.users
+        SET password_hash = $1
+        WHERE id = $2 AND password_hash = $3 AND deleted_at IS NULL
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    result, err := userRepo.dbConn.ExecContext(ctx, query, newHash, id, oldHash)
+    if err != nil {
+        return false, fmt.Errorf("failed to update password hash: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    return rowsAffected > 0, nil
+}
+
+// ResetPassword sets the new password of a forced password reset and clears the flag, if the
+// hash is still oldHash. It reports whether the password was replaced.
+func (userRepo *UserRepository) ResetPassword(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+    query := `
+        UPDATE $schema.users
+        SET password_hash = $1, password_reset_required = FALSE, updated_at = $2
+        WHERE id = $3 AND password_hash = $4 AND deleted_at IS NULL
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    result, err := userRepo.dbConn.ExecContext(ctx, query, newHash, time.Now().UTC(), id, oldHash)
+    if err != nil {
+        return false, fmt.Errorf("failed to reset password: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    return rowsAffected > 0, nil
+}
+
 // DeleteUser soft deletes a user
 func (userRepo *UserRepository) DeleteUser(ctx context.Context, id string) error {
     query := `
@@ -136,7 +198,7 @@ func (userRepo *UserRepository) DeleteUser(ctx context.Context, id string) error
         WHERE id = $3
     `
 
-    query = replaceSchema(query, userRepo.dbConn.Schema)
+    query = userRepo.dbConn.Qualify(query)
 
     result, err := userRepo.dbConn.ExecContext(ctx, query, time.Now().UTC(), time.Now().UTC(), id)
     if err != nil {
@@ -154,6 +216,69 @@ func (userRepo *UserRepository) DeleteUser(ctx context.Context, id string) error
 
     return nil
 }
+// GetDeletedUsers returns soft-deleted users, most recently deleted first
+func (userRepo *UserRepository) GetDeletedUsers(ctx context.Context) ([]*models.User, error) {
+    query := `
+        SELECT id, email, username, role, created_at, updated_at, deleted_at
+        FROM $schema.users
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    rows, err := userRepo.dbConn.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get deleted users: %w", err)
+    }
+    defer rows.Close()
+
+    users := []*models.User{}
+    for rows.Next() {
+        user := &models.User{}
+        if err := rows.Scan(&user.ID, &user.Email, &user.Username, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan user: %w", err)
+        }
+        users = append(users, user)
+    }
+
+    return users, rows.Err()
+}
+
+// RestoreUser clears deleted_at of a soft-deleted user.
+// Why: email and username stay unique across deleted rows, so a restore can't collide with a newer account
+func (userRepo *UserRepository) RestoreUser(ctx context.Context, id string) error {
+    query := `
+        UPDATE $schema.users
+        SET deleted_at = NULL, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NOT NULL
+    `
+    query = userRepo.dbConn.Qualify(query)
+
+    result, err := userRepo.dbConn.ExecContext(ctx, query, time.Now().UTC(), id)
+    if err != nil {
+        return fmt.Errorf("failed to restore user: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rowsAffected > 0 {
+        return nil
+    }
+
+    // Nothing restored: unknown id or a live user
+    var exists bool
+    err = userRepo.dbConn.QueryRowContext(ctx, userRepo.dbConn.Qualify(`SELECT true FROM $schema.users WHERE id = $1`), id).Scan(&exists)
+    if err == sql.ErrNoRows {
+        return ErrUserNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+    return ErrUserNotDeleted
+}
+
 // EmailExists checks if email already exists
 func (userRepo *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
     query := `
@@ -163,7 +288,7 @@ func (userRepo *UserRepository) EmailExists(ctx context.Context, email string) (
         )
     `
 
-    query = replaceSchema(query, userRepo.dbConn.Schema)
+    query = userRepo.dbConn.Qualify(query)
 
     var exists bool
     err := userRepo.dbConn.QueryRowContext(ctx, query, email).Scan(&exists)
@@ -182,7 +307,7 @@ func (userRepo *UserRepository) UsernameExists(ctx context.Context, username str
         )
     `
 
-    query = replaceSchema(query, userRepo.dbConn.Schema)
+    query = userRepo.dbConn.Qualify(query)
 
     var exists bool
     err := userRepo.dbConn.QueryRowContext(ctx, query, username).Scan(&exists)
@@ -192,23 +317,3 @@ func (userRepo *UserRepository) UsernameExists(ctx context.Context, username str
 
     return exists, nil
 }
-// Helper function to replace schema placeholder
-func replaceSchema(query, schema string) string {
-    return strings.ReplaceAll(query, "$schema", schema)
-}
-
-// HashPassword generates a bcrypt hash of the password
-func HashPassword(password string)(string, error){
-	hash, err := bcrypt.GenerateFromPassword([]byte(password),bcrypt.DefaultCost)
-	if err != nil {
-        return "", fmt.Errorf("failed to hash password: %w", err)
-    }
-    return string(hash), nil
-}
-// VerifyPassword checks if the password matches the hash
-func VerifyPassword(hashedPassword, password string) bool {
-    
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword),[]byte(password))
-
-	return err == nil
-}
@@ -0,0 +1,228 @@
+// Package audit records who did what for admin and security-relevant actions, in the
+// audit_logs table of each service's schema.
+//
+// Why: logs rotate and don't say who was behind a request. An audit entry keeps the actor, their
+// IP and the target's state before and after, so "who deleted this product" has an answer.
+// Handlers record entries through Write; Middleware attaches the caller to the request context,
+// so a handler only says what happened.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/db"
+)
+
+// Actions recorded by the services
+const (
+	ActionProductCreated    = "product.created"
+	ActionProductUpdated    = "product.updated"
+	ActionProductDeleted    = "product.deleted"
+	ActionInventoryAdjusted = "inventory.adjusted"
+	ActionOrderCancelled    = "order.cancelled"
+	ActionUserRoleChanged   = "user.role_changed"
+	ActionUserDisabled      = "user.disabled"
+	ActionUserEnabled       = "user.enabled"
+	ActionUserPasswordReset = "user.password_reset_forced"
+	ActionUserRestored      = "user.restored"
+	ActionUserDeleted       = "user.deleted"
+	ActionLoginFailed       = "auth.login_failed"
+)
+
+// Entry is one audited action
+type Entry struct {
+	ID         int64           `json:"id"`
+	Service    string          `json:"service"`
+	Action     string          `json:"action"`
+	ActorID    string          `json:"actor_id,omitempty"` // empty for anonymous callers, e.g. failed logins
+	ActorRole  string          `json:"actor_role,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	TargetType string          `json:"target_type,omitempty"` // product, order, user
+	TargetID   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`  // target before the action
+	After      json.RawMessage `json:"after,omitempty"`   // target after the action
+	Details    json.RawMessage `json:"details,omitempty"` // context that isn't target state, e.g. why a login failed
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// JSON marshals v for Entry.Before, After or Details; nil and unmarshalable values give nil
+func JSON(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// Recorder stores audit entries
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+// Write records entry with the actor of ctx (see Middleware) filling the actor fields it leaves
+// empty. A nil recorder records nothing.
+// A failure is only logged: the action has already happened and must not be reported as failed.
+func Write(ctx context.Context, recorder Recorder, entry Entry) {
+	if recorder == nil {
+		return
+	}
+
+	actor := ActorFrom(ctx)
+	if entry.ActorID == "" {
+		entry.ActorID = actor.ID
+	}
+	if entry.ActorRole == "" {
+		entry.ActorRole = actor.Role
+	}
+	if entry.IP == "" {
+		entry.IP = actor.IP
+	}
+
+	if err := recorder.Record(ctx, entry); err != nil {
+		log.Printf("❌ Failed to record audit entry %s %s/%s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+// Store reads and writes the audit_logs table of a service's schema
+type Store struct {
+	conn    *db.Connection
+	service string
+}
+
+// NewStore creates an audit store; service names the entries it returns
+func NewStore(conn *db.Connection, service string) *Store {
+	return &Store{conn: conn, service: service}
+}
+
+const entryColumns = `id, action, actor_id, actor_role, ip, target_type, target_id, before, after, details, created_at`
+
+// Record inserts entry
+func (s *Store) Record(ctx context.Context, entry Entry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	query := s.conn.Qualify(`
+		INSERT INTO $schema.audit_logs
+		(action, actor_id, actor_role, ip, target_type, target_id, before, after, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`)
+
+	_, err := s.conn.ExecContext(ctx, query,
+		entry.Action,
+		nullString(entry.ActorID),
+		nullString(entry.ActorRole),
+		nullString(entry.IP),
+		nullString(entry.TargetType),
+		nullString(entry.TargetID),
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		nullJSON(entry.Details),
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// List returns one page of entries matching filter, newest first, and how many match in all
+func (s *Store) List(ctx context.Context, filter Filter) ([]Entry, int, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+arg(filter.Action))
+	}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = "+arg(filter.ActorID))
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = "+arg(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		conditions = append(conditions, "target_id = "+arg(filter.TargetID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.To))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := s.conn.Qualify(`SELECT COUNT(*) FROM $schema.audit_logs ` + where)
+	if err := s.conn.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := s.conn.Qualify(`
+		SELECT ` + entryColumns + `
+		FROM $schema.audit_logs
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset()))
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		entry := Entry{Service: s.service}
+		var actorID, actorRole, ip, targetType, targetID *string
+		var before, after, details []byte
+		if err := rows.Scan(&entry.ID, &entry.Action, &actorID, &actorRole, &ip, &targetType, &targetID, &before, &after, &details, &entry.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.ActorID = deref(actorID)
+		entry.ActorRole = deref(actorRole)
+		entry.IP = deref(ip)
+		entry.TargetType = deref(targetType)
+		entry.TargetID = deref(targetID)
+		entry.Before = before
+		entry.After = after
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
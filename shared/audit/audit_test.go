@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/identity"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+type recorderFunc func(ctx context.Context, entry Entry) error
+
+func (f recorderFunc) Record(ctx context.Context, entry Entry) error {
+	return f(ctx, entry)
+}
+
+// serve runs one request through Middleware and a handler that writes an entry
+func serve(t *testing.T, keys *jwtkeys.KeySet, header string, before gin.HandlerFunc) Entry {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var got Entry
+	recorder := recorderFunc(func(ctx context.Context, entry Entry) error {
+		got = entry
+		return nil
+	})
+
+	router := gin.New()
+	router.Use(Middleware(keys))
+	if before != nil {
+		router.Use(before)
+	}
+	router.POST("/things/:id", func(c *gin.Context) {
+		Write(c.Request.Context(), recorder, Entry{Action: ActionProductDeleted, TargetType: "product", TargetID: c.Param("id")})
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/things/42", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+func TestMiddlewareUsesCallerSetLater(t *testing.T) {
+	// the JWT middleware runs after Middleware, as with a route group
+	setCaller := func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Set("role", "admin")
+	}
+
+	got := serve(t, nil, "", setCaller)
+	if got.ActorID != "admin-1" || got.ActorRole != "admin" || got.IP != "203.0.113.7" {
+		t.Errorf("actor = %q %q %q, want admin-1 admin 203.0.113.7", got.ActorID, got.ActorRole, got.IP)
+	}
+	if got.TargetID != "42" {
+		t.Errorf("target = %q, want 42", got.TargetID)
+	}
+}
+
+func TestMiddlewareFallsBackToBearerToken(t *testing.T) {
+	keys := jwtkeys.NewHMACKeySet("test-secret")
+	token, err := keys.Sign(identity.Claims{UserID: "user-9", Role: "customer"})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	got := serve(t, keys, "Bearer "+token, nil)
+	if got.ActorID != "user-9" || got.ActorRole != "customer" {
+		t.Errorf("actor = %q %q, want user-9 customer", got.ActorID, got.ActorRole)
+	}
+
+	forged, _ := jwtkeys.NewHMACKeySet("other-secret").Sign(identity.Claims{UserID: "user-9", Role: "admin"})
+	got = serve(t, keys, "Bearer "+forged, nil)
+	if got.ActorID != "" || got.ActorRole != "" {
+		t.Errorf("actor from unverified token = %q %q, want none", got.ActorID, got.ActorRole)
+	}
+}
+
+func TestWriteKeepsExplicitActorAndIgnoresFailures(t *testing.T) {
+	ctx := WithActor(context.Background(), Actor{ID: "admin-1", Role: "admin", IP: "10.0.0.1"})
+
+	var got Entry
+	Write(ctx, recorderFunc(func(ctx context.Context, entry Entry) error {
+		got = entry
+		return errors.New("database down")
+	}), Entry{Action: ActionLoginFailed, ActorID: "someone-else"})
+
+	if got.ActorID != "someone-else" || got.ActorRole != "admin" || got.IP != "10.0.0.1" {
+		t.Errorf("entry actor = %q %q %q", got.ActorID, got.ActorRole, got.IP)
+	}
+
+	Write(ctx, nil, Entry{Action: ActionLoginFailed}) // no recorder, no panic
+}
+
+func TestJSON(t *testing.T) {
+	if got := JSON(nil); got != nil {
+		t.Errorf("JSON(nil) = %s, want nil", got)
+	}
+	var missing *Entry
+	if got := JSON(missing); got != nil {
+		t.Errorf("JSON(nil pointer) = %s, want nil", got)
+	}
+	if got := string(JSON(map[string]string{"role": "admin"})); got != `{"role":"admin"}` {
+		t.Errorf("JSON(map) = %s", got)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string) (Filter, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/admin/audit-logs?"+query, nil)
+		return ParseFilter(c)
+	}
+
+	filter, err := parse("action=user.disabled&actor_id=a1&from=2026-01-02T00:00:00Z&page=3&limit=1000")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if filter.Action != "user.disabled" || filter.ActorID != "a1" || filter.Page != 3 || filter.Limit != MaxPageLimit {
+		t.Errorf("filter = %+v", filter)
+	}
+	if filter.From == nil || !filter.From.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) || filter.To != nil {
+		t.Errorf("from/to = %v/%v", filter.From, filter.To)
+	}
+	if filter.Offset() != 2*MaxPageLimit {
+		t.Errorf("offset = %d", filter.Offset())
+	}
+
+	for _, query := range []string{"page=0", "limit=x", "to=yesterday"} {
+		if _, err := parse(query); err == nil {
+			t.Errorf("%s: want an error", query)
+		}
+	}
+}
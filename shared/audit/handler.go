@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/reqctx"
+)
+
+// Page sizes of GET /admin/audit-logs
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// Filter selects audit entries; empty fields match everything
+type Filter struct {
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+	Page       int        // 1-based
+	Limit      int
+}
+
+// Offset is the number of entries before Page
+func (f Filter) Offset() int {
+	return (f.Page - 1) * f.Limit
+}
+
+// ListResponse is one page of audit entries
+type ListResponse struct {
+	Entries []Entry `json:"entries"`
+	Count   int     `json:"count"`
+	Total   int     `json:"total"`
+	Page    int     `json:"page"`
+	Limit   int     `json:"limit"`
+}
+
+// Lister reads audit entries
+type Lister interface {
+	List(ctx context.Context, filter Filter) ([]Entry, int, error)
+}
+
+// ListHandler serves GET /admin/audit-logs?action=&actor_id=&target_type=&target_id=&from=&to=&page=&limit=,
+// newest entries first. from and to are RFC 3339 times.
+func ListHandler(lister Lister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := ParseFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid filter",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		ctx, cancel := reqctx.New(c.Request)
+		defer cancel()
+
+		entries, total, err := lister.List(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "failed to list audit logs",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, ListResponse{
+			Entries: entries,
+			Count:   len(entries),
+			Total:   total,
+			Page:    filter.Page,
+			Limit:   filter.Limit,
+		})
+	}
+}
+
+// ParseFilter reads the GET /admin/audit-logs query parameters
+func ParseFilter(c *gin.Context) (Filter, error) {
+	filter := Filter{
+		Action:     c.Query("action"),
+		ActorID:    c.Query("actor_id"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Page:       1,
+		Limit:      DefaultPageLimit,
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New(param.name + " must be an RFC 3339 time")
+		}
+		*param.dest = &t
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return filter, errors.New("page must be a positive integer")
+		}
+		filter.Page = page
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		if limit > MaxPageLimit {
+			limit = MaxPageLimit
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
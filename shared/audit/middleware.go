@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/identity"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+// Actor is who made a request
+type Actor struct {
+	ID   string
+	Role string
+	IP   string
+}
+
+type actorKey struct{}
+
+// actorSource resolves the actor when an entry is written, not when the request comes in
+type actorSource func() Actor
+
+// Middleware attaches the caller to the request context for Write.
+// The caller is the user_id and role a JWT middleware set on the gin context, looked up when
+// the entry is written so the JWT middleware may run after this one. Without them, and with
+// keys set, it's the caller of a valid bearer token: routes that don't require a JWT still
+// record who called them when the gateway forwarded one.
+func Middleware(keys *jwtkeys.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var resolved *Actor
+		source := actorSource(func() Actor {
+			if resolved == nil {
+				actor := Actor{
+					ID:   c.GetString("user_id"),
+					Role: c.GetString("role"),
+					IP:   c.ClientIP(),
+				}
+				if actor.ID == "" && keys != nil {
+					actor.ID, actor.Role = bearerCaller(c, keys)
+				}
+				resolved = &actor
+			}
+			return *resolved
+		})
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), actorKey{}, source))
+		c.Next()
+	}
+}
+
+// bearerCaller returns the user and role of the request's bearer token, empty if it has none
+// or it doesn't verify
+func bearerCaller(c *gin.Context, keys *jwtkeys.KeySet) (string, string) {
+	claims, err := identity.FromRequest(c.Request, keys)
+	if err != nil {
+		return "", ""
+	}
+	return claims.UserID, claims.Role
+}
+
+// WithActor returns ctx carrying actor, for writing entries outside an HTTP request
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorSource(func() Actor { return actor }))
+}
+
+// ActorFrom returns the actor attached to ctx, empty without one
+func ActorFrom(ctx context.Context) Actor {
+	if source, ok := ctx.Value(actorKey{}).(actorSource); ok {
+		return source()
+	}
+	return Actor{}
+}
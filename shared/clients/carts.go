@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// GuestTokenHeader carries a guest token to the cart service, see WithHeader
+const GuestTokenHeader = "X-Guest-Token"
+
+// Cart is a cart service cart; calls act on the caller's active cart, found by its token
+type Cart struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"` // the guest ID for guest carts
+	Guest      bool       `json:"guest,omitempty"`
+	Items      []CartItem `json:"items"`
+	Subtotal   float64    `json:"subtotal"`
+	CouponCode *string    `json:"coupon_code,omitempty"`
+	Discount   float64    `json:"discount"`
+	Total      float64    `json:"total"`
+	Status     string     `json:"status"` // active, checked_out, abandoned, merged
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CartItem is one line of a cart
+type CartItem struct {
+	ID        string  `json:"id"`
+	ProductID int64   `json:"product_id"`
+	VariantID *int64  `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"` // when it was added
+}
+
+// AddItemRequest is the body of POST /carts/items
+type AddItemRequest struct {
+	ProductID int64   `json:"product_id"`
+	VariantID *int64  `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price,omitempty"` // optional; the cart prices the line from the catalog
+}
+
+// AddItemResponse is the body of a successful POST /carts/items
+type AddItemResponse struct {
+	Cart     Cart      `json:"cart"`
+	Item     CartItem  `json:"item"`
+	NewTotal float64   `json:"new_total"`
+	SoftLock *SoftLock `json:"soft_lock,omitempty"` // with soft locks on
+}
+
+// SoftLock is stock held in the products service for a cart line
+type SoftLock struct {
+	ReservationID string    `json:"reservation_id"`
+	ProductID     int64     `json:"product_id"`
+	VariantID     *int64    `json:"variant_id,omitempty"`
+	Quantity      int       `json:"quantity"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	TTLSeconds    int       `json:"ttl_seconds"`
+}
+
+// CheckoutResponse is the body of a successful POST /carts/checkout
+type CheckoutResponse struct {
+	Message       string `json:"message"`
+	CorrelationID string `json:"correlation_id"` // the order saga's
+}
+
+// Carts calls the cart service
+type Carts struct {
+	client
+}
+
+// NewCarts creates a cart service client
+func NewCarts(config Config) *Carts {
+	return &Carts{client: newClient(config)}
+}
+
+// GetCart calls GET /carts
+func (c *Carts) GetCart(ctx context.Context) (*Cart, error) {
+	var resp struct {
+		Cart Cart `json:"cart"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/carts", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Cart, nil
+}
+
+// AddItem calls POST /carts/items; the cart is created on the first add
+func (c *Carts) AddItem(ctx context.Context, req AddItemRequest) (*AddItemResponse, error) {
+	var resp AddItemResponse
+	if err := c.do(ctx, http.MethodPost, "/carts/items", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RemoveItem calls DELETE /carts/items/:product_id and returns the updated cart; with a
+// variant ID only that variant is removed
+func (c *Carts) RemoveItem(ctx context.Context, productID int64, variantID *int64) (*Cart, error) {
+	query := url.Values{}
+	if variantID != nil {
+		query.Set("variant_id", strconv.FormatInt(*variantID, 10))
+	}
+
+	var resp struct {
+		Cart Cart `json:"cart"`
+	}
+	if err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/carts/items/%d", productID), query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Cart, nil
+}
+
+// Checkout calls POST /carts/checkout, which starts the order saga shipping to one of the
+// user's saved addresses; signed-in users only
+func (c *Carts) Checkout(ctx context.Context, addressID string) (*CheckoutResponse, error) {
+	var resp CheckoutResponse
+	body := map[string]string{"address_id": addressID}
+	if err := c.do(ctx, http.MethodPost, "/carts/checkout", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,170 @@
+// Package clients calls the users, products, cart and orders services over HTTP with typed
+// requests and responses.
+//
+// Why: every caller used to build URLs, decode into map[string]interface{} and pick its own
+// timeout. Services calling each other (pricing and routing lookups, notifications) use these
+// clients, so a service's API is written down once and every call is bounded by a timeout.
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each call when Config.Timeout is unset
+const DefaultTimeout = 5 * time.Second
+
+// Config configures a service client
+type Config struct {
+	BaseURL    string        // e.g. http://products-service:8081
+	Timeout    time.Duration // per call; DefaultTimeout when 0. An earlier deadline on the context still applies
+	HTTPClient *http.Client  // http.DefaultClient when nil
+}
+
+// Error is a non-2xx response. Code and Message come from the services' ErrorResponse body
+// ({"error": ..., "message": ...}) when it has one.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("service returned %d: %s", e.StatusCode, e.Code)
+	}
+	return fmt.Sprintf("service returned %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is a 404 from the service
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// StatusCode returns the status of a non-2xx response, or 0 when err isn't one
+func StatusCode(err error) int {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.StatusCode
+	}
+	return 0
+}
+
+type headersKey struct{}
+
+// WithHeader returns ctx carrying a header that calls made with it send, e.g. the caller's
+// Authorization header or the cart service's X-Guest-Token
+func WithHeader(ctx context.Context, name, value string) context.Context {
+	headers := http.Header{}
+	if existing, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		headers = existing.Clone()
+	}
+	headers.Set(name, value)
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// WithBearer returns ctx carrying "Authorization: Bearer token"
+func WithBearer(ctx context.Context, token string) context.Context {
+	return WithHeader(ctx, "Authorization", "Bearer "+token)
+}
+
+// client is what the service clients share
+type client struct {
+	baseURL    string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+func newClient(config Config) client {
+	c := client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		timeout:    config.Timeout,
+		httpClient: config.HTTPClient,
+	}
+	if c.timeout <= 0 {
+		c.timeout = DefaultTimeout
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	return c
+}
+
+// do sends body (if not nil) as JSON to path and decodes the response into out (if not nil)
+func (c client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s request: %w", method, path, err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build %s %s request: %w", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if headers, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for name, values := range headers {
+			req.Header[name] = values
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s response: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp.StatusCode, respBody)
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func newError(status int, body []byte) *Error {
+	serviceErr := &Error{StatusCode: status, Body: string(body)}
+
+	var errResp struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		serviceErr.Code = errResp.Error
+		serviceErr.Message = errResp.Message
+	}
+	return serviceErr
+}
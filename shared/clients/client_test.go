@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetProductDecodesTypedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/products/7" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"id": 7, "name": "Mug", "price": 12.5, "warehouse": "east",
+			"variants": [{"id": 3, "product_id": 7, "price": 14, "attributes": {"color": "red"}}]}`))
+	}))
+	defer server.Close()
+
+	product, err := NewProducts(Config{BaseURL: server.URL + "/"}).GetProduct(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if product.Name != "Mug" || product.Price != 12.5 || product.Warehouse != "east" {
+		t.Errorf("product = %+v", product)
+	}
+	if variant := product.Variant(3); variant == nil || variant.Price != 14 || variant.Attributes["color"] != "red" {
+		t.Errorf("variant 3 = %+v", variant)
+	}
+	if product.Variant(4) != nil {
+		t.Error("variant 4 should not exist")
+	}
+}
+
+func TestErrorsCarryStatusAndServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "product not found", "message": "no product 9", "code": 404}`))
+	}))
+	defer server.Close()
+
+	_, err := NewProducts(Config{BaseURL: server.URL}).GetProduct(context.Background(), 9)
+	if !IsNotFound(err) {
+		t.Fatalf("err = %v, want a 404", err)
+	}
+	var serviceErr *Error
+	if !errors.As(err, &serviceErr) || serviceErr.Code != "product not found" || serviceErr.Message != "no product 9" {
+		t.Errorf("err = %+v", serviceErr)
+	}
+	if StatusCode(errors.New("connection refused")) != 0 {
+		t.Error("a transport error has no status")
+	}
+}
+
+func TestCallsAreBoundedByTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := NewOrders(Config{BaseURL: server.URL, Timeout: 50 * time.Millisecond}).GetOrder(context.Background(), 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("call took %v", elapsed)
+	}
+}
+
+func TestHeadersFromContextAreSent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer user-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get(GuestTokenHeader); got != "guest-token" {
+			t.Errorf("%s = %q", GuestTokenHeader, got)
+		}
+
+		var req AddItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ProductID != 2 || req.Quantity != 3 {
+			t.Errorf("body = %+v (%v)", req, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"cart": {"id": "c1", "total": 30}, "item": {"product_id": 2, "quantity": 3, "price": 10}, "new_total": 30}`))
+	}))
+	defer server.Close()
+
+	ctx := WithHeader(WithBearer(context.Background(), "user-token"), GuestTokenHeader, "guest-token")
+	resp, err := NewCarts(Config{BaseURL: server.URL}).AddItem(ctx, AddItemRequest{ProductID: 2, Quantity: 3, Price: 10})
+	if err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if resp.Cart.ID != "c1" || resp.NewTotal != 30 || resp.SoftLock != nil {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestListOrdersQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("user_id") != "u1" || query.Get("status") != "placed" || query.Get("limit") != "5" || query.Has("page") {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"orders": [{"id": 4, "status": "placed", "items": [{"product_id": 1, "quantity": 2, "price": 5}]}], "count": 1, "total": 1}`))
+	}))
+	defer server.Close()
+
+	history, err := NewOrders(Config{BaseURL: server.URL}).ListOrders(context.Background(), "u1", ListOrdersOptions{Status: "placed", Limit: 5})
+	if err != nil {
+		t.Fatalf("ListOrders: %v", err)
+	}
+	if len(history.Orders) != 1 || history.Orders[0].ID != 4 || history.Orders[0].Items[0].Quantity != 2 {
+		t.Errorf("history = %+v", history)
+	}
+}
@@ -0,0 +1,137 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Order is an orders service order
+type Order struct {
+	ID                int64       `json:"id"`
+	UserID            string      `json:"user_id"`
+	CartID            string      `json:"cart_id"`
+	Items             []OrderItem `json:"items"`
+	Subtotal          *float64    `json:"subtotal,omitempty"` // before tax
+	TaxTotal          float64     `json:"tax_total"`
+	Total             float64     `json:"total"`
+	Status            string      `json:"status"` // pending, placed, payment_pending, confirmed, shipped, delivered, cancelled, failed
+	SagaCorrelationID string      `json:"saga_correlation_id"`
+	CheckoutID        *int64      `json:"checkout_id,omitempty"`
+	TrackingNumber    *string     `json:"tracking_number,omitempty"`
+	Carrier           *string     `json:"carrier,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	ShippedAt         *time.Time  `json:"shipped_at,omitempty"`
+	DeliveredAt       *time.Time  `json:"delivered_at,omitempty"`
+	CancelledAt       *time.Time  `json:"cancelled_at,omitempty"`
+}
+
+// OrderItem is one line of an order
+type OrderItem struct {
+	ProductID int64   `json:"product_id"`
+	VariantID *int64  `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"` // when it was bought
+}
+
+// ListOrdersOptions filters and pages ListOrders; zero values use the service's defaults
+type ListOrdersOptions struct {
+	Status string
+	Page   int
+	Limit  int
+}
+
+// OrderHistory is one page of a user's orders
+type OrderHistory struct {
+	Orders       []Order        `json:"orders"`
+	Count        int            `json:"count"`     // orders on this page
+	NextPage     *int           `json:"next_page"` // nil on the last page
+	Total        int            `json:"total"`     // orders matching the filter
+	Page         int            `json:"page"`
+	Limit        int            `json:"limit"`
+	StatusCounts map[string]int `json:"status_counts"`
+}
+
+// CancelOrderResponse is the body of a successful POST /orders/:id/cancel
+type CancelOrderResponse struct {
+	Message           string `json:"message"`
+	OrderID           int64  `json:"order_id"`
+	SagaCorrelationID string `json:"saga_correlation_id"`
+}
+
+// CheckoutResult is the outcome of a checkout, see Orders.GetCheckoutResult
+type CheckoutResult struct {
+	CorrelationID string  `json:"correlation_id"`
+	UserID        string  `json:"user_id"`
+	Outcome       string  `json:"outcome"` // pending, succeeded, failed
+	OrderID       *int64  `json:"order_id,omitempty"`
+	OrderIDs      []int64 `json:"order_ids,omitempty"`
+	CheckoutID    *int64  `json:"checkout_id,omitempty"`
+	Reason        *string `json:"reason,omitempty"`
+	SagaStatus    string  `json:"saga_status"`
+}
+
+// Orders calls the orders service
+type Orders struct {
+	client
+}
+
+// NewOrders creates an orders service client
+func NewOrders(config Config) *Orders {
+	return &Orders{client: newClient(config)}
+}
+
+// GetOrder calls GET /orders/:id
+func (o *Orders) GetOrder(ctx context.Context, id int64) (*Order, error) {
+	var order Order
+	if err := o.do(ctx, http.MethodGet, fmt.Sprintf("/orders/%d", id), nil, nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders calls GET /orders for one user's order history; ctx must carry that user's token or
+// an admin's (WithBearer)
+func (o *Orders) ListOrders(ctx context.Context, userID string, opts ListOrdersOptions) (*OrderHistory, error) {
+	query := url.Values{"user_id": {userID}}
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var history OrderHistory
+	if err := o.do(ctx, http.MethodGet, "/orders", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// CancelOrder calls POST /orders/:id/cancel
+func (o *Orders) CancelOrder(ctx context.Context, id int64, reason string) (*CancelOrderResponse, error) {
+	body := map[string]string{"reason": reason}
+
+	var resp CancelOrderResponse
+	if err := o.do(ctx, http.MethodPost, fmt.Sprintf("/orders/%d/cancel", id), nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetCheckoutResult calls GET /checkout/:correlation_id/result; a checkout the saga hasn't
+// picked up yet is a 404 like an unknown one
+func (o *Orders) GetCheckoutResult(ctx context.Context, correlationID string) (*CheckoutResult, error) {
+	var result CheckoutResult
+	if err := o.do(ctx, http.MethodGet, "/checkout/"+url.PathEscape(correlationID)+"/result", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
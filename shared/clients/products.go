@@ -0,0 +1,119 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Product is a products service catalog entry
+type Product struct {
+	ID              int64                  `json:"id"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	Price           float64                `json:"price"`
+	SKU             string                 `json:"sku"`
+	CategoryID      *int64                 `json:"category_id"`
+	StockQuantity   int                    `json:"stock_quantity"`
+	ImageURL        string                 `json:"image_url"`
+	Warehouse       string                 `json:"warehouse"`        // where the product ships from
+	FulfillmentType string                 `json:"fulfillment_type"` // standard, digital or dropship
+	Attributes      map[string]interface{} `json:"attributes"`
+	AverageRating   float64                `json:"average_rating"`
+	ReviewCount     int                    `json:"review_count"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	Variants        []Variant              `json:"variants,omitempty"` // only from GetProduct
+}
+
+// Variant is one variant of a product
+type Variant struct {
+	ID            int64             `json:"id"`
+	ProductID     int64             `json:"product_id"`
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	PriceOverride *float64          `json:"price_override"`
+	Price         float64           `json:"price"` // price_override or the product's price
+	StockQuantity int               `json:"stock_quantity"`
+}
+
+// Variant returns the product's variant with ID id, or nil
+func (p *Product) Variant(id int64) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// ListProductsOptions filters ListProducts
+type ListProductsOptions struct {
+	CategoryID           *int64
+	IncludeSubcategories bool       // with CategoryID, products of its subcategories too
+	Attributes           url.Values // attribute filters, e.g. color=red
+}
+
+// Inventory is a product's stock
+type Inventory struct {
+	ProductID  int64 `json:"product_id"`
+	TotalStock int   `json:"total_stock"`
+	Reserved   int   `json:"reserved"`
+	Available  int   `json:"available"`
+}
+
+// Products calls the products service
+type Products struct {
+	client
+}
+
+// NewProducts creates a products service client
+func NewProducts(config Config) *Products {
+	return &Products{client: newClient(config)}
+}
+
+// GetProduct calls GET /products/:id; a deleted product is a 404, see IsNotFound
+func (p *Products) GetProduct(ctx context.Context, id int64) (*Product, error) {
+	var product Product
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("/products/%d", id), nil, nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ListProducts calls GET /products
+func (p *Products) ListProducts(ctx context.Context, opts ListProductsOptions) ([]Product, error) {
+	query := url.Values{}
+	for name, values := range opts.Attributes {
+		query[name] = values
+	}
+	if opts.CategoryID != nil {
+		query.Set("category_id", strconv.FormatInt(*opts.CategoryID, 10))
+		if opts.IncludeSubcategories {
+			query.Set("include_subcategories", "true")
+		}
+	}
+
+	var resp struct {
+		Products []Product `json:"products"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/products", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Products == nil {
+		resp.Products = []Product{}
+	}
+	return resp.Products, nil
+}
+
+// GetInventory calls GET /inventory/:product_id
+func (p *Products) GetInventory(ctx context.Context, productID int64) (*Inventory, error) {
+	var inventory Inventory
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("/inventory/%d", productID), nil, nil, &inventory); err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
@@ -0,0 +1,117 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// User is a users service account
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RegisterRequest is the body of POST /register
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RegisterResponse is the body of a successful POST /register; no token is issued
+type RegisterResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+// LoginRequest is the body of POST /login
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse is the body of a successful POST /login
+type LoginResponse struct {
+	User         User   `json:"user"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+}
+
+// Address is a user's saved shipping address
+type Address struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	Label         string    `json:"label"`
+	RecipientName string    `json:"recipient_name"`
+	Line1         string    `json:"line1"`
+	Line2         string    `json:"line2"`
+	City          string    `json:"city"`
+	Region        string    `json:"region"`
+	PostalCode    string    `json:"postal_code"`
+	Country       string    `json:"country"`
+	Phone         string    `json:"phone"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Users calls the users service
+type Users struct {
+	client
+}
+
+// NewUsers creates a users service client
+func NewUsers(config Config) *Users {
+	return &Users{client: newClient(config)}
+}
+
+// Register calls POST /register
+func (u *Users) Register(ctx context.Context, req RegisterRequest) (*RegisterResponse, error) {
+	var resp RegisterResponse
+	if err := u.do(ctx, http.MethodPost, "/register", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Login calls POST /login
+func (u *Users) Login(ctx context.Context, req LoginRequest) (*LoginResponse, error) {
+	var resp LoginResponse
+	if err := u.do(ctx, http.MethodPost, "/login", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetProfile calls GET /profile/:id; it needs the user's (or an admin's) token, see WithBearer
+func (u *Users) GetProfile(ctx context.Context, userID string) (*User, error) {
+	var user User
+	if err := u.do(ctx, http.MethodGet, "/profile/"+url.PathEscape(userID), nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteAccount calls DELETE /profile/:id with the password confirmation
+func (u *Users) DeleteAccount(ctx context.Context, userID, password string) error {
+	body := map[string]string{"password": password}
+	return u.do(ctx, http.MethodDelete, "/profile/"+url.PathEscape(userID), nil, body, nil)
+}
+
+// GetAddress calls GET /profile/:id/addresses/:address_id; it needs the user's token, see WithBearer
+func (u *Users) GetAddress(ctx context.Context, userID, addressID string) (*Address, error) {
+	var address Address
+	path := "/profile/" + url.PathEscape(userID) + "/addresses/" + url.PathEscape(addressID)
+	if err := u.do(ctx, http.MethodGet, path, nil, nil, &address); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
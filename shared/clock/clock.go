@@ -0,0 +1,191 @@
+// Package clock abstracts time so expiry and timeout logic can be tested
+// deterministically. Production code uses clock.New(); tests use NewFake.
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for repositories and workers
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the subset of time.Ticker used by workers
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// ==================== Real clock ====================
+
+type realClock struct{}
+
+// New returns a Clock backed by the system time (UTC)
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                         { return time.Now().UTC() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return &realTicker{t: time.NewTicker(d)} }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (rt *realTicker) C() <-chan time.Time { return rt.t.C }
+func (rt *realTicker) Stop()               { rt.t.Stop() }
+
+// ==================== Worker loop ====================
+
+// Every calls fn every interval of clk until ctx is cancelled, and right away first when
+// immediate is set. The returned channel is closed once the loop has stopped.
+// The ticker is created before returning so fake clocks can be advanced right away.
+func Every(ctx context.Context, clk Clock, interval time.Duration, immediate bool, fn func(context.Context)) <-chan struct{} {
+	ticker := clk.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+
+		if immediate {
+			fn(ctx)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				fn(ctx)
+			}
+		}
+	}()
+
+	return done
+}
+
+// ==================== Fake clock ====================
+
+// Fake is a manually driven clock for tests.
+// Time only moves when Advance or Set is called; timers and tickers fire synchronously.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration // 0 for one-shot timers
+	ch       chan time.Time
+	stopped  bool
+}
+
+// NewFake creates a fake clock starting at the given time
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start.UTC()}
+}
+
+// Now returns the fake current time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel that receives once the fake time passes now+d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{deadline: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+	f.waiters = append(f.waiters, w)
+	return w.ch
+}
+
+// NewTicker returns a ticker that fires every d of fake time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{deadline: f.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	return &fakeTicker{clock: f, w: w}
+}
+
+// Advance moves the fake time forward and fires due timers/tickers
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the fake time to t and fires due timers/tickers
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = t.UTC()
+
+	sort.Slice(f.waiters, func(i, j int) bool {
+		return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+	})
+
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.stopped {
+			continue
+		}
+		if w.deadline.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+
+		// Non-blocking send: like time.Ticker, slow readers drop ticks
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.deadline.After(f.now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	f.waiters = remaining
+}
+
+type fakeTicker struct {
+	clock *Fake
+	w     *fakeWaiter
+}
+
+func (ft *fakeTicker) C() <-chan time.Time { return ft.w.ch }
+
+func (ft *fakeTicker) Stop() {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+	ft.w.stopped = true
+}
@@ -0,0 +1,96 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestFake_NowOnlyMovesOnAdvance(t *testing.T) {
+	fc := NewFake(start)
+
+	if !fc.Now().Equal(start) {
+		t.Fatalf("expected %v, got %v", start, fc.Now())
+	}
+
+	fc.Advance(5 * time.Minute)
+
+	if got := fc.Since(start); got != 5*time.Minute {
+		t.Fatalf("expected 5m elapsed, got %v", got)
+	}
+}
+
+func TestFake_AfterFiresAtDeadline(t *testing.T) {
+	fc := NewFake(start)
+	ch := fc.After(time.Minute)
+
+	fc.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("timer fired before deadline")
+	default:
+	}
+
+	fc.Advance(time.Second)
+	select {
+	case fired := <-ch:
+		if !fired.Equal(start.Add(time.Minute)) {
+			t.Fatalf("unexpected fire time %v", fired)
+		}
+	default:
+		t.Fatal("timer did not fire at deadline")
+	}
+}
+
+func TestFake_TickerRepeatsUntilStopped(t *testing.T) {
+	fc := NewFake(start)
+	ticker := fc.NewTicker(10 * time.Second)
+
+	for i := 0; i < 3; i++ {
+		fc.Advance(10 * time.Second)
+		select {
+		case <-ticker.C():
+		default:
+			t.Fatalf("tick %d missing", i+1)
+		}
+	}
+
+	ticker.Stop()
+	fc.Advance(10 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker still firing")
+	default:
+	}
+}
+
+func TestEvery_RunsOnEachTickUntilCancelled(t *testing.T) {
+	fc := NewFake(start)
+	calls := make(chan time.Time, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := Every(ctx, fc, time.Minute, true, func(context.Context) { calls <- fc.Now() })
+
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			fc.Advance(time.Minute)
+		}
+		select {
+		case at := <-calls:
+			if want := start.Add(time.Duration(i) * time.Minute); !at.Equal(want) {
+				t.Fatalf("run %d at %v, want %v", i+1, at, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("run %d missing", i+1)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("loop did not stop after cancel")
+	}
+}
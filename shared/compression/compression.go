@@ -0,0 +1,255 @@
+// Package compression compresses responses with brotli or gzip, whichever the client accepts,
+// and answers conditional GETs of cacheable routes with 304 Not Modified from an ETag.
+//
+// Why: catalog lists are the largest responses the services send, and JSON shrinks several
+// times over when compressed. Clients that already hold a page still downloaded it in full on
+// every refresh; with an ETag they revalidate it and get an empty 304 when nothing changed.
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings the middleware produces
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// brotliQuality trades some ratio for speed; responses are compressed on every request
+const brotliQuality = 4
+
+// Config is a service's response compression and ETag settings
+type Config struct {
+	Enabled  bool // compress responses the client accepts compressed
+	MinBytes int  // smaller responses are sent as they are; compressing them saves nothing
+	ETags    bool // tag cacheable GETs and answer If-None-Match (see ETag)
+}
+
+// DefaultConfig returns the settings shared by the services
+func DefaultConfig() Config {
+	return Config{
+		Enabled:  true,
+		MinBytes: 1024,
+		ETags:    true,
+	}
+}
+
+// LoadConfig applies the HTTP_COMPRESSION_ENABLED, HTTP_COMPRESSION_MIN_BYTES and
+// HTTP_ETAGS_ENABLED environment variables over defaults. Invalid values are logged and the
+// default kept.
+func LoadConfig(defaults Config) Config {
+	c := defaults
+	c.Enabled = envBool("HTTP_COMPRESSION_ENABLED", c.Enabled)
+	c.ETags = envBool("HTTP_ETAGS_ENABLED", c.ETags)
+	if val := os.Getenv("HTTP_COMPRESSION_MIN_BYTES"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed >= 0 {
+			c.MinBytes = parsed
+		} else {
+			log.Printf("⚠️  Invalid value for HTTP_COMPRESSION_MIN_BYTES, using default %d", c.MinBytes)
+		}
+	}
+	return c
+}
+
+// Middleware compresses the response when the request's Accept-Encoding allows it, preferring
+// brotli. Only text-like content of at least MinBytes is compressed: images are compressed
+// already and event streams must reach the client as they're written. Register it before the
+// routes so it runs for all of them.
+func (c Config) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !c.Enabled {
+			ctx.Next()
+			return
+		}
+
+		// The response depends on Accept-Encoding even when it isn't compressed this time
+		ctx.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := Negotiate(ctx.GetHeader("Accept-Encoding"))
+		if encoding == "" || ctx.Request.Method == http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: ctx.Writer, encoding: encoding, minBytes: c.MinBytes}
+		ctx.Writer = w
+		defer w.close()
+
+		ctx.Next()
+	}
+}
+
+// Negotiate picks the content coding for an Accept-Encoding header: brotli, then gzip, or ""
+// for none. A coding with q=0 is refused; "*" stands for any coding not listed.
+func Negotiate(acceptEncoding string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		q[name] = weight
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{EncodingBrotli, EncodingGzip} {
+		weight, listed := q[encoding]
+		if !listed {
+			weight, listed = q["*"]
+		}
+		if listed && weight > bestQ {
+			best, bestQ = encoding, weight
+		}
+	}
+	return best
+}
+
+// compressible reports whether a response of contentType is worth compressing
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// compressWriter holds back the first MinBytes of a response, then decides whether to compress
+// it; a response that ends sooner is sent as it is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minBytes int
+
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser // nil when the response is sent as it is
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.minBytes {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends what was written so far; a response flushed before MinBytes isn't compressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.enc.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide settles whether the response is compressed and writes what was held back
+func (w *compressWriter) decide(bigEnough bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+
+	if bigEnough && header.Get("Content-Encoding") == "" && bodyAllowed(w.ResponseWriter.Status()) && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// The compressed body is a different representation of the same content
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		if w.encoding == EncodingBrotli {
+			w.enc = brotli.NewWriterLevel(w.ResponseWriter, brotliQuality)
+		} else {
+			w.enc = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// close ends the response: what's still held back is sent as it is, and a compressed stream
+// is finished
+func (w *compressWriter) close() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			log.Printf("⚠️  Failed to write response: %v", err)
+			return
+		}
+	}
+	if w.enc != nil {
+		if err := w.enc.Close(); err != nil {
+			log.Printf("⚠️  Failed to finish %s response: %v", w.encoding, err)
+		}
+	}
+}
+
+// bodyAllowed reports whether a response with status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+func envBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			return parsed
+		}
+		log.Printf("⚠️  Invalid value for %s, using default %t", key, fallback)
+	}
+	return fallback
+}
@@ -0,0 +1,122 @@
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiate(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                        "",
+		"gzip, deflate, br":       EncodingBrotli,
+		"gzip":                    EncodingGzip,
+		"br;q=0.5, gzip":          EncodingGzip,
+		"br;q=0, *":               EncodingGzip,
+		"identity":                "",
+		"*;q=0":                   "",
+		"GZIP;q=0.8, deflate;q=1": EncodingGzip,
+	} {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func newRouter(c Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(c.Middleware())
+	router.GET("/products", c.ETag(), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"products": strings.Repeat("widget ", 500)})
+	})
+	router.GET("/small", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/logo", func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "image/png", make([]byte, 4096))
+	})
+	return router
+}
+
+func get(router http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareCompresses(t *testing.T) {
+	router := newRouter(DefaultConfig())
+
+	// Arrange/Act: the same catalog page, once per coding
+	plain := get(router, "/products", nil)
+	gz := get(router, "/products", map[string]string{"Accept-Encoding": "gzip"})
+	br := get(router, "/products", map[string]string{"Accept-Encoding": "gzip, br"})
+
+	// Assert
+	if plain.Header().Get("Content-Encoding") != "" || !strings.Contains(plain.Body.String(), "widget") {
+		t.Fatalf("expected an uncompressed body, got %q", plain.Header().Get("Content-Encoding"))
+	}
+	if gz.Header().Get("Content-Encoding") != EncodingGzip || gz.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("unexpected gzip headers %v", gz.Header())
+	}
+	reader, err := gzip.NewReader(gz.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body, _ := io.ReadAll(reader); string(body) != plain.Body.String() {
+		t.Fatal("gzip body doesn't decode to the response")
+	}
+	if br.Header().Get("Content-Encoding") != EncodingBrotli || br.Body.Len() >= plain.Body.Len() {
+		t.Fatalf("unexpected brotli response %v (%d bytes)", br.Header(), br.Body.Len())
+	}
+	if body, _ := io.ReadAll(brotli.NewReader(br.Body)); string(body) != plain.Body.String() {
+		t.Fatal("brotli body doesn't decode to the response")
+	}
+
+	// Small and already compressed responses are sent as they are
+	for _, path := range []string{"/small", "/logo"} {
+		if rec := get(router, path, map[string]string{"Accept-Encoding": "gzip"}); rec.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected no compression, got %q", path, rec.Header().Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestETag(t *testing.T) {
+	router := newRouter(DefaultConfig())
+
+	first := get(router, "/products", map[string]string{"Accept-Encoding": "gzip"})
+	etag := first.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected a weak ETag, got %q", etag)
+	}
+
+	// Same tag whatever the coding, and a revalidation gets an empty 304
+	if plain := get(router, "/products", nil); plain.Header().Get("ETag") != etag {
+		t.Fatalf("expected the same ETag uncompressed, got %q", plain.Header().Get("ETag"))
+	}
+	again := get(router, "/products", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": `"other", ` + etag})
+	if again.Code != http.StatusNotModified || again.Body.Len() != 0 || again.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("expected an empty 304, got %d with %d bytes", again.Code, again.Body.Len())
+	}
+
+	if stale := get(router, "/products", map[string]string{"If-None-Match": `W/"stale"`}); stale.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a stale tag, got %d", stale.Code)
+	}
+
+	c := DefaultConfig()
+	c.ETags = false
+	if rec := get(newRouter(c), "/products", nil); rec.Header().Get("ETag") != "" {
+		t.Fatal("expected no ETag when disabled")
+	}
+}
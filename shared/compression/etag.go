@@ -0,0 +1,85 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns middleware for cacheable GET routes: it tags a 200 response with a weak ETag
+// hashed from its body and answers 304 Not Modified, without the body, when the request's
+// If-None-Match already has it. The handler still runs; what's saved is the transfer. Put it on
+// the routes, after Middleware, so the tag is taken before compression.
+func (c Config) ETag() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !c.ETags || ctx.Request.Method != http.MethodGet {
+			ctx.Next()
+			return
+		}
+
+		original := ctx.Writer
+		w := &etagWriter{ResponseWriter: original}
+		ctx.Writer = w
+		ctx.Next()
+		ctx.Writer = original
+
+		// A handler that sent its headers itself can't be answered with a 304 any more
+		if !original.Written() && original.Status() == http.StatusOK && original.Header().Get("ETag") == "" {
+			etag := BodyETag(w.body.Bytes())
+			original.Header().Set("ETag", etag)
+			if Matches(ctx.GetHeader("If-None-Match"), etag) {
+				original.Header().Del("Content-Type")
+				original.Header().Del("Content-Length")
+				original.WriteHeader(http.StatusNotModified)
+				original.WriteHeaderNow()
+				return
+			}
+		}
+		if w.body.Len() > 0 {
+			_, _ = original.Write(w.body.Bytes())
+		}
+	}
+}
+
+// BodyETag returns the weak ETag of a response body
+func BodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Matches reports whether an If-None-Match header lists etag, comparing weakly as GET
+// revalidation does
+func Matches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter keeps the body until the handler is done, so the tag covers all of it
+type etagWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.ResponseWriter.Written() {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,305 @@
+// Package config loads a service's typed configuration from command-line flags, the
+// environment and a .env file.
+//
+// A configuration is a struct whose fields are described by tags:
+//
+//	env:"NAME"       the environment variable; the flag is the same name lower-cased with
+//	                 dashes (-service-name) unless flag:"..." names it
+//	default:"value"  used when neither a flag nor the environment sets the field
+//	required:"true"  the field must end up non-empty
+//	unit:"m"         for durations, a bare number is read in this unit (s, m or h), so
+//	                 *_SECONDS and *_MINUTES variables keep working; "90s" style values work too
+//	usage:"text"     the flag's help text
+//
+// Precedence, highest first: flags, the process environment, the .env file, the default.
+// Nested structs without an env tag are walked, so settings can be grouped. A pointer field
+// stays nil when nothing sets it, for settings whose default is decided elsewhere.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// EnvFileVariable names the .env file to read; it defaults to .env in the working directory
+const EnvFileVariable = "ENV_FILE"
+
+// Validator is implemented by configurations, and groups within them, with checks beyond
+// required fields
+type Validator interface {
+	Validate() error
+}
+
+// LoadOrDie loads cfg from os.Args, the environment and the .env file, and exits if it is invalid.
+// -h prints every setting with its variable and default.
+func LoadOrDie(cfg interface{}) {
+	err := Load(cfg, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+}
+
+// Load fills cfg, a pointer to a configuration struct, from args, the environment and the .env file.
+// Every invalid or missing setting is reported in the one error.
+func Load(cfg interface{}, args []string) error {
+	root := reflect.ValueOf(cfg)
+	if root.Kind() != reflect.Ptr || root.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: %T is not a pointer to a struct", cfg)
+	}
+
+	settings, err := collect(root.Elem(), nil)
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	for _, s := range settings {
+		fs.Var(s, s.flag, s.usageText())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Why: godotenv never overrides variables already set, so the process environment wins
+	// over the file. Other shared loaders (CORS_*, JWT_*, HTTP_COMPRESSION_*) read the environment
+	// too.
+	if err := loadEnvFile(); err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, s := range settings {
+		if err := s.resolve(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) == 0 {
+		problems = validate(root.Elem(), problems)
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validate runs the Validate of v and of every group under it, groups first
+func validate(v reflect.Value, problems []string) []string {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath == "" && sf.Tag.Get("env") == "" && sf.Type.Kind() == reflect.Struct {
+			problems = validate(v.Field(i), problems)
+		}
+	}
+	if validator, ok := v.Addr().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// loadEnvFile reads ENV_FILE or .env; a missing file is fine, the environment may hold everything
+func loadEnvFile() error {
+	path := os.Getenv(EnvFileVariable)
+	if path == "" {
+		path = ".env"
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := godotenv.Load(path); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is one tagged field; it is also the field's flag.Value
+type setting struct {
+	field    reflect.Value
+	env      string
+	flag     string
+	def      string
+	usage    string
+	unit     time.Duration
+	required bool
+
+	flagValue string
+	flagSet   bool
+}
+
+// collect walks v's fields, recursing into untagged structs
+func collect(v reflect.Value, settings []*setting) ([]*setting, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		env := sf.Tag.Get("env")
+		if env == "" {
+			if sf.Type.Kind() == reflect.Struct {
+				var err error
+				if settings, err = collect(v.Field(i), settings); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		s := &setting{
+			field:    v.Field(i),
+			env:      env,
+			flag:     sf.Tag.Get("flag"),
+			def:      sf.Tag.Get("default"),
+			usage:    sf.Tag.Get("usage"),
+			required: sf.Tag.Get("required") == "true",
+		}
+		if s.flag == "" {
+			s.flag = strings.ToLower(strings.ReplaceAll(env, "_", "-"))
+		}
+		if unit := sf.Tag.Get("unit"); unit != "" {
+			d, err := time.ParseDuration("1" + unit)
+			if err != nil {
+				return nil, fmt.Errorf("config: %s has an invalid unit %q", sf.Name, unit)
+			}
+			s.unit = d
+		}
+		if !supported(sf.Type) {
+			return nil, fmt.Errorf("config: %s has unsupported type %s", sf.Name, sf.Type)
+		}
+		settings = append(settings, s)
+	}
+	return settings, nil
+}
+
+func supported(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// resolve sets the field from the flag, the environment or the default, in that order
+func (s *setting) resolve() error {
+	raw, source := s.def, "default"
+	if s.flagSet {
+		raw, source = s.flagValue, "-"+s.flag
+	} else if val := os.Getenv(s.env); val != "" {
+		raw, source = val, s.env
+	}
+
+	if raw != "" {
+		if err := s.set(raw); err != nil {
+			return fmt.Errorf("%s: invalid value %q from %s: %v", s.env, raw, source, err)
+		}
+	}
+	if s.required && s.field.IsZero() {
+		return fmt.Errorf("%s is required", s.env)
+	}
+	return nil
+}
+
+func (s *setting) set(raw string) error {
+	field := s.field
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := parse(ptr.Elem(), raw, s.unit); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	return parse(field, raw, s.unit)
+}
+
+func parse(v reflect.Value, raw string, unit time.Duration) error {
+	switch {
+	case v.Type() == durationType:
+		if unit != 0 {
+			if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				v.SetInt(n * int64(unit))
+				return nil
+			}
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+func (s *setting) usageText() string {
+	usage := s.usage
+	if usage != "" {
+		usage += " "
+	}
+	usage += "(env " + s.env
+	if s.required {
+		usage += ", required"
+	}
+	return usage + ")"
+}
+
+// String shows the default in -h output
+func (s *setting) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.def
+}
+
+// Set records the flag's value; it is parsed with the other settings so errors are reported together
+func (s *setting) Set(val string) error {
+	s.flagValue, s.flagSet = val, true
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be passed as a bare -flag
+func (s *setting) IsBoolFlag() bool {
+	t := s.field.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
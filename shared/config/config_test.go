@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name    string         `env:"TEST_NAME" default:"orders"`
+	Port    string         `env:"TEST_PORT" flag:"port" default:"8082"`
+	URL     string         `env:"TEST_URL" required:"true"`
+	Debug   bool           `env:"TEST_DEBUG"`
+	Window  time.Duration  `env:"TEST_WINDOW_MINUTES" unit:"m" default:"15"`
+	Timeout *time.Duration `env:"TEST_TIMEOUT_SECONDS" unit:"s"`
+	Nested  struct {
+		Count int `env:"TEST_COUNT" default:"3"`
+	}
+}
+
+// withEnvFile points ENV_FILE at a file holding contents, or at a missing file if contents is empty
+func withEnvFile(t *testing.T, contents string) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if contents != "" {
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(EnvFileVariable, path)
+}
+
+func TestLoadDefaults(t *testing.T) {
+	withEnvFile(t, "")
+	t.Setenv("TEST_URL", "amqp://localhost")
+
+	var cfg testConfig
+	if err := Load(&cfg, nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "orders" || cfg.Port != "8082" || cfg.Window != 15*time.Minute || cfg.Nested.Count != 3 {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	if cfg.Timeout != nil {
+		t.Errorf("unset pointer setting = %s, want nil", *cfg.Timeout)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	// TEST_NAME and TEST_PORT are unset before the .env file is read
+	t.Setenv("TEST_NAME", "")
+	os.Unsetenv("TEST_NAME")
+	t.Setenv("TEST_PORT", "")
+	os.Unsetenv("TEST_PORT")
+	withEnvFile(t, "TEST_NAME=from-file\nTEST_PORT=7000\nTEST_URL=file-url\n")
+	t.Setenv("TEST_URL", "env-url")
+
+	var cfg testConfig
+	if err := Load(&cfg, []string{"-port", "9000", "-test-debug", "-test-timeout-seconds", "1m30s"}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "from-file" {
+		t.Errorf("name = %q, want the .env value", cfg.Name)
+	}
+	if cfg.URL != "env-url" {
+		t.Errorf("url = %q, want the environment to beat .env", cfg.URL)
+	}
+	if cfg.Port != "9000" {
+		t.Errorf("port = %q, want the flag to beat .env", cfg.Port)
+	}
+	if !cfg.Debug || cfg.Timeout == nil || *cfg.Timeout != 90*time.Second {
+		t.Errorf("debug = %v, timeout = %v", cfg.Debug, cfg.Timeout)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	withEnvFile(t, "")
+	t.Setenv("TEST_URL", "")
+	t.Setenv("TEST_WINDOW_MINUTES", "soon")
+
+	var cfg testConfig
+	err := Load(&cfg, []string{"-test-count", "many"})
+	if err == nil {
+		t.Fatal("Load succeeded, want an error")
+	}
+	for _, want := range []string{"TEST_URL is required", `TEST_WINDOW_MINUTES: invalid value "soon"`, `TEST_COUNT: invalid value "many" from -test-count`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+}
+
+func TestOrdersValidate(t *testing.T) {
+	withEnvFile(t, "")
+	t.Setenv("ORDER_AUTO_CONFIRM_INTERVAL_SECONDS", "0")
+
+	var cfg Orders
+	if err := Load(&cfg, nil); err == nil || !strings.Contains(err.Error(), "ORDER_AUTO_CONFIRM_INTERVAL_SECONDS") {
+		t.Errorf("Load = %v, want the zero interval rejected", err)
+	}
+
+	t.Setenv("ORDER_AUTO_CONFIRM_INTERVAL_SECONDS", "30")
+	t.Setenv("ORDER_PAYMENT_RETRY_MINUTES", "0")
+	cfg = Orders{}
+	if err := Load(&cfg, nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.FulfillmentSLA != 48*time.Hour || *cfg.AutoConfirmInterval != 30*time.Second || *cfg.PaymentRetryWindow != 0 || cfg.AutoConfirmWindow != nil {
+		t.Errorf("orders config = %+v", cfg)
+	}
+}
+
+func TestUsersValidate(t *testing.T) {
+	withEnvFile(t, "")
+	t.Setenv("DATABASE_URL", "postgres://localhost/prost")
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "scrypt")
+
+	var cfg Users
+	if err := Load(&cfg, nil); err == nil || !strings.Contains(err.Error(), "PASSWORD_HASH_ALGORITHM") {
+		t.Errorf("Load = %v, want the unknown algorithm rejected", err)
+	}
+
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	cfg = Users{}
+	if err := Load(&cfg, nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if p := cfg.PasswordHashing; p.BcryptCost != 10 || p.Argon2MemoryKiB != 65536 || p.Argon2Iterations != 3 || p.Argon2Parallelism != 2 {
+		t.Errorf("password hashing config = %+v", p)
+	}
+}
+
+func TestHTTPValidate(t *testing.T) {
+	withEnvFile(t, "")
+	t.Setenv("GIN_MODE", "verbose")
+	t.Setenv("HTTP_WRITE_TIMEOUT_SECONDS", "45")
+
+	// The group's Validate runs though Shipping has none of its own
+	var cfg Shipping
+	if err := Load(&cfg, nil); err == nil || !strings.Contains(err.Error(), "GIN_MODE") {
+		t.Errorf("Load = %v, want the unknown Gin mode rejected", err)
+	}
+
+	t.Setenv("GIN_MODE", "")
+	cfg = Shipping{}
+	if err := Load(&cfg, nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if h := cfg.HTTP; h.GinMode != "release" || *h.WriteTimeout != 45*time.Second || h.ReadTimeout != nil || h.MaxBodyBytes != nil {
+		t.Errorf("http config = %+v", h)
+	}
+}
@@ -0,0 +1,193 @@
+// Package cors answers browser cross-origin requests for the services from an allow-list of
+// origins read from the environment.
+//
+// Why: every service answered any origin with "*" plus Allow-Credentials, a combination browsers
+// refuse for credentialed requests and one that lets any site call the APIs. Origins are now
+// listed per deployment; "*" is still accepted for local development, without credentials.
+package cors
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config is a service's CORS policy
+type Config struct {
+	// AllowedOrigins holds exact origins ("https://shop.example.com"), "*" for any origin, or
+	// regular expressions starting with "^" that must match the whole origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool          // never sent for an origin matched only by "*"
+	MaxAge           time.Duration // how long browsers may cache a preflight; 0 omits the header
+}
+
+// DefaultConfig returns the policy shared by the services: any origin, the usual methods and
+// the headers the services read
+func DefaultConfig() Config {
+	return Config{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+// LoadConfig applies the CORS_* environment variables over defaults: CORS_ALLOWED_ORIGINS,
+// CORS_ALLOWED_METHODS and CORS_ALLOWED_HEADERS (comma-separated), CORS_ALLOW_CREDENTIALS and
+// CORS_MAX_AGE_SECONDS. Invalid values are logged and the default kept.
+func LoadConfig(defaults Config) Config {
+	c := defaults
+	if val := os.Getenv("CORS_ALLOWED_ORIGINS"); val != "" {
+		c.AllowedOrigins = splitList(val)
+	}
+	if val := os.Getenv("CORS_ALLOWED_METHODS"); val != "" {
+		c.AllowedMethods = splitList(strings.ToUpper(val))
+	}
+	if val := os.Getenv("CORS_ALLOWED_HEADERS"); val != "" {
+		c.AllowedHeaders = splitList(val)
+	}
+	if val := os.Getenv("CORS_ALLOW_CREDENTIALS"); val != "" {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			c.AllowCredentials = parsed
+		} else {
+			log.Printf("⚠️  Invalid value for CORS_ALLOW_CREDENTIALS, using default %v", c.AllowCredentials)
+		}
+	}
+	if val := os.Getenv("CORS_MAX_AGE_SECONDS"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed >= 0 {
+			c.MaxAge = time.Duration(parsed) * time.Second
+		} else {
+			log.Printf("⚠️  Invalid value for CORS_MAX_AGE_SECONDS, using default %s", c.MaxAge)
+		}
+	}
+	return c
+}
+
+// Policy is a Config ready to match origins
+type Policy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    []*regexp.Regexp
+	methods     string
+	headers     string
+	credentials bool
+	maxAge      string
+}
+
+// NewPolicy compiles a Config. An origin pattern that isn't a valid regular expression is
+// logged and left out, so it matches nothing.
+func NewPolicy(c Config) *Policy {
+	p := &Policy{
+		origins:     map[string]bool{},
+		methods:     strings.Join(c.AllowedMethods, ", "),
+		headers:     strings.Join(c.AllowedHeaders, ", "),
+		credentials: c.AllowCredentials,
+	}
+	if c.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(c.MaxAge / time.Second))
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.HasPrefix(origin, "^"):
+			pattern, err := regexp.Compile("^(?:" + strings.TrimSuffix(origin[1:], "$") + ")$")
+			if err != nil {
+				log.Printf("⚠️  Invalid CORS origin pattern %q, ignoring it: %v", origin, err)
+				continue
+			}
+			p.patterns = append(p.patterns, pattern)
+		default:
+			p.origins[strings.TrimSuffix(origin, "/")] = true
+		}
+	}
+	return p
+}
+
+// matchOrigin reports whether origin is allowed, and whether it is listed by name or pattern
+// rather than only covered by "*"
+func (p *Policy) matchOrigin(origin string) (allowed, listed bool) {
+	if p.origins[origin] {
+		return true, true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(origin) {
+			return true, true
+		}
+	}
+	return p.anyOrigin, false
+}
+
+// Allows reports whether requests from origin get CORS headers
+func (p *Policy) Allows(origin string) bool {
+	allowed, _ := p.matchOrigin(origin)
+	return allowed
+}
+
+// Middleware adds the CORS headers for allowed origins and answers preflight requests.
+// A preflight from an origin that isn't allowed gets 403; other requests from it are served
+// without CORS headers, so the browser hides the response from the calling page.
+func (p *Policy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions
+
+		if origin != "" {
+			header := c.Writer.Header()
+			allowed, listed := p.matchOrigin(origin)
+			if listed || !p.anyOrigin {
+				// The answer depends on the origin, so caches must not share it
+				header.Add("Vary", "Origin")
+			}
+
+			if !allowed {
+				if preflight && c.GetHeader("Access-Control-Request-Method") != "" {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+			} else if listed {
+				header.Set("Access-Control-Allow-Origin", origin)
+				if p.credentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+			} else {
+				header.Set("Access-Control-Allow-Origin", "*")
+			}
+
+			if allowed && preflight {
+				header.Set("Access-Control-Allow-Methods", p.methods)
+				header.Set("Access-Control-Allow-Headers", p.headers)
+				if p.maxAge != "" {
+					header.Set("Access-Control-Max-Age", p.maxAge)
+				}
+			}
+		}
+
+		if preflight {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,117 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(c Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewPolicy(c).Middleware())
+	router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func request(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/products", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", "GET")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPolicyAllows(t *testing.T) {
+	p := NewPolicy(Config{AllowedOrigins: []string{"https://shop.example.com/", `^https://[a-z0-9-]+\.preview\.example\.com$`, "^("}})
+
+	tests := map[string]bool{
+		"https://shop.example.com":                            true,
+		"https://pr-42.preview.example.com":                   true,
+		"https://evil.com/?https://pr-42.preview.example.com": false,
+		"https://pr-42.preview.example.com.evil.com":          false,
+		"http://shop.example.com":                             false,
+	}
+	for origin, want := range tests {
+		if got := p.Allows(origin); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestMiddlewareListedOrigin(t *testing.T) {
+	c := DefaultConfig()
+	c.AllowedOrigins = []string{"https://shop.example.com"}
+	router := newRouter(c)
+
+	w := request(router, http.MethodGet, "https://shop.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://shop.example.com" {
+		t.Errorf("Allow-Origin = %q, want the origin echoed", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q, want true", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+
+	w = request(router, http.MethodOptions, "https://shop.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") == "" || w.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight = %d %v, want 204 with methods and max age", w.Code, w.Header())
+	}
+}
+
+func TestMiddlewareRejectedOrigin(t *testing.T) {
+	c := DefaultConfig()
+	c.AllowedOrigins = []string{"https://shop.example.com"}
+	router := newRouter(c)
+
+	w := request(router, http.MethodGet, "https://evil.com")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("GET = %d %v, want served without CORS headers", w.Code, w.Header())
+	}
+
+	if w := request(router, http.MethodOptions, "https://evil.com"); w.Code != http.StatusForbidden {
+		t.Errorf("preflight = %d, want 403", w.Code)
+	}
+}
+
+func TestMiddlewareWildcardNeverSendsCredentials(t *testing.T) {
+	router := newRouter(DefaultConfig())
+
+	w := request(router, http.MethodGet, "https://anywhere.example.org")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q, want none with *", got)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, ^https://.*\\.example\\.com")
+	t.Setenv("CORS_ALLOWED_METHODS", "get,post")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "maybe")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "60")
+
+	c := LoadConfig(DefaultConfig())
+	if len(c.AllowedOrigins) != 2 || c.AllowedOrigins[1] != `^https://.*\.example\.com` {
+		t.Errorf("origins = %q", c.AllowedOrigins)
+	}
+	if len(c.AllowedMethods) != 2 || c.AllowedMethods[0] != "GET" {
+		t.Errorf("methods = %q", c.AllowedMethods)
+	}
+	if !c.AllowCredentials {
+		t.Error("invalid CORS_ALLOW_CREDENTIALS should keep the default")
+	}
+	if c.MaxAge.Seconds() != 60 {
+		t.Errorf("max age = %s, want 60s", c.MaxAge)
+	}
+}
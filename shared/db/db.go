@@ -5,6 +5,8 @@ import (
     "database/sql" // Standard SQL package
     "fmt"
     "log"
+    "os"
+    "strconv"
     "time"
 
     _ "github.com/lib/pq" // Postgres driver
@@ -19,6 +21,64 @@ type Config struct {
     DBName   string
     Schema   string
     SSLMode  string
+    Pool     PoolConfig
+}
+
+// PoolConfig sizes the connection pool; zero values use the defaults below
+type PoolConfig struct {
+    MaxOpenConns    int
+    MaxIdleConns    int
+    ConnMaxLifetime time.Duration
+    ConnMaxIdleTime time.Duration
+}
+
+// Pool defaults
+const (
+    DefaultMaxOpenConns    = 25
+    DefaultMaxIdleConns    = 5
+    DefaultConnMaxLifetime = 5 * time.Minute
+    DefaultConnMaxIdleTime = 10 * time.Minute
+)
+
+// PoolConfigFromEnv reads DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME_SECONDS
+// and DB_CONN_MAX_IDLE_TIME_SECONDS; unset or invalid values keep the defaults
+func PoolConfigFromEnv() PoolConfig {
+    return PoolConfig{
+        MaxOpenConns:    envInt("DB_MAX_OPEN_CONNS"),
+        MaxIdleConns:    envInt("DB_MAX_IDLE_CONNS"),
+        ConnMaxLifetime: time.Duration(envInt("DB_CONN_MAX_LIFETIME_SECONDS")) * time.Second,
+        ConnMaxIdleTime: time.Duration(envInt("DB_CONN_MAX_IDLE_TIME_SECONDS")) * time.Second,
+    }
+}
+
+func envInt(key string) int {
+    val := os.Getenv(key)
+    if val == "" {
+        return 0
+    }
+    parsed, err := strconv.Atoi(val)
+    if err != nil || parsed < 0 {
+        log.Printf("⚠️  Invalid value for %s, using default", key)
+        return 0
+    }
+    return parsed
+}
+
+// withDefaults fills zero values with the pool defaults
+func (pc PoolConfig) withDefaults() PoolConfig {
+    if pc.MaxOpenConns == 0 {
+        pc.MaxOpenConns = DefaultMaxOpenConns
+    }
+    if pc.MaxIdleConns == 0 {
+        pc.MaxIdleConns = DefaultMaxIdleConns
+    }
+    if pc.ConnMaxLifetime == 0 {
+        pc.ConnMaxLifetime = DefaultConnMaxLifetime
+    }
+    if pc.ConnMaxIdleTime == 0 {
+        pc.ConnMaxIdleTime = DefaultConnMaxIdleTime
+    }
+    return pc
 }
 
 // Connection holds the database connection pool
@@ -33,6 +93,9 @@ func NewDBConnection(cfg Config) (*Connection, error) {
 	if cfg.SSLMode == "" {
 		cfg.SSLMode = "disable"
 	}
+    if err := ValidateSchema(cfg.Schema); err != nil {
+        return nil, err
+    }
 	// Prevents connection failures when not set, PostgreSQL requires an SSL mode; empty value can cause connection refusal.
 
 	dataSourceName := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,)
@@ -44,10 +107,11 @@ func NewDBConnection(cfg Config) (*Connection, error) {
 	}
 
 	// Configure connection pool
-    dbConn.SetMaxOpenConns(25)
-    dbConn.SetMaxIdleConns(5)
-    dbConn.SetConnMaxLifetime(5 * time.Minute)
-    dbConn.SetConnMaxIdleTime(10 * time.Minute)
+    pool := cfg.Pool.withDefaults()
+    dbConn.SetMaxOpenConns(pool.MaxOpenConns)
+    dbConn.SetMaxIdleConns(pool.MaxIdleConns)
+    dbConn.SetConnMaxLifetime(pool.ConnMaxLifetime)
+    dbConn.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	// Test connection
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -57,7 +121,7 @@ func NewDBConnection(cfg Config) (*Connection, error) {
         return nil, fmt.Errorf("failed to ping database: %w", err)
     }
 
-    log.Printf("Connected to PostgreSQL database: %s (schema: %s)", cfg.DBName, cfg.Schema)
+    log.Printf("Connected to PostgreSQL database: %s (schema: %s, max open conns: %d)", cfg.DBName, cfg.Schema, pool.MaxOpenConns)
 
     return &Connection{
         DB:     dbConn,
@@ -72,45 +136,15 @@ func (c *Connection) DBConnClose() error {
     return c.DB.Close()
 }
 
-
-func contains(s, substr string) bool {
-    for i := 0; i <= len(s)-len(substr); i++ {
-        if s[i:i+len(substr)] == substr {
-            return true
-        }
-    }
-    return false
+// Stats returns connection pool statistics, e.g. for metrics.RegisterDBStats
+func (c *Connection) Stats() sql.DBStats {
+    return c.DB.Stats()
 }
 
-func replaceSchema(query, schema string) string {
-    // Simple replacement of $schema with actual schema name
-    for {
-        idx := 0
-        for i := 0; i < len(query)-len("$schema"); i++ {
-            if query[i:i+len("$schema")] == "$schema" {
-                idx = i
-                break
-            }
-        }
-        if idx == 0 && query[:7] != "$schema" {
-            break
-        }
-        query = query[:idx] + schema + query[idx+len("$schema"):]
-    }
-    return query
-}
-
-
 // PrepareStmt prepares a statement with schema substitution
-// Usage: db.PrepareStmt(ctx, "SELECT * FROM $1.users WHERE id = $2")
-// The $1 will be replaced with the schema name
+// Usage: db.PrepareStmt(ctx, "SELECT * FROM $schema.users WHERE id = $1")
 func (c *Connection) PrepareStmt(ctx context.Context, query string) (*sql.Stmt, error) {
-    // Replace schema placeholder if exists
-    if schemaPlaceholder := "$schema"; contains(query, schemaPlaceholder) {
-        query = replaceSchema(query, c.Schema)
-    }
-
-    stmt, err := c.DB.PrepareContext(ctx, query)
+    stmt, err := c.DB.PrepareContext(ctx, c.Qualify(query))
     if err != nil {
         return nil, fmt.Errorf("failed to prepare statement: %w", err)
     }
@@ -118,14 +152,16 @@ func (c *Connection) PrepareStmt(ctx context.Context, query string) (*sql.Stmt,
     return stmt, nil
 }
 
-// QueryRowContext executes a query that returns a single row
+// QueryRowContext executes a query that returns a single row.
+// SELECTs are retried once on transient errors (see retry.go).
 func (c *Connection) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-    return c.DB.QueryRowContext(ctx, query, args...)
+    return c.queryRowWithRetry(ctx, query, args...)
 }
 
-// QueryContext executes a query that returns multiple rows
+// QueryContext executes a query that returns multiple rows.
+// SELECTs are retried once on transient errors and then fail with a *TransientError.
 func (c *Connection) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-    return c.DB.QueryContext(ctx, query, args...)
+    return c.queryWithRetry(ctx, query, args...)
 }
 
 // ExecContext executes a query that doesn't return rows
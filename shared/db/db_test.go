@@ -0,0 +1,49 @@
+package db
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+    t.Setenv("DB_MAX_OPEN_CONNS", "50")
+    t.Setenv("DB_MAX_IDLE_CONNS", "not-a-number")
+    t.Setenv("DB_CONN_MAX_LIFETIME_SECONDS", "120")
+
+    pool := PoolConfigFromEnv().withDefaults()
+    want := PoolConfig{
+        MaxOpenConns:    50,
+        MaxIdleConns:    DefaultMaxIdleConns,
+        ConnMaxLifetime: 2 * time.Minute,
+        ConnMaxIdleTime: DefaultConnMaxIdleTime,
+    }
+    if pool != want {
+        t.Errorf("pool = %+v, want %+v", pool, want)
+    }
+}
+
+func TestValidateSchema(t *testing.T) {
+    if err := ValidateSchema("orders"); err != nil {
+        t.Errorf("ValidateSchema(orders) = %v, want nil", err)
+    }
+    for _, schema := range []string{"", "Orders", "orders; DROP TABLE users", "public"} {
+        if err := ValidateSchema(schema); !errors.Is(err, ErrSchemaNotAllowed) {
+            t.Errorf("ValidateSchema(%q) = %v, want ErrSchemaNotAllowed", schema, err)
+        }
+    }
+}
+
+func TestQualify(t *testing.T) {
+    conn := &Connection{Schema: "cart"}
+
+    tests := map[string]string{
+        "SELECT c.id FROM $schema.carts c JOIN $schema.cart_items i ON i.cart_id = c.id WHERE c.id = $1": "SELECT c.id FROM cart.carts c JOIN cart.cart_items i ON i.cart_id = c.id WHERE c.id = $1",
+        "SET search_path TO $schema": "SET search_path TO cart",
+    }
+    for query, want := range tests {
+        if got := conn.Qualify(query); got != want {
+            t.Errorf("Qualify(%q) = %q, want %q", query, got, want)
+        }
+    }
+}
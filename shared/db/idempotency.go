@@ -25,7 +25,7 @@ func (is *IdempotencyStore) RecordProcessed(ctx context.Context, eventID, servic
         ON CONFLICT (event_id, service_name) DO NOTHING
     `
 
-    query = replaceSchema(query, is.conn.Schema)
+    query = is.conn.Qualify(query)
 
     _, err := is.conn.ExecContext(ctx, query, eventID, serviceName, action, result, time.Now().UTC())
     if err != nil {
@@ -44,7 +44,7 @@ func (is *IdempotencyStore) IsProcessed(ctx context.Context, eventID, serviceNam
         )
     `
 
-    query = replaceSchema(query, is.conn.Schema)
+    query = is.conn.Qualify(query)
 
     var exists bool
     err := is.conn.QueryRowContext(ctx, query, eventID, serviceName).Scan(&exists)
@@ -63,7 +63,7 @@ func (is *IdempotencyStore) GetRecord(ctx context.Context, eventID, serviceName
         WHERE event_id = $1 AND service_name = $2
     `
 
-    query = replaceSchema(query, is.conn.Schema)
+    query = is.conn.Qualify(query)
 
     var record map[string]interface{}
     record = make(map[string]interface{})
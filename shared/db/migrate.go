@@ -0,0 +1,281 @@
+package db
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io/fs"
+    "log"
+
+    "github.com/golang-migrate/migrate/v4"
+    "github.com/golang-migrate/migrate/v4/database"
+    "github.com/golang-migrate/migrate/v4/database/postgres"
+    "github.com/golang-migrate/migrate/v4/source"
+    "github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Schema migrations
+// Why: each service owns its schema's migrations and embeds them, so a deploy carries the
+// schema its code expects. Every schema records its version in its own table in public, and
+// runs under a Postgres advisory lock on that table: replicas starting together queue, the
+// first applies what's pending and the others find nothing left to do.
+
+// legacyMigrationsTable recorded the single sequence infra/migrations ran before migrations
+// were split per schema. It is read, never written.
+const legacyMigrationsTable = "schema_migrations"
+
+// MigrationsTable is the table in public recording how far schema's migrations have run
+func MigrationsTable(schema string) string {
+    return legacyMigrationsTable + "_" + schema
+}
+
+// MigrateOptions picks which way and how far to migrate; the zero value applies every
+// pending migration
+type MigrateOptions struct {
+    Down  bool // roll back instead
+    Steps int  // 0 for all
+}
+
+// Migrate applies the connection schema's pending migrations from files (a service's
+// embedded migrations)
+func (c *Connection) Migrate(ctx context.Context, files fs.FS) error {
+    return Migrate(ctx, c.DB, c.Schema, files, MigrateOptions{})
+}
+
+// NoVersion is a schema's version before its first migration
+const NoVersion = database.NilVersion
+
+// MigrationStatus is a schema's version and which of its migrations have run
+type MigrationStatus struct {
+    Schema     string
+    Version    int // NoVersion before the first migration
+    Dirty      bool
+    Migrations []MigrationEntry
+}
+
+// MigrationEntry is one migration in files
+type MigrationEntry struct {
+    Version uint
+    Name    string
+    Applied bool
+}
+
+// Pending counts the migrations not yet applied
+func (s *MigrationStatus) Pending() int {
+    pending := 0
+    for _, migration := range s.Migrations {
+        if !migration.Applied {
+            pending++
+        }
+    }
+    return pending
+}
+
+// migrator is a schema's migrate instance on a connection holding the schema's lock
+type migrator struct {
+    *migrate.Migrate
+    conn   *sql.Conn
+    src    source.Driver
+    lockID string
+}
+
+// openMigrator locks schema and gives it the legacy version if it has none yet. close releases
+// both; every command goes through it, so a status read never races a migration.
+func openMigrator(ctx context.Context, pool *sql.DB, schema string, files fs.FS) (*migrator, error) {
+    table := MigrationsTable(schema)
+
+    // One connection for the lock and the migrations: advisory locks belong to a session, and
+    // the driver's own lock on the same key nests inside ours
+    conn, err := pool.Conn(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var dbName string
+    if err := conn.QueryRowContext(ctx, `SELECT CURRENT_DATABASE()`).Scan(&dbName); err != nil {
+        conn.Close()
+        return nil, err
+    }
+    lockID, err := database.GenerateAdvisoryLockId(dbName, "public", table)
+    if err != nil {
+        conn.Close()
+        return nil, err
+    }
+    mg := &migrator{conn: conn, lockID: lockID}
+    if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, mg.lockID); err != nil {
+        conn.Close()
+        return nil, fmt.Errorf("lock: %w", err)
+    }
+
+    if mg.src, err = iofs.New(files, "."); err != nil {
+        mg.close()
+        return nil, err
+    }
+    driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{SchemaName: "public", MigrationsTable: table})
+    if err != nil {
+        mg.close()
+        return nil, err
+    }
+    // Not Migrate.Close: that closes conn, and the unlock in close still needs it
+    if mg.Migrate, err = migrate.NewWithInstance("iofs", mg.src, "postgres", driver); err != nil {
+        mg.close()
+        return nil, err
+    }
+
+    if err := adoptLegacyVersion(ctx, conn, mg.Migrate, mg.src, schema); err != nil {
+        mg.close()
+        return nil, err
+    }
+    return mg, nil
+}
+
+func (mg *migrator) close() error {
+    if mg.src != nil {
+        mg.src.Close()
+    }
+    _, err := mg.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, mg.lockID)
+    mg.conn.Close()
+    if err != nil {
+        return fmt.Errorf("unlock: %w", err)
+    }
+    return nil
+}
+
+// withMigrator runs fn on schema's migrator and reports its error, or else close's
+func withMigrator(ctx context.Context, pool *sql.DB, schema string, files fs.FS, fn func(*migrator) error) error {
+    mg, err := openMigrator(ctx, pool, schema, files)
+    if err != nil {
+        return fmt.Errorf("migrate %s: %w", schema, err)
+    }
+    err = fn(mg)
+    if closeErr := mg.close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return fmt.Errorf("migrate %s: %w", schema, err)
+    }
+    return nil
+}
+
+// Migrate runs schema's migrations from files as opts says. A schema with no version yet on a
+// database the single sequence migrated starts at the last of its migrations that sequence
+// had applied, so nothing runs twice.
+func Migrate(ctx context.Context, pool *sql.DB, schema string, files fs.FS, opts MigrateOptions) error {
+    return withMigrator(ctx, pool, schema, files, func(mg *migrator) error {
+        var err error
+        switch {
+        case opts.Down && opts.Steps == 0:
+            err = mg.Down()
+        case opts.Down:
+            err = mg.Steps(-opts.Steps)
+        case opts.Steps == 0:
+            err = mg.Up()
+        default:
+            err = mg.Steps(opts.Steps)
+        }
+        if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+            return err
+        }
+
+        version, dirty, versionErr := mg.Version()
+        switch {
+        case errors.Is(versionErr, migrate.ErrNilVersion):
+            log.Printf("✓ Schema %s has no migrations applied", schema)
+        case versionErr != nil:
+            return versionErr
+        case errors.Is(err, migrate.ErrNoChange):
+            log.Printf("✓ Schema %s is up to date at version %d", schema, version)
+        default:
+            log.Printf("✓ Schema %s migrated to version %d (dirty: %t)", schema, version, dirty)
+        }
+        return nil
+    })
+}
+
+// GetMigrationStatus reports schema's version and lists its migrations in files
+func GetMigrationStatus(ctx context.Context, pool *sql.DB, schema string, files fs.FS) (*MigrationStatus, error) {
+    status := &MigrationStatus{Schema: schema, Version: NoVersion}
+    err := withMigrator(ctx, pool, schema, files, func(mg *migrator) error {
+        version, dirty, err := mg.Version()
+        if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+            return err
+        }
+        if err == nil {
+            status.Version, status.Dirty = int(version), dirty
+        }
+
+        for version, err := mg.src.First(); err == nil; version, err = mg.src.Next(version) {
+            r, name, err := mg.src.ReadUp(version)
+            if err != nil {
+                return err
+            }
+            r.Close()
+            status.Migrations = append(status.Migrations, MigrationEntry{
+                Version: version,
+                Name:    name,
+                Applied: status.Version != NoVersion && version <= uint(status.Version),
+            })
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return status, nil
+}
+
+// ForceMigrationVersion records schema at version without running anything and clears the
+// dirty flag. It's for after fixing a failed migration by hand; NoVersion forgets them all.
+func ForceMigrationVersion(ctx context.Context, pool *sql.DB, schema string, files fs.FS, version int) error {
+    return withMigrator(ctx, pool, schema, files, func(mg *migrator) error {
+        if version != NoVersion {
+            r, _, err := mg.src.ReadUp(uint(version))
+            if err != nil {
+                return fmt.Errorf("no migration %d: %w", version, err)
+            }
+            r.Close()
+        }
+        return mg.Force(version)
+    })
+}
+
+// adoptLegacyVersion gives a schema with no version of its own the version the single
+// sequence reached: its last migration at or below that one
+func adoptLegacyVersion(ctx context.Context, conn *sql.Conn, m *migrate.Migrate, src source.Driver, schema string) error {
+    if _, _, err := m.Version(); !errors.Is(err, migrate.ErrNilVersion) {
+        return err
+    }
+
+    var exists bool
+    if err := conn.QueryRowContext(ctx, `SELECT to_regclass('public.`+legacyMigrationsTable+`') IS NOT NULL`).Scan(&exists); err != nil {
+        return err
+    }
+    if !exists {
+        return nil
+    }
+
+    var legacy uint
+    var dirty bool
+    err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM public.`+legacyMigrationsTable+` LIMIT 1`).Scan(&legacy, &dirty)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if dirty {
+        return fmt.Errorf("%s is dirty at version %d; fix that migration before splitting", legacyMigrationsTable, legacy)
+    }
+
+    adopted, found := uint(0), false
+    for version, err := src.First(); err == nil && version <= legacy; version, err = src.Next(version) {
+        adopted, found = version, true
+    }
+    if !found {
+        return nil
+    }
+
+    log.Printf("Schema %s starts at version %d, from %s", schema, adopted, legacyMigrationsTable)
+    return m.Force(int(adopted))
+}
@@ -0,0 +1,108 @@
+package db_test
+
+import (
+    "context"
+    "os"
+    "testing"
+    "testing/fstest"
+
+    "github.com/sanketh-sg/prost/shared/db"
+    "github.com/sanketh-sg/prost/shared/fixtures"
+)
+
+func migrationVersion(t *testing.T, database *fixtures.Database, schema string) int {
+    t.Helper()
+    var version int
+    if err := database.DB.QueryRow(`SELECT version FROM public.` + db.MigrationsTable(schema)).Scan(&version); err != nil {
+        t.Fatalf("read %s version: %v", schema, err)
+    }
+    return version
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+    database := fixtures.Open(t)
+    files := fstest.MapFS{
+        "001_create_scratch.up.sql":   {Data: []byte(`CREATE SCHEMA scratch;`)},
+        "001_create_scratch.down.sql": {Data: []byte(`DROP SCHEMA scratch CASCADE;`)},
+        "005_add_notes.up.sql":        {Data: []byte(`CREATE TABLE scratch.notes (id SERIAL PRIMARY KEY);`)},
+        "005_add_notes.down.sql":      {Data: []byte(`DROP TABLE scratch.notes;`)},
+    }
+    ctx := context.Background()
+
+    // Twice: the second run finds nothing pending
+    for i := 0; i < 2; i++ {
+        if err := db.Migrate(ctx, database.DB, "scratch", files, db.MigrateOptions{}); err != nil {
+            t.Fatalf("Migrate up (run %d): %v", i+1, err)
+        }
+    }
+    if got := migrationVersion(t, database, "scratch"); got != 5 {
+        t.Errorf("version = %d, want 5", got)
+    }
+
+    if err := db.Migrate(ctx, database.DB, "scratch", files, db.MigrateOptions{Down: true, Steps: 1}); err != nil {
+        t.Fatalf("Migrate down one step: %v", err)
+    }
+    if got := migrationVersion(t, database, "scratch"); got != 1 {
+        t.Errorf("version after one step down = %d, want 1", got)
+    }
+    var notes bool
+    if err := database.DB.QueryRow(`SELECT to_regclass('scratch.notes') IS NOT NULL`).Scan(&notes); err != nil {
+        t.Fatal(err)
+    }
+    if notes {
+        t.Error("scratch.notes survived rolling back 005")
+    }
+}
+
+func TestMigrateAdoptsTheLegacyVersion(t *testing.T) {
+    // fixtures already applied every migration, as the single sequence did up to 040
+    database := fixtures.Open(t)
+    if _, err := database.DB.Exec(`CREATE TABLE public.schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL);
+        INSERT INTO public.schema_migrations VALUES (40, false)`); err != nil {
+        t.Fatal(err)
+    }
+
+    // Nothing reruns: the cart schema starts at its last migration at or below 040
+    if err := db.Migrate(context.Background(), database.DB, "cart", os.DirFS("../../services/cart/migrations"), db.MigrateOptions{}); err != nil {
+        t.Fatalf("Migrate: %v", err)
+    }
+    if got := migrationVersion(t, database, "cart"); got != 34 {
+        t.Errorf("cart version = %d, want 34", got)
+    }
+}
+
+func TestMigrationStatusAndForce(t *testing.T) {
+    database := fixtures.Open(t)
+    files := fstest.MapFS{
+        "001_create_scratch.up.sql":   {Data: []byte(`CREATE SCHEMA scratch;`)},
+        "001_create_scratch.down.sql": {Data: []byte(`DROP SCHEMA scratch CASCADE;`)},
+        "002_add_notes.up.sql":        {Data: []byte(`CREATE TABLE scratch.notes (id SERIAL PRIMARY KEY); SELECT broken;`)},
+        "002_add_notes.down.sql":      {Data: []byte(`DROP TABLE scratch.notes;`)},
+    }
+    ctx := context.Background()
+
+    if err := db.Migrate(ctx, database.DB, "scratch", files, db.MigrateOptions{}); err == nil {
+        t.Fatal("Migrate ran a broken migration without error")
+    }
+    status, err := db.GetMigrationStatus(ctx, database.DB, "scratch", files)
+    if err != nil {
+        t.Fatalf("GetMigrationStatus: %v", err)
+    }
+    if status.Version != 2 || !status.Dirty || len(status.Migrations) != 2 || status.Migrations[1].Name != "add_notes" {
+        t.Fatalf("status = %+v, want dirty at 2 of 2", status)
+    }
+
+    if err := db.ForceMigrationVersion(ctx, database.DB, "scratch", files, 1); err != nil {
+        t.Fatalf("ForceMigrationVersion: %v", err)
+    }
+    if status, err = db.GetMigrationStatus(ctx, database.DB, "scratch", files); err != nil {
+        t.Fatalf("GetMigrationStatus: %v", err)
+    }
+    if status.Version != 1 || status.Dirty || status.Pending() != 1 {
+        t.Errorf("status after force = %+v, want clean at 1 with 002 pending", status)
+    }
+
+    if err := db.ForceMigrationVersion(ctx, database.DB, "scratch", files, 7); err == nil {
+        t.Error("forced a version with no migration")
+    }
+}
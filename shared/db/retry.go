@@ -0,0 +1,139 @@
+package db
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "net/http"
+    "strings"
+    "syscall"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// Read retries
+// Why: a connection reset (Postgres restart, failover, idle connection killed by a proxy) or a
+// serialization failure fails one query even though the same query succeeds a moment later.
+// Reads are retried once with jitter; writes are never retried here since they may have applied.
+
+// readRetryDelay is the base delay before retrying a read; the actual delay is jittered ±50%
+const readRetryDelay = 50 * time.Millisecond
+
+// TransientError is a database error that is expected to clear on its own.
+// Handlers should answer 503 (retry later) instead of 500.
+type TransientError struct {
+    Err error
+}
+
+func (e *TransientError) Error() string {
+    return fmt.Sprintf("transient database error: %v", e.Err)
+}
+
+func (e *TransientError) Unwrap() error {
+    return e.Err
+}
+
+// IsTransient reports whether err is a TransientError or a driver error that is transient,
+// e.g. from Scan on a row whose query failed after its retry
+func IsTransient(err error) bool {
+    var te *TransientError
+    if errors.As(err, &te) {
+        return true
+    }
+    return isTransientDriverError(err)
+}
+
+// ErrorStatus is the HTTP status of a request that failed with err: 503 when err is transient,
+// a connection reset or serialization failure that outlived the retry, so the client can retry;
+// 500 otherwise. Handlers map their own not-found and conflict errors on top of it.
+func ErrorStatus(err error) int {
+    if IsTransient(err) {
+        return http.StatusServiceUnavailable
+    }
+    return http.StatusInternalServerError
+}
+
+// isTransientDriverError classifies driver and network errors
+func isTransientDriverError(err error) bool {
+    if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+        return false
+    }
+
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        switch pqErr.Code {
+        case "40001", // serialization_failure
+            "40P01", // deadlock_detected
+            "57P01", // admin_shutdown
+            "57P03": // cannot_connect_now
+            return true
+        }
+        // Class 08: connection exceptions
+        return pqErr.Code.Class() == "08"
+    }
+
+    return errors.Is(err, driver.ErrBadConn) ||
+        errors.Is(err, io.EOF) ||
+        errors.Is(err, io.ErrUnexpectedEOF) ||
+        errors.Is(err, syscall.ECONNRESET) ||
+        errors.Is(err, syscall.ECONNREFUSED) ||
+        errors.Is(err, syscall.EPIPE)
+}
+
+// isReadQuery reports whether query is a plain SELECT, which is safe to run twice
+func isReadQuery(query string) bool {
+    query = strings.TrimSpace(query)
+    return len(query) >= 6 && strings.EqualFold(query[:6], "SELECT")
+}
+
+// waitForRetry sleeps the jittered retry delay, or returns false if ctx is done first
+func waitForRetry(ctx context.Context) bool {
+    delay := readRetryDelay/2 + time.Duration(rand.Int63n(int64(readRetryDelay)))
+
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        return false
+    case <-timer.C:
+        return true
+    }
+}
+
+// queryWithRetry runs a read query, retrying once on a transient error
+func (c *Connection) queryWithRetry(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    rows, err := c.DB.QueryContext(ctx, query, args...)
+    if err == nil || !isReadQuery(query) || !isTransientDriverError(err) {
+        return rows, err
+    }
+
+    if !waitForRetry(ctx) {
+        return nil, &TransientError{Err: err}
+    }
+
+    rows, err = c.DB.QueryContext(ctx, query, args...)
+    if isTransientDriverError(err) {
+        return nil, &TransientError{Err: err}
+    }
+    return rows, err
+}
+
+// queryRowWithRetry runs a single-row read query, retrying once on a transient error.
+// The error of the final attempt is returned from Scan; use IsTransient to classify it.
+func (c *Connection) queryRowWithRetry(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    row := c.DB.QueryRowContext(ctx, query, args...)
+    if !isReadQuery(query) || !isTransientDriverError(row.Err()) {
+        return row
+    }
+
+    if !waitForRetry(ctx) {
+        return row
+    }
+    return c.DB.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "os"
+    "syscall"
+    "testing"
+
+    "github.com/lib/pq"
+)
+
+func TestIsTransient(t *testing.T) {
+    reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+    cases := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"serialization failure", &pq.Error{Code: "40001"}, true},
+        {"connection failure", &pq.Error{Code: "08006"}, true},
+        {"admin shutdown", fmt.Errorf("failed to get order: %w", &pq.Error{Code: "57P01"}), true},
+        {"connection reset", reset, true},
+        {"typed", &TransientError{Err: errors.New("boom")}, true},
+        {"unique violation", &pq.Error{Code: "23505"}, false},
+        {"other", errors.New("boom"), false},
+        {"nil", nil, false},
+    }
+
+    for _, tc := range cases {
+        if got := IsTransient(tc.err); got != tc.want {
+            t.Errorf("%s: IsTransient = %v, want %v", tc.name, got, tc.want)
+        }
+    }
+}
+
+func TestErrorStatus(t *testing.T) {
+    if got := ErrorStatus(fmt.Errorf("failed to list holds: %w", &pq.Error{Code: "40001"})); got != http.StatusServiceUnavailable {
+        t.Errorf("transient: ErrorStatus = %d, want 503", got)
+    }
+    if got := ErrorStatus(errors.New("boom")); got != http.StatusInternalServerError {
+        t.Errorf("other: ErrorStatus = %d, want 500", got)
+    }
+}
+
+func TestIsReadQuery(t *testing.T) {
+    if !isReadQuery("\n        SELECT id FROM orders.orders") {
+        t.Error("SELECT should be a read query")
+    }
+    for _, q := range []string{"INSERT INTO x VALUES (1) RETURNING id", "UPDATE x SET a = 1", "WITH d AS (DELETE FROM x RETURNING *) SELECT * FROM d"} {
+        if isReadQuery(q) {
+            t.Errorf("%q should not be a read query", q)
+        }
+    }
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// Schema qualification
+// Why: queries are written against `$schema.table` and the service's schema name is spliced in
+// as text, since identifiers can't be bind parameters. That is only safe if the name is one of
+// ours, so NewDBConnection refuses any schema outside the allowlist and Qualify never sees
+// anything else.
+
+// SchemaPlaceholder is replaced with the connection's schema by Qualify
+const SchemaPlaceholder = "$schema"
+
+// allowedSchemas are the schemas created by each service's migrations
+var allowedSchemas = map[string]bool{
+    "catalog":  true,
+    "users":    true,
+    "cart":     true,
+    "orders":   true,
+    "shipping": true,
+}
+
+// ErrSchemaNotAllowed is returned for a schema name outside the allowlist
+var ErrSchemaNotAllowed = errors.New("schema not allowed")
+
+// ValidateSchema checks that schema is one of the service schemas
+func ValidateSchema(schema string) error {
+    if !allowedSchemas[schema] {
+        return fmt.Errorf("%w: %q", ErrSchemaNotAllowed, schema)
+    }
+    return nil
+}
+
+// Qualify replaces every $schema placeholder in query with the connection's schema
+// Usage: conn.Qualify("SELECT * FROM $schema.users WHERE id = $1")
+func (c *Connection) Qualify(query string) string {
+    return strings.ReplaceAll(query, SchemaPlaceholder, c.Schema)
+}
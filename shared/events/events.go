@@ -2,7 +2,6 @@ package events
 
 import (
 	"encoding/json"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -54,15 +53,60 @@ type ProductUpdatedEvent struct {
 	ImageURL    string  `json:"image_url"`
 }
 
-// StockReservedEvent fired when inventory is reserved for an order
+// ProductDeletedEvent fired when a product is removed from the catalog
+type ProductDeletedEvent struct {
+	BaseEvent
+}
+
+// ProductRestoredEvent fired when an admin brings a deleted product back into the catalog
+type ProductRestoredEvent struct {
+	BaseEvent
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+// CategoryMergedEvent fired when an admin merges a category into another; the source is deleted
+type CategoryMergedEvent struct {
+	BaseEvent
+	SourceCategoryID int64   `json:"source_category_id"`
+	TargetCategoryID int64   `json:"target_category_id"`
+	ProductIDs       []int64 `json:"product_ids"`     // products now in the target
+	SubcategoryIDs   []int64 `json:"subcategory_ids"` // subcategories now under the target
+}
+
+// CategoryProductsAssignedEvent fired when an admin moves a list of products into a category
+type CategoryProductsAssignedEvent struct {
+	BaseEvent
+	CategoryID int64   `json:"category_id"`
+	ProductIDs []int64 `json:"product_ids"`
+}
+
+// StockReservedEvent fired once all items of an order are reserved
 type StockReservedEvent struct {
 	BaseEvent
+	OrderID int64           `json:"order_id"`
+	Items   []ReservedStock `json:"items"`
+}
+
+// ReservedStock is one reserved order line
+type ReservedStock struct {
 	ProductID     int64  `json:"product_id"`
+	VariantID     *int64 `json:"variant_id,omitempty"`
 	Quantity      int    `json:"quantity"`
-	OrderID       int64  `json:"order_id"`
 	ReservationID string `json:"reservation_id"` // Link for compensation
 }
 
+// StockReservationFailedEvent fired when an order can't be reserved; nothing is held for it
+type StockReservationFailedEvent struct {
+	BaseEvent
+	OrderID   int64  `json:"order_id"`
+	ProductID int64  `json:"product_id,omitempty"` // the short product, when stock was the problem
+	VariantID *int64 `json:"variant_id,omitempty"` // the short variant of that product, if any
+	Requested int    `json:"requested,omitempty"`
+	Available int    `json:"available,omitempty"`
+	Reason    string `json:"reason"`
+}
+
 // StockReleasedEvent fired when reserved inventory is released (compensation)
 type StockReleasedEvent struct {
 	BaseEvent
@@ -72,6 +116,102 @@ type StockReleasedEvent struct {
 	Reason        string `json:"reason"`         // order_cancelled, order_failed, etc.
 }
 
+// StockReplenishedEvent fired when stock is received against a purchase order or restocked from a return
+// Why: consumers allocate backordered demand as soon as stock arrives
+type StockReplenishedEvent struct {
+	BaseEvent
+	ProductID       int64 `json:"product_id"`
+	Quantity        int   `json:"quantity"`       // units received
+	StockQuantity   int   `json:"stock_quantity"` // stock after the receipt
+	PurchaseOrderID int64 `json:"purchase_order_id"`
+	ReturnID        int64 `json:"return_id,omitempty"` // set when the stock came back from a customer return
+}
+
+// StockAdjustedEvent fired when an admin adjusts a product's (or variant's) stock by hand
+// Why: the change isn't an order, a receipt or a return, so no other event reports it; stock
+// consumers and reporting see it with its reason
+type StockAdjustedEvent struct {
+	BaseEvent
+	ProductID     int64  `json:"product_id"`
+	VariantID     *int64 `json:"variant_id,omitempty"`
+	Delta         int    `json:"delta"`          // negative takes stock out
+	StockQuantity int    `json:"stock_quantity"` // stock after the adjustment
+	Reason        string `json:"reason"`         // damage, recount, return
+	MovementID    int64  `json:"movement_id"`    // the inventory movement recording it
+	AdjustedBy    string `json:"adjusted_by,omitempty"`
+}
+
+// BackInStockEvent fired when an out-of-stock product with waiting subscribers has stock again
+// Why: the notifications service fans it out; each subscriber is listed in exactly one event
+type BackInStockEvent struct {
+	BaseEvent
+	ProductID     int64    `json:"product_id"`
+	ProductName   string   `json:"product_name"`
+	StockQuantity int      `json:"stock_quantity"`
+	UserIDs       []string `json:"user_ids"` // subscribers to notify, at most the per-product cap
+}
+
+// ReorderSuggestedEvent fired when a product's stock, with what's on order, falls to its reorder point
+// Why: purchasing raises the supplier order from it; it's published once per dip, not on every
+// forecast run, and again only after the product recovered
+type ReorderSuggestedEvent struct {
+	BaseEvent
+	ProductID         int64   `json:"product_id"`
+	ProductName       string  `json:"product_name"`
+	DailyVelocity     float64 `json:"daily_velocity"` // units sold per day over the forecast window
+	Available         int     `json:"available"`      // stock minus active reservations
+	OnOrder           int     `json:"on_order"`       // units on open purchase orders
+	DaysOfStock       float64 `json:"days_of_stock"`  // available / daily_velocity
+	ReorderPoint      int     `json:"reorder_point"`
+	SuggestedQuantity int     `json:"suggested_quantity"`
+	LeadTimeDays      int     `json:"lead_time_days"`
+	SafetyStockDays   int     `json:"safety_stock_days"`
+}
+
+// LowStockEvent fired when a product's available stock falls below its low-stock threshold
+// Why: the notifications service alerts staff from it; like ReorderSuggested it's published
+// once per dip, and again only after available stock was back at the threshold
+type LowStockEvent struct {
+	BaseEvent
+	ProductID     int64  `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	SKU           string `json:"sku"`
+	StockQuantity int    `json:"stock_quantity"`
+	Reserved      int    `json:"reserved"`
+	Available     int    `json:"available"` // stock_quantity - reserved
+	Threshold     int    `json:"threshold"`
+}
+
+// ReturnDisposition is the return a disposition event is about
+type ReturnDisposition struct {
+	ReturnID  int64  `json:"return_id"`
+	OrderID   int64  `json:"order_id,omitempty"`
+	ProductID int64  `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Condition string `json:"condition"` // from the inspection
+	Note      string `json:"note,omitempty"`
+}
+
+// ReturnRestockedEvent fired when returned units go back into sellable stock
+type ReturnRestockedEvent struct {
+	BaseEvent
+	ReturnDisposition
+	StockQuantity int `json:"stock_quantity"` // stock after the restock
+}
+
+// ReturnWrittenOffEvent fired when returned units are written off as damaged; stock is unchanged
+type ReturnWrittenOffEvent struct {
+	BaseEvent
+	ReturnDisposition
+}
+
+// ReturnRefurbishingEvent fired when returned units are sent for refurbishment; stock is
+// unchanged until the return is restocked
+type ReturnRefurbishingEvent struct {
+	BaseEvent
+	ReturnDisposition
+}
+
 // ==================== Cart Events ====================
 
 // ItemAddedToCartEvent fired when item is added to cart
@@ -102,20 +242,29 @@ type CartClearedEvent struct {
 // CartCheckoutInitiatedEvent fired when checkout process begins (saga start)
 type CartCheckoutInitiatedEvent struct {
 	BaseEvent
-	CartID string             `json:"cart_id"`
-	UserID string             `json:"user_id"`
-	Total  float64            `json:"total"`
-	Items  []models.OrderItem `json:"items"`
+	CartID     string             `json:"cart_id"`
+	UserID     string             `json:"user_id"`
+	Subtotal   float64            `json:"subtotal,omitempty"`    // items before the coupon
+	Discount   float64            `json:"discount,omitempty"`    // taken off by the coupon
+	CouponCode string             `json:"coupon_code,omitempty"` // empty without a coupon
+	Total      float64            `json:"total"`                 // what the customer pays
+	Items      []models.OrderItem `json:"items"`
+
+	ShippingAddress *models.Address `json:"shipping_address,omitempty"` // nil from carts older than addresses
 }
 
 // ==================== Order Events ====================
 // OrderCreatedEvent fired when order is created in pending state (before inventory confirmation)
 type OrderCreatedEvent struct {
 	BaseEvent
-	OrderID int64              `json:"order_id"`
-	UserID  string             `json:"user_id"`
-	Total   float64            `json:"total"`
-	Items   []models.OrderItem `json:"items"`
+	OrderID  int64              `json:"order_id"`
+	UserID   string             `json:"user_id"`
+	Subtotal float64            `json:"subtotal,omitempty"`  // before tax
+	TaxTotal float64            `json:"tax_total,omitempty"` // sum of TaxLines
+	TaxLines []models.TaxLine   `json:"tax_lines,omitempty"`
+	Total    float64            `json:"total"` // subtotal plus tax
+	Items    []models.OrderItem `json:"items"`
+	CartID   string             `json:"cart_id,omitempty"` // products releases the cart's soft locks when reserving
 }
 
 // OrderPlacedEvent fired when an order is created (saga step 1)
@@ -152,9 +301,66 @@ type OrderShippedEvent struct {
 	BaseEvent
 	OrderID        int64     `json:"order_id"`
 	TrackingNumber string    `json:"tracking_number"`
+	Carrier        string    `json:"carrier,omitempty"`
 	ShippedAt      time.Time `json:"shipped_at"`
 }
 
+// OrderDeliveredEvent fired when the carrier confirms delivery
+type OrderDeliveredEvent struct {
+	BaseEvent
+	OrderID        int64     `json:"order_id"`
+	TrackingNumber string    `json:"tracking_number"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+}
+
+// OrderPaymentPendingEvent fired when a payment fails and the order waits for a retry;
+// reservations are held until HoldUntil, after which the order fails
+type OrderPaymentPendingEvent struct {
+	BaseEvent
+	OrderID   int64     `json:"order_id"`
+	Reason    string    `json:"reason"`
+	HoldUntil time.Time `json:"hold_until"`
+}
+
+// PaymentRetryRequestedEvent fired when the customer retries the payment of a payment_pending order
+type PaymentRetryRequestedEvent struct {
+	BaseEvent
+	OrderID int64   `json:"order_id"`
+	UserID  string  `json:"user_id"`
+	Amount  float64 `json:"amount"`
+	Attempt int     `json:"attempt"`
+}
+
+// ==================== Payment Events ====================
+
+// PaymentProcessedEvent fired by the payment service when an order's payment succeeds
+type PaymentProcessedEvent struct {
+	BaseEvent
+	OrderID int64 `json:"order_id"`
+	Attempt int   `json:"attempt"`
+}
+
+// PaymentFailedEvent fired by the payment service when an order's payment is declined
+type PaymentFailedEvent struct {
+	BaseEvent
+	OrderID int64  `json:"order_id"`
+	Reason  string `json:"reason"`
+	Attempt int    `json:"attempt"`
+}
+
+// ==================== Announcement Events ====================
+
+// AnnouncementPublishedEvent fired when an admin publishes a storefront announcement
+type AnnouncementPublishedEvent struct {
+	BaseEvent
+	AnnouncementID int64      `json:"announcement_id"`
+	Kind           string     `json:"kind"` // maintenance, promotion
+	Title          string     `json:"title"`
+	Message        string     `json:"message"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+}
+
 // ==================== User Events ====================
 
 // UserRegisteredEvent fired when user creates account
@@ -173,6 +379,15 @@ type UserProfileUpdatedEvent struct {
 	Username string `json:"username"`
 }
 
+// UserDeactivatedEvent fired when an account is deleted, so the other services stop holding
+// stock and serving data for it
+type UserDeactivatedEvent struct {
+	BaseEvent
+	UserID        string    `json:"user_id"`
+	Reason        string    `json:"reason"` // account_deleted
+	DeactivatedAt time.Time `json:"deactivated_at"`
+}
+
 // ==================== Utility Functions ====================
 
 // MarshalEvent converts any event to JSON bytes
@@ -180,70 +395,6 @@ func MarshalEvent(event interface{}) ([]byte, error) {
 	return json.Marshal(event)
 }
 
-// UnmarshalEvent converts JSON bytes to an event interface
-func UnmarshalEvent(data []byte, eventType string) (interface{}, error) {
-	switch eventType {
-	case "ProductCreated":
-		var event ProductCreatedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "ProductUpdated":
-		var event ProductUpdatedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "StockReserved":
-		var event StockReservedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "StockReleased":
-		var event StockReleasedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "ItemAddedToCart":
-		var event ItemAddedToCartEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "CartCleared":
-		var event CartClearedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "CartCheckoutInitiated":
-		var event CartCheckoutInitiatedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "OrderPlaced":
-		var event OrderPlacedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "OrderConfirmed":
-		var event OrderConfirmedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "OrderFailed":
-		var event OrderFailedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "OrderCancelled":
-		var event OrderCancelledEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "OrderShipped":
-		var event OrderShippedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "UserRegistered":
-		var event UserRegisteredEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	case "UserProfileUpdated":
-		var event UserProfileUpdatedEvent
-		err := json.Unmarshal(data, &event)
-		return event, err
-	default:
-		return nil, errors.New("unknown event type: " + eventType)
-	}
-}
-
 //===================== Helper Functions for Idempotency Check ==============
 
 // GetEventID returns the event ID from base event
@@ -259,14 +410,66 @@ func (e ProductUpdatedEvent) GetEventID() string {
 	return e.EventID
 }
 
+func (e ProductDeletedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e ProductRestoredEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e CategoryMergedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e CategoryProductsAssignedEvent) GetEventID() string {
+	return e.EventID
+}
+
 func (e StockReservedEvent) GetEventID() string {
 	return e.EventID
 }
 
+func (e StockReservationFailedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e ReturnRestockedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e ReturnWrittenOffEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e ReturnRefurbishingEvent) GetEventID() string {
+	return e.EventID
+}
+
 func (e StockReleasedEvent) GetEventID() string {
 	return e.EventID
 }
 
+func (e StockReplenishedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e StockAdjustedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e BackInStockEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e ReorderSuggestedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e LowStockEvent) GetEventID() string {
+	return e.EventID
+}
+
 func (e ItemAddedToCartEvent) GetEventID() string {
 	return e.EventID
 }
@@ -307,6 +510,30 @@ func (e OrderShippedEvent) GetEventID() string {
 	return e.EventID
 }
 
+func (e OrderDeliveredEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e OrderPaymentPendingEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e PaymentRetryRequestedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e PaymentProcessedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e PaymentFailedEvent) GetEventID() string {
+	return e.EventID
+}
+
+func (e AnnouncementPublishedEvent) GetEventID() string {
+	return e.EventID
+}
+
 func (e UserRegisteredEvent) GetEventID() string {
 	return e.EventID
 }
@@ -314,3 +541,7 @@ func (e UserRegisteredEvent) GetEventID() string {
 func (e UserProfileUpdatedEvent) GetEventID() string {
 	return e.EventID
 }
+
+func (e UserDeactivatedEvent) GetEventID() string {
+	return e.EventID
+}
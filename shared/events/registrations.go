@@ -0,0 +1,52 @@
+package events
+
+// Every event and the routing key it's published with. Payment events come from a service
+// outside this repo; their keys match the bindings in messaging.GetProstTopology.
+func init() {
+	// Products (products.events)
+	RegisterEvent[ProductCreatedEvent]("ProductCreated", "product.created")
+	RegisterEvent[ProductUpdatedEvent]("ProductUpdated", "product.updated")
+	RegisterEvent[ProductDeletedEvent]("ProductDeleted", "product.deleted")
+	RegisterEvent[ProductRestoredEvent]("ProductRestored", "product.restored")
+	RegisterEvent[CategoryMergedEvent]("CategoryMerged", "category.merged")
+	RegisterEvent[CategoryProductsAssignedEvent]("CategoryProductsAssigned", "category.products_assigned")
+	RegisterEvent[StockReservedEvent]("StockReserved", "product.stock.reserved")
+	RegisterEvent[StockReservationFailedEvent]("StockReservationFailed", "product.stock.reservation_failed")
+	RegisterEvent[StockReleasedEvent]("StockReleased", "product.stock.released")
+	RegisterEvent[StockReplenishedEvent]("StockReplenished", "product.stock.replenished")
+	RegisterEvent[StockAdjustedEvent]("StockAdjusted", "product.stock.adjusted")
+	RegisterEvent[BackInStockEvent]("BackInStock", "product.stock.back_in_stock")
+	RegisterEvent[ReorderSuggestedEvent]("ReorderSuggested", "product.inventory.reorder_suggested")
+	RegisterEvent[LowStockEvent]("LowStock", "product.inventory.low_stock")
+	RegisterEvent[ReturnRestockedEvent]("ReturnRestocked", "product.return.restocked")
+	RegisterEvent[ReturnWrittenOffEvent]("ReturnWrittenOff", "product.return.written_off")
+	RegisterEvent[ReturnRefurbishingEvent]("ReturnRefurbishing", "product.return.refurbishing")
+
+	// Cart (cart.events)
+	RegisterEvent[ItemAddedToCartEvent]("ItemAddedToCart", "cart.item_added")
+	RegisterEvent[ItemRemovedFromCartEvent]("ItemRemovedFromCart", "cart.item_removed")
+	RegisterEvent[CartClearedEvent]("CartCleared", "cart.cleared")
+	RegisterEvent[CartCheckoutInitiatedEvent]("CartCheckoutInitiated", "cart.checkout.initiated")
+
+	// Orders (orders.events; shipping.events for shipped and delivered)
+	RegisterEvent[OrderCreatedEvent]("OrderCreated", "order.created")
+	RegisterEvent[OrderPlacedEvent]("OrderPlaced", "order.placed")
+	RegisterEvent[OrderConfirmedEvent]("OrderConfirmed", "order.confirmed")
+	RegisterEvent[OrderFailedEvent]("OrderFailed", "order.failed")
+	RegisterEvent[OrderCancelledEvent]("OrderCancelled", "order.cancelled")
+	RegisterEvent[OrderShippedEvent]("OrderShipped", "order.shipped")
+	RegisterEvent[OrderDeliveredEvent]("OrderDelivered", "order.delivered")
+	RegisterEvent[OrderPaymentPendingEvent]("OrderPaymentPending", "order.payment_pending")
+	RegisterEvent[PaymentRetryRequestedEvent]("PaymentRetryRequested", "order.payment_retry_requested")
+	// Not order.*, so the order queues don't receive it
+	RegisterEvent[AnnouncementPublishedEvent]("AnnouncementPublished", "announcement.published")
+
+	// Payments (payments.events)
+	RegisterEvent[PaymentProcessedEvent]("PaymentProcessed", "payment.processed")
+	RegisterEvent[PaymentFailedEvent]("PaymentFailed", "payment.failed")
+
+	// Users (users.events)
+	RegisterEvent[UserRegisteredEvent]("UserRegistered", "user.registered")
+	RegisterEvent[UserProfileUpdatedEvent]("UserProfileUpdated", "user.profile_updated")
+	RegisterEvent[UserDeactivatedEvent]("UserDeactivated", "user.deactivated")
+}
@@ -0,0 +1,206 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Event is implemented by every event through its embedded BaseEvent
+type Event interface {
+	GetEventID() string
+}
+
+// ErrUnknownEvent is returned for an event type nothing registered
+var ErrUnknownEvent = errors.New("unknown event type")
+
+// Envelope is the part of the wire format every event shares; it's enough to route a message
+// and check idempotency before the event itself is decoded
+type Envelope struct {
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+// ReadEnvelope decodes the envelope of a message
+func ReadEnvelope(data []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("failed to unmarshal event envelope: %w", err)
+	}
+	return envelope, nil
+}
+
+// Registry maps event names (the event_type on the wire) to their Go types and routing keys
+type Registry struct {
+	mu     sync.RWMutex
+	byName map[string]*registration
+	byType map[reflect.Type]*registration
+}
+
+type registration struct {
+	name       string
+	goType     reflect.Type
+	routingKey string // empty when no service here publishes the event
+	decode     func(data []byte) (Event, error)
+}
+
+// NewRegistry creates an empty registry; the events of this package are in the default one
+func NewRegistry() *Registry {
+	return &Registry{
+		byName: map[string]*registration{},
+		byType: map[reflect.Type]*registration{},
+	}
+}
+
+// Register adds T under name. It panics if name or T is already registered, so a copy-paste
+// mistake stops the service at startup instead of decoding events into the wrong type.
+func Register[T Event](r *Registry, name, routingKey string) {
+	eventType := reflect.TypeFor[T]()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.byName[name]; ok {
+		panic(fmt.Sprintf("events: %s registered twice (%s and %s)", name, existing.goType, eventType))
+	}
+	if existing, ok := r.byType[eventType]; ok {
+		panic(fmt.Sprintf("events: %s registered twice (as %s and %s)", eventType, existing.name, name))
+	}
+
+	reg := &registration{
+		name:       name,
+		goType:     eventType,
+		routingKey: routingKey,
+		decode: func(data []byte) (Event, error) {
+			var event T
+			err := json.Unmarshal(data, &event)
+			return event, err
+		},
+	}
+	r.byName[name] = reg
+	r.byType[eventType] = reg
+}
+
+// Decode decodes a message into the type registered for its event_type
+func (r *Registry) Decode(data []byte) (Event, error) {
+	envelope, err := ReadEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	reg, ok := r.byName[envelope.EventType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, envelope.EventType)
+	}
+
+	event, err := reg.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", envelope.EventType, err)
+	}
+	return event, nil
+}
+
+// Name returns the event_type registered for event
+func (r *Registry) Name(event Event) (string, error) {
+	reg, err := r.lookup(event)
+	if err != nil {
+		return "", err
+	}
+	return reg.name, nil
+}
+
+// RoutingKey returns the routing key event is published with
+func (r *Registry) RoutingKey(event Event) (string, error) {
+	reg, err := r.lookup(event)
+	if err != nil {
+		return "", err
+	}
+	if reg.routingKey == "" {
+		return "", fmt.Errorf("%s has no routing key", reg.name)
+	}
+	return reg.routingKey, nil
+}
+
+// Names returns every registered event_type, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) lookup(event Event) (*registration, error) {
+	r.mu.RLock()
+	reg, ok := r.byType[reflect.TypeOf(event)]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnknownEvent, event)
+	}
+	return reg, nil
+}
+
+// DecodeAs decodes a message into T, failing if its event_type isn't the one T is registered as
+func DecodeAs[T Event](r *Registry, data []byte) (T, error) {
+	var event T
+	r.mu.RLock()
+	reg, ok := r.byType[reflect.TypeFor[T]()]
+	r.mu.RUnlock()
+	if !ok {
+		return event, fmt.Errorf("%w: %T", ErrUnknownEvent, event)
+	}
+
+	envelope, err := ReadEnvelope(data)
+	if err != nil {
+		return event, err
+	}
+	if envelope.EventType != reg.name {
+		return event, fmt.Errorf("expected a %s event, got %s", reg.name, envelope.EventType)
+	}
+
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("failed to unmarshal %s: %w", reg.name, err)
+	}
+	return event, nil
+}
+
+// registry holds every event of this package, see registrations.go
+var registry = NewRegistry()
+
+// RegisterEvent adds T to the registry publishers and subscribers share; it panics on duplicates
+func RegisterEvent[T Event](name, routingKey string) {
+	Register[T](registry, name, routingKey)
+}
+
+// Decode decodes a message into its registered event type
+func Decode(data []byte) (Event, error) {
+	return registry.Decode(data)
+}
+
+// Unmarshal decodes a message into T, failing if it holds another event type
+func Unmarshal[T Event](data []byte) (T, error) {
+	return DecodeAs[T](registry, data)
+}
+
+// RoutingKey returns the routing key event is published with
+func RoutingKey(event Event) (string, error) {
+	return registry.RoutingKey(event)
+}
+
+// NameOf returns the event_type of event
+func NameOf(event Event) (string, error) {
+	return registry.Name(event)
+}
+
+// Names returns every registered event_type, sorted
+func Names() []string {
+	return registry.Names()
+}
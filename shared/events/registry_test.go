@@ -0,0 +1,72 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterPanicsOnDuplicates(t *testing.T) {
+	tests := map[string]func(r *Registry){
+		"name": func(r *Registry) { Register[OrderFailedEvent](r, "OrderPlaced", "order.failed") },
+		"type": func(r *Registry) { Register[OrderPlacedEvent](r, "OrderPlacedAgain", "order.placed") },
+	}
+	for name, register := range tests {
+		r := NewRegistry()
+		Register[OrderPlacedEvent](r, "OrderPlaced", "order.placed")
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: registering twice didn't panic", name)
+				}
+			}()
+			register(r)
+		}()
+	}
+}
+
+func TestDecode(t *testing.T) {
+	sent := StockReservedEvent{
+		BaseEvent: NewBaseEvent("StockReserved", "42", "order", "corr-1"),
+		OrderID:   42,
+		Items:     []ReservedStock{{ProductID: 7, Quantity: 2, ReservationID: "res-1"}},
+	}
+	data, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got, ok := event.(StockReservedEvent); !ok || got.OrderID != 42 || got.Items[0].ReservationID != "res-1" {
+		t.Errorf("Decode = %#v", event)
+	}
+
+	typed, err := Unmarshal[StockReservedEvent](data)
+	if err != nil || typed.CorrelationID != "corr-1" {
+		t.Errorf("Unmarshal = %+v, %v", typed, err)
+	}
+	if _, err := Unmarshal[StockReleasedEvent](data); err == nil || !strings.Contains(err.Error(), "expected a StockReleased event") {
+		t.Errorf("Unmarshal into the wrong type = %v", err)
+	}
+
+	if _, err := Decode([]byte(`{"event_type":"Mystery"}`)); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Decode unknown = %v, want ErrUnknownEvent", err)
+	}
+}
+
+func TestRoutingKey(t *testing.T) {
+	if key, err := RoutingKey(OrderCreatedEvent{}); err != nil || key != "order.created" {
+		t.Errorf("RoutingKey(OrderCreatedEvent) = %q, %v", key, err)
+	}
+	if name, err := NameOf(ItemRemovedFromCartEvent{}); err != nil || name != "ItemRemovedFromCart" {
+		t.Errorf("NameOf(ItemRemovedFromCartEvent) = %q, %v", name, err)
+	}
+	if _, err := RoutingKey(BaseEvent{}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("RoutingKey(BaseEvent) = %v, want ErrUnknownEvent", err)
+	}
+}
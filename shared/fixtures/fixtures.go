@@ -0,0 +1,384 @@
+// Package fixtures loads consistent users, products, carts and orders into a real Postgres
+// for repository tests.
+//
+// A Set is declared in Go or in a YAML file. Rows refer to each other by key, and the loader
+// fills in what makes them consistent: IDs, item prices from the product, cart and order totals
+// from their items. Open gives each test its own migrated database, dropped when it ends.
+//
+//	database := fixtures.Open(t)
+//	loaded := database.Load(t, fixtures.Set{
+//		Users:    []fixtures.User{{Key: "alice"}},
+//		Products: []fixtures.Product{{Key: "laptop", Price: 999.99}},
+//		Orders:   []fixtures.Order{{Key: "first", User: "alice", Items: []fixtures.Item{{Product: "laptop", Quantity: 1}}}},
+//	})
+//	repo := repository.NewOrderRepository(database.Connect(t, "orders"))
+//	order, err := repo.GetOrder(ctx, loaded.Orders["first"])
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Set is the data a test starts from
+type Set struct {
+	Users      []User     `yaml:"users"`
+	Categories []Category `yaml:"categories"`
+	Products   []Product  `yaml:"products"`
+	Carts      []Cart     `yaml:"carts"`
+	Orders     []Order    `yaml:"orders"`
+}
+
+// User is a users.users row; Email defaults to <key>@example.test, Username to the key
+type User struct {
+	Key      string `yaml:"key"`
+	Email    string `yaml:"email"`
+	Username string `yaml:"username"`
+	Role     string `yaml:"role"` // customer by default
+	Deleted  bool   `yaml:"deleted"`
+}
+
+// Category is a catalog.categories row; Parent is the key of a category listed before it
+type Category struct {
+	Key    string `yaml:"key"`
+	Name   string `yaml:"name"` // the key by default
+	Parent string `yaml:"parent"`
+}
+
+// Product is a catalog.products row; SKU defaults to the upper-cased key
+type Product struct {
+	Key      string  `yaml:"key"`
+	Name     string  `yaml:"name"`
+	SKU      string  `yaml:"sku"`
+	Category string  `yaml:"category"`
+	Price    float64 `yaml:"price"`
+	Stock    *int    `yaml:"stock"` // 100 when omitted
+	Deleted  bool    `yaml:"deleted"`
+}
+
+// Item is a cart or order line; Price defaults to the product's price
+type Item struct {
+	Product  string  `yaml:"product"`
+	Quantity int     `yaml:"quantity"` // 1 when omitted
+	Price    float64 `yaml:"price"`
+}
+
+// Cart is a cart.carts row with its items; its total is the sum of the items
+type Cart struct {
+	Key    string `yaml:"key"`
+	User   string `yaml:"user"`
+	Status string `yaml:"status"` // active by default
+	Items  []Item `yaml:"items"`
+}
+
+// Order is an orders.orders row with its items. Cart is the key of the cart it was checked
+// out from; without one the order gets a cart ID of its own.
+type Order struct {
+	Key    string `yaml:"key"`
+	User   string `yaml:"user"`
+	Cart   string `yaml:"cart"`
+	Status string `yaml:"status"` // placed by default
+	Items  []Item `yaml:"items"`
+}
+
+// Loaded maps each key to the ID its row got
+type Loaded struct {
+	Users      map[string]string
+	Categories map[string]int64
+	Products   map[string]int64
+	Carts      map[string]string
+	Orders     map[string]int64
+}
+
+// ReadFile reads a Set from a YAML file
+func ReadFile(path string) (Set, error) {
+	var set Set
+	f, err := os.Open(path)
+	if err != nil {
+		return set, err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true) // a misspelled field fails instead of silently using the default
+	if err := dec.Decode(&set); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// LoadFile loads the Set in a YAML file
+func (d *Database) LoadFile(t *testing.T, path string) *Loaded {
+	t.Helper()
+	set, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	return d.Load(t, set)
+}
+
+// Load inserts set in one transaction
+func (d *Database) Load(t *testing.T, set Set) *Loaded {
+	t.Helper()
+	if err := set.resolve(); err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	defer tx.Rollback()
+
+	loaded, err := set.insert(context.Background(), tx)
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	return loaded
+}
+
+// resolve fills defaults and checks that every reference names a row listed in the set
+func (s *Set) resolve() error {
+	users := map[string]bool{}
+	for i := range s.Users {
+		u := &s.Users[i]
+		if err := newKey(users, "user", u.Key); err != nil {
+			return err
+		}
+		if u.Email == "" {
+			u.Email = u.Key + "@example.test"
+		}
+		if u.Username == "" {
+			u.Username = u.Key
+		}
+		if u.Role == "" {
+			u.Role = "customer"
+		}
+	}
+
+	categories := map[string]bool{}
+	for i := range s.Categories {
+		c := &s.Categories[i]
+		if c.Parent != "" && !categories[c.Parent] {
+			return fmt.Errorf("category %q: parent %q must be listed before it", c.Key, c.Parent)
+		}
+		if err := newKey(categories, "category", c.Key); err != nil {
+			return err
+		}
+		if c.Name == "" {
+			c.Name = c.Key
+		}
+	}
+
+	prices := map[string]float64{}
+	for i := range s.Products {
+		p := &s.Products[i]
+		if _, ok := prices[p.Key]; ok || p.Key == "" {
+			return fmt.Errorf("product key %q is empty or repeated", p.Key)
+		}
+		if p.Category != "" && !categories[p.Category] {
+			return fmt.Errorf("product %q: unknown category %q", p.Key, p.Category)
+		}
+		if p.Price <= 0 {
+			return fmt.Errorf("product %q: price must be positive", p.Key)
+		}
+		if p.Name == "" {
+			p.Name = p.Key
+		}
+		if p.SKU == "" {
+			p.SKU = strings.ToUpper(p.Key)
+		}
+		if p.Stock == nil {
+			stock := 100
+			p.Stock = &stock
+		}
+		prices[p.Key] = p.Price
+	}
+
+	carts := map[string]bool{}
+	for i := range s.Carts {
+		c := &s.Carts[i]
+		if err := newKey(carts, "cart", c.Key); err != nil {
+			return err
+		}
+		if !users[c.User] {
+			return fmt.Errorf("cart %q: unknown user %q", c.Key, c.User)
+		}
+		if c.Status == "" {
+			c.Status = "active"
+		}
+		if err := resolveItems("cart "+c.Key, c.Items, prices); err != nil {
+			return err
+		}
+	}
+
+	orders := map[string]bool{}
+	for i := range s.Orders {
+		o := &s.Orders[i]
+		if err := newKey(orders, "order", o.Key); err != nil {
+			return err
+		}
+		if !users[o.User] {
+			return fmt.Errorf("order %q: unknown user %q", o.Key, o.User)
+		}
+		if o.Cart != "" && !carts[o.Cart] {
+			return fmt.Errorf("order %q: unknown cart %q", o.Key, o.Cart)
+		}
+		if o.Status == "" {
+			o.Status = "placed"
+		}
+		if len(o.Items) == 0 {
+			return fmt.Errorf("order %q has no items", o.Key)
+		}
+		if err := resolveItems("order "+o.Key, o.Items, prices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newKey(seen map[string]bool, kind, key string) error {
+	if key == "" || seen[key] {
+		return fmt.Errorf("%s key %q is empty or repeated", kind, key)
+	}
+	seen[key] = true
+	return nil
+}
+
+func resolveItems(owner string, items []Item, prices map[string]float64) error {
+	for i := range items {
+		item := &items[i]
+		price, ok := prices[item.Product]
+		if !ok {
+			return fmt.Errorf("%s: unknown product %q", owner, item.Product)
+		}
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Price == 0 {
+			item.Price = price
+		}
+	}
+	return nil
+}
+
+// total sums the items, rounded to cents like the DECIMAL columns
+func total(items []Item) float64 {
+	var sum float64
+	for _, item := range items {
+		sum += item.Price * float64(item.Quantity)
+	}
+	return math.Round(sum*100) / 100
+}
+
+// insert writes the resolved set; categories and products come before the rows that refer to them
+func (s *Set) insert(ctx context.Context, tx *sql.Tx) (*Loaded, error) {
+	loaded := &Loaded{
+		Users:      map[string]string{},
+		Categories: map[string]int64{},
+		Products:   map[string]int64{},
+		Carts:      map[string]string{},
+		Orders:     map[string]int64{},
+	}
+
+	for _, u := range s.Users {
+		var id string
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO users.users (email, username, role, deleted_at)
+			VALUES ($1, $2, $3, CASE WHEN $4 THEN CURRENT_TIMESTAMP END)
+			RETURNING id`, u.Email, u.Username, u.Role, u.Deleted).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %w", u.Key, err)
+		}
+		loaded.Users[u.Key] = id
+	}
+
+	for _, c := range s.Categories {
+		var parentID *int64
+		if c.Parent != "" {
+			id := loaded.Categories[c.Parent]
+			parentID = &id
+		}
+		var id int64
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO catalog.categories (name, parent_id) VALUES ($1, $2) RETURNING id`,
+			c.Name, parentID).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %w", c.Key, err)
+		}
+		loaded.Categories[c.Key] = id
+	}
+
+	for _, p := range s.Products {
+		var categoryID *int64
+		if p.Category != "" {
+			id := loaded.Categories[p.Category]
+			categoryID = &id
+		}
+		var id int64
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO catalog.products (name, sku, price, category_id, stock_quantity, deleted_at)
+			VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN CURRENT_TIMESTAMP END)
+			RETURNING id`, p.Name, p.SKU, p.Price, categoryID, *p.Stock, p.Deleted).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("product %q: %w", p.Key, err)
+		}
+		loaded.Products[p.Key] = id
+	}
+
+	for _, c := range s.Carts {
+		var id string
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO cart.carts (user_id, status, total, abandoned_at)
+			VALUES ($1, $2, $3, CASE WHEN $4 THEN CURRENT_TIMESTAMP END)
+			RETURNING id`, loaded.Users[c.User], c.Status, total(c.Items), c.Status == "abandoned").Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("cart %q: %w", c.Key, err)
+		}
+		for _, item := range c.Items {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO cart.cart_items (cart_id, product_id, quantity, price) VALUES ($1, $2, $3, $4)`,
+				id, loaded.Products[item.Product], item.Quantity, item.Price)
+			if err != nil {
+				return nil, fmt.Errorf("cart %q item %q: %w", c.Key, item.Product, err)
+			}
+		}
+		loaded.Carts[c.Key] = id
+	}
+
+	for _, o := range s.Orders {
+		cartID := uuid.NewString()
+		if o.Cart != "" {
+			cartID = loaded.Carts[o.Cart]
+		}
+		var id int64
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO orders.orders (user_id, cart_id, total, status, saga_correlation_id, placed_at)
+			VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN CURRENT_TIMESTAMP END)
+			RETURNING id`, loaded.Users[o.User], cartID, total(o.Items), o.Status, uuid.NewString(), o.Status == "placed").Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("order %q: %w", o.Key, err)
+		}
+		for _, item := range o.Items {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO orders.order_items (order_id, product_id, quantity, price) VALUES ($1, $2, $3, $4)`,
+				id, loaded.Products[item.Product], item.Quantity, item.Price)
+			if err != nil {
+				return nil, fmt.Errorf("order %q item %q: %w", o.Key, item.Product, err)
+			}
+		}
+		loaded.Orders[o.Key] = id
+	}
+	return loaded, nil
+}
@@ -0,0 +1,74 @@
+package fixtures
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadFileResolves(t *testing.T) {
+	set, err := ReadFile("testdata/shop.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := set.resolve(); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if u := set.Users[0]; u.Email != "alice@example.test" || u.Username != "alice" || u.Role != "customer" {
+		t.Errorf("user defaults = %+v", u)
+	}
+	if p := set.Products[1]; p.SKU != "MOUSE" || *p.Stock != 100 {
+		t.Errorf("product defaults = %+v", p)
+	}
+	order := set.Orders[0]
+	if order.Status != "placed" || order.Items[0].Quantity != 1 || order.Items[0].Price != 1299.99 {
+		t.Errorf("order defaults = %+v", order)
+	}
+	if got := total(order.Items); got != 1389.96 {
+		t.Errorf("order total = %v, want 1389.96", got)
+	}
+}
+
+func TestResolveRejectsDanglingReferences(t *testing.T) {
+	tests := map[string]Set{
+		"unknown user":        {Carts: []Cart{{Key: "c", User: "nobody"}}},
+		"unknown product":     {Users: []User{{Key: "u"}}, Orders: []Order{{Key: "o", User: "u", Items: []Item{{Product: "ghost"}}}}},
+		"parent listed after": {Categories: []Category{{Key: "child", Parent: "root"}, {Key: "root"}}},
+		"repeated key":        {Users: []User{{Key: "u"}, {Key: "u"}}},
+		"order without items": {Users: []User{{Key: "u"}}, Orders: []Order{{Key: "o", User: "u"}}},
+	}
+	for name, set := range tests {
+		if err := set.resolve(); err == nil {
+			t.Errorf("%s: resolve succeeded, want an error", name)
+		}
+	}
+}
+
+func TestReadFileRejectsUnknownFields(t *testing.T) {
+	if _, err := ReadFile("testdata/misspelled.yaml"); err == nil || !strings.Contains(err.Error(), "quantiy") {
+		t.Errorf("ReadFile = %v, want the misspelled field reported", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	database := Open(t)
+	loaded := database.LoadFile(t, "testdata/shop.yaml")
+
+	var total float64
+	var items int
+	err := database.DB.QueryRow(`
+		SELECT o.total, COUNT(i.id) FROM orders.orders o JOIN orders.order_items i ON i.order_id = o.id
+		WHERE o.id = $1 AND o.user_id = $2 GROUP BY o.total`,
+		loaded.Orders["bob-first"], loaded.Users["bob"]).Scan(&total, &items)
+	if err != nil {
+		t.Fatalf("reading the order: %v", err)
+	}
+	if total != 1389.96 || items != 2 {
+		t.Errorf("order total = %v with %d items, want 1389.96 with 2", total, items)
+	}
+
+	var parentID int64
+	if err := database.DB.QueryRow(`SELECT parent_id FROM catalog.categories WHERE id = $1`, loaded.Categories["laptops"]).Scan(&parentID); err != nil || parentID != loaded.Categories["electronics"] {
+		t.Errorf("laptops parent = %d (%v), want electronics", parentID, err)
+	}
+}
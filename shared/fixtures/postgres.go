@@ -0,0 +1,255 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/db"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// DatabaseURLVariable points tests at an existing Postgres instead of a container, e.g. in CI
+// jobs without Docker. Its database is only used to create and drop the per-test databases.
+const DatabaseURLVariable = "TEST_DATABASE_URL"
+
+// Image matches the Postgres the services run against (docker-compose.yml)
+const Image = "postgres:17-alpine"
+
+// Database is one test's own database holding every service schema
+type Database struct {
+	DB     *sql.DB // superuser connection, for fixtures and assertions across schemas
+	config db.Config
+}
+
+// server is the Postgres the test binary creates its databases on
+type server struct {
+	config db.Config // DBName is the maintenance database
+	skip   string    // why tests can't get a database, when Docker isn't there
+	err    error
+}
+
+var (
+	serverOnce sync.Once
+	shared     server
+	databases  atomic.Int64
+)
+
+// Open creates a database migrated with every schema's migrations and drops it when the test ends.
+// The first call starts a Postgres container that the whole test binary shares, unless
+// TEST_DATABASE_URL is set; without either the test is skipped. Seed migrations are skipped so
+// a Set is the only data.
+func Open(t *testing.T) *Database {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("fixtures: needs Postgres, skipped with -short")
+	}
+
+	serverOnce.Do(func() { shared = startServer() })
+	if shared.skip != "" {
+		t.Skipf("fixtures: %s", shared.skip)
+	}
+	if shared.err != nil {
+		t.Fatalf("fixtures: %v", shared.err)
+	}
+
+	migrations, err := migrationFiles()
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+
+	admin, err := sql.Open("postgres", dsn(shared.config))
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("fixtures_%d_%d", os.Getpid(), databases.Add(1))
+	if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("fixtures: creating %s: %v", name, err)
+	}
+
+	config := shared.config
+	config.DBName = name
+	database := &Database{config: config}
+	t.Cleanup(func() { database.drop(t) })
+
+	if database.DB, err = sql.Open("postgres", dsn(config)); err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	for _, path := range migrations {
+		script, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("fixtures: %v", err)
+		}
+		if _, err := database.DB.Exec(string(script)); err != nil {
+			t.Fatalf("fixtures: %s: %v", filepath.Base(path), err)
+		}
+	}
+	return database
+}
+
+// Connect opens the connection a service's repositories use, on schema
+func (d *Database) Connect(t *testing.T, schema string) *db.Connection {
+	t.Helper()
+	config := d.config
+	config.Schema = schema
+	config.Pool = db.PoolConfig{MaxOpenConns: 5, MaxIdleConns: 2}
+
+	conn, err := db.NewDBConnection(config)
+	if err != nil {
+		t.Fatalf("fixtures: connecting to %s: %v", schema, err)
+	}
+	t.Cleanup(func() { conn.DBConnClose() })
+	return conn
+}
+
+// drop closes the fixture connection and drops the database, disconnecting anything still open
+func (d *Database) drop(t *testing.T) {
+	if d.DB != nil {
+		d.DB.Close()
+	}
+	admin, err := sql.Open("postgres", dsn(shared.config))
+	if err != nil {
+		t.Errorf("fixtures: %v", err)
+		return
+	}
+	defer admin.Close()
+	if _, err := admin.Exec("DROP DATABASE IF EXISTS " + d.config.DBName + " WITH (FORCE)"); err != nil {
+		t.Errorf("fixtures: dropping %s: %v", d.config.DBName, err)
+	}
+}
+
+// startServer uses TEST_DATABASE_URL, or starts a container that testcontainers removes when the
+// test binary exits
+func startServer() server {
+	if raw := os.Getenv(DatabaseURLVariable); raw != "" {
+		config, err := parseURL(raw)
+		if err != nil {
+			return server{err: fmt.Errorf("%s: %w", DatabaseURLVariable, err)}
+		}
+		return server{config: config}
+	}
+
+	if err := dockerHealthy(); err != nil {
+		return server{skip: fmt.Sprintf("Docker isn't available and %s isn't set: %v", DatabaseURLVariable, err)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	container, err := postgres.Run(ctx, Image,
+		postgres.WithDatabase("prost"),
+		postgres.WithUsername("prost_admin"),
+		postgres.WithPassword("prost_password"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		return server{err: fmt.Errorf("starting %s: %w", Image, err)}
+	}
+	raw, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return server{err: err}
+	}
+	config, err := parseURL(raw)
+	return server{config: config, err: err}
+}
+
+// dockerHealthy is testcontainers.SkipIfProviderIsNotHealthy without the *testing.T, so the
+// answer can be shared by every test
+func dockerHealthy() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	return provider.Health(context.Background())
+}
+
+func parseURL(raw string) (db.Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return db.Config{}, err
+	}
+	password, _ := u.User.Password()
+	config := db.Config{
+		Host:     u.Hostname(),
+		Port:     u.Port(),
+		User:     u.User.Username(),
+		Password: password,
+		DBName:   strings.TrimPrefix(u.Path, "/"),
+		SSLMode:  u.Query().Get("sslmode"),
+	}
+	if config.Port == "" {
+		config.Port = "5432"
+	}
+	if config.SSLMode == "" {
+		config.SSLMode = "disable"
+	}
+	return config, nil
+}
+
+func dsn(c db.Config) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+}
+
+// migrationFiles finds the repository root above the working directory (the package under test)
+// and returns every schema's up migrations (services/*/migrations, plus infra/migrations/db) in
+// version order, without the seed ones
+func migrationFiles() ([]string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		candidate := filepath.Join(dir, "infra", "migrations", "db")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			var paths []string
+			for _, pattern := range []string{
+				filepath.Join(dir, "services", "*", "migrations", "*.up.sql"),
+				filepath.Join(candidate, "*.up.sql"),
+			} {
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, matches...)
+			}
+			// Why: versions keep the numbers of the single sequence they were split from, so
+			// sorting by file name replays that sequence across schemas
+			sort.Slice(paths, func(i, j int) bool {
+				bi, bj := filepath.Base(paths[i]), filepath.Base(paths[j])
+				if bi != bj {
+					return bi < bj
+				}
+				return paths[i] < paths[j]
+			})
+			var migrations []string
+			for _, path := range paths {
+				if !strings.Contains(filepath.Base(path), "_seed_") {
+					migrations = append(migrations, path)
+				}
+			}
+			return migrations, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("infra/migrations/db not found above the working directory")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,215 @@
+// Package httpserver builds a service's HTTP server from its limits: timeouts and request body
+// size, with per-route overrides.
+//
+// Why: every service hardcoded 15s read/write timeouts, which is too long for a health probe and
+// too short for a bulk upload. Routes that need a different budget override it here, and the
+// same budget bounds the handler's context, so work stops when the client can no longer get
+// the response.
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/config"
+)
+
+// writeGrace is how long after the handler's deadline the response may still be written,
+// so a handler that hit its deadline can report it
+const writeGrace = time.Second
+
+// RouteLimits bounds one route
+type RouteLimits struct {
+	Timeout      time.Duration // read, handler and write budget; 0 uses the server's
+	MaxBodyBytes int64         // 0 uses the server's
+}
+
+// Config is a service's HTTP server limits
+type Config struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration // also the default handler budget
+	IdleTimeout  time.Duration
+	MaxBodyBytes int64                  // request bodies larger than this get 413; 0 disables
+	Routes       map[string]RouteLimits // keyed by "METHOD /path" as registered, e.g. "GET /products/:id"
+}
+
+// DefaultConfig returns the limits shared by the services
+func DefaultConfig() Config {
+	return Config{
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+		MaxBodyBytes: 1 << 20,
+		Routes: map[string]RouteLimits{
+			"GET /health": {Timeout: 2 * time.Second},
+		},
+	}
+}
+
+// Configure applies a service's HTTP settings over defaults. An unset limit keeps the default;
+// HTTP_ROUTE_LIMITS entries (see ParseRouteLimits) add to or replace the default routes, and
+// one that doesn't parse is an error, so a typo can't silently drop an override.
+func Configure(defaults Config, settings config.HTTP) (Config, error) {
+	c := defaults
+	if settings.ReadTimeout != nil {
+		c.ReadTimeout = *settings.ReadTimeout
+	}
+	if settings.WriteTimeout != nil {
+		c.WriteTimeout = *settings.WriteTimeout
+	}
+	if settings.IdleTimeout != nil {
+		c.IdleTimeout = *settings.IdleTimeout
+	}
+	if settings.MaxBodyBytes != nil {
+		c.MaxBodyBytes = *settings.MaxBodyBytes
+	}
+
+	c.Routes = make(map[string]RouteLimits, len(defaults.Routes))
+	for route, limits := range defaults.Routes {
+		c.Routes[route] = limits
+	}
+	overrides, err := ParseRouteLimits(settings.RouteLimits)
+	if err != nil {
+		return Config{}, fmt.Errorf("HTTP_ROUTE_LIMITS: %w", err)
+	}
+	for route, limits := range overrides {
+		c.Routes[route] = limits
+	}
+	return c, nil
+}
+
+// ParseRouteLimits parses route overrides of the form
+//
+//	POST /products/import=5m,52428800;GET /health=2s
+//
+// Each entry is "METHOD /path=timeout[,max_body_bytes]", with a Go duration for the timeout.
+// Valid entries are returned even when others fail to parse.
+func ParseRouteLimits(s string) (map[string]RouteLimits, error) {
+	routes := map[string]RouteLimits{}
+	var errs []string
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, limits, err := parseRouteLimit(entry)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		routes[route] = limits
+	}
+	if len(errs) > 0 {
+		return routes, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return routes, nil
+}
+
+func parseRouteLimit(entry string) (string, RouteLimits, error) {
+	route, value, ok := strings.Cut(entry, "=")
+	method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok || !hasPath || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+		return "", RouteLimits{}, fmt.Errorf("%q: want \"METHOD /path=timeout[,max_body_bytes]\"", entry)
+	}
+
+	var limits RouteLimits
+	timeout, body, hasBody := strings.Cut(value, ",")
+	parsed, err := time.ParseDuration(strings.TrimSpace(timeout))
+	if err != nil || parsed < 0 {
+		return "", RouteLimits{}, fmt.Errorf("%q: invalid timeout", entry)
+	}
+	limits.Timeout = parsed
+	if hasBody {
+		limits.MaxBodyBytes, err = strconv.ParseInt(strings.TrimSpace(body), 10, 64)
+		if err != nil || limits.MaxBodyBytes < 0 {
+			return "", RouteLimits{}, fmt.Errorf("%q: invalid max_body_bytes", entry)
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.TrimSpace(path), limits, nil
+}
+
+// Limits returns the limits of a route; anything the route doesn't override comes from the server
+func (c Config) Limits(method, path string) RouteLimits {
+	limits := c.Routes[method+" "+path]
+	if limits.Timeout == 0 {
+		limits.Timeout = c.WriteTimeout
+	}
+	if limits.MaxBodyBytes == 0 {
+		limits.MaxBodyBytes = c.MaxBodyBytes
+	}
+	return limits
+}
+
+// NewServer returns the server for handler with the configured connection timeouts
+func (c Config) NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout + writeGrace,
+		IdleTimeout:  c.IdleTimeout,
+	}
+}
+
+// Middleware applies each route's limits: it rejects oversized bodies with 413, moves the
+// connection's read and write deadlines to the route's budget and bounds the request context
+// by it. Register it before the routes so it runs for all of them.
+func (c Config) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limits := c.Limits(ctx.Request.Method, ctx.FullPath())
+
+		if limits.MaxBodyBytes > 0 && ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+			if ctx.Request.ContentLength > limits.MaxBodyBytes || !bufferChunkedBody(ctx.Request, limits.MaxBodyBytes) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":   "request body too large",
+					"message": fmt.Sprintf("limit is %d bytes", limits.MaxBodyBytes),
+					"code":    http.StatusRequestEntityTooLarge,
+				})
+				return
+			}
+			// A body longer than its Content-Length is cut off by net/http; this is a backstop
+			ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limits.MaxBodyBytes)
+		}
+
+		deadline := time.Now().Add(limits.Timeout)
+		rc := http.NewResponseController(ctx.Writer)
+		if err := rc.SetReadDeadline(deadline); err != nil {
+			log.Printf("⚠️  Failed to set read deadline for %s: %v", ctx.FullPath(), err)
+		}
+		if err := rc.SetWriteDeadline(deadline.Add(writeGrace)); err != nil {
+			log.Printf("⚠️  Failed to set write deadline for %s: %v", ctx.FullPath(), err)
+		}
+
+		reqCtx, cancel := context.WithDeadline(ctx.Request.Context(), deadline)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		ctx.Next()
+	}
+}
+
+// bufferChunkedBody reads a body sent without a Content-Length into memory, up to limit, and
+// reports whether it fit. Why: a chunked body's size is only known once read; past the limit
+// a handler's JSON bind would fail with a 400, and the client should get 413 like any other
+// oversized request. Bodies with a length are left to stream.
+func bufferChunkedBody(r *http.Request, limit int64) bool {
+	if r.ContentLength >= 0 {
+		return true
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	r.Body.Close()
+	if err != nil || int64(len(body)) > limit {
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return true
+}
@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/config"
+)
+
+func TestParseRouteLimits(t *testing.T) {
+	routes, err := ParseRouteLimits("post /products/import=5m,52428800; GET /health=2s;GET /broken=soon")
+	if err == nil || !strings.Contains(err.Error(), "/broken") {
+		t.Errorf("err = %v, want the broken entry reported", err)
+	}
+
+	if got := routes["POST /products/import"]; got.Timeout != 5*time.Minute || got.MaxBodyBytes != 52428800 {
+		t.Errorf("import = %+v", got)
+	}
+	if got := routes["GET /health"]; got.Timeout != 2*time.Second || got.MaxBodyBytes != 0 {
+		t.Errorf("health = %+v", got)
+	}
+	if _, ok := routes["GET /broken"]; ok {
+		t.Error("broken entry kept")
+	}
+}
+
+func TestLimitsFallBackToServer(t *testing.T) {
+	c := DefaultConfig()
+	c.Routes["POST /products/import"] = RouteLimits{MaxBodyBytes: 10 << 20}
+
+	if got := c.Limits(http.MethodPost, "/products/import"); got.Timeout != c.WriteTimeout || got.MaxBodyBytes != 10<<20 {
+		t.Errorf("import = %+v", got)
+	}
+	if got := c.Limits(http.MethodGet, "/health"); got.Timeout != 2*time.Second || got.MaxBodyBytes != c.MaxBodyBytes {
+		t.Errorf("health = %+v", got)
+	}
+	if got := c.Limits(http.MethodGet, ""); got.Timeout != c.WriteTimeout {
+		t.Errorf("unmatched route = %+v", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := DefaultConfig()
+	c.MaxBodyBytes = 16
+	c.Routes["POST /slow"] = RouteLimits{Timeout: time.Minute}
+
+	var budget time.Duration
+	router := gin.New()
+	router.Use(c.Middleware())
+	router.POST("/slow", func(ctx *gin.Context) {
+		deadline, _ := ctx.Request.Context().Deadline()
+		budget = time.Until(deadline)
+		ctx.Status(http.StatusOK)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/slow", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || budget <= c.WriteTimeout || budget > time.Minute {
+		t.Errorf("status %d, budget %s; want 200 within the route's minute", resp.StatusCode, budget)
+	}
+
+	resp, err = http.Post(server.URL+"/slow", "application/json", strings.NewReader(`{"name":"far too long"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", resp.StatusCode)
+	}
+}
+
+func TestMiddlewareChunkedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := DefaultConfig()
+	c.MaxBodyBytes = 16
+
+	var bound map[string]string
+	router := gin.New()
+	router.Use(c.Middleware())
+	router.POST("/items", func(ctx *gin.Context) {
+		if err := ctx.ShouldBindJSON(&bound); err != nil {
+			ctx.Status(http.StatusBadRequest)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// A reader of unknown length is sent chunked, with no Content-Length
+	post := func(body string) int {
+		resp, err := http.Post(server.URL+"/items", "application/json", io.MultiReader(strings.NewReader(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post(`{"a":"b"}`); status != http.StatusOK || bound["a"] != "b" {
+		t.Errorf("status %d, bound %v; want 200 with the body intact", status, bound)
+	}
+	if status := post(`{"name":"far too long"}`); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", status)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	write, body := 45*time.Second, int64(0)
+	c, err := Configure(DefaultConfig(), config.HTTP{
+		WriteTimeout: &write,
+		MaxBodyBytes: &body,
+		RouteLimits:  "POST /products/import=5m,52428800",
+	})
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if c.WriteTimeout != write || c.MaxBodyBytes != 0 || c.ReadTimeout != DefaultConfig().ReadTimeout {
+		t.Errorf("config = %+v, want the write timeout and body limit replaced", c)
+	}
+	if c.Routes["GET /health"].Timeout != 2*time.Second || c.Routes["POST /products/import"].Timeout != 5*time.Minute {
+		t.Errorf("routes = %+v, want the default and the override", c.Routes)
+	}
+
+	if _, err := Configure(DefaultConfig(), config.HTTP{RouteLimits: "POST /import"}); err == nil {
+		t.Error("Configure accepted a route override that doesn't parse")
+	}
+}
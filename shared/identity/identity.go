@@ -0,0 +1,93 @@
+// Package identity reads who a request acts for from the users-service JWT the gateway forwards,
+// for the services' authorization decisions.
+//
+// Why: the gateway validates the caller's token and then called the services anonymously, so
+// a service either trusted any caller or parsed the token itself with its own claims type. The
+// gateway now forwards the token on every call, and the services read it here.
+package identity
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+// Keys of the caller on the gin context, read with c.GetString
+const (
+	UserIDKey = "user_id"
+	RoleKey   = "role"
+)
+
+var (
+	// ErrNoToken is returned for a request without a bearer token
+	ErrNoToken = errors.New("authorization header required")
+	// ErrInvalidToken is returned for a bearer token that doesn't verify or names no user
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Claims is the subset of users-service JWT claims naming the caller
+type Claims struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// FromRequest verifies r's bearer token with keys and returns its claims
+func FromRequest(r *http.Request, keys *jwtkeys.KeySet) (*Claims, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") || keys == nil {
+		return nil, ErrNoToken
+	}
+
+	claims := &Claims{}
+	token, err := keys.ParseWithClaims(strings.TrimPrefix(header, "Bearer "), claims)
+	if err != nil || !token.Valid || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Middleware sets user_id and role from a valid bearer token and lets every request through;
+// what an anonymous caller may do is up to the handler. keys nil sets nothing.
+func Middleware(keys *jwtkeys.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, err := FromRequest(c.Request, keys); err == nil {
+			set(c, claims)
+		}
+		c.Next()
+	}
+}
+
+// Require lets through only requests bearing a valid token, and sets user_id and role; which
+// roles may call a route is up to the RBAC policy (shared/rbac). keys nil disables the routes.
+func Require(keys *jwtkeys.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if keys == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "authenticated endpoints disabled",
+				"message": "JWT keys not configured",
+			})
+			return
+		}
+
+		claims, err := FromRequest(c.Request, keys)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		set(c, claims)
+		c.Next()
+	}
+}
+
+func set(c *gin.Context, claims *Claims) {
+	c.Set(UserIDKey, claims.UserID)
+	c.Set(RoleKey, claims.Role)
+}
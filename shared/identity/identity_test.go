@@ -0,0 +1,81 @@
+package identity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+)
+
+// serve runs mw then a handler reporting the caller it set
+func serve(t *testing.T, mw gin.HandlerFunc, authorization string) (*httptest.ResponseRecorder, string, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var userID, role string
+	router := gin.New()
+	router.GET("/", mw, func(c *gin.Context) {
+		userID, role = c.GetString(UserIDKey), c.GetString(RoleKey)
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	router.ServeHTTP(rec, req)
+	return rec, userID, role
+}
+
+func TestMiddleware(t *testing.T) {
+	keys := jwtkeys.NewHMACKeySet("test-secret")
+	token, err := keys.Sign(Claims{UserID: "user-9", Role: "customer"})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	forged, _ := jwtkeys.NewHMACKeySet("other-secret").Sign(Claims{UserID: "user-9", Role: "admin"})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantUser      string
+	}{
+		{"valid token", "Bearer " + token, "user-9"},
+		{"no token", "", ""},
+		{"forged token", "Bearer " + forged, ""},
+		{"not a bearer token", "Basic dXNlcjpwYXNz", ""},
+	}
+	for _, tt := range tests {
+		rec, userID, _ := serve(t, Middleware(keys), tt.authorization)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.name, rec.Code)
+		}
+		if userID != tt.wantUser {
+			t.Errorf("%s: user = %q, want %q", tt.name, userID, tt.wantUser)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	keys := jwtkeys.NewHMACKeySet("test-secret")
+	token, _ := keys.Sign(Claims{UserID: "admin-1", Role: "admin"})
+	anonymous, _ := keys.Sign(Claims{Role: "admin"})
+
+	rec, userID, role := serve(t, Require(keys), "Bearer "+token)
+	if rec.Code != http.StatusOK || userID != "admin-1" || role != "admin" {
+		t.Errorf("valid token: %d %q %q, want 200 admin-1 admin", rec.Code, userID, role)
+	}
+
+	if rec, _, _ := serve(t, Require(keys), ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec, _, _ := serve(t, Require(keys), "Bearer "+anonymous); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token without a user: status = %d, want 401", rec.Code)
+	}
+	if rec, _, _ := serve(t, Require(nil), "Bearer "+token); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("no keys: status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,167 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSRefresh = 5 * time.Minute
+	// jwksMinRefetch limits refetches for unknown kids, so forged tokens can't hammer the users service
+	jwksMinRefetch = 30 * time.Second
+	jwksTimeout    = 5 * time.Second
+)
+
+// JWK is one public key in a JWKS document (RFC 7517); only RSA signing keys are published
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set's RS256 keys. HMAC secrets are never included.
+func (ks *KeySet) JWKS() JWKS {
+	doc := JWKS{Keys: []JWK{}}
+	for _, kid := range ks.order {
+		key := ks.keys[kid]
+		if key.Algorithm != RS256 {
+			continue
+		}
+		doc.Keys = append(doc.Keys, JWK{
+			KeyType:   "RSA",
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: RS256,
+			N:         base64.RawURLEncoding.EncodeToString(key.public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.public.E)).Bytes()),
+		})
+	}
+	return doc
+}
+
+// publicKey decodes an RSA JWK
+func (k JWK) publicKey() (*rsa.PublicKey, error) {
+	if k.KeyType != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// JWKSClient caches the public keys published by the users service
+type JWKSClient struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	now         func() time.Time
+}
+
+// NewJWKSClient creates a client for the JWKS at url; keys are refetched every refresh
+func NewJWKSClient(url string, refresh time.Duration) *JWKSClient {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	return &JWKSClient{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksTimeout},
+		keys:    map[string]*rsa.PublicKey{},
+		now:     time.Now,
+	}
+}
+
+// Key returns the public key with the given kid. The JWKS is fetched when the cache is older
+// than the refresh interval, or for an unknown kid (a key just rotated in) at most every 30s.
+// When a refetch fails the cached keys are kept.
+func (c *JWKSClient) Key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key, known := c.keys[kid]
+	stale := now.Sub(c.fetchedAt) >= c.refresh
+	if (stale || !known) && now.Sub(c.lastAttempt) >= jwksMinRefetch {
+		c.lastAttempt = now
+		keys, err := c.fetch()
+		if err != nil && !known {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		if err == nil {
+			c.keys, c.fetchedAt = keys, now
+			key, known = keys[kid]
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSClient) fetch() (map[string]*rsa.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var doc JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.KeyID == "" || (jwk.Algorithm != "" && jwk.Algorithm != RS256) {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
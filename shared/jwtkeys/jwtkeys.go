@@ -0,0 +1,245 @@
+// Package jwtkeys holds the keys that sign and verify users-service JWTs.
+//
+// Why: a single shared JWT_SECRET can't be rotated without logging everyone out, and every
+// service that verifies tokens can also mint them. Keys now have IDs (the token's kid header):
+// several can be active at once, so a new key is added everywhere before the users service
+// signs with it and the old one is removed once its tokens have expired. RS256 keys let the
+// other services verify with public keys only, fetched from the users service's JWKS endpoint.
+//
+// Configuration (see ConfigFromEnv):
+//
+//	JWT_SECRET          legacy HS256 secret for tokens without a kid
+//	JWT_KEYS            kid=HS256:secret;kid=RS256:/path/to/key.pem
+//	JWT_SIGNING_KEY_ID  key the users service signs with (default: the first JWT_KEYS entry,
+//	                    or JWT_SECRET when there is none)
+//	JWKS_URL            verifiers: fetch RS256 public keys from the users service
+package jwtkeys
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// ErrNoSigningKey is returned when signing with a key set that has no private or secret key
+var ErrNoSigningKey = errors.New("no signing key configured")
+
+// Key is one signing or verification key
+type Key struct {
+	ID        string // kid; empty for the legacy JWT_SECRET
+	Algorithm string // HS256 or RS256
+	secret    []byte
+	private   *rsa.PrivateKey // nil when only the public key is known
+	public    *rsa.PublicKey
+}
+
+// CanSign reports whether the key can sign tokens, not only verify them
+func (k *Key) CanSign() bool {
+	return len(k.secret) > 0 || k.private != nil
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == RS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodHS256
+}
+
+func (k *Key) signKey() interface{} {
+	if k.Algorithm == RS256 {
+		return k.private
+	}
+	return k.secret
+}
+
+func (k *Key) verifyKey() interface{} {
+	if k.Algorithm == RS256 {
+		return k.public
+	}
+	return k.secret
+}
+
+// Config is where the keys come from
+type Config struct {
+	Secret       string        // JWT_SECRET
+	Keys         string        // JWT_KEYS
+	SigningKeyID string        // JWT_SIGNING_KEY_ID
+	JWKSURL      string        // JWKS_URL
+	JWKSRefresh  time.Duration // JWKS_REFRESH_SECONDS
+}
+
+// ConfigFromEnv reads the JWT_* and JWKS_* environment variables
+func ConfigFromEnv() Config {
+	config := Config{
+		Secret:       os.Getenv("JWT_SECRET"),
+		Keys:         os.Getenv("JWT_KEYS"),
+		SigningKeyID: os.Getenv("JWT_SIGNING_KEY_ID"),
+		JWKSURL:      os.Getenv("JWKS_URL"),
+		JWKSRefresh:  defaultJWKSRefresh,
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("JWKS_REFRESH_SECONDS")); err == nil && seconds > 0 {
+		config.JWKSRefresh = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// Configured reports whether any key source is set
+func (c Config) Configured() bool {
+	return c.Secret != "" || c.Keys != "" || c.JWKSURL != ""
+}
+
+// KeySet is the key tokens are signed with and every key they are verified with
+type KeySet struct {
+	signing *Key
+	legacy  *Key            // tokens without a kid
+	keys    map[string]*Key // by kid
+	order   []string        // kids in configuration order
+	jwks    *JWKSClient     // nil: local keys only
+}
+
+// NewHMACKeySet returns a key set with only a legacy HS256 secret, as before key IDs
+func NewHMACKeySet(secret string) *KeySet {
+	legacy := &Key{Algorithm: HS256, secret: []byte(secret)}
+	return &KeySet{signing: legacy, legacy: legacy, keys: map[string]*Key{}}
+}
+
+// NewKeySet builds the key set from config; PEM files are read now
+func NewKeySet(config Config) (*KeySet, error) {
+	ks := &KeySet{keys: map[string]*Key{}}
+	if config.Secret != "" {
+		ks.legacy = &Key{Algorithm: HS256, secret: []byte(config.Secret)}
+	}
+
+	for _, entry := range strings.Split(config.Keys, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, err := parseKey(entry)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := ks.keys[key.ID]; dup {
+			return nil, fmt.Errorf("JWT_KEYS: key %q is listed twice", key.ID)
+		}
+		ks.keys[key.ID] = key
+		ks.order = append(ks.order, key.ID)
+	}
+
+	switch {
+	case config.SigningKeyID != "":
+		key, ok := ks.keys[config.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_ID %q is not in JWT_KEYS", config.SigningKeyID)
+		}
+		ks.signing = key
+	case len(ks.order) > 0:
+		ks.signing = ks.keys[ks.order[0]]
+	default:
+		ks.signing = ks.legacy
+	}
+
+	if config.JWKSURL != "" {
+		ks.jwks = NewJWKSClient(config.JWKSURL, config.JWKSRefresh)
+	}
+	return ks, nil
+}
+
+// parseKey parses one "kid=ALG:value" entry; value is the secret for HS256 and a PEM file for RS256
+func parseKey(entry string) (*Key, error) {
+	kid, spec, ok := strings.Cut(entry, "=")
+	alg, value, hasValue := strings.Cut(spec, ":")
+	kid, alg = strings.TrimSpace(kid), strings.ToUpper(strings.TrimSpace(alg))
+	if !ok || !hasValue || kid == "" || value == "" {
+		return nil, fmt.Errorf("JWT_KEYS: %q: want kid=ALG:value", kid)
+	}
+
+	switch alg {
+	case HS256:
+		return &Key{ID: kid, Algorithm: HS256, secret: []byte(value)}, nil
+	case RS256:
+		pem, err := os.ReadFile(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_KEYS: %q: %w", kid, err)
+		}
+		key := &Key{ID: kid, Algorithm: RS256}
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+			key.private, key.public = private, &private.PublicKey
+			return key, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_KEYS: %q: not an RSA private or public key", kid)
+		}
+		key.public = public
+		return key, nil
+	default:
+		return nil, fmt.Errorf("JWT_KEYS: %q: unsupported algorithm %q (HS256 or RS256)", kid, alg)
+	}
+}
+
+// SigningKey returns the key new tokens are signed with; nil when there is none
+func (ks *KeySet) SigningKey() *Key {
+	return ks.signing
+}
+
+// CanVerify reports whether any token could be verified
+func (ks *KeySet) CanVerify() bool {
+	return ks.legacy != nil || len(ks.keys) > 0 || ks.jwks != nil
+}
+
+// Sign signs claims with the signing key, setting the kid header when the key has an ID
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.signing
+	if key == nil || !key.CanSign() {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.signKey())
+}
+
+// Keyfunc picks the verification key for a token by its kid, for jwt.ParseWithClaims.
+// The token's alg must be the key's, so a public RSA key is never used as an HMAC secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key *Key
+	if kid == "" {
+		key = ks.legacy
+	} else if key = ks.keys[kid]; key == nil && ks.jwks != nil {
+		public, err := ks.jwks.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		key = &Key{ID: kid, Algorithm: RS256, public: public}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey(), nil
+}
+
+// ParseWithClaims verifies tokenString and decodes it into claims
+func (ks *KeySet) ParseWithClaims(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.Keyfunc, jwt.WithValidMethods([]string{HS256, RS256}))
+}
@@ -0,0 +1,181 @@
+package jwtkeys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeRSAKey(t *testing.T, private bool) (string, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if !private {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, key
+}
+
+func claims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{Subject: "u1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+}
+
+func TestRotation(t *testing.T) {
+	old, err := NewKeySet(Config{Secret: "legacy", Keys: "k1=HS256:one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := old.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyToken, _ := NewHMACKeySet("legacy").Sign(claims())
+
+	// k2 is added and signs; k1 and the legacy secret still verify until removed
+	rotated, err := NewKeySet(Config{Secret: "legacy", Keys: "k1=HS256:one;k2=HS256:two", SigningKeyID: "k2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, _ := rotated.Sign(claims())
+
+	for name, token := range map[string]string{"old": oldToken, "legacy": legacyToken, "new": newToken} {
+		if _, err := rotated.ParseWithClaims(token, &jwt.RegisteredClaims{}); err != nil {
+			t.Errorf("%s token: %v", name, err)
+		}
+	}
+
+	parsed, _ := rotated.ParseWithClaims(newToken, &jwt.RegisteredClaims{})
+	if kid := parsed.Header["kid"]; kid != "k2" {
+		t.Errorf("kid = %v, want k2", kid)
+	}
+
+	if _, err := old.ParseWithClaims(newToken, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("token signed with an unknown kid accepted")
+	}
+}
+
+func TestSigningKeyMustExist(t *testing.T) {
+	if _, err := NewKeySet(Config{Keys: "k1=HS256:one", SigningKeyID: "k9"}); err == nil {
+		t.Error("unknown JWT_SIGNING_KEY_ID accepted")
+	}
+	if _, err := NewKeySet(Config{Keys: "k1=HS512:one"}); err == nil {
+		t.Error("unsupported algorithm accepted")
+	}
+}
+
+func TestRS256WithPublicKeyOnly(t *testing.T) {
+	privatePath, key := writeRSAKey(t, true)
+	signer, err := NewKeySet(Config{Keys: "rsa1=RS256:" + privatePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicPEM, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicPath := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := NewKeySet(Config{Keys: "rsa1=RS256:" + publicPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.ParseWithClaims(token, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("verify with public key: %v", err)
+	}
+	if _, err := verifier.Sign(claims()); err != ErrNoSigningKey {
+		t.Errorf("sign with public key: err = %v, want ErrNoSigningKey", err)
+	}
+}
+
+func TestAlgorithmMustMatchKey(t *testing.T) {
+	// An HS256 token whose kid names an RSA key must not be checked against the public key bytes
+	publicPath, _ := writeRSAKey(t, false)
+	ks, err := NewKeySet(Config{Keys: "rsa1=RS256:" + publicPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	token.Header["kid"] = "rsa1"
+	forged, _ := token.SignedString([]byte("anything"))
+
+	if _, err := ks.ParseWithClaims(forged, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("HS256 token accepted for an RS256 key")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	privatePath, _ := writeRSAKey(t, true)
+	signer, err := NewKeySet(Config{Secret: "legacy", Keys: "hs=HS256:secret;rsa1=RS256:" + privatePath, SigningKeyID: "rsa1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := signer.JWKS()
+	if len(doc.Keys) != 1 || doc.Keys[0].KeyID != "rsa1" {
+		t.Fatalf("JWKS = %+v, want only the RSA key", doc)
+	}
+
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(doc)
+	}))
+	defer srv.Close()
+
+	verifier, err := NewKeySet(Config{JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := signer.Sign(claims())
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.ParseWithClaims(token, &jwt.RegisteredClaims{}); err != nil {
+			t.Fatalf("verify via JWKS: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1 (cached)", fetches)
+	}
+
+	// Unknown kids don't refetch more than every jwksMinRefetch
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+	unknown.Header["kid"] = "rsa9"
+	_, key := writeRSAKey(t, true)
+	forged, _ := unknown.SignedString(key)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.ParseWithClaims(forged, &jwt.RegisteredClaims{}); err == nil {
+			t.Fatal("token with unknown kid accepted")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d after unknown kids, want 1", fetches)
+	}
+}
@@ -61,6 +61,25 @@ func GetProstTopology() MessagingTopology {
 				Durable:    true,
 				AutoDelete: false,
 			},
+			{
+				Name:       "shipping.events",
+				Type:       "topic",
+				Durable:    true,
+				AutoDelete: false,
+			},
+			{
+				Name:       "users.events",
+				Type:       "topic",
+				Durable:    true,
+				AutoDelete: false,
+			},
+			// Published by the payment service
+			{
+				Name:       "payments.events",
+				Type:       "topic",
+				Durable:    true,
+				AutoDelete: false,
+			},
 
 			// ========== Dead Letter Exchanges ==========
 			{
@@ -81,6 +100,12 @@ func GetProstTopology() MessagingTopology {
 				Durable:    true,
 				AutoDelete: false,
 			},
+			{
+				Name:       "shipping.events.dlx",
+				Type:       "topic",
+				Durable:    true,
+				AutoDelete: false,
+			},
 		},
 		Queues: []QueueConfig{
 			// Products service queues
@@ -133,6 +158,52 @@ func GetProstTopology() MessagingTopology {
 				AutoDelete: false,
 				Arguments:  map[string]interface{}{},
 			},
+			// Orders service: customer segmentation refresh
+			{
+				Name:       "orders.segments.queue",
+				Durable:    true,
+				AutoDelete: false,
+				Arguments: map[string]interface{}{
+					"x-dead-letter-exchange": "orders.events.dlx",
+					"x-message-ttl":          86400000,
+				},
+			},
+
+			// Shipping service queues
+			{
+				Name:       "shipping.events.queue",
+				Durable:    true,
+				AutoDelete: false,
+				Arguments: map[string]interface{}{
+					"x-dead-letter-exchange": "shipping.events.dlx",
+					"x-message-ttl":          86400000,
+				},
+			},
+			{
+				Name:       "shipping.events.dlq",
+				Durable:    true,
+				AutoDelete: false,
+				Arguments:  map[string]interface{}{},
+			},
+
+			// Notifications service queue; holds back-in-stock events until they are fanned out
+			{
+				Name:       "notifications.back_in_stock.queue",
+				Durable:    true,
+				AutoDelete: false,
+				Arguments: map[string]interface{}{
+					"x-message-ttl": 86400000,
+				},
+			},
+			// Notifications service queue for staff low-stock alerts
+			{
+				Name:       "notifications.low_stock.queue",
+				Durable:    true,
+				AutoDelete: false,
+				Arguments: map[string]interface{}{
+					"x-message-ttl": 86400000,
+				},
+			},
 		},
 		Bindings: []BindingConfig{
 			// Products service bindings
@@ -172,6 +243,12 @@ func GetProstTopology() MessagingTopology {
 				ExchangeName: "orders.events",
 				RoutingKey:   "order.failed",
 			},
+			// Cart service - abandons the carts of deleted accounts
+			{
+				QueueName:    "cart.events.queue",
+				ExchangeName: "users.events",
+				RoutingKey:   "user.deactivated",
+			},
 			// Orders service bindings - listens to cart and order events
 			{
 				QueueName:    "orders.events.queue",
@@ -183,11 +260,78 @@ func GetProstTopology() MessagingTopology {
 				ExchangeName: "orders.events",
 				RoutingKey:   "order.*",
 			},
+			// Orders service - reservation results from the products service
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "products.events",
+				RoutingKey:   "product.stock.reserved",
+			},
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "products.events",
+				RoutingKey:   "product.stock.reservation_failed",
+			},
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "products.events",
+				RoutingKey:   "product.stock.released",
+			},
 			{
 				QueueName:    "orders.events.dlq",
 				ExchangeName: "orders.events.dlx",
 				RoutingKey:   "#",
 			},
+			{
+				QueueName:    "orders.segments.queue",
+				ExchangeName: "orders.events",
+				RoutingKey:   "order.*",
+			},
+			// Orders service - shipment progress from the shipping service
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "shipping.events",
+				RoutingKey:   "order.*",
+			},
+			{
+				QueueName:    "orders.segments.queue",
+				ExchangeName: "shipping.events",
+				RoutingKey:   "order.*",
+			},
+			// Orders service - payment results from the payment service
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "payments.events",
+				RoutingKey:   "payment.*",
+			},
+			// Orders service - holds the open orders of deleted accounts for review
+			{
+				QueueName:    "orders.events.queue",
+				ExchangeName: "users.events",
+				RoutingKey:   "user.deactivated",
+			},
+			// Shipping service bindings - creates shipments for confirmed orders
+			{
+				QueueName:    "shipping.events.queue",
+				ExchangeName: "orders.events",
+				RoutingKey:   "order.confirmed",
+			},
+			{
+				QueueName:    "shipping.events.dlq",
+				ExchangeName: "shipping.events.dlx",
+				RoutingKey:   "#",
+			},
+			// Notifications service bindings - subscribers of products that are back in stock
+			{
+				QueueName:    "notifications.back_in_stock.queue",
+				ExchangeName: "products.events",
+				RoutingKey:   "product.stock.back_in_stock",
+			},
+			// Notifications service bindings - products that fell below their low-stock threshold
+			{
+				QueueName:    "notifications.low_stock.queue",
+				ExchangeName: "products.events",
+				RoutingKey:   "product.inventory.low_stock",
+			},
 		},
 	}
 }
@@ -5,6 +5,8 @@ import (
     "encoding/json"
     "fmt"
     "log"
+    "strings"
+    "sync"
     "time"
 
     amqp "github.com/rabbitmq/amqp091-go"
@@ -14,12 +16,21 @@ import (
 type Publisher struct {
 	ch *amqp.Channel
 	exchange string
+
+	// Reliable (confirm-mode) publishing, see reliable.go
+	conn      *Connection
+	mu        sync.Mutex
+	confirmCh *amqp.Channel
+	returns   chan amqp.Return
+	reliable  ReliableConfig
 }
 
 func NewPublisher(conn *Connection, exchange string) *Publisher {
 	return &Publisher{
 		ch: conn.ch,
 		exchange: exchange,
+		conn: conn,
+		reliable: DefaultReliableConfig(),
 	}
 }
 
@@ -61,55 +72,49 @@ func (pub *Publisher) PublishEvent(ctx context.Context, event interface{}, routi
 }
 
 func (pub *Publisher) PublishProductEvent(ctx context.Context, event interface{}) error {
-	var routingKey string
-
-	switch event.(type) { //The switch itself performs the type comparison internally.
-	// case events.ProductCreatedEvent: routingKey = "product.created"
-	// case events.ProductUpdatedEvent: routingKey = "product.updated"
-	case events.StockReservedEvent: routingKey = "product.stock.reserved"
-	case events.StockReleasedEvent: routingKey = "product.stock.released"
-	default:
-		return fmt.Errorf("unknown product event type: %T", event)
+	routingKey, err := familyRoutingKey(event, "product", "product", "category")
+	if err != nil {
+		return err
 	}
 
-	return pub.PublishEvent(ctx, event,routingKey)
+	return pub.PublishEvent(ctx, event, routingKey)
 }
 
-
 func (p *Publisher) PublishOrderEvent(ctx context.Context, event interface{}) error {
-    var routingKey string
-
-    switch event.(type) {
-	case events.OrderCreatedEvent:
-        routingKey = "order.created"
-    case events.OrderPlacedEvent:
-        routingKey = "order.placed"
-    case events.OrderConfirmedEvent:
-        routingKey = "order.confirmed"
-    case events.OrderFailedEvent:
-        routingKey = "order.failed"
-    case events.OrderCancelledEvent:
-        routingKey = "order.cancelled"
-    case events.OrderShippedEvent:
-        routingKey = "order.shipped"
-    default:
-        return fmt.Errorf("unknown order event type: %T", event)
+    routingKey, err := orderRoutingKey(event)
+    if err != nil {
+        return err
     }
 
     return p.PublishEvent(ctx, event, routingKey)
 }
 
+// orderRoutingKey maps an order event to its routing key; announcements go out on the orders
+// exchange too
+func orderRoutingKey(event interface{}) (string, error) {
+    return familyRoutingKey(event, "order", "order", "announcement")
+}
+
 func (p *Publisher) PublishCartEvent(ctx context.Context, event interface{}) error {
-	var routingKey string
-
-	switch event.(type) {
-	case events.CartCheckoutInitiatedEvent:
-		routingKey = "cart.checkout.initiated"
-	case events.CartClearedEvent:
-		routingKey = "cart.cleared"
-	default:
-        return fmt.Errorf("unknown order event type: %T", event)
-    }
+	routingKey, err := familyRoutingKey(event, "cart", "cart")
+	if err != nil {
+		return err
+	}
 
 	return p.PublishEvent(ctx, event, routingKey)
 }
+
+// familyRoutingKey looks the routing key up in the event registry, and checks its first
+// segment is one of families so an event isn't sent through the wrong Publish method
+func familyRoutingKey(event interface{}, kind string, families ...string) (string, error) {
+	if registered, ok := event.(events.Event); ok {
+		if routingKey, err := events.RoutingKey(registered); err == nil {
+			for _, family := range families {
+				if strings.HasPrefix(routingKey, family+".") {
+					return routingKey, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown %s event type: %T", kind, event)
+}
@@ -0,0 +1,23 @@
+package messaging
+
+import (
+	"testing"
+
+	"github.com/sanketh-sg/prost/shared/events"
+)
+
+func TestFamilyRoutingKey(t *testing.T) {
+	if key, err := orderRoutingKey(events.AnnouncementPublishedEvent{}); err != nil || key != "announcement.published" {
+		t.Errorf("orderRoutingKey(AnnouncementPublished) = %q, %v", key, err)
+	}
+	if key, err := familyRoutingKey(events.CategoryMergedEvent{}, "product", "product", "category"); err != nil || key != "category.merged" {
+		t.Errorf("familyRoutingKey(CategoryMerged) = %q, %v", key, err)
+	}
+	// Sent through the wrong Publish method
+	if _, err := familyRoutingKey(events.OrderPlacedEvent{}, "cart", "cart"); err == nil {
+		t.Error("an order event got a cart routing key")
+	}
+	if _, err := orderRoutingKey(struct{}{}); err == nil {
+		t.Error("an unregistered value got a routing key")
+	}
+}
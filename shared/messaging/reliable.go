@@ -0,0 +1,209 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrPublishNacked is returned when the broker negatively acknowledges a message
+	ErrPublishNacked = errors.New("message nacked by broker")
+	// ErrUnroutable is returned when a mandatory message matched no queue
+	ErrUnroutable = errors.New("message unroutable")
+)
+
+// ReliableConfig controls PublishReliable retries
+type ReliableConfig struct {
+	MaxAttempts    int           // total publish attempts
+	ConfirmTimeout time.Duration // how long to wait for the broker ack
+	RetryDelay     time.Duration // base delay, doubled per attempt
+}
+
+// DefaultReliableConfig returns the settings used for critical events
+func DefaultReliableConfig() ReliableConfig {
+	return ReliableConfig{
+		MaxAttempts:    3,
+		ConfirmTimeout: 5 * time.Second,
+		RetryDelay:     200 * time.Millisecond,
+	}
+}
+
+// SetReliableConfig overrides the retry settings for PublishReliable
+func (pub *Publisher) SetReliableConfig(cfg ReliableConfig) {
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	pub.reliable = cfg
+}
+
+// PublishReliable publishes with the mandatory flag on a confirm-mode channel and
+// blocks until the broker acks. Nacked, returned (unroutable) or unconfirmed
+// messages are logged and retried with backoff.
+func (pub *Publisher) PublishReliable(ctx context.Context, event interface{}, routingKey string) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var eventID string
+	if baseEvent, ok := event.(interface{ GetEventID() string }); ok {
+		eventID = baseEvent.GetEventID()
+	}
+
+	// One reliable publish in flight at a time so returns/acks can't be mixed up
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+
+	cfg := pub.reliable
+	if cfg.MaxAttempts <= 0 {
+		cfg = DefaultReliableConfig()
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := cfg.RetryDelay * time.Duration(1<<(attempt-2))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return fmt.Errorf("failed to publish event %s: %w (last error: %v)", eventID, ctx.Err(), lastErr)
+			}
+		}
+
+		lastErr = pub.publishConfirmed(ctx, body, routingKey, cfg.ConfirmTimeout)
+		if lastErr == nil {
+			log.Printf("✓ Event confirmed: %s (routing key: %s, event_id: %s)", pub.exchange, routingKey, eventID)
+			return nil
+		}
+
+		log.Printf("⚠️  Reliable publish attempt %d/%d failed (routing key: %s, event_id: %s): %v",
+			attempt, cfg.MaxAttempts, routingKey, eventID, lastErr)
+	}
+
+	return fmt.Errorf("failed to publish event %s after %d attempts: %w", eventID, cfg.MaxAttempts, lastErr)
+}
+
+// PublishOrderEventReliable publishes an order event via PublishReliable
+func (pub *Publisher) PublishOrderEventReliable(ctx context.Context, event interface{}) error {
+	routingKey, err := orderRoutingKey(event)
+	if err != nil {
+		return err
+	}
+	return pub.PublishReliable(ctx, event, routingKey)
+}
+
+// PublishUserEventReliable publishes a user event via PublishReliable
+func (pub *Publisher) PublishUserEventReliable(ctx context.Context, event interface{}) error {
+	routingKey, err := familyRoutingKey(event, "user", "user")
+	if err != nil {
+		return err
+	}
+	return pub.PublishReliable(ctx, event, routingKey)
+}
+
+// publishConfirmed does a single mandatory publish and waits for ack/nack/return.
+// Must be called with pub.mu held.
+func (pub *Publisher) publishConfirmed(ctx context.Context, body []byte, routingKey string, timeout time.Duration) error {
+	if err := pub.ensureConfirmChannel(); err != nil {
+		return err
+	}
+
+	messageID := uuid.NewString()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	confirmation, err := pub.confirmCh.PublishWithDeferredConfirmWithContext(
+		ctx,
+		pub.exchange,
+		routingKey,
+		true,  //mandatory: broker returns the message if no queue is bound
+		false, //immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			DeliveryMode: amqp.Persistent,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no publisher confirm: %w", err)
+	}
+
+	// The broker sends basic.return before the ack, so any return is already buffered
+	if returned := pub.drainReturns(messageID); returned != nil {
+		return fmt.Errorf("%w: %s (%d %s)", ErrUnroutable, routingKey, returned.ReplyCode, returned.ReplyText)
+	}
+
+	if !acked {
+		return ErrPublishNacked
+	}
+	return nil
+}
+
+// drainReturns empties the return channel and reports the return for messageID, if any
+func (pub *Publisher) drainReturns(messageID string) *amqp.Return {
+	var match *amqp.Return
+	for {
+		select {
+		case ret, ok := <-pub.returns:
+			if !ok {
+				return match
+			}
+			if ret.MessageId == messageID {
+				r := ret
+				match = &r
+				continue
+			}
+			log.Printf("⚠️  Dropping stale returned message %s (routing key: %s)", ret.MessageId, ret.RoutingKey)
+		default:
+			return match
+		}
+	}
+}
+
+// ensureConfirmChannel opens (or reopens) the dedicated confirm-mode channel.
+// A separate channel keeps confirms from piling up for fire-and-forget publishes.
+func (pub *Publisher) ensureConfirmChannel() error {
+	if pub.confirmCh != nil && !pub.confirmCh.IsClosed() {
+		return nil
+	}
+
+	ch, err := pub.conn.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open confirm channel: %w", err)
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	pub.returns = ch.NotifyReturn(make(chan amqp.Return, 16))
+	pub.confirmCh = ch
+	log.Printf("✓ Publisher confirms enabled on %s", pub.exchange)
+	return nil
+}
+
+// Close closes the confirm channel, if one was opened
+func (pub *Publisher) Close() error {
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+
+	if pub.confirmCh == nil || pub.confirmCh.IsClosed() {
+		return nil
+	}
+	return pub.confirmCh.Close()
+}
@@ -1,12 +1,16 @@
 package messaging
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
     "fmt"
     "log"
+    "sync"
     "time"
 
+    "github.com/google/uuid"
     amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sanketh-sg/prost/shared/clock"
 	"github.com/sanketh-sg/prost/shared/events"
 )
 
@@ -17,6 +21,16 @@ type MessageHandler func(message []byte) error
 type Subscriber struct {
 	ch *amqp.Channel
 	queueName string
+	conn      *Connection
+	clock     clock.Clock
+	heartbeat *heartbeat // progress seen by the Watchdog
+
+	// Why: on shutdown the consumer is cancelled by tag and the handler running at that
+	// moment is waited for, so no message is handled after the DB connection closes.
+	consumerTag string
+	mu          sync.Mutex
+	draining    bool
+	inFlight    sync.WaitGroup
 }
 
 // NewSubscriber creates a new event subscriber
@@ -24,14 +38,19 @@ func NewSubscriber(conn *Connection, queueName string) *Subscriber {
 	return &Subscriber{
 		ch: conn.GetChannel(),
 		queueName: queueName,
+		conn:      conn,
+		clock:     clock.New(),
+		heartbeat: &heartbeat{},
+		// Unique per channel; several subscribers share the connection's channel
+		consumerTag: queueName + "." + uuid.NewString()[:8],
 	}
 }
 
 // Subscribe starts consuming messages from a queue
 func (s *Subscriber) Subscribe(handler MessageHandler) error {
     deliveries, err := s.ch.Consume(
-        s.queueName, // queue
-        "",          // consumer
+        s.queueName,   // queue
+        s.consumerTag, // consumer
         false,       // auto-ack (we'll manually acknowledge)
         false,       // exclusive
         false,       // no-local
@@ -40,14 +59,21 @@ func (s *Subscriber) Subscribe(handler MessageHandler) error {
     )
 
     if err != nil {
+        s.heartbeat.failed(err)
         return fmt.Errorf("failed to consume from queue %s: %w", s.queueName, err)
     }
 
     log.Printf("Listening on queue: %s", s.queueName)
+    s.heartbeat.started(s.clock.Now())
+    defer s.heartbeat.finished()
 
     // Process incoming messages
     for delivery := range deliveries {
+        if !s.begin(delivery) {
+            continue
+        }
         log.Printf(" Message received from %s", s.queueName)
+        s.heartbeat.received(s.clock.Now())
 
         // Call the handler
         err := handler(delivery.Body)
@@ -61,6 +87,8 @@ func (s *Subscriber) Subscribe(handler MessageHandler) error {
             delivery.Ack(false)
             log.Printf(" Message processed and acknowledged")
         }
+        s.heartbeat.settled(s.clock.Now())
+        s.inFlight.Done()
     }
 
     return nil
@@ -70,7 +98,7 @@ func (s *Subscriber) Subscribe(handler MessageHandler) error {
 func (s *Subscriber) SubscribeWithRetry(handler MessageHandler, maxRetries int) error {
 	deliveries, err := s.ch.Consume(
 		s.queueName,
-		"",
+		s.consumerTag,
 		false,
 		false,
 		false,
@@ -79,11 +107,19 @@ func (s *Subscriber) SubscribeWithRetry(handler MessageHandler, maxRetries int)
 	)
 
 	if err != nil {
+		s.heartbeat.failed(err)
 		return fmt.Errorf("failed to consume from queue: %s: %w", s.queueName, err)
 	}
 
+	s.heartbeat.started(s.clock.Now())
+	defer s.heartbeat.finished()
+
 	for delivery := range deliveries{
+		if !s.begin(delivery) {
+			continue
+		}
 		log.Printf(" Message received from %s", s.queueName)
+		s.heartbeat.received(s.clock.Now())
 
 		var lastErr error
 		for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -104,20 +140,68 @@ func (s *Subscriber) SubscribeWithRetry(handler MessageHandler, maxRetries int)
 			delivery.Ack(false)
 			log.Printf("Message delivered successfully")
 		}
+		s.heartbeat.settled(s.clock.Now())
+		s.inFlight.Done()
 	}
 	return nil
 }
 
+// begin counts a delivery as in flight. Once Drain has started it requeues the delivery
+// instead, for another instance to handle, and returns false.
+func (s *Subscriber) begin(delivery amqp.Delivery) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		delivery.Nack(false, true)
+		return false
+	}
+	s.inFlight.Add(1)
+	return true
+}
 
-// ParseEvent parses JSON message into an event
-func (s *Subscriber) ParseEvent(data []byte) (interface{}, error) {
-    var baseEvent struct {
-        EventType string `json:"event_type"`
-    }
+// Drain stops consuming and waits until the message being handled is settled. It returns
+// an error if a handler is still running when ctx is done.
+func (s *Subscriber) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
 
-    if err := json.Unmarshal(data, &baseEvent); err != nil {
-        return nil, fmt.Errorf("failed to unmarshal base event: %w", err)
-    }
+	if s.ch != nil {
+		if err := s.ch.Cancel(s.consumerTag, false); err != nil {
+			log.Printf("⚠️  Failed to cancel consumer on %s: %v", s.queueName, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Printf("✓ Stopped consuming from %s", s.queueName)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("queue %s: handler still running: %w", s.queueName, ctx.Err())
+	}
+}
+
+// DrainSubscribers drains the subscribers together under one deadline
+func DrainSubscribers(ctx context.Context, subscribers ...*Subscriber) error {
+	errs := make([]error, len(subscribers))
+	var wg sync.WaitGroup
+	for i, s := range subscribers {
+		wg.Add(1)
+		go func(i int, s *Subscriber) {
+			defer wg.Done()
+			errs[i] = s.Drain(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-    return events.UnmarshalEvent(data, baseEvent.EventType)
+// ParseEvent decodes a message into the event type registered for its event_type
+func (s *Subscriber) ParseEvent(data []byte) (events.Event, error) {
+    return events.Decode(data)
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestDrainWaitsForInFlightHandler(t *testing.T) {
+	s := NewSubscriber(&Connection{}, "orders.events.queue")
+	if !s.begin(amqp.Delivery{}) {
+		t.Fatal("begin refused a delivery before Drain")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain with a running handler = %v, want the deadline", err)
+	}
+
+	if s.begin(amqp.Delivery{}) {
+		t.Error("begin accepted a delivery while draining, want it requeued")
+	}
+
+	s.inFlight.Done()
+	if err := DrainSubscribers(context.Background(), s); err != nil {
+		t.Errorf("Drain after the handler finished = %v, want nil", err)
+	}
+}
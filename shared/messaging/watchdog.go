@@ -0,0 +1,301 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/clock"
+)
+
+// progress is a point-in-time view of a subscriber loop
+type progress struct {
+	consuming     bool
+	stopped       bool
+	lastProgress  time.Time // consume start or last ack/nack
+	inFlightSince time.Time // zero when no message is being handled
+	processed     int64
+	failure       string // why the consume loop couldn't start
+}
+
+// heartbeat records subscriber loop progress for the watchdog
+type heartbeat struct {
+	mu sync.Mutex
+	progress
+}
+
+func (hb *heartbeat) started(now time.Time) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	hb.consuming = true
+	hb.stopped = false
+	hb.lastProgress = now
+}
+
+func (hb *heartbeat) received(now time.Time) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	hb.inFlightSince = now
+}
+
+func (hb *heartbeat) settled(now time.Time) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	hb.inFlightSince = time.Time{}
+	hb.lastProgress = now
+	hb.processed++
+}
+
+func (hb *heartbeat) failed(err error) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	hb.consuming = false
+	hb.stopped = true
+	hb.failure = err.Error()
+}
+
+func (hb *heartbeat) finished() {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	hb.consuming = false
+	hb.stopped = true
+}
+
+func (hb *heartbeat) snapshot() progress {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	return hb.progress
+}
+
+// WatchdogConfig controls how often subscribers are checked and when they count as stalled
+type WatchdogConfig struct {
+	Interval   time.Duration // time between checks
+	StallAfter time.Duration // no ack/nack for this long while work is waiting = stalled
+}
+
+// DefaultWatchdogConfig returns the settings used by the services
+func DefaultWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		Interval:   15 * time.Second,
+		StallAfter: 2 * time.Minute,
+	}
+}
+
+// SubscriberStatus is the watchdog's view of one subscriber
+type SubscriberStatus struct {
+	Queue             string    `json:"queue"`
+	Ready             bool      `json:"ready"`
+	Reason            string    `json:"reason,omitempty"`
+	Connected         bool      `json:"connected"`   // the subscriber's connection and channel are open
+	Consuming         bool      `json:"consuming"`   // the consume loop is running
+	QueueDepth        int       `json:"queue_depth"` // messages ready; -1 if the queue could not be inspected
+	Consumers         int       `json:"consumers"`
+	Processed         int64     `json:"processed"`
+	LastProgressAt    time.Time `json:"last_progress_at"`
+	LastAckAgeSeconds float64   `json:"last_ack_age_seconds"` // since the last ack/nack, or consume start
+	InFlightSeconds   float64   `json:"in_flight_seconds"`    // how long the current message has been in the handler
+	CheckedAt         time.Time `json:"checked_at"`
+}
+
+// queueInspector returns ready messages and consumer count for a queue
+type queueInspector func(queue string) (messages, consumers int, err error)
+
+// connectionProbe reports whether a subscriber's connection and channel are open
+type connectionProbe func(sub *Subscriber) bool
+
+// Watchdog detects subscribers that are alive but not making progress, e.g. a
+// handler blocked on a lock or a consumer whose channel silently stopped delivering.
+// Why: the process stays up and /health stays green while the saga pipeline stalls.
+type Watchdog struct {
+	subscribers []*Subscriber
+	config      WatchdogConfig
+	clock       clock.Clock
+	inspect     queueInspector
+	connected   connectionProbe
+
+	mu       sync.RWMutex
+	statuses map[string]SubscriberStatus
+}
+
+// NewWatchdog creates a watchdog for the given subscribers
+func NewWatchdog(cfg WatchdogConfig, clk clock.Clock, subscribers ...*Subscriber) *Watchdog {
+	w := &Watchdog{
+		subscribers: subscribers,
+		config:      cfg,
+		clock:       clk,
+		statuses:    make(map[string]SubscriberStatus),
+	}
+	w.inspect = w.inspectQueue
+	w.connected = subscriberConnected
+	return w
+}
+
+// Start launches the check loop; it stops when ctx is cancelled.
+func (w *Watchdog) Start(ctx context.Context) <-chan struct{} {
+	return clock.Every(ctx, w.clock, w.config.Interval, false, func(context.Context) { w.Check() })
+}
+
+// Check evaluates every subscriber once and logs diagnostics on state changes
+func (w *Watchdog) Check() {
+	w.check(true)
+}
+
+// check evaluates every subscriber. Without inspect the queue depth and consumer count of the
+// last check are reused, so a readiness probe sees a dead consumer without a broker round trip.
+func (w *Watchdog) check(inspect bool) {
+	now := w.clock.Now()
+
+	for _, sub := range w.subscribers {
+		w.mu.RLock()
+		previous, seen := w.statuses[sub.queueName]
+		w.mu.RUnlock()
+
+		depth, consumers := -1, 0
+		if inspect {
+			var err error
+			depth, consumers, err = w.inspect(sub.queueName)
+			if err != nil {
+				log.Printf("⚠️  Watchdog could not inspect %s: %v", sub.queueName, err)
+				depth = -1
+			}
+		} else if seen {
+			depth, consumers = previous.QueueDepth, previous.Consumers
+		}
+
+		status := evaluateSubscriber(sub.queueName, sub.heartbeat.snapshot(), w.connected(sub), depth, consumers, now, w.config.StallAfter)
+
+		w.mu.Lock()
+		w.statuses[sub.queueName] = status
+		w.mu.Unlock()
+
+		switch {
+		case !status.Ready && (!seen || previous.Ready):
+			log.Printf("❌ Subscriber on %s stalled: %s (depth: %d, consumers: %d, last progress: %s ago, in flight: %.0fs, processed: %d)",
+				status.Queue, status.Reason, status.QueueDepth, status.Consumers,
+				now.Sub(status.LastProgressAt).Round(time.Second), status.InFlightSeconds, status.Processed)
+		case status.Ready && seen && !previous.Ready:
+			log.Printf("✓ Subscriber on %s recovered", status.Queue)
+		}
+	}
+}
+
+// evaluateSubscriber decides whether a subscriber is progressing
+func evaluateSubscriber(queue string, hb progress, connected bool, depth, consumers int, now time.Time, stallAfter time.Duration) SubscriberStatus {
+	status := SubscriberStatus{
+		Queue:          queue,
+		Ready:          true,
+		Connected:      connected,
+		Consuming:      hb.consuming,
+		QueueDepth:     depth,
+		Consumers:      consumers,
+		Processed:      hb.processed,
+		LastProgressAt: hb.lastProgress,
+		CheckedAt:      now,
+	}
+	if !hb.lastProgress.IsZero() {
+		status.LastAckAgeSeconds = now.Sub(hb.lastProgress).Seconds()
+	}
+	if !hb.inFlightSince.IsZero() {
+		status.InFlightSeconds = now.Sub(hb.inFlightSince).Seconds()
+	}
+
+	switch {
+	case hb.failure != "":
+		status.Ready, status.Reason = false, "consumer failed to start: "+hb.failure
+	case !connected:
+		status.Ready, status.Reason = false, "connection closed"
+	case hb.stopped:
+		status.Ready, status.Reason = false, "consumer stopped"
+	case !hb.consuming:
+		status.Ready, status.Reason = false, "consumer not started"
+	case !hb.inFlightSince.IsZero() && now.Sub(hb.inFlightSince) > stallAfter:
+		status.Ready, status.Reason = false, fmt.Sprintf("handler running for more than %s", stallAfter)
+	case depth > 0 && now.Sub(hb.lastProgress) > stallAfter:
+		status.Ready, status.Reason = false, fmt.Sprintf("%d message(s) waiting and no progress for more than %s", depth, stallAfter)
+	}
+
+	return status
+}
+
+// Ready reports whether every subscriber passed its last check.
+// Subscribers not checked yet count as ready so startup isn't flagged.
+func (w *Watchdog) Ready() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, status := range w.statuses {
+		if !status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Statuses returns the last check result for each subscriber
+func (w *Watchdog) Statuses() []SubscriberStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	statuses := make([]SubscriberStatus, 0, len(w.subscribers))
+	for _, sub := range w.subscribers {
+		if status, ok := w.statuses[sub.queueName]; ok {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// ServeHTTP serves the readiness probe: 200 when all subscribers progress, 503 otherwise.
+// Connection and consume loop state are read live; the stall checks are from the last Check.
+func (w *Watchdog) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.check(false)
+
+	code := http.StatusOK
+	state := "ready"
+	if !w.Ready() {
+		code = http.StatusServiceUnavailable
+		state = "not_ready"
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	json.NewEncoder(rw).Encode(map[string]interface{}{
+		"status":      state,
+		"subscribers": w.Statuses(),
+	})
+}
+
+// subscriberConnected reports whether the subscriber's connection and consume channel are open
+func subscriberConnected(sub *Subscriber) bool {
+	if sub.conn == nil || sub.conn.conn == nil || sub.conn.conn.IsClosed() {
+		return false
+	}
+	return sub.ch != nil && !sub.ch.IsClosed()
+}
+
+// inspectQueue passively declares the queue on a short-lived channel.
+// Why: a failed passive declare closes its channel, so never use the consumer's.
+func (w *Watchdog) inspectQueue(queue string) (int, int, error) {
+	for _, sub := range w.subscribers {
+		if sub.queueName != queue || sub.conn == nil {
+			continue
+		}
+
+		ch, err := sub.conn.conn.Channel()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to open inspect channel: %w", err)
+		}
+		defer ch.Close()
+
+		q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to inspect queue: %w", err)
+		}
+		return q.Messages, q.Consumers, nil
+	}
+	return 0, 0, fmt.Errorf("no connection for queue %s", queue)
+}
@@ -0,0 +1,132 @@
+package messaging
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/clock"
+)
+
+func newTestWatchdog(clk *clock.Fake, depth *int) (*Watchdog, *Subscriber) {
+	sub := &Subscriber{queueName: "orders.events.queue", clock: clk, heartbeat: &heartbeat{}}
+	w := NewWatchdog(WatchdogConfig{Interval: time.Second, StallAfter: time.Minute}, clk, sub)
+	w.inspect = func(queue string) (int, int, error) { return *depth, 1, nil }
+	w.connected = func(*Subscriber) bool { return true }
+	return w, sub
+}
+
+func TestWatchdog_FlagsWaitingMessagesWithoutProgress(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	depth := 0
+	w, sub := newTestWatchdog(clk, &depth)
+
+	sub.heartbeat.started(clk.Now())
+	w.Check()
+	if !w.Ready() {
+		t.Fatal("expected ready right after consume starts")
+	}
+
+	// Idle with an empty queue is healthy no matter how long
+	clk.Advance(10 * time.Minute)
+	w.Check()
+	if !w.Ready() {
+		t.Fatal("expected idle subscriber to stay ready")
+	}
+
+	// Work is waiting but nothing was acked for longer than StallAfter
+	depth = 5
+	w.Check()
+	if w.Ready() {
+		t.Fatal("expected stalled subscriber to be not ready")
+	}
+
+	sub.heartbeat.received(clk.Now())
+	sub.heartbeat.settled(clk.Now())
+	w.Check()
+	if !w.Ready() {
+		t.Fatal("expected subscriber to recover after progress")
+	}
+}
+
+func TestWatchdog_FlagsStuckHandler(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	depth := 0
+	w, sub := newTestWatchdog(clk, &depth)
+
+	sub.heartbeat.started(clk.Now())
+	sub.heartbeat.received(clk.Now())
+	clk.Advance(2 * time.Minute)
+	w.Check()
+
+	statuses := w.Statuses()
+	if w.Ready() || len(statuses) != 1 || statuses[0].InFlightSeconds != 120 {
+		t.Fatalf("expected stuck handler to be flagged, got %+v", statuses)
+	}
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestWatchdog_FlagsStoppedConsumer(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	depth := 0
+	w, sub := newTestWatchdog(clk, &depth)
+
+	sub.heartbeat.started(clk.Now())
+	sub.heartbeat.finished()
+	w.Check()
+
+	if w.Ready() || w.Statuses()[0].Reason != "consumer stopped" {
+		t.Fatalf("expected stopped consumer to be flagged, got %+v", w.Statuses())
+	}
+}
+
+func TestWatchdog_ProbeSeesDeadConsumerBeforeNextCheck(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	depth := 3
+	w, sub := newTestWatchdog(clk, &depth)
+
+	sub.heartbeat.started(clk.Now())
+	w.Check()
+	clk.Advance(5 * time.Second)
+	sub.heartbeat.finished()
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 right after the consume loop exited, got %d", rec.Code)
+	}
+
+	status := w.Statuses()[0]
+	if status.Consuming || status.QueueDepth != 3 || status.LastAckAgeSeconds != 5 {
+		t.Fatalf("expected live consumer state with the last queue depth, got %+v", status)
+	}
+}
+
+func TestWatchdog_FlagsClosedConnectionAndFailedConsume(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	depth := 0
+	w, sub := newTestWatchdog(clk, &depth)
+
+	sub.heartbeat.started(clk.Now())
+	w.connected = func(*Subscriber) bool { return false }
+	w.Check()
+	if status := w.Statuses()[0]; w.Ready() || status.Connected || status.Reason != "connection closed" {
+		t.Fatalf("expected closed connection to be flagged, got %+v", status)
+	}
+
+	w.connected = func(*Subscriber) bool { return true }
+	sub.heartbeat.failed(errors.New("NOT_FOUND - no queue"))
+	w.Check()
+	if status := w.Statuses()[0]; w.Ready() || !strings.Contains(status.Reason, "no queue") {
+		t.Fatalf("expected failed consume to be flagged with its error, got %+v", status)
+	}
+}
+
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Connection pool metrics
+// Why: a pool that is too small shows up as request latency long before it shows up as
+// errors. wait_count/wait_seconds growing means requests queue for a connection.
+
+// DBStatsSource is anything that reports pool stats, e.g. *db.Connection or *sql.DB
+type DBStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// RegisterDBStats exposes the pool stats of source on /metrics, labelled with db (e.g. the schema)
+func RegisterDBStats(db string, source DBStatsSource) {
+	registry.MustRegister(newDBStatsCollector(db, source))
+}
+
+type dbStatsCollector struct {
+	source DBStatsSource
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitSeconds       *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newDBStatsCollector(db string, source DBStatsSource) *dbStatsCollector {
+	labels := prometheus.Labels{"db": db}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("prost_db_"+name, help, nil, labels)
+	}
+
+	return &dbStatsCollector{
+		source:            source,
+		maxOpen:           desc("max_open_connections", "Maximum open connections allowed."),
+		open:              desc("open_connections", "Open connections, in use and idle."),
+		inUse:             desc("in_use_connections", "Connections currently in use."),
+		idle:              desc("idle_connections", "Idle connections."),
+		waitCount:         desc("wait_count_total", "Times a query waited for a free connection."),
+		waitSeconds:       desc("wait_seconds_total", "Time spent waiting for a free connection."),
+		maxIdleClosed:     desc("max_idle_closed_total", "Connections closed because of the max idle limit."),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Connections closed because of the max idle time."),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Connections closed because of the max lifetime."),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitSeconds
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.source.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitSeconds, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
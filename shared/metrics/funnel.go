@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Conversion funnel counters
+// Why: logs tell us what happened to one order; these tell us how the funnel as a
+// whole is doing (carts → checkouts → completed/failed sagas → revenue). Each increment
+// carries a trace_id exemplar (request ID or saga correlation ID) so a spike on a
+// dashboard links straight to the requests and saga logs behind it.
+
+// registry holds this process's metrics; each service exposes it on GET /metrics
+var registry = prometheus.NewRegistry()
+
+var (
+	// CartsCreated counts new carts (cart service)
+	CartsCreated = newCounter("prost_carts_created_total", "Carts created.")
+	// CheckoutsInitiated counts checkouts that started an order saga (cart service)
+	CheckoutsInitiated = newCounter("prost_checkouts_initiated_total", "Checkouts that started an order saga.")
+	// SagasCompleted counts order sagas that reached confirmation (orders service)
+	SagasCompleted = newCounter("prost_sagas_completed_total", "Order sagas completed (order confirmed).")
+	// SagasFailed counts failed order sagas by failure category (orders service)
+	SagasFailed = newCounterVec("prost_sagas_failed_total", "Order sagas failed, by reason category.", "reason")
+	// RevenueConfirmed sums the totals of confirmed orders (orders service)
+	RevenueConfirmed = newCounter("prost_revenue_confirmed_total", "Total value of confirmed orders.")
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+func newCounter(name, help string) prometheus.Counter {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: help})
+	registry.MustRegister(counter)
+	return counter
+}
+
+func newCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	registry.MustRegister(counter)
+	return counter
+}
+
+// Handler serves the metrics in OpenMetrics format, which is what carries exemplars
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// maxTraceIDLength keeps exemplar labels under Prometheus' 128 rune limit (which panics)
+const maxTraceIDLength = 64
+
+// Add adds v to the counter with a trace_id exemplar; an empty traceID adds without one
+func Add(counter prometheus.Counter, v float64, traceID string) {
+	if len(traceID) > maxTraceIDLength {
+		traceID = traceID[:maxTraceIDLength]
+	}
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && traceID != "" && utf8.ValidString(traceID) {
+		adder.AddWithExemplar(v, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	counter.Add(v)
+}
+
+// Inc adds one to the counter with a trace_id exemplar
+func Inc(counter prometheus.Counter, traceID string) {
+	Add(counter, 1, traceID)
+}
+
+// TraceIDFromRequest returns the trace ID of a W3C traceparent header, falling back to
+// the gateway's X-Request-ID
+func TraceIDFromRequest(r *http.Request) string {
+	// traceparent: version-traceid-spanid-flags
+	if parts := strings.Split(r.Header.Get("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return r.Header.Get("X-Request-ID")
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestTraceIDFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	if got := TraceIDFromRequest(req); got != "req-1" {
+		t.Errorf("without traceparent got %q, want request ID", got)
+	}
+
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := TraceIDFromRequest(req); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("with traceparent got %q", got)
+	}
+}
+
+func TestAddRecordsExemplar(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "test"})
+
+	Inc(counter, "corr-1")
+	// Oversized IDs are truncated instead of panicking
+	Add(counter, 2, strings.Repeat("x", 200))
+
+	var m dto.Metric
+	if err := counter.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetValue(); got != 3 {
+		t.Errorf("value = %v, want 3", got)
+	}
+	exemplar := m.GetCounter().GetExemplar()
+	if exemplar == nil || len(exemplar.GetLabel()) != 1 || len(exemplar.GetLabel()[0].GetValue()) != maxTraceIDLength {
+		t.Errorf("unexpected exemplar: %v", exemplar)
+	}
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Password hashing metrics (users service)
+// Why: the bcrypt cost and argon2id parameters trade login latency for resistance to
+// cracking; the histogram shows what a setting costs in production before it is raised.
+
+var (
+	// PasswordHashDuration times password hashing and verification, by algorithm and operation (hash, verify)
+	PasswordHashDuration = newHistogramVec("prost_password_hash_duration_seconds",
+		"Time to hash or verify a password, by algorithm and operation.",
+		prometheus.ExponentialBuckets(0.005, 2, 10), "algorithm", "operation")
+	// PasswordsRehashed counts stored hashes upgraded at login, by the algorithm they were upgraded to
+	PasswordsRehashed = newCounterVec("prost_passwords_rehashed_total",
+		"Password hashes upgraded at login to the configured algorithm and parameters.", "algorithm")
+)
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	registry.MustRegister(histogram)
+	return histogram
+}
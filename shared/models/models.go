@@ -40,6 +40,7 @@ type CartItem struct {
     ID        int64     `json:"id"`
     CartID    string    `json:"cart_id"`
     ProductID int64     `json:"product_id"`
+    VariantID *int64    `json:"variant_id,omitempty"` // nil for products without variants
     Quantity  int       `json:"quantity"`
     Price     float64   `json:"price"` 
     CreatedAt time.Time `json:"created_at"`
@@ -75,11 +76,33 @@ type OrderItem struct {
     ID        int64     `json:"id"`
     OrderID   int64     `json:"order_id"`
     ProductID int64     `json:"product_id"`
+    VariantID *int64    `json:"variant_id,omitempty"` // nil for products without variants
     Quantity  int       `json:"quantity"`
     Price     float64   `json:"price"` // Price at time of purchase
     CreatedAt time.Time `json:"created_at"`
 }
 
+// TaxLine is one tax charged on an order, e.g. state or county sales tax
+type TaxLine struct {
+    Name   string  `json:"name"`
+    Region string  `json:"region,omitempty"` // the region whose rate applied
+    Rate   float64 `json:"rate"`             // percent
+    Amount float64 `json:"amount"`
+}
+
+// Address is the shipping address an order goes to, copied from the user's saved address at checkout
+type Address struct {
+    ID            string `json:"id,omitempty"` // the saved address it was copied from
+    RecipientName string `json:"recipient_name"`
+    Line1         string `json:"line1"`
+    Line2         string `json:"line2,omitempty"`
+    City          string `json:"city"`
+    Region        string `json:"region,omitempty"`
+    PostalCode    string `json:"postal_code"`
+    Country       string `json:"country"` // ISO 3166-1 alpha-2
+    Phone         string `json:"phone,omitempty"`
+}
+
 // SagaState tracks distributed transaction state
 type SagaState struct {
     ID              string    `json:"id"`
@@ -0,0 +1,145 @@
+// Package pagination implements keyset (cursor) pagination for list endpoints.
+//
+// Why: OFFSET pages shift when rows are added while a client scrolls, and get slower the
+// further it scrolls. A keyset page continues strictly after the last row the client saw,
+// ordered by a sort key with the row ID as tiebreaker, so it's stable and uses the index.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidCursor is returned for an after cursor this package didn't encode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page. It's opaque to clients.
+type Cursor struct {
+	Sort string `json:"s,omitempty"` // the order it was taken in, for endpoints with several
+	Key  string `json:"k"`           // sort key of the row as text, cast back by Postgres
+	ID   int64  `json:"i"`
+}
+
+// TimeKey formats a timestamp sort key with the microsecond precision Postgres keeps
+func TimeKey(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.999999Z07:00")
+}
+
+// NumberKey formats a numeric sort key
+func NumberKey(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Key == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Page is a requested page: the First rows after the After cursor, or from the start
+type Page struct {
+	First int
+	After *Cursor
+}
+
+// ParsePage reads the first and after query params; an empty first is defaultFirst and a
+// larger one is capped at maxFirst
+func ParsePage(first, after string, defaultFirst, maxFirst int) (Page, error) {
+	page := Page{First: defaultFirst}
+
+	if first != "" {
+		n, err := strconv.Atoi(first)
+		if err != nil || n < 1 {
+			return page, fmt.Errorf("first must be a positive integer")
+		}
+		if n > maxFirst {
+			n = maxFirst
+		}
+		page.First = n
+	}
+
+	if after != "" {
+		cursor, err := DecodeCursor(after)
+		if err != nil {
+			return page, err
+		}
+		page.After = &cursor
+	}
+
+	return page, nil
+}
+
+// Limit is the number of rows to fetch: one more than First tells whether a next page exists
+func (p Page) Limit() int {
+	return p.First + 1
+}
+
+// Condition returns the WHERE condition selecting the rows after the cursor, for rows ordered
+// by keyColumn then idColumn (both descending when desc), with its arguments numbered from
+// next. On the first page it's TRUE with no arguments.
+func (p Page) Condition(keyColumn, idColumn string, desc bool, next int) (string, []interface{}) {
+	if p.After == nil {
+		return "TRUE", nil
+	}
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s, %s) %s ($%d, $%d)", keyColumn, idColumn, op, next, next+1), []interface{}{p.After.Key, p.After.ID}
+}
+
+// PageInfo tells a client where the page ends and whether to keep scrolling
+type PageInfo struct {
+	HasNextPage     bool   `json:"has_next_page"`
+	HasPreviousPage bool   `json:"has_previous_page"` // the page started after a cursor
+	StartCursor     string `json:"start_cursor,omitempty"`
+	EndCursor       string `json:"end_cursor,omitempty"` // pass as after for the next page
+}
+
+// Edge is a row with its cursor
+type Edge[T any] struct {
+	Cursor string `json:"cursor"`
+	Node   T      `json:"node"`
+}
+
+// Connection is one page of rows in the shape of a Relay connection
+type Connection[T any] struct {
+	Edges    []Edge[T] `json:"edges"`
+	PageInfo PageInfo  `json:"page_info"`
+}
+
+// NewConnection builds the page from rows fetched with p.Limit(); cursor gives a row's cursor
+func NewConnection[T any](p Page, rows []T, cursor func(T) Cursor) Connection[T] {
+	conn := Connection[T]{
+		Edges:    []Edge[T]{},
+		PageInfo: PageInfo{HasPreviousPage: p.After != nil},
+	}
+
+	if len(rows) > p.First {
+		rows = rows[:p.First]
+		conn.PageInfo.HasNextPage = true
+	}
+	for _, row := range rows {
+		conn.Edges = append(conn.Edges, Edge[T]{Cursor: cursor(row).Encode(), Node: row})
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn
+}
@@ -0,0 +1,100 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	cursor := Cursor{Sort: "created_at_desc", Key: TimeKey(at), ID: 42}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != cursor {
+		t.Fatalf("expected %+v, got %+v", cursor, decoded)
+	}
+	if decoded.Key != "2025-03-01T12:30:00.123456Z" {
+		t.Fatalf("unexpected time key %q", decoded.Key)
+	}
+
+	for _, bad := range []string{"not base64!", "e30", Cursor{ID: 1}.Encode()} {
+		if _, err := DecodeCursor(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q): expected ErrInvalidCursor, got %v", bad, err)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	page, err := ParsePage("", "", 20, 100)
+	if err != nil || page.First != 20 || page.After != nil {
+		t.Fatalf("unexpected default page %+v (%v)", page, err)
+	}
+
+	page, err = ParsePage("500", Cursor{Key: NumberKey(19.99), ID: 7}.Encode(), 20, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.First != 100 || page.Limit() != 101 {
+		t.Fatalf("expected first capped at 100, got %+v", page)
+	}
+	if page.After == nil || page.After.Key != "19.99" || page.After.ID != 7 {
+		t.Fatalf("unexpected cursor %+v", page.After)
+	}
+
+	if _, err := ParsePage("0", "", 20, 100); err == nil {
+		t.Fatal("expected an error for first=0")
+	}
+	if _, err := ParsePage("", "garbage", 20, 100); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestPageCondition(t *testing.T) {
+	if cond, args := (Page{First: 10}).Condition("created_at", "id", true, 1); cond != "TRUE" || args != nil {
+		t.Fatalf("expected no condition on the first page, got %q %v", cond, args)
+	}
+
+	page := Page{First: 10, After: &Cursor{Key: "10.5", ID: 3}}
+	cond, args := page.Condition("total", "id", false, 4)
+	if cond != "(total, id) > ($4, $5)" || len(args) != 2 || args[0] != "10.5" || args[1] != int64(3) {
+		t.Fatalf("unexpected ascending condition %q %v", cond, args)
+	}
+	if cond, _ := page.Condition("p.created_at", "p.id", true, 1); cond != "(p.created_at, p.id) < ($1, $2)" {
+		t.Fatalf("unexpected descending condition %q", cond)
+	}
+}
+
+func TestNewConnection(t *testing.T) {
+	cursor := func(id int64) Cursor { return Cursor{Key: NumberKey(float64(id)), ID: id} }
+
+	// Arrange: fetched one more row than asked for
+	conn := NewConnection(Page{First: 2}, []int64{1, 2, 3}, cursor)
+
+	// Assert
+	if len(conn.Edges) != 2 || conn.Edges[1].Node != 2 {
+		t.Fatalf("expected the first 2 rows, got %+v", conn.Edges)
+	}
+	if !conn.PageInfo.HasNextPage || conn.PageInfo.HasPreviousPage {
+		t.Fatalf("unexpected page info %+v", conn.PageInfo)
+	}
+	if conn.PageInfo.EndCursor != cursor(2).Encode() || conn.PageInfo.StartCursor != cursor(1).Encode() {
+		t.Fatalf("unexpected cursors %+v", conn.PageInfo)
+	}
+
+	// The last page, continued from a cursor
+	after := cursor(2)
+	last := NewConnection(Page{First: 2, After: &after}, []int64{3}, cursor)
+	if last.PageInfo.HasNextPage || !last.PageInfo.HasPreviousPage || len(last.Edges) != 1 {
+		t.Fatalf("unexpected last page %+v", last)
+	}
+
+	// An empty page still has an edges array
+	empty := NewConnection(Page{First: 2}, nil, cursor)
+	if empty.Edges == nil || empty.PageInfo.EndCursor != "" {
+		t.Fatalf("unexpected empty page %+v", empty)
+	}
+}
@@ -0,0 +1,207 @@
+// Package rbac decides which roles may call which service routes, from the policy file every
+// service and the gateway load at startup.
+//
+// Why: role checks were spread over each service's admin middleware and the gateway resolvers,
+// so there was no one place to review who can do what. policy.yaml is that place; this package
+// reads it for the services and gateway/rbac.go mirrors it for the GraphQL fields.
+package rbac
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// AnyRole in a rule's roles lets in any authenticated caller, whatever its role claim
+const AnyRole = "*"
+
+//go:embed policy.yaml
+var defaultPolicy []byte
+
+// Policy is a parsed policy file
+type Policy struct {
+	Roles    []string          `yaml:"roles"`
+	Services map[string][]Rule `yaml:"services"`
+	GraphQL  []FieldRule       `yaml:"graphql"`
+}
+
+// Rule lets roles call the routes matching Route ("METHOD /path")
+type Rule struct {
+	Route string   `yaml:"route"`
+	Roles []string `yaml:"roles"`
+
+	method string
+	path   string
+	prefix bool // Route ended in "/*"
+}
+
+// FieldRule lets roles resolve a GraphQL root field ("Query.name" or "Mutation.name"); the
+// gateway enforces these
+type FieldRule struct {
+	Field string   `yaml:"field"`
+	Roles []string `yaml:"roles"`
+}
+
+// Load reads the file named by RBAC_POLICY_FILE, or the policy.yaml built into this package
+func Load() (*Policy, error) {
+	path := os.Getenv("RBAC_POLICY_FILE")
+	if path == "" {
+		return Parse(defaultPolicy)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RBAC policy: %w", err)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse decodes and checks a policy. Unknown keys, roles the policy doesn't declare and
+// malformed routes or fields are errors, so a typo can't quietly open or close a route.
+func Parse(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	policy := &Policy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("failed to parse RBAC policy: %w", err)
+	}
+	if len(policy.Roles) == 0 {
+		return nil, errors.New("RBAC policy declares no roles")
+	}
+
+	for service, rules := range policy.Services {
+		for i := range rules {
+			rule := &rules[i]
+			if err := rule.parse(); err != nil {
+				return nil, fmt.Errorf("service %s: %w", service, err)
+			}
+			if err := policy.checkRoles(rule.Roles); err != nil {
+				return nil, fmt.Errorf("service %s, %s: %w", service, rule.Route, err)
+			}
+		}
+	}
+
+	for _, field := range policy.GraphQL {
+		typeName, name, ok := strings.Cut(field.Field, ".")
+		if !ok || (typeName != "Query" && typeName != "Mutation") || name == "" {
+			return nil, fmt.Errorf("graphql field %q must be Query.name or Mutation.name", field.Field)
+		}
+		if err := policy.checkRoles(field.Roles); err != nil {
+			return nil, fmt.Errorf("graphql field %s: %w", field.Field, err)
+		}
+	}
+
+	return policy, nil
+}
+
+func (p *Policy) checkRoles(roles []string) error {
+	if len(roles) == 0 {
+		return errors.New("no roles")
+	}
+	for _, role := range roles {
+		if role == AnyRole {
+			continue
+		}
+		declared := false
+		for _, known := range p.Roles {
+			declared = declared || known == role
+		}
+		if !declared {
+			return fmt.Errorf("undeclared role %q", role)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) parse() error {
+	method, path, ok := strings.Cut(r.Route, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("route %q must be \"METHOD /path\"", r.Route)
+	}
+	r.method = strings.ToUpper(method)
+	r.path = path
+	if trimmed, found := strings.CutSuffix(path, "/*"); found {
+		r.path = trimmed
+		r.prefix = true
+	}
+	return nil
+}
+
+func (r *Rule) matches(method, path string) bool {
+	if r.method != "*" && r.method != method {
+		return false
+	}
+	if !r.prefix {
+		return path == r.path
+	}
+	return path == r.path || strings.HasPrefix(path, r.path+"/")
+}
+
+// ServicePolicy is the part of a policy for one service
+type ServicePolicy struct {
+	service string
+	rules   []Rule
+}
+
+// Service returns the rules of service; a service without rules refuses every checked route
+func (p *Policy) Service(service string) *ServicePolicy {
+	return &ServicePolicy{service: service, rules: p.Services[service]}
+}
+
+// Allows reports whether role may call route, gin's pattern for the request path
+// ("/orders/:id", not "/orders/42"). The first rule matching method and route decides.
+func (sp *ServicePolicy) Allows(role, method, route string) bool {
+	rule := sp.match(method, route)
+	return rule != nil && rule.allows(role)
+}
+
+func (sp *ServicePolicy) match(method, route string) *Rule {
+	for i := range sp.rules {
+		if sp.rules[i].matches(method, route) {
+			return &sp.rules[i]
+		}
+	}
+	return nil
+}
+
+func (r *Rule) allows(role string) bool {
+	for _, allowed := range r.Roles {
+		if allowed == AnyRole || allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware refuses requests whose role may not call the route with 403. It must run after
+// the service's JWT middleware, which sets "role".
+func (sp *ServicePolicy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule := sp.match(c.Request.Method, c.FullPath())
+		switch {
+		case rule == nil:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "route not allowed",
+				"message": fmt.Sprintf("no %s RBAC rule for %s %s", sp.service, c.Request.Method, c.FullPath()),
+			})
+		case !rule.allows(c.GetString("role")):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": strings.Join(rule.Roles, " or ") + " role required",
+			})
+		default:
+			c.Next()
+		}
+	}
+}
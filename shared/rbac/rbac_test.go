@@ -0,0 +1,91 @@
+package rbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy, err := Parse(defaultPolicy)
+	if err != nil {
+		t.Fatalf("policy.yaml doesn't parse: %v", err)
+	}
+
+	cases := []struct {
+		service, role, method, route string
+		want                         bool
+	}{
+		{"orders", "admin", "GET", "/admin/stats", true},
+		{"orders", "customer", "GET", "/admin/stats", false},
+		{"orders", "", "POST", "/admin/orders/:id/hold", false},
+		{"cart", "customer", "POST", "/carts/items", true},
+		{"cart", "customer", "GET", "/carts", true},
+		{"cart", "customer", "GET", "/admin/funnel", false},
+		{"users", "customer", "PATCH", "/profile/:id", true},
+		{"users", "customer", "GET", "/users/deleted", false},
+		{"users", "admin", "POST", "/users/:id/restore", true},
+		{"users", "admin", "DELETE", "/users/:id/restore", false}, // no rule
+		{"users", "admin", "GET", "/users", true},
+		{"users", "customer", "GET", "/users", false},
+		{"users", "customer", "PUT", "/users/:id/role", false},
+		{"users", "admin", "POST", "/users/:id/password-reset", true},
+		{"users", "customer", "GET", "/admin/audit-logs", false},
+		{"products", "admin", "POST", "/purchase-orders", true},
+		{"products", "customer", "POST", "/purchase-orders/:id/receive", false},
+		{"products", "admin", "POST", "/returns/:id/disposition", true},
+		{"products", "customer", "GET", "/channels/:id/report", false},
+		{"products", "customer", "POST", "/inventory/reserve", false},
+		{"products", "admin", "GET", "/admin/reorder-suggestions", true},
+		{"shipping", "admin", "GET", "/shipments", false}, // no rules at all
+	}
+	for _, tc := range cases {
+		if got := policy.Service(tc.service).Allows(tc.role, tc.method, tc.route); got != tc.want {
+			t.Errorf("%s: %q %s %s = %v, want %v", tc.service, tc.role, tc.method, tc.route, got, tc.want)
+		}
+	}
+}
+
+func TestParse_RejectsMistakes(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":     "roles: [admin]\nservice: {}\n",
+		"undeclared role": "roles: [admin]\nservices:\n  cart:\n    - route: GET /admin/*\n      roles: [amdin]\n",
+		"bad route":       "roles: [admin]\nservices:\n  cart:\n    - route: /admin\n      roles: [admin]\n",
+		"bad field":       "roles: [admin]\ngraphql:\n  - field: adminStats\n    roles: [admin]\n",
+		"no roles":        "services: {}\n",
+	}
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy, err := Parse([]byte("roles: [customer, admin]\nservices:\n  orders:\n    - route: \"* /admin/*\"\n      roles: [admin]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	role := "customer"
+	admin := router.Group("/admin", func(c *gin.Context) { c.Set("role", role) }, policy.Service("orders").Middleware())
+	admin.GET("/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "admin role required") {
+		t.Fatalf("expected 403 admin role required, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	role = "admin"
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for admin, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,28 @@
+// Package reqctx derives the context handlers pass to repositories and downstream calls.
+//
+// Why: the context must come from the HTTP request, so a client disconnect or server shutdown
+// cancels in-flight queries, and it must be bounded, so one slow query can't hold a pooled
+// connection for as long as the client is willing to wait.
+package reqctx
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Handler timeouts
+const (
+	DefaultTimeout = 5 * time.Second  // single reads and writes
+	LongTimeout    = 10 * time.Second // reports and multi-step writes
+)
+
+// New returns the request's context bounded by DefaultTimeout
+func New(r *http.Request) (context.Context, context.CancelFunc) {
+	return WithTimeout(r, DefaultTimeout)
+}
+
+// WithTimeout returns the request's context bounded by timeout; an earlier request deadline still applies
+func WithTimeout(r *http.Request, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), timeout)
+}
@@ -0,0 +1,46 @@
+package reqctx
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewFollowsRequestCancellation(t *testing.T) {
+	parent, cancelRequest := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(parent)
+
+	ctx, cancel := New(req)
+	defer cancel()
+
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > DefaultTimeout {
+		t.Fatalf("deadline = %v (set %v), want within %v", deadline, ok, DefaultTimeout)
+	}
+
+	// Client went away
+	cancelRequest()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("derived context not cancelled with the request")
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", ctx.Err())
+	}
+}
+
+func TestWithTimeoutKeepsEarlierRequestDeadline(t *testing.T) {
+	parent, cancelRequest := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelRequest()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(parent)
+
+	ctx, cancel := WithTimeout(req, LongTimeout)
+	defer cancel()
+
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", ctx.Err())
+	}
+}
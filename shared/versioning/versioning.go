@@ -0,0 +1,42 @@
+// Package versioning exposes a row's optimistic concurrency version over HTTP: a strong ETag on
+// reads, and If-Match on writes.
+//
+// Why: a client that read a cart or order and then changes it should find out when someone
+// else changed it in between, rather than overwrite them. The row's version column is bumped on
+// every write and compared on the ones that race; the ETag carries it to the client.
+package versioning
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag is the strong entity tag of version of the resource id. Why the id: a new cart starts
+// at version 1 again, and an ETag from the last one mustn't match it.
+func ETag(id string, version int) string {
+	return `"` + id + "." + strconv.Itoa(version) + `"`
+}
+
+// SetETag puts version of id in the response's ETag header
+func SetETag(w http.ResponseWriter, id string, version int) {
+	w.Header().Set("ETag", ETag(id, version))
+}
+
+// IfMatch reports whether r's If-Match allows a write to version of id: there is no If-Match,
+// it is *, or it lists ETag(id, version). A W/ prefix is ignored: shared/compression weakens the
+// tag of a compressed response, but the tag names the row's version, not the response's bytes.
+func IfMatch(r *http.Request, id string, version int) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	want := ETag(id, version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == want {
+			return true
+		}
+	}
+	return false
+}
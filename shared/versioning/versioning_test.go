@@ -0,0 +1,39 @@
+package versioning
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", true},
+		{"*", true},
+		{`"cart-1.3"`, true},
+		{`"cart-1.2", "cart-1.3"`, true},
+		{`"cart-1.2"`, false},
+		{`W/"cart-1.3"`, true},
+		{`W/"cart-1.2"`, false},
+		{`"cart-2.3"`, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/carts/checkout", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+		if got := IfMatch(r, "cart-1", 3); got != tt.want {
+			t.Errorf("IfMatch(%s) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetETag(w, "42", 7)
+	if got := w.Header().Get("ETag"); got != `"42.7"` {
+		t.Errorf("ETag = %s, want \"42.7\"", got)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/rabbitmq"
+)
+
+// BrokerURLVariable points the suite at an existing RabbitMQ instead of a container. Its queues
+// must be empty: the suite declares the prost topology on it and consumes from its queues.
+const BrokerURLVariable = "TEST_RABBITMQ_URL"
+
+// BrokerImage is a RabbitMQ like the one the services run against (docker-compose.yml)
+const BrokerImage = "rabbitmq:4-management-alpine"
+
+var (
+	brokerOnce sync.Once
+	brokerURL  string
+	brokerErr  error
+)
+
+// broker returns the AMQP URL of the RabbitMQ the test binary shares. The first call starts a
+// container, removed when the test binary exits, unless TEST_RABBITMQ_URL is set.
+func broker(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration: needs RabbitMQ, skipped with -short")
+	}
+	if url := os.Getenv(BrokerURLVariable); url != "" {
+		return url
+	}
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	brokerOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		container, err := rabbitmq.Run(ctx, BrokerImage)
+		if err != nil {
+			brokerErr = fmt.Errorf("starting %s: %w", BrokerImage, err)
+			return
+		}
+		brokerURL, brokerErr = container.AmqpURL(ctx)
+	})
+	if brokerErr != nil {
+		t.Fatalf("integration: %v", brokerErr)
+	}
+	return brokerURL
+}
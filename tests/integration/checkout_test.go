@@ -0,0 +1,170 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sanketh-sg/prost/shared/fixtures"
+)
+
+// sagaTimeout bounds a checkout from POST /carts/checkout to its outcome
+const sagaTimeout = 30 * time.Second
+
+// checkoutResult is the part of GET /checkout/:correlation_id/result the tests read
+type checkoutResult struct {
+	Outcome    string  `json:"outcome"`
+	OrderIDs   []int64 `json:"order_ids"`
+	Reason     *string `json:"reason"`
+	SagaStatus string  `json:"saga_status"`
+}
+
+// TestCheckoutSaga runs checkouts through cart, orders and products over RabbitMQ, from the
+// HTTP request to the outcome the orders service reports
+func TestCheckoutSaga(t *testing.T) {
+	s := startStack(t)
+
+	lampStock, rugStock := 5, 1
+	loaded := s.database.Load(t, fixtures.Set{
+		Products: []fixtures.Product{
+			{Key: "lamp", Price: 25, Stock: &lampStock},
+			{Key: "rug", Price: 80, Stock: &rugStock},
+		},
+	})
+	lamp, rug := loaded.Products["lamp"], loaded.Products["rug"]
+	ada := s.signUp(t, "ada@example.test", "ada")
+
+	t.Run("confirms the order and commits the stock", func(t *testing.T) {
+		call(t, http.MethodPost, s.cart.URL+"/carts/items", ada.token, map[string]interface{}{
+			"product_id": lamp,
+			"quantity":   2,
+		}, http.StatusCreated, nil)
+
+		correlationID := s.checkout(t, ada)
+		result := s.waitForOutcome(t, correlationID)
+		if result.Outcome != "succeeded" || result.SagaStatus != "completed" || len(result.OrderIDs) != 1 {
+			t.Fatalf("result = %+v, want one order, succeeded", result)
+		}
+
+		var order struct {
+			Status          string  `json:"status"`
+			UserID          string  `json:"user_id"`
+			Total           float64 `json:"total"`
+			ShippingAddress struct {
+				City    string `json:"city"`
+				Country string `json:"country"`
+			} `json:"shipping_address"`
+		}
+		call(t, http.MethodGet, fmt.Sprintf("%s/orders/%d", s.orders.URL, result.OrderIDs[0]), ada.token, nil, http.StatusOK, &order)
+		if order.Status != "confirmed" || order.UserID != ada.id || order.Total != 50 {
+			t.Errorf("order = %+v, want confirmed for %s at 50", order, ada.id)
+		}
+		if order.ShippingAddress.City != "London" || order.ShippingAddress.Country != "GB" {
+			t.Errorf("shipping address = %+v, want the saved one", order.ShippingAddress)
+		}
+
+		// products commits the reservation when it sees OrderConfirmed, concurrently with orders
+		eventually(t, sagaTimeout, "the lamp reservation to be committed", func() bool {
+			return s.stock(t, lamp) == lampStock-2 && s.reservationStatus(t, result.OrderIDs[0]) == "committed"
+		})
+
+		// cart marks its copy of the saga when it sees OrderPlaced
+		eventually(t, sagaTimeout, "the cart saga to be confirmed", func() bool {
+			return s.cartSagaStatus(t, correlationID) == "order_confirmed"
+		})
+	})
+
+	t.Run("fails without enough stock and holds nothing", func(t *testing.T) {
+		call(t, http.MethodPost, s.cart.URL+"/carts/items", ada.token, map[string]interface{}{
+			"product_id": rug,
+			"quantity":   2,
+		}, http.StatusCreated, nil)
+
+		correlationID := s.checkout(t, ada)
+		result := s.waitForOutcome(t, correlationID)
+		if result.Outcome != "failed" || result.Reason == nil || *result.Reason == "" {
+			t.Fatalf("result = %+v, want failed with a reason", result)
+		}
+
+		if stock := s.stock(t, rug); stock != rugStock {
+			t.Errorf("rug stock = %d, want %d untouched", stock, rugStock)
+		}
+		var reserved int
+		if err := s.database.DB.QueryRow(`SELECT COUNT(*) FROM catalog.inventory_reservations WHERE product_id = $1 AND status = 'reserved'`, rug).Scan(&reserved); err != nil {
+			t.Fatalf("reservations: %v", err)
+		}
+		if reserved != 0 {
+			t.Errorf("%d rug reservation(s) still held", reserved)
+		}
+	})
+}
+
+// checkout starts a checkout of the shopper's cart to their saved address and returns its
+// correlation ID
+func (s *stack) checkout(t *testing.T, shopper *shopper) string {
+	t.Helper()
+	var started struct {
+		CorrelationID string `json:"correlation_id"`
+	}
+	call(t, http.MethodPost, s.cart.URL+"/carts/checkout", shopper.token, map[string]string{
+		"address_id": shopper.addressID,
+	}, http.StatusAccepted, &started)
+	if started.CorrelationID == "" {
+		t.Fatal("checkout returned no correlation_id")
+	}
+	return started.CorrelationID
+}
+
+// waitForOutcome polls the orders service until the checkout is no longer pending
+func (s *stack) waitForOutcome(t *testing.T, correlationID string) checkoutResult {
+	t.Helper()
+	var result checkoutResult
+	eventually(t, sagaTimeout, "checkout "+correlationID+" to settle", func() bool {
+		resp, err := http.Get(s.orders.URL + "/checkout/" + correlationID + "/result")
+		if err != nil {
+			t.Fatalf("checkout result: %v", err)
+		}
+		defer resp.Body.Close()
+		// 404 until orders has consumed CartCheckoutInitiated
+		if resp.StatusCode != http.StatusOK {
+			return false
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("checkout result: %v", err)
+		}
+		return result.Outcome != "pending"
+	})
+	return result
+}
+
+func (s *stack) stock(t *testing.T, productID int64) int {
+	t.Helper()
+	var stock int
+	if err := s.database.DB.QueryRow(`SELECT stock_quantity FROM catalog.products WHERE id = $1`, productID).Scan(&stock); err != nil {
+		t.Fatalf("stock of product %d: %v", productID, err)
+	}
+	return stock
+}
+
+func (s *stack) reservationStatus(t *testing.T, orderID int64) string {
+	t.Helper()
+	var status string
+	err := s.database.DB.QueryRow(`SELECT status FROM catalog.inventory_reservations WHERE order_id = $1 LIMIT 1`, orderID).Scan(&status)
+	if err != nil {
+		return ""
+	}
+	return status
+}
+
+func (s *stack) cartSagaStatus(t *testing.T, correlationID string) string {
+	t.Helper()
+	var status string
+	if err := s.database.DB.QueryRow(`SELECT status FROM cart.saga_states WHERE correlation_id = $1`, correlationID).Scan(&status); err != nil {
+		t.Fatalf("cart saga %s: %v", correlationID, err)
+	}
+	return status
+}
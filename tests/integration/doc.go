@@ -0,0 +1,8 @@
+// Package integration runs the services together against real Postgres and RabbitMQ.
+//
+// The tests carry the integration build tag, so a plain go test ./... skips them; run them
+// with make test-integration. Postgres comes from shared/fixtures (TEST_DATABASE_URL or a
+// container) and RabbitMQ from TEST_RABBITMQ_URL or a container; without Docker or either
+// variable the tests are skipped. Each service is booted in-process on an httptest server with
+// its subscribers, wired as its main.go does for the parts the checkout saga goes through.
+package integration
@@ -0,0 +1,352 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanketh-sg/prost/services/cart/addresses"
+	carthandlers "github.com/sanketh-sg/prost/services/cart/handlers"
+	cartmiddleware "github.com/sanketh-sg/prost/services/cart/middleware"
+	"github.com/sanketh-sg/prost/services/cart/pricing"
+	cartrepository "github.com/sanketh-sg/prost/services/cart/repository"
+	cartsubscribers "github.com/sanketh-sg/prost/services/cart/subscribers"
+	"github.com/sanketh-sg/prost/services/orders/autoconfirm"
+	orderhandlers "github.com/sanketh-sg/prost/services/orders/handlers"
+	orderrepository "github.com/sanketh-sg/prost/services/orders/repository"
+	"github.com/sanketh-sg/prost/services/orders/saga"
+	producthandlers "github.com/sanketh-sg/prost/services/products/handlers"
+	productrepository "github.com/sanketh-sg/prost/services/products/repository"
+	productsubscribers "github.com/sanketh-sg/prost/services/products/subscribers"
+	"github.com/sanketh-sg/prost/services/users/auth"
+	userhandlers "github.com/sanketh-sg/prost/services/users/handlers"
+	usermiddleware "github.com/sanketh-sg/prost/services/users/middleware"
+	userrepository "github.com/sanketh-sg/prost/services/users/repository"
+	"github.com/sanketh-sg/prost/shared/clock"
+	"github.com/sanketh-sg/prost/shared/config"
+	"github.com/sanketh-sg/prost/shared/db"
+	"github.com/sanketh-sg/prost/shared/fixtures"
+	"github.com/sanketh-sg/prost/shared/identity"
+	"github.com/sanketh-sg/prost/shared/jwtkeys"
+	"github.com/sanketh-sg/prost/shared/messaging"
+	"github.com/sanketh-sg/prost/shared/rbac"
+)
+
+// jwtSecret is the JWT_SECRET every service of the stack shares
+const jwtSecret = "integration-secret"
+
+// Saga timings, short so a checkout settles in seconds
+const (
+	autoConfirmWindow  = time.Second
+	workerInterval     = 200 * time.Millisecond
+	reservationTimeout = 30 * time.Second
+	messageTimeout     = 10 * time.Second // each main.go's per-message timeout
+)
+
+// stack is the users, products, cart and orders services running in the test process against
+// one migrated database and the shared broker
+type stack struct {
+	database *fixtures.Database
+	users    *httptest.Server
+	products *httptest.Server
+	cart     *httptest.Server
+	orders   *httptest.Server
+}
+
+// startStack boots the services; everything is stopped when the test ends.
+// Why: the services share the broker's queues, so a test binary runs one stack at a time.
+func startStack(t *testing.T) *stack {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	amqpURL := broker(t)
+	s := &stack{database: fixtures.Open(t)}
+
+	keys := jwtkeys.NewHMACKeySet(jwtSecret)
+	policy, err := rbac.Load()
+	if err != nil {
+		t.Fatalf("rbac policy: %v", err)
+	}
+
+	ctx, stopWorkers := context.WithCancel(context.Background())
+	var subscribers []*messaging.Subscriber
+	t.Cleanup(func() {
+		drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := messaging.DrainSubscribers(drainCtx, subscribers...); err != nil {
+			t.Logf("drain: %v", err)
+		}
+		stopWorkers()
+	})
+
+	// connect opens a service's own broker connection, as its process would
+	connect := func() *messaging.Connection {
+		conn, err := messaging.NewRmqConnection(amqpURL)
+		if err != nil {
+			t.Fatalf("rabbitmq: %v", err)
+		}
+		if err := conn.SetupRabbitMQ(messaging.GetProstTopology()); err != nil {
+			t.Fatalf("rabbitmq topology: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	// consume runs handle for every message of queue, with the per-message timeout of main.go
+	consume := func(conn *messaging.Connection, queue string, handle func(ctx context.Context, message []byte) error) {
+		subscriber := messaging.NewSubscriber(conn, queue)
+		subscribers = append(subscribers, subscriber)
+		go func() {
+			if err := subscriber.Subscribe(func(message []byte) error {
+				ctx, cancel := context.WithTimeout(context.Background(), messageTimeout)
+				defer cancel()
+				return handle(ctx, message)
+			}); err != nil {
+				t.Logf("%s subscriber: %v", queue, err)
+			}
+		}()
+	}
+
+	serve := func(router *gin.Engine) *httptest.Server {
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	// Users: accounts, logins and saved addresses
+	{
+		conn := s.database.Connect(t, "users")
+		userRepo := userrepository.NewUserRepository(conn)
+		jwtManager := auth.NewJWTManagerWithKeys(keys)
+		hasher := auth.NewPasswordHasher(config.PasswordHashing{Algorithm: "bcrypt", BcryptCost: 4})
+		userHandler := userhandlers.NewUserHandler(userRepo, jwtManager, hasher)
+
+		router := gin.New()
+		router.POST("/register", userHandler.Register)
+		router.POST("/login", userHandler.Login)
+		protected := router.Group("/", usermiddleware.AuthMiddleware(jwtManager), policy.Service("users").Middleware())
+		protected.GET("profile/:id/addresses/:address_id", userHandler.GetAddress)
+		protected.POST("profile/:id/addresses", userHandler.CreateAddress)
+		s.users = serve(router)
+	}
+
+	// Products: the catalog, and stock reserved and committed for orders
+	{
+		conn := s.database.Connect(t, "catalog")
+		clk := clock.New()
+		productRepo := productrepository.NewProductRepository(conn)
+		inventoryRepo := productrepository.NewInventoryReservationRepository(conn, clk)
+		idempotencyStore := db.NewIdempotencyStore(conn)
+
+		rmq := connect()
+		publisher := messaging.NewPublisher(rmq, "products.events")
+		stockSubscriptionHandler := producthandlers.NewStockSubscriptionHandler(productrepository.NewStockSubscriptionRepository(conn, clk), productRepo, publisher, 0)
+		productHandler := producthandlers.NewProductHandler(
+			productRepo,
+			productrepository.NewCategoryRepository(conn),
+			productrepository.NewVariantRepository(conn),
+			productrepository.NewAttributeTemplateRepository(conn),
+			inventoryRepo,
+			idempotencyStore,
+			publisher,
+			stockSubscriptionHandler,
+		)
+
+		router := gin.New()
+		router.GET("/products/:id", productHandler.GetProduct)
+		router.GET("/inventory/:product_id", productHandler.GetInventory)
+		s.products = serve(router)
+
+		eventHandler := productsubscribers.NewEventHandler(inventoryRepo, idempotencyStore, publisher)
+		consume(rmq, "products.events.queue", eventHandler.HandleEvent)
+	}
+
+	// Orders: the saga orchestrator, with placed orders auto-confirmed after a second
+	{
+		conn := s.database.Connect(t, "orders")
+		orderRepo := orderrepository.NewOrderRepository(conn)
+		sagaRepo := orderrepository.NewSagaStateRepository(conn)
+		compensationRepo := orderrepository.NewCompensationLogRepository(conn)
+		inventoryResRepo := orderrepository.NewInventoryReservationRepository(conn)
+		holdRepo := orderrepository.NewHoldRepository(conn)
+		idempotencyStore := db.NewIdempotencyStore(conn)
+
+		rmq := connect()
+		publisher := messaging.NewPublisher(rmq, "orders.events")
+		t.Cleanup(func() { publisher.Close() })
+
+		// No 3PL, no warehouse routing and no tax: one untaxed order per checkout
+		orchestrator := saga.NewSagaOrchestrator(
+			orderRepo,
+			orderrepository.NewCheckoutRepository(conn),
+			sagaRepo,
+			compensationRepo,
+			inventoryResRepo,
+			idempotencyStore,
+			publisher,
+			nil,
+			nil,
+			nil,
+			orderrepository.NewPaymentRepository(conn),
+			holdRepo,
+			orderrepository.NewFulfillmentRepository(conn, clock.New()),
+			0,
+			reservationTimeout,
+			clock.New(),
+		)
+		orderHandler := orderhandlers.NewOrderHandler(orderRepo, sagaRepo, compensationRepo, inventoryResRepo, idempotencyStore, publisher, orchestrator)
+
+		router := gin.New()
+		router.GET("/orders/:id", identity.Require(keys), orderHandler.GetOrder)
+		router.GET("/checkout/:correlation_id/result", orderHandler.GetCheckoutResult)
+		s.orders = serve(router)
+
+		consume(rmq, "orders.events.queue", orchestrator.HandleEvent)
+		autoconfirm.NewWorker(holdRepo, publisher, clock.New(), autoconfirm.Config{
+			Window:    autoConfirmWindow,
+			Interval:  workerInterval,
+			BatchSize: 100,
+		}).Start(ctx)
+		orchestrator.StartReservationExpiry(ctx, workerInterval, 100)
+	}
+
+	// Cart: priced from products, shipped to an address read from users; no soft locks
+	{
+		conn := s.database.Connect(t, "cart")
+		clk := clock.New()
+		cartRepo := cartrepository.NewCartRepository(conn, clk)
+		sagaRepo := cartrepository.NewSagaStateRepository(conn)
+		inventoryLockRepo := cartrepository.NewInventoryLockRepository(conn, clk)
+		couponRepo := cartrepository.NewCouponRepository(conn, clk)
+		idempotencyStore := db.NewIdempotencyStore(conn)
+
+		rmq := connect()
+		publisher := messaging.NewPublisher(rmq, "cart.events")
+		cartHandler := carthandlers.NewCartHandler(
+			cartRepo,
+			sagaRepo,
+			inventoryLockRepo,
+			idempotencyStore,
+			couponRepo,
+			publisher,
+			pricing.NewClient(s.products.URL, 2*time.Second),
+			nil,
+			addresses.NewClient(s.users.URL, 2*time.Second),
+		)
+
+		router := gin.New()
+		carts := router.Group("/carts", cartmiddleware.CartAuthMiddleware(keys, nil), policy.Service("cart").Middleware())
+		carts.GET("", cartHandler.GetCart)
+		carts.POST("/items", cartHandler.AddItem)
+		carts.POST("/checkout", cartmiddleware.RequireUser(), cartHandler.CheckoutCart)
+		s.cart = serve(router)
+
+		eventHandler := cartsubscribers.NewEventHandler(cartRepo, sagaRepo, inventoryLockRepo, couponRepo, idempotencyStore, nil)
+		consume(rmq, "cart.events.queue", eventHandler.HandleEvent)
+	}
+
+	return s
+}
+
+// shopper is a registered user with a saved address
+type shopper struct {
+	id        string
+	token     string
+	addressID string
+}
+
+// signUp registers a user through the users service, logs in and saves an address
+func (s *stack) signUp(t *testing.T, email, username string) *shopper {
+	t.Helper()
+	password := "correct-horse-battery"
+
+	call(t, http.MethodPost, s.users.URL+"/register", "", map[string]string{
+		"email":    email,
+		"username": username,
+		"password": password,
+	}, http.StatusCreated, nil)
+
+	var login struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		AccessToken string `json:"access_token"`
+	}
+	call(t, http.MethodPost, s.users.URL+"/login", "", map[string]string{
+		"email":    email,
+		"password": password,
+	}, http.StatusOK, &login)
+
+	var address struct {
+		ID string `json:"id"`
+	}
+	call(t, http.MethodPost, s.users.URL+"/profile/"+login.User.ID+"/addresses", login.AccessToken, map[string]interface{}{
+		"recipient_name": "Ada Lovelace",
+		"line1":          "12 St James's Square",
+		"city":           "London",
+		"postal_code":    "SW1Y 4JH",
+		"country":        "gb",
+		"is_default":     true,
+	}, http.StatusCreated, &address)
+
+	return &shopper{id: login.User.ID, token: login.AccessToken, addressID: address.ID}
+}
+
+// call sends a JSON request, fails the test unless the response has status, and decodes the
+// response into out when it isn't nil
+func call(t *testing.T, method, url, token string, body interface{}, status int, out interface{}) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s = %d, want %d: %s", method, url, resp.StatusCode, status, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, url, data, err)
+		}
+	}
+}
+
+// eventually polls check until it returns true or the timeout passes
+func eventually(t *testing.T, timeout time.Duration, what string, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for %s", timeout, what)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}